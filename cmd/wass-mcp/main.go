@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	_ "embed"
 	"encoding/json"
 	"errors"
@@ -11,27 +12,80 @@ import (
 	_ "net/http/pprof" //nolint:gosec
 	"os"
 	"os/signal"
+	"path/filepath"
 	"strings"
 	"syscall"
 	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/rs/zerolog"
+	"github.com/tb0hdan/wass-mcp/pkg/admin"
+	"github.com/tb0hdan/wass-mcp/pkg/apikey"
+	"github.com/tb0hdan/wass-mcp/pkg/artifacts"
+	"github.com/tb0hdan/wass-mcp/pkg/bundle"
+	"github.com/tb0hdan/wass-mcp/pkg/crypto"
+	"github.com/tb0hdan/wass-mcp/pkg/datadir"
+	"github.com/tb0hdan/wass-mcp/pkg/info"
+	"github.com/tb0hdan/wass-mcp/pkg/intel"
+	"github.com/tb0hdan/wass-mcp/pkg/jobs"
+	"github.com/tb0hdan/wass-mcp/pkg/limiter"
+	"github.com/tb0hdan/wass-mcp/pkg/logging"
+	"github.com/tb0hdan/wass-mcp/pkg/metrics"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/redact"
+	"github.com/tb0hdan/wass-mcp/pkg/scanconfig"
+	"github.com/tb0hdan/wass-mcp/pkg/scope"
 	"github.com/tb0hdan/wass-mcp/pkg/server"
 	"github.com/tb0hdan/wass-mcp/pkg/storage"
+	"github.com/tb0hdan/wass-mcp/pkg/suppress"
+	"github.com/tb0hdan/wass-mcp/pkg/tenant"
+	"github.com/tb0hdan/wass-mcp/pkg/tlscert"
 	"github.com/tb0hdan/wass-mcp/pkg/tools"
+	"github.com/tb0hdan/wass-mcp/pkg/tools/checkscope"
+	"github.com/tb0hdan/wass-mcp/pkg/tools/compare"
+	"github.com/tb0hdan/wass-mcp/pkg/tools/continueoutput"
+	"github.com/tb0hdan/wass-mcp/pkg/tools/crawl"
+	"github.com/tb0hdan/wass-mcp/pkg/tools/credentials"
 	"github.com/tb0hdan/wass-mcp/pkg/tools/fullscan"
 	"github.com/tb0hdan/wass-mcp/pkg/tools/history"
-	"github.com/tb0hdan/wass-mcp/pkg/tools/nikto"
-	"github.com/tb0hdan/wass-mcp/pkg/tools/nuclei"
-	"github.com/tb0hdan/wass-mcp/pkg/tools/shcheck"
-	"github.com/tb0hdan/wass-mcp/pkg/tools/wapiti"
+	"github.com/tb0hdan/wass-mcp/pkg/tools/scanjobs"
+	"github.com/tb0hdan/wass-mcp/pkg/tools/scantemplates"
+	"github.com/tb0hdan/wass-mcp/pkg/tools/setcontext"
+	"github.com/tb0hdan/wass-mcp/pkg/tools/summarize"
+	"github.com/tb0hdan/wass-mcp/pkg/tools/suppressions"
+	"github.com/tb0hdan/wass-mcp/pkg/tools/targetgroups"
+	"github.com/tb0hdan/wass-mcp/pkg/tools/targetprofile"
+	"github.com/tb0hdan/wass-mcp/pkg/tools/targetstatus"
+	"github.com/tb0hdan/wass-mcp/pkg/tools/timeline"
+	"github.com/tb0hdan/wass-mcp/pkg/tools/trends"
+	"github.com/tb0hdan/wass-mcp/pkg/tools/triage"
+	"github.com/tb0hdan/wass-mcp/pkg/tools/wordlists"
+	"github.com/tb0hdan/wass-mcp/pkg/types"
+	"github.com/tb0hdan/wass-mcp/pkg/vault"
+	"github.com/tb0hdan/wass-mcp/pkg/wordlist"
 )
 
 const (
 	ServerName      = "wass-mcp"
 	ServiceName     = "Web Application Security Scanner MCP Server"
 	ShutdownTimeout = 10 * time.Second
+	MCPEndpoint     = "/mcp"
+	MetricsEndpoint = "/metrics"
+	AdminTokenEnv   = "WASS_ADMIN_TOKEN"
+	// APIKeyEnv is the default of --api-key.
+	APIKeyEnv = "WASS_API_KEY"
+	// InteractshTokenEnv is the default of --nuclei-interactsh-token.
+	InteractshTokenEnv = "WASS_INTERACTSH_TOKEN"
+	// ZAPAPIKeyEnv is the default of --zap-api-key.
+	ZAPAPIKeyEnv = "WASS_ZAP_API_KEY"
+	// WPScanAPITokenEnv is the default of --wpscan-api-token.
+	WPScanAPITokenEnv = "WASS_WPSCAN_API_TOKEN"
+	// ToolsVersionsEndpoint reports the versions of the scanner binaries.
+	ToolsVersionsEndpoint = "/tools_versions"
+	// ProfilingPrefix is the path prefix of the profiling endpoints of net/http/pprof.
+	ProfilingPrefix = "/debug/pprof/"
+	// ToolCheckTimeout bounds --check-tools and --record-tools.
+	ToolCheckTimeout = time.Minute
 )
 
 //go:embed VERSION
@@ -39,15 +93,122 @@ var Version string
 
 func main() {
 	var (
-		debug        bool
-		bindAddr     string
-		dbPath       string
-		printVersion bool
+		debug          bool
+		bindAddr       string
+		dataDir        string
+		dbPath         string
+		printVersion   bool
+		artifactDir    string
+		artifactRet    artifacts.RetentionConfig
+		workDir        string
+		maxOutputBytes int
+		maxRespBytes   int
+		compressAbove  int
+		redactFields   string
+		redactHeaders  string
+		redactPattern  []string
+		requeue        bool
+		maxScans       int
+		maxJobs        int
+		scanTimeout    time.Duration
+		fullTimeout    time.Duration
+		jobQueue       string
+		jobWorker      bool
+		jobWorkerID    string
+		adminToken     string
+		apiKey         string
+		retention      time.Duration
+		hardDelete     bool
+		secureErase    bool
+		tenantKeys     string
+		intelCfg       intel.Config
+		suppressFile   string
+		scopeFile      string
+		niktoConfig    string
+		wapitiConfig   string
+		scannerConfigs scanconfig.Config
+		keyFile        string
+		wordlistDir    string
+		wordlistMax    int64
+		embeddedTools  bool
+		toolsDir       string
+		checkTools     bool
+		recordTools    bool
+		skipWarmUp     bool
+		scannerCfg     scannerConfig
+		tlsCert        string
+		tlsKey         string
+		tlsSelfSigned  bool
+		branding       models.ReportBranding
+		logCfg         logging.Config
 	)
-	flag.BoolVar(&debug, "debug", false, "debug mode")
+	flag.BoolVar(&debug, "debug", false, "debug mode, shorthand for --log-level debug")
 	flag.StringVar(&bindAddr, "bind", "localhost:8989", "bind address (host:port)")
-	flag.StringVar(&dbPath, "db", "build/wass-mcp.db", "SQLite database file path")
+	flag.StringVar(&dataDir, "data-dir", datadir.DefaultRoot, "data directory holding the database, artifacts, logs, wordlists, templates and plugins")
+	flag.StringVar(&dbPath, "db", "", "SQLite database file path (default <data-dir>/"+datadir.DBFile+")")
 	flag.BoolVar(&printVersion, "version", false, "print version and exit")
+	flag.StringVar(&artifactDir, "artifact-dir", "", "directory for outputs exceeding --max-output-bytes (default <data-dir>/"+datadir.ArtifactsDir+")")
+	flag.DurationVar(&artifactRet.MaxAge, "artifact-retention", 0, "time after its last use at which an artifact file is removed, independently of its execution, 0 to keep artifacts")
+	flag.Int64Var(&artifactRet.MaxBytes, "artifact-max-bytes", 0, "disk quota of --artifact-dir, least recently used artifacts are removed above it, 0 for unlimited")
+	flag.DurationVar(&artifactRet.Interval, "artifact-sweep-interval", types.DefaultArtifactSweepInterval, "interval at which artifacts are checked against --artifact-retention and --artifact-max-bytes")
+	flag.StringVar(&workDir, "work-dir", "", "directory for per-scan working directories, e.g. a tmpfs mount (default: system temp directory)")
+	flag.IntVar(&maxOutputBytes, "max-output-bytes", types.DefaultMaxOutputBytes, "maximum output size stored in the database, 0 for unlimited")
+	flag.IntVar(&maxRespBytes, "max-response-bytes", types.DefaultMaxResponseBytes, "maximum output bytes returned per tool call before a continuation cursor, 0 for unlimited")
+	flag.IntVar(&compressAbove, "compress-threshold", types.DefaultCompressThreshold, "report page size in bytes above which full_scan returns the page gzip-compressed without the client asking, 0 to never")
+	flag.StringVar(&redactFields, "redact-fields", "", "comma-separated extra JSON field names to redact in stored executions")
+	flag.StringVar(&redactHeaders, "redact-headers", "", "comma-separated extra HTTP header names to redact in stored executions")
+	flag.IntVar(&maxJobs, "max-concurrent-jobs", types.DefaultMaxConcurrentJobs, "maximum background scan jobs running at once, further jobs stay queued, 0 for unlimited")
+	flag.StringVar(&jobQueue, "job-queue", jobs.QueueSQLite, "scan job queue shared by front ends and workers: sqlite, or a redis://[user:password@]host:port[/db][?key=name] URL")
+	flag.BoolVar(&jobWorker, "job-worker", true, "run queued scan jobs in this process; disable on front ends of a shared queue")
+	flag.StringVar(&jobWorkerID, "job-worker-id", "", "ID recorded on the scan jobs this process runs, stable across restarts (default host name)")
+	flag.IntVar(&maxScans, "max-concurrent-scans", types.DefaultMaxConcurrentScans, "maximum scanner runs in flight across all tools, 0 for unlimited")
+	flag.DurationVar(&scanTimeout, "scan-timeout", 0, "default bound of each scanner run of calls that set no timeout, 0 for no bound")
+	flag.DurationVar(&fullTimeout, "full-scan-timeout", 0, "default bound of a whole full_scan call that sets no total_timeout, 0 for no bound")
+	flag.StringVar(&adminToken, "admin-token", os.Getenv(AdminTokenEnv), "bearer token enabling the admin endpoints (default $"+AdminTokenEnv+")")
+	flag.StringVar(&apiKey, "api-key", os.Getenv(APIKeyEnv), "bearer API key required on MCP requests of single-tenant deployments (default $"+APIKeyEnv+")")
+	flag.BoolVar(&hardDelete, "hard-delete", false, "make history delete and clear remove executions permanently, with their findings and artifacts, instead of soft-deleting them")
+	flag.BoolVar(&secureErase, "secure-erase", false, "overwrite artifact files with random data before removing them, on purge, prune, hard delete and artifact eviction")
+	flag.DurationVar(&retention, "retention", 0, "default age of executions removed by admin pruning, 0 to require an explicit age")
+	flag.StringVar(&tlsCert, "tls-cert", "", "PEM certificate file serving the endpoints over HTTPS, with --tls-key")
+	flag.StringVar(&tlsKey, "tls-key", "", "PEM private key file of --tls-cert")
+	flag.BoolVar(&tlsSelfSigned, "tls-self-signed", false, "serve the endpoints over HTTPS with a self-signed certificate generated in <data-dir>/"+datadir.TLSDir)
+	flag.StringVar(&tenantKeys, "tenant-keys", "", "file of tenant:key[:role] lines; requires an API key on MCP requests and isolates data per tenant")
+	flag.StringVar(&intelCfg.EPSSFile, "epss-file", "", "FIRST EPSS scores CSV (optionally gzipped) used to enrich CVE-linked findings")
+	flag.StringVar(&intelCfg.KEVFile, "kev-file", "", "CISA Known Exploited Vulnerabilities catalog JSON used to enrich CVE-linked findings")
+	flag.DurationVar(&intelCfg.Refresh, "intel-refresh", types.DefaultIntelRefresh, "interval at which --epss-file and --kev-file are reloaded when changed, 0 to load once")
+	flag.StringVar(&niktoConfig, "nikto-config", "", "nikto.conf used by nikto scans that name no config")
+	flag.StringVar(&scannerCfg.interactshServer, "nuclei-interactsh-server", "", "interactsh server nuclei out-of-band templates report to (default: nuclei public servers)")
+	flag.StringVar(&scannerCfg.interactshToken, "nuclei-interactsh-token", os.Getenv(InteractshTokenEnv), "token of --nuclei-interactsh-server (default $"+InteractshTokenEnv+")")
+	flag.BoolVar(&scannerCfg.interactshDisabled, "nuclei-no-interactsh", false, "disable nuclei out-of-band testing for every scan")
+	flag.StringVar(&scannerCfg.zapAPIURL, "zap-api-url", "", "API URL of a running ZAP daemon zap scans run through instead of zap-baseline.py")
+	flag.StringVar(&scannerCfg.zapAPIKey, "zap-api-key", os.Getenv(ZAPAPIKeyEnv), "API key of --zap-api-url (default $"+ZAPAPIKeyEnv+")")
+	flag.StringVar(&scannerCfg.wpscanToken, "wpscan-api-token", os.Getenv(WPScanAPITokenEnv), "WPScan API token of wpscan scans that pass no api_token option, for vulnerability data (default $"+WPScanAPITokenEnv+")")
+	flag.StringVar(&wapitiConfig, "wapiti-config", "", "file of extra wapiti arguments used by wapiti scans that name no config")
+	flag.StringVar(&scannerConfigs.Dir, "scanner-config-dir", "", "allowlisted directory of per-call scanner config files, one subdirectory per scanner")
+	flag.StringVar(&keyFile, "encryption-key-file", "", "file of base64 32-byte master keys encrypting stored secrets, primary key first (default $"+crypto.KeyEnv+")")
+	flag.StringVar(&wordlistDir, "wordlist-dir", "", "directory of the wordlists scans reference by name (default <data-dir>/"+datadir.WordlistsDir+")")
+	flag.Int64Var(&wordlistMax, "wordlist-max-bytes", wordlist.DefaultMaxSize, "maximum size of an uploaded wordlist")
+	flag.BoolVar(&embeddedTools, "embedded-tools", false, "prefer the scanner binaries bundled in --tools-dir over PATH and validate their recorded versions")
+	flag.StringVar(&toolsDir, "tools-dir", bundle.DefaultDir, "directory of bundled scanner binaries (bin/) and their version manifest, used with --embedded-tools")
+	flag.BoolVar(&checkTools, "check-tools", false, "print the scanner binary versions as JSON and exit, non-zero when a bundled binary does not match its recorded version")
+	flag.BoolVar(&recordTools, "record-tools", false, "record the versions of the bundled scanner binaries in the --tools-dir manifest and exit, requires --embedded-tools")
+	flag.BoolVar(&skipWarmUp, "skip-warmup", false, "skip running each scanner with benign flags at startup to detect broken installs")
+	flag.StringVar(&suppressFile, "suppressions", "", "JSON file of finding suppression rules applied to every tenant")
+	flag.StringVar(&scopeFile, "scope-file", "", "file of scope rules, one host, *.domain, IP or CIDR per line with an optional :port or :port-port; scans of other targets are refused")
+	flag.StringVar(&logCfg.Output, "log-output", logging.DefaultOutput, "log destination: stdout, stderr or a file path rotated by size, bare file names in <data-dir>/"+datadir.LogsDir)
+	flag.StringVar(&logCfg.Format, "log-format", logging.DefaultFormat, "log format: json or console")
+	flag.StringVar(&logCfg.Level, "log-level", logging.DefaultLevel, "minimum log level: trace, debug, info, warn, error, fatal or panic")
+	flag.IntVar(&logCfg.MaxSizeMB, "log-max-size", logging.DefaultMaxSizeMB, "size in MB at which the log file is rotated, 0 to never rotate")
+	flag.IntVar(&logCfg.MaxBackups, "log-max-backups", logging.DefaultMaxBackups, "number of rotated log files kept")
+	flag.StringVar(&branding.Assessor, "report-assessor", "", "assessor named in the header of full_scan reports")
+	flag.StringVar(&branding.Banner, "report-banner", "", "confidentiality banner printed above and below full_scan reports")
+	flag.StringVar(&branding.EngagementID, "report-engagement-id", "", "engagement ID printed in the header of full_scan reports")
+	flag.StringVar(&branding.Organization, "report-organization", "", "organization named in the header of full_scan reports")
+	flag.BoolVar(&requeue, "requeue-interrupted", false, "re-run interrupted executions that asked to be retried on restart")
+	flag.Func("redact-pattern", "extra regular expression to redact in stored executions (repeatable)", func(value string) error {
+		redactPattern = append(redactPattern, value)
+		return nil
+	})
 	flag.Parse()
 	// Sanitize version
 	version := strings.TrimSpace(Version)
@@ -57,14 +218,50 @@ func main() {
 		os.Exit(0)
 	}
 
+	// Prefer the scanner binaries bundled with the container image over PATH
+	if embeddedTools {
+		toolBundle, err := bundle.Load(toolsDir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to load embedded tools: %v\n", err)
+			os.Exit(1)
+		}
+		bundle.Use(toolBundle)
+	}
+	if checkTools || recordTools {
+		os.Exit(runToolCheck(recordTools))
+	}
+
 	signalCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
-	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	// Derive the paths not set by their own flags from the data directory and check them
+	layout := datadir.New(dataDir)
+	if dbPath == "" {
+		dbPath = layout.DB()
+	}
+	if artifactDir == "" {
+		artifactDir = layout.Artifacts()
+	}
+	if wordlistDir == "" {
+		wordlistDir = layout.Wordlists()
+	}
+	logCfg.Output = layout.LogFile(logCfg.Output)
+	if err := layout.Prepare(filepath.Dir(dbPath), artifactDir, wordlistDir, workDir); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to prepare data directory: %v\n", err)
+		os.Exit(1)
+	}
+
 	if debug {
-		zerolog.SetGlobalLevel(zerolog.DebugLevel)
-		logger.Debug().Msg("debug mode enabled")
+		logCfg.Level = zerolog.DebugLevel.String()
 	}
+	logger, logCloser, err := logging.New(logCfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to configure logging: %v\n", err)
+		os.Exit(1)
+	}
+	defer logCloser.Close()
+	logger.Debug().Msg("debug mode enabled")
+	logger.Info().Msgf("Using data directory %s", layout.Root)
 
 	impl := &mcp.Implementation{
 		Name:    ServerName,
@@ -75,6 +272,8 @@ func main() {
 	storeCfg := storage.Config{
 		DatabasePath: dbPath,
 		Debug:        debug,
+		HardDelete:   hardDelete,
+		SecureErase:  secureErase,
 	}
 	store, err := storage.NewSQLiteStorage(storeCfg)
 	if err != nil {
@@ -85,18 +284,133 @@ func main() {
 
 	srv := server.NewServer(impl, store)
 
+	redactor, err := redact.New(redact.Config{
+		Fields:   splitList(redactFields),
+		Headers:  splitList(redactHeaders),
+		Patterns: redactPattern,
+	})
+	if err != nil {
+		logger.Fatal().Msgf("Failed to configure redaction: %v", err)
+	}
+	srv.SetRedactor(redactor)
+	srv.SetArtifacts(artifacts.Config{Dir: artifactDir, MaxOutputBytes: maxOutputBytes})
+	// Evict artifacts on their own schedule, independently of execution retention
+	artifactRet.Dir = artifactDir
+	artifactRet.Shred = secureErase
+	go artifacts.RunRetention(signalCtx, artifactRet, logger)
+	srv.SetWorkDir(workDir)
+	scannerConfigs.Defaults = map[string]string{"nikto": niktoConfig, "wapiti": wapitiConfig}
+	if err := scannerConfigs.Validate(); err != nil {
+		logger.Fatal().Msgf("Failed to configure scanner config files: %v", err)
+	}
+	srv.SetScannerConfigs(scannerConfigs)
+	// Decrypt the stored credentials scans authenticate with
+	keyring, err := crypto.LoadKeyring(keyFile)
+	if err != nil {
+		logger.Fatal().Msgf("Failed to load encryption keys: %v", err)
+	}
+	if keyring != nil {
+		logger.Info().Msgf("Encrypting stored secrets with master key %s", keyring.Primary())
+	}
+	srv.SetVault(vault.New(keyring))
+	srv.SetWordlists(wordlist.New(wordlistDir, wordlistMax))
+	srv.SetScanLimiter(limiter.New(maxScans))
+	srv.SetScanTimeout(scanTimeout)
+	srv.SetFullScanTimeout(fullTimeout)
+	srv.SetMaxResponseBytes(maxRespBytes)
+	srv.SetCompressThreshold(compressAbove)
+	srv.SetReportBranding(branding)
+	srv.SetMetrics(metrics.New())
+
+	// Enrich CVE-linked findings with exploit intelligence, reloaded as the datasets are refreshed
+	threatIntel := intel.New(intelCfg, logger)
+	if err := threatIntel.Load(); err != nil {
+		logger.Fatal().Msgf("Failed to load exploit intelligence: %v", err)
+	}
+	if status := threatIntel.Status(); status.EPSSEntries > 0 || status.KEVEntries > 0 {
+		logger.Info().Msgf("Loaded %d EPSS scores and %d known exploited vulnerabilities", status.EPSSEntries, status.KEVEntries)
+	}
+	srv.SetIntel(threatIntel)
+	go threatIntel.Run(signalCtx)
+
+	// Drop accepted noise matching the configured suppression rules when output is parsed
+	if suppressFile != "" {
+		rules, err := suppress.LoadFile(suppressFile)
+		if err != nil {
+			logger.Fatal().Msgf("Failed to load suppression rules: %v", err)
+		}
+		logger.Info().Msgf("Loaded %d suppression rules from %s", len(rules), suppressFile)
+		srv.SetSuppressionRules(rules)
+	}
+
+	// Refuse scans of targets outside the configured scope
+	if scopeFile != "" {
+		policy, err := scope.LoadFile(scopeFile)
+		if err != nil {
+			logger.Fatal().Msgf("Failed to load scope rules: %v", err)
+		}
+		logger.Info().Msgf("Loaded %d scope rules from %s", len(policy.Rules()), scopeFile)
+		srv.SetScope(policy)
+	}
+
 	// Create scanner instances.
-	scanners := []tools.Scanner{
-		nikto.New(logger),
-		wapiti.New(logger),
-		nuclei.New(logger),
-		shcheck.New(logger),
+	if err := configureScanners(logger, scannerCfg); err != nil {
+		logger.Fatal().Msgf("Failed to configure scanners: %v", err)
+	}
+	scanners := newScanners(logger, scannerCfg)
+	if len(scanners) == 0 {
+		logger.Warn().Msg("No scanners compiled into this build")
+	}
+	if toolBundle := bundle.Active(); toolBundle != nil {
+		report := info.ToolVersions(signalCtx, scanners...)
+		for _, status := range report.Tools {
+			if !status.Healthy {
+				logger.Error().Msgf("Embedded tool %s failed its health check: %s", status.Name, status.Error)
+			}
+		}
+		logger.Info().Msgf("Using embedded tools from %s", toolBundle.BinDir())
+	}
+	// Run each scanner with benign flags, so that broken installs are reported unavailable
+	if !skipWarmUp {
+		tools.WarmUp(signalCtx, logger, scanners...)
 	}
+	// Parse stored outputs with the scanners' native parsers
+	tools.RegisterFindingsParsers(scanners...)
+	// Check scanner availability once warmed up, shared by full_scan, the admin API and the capability document
+	registry := tools.NewRegistry(scanners...)
 
 	// Create tool instances.
+	fullScan := fullscan.New(logger, registry)
+	jobQ, err := jobs.NewQueue(jobQueue, srv.Storage())
+	if err != nil {
+		logger.Fatal().Msgf("Failed to create scan job queue: %v", err)
+	}
+	jobOptions := []jobs.Option{jobs.WithQueue(jobQ)}
+	if jobWorkerID != "" {
+		jobOptions = append(jobOptions, jobs.WithWorker(jobWorkerID))
+	}
+	scanJobs := jobs.New(srv, maxJobs, logger, jobOptions...)
+	defer scanJobs.Close()
 	toolList := []tools.Tool{
-		fullscan.New(logger, scanners...),
+		checkscope.New(logger),
+		compare.New(logger),
+		continueoutput.New(logger),
+		crawl.New(logger),
+		credentials.New(logger),
+		fullScan,
 		history.New(logger),
+		scanjobs.New(logger, scanJobs),
+		scantemplates.New(logger, fullScan.(*fullscan.Tool)),
+		setcontext.New(logger),
+		summarize.New(logger),
+		suppressions.New(logger),
+		targetgroups.New(logger),
+		targetprofile.New(logger),
+		targetstatus.New(logger),
+		timeline.New(logger),
+		trends.New(logger),
+		triage.New(logger),
+		wordlists.New(logger),
 	}
 
 	// Add individual scanners as tools
@@ -110,33 +424,136 @@ func main() {
 			logger.Error().Msgf("Failed to register tool: %v", err)
 		}
 	}
+
+	// Recover executions left running by a previous process
+	interrupted, err := srv.RecoverInterrupted(signalCtx, requeue, func(exec models.ToolExecution, err error) {
+		if err != nil {
+			logger.Error().Msgf("Re-run of interrupted %s execution %d failed: %v", exec.ToolName, exec.ID, err)
+			return
+		}
+		logger.Info().Msgf("Re-ran interrupted %s execution %d", exec.ToolName, exec.ID)
+	})
+	if err != nil {
+		logger.Error().Msgf("Failed to recover interrupted executions: %v", err)
+	} else if len(interrupted) > 0 {
+		logger.Warn().Msgf("Marked %d executions interrupted by the previous shutdown", len(interrupted))
+	}
+	// Mark scan jobs left running by this worker as interrupted and queue the queued ones again
+	interruptedJobs, queuedJobs, err := scanJobs.Recover(signalCtx)
+	if err != nil {
+		logger.Error().Msgf("Failed to recover scan jobs: %v", err)
+	}
+	if len(interruptedJobs) > 0 {
+		logger.Warn().Msgf("Marked %d scan jobs interrupted by the previous shutdown", len(interruptedJobs))
+	}
+	if len(queuedJobs) > 0 {
+		logger.Info().Msgf("Queued %d scan jobs again", len(queuedJobs))
+	}
+	if jobWorker {
+		go scanJobs.Work(signalCtx)
+	}
 	// Create HTTP handler for MCP server
 	// Stateless mode avoids "session not found" errors after server restart
-	handler := mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server {
+	var handler http.Handler = mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server {
 		return &srv.Server
 	}, &mcp.StreamableHTTPOptions{
 		Stateless: true,
 	})
 
-	http.Handle("/mcp", handler)
-
-	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		_ = json.NewEncoder(w).Encode(map[string]interface{}{
-			"service": ServiceName,
-			"version": version,
-			"endpoints": map[string]string{
-				"mcp": "/mcp",
-			},
-		})
-	})
+	// Require tenant API keys, or the shared API key, on MCP requests when configured, and on the
+	// endpoints describing the server and its scanners
+	authMode := info.AuthNone
+	authenticate := func(next http.Handler) http.Handler { return next }
+	switch {
+	case tenantKeys != "" && apiKey != "":
+		logger.Fatal().Msg("--api-key and --tenant-keys are mutually exclusive")
+	case tenantKeys != "":
+		keys, err := tenant.LoadKeys(tenantKeys)
+		if err != nil {
+			logger.Fatal().Msgf("Failed to load tenant keys: %v", err)
+		}
+		authMode = info.AuthBearer
+		authenticate = apikey.Require(keys.Verifier(), logger)
+		logger.Info().Msgf("Loaded %d tenant API keys from %s", len(keys), tenantKeys)
+	case apiKey != "":
+		authMode = info.AuthBearer
+		authenticate = apikey.Require(apikey.Verifier(apiKey), logger)
+		logger.Info().Msg("MCP requests require the API key")
+	default:
+		logger.Warn().Msg("MCP endpoint is unauthenticated: anyone reaching it can launch scans; set --api-key or --tenant-keys")
+	}
+
+	http.Handle(MCPEndpoint, server.WithRemoteAddr(authenticate(handler)))
+
+	// Serve over HTTPS with the given certificate or a generated self-signed one
+	scheme := "http"
+	switch {
+	case tlsSelfSigned && (tlsCert != "" || tlsKey != ""):
+		logger.Fatal().Msg("--tls-self-signed and --tls-cert/--tls-key are mutually exclusive")
+	case tlsSelfSigned:
+		certFile, keyFile, generated, err := tlscert.SelfSigned(layout.TLS(), tlscert.Hosts(bindAddr))
+		if err != nil {
+			logger.Fatal().Msgf("Failed to set up the self-signed TLS certificate: %v", err)
+		}
+		if generated {
+			logger.Info().Msgf("Generated a self-signed TLS certificate in %s", layout.TLS())
+		}
+		tlsCert, tlsKey = certFile, keyFile
+	}
+	var tlsConfig *tls.Config
+	if tlsCert != "" || tlsKey != "" {
+		tlsConfig, err = tlscert.Config(tlsCert, tlsKey)
+		if err != nil {
+			logger.Fatal().Msgf("Failed to configure TLS: %v", err)
+		}
+		fingerprint, err := tlscert.Fingerprint(tlsCert)
+		if err != nil {
+			logger.Fatal().Msgf("Failed to configure TLS: %v", err)
+		}
+		scheme = "https"
+		logger.Info().Msgf("Serving HTTPS with the certificate %s (SHA-256 fingerprint %s)", tlsCert, fingerprint)
+	}
+
+	// Scanner failure metrics for Prometheus
+	http.Handle(MetricsEndpoint, authenticate(srv.Metrics()))
+	endpoints := map[string]string{"metrics": MetricsEndpoint, "tools_versions": ToolsVersionsEndpoint}
+
+	// Runtime control endpoints, only served when an admin token is configured
+	if adminToken != "" {
+		endpoints["admin"] = admin.Prefix
+		http.Handle(admin.Prefix, admin.New(srv, admin.Config{Retention: retention, Token: adminToken}, logger, registry))
+		logger.Info().Msgf("Admin endpoints available at: %s://%s%s", scheme, bindAddr, admin.Prefix)
+	}
+
+	// Serve the capability document for orchestrators introspecting the server
+	provider := info.New(srv, info.Config{
+		Auth:      authMode,
+		Endpoints: endpoints,
+		Name:      ServerName,
+		Service:   ServiceName,
+		Transport: info.Transport{
+			Endpoint:  MCPEndpoint,
+			Stateless: true,
+			Type:      info.TransportStreamableHTTP,
+		},
+		Version: version,
+	}, registry)
+	http.Handle("/", authenticate(provider))
+	// Report the scanner binary versions, failing when embedded tools drift from their manifest
+	http.Handle(ToolsVersionsEndpoint, authenticate(provider.ToolVersionsHandler()))
 
 	logger.Info().Msgf("%s starting on address %s", ServiceName, bindAddr)
-	logger.Info().Msgf("MCP endpoint available at: http://%s/mcp", bindAddr)
+	logger.Info().Msgf("MCP endpoint available at: %s://%s%s", scheme, bindAddr, MCPEndpoint)
 
 	go func() {
 		//nolint:gosec
-		if err := http.ListenAndServe(bindAddr, nil); !errors.Is(err, http.ErrServerClosed) {
+		httpServer := &http.Server{Addr: bindAddr, Handler: requirePrefix(ProfilingPrefix, authenticate, http.DefaultServeMux), TLSConfig: tlsConfig}
+		listen := httpServer.ListenAndServe
+		if tlsConfig != nil {
+			// The certificate is in the TLS configuration.
+			listen = func() error { return httpServer.ListenAndServeTLS("", "") }
+		}
+		if err := listen(); !errors.Is(err, http.ErrServerClosed) {
 			logger.Fatal().Msgf("%s failed to start: %v", ServerName, err)
 		}
 	}()
@@ -150,3 +567,58 @@ func main() {
 		logger.Info().Msgf("%s shutdown complete", ServiceName)
 	}
 }
+
+// runToolCheck prints the tool versions report and returns the exit code: non-zero when a tool is
+// unhealthy. With record, the versions of the bundled tools are recorded in the manifest first.
+func runToolCheck(record bool) int {
+	ctx, cancel := context.WithTimeout(context.Background(), ToolCheckTimeout)
+	defer cancel()
+
+	report := info.ToolVersions(ctx, newScanners(zerolog.Nop(), scannerConfig{})...)
+	if record {
+		toolBundle := bundle.Active()
+		if toolBundle == nil {
+			fmt.Fprintln(os.Stderr, "--record-tools requires --embedded-tools")
+			return 1
+		}
+		if err := toolBundle.Record(report.Tools); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to record embedded tools: %v\n", err)
+			return 1
+		}
+		report = info.ToolVersions(ctx, newScanners(zerolog.Nop(), scannerConfig{})...)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	_ = encoder.Encode(report)
+	if !report.Healthy {
+		return 1
+	}
+
+	return 0
+}
+
+// requirePrefix returns mux with the requests of paths under prefix passed through authenticate,
+// for handlers registered on mux elsewhere, such as the profiling endpoints.
+func requirePrefix(prefix string, authenticate func(http.Handler) http.Handler, mux http.Handler) http.Handler {
+	protected := authenticate(mux)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, prefix) {
+			protected.ServeHTTP(w, r)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// splitList splits a comma-separated flag value, dropping empty entries.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,18 @@
+//go:build !no_scanners && !no_droopescan
+
+package main
+
+import (
+	"github.com/rs/zerolog"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+	"github.com/tb0hdan/wass-mcp/pkg/tools/droopescan"
+)
+
+func init() {
+	compileScanner(compiledScanner{
+		order: 10,
+		create: func(logger zerolog.Logger, _ scannerConfig) tools.Scanner {
+			return droopescan.New(logger)
+		},
+	})
+}
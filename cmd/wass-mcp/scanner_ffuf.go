@@ -0,0 +1,18 @@
+//go:build !no_scanners && !no_ffuf
+
+package main
+
+import (
+	"github.com/rs/zerolog"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+	"github.com/tb0hdan/wass-mcp/pkg/tools/ffuf"
+)
+
+func init() {
+	compileScanner(compiledScanner{
+		order: 7,
+		create: func(logger zerolog.Logger, _ scannerConfig) tools.Scanner {
+			return ffuf.New(logger)
+		},
+	})
+}
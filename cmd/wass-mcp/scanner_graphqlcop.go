@@ -0,0 +1,18 @@
+//go:build !no_scanners && !no_graphqlcop
+
+package main
+
+import (
+	"github.com/rs/zerolog"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+	"github.com/tb0hdan/wass-mcp/pkg/tools/graphqlcop"
+)
+
+func init() {
+	compileScanner(compiledScanner{
+		order: 11,
+		create: func(logger zerolog.Logger, _ scannerConfig) tools.Scanner {
+			return graphqlcop.New(logger)
+		},
+	})
+}
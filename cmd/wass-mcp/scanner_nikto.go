@@ -0,0 +1,18 @@
+//go:build !no_scanners && !no_nikto
+
+package main
+
+import (
+	"github.com/rs/zerolog"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+	"github.com/tb0hdan/wass-mcp/pkg/tools/nikto"
+)
+
+func init() {
+	compileScanner(compiledScanner{
+		order: 0,
+		create: func(logger zerolog.Logger, _ scannerConfig) tools.Scanner {
+			return nikto.New(logger)
+		},
+	})
+}
@@ -0,0 +1,18 @@
+//go:build !no_scanners && !no_nmap
+
+package main
+
+import (
+	"github.com/rs/zerolog"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+	"github.com/tb0hdan/wass-mcp/pkg/tools/nmap"
+)
+
+func init() {
+	compileScanner(compiledScanner{
+		order: 6,
+		create: func(logger zerolog.Logger, _ scannerConfig) tools.Scanner {
+			return nmap.New(logger)
+		},
+	})
+}
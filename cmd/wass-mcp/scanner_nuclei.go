@@ -0,0 +1,40 @@
+//go:build !no_scanners && !no_nuclei
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/rs/zerolog"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+	"github.com/tb0hdan/wass-mcp/pkg/tools/nuclei"
+)
+
+func init() {
+	compileScanner(compiledScanner{
+		order: 2,
+		configure: func(logger zerolog.Logger, cfg scannerConfig) error {
+			interactsh := nucleiInteractsh(cfg)
+			if err := interactsh.Validate(); err != nil {
+				return fmt.Errorf("failed to configure nuclei out-of-band testing: %w", err)
+			}
+			switch {
+			case interactsh.Disabled:
+				logger.Info().Msg("Nuclei out-of-band testing disabled")
+			case interactsh.Server != "":
+				logger.Info().Msgf("Nuclei out-of-band interactions reported to %s", interactsh.Server)
+			}
+			return nil
+		},
+		create: func(logger zerolog.Logger, cfg scannerConfig) tools.Scanner {
+			scanner := nuclei.New(logger)
+			scanner.(*nuclei.Tool).SetInteractsh(nucleiInteractsh(cfg))
+			return scanner
+		},
+	})
+}
+
+// nucleiInteractsh returns the out-of-band testing configuration of nuclei.
+func nucleiInteractsh(cfg scannerConfig) nuclei.Interactsh {
+	return nuclei.Interactsh{Disabled: cfg.interactshDisabled, Server: cfg.interactshServer, Token: cfg.interactshToken}
+}
@@ -0,0 +1,18 @@
+//go:build !no_scanners && !no_shcheck
+
+package main
+
+import (
+	"github.com/rs/zerolog"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+	"github.com/tb0hdan/wass-mcp/pkg/tools/shcheck"
+)
+
+func init() {
+	compileScanner(compiledScanner{
+		order: 3,
+		create: func(logger zerolog.Logger, _ scannerConfig) tools.Scanner {
+			return shcheck.New(logger)
+		},
+	})
+}
@@ -0,0 +1,18 @@
+//go:build !no_scanners && !no_sqlmap
+
+package main
+
+import (
+	"github.com/rs/zerolog"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+	"github.com/tb0hdan/wass-mcp/pkg/tools/sqlmap"
+)
+
+func init() {
+	compileScanner(compiledScanner{
+		order: 4,
+		create: func(logger zerolog.Logger, _ scannerConfig) tools.Scanner {
+			return sqlmap.New(logger)
+		},
+	})
+}
@@ -0,0 +1,18 @@
+//go:build !no_scanners && !no_wapiti
+
+package main
+
+import (
+	"github.com/rs/zerolog"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+	"github.com/tb0hdan/wass-mcp/pkg/tools/wapiti"
+)
+
+func init() {
+	compileScanner(compiledScanner{
+		order: 1,
+		create: func(logger zerolog.Logger, _ scannerConfig) tools.Scanner {
+			return wapiti.New(logger)
+		},
+	})
+}
@@ -0,0 +1,18 @@
+//go:build !no_scanners && !no_whatweb
+
+package main
+
+import (
+	"github.com/rs/zerolog"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+	"github.com/tb0hdan/wass-mcp/pkg/tools/whatweb"
+)
+
+func init() {
+	compileScanner(compiledScanner{
+		order: 8,
+		create: func(logger zerolog.Logger, _ scannerConfig) tools.Scanner {
+			return whatweb.New(logger)
+		},
+	})
+}
@@ -0,0 +1,20 @@
+//go:build !no_scanners && !no_wpscan
+
+package main
+
+import (
+	"github.com/rs/zerolog"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+	"github.com/tb0hdan/wass-mcp/pkg/tools/wpscan"
+)
+
+func init() {
+	compileScanner(compiledScanner{
+		order: 9,
+		create: func(logger zerolog.Logger, cfg scannerConfig) tools.Scanner {
+			scanner := wpscan.New(logger)
+			scanner.(*wpscan.Tool).SetAPIToken(cfg.wpscanToken)
+			return scanner
+		},
+	})
+}
@@ -0,0 +1,32 @@
+//go:build !no_scanners && !no_zap
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/rs/zerolog"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+	"github.com/tb0hdan/wass-mcp/pkg/tools/zap"
+)
+
+func init() {
+	compileScanner(compiledScanner{
+		order: 5,
+		configure: func(logger zerolog.Logger, cfg scannerConfig) error {
+			daemon := zap.Daemon{APIKey: cfg.zapAPIKey, URL: cfg.zapAPIURL}
+			if err := daemon.Validate(); err != nil {
+				return fmt.Errorf("failed to configure the ZAP daemon: %w", err)
+			}
+			if daemon.Enabled() {
+				logger.Info().Msgf("ZAP scans run through the daemon at %s", daemon.URL)
+			}
+			return nil
+		},
+		create: func(logger zerolog.Logger, cfg scannerConfig) tools.Scanner {
+			scanner := zap.New(logger)
+			scanner.(*zap.Tool).SetDaemon(zap.Daemon{APIKey: cfg.zapAPIKey, URL: cfg.zapAPIURL})
+			return scanner
+		},
+	})
+}
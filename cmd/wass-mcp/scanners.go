@@ -0,0 +1,68 @@
+package main
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/rs/zerolog"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+)
+
+// scannerConfig holds the scanner flags. Flags of scanners left out of the build are accepted
+// and ignored.
+type scannerConfig struct {
+	// interactshDisabled, interactshServer and interactshToken configure nuclei out-of-band testing.
+	interactshDisabled bool
+	interactshServer   string
+	interactshToken    string
+	// wpscanToken is the WPScan API token of wpscan scans passing none.
+	wpscanToken string
+	// zapAPIKey and zapAPIURL name the ZAP daemon zap scans run through.
+	zapAPIKey string
+	zapAPIURL string
+}
+
+// compiledScanner is a scanner compiled into the binary. Each scanner adds itself from its own
+// file, guarded by the no_<scanner> build tag and the no_scanners tag leaving every scanner out,
+// so that minimal binaries do not link the scanners they do not ship.
+type compiledScanner struct {
+	// order is the position of the scanner in the tool list and in full_scan runs.
+	order int
+	// configure validates the flags of the scanner at startup and logs its setup, nil without flags.
+	configure func(logger zerolog.Logger, cfg scannerConfig) error
+	// create returns the scanner instance.
+	create func(logger zerolog.Logger, cfg scannerConfig) tools.Scanner
+}
+
+// compiledScanners are the scanners compiled into the binary, added by init functions.
+var compiledScanners []compiledScanner
+
+// compileScanner adds scanner to the scanners of the binary.
+func compileScanner(scanner compiledScanner) {
+	compiledScanners = append(compiledScanners, scanner)
+	slices.SortStableFunc(compiledScanners, func(a, b compiledScanner) int { return cmp.Compare(a.order, b.order) })
+}
+
+// configureScanners validates the flags of the compiled scanners.
+func configureScanners(logger zerolog.Logger, cfg scannerConfig) error {
+	for _, scanner := range compiledScanners {
+		if scanner.configure == nil {
+			continue
+		}
+		if err := scanner.configure(logger, cfg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// newScanners creates the instances of the compiled scanners, configured by cfg.
+func newScanners(logger zerolog.Logger, cfg scannerConfig) []tools.Scanner {
+	scanners := make([]tools.Scanner, 0, len(compiledScanners))
+	for _, scanner := range compiledScanners {
+		scanners = append(scanners, scanner.create(logger, cfg))
+	}
+
+	return scanners
+}
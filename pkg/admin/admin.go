@@ -0,0 +1,543 @@
+// Package admin serves the authenticated HTTP endpoints used to control a running server:
+// listing and cancelling running jobs, toggling scanners, changing the log level, exporting and
+// pruning executions, managing the stored credentials scans authenticate with, rotating the master key
+// encrypting them and uploading the wordlists content discovery scans reference by name.
+package admin
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/running"
+	"github.com/tb0hdan/wass-mcp/pkg/server"
+	"github.com/tb0hdan/wass-mcp/pkg/storage"
+	"github.com/tb0hdan/wass-mcp/pkg/tenant"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+	"github.com/tb0hdan/wass-mcp/pkg/vault"
+	"github.com/tb0hdan/wass-mcp/pkg/wordlist"
+	"gorm.io/gorm"
+)
+
+// Prefix is the path prefix of the admin endpoints.
+const Prefix = "/admin/"
+
+// maxCredentialName bounds the length of credential names, as for other named records.
+const maxCredentialName = 64
+
+// exportFlushRows is the number of exported executions written between flushes of the response.
+const exportFlushRows = 100
+
+var (
+	// errNoRetention is returned when pruning without an age and without a configured retention.
+	errNoRetention = errors.New("older_than is required when no retention is configured")
+	// errUnauthorized is returned for requests without the admin token.
+	errUnauthorized = errors.New("unauthorized")
+)
+
+// Config configures the admin endpoints.
+type Config struct {
+	// Retention is the default age after which executions are pruned, zero when unset.
+	Retention time.Duration
+	// Token is the bearer token required on every admin request. Without a token every
+	// request is rejected.
+	Token string
+}
+
+// ScannerState describes a scanner and whether it is enabled.
+type ScannerState struct {
+	Available bool   `json:"available"`
+	Enabled   bool   `json:"enabled"`
+	Name      string `json:"name"`
+}
+
+// PruneRequest is the optional body of a prune request.
+type PruneRequest struct {
+	// OlderThan is a Go duration such as "720h"; the configured retention is used when empty.
+	OlderThan string `json:"older_than,omitempty"`
+}
+
+// PruneResult reports a pruning run.
+type PruneResult struct {
+	Before time.Time `json:"before"`
+	Pruned int64     `json:"pruned"`
+}
+
+// LogLevel is the body of log level requests and responses.
+type LogLevel struct {
+	Level string `json:"level"`
+}
+
+// CredentialRequest is the body of a request storing a credential.
+type CredentialRequest struct {
+	Description string       `json:"description,omitempty"`
+	Secret      vault.Secret `json:"secret"`
+	Type        string       `json:"type"`
+}
+
+// Handler serves the admin endpoints.
+type Handler struct {
+	config   Config
+	logger   zerolog.Logger
+	mux      *http.ServeMux
+	registry *tools.Registry
+	srv      *server.Server
+}
+
+// New creates the admin handler for srv and the scanners of registry, which can be toggled.
+func New(srv *server.Server, cfg Config, logger zerolog.Logger, registry *tools.Registry) *Handler {
+	handler := &Handler{
+		config:   cfg,
+		logger:   logger.With().Str("component", "admin").Logger(),
+		mux:      http.NewServeMux(),
+		registry: registry,
+		srv:      srv,
+	}
+
+	handler.mux.HandleFunc("GET "+Prefix+"jobs", handler.listJobs)
+	handler.mux.HandleFunc("POST "+Prefix+"jobs/{id}/cancel", handler.jobAction("canceled", srv.Jobs().Cancel))
+	handler.mux.HandleFunc("POST "+Prefix+"jobs/{id}/pause", handler.jobAction("paused", srv.Jobs().Pause))
+	handler.mux.HandleFunc("GET "+Prefix+"scanners", handler.listScanners)
+	handler.mux.HandleFunc("POST "+Prefix+"scanners/{name}/enable", handler.toggleScanner(true))
+	handler.mux.HandleFunc("POST "+Prefix+"scanners/{name}/disable", handler.toggleScanner(false))
+	handler.mux.HandleFunc("GET "+Prefix+"log-level", handler.getLogLevel)
+	handler.mux.HandleFunc("PUT "+Prefix+"log-level", handler.setLogLevel)
+	handler.mux.HandleFunc("GET "+Prefix+"executions/export", handler.exportExecutions)
+	handler.mux.HandleFunc("POST "+Prefix+"prune", handler.prune)
+	handler.mux.HandleFunc("GET "+Prefix+"credentials", handler.listCredentials)
+	handler.mux.HandleFunc("PUT "+Prefix+"credentials/{name}", handler.setCredential)
+	handler.mux.HandleFunc("DELETE "+Prefix+"credentials/{name}", handler.deleteCredential)
+	handler.mux.HandleFunc("POST "+Prefix+"rotate-keys", handler.rotateKeys)
+	handler.mux.HandleFunc("GET "+Prefix+"wordlists", handler.listWordlists)
+	handler.mux.HandleFunc("PUT "+Prefix+"wordlists/{name}", handler.setWordlist)
+	handler.mux.HandleFunc("DELETE "+Prefix+"wordlists/{name}", handler.deleteWordlist)
+
+	return handler
+}
+
+// ServeHTTP authenticates the request with the admin bearer token and dispatches it.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !h.authorized(r) {
+		h.logger.Warn().Str("remote", r.RemoteAddr).Msgf("Rejected unauthenticated admin request %s %s", r.Method, r.URL.Path)
+		w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+		writeError(w, http.StatusUnauthorized, errUnauthorized)
+		return
+	}
+
+	h.mux.ServeHTTP(w, r)
+}
+
+// authorized reports whether the request carries the admin token.
+func (h *Handler) authorized(r *http.Request) bool {
+	if h.config.Token == "" {
+		return false
+	}
+
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(token), []byte(h.config.Token)) == 1
+}
+
+func (h *Handler) listJobs(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{"jobs": h.srv.Jobs().List()})
+}
+
+// jobAction returns the handler applying action, such as cancel or pause, to the job in the path.
+// The response reports the job ID under done, the past tense of the action.
+func (h *Handler) jobAction(done string, action func(id uint64) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid job ID: %w", err))
+			return
+		}
+
+		if err := action(id); err != nil {
+			if errors.Is(err, running.ErrNotFound) {
+				writeError(w, http.StatusNotFound, err)
+				return
+			}
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		h.logger.Info().Msgf("Job %d %s", id, done)
+		writeJSON(w, http.StatusAccepted, map[string]any{done: id})
+	}
+}
+
+func (h *Handler) listScanners(w http.ResponseWriter, _ *http.Request) {
+	// Listing checks availability again, picking up binaries installed since startup.
+	h.registry.Refresh()
+	scanners := h.registry.Scanners()
+	states := make([]ScannerState, 0, len(scanners))
+	for _, scanner := range scanners {
+		states = append(states, h.scannerState(scanner))
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"scanners": states})
+}
+
+func (h *Handler) toggleScanner(enabled bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := r.PathValue("name")
+		scanner, err := h.registry.Lookup(name)
+		if err != nil {
+			writeError(w, http.StatusNotFound, err)
+			return
+		}
+
+		h.srv.SetScannerEnabled(name, enabled)
+		h.logger.Info().Bool("enabled", enabled).Msgf("Scanner %s toggled", name)
+		writeJSON(w, http.StatusOK, h.scannerState(scanner))
+	}
+}
+
+// scannerState returns the current state of scanner.
+func (h *Handler) scannerState(scanner tools.Scanner) ScannerState {
+	return ScannerState{
+		Available: h.registry.IsAvailable(scanner.Name()),
+		Enabled:   h.srv.ScannerEnabled(scanner.Name()),
+		Name:      scanner.Name(),
+	}
+}
+
+func (h *Handler) getLogLevel(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, LogLevel{Level: zerolog.GlobalLevel().String()})
+}
+
+func (h *Handler) setLogLevel(w http.ResponseWriter, r *http.Request) {
+	var request LogLevel
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+
+	level, err := zerolog.ParseLevel(strings.ToLower(request.Level))
+	if err != nil || request.Level == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid log level: %q", request.Level))
+		return
+	}
+
+	zerolog.SetGlobalLevel(level)
+	h.logger.Info().Msgf("Log level set to %s", level)
+	writeJSON(w, http.StatusOK, LogLevel{Level: level.String()})
+}
+
+// exportExecutions streams the executions matching the query parameters as NDJSON, one execution
+// per line, oldest first. Rows are written as they are read from storage, so that exporting a
+// large history does not hold it in memory. An error after the first line ends the stream with an
+// {"error": ...} line, the status having been sent.
+func (h *Handler) exportExecutions(w http.ResponseWriter, r *http.Request) {
+	filter, err := exportFilter(r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	ctx, err := credentialContext(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	encoder := json.NewEncoder(w)
+	controller := http.NewResponseController(w)
+	exported := 0
+	err = h.srv.Storage().ExportToolExecutions(ctx, filter, func(exec *models.ToolExecution) error {
+		if err := encoder.Encode(exec); err != nil {
+			return err
+		}
+		exported++
+		if exported%exportFlushRows == 0 {
+			_ = controller.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		h.logger.Error().Err(err).Msgf("Execution export failed after %d executions", exported)
+		_ = encoder.Encode(map[string]string{"error": fmt.Sprintf("export failed: %v", err)})
+		return
+	}
+
+	h.logger.Info().Str("tenant", r.URL.Query().Get("tenant")).Msgf("Exported %d executions", exported)
+}
+
+// exportFilter returns the filter of an export request: the tool, session, correlation_id, host,
+// target, success, since and until (RFC3339) query parameters, and deleted to export soft-deleted
+// executions instead of live ones.
+func exportFilter(query url.Values) (storage.ExecutionFilter, error) {
+	filter := storage.ExecutionFilter{
+		ToolName:      query.Get("tool"),
+		SessionID:     query.Get("session"),
+		CorrelationID: query.Get("correlation_id"),
+		Host:          query.Get("host"),
+		Target:        query.Get("target"),
+	}
+	if value := query.Get("success"); value != "" {
+		success, err := strconv.ParseBool(value)
+		if err != nil {
+			return filter, fmt.Errorf("invalid success: %q", value)
+		}
+		filter.Success = &success
+	}
+	if value := query.Get("deleted"); value != "" {
+		deleted, err := strconv.ParseBool(value)
+		if err != nil {
+			return filter, fmt.Errorf("invalid deleted: %q", value)
+		}
+		filter.Deleted = deleted
+	}
+	for name, bound := range map[string]*time.Time{"since": &filter.Since, "until": &filter.Until} {
+		value := query.Get(name)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return filter, fmt.Errorf("invalid %s, expected an RFC3339 time: %q", name, value)
+		}
+		*bound = parsed
+	}
+
+	return filter, nil
+}
+
+func (h *Handler) prune(w http.ResponseWriter, r *http.Request) {
+	var request PruneRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+			return
+		}
+	}
+
+	age := h.config.Retention
+	if request.OlderThan != "" {
+		parsed, err := time.ParseDuration(request.OlderThan)
+		if err != nil || parsed <= 0 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid older_than: %q", request.OlderThan))
+			return
+		}
+		age = parsed
+	}
+	if age <= 0 {
+		writeError(w, http.StatusBadRequest, errNoRetention)
+		return
+	}
+
+	before := time.Now().Add(-age)
+	pruned, err := h.srv.Storage().PruneToolExecutions(r.Context(), before)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("failed to prune executions: %w", err))
+		return
+	}
+
+	h.logger.Info().Msgf("Pruned %d executions created before %s", pruned, before.Format(time.RFC3339))
+	writeJSON(w, http.StatusOK, PruneResult{Before: before, Pruned: pruned})
+}
+
+// credentialContext returns the context of a credential request, scoped to the tenant query
+// parameter. Without one, the credentials of single-tenant deployments are managed. Tenant names
+// also name wordlist directories, so they must be valid tenant names.
+func credentialContext(r *http.Request) (context.Context, error) {
+	name := r.URL.Query().Get("tenant")
+	if name != "" && !tenant.ValidName(name) {
+		return nil, fmt.Errorf("invalid tenant %q", name)
+	}
+
+	return tenant.WithTenant(r.Context(), name), nil
+}
+
+func (h *Handler) listCredentials(w http.ResponseWriter, r *http.Request) {
+	ctx, err := credentialContext(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	credentials, err := h.srv.Storage().ListCredentials(ctx)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("failed to list credentials: %w", err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"credentials": credentials})
+}
+
+// setCredential encrypts and stores the credential in the path, replacing any of the same name.
+func (h *Handler) setCredential(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	ctx, err := credentialContext(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if len(name) > maxCredentialName {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("credential name exceeds %d characters", maxCredentialName))
+		return
+	}
+
+	var request CredentialRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+	if err := request.Secret.Validate(request.Type); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	sealed, err := h.srv.Vault().Seal(request.Secret)
+	if errors.Is(err, vault.ErrDisabled) {
+		writeError(w, http.StatusConflict, err)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	credential := &models.Credential{
+		Description: request.Description,
+		Name:        name,
+		Secret:      sealed,
+		Type:        request.Type,
+	}
+	if err := h.srv.Storage().SaveCredential(ctx, credential); err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("failed to save credential %s: %w", name, err))
+		return
+	}
+
+	h.logger.Info().Str("tenant", credential.Tenant).Msgf("Credential %s stored", name)
+	writeJSON(w, http.StatusOK, credential)
+}
+
+func (h *Handler) deleteCredential(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	ctx, err := credentialContext(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	err = h.srv.Storage().DeleteCredential(ctx, name)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		writeError(w, http.StatusNotFound, fmt.Errorf("credential %s not found", name))
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("failed to delete credential %s: %w", name, err))
+		return
+	}
+
+	h.logger.Info().Msgf("Credential %s deleted", name)
+	writeJSON(w, http.StatusOK, map[string]string{"deleted": name})
+}
+
+// rotateKeys rewraps the stored secrets of every tenant sealed with an older master key with the
+// primary key, after which the older keys can be removed from the key file.
+func (h *Handler) rotateKeys(w http.ResponseWriter, r *http.Request) {
+	rewrapped, err := h.srv.Vault().Rotate(r.Context(), h.srv.Storage())
+	if errors.Is(err, vault.ErrDisabled) {
+		writeError(w, http.StatusConflict, err)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("failed to rotate keys: %w", err))
+		return
+	}
+
+	h.logger.Info().Msgf("Rewrapped %d credentials with the primary master key", rewrapped)
+	writeJSON(w, http.StatusOK, map[string]int{"rewrapped": rewrapped})
+}
+
+func (h *Handler) listWordlists(w http.ResponseWriter, r *http.Request) {
+	ctx, err := credentialContext(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	wordlists, err := h.srv.Wordlists().List(ctx)
+	if err != nil {
+		writeError(w, wordlistStatus(err), err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"wordlists": wordlists})
+}
+
+// setWordlist stores the request body, one entry per line, as the wordlist in the path, replacing
+// any of the same name. Without a tenant query parameter the wordlist is shared by every tenant.
+func (h *Handler) setWordlist(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	ctx, err := credentialContext(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	info, err := h.srv.Wordlists().Save(ctx, name, r.Body)
+	if err != nil {
+		writeError(w, wordlistStatus(err), err)
+		return
+	}
+
+	h.logger.Info().Str("tenant", r.URL.Query().Get("tenant")).Msgf("Wordlist %s stored with %d entries", name, info.Entries)
+	writeJSON(w, http.StatusOK, info)
+}
+
+func (h *Handler) deleteWordlist(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	ctx, err := credentialContext(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := h.srv.Wordlists().Delete(ctx, name); err != nil {
+		writeError(w, wordlistStatus(err), err)
+		return
+	}
+
+	h.logger.Info().Msgf("Wordlist %s deleted", name)
+	writeJSON(w, http.StatusOK, map[string]string{"deleted": name})
+}
+
+// wordlistStatus returns the response status of a wordlist registry error.
+func wordlistStatus(err error) int {
+	switch {
+	case errors.Is(err, wordlist.ErrDisabled):
+		return http.StatusConflict
+	case errors.Is(err, wordlist.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, wordlist.ErrTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, wordlist.ErrInvalidName), errors.Is(err, wordlist.ErrInvalidTenant),
+		errors.Is(err, wordlist.ErrInvalidContent):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// writeJSON writes value as a JSON response with the given status.
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+// writeError writes err as a JSON error response with the given status.
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,372 @@
+package admin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/crypto"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/running"
+	"github.com/tb0hdan/wass-mcp/pkg/server"
+	"github.com/tb0hdan/wass-mcp/pkg/storage"
+	"github.com/tb0hdan/wass-mcp/pkg/tenant"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+	"github.com/tb0hdan/wass-mcp/pkg/vault"
+	"github.com/tb0hdan/wass-mcp/pkg/wordlist"
+)
+
+const testToken = "s3cret"
+
+// fakeScanner is a scanner that is always available.
+type fakeScanner struct {
+	name string
+}
+
+func (f *fakeScanner) Register(*server.Server) error { return nil }
+
+func (f *fakeScanner) Name() string { return f.name }
+
+func (f *fakeScanner) IsAvailable() bool { return true }
+
+func (f *fakeScanner) Scan(context.Context, tools.ScanParams) tools.ScanResult {
+	return tools.ScanResult{}
+}
+
+type AdminTestSuite struct {
+	suite.Suite
+	handler *Handler
+	srv     *server.Server
+	store   storage.Storage
+}
+
+func (s *AdminTestSuite) SetupTest() {
+	store, err := storage.NewSQLiteStorage(storage.Config{DatabasePath: filepath.Join(s.T().TempDir(), "admin.db")})
+	s.Require().NoError(err)
+	s.store = store
+
+	s.srv = server.NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}, store)
+	s.handler = New(s.srv, Config{Retention: 24 * time.Hour, Token: testToken}, zerolog.Nop(),
+		tools.NewRegistry(&fakeScanner{name: "nikto"}, &fakeScanner{name: "nuclei"}))
+}
+
+func (s *AdminTestSuite) TearDownTest() {
+	s.Require().NoError(s.store.Close())
+}
+
+// do sends an authenticated admin request and returns the response.
+func (s *AdminTestSuite) do(method, path, body string) *httptest.ResponseRecorder {
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req := httptest.NewRequestWithContext(context.Background(), method, path, reader)
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	rec := httptest.NewRecorder()
+	s.handler.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func (s *AdminTestSuite) TestAuthentication() {
+	for _, header := range []string{"", "Bearer wrong", "Basic " + testToken, testToken} {
+		req := httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/admin/jobs", nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		rec := httptest.NewRecorder()
+		s.handler.ServeHTTP(rec, req)
+		s.Equal(http.StatusUnauthorized, rec.Code, header)
+		s.Equal(`Bearer realm="admin"`, rec.Header().Get("WWW-Authenticate"))
+	}
+
+	s.Equal(http.StatusOK, s.do(http.MethodGet, "/admin/jobs", "").Code)
+}
+
+func (s *AdminTestSuite) TestAuthentication_NoToken() {
+	handler := New(s.srv, Config{}, zerolog.Nop(), tools.NewRegistry())
+	req := httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/admin/jobs", nil)
+	req.Header.Set("Authorization", "Bearer ")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	s.Equal(http.StatusUnauthorized, rec.Code)
+}
+
+func (s *AdminTestSuite) TestJobs_ListAndCancel() {
+	ctx, done := s.srv.Jobs().Start(context.Background(), running.Job{ToolName: "nikto", Target: "http://example.com"})
+	defer done()
+
+	rec := s.do(http.MethodGet, "/admin/jobs", "")
+	s.Equal(http.StatusOK, rec.Code)
+	var listed struct {
+		Jobs []running.Job `json:"jobs"`
+	}
+	s.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &listed))
+	s.Require().Len(listed.Jobs, 1)
+	s.Equal("nikto", listed.Jobs[0].ToolName)
+
+	rec = s.do(http.MethodPost, "/admin/jobs/1/cancel", "")
+	s.Equal(http.StatusAccepted, rec.Code)
+	<-ctx.Done()
+	s.True(running.Canceled(ctx))
+
+	s.Equal(http.StatusNotFound, s.do(http.MethodPost, "/admin/jobs/42/cancel", "").Code)
+	s.Equal(http.StatusBadRequest, s.do(http.MethodPost, "/admin/jobs/abc/cancel", "").Code)
+}
+
+func (s *AdminTestSuite) TestJobs_Pause() {
+	ctx, done := s.srv.Jobs().Start(context.Background(), running.Job{ToolName: "full_scan"})
+	defer done()
+
+	rec := s.do(http.MethodPost, "/admin/jobs/1/pause", "")
+	s.Equal(http.StatusAccepted, rec.Code)
+	s.JSONEq(`{"paused": 1}`, rec.Body.String())
+	s.True(running.Paused(ctx))
+	s.NoError(ctx.Err())
+
+	s.Equal(http.StatusNotFound, s.do(http.MethodPost, "/admin/jobs/42/pause", "").Code)
+	s.Equal(http.StatusBadRequest, s.do(http.MethodPost, "/admin/jobs/abc/pause", "").Code)
+}
+
+func (s *AdminTestSuite) TestScanners_Toggle() {
+	rec := s.do(http.MethodPost, "/admin/scanners/nikto/disable", "")
+	s.Equal(http.StatusOK, rec.Code)
+	var state ScannerState
+	s.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &state))
+	s.Equal(ScannerState{Available: true, Enabled: false, Name: "nikto"}, state)
+	s.False(s.srv.ScannerEnabled("nikto"))
+
+	rec = s.do(http.MethodGet, "/admin/scanners", "")
+	var listed struct {
+		Scanners []ScannerState `json:"scanners"`
+	}
+	s.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &listed))
+	s.Equal([]ScannerState{
+		{Available: true, Enabled: false, Name: "nikto"},
+		{Available: true, Enabled: true, Name: "nuclei"},
+	}, listed.Scanners)
+
+	s.Equal(http.StatusOK, s.do(http.MethodPost, "/admin/scanners/nikto/enable", "").Code)
+	s.True(s.srv.ScannerEnabled("nikto"))
+
+	s.Equal(http.StatusNotFound, s.do(http.MethodPost, "/admin/scanners/zap/disable", "").Code)
+}
+
+func (s *AdminTestSuite) TestLogLevel() {
+	previous := zerolog.GlobalLevel()
+	defer zerolog.SetGlobalLevel(previous)
+
+	rec := s.do(http.MethodPut, "/admin/log-level", `{"level":"WARN"}`)
+	s.Equal(http.StatusOK, rec.Code)
+	s.Equal(zerolog.WarnLevel, zerolog.GlobalLevel())
+
+	rec = s.do(http.MethodGet, "/admin/log-level", "")
+	s.JSONEq(`{"level":"warn"}`, rec.Body.String())
+
+	s.Equal(http.StatusBadRequest, s.do(http.MethodPut, "/admin/log-level", `{"level":"loud"}`).Code)
+	s.Equal(http.StatusBadRequest, s.do(http.MethodPut, "/admin/log-level", `{}`).Code)
+	s.Equal(zerolog.WarnLevel, zerolog.GlobalLevel())
+}
+
+func (s *AdminTestSuite) TestPrune() {
+	ctx := context.Background()
+	old := &models.ToolExecution{ToolName: "nikto", CreatedAt: time.Now().Add(-48 * time.Hour)}
+	recent := &models.ToolExecution{ToolName: "nikto", CreatedAt: time.Now().Add(-2 * time.Hour)}
+	s.Require().NoError(s.store.CreateToolExecution(ctx, old))
+	s.Require().NoError(s.store.CreateToolExecution(ctx, recent))
+
+	// The configured retention of 24h applies without a body.
+	rec := s.do(http.MethodPost, "/admin/prune", "")
+	s.Equal(http.StatusOK, rec.Code)
+	var result PruneResult
+	s.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &result))
+	s.Equal(int64(1), result.Pruned)
+
+	rec = s.do(http.MethodPost, "/admin/prune", `{"older_than":"1h"}`)
+	s.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &result))
+	s.Equal(int64(1), result.Pruned)
+
+	s.Equal(http.StatusBadRequest, s.do(http.MethodPost, "/admin/prune", `{"older_than":"soon"}`).Code)
+	s.Equal(http.StatusBadRequest, s.do(http.MethodPost, "/admin/prune", `{"older_than":"-1h"}`).Code)
+}
+
+func (s *AdminTestSuite) TestExportExecutions() {
+	ctx := context.Background()
+	for _, exec := range []*models.ToolExecution{
+		{ToolName: "nikto", Host: "example.com", Success: true, CreatedAt: time.Now().Add(-48 * time.Hour)},
+		{ToolName: "wapiti", Host: "example.com", Success: false},
+		{ToolName: "nikto", Host: "other.org", Success: true},
+	} {
+		s.Require().NoError(s.store.CreateToolExecution(ctx, exec))
+	}
+
+	// lines decodes the executions of an NDJSON export.
+	lines := func(rec *httptest.ResponseRecorder) []models.ToolExecution {
+		s.Equal(http.StatusOK, rec.Code)
+		s.Equal("application/x-ndjson", rec.Header().Get("Content-Type"))
+		var executions []models.ToolExecution
+		for _, line := range strings.Split(strings.TrimSpace(rec.Body.String()), "\n") {
+			var exec models.ToolExecution
+			s.Require().NoError(json.Unmarshal([]byte(line), &exec), line)
+			executions = append(executions, exec)
+		}
+		return executions
+	}
+
+	all := lines(s.do(http.MethodGet, "/admin/executions/export", ""))
+	s.Require().Len(all, 3)
+	s.Equal([]uint{1, 2, 3}, []uint{all[0].ID, all[1].ID, all[2].ID}, "oldest first")
+
+	filtered := lines(s.do(http.MethodGet, "/admin/executions/export?tool=nikto&success=true&since="+
+		url.QueryEscape(time.Now().Add(-time.Hour).Format(time.RFC3339)), ""))
+	s.Require().Len(filtered, 1)
+	s.Equal("other.org", filtered[0].Host)
+
+	s.Require().NoError(s.store.DeleteToolExecution(ctx, 2))
+	deleted := lines(s.do(http.MethodGet, "/admin/executions/export?deleted=true", ""))
+	s.Require().Len(deleted, 1)
+	s.Equal(uint(2), deleted[0].ID)
+
+	s.Empty(s.do(http.MethodGet, "/admin/executions/export?tenant=acme", "").Body.String(), "exports are scoped to the tenant")
+	s.Equal(http.StatusBadRequest, s.do(http.MethodGet, "/admin/executions/export?since=yesterday", "").Code)
+	s.Equal(http.StatusBadRequest, s.do(http.MethodGet, "/admin/executions/export?success=maybe", "").Code)
+}
+
+func (s *AdminTestSuite) TestPrune_NoRetention() {
+	handler := New(s.srv, Config{Token: testToken}, zerolog.Nop(), tools.NewRegistry())
+	req := httptest.NewRequestWithContext(context.Background(), http.MethodPost, "/admin/prune", nil)
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	s.Equal(http.StatusBadRequest, rec.Code)
+	s.Contains(rec.Body.String(), "older_than is required")
+}
+
+func (s *AdminTestSuite) TestCredentials() {
+	body := `{"type":"basic","description":"Staging admin","secret":{"username":"admin","password":"hunter2"}}`
+	rec := s.do(http.MethodPut, "/admin/credentials/staging-admin?tenant=alpha", body)
+	s.Equal(http.StatusConflict, rec.Code, "storing credentials requires an encryption key")
+
+	keyring, err := crypto.NewKeyring(make([]byte, crypto.KeySize))
+	s.Require().NoError(err)
+	credentialVault := vault.New(keyring)
+	s.srv.SetVault(credentialVault)
+
+	rec = s.do(http.MethodPut, "/admin/credentials/staging-admin?tenant=alpha", body)
+	s.Require().Equal(http.StatusOK, rec.Code, rec.Body.String())
+	s.NotContains(rec.Body.String(), "hunter2")
+
+	alpha := tenant.WithTenant(context.Background(), "alpha")
+	credential, err := credentialVault.Resolve(alpha, s.store, "staging-admin")
+	s.Require().NoError(err)
+	s.Equal("hunter2", credential.Password)
+	_, err = credentialVault.Resolve(tenant.WithTenant(context.Background(), "beta"), s.store, "staging-admin")
+	s.Error(err)
+
+	rec = s.do(http.MethodGet, "/admin/credentials?tenant=alpha", "")
+	s.Equal(http.StatusOK, rec.Code)
+	var list struct {
+		Credentials []models.Credential `json:"credentials"`
+	}
+	s.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &list))
+	s.Require().Len(list.Credentials, 1)
+	s.Equal("staging-admin", list.Credentials[0].Name)
+	s.Equal(vault.TypeBasic, list.Credentials[0].Type)
+
+	s.Equal(http.StatusBadRequest, s.do(http.MethodPut, "/admin/credentials/api", `{"type":"bearer","secret":{}}`).Code)
+	s.Equal(http.StatusBadRequest, s.do(http.MethodPut, "/admin/credentials/api", `{"type":"ntlm","secret":{}}`).Code)
+	s.Equal(http.StatusBadRequest, s.do(http.MethodPut, "/admin/credentials/api", `not json`).Code)
+
+	s.Equal(http.StatusNotFound, s.do(http.MethodDelete, "/admin/credentials/staging-admin?tenant=beta", "").Code)
+	s.Equal(http.StatusOK, s.do(http.MethodDelete, "/admin/credentials/staging-admin?tenant=alpha", "").Code)
+	s.Equal(http.StatusNotFound, s.do(http.MethodDelete, "/admin/credentials/staging-admin?tenant=alpha", "").Code)
+}
+
+func (s *AdminTestSuite) TestRotateKeys() {
+	s.Equal(http.StatusConflict, s.do(http.MethodPost, "/admin/rotate-keys", "").Code)
+
+	oldKey := bytes.Repeat([]byte{1}, crypto.KeySize)
+	keyring, err := crypto.NewKeyring(oldKey)
+	s.Require().NoError(err)
+	s.srv.SetVault(vault.New(keyring))
+	body := `{"type":"bearer","secret":{"token":"abc"}}`
+	s.Require().Equal(http.StatusOK, s.do(http.MethodPut, "/admin/credentials/api?tenant=alpha", body).Code)
+	s.Require().Equal(http.StatusOK, s.do(http.MethodPut, "/admin/credentials/api?tenant=beta", body).Code)
+
+	keyring, err = crypto.NewKeyring(bytes.Repeat([]byte{2}, crypto.KeySize), oldKey)
+	s.Require().NoError(err)
+	s.srv.SetVault(vault.New(keyring))
+	rec := s.do(http.MethodPost, "/admin/rotate-keys", "")
+	s.Require().Equal(http.StatusOK, rec.Code, rec.Body.String())
+	s.JSONEq(`{"rewrapped":2}`, rec.Body.String())
+
+	rec = s.do(http.MethodPost, "/admin/rotate-keys", "")
+	s.JSONEq(`{"rewrapped":0}`, rec.Body.String())
+}
+
+func (s *AdminTestSuite) TestWordlists() {
+	s.Equal(http.StatusConflict, s.do(http.MethodPut, "/admin/wordlists/common", "admin\n").Code,
+		"uploading wordlists requires a wordlist directory")
+
+	registry := wordlist.New(s.T().TempDir(), 16)
+	s.srv.SetWordlists(registry)
+
+	rec := s.do(http.MethodPut, "/admin/wordlists/common", "admin\nlogin\n")
+	s.Require().Equal(http.StatusOK, rec.Code, rec.Body.String())
+	var info wordlist.Info
+	s.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &info))
+	s.Equal(2, info.Entries)
+	s.True(info.Shared)
+	s.Require().Equal(http.StatusOK, s.do(http.MethodPut, "/admin/wordlists/internal?tenant=alpha", "api\n").Code)
+
+	_, err := registry.Resolve(tenant.WithTenant(context.Background(), "beta"), "common")
+	s.NoError(err, "wordlists uploaded without a tenant are shared")
+	_, err = registry.Resolve(tenant.WithTenant(context.Background(), "beta"), "internal")
+	s.ErrorIs(err, wordlist.ErrNotFound)
+
+	rec = s.do(http.MethodGet, "/admin/wordlists?tenant=alpha", "")
+	s.Equal(http.StatusOK, rec.Code)
+	var list struct {
+		Wordlists []wordlist.Info `json:"wordlists"`
+	}
+	s.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &list))
+	s.Len(list.Wordlists, 2)
+
+	s.Equal(http.StatusBadRequest, s.do(http.MethodPut, "/admin/wordlists/.hidden", "admin\n").Code)
+	s.Equal(http.StatusBadRequest, s.do(http.MethodPut, "/admin/wordlists/empty", "").Code)
+	s.Equal(http.StatusRequestEntityTooLarge, s.do(http.MethodPut, "/admin/wordlists/large", strings.Repeat("admin\n", 10)).Code)
+
+	for _, name := range []string{"..", "../../../../tmp", "alpha%2F..%2F.."} {
+		rec = s.do(http.MethodPut, "/admin/wordlists/escape?tenant="+name, "admin\n")
+		s.Equal(http.StatusBadRequest, rec.Code, "tenant %s: %s", name, rec.Body.String())
+		s.Equal(http.StatusBadRequest, s.do(http.MethodDelete, "/admin/wordlists/common?tenant="+name, "").Code, name)
+		s.Equal(http.StatusBadRequest, s.do(http.MethodGet, "/admin/credentials?tenant="+name, "").Code, name)
+	}
+	_, err = registry.Resolve(context.Background(), "escape")
+	s.ErrorIs(err, wordlist.ErrNotFound)
+
+	s.Equal(http.StatusNotFound, s.do(http.MethodDelete, "/admin/wordlists/common?tenant=alpha", "").Code)
+	s.Equal(http.StatusOK, s.do(http.MethodDelete, "/admin/wordlists/common", "").Code)
+	s.Equal(http.StatusNotFound, s.do(http.MethodDelete, "/admin/wordlists/common", "").Code)
+}
+
+func (s *AdminTestSuite) TestUnknownRoute() {
+	s.Equal(http.StatusNotFound, s.do(http.MethodGet, "/admin/unknown", "").Code)
+	s.Equal(http.StatusMethodNotAllowed, s.do(http.MethodDelete, "/admin/jobs", "").Code)
+}
+
+func TestAdminTestSuite(t *testing.T) {
+	suite.Run(t, new(AdminTestSuite))
+}
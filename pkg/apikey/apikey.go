@@ -0,0 +1,77 @@
+// Package apikey authenticates requests to the MCP endpoint, and to the endpoints describing the
+// server, with bearer API keys. The server
+// launches scanners against arbitrary hosts, so deployments reachable by others require a key,
+// either the single key of --api-key or the tenant keys of --tenant-keys. Every request is logged
+// with its authentication outcome.
+package apikey
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// SharedUser is the user of requests authenticated by the shared API key.
+	SharedUser = "api-key"
+	// tokenLifetime is how long a verified key is valid for; keys are verified again on every
+	// request, but the bearer token middleware rejects tokens without an expiration.
+	tokenLifetime = time.Hour
+)
+
+// Verifier returns a bearer token verifier accepting the shared API key key. Requests it
+// authenticates are not scoped to a tenant and not restricted by a role.
+func Verifier(key string) auth.TokenVerifier {
+	return func(_ context.Context, token string, _ *http.Request) (*auth.TokenInfo, error) {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(key)) != 1 {
+			return nil, auth.ErrInvalidToken
+		}
+
+		return &auth.TokenInfo{Expiration: time.Now().Add(tokenLifetime), UserID: SharedUser}, nil
+	}
+}
+
+// Require rejects requests without a bearer token accepted by verifier with 401 Unauthorized,
+// before they reach next, so that unauthenticated clients can neither list nor call tools, nor
+// read the capability document, metrics or profiles. Each request is logged: rejected ones as warnings with the reason, authenticated ones
+// with their user.
+func Require(verifier auth.TokenVerifier, logger zerolog.Logger) func(http.Handler) http.Handler {
+	logged := func(ctx context.Context, token string, r *http.Request) (*auth.TokenInfo, error) {
+		info, err := verifier(ctx, token, r)
+		if err != nil {
+			logger.Warn().Err(err).Str("remote", r.RemoteAddr).Msgf("Rejected request %s %s with an invalid API key", r.Method, r.URL.Path)
+		}
+		return info, err
+	}
+
+	return func(next http.Handler) http.Handler {
+		authenticated := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user := ""
+			if info := auth.TokenInfoFromContext(r.Context()); info != nil {
+				user = info.UserID
+			}
+			logger.Info().Str("remote", r.RemoteAddr).Str("user", user).Msgf("Authenticated request %s %s", r.Method, r.URL.Path)
+			next.ServeHTTP(w, r)
+		})
+		protected := auth.RequireBearerToken(logged, nil)(authenticated)
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !hasBearerToken(r) {
+				logger.Warn().Str("remote", r.RemoteAddr).Msgf("Rejected request %s %s without a bearer token", r.Method, r.URL.Path)
+			}
+			protected.ServeHTTP(w, r)
+		})
+	}
+}
+
+// hasBearerToken reports whether r carries an Authorization header the bearer token middleware
+// reads a token from.
+func hasBearerToken(r *http.Request) bool {
+	fields := strings.Fields(r.Header.Get("Authorization"))
+	return len(fields) == 2 && strings.EqualFold(fields[0], "bearer")
+}
@@ -0,0 +1,83 @@
+package apikey
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+)
+
+const testKey = "s3cret-key"
+
+type APIKeyTestSuite struct {
+	suite.Suite
+	logs    bytes.Buffer
+	handler http.Handler
+	user    string
+}
+
+func (s *APIKeyTestSuite) SetupTest() {
+	s.logs.Reset()
+	s.user = ""
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.user = auth.TokenInfoFromContext(r.Context()).UserID
+		w.WriteHeader(http.StatusOK)
+	})
+	s.handler = Require(Verifier(testKey), zerolog.New(&s.logs))(next)
+}
+
+func (s *APIKeyTestSuite) do(authorization string) *httptest.ResponseRecorder {
+	req := httptest.NewRequestWithContext(context.Background(), http.MethodPost, "/mcp", nil)
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	rec := httptest.NewRecorder()
+	s.handler.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func (s *APIKeyTestSuite) TestAuthenticated() {
+	s.Equal(http.StatusOK, s.do("Bearer "+testKey).Code)
+	s.Equal(SharedUser, s.user)
+	s.Contains(s.logs.String(), "Authenticated request POST /mcp")
+	s.Contains(s.logs.String(), `"user":"api-key"`)
+	s.NotContains(s.logs.String(), "Rejected")
+}
+
+func (s *APIKeyTestSuite) TestMissingToken() {
+	for _, authorization := range []string{"", "Basic dXNlcjpwYXNz", testKey} {
+		s.logs.Reset()
+		s.Equal(http.StatusUnauthorized, s.do(authorization).Code, authorization)
+		s.Contains(s.logs.String(), "without a bearer token", authorization)
+		s.NotContains(s.logs.String(), "Authenticated", authorization)
+	}
+	s.Empty(s.user, "rejected requests do not reach the handler")
+}
+
+func (s *APIKeyTestSuite) TestInvalidToken() {
+	s.Equal(http.StatusUnauthorized, s.do("Bearer wrong").Code)
+	s.Contains(s.logs.String(), "with an invalid API key")
+	s.NotContains(s.logs.String(), "wrong", "keys are not logged")
+	s.Empty(s.user)
+}
+
+func (s *APIKeyTestSuite) TestVerifier() {
+	info, err := Verifier(testKey)(context.Background(), testKey, nil)
+	s.Require().NoError(err)
+	s.Equal(SharedUser, info.UserID)
+	s.False(info.Expiration.IsZero())
+	s.Empty(info.Extra, "the shared key is not scoped to a tenant")
+
+	_, err = Verifier(testKey)(context.Background(), testKey+"x", nil)
+	s.ErrorIs(err, auth.ErrInvalidToken)
+}
+
+func TestAPIKeyTestSuite(t *testing.T) {
+	suite.Run(t, new(APIKeyTestSuite))
+}
@@ -0,0 +1,192 @@
+package artifacts
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/types"
+)
+
+const (
+	dirPerms  = 0o750
+	filePerms = 0o600
+)
+
+// Config controls spilling of large outputs to artifact files.
+type Config struct {
+	// Dir is the directory artifact files are written to.
+	Dir string
+	// MaxOutputBytes is the largest output stored in the database; 0 disables spilling.
+	MaxOutputBytes int
+}
+
+// SpillOutput moves an OutputJSON larger than cfg.MaxOutputBytes to an artifact file,
+// leaving a truncated preview, the full size and the file reference on the execution.
+// When the artifact cannot be written, only the preview is kept and an error is returned.
+func SpillOutput(cfg Config, exec *models.ToolExecution) error {
+	exec.OutputSize = len(exec.OutputJSON)
+	if cfg.MaxOutputBytes <= 0 || len(exec.OutputJSON) <= cfg.MaxOutputBytes {
+		return nil
+	}
+
+	output := exec.OutputJSON
+	exec.OutputJSON = Preview(output, types.OutputPreviewBytes)
+
+	path, err := write(cfg.Dir, Prefix(exec)+"-*.json", output)
+	if err != nil {
+		return err
+	}
+	exec.OutputFile = path
+
+	return nil
+}
+
+// Prefix returns the name prefix of the artifacts of exec: the tool name and the correlation ID,
+// so that an artifact can be traced from the logs.
+func Prefix(exec *models.ToolExecution) string {
+	if exec.CorrelationID == "" {
+		return exec.ToolName
+	}
+
+	return exec.ToolName + "-" + exec.CorrelationID
+}
+
+// SaveCapture writes a HAR document recorded by a capture proxy to a new artifact file in dir,
+// named after prefix, and returns its absolute path.
+func SaveCapture(dir, prefix string, har []byte) (string, error) {
+	if dir == "" {
+		return "", fmt.Errorf("failed to write capture: no artifact directory configured")
+	}
+
+	return write(dir, prefix+"-*.har", string(har))
+}
+
+// LoadOutput returns the full OutputJSON of an execution, reading it from the artifact file
+// when the output was spilled. The preview is returned with an error when the artifact cannot be
+// read, e.g. once evicted by retention (see Sweep); loaded artifacts are marked used.
+func LoadOutput(exec *models.ToolExecution) (string, error) {
+	if exec.OutputFile == "" {
+		return exec.OutputJSON, nil
+	}
+
+	data, err := os.ReadFile(exec.OutputFile)
+	if err != nil {
+		return exec.OutputJSON, fmt.Errorf("failed to read output artifact: %w", err)
+	}
+	touch(exec.OutputFile)
+
+	return string(data), nil
+}
+
+// Remove deletes artifact files, ignoring files that no longer exist.
+func Remove(paths ...string) error {
+	return removeAll(paths, false)
+}
+
+// Shred overwrites artifact files with random data and syncs them to disk before deleting them,
+// so that their content is not left in the freed blocks. Files that no longer exist are ignored.
+// Overwriting does not reach copies kept by copy-on-write filesystems or snapshots.
+func Shred(paths ...string) error {
+	return removeAll(paths, true)
+}
+
+// removeAll deletes the files of paths, shredding them first if shred is set.
+func removeAll(paths []string, shred bool) error {
+	var errs []string
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		if err := remove(path, shred); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to remove artifacts: %s", strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+// remove deletes the file at path, overwriting it with random data first if shred is set.
+func remove(path string, shred bool) error {
+	if shred {
+		if err := overwrite(path); err != nil {
+			return err
+		}
+	}
+
+	return os.Remove(path)
+}
+
+// overwrite replaces the content of the file at path with as many random bytes and syncs it.
+func overwrite(path string) error {
+	file, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err == nil {
+		_, err = io.CopyN(file, rand.Reader, info.Size())
+	}
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to overwrite %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// Preview truncates output to at most maxBytes without splitting a UTF-8 sequence.
+func Preview(output string, maxBytes int) string {
+	if len(output) <= maxBytes {
+		return output
+	}
+
+	cut := maxBytes
+	for cut > 0 && !utf8.RuneStart(output[cut]) {
+		cut--
+	}
+
+	return output[:cut]
+}
+
+// write stores output in a new uniquely named file in dir, named after pattern as with
+// os.CreateTemp, and returns its absolute path.
+func write(dir, pattern, output string) (string, error) {
+	if err := os.MkdirAll(dir, dirPerms); err != nil {
+		return "", fmt.Errorf("failed to create artifact directory: %w", err)
+	}
+
+	file, err := os.CreateTemp(dir, pattern)
+	if err != nil {
+		return "", fmt.Errorf("failed to create artifact: %w", err)
+	}
+
+	err = file.Chmod(filePerms)
+	if err == nil {
+		_, err = file.WriteString(output)
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(file.Name())
+		return "", fmt.Errorf("failed to write artifact: %w", err)
+	}
+
+	return filepath.Abs(file.Name())
+}
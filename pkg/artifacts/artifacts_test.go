@@ -0,0 +1,135 @@
+package artifacts
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/types"
+)
+
+type ArtifactsTestSuite struct {
+	suite.Suite
+	dir string
+}
+
+func (s *ArtifactsTestSuite) SetupTest() {
+	s.dir = s.T().TempDir()
+}
+
+func (s *ArtifactsTestSuite) TestSpillOutput_BelowLimit() {
+	exec := &models.ToolExecution{ToolName: "nikto", OutputJSON: `{"content":[]}`}
+	s.Require().NoError(SpillOutput(Config{Dir: s.dir, MaxOutputBytes: 1024}, exec))
+	s.Equal(`{"content":[]}`, exec.OutputJSON)
+	s.Equal(len(`{"content":[]}`), exec.OutputSize)
+	s.Empty(exec.OutputFile)
+}
+
+func (s *ArtifactsTestSuite) TestSpillOutput_Disabled() {
+	output := strings.Repeat("x", types.OutputPreviewBytes*2)
+	exec := &models.ToolExecution{ToolName: "nikto", OutputJSON: output}
+	s.Require().NoError(SpillOutput(Config{Dir: s.dir}, exec))
+	s.Equal(output, exec.OutputJSON)
+	s.Empty(exec.OutputFile)
+}
+
+func (s *ArtifactsTestSuite) TestSpillOutput_AboveLimit() {
+	output := strings.Repeat("x", types.OutputPreviewBytes*2)
+	exec := &models.ToolExecution{ToolName: "nikto", OutputJSON: output}
+	s.Require().NoError(SpillOutput(Config{Dir: s.dir, MaxOutputBytes: 100}, exec))
+
+	s.Len(exec.OutputJSON, types.OutputPreviewBytes)
+	s.Equal(len(output), exec.OutputSize)
+	s.True(filepath.IsAbs(exec.OutputFile))
+	s.Equal(s.dir, filepath.Dir(exec.OutputFile))
+
+	loaded, err := LoadOutput(exec)
+	s.Require().NoError(err)
+	s.Equal(output, loaded)
+
+	s.Require().NoError(Remove(exec.OutputFile))
+	_, err = os.Stat(exec.OutputFile)
+	s.True(os.IsNotExist(err))
+}
+
+func (s *ArtifactsTestSuite) TestSpillOutput_NamedAfterCorrelationID() {
+	exec := &models.ToolExecution{
+		CorrelationID: "0123456789abcdef",
+		OutputJSON:    strings.Repeat("x", types.OutputPreviewBytes*2),
+		ToolName:      "nikto",
+	}
+	s.Require().NoError(SpillOutput(Config{Dir: s.dir, MaxOutputBytes: 100}, exec))
+	s.True(strings.HasPrefix(filepath.Base(exec.OutputFile), "nikto-0123456789abcdef-"), exec.OutputFile)
+}
+
+func (s *ArtifactsTestSuite) TestSpillOutput_WriteFailureKeepsPreview() {
+	blocker := filepath.Join(s.dir, "file")
+	s.Require().NoError(os.WriteFile(blocker, []byte("x"), 0o600))
+
+	exec := &models.ToolExecution{ToolName: "nikto", OutputJSON: strings.Repeat("x", types.OutputPreviewBytes*2)}
+	s.Error(SpillOutput(Config{Dir: filepath.Join(blocker, "artifacts"), MaxOutputBytes: 100}, exec))
+	s.Len(exec.OutputJSON, types.OutputPreviewBytes)
+	s.Empty(exec.OutputFile)
+}
+
+func (s *ArtifactsTestSuite) TestLoadOutput() {
+	exec := &models.ToolExecution{OutputJSON: "inline"}
+	output, err := LoadOutput(exec)
+	s.Require().NoError(err)
+	s.Equal("inline", output)
+
+	exec.OutputFile = filepath.Join(s.dir, "missing.json")
+	output, err = LoadOutput(exec)
+	s.Error(err)
+	s.Equal("inline", output)
+}
+
+func (s *ArtifactsTestSuite) TestSaveCapture() {
+	path, err := SaveCapture(filepath.Join(s.dir, "captures"), "nikto-0123456789abcdef", []byte(`{"log":{}}`))
+	s.Require().NoError(err)
+	s.True(filepath.IsAbs(path))
+	s.True(strings.HasPrefix(filepath.Base(path), "nikto-0123456789abcdef-"), path)
+	s.Equal(".har", filepath.Ext(path))
+
+	data, err := os.ReadFile(path)
+	s.Require().NoError(err)
+	s.Equal(`{"log":{}}`, string(data))
+
+	_, err = SaveCapture("", "nikto", nil)
+	s.Error(err)
+}
+
+func (s *ArtifactsTestSuite) TestRemove_IgnoresMissing() {
+	s.NoError(Remove("", filepath.Join(s.dir, "missing.json")))
+}
+
+func (s *ArtifactsTestSuite) TestShred() {
+	secret := []byte("Authorization: Bearer s3cr3t")
+	path := filepath.Join(s.dir, "nikto.json")
+	s.Require().NoError(os.WriteFile(path, secret, 0o600))
+	// A second link to the file shows the content left on disk once the artifact is removed.
+	link := filepath.Join(s.dir, "link")
+	s.Require().NoError(os.Link(path, link))
+
+	s.Require().NoError(Shred(path, filepath.Join(s.dir, "missing.json")))
+	_, err := os.Stat(path)
+	s.True(os.IsNotExist(err))
+	data, err := os.ReadFile(link)
+	s.Require().NoError(err)
+	s.Len(data, len(secret), "the file is overwritten in place")
+	s.NotContains(string(data), "s3cr3t")
+}
+
+func (s *ArtifactsTestSuite) TestPreview() {
+	s.Equal("short", Preview("short", 10))
+	s.Equal("abc", Preview("abcdef", 3))
+	// "é" is two bytes; cutting in the middle keeps the sequence whole.
+	s.Equal("a", Preview("aé", 2))
+}
+
+func TestArtifactsTestSuite(t *testing.T) {
+	suite.Run(t, new(ArtifactsTestSuite))
+}
@@ -0,0 +1,154 @@
+package artifacts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// RetentionConfig controls the eviction of artifact files: spilled outputs and HAR captures.
+// Artifacts are evicted independently of the executions referencing them, which keep their
+// output preview once the file is gone.
+type RetentionConfig struct {
+	// Dir is the artifact directory, swept recursively.
+	Dir string
+	// MaxAge is the time after its last use at which a file is removed, 0 to keep files.
+	MaxAge time.Duration
+	// MaxBytes is the disk quota of the directory. Above it the least recently used files are
+	// removed until it fits, 0 for no quota.
+	MaxBytes int64
+	// Interval is how often the directory is swept, 0 to sweep once.
+	Interval time.Duration
+	// Shred overwrites evicted files before removing them, see Shred.
+	Shred bool
+}
+
+// Enabled reports whether the config evicts any file.
+func (c RetentionConfig) Enabled() bool {
+	return c.Dir != "" && (c.MaxAge > 0 || c.MaxBytes > 0)
+}
+
+// SweepResult reports a sweep of the artifact directory.
+type SweepResult struct {
+	// Removed and FreedBytes are the files evicted and their total size.
+	Removed    int   `json:"removed"`
+	FreedBytes int64 `json:"freed_bytes"`
+	// Files and Bytes are the files kept and their total size.
+	Files int   `json:"files"`
+	Bytes int64 `json:"bytes"`
+}
+
+// artifactFile is a file found by a sweep.
+type artifactFile struct {
+	path    string
+	size    int64
+	lastUse time.Time
+}
+
+// Sweep removes the artifact files unused since cfg.MaxAge before now, then the least recently
+// used files while the directory exceeds cfg.MaxBytes. A file is used when it is written or
+// loaded, see LoadOutput. Files that cannot be removed are counted as kept and reported in the
+// returned error.
+func Sweep(cfg RetentionConfig, now time.Time) (SweepResult, error) {
+	var result SweepResult
+	if !cfg.Enabled() {
+		return result, nil
+	}
+
+	var files []artifactFile
+	err := filepath.WalkDir(cfg.Dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		// Files removed since they were listed are skipped.
+		if info, err := entry.Info(); err == nil {
+			files = append(files, artifactFile{path: path, size: info.Size(), lastUse: info.ModTime()})
+		}
+		return nil
+	})
+	if err != nil {
+		return result, fmt.Errorf("failed to list artifacts: %w", err)
+	}
+
+	// Least recently used first
+	sort.Slice(files, func(i, j int) bool { return files[i].lastUse.Before(files[j].lastUse) })
+	for _, file := range files {
+		result.Files++
+		result.Bytes += file.size
+	}
+
+	var errs []error
+	for _, file := range files {
+		expired := cfg.MaxAge > 0 && now.Sub(file.lastUse) > cfg.MaxAge
+		overQuota := cfg.MaxBytes > 0 && result.Bytes > cfg.MaxBytes
+		if !expired && !overQuota {
+			continue
+		}
+		if err := remove(file.path, cfg.Shred); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			errs = append(errs, err)
+			continue
+		}
+		result.Files--
+		result.Bytes -= file.size
+		result.Removed++
+		result.FreedBytes += file.size
+	}
+	if len(errs) > 0 {
+		return result, fmt.Errorf("failed to remove artifacts: %w", errors.Join(errs...))
+	}
+
+	return result, nil
+}
+
+// RunRetention sweeps the artifact directory at once and then every cfg.Interval until ctx is
+// done, logging evictions and failures. It returns at once when cfg evicts nothing.
+func RunRetention(ctx context.Context, cfg RetentionConfig, logger zerolog.Logger) {
+	if !cfg.Enabled() {
+		return
+	}
+
+	sweep := func() {
+		result, err := Sweep(cfg, time.Now())
+		if err != nil {
+			logger.Warn().Err(err).Msg("Artifact retention sweep failed")
+		}
+		if result.Removed > 0 {
+			logger.Info().Msgf("Evicted %d artifacts (%d bytes), %d artifacts (%d bytes) kept",
+				result.Removed, result.FreedBytes, result.Files, result.Bytes)
+		}
+	}
+
+	sweep()
+	if cfg.Interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			sweep()
+		}
+	}
+}
+
+// touch marks the artifact at path as used now, so that retention evicts it last.
+func touch(path string) {
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
+}
@@ -0,0 +1,104 @@
+package artifacts
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+)
+
+type RetentionTestSuite struct {
+	suite.Suite
+	dir string
+	now time.Time
+}
+
+func (s *RetentionTestSuite) SetupTest() {
+	s.dir = s.T().TempDir()
+	s.now = time.Now()
+}
+
+// write creates an artifact of size bytes under the artifact directory, last used age ago.
+func (s *RetentionTestSuite) write(name string, size int, age time.Duration) string {
+	path := filepath.Join(s.dir, name)
+	s.Require().NoError(os.MkdirAll(filepath.Dir(path), 0o750))
+	s.Require().NoError(os.WriteFile(path, []byte(strings.Repeat("x", size)), 0o600))
+	used := s.now.Add(-age)
+	s.Require().NoError(os.Chtimes(path, used, used))
+
+	return path
+}
+
+func (s *RetentionTestSuite) exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func (s *RetentionTestSuite) TestSweep_Disabled() {
+	path := s.write("old.json", 10, 365*24*time.Hour)
+
+	result, err := Sweep(RetentionConfig{Dir: s.dir}, s.now)
+	s.Require().NoError(err)
+	s.Equal(SweepResult{}, result)
+	s.True(s.exists(path))
+}
+
+func (s *RetentionTestSuite) TestSweep_MaxAge() {
+	old := s.write("nikto-old.json", 10, 48*time.Hour)
+	capture := s.write("captures/nikto-old.har", 20, 48*time.Hour)
+	recent := s.write("nikto-recent.json", 30, time.Hour)
+
+	result, err := Sweep(RetentionConfig{Dir: s.dir, MaxAge: 24 * time.Hour}, s.now)
+	s.Require().NoError(err)
+	s.Equal(SweepResult{Removed: 2, FreedBytes: 30, Files: 1, Bytes: 30}, result)
+	s.False(s.exists(old))
+	s.False(s.exists(capture), "captures are swept too")
+	s.True(s.exists(recent))
+}
+
+func (s *RetentionTestSuite) TestSweep_QuotaEvictsLeastRecentlyUsed() {
+	oldest := s.write("a.json", 40, 3*time.Hour)
+	loaded := s.write("b.json", 40, 2*time.Hour)
+	newest := s.write("c.json", 40, time.Hour)
+
+	// Loading an output marks its artifact used.
+	_, err := LoadOutput(&models.ToolExecution{OutputFile: loaded})
+	s.Require().NoError(err)
+
+	result, err := Sweep(RetentionConfig{Dir: s.dir, MaxBytes: 100}, time.Now())
+	s.Require().NoError(err)
+	s.Equal(SweepResult{Removed: 1, FreedBytes: 40, Files: 2, Bytes: 80}, result)
+	s.False(s.exists(oldest))
+	s.True(s.exists(loaded))
+	s.True(s.exists(newest))
+
+	result, err = Sweep(RetentionConfig{Dir: s.dir, MaxBytes: 50}, time.Now())
+	s.Require().NoError(err)
+	s.Equal(1, result.Removed)
+	s.False(s.exists(newest), "the file loaded last is kept")
+	s.True(s.exists(loaded))
+}
+
+func (s *RetentionTestSuite) TestSweep_MissingDir() {
+	result, err := Sweep(RetentionConfig{Dir: filepath.Join(s.dir, "missing"), MaxBytes: 1}, s.now)
+	s.Require().NoError(err)
+	s.Equal(SweepResult{}, result)
+}
+
+func (s *RetentionTestSuite) TestSweep_EvictedOutputKeepsPreview() {
+	path := s.write("nikto.json", 10, 48*time.Hour)
+	_, err := Sweep(RetentionConfig{Dir: s.dir, MaxAge: time.Hour}, s.now)
+	s.Require().NoError(err)
+
+	output, err := LoadOutput(&models.ToolExecution{OutputJSON: "preview", OutputFile: path})
+	s.Error(err)
+	s.Equal("preview", output)
+}
+
+func TestRetentionTestSuite(t *testing.T) {
+	suite.Run(t, new(RetentionTestSuite))
+}
@@ -0,0 +1,181 @@
+package artifacts
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tb0hdan/wass-mcp/pkg/types"
+)
+
+// maxTailBytes bounds the end of a stream file read by TailStream.
+const maxTailBytes = 1 << 20
+
+// ErrStreamClosed is returned when writing to a closed Stream.
+var ErrStreamClosed = errors.New("output stream closed")
+
+// Stream is the output stream artifact of a running execution. Output written to it is buffered
+// and appended to its file in chunks, once types.StreamChunkBytes are buffered and at every flush
+// interval, so that the output of long scans survives a crash of the server and can be tailed
+// while they run. It is safe for concurrent use.
+type Stream struct {
+	buf    []byte
+	closed bool
+	done   chan struct{}
+	file   *os.File
+	path   string
+
+	mu sync.Mutex
+}
+
+// OpenStream creates the output stream artifact of an execution in dir, named after prefix, and
+// flushes it every interval until it is closed.
+func OpenStream(dir, prefix string, interval time.Duration) (*Stream, error) {
+	if err := os.MkdirAll(dir, dirPerms); err != nil {
+		return nil, fmt.Errorf("failed to create artifact directory: %w", err)
+	}
+	file, err := os.CreateTemp(dir, prefix+"-*.log")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create output stream: %w", err)
+	}
+	if err := file.Chmod(filePerms); err != nil {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+		return nil, fmt.Errorf("failed to create output stream: %w", err)
+	}
+	path, err := filepath.Abs(file.Name())
+	if err != nil {
+		path = file.Name()
+	}
+
+	stream := &Stream{done: make(chan struct{}), file: file, path: path}
+	go stream.flushEvery(interval)
+
+	return stream, nil
+}
+
+// Path returns the absolute path of the stream file.
+func (s *Stream) Path() string {
+	return s.path
+}
+
+// Write buffers p, flushing the buffer once it holds types.StreamChunkBytes.
+func (s *Stream) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return 0, ErrStreamClosed
+	}
+	s.buf = append(s.buf, p...)
+	if len(s.buf) >= types.StreamChunkBytes {
+		if err := s.flush(); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(p), nil
+}
+
+// Flush appends the buffered output to the stream file.
+func (s *Stream) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+
+	return s.flush()
+}
+
+// Close flushes the buffered output and closes the stream file, which is kept.
+func (s *Stream) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	close(s.done)
+
+	err := s.flush()
+	if closeErr := s.file.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to close output stream: %w", closeErr)
+	}
+
+	return err
+}
+
+// flush writes the buffer to the stream file. The caller holds mu.
+func (s *Stream) flush() error {
+	if len(s.buf) == 0 {
+		return nil
+	}
+	_, err := s.file.Write(s.buf)
+	s.buf = s.buf[:0]
+	if err != nil {
+		return fmt.Errorf("failed to write output stream: %w", err)
+	}
+
+	return nil
+}
+
+// flushEvery flushes the stream every interval until it is closed.
+func (s *Stream) flushEvery(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			_ = s.Flush()
+		}
+	}
+}
+
+// TailStream returns the last lines of the stream file at path, read from at most its last MiB.
+func TailStream(path string, lines int) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open output stream: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return "", fmt.Errorf("failed to read output stream: %w", err)
+	}
+	offset := max(info.Size()-maxTailBytes, 0)
+	data, err := io.ReadAll(io.NewSectionReader(file, offset, info.Size()-offset))
+	if err != nil {
+		return "", fmt.Errorf("failed to read output stream: %w", err)
+	}
+	text := string(data)
+	if offset > 0 {
+		// Drop the line cut by the offset.
+		if _, rest, ok := strings.Cut(text, "\n"); ok {
+			text = rest
+		}
+	}
+
+	return TailLines(text, lines), nil
+}
+
+// TailLines returns the last n lines of text, without the trailing newline.
+func TailLines(text string, n int) string {
+	text = strings.TrimRight(text, "\n")
+	if n <= 0 || text == "" {
+		return ""
+	}
+	all := strings.Split(text, "\n")
+	if len(all) > n {
+		all = all[len(all)-n:]
+	}
+
+	return strings.Join(all, "\n")
+}
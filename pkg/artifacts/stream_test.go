@@ -0,0 +1,119 @@
+package artifacts
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/types"
+)
+
+type StreamTestSuite struct {
+	suite.Suite
+	dir string
+}
+
+func (s *StreamTestSuite) SetupTest() {
+	s.dir = s.T().TempDir()
+}
+
+// read returns the content of the stream file.
+func (s *StreamTestSuite) read(stream *Stream) string {
+	data, err := os.ReadFile(stream.Path())
+	s.Require().NoError(err)
+	return string(data)
+}
+
+func (s *StreamTestSuite) TestOpenStream() {
+	stream, err := OpenStream(filepath.Join(s.dir, "artifacts"), "nikto-0123456789abcdef", time.Hour)
+	s.Require().NoError(err)
+	defer stream.Close()
+
+	s.True(filepath.IsAbs(stream.Path()))
+	s.True(strings.HasPrefix(filepath.Base(stream.Path()), "nikto-0123456789abcdef-"), stream.Path())
+	s.Equal(".log", filepath.Ext(stream.Path()))
+	info, err := os.Stat(stream.Path())
+	s.Require().NoError(err)
+	s.Equal(os.FileMode(filePerms), info.Mode().Perm())
+}
+
+func (s *StreamTestSuite) TestWrite_FlushesChunks() {
+	stream, err := OpenStream(s.dir, "nuclei", time.Hour)
+	s.Require().NoError(err)
+	defer stream.Close()
+
+	_, err = stream.Write([]byte("first line\n"))
+	s.Require().NoError(err)
+	s.Empty(s.read(stream), "output is buffered below a chunk")
+
+	chunk := strings.Repeat("x", types.StreamChunkBytes)
+	_, err = stream.Write([]byte(chunk))
+	s.Require().NoError(err)
+	s.Equal("first line\n"+chunk, s.read(stream), "a full chunk is flushed")
+}
+
+func (s *StreamTestSuite) TestFlushInterval() {
+	stream, err := OpenStream(s.dir, "nuclei", 10*time.Millisecond)
+	s.Require().NoError(err)
+	defer stream.Close()
+
+	_, err = stream.Write([]byte("partial result\n"))
+	s.Require().NoError(err)
+	s.Eventually(func() bool { return s.read(stream) == "partial result\n" }, time.Second, 10*time.Millisecond)
+}
+
+func (s *StreamTestSuite) TestClose() {
+	stream, err := OpenStream(s.dir, "wapiti", time.Hour)
+	s.Require().NoError(err)
+	_, err = stream.Write([]byte("last line\n"))
+	s.Require().NoError(err)
+
+	s.Require().NoError(stream.Close())
+	s.NoError(stream.Close(), "closing twice is harmless")
+	s.Equal("last line\n", s.read(stream), "the file is kept with the buffered output")
+	_, err = stream.Write([]byte("late"))
+	s.ErrorIs(err, ErrStreamClosed)
+}
+
+func (s *StreamTestSuite) TestOpenStream_Failure() {
+	blocker := filepath.Join(s.dir, "file")
+	s.Require().NoError(os.WriteFile(blocker, []byte("x"), 0o600))
+
+	_, err := OpenStream(filepath.Join(blocker, "artifacts"), "nikto", time.Hour)
+	s.Error(err)
+}
+
+func (s *StreamTestSuite) TestTailStream() {
+	path := filepath.Join(s.dir, "stream.log")
+	s.Require().NoError(os.WriteFile(path, []byte("one\ntwo\nthree\n"), 0o600))
+
+	tail, err := TailStream(path, 2)
+	s.Require().NoError(err)
+	s.Equal("two\nthree", tail)
+
+	_, err = TailStream(filepath.Join(s.dir, "missing.log"), 2)
+	s.Error(err)
+}
+
+func (s *StreamTestSuite) TestTailStream_LargeFile() {
+	path := filepath.Join(s.dir, "stream.log")
+	s.Require().NoError(os.WriteFile(path, []byte(strings.Repeat("y", maxTailBytes)+"\nlast\n"), 0o600))
+
+	tail, err := TailStream(path, 5)
+	s.Require().NoError(err)
+	s.Equal("last", tail, "the line cut by the read offset is dropped")
+}
+
+func (s *StreamTestSuite) TestTailLines() {
+	s.Equal("b\nc", TailLines("a\nb\nc\n", 2))
+	s.Equal("a\nb", TailLines("a\nb", 5))
+	s.Empty(TailLines("a\nb", 0))
+	s.Empty(TailLines("", 3))
+}
+
+func TestStreamTestSuite(t *testing.T) {
+	suite.Run(t, new(StreamTestSuite))
+}
@@ -0,0 +1,220 @@
+// Package bundle locates the scanner binaries bundled with the all-in-one container image. The
+// bundle directory holds the binaries in bin/ and a manifest of the versions they were built with,
+// so that the server can prefer bundled binaries over PATH and detect when they drift from the
+// versions the image was validated against.
+package bundle
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"sync/atomic"
+)
+
+// DefaultDir is the bundle directory of the container image.
+const DefaultDir = "/opt/wass-mcp/tools"
+
+// Entries of the bundle directory.
+const (
+	// BinDir holds the bundled binaries.
+	BinDir = "bin"
+	// ManifestFile maps the name of each bundled binary to its expected version.
+	ManifestFile = "versions.json"
+)
+
+// manifestPerms are the permissions of the manifest written by Record.
+const manifestPerms = 0o644
+
+// active is the bundle binaries are looked up in first, nil when binaries come from PATH only.
+var active atomic.Pointer[Bundle]
+
+// Bundle is a directory of bundled scanner binaries.
+type Bundle struct {
+	// Dir is the bundle directory.
+	Dir string
+	// Versions are the expected versions of the bundled binaries by name, empty when the bundle
+	// has no manifest.
+	Versions map[string]string
+}
+
+// Load opens the bundle in dir, DefaultDir when empty. The bin directory is required, while a
+// missing manifest leaves the bundled versions unchecked.
+func Load(dir string) (*Bundle, error) {
+	if dir == "" {
+		dir = DefaultDir
+	}
+
+	bundle := &Bundle{Dir: dir, Versions: make(map[string]string)}
+	info, err := os.Stat(bundle.BinDir())
+	if err != nil {
+		return nil, fmt.Errorf("tool bundle %s: %w", dir, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("tool bundle %s: %s is not a directory", dir, bundle.BinDir())
+	}
+
+	data, err := os.ReadFile(bundle.Manifest())
+	if errors.Is(err, os.ErrNotExist) {
+		return bundle, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tool manifest: %w", err)
+	}
+	if err := json.Unmarshal(data, &bundle.Versions); err != nil {
+		return nil, fmt.Errorf("failed to parse tool manifest %s: %w", bundle.Manifest(), err)
+	}
+
+	return bundle, nil
+}
+
+// BinDir returns the directory of the bundled binaries.
+func (b *Bundle) BinDir() string {
+	return filepath.Join(b.Dir, BinDir)
+}
+
+// Manifest returns the path of the version manifest.
+func (b *Bundle) Manifest() string {
+	return filepath.Join(b.Dir, ManifestFile)
+}
+
+// Path returns the path of the bundled binary name and whether it exists and is executable.
+func (b *Bundle) Path(name string) (string, bool) {
+	path := filepath.Join(b.BinDir(), name)
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() || info.Mode().Perm()&0o111 == 0 {
+		return "", false
+	}
+
+	return path, true
+}
+
+// Record writes the manifest from the versions of the bundled tools in statuses, e.g. when the
+// image is built. Tools found on PATH or reporting no version are left out.
+func (b *Bundle) Record(statuses []Status) error {
+	versions := make(map[string]string, len(statuses))
+	for _, status := range statuses {
+		if status.Bundled && status.Version != "" {
+			versions[status.Name] = status.Version
+		}
+	}
+
+	data, err := json.MarshalIndent(versions, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode tool manifest: %w", err)
+	}
+	if err := os.WriteFile(b.Manifest(), append(data, '\n'), manifestPerms); err != nil { //nolint:gosec
+		return fmt.Errorf("failed to write tool manifest: %w", err)
+	}
+	b.Versions = versions
+
+	return nil
+}
+
+// Use makes LookPath prefer the binaries of b over PATH; nil restores PATH only lookups.
+func Use(b *Bundle) {
+	active.Store(b)
+}
+
+// Active returns the bundle passed to Use, nil when binaries come from PATH only.
+func Active() *Bundle {
+	return active.Load()
+}
+
+// LookPath returns the path of the binary name: the bundled binary when a bundle is in use and
+// has it, otherwise the binary found in PATH.
+func LookPath(name string) (path string, bundled bool, err error) {
+	if b := Active(); b != nil {
+		if path, ok := b.Path(name); ok {
+			return path, true, nil
+		}
+	}
+
+	path, err = exec.LookPath(name)
+	return path, false, err
+}
+
+// Status is the health of a tool binary.
+type Status struct {
+	// Available reports whether the binary was found.
+	Available bool `json:"available"`
+	// Bundled reports whether the binary comes from the bundle rather than PATH.
+	Bundled bool   `json:"bundled"`
+	Error   string `json:"error,omitempty"`
+	// Expected is the version recorded in the manifest, empty for tools it does not list.
+	Expected string `json:"expected,omitempty"`
+	// Healthy reports whether the binary matches the manifest: tools it lists must be bundled
+	// with the expected version, while other tools are healthy even when unavailable.
+	Healthy bool   `json:"healthy"`
+	Name    string `json:"name"`
+	Path    string `json:"path,omitempty"`
+	Version string `json:"version,omitempty"`
+}
+
+// Check returns the status of the binary name, whose version is reported by version. A nil
+// version skips the version lookup of tools that cannot report one.
+func Check(name string, version func() (string, error)) Status {
+	status := Status{Name: name}
+	if b := Active(); b != nil {
+		status.Expected = b.Versions[name]
+	}
+
+	path, bundled, err := LookPath(name)
+	if err != nil {
+		status.Error = err.Error()
+		status.Healthy = status.Expected == ""
+		return status
+	}
+	status.Available, status.Path, status.Bundled = true, path, bundled
+
+	if version != nil {
+		found, err := version()
+		if err != nil {
+			status.Error = err.Error()
+		}
+		status.Version = found
+	}
+
+	switch {
+	case status.Expected == "":
+		status.Healthy = true
+	case !status.Bundled:
+		status.Error = "expected a bundled binary, found " + path
+	case status.Version != status.Expected:
+		status.Error = fmt.Sprintf("version %q does not match the bundled version %q", status.Version, status.Expected)
+	default:
+		status.Healthy = true
+	}
+
+	return status
+}
+
+// Report is the health of the tool binaries served by the tools_versions endpoint.
+type Report struct {
+	// Dir is the bundle directory, empty when binaries come from PATH only.
+	Dir      string   `json:"dir,omitempty"`
+	Embedded bool     `json:"embedded"`
+	Healthy  bool     `json:"healthy"`
+	Tools    []Status `json:"tools"`
+}
+
+// NewReport returns the report of statuses, sorted by name. It is healthy when every tool is.
+func NewReport(statuses []Status) Report {
+	report := Report{Healthy: true, Tools: statuses}
+	if b := Active(); b != nil {
+		report.Dir, report.Embedded = b.Dir, true
+	}
+	sort.Slice(report.Tools, func(i, j int) bool {
+		return report.Tools[i].Name < report.Tools[j].Name
+	})
+	for _, status := range statuses {
+		if !status.Healthy {
+			report.Healthy = false
+		}
+	}
+
+	return report
+}
@@ -0,0 +1,150 @@
+package bundle
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type BundleTestSuite struct {
+	suite.Suite
+	dir string
+}
+
+func (s *BundleTestSuite) SetupTest() {
+	s.dir = s.T().TempDir()
+	s.Require().NoError(os.Mkdir(filepath.Join(s.dir, BinDir), 0o750))
+	Use(nil)
+}
+
+func (s *BundleTestSuite) TearDownTest() {
+	Use(nil)
+}
+
+// install writes an executable named name into the bundle.
+func (s *BundleTestSuite) install(name string) string {
+	path := filepath.Join(s.dir, BinDir, name)
+	s.Require().NoError(os.WriteFile(path, []byte("#!/bin/sh\n"), 0o755)) //nolint:gosec
+	return path
+}
+
+func (s *BundleTestSuite) TestLoad() {
+	b, err := Load(s.dir)
+	s.Require().NoError(err)
+	s.Empty(b.Versions, "a missing manifest leaves versions unchecked")
+
+	s.Require().NoError(os.WriteFile(filepath.Join(s.dir, ManifestFile), []byte(`{"nikto":"2.5.0"}`), 0o600))
+	b, err = Load(s.dir)
+	s.Require().NoError(err)
+	s.Equal(map[string]string{"nikto": "2.5.0"}, b.Versions)
+
+	s.Require().NoError(os.WriteFile(filepath.Join(s.dir, ManifestFile), []byte(`{`), 0o600))
+	_, err = Load(s.dir)
+	s.Error(err)
+
+	_, err = Load(filepath.Join(s.dir, "missing"))
+	s.Error(err)
+}
+
+func (s *BundleTestSuite) TestPath() {
+	b := &Bundle{Dir: s.dir}
+	path := s.install("nikto")
+
+	found, ok := b.Path("nikto")
+	s.True(ok)
+	s.Equal(path, found)
+
+	s.Require().NoError(os.WriteFile(filepath.Join(s.dir, BinDir, "wapiti"), []byte("data"), 0o600))
+	_, ok = b.Path("wapiti")
+	s.False(ok, "files that are not executable are not bundled binaries")
+
+	_, ok = b.Path("nuclei")
+	s.False(ok)
+}
+
+func (s *BundleTestSuite) TestLookPath_PrefersBundle() {
+	path := s.install("sh")
+
+	found, bundled, err := LookPath("sh")
+	s.Require().NoError(err)
+	s.False(bundled, "without a bundle in use binaries come from PATH")
+	s.NotEqual(path, found)
+
+	Use(&Bundle{Dir: s.dir})
+	found, bundled, err = LookPath("sh")
+	s.Require().NoError(err)
+	s.True(bundled)
+	s.Equal(path, found)
+
+	_, bundled, err = LookPath("ls")
+	s.Require().NoError(err, "binaries missing from the bundle fall back to PATH")
+	s.False(bundled)
+}
+
+func (s *BundleTestSuite) TestCheck() {
+	s.install("nikto")
+	Use(&Bundle{Dir: s.dir, Versions: map[string]string{"nikto": "2.5.0", "nuclei": "3.4.0"}})
+	version := func(found string) func() (string, error) {
+		return func() (string, error) { return found, nil }
+	}
+
+	status := Check("nikto", version("2.5.0"))
+	s.True(status.Healthy)
+	s.True(status.Available)
+	s.True(status.Bundled)
+	s.Equal("2.5.0", status.Expected)
+
+	status = Check("nikto", version("2.6.0"))
+	s.False(status.Healthy)
+	s.Contains(status.Error, "does not match")
+
+	status = Check("nuclei", version("3.4.0"))
+	s.False(status.Healthy, "tools listed in the manifest must be bundled")
+	s.False(status.Available)
+
+	status = Check("wass-missing-tool", nil)
+	s.True(status.Healthy, "tools the manifest does not list are healthy even when unavailable")
+	s.False(status.Available)
+
+	status = Check("sh", func() (string, error) { return "", errors.New("no version") })
+	s.True(status.Healthy)
+	s.False(status.Bundled)
+	s.Equal("no version", status.Error)
+}
+
+func (s *BundleTestSuite) TestRecordAndReport() {
+	b := &Bundle{Dir: s.dir}
+	Use(b)
+
+	s.Require().NoError(b.Record([]Status{
+		{Bundled: true, Name: "nikto", Version: "2.5.0"},
+		{Bundled: false, Name: "sh", Version: "5.2"},
+		{Bundled: true, Name: "shcheck.py"},
+	}))
+	s.Equal(map[string]string{"nikto": "2.5.0"}, b.Versions)
+
+	data, err := os.ReadFile(b.Manifest())
+	s.Require().NoError(err)
+	var versions map[string]string
+	s.Require().NoError(json.Unmarshal(data, &versions))
+	s.Equal(b.Versions, versions)
+
+	report := NewReport([]Status{{Healthy: true, Name: "wapiti"}, {Name: "nikto"}})
+	s.True(report.Embedded)
+	s.Equal(s.dir, report.Dir)
+	s.False(report.Healthy)
+	s.Equal("nikto", report.Tools[0].Name)
+
+	Use(nil)
+	report = NewReport([]Status{{Healthy: true, Name: "wapiti"}})
+	s.False(report.Embedded)
+	s.True(report.Healthy)
+}
+
+func TestBundleTestSuite(t *testing.T) {
+	suite.Run(t, new(BundleTestSuite))
+}
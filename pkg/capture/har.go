@@ -0,0 +1,150 @@
+package capture
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tb0hdan/wass-mcp/pkg/redact"
+)
+
+// harVersion is the HAR format version written.
+const harVersion = "1.2"
+
+// HAR is an HTTP Archive holding the transactions recorded by a capture proxy.
+type HAR struct {
+	Log Log `json:"log"`
+}
+
+// Log is the root of a HAR document.
+type Log struct {
+	Comment string  `json:"comment,omitempty"`
+	Creator Creator `json:"creator"`
+	Entries []Entry `json:"entries"`
+	Version string  `json:"version"`
+}
+
+// Creator names the application that recorded a HAR.
+type Creator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// Entry is a single recorded HTTP transaction. CONNECT tunnels are recorded without their
+// encrypted content.
+type Entry struct {
+	Cache           struct{}  `json:"cache"`
+	Comment         string    `json:"comment,omitempty"`
+	Request         Request   `json:"request"`
+	Response        Response  `json:"response"`
+	ServerIPAddress string    `json:"serverIPAddress,omitempty"`
+	StartedDateTime time.Time `json:"startedDateTime"`
+	Time            float64   `json:"time"`
+	Timings         Timings   `json:"timings"`
+}
+
+// NameValue is a header, cookie or query string parameter.
+type NameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Request is the request of an entry.
+type Request struct {
+	BodySize    int         `json:"bodySize"`
+	Cookies     []NameValue `json:"cookies"`
+	Headers     []NameValue `json:"headers"`
+	HeadersSize int         `json:"headersSize"`
+	HTTPVersion string      `json:"httpVersion"`
+	Method      string      `json:"method"`
+	PostData    *PostData   `json:"postData,omitempty"`
+	QueryString []NameValue `json:"queryString"`
+	URL         string      `json:"url"`
+}
+
+// PostData is the body of a request.
+type PostData struct {
+	Comment  string `json:"comment,omitempty"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+// Response is the response of an entry.
+type Response struct {
+	BodySize    int         `json:"bodySize"`
+	Content     Content     `json:"content"`
+	Cookies     []NameValue `json:"cookies"`
+	Headers     []NameValue `json:"headers"`
+	HeadersSize int         `json:"headersSize"`
+	HTTPVersion string      `json:"httpVersion"`
+	RedirectURL string      `json:"redirectURL"`
+	Status      int         `json:"status"`
+	StatusText  string      `json:"statusText"`
+}
+
+// Content is the body of a response.
+type Content struct {
+	Comment  string `json:"comment,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+	MimeType string `json:"mimeType"`
+	Size     int    `json:"size"`
+	Text     string `json:"text,omitempty"`
+}
+
+// Timings are the phases of an entry in milliseconds.
+type Timings struct {
+	Receive float64 `json:"receive"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+}
+
+// Redact scrubs sensitive header and cookie values and the bodies of the recorded transactions
+// with redactor, as execution records are before they are stored.
+func (h *HAR) Redact(redactor *redact.Redactor) {
+	for i := range h.Log.Entries {
+		entry := &h.Log.Entries[i]
+		entry.Request.URL = redactor.Text(entry.Request.URL)
+		redactHeaders(redactor, entry.Request.Headers)
+		redactHeaders(redactor, entry.Response.Headers)
+		redactValues(entry.Request.Cookies)
+		redactValues(entry.Response.Cookies)
+		if entry.Request.PostData != nil {
+			entry.Request.PostData.Text = redactor.Text(entry.Request.PostData.Text)
+		}
+		if entry.Response.Content.Encoding == "" {
+			entry.Response.Content.Text = redactor.Text(entry.Response.Content.Text)
+		}
+	}
+}
+
+// Transactions returns up to limit "METHOD URL -> STATUS" lines of the entries whose URL contains
+// match, every entry when match is empty.
+func (h *HAR) Transactions(match string, limit int) []string {
+	var lines []string
+	for _, entry := range h.Log.Entries {
+		if len(lines) == limit {
+			break
+		}
+		if match != "" && !strings.Contains(entry.Request.URL, match) {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%s %s -> %d", entry.Request.Method, entry.Request.URL, entry.Response.Status))
+	}
+
+	return lines
+}
+
+// redactHeaders redacts the values of sensitive headers.
+func redactHeaders(redactor *redact.Redactor, headers []NameValue) {
+	for i := range headers {
+		line := headers[i].Name + ": " + headers[i].Value
+		headers[i].Value = strings.TrimPrefix(redactor.Text(line), headers[i].Name+": ")
+	}
+}
+
+// redactValues replaces every value, used for cookies.
+func redactValues(values []NameValue) {
+	for i := range values {
+		values[i].Value = redact.Placeholder
+	}
+}
@@ -0,0 +1,406 @@
+package capture
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/tb0hdan/wass-mcp/pkg/types"
+)
+
+const (
+	// creatorName names the recorder in HAR documents.
+	creatorName = "wass-mcp"
+	// tunnelComment marks CONNECT entries, whose encrypted content is not recorded.
+	tunnelComment = "CONNECT tunnel, encrypted content not captured"
+	// truncatedComment marks bodies cut to types.MaxCaptureBodyBytes.
+	truncatedComment = "truncated"
+)
+
+// hopHeaders are the hop-by-hop headers not forwarded by the proxy.
+var hopHeaders = []string{
+	"Connection", "Keep-Alive", "Proxy-Authenticate", "Proxy-Authorization", "Proxy-Connection",
+	"Te", "Trailer", "Transfer-Encoding", "Upgrade",
+}
+
+// Proxy is a forward HTTP proxy on the loopback interface that records the transactions routed
+// through it. Plain HTTP requests are recorded in full, bodies cut to types.MaxCaptureBodyBytes;
+// HTTPS is tunnelled with CONNECT and recorded as the tunnel only.
+type Proxy struct {
+	dialer   net.Dialer
+	listener net.Listener
+	server   *http.Server
+	// transport forwards plain HTTP requests, ignoring the proxy environment of the server.
+	transport *http.Transport
+	// tunnels tracks the open CONNECT tunnels, closed along with the proxy.
+	tunnels sync.WaitGroup
+
+	mu      sync.Mutex
+	closed  bool
+	conns   map[net.Conn]struct{}
+	dropped int
+	entries []Entry
+}
+
+// Start starts a capture proxy on a random loopback port. tlsConfig applies to HTTPS requests
+// sent to the proxy in absolute form, nil for the defaults.
+func Start(tlsConfig *tls.Config) (*Proxy, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen: %w", err)
+	}
+
+	proxy := &Proxy{
+		dialer:   net.Dialer{Timeout: types.ProbeTimeout},
+		listener: listener,
+		transport: &http.Transport{
+			TLSClientConfig:     tlsConfig,
+			TLSHandshakeTimeout: types.ProbeTimeout,
+		},
+		conns: make(map[net.Conn]struct{}),
+	}
+	proxy.server = &http.Server{
+		Handler:           proxy,
+		ReadHeaderTimeout: types.ProbeTimeout,
+	}
+	go func() { _ = proxy.server.Serve(listener) }()
+
+	return proxy, nil
+}
+
+// URL returns the URL scanners use as their HTTP proxy.
+func (p *Proxy) URL() string {
+	return "http://" + p.listener.Addr().String()
+}
+
+// Close stops the proxy, closing open tunnels, and returns the recorded transactions.
+func (p *Proxy) Close() *HAR {
+	_ = p.server.Close()
+	p.mu.Lock()
+	p.closed = true
+	for conn := range p.conns {
+		_ = conn.Close()
+	}
+	p.mu.Unlock()
+	p.tunnels.Wait()
+	p.transport.CloseIdleConnections()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	har := &HAR{Log: Log{
+		Creator: Creator{Name: creatorName, Version: harVersion},
+		Entries: p.entries,
+		Version: harVersion,
+	}}
+	if har.Log.Entries == nil {
+		har.Log.Entries = []Entry{}
+	}
+	if p.dropped > 0 {
+		har.Log.Comment = fmt.Sprintf("%d transactions not recorded beyond the limit of %d", p.dropped, types.MaxCaptureEntries)
+	}
+
+	return har
+}
+
+// ServeHTTP forwards a proxied request and records the transaction.
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method == http.MethodConnect {
+		p.tunnel(w, req)
+		return
+	}
+	if !req.URL.IsAbs() {
+		http.Error(w, "capture proxy only accepts proxy requests", http.StatusBadRequest)
+		return
+	}
+
+	started := time.Now()
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		http.Error(w, "failed to read request body", http.StatusBadRequest)
+		return
+	}
+
+	out := req.Clone(req.Context())
+	out.RequestURI = ""
+	out.Body = io.NopCloser(bytes.NewReader(body))
+	out.ContentLength = int64(len(body))
+	removeHopHeaders(out.Header)
+
+	entry := Entry{
+		Request:         newRequest(req, body),
+		StartedDateTime: started.UTC(),
+	}
+
+	resp, err := p.transport.RoundTrip(out)
+	sent := time.Now()
+	if err != nil {
+		entry.Comment = err.Error()
+		entry.Response = Response{Cookies: []NameValue{}, Headers: []NameValue{}, HeadersSize: -1, HTTPVersion: req.Proto}
+		entry.Time = milliseconds(sent.Sub(started))
+		entry.Timings = Timings{Wait: entry.Time}
+		p.record(entry)
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+
+	removeHopHeaders(resp.Header)
+	for name, values := range resp.Header {
+		for _, value := range values {
+			w.Header().Add(name, value)
+		}
+	}
+	w.WriteHeader(resp.StatusCode)
+
+	captured := &limitedBuffer{limit: types.MaxCaptureBodyBytes}
+	_, _ = io.Copy(w, io.TeeReader(resp.Body, captured))
+
+	done := time.Now()
+	entry.Response = newResponse(resp, captured)
+	entry.Time = milliseconds(done.Sub(started))
+	entry.Timings = Timings{Wait: milliseconds(sent.Sub(started)), Receive: milliseconds(done.Sub(sent))}
+	p.record(entry)
+}
+
+// tunnel relays a CONNECT tunnel, recording its target and duration.
+func (p *Proxy) tunnel(w http.ResponseWriter, req *http.Request) {
+	started := time.Now()
+	entry := Entry{
+		Comment: tunnelComment,
+		Request: Request{
+			Cookies:     []NameValue{},
+			Headers:     headers(req.Header),
+			HeadersSize: -1,
+			HTTPVersion: req.Proto,
+			Method:      req.Method,
+			QueryString: []NameValue{},
+			URL:         "https://" + req.Host,
+		},
+		Response:        Response{Cookies: []NameValue{}, Headers: []NameValue{}, HeadersSize: -1, HTTPVersion: req.Proto},
+		StartedDateTime: started.UTC(),
+	}
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "tunnelling not supported", http.StatusInternalServerError)
+		return
+	}
+	upstream, err := p.dialer.DialContext(req.Context(), "tcp", req.Host)
+	if err != nil {
+		entry.Comment = err.Error()
+		entry.Response.Status = http.StatusBadGateway
+		entry.Response.StatusText = http.StatusText(http.StatusBadGateway)
+		p.record(entry)
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	client, _, err := hijacker.Hijack()
+	if err != nil {
+		_ = upstream.Close()
+		return
+	}
+	_, _ = client.Write([]byte("HTTP/1.1 200 Connection Established\r\n\r\n"))
+	entry.Response.Status = http.StatusOK
+	entry.Response.StatusText = "Connection Established"
+	if addr, ok := upstream.RemoteAddr().(*net.TCPAddr); ok {
+		entry.ServerIPAddress = addr.IP.String()
+	}
+
+	if !p.track(client, upstream) {
+		_ = client.Close()
+		_ = upstream.Close()
+		return
+	}
+	go func() {
+		defer p.tunnels.Done()
+		relay(client, upstream)
+		p.untrack(client, upstream)
+		entry.Time = milliseconds(time.Since(started))
+		p.record(entry)
+	}()
+}
+
+// record appends entry, dropping it beyond types.MaxCaptureEntries.
+func (p *Proxy) record(entry Entry) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.entries) >= types.MaxCaptureEntries {
+		p.dropped++
+		return
+	}
+	p.entries = append(p.entries, entry)
+}
+
+// track registers the connections of a new tunnel so that Close can end them. It reports false
+// once the proxy is closed.
+func (p *Proxy) track(conns ...net.Conn) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return false
+	}
+	for _, conn := range conns {
+		p.conns[conn] = struct{}{}
+	}
+	p.tunnels.Add(1)
+
+	return true
+}
+
+// untrack forgets closed tunnel connections.
+func (p *Proxy) untrack(conns ...net.Conn) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, conn := range conns {
+		delete(p.conns, conn)
+	}
+}
+
+// relay copies data both ways until either side closes, then closes both.
+func relay(client, upstream net.Conn) {
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		_, _ = io.Copy(upstream, client)
+		_ = upstream.Close()
+	}()
+	go func() {
+		defer wg.Done()
+		_, _ = io.Copy(client, upstream)
+		_ = client.Close()
+	}()
+	wg.Wait()
+}
+
+// newRequest returns the HAR request of req with body.
+func newRequest(req *http.Request, body []byte) Request {
+	request := Request{
+		BodySize:    len(body),
+		Cookies:     []NameValue{},
+		Headers:     headers(req.Header),
+		HeadersSize: -1,
+		HTTPVersion: req.Proto,
+		Method:      req.Method,
+		QueryString: []NameValue{},
+		URL:         req.URL.String(),
+	}
+	for _, cookie := range req.Cookies() {
+		request.Cookies = append(request.Cookies, NameValue{Name: cookie.Name, Value: cookie.Value})
+	}
+	for name, values := range req.URL.Query() {
+		for _, value := range values {
+			request.QueryString = append(request.QueryString, NameValue{Name: name, Value: value})
+		}
+	}
+	if len(body) > 0 {
+		text, _, truncated := bodyText(body, types.MaxCaptureBodyBytes)
+		request.PostData = &PostData{MimeType: req.Header.Get("Content-Type"), Text: text}
+		if truncated {
+			request.PostData.Comment = truncatedComment
+		}
+	}
+
+	return request
+}
+
+// newResponse returns the HAR response of resp with the captured body.
+func newResponse(resp *http.Response, body *limitedBuffer) Response {
+	response := Response{
+		BodySize:    body.total,
+		Cookies:     []NameValue{},
+		Headers:     headers(resp.Header),
+		HeadersSize: -1,
+		HTTPVersion: resp.Proto,
+		RedirectURL: resp.Header.Get("Location"),
+		Status:      resp.StatusCode,
+		StatusText:  strings.TrimSpace(strings.TrimPrefix(resp.Status, fmt.Sprint(resp.StatusCode))),
+	}
+	for _, cookie := range resp.Cookies() {
+		response.Cookies = append(response.Cookies, NameValue{Name: cookie.Name, Value: cookie.Value})
+	}
+
+	text, encoding, _ := bodyText(body.Bytes(), types.MaxCaptureBodyBytes)
+	response.Content = Content{
+		Encoding: encoding,
+		MimeType: resp.Header.Get("Content-Type"),
+		Size:     body.total,
+		Text:     text,
+	}
+	if body.total > body.Len() {
+		response.Content.Comment = truncatedComment
+	}
+
+	return response
+}
+
+// bodyText returns body cut to limit as text, base64-encoded when it is not valid UTF-8, with its
+// encoding and whether it was cut.
+func bodyText(body []byte, limit int) (string, string, bool) {
+	truncated := len(body) > limit
+	if truncated {
+		body = body[:limit]
+	}
+	if utf8.Valid(body) {
+		return string(body), "", truncated
+	}
+
+	return base64.StdEncoding.EncodeToString(body), "base64", truncated
+}
+
+// headers returns the HAR headers of header.
+func headers(header http.Header) []NameValue {
+	list := make([]NameValue, 0, len(header))
+	for name, values := range header {
+		for _, value := range values {
+			list = append(list, NameValue{Name: name, Value: value})
+		}
+	}
+
+	return list
+}
+
+// removeHopHeaders removes the hop-by-hop headers of header.
+func removeHopHeaders(header http.Header) {
+	for _, name := range hopHeaders {
+		header.Del(name)
+	}
+}
+
+// milliseconds returns d in milliseconds.
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// limitedBuffer keeps the first limit bytes written to it and counts them all.
+type limitedBuffer struct {
+	bytes.Buffer
+	limit int
+	total int
+}
+
+// Write keeps what fits within the limit and never fails.
+func (b *limitedBuffer) Write(data []byte) (int, error) {
+	b.total += len(data)
+	if room := b.limit - b.Len(); room > 0 {
+		if len(data) > room {
+			b.Buffer.Write(data[:room])
+		} else {
+			b.Buffer.Write(data)
+		}
+	}
+
+	return len(data), nil
+}
@@ -0,0 +1,146 @@
+package capture
+
+import (
+	"crypto/tls"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/redact"
+	"github.com/tb0hdan/wass-mcp/pkg/types"
+)
+
+type ProxyTestSuite struct {
+	suite.Suite
+	proxy  *Proxy
+	target *httptest.Server
+}
+
+func (s *ProxyTestSuite) SetupTest() {
+	s.target = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "s3cret"})
+		w.Header().Set("Content-Type", "text/plain")
+		switch r.URL.Path {
+		case "/large":
+			_, _ = w.Write([]byte(strings.Repeat("x", types.MaxCaptureBodyBytes+100)))
+		case "/binary":
+			_, _ = w.Write([]byte{0xff, 0xfe, 0x00})
+		default:
+			_, _ = w.Write([]byte("echo:" + string(body)))
+		}
+	}))
+
+	var err error
+	s.proxy, err = Start(nil)
+	s.Require().NoError(err)
+}
+
+func (s *ProxyTestSuite) TearDownTest() {
+	s.target.Close()
+}
+
+// client returns an HTTP client routed through the proxy.
+func (s *ProxyTestSuite) client() *http.Client {
+	proxyURL, err := url.Parse(s.proxy.URL())
+	s.Require().NoError(err)
+
+	return &http.Client{Transport: &http.Transport{
+		Proxy:           http.ProxyURL(proxyURL),
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, //nolint:gosec
+	}}
+}
+
+func (s *ProxyTestSuite) TestRecordsHTTPTransactions() {
+	client := s.client()
+
+	req, err := http.NewRequest(http.MethodPost, s.target.URL+"/login?next=%2Fadmin", strings.NewReader("user=admin"))
+	s.Require().NoError(err)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "Bearer abc")
+	resp, err := client.Do(req)
+	s.Require().NoError(err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	s.Equal("echo:user=admin", string(body))
+
+	for _, path := range []string{"/large", "/binary"} {
+		resp, err = client.Get(s.target.URL + path)
+		s.Require().NoError(err)
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+
+	har := s.proxy.Close()
+	s.Equal("1.2", har.Log.Version)
+	s.Require().Len(har.Log.Entries, 3)
+
+	login := har.Log.Entries[0]
+	s.Equal(http.MethodPost, login.Request.Method)
+	s.Equal(s.target.URL+"/login?next=%2Fadmin", login.Request.URL)
+	s.Equal([]NameValue{{Name: "next", Value: "/admin"}}, login.Request.QueryString)
+	s.Require().NotNil(login.Request.PostData)
+	s.Equal("user=admin", login.Request.PostData.Text)
+	s.Equal(http.StatusOK, login.Response.Status)
+	s.Equal("echo:user=admin", login.Response.Content.Text)
+	s.Equal([]NameValue{{Name: "session", Value: "s3cret"}}, login.Response.Cookies)
+
+	large := har.Log.Entries[1].Response.Content
+	s.Equal(types.MaxCaptureBodyBytes+100, large.Size)
+	s.Len(large.Text, types.MaxCaptureBodyBytes)
+	s.Equal(truncatedComment, large.Comment)
+
+	s.Equal("base64", har.Log.Entries[2].Response.Content.Encoding)
+	s.Equal("//4A", har.Log.Entries[2].Response.Content.Text)
+
+	s.Equal([]string{"POST " + s.target.URL + "/login?next=%2Fadmin -> 200"}, har.Transactions("/login", 5))
+	s.Len(har.Transactions("", 2), 2)
+
+	har.Redact(redact.Default())
+	for _, header := range har.Log.Entries[0].Request.Headers {
+		if header.Name == "Authorization" {
+			s.Equal(redact.Placeholder, header.Value)
+		}
+	}
+	s.Equal(redact.Placeholder, har.Log.Entries[0].Response.Cookies[0].Value)
+}
+
+func (s *ProxyTestSuite) TestRecordsTunnels() {
+	secure := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("secure"))
+	}))
+	defer secure.Close()
+
+	resp, err := s.client().Get(secure.URL)
+	s.Require().NoError(err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	s.Equal("secure", string(body))
+
+	har := s.proxy.Close()
+	s.Require().Len(har.Log.Entries, 1)
+	entry := har.Log.Entries[0]
+	s.Equal(http.MethodConnect, entry.Request.Method)
+	s.Equal("https://"+strings.TrimPrefix(secure.URL, "https://"), entry.Request.URL)
+	s.Equal(http.StatusOK, entry.Response.Status)
+	s.Equal(tunnelComment, entry.Comment)
+}
+
+func (s *ProxyTestSuite) TestRejectsDirectRequests() {
+	resp, err := http.Get(s.proxy.URL() + "/")
+	s.Require().NoError(err)
+	resp.Body.Close()
+	s.Equal(http.StatusBadRequest, resp.StatusCode)
+
+	har := s.proxy.Close()
+	s.Empty(har.Log.Entries)
+	s.NotNil(har.Log.Entries)
+}
+
+func TestProxyTestSuite(t *testing.T) {
+	suite.Run(t, new(ProxyTestSuite))
+}
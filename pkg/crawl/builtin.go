@@ -0,0 +1,186 @@
+package crawl
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/tb0hdan/wass-mcp/pkg/httpclient"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+	"github.com/tb0hdan/wass-mcp/pkg/types"
+	"github.com/tb0hdan/wass-mcp/pkg/vault"
+)
+
+const (
+	builtinName = "builtin"
+	// maxPageBytes bounds the body read per crawled page.
+	maxPageBytes = 1 << 20
+)
+
+// linkPattern matches the link attributes of HTML elements: anchors, forms, frames and scripts.
+var linkPattern = regexp.MustCompile(`(?i)\b(?:href|src|action)\s*=\s*["']([^"'<>]+)["']`)
+
+// staticExtensions are the extensions of resources listed but not fetched, as they hold no links.
+var staticExtensions = []string{
+	".css", ".eot", ".gif", ".ico", ".jpeg", ".jpg", ".mp4", ".pdf", ".png", ".svg", ".ttf", ".webp",
+	".woff", ".woff2", ".zip",
+}
+
+// Builtin crawls with the HTTP client of the server, following the links of HTML pages
+// breadth-first. It needs no binary, so it is always available.
+type Builtin struct{}
+
+// NewBuiltin creates a built-in crawl engine.
+func NewBuiltin() *Builtin {
+	return &Builtin{}
+}
+
+// Name returns the engine name.
+func (b *Builtin) Name() string {
+	return builtinName
+}
+
+// IsAvailable reports that the built-in crawler is always available.
+func (b *Builtin) IsAvailable() bool {
+	return true
+}
+
+// page is a crawled page and its distance in links from the start page.
+type page struct {
+	depth int
+	url   string
+}
+
+// Crawl fetches the start page of the target of params and the same-origin pages it links to, up
+// to limits.Depth links away, and returns the URLs found. Only the start page must be reachable.
+func (b *Builtin) Crawl(ctx context.Context, params tools.ScanParams, limits Limits) ([]string, error) {
+	if err := params.CheckCredential(builtinName, vault.HeaderTypes...); err != nil {
+		return nil, err
+	}
+	tlsConfig, err := tools.TLSConfig(params)
+	if err != nil {
+		return nil, err
+	}
+	proxy := http.ProxyFromEnvironment
+	if params.Proxy != "" {
+		proxyURL, err := url.Parse(params.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy: %w", err)
+		}
+		proxy = http.ProxyURL(proxyURL)
+	}
+	client := httpclient.New(httpclient.Config{
+		Proxy:   proxy,
+		Scope:   []string{params.Host},
+		TLS:     tlsConfig,
+		Timeout: types.ProbeTimeout,
+	})
+	defer client.CloseIdleConnections()
+
+	start := params.Target().RequestURL()
+	origin, err := url.Parse(start)
+	if err != nil {
+		return nil, fmt.Errorf("invalid target: %w", err)
+	}
+
+	found := []string{start}
+	queue := []page{{url: start}}
+	for len(queue) > 0 && len(found) < limits.MaxURLs && ctx.Err() == nil {
+		current := queue[0]
+		queue = queue[1:]
+
+		body, err := b.fetch(ctx, client, params, current.url)
+		if err != nil {
+			if current.url == start {
+				return nil, err
+			}
+			continue
+		}
+		if current.depth >= limits.Depth {
+			continue
+		}
+		for _, link := range links(origin, current.url, body) {
+			if slices.Contains(found, link) {
+				continue
+			}
+			found = append(found, link)
+			if !isStatic(link) {
+				queue = append(queue, page{depth: current.depth + 1, url: link})
+			}
+		}
+	}
+
+	return found, nil
+}
+
+// fetch returns the body of the HTML page at pageURL, empty for other content types.
+func (b *Builtin) fetch(ctx context.Context, client *http.Client, params tools.ScanParams, pageURL string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	if params.Vhost != "" {
+		req.Host = params.Vhost
+	}
+	if userAgent := params.Option(tools.OptionUserAgent); userAgent != "" {
+		req.Header.Set("User-Agent", userAgent)
+	}
+	if params.Credential != nil {
+		for _, header := range params.Credential.Headers() {
+			name, value, _ := strings.Cut(header, ":")
+			req.Header.Add(strings.TrimSpace(name), strings.TrimSpace(value))
+		}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch %s: %w", pageURL, err)
+	}
+	defer resp.Body.Close()
+	if !strings.Contains(resp.Header.Get("Content-Type"), "html") {
+		return "", nil
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxPageBytes))
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", pageURL, err)
+	}
+
+	return string(body), nil
+}
+
+// links returns the same-origin URLs linked from the page at pageURL, resolved against it.
+func links(origin *url.URL, pageURL, body string) []string {
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return nil
+	}
+
+	var found []string
+	for _, match := range linkPattern.FindAllStringSubmatch(body, -1) {
+		resolved, err := base.Parse(strings.TrimSpace(match[1]))
+		if err != nil {
+			continue
+		}
+		if link, ok := normalize(origin, resolved.String()); ok && !slices.Contains(found, link) {
+			found = append(found, link)
+		}
+	}
+
+	return found
+}
+
+// isStatic reports whether link is a static resource, see staticExtensions.
+func isStatic(link string) bool {
+	parsed, err := url.Parse(link)
+	if err != nil {
+		return false
+	}
+
+	return slices.Contains(staticExtensions, strings.ToLower(path.Ext(parsed.Path)))
+}
@@ -0,0 +1,181 @@
+// Package crawl enumerates the URLs of a web application before it is scanned, so that scanners
+// taking a URL list test pages they would not reach from the start page alone.
+package crawl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/tb0hdan/wass-mcp/pkg/bundle"
+	"github.com/tb0hdan/wass-mcp/pkg/target"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+)
+
+const (
+	// DefaultDepth is the number of links followed from the start page when none is requested.
+	DefaultDepth = 2
+	// DefaultMaxURLs bounds the URLs returned when no bound is requested.
+	DefaultMaxURLs = 200
+	// MaxDepth is the deepest crawl that can be requested.
+	MaxDepth = 10
+	// MaxURLs is the largest URL list that can be requested.
+	MaxURLs = 1000
+)
+
+var (
+	// ErrNoEngine is returned when no crawl engine is available.
+	ErrNoEngine = errors.New("no crawl engine available")
+	// ErrUnknownEngine is returned when requesting an engine that does not exist or is not available.
+	ErrUnknownEngine = errors.New("unknown or unavailable crawl engine")
+)
+
+// Engine crawls a target for the URLs it links to.
+type Engine interface {
+	// Name returns the engine name.
+	Name() string
+	// IsAvailable checks if the engine can run, e.g. that its binary is installed.
+	IsAvailable() bool
+	// Crawl returns the URLs found following links from the target of params, up to limits.
+	Crawl(ctx context.Context, params tools.ScanParams, limits Limits) ([]string, error)
+}
+
+// Limits bound a crawl.
+type Limits struct {
+	// Depth is the number of links followed from the start page, DefaultDepth when zero.
+	Depth int
+	// MaxURLs bounds the URLs returned, DefaultMaxURLs when zero.
+	MaxURLs int
+}
+
+// withDefaults returns the limits with the defaults of unset bounds applied.
+func (l Limits) withDefaults() Limits {
+	if l.Depth <= 0 {
+		l.Depth = DefaultDepth
+	}
+	if l.MaxURLs <= 0 {
+		l.MaxURLs = DefaultMaxURLs
+	}
+
+	return l
+}
+
+// Result is the outcome of a crawl.
+type Result struct {
+	// Engine is the name of the engine used.
+	Engine string
+	// Truncated reports whether URLs were left out to respect Limits.MaxURLs.
+	Truncated bool
+	// URLs are the crawled URLs of the target, the start URL first and the others sorted.
+	URLs []string
+}
+
+// Crawler crawls targets with the first available of its engines, or a named one.
+type Crawler struct {
+	// Engines are tried in order; the first available one is used.
+	Engines []Engine
+}
+
+// binaryPath returns the path of the crawler binary name, preferring a bundled binary over PATH,
+// or the name itself when it cannot be found.
+func binaryPath(name string) string {
+	path, _, err := bundle.LookPath(name)
+	if err != nil {
+		return name
+	}
+	return path
+}
+
+// New creates a Crawler using katana, falling back to the built-in crawler.
+func New() *Crawler {
+	return &Crawler{Engines: []Engine{NewKatana(), NewBuiltin()}}
+}
+
+// Names returns the names of the engines.
+func (c *Crawler) Names() []string {
+	names := make([]string, 0, len(c.Engines))
+	for _, engine := range c.Engines {
+		names = append(names, engine.Name())
+	}
+
+	return names
+}
+
+// engine returns the named engine, or the first available one when name is empty.
+func (c *Crawler) engine(name string) (Engine, error) {
+	for _, engine := range c.Engines {
+		if (name == "" || engine.Name() == name) && engine.IsAvailable() {
+			return engine, nil
+		}
+	}
+	if name != "" {
+		return nil, fmt.Errorf("%w: %s (engines: %s)", ErrUnknownEngine, name, strings.Join(c.Names(), ", "))
+	}
+
+	return nil, ErrNoEngine
+}
+
+// Crawl crawls the target of params with the named engine, or the first available one when name is
+// empty. Only the URLs of the target origin are kept, so that scans of the list stay on the target.
+func (c *Crawler) Crawl(ctx context.Context, name string, params tools.ScanParams, limits Limits) (Result, error) {
+	engine, err := c.engine(name)
+	if err != nil {
+		return Result{}, err
+	}
+	limits = limits.withDefaults()
+
+	found, err := engine.Crawl(ctx, params, limits)
+	if err != nil {
+		return Result{Engine: engine.Name()}, fmt.Errorf("%s crawl failed: %w", engine.Name(), err)
+	}
+	urls, truncated := sameOrigin(params.Target(), found, limits.MaxURLs)
+
+	return Result{Engine: engine.Name(), Truncated: truncated, URLs: urls}, nil
+}
+
+// sameOrigin returns the URLs of found on the origin of start without fragments or duplicates,
+// the start URL first and the others sorted, at most maxURLs of them. It also reports whether URLs
+// were left out to respect maxURLs.
+func sameOrigin(start target.Target, found []string, maxURLs int) ([]string, bool) {
+	startURL := start.RequestURL()
+	origin, err := url.Parse(startURL)
+	if err != nil {
+		return nil, false
+	}
+
+	var urls []string
+	for _, raw := range found {
+		normalized, ok := normalize(origin, raw)
+		if !ok || normalized == startURL || slices.Contains(urls, normalized) {
+			continue
+		}
+		urls = append(urls, normalized)
+	}
+	slices.Sort(urls)
+	urls = append([]string{startURL}, urls...)
+	if len(urls) > maxURLs {
+		return urls[:maxURLs], true
+	}
+
+	return urls, false
+}
+
+// normalize resolves raw against origin and returns it without fragment, when it is an http(s)
+// URL of the same scheme, host and port as origin.
+func normalize(origin *url.URL, raw string) (string, bool) {
+	parsed, err := origin.Parse(strings.TrimSpace(raw))
+	if err != nil || parsed.Scheme != origin.Scheme || !strings.EqualFold(parsed.Host, origin.Host) {
+		return "", false
+	}
+	parsed.Fragment = ""
+	parsed.RawFragment = ""
+	parsed.Host = origin.Host
+	if parsed.Path == "" {
+		parsed.Path = "/"
+	}
+
+	return parsed.String(), true
+}
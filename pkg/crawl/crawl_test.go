@@ -0,0 +1,170 @@
+package crawl
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/target"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+	"github.com/tb0hdan/wass-mcp/pkg/vault"
+)
+
+// fakeEngine is an Engine returning fixed URLs.
+type fakeEngine struct {
+	available bool
+	err       error
+	limits    Limits
+	name      string
+	urls      []string
+}
+
+func (f *fakeEngine) Name() string { return f.name }
+
+func (f *fakeEngine) IsAvailable() bool { return f.available }
+
+func (f *fakeEngine) Crawl(_ context.Context, _ tools.ScanParams, limits Limits) ([]string, error) {
+	f.limits = limits
+	return f.urls, f.err
+}
+
+type CrawlTestSuite struct {
+	suite.Suite
+}
+
+// siteParams returns the scan parameters of an HTTP test server.
+func (s *CrawlTestSuite) siteParams(server *httptest.Server) tools.ScanParams {
+	parsed, err := url.Parse(server.URL)
+	s.Require().NoError(err)
+	port, err := strconv.Atoi(parsed.Port())
+	s.Require().NoError(err)
+
+	return tools.ScanParams{Host: parsed.Hostname(), Port: port, Scheme: "http"}
+}
+
+func (s *CrawlTestSuite) TestCrawl_UsesFirstAvailableEngine() {
+	katana := &fakeEngine{name: "katana"}
+	builtin := &fakeEngine{name: "builtin", available: true, urls: []string{
+		"http://example.com/b#top", "http://example.com/a", "http://example.com/a", "https://example.com/tls",
+		"http://other.example/", "http://example.com:8080/port", "/relative",
+	}}
+	crawler := &Crawler{Engines: []Engine{katana, builtin}}
+
+	result, err := crawler.Crawl(context.Background(), "", tools.ScanParams{Host: "example.com", Port: 80, Scheme: "http"}, Limits{})
+	s.Require().NoError(err)
+	s.Equal("builtin", result.Engine)
+	s.Equal([]string{"http://example.com/", "http://example.com/a", "http://example.com/b", "http://example.com/relative"}, result.URLs,
+		"only same-origin URLs are kept, the start URL first")
+	s.False(result.Truncated)
+	s.Equal(Limits{Depth: DefaultDepth, MaxURLs: DefaultMaxURLs}, builtin.limits)
+}
+
+func (s *CrawlTestSuite) TestCrawl_NamedEngine() {
+	katana := &fakeEngine{name: "katana"}
+	crawler := &Crawler{Engines: []Engine{katana, &fakeEngine{name: "builtin", available: true}}}
+
+	_, err := crawler.Crawl(context.Background(), "katana", tools.ScanParams{Host: "example.com"}, Limits{})
+	s.ErrorIs(err, ErrUnknownEngine, "unavailable engines cannot be requested")
+	_, err = crawler.Crawl(context.Background(), "gospider", tools.ScanParams{Host: "example.com"}, Limits{})
+	s.ErrorIs(err, ErrUnknownEngine)
+
+	katana.available = true
+	result, err := crawler.Crawl(context.Background(), "katana", tools.ScanParams{Host: "example.com"}, Limits{})
+	s.Require().NoError(err)
+	s.Equal("katana", result.Engine)
+}
+
+func (s *CrawlTestSuite) TestCrawl_Errors() {
+	_, err := (&Crawler{Engines: []Engine{&fakeEngine{name: "katana"}}}).Crawl(context.Background(), "", tools.ScanParams{}, Limits{})
+	s.ErrorIs(err, ErrNoEngine)
+
+	failing := &fakeEngine{name: "katana", available: true, err: errors.New("boom")}
+	result, err := (&Crawler{Engines: []Engine{failing}}).Crawl(context.Background(), "", tools.ScanParams{Host: "example.com"}, Limits{})
+	s.Require().Error(err)
+	s.Contains(err.Error(), "katana crawl failed")
+	s.Equal("katana", result.Engine)
+}
+
+func (s *CrawlTestSuite) TestSameOrigin_Truncates() {
+	start := target.Target{Host: "example.com", Port: 443, Scheme: "https", Path: "/app"}
+	urls, truncated := sameOrigin(start, []string{"/app/c", "/app/b", "/app/a"}, 3)
+	s.True(truncated)
+	s.Equal([]string{"https://example.com/app", "https://example.com/app/a", "https://example.com/app/b"}, urls)
+}
+
+func (s *CrawlTestSuite) TestBuiltin_Crawl() {
+	var userAgents []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		userAgents = append(userAgents, r.UserAgent())
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<a href="/about">About</a> <a href='/login#form'>Login</a>
+			<img src="/logo.png"> <a href="https://other.example/">Out</a> <a href="mailto:admin@example.com">Mail</a>`))
+	})
+	mux.HandleFunc("/about", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(`<form action="contact.php" method="post"></form>`))
+	})
+	mux.HandleFunc("/contact.php", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<a href="/deep">Too deep</a>`))
+	})
+	mux.HandleFunc("/logo.png", func(http.ResponseWriter, *http.Request) {
+		s.Fail("static resources are not fetched")
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	params := s.siteParams(server)
+	params.Options = map[string]string{tools.OptionUserAgent: "wass-crawler"}
+
+	found, err := NewBuiltin().Crawl(context.Background(), params, Limits{Depth: 2, MaxURLs: 100})
+	s.Require().NoError(err)
+	base := server.URL
+	s.ElementsMatch([]string{base + "/", base + "/about", base + "/login", base + "/logo.png", base + "/contact.php"}, found)
+	s.Contains(userAgents, "wass-crawler")
+}
+
+func (s *CrawlTestSuite) TestBuiltin_StartPageUnreachable() {
+	server := httptest.NewServer(http.NotFoundHandler())
+	params := s.siteParams(server)
+	server.Close()
+
+	_, err := NewBuiltin().Crawl(context.Background(), params, Limits{Depth: 1, MaxURLs: 10})
+	s.Error(err)
+}
+
+func (s *CrawlTestSuite) TestBuiltin_UnsupportedCredential() {
+	params := tools.ScanParams{Host: "example.com", Credential: &vault.Credential{Type: vault.TypeLoginForm}}
+
+	_, err := NewBuiltin().Crawl(context.Background(), params, Limits{Depth: 1, MaxURLs: 10})
+	s.ErrorIs(err, vault.ErrUnsupportedType)
+}
+
+func (s *CrawlTestSuite) TestKatanaArgs() {
+	params := tools.ScanParams{
+		Host: "example.com", Port: 443, Scheme: "https", Vhost: "app.example.com", Proxy: "http://127.0.0.1:8081",
+		Options:    map[string]string{tools.OptionUserAgent: "wass"},
+		Credential: &vault.Credential{Type: vault.TypeBearer, Secret: vault.Secret{Token: "secret"}},
+	}
+
+	s.Equal([]string{
+		"-u", "https://example.com/", "-d", "3", "-fs", "fqdn", "-jc", "-silent", "-nc",
+		"-H", "Host: app.example.com", "-H", "User-Agent: wass", "-H", "Authorization: Bearer secret",
+		"-proxy", "http://127.0.0.1:8081",
+	}, katanaArgs(params, Limits{Depth: 3}))
+}
+
+func (s *CrawlTestSuite) TestParseKatana() {
+	output := "[INF] Started crawling\nhttps://example.com/\n  https://example.com/login  \nnot a url\n"
+
+	s.Equal([]string{"https://example.com/", "https://example.com/login"}, parseKatana(output))
+}
+
+func TestCrawlTestSuite(t *testing.T) {
+	suite.Run(t, new(CrawlTestSuite))
+}
@@ -0,0 +1,82 @@
+package crawl
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/tb0hdan/wass-mcp/pkg/bundle"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+)
+
+const katanaBinary = "katana"
+
+// Katana crawls with katana, which also parses JavaScript files for endpoints.
+type Katana struct{}
+
+// NewKatana creates a katana crawl engine.
+func NewKatana() *Katana {
+	return &Katana{}
+}
+
+// Name returns the engine name.
+func (k *Katana) Name() string {
+	return katanaBinary
+}
+
+// IsAvailable checks if the katana binary is bundled or available in PATH.
+func (k *Katana) IsAvailable() bool {
+	_, _, err := bundle.LookPath(katanaBinary)
+	return err == nil
+}
+
+// Crawl runs katana against the target of params and returns the URLs it printed.
+func (k *Katana) Crawl(ctx context.Context, params tools.ScanParams, limits Limits) ([]string, error) {
+	cmd := exec.CommandContext(ctx, binaryPath(katanaBinary), katanaArgs(params, limits)...) //nolint:gosec
+	cmd.Env = append(os.Environ(), tools.TLSEnv(params)...)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute katana: %w", err)
+	}
+
+	return parseKatana(string(output)), nil
+}
+
+// katanaArgs builds the katana command line arguments, crawling the host of the target only.
+func katanaArgs(params tools.ScanParams, limits Limits) []string {
+	args := []string{
+		"-u", params.Target().RequestURL(), "-d", strconv.Itoa(limits.Depth), "-fs", "fqdn", "-jc", "-silent", "-nc",
+	}
+	if header := params.Target().HostHeader(); header != "" {
+		args = append(args, "-H", header)
+	}
+	if userAgent := params.Option(tools.OptionUserAgent); userAgent != "" {
+		args = append(args, "-H", "User-Agent: "+userAgent)
+	}
+	if params.Credential != nil {
+		for _, header := range params.Credential.Headers() {
+			args = append(args, "-H", header)
+		}
+	}
+	if params.Proxy != "" {
+		args = append(args, "-proxy", params.Proxy)
+	}
+
+	return args
+}
+
+// parseKatana parses katana silent output, one URL per line.
+func parseKatana(output string) []string {
+	var urls []string
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "http://") || strings.HasPrefix(line, "https://") {
+			urls = append(urls, line)
+		}
+	}
+
+	return urls
+}
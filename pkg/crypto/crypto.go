@@ -0,0 +1,264 @@
+// Package crypto encrypts stored secrets with envelope encryption: every value is encrypted with
+// its own random data key, which is in turn encrypted ("wrapped") with a master key of a Keyring.
+// Master keys are rotated by adding a new primary key and rewrapping the stored data keys, without
+// re-encrypting the values themselves.
+package crypto
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// KeyEnv is the environment variable holding the master keys when no key file is set.
+const KeyEnv = "WASS_ENCRYPTION_KEY"
+
+// KeySize is the size of master and data keys in bytes, AES-256 keys.
+const KeySize = 32
+
+// version is the format version of sealed values.
+const version = 1
+
+// keyIDSize is the size of master key IDs in bytes, see KeyID.
+const keyIDSize = 8
+
+var (
+	// ErrUnknownKey is returned when opening values sealed with a master key the keyring lacks.
+	ErrUnknownKey = errors.New("value was sealed with an unknown master key")
+	// ErrMalformed is returned for sealed values that were not produced by Keyring.Seal.
+	ErrMalformed = errors.New("malformed sealed value")
+)
+
+// Keyring holds the master keys. The primary key seals new values, while every key opens the
+// values sealed with it.
+type Keyring struct {
+	keys    map[string]cipher.AEAD
+	primary string
+}
+
+// KeyID returns the ID of the master key key, a hex-encoded prefix of its SHA-256 digest.
+func KeyID(key []byte) string {
+	digest := sha256.Sum256(key)
+	return hex.EncodeToString(digest[:keyIDSize])
+}
+
+// NewKeyring creates a keyring of keys, each KeySize bytes long. The first key is the primary key.
+func NewKeyring(keys ...[]byte) (*Keyring, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("keyring requires at least one master key")
+	}
+
+	keyring := &Keyring{keys: make(map[string]cipher.AEAD, len(keys)), primary: KeyID(keys[0])}
+	for i, key := range keys {
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, fmt.Errorf("master key %d: %w", i+1, err)
+		}
+		keyring.keys[KeyID(key)] = aead
+	}
+
+	return keyring, nil
+}
+
+// ParseKeys parses base64-encoded master keys separated by commas or whitespace, the primary key
+// first.
+func ParseKeys(encoded string) ([][]byte, error) {
+	fields := strings.FieldsFunc(encoded, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n' || r == '\r'
+	})
+
+	keys := make([][]byte, 0, len(fields))
+	for i, field := range fields {
+		key, err := base64.StdEncoding.DecodeString(field)
+		if err != nil {
+			return nil, fmt.Errorf("master key %d is not valid base64: %w", i+1, err)
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// LoadKeyring loads the master keys from keyFile, one per line, or from the KeyEnv environment
+// variable when keyFile is empty. It returns a nil keyring when neither holds a key.
+func LoadKeyring(keyFile string) (*Keyring, error) {
+	encoded := os.Getenv(KeyEnv)
+	if keyFile != "" {
+		data, err := os.ReadFile(keyFile) //nolint:gosec
+		if err != nil {
+			return nil, fmt.Errorf("failed to read encryption keys: %w", err)
+		}
+		encoded = string(data)
+	}
+
+	keys, err := ParseKeys(encoded)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	return NewKeyring(keys...)
+}
+
+// Primary returns the ID of the primary key.
+func (k *Keyring) Primary() string {
+	return k.primary
+}
+
+// Seal encrypts plaintext with a new data key wrapped by the primary key. The sealed value is the
+// format version, the master key ID, the wrapped data key and the encrypted plaintext, each
+// encryption prefixed with its random nonce.
+func (k *Keyring) Seal(plaintext []byte) ([]byte, error) {
+	dataKey := make([]byte, KeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, fmt.Errorf("failed to generate data key: %w", err)
+	}
+	data, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	sealed, err := k.wrap(k.primary, dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return seal(data, sealed, plaintext, nil)
+}
+
+// Open decrypts a value sealed by Seal with any key of the keyring.
+func (k *Keyring) Open(sealed []byte) ([]byte, error) {
+	dataKey, rest, err := k.unwrap(sealed)
+	if err != nil {
+		return nil, err
+	}
+	data, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return open(data, rest, nil)
+}
+
+// Rewrap wraps the data key of a value sealed by Seal with the primary key, leaving the encrypted
+// value itself as is. It reports whether the value changed, false for values already sealed with
+// the primary key.
+func (k *Keyring) Rewrap(sealed []byte) ([]byte, bool, error) {
+	keyID, err := SealedKeyID(sealed)
+	if err != nil {
+		return nil, false, err
+	}
+	if keyID == k.primary {
+		return sealed, false, nil
+	}
+
+	dataKey, rest, err := k.unwrap(sealed)
+	if err != nil {
+		return nil, false, err
+	}
+	rewrapped, err := k.wrap(k.primary, dataKey)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return append(rewrapped, rest...), true, nil
+}
+
+// SealedKeyID returns the ID of the master key a value was sealed with.
+func SealedKeyID(sealed []byte) (string, error) {
+	if len(sealed) < 1+keyIDSize || sealed[0] != version {
+		return "", ErrMalformed
+	}
+
+	return hex.EncodeToString(sealed[1 : 1+keyIDSize]), nil
+}
+
+// wrap returns the header of a sealed value: the format version, the ID of the master key and the
+// data key encrypted with it. The header before the data key is authenticated with it.
+func (k *Keyring) wrap(keyID string, dataKey []byte) ([]byte, error) {
+	rawID, err := hex.DecodeString(keyID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid master key ID %s: %w", keyID, err)
+	}
+
+	header := append([]byte{version}, rawID...)
+	return seal(k.keys[keyID], header, dataKey, header)
+}
+
+// unwrap decrypts the data key of sealed and returns it with the encrypted value that follows.
+func (k *Keyring) unwrap(sealed []byte) ([]byte, []byte, error) {
+	keyID, err := SealedKeyID(sealed)
+	if err != nil {
+		return nil, nil, err
+	}
+	master, ok := k.keys[keyID]
+	if !ok {
+		return nil, nil, fmt.Errorf("%w %s", ErrUnknownKey, keyID)
+	}
+
+	header := sealed[:1+keyIDSize]
+	wrappedSize := master.NonceSize() + KeySize + master.Overhead()
+	if len(sealed) < len(header)+wrappedSize {
+		return nil, nil, ErrMalformed
+	}
+	wrapped := sealed[len(header) : len(header)+wrappedSize]
+	dataKey, err := open(master, wrapped, header)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to unwrap data key: %w", err)
+	}
+
+	return dataKey, sealed[len(header)+wrappedSize:], nil
+}
+
+// newAEAD returns the AES-256-GCM cipher of key.
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("key must be %d bytes, got %d", KeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	return aead, nil
+}
+
+// seal appends plaintext encrypted with aead and authenticated with additionalData, prefixed
+// with its nonce, to dst.
+func seal(aead cipher.AEAD, dst, plaintext, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	dst = append(bytes.Clone(dst), nonce...)
+
+	return aead.Seal(dst, nonce, plaintext, additionalData), nil
+}
+
+// open decrypts a nonce-prefixed ciphertext produced by seal.
+func open(aead cipher.AEAD, ciphertext, additionalData []byte) ([]byte, error) {
+	size := aead.NonceSize()
+	if len(ciphertext) < size+aead.Overhead() {
+		return nil, ErrMalformed
+	}
+	plaintext, err := aead.Open(nil, ciphertext[:size], ciphertext[size:], additionalData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt: %w", err)
+	}
+
+	return plaintext, nil
+}
@@ -0,0 +1,143 @@
+package crypto
+
+import (
+	"bytes"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type CryptoTestSuite struct {
+	suite.Suite
+	oldKey []byte
+	newKey []byte
+}
+
+func (s *CryptoTestSuite) SetupTest() {
+	s.oldKey = bytes.Repeat([]byte{1}, KeySize)
+	s.newKey = bytes.Repeat([]byte{2}, KeySize)
+}
+
+func (s *CryptoTestSuite) TestSealOpen() {
+	keyring, err := NewKeyring(s.oldKey)
+	s.Require().NoError(err)
+
+	sealed, err := keyring.Seal([]byte("hunter2"))
+	s.Require().NoError(err)
+	s.NotContains(string(sealed), "hunter2")
+	keyID, err := SealedKeyID(sealed)
+	s.Require().NoError(err)
+	s.Equal(KeyID(s.oldKey), keyID)
+	s.Equal(keyring.Primary(), keyID)
+
+	again, err := keyring.Seal([]byte("hunter2"))
+	s.Require().NoError(err)
+	s.NotEqual(sealed, again, "each seal uses a fresh data key")
+
+	opened, err := keyring.Open(sealed)
+	s.Require().NoError(err)
+	s.Equal("hunter2", string(opened))
+}
+
+func (s *CryptoTestSuite) TestOpen_Tampered() {
+	keyring, err := NewKeyring(s.oldKey)
+	s.Require().NoError(err)
+	sealed, err := keyring.Seal([]byte("hunter2"))
+	s.Require().NoError(err)
+
+	for _, i := range []int{0, 1, 1 + keyIDSize, len(sealed) - 1} {
+		tampered := bytes.Clone(sealed)
+		tampered[i] ^= 0xff
+		_, err := keyring.Open(tampered)
+		s.Error(err, "byte %d", i)
+	}
+	_, err = keyring.Open(sealed[:20])
+	s.ErrorIs(err, ErrMalformed)
+	_, err = keyring.Open(nil)
+	s.ErrorIs(err, ErrMalformed)
+}
+
+func (s *CryptoTestSuite) TestRotation() {
+	old, err := NewKeyring(s.oldKey)
+	s.Require().NoError(err)
+	sealed, err := old.Seal([]byte("hunter2"))
+	s.Require().NoError(err)
+
+	newOnly, err := NewKeyring(s.newKey)
+	s.Require().NoError(err)
+	_, err = newOnly.Open(sealed)
+	s.ErrorIs(err, ErrUnknownKey)
+
+	rotating, err := NewKeyring(s.newKey, s.oldKey)
+	s.Require().NoError(err)
+	s.Equal(KeyID(s.newKey), rotating.Primary())
+	opened, err := rotating.Open(sealed)
+	s.Require().NoError(err, "older keys still open their values")
+	s.Equal("hunter2", string(opened))
+
+	rewrapped, changed, err := rotating.Rewrap(sealed)
+	s.Require().NoError(err)
+	s.True(changed)
+	dataOffset := 1 + keyIDSize + 12 + KeySize + 16 // version, key ID, nonce, data key, tag
+	s.Equal(sealed[dataOffset:], rewrapped[dataOffset:], "the value itself is not re-encrypted")
+
+	opened, err = newOnly.Open(rewrapped)
+	s.Require().NoError(err)
+	s.Equal("hunter2", string(opened))
+
+	same, changed, err := rotating.Rewrap(rewrapped)
+	s.Require().NoError(err)
+	s.False(changed)
+	s.Equal(rewrapped, same)
+
+	_, _, err = newOnly.Rewrap(sealed)
+	s.ErrorIs(err, ErrUnknownKey)
+}
+
+func (s *CryptoTestSuite) TestNewKeyring_Invalid() {
+	_, err := NewKeyring()
+	s.Error(err)
+	_, err = NewKeyring(s.oldKey, []byte("short"))
+	s.ErrorContains(err, "master key 2: key must be 32 bytes")
+}
+
+func (s *CryptoTestSuite) TestLoadKeyring() {
+	oldEncoded := base64.StdEncoding.EncodeToString(s.oldKey)
+	newEncoded := base64.StdEncoding.EncodeToString(s.newKey)
+
+	s.T().Setenv(KeyEnv, "")
+	keyring, err := LoadKeyring("")
+	s.Require().NoError(err)
+	s.Nil(keyring)
+
+	s.T().Setenv(KeyEnv, oldEncoded)
+	keyring, err = LoadKeyring("")
+	s.Require().NoError(err)
+	s.Equal(KeyID(s.oldKey), keyring.Primary())
+
+	s.T().Setenv(KeyEnv, newEncoded+","+oldEncoded)
+	keyring, err = LoadKeyring("")
+	s.Require().NoError(err)
+	s.Equal(KeyID(s.newKey), keyring.Primary())
+	s.Len(keyring.keys, 2)
+
+	keyFile := filepath.Join(s.T().TempDir(), "keys")
+	s.Require().NoError(os.WriteFile(keyFile, []byte(oldEncoded+"\n\n"), 0o600))
+	keyring, err = LoadKeyring(keyFile)
+	s.Require().NoError(err)
+	s.Equal(KeyID(s.oldKey), keyring.Primary(), "the key file wins over the environment")
+	s.Len(keyring.keys, 1)
+
+	s.T().Setenv(KeyEnv, "not base64!")
+	_, err = LoadKeyring("")
+	s.ErrorContains(err, "master key 1 is not valid base64")
+	_, err = LoadKeyring(filepath.Join(s.T().TempDir(), "missing"))
+	s.Error(err)
+}
+
+func TestCryptoTestSuite(t *testing.T) {
+	suite.Run(t, new(CryptoTestSuite))
+}
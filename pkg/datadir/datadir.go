@@ -0,0 +1,160 @@
+// Package datadir lays out the data directory holding everything the server persists: the
+// database, output artifacts, log files, wordlists, scanner templates and plugins. Paths not
+// overridden by their own flags are derived from one root, and every directory is created and
+// checked at startup so that permission problems fail fast instead of during the first scan.
+package datadir
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tb0hdan/wass-mcp/pkg/logging"
+)
+
+// DefaultRoot is the data directory used when none is configured.
+const DefaultRoot = "build"
+
+// Entries of the data directory.
+const (
+	// DBFile is the SQLite database file.
+	DBFile = "wass-mcp.db"
+	// ArtifactsDir holds spilled outputs, HAR captures and other artifacts.
+	ArtifactsDir = "artifacts"
+	// LogsDir holds log files named without a directory.
+	LogsDir = "logs"
+	// WordlistsDir holds the wordlists scans reference by name.
+	WordlistsDir = "wordlists"
+	// TemplatesDir holds custom scanner templates.
+	TemplatesDir = "templates"
+	// PluginsDir holds scanner plugins.
+	PluginsDir = "plugins"
+	// TLSDir holds the generated self-signed certificate of the server. It is only created when
+	// one is generated.
+	TLSDir = "tls"
+)
+
+// dirPerms are the permissions of the directories created by Prepare.
+const dirPerms = 0o750
+
+// ErrWorldWritable is returned for directories any local user can write to, which would let them
+// replace wordlists, templates or artifacts the server trusts. Sticky directories such as /tmp,
+// where users cannot replace each other's files, are accepted.
+var ErrWorldWritable = errors.New("directory is world-writable")
+
+// Layout locates the entries of a data directory.
+type Layout struct {
+	// Root is the data directory.
+	Root string
+}
+
+// New returns the layout of the data directory root, DefaultRoot when empty.
+func New(root string) Layout {
+	if root == "" {
+		root = DefaultRoot
+	}
+
+	return Layout{Root: root}
+}
+
+// DB returns the path of the database file.
+func (l Layout) DB() string {
+	return filepath.Join(l.Root, DBFile)
+}
+
+// Artifacts returns the artifact directory.
+func (l Layout) Artifacts() string {
+	return filepath.Join(l.Root, ArtifactsDir)
+}
+
+// Logs returns the log directory.
+func (l Layout) Logs() string {
+	return filepath.Join(l.Root, LogsDir)
+}
+
+// Wordlists returns the wordlist directory.
+func (l Layout) Wordlists() string {
+	return filepath.Join(l.Root, WordlistsDir)
+}
+
+// Templates returns the scanner template directory.
+func (l Layout) Templates() string {
+	return filepath.Join(l.Root, TemplatesDir)
+}
+
+// Plugins returns the plugin directory.
+func (l Layout) Plugins() string {
+	return filepath.Join(l.Root, PluginsDir)
+}
+
+// TLS returns the directory of the generated self-signed certificate.
+func (l Layout) TLS() string {
+	return filepath.Join(l.Root, TLSDir)
+}
+
+// Dirs returns the root and every directory of the layout.
+func (l Layout) Dirs() []string {
+	return []string{l.Root, l.Artifacts(), l.Logs(), l.Wordlists(), l.Templates(), l.Plugins()}
+}
+
+// LogFile returns the path of the log file output: a bare file name is placed in the log
+// directory, while stdout, stderr and paths with a directory are returned as is.
+func (l Layout) LogFile(output string) string {
+	if output == "" || output == logging.OutputStdout || output == logging.OutputStderr || strings.ContainsAny(output, `/\`) {
+		return output
+	}
+
+	return filepath.Join(l.Logs(), output)
+}
+
+// Prepare creates the directories of the layout and the extra directories, such as directories
+// overridden by flags, and checks each with CheckDir. Empty extra entries are skipped.
+func (l Layout) Prepare(extra ...string) error {
+	seen := make(map[string]struct{})
+	for _, dir := range append(l.Dirs(), extra...) {
+		if dir == "" {
+			continue
+		}
+		if _, ok := seen[filepath.Clean(dir)]; ok {
+			continue
+		}
+		seen[filepath.Clean(dir)] = struct{}{}
+
+		if err := os.MkdirAll(dir, dirPerms); err != nil {
+			return fmt.Errorf("failed to create %s: %w", dir, err)
+		}
+		if err := CheckDir(dir); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CheckDir checks that dir is a directory the server can write to and that is not world-writable
+// without the sticky bit.
+func CheckDir(dir string) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return fmt.Errorf("data directory %s: %w", dir, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("data directory %s is not a directory", dir)
+	}
+	if info.Mode().Perm()&0o002 != 0 && info.Mode()&os.ModeSticky == 0 {
+		return fmt.Errorf("data directory %s: %w (mode %s), remove write access for others", dir, ErrWorldWritable, info.Mode().Perm())
+	}
+
+	probe, err := os.CreateTemp(dir, ".write-check-*")
+	if err != nil {
+		return fmt.Errorf("data directory %s is not writable: %w", dir, err)
+	}
+	_ = probe.Close()
+	if err := os.Remove(probe.Name()); err != nil {
+		return fmt.Errorf("data directory %s: %w", dir, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package datadir
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type DataDirTestSuite struct {
+	suite.Suite
+}
+
+func (s *DataDirTestSuite) TestLayout() {
+	layout := New("/var/lib/wass")
+	s.Equal("/var/lib/wass/wass-mcp.db", layout.DB())
+	s.Equal("/var/lib/wass/artifacts", layout.Artifacts())
+	s.Equal("/var/lib/wass/logs", layout.Logs())
+	s.Equal("/var/lib/wass/wordlists", layout.Wordlists())
+	s.Equal("/var/lib/wass/templates", layout.Templates())
+	s.Equal("/var/lib/wass/plugins", layout.Plugins())
+	s.Equal("/var/lib/wass/tls", layout.TLS())
+
+	s.Equal(DefaultRoot, New("").Root)
+	s.Equal(filepath.Join(DefaultRoot, DBFile), New("").DB(), "the default keeps the former database path")
+}
+
+func (s *DataDirTestSuite) TestLogFile() {
+	layout := New("/data")
+	s.Equal("stdout", layout.LogFile("stdout"))
+	s.Equal("stderr", layout.LogFile("stderr"))
+	s.Equal("/data/logs/wass.log", layout.LogFile("wass.log"))
+	s.Equal("/var/log/wass.log", layout.LogFile("/var/log/wass.log"))
+	s.Equal("logs/wass.log", layout.LogFile("logs/wass.log"))
+}
+
+func (s *DataDirTestSuite) TestPrepare() {
+	root := filepath.Join(s.T().TempDir(), "data")
+	extra := filepath.Join(s.T().TempDir(), "work")
+	layout := New(root)
+
+	s.Require().NoError(layout.Prepare(extra, "", layout.Artifacts()))
+	for _, dir := range append(layout.Dirs(), extra) {
+		info, err := os.Stat(dir)
+		s.Require().NoError(err, dir)
+		s.True(info.IsDir())
+		s.Equal(os.FileMode(dirPerms), info.Mode().Perm())
+		entries, err := os.ReadDir(dir)
+		s.Require().NoError(err)
+		for _, entry := range entries {
+			s.NotContains(entry.Name(), ".write-check", "write probes are removed")
+		}
+	}
+
+	s.Require().NoError(layout.Prepare(), "preparing an existing layout succeeds")
+}
+
+func (s *DataDirTestSuite) TestCheckDir() {
+	dir := s.T().TempDir()
+	s.NoError(CheckDir(dir))
+
+	s.Require().NoError(os.Chmod(dir, 0o777))
+	s.ErrorIs(CheckDir(dir), ErrWorldWritable)
+	s.Require().NoError(os.Chmod(dir, 0o777|os.ModeSticky))
+	s.NoError(CheckDir(dir), "sticky directories such as /tmp are accepted")
+
+	file := filepath.Join(dir, "file")
+	s.Require().NoError(os.WriteFile(file, []byte("x"), 0o600))
+	s.ErrorContains(CheckDir(file), "is not a directory")
+	s.Error(CheckDir(filepath.Join(dir, "missing")))
+
+	s.Require().NoError(os.Chmod(dir, 0o700))
+	s.ErrorContains(New(file).Prepare(), "failed to create")
+}
+
+func TestDataDirTestSuite(t *testing.T) {
+	suite.Run(t, new(DataDirTestSuite))
+}
@@ -0,0 +1,123 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/tb0hdan/wass-mcp/pkg/bundle"
+)
+
+// ErrNoPortScanner is returned when no port scanner binary is available.
+var ErrNoPortScanner = errors.New("no port scanner available (naabu or nmap)")
+
+// PortScanner finds open TCP ports on a host.
+type PortScanner interface {
+	// Name returns the port scanner name.
+	Name() string
+	// IsAvailable checks if the port scanner binary is available.
+	IsAvailable() bool
+	// OpenPorts returns the open TCP ports of host. When ports is empty the scanner's
+	// default port selection is used.
+	OpenPorts(ctx context.Context, host string, ports []int) ([]int, error)
+}
+
+// ProbeFunc reports the scheme of the HTTP(S) service listening on host:port, if any.
+type ProbeFunc func(ctx context.Context, host string, port int) (string, bool)
+
+// Service is an HTTP(S) service found on a host.
+type Service struct {
+	Port   int
+	Scheme string
+}
+
+// Result is the outcome of a discovery run.
+type Result struct {
+	// OpenPorts are all open ports found by the port scanner.
+	OpenPorts []int
+	// Scanner is the name of the port scanner used.
+	Scanner string
+	// Services are the open ports serving HTTP(S), ordered by port.
+	Services []Service
+}
+
+// Discoverer finds the HTTP(S) services of a host by running a port scanner and probing each open port.
+type Discoverer struct {
+	// Probe detects HTTP(S) on an open port.
+	Probe ProbeFunc
+	// Scanners are tried in order; the first available one is used.
+	Scanners []PortScanner
+}
+
+// binaryPath returns the path of the port scanner binary name, preferring a bundled binary over
+// PATH, or the name itself when it cannot be found.
+func binaryPath(name string) string {
+	path, _, err := bundle.LookPath(name)
+	if err != nil {
+		return name
+	}
+	return path
+}
+
+// New creates a Discoverer using naabu, falling back to nmap, and HTTP(S) probing.
+func New() *Discoverer {
+	return &Discoverer{
+		Probe:    ProbeHTTP,
+		Scanners: []PortScanner{NewNaabu(), NewNmap()},
+	}
+}
+
+// Discover returns the HTTP(S) services of host, limited to ports when given.
+func (d *Discoverer) Discover(ctx context.Context, host string, ports []int) (Result, error) {
+	var scanner PortScanner
+	for _, candidate := range d.Scanners {
+		if candidate.IsAvailable() {
+			scanner = candidate
+			break
+		}
+	}
+	if scanner == nil {
+		return Result{}, ErrNoPortScanner
+	}
+
+	open, err := scanner.OpenPorts(ctx, host, ports)
+	if err != nil {
+		return Result{Scanner: scanner.Name()}, fmt.Errorf("%s port scan failed: %w", scanner.Name(), err)
+	}
+	slices.Sort(open)
+	open = slices.Compact(open)
+
+	return Result{
+		OpenPorts: open,
+		Scanner:   scanner.Name(),
+		Services:  d.probeAll(ctx, host, open),
+	}, nil
+}
+
+// probeAll probes the open ports concurrently and returns the HTTP(S) services ordered by port.
+func (d *Discoverer) probeAll(ctx context.Context, host string, ports []int) []Service {
+	schemes := make([]string, len(ports))
+
+	var waitGroup sync.WaitGroup
+	for i, port := range ports {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			if scheme, ok := d.Probe(ctx, host, port); ok {
+				schemes[i] = scheme
+			}
+		}()
+	}
+	waitGroup.Wait()
+
+	var services []Service
+	for i, port := range ports {
+		if schemes[i] != "" {
+			services = append(services, Service{Port: port, Scheme: schemes[i]})
+		}
+	}
+
+	return services
+}
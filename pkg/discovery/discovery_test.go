@@ -0,0 +1,161 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/types"
+)
+
+// fakePortScanner is a PortScanner returning fixed ports.
+type fakePortScanner struct {
+	available bool
+	err       error
+	name      string
+	open      []int
+	requested []int
+}
+
+func (f *fakePortScanner) Name() string { return f.name }
+
+func (f *fakePortScanner) IsAvailable() bool { return f.available }
+
+func (f *fakePortScanner) OpenPorts(_ context.Context, _ string, ports []int) ([]int, error) {
+	f.requested = ports
+	return f.open, f.err
+}
+
+type DiscoveryTestSuite struct {
+	suite.Suite
+}
+
+func (s *DiscoveryTestSuite) TestDiscover_UsesFirstAvailableScanner() {
+	unavailable := &fakePortScanner{name: "naabu"}
+	nmap := &fakePortScanner{name: "nmap", available: true, open: []int{8443, 22, 80, 80}}
+	discoverer := &Discoverer{
+		Probe: func(_ context.Context, _ string, port int) (string, bool) {
+			switch port {
+			case 80:
+				return types.SchemeHTTP, true
+			case 8443:
+				return types.SchemeHTTPS, true
+			default:
+				return "", false
+			}
+		},
+		Scanners: []PortScanner{unavailable, nmap},
+	}
+
+	result, err := discoverer.Discover(context.Background(), "example.com", []int{22, 80, 8443})
+	s.Require().NoError(err)
+	s.Equal("nmap", result.Scanner)
+	s.Equal([]int{22, 80, 8443}, result.OpenPorts)
+	s.Equal([]Service{{Port: 80, Scheme: "http"}, {Port: 8443, Scheme: "https"}}, result.Services)
+	s.Equal([]int{22, 80, 8443}, nmap.requested)
+}
+
+func (s *DiscoveryTestSuite) TestDiscover_NoScanner() {
+	discoverer := &Discoverer{Scanners: []PortScanner{&fakePortScanner{name: "naabu"}}}
+
+	_, err := discoverer.Discover(context.Background(), "example.com", nil)
+	s.ErrorIs(err, ErrNoPortScanner)
+}
+
+func (s *DiscoveryTestSuite) TestDiscover_ScannerError() {
+	discoverer := &Discoverer{
+		Scanners: []PortScanner{&fakePortScanner{name: "nmap", available: true, err: errors.New("boom")}},
+	}
+
+	result, err := discoverer.Discover(context.Background(), "example.com", nil)
+	s.Require().Error(err)
+	s.Contains(err.Error(), "nmap port scan failed")
+	s.Equal("nmap", result.Scanner)
+}
+
+func (s *DiscoveryTestSuite) TestParseNaabu() {
+	output := "example.com:80\nexample.com:443\n\n[INF] noise\n[2001:db8::1]:8080\n"
+	s.Equal([]int{80, 443, 8080}, parseNaabu(output))
+}
+
+func (s *DiscoveryTestSuite) TestNaabuArgs() {
+	s.Equal([]string{"-host", "example.com", "-silent"}, naabuArgs("example.com", nil))
+	s.Equal([]string{"-host", "example.com", "-silent", "-p", "80,443"}, naabuArgs("example.com", []int{80, 443}))
+}
+
+func (s *DiscoveryTestSuite) TestParseNmap() {
+	output := "# Nmap 7.94 scan initiated\n" +
+		"Host: 10.0.0.1 (example.com)\tStatus: Up\n" +
+		"Host: 10.0.0.1 (example.com)\tPorts: 22/open/tcp//ssh///, 80/open/tcp//http///, 443/open/tcp//https///\tIgnored State: closed (997)\n" +
+		"# Nmap done\n"
+	s.Equal([]int{22, 80, 443}, parseNmap(output))
+}
+
+func (s *DiscoveryTestSuite) TestNmapArgs() {
+	s.Equal([]string{"-Pn", "-sT", "--open", "-oG", "-", "example.com"}, nmapArgs("example.com", nil))
+	s.Equal([]string{"-Pn", "-sT", "--open", "-oG", "-", "-p", "8080", "example.com"}, nmapArgs("example.com", []int{8080}))
+}
+
+func (s *DiscoveryTestSuite) TestProbeHTTP_PlainHTTP() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	host, port := s.hostPort(server.URL)
+	scheme, ok := ProbeHTTP(context.Background(), host, port)
+	s.True(ok)
+	s.Equal(types.SchemeHTTP, scheme)
+}
+
+func (s *DiscoveryTestSuite) TestProbeHTTP_HTTPS() {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Location", "/login")
+		w.WriteHeader(http.StatusFound)
+	}))
+	defer server.Close()
+
+	host, port := s.hostPort(server.URL)
+	scheme, ok := ProbeHTTP(context.Background(), host, port)
+	s.True(ok)
+	s.Equal(types.SchemeHTTPS, scheme)
+}
+
+func (s *DiscoveryTestSuite) TestProbeHTTP_NotHTTP() {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	s.Require().NoError(err)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			_, _ = conn.Write([]byte("SSH-2.0-OpenSSH\r\n"))
+			_ = conn.Close()
+		}
+	}()
+	defer func() { _ = listener.Close() }()
+
+	port := listener.Addr().(*net.TCPAddr).Port
+	_, ok := ProbeHTTP(context.Background(), "127.0.0.1", port)
+	s.False(ok)
+}
+
+func (s *DiscoveryTestSuite) hostPort(rawURL string) (string, int) {
+	parsed, err := url.Parse(rawURL)
+	s.Require().NoError(err)
+	port, err := strconv.Atoi(parsed.Port())
+	s.Require().NoError(err)
+
+	return parsed.Hostname(), port
+}
+
+func TestDiscoveryTestSuite(t *testing.T) {
+	suite.Run(t, new(DiscoveryTestSuite))
+}
@@ -0,0 +1,80 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/tb0hdan/wass-mcp/pkg/bundle"
+)
+
+const naabuBinary = "naabu"
+
+// Naabu finds open ports with naabu.
+type Naabu struct{}
+
+// NewNaabu creates a naabu port scanner.
+func NewNaabu() *Naabu {
+	return &Naabu{}
+}
+
+// Name returns the port scanner name.
+func (n *Naabu) Name() string {
+	return naabuBinary
+}
+
+// IsAvailable checks if the naabu binary is bundled or available in PATH.
+func (n *Naabu) IsAvailable() bool {
+	_, _, err := bundle.LookPath(naabuBinary)
+	return err == nil
+}
+
+// OpenPorts runs naabu against host and returns the open ports.
+func (n *Naabu) OpenPorts(ctx context.Context, host string, ports []int) ([]int, error) {
+	cmd := exec.CommandContext(ctx, binaryPath(naabuBinary), naabuArgs(host, ports)...) //nolint:gosec
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute naabu: %w", err)
+	}
+
+	return parseNaabu(string(output)), nil
+}
+
+// naabuArgs builds the naabu command line arguments.
+func naabuArgs(host string, ports []int) []string {
+	args := []string{"-host", host, "-silent"}
+	if len(ports) > 0 {
+		args = append(args, "-p", joinPorts(ports))
+	}
+
+	return args
+}
+
+// parseNaabu parses naabu silent output, one "host:port" per line.
+func parseNaabu(output string) []int {
+	var ports []int
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		idx := strings.LastIndex(line, ":")
+		if idx < 0 {
+			continue
+		}
+		if port, err := strconv.Atoi(line[idx+1:]); err == nil {
+			ports = append(ports, port)
+		}
+	}
+
+	return ports
+}
+
+// joinPorts formats ports as a comma-separated list.
+func joinPorts(ports []int) string {
+	parts := make([]string, 0, len(ports))
+	for _, port := range ports {
+		parts = append(parts, strconv.Itoa(port))
+	}
+
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,68 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+
+	"github.com/tb0hdan/wass-mcp/pkg/bundle"
+)
+
+const nmapBinary = "nmap"
+
+// nmapOpenPortRe matches open TCP ports in nmap grepable output, e.g. "80/open/tcp//http///".
+var nmapOpenPortRe = regexp.MustCompile(`(\d+)/open/tcp/`)
+
+// Nmap finds open ports with nmap.
+type Nmap struct{}
+
+// NewNmap creates an nmap port scanner.
+func NewNmap() *Nmap {
+	return &Nmap{}
+}
+
+// Name returns the port scanner name.
+func (n *Nmap) Name() string {
+	return nmapBinary
+}
+
+// IsAvailable checks if the nmap binary is bundled or available in PATH.
+func (n *Nmap) IsAvailable() bool {
+	_, _, err := bundle.LookPath(nmapBinary)
+	return err == nil
+}
+
+// OpenPorts runs nmap against host and returns the open ports.
+func (n *Nmap) OpenPorts(ctx context.Context, host string, ports []int) ([]int, error) {
+	cmd := exec.CommandContext(ctx, binaryPath(nmapBinary), nmapArgs(host, ports)...) //nolint:gosec
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute nmap: %w", err)
+	}
+
+	return parseNmap(string(output)), nil
+}
+
+// nmapArgs builds the nmap command line arguments for a grepable TCP connect scan.
+func nmapArgs(host string, ports []int) []string {
+	args := []string{"-Pn", "-sT", "--open", "-oG", "-"}
+	if len(ports) > 0 {
+		args = append(args, "-p", joinPorts(ports))
+	}
+
+	return append(args, host)
+}
+
+// parseNmap parses open TCP ports from nmap grepable output.
+func parseNmap(output string) []int {
+	var ports []int
+	for _, match := range nmapOpenPortRe.FindAllStringSubmatch(output, -1) {
+		if port, err := strconv.Atoi(match[1]); err == nil {
+			ports = append(ports, port)
+		}
+	}
+
+	return ports
+}
@@ -0,0 +1,40 @@
+package discovery
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+
+	"github.com/tb0hdan/wass-mcp/pkg/httpclient"
+	"github.com/tb0hdan/wass-mcp/pkg/target"
+	"github.com/tb0hdan/wass-mcp/pkg/types"
+)
+
+// ProbeHTTP reports whether host:port serves HTTPS or HTTP. HTTPS is tried first, since plain
+// HTTP servers reject TLS handshakes while many HTTPS servers answer plain HTTP with an error page.
+// Certificates are not verified: the probe only detects the protocol.
+func ProbeHTTP(ctx context.Context, host string, port int) (string, bool) {
+	client := httpclient.New(httpclient.Config{
+		Scope:   []string{host},
+		TLS:     &tls.Config{InsecureSkipVerify: true}, //nolint:gosec
+		Timeout: types.ProbeTimeout,
+	})
+	defer client.CloseIdleConnections()
+
+	for _, scheme := range []string{types.SchemeHTTPS, types.SchemeHTTP} {
+		probeURL := target.Target{Host: host, Port: port, Scheme: scheme}.RequestURL()
+		req, err := http.NewRequestWithContext(ctx, http.MethodHead, probeURL, nil)
+		if err != nil {
+			return "", false
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			continue
+		}
+		_ = resp.Body.Close()
+
+		return scheme, true
+	}
+
+	return "", false
+}
@@ -0,0 +1,59 @@
+package findings
+
+import (
+	"strings"
+
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+)
+
+// Comparison is the difference between the findings of two targets.
+type Comparison struct {
+	// OnlyInBase are the findings of the base target the other target does not have.
+	OnlyInBase []models.Finding `json:"only_in_base"`
+	// OnlyInOther are the findings of the other target the base target does not have.
+	OnlyInOther []models.Finding `json:"only_in_other"`
+	// Shared is the number of findings both targets have.
+	Shared int `json:"shared"`
+}
+
+// Compare diffs the findings of two different targets, e.g. staging and production of the same
+// application. Findings match by scanner, severity, check, URL path and parameter (see
+// Fingerprint), ignoring the hosts they were reported on. Each finding matches at most one finding of the other target.
+// The findings only one target has are prioritized, see Prioritize.
+func Compare(base, other []models.Finding) Comparison {
+	remaining := make(map[string]int, len(other))
+	for _, finding := range other {
+		remaining[comparisonKey(finding)]++
+	}
+
+	comparison := Comparison{OnlyInBase: []models.Finding{}, OnlyInOther: []models.Finding{}}
+	matched := make(map[string]int, len(base))
+	for _, finding := range base {
+		key := comparisonKey(finding)
+		if remaining[key] > 0 {
+			remaining[key]--
+			matched[key]++
+			comparison.Shared++
+			continue
+		}
+		comparison.OnlyInBase = append(comparison.OnlyInBase, finding)
+	}
+	for _, finding := range other {
+		key := comparisonKey(finding)
+		if matched[key] > 0 {
+			matched[key]--
+			continue
+		}
+		comparison.OnlyInOther = append(comparison.OnlyInOther, finding)
+	}
+
+	Prioritize(comparison.OnlyInBase)
+	Prioritize(comparison.OnlyInOther)
+
+	return comparison
+}
+
+// comparisonKey identifies a finding independently of the target it was reported on.
+func comparisonKey(finding models.Finding) string {
+	return strings.Join([]string{finding.Scanner, NormalizeSeverity(finding.Severity), check(finding), findingPath(finding), findingParameter(finding)}, "\x00")
+}
@@ -0,0 +1,157 @@
+package findings
+
+import (
+	"bufio"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+)
+
+const (
+	// StatusSuccess marks a scanner section that completed successfully.
+	StatusSuccess = "SUCCESS"
+	// StatusFailed marks a scanner section that failed.
+	StatusFailed = "FAILED"
+
+	// FullScanTool is the name of the full scan tool whose merged reports are split into sections.
+	FullScanTool = "full_scan"
+
+	// reportSeparatorWidth is the width of the "=" separator lines in full_scan reports.
+	reportSeparatorWidth = 79
+)
+
+var (
+	// sectionHeaderRe matches per-scanner result headers in full_scan reports.
+	sectionHeaderRe = regexp.MustCompile(`^\s+([A-Z0-9._-]+) RESULTS$`)
+	// summaryLineRe matches per-scanner status lines in full_scan report summaries.
+	summaryLineRe = regexp.MustCompile(`^\s{2}(\S+)\s*: ([A-Z ]+) \(`)
+	// severityTagRe matches bracketed severity tags such as "[high]".
+	severityTagRe = regexp.MustCompile(`(?i)\[(critical|high|medium|low|info)\]`)
+	// urlRe matches absolute HTTP(S) URLs.
+	urlRe = regexp.MustCompile(`https?://[^\s"'<>\]]+`)
+)
+
+// Section is the output of a single scanner within a tool result.
+type Section struct {
+	Output  string
+	Scanner string
+	Status  string
+}
+
+// Sections splits tool output into per-scanner sections. full_scan reports are split by
+// scanner using their result headers and summary status lines; other tools yield a single
+// successful section named after the tool.
+func Sections(toolName, output string) []Section {
+	if toolName != FullScanTool {
+		return []Section{{Output: output, Scanner: toolName, Status: StatusSuccess}}
+	}
+
+	var (
+		sections []Section
+		current  *Section
+		body     strings.Builder
+		statuses = make(map[string]string)
+	)
+
+	flush := func() {
+		if current != nil {
+			current.Output = strings.TrimSpace(body.String())
+			sections = append(sections, *current)
+			current = nil
+		}
+		body.Reset()
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), len(output)+1)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if match := summaryLineRe.FindStringSubmatch(line); match != nil && current == nil {
+			statuses[match[1]] = strings.TrimSpace(match[2])
+			continue
+		}
+		if match := sectionHeaderRe.FindStringSubmatch(line); match != nil {
+			flush()
+			name := strings.ToLower(match[1])
+			current = &Section{Scanner: name, Status: StatusSuccess}
+			if status, ok := statuses[name]; ok {
+				current.Status = status
+			}
+			continue
+		}
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "VHOST: ") || strings.HasPrefix(trimmed, "PORT: ") ||
+			trimmed == "END OF REPORT" || trimmed == "SCAN SUMMARY" {
+			flush()
+			continue
+		}
+		if current != nil && trimmed != strings.Repeat("=", reportSeparatorWidth) {
+			body.WriteString(line)
+			body.WriteString("\n")
+		}
+	}
+	flush()
+
+	return sections
+}
+
+// Parser parses the raw output of a single scanner into findings.
+type Parser func(output string) ([]models.Finding, error)
+
+var (
+	parsersMu sync.RWMutex
+	// parsers maps scanner names to their registered native parsers.
+	parsers = make(map[string]Parser)
+)
+
+// RegisterParser registers the native findings parser of the named scanner, replacing any previous one.
+func RegisterParser(scanner string, parser Parser) {
+	parsersMu.Lock()
+	defer parsersMu.Unlock()
+
+	parsers[scanner] = parser
+}
+
+// Extract extracts findings from the raw output of the named scanner using its registered parser.
+// Scanners without a parser, or whose parser fails, fall back to bracketed severity tags. The CWE
+// IDs of the findings are normalized, see CWEIDs.
+func Extract(scanner, output string) []models.Finding {
+	parsersMu.RLock()
+	parser, ok := parsers[scanner]
+	parsersMu.RUnlock()
+
+	var found []models.Finding
+	var err error
+	if ok {
+		found, err = parser(output)
+	}
+	if !ok || err != nil {
+		found = ExtractGeneric(scanner, output)
+	}
+	SetCWEs(found)
+
+	return found
+}
+
+// ExtractGeneric extracts findings from lines carrying bracketed severity tags.
+func ExtractGeneric(scanner, output string) []models.Finding {
+	var findings []models.Finding
+
+	for _, line := range strings.Split(output, "\n") {
+		match := severityTagRe.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		findings = append(findings, models.Finding{
+			Scanner:  scanner,
+			Severity: NormalizeSeverity(match[1]),
+			Title:    strings.TrimSpace(line),
+			URL:      urlRe.FindString(line),
+		})
+	}
+
+	return findings
+}
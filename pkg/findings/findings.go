@@ -0,0 +1,447 @@
+package findings
+
+import (
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/types"
+)
+
+const (
+	// DefaultTopFindings is the default number of top findings included in a summary.
+	DefaultTopFindings = 10
+	// maxAffectedURLs caps the number of affected URLs included in a summary.
+	maxAffectedURLs = 50
+	// KEVRiskFactor multiplies the risk weight of findings of a known exploited vulnerability.
+	KEVRiskFactor = 2
+)
+
+// cvePattern matches CVE IDs.
+var cvePattern = regexp.MustCompile(`(?i)\bCVE-\d{4}-\d{4,}\b`)
+
+// cwePattern matches CWE IDs and links to their MITRE definitions, capturing the number.
+var cwePattern = regexp.MustCompile(`(?i)\bCWE-(\d+)\b|cwe\.mitre\.org/data/definitions/(\d+)`)
+
+// severityRanks orders severities from least to most severe.
+var severityRanks = map[string]int{
+	types.SeverityInfo:     1,
+	types.SeverityLow:      2,
+	types.SeverityMedium:   3,
+	types.SeverityHigh:     4,
+	types.SeverityCritical: 5,
+}
+
+// SeverityWeights are the per-finding weights used to compute risk scores.
+var SeverityWeights = map[string]float64{
+	types.SeverityCritical: 10,
+	types.SeverityHigh:     5,
+	types.SeverityMedium:   2,
+	types.SeverityLow:      0.5,
+	types.SeverityInfo:     0,
+}
+
+// Severities lists all known severities from most to least severe.
+var Severities = []string{
+	types.SeverityCritical,
+	types.SeverityHigh,
+	types.SeverityMedium,
+	types.SeverityLow,
+	types.SeverityInfo,
+}
+
+// ScannerSummary describes the outcome of a single scanner within a tool result.
+type ScannerSummary struct {
+	Findings int    `json:"findings"`
+	Name     string `json:"name"`
+	Status   string `json:"status"`
+}
+
+// Disagreement is a URL reported by some, but not all, successful scanners.
+type Disagreement struct {
+	NotReportedBy []string `json:"not_reported_by"`
+	ReportedBy    []string `json:"reported_by"`
+	URL           string   `json:"url"`
+}
+
+// Summary is a compact extractive summary of a tool result.
+type Summary struct {
+	AffectedURLs   []string         `json:"affected_urls"`
+	Disagreements  []Disagreement   `json:"disagreements,omitempty"`
+	RiskScore      float64          `json:"risk_score"`
+	Scanners       []ScannerSummary `json:"scanners"`
+	SeverityCounts map[string]int   `json:"severity_counts"`
+	// Suppressed is the number of findings dropped by suppression rules.
+	Suppressed    int              `json:"suppressed,omitempty"`
+	TopFindings   []models.Finding `json:"top_findings"`
+	TotalFindings int              `json:"total_findings"`
+}
+
+// Suppressor reports whether a finding is suppressed, see suppress.Matcher.
+type Suppressor interface {
+	Suppressed(finding models.Finding) bool
+}
+
+// SeverityRank returns the rank of a severity, higher is more severe. Unknown severities rank 0.
+func SeverityRank(severity string) int {
+	return severityRanks[severity]
+}
+
+// NormalizeSeverity maps a scanner-provided severity to one of the known severities.
+// Unknown values map to info.
+func NormalizeSeverity(severity string) string {
+	switch sev := strings.ToLower(strings.TrimSpace(severity)); sev {
+	case types.SeverityCritical, types.SeverityHigh, types.SeverityMedium, types.SeverityLow:
+		return sev
+	case "moderate":
+		return types.SeverityMedium
+	default:
+		return types.SeverityInfo
+	}
+}
+
+// CountBySeverity counts findings per severity, always including every known severity.
+func CountBySeverity(findings []models.Finding) map[string]int {
+	counts := make(map[string]int, len(Severities))
+	for _, severity := range Severities {
+		counts[severity] = 0
+	}
+	for _, finding := range findings {
+		counts[NormalizeSeverity(finding.Severity)]++
+	}
+
+	return counts
+}
+
+// RiskScore computes a severity-weighted risk score from per-severity finding counts.
+// The score is the sum of each finding's severity weight, so it grows with both severity and count.
+func RiskScore(counts map[string]int) float64 {
+	var score float64
+	for severity, count := range counts {
+		score += SeverityWeights[severity] * float64(count)
+	}
+
+	return score
+}
+
+// FindingRisk returns the risk weight of a finding: its severity weight raised by its EPSS
+// probability, multiplied by KEVRiskFactor for a known exploited vulnerability.
+func FindingRisk(finding models.Finding) float64 {
+	weight := SeverityWeights[NormalizeSeverity(finding.Severity)] * (1 + finding.EPSS)
+	if finding.KEV {
+		weight *= KEVRiskFactor
+	}
+
+	return weight
+}
+
+// ScoreFindings computes the risk score of findings as the sum of their FindingRisk. Without
+// exploit intelligence it equals the RiskScore of their severity counts.
+func ScoreFindings(found []models.Finding) float64 {
+	var score float64
+	for _, finding := range found {
+		score += FindingRisk(finding)
+	}
+
+	return score
+}
+
+// Prioritize sorts findings by descending risk weight, then severity, keeping the order of equal
+// findings.
+func Prioritize(found []models.Finding) {
+	sort.SliceStable(found, func(i, j int) bool {
+		if left, right := FindingRisk(found[i]), FindingRisk(found[j]); left != right {
+			return left > right
+		}
+		return SeverityRank(NormalizeSeverity(found[i].Severity)) > SeverityRank(NormalizeSeverity(found[j].Severity))
+	})
+}
+
+// CVEIDs returns the upper-cased, unique CVE IDs of a finding: those set by its scanner followed
+// by those named in its title and references.
+func CVEIDs(finding models.Finding) []string {
+	var ids []string
+	seen := make(map[string]struct{})
+	add := func(id string) {
+		id = strings.ToUpper(strings.TrimSpace(id))
+		if _, ok := seen[id]; ok || !cvePattern.MatchString(id) {
+			return
+		}
+		seen[id] = struct{}{}
+		ids = append(ids, id)
+	}
+
+	for _, id := range finding.CVEs {
+		add(id)
+	}
+	for _, text := range append([]string{finding.Title}, finding.References...) {
+		for _, id := range cvePattern.FindAllString(text, -1) {
+			add(id)
+		}
+	}
+
+	return ids
+}
+
+// CWEIDs returns the normalized, unique CWE IDs of a finding, such as "CWE-79": those set by its
+// scanner followed by those named or linked in its title and references.
+func CWEIDs(finding models.Finding) []string {
+	var ids []string
+	seen := make(map[string]struct{})
+	addAll := func(text string) {
+		for _, match := range cwePattern.FindAllStringSubmatch(text, -1) {
+			id := "CWE-" + match[1] + match[2]
+			if _, ok := seen[id]; ok {
+				continue
+			}
+			seen[id] = struct{}{}
+			ids = append(ids, id)
+		}
+	}
+
+	for _, id := range finding.CWEs {
+		addAll(id)
+	}
+	for _, text := range append([]string{finding.Title}, finding.References...) {
+		addAll(text)
+	}
+
+	return ids
+}
+
+// SetCWEs normalizes the CWE IDs of each finding, see CWEIDs.
+func SetCWEs(found []models.Finding) {
+	for i := range found {
+		found[i].CWEs = CWEIDs(found[i])
+	}
+}
+
+// ExtractAll extracts findings from every scanner section of a tool's raw output.
+func ExtractAll(toolName, output string) []models.Finding {
+	var all []models.Finding
+	for _, section := range Sections(toolName, output) {
+		all = append(all, Extract(section.Scanner, section.Output)...)
+	}
+
+	return all
+}
+
+// ScoreOutput extracts findings from a tool's raw output and returns its risk score.
+func ScoreOutput(toolName, output string) float64 {
+	return RiskScore(CountBySeverity(ExtractAll(toolName, output)))
+}
+
+// Summarize builds a summary from the sections of a tool result, keeping at most topN top findings.
+// The stored findings of the result are merged in (see MergeStored) before the findings are
+// prioritized and scored, so that exploit intelligence recorded with them ranks the top findings.
+// Findings suppressed by suppressor, if any, are left out.
+func Summarize(sections []Section, topN int, stored []models.Finding, suppressor Suppressor) Summary {
+	if topN <= 0 {
+		topN = DefaultTopFindings
+	}
+
+	var all []models.Finding
+	suppressed := 0
+	scanners := make([]ScannerSummary, 0, len(sections))
+	for _, section := range sections {
+		sectionFindings := Extract(section.Scanner, section.Output)
+		if suppressor != nil {
+			kept := sectionFindings[:0]
+			for _, finding := range sectionFindings {
+				if suppressor.Suppressed(finding) {
+					suppressed++
+					continue
+				}
+				kept = append(kept, finding)
+			}
+			sectionFindings = kept
+		}
+		all = append(all, sectionFindings...)
+		scanners = append(scanners, ScannerSummary{
+			Findings: len(sectionFindings),
+			Name:     section.Scanner,
+			Status:   section.Status,
+		})
+	}
+
+	all = MergeStored(all, stored)
+	top := make([]models.Finding, len(all))
+	copy(top, all)
+	Prioritize(top)
+	if len(top) > topN {
+		top = top[:topN]
+	}
+
+	counts := CountBySeverity(all)
+
+	return Summary{
+		AffectedURLs:   affectedURLs(all),
+		Disagreements:  disagreements(sections, all, topN),
+		RiskScore:      ScoreFindings(all),
+		Scanners:       scanners,
+		SeverityCounts: counts,
+		Suppressed:     suppressed,
+		TopFindings:    top,
+		TotalFindings:  len(all),
+	}
+}
+
+// truncatedMarker ends evidence content cut to types.MaxEvidenceBytes.
+const truncatedMarker = "\n[truncated]"
+
+// AppendEvidence appends scanner-captured evidence of kind to evidence, cutting content to
+// types.MaxEvidenceBytes. Blank content is skipped.
+func AppendEvidence(evidence []models.Evidence, kind, scanner, content string) []models.Evidence {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return evidence
+	}
+
+	return append(evidence, models.Evidence{Content: TruncateEvidence(content), Kind: kind, Source: scanner})
+}
+
+// TruncateEvidence cuts content to types.MaxEvidenceBytes, marking the cut.
+func TruncateEvidence(content string) string {
+	if len(content) <= types.MaxEvidenceBytes {
+		return content
+	}
+	cut := types.MaxEvidenceBytes - len(truncatedMarker)
+	for cut > 0 && !utf8.RuneStart(content[cut]) {
+		cut--
+	}
+
+	return content[:cut] + truncatedMarker
+}
+
+// MergeStored copies the ID, triage status, assignee, evidence and exploit intelligence of stored
+// findings onto the matching findings extracted again from the same output, so that summaries
+// show what was triaged, attached and enriched since. Each stored finding matches at most one
+// finding.
+func MergeStored(extracted, stored []models.Finding) []models.Finding {
+	byKey := make(map[string][]models.Finding, len(stored))
+	for _, finding := range stored {
+		key := findingKey(finding)
+		byKey[key] = append(byKey[key], finding)
+	}
+
+	merged := make([]models.Finding, len(extracted))
+	for i, finding := range extracted {
+		key := findingKey(finding)
+		if matches := byKey[key]; len(matches) > 0 {
+			match := matches[0]
+			byKey[key] = matches[1:]
+			finding.ID = match.ID
+			finding.Assignee = match.Assignee
+			finding.Status = match.Status
+			finding.Evidence = match.Evidence
+			finding.CVEs = match.CVEs
+			finding.EPSS = match.EPSS
+			finding.KEV = match.KEV
+		}
+		merged[i] = finding
+	}
+
+	return merged
+}
+
+// findingKey identifies a finding by what its scanner reported.
+func findingKey(finding models.Finding) string {
+	return strings.Join([]string{finding.Scanner, NormalizeSeverity(finding.Severity), finding.Title, finding.URL}, "\x00")
+}
+
+// affectedURLs returns the sorted unique URLs referenced by findings.
+func affectedURLs(findings []models.Finding) []string {
+	seen := make(map[string]struct{})
+	urls := make([]string, 0)
+	for _, finding := range findings {
+		if finding.URL == "" {
+			continue
+		}
+		if _, ok := seen[finding.URL]; ok {
+			continue
+		}
+		seen[finding.URL] = struct{}{}
+		urls = append(urls, finding.URL)
+	}
+	sort.Strings(urls)
+	if len(urls) > maxAffectedURLs {
+		urls = urls[:maxAffectedURLs]
+	}
+
+	return urls
+}
+
+// disagreements lists URL paths reported by only a subset of the successful scanners.
+func disagreements(sections []Section, findings []models.Finding, limit int) []Disagreement {
+	successful := make(map[string]struct{})
+	for _, section := range sections {
+		if section.Status == StatusSuccess {
+			successful[section.Scanner] = struct{}{}
+		}
+	}
+	if len(successful) < 2 { //nolint:mnd
+		return nil
+	}
+
+	reported := make(map[string]map[string]struct{})
+	for _, finding := range findings {
+		if finding.URL == "" {
+			continue
+		}
+		if _, ok := successful[finding.Scanner]; !ok {
+			continue
+		}
+		path := urlPath(finding.URL)
+		if reported[path] == nil {
+			reported[path] = make(map[string]struct{})
+		}
+		reported[path][finding.Scanner] = struct{}{}
+	}
+
+	paths := make([]string, 0, len(reported))
+	for path := range reported {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var result []Disagreement
+	for _, path := range paths {
+		if len(reported[path]) == len(successful) {
+			continue
+		}
+		disagreement := Disagreement{URL: path}
+		for scanner := range successful {
+			if _, ok := reported[path][scanner]; ok {
+				disagreement.ReportedBy = append(disagreement.ReportedBy, scanner)
+			} else {
+				disagreement.NotReportedBy = append(disagreement.NotReportedBy, scanner)
+			}
+		}
+		sort.Strings(disagreement.ReportedBy)
+		sort.Strings(disagreement.NotReportedBy)
+		result = append(result, disagreement)
+		if len(result) >= limit {
+			break
+		}
+	}
+
+	return result
+}
+
+// urlPath reduces a full URL or bare path to its path component for cross-scanner comparison.
+func urlPath(raw string) string {
+	parsed, err := url.Parse(raw)
+	switch {
+	case err != nil:
+		return raw
+	case parsed.Path != "":
+		return parsed.Path
+	case parsed.Host != "":
+		return "/"
+	default:
+		return raw
+	}
+}
@@ -0,0 +1,447 @@
+package findings
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/types"
+)
+
+const (
+	// alphaOutput is parsed by the generic bracketed severity tag extraction.
+	alphaOutput = `[exposed-git] [http] [high] http://example.com/.git/config
+[tech-detect] [http] [info] http://example.com/
+[INF] Templates loaded for current scan: 1000`
+
+	// betaOutput is parsed by pipeParser, registered for the beta scanner.
+	betaOutput = `info|Server: Apache|
+low|Missing header|/
+low|Admin area|/admin/`
+)
+
+// pipeParser parses "severity|title|url" lines and fails on malformed lines.
+func pipeParser(output string) ([]models.Finding, error) {
+	var found []models.Finding
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Split(line, "|")
+		if len(fields) != 3 {
+			return nil, errors.New("malformed line")
+		}
+		found = append(found, models.Finding{Scanner: "beta", Severity: fields[0], Title: fields[1], URL: fields[2]})
+	}
+
+	return found, nil
+}
+
+type FindingsTestSuite struct {
+	suite.Suite
+}
+
+func (s *FindingsTestSuite) SetupSuite() {
+	RegisterParser("beta", pipeParser)
+}
+
+func (s *FindingsTestSuite) TestNormalizeSeverity() {
+	s.Equal(types.SeverityHigh, NormalizeSeverity("HIGH"))
+	s.Equal(types.SeverityMedium, NormalizeSeverity("moderate"))
+	s.Equal(types.SeverityInfo, NormalizeSeverity("unknown"))
+	s.Equal(types.SeverityInfo, NormalizeSeverity(""))
+}
+
+func (s *FindingsTestSuite) TestSeverityRank() {
+	s.Greater(SeverityRank(types.SeverityCritical), SeverityRank(types.SeverityHigh))
+	s.Greater(SeverityRank(types.SeverityLow), SeverityRank(types.SeverityInfo))
+	s.Equal(0, SeverityRank("bogus"))
+}
+
+func (s *FindingsTestSuite) TestRiskScore() {
+	s.InDelta(0.0, RiskScore(map[string]int{}), 0.001)
+	s.InDelta(17.0, RiskScore(map[string]int{types.SeverityCritical: 1, types.SeverityHigh: 1, types.SeverityMedium: 1}), 0.001)
+	s.InDelta(1.0, RiskScore(map[string]int{types.SeverityLow: 2, types.SeverityInfo: 10}), 0.001)
+}
+
+func (s *FindingsTestSuite) TestScoreOutput() {
+	// One high (5) and one info (0) finding.
+	s.InDelta(5.0, ScoreOutput("alpha", alphaOutput), 0.001)
+	// One info (0) and two low (0.5) findings from the registered parser.
+	s.InDelta(1.0, ScoreOutput("beta", betaOutput), 0.001)
+}
+
+func (s *FindingsTestSuite) TestExtract_RegisteredParser() {
+	found := Extract("beta", betaOutput)
+	s.Require().Len(found, 3)
+	s.Equal(models.Finding{
+		Scanner:  "beta",
+		Severity: types.SeverityLow,
+		Title:    "Admin area",
+		URL:      "/admin/",
+	}, found[2])
+}
+
+func (s *FindingsTestSuite) TestExtract_ParserErrorFallsBackToGeneric() {
+	found := Extract("beta", "not a pipe line [medium] https://example.com/y")
+	s.Require().Len(found, 1)
+	s.Equal("beta", found[0].Scanner)
+	s.Equal(types.SeverityMedium, found[0].Severity)
+	s.Equal("https://example.com/y", found[0].URL)
+}
+
+func (s *FindingsTestSuite) TestExtract_Generic() {
+	found := Extract("other", "[template] [http] [critical] https://example.com/x\nnoise")
+	s.Require().Len(found, 1)
+	s.Equal(types.SeverityCritical, found[0].Severity)
+	s.Equal("https://example.com/x", found[0].URL)
+}
+
+func (s *FindingsTestSuite) TestSections_SingleTool() {
+	sections := Sections("nikto", "output")
+	s.Equal([]Section{{Output: "output", Scanner: "nikto", Status: StatusSuccess}}, sections)
+}
+
+func (s *FindingsTestSuite) TestSections_FullScanReport() {
+	report := `===============================================================================
+                    FULL SECURITY SCAN REPORT
+===============================================================================
+Target: http://example.com
+===============================================================================
+
+SCAN SUMMARY
+-------------------------------------------------------------------------------
+  nikto     : SUCCESS (1.00s)
+  wapiti    : FAILED (2.00s)
+
+===============================================================================
+                    NIKTO RESULTS
+===============================================================================
+
++ /admin/: Admin area.
+
+===============================================================================
+                    WAPITI RESULTS
+===============================================================================
+
+ERROR: failed to execute wapiti
+
+===============================================================================
+                    END OF REPORT
+===============================================================================
+`
+	sections := Sections(FullScanTool, report)
+	s.Require().Len(sections, 2)
+	s.Equal("nikto", sections[0].Scanner)
+	s.Equal(StatusSuccess, sections[0].Status)
+	s.Equal("+ /admin/: Admin area.", sections[0].Output)
+	s.Equal("wapiti", sections[1].Scanner)
+	s.Equal(StatusFailed, sections[1].Status)
+}
+
+func (s *FindingsTestSuite) TestSections_PortReport() {
+	report := `===============================================================================
+                    FULL SECURITY SCAN REPORT
+===============================================================================
+Target: example.com
+Ports: 80, 8080
+===============================================================================
+
+===============================================================================
+                    PORT: 80 (http://example.com)
+===============================================================================
+
+SCAN SUMMARY
+-------------------------------------------------------------------------------
+  nikto     : SUCCESS (1.00s)
+
+===============================================================================
+                    NIKTO RESULTS
+===============================================================================
+
++ /admin/: Admin area.
+
+===============================================================================
+                    PORT: 8080 (http://example.com:8080)
+===============================================================================
+
+SCAN SUMMARY
+-------------------------------------------------------------------------------
+  nikto     : SUCCESS (1.00s)
+
+===============================================================================
+                    NIKTO RESULTS
+===============================================================================
+
++ /login/: Login page.
+
+===============================================================================
+                    END OF REPORT
+===============================================================================
+`
+	sections := Sections(FullScanTool, report)
+	s.Require().Len(sections, 2)
+	s.Equal("+ /admin/: Admin area.", sections[0].Output)
+	s.Equal("+ /login/: Login page.", sections[1].Output)
+}
+
+func (s *FindingsTestSuite) TestSummarize() {
+	sections := []Section{
+		{Scanner: "alpha", Status: StatusSuccess, Output: alphaOutput},
+		{Scanner: "beta", Status: StatusSuccess, Output: betaOutput},
+	}
+
+	summary := Summarize(sections, 2, nil, nil)
+	s.Equal(5, summary.TotalFindings)
+	s.Len(summary.TopFindings, 2)
+	s.Equal(types.SeverityHigh, summary.TopFindings[0].Severity)
+	s.Equal(1, summary.SeverityCounts[types.SeverityHigh])
+	s.Equal(2, summary.SeverityCounts[types.SeverityInfo])
+	s.Equal(0, summary.SeverityCounts[types.SeverityCritical])
+	s.InDelta(6.0, summary.RiskScore, 0.001)
+	s.Contains(summary.AffectedURLs, "/admin/")
+	s.Len(summary.Scanners, 2)
+
+	// "/" is reported by both scanners, "/.git/config" and "/admin/" by one each.
+	s.Require().Len(summary.Disagreements, 2)
+	s.Equal("/.git/config", summary.Disagreements[0].URL)
+	s.Equal([]string{"alpha"}, summary.Disagreements[0].ReportedBy)
+	s.Equal([]string{"beta"}, summary.Disagreements[0].NotReportedBy)
+}
+
+func (s *FindingsTestSuite) TestSummarize_SingleScannerHasNoDisagreements() {
+	summary := Summarize([]Section{{Scanner: "alpha", Status: StatusSuccess, Output: alphaOutput}}, 0, nil, nil)
+	s.Empty(summary.Disagreements)
+	s.Len(summary.TopFindings, 2)
+}
+
+func (s *FindingsTestSuite) TestSummarize_PrioritizesExploited() {
+	output := `[exposed-git] [http] [high] http://example.com/.git/config
+[struts-rce] [http] [medium] http://example.com/struts`
+	stored := []models.Finding{
+		{ID: 3, Scanner: "alpha", Severity: "medium", Title: "[struts-rce] [http] [medium] http://example.com/struts", URL: "http://example.com/struts",
+			CVEs: []string{"CVE-2017-5638"}, EPSS: 0.5, KEV: true},
+	}
+
+	summary := Summarize([]Section{{Scanner: "alpha", Status: StatusSuccess, Output: output}}, 1, stored, nil)
+	s.Require().Len(summary.TopFindings, 1)
+	s.Equal(uint(3), summary.TopFindings[0].ID)
+	s.True(summary.TopFindings[0].KEV)
+	// The high finding (5) and the known exploited medium finding (2 * 1.5 * 2).
+	s.InDelta(11.0, summary.RiskScore, 0.001)
+}
+
+// titleSuppressor suppresses findings whose title contains its text.
+type titleSuppressor string
+
+func (t titleSuppressor) Suppressed(finding models.Finding) bool {
+	return strings.Contains(finding.Title, string(t))
+}
+
+func (s *FindingsTestSuite) TestSummarize_Suppressed() {
+	output := `[exposed-git] [http] [high] http://example.com/.git/config
+[tech-detect] [http] [info] http://example.com/`
+
+	summary := Summarize([]Section{{Scanner: "alpha", Status: StatusSuccess, Output: output}}, 0, nil, titleSuppressor("tech-detect"))
+	s.Equal(1, summary.TotalFindings)
+	s.Equal(1, summary.Suppressed)
+	s.Zero(summary.SeverityCounts[types.SeverityInfo])
+	s.Require().Len(summary.TopFindings, 1)
+	s.Equal(types.SeverityHigh, summary.TopFindings[0].Severity)
+}
+
+func (s *FindingsTestSuite) TestCompare() {
+	staging := []models.Finding{
+		{Scanner: "nuclei", Severity: types.SeverityHigh, TemplateID: "exposed-git", Title: "Exposed Git", URL: "https://staging.example.com/.git/config"},
+		{Scanner: "nikto", Severity: types.SeverityLow, Title: "/admin/: Admin login page found.", URL: "https://staging.example.com/admin/"},
+		{Scanner: "alpha", Severity: types.SeverityInfo, Title: "[tech] [http] [info] https://staging.example.com/", URL: "https://staging.example.com/"},
+		{Scanner: "nikto", Severity: types.SeverityMedium, Title: "/debug/: Debug console.", URL: "https://staging.example.com/debug/"},
+	}
+	production := []models.Finding{
+		{Scanner: "nuclei", Severity: types.SeverityHigh, TemplateID: "exposed-git", Title: "Exposed Git", URL: "https://www.example.com/.git/config"},
+		{Scanner: "alpha", Severity: types.SeverityInfo, Title: "[tech] [http] [info] https://www.example.com/", URL: "https://www.example.com/"},
+		{Scanner: "nikto", Severity: types.SeverityLow, Title: "/admin/: Admin login page found.", URL: "https://www.example.com/admin/"},
+		{Scanner: "nikto", Severity: types.SeverityLow, Title: "/admin/: Admin login page found.", URL: "https://www.example.com/admin/"},
+		{Scanner: "shcheck", Severity: types.SeverityLow, Title: "Missing security header: Strict-Transport-Security", URL: "https://www.example.com"},
+	}
+
+	comparison := Compare(staging, production)
+	s.Equal(3, comparison.Shared)
+	s.Equal([]models.Finding{staging[3]}, comparison.OnlyInBase)
+	// The duplicate admin finding matches once; the low findings keep their order.
+	s.Equal([]models.Finding{production[3], production[4]}, comparison.OnlyInOther)
+
+	empty := Compare(nil, nil)
+	s.Zero(empty.Shared)
+	s.NotNil(empty.OnlyInBase)
+	s.NotNil(empty.OnlyInOther)
+}
+
+func (s *FindingsTestSuite) TestFingerprint_ScannerVersions() {
+	const target = "https://www.example.com"
+	same := func(a, b models.Finding, msg string) {
+		s.Equal(Fingerprint(target, a), Fingerprint(target, b), msg)
+	}
+
+	same(models.Finding{Scanner: "nikto", Title: "OSVDB-3092: /admin/: This might be interesting.", URL: "/admin/"},
+		models.Finding{Scanner: "nikto", Title: "/admin: This might be interesting", URL: "https://www.example.com//admin"},
+		"OSVDB IDs, path prefixes, punctuation and trailing slashes do not matter")
+	same(models.Finding{Scanner: "nikto", Title: "Apache/2.4.41 appears to be outdated (current is at least Apache/2.4.54).", URL: "/"},
+		models.Finding{Scanner: "Nikto", Title: "Apache/2.4.41 appears to be outdated (current is at least Apache/2.4.58)", URL: "/"},
+		"version numbers do not matter")
+	same(models.Finding{Scanner: "wapiti", TemplateID: "SQL Injection", Title: "SQL Injection: via injection in the parameter id", URL: "/item", Parameter: "id"},
+		models.Finding{Scanner: "wapiti", TemplateID: "sql injection", Title: "SQL Injection: SQL injection (DBMS: MySQL) in parameter id", URL: "/item", Parameter: "id"},
+		"template IDs are matched case-insensitively, whatever the title")
+	same(models.Finding{Scanner: "nuclei", TemplateID: "xss", URL: "https://www.example.com/search?q=1&page=2"},
+		models.Finding{Scanner: "nuclei", TemplateID: "xss", URL: "https://www.example.com/search?page=7&q=%3Cx%3E"},
+		"query values and parameter order do not matter")
+
+	sqli := models.Finding{Scanner: "wapiti", TemplateID: "SQL Injection", URL: "/item", Parameter: "id"}
+	other := sqli
+	other.Parameter = "sort"
+	s.NotEqual(Fingerprint(target, sqli), Fingerprint(target, other), "parameters differ")
+	s.NotEqual(Fingerprint(target, models.Finding{Scanner: "nuclei", TemplateID: "xss", URL: "/search?q=1"}),
+		Fingerprint(target, models.Finding{Scanner: "nuclei", TemplateID: "xss", URL: "/search?name=1"}),
+		"query parameters differ")
+}
+
+func (s *FindingsTestSuite) TestFingerprint() {
+	admin := models.Finding{Scanner: "nikto", Severity: types.SeverityLow, Title: "/admin/: Admin login page found.", URL: "https://www.example.com/admin/"}
+	fingerprint := Fingerprint("https://www.example.com", admin)
+	s.Len(fingerprint, 2*fingerprintSize)
+
+	rescanned := admin
+	rescanned.Severity = types.SeverityMedium
+	rescanned.Evidence = []models.Evidence{{Kind: models.EvidenceNote, Content: "seen again"}}
+	s.Equal(fingerprint, Fingerprint("HTTPS://www.example.com", rescanned), "severity and evidence do not matter")
+
+	reworded := models.Finding{Scanner: "alpha", Title: "[tech] [http] [info] https://www.example.com/"}
+	s.Equal(Fingerprint("https://www.example.com", reworded),
+		Fingerprint("https://www.example.com", models.Finding{Scanner: "alpha", Title: "[tech] [http] [info] http://10.0.0.1/"}),
+		"origins in titles do not matter")
+
+	s.NotEqual(fingerprint, Fingerprint("https://staging.example.com", admin), "targets differ")
+	other := admin
+	other.URL = "https://www.example.com/backup/"
+	s.NotEqual(fingerprint, Fingerprint("https://www.example.com", other), "paths differ")
+	other = admin
+	other.Scanner = "wapiti"
+	s.NotEqual(fingerprint, Fingerprint("https://www.example.com", other), "scanners differ")
+
+	found := []models.Finding{admin, {Scanner: "nikto", Title: "kept", Fingerprint: "preset"}}
+	SetFingerprints("https://www.example.com", found)
+	s.Equal(fingerprint, found[0].Fingerprint)
+	s.Equal("preset", found[1].Fingerprint)
+}
+
+func (s *FindingsTestSuite) TestUnseen() {
+	found := []models.Finding{
+		{Title: "old", Fingerprint: "a"},
+		{Title: "new", Fingerprint: "b"},
+		{Title: "duplicate", Fingerprint: "b"},
+		{Title: "unfingerprinted"},
+	}
+	s.Equal([]string{"a", "b"}, Fingerprints(found))
+
+	unseen := Unseen(found, map[string]bool{"a": true})
+	s.Require().Len(unseen, 2)
+	s.Equal("new", unseen[0].Title)
+	s.Equal("unfingerprinted", unseen[1].Title)
+	s.Empty(Unseen(nil, nil))
+}
+
+func (s *FindingsTestSuite) TestFindingRisk() {
+	s.InDelta(5.0, FindingRisk(models.Finding{Severity: types.SeverityHigh}), 0.001)
+	s.InDelta(7.5, FindingRisk(models.Finding{Severity: types.SeverityHigh, EPSS: 0.5}), 0.001)
+	s.InDelta(15.0, FindingRisk(models.Finding{Severity: types.SeverityHigh, EPSS: 0.5, KEV: true}), 0.001)
+	s.InDelta(0.0, FindingRisk(models.Finding{Severity: types.SeverityInfo, KEV: true}), 0.001)
+
+	found := []models.Finding{
+		{Severity: types.SeverityCritical},
+		{Severity: types.SeverityLow, KEV: true},
+	}
+	s.InDelta(RiskScore(CountBySeverity(found[:1])), ScoreFindings(found[:1]), 0.001)
+	s.InDelta(11.0, ScoreFindings(found), 0.001)
+}
+
+func (s *FindingsTestSuite) TestPrioritize() {
+	found := []models.Finding{
+		{Title: "info", Severity: types.SeverityInfo},
+		{Title: "low", Severity: types.SeverityLow},
+		{Title: "high", Severity: types.SeverityHigh},
+		{Title: "medium-kev", Severity: types.SeverityMedium, EPSS: 0.9, KEV: true},
+		{Title: "unknown", Severity: "bogus"},
+	}
+
+	Prioritize(found)
+	titles := make([]string, 0, len(found))
+	for _, finding := range found {
+		titles = append(titles, finding.Title)
+	}
+	s.Equal([]string{"medium-kev", "high", "low", "info", "unknown"}, titles)
+}
+
+func (s *FindingsTestSuite) TestCVEIDs() {
+	s.Empty(CVEIDs(models.Finding{Title: "Missing header"}))
+	s.Equal([]string{"CVE-2021-44228", "CVE-2017-5638", "CVE-2019-0708"}, CVEIDs(models.Finding{
+		CVEs:       []string{"cve-2021-44228", "not-a-cve"},
+		Title:      "Apache Struts RCE (CVE-2017-5638, cve-2021-44228)",
+		References: []string{"https://nvd.nist.gov/vuln/detail/CVE-2019-0708"},
+	}))
+}
+
+func (s *FindingsTestSuite) TestCWEIDs() {
+	s.Empty(CWEIDs(models.Finding{Title: "Missing header"}))
+	s.Equal([]string{"CWE-79", "CWE-89", "CWE-22"}, CWEIDs(models.Finding{
+		CWEs:       []string{"cwe-79", "not-a-cwe"},
+		Title:      "SQL injection (CWE-89, CWE-79)",
+		References: []string{"https://cwe.mitre.org/data/definitions/22.html"},
+	}))
+
+	found := Extract("unparsed", "[xss] [http] [high] http://localhost/ CWE-79")
+	s.Require().Len(found, 1)
+	s.Equal([]string{"CWE-79"}, found[0].CWEs)
+}
+
+func (s *FindingsTestSuite) TestAppendEvidence() {
+	evidence := AppendEvidence(nil, models.EvidenceRequest, "alpha", "  GET / HTTP/1.1\n")
+	evidence = AppendEvidence(evidence, models.EvidenceResponse, "alpha", " \n")
+	s.Equal([]models.Evidence{{Content: "GET / HTTP/1.1", Kind: models.EvidenceRequest, Source: "alpha"}}, evidence)
+
+	s.Equal("short", TruncateEvidence("short"))
+	long := TruncateEvidence(strings.Repeat("é", types.MaxEvidenceBytes))
+	s.LessOrEqual(len(long), types.MaxEvidenceBytes)
+	s.True(strings.HasSuffix(long, "\n[truncated]"))
+	s.True(utf8.ValidString(long))
+}
+
+func (s *FindingsTestSuite) TestMergeStored() {
+	extracted := []models.Finding{
+		{Scanner: "alpha", Severity: "high", Title: "Exposed Git", URL: "/.git/config"},
+		{Scanner: "alpha", Severity: "low", Title: "Missing header", URL: "/"},
+		{Scanner: "alpha", Severity: "low", Title: "Missing header", URL: "/"},
+	}
+	stored := []models.Finding{
+		{ID: 7, Scanner: "alpha", Severity: "low", Title: "Missing header", URL: "/", Status: models.FindingAccepted},
+		{ID: 5, Scanner: "alpha", Severity: "high", Title: "Exposed Git", URL: "/.git/config", Assignee: "alice",
+			Evidence: []models.Evidence{{Kind: models.EvidenceNote, Content: "confirmed", Source: models.EvidenceSourceManual}},
+			CVEs:     []string{"CVE-2020-1234"}, EPSS: 0.2, KEV: true},
+	}
+
+	merged := MergeStored(extracted, stored)
+	s.Require().Len(merged, 3)
+	s.Equal(uint(5), merged[0].ID)
+	s.Equal("alice", merged[0].Assignee)
+	s.Len(merged[0].Evidence, 1)
+	s.Equal([]string{"CVE-2020-1234"}, merged[0].CVEs)
+	s.True(merged[0].KEV)
+	s.Equal(uint(7), merged[1].ID)
+	s.Equal(models.FindingAccepted, merged[1].Status)
+	// Each stored finding matches once.
+	s.Zero(merged[2].ID)
+	s.Empty(extracted[0].Assignee)
+}
+
+func TestFindingsTestSuite(t *testing.T) {
+	suite.Run(t, new(FindingsTestSuite))
+}
@@ -0,0 +1,138 @@
+package findings
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+)
+
+// fingerprintSize is the size of finding fingerprints in bytes, before hex encoding.
+const fingerprintSize = 16
+
+var (
+	// originPattern matches the scheme and authority of URLs embedded in finding titles.
+	originPattern = regexp.MustCompile(`(?i)\bhttps?://[^/\s]+`)
+	// osvdbPattern matches the OSVDB IDs nikto prefixed its messages with before version 2.5.
+	osvdbPattern = regexp.MustCompile(`(?i)\bOSVDB-\d+:?`)
+	// pathPrefixPattern matches the path nikto messages start with, e.g. "/admin/: ".
+	pathPrefixPattern = regexp.MustCompile(`^\s*/\S*?:\s+`)
+	// versionPattern matches version numbers, such as the current releases named by outdated
+	// software checks, which change with the scanner databases.
+	versionPattern = regexp.MustCompile(`(?i)\bv?\d+(?:[._-]\d+)+[a-z]*\b`)
+)
+
+// Fingerprint identifies a finding of a scan of target, the scanned URL, across scans: the same
+// check of the same scanner at the same URL path and parameter of the target has the same
+// fingerprint, whatever its title wording, severity or evidence in a given scan. Checks, paths
+// and parameters are normalized (see check, findingPath and findingParameter), so that scanner
+// upgrades and the order of results do not change fingerprints.
+func Fingerprint(target string, finding models.Finding) string {
+	key := strings.Join([]string{
+		strings.ToLower(target), strings.ToLower(finding.Scanner), check(finding), findingPath(finding), findingParameter(finding),
+	}, "\x00")
+	digest := sha256.Sum256([]byte(key))
+
+	return hex.EncodeToString(digest[:fingerprintSize])
+}
+
+// check identifies the scanner check that raised finding: its lower-cased template ID, or
+// otherwise its title without the origins of embedded URLs, severity tags, OSVDB IDs, a leading
+// path, version numbers and punctuation, lower-cased.
+func check(finding models.Finding) string {
+	if id := strings.TrimSpace(finding.TemplateID); id != "" {
+		return strings.ToLower(id)
+	}
+
+	title := originPattern.ReplaceAllString(finding.Title, "")
+	title = severityTagRe.ReplaceAllString(title, "")
+	title = osvdbPattern.ReplaceAllString(title, "")
+	title = pathPrefixPattern.ReplaceAllString(title, "")
+	title = versionPattern.ReplaceAllString(title, "")
+	words := strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '/'
+	})
+
+	return strings.Join(words, " ")
+}
+
+// findingPath returns the cleaned URL path of finding, without duplicate or trailing slashes, and
+// empty for findings without a URL.
+func findingPath(finding models.Finding) string {
+	if finding.URL == "" {
+		return ""
+	}
+
+	return path.Clean("/" + urlPath(finding.URL))
+}
+
+// findingParameter returns the request parameter finding concerns: its Parameter, or otherwise
+// the sorted query parameter names of its URL, whose values vary between scans.
+func findingParameter(finding models.Finding) string {
+	if finding.Parameter != "" {
+		return finding.Parameter
+	}
+	parsed, err := url.Parse(finding.URL)
+	if err != nil || parsed.RawQuery == "" {
+		return ""
+	}
+	query, err := url.ParseQuery(parsed.RawQuery)
+	if err != nil {
+		return ""
+	}
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	return strings.Join(names, ",")
+}
+
+// SetFingerprints sets the fingerprint of the findings of a scan of target that have none.
+func SetFingerprints(target string, found []models.Finding) {
+	for i := range found {
+		if found[i].Fingerprint == "" {
+			found[i].Fingerprint = Fingerprint(target, found[i])
+		}
+	}
+}
+
+// Fingerprints returns the unique fingerprints of found, skipping findings without one.
+func Fingerprints(found []models.Finding) []string {
+	seen := make(map[string]struct{}, len(found))
+	fingerprints := make([]string, 0, len(found))
+	for _, finding := range found {
+		if _, ok := seen[finding.Fingerprint]; ok || finding.Fingerprint == "" {
+			continue
+		}
+		seen[finding.Fingerprint] = struct{}{}
+		fingerprints = append(fingerprints, finding.Fingerprint)
+	}
+
+	return fingerprints
+}
+
+// Unseen returns the findings of found whose fingerprint is not in seen, one per fingerprint.
+// Findings without a fingerprint are always returned.
+func Unseen(found []models.Finding, seen map[string]bool) []models.Finding {
+	unseen := make([]models.Finding, 0, len(found))
+	reported := make(map[string]struct{}, len(found))
+	for _, finding := range found {
+		if finding.Fingerprint != "" {
+			if _, ok := reported[finding.Fingerprint]; ok || seen[finding.Fingerprint] {
+				continue
+			}
+			reported[finding.Fingerprint] = struct{}{}
+		}
+		unseen = append(unseen, finding)
+	}
+
+	return unseen
+}
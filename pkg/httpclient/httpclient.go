@@ -0,0 +1,234 @@
+// Package httpclient provides the SSRF-hardened HTTP client shared by the built-in Go scanners:
+// target normalization, HTTP(S) probing and webhook notifications. Requests to link-local and
+// cloud metadata addresses are refused unless the host is explicitly in scope, every redirect is
+// validated the same way as the first request, and addresses are checked again when connecting,
+// so that DNS answers changing between the check and the connection cannot bypass the policy.
+package httpclient
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/tb0hdan/wass-mcp/pkg/types"
+)
+
+var (
+	// ErrBlockedAddress is returned for requests to addresses outside the policy, such as the
+	// 169.254.169.254 cloud metadata service.
+	ErrBlockedAddress = errors.New("address is blocked")
+	// ErrUnsupportedScheme is returned for requests and redirects to schemes other than http(s).
+	ErrUnsupportedScheme = errors.New("unsupported URL scheme")
+)
+
+// blockedPrefixes are the networks requests are refused to unless in scope: link-local networks,
+// which host the metadata services of most clouds, the metadata addresses outside them,
+// unspecified addresses, which reach the local host, and multicast and broadcast addresses.
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("169.254.0.0/16"),
+	netip.MustParsePrefix("100.100.100.200/32"),
+	netip.MustParsePrefix("224.0.0.0/4"),
+	netip.MustParsePrefix("255.255.255.255/32"),
+	netip.MustParsePrefix("::/128"),
+	netip.MustParsePrefix("fe80::/10"),
+	netip.MustParsePrefix("fd00:ec2::254/128"),
+	netip.MustParsePrefix("ff00::/8"),
+}
+
+// Blocked reports whether addr is in a blocked network. IPv4-mapped IPv6 addresses are checked as
+// IPv4 addresses.
+func Blocked(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// Config configures a client.
+type Config struct {
+	// FollowRedirects makes the client follow up to MaxRedirects redirects, each validated like
+	// the first request. Otherwise the redirect response is returned.
+	FollowRedirects bool
+	// MaxRedirects bounds followed redirects, types.MaxRedirects when zero.
+	MaxRedirects int
+	// Proxy selects the proxy of a request, e.g. http.ProxyFromEnvironment; nil for none.
+	Proxy func(*http.Request) (*url.URL, error)
+	// Scope are the hosts explicitly in scope, such as the scan target, which may resolve to
+	// blocked addresses. Entries are host names, IP addresses or CIDR networks.
+	Scope []string
+	// TLS configures TLS connections, the default configuration when nil.
+	TLS *tls.Config
+	// Timeout bounds each request including redirects, none when zero.
+	Timeout time.Duration
+	// Redirect is called for every redirect after it was validated, e.g. to adjust its headers.
+	Redirect func(req *http.Request, via []*http.Request) error
+}
+
+// policy decides which hosts and addresses requests may reach.
+type policy struct {
+	hosts    map[string]struct{}
+	networks []netip.Prefix
+	resolver *net.Resolver
+}
+
+// newPolicy returns the policy allowing the scope entries.
+func newPolicy(scope []string) *policy {
+	p := &policy{hosts: make(map[string]struct{}), resolver: net.DefaultResolver}
+	for _, entry := range scope {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if prefix, err := netip.ParsePrefix(entry); err == nil {
+			p.networks = append(p.networks, prefix.Masked())
+			continue
+		}
+		if addr, err := netip.ParseAddr(strings.Trim(entry, "[]")); err == nil {
+			p.networks = append(p.networks, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+		p.hosts[strings.ToLower(strings.TrimSuffix(entry, "."))] = struct{}{}
+	}
+
+	return p
+}
+
+// inScope reports whether host was put in scope by name.
+func (p *policy) inScope(host string) bool {
+	_, ok := p.hosts[strings.ToLower(strings.TrimSuffix(host, "."))]
+	return ok
+}
+
+// allowed reports whether addr, reached through host, may be connected to.
+func (p *policy) allowed(host string, addr netip.Addr) bool {
+	if !Blocked(addr) || p.inScope(host) {
+		return true
+	}
+	for _, network := range p.networks {
+		if network.Contains(addr.Unmap()) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkURL validates the scheme of u and the addresses its host resolves to.
+func (p *policy) checkURL(ctx context.Context, u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%w: %q", ErrUnsupportedScheme, u.Scheme)
+	}
+
+	host := u.Hostname()
+	if p.inScope(host) {
+		return nil
+	}
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return p.checkAddr(host, addr)
+	}
+
+	addrs, err := p.resolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		// Leave resolution failures to the connection, which reports them as usual.
+		return nil //nolint:nilerr
+	}
+	for _, addr := range addrs {
+		if err := p.checkAddr(host, addr); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkAddr returns ErrBlockedAddress when addr, reached through host, is not allowed.
+func (p *policy) checkAddr(host string, addr netip.Addr) error {
+	if p.allowed(host, addr) {
+		return nil
+	}
+	if host == addr.String() {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, addr)
+	}
+	return fmt.Errorf("%w: %s resolves to %s", ErrBlockedAddress, host, addr)
+}
+
+// dialContext connects to addr, checking the address actually connected to against the policy.
+func (p *policy) dialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address %q: %w", addr, err)
+	}
+
+	dialer := &net.Dialer{
+		Control: func(_, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return fmt.Errorf("invalid address %q: %w", address, err)
+			}
+			return p.checkAddr(host, addrPort.Addr())
+		},
+	}
+
+	return dialer.DialContext(ctx, network, addr)
+}
+
+// guardedTransport validates every request, including redirects, before sending it.
+type guardedTransport struct {
+	policy    *policy
+	transport http.RoundTripper
+}
+
+func (g *guardedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := g.policy.checkURL(req.Context(), req.URL); err != nil {
+		return nil, err
+	}
+	return g.transport.RoundTrip(req)
+}
+
+// New creates a client enforcing the SSRF policy with cfg. Proxied requests are checked before
+// they are sent; direct connections are also checked when connecting.
+func New(cfg Config) *http.Client {
+	guard := newPolicy(cfg.Scope)
+	maxRedirects := cfg.MaxRedirects
+	if maxRedirects == 0 {
+		maxRedirects = types.MaxRedirects
+	}
+
+	return &http.Client{
+		Transport: &guardedTransport{
+			policy: guard,
+			transport: &http.Transport{
+				DialContext:     guard.dialContext,
+				Proxy:           cfg.Proxy,
+				TLSClientConfig: cfg.TLS,
+			},
+		},
+		Timeout: cfg.Timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if !cfg.FollowRedirects {
+				return http.ErrUseLastResponse
+			}
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+				return fmt.Errorf("%w: redirect to %q", ErrUnsupportedScheme, req.URL.Scheme)
+			}
+			if cfg.Redirect != nil {
+				return cfg.Redirect(req, via)
+			}
+			return nil
+		},
+	}
+}
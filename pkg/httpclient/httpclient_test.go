@@ -0,0 +1,118 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type HTTPClientTestSuite struct {
+	suite.Suite
+}
+
+// redirectServer returns a server redirecting every request to location.
+func (s *HTTPClientTestSuite) redirectServer(location string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/final" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		http.Redirect(w, r, location, http.StatusFound)
+	}))
+	s.T().Cleanup(server.Close)
+
+	return server
+}
+
+func (s *HTTPClientTestSuite) get(client *http.Client, target string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, target, nil)
+	s.Require().NoError(err)
+
+	resp, err := client.Do(req)
+	if err == nil {
+		_ = resp.Body.Close()
+	}
+	return resp, err
+}
+
+func (s *HTTPClientTestSuite) TestBlocked() {
+	for _, addr := range []string{
+		"169.254.169.254", "169.254.170.2", "100.100.100.200", "0.0.0.0", "224.0.0.1",
+		"255.255.255.255", "::", "fe80::1", "fd00:ec2::254", "ff02::1", "::ffff:169.254.169.254",
+	} {
+		s.True(Blocked(netip.MustParseAddr(addr)), addr)
+	}
+	for _, addr := range []string{"127.0.0.1", "10.0.0.5", "192.168.1.1", "8.8.8.8", "::1", "2001:db8::1"} {
+		s.False(Blocked(netip.MustParseAddr(addr)), addr)
+	}
+}
+
+func (s *HTTPClientTestSuite) TestPolicy_Scope() {
+	metadata := netip.MustParseAddr("169.254.169.254")
+
+	s.False(newPolicy(nil).allowed("169.254.169.254", metadata))
+	s.True(newPolicy([]string{"169.254.169.254"}).allowed("169.254.169.254", metadata))
+	s.True(newPolicy([]string{"169.254.0.0/16"}).allowed("metadata.internal", metadata))
+	s.True(newPolicy([]string{"Metadata.Internal."}).allowed("metadata.internal", metadata), "host names match case-insensitively")
+	s.False(newPolicy([]string{"app.example.com"}).allowed("metadata.internal", metadata))
+	s.True(newPolicy(nil).allowed("app.example.com", netip.MustParseAddr("10.0.0.5")), "private networks are not blocked")
+}
+
+func (s *HTTPClientTestSuite) TestNew_BlocksMetadata() {
+	_, err := s.get(New(Config{}), "http://169.254.169.254/latest/meta-data/")
+	s.ErrorIs(err, ErrBlockedAddress)
+
+	_, err = s.get(New(Config{}), "http://[fd00:ec2::254]/latest/meta-data/")
+	s.ErrorIs(err, ErrBlockedAddress)
+}
+
+func (s *HTTPClientTestSuite) TestNew_ValidatesRedirects() {
+	server := s.redirectServer("http://169.254.169.254/latest/meta-data/")
+	_, err := s.get(New(Config{FollowRedirects: true}), server.URL)
+	s.ErrorIs(err, ErrBlockedAddress, "redirects to metadata services are refused")
+
+	server = s.redirectServer("ftp://files.example.com/")
+	_, err = s.get(New(Config{FollowRedirects: true}), server.URL)
+	s.ErrorIs(err, ErrUnsupportedScheme)
+
+	server = s.redirectServer("/final")
+	resp, err := s.get(New(Config{FollowRedirects: true}), server.URL)
+	s.Require().NoError(err)
+	s.Equal(http.StatusOK, resp.StatusCode)
+
+	resp, err = s.get(New(Config{}), server.URL)
+	s.Require().NoError(err)
+	s.Equal(http.StatusFound, resp.StatusCode, "redirects are returned unless followed")
+}
+
+func (s *HTTPClientTestSuite) TestNew_MaxRedirects() {
+	server := s.redirectServer("/again")
+	_, err := s.get(New(Config{FollowRedirects: true, MaxRedirects: 2}), server.URL)
+	s.ErrorContains(err, "stopped after 2 redirects")
+}
+
+func (s *HTTPClientTestSuite) TestNew_RedirectHook() {
+	server := s.redirectServer("/final")
+	var hosts []string
+	client := New(Config{FollowRedirects: true, Redirect: func(req *http.Request, _ []*http.Request) error {
+		hosts = append(hosts, req.URL.Host)
+		return nil
+	}})
+
+	_, err := s.get(client, server.URL)
+	s.Require().NoError(err)
+	s.Len(hosts, 1)
+}
+
+func (s *HTTPClientTestSuite) TestDialContext_ChecksConnectedAddress() {
+	_, err := newPolicy(nil).dialContext(context.Background(), "tcp", "169.254.169.254:80")
+	s.ErrorIs(err, ErrBlockedAddress, "addresses are checked again when connecting")
+}
+
+func TestHTTPClientTestSuite(t *testing.T) {
+	suite.Run(t, new(HTTPClientTestSuite))
+}
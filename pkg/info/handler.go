@@ -0,0 +1,195 @@
+package info
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	// ContentTypeJSON is the JSON rendering of the capability document, served by default.
+	ContentTypeJSON = "application/json"
+	// ContentTypeText is the plain text rendering of the capability document.
+	ContentTypeText = "text/plain"
+)
+
+// offers are the supported renderings in order of preference.
+var offers = []string{ContentTypeJSON, ContentTypeText}
+
+// ServeHTTP serves the capability document, rendered as JSON or plain text according to the
+// Accept header. Requests accepting neither rendering get 406 Not Acceptable.
+func (p *Provider) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Vary", "Accept")
+
+	contentType := Negotiate(r.Header.Get("Accept"))
+	if contentType == "" {
+		http.Error(w, "supported content types: "+strings.Join(offers, ", "), http.StatusNotAcceptable)
+		return
+	}
+
+	doc, err := p.Document(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if contentType == ContentTypeText {
+		w.Header().Set("Content-Type", ContentTypeText+"; charset=utf-8")
+		writeText(w, doc)
+		return
+	}
+
+	w.Header().Set("Content-Type", ContentTypeJSON)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	_ = encoder.Encode(doc)
+}
+
+// ToolVersionsHandler serves the tool versions report of the scanners as JSON, see ToolVersions.
+// It answers 503 Service Unavailable when a bundled binary does not match its recorded version, so
+// that it can back container health checks.
+func (p *Provider) ToolVersionsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := ToolVersions(r.Context(), p.registry.Scanners()...)
+
+		w.Header().Set("Content-Type", ContentTypeJSON)
+		if !report.Healthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		_ = encoder.Encode(report)
+	})
+}
+
+// Negotiate picks the supported content type preferred by an Accept header value. An empty header
+// accepts anything. Among equally weighted media ranges, more specific ones win, then the order of
+// offers. It returns an empty string when no supported content type is acceptable.
+func Negotiate(accept string) string {
+	if strings.TrimSpace(accept) == "" {
+		return offers[0]
+	}
+
+	var (
+		best        string
+		bestQuality float64
+	)
+	for _, offer := range offers {
+		quality := offerQuality(accept, offer)
+		if quality > bestQuality {
+			best, bestQuality = offer, quality
+		}
+	}
+
+	return best
+}
+
+// offerQuality returns the quality the Accept header value gives to offer, taken from the most
+// specific media range matching it. Unmatched offers have quality zero.
+func offerQuality(accept, offer string) float64 {
+	offerType, _, _ := strings.Cut(offer, "/")
+
+	var (
+		quality     float64
+		specificity = -1
+	)
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		rangeSpecificity := -1
+		switch {
+		case mediaType == offer:
+			rangeSpecificity = 2 //nolint:mnd
+		case mediaType == offerType+"/*":
+			rangeSpecificity = 1
+		case mediaType == "*/*":
+			rangeSpecificity = 0
+		}
+		if rangeSpecificity <= specificity {
+			continue
+		}
+
+		specificity = rangeSpecificity
+		quality = 1
+		if value, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				quality = parsed
+			}
+		}
+	}
+
+	return quality
+}
+
+// writeText renders the capability document as human-readable text.
+func writeText(w io.Writer, doc Document) {
+	_, _ = fmt.Fprintf(w, "%s %s - %s\n\n", doc.Name, doc.Version, doc.Service)
+
+	transport := doc.Transport.Type
+	if doc.Transport.Stateless {
+		transport += " (stateless)"
+	}
+	_, _ = fmt.Fprintf(w, "MCP endpoint: %s\nTransport: %s\nAuth: %s\n", doc.Transport.Endpoint, transport, doc.Auth)
+
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0) //nolint:mnd
+
+	_, _ = fmt.Fprintf(table, "\nTools:\n")
+	for _, tool := range doc.Tools {
+		_, _ = fmt.Fprintf(table, "  %s\t%s\n", tool.Name, tool.Description)
+	}
+
+	_, _ = fmt.Fprintf(table, "\nScanners:\n")
+	for _, scanner := range doc.Scanners {
+		status := "unavailable"
+		switch {
+		case !scanner.Enabled:
+			status = "disabled"
+		case scanner.Available:
+			status = "available"
+		}
+		version := scanner.Version
+		if version == "" {
+			version = "-"
+		}
+		_, _ = fmt.Fprintf(table, "  %s\t%s\t%s\toptions: %s\n",
+			scanner.Name, status, version, strings.Join(scanner.Options, ", "))
+		if scanner.Diagnostics != "" {
+			_, _ = fmt.Fprintf(table, "    diagnostics: %s\n", scanner.Diagnostics)
+		}
+	}
+
+	_, _ = fmt.Fprintf(table, "\nLimits:\n")
+	for _, limit := range []struct {
+		name  string
+		value int
+		zero  string
+	}{
+		{"compress_threshold", doc.Limits.CompressThreshold, "never"},
+		{"max_concurrent_scans", doc.Limits.MaxConcurrentScans, ""},
+		{"max_lines", doc.Limits.MaxLines, ""},
+		{"max_options", doc.Limits.MaxOptions, ""},
+		{"max_output_bytes", doc.Limits.MaxOutputBytes, ""},
+		{"max_ports", doc.Limits.MaxPorts, ""},
+		{"max_response_bytes", doc.Limits.MaxResponseBytes, ""},
+		{"max_vhosts", doc.Limits.MaxVhosts, ""},
+	} {
+		value := strconv.Itoa(limit.value)
+		if limit.value == 0 {
+			value = "unlimited"
+			if limit.zero != "" {
+				value = limit.zero
+			}
+		}
+		_, _ = fmt.Fprintf(table, "  %s\t%s\n", limit.name, value)
+	}
+
+	_ = table.Flush()
+}
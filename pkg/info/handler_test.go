@@ -0,0 +1,130 @@
+package info
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/bundle"
+	"github.com/tb0hdan/wass-mcp/pkg/server"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+)
+
+type HandlerTestSuite struct {
+	suite.Suite
+	provider *Provider
+}
+
+func (s *HandlerTestSuite) SetupTest() {
+	srv := server.NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}, nil)
+	scanner := &plainScanner{name: "alpha", available: true}
+	s.Require().NoError(scanner.Register(srv))
+
+	s.provider = New(srv, Config{
+		Name:      "wass-mcp",
+		Service:   "Test Service",
+		Transport: Transport{Endpoint: "/mcp", Stateless: true, Type: TransportStreamableHTTP},
+		Version:   "1.0.0",
+	}, tools.NewRegistry(scanner))
+}
+
+func (s *HandlerTestSuite) serve(accept string) *httptest.ResponseRecorder {
+	req := httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/", nil)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	rec := httptest.NewRecorder()
+	s.provider.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func (s *HandlerTestSuite) TestNegotiate() {
+	s.Equal(ContentTypeJSON, Negotiate(""))
+	s.Equal(ContentTypeJSON, Negotiate("*/*"))
+	s.Equal(ContentTypeJSON, Negotiate("application/json"))
+	s.Equal(ContentTypeText, Negotiate("text/plain"))
+	s.Equal(ContentTypeText, Negotiate("text/*"))
+	s.Equal(ContentTypeText, Negotiate("text/html, text/plain;q=0.9, */*;q=0.1"))
+	s.Equal(ContentTypeText, Negotiate("application/json;q=0.5, text/plain"))
+	s.Equal(ContentTypeText, Negotiate("*/*;q=0.8, application/json;q=0"))
+	s.Empty(Negotiate("application/xml"))
+	s.Empty(Negotiate("text/plain;q=0, application/json;q=0"))
+}
+
+func (s *HandlerTestSuite) TestServeHTTP_JSON() {
+	rec := s.serve("")
+	s.Equal(http.StatusOK, rec.Code)
+	s.Equal(ContentTypeJSON, rec.Header().Get("Content-Type"))
+	s.Equal("Accept", rec.Header().Get("Vary"))
+
+	var doc Document
+	s.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &doc))
+	s.Equal("Test Service", doc.Service)
+	s.Equal("/mcp", doc.Endpoints["mcp"])
+	s.Require().Len(doc.Tools, 1)
+	s.Equal("alpha", doc.Tools[0].Name)
+	s.Require().Len(doc.Scanners, 1)
+	s.True(doc.Scanners[0].Available)
+}
+
+func (s *HandlerTestSuite) TestServeHTTP_Text() {
+	rec := s.serve("text/plain")
+	s.Equal(http.StatusOK, rec.Code)
+	s.Equal("text/plain; charset=utf-8", rec.Header().Get("Content-Type"))
+
+	body := rec.Body.String()
+	s.Contains(body, "wass-mcp 1.0.0 - Test Service")
+	s.Contains(body, "MCP endpoint: /mcp")
+	s.Contains(body, "Transport: streamable-http (stateless)")
+	s.Contains(body, "Auth: none")
+	s.Contains(body, "alpha  Runs alpha.")
+	s.Contains(body, "alpha  available  -  options: ca_bundle, insecure_skip_verify, vhost")
+	s.Contains(body, "max_concurrent_scans  unlimited")
+	s.Contains(body, "compress_threshold    never")
+}
+
+func (s *HandlerTestSuite) TestServeHTTP_NotAcceptable() {
+	rec := s.serve("application/xml")
+	s.Equal(http.StatusNotAcceptable, rec.Code)
+	s.Contains(rec.Body.String(), "application/json, text/plain")
+}
+
+func (s *HandlerTestSuite) TestToolVersionsHandler() {
+	serve := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/tools_versions", nil)
+		rec := httptest.NewRecorder()
+		s.provider.ToolVersionsHandler().ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := serve()
+	s.Equal(http.StatusOK, rec.Code)
+	s.Equal(ContentTypeJSON, rec.Header().Get("Content-Type"))
+	var report bundle.Report
+	s.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &report))
+	s.False(report.Embedded)
+	s.True(report.Healthy)
+	names := make([]string, 0, len(report.Tools))
+	for _, status := range report.Tools {
+		names = append(names, status.Name)
+	}
+	s.Equal([]string{"alpha", "naabu", "nmap"}, names, "port scanners are reported next to scanners")
+
+	// A tool the manifest lists but the bundle lacks fails the health check.
+	bundle.Use(&bundle.Bundle{Dir: s.T().TempDir(), Versions: map[string]string{"alpha": "1.0.0"}})
+	defer bundle.Use(nil)
+	rec = serve()
+	s.Equal(http.StatusServiceUnavailable, rec.Code)
+	s.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &report))
+	s.True(report.Embedded)
+	s.False(report.Healthy)
+}
+
+func TestHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(HandlerTestSuite))
+}
@@ -0,0 +1,209 @@
+// Package info builds the capability document served on the root HTTP endpoint, so that
+// orchestrators can introspect a server before connecting to it over MCP.
+package info
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"sync"
+
+	"github.com/tb0hdan/wass-mcp/pkg/bundle"
+	"github.com/tb0hdan/wass-mcp/pkg/discovery"
+	"github.com/tb0hdan/wass-mcp/pkg/server"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+	"github.com/tb0hdan/wass-mcp/pkg/types"
+)
+
+const (
+	// AuthBearer means MCP requests must carry a tenant API key as a bearer token.
+	AuthBearer = "bearer"
+	// AuthNone means MCP requests are not authenticated.
+	AuthNone = "none"
+	// TransportStreamableHTTP is the MCP streamable HTTP transport.
+	TransportStreamableHTTP = "streamable-http"
+)
+
+// Tool describes a registered MCP tool.
+type Tool struct {
+	Description string `json:"description"`
+	Name        string `json:"name"`
+}
+
+// Scanner describes a scanner, whether its binary can be run and whether it was disabled at runtime.
+type Scanner struct {
+	Available bool `json:"available"`
+	// Diagnostics explains why a scanner that failed its startup warm-up is unavailable.
+	Diagnostics string   `json:"diagnostics,omitempty"`
+	Enabled     bool     `json:"enabled"`
+	Name        string   `json:"name"`
+	Options     []string `json:"options"`
+	Version     string   `json:"version,omitempty"`
+}
+
+// Transport describes how to reach the MCP server.
+type Transport struct {
+	Endpoint  string `json:"endpoint"`
+	Stateless bool   `json:"stateless"`
+	Type      string `json:"type"`
+}
+
+// Limits are the server-wide limits applied to tool requests. Zero means unlimited, or for
+// CompressThreshold that full_scan never compresses its report automatically.
+type Limits struct {
+	CompressThreshold  int `json:"compress_threshold"`
+	MaxConcurrentScans int `json:"max_concurrent_scans"`
+	MaxLines           int `json:"max_lines"`
+	MaxOptions         int `json:"max_options"`
+	MaxOutputBytes     int `json:"max_output_bytes"`
+	MaxPorts           int `json:"max_ports"`
+	MaxResponseBytes   int `json:"max_response_bytes"`
+	MaxVhosts          int `json:"max_vhosts"`
+}
+
+// Document is the capability document of a server.
+type Document struct {
+	Auth      string            `json:"auth"`
+	Endpoints map[string]string `json:"endpoints"`
+	Limits    Limits            `json:"limits"`
+	Name      string            `json:"name"`
+	Scanners  []Scanner         `json:"scanners"`
+	Service   string            `json:"service"`
+	Tools     []Tool            `json:"tools"`
+	Transport Transport         `json:"transport"`
+	Version   string            `json:"version"`
+}
+
+// Config describes the server instance the document is built for.
+type Config struct {
+	// Endpoints are the paths of other HTTP endpoints by name, listed next to the MCP endpoint.
+	Endpoints map[string]string
+	// Auth is the authentication mode of the MCP endpoint, AuthNone when empty.
+	Auth      string
+	Name      string
+	Service   string
+	Transport Transport
+	Version   string
+}
+
+// Provider builds capability documents from the live server state.
+type Provider struct {
+	config   Config
+	registry *tools.Registry
+	srv      *server.Server
+
+	versionsMu sync.Mutex
+	// versions caches scanner versions by scanner name once their binary was found.
+	versions map[string]string
+}
+
+// New creates a provider describing srv and the scanners of registry.
+func New(srv *server.Server, cfg Config, registry *tools.Registry) *Provider {
+	if cfg.Auth == "" {
+		cfg.Auth = AuthNone
+	}
+
+	return &Provider{
+		config:   cfg,
+		registry: registry,
+		srv:      srv,
+		versions: make(map[string]string),
+	}
+}
+
+// Document builds the capability document. Tools are read and scanner availability is refreshed in
+// the registry on every call; scanner versions are looked up once per scanner after its binary becomes available.
+func (p *Provider) Document(ctx context.Context) (Document, error) {
+	registered, err := p.srv.Tools(ctx)
+	if err != nil {
+		return Document{}, fmt.Errorf("failed to list tools: %w", err)
+	}
+
+	toolList := make([]Tool, 0, len(registered))
+	for _, tool := range registered {
+		toolList = append(toolList, Tool{Description: tool.Description, Name: tool.Name})
+	}
+
+	p.registry.Refresh()
+	all := p.registry.Scanners()
+	scanners := make([]Scanner, 0, len(all))
+	for _, scanner := range all {
+		available := p.registry.IsAvailable(scanner.Name())
+		info := Scanner{
+			Available: available,
+			Enabled:   p.srv.ScannerEnabled(scanner.Name()),
+			Name:      scanner.Name(),
+			Options:   tools.SupportedOptions(scanner),
+		}
+		if checker, ok := scanner.(tools.WarmUpChecker); ok {
+			info.Diagnostics = checker.Diagnostics()
+		}
+		if available {
+			info.Version = p.version(ctx, scanner)
+		}
+		scanners = append(scanners, info)
+	}
+
+	endpoints := maps.Clone(p.config.Endpoints)
+	if endpoints == nil {
+		endpoints = make(map[string]string)
+	}
+	endpoints["mcp"] = p.config.Transport.Endpoint
+
+	return Document{
+		Auth:      p.config.Auth,
+		Endpoints: endpoints,
+		Limits: Limits{
+			CompressThreshold:  p.srv.CompressThreshold(),
+			MaxConcurrentScans: p.srv.ScanLimiter().Limit(),
+			MaxLines:           types.MaxAllowedLines,
+			MaxOptions:         types.MaxOptions,
+			MaxOutputBytes:     p.srv.Artifacts().MaxOutputBytes,
+			MaxPorts:           types.MaxPorts,
+			MaxResponseBytes:   p.srv.MaxResponseBytes(),
+			MaxVhosts:          types.MaxVhosts,
+		},
+		Name:      p.config.Name,
+		Scanners:  scanners,
+		Service:   p.config.Service,
+		Tools:     toolList,
+		Transport: p.config.Transport,
+		Version:   p.config.Version,
+	}, nil
+}
+
+// version returns the cached version of scanner, looking it up on first use.
+// Scanners that cannot report a version are cached with an empty version.
+func (p *Provider) version(ctx context.Context, scanner tools.Scanner) string {
+	p.versionsMu.Lock()
+	defer p.versionsMu.Unlock()
+
+	if version, ok := p.versions[scanner.Name()]; ok {
+		return version
+	}
+
+	var version string
+	if reporter, ok := scanner.(tools.VersionReporter); ok {
+		found, err := reporter.Version(ctx)
+		if err != nil && ctx.Err() != nil {
+			// Do not cache lookups cut short by the request going away.
+			return ""
+		}
+		version = found
+	}
+	p.versions[scanner.Name()] = version
+
+	return version
+}
+
+// ToolVersions returns the health of the binaries of scanners and of the port scanners used by
+// discovery, see bundle.Check. Versions are looked up on every call, so that binaries replaced
+// after startup are reported.
+func ToolVersions(ctx context.Context, scanners ...tools.Scanner) bundle.Report {
+	statuses := tools.ToolVersions(ctx, scanners...)
+	for _, portScanner := range discovery.New().Scanners {
+		statuses = append(statuses, bundle.Check(portScanner.Name(), nil))
+	}
+
+	return bundle.NewReport(statuses)
+}
@@ -0,0 +1,164 @@
+package info
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/artifacts"
+	"github.com/tb0hdan/wass-mcp/pkg/limiter"
+	"github.com/tb0hdan/wass-mcp/pkg/server"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+	"github.com/tb0hdan/wass-mcp/pkg/types"
+)
+
+// plainScanner is a scanner that neither declares options nor reports a version.
+type plainScanner struct {
+	available bool
+	name      string
+}
+
+func (p *plainScanner) Register(srv *server.Server) error {
+	mcp.AddTool(&srv.Server, &mcp.Tool{Name: p.name, Description: "Runs " + p.name + "."},
+		func(context.Context, *mcp.CallToolRequest, tools.ScannerInput) (*mcp.CallToolResult, any, error) {
+			return &mcp.CallToolResult{}, nil, nil
+		})
+	return nil
+}
+
+func (p *plainScanner) Name() string { return p.name }
+
+func (p *plainScanner) IsAvailable() bool { return p.available }
+
+func (p *plainScanner) Scan(context.Context, tools.ScanParams) tools.ScanResult {
+	return tools.ScanResult{}
+}
+
+// fakeScanner is a scanner declaring its options and reporting a fixed version.
+type fakeScanner struct {
+	plainScanner
+
+	lookups int
+	version string
+}
+
+func (f *fakeScanner) SupportedOptions() []string {
+	return []string{tools.OptionUserAgent}
+}
+
+func (f *fakeScanner) Version(context.Context) (string, error) {
+	f.lookups++
+	if f.version == "" {
+		return "", errors.New("no version")
+	}
+	return f.version, nil
+}
+
+// brokenScanner is a scanner that failed its warm-up.
+type brokenScanner struct {
+	plainScanner
+
+	diagnostics string
+}
+
+func (b *brokenScanner) WarmUp(context.Context) error { return errors.New(b.diagnostics) }
+
+func (b *brokenScanner) Diagnostics() string { return b.diagnostics }
+
+type InfoTestSuite struct {
+	suite.Suite
+	alpha    *fakeScanner
+	beta     *fakeScanner
+	provider *Provider
+}
+
+func (s *InfoTestSuite) SetupTest() {
+	srv := server.NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}, nil)
+	srv.SetScanLimiter(limiter.New(4))
+	srv.SetArtifacts(artifacts.Config{MaxOutputBytes: 1024})
+	srv.SetMaxResponseBytes(2048)
+	srv.SetCompressThreshold(4096)
+
+	s.alpha = &fakeScanner{plainScanner: plainScanner{name: "alpha", available: true}, version: "1.2.3"}
+	s.beta = &fakeScanner{plainScanner: plainScanner{name: "beta"}}
+	s.Require().NoError(s.alpha.Register(srv))
+	srv.SetScannerEnabled("beta", false)
+
+	s.provider = New(srv, Config{
+		Endpoints: map[string]string{"admin": "/admin/"},
+		Name:      "wass-mcp",
+		Service:   "Test Service",
+		Transport: Transport{Endpoint: "/mcp", Stateless: true, Type: TransportStreamableHTTP},
+		Version:   "1.0.0",
+	}, tools.NewRegistry(s.alpha, s.beta))
+}
+
+func (s *InfoTestSuite) TestDocument() {
+	doc, err := s.provider.Document(context.Background())
+	s.Require().NoError(err)
+
+	s.Equal(AuthNone, doc.Auth)
+	s.Equal(map[string]string{"admin": "/admin/", "mcp": "/mcp"}, doc.Endpoints)
+	s.Equal("wass-mcp", doc.Name)
+	s.Equal("1.0.0", doc.Version)
+	s.Equal([]Tool{{Description: "Runs alpha.", Name: "alpha"}}, doc.Tools)
+	s.Equal([]Scanner{
+		{Available: true, Enabled: true, Name: "alpha", Options: []string{tools.OptionUserAgent}, Version: "1.2.3"},
+		{Available: false, Enabled: false, Name: "beta", Options: []string{tools.OptionUserAgent}},
+	}, doc.Scanners)
+	s.Equal(Limits{
+		CompressThreshold:  4096,
+		MaxConcurrentScans: 4,
+		MaxLines:           types.MaxAllowedLines,
+		MaxOptions:         types.MaxOptions,
+		MaxOutputBytes:     1024,
+		MaxPorts:           types.MaxPorts,
+		MaxResponseBytes:   2048,
+		MaxVhosts:          types.MaxVhosts,
+	}, doc.Limits)
+}
+
+func (s *InfoTestSuite) TestDocument_CachesVersions() {
+	for range 3 {
+		_, err := s.provider.Document(context.Background())
+		s.Require().NoError(err)
+	}
+	s.Equal(1, s.alpha.lookups)
+	s.Equal(0, s.beta.lookups)
+}
+
+func (s *InfoTestSuite) TestDocument_ScannerWithoutCapabilities() {
+	srv := server.NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}, nil)
+	plain := &plainScanner{name: "plain", available: true}
+	provider := New(srv, Config{}, tools.NewRegistry(plain))
+
+	doc, err := provider.Document(context.Background())
+	s.Require().NoError(err)
+	s.Require().Len(doc.Scanners, 1)
+	s.Empty(doc.Scanners[0].Version)
+	s.Equal([]string{tools.OptionCABundle, tools.OptionInsecureSkipVerify, tools.OptionVhost}, doc.Scanners[0].Options)
+	s.Equal(0, doc.Limits.MaxConcurrentScans)
+	s.NotContains(doc.Endpoints, "admin")
+	s.Empty(doc.Tools)
+}
+
+func (s *InfoTestSuite) TestDocument_WarmUpDiagnostics() {
+	srv := server.NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}, nil)
+	broken := &brokenScanner{
+		plainScanner: plainScanner{name: "wapiti"},
+		diagnostics:  "wapiti --version failed: exit status 1: ModuleNotFoundError: No module named 'wapitiCore'",
+	}
+	provider := New(srv, Config{}, tools.NewRegistry(broken))
+
+	doc, err := provider.Document(context.Background())
+	s.Require().NoError(err)
+	s.Require().Len(doc.Scanners, 1)
+	s.False(doc.Scanners[0].Available)
+	s.Equal(broken.diagnostics, doc.Scanners[0].Diagnostics)
+}
+
+func TestInfoTestSuite(t *testing.T) {
+	suite.Run(t, new(InfoTestSuite))
+}
@@ -0,0 +1,98 @@
+package intel
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// gzipMagic starts gzip-compressed files.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// kevCatalog is the subset of the CISA KEV catalog JSON used.
+type kevCatalog struct {
+	Vulnerabilities []struct {
+		CveID string `json:"cveID"`
+	} `json:"vulnerabilities"`
+}
+
+// parseEPSS parses a FIRST EPSS scores CSV, plain or gzip-compressed, into the EPSS probability
+// of each CVE. Comment lines such as the leading model version are skipped and the columns are
+// located by the cve and epss header names.
+func parseEPSS(reader io.Reader) (map[string]float64, error) {
+	buffered := bufio.NewReader(reader)
+	if magic, err := buffered.Peek(len(gzipMagic)); err == nil && string(magic) == string(gzipMagic) {
+		unzipped, err := gzip.NewReader(buffered)
+		if err != nil {
+			return nil, err
+		}
+		defer unzipped.Close()
+		reader = unzipped
+	} else {
+		reader = buffered
+	}
+
+	records := csv.NewReader(reader)
+	records.Comment = '#'
+	records.FieldsPerRecord = -1
+	header, err := records.Read()
+	if err != nil {
+		return nil, fmt.Errorf("missing header: %w", err)
+	}
+	cveColumn, epssColumn := -1, -1
+	for column, name := range header {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "cve":
+			cveColumn = column
+		case "epss":
+			epssColumn = column
+		}
+	}
+	if cveColumn < 0 || epssColumn < 0 {
+		return nil, errors.New("header has no cve and epss columns")
+	}
+
+	scores := make(map[string]float64)
+	for {
+		record, err := records.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(record) <= max(cveColumn, epssColumn) {
+			continue
+		}
+		score, err := strconv.ParseFloat(strings.TrimSpace(record[epssColumn]), 64)
+		if err != nil || score < 0 || score > 1 {
+			return nil, fmt.Errorf("invalid EPSS score %q for %s", record[epssColumn], record[cveColumn])
+		}
+		scores[strings.ToUpper(strings.TrimSpace(record[cveColumn]))] = score
+	}
+
+	return scores, nil
+}
+
+// parseKEV parses the CISA KEV catalog JSON into the set of its CVE IDs.
+func parseKEV(reader io.Reader) (map[string]struct{}, error) {
+	var catalog kevCatalog
+	if err := json.NewDecoder(reader).Decode(&catalog); err != nil {
+		return nil, err
+	}
+
+	kev := make(map[string]struct{}, len(catalog.Vulnerabilities))
+	for _, vulnerability := range catalog.Vulnerabilities {
+		if id := strings.ToUpper(strings.TrimSpace(vulnerability.CveID)); id != "" {
+			kev[id] = struct{}{}
+		}
+	}
+
+	return kev, nil
+}
@@ -0,0 +1,181 @@
+// Package intel enriches CVE-linked findings with exploit intelligence from local datasets: the
+// FIRST EPSS exploit prediction scores and the CISA Known Exploited Vulnerabilities catalog.
+package intel
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/tb0hdan/wass-mcp/pkg/findings"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+)
+
+// Config locates the datasets. Either file may be empty to go without that dataset.
+type Config struct {
+	// EPSSFile is the FIRST EPSS scores CSV, optionally gzip-compressed.
+	EPSSFile string
+	// KEVFile is the CISA KEV catalog JSON.
+	KEVFile string
+	// Refresh is how often the files are checked for changes, 0 to load them once.
+	Refresh time.Duration
+}
+
+// Status describes the loaded datasets.
+type Status struct {
+	EPSSEntries int       `json:"epss_entries"`
+	KEVEntries  int       `json:"kev_entries"`
+	LoadedAt    time.Time `json:"loaded_at,omitzero"`
+}
+
+// Intel holds the loaded datasets. A nil Intel holds nothing; enriching with it only extracts the
+// CVE IDs of findings.
+type Intel struct {
+	cfg    Config
+	logger zerolog.Logger
+
+	mu       sync.RWMutex
+	epss     map[string]float64
+	epssMod  time.Time
+	kev      map[string]struct{}
+	kevMod   time.Time
+	loadedAt time.Time
+}
+
+// New returns an Intel reading the datasets of cfg. Nothing is loaded until Load is called.
+func New(cfg Config, logger zerolog.Logger) *Intel {
+	return &Intel{cfg: cfg, logger: logger}
+}
+
+// Load reads the dataset files changed since they were last loaded. A file that fails to load
+// keeps its previous data.
+func (i *Intel) Load() error {
+	if i == nil {
+		return nil
+	}
+
+	if i.cfg.EPSSFile != "" {
+		if err := i.reload(i.cfg.EPSSFile, &i.epssMod, func(file *os.File) error {
+			scores, err := parseEPSS(file)
+			if err != nil {
+				return err
+			}
+			i.mu.Lock()
+			i.epss = scores
+			i.mu.Unlock()
+			return nil
+		}); err != nil {
+			return fmt.Errorf("failed to load EPSS scores from %s: %w", i.cfg.EPSSFile, err)
+		}
+	}
+
+	if i.cfg.KEVFile != "" {
+		if err := i.reload(i.cfg.KEVFile, &i.kevMod, func(file *os.File) error {
+			catalog, err := parseKEV(file)
+			if err != nil {
+				return err
+			}
+			i.mu.Lock()
+			i.kev = catalog
+			i.mu.Unlock()
+			return nil
+		}); err != nil {
+			return fmt.Errorf("failed to load KEV catalog from %s: %w", i.cfg.KEVFile, err)
+		}
+	}
+
+	return nil
+}
+
+// reload calls parse with the file at path when it was modified after *modified, then records its
+// modification time.
+func (i *Intel) reload(path string, modified *time.Time, parse func(*os.File) error) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if info.ModTime().Equal(*modified) {
+		return nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := parse(file); err != nil {
+		return err
+	}
+	*modified = info.ModTime()
+	i.mu.Lock()
+	i.loadedAt = time.Now().UTC()
+	i.mu.Unlock()
+
+	return nil
+}
+
+// Run reloads changed datasets every Config.Refresh until ctx is done, logging failures.
+// It returns at once without a refresh interval or datasets.
+func (i *Intel) Run(ctx context.Context) {
+	if i == nil || i.cfg.Refresh <= 0 || (i.cfg.EPSSFile == "" && i.cfg.KEVFile == "") {
+		return
+	}
+
+	ticker := time.NewTicker(i.cfg.Refresh)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := i.Load(); err != nil {
+				i.logger.Warn().Err(err).Msg("Failed to refresh exploit intelligence, keeping previous data")
+			}
+		}
+	}
+}
+
+// Status returns the sizes of the loaded datasets and when they were last loaded.
+func (i *Intel) Status() Status {
+	if i == nil {
+		return Status{}
+	}
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	return Status{EPSSEntries: len(i.epss), KEVEntries: len(i.kev), LoadedAt: i.loadedAt}
+}
+
+// Lookup returns the EPSS probability of cve and whether it is a known exploited vulnerability.
+func (i *Intel) Lookup(cve string) (float64, bool) {
+	if i == nil {
+		return 0, false
+	}
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	_, kev := i.kev[cve]
+
+	return i.epss[cve], kev
+}
+
+// Enrich sets the CVE IDs of each finding (see findings.CVEIDs), the highest EPSS probability of
+// those CVEs and whether any of them is a known exploited vulnerability. Enriching again with
+// refreshed datasets updates the values.
+func (i *Intel) Enrich(found []models.Finding) {
+	for idx := range found {
+		finding := &found[idx]
+		finding.CVEs = findings.CVEIDs(*finding)
+		finding.EPSS = 0
+		finding.KEV = false
+		for _, cve := range finding.CVEs {
+			epss, kev := i.Lookup(cve)
+			finding.EPSS = max(finding.EPSS, epss)
+			finding.KEV = finding.KEV || kev
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package intel
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/types"
+)
+
+const (
+	epssCSV = `#model_version:v2023.03.01,score_date:2026-10-15T00:00:00+0000
+cve,epss,percentile
+CVE-2021-44228,0.97565,0.99997
+CVE-2017-5638,0.97450,0.99980
+CVE-2020-0001,0.00043,0.10000
+`
+	kevJSON = `{"title":"CISA Catalog of Known Exploited Vulnerabilities","vulnerabilities":[
+{"cveID":"CVE-2021-44228","vendorProject":"Apache","dateAdded":"2021-12-10"},
+{"cveID":"cve-2019-0708","vendorProject":"Microsoft","dateAdded":"2021-11-03"}]}`
+)
+
+type IntelTestSuite struct {
+	suite.Suite
+	dir string
+}
+
+func (s *IntelTestSuite) SetupTest() {
+	s.dir = s.T().TempDir()
+}
+
+// write writes a dataset file and returns its path.
+func (s *IntelTestSuite) write(name string, data []byte) string {
+	path := filepath.Join(s.dir, name)
+	s.Require().NoError(os.WriteFile(path, data, 0o600))
+
+	return path
+}
+
+func (s *IntelTestSuite) TestLoadAndLookup() {
+	threatIntel := New(Config{
+		EPSSFile: s.write("epss.csv", []byte(epssCSV)),
+		KEVFile:  s.write("kev.json", []byte(kevJSON)),
+	}, zerolog.Nop())
+	s.Require().NoError(threatIntel.Load())
+
+	epss, kev := threatIntel.Lookup("CVE-2021-44228")
+	s.InDelta(0.97565, epss, 0.00001)
+	s.True(kev)
+	epss, kev = threatIntel.Lookup("CVE-2019-0708")
+	s.Zero(epss)
+	s.True(kev)
+	epss, kev = threatIntel.Lookup("CVE-1999-0001")
+	s.Zero(epss)
+	s.False(kev)
+
+	status := threatIntel.Status()
+	s.Equal(3, status.EPSSEntries)
+	s.Equal(2, status.KEVEntries)
+	s.False(status.LoadedAt.IsZero())
+}
+
+func (s *IntelTestSuite) TestLoad_GzipEPSS() {
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	_, err := writer.Write([]byte(epssCSV))
+	s.Require().NoError(err)
+	s.Require().NoError(writer.Close())
+
+	threatIntel := New(Config{EPSSFile: s.write("epss.csv.gz", compressed.Bytes())}, zerolog.Nop())
+	s.Require().NoError(threatIntel.Load())
+	epss, _ := threatIntel.Lookup("CVE-2017-5638")
+	s.InDelta(0.9745, epss, 0.00001)
+}
+
+func (s *IntelTestSuite) TestLoad_Errors() {
+	missing := New(Config{KEVFile: filepath.Join(s.dir, "missing.json")}, zerolog.Nop())
+	s.ErrorContains(missing.Load(), "KEV catalog")
+
+	noColumns := New(Config{EPSSFile: s.write("bad.csv", []byte("id,score\nCVE-2021-44228,0.5\n"))}, zerolog.Nop())
+	s.ErrorContains(noColumns.Load(), "no cve and epss columns")
+
+	badScore := New(Config{EPSSFile: s.write("range.csv", []byte("cve,epss\nCVE-2021-44228,1.5\n"))}, zerolog.Nop())
+	s.ErrorContains(badScore.Load(), "invalid EPSS score")
+}
+
+func (s *IntelTestSuite) TestLoad_ReloadsChangedFiles() {
+	path := s.write("kev.json", []byte(kevJSON))
+	threatIntel := New(Config{KEVFile: path}, zerolog.Nop())
+	s.Require().NoError(threatIntel.Load())
+	s.Equal(2, threatIntel.Status().KEVEntries)
+
+	// A broken update keeps the previous catalog.
+	s.write("kev.json", []byte("{broken"))
+	s.Require().NoError(os.Chtimes(path, time.Now(), time.Now().Add(time.Minute)))
+	s.Error(threatIntel.Load())
+	s.Equal(2, threatIntel.Status().KEVEntries)
+
+	s.write("kev.json", []byte(`{"vulnerabilities":[{"cveID":"CVE-2023-4966"}]}`))
+	s.Require().NoError(os.Chtimes(path, time.Now(), time.Now().Add(2*time.Minute)))
+	s.Require().NoError(threatIntel.Load())
+	_, kev := threatIntel.Lookup("CVE-2023-4966")
+	s.True(kev)
+	s.Equal(1, threatIntel.Status().KEVEntries)
+}
+
+func (s *IntelTestSuite) TestEnrich() {
+	threatIntel := New(Config{
+		EPSSFile: s.write("epss.csv", []byte(epssCSV)),
+		KEVFile:  s.write("kev.json", []byte(kevJSON)),
+	}, zerolog.Nop())
+	s.Require().NoError(threatIntel.Load())
+
+	found := []models.Finding{
+		{Severity: types.SeverityCritical, Title: "Log4Shell", CVEs: []string{"cve-2021-44228"}},
+		{Severity: types.SeverityHigh, Title: "Old bugs CVE-2020-0001 and CVE-2017-5638"},
+		{Severity: types.SeverityLow, Title: "Missing header", EPSS: 0.5, KEV: true},
+	}
+	threatIntel.Enrich(found)
+
+	s.Equal([]string{"CVE-2021-44228"}, found[0].CVEs)
+	s.InDelta(0.97565, found[0].EPSS, 0.00001)
+	s.True(found[0].KEV)
+	s.Equal([]string{"CVE-2020-0001", "CVE-2017-5638"}, found[1].CVEs)
+	s.InDelta(0.9745, found[1].EPSS, 0.00001)
+	s.False(found[1].KEV)
+	s.Empty(found[2].CVEs)
+	s.Zero(found[2].EPSS)
+	s.False(found[2].KEV)
+}
+
+func (s *IntelTestSuite) TestNilIntel() {
+	var threatIntel *Intel
+	s.NoError(threatIntel.Load())
+	s.Equal(Status{}, threatIntel.Status())
+	threatIntel.Run(context.Background())
+
+	found := []models.Finding{{Title: "Struts cve-2017-5638"}}
+	threatIntel.Enrich(found)
+	s.Equal([]string{"CVE-2017-5638"}, found[0].CVEs)
+	s.False(found[0].KEV)
+}
+
+func TestIntelTestSuite(t *testing.T) {
+	suite.Run(t, new(IntelTestSuite))
+}
@@ -0,0 +1,438 @@
+// Package jobs runs scans in the background on behalf of MCP clients whose tool calls time out
+// long before a scan completes. Jobs are stored so that their status and result survive the
+// connection that started them, and handed to the workers running them through a Queue, so that
+// MCP front ends and workers can run as separate processes sharing one queue. Jobs a worker left
+// running in a previous process are marked interrupted and queued ones are pushed again at
+// startup.
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/running"
+	"github.com/tb0hdan/wass-mcp/pkg/server"
+	"github.com/tb0hdan/wass-mcp/pkg/storage"
+	"github.com/tb0hdan/wass-mcp/pkg/tenant"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+	"gorm.io/gorm"
+)
+
+// maxClaimBackoff bounds the pause of the worker after consecutive failures to claim a job.
+const maxClaimBackoff = 30 * time.Second
+
+var (
+	// ErrNotFound is returned for jobs that do not exist or belong to another tenant.
+	ErrNotFound = errors.New("scan job not found")
+	// ErrFinished is returned when cancelling a job that already finished.
+	ErrFinished = errors.New("scan job already finished")
+	// ErrUnknownTool is returned when starting a job for a tool that cannot run in the background.
+	ErrUnknownTool = errors.New("tool cannot run as a scan job")
+	// ErrOtherWorker is returned when cancelling a job running on another worker process.
+	ErrOtherWorker = errors.New("scan job runs on another worker")
+	// ErrRedactedInput fails jobs whose input was redacted and cannot be run as given by workers
+	// other than the one of the process that started them, see Manager.Start.
+	ErrRedactedInput = errors.New("scan job input holds redacted secrets and was not sealed, start the server with --encryption-key-file to run it on other workers or after a restart")
+)
+
+// Manager starts, tracks and cancels scan jobs. Started jobs are stored and pushed to the queue
+// of the manager; Work pops and runs them in worker processes.
+type Manager struct {
+	logger zerolog.Logger
+	queue  Queue
+	srv    *server.Server
+	store  storage.Storage
+	// worker is the ID recorded on the jobs the manager runs, see WithWorker.
+	worker string
+	// slots bounds the jobs running at once, nil for no limit.
+	slots chan struct{}
+	// claimFailures counts the consecutive failures to claim a popped job, see runNext. Only Work
+	// uses it.
+	claimFailures int
+
+	mu      sync.Mutex
+	cancels map[uint]context.CancelCauseFunc
+	// inputs are the inputs of the jobs started by the manager as given, run instead of their
+	// redacted stored input when the manager runs them.
+	inputs map[uint]string
+	// pending are the jobs started or pushed again by the manager, see Wait.
+	pending map[uint]bool
+	wg      sync.WaitGroup
+}
+
+// Option configures a Manager.
+type Option func(*Manager)
+
+// WithQueue sets the queue jobs are pushed to and popped from, the stored jobs themselves by
+// default, see StoreQueue.
+func WithQueue(queue Queue) Option {
+	return func(m *Manager) {
+		m.queue = queue
+	}
+}
+
+// WithWorker sets the ID of the worker process recorded on the jobs the manager runs, the host
+// name by default. Workers sharing a database need distinct IDs that stay the same across
+// restarts, so that a restarting worker marks only its own jobs interrupted.
+func WithWorker(id string) Option {
+	return func(m *Manager) {
+		m.worker = id
+	}
+}
+
+// New creates a manager whose worker runs up to maxConcurrent jobs at once, without limit when
+// zero or negative. Jobs run the tools registered with srv, see server.Server.Run.
+func New(srv *server.Server, maxConcurrent int, logger zerolog.Logger, options ...Option) *Manager {
+	m := &Manager{
+		logger:  logger.With().Str("component", "jobs").Logger(),
+		srv:     srv,
+		store:   srv.Storage(),
+		cancels: make(map[uint]context.CancelCauseFunc),
+		inputs:  make(map[uint]string),
+		pending: make(map[uint]bool),
+	}
+	m.worker, _ = os.Hostname()
+	for _, option := range options {
+		option(m)
+	}
+	if m.queue == nil {
+		m.queue = NewStoreQueue(m.store, DefaultPollInterval)
+	}
+	if maxConcurrent > 0 {
+		m.slots = make(chan struct{}, maxConcurrent)
+	}
+
+	return m
+}
+
+// Start queues a job running toolName with input on behalf of the tenant of ctx and returns it.
+// The job keeps running after ctx is done. The stored input is redacted; when redaction changed
+// it, the input as given is stored sealed with the credential vault, so that other workers and
+// restarted processes run it. Without a vault, only the worker of this process can run the job,
+// others fail it with ErrRedactedInput rather than scanning with redacted secrets. Queued jobs are
+// run by the priority field of input, see limiter.PriorityHigh.
+func (m *Manager) Start(ctx context.Context, toolName string, input map[string]any) (*models.ScanJob, error) {
+	if !m.srv.Runnable(toolName) {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownTool, toolName)
+	}
+	if input == nil {
+		input = map[string]any{}
+	}
+	inputJSON, err := json.Marshal(input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode input: %w", err)
+	}
+
+	redacted := m.srv.Redactor().JSON(string(inputJSON))
+	priority, _ := input["priority"].(string)
+	job := &models.ScanJob{
+		CorrelationID: tools.NewCorrelationID(),
+		ToolName:      toolName,
+		Priority:      priority,
+		InputJSON:     redacted,
+		InputRedacted: redacted != string(inputJSON),
+		Status:        models.JobQueued,
+	}
+	if job.InputRedacted && m.srv.Vault() != nil {
+		if job.SealedInput, err = m.srv.Vault().SealBytes(inputJSON); err != nil {
+			return nil, fmt.Errorf("failed to seal input: %w", err)
+		}
+	}
+	if err := m.store.CreateScanJob(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to create scan job: %w", err)
+	}
+
+	m.mu.Lock()
+	m.inputs[job.ID] = string(inputJSON)
+	m.mu.Unlock()
+	if err := m.push(ctx, job.ID, job.Priority); err != nil {
+		m.finish(ctx, job, err)
+		return nil, fmt.Errorf("failed to queue scan job: %w", err)
+	}
+
+	return job, nil
+}
+
+// Get returns the job id of the tenant of ctx.
+func (m *Manager) Get(ctx context.Context, id uint) (*models.ScanJob, error) {
+	job, err := m.store.GetScanJob(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("%w: %d", ErrNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load scan job %d: %w", id, err)
+	}
+
+	return job, nil
+}
+
+// Cancel cancels the job id of the tenant of ctx. Running jobs stop with running.ErrClientCanceled
+// as the cause and are marked canceled once their scan returned; only the worker running a job
+// can stop it. Queued jobs are marked canceled and skipped by the worker popping them.
+func (m *Manager) Cancel(ctx context.Context, id uint) (*models.ScanJob, error) {
+	job, err := m.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job.Finished() {
+		return nil, fmt.Errorf("%w: %d is %s", ErrFinished, id, job.Status)
+	}
+
+	m.mu.Lock()
+	cancel, ok := m.cancels[id]
+	m.mu.Unlock()
+	if ok {
+		cancel(running.ErrClientCanceled)
+		return job, nil
+	}
+	if job.Status == models.JobRunning {
+		return nil, fmt.Errorf("%w: %d runs on %s", ErrOtherWorker, id, job.Worker)
+	}
+
+	now := time.Now()
+	job.Status = models.JobCanceled
+	job.Error = running.ErrClientCanceled.Error()
+	job.FinishedAt = &now
+	job.SealedInput = nil
+	if err := m.store.UpdateScanJob(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to cancel scan job %d: %w", id, err)
+	}
+	m.done(id)
+
+	return job, nil
+}
+
+// Recover marks the jobs the worker of the manager left running in a previous process as
+// interrupted and pushes the queued ones again, in case they were lost with it. It returns the
+// interrupted and queued jobs.
+func (m *Manager) Recover(ctx context.Context) ([]models.ScanJob, []models.ScanJob, error) {
+	interrupted, err := m.store.MarkInterruptedScanJobs(ctx, m.worker)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to mark interrupted scan jobs: %w", err)
+	}
+	queued, err := m.store.GetQueuedScanJobs(ctx)
+	if err != nil {
+		return interrupted, nil, fmt.Errorf("failed to load queued scan jobs: %w", err)
+	}
+	for _, job := range queued {
+		if err := m.push(ctx, job.ID, job.Priority); err != nil {
+			return interrupted, nil, fmt.Errorf("failed to queue scan job %d: %w", job.ID, err)
+		}
+	}
+
+	return interrupted, queued, nil
+}
+
+// Work pops and runs queued jobs, up to the concurrency limit of the manager at once, until ctx
+// is done. Jobs already running then keep running.
+func (m *Manager) Work(ctx context.Context) {
+	for {
+		if m.slots != nil {
+			select {
+			case m.slots <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+		}
+		if !m.runNext(ctx) {
+			m.release()
+		}
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// runNext pops a job and starts it unless another worker claimed it, it was cancelled while
+// queued or it cannot be claimed. It reports whether a job was started.
+func (m *Manager) runNext(ctx context.Context) bool {
+	id, err := m.queue.Pop(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			m.logger.Error().Err(err).Msg("Failed to pop a scan job")
+			sleep(ctx, DefaultPollInterval)
+		}
+		return false
+	}
+
+	job, err := m.store.ClaimScanJob(context.WithoutCancel(ctx), id, m.worker)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		m.claimFailures = 0
+		m.done(id)
+		return false
+	case err != nil:
+		// Back off while the store fails, instead of popping and failing again at once.
+		m.claimFailures++
+		delay := claimBackoff(m.claimFailures)
+		m.logger.Error().Err(err).Msgf("Failed to claim scan job %d, backing off for %s", id, delay)
+		sleep(ctx, delay)
+		return false
+	}
+	m.claimFailures = 0
+
+	m.mu.Lock()
+	inputJSON, ok := m.inputs[id]
+	m.mu.Unlock()
+	if !ok {
+		if inputJSON, err = m.storedInput(job); err != nil {
+			m.finish(context.WithoutCancel(ctx), job, err)
+			m.done(id)
+			return false
+		}
+	}
+	m.launch(*job, inputJSON)
+
+	return true
+}
+
+// storedInput returns the input job runs with when it was started by another process: the sealed
+// input as given when redaction changed it, the stored input otherwise.
+func (m *Manager) storedInput(job *models.ScanJob) (string, error) {
+	switch {
+	case len(job.SealedInput) > 0:
+		inputJSON, err := m.srv.Vault().OpenBytes(job.SealedInput)
+		if err != nil {
+			return "", fmt.Errorf("failed to open the sealed input: %w", err)
+		}
+		return string(inputJSON), nil
+	case job.InputRedacted:
+		return "", ErrRedactedInput
+	default:
+		return job.InputJSON, nil
+	}
+}
+
+// claimBackoff returns the pause of the worker after failures consecutive failures to claim a
+// job: DefaultPollInterval, doubled with each further failure up to maxClaimBackoff.
+func claimBackoff(failures int) time.Duration {
+	delay := DefaultPollInterval
+	for range failures - 1 {
+		if delay >= maxClaimBackoff/2 {
+			return maxClaimBackoff
+		}
+		delay *= 2
+	}
+
+	return delay
+}
+
+// sleep waits for delay or until ctx is done.
+func sleep(ctx context.Context, delay time.Duration) {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+}
+
+// Wait blocks until the jobs started or pushed again by the manager finished or were skipped by
+// its worker. Jobs run by other workers are not waited for, so it is meant for single-process
+// deployments.
+func (m *Manager) Wait() {
+	m.wg.Wait()
+}
+
+// Close closes the queue of the manager.
+func (m *Manager) Close() error {
+	return m.queue.Close()
+}
+
+// push pushes job id of priority to the queue, tracking it until it finished, see Wait.
+func (m *Manager) push(ctx context.Context, id uint, priority string) error {
+	m.mu.Lock()
+	if !m.pending[id] {
+		m.pending[id] = true
+		m.wg.Add(1)
+	}
+	m.mu.Unlock()
+
+	if err := m.queue.Push(ctx, id, priority); err != nil {
+		m.done(id)
+		return err
+	}
+
+	return nil
+}
+
+// done stops tracking job id.
+func (m *Manager) done(id uint) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.inputs, id)
+	if m.pending[id] {
+		delete(m.pending, id)
+		m.wg.Done()
+	}
+}
+
+// release frees the slot of a job, if jobs are limited.
+func (m *Manager) release() {
+	if m.slots != nil {
+		<-m.slots
+	}
+}
+
+// launch runs the claimed job in the background with inputJSON, tracking its cancel function
+// until it finished, and frees its slot.
+func (m *Manager) launch(job models.ScanJob, inputJSON string) {
+	ctx := tools.WithCorrelationID(context.Background(), job.CorrelationID)
+	if job.Tenant != "" {
+		ctx = tenant.WithTenant(ctx, job.Tenant)
+	}
+	ctx, cancel := context.WithCancelCause(ctx)
+
+	m.mu.Lock()
+	m.cancels[job.ID] = cancel
+	m.mu.Unlock()
+
+	go func() {
+		defer func() {
+			m.mu.Lock()
+			delete(m.cancels, job.ID)
+			m.mu.Unlock()
+			cancel(nil)
+			m.release()
+			m.done(job.ID)
+		}()
+		err := m.srv.Run(ctx, job.ToolName, inputJSON)
+		m.finish(ctx, &job, err)
+	}()
+}
+
+// finish stores the final status of job after its scan returned err, linking the execution
+// that ran it.
+func (m *Manager) finish(jobCtx context.Context, job *models.ScanJob, err error) {
+	storeCtx := context.WithoutCancel(jobCtx)
+	executions, _, queryErr := m.store.QueryToolExecutions(storeCtx, storage.ExecutionFilter{CorrelationID: job.CorrelationID, Limit: 1})
+	if queryErr == nil && len(executions) > 0 {
+		job.ExecutionID = executions[0].ID
+	}
+
+	now := time.Now()
+	job.FinishedAt = &now
+	job.SealedInput = nil
+	switch {
+	case running.Canceled(jobCtx):
+		job.Status = models.JobCanceled
+		job.Error = context.Cause(jobCtx).Error()
+	case err != nil:
+		job.Status = models.JobFailed
+		job.Error = m.srv.Redactor().Text(err.Error())
+	default:
+		job.Status = models.JobCompleted
+	}
+	if err := m.store.UpdateScanJob(storeCtx, job); err != nil {
+		m.logger.Error().Err(err).Msgf("Failed to store the outcome of scan job %d", job.ID)
+		return
+	}
+	m.logger.Info().Msgf("Scan job %d (%s) %s", job.ID, job.ToolName, job.Status)
+}
@@ -0,0 +1,339 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/crypto"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/running"
+	"github.com/tb0hdan/wass-mcp/pkg/server"
+	"github.com/tb0hdan/wass-mcp/pkg/storage"
+	"github.com/tb0hdan/wass-mcp/pkg/tenant"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+	"github.com/tb0hdan/wass-mcp/pkg/vault"
+)
+
+type JobsTestSuite struct {
+	suite.Suite
+	srv   *server.Server
+	store storage.Storage
+	// inputs receives the input of every fake scan run.
+	inputs chan string
+}
+
+func (s *JobsTestSuite) SetupTest() {
+	tmpFile, err := os.CreateTemp("", "jobs-test-*.db")
+	s.Require().NoError(err)
+	s.Require().NoError(tmpFile.Close())
+	s.T().Cleanup(func() { _ = os.Remove(tmpFile.Name()) })
+
+	store, err := storage.NewSQLiteStorage(storage.Config{DatabasePath: tmpFile.Name()})
+	s.Require().NoError(err)
+	s.T().Cleanup(func() { _ = store.Close() })
+
+	s.store = store
+	s.inputs = make(chan string, 10)
+	s.srv = server.NewServer(&mcp.Implementation{Name: "test"}, store)
+	s.srv.RegisterRerun("nikto", func(ctx context.Context, inputJSON string, _ int) error {
+		s.inputs <- inputJSON
+		return store.CreateToolExecution(ctx, &models.ToolExecution{
+			CorrelationID: tools.CorrelationID(ctx),
+			ToolName:      "nikto",
+			Status:        models.StatusCompleted,
+			Success:       true,
+			RawOutput:     "+ Server: nginx",
+		})
+	})
+	s.srv.RegisterRerun("nuclei", func(context.Context, string, int) error {
+		return errors.New("nuclei exited with status 2")
+	})
+	s.srv.RegisterRerun("wapiti", func(ctx context.Context, _ string, _ int) error {
+		<-ctx.Done()
+		return context.Cause(ctx)
+	})
+}
+
+// newManager returns a manager whose worker runs until the end of the test.
+func (s *JobsTestSuite) newManager(maxConcurrent int, options ...Option) *Manager {
+	manager := New(s.srv, maxConcurrent, zerolog.Nop(), options...)
+	ctx, cancel := context.WithCancel(context.Background())
+	go manager.Work(ctx)
+	s.T().Cleanup(func() {
+		cancel()
+		_ = manager.Close()
+	})
+
+	return manager
+}
+
+// failingClaims is a store whose claims of jobs fail.
+type failingClaims struct {
+	storage.Storage
+}
+
+func (failingClaims) ClaimScanJob(context.Context, uint, string) (*models.ScanJob, error) {
+	return nil, errors.New("database is locked")
+}
+
+// countingQueue is a queue always popping job 1, counting its pops.
+type countingQueue struct {
+	pops atomic.Int32
+}
+
+func (q *countingQueue) Push(context.Context, uint, string) error { return nil }
+
+func (q *countingQueue) Pop(context.Context) (uint, error) {
+	q.pops.Add(1)
+	return 1, nil
+}
+
+func (q *countingQueue) Close() error { return nil }
+
+func (s *JobsTestSuite) TestWork_ClaimBackoff() {
+	queue := &countingQueue{}
+	manager := New(s.srv, 1, zerolog.Nop(), WithQueue(queue))
+	manager.store = failingClaims{s.store}
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultPollInterval/2)
+	defer cancel()
+
+	manager.Work(ctx)
+	s.Equal(int32(1), queue.pops.Load(), "the worker backs off after a failed claim")
+	s.Equal(1, manager.claimFailures)
+
+	s.Equal(DefaultPollInterval, claimBackoff(1))
+	s.Equal(4*DefaultPollInterval, claimBackoff(3))
+	s.Equal(maxClaimBackoff, claimBackoff(100))
+}
+
+func (s *JobsTestSuite) TestStart_Completes() {
+	manager := s.newManager(0)
+
+	job, err := manager.Start(context.Background(), "nikto", map[string]any{"host": "example.com", "password": "hunter2"})
+	s.Require().NoError(err)
+	s.Equal(models.JobQueued, job.Status)
+	s.NotEmpty(job.CorrelationID)
+	s.NotContains(job.InputJSON, "hunter2", "stored inputs are redacted")
+	manager.Wait()
+
+	s.Contains(<-s.inputs, "hunter2", "scans run with the input as given")
+	stored, err := manager.Get(context.Background(), job.ID)
+	s.Require().NoError(err)
+	s.Equal(models.JobCompleted, stored.Status)
+	s.NotNil(stored.StartedAt)
+	s.NotNil(stored.FinishedAt)
+	s.NotZero(stored.ExecutionID, "jobs link the execution that ran them")
+}
+
+func (s *JobsTestSuite) TestStart_Priority() {
+	manager := New(s.srv, 0, zerolog.Nop(), WithQueue(&countingQueue{}))
+
+	job, err := manager.Start(context.Background(), "nikto", map[string]any{"host": "example.com", "priority": "high"})
+	s.Require().NoError(err)
+	s.Equal("high", job.Priority, "jobs are queued by the priority of their input")
+}
+
+func (s *JobsTestSuite) TestStart_UnknownTool() {
+	manager := s.newManager(0)
+
+	_, err := manager.Start(context.Background(), "history", nil)
+	s.ErrorIs(err, ErrUnknownTool)
+}
+
+func (s *JobsTestSuite) TestStart_Fails() {
+	manager := s.newManager(0)
+
+	job, err := manager.Start(context.Background(), "nuclei", nil)
+	s.Require().NoError(err)
+	manager.Wait()
+
+	stored, err := manager.Get(context.Background(), job.ID)
+	s.Require().NoError(err)
+	s.Equal(models.JobFailed, stored.Status)
+	s.Equal("nuclei exited with status 2", stored.Error)
+	s.Zero(stored.ExecutionID)
+}
+
+func (s *JobsTestSuite) TestCancel_Running() {
+	manager := s.newManager(0)
+
+	job, err := manager.Start(context.Background(), "wapiti", nil)
+	s.Require().NoError(err)
+	_, err = manager.Cancel(context.Background(), job.ID)
+	s.Require().NoError(err)
+	manager.Wait()
+
+	stored, err := manager.Get(context.Background(), job.ID)
+	s.Require().NoError(err)
+	s.Equal(models.JobCanceled, stored.Status)
+	s.Equal(running.ErrClientCanceled.Error(), stored.Error)
+
+	_, err = manager.Cancel(context.Background(), job.ID)
+	s.ErrorIs(err, ErrFinished)
+}
+
+func (s *JobsTestSuite) TestCancel_Queued() {
+	manager := s.newManager(1)
+
+	first, err := manager.Start(context.Background(), "wapiti", nil)
+	s.Require().NoError(err)
+	s.Eventually(func() bool {
+		job, err := manager.Get(context.Background(), first.ID)
+		return err == nil && job.Status == models.JobRunning
+	}, 5*time.Second, 10*time.Millisecond)
+	second, err := manager.Start(context.Background(), "nikto", nil)
+	s.Require().NoError(err)
+
+	_, err = manager.Cancel(context.Background(), second.ID)
+	s.Require().NoError(err)
+	_, err = manager.Cancel(context.Background(), first.ID)
+	s.Require().NoError(err)
+	manager.Wait()
+
+	stored, err := manager.Get(context.Background(), second.ID)
+	s.Require().NoError(err)
+	s.Equal(models.JobCanceled, stored.Status)
+	s.Nil(stored.StartedAt, "cancelled queued jobs never start")
+	s.Empty(s.inputs)
+}
+
+func (s *JobsTestSuite) TestGet_Tenants() {
+	manager := s.newManager(0)
+
+	job, err := manager.Start(tenant.WithTenant(context.Background(), "acme"), "nikto", nil)
+	s.Require().NoError(err)
+	manager.Wait()
+
+	_, err = manager.Get(tenant.WithTenant(context.Background(), "globex"), job.ID)
+	s.ErrorIs(err, ErrNotFound)
+	_, err = manager.Cancel(tenant.WithTenant(context.Background(), "globex"), job.ID)
+	s.ErrorIs(err, ErrNotFound)
+
+	stored, err := manager.Get(tenant.WithTenant(context.Background(), "acme"), job.ID)
+	s.Require().NoError(err)
+	s.Equal(models.JobCompleted, stored.Status)
+}
+
+func (s *JobsTestSuite) TestRecover() {
+	ctx := context.Background()
+	left := &models.ScanJob{ToolName: "nikto", Status: models.JobRunning, CorrelationID: tools.NewCorrelationID()}
+	queued := &models.ScanJob{ToolName: "nikto", Status: models.JobQueued, CorrelationID: tools.NewCorrelationID(), InputJSON: `{"host":"example.com"}`}
+	s.Require().NoError(s.store.CreateScanJob(ctx, left))
+	s.Require().NoError(s.store.CreateScanJob(ctx, queued))
+	manager := s.newManager(0)
+
+	interrupted, restarted, err := manager.Recover(ctx)
+	s.Require().NoError(err)
+	manager.Wait()
+	s.Require().Len(interrupted, 1)
+	s.Equal(left.ID, interrupted[0].ID)
+	s.Require().Len(restarted, 1)
+	s.Equal(`{"host":"example.com"}`, <-s.inputs)
+
+	stored, err := manager.Get(ctx, left.ID)
+	s.Require().NoError(err)
+	s.Equal(models.JobInterrupted, stored.Status)
+	stored, err = manager.Get(ctx, queued.ID)
+	s.Require().NoError(err)
+	s.Equal(models.JobCompleted, stored.Status)
+}
+
+// sharedQueue returns a front end manager queuing jobs and a worker manager running them in the
+// background, sharing a Redis queue as separate processes would.
+func (s *JobsTestSuite) sharedQueue() (*Manager, *Manager) {
+	server := newFakeRedis(s.T())
+	target, err := url.Parse("redis://" + server.listener.Addr().String())
+	s.Require().NoError(err)
+	frontQueue, err := NewRedisQueue(target)
+	s.Require().NoError(err)
+	workerQueue, err := NewRedisQueue(target)
+	s.Require().NoError(err)
+
+	front := New(s.srv, 0, zerolog.Nop(), WithQueue(frontQueue), WithWorker("front"))
+	worker := New(s.srv, 0, zerolog.Nop(), WithQueue(workerQueue), WithWorker("worker-a"))
+	workCtx, cancel := context.WithCancel(context.Background())
+	go worker.Work(workCtx)
+	s.T().Cleanup(func() {
+		cancel()
+		_ = front.Close()
+		_ = worker.Close()
+	})
+
+	return front, worker
+}
+
+func (s *JobsTestSuite) TestStart_SharedQueue() {
+	ctx := context.Background()
+	front, worker := s.sharedQueue()
+
+	job, err := front.Start(ctx, "nikto", map[string]any{"host": "example.com"})
+	s.Require().NoError(err)
+	s.Equal(`{"host":"example.com"}`, <-s.inputs, "workers run the stored input of jobs queued by front ends")
+	s.Eventually(func() bool {
+		stored, err := front.Get(ctx, job.ID)
+		return err == nil && stored.Status == models.JobCompleted && stored.Worker == "worker-a"
+	}, 5*time.Second, 10*time.Millisecond)
+
+	remote, err := front.Start(ctx, "wapiti", nil)
+	s.Require().NoError(err)
+	s.Eventually(func() bool {
+		stored, err := front.Get(ctx, remote.ID)
+		return err == nil && stored.Status == models.JobRunning
+	}, 5*time.Second, 10*time.Millisecond)
+	_, err = front.Cancel(ctx, remote.ID)
+	s.ErrorIs(err, ErrOtherWorker, "only the worker running a job can stop it")
+	_, err = worker.Cancel(ctx, remote.ID)
+	s.Require().NoError(err)
+	s.Eventually(func() bool {
+		stored, err := front.Get(ctx, remote.ID)
+		return err == nil && stored.Status == models.JobCanceled
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func (s *JobsTestSuite) TestStart_SealedInput() {
+	ctx := context.Background()
+	keyring, err := crypto.NewKeyring(make([]byte, crypto.KeySize))
+	s.Require().NoError(err)
+	s.srv.SetVault(vault.New(keyring))
+	front, _ := s.sharedQueue()
+
+	job, err := front.Start(ctx, "nikto", map[string]any{"host": "example.com", "password": "hunter2"})
+	s.Require().NoError(err)
+	s.True(job.InputRedacted)
+	s.NotContains(job.InputJSON, "hunter2")
+	s.Equal(`{"host":"example.com","password":"hunter2"}`, <-s.inputs, "workers run the sealed input as given")
+	s.Eventually(func() bool {
+		stored, err := front.Get(ctx, job.ID)
+		return err == nil && stored.Status == models.JobCompleted && len(stored.SealedInput) == 0
+	}, 5*time.Second, 10*time.Millisecond, "the sealed input is cleared once the job finished")
+}
+
+func (s *JobsTestSuite) TestStart_RedactedInputWithoutVault() {
+	ctx := context.Background()
+	front, _ := s.sharedQueue()
+
+	job, err := front.Start(ctx, "nikto", map[string]any{"host": "example.com", "password": "hunter2"})
+	s.Require().NoError(err)
+	s.Eventually(func() bool {
+		stored, err := front.Get(ctx, job.ID)
+		return err == nil && stored.Status == models.JobFailed && stored.Error == ErrRedactedInput.Error()
+	}, 5*time.Second, 10*time.Millisecond)
+	s.Empty(s.inputs, "jobs are not run with redacted secrets")
+
+	job, err = front.Start(ctx, "nikto", map[string]any{"host": "example.com"})
+	s.Require().NoError(err)
+	s.False(job.InputRedacted)
+	s.Equal(`{"host":"example.com"}`, <-s.inputs, "inputs without secrets run on other workers")
+}
+
+func TestJobsTestSuite(t *testing.T) {
+	suite.Run(t, new(JobsTestSuite))
+}
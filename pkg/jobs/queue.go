@@ -0,0 +1,103 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/tb0hdan/wass-mcp/pkg/storage"
+)
+
+// Job queue backends, see NewQueue.
+const (
+	QueueSQLite = "sqlite"
+	QueueRedis  = "redis"
+)
+
+// DefaultPollInterval is how often the SQLite queue looks for jobs queued by other processes.
+const DefaultPollInterval = time.Second
+
+// ErrQueueBackend is returned for job queue specs naming no known backend.
+var ErrQueueBackend = errors.New("unknown job queue backend")
+
+// Queue hands the IDs of queued scan jobs to the workers running them. The job itself, with its
+// input and status, is stored; workers claim the popped job in storage before running it, so a
+// job popped twice, e.g. pushed again at startup, runs once. Jobs are popped by priority, see
+// limiter.PriorityHigh, then oldest first.
+type Queue interface {
+	// Push adds the queued job id of the given priority to the queue.
+	Push(ctx context.Context, id uint, priority string) error
+	// Pop blocks until a job is queued and returns the ID of the first by priority, or returns the
+	// error of ctx once done.
+	Pop(ctx context.Context) (uint, error)
+	// Close releases the resources of the queue.
+	Close() error
+}
+
+// NewQueue returns the queue of spec: "sqlite" (or empty) for the stored jobs themselves, or a
+// redis:// URL for a Redis list shared by front ends and workers.
+func NewQueue(spec string, store storage.Storage) (Queue, error) {
+	if spec == "" || spec == QueueSQLite {
+		return NewStoreQueue(store, DefaultPollInterval), nil
+	}
+	parsed, err := url.Parse(spec)
+	if err != nil || parsed.Scheme != QueueRedis {
+		return nil, fmt.Errorf("%w: %q, expected %s or a redis:// URL", ErrQueueBackend, spec, QueueSQLite)
+	}
+
+	return NewRedisQueue(parsed)
+}
+
+// StoreQueue is the default queue: the stored jobs with the queued status, by priority, see
+// storage.Storage.GetQueuedScanJobs. Pushes
+// wake the workers of the process at once; jobs queued by other processes sharing the database are
+// found by polling every interval.
+type StoreQueue struct {
+	interval time.Duration
+	store    storage.Storage
+	wake     chan struct{}
+}
+
+// NewStoreQueue returns the queue of the jobs queued in store, polled every interval.
+func NewStoreQueue(store storage.Storage, interval time.Duration) *StoreQueue {
+	return &StoreQueue{interval: interval, store: store, wake: make(chan struct{}, 1)}
+}
+
+// Push wakes a worker waiting in Pop. The stored job is the queue entry.
+func (q *StoreQueue) Push(context.Context, uint, string) error {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// Pop returns the first queued job of every tenant by priority.
+func (q *StoreQueue) Pop(ctx context.Context) (uint, error) {
+	ticker := time.NewTicker(q.interval)
+	defer ticker.Stop()
+	for {
+		jobs, err := q.store.GetQueuedScanJobs(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("failed to load queued scan jobs: %w", err)
+		}
+		if len(jobs) > 0 {
+			return jobs[0].ID, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return 0, context.Cause(ctx)
+		case <-q.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// Close is a no-op, the store is closed by its owner.
+func (q *StoreQueue) Close() error {
+	return nil
+}
@@ -0,0 +1,320 @@
+package jobs
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/limiter"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/storage"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+)
+
+// fakeRedis is a Redis server holding lists, answering the commands of RedisQueue.
+type fakeRedis struct {
+	listener net.Listener
+
+	mu       sync.Mutex
+	commands [][]string
+	lists    map[string][]string
+	pushed   chan struct{}
+}
+
+func newFakeRedis(t *testing.T) *fakeRedis {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := &fakeRedis{listener: listener, lists: make(map[string][]string), pushed: make(chan struct{}, 100)}
+	t.Cleanup(func() { _ = listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn)
+		}
+	}()
+
+	return server
+}
+
+func (r *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	for {
+		request, err := readReply(reader)
+		if err != nil {
+			return
+		}
+		var args []string
+		for _, value := range request.([]any) {
+			args = append(args, value.(string))
+		}
+		if _, err := conn.Write([]byte(r.reply(args))); err != nil {
+			return
+		}
+	}
+}
+
+func (r *fakeRedis) reply(args []string) string {
+	r.mu.Lock()
+	r.commands = append(r.commands, args)
+	r.mu.Unlock()
+
+	switch args[0] {
+	case "AUTH":
+		if args[len(args)-1] != "secret" {
+			return "-WRONGPASS invalid username-password pair\r\n"
+		}
+		return "+OK\r\n"
+	case "SELECT":
+		return "+OK\r\n"
+	case "LPUSH":
+		r.mu.Lock()
+		r.lists[args[1]] = append([]string{args[2]}, r.lists[args[1]]...)
+		size := len(r.lists[args[1]])
+		r.mu.Unlock()
+		r.pushed <- struct{}{}
+		return fmt.Sprintf(":%d\r\n", size)
+	case "BRPOP":
+		deadline := time.After(time.Second)
+		for {
+			r.mu.Lock()
+			for _, key := range args[1 : len(args)-1] {
+				list := r.lists[key]
+				if len(list) > 0 {
+					value := list[len(list)-1]
+					r.lists[key] = list[:len(list)-1]
+					r.mu.Unlock()
+					return fmt.Sprintf("*2\r\n$%d\r\n%s\r\n$%d\r\n%s\r\n", len(key), key, len(value), value)
+				}
+			}
+			r.mu.Unlock()
+			select {
+			case <-r.pushed:
+			case <-deadline:
+				return "*-1\r\n"
+			}
+		}
+	}
+
+	return "-ERR unknown command '" + args[0] + "'\r\n"
+}
+
+// sent returns the commands received, arguments joined by spaces.
+func (r *fakeRedis) sent() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var names []string
+	for _, command := range r.commands {
+		names = append(names, strings.Join(command, " "))
+	}
+
+	return names
+}
+
+type QueueTestSuite struct {
+	suite.Suite
+	store storage.Storage
+}
+
+func (s *QueueTestSuite) SetupTest() {
+	tmpFile, err := os.CreateTemp("", "queue-test-*.db")
+	s.Require().NoError(err)
+	s.Require().NoError(tmpFile.Close())
+	s.T().Cleanup(func() { _ = os.Remove(tmpFile.Name()) })
+
+	store, err := storage.NewSQLiteStorage(storage.Config{DatabasePath: tmpFile.Name()})
+	s.Require().NoError(err)
+	s.T().Cleanup(func() { _ = store.Close() })
+	s.store = store
+}
+
+func (s *QueueTestSuite) TestNewQueue() {
+	queue, err := NewQueue("", s.store)
+	s.Require().NoError(err)
+	s.IsType(&StoreQueue{}, queue)
+	queue, err = NewQueue(QueueSQLite, s.store)
+	s.Require().NoError(err)
+	s.IsType(&StoreQueue{}, queue)
+
+	queue, err = NewQueue("redis://:secret@cache:6380/2?key=jobs", s.store)
+	s.Require().NoError(err)
+	redis, ok := queue.(*RedisQueue)
+	s.Require().True(ok)
+	s.Equal("cache:6380", redis.address)
+	s.Equal(2, redis.database)
+	s.Equal("jobs", redis.key)
+	s.Equal("secret", redis.password)
+
+	queue, err = NewQueue("redis://cache", s.store)
+	s.Require().NoError(err)
+	s.Equal("cache:6379", queue.(*RedisQueue).address)
+	s.Equal(DefaultRedisKey, queue.(*RedisQueue).key)
+
+	for _, spec := range []string{"postgres", "amqp://broker", "redis:///0", "redis://cache/db"} {
+		_, err = NewQueue(spec, s.store)
+		s.ErrorIs(err, ErrQueueBackend, spec)
+	}
+}
+
+func (s *QueueTestSuite) TestStoreQueue() {
+	ctx := context.Background()
+	queue := NewStoreQueue(s.store, time.Hour)
+	first := &models.ScanJob{ToolName: "nikto", Status: models.JobQueued, CorrelationID: tools.NewCorrelationID()}
+	second := &models.ScanJob{ToolName: "nikto", Status: models.JobQueued, CorrelationID: tools.NewCorrelationID()}
+	s.Require().NoError(s.store.CreateScanJob(ctx, first))
+	s.Require().NoError(s.store.CreateScanJob(ctx, second))
+
+	id, err := queue.Pop(ctx)
+	s.Require().NoError(err)
+	s.Equal(first.ID, id, "the oldest queued job is popped")
+	_, err = s.store.ClaimScanJob(ctx, id, "worker-a")
+	s.Require().NoError(err)
+	id, err = queue.Pop(ctx)
+	s.Require().NoError(err)
+	s.Equal(second.ID, id)
+	_, err = s.store.ClaimScanJob(ctx, id, "worker-a")
+	s.Require().NoError(err)
+
+	popped := make(chan uint, 1)
+	go func() {
+		id, _ := queue.Pop(ctx)
+		popped <- id
+	}()
+	third := &models.ScanJob{ToolName: "nikto", Status: models.JobQueued, CorrelationID: tools.NewCorrelationID()}
+	s.Require().NoError(s.store.CreateScanJob(ctx, third))
+	s.Require().NoError(queue.Push(ctx, third.ID, ""))
+	select {
+	case id := <-popped:
+		s.Equal(third.ID, id, "pushes wake waiting pops")
+	case <-time.After(5 * time.Second):
+		s.Fail("pop did not wake up")
+	}
+
+	cancelCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = s.store.ClaimScanJob(ctx, third.ID, "worker-a")
+	s.Require().NoError(err)
+	_, err = queue.Pop(cancelCtx)
+	s.ErrorIs(err, context.Canceled)
+}
+
+func (s *QueueTestSuite) TestRedisQueue() {
+	ctx := context.Background()
+	server := newFakeRedis(s.T())
+	target, err := url.Parse("redis://:secret@" + server.listener.Addr().String() + "/3")
+	s.Require().NoError(err)
+	queue, err := NewRedisQueue(target)
+	s.Require().NoError(err)
+	s.T().Cleanup(func() { _ = queue.Close() })
+
+	s.Require().NoError(queue.Push(ctx, 7, ""))
+	s.Require().NoError(queue.Push(ctx, 8, limiter.PriorityNormal))
+	id, err := queue.Pop(ctx)
+	s.Require().NoError(err)
+	s.Equal(uint(7), id, "the oldest pushed job is popped")
+	id, err = queue.Pop(ctx)
+	s.Require().NoError(err)
+	s.Equal(uint(8), id)
+	s.Contains(server.sent(), "AUTH secret")
+	s.Contains(server.sent(), "SELECT 3")
+	s.Contains(server.sent(), "LPUSH "+DefaultRedisKey+" 7")
+
+	cancelCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	_, err = queue.Pop(cancelCtx)
+	s.Error(err, "pops give up once the context is done")
+}
+
+func (s *QueueTestSuite) TestStoreQueue_Priority() {
+	ctx := context.Background()
+	queue := NewStoreQueue(s.store, time.Hour)
+	var low []uint
+	for range 2 {
+		job := &models.ScanJob{ToolName: "nikto", Status: models.JobQueued, Priority: limiter.PriorityLow, CorrelationID: tools.NewCorrelationID()}
+		s.Require().NoError(s.store.CreateScanJob(ctx, job))
+		low = append(low, job.ID)
+	}
+	normal := &models.ScanJob{ToolName: "nikto", Status: models.JobQueued, CorrelationID: tools.NewCorrelationID()}
+	s.Require().NoError(s.store.CreateScanJob(ctx, normal))
+	high := &models.ScanJob{ToolName: "nikto", Status: models.JobQueued, Priority: limiter.PriorityHigh, CorrelationID: tools.NewCorrelationID()}
+	s.Require().NoError(s.store.CreateScanJob(ctx, high))
+
+	var popped []uint
+	for range 4 {
+		id, err := queue.Pop(ctx)
+		s.Require().NoError(err)
+		_, err = s.store.ClaimScanJob(ctx, id, "worker-a")
+		s.Require().NoError(err)
+		popped = append(popped, id)
+	}
+	s.Equal([]uint{high.ID, normal.ID, low[0], low[1]}, popped, "high priority jobs queued last are popped first")
+}
+
+func (s *QueueTestSuite) TestRedisQueue_Priority() {
+	ctx := context.Background()
+	server := newFakeRedis(s.T())
+	target, err := url.Parse("redis://" + server.listener.Addr().String())
+	s.Require().NoError(err)
+	queue, err := NewRedisQueue(target)
+	s.Require().NoError(err)
+	s.T().Cleanup(func() { _ = queue.Close() })
+
+	s.Require().NoError(queue.Push(ctx, 1, limiter.PriorityLow))
+	s.Require().NoError(queue.Push(ctx, 2, limiter.PriorityLow))
+	s.Require().NoError(queue.Push(ctx, 3, ""))
+	s.Require().NoError(queue.Push(ctx, 4, limiter.PriorityHigh))
+
+	var popped []uint
+	for range 4 {
+		id, err := queue.Pop(ctx)
+		s.Require().NoError(err)
+		popped = append(popped, id)
+	}
+	s.Equal([]uint{4, 3, 1, 2}, popped, "high priority jobs queued last are popped first")
+	s.Contains(server.sent(), "LPUSH "+DefaultRedisKey+":high 4")
+	s.Contains(server.sent(), "LPUSH "+DefaultRedisKey+":low 1")
+}
+
+func (s *QueueTestSuite) TestRedisQueue_AuthFails() {
+	server := newFakeRedis(s.T())
+	target, err := url.Parse("redis://:wrong@" + server.listener.Addr().String())
+	s.Require().NoError(err)
+	queue, err := NewRedisQueue(target)
+	s.Require().NoError(err)
+
+	err = queue.Push(context.Background(), 1, "")
+	s.ErrorIs(err, ErrRedis)
+	s.Contains(err.Error(), "WRONGPASS")
+}
+
+func (s *QueueTestSuite) TestReadReply_Limits() {
+	reply, err := readReply(bufio.NewReader(strings.NewReader("*2\r\n$4\r\njobs\r\n$1\r\n7\r\n")))
+	s.Require().NoError(err)
+	s.Equal([]any{"jobs", "7"}, reply)
+
+	for _, raw := range []string{
+		fmt.Sprintf("$%d\r\n", maxRedisBulk+1),
+		fmt.Sprintf("*%d\r\n", maxRedisArray+1),
+		"*2147483647\r\n",
+	} {
+		_, err := readReply(bufio.NewReader(strings.NewReader(raw)))
+		s.ErrorIs(err, ErrRedis, raw)
+	}
+}
+
+func TestQueueTestSuite(t *testing.T) {
+	suite.Run(t, new(QueueTestSuite))
+}
@@ -0,0 +1,293 @@
+package jobs
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tb0hdan/wass-mcp/pkg/limiter"
+)
+
+const (
+	// DefaultRedisKey is the Redis list holding the queued job IDs of normal priority, overridden by
+	// the key query parameter of the queue URL. High and low priority jobs are held in the lists
+	// of the key suffixed with ":high" and ":low".
+	DefaultRedisKey = "wass-mcp:scan-jobs"
+	// redisPopTimeout bounds each blocking pop, so that Pop notices its context is done.
+	redisPopTimeout = time.Second
+	// redisDialTimeout bounds connecting to Redis.
+	redisDialTimeout = 5 * time.Second
+	// maxRedisBulk bounds the bulk string replies read, far above any job ID.
+	maxRedisBulk = 1 << 20
+	// maxRedisArray bounds the elements of the array replies read, far above the two of BLPOP.
+	maxRedisArray = 1024
+)
+
+// ErrRedis is returned for error replies of Redis and replies the queue cannot read.
+var ErrRedis = errors.New("redis error")
+
+// RedisQueue is a queue held in Redis lists, one per priority, shared by the front ends pushing
+// jobs and the workers popping them: LPUSH adds a job to the list of its priority, BRPOP takes the
+// oldest of the first non-empty list by priority. It speaks the Redis protocol
+// (RESP) over its own connections, one for pushes and one for blocking pops, dialled again after
+// a failure.
+type RedisQueue struct {
+	address  string
+	database int
+	key      string
+	password string
+	username string
+
+	pushMu sync.Mutex
+	push   *redisConn
+	popMu  sync.Mutex
+	pop    *redisConn
+}
+
+// NewRedisQueue returns the queue of a redis://[user:password@]host[:port][/db][?key=name] URL.
+// Nothing is dialled until the first push or pop.
+func NewRedisQueue(target *url.URL) (*RedisQueue, error) {
+	if target.Hostname() == "" {
+		return nil, fmt.Errorf("%w: redis URL without host", ErrQueueBackend)
+	}
+	queue := &RedisQueue{address: target.Host, key: DefaultRedisKey}
+	if target.Port() == "" {
+		queue.address = net.JoinHostPort(target.Hostname(), "6379")
+	}
+	if target.User != nil {
+		queue.username = target.User.Username()
+		queue.password, _ = target.User.Password()
+	}
+	if path := strings.Trim(target.Path, "/"); path != "" {
+		database, err := strconv.Atoi(path)
+		if err != nil || database < 0 {
+			return nil, fmt.Errorf("%w: invalid redis database %q", ErrQueueBackend, path)
+		}
+		queue.database = database
+	}
+	if key := target.Query().Get("key"); key != "" {
+		queue.key = key
+	}
+
+	return queue, nil
+}
+
+// Push adds id to the head of the list of priority.
+func (q *RedisQueue) Push(ctx context.Context, id uint, priority string) error {
+	q.pushMu.Lock()
+	defer q.pushMu.Unlock()
+
+	_, err := q.do(ctx, &q.push, 0, "LPUSH", q.listKey(priority), strconv.FormatUint(uint64(id), 10))
+	if err != nil {
+		return fmt.Errorf("failed to push scan job %d: %w", id, err)
+	}
+
+	return nil
+}
+
+// Pop takes the ID at the tail of the first non-empty list by priority, the oldest pushed, waiting
+// for one.
+func (q *RedisQueue) Pop(ctx context.Context) (uint, error) {
+	q.popMu.Lock()
+	defer q.popMu.Unlock()
+
+	timeout := strconv.Itoa(int(redisPopTimeout / time.Second))
+	// BRPOP checks its keys in order.
+	command := []string{
+		"BRPOP",
+		q.listKey(limiter.PriorityHigh),
+		q.listKey(limiter.PriorityNormal),
+		q.listKey(limiter.PriorityLow),
+		timeout,
+	}
+	for {
+		if err := ctx.Err(); err != nil {
+			return 0, context.Cause(ctx)
+		}
+		reply, err := q.do(ctx, &q.pop, redisPopTimeout, command...)
+		if err != nil {
+			return 0, fmt.Errorf("failed to pop scan job: %w", err)
+		}
+		// A nil reply when the timeout passed without a job, else the key and the value.
+		values, _ := reply.([]any)
+		if len(values) != 2 {
+			continue
+		}
+		value, _ := values[1].(string)
+		id, err := strconv.ParseUint(value, 10, 0)
+		if err != nil {
+			return 0, fmt.Errorf("%w: invalid scan job ID %q", ErrRedis, value)
+		}
+
+		return uint(id), nil
+	}
+}
+
+// listKey returns the key of the list holding the jobs of priority, the queue key itself for
+// normal and unknown priorities.
+func (q *RedisQueue) listKey(priority string) string {
+	switch priority {
+	case limiter.PriorityHigh, limiter.PriorityLow:
+		return q.key + ":" + priority
+	default:
+		return q.key
+	}
+}
+
+// Close closes the connections of the queue.
+func (q *RedisQueue) Close() error {
+	q.pushMu.Lock()
+	defer q.pushMu.Unlock()
+	q.popMu.Lock()
+	defer q.popMu.Unlock()
+
+	return errors.Join(q.push.close(), q.pop.close())
+}
+
+// do sends a command on *conn, dialled first when nil, and reads its reply, waiting up to wait
+// longer than a regular command. The connection is dropped after a failure.
+func (q *RedisQueue) do(ctx context.Context, conn **redisConn, wait time.Duration, args ...string) (any, error) {
+	if *conn == nil {
+		dialled, err := q.dial(ctx)
+		if err != nil {
+			return nil, err
+		}
+		*conn = dialled
+	}
+	reply, err := (*conn).do(ctx, wait, args...)
+	if err != nil && !errors.Is(err, ErrRedis) {
+		_ = (*conn).close()
+		*conn = nil
+	}
+
+	return reply, err
+}
+
+// dial connects to Redis, authenticates and selects the database of the queue.
+func (q *RedisQueue) dial(ctx context.Context) (*redisConn, error) {
+	dialer := net.Dialer{Timeout: redisDialTimeout}
+	netConn, err := dialer.DialContext(ctx, "tcp", q.address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+	conn := &redisConn{conn: netConn, reader: bufio.NewReader(netConn)}
+
+	var setup [][]string
+	switch {
+	case q.username != "" && q.password != "":
+		setup = append(setup, []string{"AUTH", q.username, q.password})
+	case q.password != "":
+		setup = append(setup, []string{"AUTH", q.password})
+	}
+	if q.database != 0 {
+		setup = append(setup, []string{"SELECT", strconv.Itoa(q.database)})
+	}
+	for _, command := range setup {
+		if _, err := conn.do(ctx, 0, command...); err != nil {
+			_ = conn.close()
+			return nil, fmt.Errorf("failed to set up redis connection: %w", err)
+		}
+	}
+
+	return conn, nil
+}
+
+// redisConn is a connection to Redis.
+type redisConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// do writes a command as a RESP array of bulk strings and reads its reply within redisDialTimeout
+// plus wait.
+func (c *redisConn) do(ctx context.Context, wait time.Duration, args ...string) (any, error) {
+	deadline := time.Now().Add(redisDialTimeout + wait)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	if err := c.conn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	var command strings.Builder
+	fmt.Fprintf(&command, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&command, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if _, err := io.WriteString(c.conn, command.String()); err != nil {
+		return nil, err
+	}
+
+	return readReply(c.reader)
+}
+
+// close closes the connection, if any.
+func (c *redisConn) close() error {
+	if c == nil {
+		return nil
+	}
+
+	return c.conn.Close()
+}
+
+// readReply reads a RESP reply: a string for simple and bulk strings, an int64 for integers, a
+// []any for arrays, nil for null replies, and an error wrapping ErrRedis for error replies.
+func readReply(reader *bufio.Reader) (any, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, fmt.Errorf("%w: empty reply", ErrRedis)
+	}
+
+	switch kind, rest := line[0], line[1:]; kind {
+	case '+':
+		return rest, nil
+	case '-':
+		return nil, fmt.Errorf("%w: %s", ErrRedis, rest)
+	case ':':
+		return strconv.ParseInt(rest, 10, 64)
+	case '$':
+		size, err := strconv.Atoi(rest)
+		if err != nil || size < 0 {
+			return nil, err
+		}
+		if size > maxRedisBulk {
+			return nil, fmt.Errorf("%w: reply of %d bytes", ErrRedis, size)
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return nil, err
+		}
+		return string(data[:size]), nil
+	case '*':
+		count, err := strconv.Atoi(rest)
+		if err != nil || count < 0 {
+			return nil, err
+		}
+		if count > maxRedisArray {
+			return nil, fmt.Errorf("%w: reply of %d elements", ErrRedis, count)
+		}
+		values := make([]any, 0, count)
+		for range count {
+			value, err := readReply(reader)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		return values, nil
+	}
+
+	return nil, fmt.Errorf("%w: unexpected reply %q", ErrRedis, line)
+}
@@ -0,0 +1,177 @@
+package limiter
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Scan priorities. Runs waiting for a slot are served by priority, then in arrival order.
+const (
+	PriorityLow    = "low"
+	PriorityNormal = "normal"
+	PriorityHigh   = "high"
+)
+
+type priorityKey struct{}
+
+// WithPriority returns a context whose scanner runs wait for a slot with the given priority.
+func WithPriority(ctx context.Context, priority string) context.Context {
+	return context.WithValue(ctx, priorityKey{}, priority)
+}
+
+// PriorityFrom returns the priority set on ctx by WithPriority, or an empty string.
+func PriorityFrom(ctx context.Context) string {
+	priority, _ := ctx.Value(priorityKey{}).(string)
+
+	return priority
+}
+
+// rank orders priorities, unknown and empty ones ranking as normal.
+func rank(priority string) int {
+	switch priority {
+	case PriorityLow:
+		return 0
+	case PriorityHigh:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// waiter is a run waiting for a slot.
+type waiter struct {
+	granted bool
+	rank    int
+	ready   chan struct{}
+}
+
+// Limiter bounds the number of scanner runs in flight at the same time across all tools.
+// Runs waiting for a slot are queued by the priority of their context.
+// A nil Limiter is unlimited.
+type Limiter struct {
+	mu       sync.Mutex
+	inFlight int
+	limit    int
+	// waiters is ordered by descending priority, then by arrival.
+	waiters []*waiter
+}
+
+// New creates a Limiter allowing at most limit concurrent runs. It returns nil, meaning
+// unlimited, when limit is not positive.
+func New(limit int) *Limiter {
+	if limit <= 0 {
+		return nil
+	}
+
+	return &Limiter{limit: limit}
+}
+
+// Acquire waits for a free slot, behind runs of the same or a higher priority already waiting.
+// It fails when ctx is done first.
+func (l *Limiter) Acquire(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	if l.inFlight < l.limit && len(l.waiters) == 0 {
+		l.inFlight++
+		l.mu.Unlock()
+		return nil
+	}
+
+	current := &waiter{rank: rank(PriorityFrom(ctx)), ready: make(chan struct{})}
+	position := len(l.waiters)
+	for i, queued := range l.waiters {
+		if queued.rank < current.rank {
+			position = i
+			break
+		}
+	}
+	l.waiters = append(l.waiters, nil)
+	copy(l.waiters[position+1:], l.waiters[position:])
+	l.waiters[position] = current
+	l.mu.Unlock()
+
+	select {
+	case <-current.ready:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		if current.granted {
+			// The slot was handed over while giving up: pass it on.
+			l.releaseLocked()
+		} else {
+			l.removeLocked(current)
+		}
+		l.mu.Unlock()
+		return fmt.Errorf("waiting for a scan slot: %w", ctx.Err())
+	}
+}
+
+// Release frees a slot taken by Acquire, handing it to the first waiting run.
+func (l *Limiter) Release() {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	l.releaseLocked()
+	l.mu.Unlock()
+}
+
+func (l *Limiter) releaseLocked() {
+	l.inFlight--
+	if len(l.waiters) == 0 {
+		return
+	}
+
+	next := l.waiters[0]
+	l.waiters = l.waiters[1:]
+	next.granted = true
+	l.inFlight++
+	close(next.ready)
+}
+
+func (l *Limiter) removeLocked(target *waiter) {
+	for i, queued := range l.waiters {
+		if queued == target {
+			l.waiters = append(l.waiters[:i], l.waiters[i+1:]...)
+			return
+		}
+	}
+}
+
+// Limit returns the maximum number of concurrent runs, 0 when unlimited.
+func (l *Limiter) Limit() int {
+	if l == nil {
+		return 0
+	}
+
+	return l.limit
+}
+
+// InFlight returns the number of runs currently holding a slot.
+func (l *Limiter) InFlight() int {
+	if l == nil {
+		return 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.inFlight
+}
+
+// Waiting returns the number of runs waiting for a slot.
+func (l *Limiter) Waiting() int {
+	if l == nil {
+		return 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return len(l.waiters)
+}
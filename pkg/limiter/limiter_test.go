@@ -0,0 +1,146 @@
+package limiter
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type LimiterTestSuite struct {
+	suite.Suite
+}
+
+func (s *LimiterTestSuite) TestNew_Unlimited() {
+	s.Nil(New(0))
+	s.Nil(New(-1))
+
+	var unlimited *Limiter
+	s.NoError(unlimited.Acquire(context.Background()))
+	unlimited.Release()
+	s.Equal(0, unlimited.Limit())
+	s.Equal(0, unlimited.InFlight())
+}
+
+func (s *LimiterTestSuite) TestAcquireRelease() {
+	limiter := New(2)
+	s.Equal(2, limiter.Limit())
+
+	s.Require().NoError(limiter.Acquire(context.Background()))
+	s.Require().NoError(limiter.Acquire(context.Background()))
+	s.Equal(2, limiter.InFlight())
+
+	limiter.Release()
+	s.Equal(1, limiter.InFlight())
+}
+
+func (s *LimiterTestSuite) TestAcquire_ContextDone() {
+	limiter := New(1)
+	s.Require().NoError(limiter.Acquire(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := limiter.Acquire(ctx)
+	s.Error(err)
+	s.ErrorIs(err, context.DeadlineExceeded)
+}
+
+func (s *LimiterTestSuite) TestBoundsConcurrency() {
+	limiter := New(3)
+
+	var (
+		running atomic.Int32
+		peak    atomic.Int32
+		wg      sync.WaitGroup
+	)
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.NoError(limiter.Acquire(context.Background()))
+			defer limiter.Release()
+
+			current := running.Add(1)
+			for {
+				observed := peak.Load()
+				if current <= observed || peak.CompareAndSwap(observed, current) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			running.Add(-1)
+		}()
+	}
+	wg.Wait()
+
+	s.LessOrEqual(peak.Load(), int32(3))
+	s.Equal(0, limiter.InFlight())
+}
+
+// queue starts a run with the given priority waiting on limiter and waits until it is queued.
+// The run records its priority in order once it gets a slot, then releases it.
+func (s *LimiterTestSuite) queue(limiter *Limiter, priority string, order chan<- string, wg *sync.WaitGroup) {
+	waiting := limiter.Waiting()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		s.NoError(limiter.Acquire(WithPriority(context.Background(), priority)))
+		order <- priority
+		limiter.Release()
+	}()
+	s.Eventually(func() bool { return limiter.Waiting() == waiting+1 }, time.Second, time.Millisecond)
+}
+
+func (s *LimiterTestSuite) TestAcquire_Priority() {
+	limiter := New(1)
+	s.Require().NoError(limiter.Acquire(context.Background()))
+
+	order := make(chan string, 4)
+	var wg sync.WaitGroup
+	s.queue(limiter, PriorityLow, order, &wg)
+	s.queue(limiter, PriorityNormal, order, &wg)
+	s.queue(limiter, PriorityHigh, order, &wg)
+	s.queue(limiter, "", order, &wg)
+
+	limiter.Release()
+	wg.Wait()
+	close(order)
+
+	var served []string
+	for priority := range order {
+		served = append(served, priority)
+	}
+	s.Equal([]string{PriorityHigh, PriorityNormal, "", PriorityLow}, served)
+	s.Equal(0, limiter.InFlight())
+}
+
+func (s *LimiterTestSuite) TestAcquire_CancelledWaiterLeavesQueue() {
+	limiter := New(1)
+	s.Require().NoError(limiter.Acquire(context.Background()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- limiter.Acquire(ctx) }()
+	s.Eventually(func() bool { return limiter.Waiting() == 1 }, time.Second, time.Millisecond)
+
+	cancel()
+	s.ErrorIs(<-done, context.Canceled)
+	s.Equal(0, limiter.Waiting())
+
+	limiter.Release()
+	s.Equal(0, limiter.InFlight())
+	s.Require().NoError(limiter.Acquire(context.Background()))
+}
+
+func (s *LimiterTestSuite) TestPriorityFrom() {
+	s.Empty(PriorityFrom(context.Background()))
+	s.Equal(PriorityHigh, PriorityFrom(WithPriority(context.Background(), PriorityHigh)))
+}
+
+func TestLimiterTestSuite(t *testing.T) {
+	suite.Run(t, new(LimiterTestSuite))
+}
@@ -0,0 +1,99 @@
+// Package logging builds the server logger from the command line configuration: where log lines
+// go (stdout, stderr or a size-rotated file), how they are formatted and the minimum level.
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Outputs and formats.
+const (
+	OutputStdout  = "stdout"
+	OutputStderr  = "stderr"
+	FormatJSON    = "json"
+	FormatConsole = "console"
+)
+
+// Defaults for the command line flags.
+const (
+	DefaultOutput     = OutputStdout
+	DefaultFormat     = FormatJSON
+	DefaultLevel      = "info"
+	DefaultMaxSizeMB  = 100
+	DefaultMaxBackups = 5
+)
+
+// bytesPerMB converts Config.MaxSizeMB to bytes.
+const bytesPerMB = 1 << 20
+
+// ErrInvalidConfig is returned for unknown formats and levels and negative rotation limits.
+var ErrInvalidConfig = errors.New("invalid logging config")
+
+// Config selects the output, format and level of the logger.
+type Config struct {
+	// Output is OutputStdout, OutputStderr or the path of a log file.
+	Output string
+	// Format is FormatJSON or FormatConsole.
+	Format string
+	// Level is a zerolog level name such as "debug" or "warn".
+	Level string
+	// MaxSizeMB is the size at which a log file is rotated, 0 to never rotate.
+	MaxSizeMB int
+	// MaxBackups is the number of rotated log files kept.
+	MaxBackups int
+}
+
+// New creates the logger described by cfg and sets the zerolog global level, which the admin
+// endpoints change at runtime. The returned closer releases the log file, if any.
+func New(cfg Config) (zerolog.Logger, io.Closer, error) {
+	level, err := zerolog.ParseLevel(strings.ToLower(cfg.Level))
+	if err != nil || level == zerolog.NoLevel {
+		return zerolog.Nop(), nil, fmt.Errorf("%w: unknown level %q", ErrInvalidConfig, cfg.Level)
+	}
+	format := strings.ToLower(cfg.Format)
+	if format != "" && format != FormatJSON && format != FormatConsole {
+		return zerolog.Nop(), nil, fmt.Errorf("%w: unknown format %q", ErrInvalidConfig, cfg.Format)
+	}
+	if cfg.MaxSizeMB < 0 || cfg.MaxBackups < 0 {
+		return zerolog.Nop(), nil, fmt.Errorf("%w: rotation limits must not be negative", ErrInvalidConfig)
+	}
+
+	var (
+		out    io.Writer
+		closer io.Closer = nopCloser{}
+		isFile bool
+	)
+	switch cfg.Output {
+	case "", OutputStdout:
+		out = os.Stdout
+	case OutputStderr:
+		out = os.Stderr
+	default:
+		file, err := OpenRotatingFile(cfg.Output, int64(cfg.MaxSizeMB)*bytesPerMB, cfg.MaxBackups)
+		if err != nil {
+			return zerolog.Nop(), nil, err
+		}
+		out, closer, isFile = file, file, true
+	}
+
+	if format == FormatConsole {
+		// Colors only make sense on a terminal.
+		out = zerolog.ConsoleWriter{Out: out, NoColor: isFile, TimeFormat: time.RFC3339}
+	}
+
+	zerolog.SetGlobalLevel(level)
+
+	return zerolog.New(out).With().Timestamp().Logger(), closer, nil
+}
+
+// nopCloser is the closer of loggers writing to stdout or stderr, which are left open.
+type nopCloser struct{}
+
+func (nopCloser) Close() error { return nil }
@@ -0,0 +1,85 @@
+package logging
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+)
+
+type LoggingTestSuite struct {
+	suite.Suite
+	level zerolog.Level
+}
+
+func (s *LoggingTestSuite) SetupTest() {
+	s.level = zerolog.GlobalLevel()
+}
+
+func (s *LoggingTestSuite) TearDownTest() {
+	zerolog.SetGlobalLevel(s.level)
+}
+
+func (s *LoggingTestSuite) TestJSONFile() {
+	path := filepath.Join(s.T().TempDir(), "wass-mcp.log")
+	logger, closer, err := New(Config{Output: path, Format: FormatJSON, Level: "warn"})
+	s.Require().NoError(err)
+
+	logger.Info().Msg("dropped")
+	logger.Warn().Msg("kept")
+	s.Require().NoError(closer.Close())
+
+	data, err := os.ReadFile(path)
+	s.Require().NoError(err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	s.Require().Len(lines, 1)
+
+	var entry map[string]any
+	s.Require().NoError(json.Unmarshal([]byte(lines[0]), &entry))
+	s.Equal("warn", entry["level"])
+	s.Equal("kept", entry["message"])
+	s.Contains(entry, "time")
+	s.Equal(zerolog.WarnLevel, zerolog.GlobalLevel())
+}
+
+func (s *LoggingTestSuite) TestConsoleFile() {
+	path := filepath.Join(s.T().TempDir(), "wass-mcp.log")
+	logger, closer, err := New(Config{Output: path, Format: "Console", Level: "DEBUG"})
+	s.Require().NoError(err)
+
+	logger.Debug().Str("tool", "nikto").Msg("starting scan")
+	s.Require().NoError(closer.Close())
+
+	data, err := os.ReadFile(path)
+	s.Require().NoError(err)
+	s.Contains(string(data), "DBG starting scan tool=nikto")
+	s.NotContains(string(data), "\x1b[")
+}
+
+func (s *LoggingTestSuite) TestStdout() {
+	_, closer, err := New(Config{Level: DefaultLevel})
+	s.Require().NoError(err)
+	s.NoError(closer.Close())
+	s.Equal(zerolog.InfoLevel, zerolog.GlobalLevel())
+}
+
+func (s *LoggingTestSuite) TestInvalid() {
+	for _, cfg := range []Config{
+		{Level: "loud"},
+		{Level: ""},
+		{Level: "info", Format: "xml"},
+		{Level: "info", MaxSizeMB: -1},
+		{Level: "info", MaxBackups: -1},
+	} {
+		_, _, err := New(cfg)
+		s.ErrorIs(err, ErrInvalidConfig, "%+v", cfg)
+	}
+}
+
+func TestLoggingTestSuite(t *testing.T) {
+	suite.Run(t, new(LoggingTestSuite))
+}
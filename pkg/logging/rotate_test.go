@@ -0,0 +1,98 @@
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type RotatingFileTestSuite struct {
+	suite.Suite
+	path string
+}
+
+func (s *RotatingFileTestSuite) SetupTest() {
+	s.path = filepath.Join(s.T().TempDir(), "logs", "wass-mcp.log")
+}
+
+func (s *RotatingFileTestSuite) read(path string) string {
+	data, err := os.ReadFile(path)
+	s.Require().NoError(err)
+	return string(data)
+}
+
+func (s *RotatingFileTestSuite) TestRotatesAndKeepsBackups() {
+	file, err := OpenRotatingFile(s.path, 10, 2)
+	s.Require().NoError(err)
+	defer file.Close()
+
+	for _, line := range []string{"aaaaaaaa\n", "bbbbbbbb\n", "cccccccc\n", "dddddddd\n"} {
+		_, err := file.Write([]byte(line))
+		s.Require().NoError(err)
+	}
+
+	s.Equal("dddddddd\n", s.read(s.path))
+	s.Equal("cccccccc\n", s.read(s.path+".1"))
+	s.Equal("bbbbbbbb\n", s.read(s.path+".2"))
+	s.NoFileExists(s.path + ".3")
+}
+
+func (s *RotatingFileTestSuite) TestNoBackups() {
+	file, err := OpenRotatingFile(s.path, 10, 0)
+	s.Require().NoError(err)
+	defer file.Close()
+
+	_, err = file.Write([]byte("aaaaaaaa\n"))
+	s.Require().NoError(err)
+	_, err = file.Write([]byte("bbbbbbbb\n"))
+	s.Require().NoError(err)
+
+	s.Equal("bbbbbbbb\n", s.read(s.path))
+	s.NoFileExists(s.path + ".1")
+}
+
+func (s *RotatingFileTestSuite) TestUnlimitedAndAppends() {
+	s.Require().NoError(os.MkdirAll(filepath.Dir(s.path), 0o750))
+	s.Require().NoError(os.WriteFile(s.path, []byte("existing\n"), 0o600))
+
+	file, err := OpenRotatingFile(s.path, 0, 1)
+	s.Require().NoError(err)
+	_, err = file.Write([]byte(strings.Repeat("x", 100) + "\n"))
+	s.Require().NoError(err)
+	s.Require().NoError(file.Close())
+
+	s.True(strings.HasPrefix(s.read(s.path), "existing\nxxx"))
+	s.NoFileExists(s.path + ".1")
+}
+
+func (s *RotatingFileTestSuite) TestExistingSizeCounts() {
+	s.Require().NoError(os.MkdirAll(filepath.Dir(s.path), 0o750))
+	s.Require().NoError(os.WriteFile(s.path, []byte("existing\n"), 0o600))
+
+	file, err := OpenRotatingFile(s.path, 10, 1)
+	s.Require().NoError(err)
+	defer file.Close()
+
+	_, err = file.Write([]byte("new\n"))
+	s.Require().NoError(err)
+
+	s.Equal("new\n", s.read(s.path))
+	s.Equal("existing\n", s.read(s.path+".1"))
+}
+
+func (s *RotatingFileTestSuite) TestWriteAfterClose() {
+	file, err := OpenRotatingFile(s.path, 10, 1)
+	s.Require().NoError(err)
+	s.Require().NoError(file.Close())
+	s.Require().NoError(file.Close())
+
+	_, err = file.Write([]byte("late\n"))
+	s.ErrorIs(err, os.ErrClosed)
+}
+
+func TestRotatingFileTestSuite(t *testing.T) {
+	suite.Run(t, new(RotatingFileTestSuite))
+}
@@ -0,0 +1,300 @@
+// Package metrics tracks scanner failures, tool output sizes and execution phase timings and exposes them in the Prometheus
+// text format, so that alerting can catch scanners or targets that keep failing.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ContentType is the Prometheus text exposition format content type.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// labelEscaper escapes label values as required by the text exposition format.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// scannerStats are the run statistics of a single scanner.
+type scannerStats struct {
+	consecutive int
+	failures    uint64
+	lastFailure time.Time
+	runs        uint64
+}
+
+// outputStats are the output statistics of a single tool.
+type outputStats struct {
+	bytes   uint64
+	outputs uint64
+	spilled uint64
+}
+
+// phaseKey identifies a tool and execution phase pair.
+type phaseKey struct {
+	tool  string
+	phase string
+}
+
+// phaseStats are the timings of an execution phase of a single tool.
+type phaseStats struct {
+	count uint64
+	total time.Duration
+}
+
+// targetKey identifies a scanner and target pair.
+type targetKey struct {
+	scanner string
+	target  string
+}
+
+// Metrics records scanner run outcomes. A nil Metrics records nothing.
+type Metrics struct {
+	mu       sync.Mutex
+	outputs  map[string]*outputStats
+	phases   map[phaseKey]*phaseStats
+	scanners map[string]*scannerStats
+	// targets holds the consecutive failures of scanner and target pairs that are currently failing.
+	targets map[targetKey]int
+}
+
+// New creates empty metrics.
+func New() *Metrics {
+	return &Metrics{
+		outputs:  make(map[string]*outputStats),
+		phases:   make(map[phaseKey]*phaseStats),
+		scanners: make(map[string]*scannerStats),
+		targets:  make(map[targetKey]int),
+	}
+}
+
+// RecordScan records the outcome of a scanner run against target. A failed run increments the
+// consecutive failure counts of the scanner and of the scanner and target pair; a successful run
+// resets them. Pairs are dropped once they succeed again to bound the number of target series.
+func (m *Metrics) RecordScan(scanner, target string, err error) {
+	if m == nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats, ok := m.scanners[scanner]
+	if !ok {
+		stats = &scannerStats{}
+		m.scanners[scanner] = stats
+	}
+	stats.runs++
+
+	key := targetKey{scanner: scanner, target: target}
+	if err == nil {
+		stats.consecutive = 0
+		delete(m.targets, key)
+		return
+	}
+
+	stats.consecutive++
+	stats.failures++
+	stats.lastFailure = time.Now()
+	m.targets[key]++
+}
+
+// RecordOutput records the output of a tool call: size is the size in bytes of the content
+// returned, and spilled whether its stored output was moved to an artifact file.
+func (m *Metrics) RecordOutput(tool string, size int, spilled bool) {
+	if m == nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats, ok := m.outputs[tool]
+	if !ok {
+		stats = &outputStats{}
+		m.outputs[tool] = stats
+	}
+	stats.outputs++
+	stats.bytes += uint64(max(size, 0))
+	if spilled {
+		stats.spilled++
+	}
+}
+
+// RecordPhases records the phase durations of an execution of tool, keyed by phase name.
+func (m *Metrics) RecordPhases(tool string, durations map[string]time.Duration) {
+	if m == nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for phase, d := range durations {
+		key := phaseKey{tool: tool, phase: phase}
+		stats, ok := m.phases[key]
+		if !ok {
+			stats = &phaseStats{}
+			m.phases[key] = stats
+		}
+		stats.count++
+		stats.total += d
+	}
+}
+
+// ConsecutiveFailures returns the number of failed runs of scanner since its last successful run.
+func (m *Metrics) ConsecutiveFailures(scanner string) int {
+	if m == nil {
+		return 0
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if stats, ok := m.scanners[scanner]; ok {
+		return stats.consecutive
+	}
+	return 0
+}
+
+// TargetFailures returns the number of failed runs of scanner against target since its last
+// successful run against it.
+func (m *Metrics) TargetFailures(scanner, target string) int {
+	if m == nil {
+		return 0
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.targets[targetKey{scanner: scanner, target: target}]
+}
+
+// ServeHTTP writes the metrics in the Prometheus text exposition format.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", ContentType)
+	_ = m.Write(w)
+}
+
+// Write writes the metrics in the Prometheus text exposition format, sorted by label values.
+func (m *Metrics) Write(w io.Writer) error {
+	if m == nil {
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	names := make([]string, 0, len(m.scanners))
+	for name := range m.scanners {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	keys := make([]targetKey, 0, len(m.targets))
+	for key := range m.targets {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].scanner != keys[j].scanner {
+			return keys[i].scanner < keys[j].scanner
+		}
+		return keys[i].target < keys[j].target
+	})
+
+	tools := make([]string, 0, len(m.outputs))
+	for tool := range m.outputs {
+		tools = append(tools, tool)
+	}
+	sort.Strings(tools)
+
+	phases := make([]phaseKey, 0, len(m.phases))
+	for key := range m.phases {
+		phases = append(phases, key)
+	}
+	sort.Slice(phases, func(i, j int) bool {
+		if phases[i].tool != phases[j].tool {
+			return phases[i].tool < phases[j].tool
+		}
+		return phases[i].phase < phases[j].phase
+	})
+
+	var builder strings.Builder
+	family := func(name, kind, help string, samples func()) {
+		fmt.Fprintf(&builder, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+		samples()
+	}
+
+	family("wass_scanner_runs_total", "counter", "Scanner runs.", func() {
+		for _, name := range names {
+			fmt.Fprintf(&builder, "wass_scanner_runs_total{scanner=\"%s\"} %d\n", escape(name), m.scanners[name].runs)
+		}
+	})
+	family("wass_scanner_failures_total", "counter", "Failed scanner runs.", func() {
+		for _, name := range names {
+			fmt.Fprintf(&builder, "wass_scanner_failures_total{scanner=\"%s\"} %d\n", escape(name), m.scanners[name].failures)
+		}
+	})
+	family("wass_scanner_consecutive_failures", "gauge",
+		"Failed scanner runs since the last successful run.", func() {
+			for _, name := range names {
+				fmt.Fprintf(&builder, "wass_scanner_consecutive_failures{scanner=\"%s\"} %d\n",
+					escape(name), m.scanners[name].consecutive)
+			}
+		})
+	family("wass_scanner_last_failure_timestamp_seconds", "gauge",
+		"Unix time of the last failed scanner run, 0 when it never failed.", func() {
+			for _, name := range names {
+				var timestamp int64
+				if last := m.scanners[name].lastFailure; !last.IsZero() {
+					timestamp = last.Unix()
+				}
+				fmt.Fprintf(&builder, "wass_scanner_last_failure_timestamp_seconds{scanner=\"%s\"} %d\n",
+					escape(name), timestamp)
+			}
+		})
+	family("wass_target_consecutive_failures", "gauge",
+		"Failed scanner runs against a target since the last successful run, only for failing targets.", func() {
+			for _, key := range keys {
+				fmt.Fprintf(&builder, "wass_target_consecutive_failures{scanner=\"%s\",target=\"%s\"} %d\n",
+					escape(key.scanner), escape(key.target), m.targets[key])
+			}
+		})
+	family("wass_tool_outputs_total", "counter", "Tool calls with an output.", func() {
+		for _, tool := range tools {
+			fmt.Fprintf(&builder, "wass_tool_outputs_total{tool=\"%s\"} %d\n", escape(tool), m.outputs[tool].outputs)
+		}
+	})
+	family("wass_tool_output_bytes_total", "counter", "Bytes of content returned by tool calls.", func() {
+		for _, tool := range tools {
+			fmt.Fprintf(&builder, "wass_tool_output_bytes_total{tool=\"%s\"} %d\n", escape(tool), m.outputs[tool].bytes)
+		}
+	})
+	family("wass_tool_outputs_spilled_total", "counter", "Tool outputs stored in artifact files.", func() {
+		for _, tool := range tools {
+			fmt.Fprintf(&builder, "wass_tool_outputs_spilled_total{tool=\"%s\"} %d\n", escape(tool), m.outputs[tool].spilled)
+		}
+	})
+
+	family("wass_tool_phase_seconds", "summary", "Time tool executions spent in each phase.", func() {
+		for _, key := range phases {
+			labels := fmt.Sprintf("tool=\"%s\",phase=\"%s\"", escape(key.tool), escape(key.phase))
+			fmt.Fprintf(&builder, "wass_tool_phase_seconds_sum{%s} %g\n", labels, m.phases[key].total.Seconds())
+			fmt.Fprintf(&builder, "wass_tool_phase_seconds_count{%s} %d\n", labels, m.phases[key].count)
+		}
+	})
+
+	if _, err := io.WriteString(w, builder.String()); err != nil {
+		return fmt.Errorf("failed to write metrics: %w", err)
+	}
+
+	return nil
+}
+
+// escape escapes a label value.
+func escape(value string) string {
+	return labelEscaper.Replace(value)
+}
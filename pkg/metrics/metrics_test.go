@@ -0,0 +1,129 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+var errScan = errors.New("scan failed")
+
+type MetricsTestSuite struct {
+	suite.Suite
+}
+
+func (s *MetricsTestSuite) TestRecordScan_ConsecutiveFailures() {
+	metrics := New()
+	for range 3 {
+		metrics.RecordScan("nuclei", "https://example.com", errScan)
+	}
+	metrics.RecordScan("nuclei", "https://other.example.com", nil)
+
+	s.Equal(0, metrics.ConsecutiveFailures("nuclei"))
+	s.Equal(3, metrics.TargetFailures("nuclei", "https://example.com"))
+
+	metrics.RecordScan("nuclei", "https://example.com", errScan)
+	s.Equal(1, metrics.ConsecutiveFailures("nuclei"))
+	s.Equal(4, metrics.TargetFailures("nuclei", "https://example.com"))
+
+	metrics.RecordScan("nuclei", "https://example.com", nil)
+	s.Equal(0, metrics.ConsecutiveFailures("nuclei"))
+	s.Equal(0, metrics.TargetFailures("nuclei", "https://example.com"))
+	s.Equal(0, metrics.ConsecutiveFailures("nikto"))
+}
+
+func (s *MetricsTestSuite) TestWrite() {
+	metrics := New()
+	metrics.RecordScan("nuclei", "https://example.com", errScan)
+	metrics.RecordScan("nuclei", "https://example.com", errScan)
+	metrics.RecordScan("nikto", "http://example.com", nil)
+
+	var builder strings.Builder
+	s.Require().NoError(metrics.Write(&builder))
+	text := builder.String()
+
+	s.Contains(text, "# TYPE wass_scanner_consecutive_failures gauge\n")
+	s.Contains(text, "# TYPE wass_scanner_runs_total counter\n")
+	s.Contains(text, `wass_scanner_runs_total{scanner="nikto"} 1`)
+	s.Contains(text, `wass_scanner_runs_total{scanner="nuclei"} 2`)
+	s.Contains(text, `wass_scanner_failures_total{scanner="nikto"} 0`)
+	s.Contains(text, `wass_scanner_failures_total{scanner="nuclei"} 2`)
+	s.Contains(text, `wass_scanner_consecutive_failures{scanner="nuclei"} 2`)
+	s.Contains(text, `wass_scanner_last_failure_timestamp_seconds{scanner="nikto"} 0`)
+	s.Contains(text, `wass_target_consecutive_failures{scanner="nuclei",target="https://example.com"} 2`)
+	s.NotContains(text, `target="http://example.com"`)
+	// Series are sorted by label values.
+	s.Less(strings.Index(text, `runs_total{scanner="nikto"}`), strings.Index(text, `runs_total{scanner="nuclei"}`))
+}
+
+func (s *MetricsTestSuite) TestRecordOutput() {
+	metrics := New()
+	metrics.RecordOutput("nuclei", 100, false)
+	metrics.RecordOutput("nuclei", 5000, true)
+	metrics.RecordOutput("nikto", 10, false)
+
+	var builder strings.Builder
+	s.Require().NoError(metrics.Write(&builder))
+	text := builder.String()
+
+	s.Contains(text, "# TYPE wass_tool_output_bytes_total counter\n")
+	s.Contains(text, `wass_tool_outputs_total{tool="nuclei"} 2`)
+	s.Contains(text, `wass_tool_output_bytes_total{tool="nuclei"} 5100`)
+	s.Contains(text, `wass_tool_outputs_spilled_total{tool="nuclei"} 1`)
+	s.Contains(text, `wass_tool_outputs_spilled_total{tool="nikto"} 0`)
+	s.Less(strings.Index(text, `outputs_total{tool="nikto"}`), strings.Index(text, `outputs_total{tool="nuclei"}`))
+}
+
+func (s *MetricsTestSuite) TestRecordPhases() {
+	metrics := New()
+	metrics.RecordPhases("nikto", map[string]time.Duration{"exec": 2 * time.Second, "parse": 0})
+	metrics.RecordPhases("nikto", map[string]time.Duration{"exec": 500 * time.Millisecond, "parse": 0})
+
+	var builder strings.Builder
+	s.Require().NoError(metrics.Write(&builder))
+	text := builder.String()
+	s.Contains(text, "# TYPE wass_tool_phase_seconds summary\n")
+	s.Contains(text, `wass_tool_phase_seconds_sum{tool="nikto",phase="exec"} 2.5`)
+	s.Contains(text, `wass_tool_phase_seconds_count{tool="nikto",phase="exec"} 2`)
+	s.Contains(text, `wass_tool_phase_seconds_count{tool="nikto",phase="parse"} 2`)
+	s.Less(strings.Index(text, `phase="exec"`), strings.Index(text, `phase="parse"`))
+}
+
+func (s *MetricsTestSuite) TestWrite_EscapesLabels() {
+	metrics := New()
+	metrics.RecordScan("nuclei", "http://example.com/\"quoted\"\\\n", errScan)
+
+	var builder strings.Builder
+	s.Require().NoError(metrics.Write(&builder))
+	s.Contains(builder.String(), `target="http://example.com/\"quoted\"\\\n"`)
+}
+
+func (s *MetricsTestSuite) TestServeHTTP() {
+	metrics := New()
+	metrics.RecordScan("nikto", "http://example.com", nil)
+
+	rec := httptest.NewRecorder()
+	metrics.ServeHTTP(rec, httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/metrics", nil))
+	s.Equal(ContentType, rec.Header().Get("Content-Type"))
+	s.Contains(rec.Body.String(), `wass_scanner_runs_total{scanner="nikto"} 1`)
+}
+
+func (s *MetricsTestSuite) TestNilMetrics() {
+	var metrics *Metrics
+	metrics.RecordScan("nikto", "http://example.com", errScan)
+	metrics.RecordOutput("nikto", 10, true)
+	metrics.RecordPhases("nikto", map[string]time.Duration{"exec": time.Second})
+	s.Equal(0, metrics.ConsecutiveFailures("nikto"))
+	s.Equal(0, metrics.TargetFailures("nikto", "http://example.com"))
+	s.NoError(metrics.Write(&strings.Builder{}))
+}
+
+func TestMetricsTestSuite(t *testing.T) {
+	suite.Run(t, new(MetricsTestSuite))
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// Credential is a named secret of a tenant used to authenticate scans, e.g. "staging-admin".
+// Scan inputs reference credentials by name, so that secrets never travel through MCP calls.
+// The secret is stored encrypted by the credential vault and never serialized. Names are unique
+// per tenant.
+type Credential struct {
+	ID          uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	Tenant      string    `gorm:"type:varchar(64);uniqueIndex:idx_credentials_tenant_name" json:"-"`
+	Name        string    `gorm:"type:varchar(64);uniqueIndex:idx_credentials_tenant_name;not null" json:"name"`
+	Type        string    `gorm:"type:varchar(32);not null" json:"type"`
+	Description string    `gorm:"type:text" json:"description,omitempty"`
+	// Secret is the encrypted secret, see vault.Vault.Seal.
+	Secret []byte `gorm:"type:blob;not null" json:"-"`
+}
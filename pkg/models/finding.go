@@ -0,0 +1,100 @@
+package models
+
+import "time"
+
+// Finding triage statuses. Findings start open; resolved, false positive and accepted findings
+// can be reopened.
+const (
+	FindingOpen          = "open"
+	FindingInProgress    = "in_progress"
+	FindingResolved      = "resolved"
+	FindingFalsePositive = "false_positive"
+	FindingAccepted      = "accepted"
+)
+
+// Evidence kinds.
+const (
+	EvidenceRequest   = "request"
+	EvidenceResponse  = "response"
+	EvidenceCurl      = "curl"
+	EvidenceExtracted = "extracted"
+	EvidenceArtifact  = "artifact"
+	EvidenceNote      = "note"
+)
+
+// Evidence sources other than scanner names.
+const (
+	// EvidenceSourceManual is the source of evidence attached by hand.
+	EvidenceSourceManual = "manual"
+	// EvidenceSourceCapture is the source of HAR captures recorded by the capture proxy.
+	EvidenceSourceCapture = "capture"
+)
+
+// Evidence backs a finding with a request or response snippet, a reproduction command, data
+// extracted by the scanner, a note, or a reference to an artifact such as a file, URL or output
+// resource. Source is the scanner that captured it or EvidenceSourceManual.
+type Evidence struct {
+	AddedAt   time.Time `json:"added_at,omitzero"`
+	AddedBy   string    `json:"added_by,omitempty"`
+	Content   string    `json:"content,omitempty"`
+	Kind      string    `json:"kind"`
+	Reference string    `json:"reference,omitempty"`
+	Source    string    `json:"source"`
+}
+
+// findingTransitions lists the statuses each triage status can move to.
+var findingTransitions = map[string][]string{
+	FindingOpen:          {FindingInProgress, FindingResolved, FindingFalsePositive, FindingAccepted},
+	FindingInProgress:    {FindingOpen, FindingResolved, FindingFalsePositive, FindingAccepted},
+	FindingResolved:      {FindingOpen},
+	FindingFalsePositive: {FindingOpen},
+	FindingAccepted:      {FindingOpen},
+}
+
+// CanTransition reports whether a finding with triage status from can move to status to.
+// Findings stored before triage statuses existed have an empty status and count as open.
+func CanTransition(from, to string) bool {
+	if from == "" {
+		from = FindingOpen
+	}
+	for _, next := range findingTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Finding is a single security finding extracted from scanner output. TemplateID identifies the
+// scanner check that raised it when the scanner reports one, such as a nuclei template ID, and
+// Parameter the request parameter it concerns, such as the injectable parameter of a wapiti issue.
+// Findings persisted by the execution logger reference their execution via ExecutionID and
+// belong to the tenant of that execution. Stored findings carry a triage status and the
+// identity of the owner they are assigned to. Evidence holds the snippets scanners provided and
+// the evidence attached since. CWEs are the weakness classes of the finding, see
+// findings.CWEIDs. CVE-linked findings carry the highest EPSS probability of their CVEs and
+// whether any is in the CISA Known Exploited Vulnerabilities catalog. Fingerprint identifies the
+// same finding of the same target across scans, see findings.Fingerprint.
+type Finding struct {
+	ID          uint       `gorm:"primaryKey;autoIncrement" json:"id,omitempty"`
+	CreatedAt   time.Time  `json:"-"`
+	UpdatedAt   time.Time  `json:"-"`
+	ExecutionID uint       `gorm:"index" json:"execution_id,omitempty"`
+	Tenant      string     `gorm:"type:varchar(64);index" json:"-"`
+	Scanner     string     `gorm:"type:varchar(255)" json:"scanner"`
+	Severity    string     `gorm:"type:varchar(16);index" json:"severity"`
+	TemplateID  string     `gorm:"type:varchar(255)" json:"template_id,omitempty"`
+	Fingerprint string     `gorm:"type:varchar(64);index" json:"fingerprint,omitempty"`
+	Title       string     `gorm:"type:text" json:"title"`
+	URL         string     `gorm:"type:text" json:"url,omitempty"`
+	Parameter   string     `gorm:"type:varchar(255)" json:"parameter,omitempty"`
+	References  []string   `gorm:"serializer:json" json:"references,omitempty"`
+	CVEs        []string   `gorm:"serializer:json" json:"cves,omitempty"`
+	CWEs        []string   `gorm:"serializer:json" json:"cwes,omitempty"`
+	EPSS        float64    `json:"epss,omitempty"`
+	KEV         bool       `gorm:"index" json:"kev,omitempty"`
+	Evidence    []Evidence `gorm:"serializer:json" json:"evidence,omitempty"`
+	Assignee    string     `gorm:"type:varchar(255);index" json:"assignee,omitempty"`
+	Status      string     `gorm:"type:varchar(16);index;default:open" json:"status,omitempty"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import "testing"
+
+func TestCanTransition(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     bool
+	}{
+		{FindingOpen, FindingInProgress, true},
+		{FindingOpen, FindingAccepted, true},
+		{"", FindingResolved, true},
+		{FindingInProgress, FindingFalsePositive, true},
+		{FindingResolved, FindingOpen, true},
+		{FindingResolved, FindingInProgress, false},
+		{FindingAccepted, FindingResolved, false},
+		{FindingOpen, FindingOpen, false},
+		{FindingOpen, "closed", false},
+		{"closed", FindingOpen, false},
+	}
+	for _, tt := range tests {
+		if got := CanTransition(tt.from, tt.to); got != tt.want {
+			t.Errorf("CanTransition(%q, %q) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// OutputCursor is a continuation cursor into the stored raw output of an execution, issued when a
+// response was cut short and served by the continue_output tool. Clients only see the random
+// token; the position stays on the server. Cursors never change: every page issues a new one.
+type OutputCursor struct {
+	ID          uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	CreatedAt   time.Time `json:"created_at"`
+	Tenant      string    `gorm:"type:varchar(64);index" json:"-"`
+	Token       string    `gorm:"type:varchar(64);uniqueIndex;not null" json:"token"`
+	ExecutionID uint      `gorm:"index;not null" json:"execution_id"`
+	// Line and Byte are the position the next page starts at, see tools.Cursor.
+	Line int `json:"line"`
+	Byte int `json:"byte"`
+	// MaxLines is the page size of the response the cursor continues.
+	MaxLines int `json:"max_lines"`
+}
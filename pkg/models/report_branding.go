@@ -0,0 +1,62 @@
+package models
+
+import "strings"
+
+// ReportBranding is the engagement metadata printed in the header of full_scan reports handed to
+// clients. Server-wide defaults are set with the --report-* flags; scans and scan templates
+// override them field by field.
+type ReportBranding struct {
+	Assessor string `json:"assessor,omitempty" validate:"max=256"`
+	// Banner is a confidentiality notice printed above and below the report, e.g.
+	// "CONFIDENTIAL - prepared for Example Corp".
+	Banner       string `json:"banner,omitempty" validate:"max=256"`
+	EngagementID string `json:"engagement_id,omitempty" validate:"max=128"`
+	Organization string `json:"organization,omitempty" validate:"max=256"`
+}
+
+// Merge returns b with the fields set in override replacing its own.
+func (b ReportBranding) Merge(override *ReportBranding) ReportBranding {
+	if override == nil {
+		return b
+	}
+	if override.Assessor != "" {
+		b.Assessor = override.Assessor
+	}
+	if override.Banner != "" {
+		b.Banner = override.Banner
+	}
+	if override.EngagementID != "" {
+		b.EngagementID = override.EngagementID
+	}
+	if override.Organization != "" {
+		b.Organization = override.Organization
+	}
+
+	return b
+}
+
+// HeaderLines returns the report header lines of the fields set, each on a single line.
+func (b ReportBranding) HeaderLines() []string {
+	var lines []string
+	for _, field := range []struct{ label, value string }{
+		{"Organization", b.Organization},
+		{"Engagement ID", b.EngagementID},
+		{"Assessor", b.Assessor},
+	} {
+		if value := singleLine(field.value); value != "" {
+			lines = append(lines, field.label+": "+value)
+		}
+	}
+
+	return lines
+}
+
+// BannerLine returns the confidentiality banner on a single line, empty when unset.
+func (b ReportBranding) BannerLine() string {
+	return singleLine(b.Banner)
+}
+
+// singleLine joins the lines of value with spaces, so that metadata cannot break report layout.
+func singleLine(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
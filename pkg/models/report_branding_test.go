@@ -0,0 +1,39 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReportBranding_Merge(t *testing.T) {
+	defaults := ReportBranding{Organization: "Example Security", Assessor: "J. Doe", Banner: "CONFIDENTIAL"}
+
+	if got := defaults.Merge(nil); got != defaults {
+		t.Errorf("expected defaults without override, got %+v", got)
+	}
+
+	got := defaults.Merge(&ReportBranding{Assessor: "A. Smith", EngagementID: "ENG-42"})
+	want := ReportBranding{Organization: "Example Security", Assessor: "A. Smith", Banner: "CONFIDENTIAL", EngagementID: "ENG-42"}
+	if got != want {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+}
+
+func TestReportBranding_Lines(t *testing.T) {
+	branding := ReportBranding{
+		Organization: "Example\nSecurity",
+		EngagementID: " ENG-42 ",
+		Banner:       "CONFIDENTIAL\r\n- client use only",
+	}
+
+	want := []string{"Organization: Example Security", "Engagement ID: ENG-42"}
+	if got := branding.HeaderLines(); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected header lines %q, got %q", want, got)
+	}
+	if got := branding.BannerLine(); got != "CONFIDENTIAL - client use only" {
+		t.Errorf("expected a single-line banner, got %q", got)
+	}
+	if lines := (ReportBranding{}).HeaderLines(); len(lines) != 0 {
+		t.Errorf("expected no header lines, got %q", lines)
+	}
+}
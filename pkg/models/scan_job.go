@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+// Scan job statuses.
+const (
+	JobQueued    = "queued"
+	JobRunning   = "running"
+	JobCompleted = "completed"
+	JobFailed    = "failed"
+	JobCanceled  = "canceled"
+	// JobInterrupted marks jobs left running by a previous process.
+	JobInterrupted = "interrupted"
+)
+
+// ScanJob is a scan run in the background with scan_start, so that scans outlasting the tool call
+// timeout of MCP clients can be polled with scan_status and collected with scan_result. The scan
+// itself is logged as a regular execution, found by the correlation ID of the job.
+type ScanJob struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Tenant    string    `gorm:"type:varchar(64);index" json:"-"`
+	// CorrelationID is the correlation ID of the execution running the scan.
+	CorrelationID string `gorm:"type:varchar(32);index" json:"correlation_id"`
+	ToolName      string `gorm:"type:varchar(255);not null" json:"tool_name"`
+	// Priority is the scan priority of the input, see limiter.PriorityHigh. Queued jobs are run by
+	// priority, then oldest first.
+	Priority string `gorm:"type:varchar(16)" json:"priority,omitempty"`
+	// InputJSON is the redacted tool input, shown to clients. It is run as is only when redaction
+	// left it unchanged, see InputRedacted.
+	InputJSON string `gorm:"type:text" json:"input_json"`
+	// InputRedacted tells that redaction changed the input, so that InputJSON cannot be run.
+	InputRedacted bool `json:"input_redacted,omitempty"`
+	// SealedInput is the input as given, sealed with the credential vault, see vault.Vault.SealBytes.
+	// Workers run it for jobs with a redacted input, and it is cleared once the job finished.
+	SealedInput []byte `gorm:"type:blob" json:"-"`
+	Status      string `gorm:"type:varchar(16);index" json:"status"`
+	// Worker is the ID of the worker process that claimed the job, see jobs.Manager.
+	Worker      string     `gorm:"type:varchar(255);index" json:"worker,omitempty"`
+	ExecutionID uint       `gorm:"index" json:"execution_id,omitempty"`
+	Error       string     `gorm:"type:text" json:"error,omitempty"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+}
+
+// Finished reports whether the job reached a final status.
+func (j *ScanJob) Finished() bool {
+	return j.Status != JobQueued && j.Status != JobRunning
+}
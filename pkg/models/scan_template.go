@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// Notification configures the webhook notified when a scan completes.
+type Notification struct {
+	// MinSeverity only notifies scans with a finding of at least this severity, empty for every scan.
+	MinSeverity string `json:"min_severity,omitempty" validate:"omitempty,oneof=critical high medium low info"`
+	// NewFindingsOnly only notifies the findings not reported for the same target by earlier scans,
+	// see findings.Fingerprint, and skips scans without any.
+	NewFindingsOnly bool   `json:"new_findings_only,omitempty"`
+	WebhookURL      string `json:"webhook_url" validate:"required,http_url,max=2048"`
+}
+
+// ScanProfile holds the scan settings of a scan template, the full_scan parameters besides the
+// target.
+type ScanProfile struct {
+	CABundle           string            `json:"ca_bundle,omitempty"`
+	DiscoverPorts      bool              `json:"discover_ports,omitempty"`
+	FollowRedirects    bool              `json:"follow_redirects,omitempty"`
+	InsecureSkipVerify bool              `json:"insecure_skip_verify,omitempty"`
+	Options            map[string]string `json:"options,omitempty"`
+	Path               string            `json:"path,omitempty"`
+	Port               int               `json:"port,omitempty"`
+	Ports              []int             `json:"ports,omitempty"`
+	Priority           string            `json:"priority,omitempty"`
+	Scheme             string            `json:"scheme,omitempty"`
+	Timeout            int               `json:"timeout,omitempty"`
+	Vhost              string            `json:"vhost,omitempty"`
+	Vhosts             []string          `json:"vhosts,omitempty"`
+}
+
+// ScanTemplate is a named full_scan setup of a tenant: a target or target group, the scanners
+// to run, the scan profile and the completion notification. Names are unique per tenant.
+type ScanTemplate struct {
+	ID          uint          `gorm:"primaryKey;autoIncrement" json:"id"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+	Tenant      string        `gorm:"type:varchar(64);uniqueIndex:idx_scan_templates_tenant_name" json:"-"`
+	Name        string        `gorm:"type:varchar(64);uniqueIndex:idx_scan_templates_tenant_name;not null" json:"name"`
+	Description string        `gorm:"type:text" json:"description,omitempty"`
+	Host        string        `gorm:"type:varchar(2048)" json:"host,omitempty"`
+	Group       string        `gorm:"type:varchar(64)" json:"group,omitempty"`
+	Scanners    []string      `gorm:"serializer:json" json:"scanners,omitempty"`
+	Profile     ScanProfile   `gorm:"serializer:json" json:"profile"`
+	Notify      *Notification `gorm:"serializer:json" json:"notify,omitempty"`
+	// Report overrides the engagement metadata of the reports of the template's scans.
+	Report *ReportBranding `gorm:"serializer:json" json:"report,omitempty"`
+}
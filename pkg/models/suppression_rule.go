@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// SuppressionRule drops matching findings when scanner output is parsed, so that accepted noise,
+// e.g. a missing header on a health endpoint, never reaches stored findings, risk scores or
+// summaries. A finding matches when every set criterion does: Scanner and TemplateID exactly
+// (ignoring case), URLPattern and TitlePattern as regular expressions. Rules are stored per tenant
+// with unique names, or loaded from the server configuration for every tenant.
+type SuppressionRule struct {
+	ID           uint      `gorm:"primaryKey;autoIncrement" json:"id,omitempty"`
+	CreatedAt    time.Time `json:"created_at,omitzero"`
+	UpdatedAt    time.Time `json:"updated_at,omitzero"`
+	Tenant       string    `gorm:"type:varchar(64);uniqueIndex:idx_suppression_rules_tenant_name" json:"-"`
+	Name         string    `gorm:"type:varchar(64);uniqueIndex:idx_suppression_rules_tenant_name;not null" json:"name"`
+	Scanner      string    `gorm:"type:varchar(255)" json:"scanner,omitempty"`
+	TemplateID   string    `gorm:"type:varchar(255)" json:"template_id,omitempty"`
+	URLPattern   string    `gorm:"type:text" json:"url_pattern,omitempty"`
+	TitlePattern string    `gorm:"type:text" json:"title_pattern,omitempty"`
+	Reason       string    `gorm:"type:text" json:"reason,omitempty"`
+}
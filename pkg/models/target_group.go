@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// TargetGroup is a named set of scan targets of a tenant, e.g. "staging-cluster", scanned
+// together by full_scan. Names are unique per tenant.
+type TargetGroup struct {
+	ID          uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	Tenant      string    `gorm:"type:varchar(64);uniqueIndex:idx_target_groups_tenant_name" json:"-"`
+	Name        string    `gorm:"type:varchar(64);uniqueIndex:idx_target_groups_tenant_name;not null" json:"name"`
+	Description string    `gorm:"type:text" json:"description,omitempty"`
+	Hosts       []string  `gorm:"serializer:json" json:"hosts"`
+}
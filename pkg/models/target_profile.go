@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// Technology categories of a target profile.
+const (
+	TechnologyServer     = "server"
+	TechnologyCMS        = "cms"
+	TechnologyFramework  = "framework"
+	TechnologyJavaScript = "javascript"
+	TechnologyLanguage   = "language"
+	TechnologyOther      = "other"
+)
+
+// Technology is a technology detected on a target, with its version when the scanner reported
+// one. Name is lower-cased, e.g. "wordpress", the form technology scanners match.
+type Technology struct {
+	Category string `json:"category"`
+	Name     string `json:"name"`
+	Version  string `json:"version,omitempty"`
+}
+
+// TargetProfile is the technology profile of a scan target of a tenant, e.g. its web server, CMS
+// and JavaScript libraries, replaced by each fingerprinting scan of the target. Targets are unique
+// per tenant. ExecutionID references the execution of the latest fingerprinting scan.
+type TargetProfile struct {
+	ID           uint         `gorm:"primaryKey;autoIncrement" json:"id"`
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at"`
+	Tenant       string       `gorm:"type:varchar(64);uniqueIndex:idx_target_profiles_tenant_target" json:"-"`
+	Target       string       `gorm:"type:varchar(2048);uniqueIndex:idx_target_profiles_tenant_target;not null" json:"target"`
+	Host         string       `gorm:"type:varchar(255);index" json:"host"`
+	Port         int          `json:"port"`
+	ExecutionID  uint         `json:"execution_id,omitempty"`
+	Scanner      string       `gorm:"type:varchar(255)" json:"scanner"`
+	Technologies []Technology `gorm:"serializer:json" json:"technologies"`
+}
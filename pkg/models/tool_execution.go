@@ -6,15 +6,166 @@ import (
 	"gorm.io/gorm"
 )
 
+// Execution statuses.
+const (
+	StatusRunning     = "running"
+	StatusCompleted   = "completed"
+	StatusFailed      = "failed"
+	StatusInterrupted = "interrupted"
+	StatusCanceled    = "canceled"
+	// StatusPaused marks a multi-scanner execution paused with scanner runs held for a resume.
+	StatusPaused = "paused"
+	// StatusResumed marks a paused execution that was resumed by a later execution.
+	StatusResumed = "resumed"
+)
+
+// Scanner run states of a paused execution and of its timeline.
+const (
+	ScannerCompleted = "completed"
+	ScannerFailed    = "failed"
+	ScannerHeld      = "held"
+	ScannerTimedOut  = "timed_out"
+)
+
+// ScannerState is the outcome of one scanner run of a multi-scanner execution, keeping the
+// output of finished runs so that a resume only runs the held ones. Host is the scanned host as
+// requested, the group entry for target group scans.
+type ScannerState struct {
+	DurationMs int64  `json:"duration_ms,omitempty"`
+	Error      string `json:"error,omitempty"`
+	Host       string `json:"host,omitempty"`
+	Output     string `json:"output,omitempty"`
+	Port       int    `json:"port"`
+	Scanner    string `json:"scanner"`
+	Status     string `json:"status"`
+	Vhost      string `json:"vhost,omitempty"`
+}
+
+// ScannerRun is a scanner run of an execution on its timeline: when it was queued for a scan slot,
+// started once it had one and finished, with its outcome, one of the Scanner run states. Runs that
+// never got a slot, such as held ones, start when they finish.
+type ScannerRun struct {
+	FinishedAt time.Time `json:"finished_at"`
+	Host       string    `json:"host,omitempty"`
+	Port       int       `json:"port,omitempty"`
+	QueuedAt   time.Time `json:"queued_at"`
+	Scanner    string    `json:"scanner"`
+	StartedAt  time.Time `json:"started_at"`
+	Status     string    `json:"status"`
+	Vhost      string    `json:"vhost,omitempty"`
+}
+
+// BaseSchemaVersion is the first schema version of tool inputs, the version of inputs stored
+// before executions recorded one.
+const BaseSchemaVersion = 1
+
+// Execution phases timed in PhaseTimings.
+const (
+	PhaseQueueWait    = "queue_wait"
+	PhaseAvailability = "availability"
+	PhaseExec         = "exec"
+	PhaseParse        = "parse"
+	PhasePersist      = "persist"
+)
+
+// Phases lists the execution phases in the order they occur.
+var Phases = []string{PhaseQueueWait, PhaseAvailability, PhaseExec, PhaseParse, PhasePersist}
+
+// PhaseTimings breaks the duration of an execution down by phase: waiting for a scan slot,
+// looking up scanner binaries, running the scanners, parsing findings from their output and
+// storing the execution. Phases of multi-scanner executions are summed across scanner runs, so
+// that concurrent runs can add up to more than the execution took.
+type PhaseTimings struct {
+	QueueWaitMs    int64 `json:"queue_wait_ms"`
+	AvailabilityMs int64 `json:"availability_ms"`
+	ExecMs         int64 `json:"exec_ms"`
+	ParseMs        int64 `json:"parse_ms"`
+	PersistMs      int64 `json:"persist_ms"`
+}
+
+// field returns the timing of phase, nil for unknown phases.
+func (p *PhaseTimings) field(phase string) *int64 {
+	switch phase {
+	case PhaseQueueWait:
+		return &p.QueueWaitMs
+	case PhaseAvailability:
+		return &p.AvailabilityMs
+	case PhaseExec:
+		return &p.ExecMs
+	case PhaseParse:
+		return &p.ParseMs
+	case PhasePersist:
+		return &p.PersistMs
+	}
+	return nil
+}
+
+// Set sets the timing of phase to d. Unknown phases are ignored.
+func (p *PhaseTimings) Set(phase string, d time.Duration) {
+	if field := p.field(phase); field != nil {
+		*field = d.Milliseconds()
+	}
+}
+
+// Get returns the timing of phase, 0 for unknown phases.
+func (p PhaseTimings) Get(phase string) time.Duration {
+	if field := (&p).field(phase); field != nil {
+		return time.Duration(*field) * time.Millisecond
+	}
+	return 0
+}
+
+// ToolExecution is a stored tool call. The single column indexes of the history filters serve
+// pages ordered by id, the row ID closing every SQLite index; the composite indexes of tool_name,
+// session_id and success with created_at serve those filters combined with a time range.
 type ToolExecution struct {
-	ID           uint           `gorm:"primaryKey;autoIncrement" json:"id"`
-	CreatedAt    time.Time      `json:"created_at"`
-	DeletedAt    gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
-	SessionID    string         `gorm:"type:varchar(64);index" json:"session_id,omitempty"`
-	ToolName     string         `gorm:"type:varchar(255);index;not null" json:"tool_name"`
-	InputJSON    string         `gorm:"type:text" json:"input_json"`
-	OutputJSON   string         `gorm:"type:text" json:"output_json,omitempty"`
+	ID            uint           `gorm:"primaryKey;autoIncrement" json:"id"`
+	CreatedAt     time.Time      `gorm:"index:idx_tool_executions_tool_created,priority:2;index:idx_tool_executions_session_created,priority:2;index:idx_tool_executions_success_created,priority:2" json:"created_at"`
+	DeletedAt     gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+	CorrelationID string         `gorm:"type:varchar(32);index" json:"correlation_id,omitempty"`
+	SessionID     string         `gorm:"type:varchar(64);index;index:idx_tool_executions_session_created,priority:1" json:"session_id,omitempty"`
+	Tenant        string         `gorm:"type:varchar(64);index" json:"tenant,omitempty"`
+	ClientName    string         `gorm:"type:varchar(255);index" json:"client_name,omitempty"`
+	ClientVersion string         `gorm:"type:varchar(64)" json:"client_version,omitempty"`
+	RemoteAddr    string         `gorm:"type:varchar(255)" json:"remote_addr,omitempty"`
+	ToolName      string         `gorm:"type:varchar(255);index;index:idx_tool_executions_tool_created,priority:1;not null" json:"tool_name"`
+	Target        string         `gorm:"type:varchar(2048);index" json:"target,omitempty"`
+	Host          string         `gorm:"type:varchar(255);index" json:"host,omitempty"`
+	Port          int            `json:"port,omitempty"`
+	Scheme        string         `gorm:"type:varchar(16)" json:"scheme,omitempty"`
+	InputJSON     string         `gorm:"type:text" json:"input_json"`
+	// SchemaVersion is the version of the input schema of the tool InputJSON was stored with, 0
+	// for executions stored before inputs were versioned, see InputSchemaVersion.
+	SchemaVersion int    `json:"schema_version,omitempty"`
+	OutputJSON    string `gorm:"type:text" json:"output_json,omitempty"`
+	OutputSize    int    `json:"output_size,omitempty"`
+	ResultBytes   int    `json:"result_bytes,omitempty"`
+	OutputFile    string `gorm:"type:varchar(1024)" json:"output_file,omitempty"`
+	// StreamFile is the artifact the scanner output is streamed to while the execution runs, see
+	// artifacts.Stream. It is cleared once the final output is stored, and kept by interrupted
+	// executions with the output they streamed before the server stopped.
+	StreamFile   string         `gorm:"type:varchar(1024)" json:"stream_file,omitempty"`
+	CaptureFiles []string       `gorm:"serializer:json" json:"capture_files,omitempty"`
+	RawOutput    string         `gorm:"type:text" json:"-"`
 	ErrorMessage string         `gorm:"type:text" json:"error_message,omitempty"`
 	DurationMs   int64          `json:"duration_ms"`
-	Success      bool           `gorm:"index" json:"success"`
+	Phases       PhaseTimings   `gorm:"serializer:json" json:"phases"`
+	RiskScore    float64        `json:"risk_score"`
+	Suppressed   int            `json:"suppressed,omitempty"`
+	Success      bool           `gorm:"index;index:idx_tool_executions_success_created,priority:1" json:"success"`
+	Status       string         `gorm:"type:varchar(16);index" json:"status,omitempty"`
+	Retryable    bool           `json:"retryable,omitempty"`
+	ScanState    []ScannerState `gorm:"serializer:json" json:"scan_state,omitempty"`
+	// Timeline lists the scanner runs of the execution in the order they were queued.
+	Timeline []ScannerRun `gorm:"serializer:json" json:"timeline,omitempty"`
+}
+
+// InputSchemaVersion returns the input schema version the execution was stored with,
+// BaseSchemaVersion for executions stored before inputs were versioned.
+func (e ToolExecution) InputSchemaVersion() int {
+	if e.SchemaVersion == 0 {
+		return BaseSchemaVersion
+	}
+
+	return e.SchemaVersion
 }
@@ -157,3 +157,42 @@ func containsHelper(s, substr string) bool {
 	}
 	return false
 }
+
+func TestPhaseTimings(t *testing.T) {
+	var phases PhaseTimings
+	phases.Set(PhaseExec, 1500*time.Millisecond)
+	phases.Set(PhaseParse, 20*time.Millisecond)
+	phases.Set("unknown", time.Second)
+
+	if got := phases.Get(PhaseExec); got != 1500*time.Millisecond {
+		t.Errorf("exec mismatch: expected 1.5s, got %s", got)
+	}
+	if got := phases.Get("unknown"); got != 0 {
+		t.Errorf("unknown phases should be 0, got %s", got)
+	}
+
+	data, err := json.Marshal(ToolExecution{Phases: phases})
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	encoded, ok := decoded["phases"].(map[string]any)
+	if !ok {
+		t.Fatalf("phases missing from %s", data)
+	}
+	if encoded["exec_ms"] != float64(1500) || encoded["parse_ms"] != float64(20) {
+		t.Errorf("unexpected phases: %v", encoded)
+	}
+}
+
+func TestToolExecution_InputSchemaVersion(t *testing.T) {
+	if version := (ToolExecution{}).InputSchemaVersion(); version != BaseSchemaVersion {
+		t.Errorf("expected executions without a version at the base version, got %d", version)
+	}
+	if version := (ToolExecution{SchemaVersion: 3}).InputSchemaVersion(); version != 3 {
+		t.Errorf("expected schema version 3, got %d", version)
+	}
+}
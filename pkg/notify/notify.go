@@ -0,0 +1,133 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/tb0hdan/wass-mcp/pkg/findings"
+	"github.com/tb0hdan/wass-mcp/pkg/httpclient"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/types"
+)
+
+// userAgent identifies webhook requests.
+const userAgent = "wass-mcp"
+
+// Event types.
+const (
+	// EventScanCompleted reports the findings of a completed scan.
+	EventScanCompleted = "scan_completed"
+	// EventNewFindings reports the findings of a completed scan not reported by earlier scans of
+	// the same target.
+	EventNewFindings = "new_findings"
+)
+
+// Event is the JSON payload posted to a webhook when a scan completes.
+type Event struct {
+	CompletedAt   time.Time `json:"completed_at"`
+	CorrelationID string    `json:"correlation_id,omitempty"`
+	ExecutionID   uint      `json:"execution_id,omitempty"`
+	// NewFindings lists the new findings of EventNewFindings events, without their evidence.
+	NewFindings    []models.Finding `json:"new_findings,omitempty"`
+	RiskScore      float64          `json:"risk_score"`
+	SeverityCounts map[string]int   `json:"severity_counts"`
+	// Status is completed, or paused when scanner runs were held.
+	Status        string `json:"status"`
+	Target        string `json:"target"`
+	Template      string `json:"template,omitempty"`
+	Tool          string `json:"tool"`
+	TotalFindings int    `json:"total_findings"`
+	Type          string `json:"type"`
+}
+
+// NewEvent returns the event of a scan of target with the given findings, completed now.
+func NewEvent(tool, target string, found []models.Finding) Event {
+	counts := findings.CountBySeverity(found)
+
+	return Event{
+		CompletedAt:    time.Now().UTC(),
+		RiskScore:      findings.ScoreFindings(found),
+		SeverityCounts: counts,
+		Status:         models.StatusCompleted,
+		Target:         target,
+		Tool:           tool,
+		TotalFindings:  len(found),
+		Type:           EventScanCompleted,
+	}
+}
+
+// NewFindingsEvent returns the event of a scan of target whose findings not reported before are
+// fresh, completed now. The counts, risk score and total are those of the new findings.
+func NewFindingsEvent(tool, target string, fresh []models.Finding) Event {
+	event := NewEvent(tool, target, fresh)
+	event.Type = EventNewFindings
+	event.NewFindings = make([]models.Finding, len(fresh))
+	for i, finding := range fresh {
+		finding.Evidence = nil
+		event.NewFindings[i] = finding
+	}
+
+	return event
+}
+
+// ShouldNotify reports whether event is notified with settings: always without a minimum
+// severity, otherwise when the scan has a finding of at least that severity. New findings events
+// are only notified with new findings.
+func ShouldNotify(settings models.Notification, event Event) bool {
+	if event.Type == EventNewFindings && event.TotalFindings == 0 {
+		return false
+	}
+	if settings.MinSeverity == "" {
+		return true
+	}
+
+	minimum := findings.SeverityRank(settings.MinSeverity)
+	for severity, count := range event.SeverityCounts {
+		if count > 0 && findings.SeverityRank(severity) >= minimum {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Notifier posts scan events to webhooks.
+type Notifier struct {
+	client *http.Client
+}
+
+// New creates a Notifier whose requests time out after types.NotifyTimeout. Webhooks are held to
+// the SSRF policy of httpclient, so that they cannot reach cloud metadata services.
+func New() *Notifier {
+	return &Notifier{client: httpclient.New(httpclient.Config{FollowRedirects: true, Timeout: types.NotifyTimeout})}
+}
+
+// Send posts event as JSON to the webhook of settings. Responses other than 2xx are errors.
+func (n *Notifier) Send(ctx context.Context, settings models.Notification, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, settings.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create notification request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send notification: %w", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("notification webhook returned %s", resp.Status)
+	}
+
+	return nil
+}
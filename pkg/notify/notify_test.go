@@ -0,0 +1,98 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/types"
+)
+
+type NotifyTestSuite struct {
+	suite.Suite
+}
+
+func (s *NotifyTestSuite) TestNewEvent() {
+	event := NewEvent("full_scan", "http://example.com", []models.Finding{
+		{Severity: types.SeverityHigh},
+		{Severity: types.SeverityLow},
+	})
+
+	s.Equal(models.StatusCompleted, event.Status)
+	s.Equal(2, event.TotalFindings)
+	s.Equal(1, event.SeverityCounts[types.SeverityHigh])
+	s.Equal(0, event.SeverityCounts[types.SeverityCritical])
+	s.InDelta(5.5, event.RiskScore, 0.001)
+}
+
+func (s *NotifyTestSuite) TestNewFindingsEvent() {
+	event := NewFindingsEvent("full_scan", "http://example.com", []models.Finding{
+		{Severity: types.SeverityHigh, Title: "Exposed Git", Fingerprint: "a",
+			Evidence: []models.Evidence{{Kind: models.EvidenceResponse, Content: "[core]"}}},
+	})
+
+	s.Equal(EventNewFindings, event.Type)
+	s.Equal(1, event.TotalFindings)
+	s.Equal(1, event.SeverityCounts[types.SeverityHigh])
+	s.Require().Len(event.NewFindings, 1)
+	s.Equal("a", event.NewFindings[0].Fingerprint)
+	s.Empty(event.NewFindings[0].Evidence)
+	s.Equal(EventScanCompleted, NewEvent("full_scan", "http://example.com", nil).Type)
+}
+
+func (s *NotifyTestSuite) TestShouldNotify() {
+	event := NewEvent("full_scan", "http://example.com", []models.Finding{{Severity: types.SeverityMedium}})
+
+	s.True(ShouldNotify(models.Notification{}, event))
+	s.True(ShouldNotify(models.Notification{MinSeverity: types.SeverityLow}, event))
+	s.True(ShouldNotify(models.Notification{MinSeverity: types.SeverityMedium}, event))
+	s.False(ShouldNotify(models.Notification{MinSeverity: types.SeverityHigh}, event))
+	s.False(ShouldNotify(models.Notification{MinSeverity: types.SeverityInfo}, NewEvent("full_scan", "", nil)))
+	s.True(ShouldNotify(models.Notification{}, NewEvent("full_scan", "", nil)))
+	s.False(ShouldNotify(models.Notification{NewFindingsOnly: true}, NewFindingsEvent("full_scan", "", nil)))
+}
+
+func (s *NotifyTestSuite) TestSend() {
+	received := make(chan Event, 1)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.Equal(http.MethodPost, r.Method)
+		s.Equal("application/json", r.Header.Get("Content-Type"))
+		s.Equal(userAgent, r.Header.Get("User-Agent"))
+		var event Event
+		s.NoError(json.NewDecoder(r.Body).Decode(&event))
+		received <- event
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer webhook.Close()
+
+	event := NewEvent("full_scan", "http://example.com", nil)
+	event.ExecutionID = 7
+	event.Template = "weekly"
+	s.Require().NoError(New().Send(context.Background(), models.Notification{WebhookURL: webhook.URL}, event))
+
+	sent := <-received
+	s.Equal(uint(7), sent.ExecutionID)
+	s.Equal("weekly", sent.Template)
+	s.Equal("http://example.com", sent.Target)
+}
+
+func (s *NotifyTestSuite) TestSend_Failure() {
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer webhook.Close()
+
+	err := New().Send(context.Background(), models.Notification{WebhookURL: webhook.URL}, Event{})
+	s.ErrorContains(err, "notification webhook returned 502 Bad Gateway")
+
+	err = New().Send(context.Background(), models.Notification{WebhookURL: "http://127.0.0.1:1/hook"}, Event{})
+	s.ErrorContains(err, "failed to send notification")
+}
+
+func TestNotifyTestSuite(t *testing.T) {
+	suite.Run(t, new(NotifyTestSuite))
+}
@@ -0,0 +1,168 @@
+package redact
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Placeholder replaces redacted values.
+const Placeholder = "[REDACTED]"
+
+// DefaultFields are the JSON field names whose values are always redacted.
+// Field names are compared case-insensitively, ignoring "-" and "_".
+var DefaultFields = []string{
+	"api_key", "api_token", "auth", "auth_token", "authorization", "cookie", "cookies", "credentials",
+	"passwd", "password", "secret", "session_token", "token", "access_token", "refresh_token",
+}
+
+// DefaultHeaders are the HTTP header names whose values are redacted wherever they appear in text.
+var DefaultHeaders = []string{
+	"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key", "X-Auth-Token",
+}
+
+// bearerRe matches bearer/basic credentials outside of a recognized header.
+var bearerRe = regexp.MustCompile(`(?i)\b(bearer|basic)\s+[A-Za-z0-9\-._~+/]+=*`)
+
+// Config configures redaction. Entries extend the defaults, they do not replace them.
+type Config struct {
+	// Fields are additional JSON field names whose values are redacted.
+	Fields []string
+	// Headers are additional HTTP header names whose values are redacted in text.
+	Headers []string
+	// Patterns are additional regular expressions whose matches are redacted in text.
+	Patterns []string
+}
+
+// Redactor scrubs sensitive values from JSON documents and free text.
+type Redactor struct {
+	fields   map[string]struct{}
+	headerRe *regexp.Regexp
+	patterns []*regexp.Regexp
+}
+
+// New builds a Redactor from the defaults extended by cfg.
+func New(cfg Config) (*Redactor, error) {
+	redactor := &Redactor{fields: make(map[string]struct{})}
+
+	for _, field := range append(append([]string{}, DefaultFields...), cfg.Fields...) {
+		if field = normalizeField(field); field != "" {
+			redactor.fields[field] = struct{}{}
+		}
+	}
+
+	headers := make([]string, 0, len(DefaultHeaders)+len(cfg.Headers))
+	for _, header := range append(append([]string{}, DefaultHeaders...), cfg.Headers...) {
+		if header = strings.TrimSpace(header); header != "" {
+			headers = append(headers, regexp.QuoteMeta(header))
+		}
+	}
+	redactor.headerRe = regexp.MustCompile(`(?i)\b(` + strings.Join(headers, "|") + `)(\s*[:=]\s*)[^\r\n"']+`)
+
+	for _, pattern := range cfg.Patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %q: %w", pattern, err)
+		}
+		redactor.patterns = append(redactor.patterns, re)
+	}
+
+	return redactor, nil
+}
+
+// Default returns a Redactor using only the default fields and headers.
+func Default() *Redactor {
+	redactor, _ := New(Config{})
+	return redactor
+}
+
+// Text redacts sensitive header values, bearer/basic credentials and configured patterns from text.
+func (r *Redactor) Text(text string) string {
+	if text == "" {
+		return text
+	}
+
+	text = r.headerRe.ReplaceAllString(text, "${1}${2}"+Placeholder)
+	text = bearerRe.ReplaceAllString(text, "${1} "+Placeholder)
+	for _, re := range r.patterns {
+		text = re.ReplaceAllString(text, Placeholder)
+	}
+
+	return text
+}
+
+// JSON redacts the values of sensitive fields and applies Text to every string value of a JSON
+// document. The document is returned unchanged when nothing was redacted, and redacted as text
+// when it is not valid JSON.
+func (r *Redactor) JSON(data string) string {
+	if data == "" {
+		return data
+	}
+
+	var value any
+	if err := json.Unmarshal([]byte(data), &value); err != nil {
+		return r.Text(data)
+	}
+
+	redacted, changed := r.value(value)
+	if !changed {
+		return data
+	}
+
+	out, err := json.Marshal(redacted)
+	if err != nil {
+		return r.Text(data)
+	}
+
+	return string(out)
+}
+
+// value redacts a decoded JSON value and reports whether anything changed.
+func (r *Redactor) value(value any) (any, bool) {
+	switch typed := value.(type) {
+	case map[string]any:
+		changed := false
+		for key, item := range typed {
+			if _, sensitive := r.fields[normalizeField(key)]; sensitive && !isEmpty(item) {
+				typed[key] = Placeholder
+				changed = true
+				continue
+			}
+			redacted, itemChanged := r.value(item)
+			typed[key] = redacted
+			changed = changed || itemChanged
+		}
+		return typed, changed
+	case []any:
+		changed := false
+		for i, item := range typed {
+			redacted, itemChanged := r.value(item)
+			typed[i] = redacted
+			changed = changed || itemChanged
+		}
+		return typed, changed
+	case string:
+		redacted := r.Text(typed)
+		return redacted, redacted != typed
+	default:
+		return value, false
+	}
+}
+
+// normalizeField lowercases a field name and strips "-" and "_" separators.
+func normalizeField(field string) string {
+	return strings.NewReplacer("-", "", "_", "").Replace(strings.ToLower(strings.TrimSpace(field)))
+}
+
+// isEmpty reports whether a JSON value carries no data worth redacting.
+func isEmpty(value any) bool {
+	switch typed := value.(type) {
+	case nil:
+		return true
+	case string:
+		return typed == ""
+	default:
+		return false
+	}
+}
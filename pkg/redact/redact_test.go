@@ -0,0 +1,78 @@
+package redact
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type RedactTestSuite struct {
+	suite.Suite
+	redactor *Redactor
+}
+
+func (s *RedactTestSuite) SetupTest() {
+	s.redactor = Default()
+}
+
+func (s *RedactTestSuite) TestJSON_DefaultFields() {
+	redacted := s.redactor.JSON(`{"host":"example.com","api_key":"abc123","Auth-Token":"x","password":""}`)
+	s.JSONEq(`{"host":"example.com","api_key":"[REDACTED]","Auth-Token":"[REDACTED]","password":""}`, redacted)
+}
+
+func (s *RedactTestSuite) TestJSON_NestedAndArrays() {
+	redacted := s.redactor.JSON(`{"headers":[{"name":"a","token":"t"}],"cookies":["a=b"]}`)
+	s.JSONEq(`{"headers":[{"name":"a","token":"[REDACTED]"}],"cookies":"[REDACTED]"}`, redacted)
+}
+
+func (s *RedactTestSuite) TestJSON_ScanOptions() {
+	redacted := s.redactor.JSON(`{"host":"blog.example.com","options":{"api_token":"abc123","enumerate":"vp"}}`)
+	s.JSONEq(`{"host":"blog.example.com","options":{"api_token":"[REDACTED]","enumerate":"vp"}}`, redacted)
+}
+
+func (s *RedactTestSuite) TestJSON_HeaderValuesInStrings() {
+	redacted := s.redactor.JSON(`{"content":[{"type":"text","text":"GET / HTTP/1.1\nCookie: session=secret\n"}]}`)
+	s.Contains(redacted, `Cookie: [REDACTED]`)
+	s.NotContains(redacted, "session=secret")
+}
+
+func (s *RedactTestSuite) TestJSON_UnchangedKeepsOriginal() {
+	original := `{"port":80,"host":"example.com"}`
+	s.Equal(original, s.redactor.JSON(original))
+	s.Equal("", s.redactor.JSON(""))
+}
+
+func (s *RedactTestSuite) TestJSON_InvalidFallsBackToText() {
+	s.Equal("Authorization: [REDACTED]", s.redactor.JSON("Authorization: Basic dXNlcjpwYXNz"))
+}
+
+func (s *RedactTestSuite) TestText() {
+	s.Equal("Authorization: [REDACTED]\nok", s.redactor.Text("Authorization: Bearer abc.def\nok"))
+	s.Equal("x-api-key=[REDACTED]", s.redactor.Text("x-api-key=12345"))
+	s.Equal("token Bearer [REDACTED] used", s.redactor.Text("token Bearer eyJhbGciOi.J9 used"))
+	s.Equal("nothing to see", s.redactor.Text("nothing to see"))
+}
+
+func (s *RedactTestSuite) TestConfig() {
+	redactor, err := New(Config{
+		Fields:   []string{"client_id"},
+		Headers:  []string{"X-Custom-Auth"},
+		Patterns: []string{`sk_live_[A-Za-z0-9]+`},
+	})
+	s.Require().NoError(err)
+
+	s.JSONEq(`{"clientId":"[REDACTED]"}`, redactor.JSON(`{"clientId":"abc"}`))
+	s.Equal("X-Custom-Auth: [REDACTED]", redactor.Text("X-Custom-Auth: abc"))
+	s.Equal("key [REDACTED] leaked", redactor.Text("key sk_live_abc123 leaked"))
+	// Defaults still apply.
+	s.JSONEq(`{"password":"[REDACTED]"}`, redactor.JSON(`{"password":"p"}`))
+}
+
+func (s *RedactTestSuite) TestConfig_InvalidPattern() {
+	_, err := New(Config{Patterns: []string{"("}})
+	s.Error(err)
+}
+
+func TestRedactTestSuite(t *testing.T) {
+	suite.Run(t, new(RedactTestSuite))
+}
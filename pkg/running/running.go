@@ -0,0 +1,158 @@
+// Package running tracks in-flight tool executions so that they can be listed and cancelled
+// at runtime.
+package running
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// ErrCanceled is the cancellation cause of jobs cancelled through the registry.
+	ErrCanceled = errors.New("canceled by an administrator")
+	// ErrClientCanceled is the cancellation cause of background scan jobs cancelled by their client.
+	ErrClientCanceled = errors.New("canceled by the client")
+	// ErrNotFound is returned when cancelling a job that is not running.
+	ErrNotFound = errors.New("job not found")
+)
+
+// Job describes an in-flight tool execution.
+type Job struct {
+	CorrelationID string `json:"correlation_id,omitempty"`
+	// ExecutionID is the stored execution record, zero when it could not be created up front.
+	ExecutionID uint   `json:"execution_id,omitempty"`
+	ID          uint64 `json:"id"`
+	// Paused is set once the job was paused: runs in progress complete, the rest are held.
+	Paused    bool      `json:"paused,omitempty"`
+	SessionID string    `json:"session_id,omitempty"`
+	StartedAt time.Time `json:"started_at"`
+	Target    string    `json:"target,omitempty"`
+	Tenant    string    `json:"tenant,omitempty"`
+	ToolName  string    `json:"tool_name"`
+}
+
+// entry is a registered job together with the cancel function and pause flag of its context.
+type entry struct {
+	cancel context.CancelCauseFunc
+	job    Job
+	paused *atomic.Bool
+}
+
+// pausedKey is the context key for the pause flag of a job.
+type pausedKey struct{}
+
+// Registry holds the running jobs. A nil registry tracks nothing.
+type Registry struct {
+	mu     sync.Mutex
+	jobs   map[uint64]entry
+	nextID uint64
+}
+
+// New creates an empty registry.
+func New() *Registry {
+	return &Registry{jobs: make(map[uint64]entry)}
+}
+
+// Start registers job and returns a context derived from ctx that is cancelled with ErrCanceled
+// when the job is cancelled, together with a function that unregisters the job once it finished.
+func (r *Registry) Start(ctx context.Context, job Job) (context.Context, func()) {
+	if r == nil {
+		return ctx, func() {}
+	}
+
+	jobCtx, cancel := context.WithCancelCause(ctx)
+	paused := &atomic.Bool{}
+	jobCtx = context.WithValue(jobCtx, pausedKey{}, paused)
+
+	r.mu.Lock()
+	r.nextID++
+	job.ID = r.nextID
+	if job.StartedAt.IsZero() {
+		job.StartedAt = time.Now()
+	}
+	r.jobs[job.ID] = entry{cancel: cancel, job: job, paused: paused}
+	r.mu.Unlock()
+
+	return jobCtx, func() {
+		r.mu.Lock()
+		delete(r.jobs, job.ID)
+		r.mu.Unlock()
+		cancel(nil)
+	}
+}
+
+// List returns the running jobs ordered by start.
+func (r *Registry) List() []Job {
+	if r == nil {
+		return []Job{}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	jobs := make([]Job, 0, len(r.jobs))
+	for _, registered := range r.jobs {
+		jobs = append(jobs, registered.job)
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].ID < jobs[j].ID })
+
+	return jobs
+}
+
+// Cancel cancels the running job with the given ID. The job stays listed until its tool returns.
+func (r *Registry) Cancel(id uint64) error {
+	if r == nil {
+		return ErrNotFound
+	}
+
+	r.mu.Lock()
+	registered, ok := r.jobs[id]
+	r.mu.Unlock()
+	if !ok {
+		return ErrNotFound
+	}
+
+	registered.cancel(ErrCanceled)
+
+	return nil
+}
+
+// Pause pauses the running job with the given ID. Unlike Cancel it leaves the job context alive:
+// tools running several scanners let the runs in progress complete and hold the others, see Paused.
+// The job stays listed, marked paused, until its tool returns.
+func (r *Registry) Pause(id uint64) error {
+	if r == nil {
+		return ErrNotFound
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	registered, ok := r.jobs[id]
+	if !ok {
+		return ErrNotFound
+	}
+	registered.paused.Store(true)
+	registered.job.Paused = true
+	r.jobs[id] = registered
+
+	return nil
+}
+
+// Paused reports whether the job of ctx was paused through the registry.
+func Paused(ctx context.Context) bool {
+	paused, ok := ctx.Value(pausedKey{}).(*atomic.Bool)
+
+	return ok && paused.Load()
+}
+
+// Canceled reports whether ctx was cancelled through the registry or, for background scan jobs,
+// by their client.
+func Canceled(ctx context.Context) bool {
+	cause := context.Cause(ctx)
+	return errors.Is(cause, ErrCanceled) || errors.Is(cause, ErrClientCanceled)
+}
@@ -0,0 +1,94 @@
+package running
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type RunningTestSuite struct {
+	suite.Suite
+}
+
+func (s *RunningTestSuite) TestStartList() {
+	registry := New()
+	_, doneFirst := registry.Start(context.Background(), Job{ToolName: "nikto", Target: "http://example.com"})
+	_, doneSecond := registry.Start(context.Background(), Job{ExecutionID: 7, ToolName: "full_scan"})
+
+	jobs := registry.List()
+	s.Require().Len(jobs, 2)
+	s.Equal(uint64(1), jobs[0].ID)
+	s.Equal("nikto", jobs[0].ToolName)
+	s.False(jobs[0].StartedAt.IsZero())
+	s.Equal(uint64(2), jobs[1].ID)
+	s.Equal(uint(7), jobs[1].ExecutionID)
+
+	doneFirst()
+	jobs = registry.List()
+	s.Require().Len(jobs, 1)
+	s.Equal("full_scan", jobs[0].ToolName)
+
+	doneSecond()
+	s.Empty(registry.List())
+}
+
+func (s *RunningTestSuite) TestCancel() {
+	registry := New()
+	ctx, done := registry.Start(context.Background(), Job{ToolName: "nikto"})
+	defer done()
+
+	s.NoError(registry.Cancel(1))
+	<-ctx.Done()
+	s.True(Canceled(ctx))
+	s.ErrorIs(context.Cause(ctx), ErrCanceled)
+
+	s.ErrorIs(registry.Cancel(42), ErrNotFound)
+}
+
+func (s *RunningTestSuite) TestPause() {
+	registry := New()
+	ctx, done := registry.Start(context.Background(), Job{ToolName: "full_scan"})
+	defer done()
+	s.False(Paused(ctx))
+
+	s.NoError(registry.Pause(1))
+	s.True(Paused(ctx))
+	s.NoError(ctx.Err())
+	s.True(registry.List()[0].Paused)
+
+	s.ErrorIs(registry.Pause(42), ErrNotFound)
+	s.False(Paused(context.Background()))
+}
+
+func (s *RunningTestSuite) TestCanceled_ByClient() {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cancel(ErrClientCanceled)
+
+	s.True(Canceled(ctx), "background scan jobs cancelled by their client count as cancelled")
+}
+
+func (s *RunningTestSuite) TestDone_IsNotACancellation() {
+	registry := New()
+	ctx, done := registry.Start(context.Background(), Job{ToolName: "nikto"})
+	done()
+
+	<-ctx.Done()
+	s.False(Canceled(ctx))
+	s.ErrorIs(registry.Cancel(1), ErrNotFound)
+}
+
+func (s *RunningTestSuite) TestNilRegistry() {
+	var registry *Registry
+	ctx, done := registry.Start(context.Background(), Job{ToolName: "nikto"})
+	done()
+
+	s.NoError(ctx.Err())
+	s.Empty(registry.List())
+	s.ErrorIs(registry.Cancel(1), ErrNotFound)
+	s.ErrorIs(registry.Pause(1), ErrNotFound)
+}
+
+func TestRunningTestSuite(t *testing.T) {
+	suite.Run(t, new(RunningTestSuite))
+}
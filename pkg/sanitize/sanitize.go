@@ -0,0 +1,65 @@
+// Package sanitize normalizes scanner output to clean UTF-8 text before it is stored or returned
+// to clients. Scanners write for terminals: color codes, progress bars redrawn with carriage
+// returns and stray bytes of other encodings break the rendering of some clients and the findings
+// parsers.
+package sanitize
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ansiRe matches terminal escape sequences: CSI sequences such as colors and cursor movements,
+// OSC sequences such as window titles and hyperlinks, and two-byte escapes such as resets. An
+// unterminated OSC sequence ends at the end of its line, so that it cannot swallow the rest of
+// the output.
+var ansiRe = regexp.MustCompile(`\x1b(?:\[[0-?]*[ -/]*[@-~]|\][^\x07\x1b\n]*(?:\x07|\x1b\\)?|[0-~])`)
+
+// StripANSI removes terminal escape sequences from text.
+func StripANSI(text string) string {
+	if !strings.Contains(text, "\x1b") {
+		return text
+	}
+
+	return ansiRe.ReplaceAllString(text, "")
+}
+
+// Output normalizes scanner output: terminal escape sequences and invalid UTF-8 sequences are
+// removed, lines redrawn with carriage returns keep what a terminal would show (the text after
+// the last carriage return), and control characters other than newlines and tabs are dropped.
+func Output(output string) string {
+	if clean(output) {
+		return output
+	}
+
+	output = strings.ToValidUTF8(StripANSI(output), "")
+	lines := strings.Split(output, "\n")
+	for i, line := range lines {
+		line = strings.TrimRight(line, "\r")
+		if cut := strings.LastIndexByte(line, '\r'); cut >= 0 {
+			line = line[cut+1:]
+		}
+		lines[i] = strings.Map(func(r rune) rune {
+			if r != '\t' && unicode.IsControl(r) {
+				return -1
+			}
+			return r
+		}, line)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// clean reports whether output is valid UTF-8 free of control characters other than newlines
+// and tabs, so that Output can return it as is.
+func clean(output string) bool {
+	if !utf8.ValidString(output) {
+		return false
+	}
+
+	return strings.IndexFunc(output, func(r rune) bool {
+		return r != '\n' && r != '\t' && unicode.IsControl(r)
+	}) < 0
+}
@@ -0,0 +1,61 @@
+package sanitize
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type SanitizeTestSuite struct {
+	suite.Suite
+}
+
+func (s *SanitizeTestSuite) TestStripANSI() {
+	s.Equal("plain", StripANSI("plain"))
+	s.Equal("[high] exposed-git", StripANSI("\x1b[31m[high]\x1b[0m exposed-git"))
+	s.Equal("bold underlined", StripANSI("\x1b[1;4mbold underlined\x1b[m"))
+	s.Equal("cleared", StripANSI("\x1b[2K\x1b[1Gcleared"))
+	s.Equal("link", StripANSI("\x1b]8;;https://example.com\x1b\\link\x1b]8;;\x1b\\"))
+	s.Equal("titled", StripANSI("\x1b]0;nuclei\x07titled"))
+	s.Equal("reset", StripANSI("\x1bcreset"))
+	s.Equal("\n[high] finding", StripANSI("\x1b]0;unterminated title\n[high] finding"), "unterminated sequences end with their line")
+}
+
+func (s *SanitizeTestSuite) TestOutput() {
+	s.Equal("+ Server: nginx\n+ /admin/: found\t(200)", Output("+ Server: nginx\n+ /admin/: found\t(200)"))
+	s.Equal("+ Target IP: 127.0.0.1", Output("\x1b[32m+ Target IP:\x1b[0m 127.0.0.1"))
+	s.Equal("line one\nline two\n", Output("line one\r\nline two\r\n"))
+	s.Equal("[*] 100% done\nnext", Output("[*] 10%\r[*] 50%\r[*] 100% done\nnext"))
+	s.Equal("bell and backspace", Output("bell\a and back\bspace"))
+	s.Equal("invalid  bytes", Output("invalid \xff\xfe bytes"))
+	s.Equal("Überprüfung ✓", Output("Überprüfung ✓\x00"))
+}
+
+func TestSanitizeTestSuite(t *testing.T) {
+	suite.Run(t, new(SanitizeTestSuite))
+}
+
+// FuzzOutput checks that any scanner output, binary included, is normalized to valid UTF-8
+// without control characters other than newlines and tabs, and that normalizing again is a no-op.
+func FuzzOutput(f *testing.F) {
+	for _, seed := range []string{
+		"", "plain\n", "\x1b[31m[high]\x1b[0m", "50%\r100%\r\n", "\x1b]8;;http://x\x07link\x1b]8;;\x07",
+		"\xff\xfe\x00binary", "\x1b\x80[31m", "\x1b[", "tab\there\x7f", "\x1b]0;title\n[high] finding",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, output string) {
+		sanitized := Output(output)
+		if !clean(sanitized) {
+			t.Fatalf("Output(%q) = %q is not clean", output, sanitized)
+		}
+		if again := Output(sanitized); again != sanitized {
+			t.Fatalf("Output(%q) = %q, but normalizing it again gives %q", output, sanitized, again)
+		}
+		if strings.Count(sanitized, "\n") != strings.Count(output, "\n") {
+			t.Fatalf("Output(%q) = %q changed the number of lines", output, sanitized)
+		}
+	})
+}
@@ -0,0 +1,91 @@
+// Package scanconfig resolves the configuration files scanners are run with: a default file per
+// scanner set on the server, or a file named per call from an allowlisted directory.
+package scanconfig
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxNameLength bounds the length of per-call config file names.
+const maxNameLength = 255
+
+var (
+	// ErrOverridesDisabled is returned for per-call config files without a config directory.
+	ErrOverridesDisabled = errors.New("per-call scanner config files are disabled, start the server with --scanner-config-dir")
+	// ErrInvalidName is returned for per-call config names that are not plain file names.
+	ErrInvalidName = errors.New("scanner config must be a file name in the scanner config directory")
+)
+
+// Config locates scanner configuration files.
+type Config struct {
+	// Defaults maps scanner names to the config file used when a call names none.
+	Defaults map[string]string
+	// Dir is the allowlisted directory of per-call config files, one subdirectory per scanner
+	// (e.g. <Dir>/nikto/tuned.conf). Empty disables per-call config files.
+	Dir string
+}
+
+// Validate checks that the default files and the directory exist.
+func (c Config) Validate() error {
+	for scanner, path := range c.Defaults {
+		if path == "" {
+			continue
+		}
+		if info, err := os.Stat(path); err != nil {
+			return fmt.Errorf("%s config: %w", scanner, err)
+		} else if info.IsDir() {
+			return fmt.Errorf("%s config: %s is a directory", scanner, path)
+		}
+	}
+	if c.Dir != "" {
+		if info, err := os.Stat(c.Dir); err != nil {
+			return fmt.Errorf("scanner config directory: %w", err)
+		} else if !info.IsDir() {
+			return fmt.Errorf("scanner config directory: %s is not a directory", c.Dir)
+		}
+	}
+
+	return nil
+}
+
+// ValidName reports whether name can name a per-call config file: a plain, visible file name.
+func ValidName(name string) bool {
+	return name != "" && len(name) <= maxNameLength && name == filepath.Base(name) &&
+		!strings.HasPrefix(name, ".") && !strings.ContainsAny(name, `/\`)
+}
+
+// Resolve returns the config file scanner runs with: the file name of the call in the scanner's
+// subdirectory of Dir, or otherwise the scanner's default. It returns an empty path when there
+// is neither. Files reached through symlinks must stay inside the scanner's subdirectory.
+func (c Config) Resolve(scanner, name string) (string, error) {
+	if name == "" {
+		return c.Defaults[scanner], nil
+	}
+	if c.Dir == "" {
+		return "", ErrOverridesDisabled
+	}
+	if !ValidName(name) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidName, name)
+	}
+
+	dir, err := filepath.EvalSymlinks(filepath.Join(c.Dir, scanner))
+	if err != nil {
+		return "", fmt.Errorf("%s config %s not found", scanner, name)
+	}
+	path, err := filepath.EvalSymlinks(filepath.Join(dir, name))
+	if err != nil {
+		return "", fmt.Errorf("%s config %s not found", scanner, name)
+	}
+	if !strings.HasPrefix(path, dir+string(filepath.Separator)) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidName, name)
+	}
+	if info, err := os.Stat(path); err != nil || !info.Mode().IsRegular() {
+		return "", fmt.Errorf("%s config %s not found", scanner, name)
+	}
+
+	return path, nil
+}
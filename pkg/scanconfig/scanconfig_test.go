@@ -0,0 +1,83 @@
+package scanconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ScanConfigTestSuite struct {
+	suite.Suite
+	dir string
+}
+
+func (s *ScanConfigTestSuite) SetupTest() {
+	s.dir = s.T().TempDir()
+	s.Require().NoError(os.MkdirAll(filepath.Join(s.dir, "nikto"), 0o750))
+	s.Require().NoError(os.WriteFile(filepath.Join(s.dir, "nikto", "tuned.conf"), []byte("CLIOPTS=-Tuning 123\n"), 0o600))
+	s.Require().NoError(os.WriteFile(filepath.Join(s.dir, "secret.conf"), []byte("secret"), 0o600))
+}
+
+func (s *ScanConfigTestSuite) TestResolve_Default() {
+	cfg := Config{Defaults: map[string]string{"nikto": "/etc/nikto/org.conf"}}
+
+	path, err := cfg.Resolve("nikto", "")
+	s.Require().NoError(err)
+	s.Equal("/etc/nikto/org.conf", path)
+
+	path, err = cfg.Resolve("wapiti", "")
+	s.Require().NoError(err)
+	s.Empty(path)
+}
+
+func (s *ScanConfigTestSuite) TestResolve_Override() {
+	cfg := Config{Dir: s.dir}
+
+	path, err := cfg.Resolve("nikto", "tuned.conf")
+	s.Require().NoError(err)
+	s.Equal("tuned.conf", filepath.Base(path))
+
+	_, err = cfg.Resolve("nikto", "missing.conf")
+	s.ErrorContains(err, "nikto config missing.conf not found")
+	_, err = cfg.Resolve("wapiti", "tuned.conf")
+	s.ErrorContains(err, "wapiti config tuned.conf not found")
+	_, err = cfg.Resolve("nikto", "../secret.conf")
+	s.ErrorIs(err, ErrInvalidName)
+}
+
+func (s *ScanConfigTestSuite) TestResolve_SymlinkEscape() {
+	s.Require().NoError(os.Symlink(filepath.Join(s.dir, "secret.conf"), filepath.Join(s.dir, "nikto", "escape.conf")))
+
+	_, err := Config{Dir: s.dir}.Resolve("nikto", "escape.conf")
+	s.ErrorIs(err, ErrInvalidName)
+}
+
+func (s *ScanConfigTestSuite) TestResolve_OverridesDisabled() {
+	_, err := Config{}.Resolve("nikto", "tuned.conf")
+	s.ErrorIs(err, ErrOverridesDisabled)
+}
+
+func (s *ScanConfigTestSuite) TestValidate() {
+	s.NoError(Config{}.Validate())
+	s.NoError(Config{Defaults: map[string]string{"nikto": filepath.Join(s.dir, "nikto", "tuned.conf")}, Dir: s.dir}.Validate())
+
+	s.ErrorContains(Config{Defaults: map[string]string{"nikto": filepath.Join(s.dir, "missing.conf")}}.Validate(), "nikto config")
+	s.ErrorContains(Config{Defaults: map[string]string{"wapiti": s.dir}}.Validate(), "is a directory")
+	s.ErrorContains(Config{Dir: filepath.Join(s.dir, "secret.conf")}.Validate(), "is not a directory")
+	s.ErrorContains(Config{Dir: filepath.Join(s.dir, "missing")}.Validate(), "scanner config directory")
+}
+
+func (s *ScanConfigTestSuite) TestValidName() {
+	s.True(ValidName("tuned.conf"))
+	s.False(ValidName(""))
+	s.False(ValidName(".."))
+	s.False(ValidName(".tuned.conf"))
+	s.False(ValidName("nikto/tuned.conf"))
+	s.False(ValidName(`nikto\tuned.conf`))
+}
+
+func TestScanConfigTestSuite(t *testing.T) {
+	suite.Run(t, new(ScanConfigTestSuite))
+}
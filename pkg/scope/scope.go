@@ -0,0 +1,293 @@
+// Package scope implements the scope policy: the allowlist of hosts and ports scans may target,
+// loaded with --scope-file. Rules name a host, a wildcard domain, an IP address or a CIDR network,
+// optionally restricted to a port or port range. Without a policy every target is in scope.
+package scope
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/tb0hdan/wass-mcp/pkg/types"
+)
+
+var (
+	// ErrInvalidRule is returned for scope rules that cannot be parsed.
+	ErrInvalidRule = errors.New("invalid scope rule")
+	// ErrOutOfScope is returned for targets no scope rule permits.
+	ErrOutOfScope = errors.New("target is out of scope")
+)
+
+// maxPort is the highest TCP port.
+const maxPort = 65535
+
+// Rule is a parsed scope rule.
+type Rule struct {
+	// Text is the rule as written.
+	Text string
+
+	// host is the lowercase host name of host rules, the domain of wildcard rules.
+	host     string
+	wildcard bool
+	// prefix is the network of IP address and CIDR rules.
+	prefix netip.Prefix
+	// minPort and maxPort bound the ports the rule permits, 0 for any port.
+	minPort, maxPort int
+}
+
+// ParseRule parses a scope rule: a host name ("app.example.com"), a wildcard domain
+// ("*.internal.example.com", any subdomain but not the domain itself), an IP address or a CIDR
+// network ("10.0.0.0/8"), optionally followed by a port or port range (":443", ":8000-8999").
+// IPv6 addresses and networks take a port in brackets, e.g. "[2001:db8::/32]:443".
+func ParseRule(text string) (Rule, error) {
+	text = strings.TrimSpace(text)
+	rule := Rule{Text: text}
+
+	host, ports := text, ""
+	switch {
+	case strings.HasPrefix(text, "["):
+		end := strings.Index(text, "]")
+		if end < 0 || (end < len(text)-1 && text[end+1] != ':') {
+			return Rule{}, fmt.Errorf("%w %q: unbalanced brackets", ErrInvalidRule, text)
+		}
+		host = text[1:end]
+		if end < len(text)-1 {
+			ports = text[end+2:]
+			if ports == "" {
+				return Rule{}, fmt.Errorf("%w %q: empty port", ErrInvalidRule, text)
+			}
+		}
+	case strings.Count(text, ":") == 1:
+		host, ports, _ = strings.Cut(text, ":")
+		if ports == "" {
+			return Rule{}, fmt.Errorf("%w %q: empty port", ErrInvalidRule, text)
+		}
+	}
+
+	if ports != "" {
+		var err error
+		if rule.minPort, rule.maxPort, err = parsePorts(ports); err != nil {
+			return Rule{}, fmt.Errorf("%w %q: %w", ErrInvalidRule, text, err)
+		}
+	}
+
+	if prefix, err := netip.ParsePrefix(host); err == nil {
+		rule.prefix = prefix.Masked()
+		return rule, nil
+	}
+	if addr, err := netip.ParseAddr(host); err == nil {
+		rule.prefix = netip.PrefixFrom(addr, addr.BitLen())
+		return rule, nil
+	}
+
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if domain, ok := strings.CutPrefix(host, "*."); ok {
+		rule.wildcard = true
+		host = domain
+	}
+	if !validHostname(host) {
+		return Rule{}, fmt.Errorf("%w %q: expected a host name, *.domain, IP address or CIDR network", ErrInvalidRule, text)
+	}
+	rule.host = host
+
+	return rule, nil
+}
+
+// parsePorts parses a port ("443") or an inclusive port range ("8000-8999").
+func parsePorts(text string) (int, int, error) {
+	low, high, isRange := strings.Cut(text, "-")
+	minPort, err := strconv.Atoi(low)
+	if err != nil || minPort < 1 || minPort > maxPort {
+		return 0, 0, fmt.Errorf("invalid port %q", low)
+	}
+	if !isRange {
+		return minPort, minPort, nil
+	}
+	maxPortValue, err := strconv.Atoi(high)
+	if err != nil || maxPortValue < minPort || maxPortValue > maxPort {
+		return 0, 0, fmt.Errorf("invalid port range %q", text)
+	}
+
+	return minPort, maxPortValue, nil
+}
+
+// validHostname reports whether host is a DNS host name, with labels of letters, digits,
+// hyphens and underscores.
+func validHostname(host string) bool {
+	if host == "" || len(host) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(host, ".") {
+		if label == "" || len(label) > 63 || strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+			return false
+		}
+		for _, char := range label {
+			if (char < 'a' || char > 'z') && (char < '0' || char > '9') && char != '-' && char != '_' {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// Matches reports whether the rule permits host on port. Host names match host and wildcard
+// rules case-insensitively, IP addresses match IP address and CIDR rules; host names are not
+// resolved.
+func (r Rule) Matches(host string, port int) bool {
+	if r.minPort != 0 && (port < r.minPort || port > r.maxPort) {
+		return false
+	}
+
+	return r.matchesHost(host)
+}
+
+// matchesHost reports whether the rule permits host on any of its ports.
+func (r Rule) matchesHost(host string) bool {
+	if addr, err := netip.ParseAddr(strings.Trim(host, "[]")); err == nil {
+		return r.prefix.IsValid() && r.prefix.Contains(addr.Unmap().WithZone(""))
+	}
+	if r.prefix.IsValid() {
+		return false
+	}
+
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if r.wildcard {
+		return strings.HasSuffix(host, "."+r.host)
+	}
+
+	return host == r.host
+}
+
+// Policy is a scope policy, an allowlist of rules. A nil policy permits every target.
+type Policy struct {
+	rules []Rule
+}
+
+// New returns a policy of the rules, see ParseRule.
+func New(rules ...string) (*Policy, error) {
+	policy := &Policy{}
+	for _, text := range rules {
+		rule, err := ParseRule(text)
+		if err != nil {
+			return nil, err
+		}
+		policy.rules = append(policy.rules, rule)
+	}
+
+	return policy, nil
+}
+
+// LoadFile reads a policy from a file, see Parse.
+func LoadFile(path string) (*Policy, error) {
+	file, err := os.Open(path) //nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("failed to open scope file: %w", err)
+	}
+	defer file.Close()
+
+	return Parse(file)
+}
+
+// Parse reads a policy of one rule per line, see ParseRule. Blank lines and lines starting with #
+// are ignored.
+func Parse(r io.Reader) (*Policy, error) {
+	policy := &Policy{}
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		rule, err := ParseRule(text)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		policy.rules = append(policy.rules, rule)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read scope file: %w", err)
+	}
+	if len(policy.rules) == 0 {
+		return nil, fmt.Errorf("%w: no rules", ErrInvalidRule)
+	}
+
+	return policy, nil
+}
+
+// Enabled reports whether the policy restricts targets.
+func (p *Policy) Enabled() bool {
+	return p != nil
+}
+
+// Rules returns the rules of the policy as written.
+func (p *Policy) Rules() []string {
+	if p == nil {
+		return nil
+	}
+	rules := make([]string, 0, len(p.rules))
+	for _, rule := range p.rules {
+		rules = append(rules, rule.Text)
+	}
+
+	return rules
+}
+
+// Match returns the first rule permitting host on port, 0 for the default HTTP port.
+func (p *Policy) Match(host string, port int) (Rule, bool) {
+	if p == nil {
+		return Rule{}, true
+	}
+	if port == 0 {
+		port = types.DefaultPort
+	}
+	for _, rule := range p.rules {
+		if rule.Matches(host, port) {
+			return rule, true
+		}
+	}
+
+	return Rule{}, false
+}
+
+// CheckHost returns an error wrapping ErrOutOfScope when no rule permits host on any port, e.g.
+// before discovering the ports of host.
+func (p *Policy) CheckHost(host string) error {
+	if p == nil {
+		return nil
+	}
+	for _, rule := range p.rules {
+		if rule.matchesHost(host) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %s", ErrOutOfScope, host)
+}
+
+// Check returns an error wrapping ErrOutOfScope when no rule permits host on port.
+func (p *Policy) Check(host string, port int) error {
+	if _, ok := p.Match(host, port); !ok {
+		return fmt.Errorf("%w: %s", ErrOutOfScope, hostPort(host, port))
+	}
+
+	return nil
+}
+
+// hostPort formats host and port, bracketing IPv6 addresses.
+func hostPort(host string, port int) string {
+	if port == 0 {
+		port = types.DefaultPort
+	}
+	if strings.Contains(host, ":") {
+		return "[" + host + "]:" + strconv.Itoa(port)
+	}
+
+	return host + ":" + strconv.Itoa(port)
+}
@@ -0,0 +1,134 @@
+package scope
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ScopeTestSuite struct {
+	suite.Suite
+}
+
+func (s *ScopeTestSuite) TestParseRule() {
+	for _, text := range []string{
+		"app.example.com", "APP.example.com.", "*.internal.example.com", "*.internal.example.com:8000-8999",
+		"10.0.0.0/8", "192.0.2.10:443", "2001:db8::1", "2001:db8::/32", "[2001:db8::/32]:443", "[::1]:80-90",
+	} {
+		_, err := ParseRule(text)
+		s.NoError(err, text)
+	}
+
+	for _, text := range []string{
+		"", "*", "*.", "a..example.com", "-app.example.com", "app.example.com:", "app.example.com:0",
+		"app.example.com:70000", "app.example.com:90-80", "app.example.com:http", "[2001:db8::1", "[2001:db8::1]443",
+		"[::1]:", "*.10.0.0.0/8", "app example.com",
+	} {
+		_, err := ParseRule(text)
+		s.ErrorIs(err, ErrInvalidRule, text)
+	}
+}
+
+func (s *ScopeTestSuite) TestRule_Matches() {
+	tests := []struct {
+		rule  string
+		host  string
+		port  int
+		match bool
+	}{
+		{"app.example.com", "app.example.com", 443, true},
+		{"app.example.com", "APP.Example.com.", 80, true},
+		{"app.example.com", "www.app.example.com", 80, false},
+		{"*.internal.example.com", "api.internal.example.com", 443, true},
+		{"*.internal.example.com", "a.b.internal.example.com", 443, true},
+		{"*.internal.example.com", "internal.example.com", 443, false},
+		{"*.internal.example.com", "evilinternal.example.com", 443, false},
+		{"*.internal.example.com:8000-8999", "api.internal.example.com", 8080, true},
+		{"*.internal.example.com:8000-8999", "api.internal.example.com", 443, false},
+		{"10.0.0.0/8", "10.1.2.3", 80, true},
+		{"10.0.0.0/8", "11.1.2.3", 80, false},
+		{"10.0.0.0/8", "::ffff:10.1.2.3", 80, true},
+		{"10.0.0.0/8", "ten.example.com", 80, false},
+		{"192.0.2.10:443", "192.0.2.10", 443, true},
+		{"192.0.2.10:443", "192.0.2.10", 80, false},
+		{"[2001:db8::/32]:443", "2001:db8::5", 443, true},
+		{"[2001:db8::/32]:443", "[2001:db8::5]", 443, true},
+		{"2001:db8::/32", "2001:db9::5", 443, false},
+		{"app.example.com", "10.0.0.1", 80, false},
+	}
+	for _, test := range tests {
+		rule, err := ParseRule(test.rule)
+		s.Require().NoError(err)
+		s.Equal(test.match, rule.Matches(test.host, test.port), "%s against %s:%d", test.rule, test.host, test.port)
+	}
+}
+
+func (s *ScopeTestSuite) TestPolicy() {
+	policy, err := New("*.internal.example.com:8000-8999", "app.example.com")
+	s.Require().NoError(err)
+	s.True(policy.Enabled())
+	s.Equal([]string{"*.internal.example.com:8000-8999", "app.example.com"}, policy.Rules())
+
+	rule, ok := policy.Match("api.internal.example.com", 8443)
+	s.True(ok)
+	s.Equal("*.internal.example.com:8000-8999", rule.Text)
+
+	// Port 0 is the default HTTP port.
+	s.NoError(policy.Check("app.example.com", 0))
+	s.EqualError(policy.Check("api.internal.example.com", 443), "target is out of scope: api.internal.example.com:443")
+	s.ErrorIs(policy.Check("2001:db8::1", 443), ErrOutOfScope)
+	s.ErrorContains(policy.Check("2001:db8::1", 443), "[2001:db8::1]:443")
+
+	s.NoError(policy.CheckHost("api.internal.example.com"))
+	s.ErrorIs(policy.CheckHost("www.example.com"), ErrOutOfScope)
+
+	_, err = New("app.example.com", "bad rule")
+	s.ErrorIs(err, ErrInvalidRule)
+}
+
+func (s *ScopeTestSuite) TestNilPolicy() {
+	var policy *Policy
+	s.False(policy.Enabled())
+	s.Nil(policy.Rules())
+	s.NoError(policy.Check("anything.example.com", 22))
+	s.NoError(policy.CheckHost("anything.example.com"))
+	_, ok := policy.Match("anything.example.com", 22)
+	s.True(ok)
+}
+
+func (s *ScopeTestSuite) TestParse() {
+	policy, err := Parse(strings.NewReader(`
+# staging
+*.staging.example.com
+
+10.10.0.0/16:80-443
+`))
+	s.Require().NoError(err)
+	s.Equal([]string{"*.staging.example.com", "10.10.0.0/16:80-443"}, policy.Rules())
+
+	_, err = Parse(strings.NewReader("app.example.com\nhttp://app.example.com\n"))
+	s.ErrorIs(err, ErrInvalidRule)
+	s.ErrorContains(err, "line 2")
+
+	_, err = Parse(strings.NewReader("# nothing\n"))
+	s.ErrorIs(err, ErrInvalidRule)
+}
+
+func (s *ScopeTestSuite) TestLoadFile() {
+	path := filepath.Join(s.T().TempDir(), "scope.txt")
+	s.Require().NoError(os.WriteFile(path, []byte("app.example.com:443\n"), 0o600))
+
+	policy, err := LoadFile(path)
+	s.Require().NoError(err)
+	s.NoError(policy.Check("app.example.com", 443))
+
+	_, err = LoadFile(filepath.Join(s.T().TempDir(), "missing.txt"))
+	s.Error(err)
+}
+
+func TestScopeTestSuite(t *testing.T) {
+	suite.Run(t, new(ScopeTestSuite))
+}
@@ -0,0 +1,53 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// remoteAddrKey is the context key for the remote address of the HTTP request.
+type remoteAddrKey struct{}
+
+// Client identifies the MCP client that sent a request.
+type Client struct {
+	Name       string
+	RemoteAddr string
+	Version    string
+}
+
+// WithRemoteAddr makes the remote address of HTTP requests available to tool handlers through
+// ClientFromRequest.
+func WithRemoteAddr(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), remoteAddrKey{}, r.RemoteAddr)))
+	})
+}
+
+// ClientFromRequest returns the client behind a tool call. The name and version come from the
+// client info sent with initialize. Stateless sessions do not keep it past the initialize request,
+// so the product token of the User-Agent header is used instead when it is missing.
+func ClientFromRequest(ctx context.Context, req *mcp.CallToolRequest) Client {
+	var client Client
+	client.RemoteAddr, _ = ctx.Value(remoteAddrKey{}).(string)
+	if req == nil {
+		return client
+	}
+
+	if req.Session != nil {
+		if params := req.Session.InitializeParams(); params != nil && params.ClientInfo != nil {
+			client.Name = params.ClientInfo.Name
+			client.Version = params.ClientInfo.Version
+			return client
+		}
+	}
+
+	if req.Extra != nil && req.Extra.Header != nil {
+		product, _, _ := strings.Cut(strings.TrimSpace(req.Extra.Header.Get("User-Agent")), " ")
+		client.Name, client.Version, _ = strings.Cut(product, "/")
+	}
+
+	return client
+}
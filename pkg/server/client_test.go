@@ -0,0 +1,74 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestWithRemoteAddr(t *testing.T) {
+	var client Client
+	handler := WithRemoteAddr(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		client = ClientFromRequest(r.Context(), nil)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+	req.RemoteAddr = "192.0.2.10:51234"
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if client.RemoteAddr != "192.0.2.10:51234" {
+		t.Errorf("expected remote address to be recorded, got %q", client.RemoteAddr)
+	}
+}
+
+func TestClientFromRequest_UserAgent(t *testing.T) {
+	req := &mcp.CallToolRequest{Extra: &mcp.RequestExtra{Header: http.Header{
+		"User-Agent": []string{"claude-code/2.0.14 (linux)"},
+	}}}
+
+	client := ClientFromRequest(context.Background(), req)
+	if client.Name != "claude-code" || client.Version != "2.0.14" {
+		t.Errorf("expected client from User-Agent, got %+v", client)
+	}
+	if client.RemoteAddr != "" {
+		t.Errorf("expected no remote address outside HTTP, got %q", client.RemoteAddr)
+	}
+}
+
+func TestClientFromRequest_InitializeParams(t *testing.T) {
+	store, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	srv := NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}, store)
+	clients := make(chan Client, 1)
+	mcp.AddTool(&srv.Server, &mcp.Tool{Name: "probe"}, func(ctx context.Context, req *mcp.CallToolRequest, _ any) (*mcp.CallToolResult, any, error) {
+		clients <- ClientFromRequest(ctx, req)
+		return &mcp.CallToolResult{}, nil, nil
+	})
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := srv.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("failed to connect server: %v", err)
+	}
+	defer serverSession.Close()
+
+	session, err := mcp.NewClient(&mcp.Implementation{Name: "scan-agent", Version: "0.3.1"}, nil).Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("failed to connect client: %v", err)
+	}
+	defer session.Close()
+
+	if _, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "probe"}); err != nil {
+		t.Fatalf("failed to call tool: %v", err)
+	}
+
+	client := <-clients
+	if client.Name != "scan-agent" || client.Version != "0.3.1" {
+		t.Errorf("expected client from initialize, got %+v", client)
+	}
+}
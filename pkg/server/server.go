@@ -2,27 +2,405 @@ package server
 
 import (
 	"context"
+	"fmt"
+	"sync"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/tb0hdan/wass-mcp/pkg/artifacts"
+	"github.com/tb0hdan/wass-mcp/pkg/intel"
+	"github.com/tb0hdan/wass-mcp/pkg/limiter"
+	"github.com/tb0hdan/wass-mcp/pkg/metrics"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/redact"
+	"github.com/tb0hdan/wass-mcp/pkg/running"
+	"github.com/tb0hdan/wass-mcp/pkg/scanconfig"
+	"github.com/tb0hdan/wass-mcp/pkg/scope"
+	"github.com/tb0hdan/wass-mcp/pkg/session"
 	"github.com/tb0hdan/wass-mcp/pkg/storage"
+	"github.com/tb0hdan/wass-mcp/pkg/tenant"
+	"github.com/tb0hdan/wass-mcp/pkg/vault"
+	"github.com/tb0hdan/wass-mcp/pkg/wordlist"
 )
 
+// RerunFunc re-runs a tool from the stored (redacted) JSON input of a previous execution, stored
+// with the input schema version schemaVersion, 0 for an input in the current schema.
+type RerunFunc func(ctx context.Context, inputJSON string, schemaVersion int) error
+
 type Server struct {
 	mcp.Server
-	storage storage.Storage
+	storage   storage.Storage
+	redactor  *redact.Redactor
+	artifacts artifacts.Config
+	// intel holds the EPSS and KEV datasets findings are enriched with.
+	intel *intel.Intel
+	// suppressions are the suppression rules configured for every tenant.
+	suppressions []models.SuppressionRule
+	// compressThreshold is the output size above which full_scan compresses its response, 0 to never.
+	compressThreshold int
+	limiter           *limiter.Limiter
+	// maxResponseBytes bounds the output text returned per tool call, 0 for unlimited.
+	maxResponseBytes int
+	metrics          *metrics.Metrics
+	// reportBranding is the engagement metadata printed in full_scan reports by default.
+	reportBranding models.ReportBranding
+	// workDir holds the working directories of scanner runs, empty for the system temp directory.
+	workDir string
+	// scanTimeout bounds each scanner run of inputs without a timeout, 0 for no bound.
+	scanTimeout time.Duration
+	// fullScanTimeout bounds full scans without a total timeout, 0 for no bound.
+	fullScanTimeout time.Duration
+	// scannerConfigs locates the configuration files scanners are run with.
+	scannerConfigs scanconfig.Config
+	// scope is the allowlist of scan targets, nil to permit every target.
+	scope *scope.Policy
+	// vault decrypts the stored credentials scans authenticate with, nil when disabled.
+	vault *vault.Vault
+	// wordlists holds the wordlists content discovery scans reference by name, nil when disabled.
+	wordlists *wordlist.Registry
+	reruns    map[string]RerunFunc
+	jobs      *running.Registry
+	// sessions holds the defaults set by MCP sessions with set_context.
+	sessions *session.Store
+
+	disabledMu sync.RWMutex
+	// disabled holds the names of scanners disabled at runtime.
+	disabled map[string]struct{}
 }
 
 func NewServer(impl *mcp.Implementation, store storage.Storage) *Server {
-	return &Server{
-		Server:  *mcp.NewServer(impl, nil),
-		storage: store,
+	sessions := session.NewStore(session.DefaultTTL)
+	srv := &Server{
+		// Issue session IDs set_context can verify, see session.Store.NewID
+		Server:   *mcp.NewServer(impl, &mcp.ServerOptions{GetSessionID: sessions.NewID}),
+		storage:  store,
+		jobs:     running.New(),
+		sessions: sessions,
+		disabled: make(map[string]struct{}),
 	}
+	// Scope requests authenticated with a tenant key to the data of that tenant
+	srv.AddReceivingMiddleware(tenant.Middleware())
+
+	return srv
 }
 
 func (s *Server) Storage() storage.Storage {
 	return s.storage
 }
 
+// SetRedactor sets the redactor applied to execution records before they are stored.
+func (s *Server) SetRedactor(redactor *redact.Redactor) {
+	s.redactor = redactor
+}
+
+// Redactor returns the configured redactor, or the default one when none was set.
+func (s *Server) Redactor() *redact.Redactor {
+	if s.redactor == nil {
+		return redact.Default()
+	}
+	return s.redactor
+}
+
+// SetArtifacts sets where outputs exceeding the stored output size limit are spilled.
+func (s *Server) SetArtifacts(cfg artifacts.Config) {
+	s.artifacts = cfg
+}
+
+// Artifacts returns the output spilling configuration. Spilling is disabled unless configured.
+func (s *Server) Artifacts() artifacts.Config {
+	return s.artifacts
+}
+
+// SetIntel sets the exploit intelligence stored findings are enriched with.
+func (s *Server) SetIntel(threatIntel *intel.Intel) {
+	s.intel = threatIntel
+}
+
+// Intel returns the exploit intelligence. It is nil, meaning findings only get their CVE IDs,
+// unless configured.
+func (s *Server) Intel() *intel.Intel {
+	return s.intel
+}
+
+// SetSuppressionRules sets the suppression rules applied to the findings of every tenant, in
+// addition to the rules each tenant stores.
+func (s *Server) SetSuppressionRules(rules []models.SuppressionRule) {
+	s.suppressions = rules
+}
+
+// SuppressionRules returns the configured suppression rules.
+func (s *Server) SuppressionRules() []models.SuppressionRule {
+	return s.suppressions
+}
+
+// SetScanLimiter sets the limiter bounding concurrent scanner runs across all tools.
+func (s *Server) SetScanLimiter(scanLimiter *limiter.Limiter) {
+	s.limiter = scanLimiter
+}
+
+// ScanLimiter returns the scan limiter. It is nil, meaning unlimited, unless configured.
+func (s *Server) ScanLimiter() *limiter.Limiter {
+	return s.limiter
+}
+
+// SetCompressThreshold sets the page size above which full_scan returns a compressed response,
+// 0 to never compress automatically.
+func (s *Server) SetCompressThreshold(threshold int) {
+	s.compressThreshold = threshold
+}
+
+// CompressThreshold returns the page size above which full_scan returns a compressed response.
+// It is 0, meaning never, unless configured.
+func (s *Server) CompressThreshold() int {
+	return s.compressThreshold
+}
+
+// SetMaxResponseBytes sets the byte budget of the output returned per tool call, 0 for unlimited.
+func (s *Server) SetMaxResponseBytes(maxBytes int) {
+	s.maxResponseBytes = maxBytes
+}
+
+// MaxResponseBytes returns the byte budget of the output returned per tool call. It is 0, meaning
+// unlimited, unless configured.
+func (s *Server) MaxResponseBytes() int {
+	return s.maxResponseBytes
+}
+
+// SetReportBranding sets the engagement metadata printed in full_scan reports unless a scan
+// overrides it.
+func (s *Server) SetReportBranding(branding models.ReportBranding) {
+	s.reportBranding = branding
+}
+
+// ReportBranding returns the default engagement metadata of full_scan reports, empty unless
+// configured.
+func (s *Server) ReportBranding() models.ReportBranding {
+	return s.reportBranding
+}
+
+// SetWorkDir sets the directory holding the working directories of scanner runs, e.g. a tmpfs
+// mount. Empty uses the system temp directory.
+func (s *Server) SetWorkDir(dir string) {
+	s.workDir = dir
+}
+
+// WorkDir returns the directory holding the working directories of scanner runs. It is empty,
+// meaning the system temp directory, unless configured.
+func (s *Server) WorkDir() string {
+	return s.workDir
+}
+
+// SetScanTimeout sets the run time allowed to each scanner run of inputs without a timeout, 0 for
+// no bound.
+func (s *Server) SetScanTimeout(timeout time.Duration) {
+	s.scanTimeout = timeout
+}
+
+// ScanTimeout returns the run time allowed to each scanner run of inputs without a timeout. It is
+// 0, meaning no bound, unless configured.
+func (s *Server) ScanTimeout() time.Duration {
+	return s.scanTimeout
+}
+
+// SetFullScanTimeout sets the run time allowed to full scans without a total timeout, 0 for no
+// bound.
+func (s *Server) SetFullScanTimeout(timeout time.Duration) {
+	s.fullScanTimeout = timeout
+}
+
+// FullScanTimeout returns the run time allowed to full scans without a total timeout. It is 0,
+// meaning no bound, unless configured.
+func (s *Server) FullScanTimeout() time.Duration {
+	return s.fullScanTimeout
+}
+
+// SetScannerConfigs sets the default scanner config files and the directory of per-call ones.
+func (s *Server) SetScannerConfigs(cfg scanconfig.Config) {
+	s.scannerConfigs = cfg
+}
+
+// ScannerConfigs returns the scanner config file locations. Scanners run with their built-in
+// configuration unless configured.
+func (s *Server) ScannerConfigs() scanconfig.Config {
+	return s.scannerConfigs
+}
+
+// SetScope sets the scope policy scan targets are checked against.
+func (s *Server) SetScope(policy *scope.Policy) {
+	s.scope = policy
+}
+
+// Scope returns the scope policy, nil when every target is permitted.
+func (s *Server) Scope() *scope.Policy {
+	return s.scope
+}
+
+// SetVault sets the vault decrypting stored credentials.
+func (s *Server) SetVault(credentialVault *vault.Vault) {
+	s.vault = credentialVault
+}
+
+// Vault returns the credential vault, nil when scans cannot use stored credentials.
+func (s *Server) Vault() *vault.Vault {
+	return s.vault
+}
+
+// SetWordlists sets the registry of the wordlists scans reference by name.
+func (s *Server) SetWordlists(registry *wordlist.Registry) {
+	s.wordlists = registry
+}
+
+// Wordlists returns the wordlist registry, nil when scans cannot reference wordlists.
+func (s *Server) Wordlists() *wordlist.Registry {
+	return s.wordlists
+}
+
+// SetMetrics sets the metrics recording scanner run outcomes.
+func (s *Server) SetMetrics(scanMetrics *metrics.Metrics) {
+	s.metrics = scanMetrics
+}
+
+// Metrics returns the scanner metrics. It is nil, meaning nothing is recorded, unless configured.
+func (s *Server) Metrics() *metrics.Metrics {
+	return s.metrics
+}
+
+// Sessions returns the store of session defaults set with set_context.
+func (s *Server) Sessions() *session.Store {
+	return s.sessions
+}
+
+// Jobs returns the registry of running tool executions.
+func (s *Server) Jobs() *running.Registry {
+	return s.jobs
+}
+
+// SetScannerEnabled enables or disables the named scanner at runtime. Disabled scanners stay
+// registered but refuse to scan and are skipped by multi-scanner tools.
+func (s *Server) SetScannerEnabled(name string, enabled bool) {
+	s.disabledMu.Lock()
+	defer s.disabledMu.Unlock()
+
+	if enabled {
+		delete(s.disabled, name)
+	} else {
+		s.disabled[name] = struct{}{}
+	}
+}
+
+// ScannerEnabled reports whether the named scanner is enabled. Scanners are enabled unless
+// disabled with SetScannerEnabled.
+func (s *Server) ScannerEnabled(name string) bool {
+	s.disabledMu.RLock()
+	defer s.disabledMu.RUnlock()
+
+	_, disabled := s.disabled[name]
+	return !disabled
+}
+
+// RegisterRerun registers how executions of the named tool are re-run after an interruption.
+func (s *Server) RegisterRerun(toolName string, rerun RerunFunc) {
+	if s.reruns == nil {
+		s.reruns = make(map[string]RerunFunc)
+	}
+	s.reruns[toolName] = rerun
+}
+
+// Rerun re-runs the tool of exec from its stored input, upgraded from the input schema version it
+// was stored with, see RegisterRerun.
+func (s *Server) Rerun(ctx context.Context, exec models.ToolExecution) error {
+	rerun, ok := s.reruns[exec.ToolName]
+	if !ok {
+		return fmt.Errorf("tool %s is not registered", exec.ToolName)
+	}
+
+	return rerun(ctx, exec.InputJSON, exec.InputSchemaVersion())
+}
+
+// Runnable reports whether the named tool can be run outside an MCP call, see RegisterRerun.
+func (s *Server) Runnable(toolName string) bool {
+	_, ok := s.reruns[toolName]
+	return ok
+}
+
+// Run runs the named tool with the JSON input outside an MCP call, as background scan jobs do.
+func (s *Server) Run(ctx context.Context, toolName, inputJSON string) error {
+	rerun, ok := s.reruns[toolName]
+	if !ok {
+		return fmt.Errorf("tool %s is not registered", toolName)
+	}
+
+	return rerun(ctx, inputJSON, 0)
+}
+
+// RecoverInterrupted marks executions left running by a previous process as interrupted and
+// returns them. When requeue is set, the retryable ones are re-run in the background one at a
+// time on behalf of their tenant, at low scan priority so that interactive scans go first, calling
+// report with the outcome of each re-run.
+func (s *Server) RecoverInterrupted(
+	ctx context.Context,
+	requeue bool,
+	report func(exec models.ToolExecution, err error),
+) ([]models.ToolExecution, error) {
+	interrupted, err := s.storage.MarkInterruptedExecutions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to mark interrupted executions: %w", err)
+	}
+	if !requeue {
+		return interrupted, nil
+	}
+
+	var retry []models.ToolExecution
+	for _, exec := range interrupted {
+		if exec.Retryable {
+			retry = append(retry, exec)
+		}
+	}
+	if len(retry) == 0 {
+		return interrupted, nil
+	}
+
+	go func() {
+		for _, exec := range retry {
+			rerunCtx := limiter.WithPriority(ctx, limiter.PriorityLow)
+			if exec.Tenant != "" {
+				rerunCtx = tenant.WithTenant(rerunCtx, exec.Tenant)
+			}
+			report(exec, s.Rerun(rerunCtx, exec))
+		}
+	}()
+
+	return interrupted, nil
+}
+
+// Tools lists the tools currently registered with the MCP server, as an MCP client would see them.
+func (s *Server) Tools(ctx context.Context) ([]*mcp.Tool, error) {
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+
+	serverSession, err := s.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to server: %w", err)
+	}
+	defer serverSession.Close()
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "tool-lister"}, nil)
+	clientSession, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect client: %w", err)
+	}
+	defer clientSession.Close()
+
+	var list []*mcp.Tool
+	for tool, err := range clientSession.Tools(ctx, nil) {
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tools: %w", err)
+		}
+		list = append(list, tool)
+	}
+
+	return list, nil
+}
+
 func (s *Server) Shutdown(ctx context.Context) error {
 	if s.storage != nil {
 		return s.storage.Close()
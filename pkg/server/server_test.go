@@ -6,8 +6,14 @@ import (
 	"testing"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/tb0hdan/wass-mcp/pkg/crypto"
+	"github.com/tb0hdan/wass-mcp/pkg/limiter"
+	"github.com/tb0hdan/wass-mcp/pkg/metrics"
 	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/redact"
+	"github.com/tb0hdan/wass-mcp/pkg/scanconfig"
 	"github.com/tb0hdan/wass-mcp/pkg/storage"
+	"github.com/tb0hdan/wass-mcp/pkg/vault"
 )
 
 func setupTestStorage(t *testing.T) (storage.Storage, func()) {
@@ -157,3 +163,237 @@ func TestServer_EmbeddedMCPServer(t *testing.T) {
 		t.Error("expected Storage() to return the same store passed to NewServer")
 	}
 }
+
+func TestServer_Redactor(t *testing.T) {
+	srv := NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}, nil)
+
+	if srv.Redactor() == nil {
+		t.Fatal("expected default redactor when none is set")
+	}
+
+	redactor, err := redact.New(redact.Config{Fields: []string{"custom"}})
+	if err != nil {
+		t.Fatalf("failed to create redactor: %v", err)
+	}
+	srv.SetRedactor(redactor)
+
+	if srv.Redactor() != redactor {
+		t.Error("expected configured redactor to be returned")
+	}
+}
+
+func TestServer_RecoverInterrupted(t *testing.T) {
+	store, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	executions := []*models.ToolExecution{
+		{ToolName: "nikto", Status: models.StatusRunning, InputJSON: `{"host":"a"}`, Retryable: true},
+		{ToolName: "nikto", Status: models.StatusRunning, InputJSON: `{"host":"b"}`},
+		{ToolName: "unknown", Status: models.StatusRunning, Retryable: true},
+		{ToolName: "nikto", Status: models.StatusCompleted, Retryable: true},
+	}
+	for _, exec := range executions {
+		if err := store.CreateToolExecution(ctx, exec); err != nil {
+			t.Fatalf("failed to create execution: %v", err)
+		}
+	}
+
+	srv := NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}, store)
+
+	reran := make(chan string, 1)
+	srv.RegisterRerun("nikto", func(rerunCtx context.Context, inputJSON string, _ int) error {
+		if priority := limiter.PriorityFrom(rerunCtx); priority != limiter.PriorityLow {
+			t.Errorf("expected re-runs at low priority, got %q", priority)
+		}
+		reran <- inputJSON
+		return nil
+	})
+
+	reports := make(chan error, 2)
+	interrupted, err := srv.RecoverInterrupted(ctx, true, func(_ models.ToolExecution, err error) {
+		reports <- err
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(interrupted) != 3 {
+		t.Errorf("expected 3 interrupted executions, got %d", len(interrupted))
+	}
+
+	if input := <-reran; input != `{"host":"a"}` {
+		t.Errorf("expected retryable execution to be re-run, got %s", input)
+	}
+	if err := <-reports; err != nil {
+		t.Errorf("expected successful re-run, got %v", err)
+	}
+	if err := <-reports; err == nil {
+		t.Error("expected an error for an unregistered tool")
+	}
+}
+
+func TestServer_RecoverInterrupted_NoRequeue(t *testing.T) {
+	store, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	exec := &models.ToolExecution{ToolName: "nikto", Status: models.StatusRunning, Retryable: true}
+	if err := store.CreateToolExecution(ctx, exec); err != nil {
+		t.Fatalf("failed to create execution: %v", err)
+	}
+
+	srv := NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}, store)
+	srv.RegisterRerun("nikto", func(context.Context, string, int) error {
+		t.Error("expected no re-run without requeue")
+		return nil
+	})
+
+	interrupted, err := srv.RecoverInterrupted(ctx, false, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(interrupted) != 1 {
+		t.Errorf("expected 1 interrupted execution, got %d", len(interrupted))
+	}
+}
+
+func TestServer_RerunSchemaVersion(t *testing.T) {
+	srv := NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}, nil)
+
+	versions := make(chan int, 3)
+	srv.RegisterRerun("nikto", func(_ context.Context, _ string, schemaVersion int) error {
+		versions <- schemaVersion
+		return nil
+	})
+
+	ctx := context.Background()
+	if err := srv.Rerun(ctx, models.ToolExecution{ToolName: "nikto"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := srv.Rerun(ctx, models.ToolExecution{ToolName: "nikto", SchemaVersion: 2}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := srv.Run(ctx, "nikto", `{}`); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := srv.Rerun(ctx, models.ToolExecution{ToolName: "unknown"}); err == nil {
+		t.Error("expected an error for an unregistered tool")
+	}
+
+	for _, expected := range []int{models.BaseSchemaVersion, 2, 0} {
+		if version := <-versions; version != expected {
+			t.Errorf("expected schema version %d, got %d", expected, version)
+		}
+	}
+}
+
+func TestServer_Tools(t *testing.T) {
+	srv := NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}, nil)
+	mcp.AddTool(&srv.Server, &mcp.Tool{Name: "echo", Description: "Echoes its input."},
+		func(context.Context, *mcp.CallToolRequest, struct{}) (*mcp.CallToolResult, any, error) {
+			return &mcp.CallToolResult{}, nil, nil
+		})
+
+	list, err := srv.Tools(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(list) != 1 || list[0].Name != "echo" || list[0].Description != "Echoes its input." {
+		t.Errorf("expected the echo tool, got %+v", list)
+	}
+}
+
+func TestServer_ScannerEnabled(t *testing.T) {
+	srv := NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}, nil)
+
+	if !srv.ScannerEnabled("nikto") {
+		t.Error("expected scanners to be enabled by default")
+	}
+
+	srv.SetScannerEnabled("nikto", false)
+	if srv.ScannerEnabled("nikto") {
+		t.Error("expected nikto to be disabled")
+	}
+	if !srv.ScannerEnabled("nuclei") {
+		t.Error("expected other scanners to stay enabled")
+	}
+
+	srv.SetScannerEnabled("nikto", true)
+	if !srv.ScannerEnabled("nikto") {
+		t.Error("expected nikto to be enabled again")
+	}
+}
+
+func TestServer_Jobs(t *testing.T) {
+	srv := NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}, nil)
+	if srv.Jobs() == nil {
+		t.Fatal("expected a job registry")
+	}
+	if len(srv.Jobs().List()) != 0 {
+		t.Error("expected no running jobs")
+	}
+}
+
+func TestServer_Metrics(t *testing.T) {
+	srv := NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}, nil)
+	if srv.Metrics() != nil {
+		t.Error("expected no metrics unless configured")
+	}
+
+	scanMetrics := metrics.New()
+	srv.SetMetrics(scanMetrics)
+	if srv.Metrics() != scanMetrics {
+		t.Error("expected the configured metrics")
+	}
+}
+
+func TestServer_WorkDir(t *testing.T) {
+	srv := NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}, nil)
+	if srv.WorkDir() != "" {
+		t.Error("expected the system temp directory unless configured")
+	}
+
+	srv.SetWorkDir("/dev/shm/wass")
+	if srv.WorkDir() != "/dev/shm/wass" {
+		t.Errorf("expected the configured work directory, got %q", srv.WorkDir())
+	}
+}
+
+func TestServer_ScannerConfigs(t *testing.T) {
+	srv := NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}, nil)
+	if path, err := srv.ScannerConfigs().Resolve("nikto", ""); err != nil || path != "" {
+		t.Errorf("expected no scanner config unless configured, got %q (err: %v)", path, err)
+	}
+
+	srv.SetScannerConfigs(scanconfig.Config{Defaults: map[string]string{"nikto": "/etc/wass/nikto.conf"}})
+	if path, _ := srv.ScannerConfigs().Resolve("nikto", ""); path != "/etc/wass/nikto.conf" {
+		t.Errorf("expected the configured nikto config, got %q", path)
+	}
+}
+
+func TestServer_Vault(t *testing.T) {
+	srv := NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}, nil)
+	if srv.Vault() != nil {
+		t.Error("expected no vault unless configured")
+	}
+
+	keyring, err := crypto.NewKeyring(make([]byte, crypto.KeySize))
+	if err != nil {
+		t.Fatalf("failed to create keyring: %v", err)
+	}
+	credentialVault := vault.New(keyring)
+	srv.SetVault(credentialVault)
+	if srv.Vault() != credentialVault {
+		t.Error("expected the configured vault")
+	}
+}
+
+func TestServer_Sessions(t *testing.T) {
+	srv := NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}, nil)
+	if srv.Sessions() == nil {
+		t.Fatal("expected a session store")
+	}
+	if srv.Sessions().Len() != 0 {
+		t.Error("expected no session defaults")
+	}
+}
@@ -0,0 +1,173 @@
+// Package session holds per-session defaults set by MCP clients, such as the default scan target
+// of an agent loop. The defaults are held in the memory of the server process: they are lost on
+// restart and not shared between the front ends of a shared job queue.
+package session
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"maps"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultTTL is how long the defaults of an idle session are kept.
+const DefaultTTL = 24 * time.Hour
+
+// Defaults is the default scan target and profile of a session, used by scanner calls that do not
+// name a host.
+type Defaults struct {
+	CABundle           string            `json:"ca_bundle,omitempty"`
+	Host               string            `json:"host"`
+	InsecureSkipVerify bool              `json:"insecure_skip_verify,omitempty"`
+	Options            map[string]string `json:"options,omitempty"`
+	Path               string            `json:"path,omitempty"`
+	Port               int               `json:"port,omitempty"`
+	Scheme             string            `json:"scheme,omitempty"`
+	Vhost              string            `json:"vhost,omitempty"`
+}
+
+// key identifies a session within a tenant.
+type key struct {
+	id     string
+	tenant string
+}
+
+// entry holds the defaults of a session and when they were last used.
+type entry struct {
+	defaults Defaults
+	lastUsed time.Time
+}
+
+// Store holds session defaults in memory, scoped by tenant. Sessions idle for longer than the TTL
+// are dropped. It also issues the session IDs of the MCP server, signed with a random key of the
+// store, see NewID. A nil Store holds nothing.
+type Store struct {
+	mu      sync.Mutex
+	entries map[key]entry
+	key     []byte
+	now     func() time.Time
+	ttl     time.Duration
+}
+
+// NewStore creates an empty store dropping sessions idle for longer than ttl, 0 to keep them
+// until the server stops.
+func NewStore(ttl time.Duration) *Store {
+	signingKey := make([]byte, sha256.Size)
+	_, _ = rand.Read(signingKey)
+
+	return &Store{entries: make(map[key]entry), key: signingKey, now: time.Now, ttl: ttl}
+}
+
+// NewID returns a new random session ID signed with the key of the store. It is the session ID
+// generator of the MCP server: the server is stateless, so the SDK accepts any Mcp-Session-Id a
+// client sends, and Issued tells the IDs of this store from made-up ones. A nil Store returns an
+// empty ID, leaving requests without session.
+func (s *Store) NewID() string {
+	if s == nil {
+		return ""
+	}
+
+	nonce := make([]byte, 16)
+	_, _ = rand.Read(nonce)
+	id := base64.RawURLEncoding.EncodeToString(nonce)
+
+	return id + "." + s.sign(id)
+}
+
+// Issued reports whether id was returned by NewID of the store, i.e. by this server process.
+func (s *Store) Issued(id string) bool {
+	if s == nil {
+		return false
+	}
+	nonce, signature, ok := strings.Cut(id, ".")
+
+	return ok && hmac.Equal([]byte(signature), []byte(s.sign(nonce)))
+}
+
+// sign returns the signature of nonce with the key of the store.
+func (s *Store) sign(nonce string) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(nonce))
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Set sets the defaults of session id of tenant.
+func (s *Store) Set(tenant, id string, defaults Defaults) {
+	if s == nil {
+		return
+	}
+
+	defaults.Options = maps.Clone(defaults.Options)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expireLocked()
+	s.entries[key{id: id, tenant: tenant}] = entry{defaults: defaults, lastUsed: s.now()}
+}
+
+// Get returns the defaults of session id of tenant, keeping the session alive.
+func (s *Store) Get(tenant, id string) (Defaults, bool) {
+	if s == nil || id == "" {
+		return Defaults{}, false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expireLocked()
+	current, ok := s.entries[key{id: id, tenant: tenant}]
+	if !ok {
+		return Defaults{}, false
+	}
+	current.lastUsed = s.now()
+	s.entries[key{id: id, tenant: tenant}] = current
+
+	defaults := current.defaults
+	defaults.Options = maps.Clone(defaults.Options)
+
+	return defaults, true
+}
+
+// Clear removes the defaults of session id of tenant. It reports whether any were set.
+func (s *Store) Clear(tenant, id string) bool {
+	if s == nil {
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.entries[key{id: id, tenant: tenant}]
+	delete(s.entries, key{id: id, tenant: tenant})
+
+	return ok
+}
+
+// Len returns the number of sessions with defaults.
+func (s *Store) Len() int {
+	if s == nil {
+		return 0
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expireLocked()
+
+	return len(s.entries)
+}
+
+// expireLocked drops the sessions idle for longer than the TTL.
+func (s *Store) expireLocked() {
+	if s.ttl <= 0 {
+		return
+	}
+
+	cutoff := s.now().Add(-s.ttl)
+	for k, current := range s.entries {
+		if current.lastUsed.Before(cutoff) {
+			delete(s.entries, k)
+		}
+	}
+}
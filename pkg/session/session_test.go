@@ -0,0 +1,87 @@
+package session
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type SessionTestSuite struct {
+	suite.Suite
+}
+
+func (s *SessionTestSuite) TestSetGetClear() {
+	store := NewStore(DefaultTTL)
+	options := map[string]string{"user_agent": "agent"}
+	store.Set("acme", "s1", Defaults{Host: "example.com", Port: 8080, Options: options})
+	options["user_agent"] = "changed"
+
+	defaults, ok := store.Get("acme", "s1")
+	s.Require().True(ok)
+	s.Equal("example.com", defaults.Host)
+	s.Equal(8080, defaults.Port)
+	s.Equal("agent", defaults.Options["user_agent"])
+	defaults.Options["user_agent"] = "changed"
+	again, _ := store.Get("acme", "s1")
+	s.Equal("agent", again.Options["user_agent"])
+
+	// Sessions are scoped by tenant.
+	_, ok = store.Get("", "s1")
+	s.False(ok)
+	_, ok = store.Get("acme", "")
+	s.False(ok)
+	s.Equal(1, store.Len())
+
+	s.True(store.Clear("acme", "s1"))
+	s.False(store.Clear("acme", "s1"))
+	_, ok = store.Get("acme", "s1")
+	s.False(ok)
+}
+
+func (s *SessionTestSuite) TestExpiry() {
+	store := NewStore(time.Hour)
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+
+	store.Set("", "idle", Defaults{Host: "a.example"})
+	store.Set("", "active", Defaults{Host: "b.example"})
+	now = now.Add(40 * time.Minute)
+	_, ok := store.Get("", "active")
+	s.True(ok)
+
+	// Getting a session keeps it alive.
+	now = now.Add(40 * time.Minute)
+	_, ok = store.Get("", "idle")
+	s.False(ok)
+	_, ok = store.Get("", "active")
+	s.True(ok)
+	s.Equal(1, store.Len())
+}
+
+func (s *SessionTestSuite) TestIssuedIDs() {
+	store := NewStore(0)
+	id := store.NewID()
+	s.True(store.Issued(id))
+	s.NotEqual(id, store.NewID())
+
+	s.False(store.Issued(""))
+	s.False(store.Issued("client-chosen"))
+	s.False(store.Issued(id+"x"), "tampered IDs are rejected")
+	s.False(NewStore(0).Issued(id), "IDs of another process are rejected")
+}
+
+func (s *SessionTestSuite) TestNilStore() {
+	var store *Store
+	s.Empty(store.NewID())
+	s.False(store.Issued("s1"))
+	store.Set("", "s1", Defaults{Host: "example.com"})
+	_, ok := store.Get("", "s1")
+	s.False(ok)
+	s.False(store.Clear("", "s1"))
+	s.Zero(store.Len())
+}
+
+func TestSessionTestSuite(t *testing.T) {
+	suite.Run(t, new(SessionTestSuite))
+}
@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/tb0hdan/wass-mcp/pkg/tenant"
+)
+
+const (
+	// countTTL is how long a cached execution count is used. Writes of this process invalidate the
+	// cached counts at once; those of other processes sharing the database show within countTTL.
+	countTTL = 10 * time.Second
+	// maxCachedCounts bounds the number of filters counts are cached for.
+	maxCachedCounts = 256
+)
+
+// cachedCount is the number of executions matching a filter, counted at some point before expires.
+type cachedCount struct {
+	expires time.Time
+	total   int64
+}
+
+// countCache caches the number of executions matching filters, so that listing pages of a large
+// history doesn't count every matching execution on each call.
+type countCache struct {
+	mu      sync.Mutex
+	entries map[string]cachedCount
+}
+
+// newCountCache returns an empty count cache.
+func newCountCache() *countCache {
+	return &countCache{entries: make(map[string]cachedCount)}
+}
+
+// get returns the cached count of key, if not expired.
+func (c *countCache) get(key string) (int64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok || time.Now().After(entry.expires) {
+		return 0, false
+	}
+	return entry.total, true
+}
+
+// put caches the count of key, dropping every cached count when the cache is full.
+func (c *countCache) put(key string, total int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.entries) >= maxCachedCounts {
+		clear(c.entries)
+	}
+	c.entries[key] = cachedCount{expires: time.Now().Add(countTTL), total: total}
+}
+
+// invalidate drops every cached count, after executions were added, changed or removed.
+func (c *countCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	clear(c.entries)
+}
+
+// countKey returns the cache key of the count of the executions of the tenant of ctx matching the
+// conditions of filter, leaving out its sort and pagination fields.
+func countKey(ctx context.Context, filter ExecutionFilter) string {
+	name, _ := tenant.FromContext(ctx)
+	success := ""
+	if filter.Success != nil {
+		success = fmt.Sprint(*filter.Success)
+	}
+
+	return fmt.Sprintf("%q|%q|%q|%q|%s|%d|%d|%q|%q|%t", name, filter.ToolName, filter.SessionID, filter.CorrelationID,
+		success, filter.Since.UnixNano(), filter.Until.UnixNano(), filter.Host, filter.Target, filter.Deleted)
+}
@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrCursorNotFound is returned for an ExecutionFilter.AfterID matching no execution of the tenant.
+var ErrCursorNotFound = errors.New("after_id does not match an execution")
+
+// Sort fields accepted by ExecutionFilter.SortBy.
+const (
+	SortByCreatedAt  = "created_at"
+	SortByDurationMs = "duration_ms"
+	SortByID         = "id"
+	SortByRiskScore  = "risk_score"
+	SortByToolName   = "tool_name"
+)
+
+// sortColumns whitelists the columns executions can be sorted by.
+var sortColumns = map[string]struct{}{
+	SortByCreatedAt:  {},
+	SortByDurationMs: {},
+	SortByID:         {},
+	SortByRiskScore:  {},
+	SortByToolName:   {},
+}
+
+// ExecutionFilter selects tool executions. Zero-valued fields are not applied.
+type ExecutionFilter struct {
+	// ToolName matches the tool name exactly.
+	ToolName string
+	// SessionID matches the MCP session ID exactly.
+	SessionID string
+	// CorrelationID matches the correlation ID of a tool call exactly.
+	CorrelationID string
+	// Success, when set, matches successful or failed executions only.
+	Success *bool
+	// Since and Until bound the creation time (inclusive).
+	Since time.Time
+	Until time.Time
+	// Host matches the target host exactly.
+	Host string
+	// Target matches executions whose target URL contains the substring.
+	Target string
+	// Limit and Offset paginate the results.
+	Limit  int
+	Offset int
+	// AfterID, when set, paginates by keyset instead of Offset: the results start after the
+	// execution AfterID in the sort order, typically the last execution of the previous page. Pages
+	// of a large history are read without skipping the executions before them, and executions
+	// added meanwhile do not shift them. It must be an execution of the tenant, deleted or not,
+	// see ErrCursorNotFound.
+	AfterID uint
+	// SortBy is one of the SortBy* columns, defaulting to id: the insertion order, which unlike
+	// created_at cannot go backwards with the server clock or tie between executions created in
+	// the same instant.
+	SortBy string
+	// Ascending sorts oldest/smallest first instead of the default descending order.
+	Ascending bool
+	// Deleted selects soft-deleted executions instead of live ones.
+	Deleted bool
+}
+
+// IsValidSortBy reports whether field can be used as ExecutionFilter.SortBy.
+func IsValidSortBy(field string) bool {
+	_, ok := sortColumns[field]
+	return ok
+}
+
+// FindingFilter selects stored findings. Zero-valued fields are not applied.
+type FindingFilter struct {
+	// Assignee matches the assignee exactly.
+	Assignee string
+	// Unassigned matches findings without an assignee, ignoring Assignee.
+	Unassigned bool
+	// Status matches the triage status, findings without a status counting as open.
+	Status string
+	// Severity matches the normalized severity exactly.
+	Severity string
+	// ExecutionID matches the execution the findings were extracted from.
+	ExecutionID uint
+	// Limit and Offset paginate the results.
+	Limit  int
+	Offset int
+}
@@ -0,0 +1,16 @@
+package storage
+
+import "testing"
+
+func TestIsValidSortBy(t *testing.T) {
+	for _, field := range []string{SortByCreatedAt, SortByDurationMs, SortByID, SortByRiskScore, SortByToolName} {
+		if !IsValidSortBy(field) {
+			t.Errorf("expected %q to be a valid sort field", field)
+		}
+	}
+	for _, field := range []string{"", "input_json", "created_at DESC"} {
+		if IsValidSortBy(field) {
+			t.Errorf("expected %q to be rejected", field)
+		}
+	}
+}
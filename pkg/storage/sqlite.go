@@ -2,25 +2,51 @@ package storage
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
+	"github.com/tb0hdan/wass-mcp/pkg/artifacts"
+	"github.com/tb0hdan/wass-mcp/pkg/limiter"
 	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/tenant"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
 
-const defaultDirPerms = 0o750
+const (
+	defaultDirPerms = 0o750
+
+	interruptedMessage = "execution interrupted by server restart"
+	// jobInterruptedMessage is the error of scan jobs left running by a previous process.
+	jobInterruptedMessage = "scan job interrupted by server restart"
+
+	// fingerprintBatch bounds the fingerprints looked up per query, below SQLite's variable limit.
+	fingerprintBatch = 500
+	// exportBatch is the number of executions read per query by ExportToolExecutions.
+	exportBatch = 200
+)
 
 type SQLiteStorage struct {
-	db *gorm.DB
+	counts      *countCache
+	db          *gorm.DB
+	hardDelete  bool
+	secureErase bool
 }
 
 type Config struct {
 	DatabasePath string
 	Debug        bool
+	// HardDelete makes deleting and clearing executions remove them permanently, like
+	// PurgeDeletedToolExecutions, instead of soft-deleting them, e.g. for retention compliance.
+	HardDelete bool
+	// SecureErase overwrites the artifact files of permanently removed executions before
+	// deleting them, see artifacts.Shred.
+	SecureErase bool
 }
 
 func NewSQLiteStorage(cfg Config) (*SQLiteStorage, error) {
@@ -45,70 +71,852 @@ func NewSQLiteStorage(cfg Config) (*SQLiteStorage, error) {
 	}
 
 	// Auto-migrate schema
-	if err := database.AutoMigrate(&models.ToolExecution{}); err != nil {
+	if err := database.AutoMigrate(&models.ToolExecution{}, &models.Finding{}, &models.TargetGroup{}, &models.ScanTemplate{}, &models.SuppressionRule{}, &models.Credential{}, &models.OutputCursor{}, &models.ScanJob{}, &models.TargetProfile{}); err != nil {
 		return nil, fmt.Errorf("failed to migrate schema: %w", err)
 	}
 
-	return &SQLiteStorage{db: database}, nil
+	return &SQLiteStorage{counts: newCountCache(), db: database, hardDelete: cfg.HardDelete, secureErase: cfg.SecureErase}, nil
+}
+
+// scoped restricts query to the rows of the tenant ctx is scoped to, if any.
+func scoped(ctx context.Context, query *gorm.DB) *gorm.DB {
+	if name, ok := tenant.FromContext(ctx); ok {
+		return query.Where("tenant = ?", name)
+	}
+	return query
 }
 
+// CreateToolExecution stores exec, assigning it to the tenant of ctx unless it already has one.
 func (s *SQLiteStorage) CreateToolExecution(ctx context.Context, exec *models.ToolExecution) error {
+	if name, ok := tenant.FromContext(ctx); ok && exec.Tenant == "" {
+		exec.Tenant = name
+	}
+	defer s.counts.invalidate()
 	return s.db.WithContext(ctx).Create(exec).Error
 }
 
+func (s *SQLiteStorage) UpdateToolExecution(ctx context.Context, exec *models.ToolExecution) error {
+	defer s.counts.invalidate()
+	return s.db.WithContext(ctx).Save(exec).Error
+}
+
+// UpdateToolExecutionPhases stores the phase timings of the execution id, leaving the other fields
+// alone. It returns gorm.ErrRecordNotFound when there is no such execution.
+func (s *SQLiteStorage) UpdateToolExecutionPhases(ctx context.Context, id uint, phases models.PhaseTimings) error {
+	result := s.db.WithContext(ctx).Model(&models.ToolExecution{}).
+		Where("id = ?", id).
+		Select("phases").
+		Updates(&models.ToolExecution{Phases: phases})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// UpdateToolExecutionStream stores the output stream file of the execution id, leaving the other
+// fields alone. It returns gorm.ErrRecordNotFound when there is no such execution.
+func (s *SQLiteStorage) UpdateToolExecutionStream(ctx context.Context, id uint, path string) error {
+	result := s.db.WithContext(ctx).Model(&models.ToolExecution{}).
+		Where("id = ?", id).
+		Update("stream_file", path)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// MarkInterruptedExecutions marks executions still running, i.e. left behind by a previous process,
+// as interrupted and returns them.
+func (s *SQLiteStorage) MarkInterruptedExecutions(ctx context.Context) ([]models.ToolExecution, error) {
+	var executions []models.ToolExecution
+	defer s.counts.invalidate()
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := scoped(ctx, tx).Where("status = ?", models.StatusRunning).Order("id ASC").Find(&executions).Error; err != nil {
+			return err
+		}
+		if len(executions) == 0 {
+			return nil
+		}
+		return scoped(ctx, tx.Model(&models.ToolExecution{})).
+			Where("status = ?", models.StatusRunning).
+			Updates(map[string]any{
+				"status":        models.StatusInterrupted,
+				"error_message": interruptedMessage,
+			}).Error
+	})
+	for i := range executions {
+		executions[i].Status = models.StatusInterrupted
+		executions[i].ErrorMessage = interruptedMessage
+	}
+	return executions, err
+}
+
 func (s *SQLiteStorage) GetToolExecution(ctx context.Context, id uint) (*models.ToolExecution, error) {
 	var exec models.ToolExecution
-	err := s.db.WithContext(ctx).First(&exec, id).Error
+	err := scoped(ctx, s.db.WithContext(ctx)).First(&exec, id).Error
 	if err != nil {
 		return nil, err
 	}
 	return &exec, nil
 }
 
+// GetToolExecutions returns a page of the executions, newest first by insertion order (see
+// ExecutionFilter.SortBy), and the total number of executions.
 func (s *SQLiteStorage) GetToolExecutions(ctx context.Context, limit, offset int) ([]models.ToolExecution, int64, error) {
+	return s.QueryToolExecutions(ctx, ExecutionFilter{Limit: limit, Offset: offset})
+}
+
+func (s *SQLiteStorage) GetToolExecutionsBySession(ctx context.Context, sessionID string) ([]models.ToolExecution, error) {
+	executions, _, err := s.QueryToolExecutions(ctx, ExecutionFilter{SessionID: sessionID})
+	return executions, err
+}
+
+func (s *SQLiteStorage) GetToolExecutionsByTool(ctx context.Context, toolName string, limit int) ([]models.ToolExecution, error) {
+	executions, _, err := s.QueryToolExecutions(ctx, ExecutionFilter{ToolName: toolName, Limit: limit})
+	return executions, err
+}
+
+func (s *SQLiteStorage) GetToolExecutionsByHost(ctx context.Context, host string, limit int) ([]models.ToolExecution, error) {
+	executions, _, err := s.QueryToolExecutions(ctx, ExecutionFilter{Host: host, Limit: limit})
+	return executions, err
+}
+
+// QueryToolExecutions returns the executions matching filter and the total number of matches
+// before pagination. Totals are cached per filter, see countCache.
+func (s *SQLiteStorage) QueryToolExecutions(ctx context.Context, filter ExecutionFilter) ([]models.ToolExecution, int64, error) {
 	var executions []models.ToolExecution
-	var total int64
 
-	s.db.WithContext(ctx).Model(&models.ToolExecution{}).Count(&total)
+	total, err := s.countExecutions(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
 
-	query := s.db.WithContext(ctx).Order("created_at DESC")
-	if limit > 0 {
-		query = query.Limit(limit)
+	sortBy := SortByID
+	if IsValidSortBy(filter.SortBy) {
+		sortBy = filter.SortBy
+	}
+	direction, after := " DESC", "<"
+	if filter.Ascending {
+		direction, after = " ASC", ">"
+	}
+	if filter.AfterID > 0 {
+		var found int64
+		if err := s.cursorExecution(ctx, filter.AfterID).Count(&found).Error; err != nil {
+			return nil, 0, err
+		}
+		if found == 0 {
+			return nil, 0, fmt.Errorf("%w: %d", ErrCursorNotFound, filter.AfterID)
+		}
+	}
+	query := s.filterExecutions(ctx, filter)
+	switch {
+	case filter.AfterID > 0 && sortBy == SortByID:
+		query = query.Where("id "+after+" ?", filter.AfterID)
+	case filter.AfterID > 0:
+		// The sort key of the execution AfterID bounds the page.
+		query = query.Where(fmt.Sprintf("(%s, id) %s (?)", sortBy, after), s.cursorExecution(ctx, filter.AfterID).Select(sortBy+", id"))
+	case filter.Offset > 0:
+		query = query.Offset(filter.Offset)
 	}
-	if offset > 0 {
-		query = query.Offset(offset)
+	query = query.Order(sortBy + direction)
+	if sortBy != SortByID {
+		// Keep results stable for equal sort keys.
+		query = query.Order(SortByID + direction)
+	}
+
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
 	}
-	err := query.Find(&executions).Error
+	err = query.Find(&executions).Error
 	return executions, total, err
 }
 
-func (s *SQLiteStorage) GetToolExecutionsBySession(ctx context.Context, sessionID string) ([]models.ToolExecution, error) {
-	var executions []models.ToolExecution
-	err := s.db.WithContext(ctx).
-		Where("session_id = ?", sessionID).
-		Order("created_at DESC").
-		Find(&executions).Error
-	return executions, err
+// cursorExecution returns the query of the execution id of the tenant of ctx, deleted or not, that
+// keyset pages start after.
+func (s *SQLiteStorage) cursorExecution(ctx context.Context, id uint) *gorm.DB {
+	return scoped(ctx, s.db.WithContext(ctx).Unscoped().Model(&models.ToolExecution{})).Where("id = ?", id)
 }
 
-func (s *SQLiteStorage) GetToolExecutionsByTool(ctx context.Context, toolName string, limit int) ([]models.ToolExecution, error) {
-	var executions []models.ToolExecution
-	query := s.db.WithContext(ctx).
-		Where("tool_name = ?", toolName).
-		Order("created_at DESC")
-	if limit > 0 {
-		query = query.Limit(limit)
+// countExecutions returns the number of executions matching the conditions of filter, cached
+// until executions are written or for countTTL.
+func (s *SQLiteStorage) countExecutions(ctx context.Context, filter ExecutionFilter) (int64, error) {
+	key := countKey(ctx, filter)
+	if total, ok := s.counts.get(key); ok {
+		return total, nil
+	}
+
+	var total int64
+	if err := s.filterExecutions(ctx, filter).Count(&total).Error; err != nil {
+		return 0, err
+	}
+	s.counts.put(key, total)
+	return total, nil
+}
+
+// ExportToolExecutions calls fn with each execution matching filter, oldest first, stopping at the
+// first error. Executions are read exportBatch at a time, each batch starting after the ID of the
+// last execution read, so that memory stays bounded and executions added meanwhile do not shift
+// the batches. The sort and pagination fields of filter are ignored.
+func (s *SQLiteStorage) ExportToolExecutions(ctx context.Context, filter ExecutionFilter, fn func(*models.ToolExecution) error) error {
+	var lastID uint
+	for {
+		var batch []models.ToolExecution
+		err := s.filterExecutions(ctx, filter).Where("id > ?", lastID).Order("id ASC").Limit(exportBatch).Find(&batch).Error
+		if err != nil {
+			return err
+		}
+		for i := range batch {
+			if err := fn(&batch[i]); err != nil {
+				return err
+			}
+		}
+		if len(batch) < exportBatch {
+			return nil
+		}
+		lastID = batch[len(batch)-1].ID
 	}
-	err := query.Find(&executions).Error
-	return executions, err
 }
 
+// filterExecutions returns the query of the executions matching the conditions of filter.
+func (s *SQLiteStorage) filterExecutions(ctx context.Context, filter ExecutionFilter) *gorm.DB {
+	query := scoped(ctx, s.db.WithContext(ctx).Model(&models.ToolExecution{}))
+	if filter.Deleted {
+		query = query.Unscoped().Where("deleted_at IS NOT NULL")
+	}
+	if filter.ToolName != "" {
+		query = query.Where("tool_name = ?", filter.ToolName)
+	}
+	if filter.SessionID != "" {
+		query = query.Where("session_id = ?", filter.SessionID)
+	}
+	if filter.CorrelationID != "" {
+		query = query.Where("correlation_id = ?", filter.CorrelationID)
+	}
+	if filter.Success != nil {
+		query = query.Where("success = ?", *filter.Success)
+	}
+	if !filter.Since.IsZero() {
+		query = query.Where("created_at >= ?", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		query = query.Where("created_at <= ?", filter.Until)
+	}
+	if filter.Host != "" {
+		query = query.Where("host = ?", filter.Host)
+	}
+	if filter.Target != "" {
+		query = query.Where("target LIKE ?", "%"+filter.Target+"%")
+	}
+	return query
+}
+
+// DeleteToolExecution soft-deletes an execution, or removes it permanently with its findings and
+// artifacts when hard deletes are configured.
 func (s *SQLiteStorage) DeleteToolExecution(ctx context.Context, id uint) error {
-	return s.db.WithContext(ctx).Delete(&models.ToolExecution{}, id).Error
+	defer s.counts.invalidate()
+	if s.hardDelete {
+		_, err := s.removeExecutions(ctx, "id = ?", id)
+		return err
+	}
+	return scoped(ctx, s.db.WithContext(ctx)).Delete(&models.ToolExecution{}, id).Error
 }
 
+// DeleteAllToolExecutions soft-deletes all executions, or removes them permanently with their
+// findings and artifacts when hard deletes are configured.
 func (s *SQLiteStorage) DeleteAllToolExecutions(ctx context.Context) error {
-	return s.db.WithContext(ctx).Where("1 = 1").Delete(&models.ToolExecution{}).Error
+	defer s.counts.invalidate()
+	if s.hardDelete {
+		_, err := s.removeExecutions(ctx, "1 = 1")
+		return err
+	}
+	return scoped(ctx, s.db.WithContext(ctx)).Where("1 = 1").Delete(&models.ToolExecution{}).Error
+}
+
+// RestoreToolExecution undeletes a soft-deleted execution.
+// It returns gorm.ErrRecordNotFound when no deleted execution has the given ID.
+func (s *SQLiteStorage) RestoreToolExecution(ctx context.Context, id uint) error {
+	defer s.counts.invalidate()
+	result := scoped(ctx, s.db.WithContext(ctx).Unscoped().Model(&models.ToolExecution{})).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// PurgeDeletedToolExecutions permanently removes all soft-deleted executions, their findings
+// and their output and capture artifact files. It returns the number of purged executions.
+func (s *SQLiteStorage) PurgeDeletedToolExecutions(ctx context.Context) (int64, error) {
+	return s.removeExecutions(ctx, "deleted_at IS NOT NULL")
+}
+
+// PruneToolExecutions permanently removes executions created before the given time, deleted or
+// not, together with their findings and output and capture artifact files. Running executions are kept.
+// It returns the number of pruned executions.
+func (s *SQLiteStorage) PruneToolExecutions(ctx context.Context, before time.Time) (int64, error) {
+	return s.removeExecutions(ctx, "created_at < ? AND status <> ?", before, models.StatusRunning)
+}
+
+// removeExecutions permanently removes the executions matching the condition, including
+// soft-deleted ones, together with their findings, output cursors and output, stream and capture
+// artifact files, shredded when secure erasure is configured.
+func (s *SQLiteStorage) removeExecutions(ctx context.Context, condition string, args ...any) (int64, error) {
+	defer s.counts.invalidate()
+	var removed int64
+	var files, streams, captures []string
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		matching := scoped(ctx, tx.Unscoped().Model(&models.ToolExecution{})).Where(condition, args...).Session(&gorm.Session{})
+		if err := matching.Where("output_file <> ''").Pluck("output_file", &files).Error; err != nil {
+			return err
+		}
+		if err := matching.Where("stream_file <> ''").Pluck("stream_file", &streams).Error; err != nil {
+			return err
+		}
+		if err := matching.Where("capture_files IS NOT NULL AND capture_files <> 'null'").Pluck("capture_files", &captures).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("execution_id IN (?)", matching.Select("id")).Delete(&models.Finding{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("execution_id IN (?)", matching.Select("id")).Delete(&models.OutputCursor{}).Error; err != nil {
+			return err
+		}
+		result := scoped(ctx, tx.Unscoped()).Where(condition, args...).Delete(&models.ToolExecution{})
+		removed = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	files = append(files, streams...)
+	for _, list := range captures {
+		var paths []string
+		if json.Unmarshal([]byte(list), &paths) == nil {
+			files = append(files, paths...)
+		}
+	}
+	if s.secureErase {
+		return removed, artifacts.Shred(files...)
+	}
+	return removed, artifacts.Remove(files...)
+}
+
+// CreateFindings stores findings, assigning those without a tenant to the tenant of ctx.
+func (s *SQLiteStorage) CreateFindings(ctx context.Context, findings []models.Finding) error {
+	if len(findings) == 0 {
+		return nil
+	}
+	if name, ok := tenant.FromContext(ctx); ok {
+		for i := range findings {
+			if findings[i].Tenant == "" {
+				findings[i].Tenant = name
+			}
+		}
+	}
+	return s.db.WithContext(ctx).Create(&findings).Error
+}
+
+func (s *SQLiteStorage) GetFindingsByExecutions(ctx context.Context, executionIDs []uint) ([]models.Finding, error) {
+	var findings []models.Finding
+	if len(executionIDs) == 0 {
+		return findings, nil
+	}
+	err := scoped(ctx, s.db.WithContext(ctx)).
+		Where("execution_id IN ?", executionIDs).
+		Order("id ASC").
+		Find(&findings).Error
+	return findings, err
+}
+
+// QueryFindings returns the findings matching filter, newest first, and the total number of
+// matches before pagination.
+func (s *SQLiteStorage) QueryFindings(ctx context.Context, filter FindingFilter) ([]models.Finding, int64, error) {
+	var findings []models.Finding
+	var total int64
+
+	query := scoped(ctx, s.db.WithContext(ctx).Model(&models.Finding{}))
+	switch {
+	case filter.Unassigned:
+		query = query.Where("assignee = '' OR assignee IS NULL")
+	case filter.Assignee != "":
+		query = query.Where("assignee = ?", filter.Assignee)
+	}
+	if filter.Status == models.FindingOpen {
+		query = query.Where("status = ? OR status = '' OR status IS NULL", filter.Status)
+	} else if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Severity != "" {
+		query = query.Where("severity = ?", filter.Severity)
+	}
+	if filter.ExecutionID != 0 {
+		query = query.Where("execution_id = ?", filter.ExecutionID)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	query = query.Order("id DESC")
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		query = query.Offset(filter.Offset)
+	}
+
+	err := query.Find(&findings).Error
+	return findings, total, err
+}
+
+// GetFinding returns the finding with the given ID. It returns gorm.ErrRecordNotFound when there is none.
+func (s *SQLiteStorage) GetFinding(ctx context.Context, id uint) (*models.Finding, error) {
+	var finding models.Finding
+	err := scoped(ctx, s.db.WithContext(ctx)).First(&finding, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &finding, nil
+}
+
+// UpdateFindingTriage stores the assignee and triage status of finding, leaving the scanner-provided
+// fields alone. It returns gorm.ErrRecordNotFound when there is no such finding.
+func (s *SQLiteStorage) UpdateFindingTriage(ctx context.Context, finding *models.Finding) error {
+	finding.UpdatedAt = time.Now()
+	result := scoped(ctx, s.db.WithContext(ctx).Model(&models.Finding{})).
+		Where("id = ?", finding.ID).
+		Updates(map[string]any{
+			"assignee":   finding.Assignee,
+			"status":     finding.Status,
+			"updated_at": finding.UpdatedAt,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// UpdateFindingEvidence stores the evidence of finding, leaving the other fields alone. It returns
+// gorm.ErrRecordNotFound when there is no such finding.
+func (s *SQLiteStorage) UpdateFindingEvidence(ctx context.Context, finding *models.Finding) error {
+	finding.UpdatedAt = time.Now()
+	result := scoped(ctx, s.db.WithContext(ctx).Model(&models.Finding{})).
+		Where("id = ?", finding.ID).
+		Select("evidence", "updated_at").
+		Updates(finding)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// SeenFingerprints returns which of fingerprints stored findings have, leaving out the findings of
+// the execution excludeExecutionID, such as the execution reporting them.
+func (s *SQLiteStorage) SeenFingerprints(ctx context.Context, fingerprints []string, excludeExecutionID uint) (map[string]bool, error) {
+	seen := make(map[string]bool, len(fingerprints))
+	for start := 0; start < len(fingerprints); start += fingerprintBatch {
+		batch := fingerprints[start:min(start+fingerprintBatch, len(fingerprints))]
+		var found []string
+		err := scoped(ctx, s.db.WithContext(ctx).Model(&models.Finding{})).
+			Where("fingerprint IN ? AND execution_id <> ?", batch, excludeExecutionID).
+			Distinct().Pluck("fingerprint", &found).Error
+		if err != nil {
+			return nil, err
+		}
+		for _, fingerprint := range found {
+			seen[fingerprint] = true
+		}
+	}
+	return seen, nil
+}
+
+// SaveTargetGroup creates group, or replaces the description and hosts of the group of the same
+// name, in the tenant of ctx.
+func (s *SQLiteStorage) SaveTargetGroup(ctx context.Context, group *models.TargetGroup) error {
+	if name, ok := tenant.FromContext(ctx); ok {
+		group.Tenant = name
+	}
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing models.TargetGroup
+		err := tx.Where("tenant = ? AND name = ?", group.Tenant, group.Name).First(&existing).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return tx.Create(group).Error
+		case err != nil:
+			return err
+		}
+		group.ID = existing.ID
+		group.CreatedAt = existing.CreatedAt
+		return tx.Save(group).Error
+	})
+}
+
+// GetTargetGroup returns the group named name. It returns gorm.ErrRecordNotFound when there is none.
+func (s *SQLiteStorage) GetTargetGroup(ctx context.Context, name string) (*models.TargetGroup, error) {
+	var group models.TargetGroup
+	err := scoped(ctx, s.db.WithContext(ctx)).Where("name = ?", name).First(&group).Error
+	if err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+// ListTargetGroups returns the target groups ordered by name.
+func (s *SQLiteStorage) ListTargetGroups(ctx context.Context) ([]models.TargetGroup, error) {
+	var groups []models.TargetGroup
+	err := scoped(ctx, s.db.WithContext(ctx)).Order("name ASC").Find(&groups).Error
+	return groups, err
+}
+
+// DeleteTargetGroup removes the group named name. It returns gorm.ErrRecordNotFound when there is none.
+func (s *SQLiteStorage) DeleteTargetGroup(ctx context.Context, name string) error {
+	result := scoped(ctx, s.db.WithContext(ctx)).Where("name = ?", name).Delete(&models.TargetGroup{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// SaveTargetProfile creates profile, or replaces the profile of the same target, assigning it to
+// the tenant of ctx unless it already has one.
+func (s *SQLiteStorage) SaveTargetProfile(ctx context.Context, profile *models.TargetProfile) error {
+	if name, ok := tenant.FromContext(ctx); ok && profile.Tenant == "" {
+		profile.Tenant = name
+	}
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing models.TargetProfile
+		err := tx.Where("tenant = ? AND target = ?", profile.Tenant, profile.Target).First(&existing).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return tx.Create(profile).Error
+		case err != nil:
+			return err
+		}
+		profile.ID = existing.ID
+		profile.CreatedAt = existing.CreatedAt
+		return tx.Save(profile).Error
+	})
+}
+
+// GetTargetProfiles returns the profiles of the targets of host, or of every target when host is
+// empty, ordered by target.
+func (s *SQLiteStorage) GetTargetProfiles(ctx context.Context, host string) ([]models.TargetProfile, error) {
+	query := scoped(ctx, s.db.WithContext(ctx))
+	if host != "" {
+		query = query.Where("host = ?", host)
+	}
+	var profiles []models.TargetProfile
+	err := query.Order("target ASC").Find(&profiles).Error
+	return profiles, err
+}
+
+// SaveScanTemplate creates template, or replaces the template of the same name, in the tenant of ctx.
+func (s *SQLiteStorage) SaveScanTemplate(ctx context.Context, template *models.ScanTemplate) error {
+	if name, ok := tenant.FromContext(ctx); ok {
+		template.Tenant = name
+	}
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing models.ScanTemplate
+		err := tx.Where("tenant = ? AND name = ?", template.Tenant, template.Name).First(&existing).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return tx.Create(template).Error
+		case err != nil:
+			return err
+		}
+		template.ID = existing.ID
+		template.CreatedAt = existing.CreatedAt
+		return tx.Save(template).Error
+	})
+}
+
+// GetScanTemplate returns the template named name. It returns gorm.ErrRecordNotFound when there is none.
+func (s *SQLiteStorage) GetScanTemplate(ctx context.Context, name string) (*models.ScanTemplate, error) {
+	var template models.ScanTemplate
+	err := scoped(ctx, s.db.WithContext(ctx)).Where("name = ?", name).First(&template).Error
+	if err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+// ListScanTemplates returns the scan templates ordered by name.
+func (s *SQLiteStorage) ListScanTemplates(ctx context.Context) ([]models.ScanTemplate, error) {
+	var templates []models.ScanTemplate
+	err := scoped(ctx, s.db.WithContext(ctx)).Order("name ASC").Find(&templates).Error
+	return templates, err
+}
+
+// DeleteScanTemplate removes the template named name. It returns gorm.ErrRecordNotFound when there is none.
+func (s *SQLiteStorage) DeleteScanTemplate(ctx context.Context, name string) error {
+	result := scoped(ctx, s.db.WithContext(ctx)).Where("name = ?", name).Delete(&models.ScanTemplate{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// SaveSuppressionRule creates rule, or replaces the criteria and reason of the rule of the same
+// name of its tenant.
+func (s *SQLiteStorage) SaveSuppressionRule(ctx context.Context, rule *models.SuppressionRule) error {
+	if name, ok := tenant.FromContext(ctx); ok {
+		rule.Tenant = name
+	}
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing models.SuppressionRule
+		err := tx.Where("tenant = ? AND name = ?", rule.Tenant, rule.Name).First(&existing).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return tx.Create(rule).Error
+		case err != nil:
+			return err
+		}
+		rule.ID = existing.ID
+		rule.CreatedAt = existing.CreatedAt
+		return tx.Save(rule).Error
+	})
+}
+
+// GetSuppressionRule returns the rule named name. It returns gorm.ErrRecordNotFound when there is none.
+func (s *SQLiteStorage) GetSuppressionRule(ctx context.Context, name string) (*models.SuppressionRule, error) {
+	var rule models.SuppressionRule
+	err := scoped(ctx, s.db.WithContext(ctx)).Where("name = ?", name).First(&rule).Error
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// ListSuppressionRules returns the suppression rules ordered by name.
+func (s *SQLiteStorage) ListSuppressionRules(ctx context.Context) ([]models.SuppressionRule, error) {
+	var rules []models.SuppressionRule
+	err := scoped(ctx, s.db.WithContext(ctx)).Order("name ASC").Find(&rules).Error
+	return rules, err
+}
+
+// DeleteSuppressionRule removes the rule named name. It returns gorm.ErrRecordNotFound when there is none.
+func (s *SQLiteStorage) DeleteSuppressionRule(ctx context.Context, name string) error {
+	result := scoped(ctx, s.db.WithContext(ctx)).Where("name = ?", name).Delete(&models.SuppressionRule{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// SaveCredential creates credential, or replaces the type, description and secret of the
+// credential of the same name of its tenant.
+func (s *SQLiteStorage) SaveCredential(ctx context.Context, credential *models.Credential) error {
+	if name, ok := tenant.FromContext(ctx); ok {
+		credential.Tenant = name
+	}
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing models.Credential
+		err := tx.Where("tenant = ? AND name = ?", credential.Tenant, credential.Name).First(&existing).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return tx.Create(credential).Error
+		case err != nil:
+			return err
+		}
+		credential.ID = existing.ID
+		credential.CreatedAt = existing.CreatedAt
+		return tx.Save(credential).Error
+	})
+}
+
+// GetCredential returns the credential named name. It returns gorm.ErrRecordNotFound when there is none.
+func (s *SQLiteStorage) GetCredential(ctx context.Context, name string) (*models.Credential, error) {
+	var credential models.Credential
+	err := scoped(ctx, s.db.WithContext(ctx)).Where("name = ?", name).First(&credential).Error
+	if err != nil {
+		return nil, err
+	}
+	return &credential, nil
+}
+
+// ListCredentials returns the credentials ordered by name, without their secrets.
+func (s *SQLiteStorage) ListCredentials(ctx context.Context) ([]models.Credential, error) {
+	var credentials []models.Credential
+	err := scoped(ctx, s.db.WithContext(ctx)).Omit("secret").Order("name ASC").Find(&credentials).Error
+	return credentials, err
+}
+
+// DeleteCredential removes the credential named name. It returns gorm.ErrRecordNotFound when there is none.
+func (s *SQLiteStorage) DeleteCredential(ctx context.Context, name string) error {
+	result := scoped(ctx, s.db.WithContext(ctx)).Where("name = ?", name).Delete(&models.Credential{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// CreateOutputCursor stores cursor under the tenant of ctx.
+func (s *SQLiteStorage) CreateOutputCursor(ctx context.Context, cursor *models.OutputCursor) error {
+	if name, ok := tenant.FromContext(ctx); ok {
+		cursor.Tenant = name
+	}
+	return s.db.WithContext(ctx).Create(cursor).Error
+}
+
+// GetOutputCursor returns the output cursor of token. It returns gorm.ErrRecordNotFound when there is none.
+func (s *SQLiteStorage) GetOutputCursor(ctx context.Context, token string) (*models.OutputCursor, error) {
+	var cursor models.OutputCursor
+	err := scoped(ctx, s.db.WithContext(ctx)).Where("token = ?", token).First(&cursor).Error
+	if err != nil {
+		return nil, err
+	}
+	return &cursor, nil
+}
+
+// CreateScanJob stores job, assigning it to the tenant of ctx unless it already has one.
+func (s *SQLiteStorage) CreateScanJob(ctx context.Context, job *models.ScanJob) error {
+	if name, ok := tenant.FromContext(ctx); ok && job.Tenant == "" {
+		job.Tenant = name
+	}
+	return s.db.WithContext(ctx).Create(job).Error
+}
+
+func (s *SQLiteStorage) UpdateScanJob(ctx context.Context, job *models.ScanJob) error {
+	return s.db.WithContext(ctx).Save(job).Error
+}
+
+// GetScanJob returns the scan job id. It returns gorm.ErrRecordNotFound when there is none.
+func (s *SQLiteStorage) GetScanJob(ctx context.Context, id uint) (*models.ScanJob, error) {
+	var job models.ScanJob
+	err := scoped(ctx, s.db.WithContext(ctx)).First(&job, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// queuedJobOrder orders queued scan jobs by priority rank, then oldest first.
+const queuedJobOrder = "CASE priority WHEN '" + limiter.PriorityHigh + "' THEN 0 WHEN '" + limiter.PriorityLow + "' THEN 2 ELSE 1 END, id ASC"
+
+// GetQueuedScanJobs returns the scan jobs waiting to start, high priority first, then normal and
+// low priority, each oldest first. Unknown priorities rank as normal.
+func (s *SQLiteStorage) GetQueuedScanJobs(ctx context.Context) ([]models.ScanJob, error) {
+	var jobs []models.ScanJob
+	err := scoped(ctx, s.db.WithContext(ctx)).Where("status = ?", models.JobQueued).
+		Order(queuedJobOrder).Find(&jobs).Error
+	return jobs, err
+}
+
+// ClaimScanJob marks the queued scan job id running on worker and returns it. Only one of the
+// workers claiming the same job succeeds; the others get gorm.ErrRecordNotFound, as for jobs that
+// are no longer queued, e.g. cancelled ones.
+func (s *SQLiteStorage) ClaimScanJob(ctx context.Context, id uint, worker string) (*models.ScanJob, error) {
+	var job models.ScanJob
+	now := time.Now()
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		claimed := scoped(ctx, tx.Model(&models.ScanJob{})).
+			Where("id = ? AND status = ?", id, models.JobQueued).
+			Updates(map[string]any{
+				"status":     models.JobRunning,
+				"started_at": now,
+				"worker":     worker,
+			})
+		if claimed.Error != nil {
+			return claimed.Error
+		}
+		if claimed.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return tx.First(&job, id).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// MarkInterruptedScanJobs marks scan jobs still running on worker, i.e. left behind by its
+// previous process, as interrupted and returns them. Jobs claimed before workers were recorded
+// are marked by any worker.
+func (s *SQLiteStorage) MarkInterruptedScanJobs(ctx context.Context, worker string) ([]models.ScanJob, error) {
+	var jobs []models.ScanJob
+	now := time.Now()
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		left := func(query *gorm.DB) *gorm.DB {
+			return scoped(ctx, query).Where("status = ? AND (worker = ? OR worker = '' OR worker IS NULL)", models.JobRunning, worker)
+		}
+		if err := left(tx).Order("id ASC").Find(&jobs).Error; err != nil {
+			return err
+		}
+		if len(jobs) == 0 {
+			return nil
+		}
+		return left(tx.Model(&models.ScanJob{})).
+			Updates(map[string]any{
+				"status":      models.JobInterrupted,
+				"error":       jobInterruptedMessage,
+				"finished_at": now,
+			}).Error
+	})
+	for i := range jobs {
+		jobs[i].Status = models.JobInterrupted
+		jobs[i].Error = jobInterruptedMessage
+		jobs[i].FinishedAt = &now
+	}
+	return jobs, err
+}
+
+// RewrapCredentials replaces the secret of every credential with the result of rewrap when it
+// reports a change, in one transaction, and returns the number of credentials changed.
+func (s *SQLiteStorage) RewrapCredentials(ctx context.Context, rewrap func(secret []byte) ([]byte, bool, error)) (int, error) {
+	changed := 0
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var credentials []models.Credential
+		if err := scoped(ctx, tx).Find(&credentials).Error; err != nil {
+			return err
+		}
+		for _, credential := range credentials {
+			secret, ok, err := rewrap(credential.Secret)
+			if err != nil {
+				return fmt.Errorf("credential %s: %w", credential.Name, err)
+			}
+			if !ok {
+				continue
+			}
+			if err := tx.Model(&models.Credential{}).Where("id = ?", credential.ID).Update("secret", secret).Error; err != nil {
+				return err
+			}
+			changed++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return changed, nil
 }
 
 func (s *SQLiteStorage) Close() error {
@@ -2,11 +2,17 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/tenant"
+	"gorm.io/gorm"
 )
 
 func setupTestDB(t *testing.T) (*SQLiteStorage, func()) {
@@ -89,6 +95,136 @@ func TestCreateToolExecution(t *testing.T) {
 	}
 }
 
+func TestUpdateToolExecution(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	exec := &models.ToolExecution{ToolName: "nikto", Status: models.StatusRunning}
+	if err := store.CreateToolExecution(ctx, exec); err != nil {
+		t.Fatalf("failed to create execution: %v", err)
+	}
+
+	exec.Status = models.StatusCompleted
+	exec.Success = true
+	exec.OutputJSON = `{"content":[]}`
+	if err := store.UpdateToolExecution(ctx, exec); err != nil {
+		t.Fatalf("failed to update execution: %v", err)
+	}
+
+	retrieved, err := store.GetToolExecution(ctx, exec.ID)
+	if err != nil {
+		t.Fatalf("failed to get execution: %v", err)
+	}
+	if retrieved.Status != models.StatusCompleted || !retrieved.Success || retrieved.OutputJSON != `{"content":[]}` {
+		t.Errorf("expected updated execution, got status=%s success=%v output=%s", retrieved.Status, retrieved.Success, retrieved.OutputJSON)
+	}
+}
+
+func TestUpdateToolExecutionPhases(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	exec := &models.ToolExecution{ToolName: "nikto", Status: models.StatusCompleted, OutputJSON: "output"}
+	if err := store.CreateToolExecution(ctx, exec); err != nil {
+		t.Fatalf("failed to create execution: %v", err)
+	}
+
+	phases := models.PhaseTimings{QueueWaitMs: 10, ExecMs: 2000, PersistMs: 5}
+	if err := store.UpdateToolExecutionPhases(ctx, exec.ID, phases); err != nil {
+		t.Fatalf("failed to update phases: %v", err)
+	}
+
+	retrieved, err := store.GetToolExecution(ctx, exec.ID)
+	if err != nil {
+		t.Fatalf("failed to get execution: %v", err)
+	}
+	if retrieved.Phases != phases {
+		t.Errorf("expected phases %+v, got %+v", phases, retrieved.Phases)
+	}
+	if retrieved.OutputJSON != "output" || retrieved.Status != models.StatusCompleted {
+		t.Errorf("expected other fields unchanged, got status=%s output=%s", retrieved.Status, retrieved.OutputJSON)
+	}
+
+	if err := store.UpdateToolExecutionPhases(ctx, 99999, phases); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("expected ErrRecordNotFound, got %v", err)
+	}
+}
+
+func TestUpdateToolExecutionStream(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	exec := &models.ToolExecution{ToolName: "nuclei", Status: models.StatusRunning, InputJSON: `{"host":"example.com"}`}
+	if err := store.CreateToolExecution(ctx, exec); err != nil {
+		t.Fatalf("failed to create execution: %v", err)
+	}
+
+	if err := store.UpdateToolExecutionStream(ctx, exec.ID, "/artifacts/nuclei-1.log"); err != nil {
+		t.Fatalf("failed to update stream file: %v", err)
+	}
+
+	retrieved, err := store.GetToolExecution(ctx, exec.ID)
+	if err != nil {
+		t.Fatalf("failed to get execution: %v", err)
+	}
+	if retrieved.StreamFile != "/artifacts/nuclei-1.log" {
+		t.Errorf("expected stream file to be stored, got %q", retrieved.StreamFile)
+	}
+	if retrieved.Status != models.StatusRunning || retrieved.InputJSON != exec.InputJSON {
+		t.Errorf("expected other fields unchanged, got status=%s input=%s", retrieved.Status, retrieved.InputJSON)
+	}
+
+	if err := store.UpdateToolExecutionStream(ctx, 99999, "/artifacts/x.log"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("expected ErrRecordNotFound, got %v", err)
+	}
+}
+
+func TestMarkInterruptedExecutions(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	statuses := []string{models.StatusRunning, models.StatusCompleted, models.StatusRunning, ""}
+	for _, status := range statuses {
+		exec := &models.ToolExecution{ToolName: "nikto", Status: status}
+		if err := store.CreateToolExecution(ctx, exec); err != nil {
+			t.Fatalf("failed to create execution: %v", err)
+		}
+	}
+
+	interrupted, err := store.MarkInterruptedExecutions(ctx)
+	if err != nil {
+		t.Fatalf("failed to mark interrupted executions: %v", err)
+	}
+	if len(interrupted) != 2 || interrupted[0].ID != 1 || interrupted[1].ID != 3 {
+		t.Fatalf("expected executions 1 and 3 to be interrupted, got %+v", interrupted)
+	}
+	if interrupted[0].Status != models.StatusInterrupted {
+		t.Errorf("expected returned executions to be marked interrupted, got %s", interrupted[0].Status)
+	}
+
+	retrieved, err := store.GetToolExecution(ctx, 3)
+	if err != nil {
+		t.Fatalf("failed to get execution: %v", err)
+	}
+	if retrieved.Status != models.StatusInterrupted || retrieved.ErrorMessage == "" {
+		t.Errorf("expected stored execution to be interrupted with a message, got %s %q", retrieved.Status, retrieved.ErrorMessage)
+	}
+
+	// Nothing is left running
+	interrupted, err = store.MarkInterruptedExecutions(ctx)
+	if err != nil || len(interrupted) != 0 {
+		t.Errorf("expected no further interrupted executions, got %d (err: %v)", len(interrupted), err)
+	}
+}
+
 func TestGetToolExecution(t *testing.T) {
 	store, cleanup := setupTestDB(t)
 	defer cleanup()
@@ -274,105 +410,1463 @@ func TestGetToolExecutionsByTool(t *testing.T) {
 	}
 }
 
-func TestDeleteToolExecution(t *testing.T) {
+func TestGetToolExecutionsByHost(t *testing.T) {
 	store, cleanup := setupTestDB(t)
 	defer cleanup()
 
 	ctx := context.Background()
 
-	// Create execution
-	exec := &models.ToolExecution{
-		ToolName: "nikto",
-		Success:  true,
+	hosts := []string{"example.com", "example.com", "www.example.com", "other.org"}
+	for _, host := range hosts {
+		exec := &models.ToolExecution{
+			ToolName: "nikto",
+			Host:     host,
+		}
+		if err := store.CreateToolExecution(ctx, exec); err != nil {
+			t.Fatalf("failed to create execution: %v", err)
+		}
 	}
-	if err := store.CreateToolExecution(ctx, exec); err != nil {
-		t.Fatalf("failed to create execution: %v", err)
+
+	executions, err := store.GetToolExecutionsByHost(ctx, "example.com", 0)
+	if err != nil {
+		t.Fatalf("failed to get executions by host: %v", err)
+	}
+	if len(executions) != 2 {
+		t.Errorf("expected 2 example.com executions, got %d", len(executions))
 	}
 
-	// Delete it
-	if err := store.DeleteToolExecution(ctx, exec.ID); err != nil {
-		t.Fatalf("failed to delete execution: %v", err)
+	executions, err = store.GetToolExecutionsByHost(ctx, "example.com", 1)
+	if err != nil {
+		t.Fatalf("failed to get executions by host with limit: %v", err)
 	}
+	if len(executions) != 1 {
+		t.Errorf("expected 1 execution with limit, got %d", len(executions))
+	}
+}
 
-	// Verify it's deleted (soft delete, so GetToolExecution should fail)
-	_, err := store.GetToolExecution(ctx, exec.ID)
-	if err == nil {
-		t.Error("expected error when getting deleted execution")
+func TestQueryToolExecutions(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	executions := []*models.ToolExecution{
+		{ToolName: "nikto", SessionID: "a", Target: "http://example.com", Host: "example.com", Success: true, DurationMs: 300},
+		{ToolName: "nikto", SessionID: "b", CorrelationID: "c0ffee", Target: "http://other.org", Host: "other.org", Success: false, DurationMs: 100},
+		{ToolName: "wapiti", SessionID: "a", Target: "https://www.example.com", Host: "www.example.com", Success: true, DurationMs: 200},
+	}
+	for _, exec := range executions {
+		if err := store.CreateToolExecution(ctx, exec); err != nil {
+			t.Fatalf("failed to create execution: %v", err)
+		}
+	}
+
+	failed := false
+	tests := []struct {
+		name    string
+		filter  ExecutionFilter
+		wantIDs []uint
+		total   int64
+	}{
+		{"no filter", ExecutionFilter{}, []uint{3, 2, 1}, 3},
+		{"tool", ExecutionFilter{ToolName: "nikto"}, []uint{2, 1}, 2},
+		{"session", ExecutionFilter{SessionID: "a"}, []uint{3, 1}, 2},
+		{"correlation ID", ExecutionFilter{CorrelationID: "c0ffee"}, []uint{2}, 1},
+		{"success", ExecutionFilter{Success: &failed}, []uint{2}, 1},
+		{"target substring", ExecutionFilter{Target: "example.com"}, []uint{3, 1}, 2},
+		{"host exact", ExecutionFilter{Host: "example.com"}, []uint{1}, 1},
+		{"sort ascending", ExecutionFilter{SortBy: SortByDurationMs, Ascending: true}, []uint{2, 3, 1}, 3},
+		{"invalid sort falls back", ExecutionFilter{SortBy: "input_json; DROP"}, []uint{3, 2, 1}, 3},
+		{"pagination", ExecutionFilter{Limit: 1, Offset: 1}, []uint{2}, 3},
+		{"future since", ExecutionFilter{Since: time.Now().Add(time.Hour)}, []uint{}, 0},
+		{"past until", ExecutionFilter{Until: time.Now().Add(-time.Hour)}, []uint{}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, total, err := store.QueryToolExecutions(ctx, tt.filter)
+			if err != nil {
+				t.Fatalf("failed to query executions: %v", err)
+			}
+			if total != tt.total {
+				t.Errorf("expected total %d, got %d", tt.total, total)
+			}
+			ids := make([]uint, 0, len(result))
+			for _, exec := range result {
+				ids = append(ids, exec.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Errorf("expected IDs %v, got %v", tt.wantIDs, ids)
+			}
+		})
 	}
 }
 
-func TestDeleteAllToolExecutions(t *testing.T) {
+func TestQueryToolExecutions_InsertionOrder(t *testing.T) {
 	store, cleanup := setupTestDB(t)
 	defer cleanup()
 
 	ctx := context.Background()
 
-	// Create multiple executions
-	for i := 0; i < 5; i++ {
-		exec := &models.ToolExecution{
-			ToolName: "nikto",
-			Success:  true,
+	// Bulk inserts within the same instant, followed by one whose clock stepped backwards.
+	now := time.Now()
+	createdAt := []time.Time{now, now, now, now, now.Add(-time.Minute)}
+	for _, at := range createdAt {
+		exec := &models.ToolExecution{ToolName: "nikto", Target: "http://example.com", Host: "example.com", CreatedAt: at}
+		if err := store.CreateToolExecution(ctx, exec); err != nil {
+			t.Fatalf("failed to create execution: %v", err)
+		}
+	}
+
+	var ids []uint
+	for offset := 0; offset < len(createdAt); offset += 2 {
+		page, _, err := store.QueryToolExecutions(ctx, ExecutionFilter{Limit: 2, Offset: offset})
+		if err != nil {
+			t.Fatalf("failed to query executions: %v", err)
+		}
+		for _, exec := range page {
+			ids = append(ids, exec.ID)
+		}
+	}
+	if want := []uint{5, 4, 3, 2, 1}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("expected pages in insertion order %v, got %v", want, ids)
+	}
+
+	recent, _, err := store.GetToolExecutions(ctx, 2, 0)
+	if err != nil {
+		t.Fatalf("failed to get executions: %v", err)
+	}
+	if len(recent) != 2 || recent[0].ID != 5 || recent[1].ID != 4 {
+		t.Errorf("expected the latest inserted executions first, got %+v", recent)
+	}
+}
+
+func TestQueryToolExecutions_Keyset(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	durations := []int64{300, 100, 200, 100, 400}
+	for _, duration := range durations {
+		if err := store.CreateToolExecution(ctx, &models.ToolExecution{ToolName: "nikto", DurationMs: duration}); err != nil {
+			t.Fatalf("failed to create execution: %v", err)
+		}
+	}
+
+	pages := func(filter ExecutionFilter) []uint {
+		var ids []uint
+		for {
+			page, total, err := store.QueryToolExecutions(ctx, filter)
+			if err != nil {
+				t.Fatalf("failed to query executions: %v", err)
+			}
+			if total != int64(len(durations)) {
+				t.Errorf("expected total %d on every page, got %d", len(durations), total)
+			}
+			for _, exec := range page {
+				ids = append(ids, exec.ID)
+			}
+			if len(page) < filter.Limit {
+				return ids
+			}
+			filter.AfterID = page[len(page)-1].ID
+		}
+	}
+
+	tests := []struct {
+		name    string
+		filter  ExecutionFilter
+		wantIDs []uint
+	}{
+		{"id descending", ExecutionFilter{Limit: 2}, []uint{5, 4, 3, 2, 1}},
+		{"id ascending", ExecutionFilter{Limit: 2, Ascending: true}, []uint{1, 2, 3, 4, 5}},
+		{"sort column with ties", ExecutionFilter{Limit: 2, SortBy: SortByDurationMs}, []uint{5, 1, 3, 4, 2}},
+		{"sort column ascending", ExecutionFilter{Limit: 2, SortBy: SortByDurationMs, Ascending: true}, []uint{2, 4, 3, 1, 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if ids := pages(tt.filter); !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Errorf("expected IDs %v, got %v", tt.wantIDs, ids)
+			}
+		})
+	}
+
+	// The keyset overrides the offset.
+	page, _, err := store.QueryToolExecutions(ctx, ExecutionFilter{AfterID: 4, Offset: 3})
+	if err != nil {
+		t.Fatalf("failed to query executions: %v", err)
+	}
+	if len(page) != 3 || page[0].ID != 3 {
+		t.Errorf("expected executions [3 2 1], got %+v", page)
+	}
+
+	// A page after a deleted execution continues from its position.
+	if err := store.DeleteToolExecution(ctx, 3); err != nil {
+		t.Fatalf("failed to delete execution: %v", err)
+	}
+	page, total, err := store.QueryToolExecutions(ctx, ExecutionFilter{AfterID: 3, SortBy: SortByDurationMs})
+	if err != nil {
+		t.Fatalf("failed to query executions: %v", err)
+	}
+	if len(page) != 2 || page[0].ID != 4 || page[1].ID != 2 || total != 4 {
+		t.Errorf("expected executions [4 2] of 4 after the deleted execution, got %+v of %d", page, total)
+	}
+
+	// Cursors of other tenants and unknown executions are refused.
+	if err := store.CreateToolExecution(tenant.WithTenant(ctx, "acme"), &models.ToolExecution{ToolName: "nikto", DurationMs: 250}); err != nil {
+		t.Fatalf("failed to create execution: %v", err)
+	}
+	for name, tc := range map[string]struct {
+		ctx    context.Context
+		filter ExecutionFilter
+	}{
+		"other tenant":            {tenant.WithTenant(ctx, "beta"), ExecutionFilter{AfterID: 6, SortBy: SortByDurationMs}},
+		"other tenant by id":      {tenant.WithTenant(ctx, "beta"), ExecutionFilter{AfterID: 6}},
+		"unknown execution":       {ctx, ExecutionFilter{AfterID: 99, SortBy: SortByDurationMs}},
+		"unknown execution by id": {ctx, ExecutionFilter{AfterID: 99}},
+	} {
+		if _, _, err := store.QueryToolExecutions(tc.ctx, tc.filter); !errors.Is(err, ErrCursorNotFound) {
+			t.Errorf("%s: expected ErrCursorNotFound, got %v", name, err)
 		}
+	}
+}
+
+func TestQueryToolExecutions_CachedCount(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	tenantCtx := tenant.WithTenant(ctx, "acme")
+
+	for _, exec := range []*models.ToolExecution{{ToolName: "nikto"}, {ToolName: "wapiti"}} {
 		if err := store.CreateToolExecution(ctx, exec); err != nil {
 			t.Fatalf("failed to create execution: %v", err)
 		}
 	}
+	count := func(ctx context.Context, filter ExecutionFilter) int64 {
+		t.Helper()
+		_, total, err := store.QueryToolExecutions(ctx, filter)
+		if err != nil {
+			t.Fatalf("failed to query executions: %v", err)
+		}
+		return total
+	}
 
-	// Verify they exist
-	_, total, _ := store.GetToolExecutions(ctx, 10, 0)
-	if total != 5 {
-		t.Fatalf("expected 5 executions before delete, got %d", total)
+	if total := count(ctx, ExecutionFilter{}); total != 2 {
+		t.Fatalf("expected 2 executions, got %d", total)
+	}
+	if total := count(tenantCtx, ExecutionFilter{}); total != 0 {
+		t.Errorf("expected counts cached per tenant, got %d", total)
 	}
 
-	// Delete all
-	if err := store.DeleteAllToolExecutions(ctx); err != nil {
-		t.Fatalf("failed to delete all executions: %v", err)
+	// Rows written behind the storage's back, as by another process, are counted once the cached
+	// count expires.
+	if err := store.db.Create(&models.ToolExecution{ToolName: "nikto"}).Error; err != nil {
+		t.Fatalf("failed to insert execution: %v", err)
+	}
+	if total := count(ctx, ExecutionFilter{}); total != 2 {
+		t.Errorf("expected the cached count 2, got %d", total)
+	}
+	if total := count(ctx, ExecutionFilter{ToolName: "nikto"}); total != 2 {
+		t.Errorf("expected filters counted separately, got %d", total)
 	}
 
-	// Verify all deleted
-	_, total, _ = store.GetToolExecutions(ctx, 10, 0)
-	if total != 0 {
-		t.Errorf("expected 0 executions after delete all, got %d", total)
+	// Writes through the storage invalidate the cached counts.
+	if err := store.DeleteToolExecution(ctx, 2); err != nil {
+		t.Fatalf("failed to delete execution: %v", err)
+	}
+	if total := count(ctx, ExecutionFilter{}); total != 2 {
+		t.Errorf("expected 2 executions after the delete, got %d", total)
+	}
+	if total := count(ctx, ExecutionFilter{Deleted: true}); total != 1 {
+		t.Errorf("expected 1 deleted execution, got %d", total)
+	}
+	if err := store.CreateToolExecution(ctx, &models.ToolExecution{ToolName: "nikto"}); err != nil {
+		t.Fatalf("failed to create execution: %v", err)
+	}
+	if total := count(ctx, ExecutionFilter{ToolName: "nikto"}); total != 3 {
+		t.Errorf("expected 3 nikto executions after the create, got %d", total)
+	}
+
+	// Startup recovery invalidates the cached counts too.
+	if err := store.db.Create(&models.ToolExecution{ToolName: "nikto", Status: models.StatusRunning}).Error; err != nil {
+		t.Fatalf("failed to insert execution: %v", err)
+	}
+	if total := count(ctx, ExecutionFilter{ToolName: "nikto"}); total != 3 {
+		t.Errorf("expected the cached count 3, got %d", total)
+	}
+	if _, err := store.MarkInterruptedExecutions(ctx); err != nil {
+		t.Fatalf("failed to mark interrupted executions: %v", err)
+	}
+	if total := count(ctx, ExecutionFilter{ToolName: "nikto"}); total != 4 {
+		t.Errorf("expected 4 nikto executions after recovery, got %d", total)
 	}
 }
 
-func TestClose(t *testing.T) {
+func TestToolExecutionIndexes(t *testing.T) {
 	store, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	err := store.Close()
+	for _, name := range []string{
+		"idx_tool_executions_tool_created",
+		"idx_tool_executions_session_created",
+		"idx_tool_executions_success_created",
+	} {
+		if !store.db.Migrator().HasIndex(&models.ToolExecution{}, name) {
+			t.Errorf("expected index %s", name)
+		}
+	}
+
+	var plan []struct{ Detail string }
+	err := store.db.Raw("EXPLAIN QUERY PLAN SELECT id FROM tool_executions WHERE tool_name = ? AND created_at >= ?", "nikto", time.Now()).
+		Scan(&plan).Error
 	if err != nil {
-		t.Fatalf("failed to close storage: %v", err)
+		t.Fatalf("failed to explain query: %v", err)
+	}
+	if len(plan) == 0 || !strings.Contains(plan[0].Detail, "idx_tool_executions_tool_created") {
+		t.Errorf("expected tool and time range lookups to use the composite index, got %+v", plan)
 	}
 }
 
-func TestToolExecution_WithError(t *testing.T) {
+func TestExportToolExecutions(t *testing.T) {
 	store, cleanup := setupTestDB(t)
 	defer cleanup()
 
 	ctx := context.Background()
-	exec := &models.ToolExecution{
-		ToolName:     "nikto",
-		InputJSON:    `{"host": "invalid"}`,
-		ErrorMessage: "connection refused",
-		DurationMs:   50,
-		Success:      false,
+
+	// More than one batch, every third execution by another tool.
+	count := exportBatch + 10
+	for i := 0; i < count; i++ {
+		exec := &models.ToolExecution{ToolName: "nikto"}
+		if i%3 == 0 {
+			exec.ToolName = "wapiti"
+		}
+		if err := store.CreateToolExecution(ctx, exec); err != nil {
+			t.Fatalf("failed to create execution: %v", err)
+		}
 	}
 
-	err := store.CreateToolExecution(ctx, exec)
+	var ids []uint
+	err := store.ExportToolExecutions(ctx, ExecutionFilter{ToolName: "nikto", Limit: 1, SortBy: SortByToolName}, func(exec *models.ToolExecution) error {
+		if exec.ToolName != "nikto" {
+			t.Errorf("expected only nikto executions, got %s", exec.ToolName)
+		}
+		ids = append(ids, exec.ID)
+		return nil
+	})
 	if err != nil {
-		t.Fatalf("failed to create failed execution: %v", err)
+		t.Fatalf("failed to export executions: %v", err)
+	}
+	if want := count - (count+2)/3; len(ids) != want {
+		t.Errorf("expected %d executions ignoring the limit, got %d", want, len(ids))
+	}
+	for i := 1; i < len(ids); i++ {
+		if ids[i] <= ids[i-1] {
+			t.Fatalf("expected ascending IDs, got %d after %d", ids[i], ids[i-1])
+		}
 	}
 
-	retrieved, err := store.GetToolExecution(ctx, exec.ID)
+	errStop := errors.New("stop")
+	exported := 0
+	err = store.ExportToolExecutions(ctx, ExecutionFilter{}, func(*models.ToolExecution) error {
+		exported++
+		if exported == 3 {
+			return errStop
+		}
+		return nil
+	})
+	if !errors.Is(err, errStop) || exported != 3 {
+		t.Errorf("expected the export to stop at the first error, got %v after %d", err, exported)
+	}
+}
+
+func TestFindings(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	if err := store.CreateFindings(ctx, nil); err != nil {
+		t.Fatalf("expected no error for empty findings, got: %v", err)
+	}
+
+	findings := []models.Finding{
+		{ExecutionID: 1, Scanner: "nuclei", Severity: "high", Title: "Exposed Git"},
+		{ExecutionID: 1, Scanner: "nuclei", Severity: "info", Title: "Tech Detect"},
+		{ExecutionID: 2, Scanner: "nikto", Severity: "low", Title: "Missing header", References: []string{"OSVDB-3092"}},
+	}
+	if err := store.CreateFindings(ctx, findings); err != nil {
+		t.Fatalf("failed to create findings: %v", err)
+	}
+
+	found, err := store.GetFindingsByExecutions(ctx, []uint{2})
 	if err != nil {
-		t.Fatalf("failed to get execution: %v", err)
+		t.Fatalf("failed to get findings: %v", err)
+	}
+	if len(found) != 1 || len(found[0].References) != 1 || found[0].References[0] != "OSVDB-3092" {
+		t.Errorf("expected references to round-trip, got %+v", found)
 	}
 
-	if retrieved.Success {
-		t.Error("expected Success to be false")
+	found, err = store.GetFindingsByExecutions(ctx, []uint{1})
+	if err != nil {
+		t.Fatalf("failed to get findings: %v", err)
 	}
-	if retrieved.ErrorMessage != "connection refused" {
-		t.Errorf("expected error message 'connection refused', got '%s'", retrieved.ErrorMessage)
+	if len(found) != 2 {
+		t.Errorf("expected 2 findings for execution 1, got %d", len(found))
+	}
+
+	found, err = store.GetFindingsByExecutions(ctx, []uint{1, 2})
+	if err != nil {
+		t.Fatalf("failed to get findings: %v", err)
+	}
+	if len(found) != 3 {
+		t.Errorf("expected 3 findings, got %d", len(found))
+	}
+
+	found, err = store.GetFindingsByExecutions(ctx, nil)
+	if err != nil {
+		t.Fatalf("failed to get findings: %v", err)
+	}
+	if len(found) != 0 {
+		t.Errorf("expected no findings without execution IDs, got %d", len(found))
+	}
+}
+
+func TestFindingTriage(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	alpha := tenant.WithTenant(context.Background(), "alpha")
+	beta := tenant.WithTenant(context.Background(), "beta")
+
+	findings := []models.Finding{
+		{ExecutionID: 1, Scanner: "nuclei", Severity: "high", Title: "Exposed Git"},
+		{ExecutionID: 1, Scanner: "nuclei", Severity: "info", Title: "Tech Detect"},
+		{ExecutionID: 2, Scanner: "nikto", Severity: "high", Title: "Missing header"},
+	}
+	if err := store.CreateFindings(alpha, findings); err != nil {
+		t.Fatalf("failed to create findings: %v", err)
+	}
+	if err := store.CreateFindings(beta, []models.Finding{{ExecutionID: 3, Scanner: "nikto", Severity: "high", Title: "Other"}}); err != nil {
+		t.Fatalf("failed to create findings: %v", err)
+	}
+
+	finding, err := store.GetFinding(alpha, findings[0].ID)
+	if err != nil {
+		t.Fatalf("failed to get finding: %v", err)
+	}
+	if finding.Status != models.FindingOpen || finding.Assignee != "" {
+		t.Errorf("expected a new finding to be open and unassigned, got %+v", finding)
+	}
+	if _, err := store.GetFinding(beta, findings[0].ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("expected another tenant's finding to be hidden, got %v", err)
+	}
+
+	finding.Assignee = "alice"
+	finding.Status = models.FindingInProgress
+	if err := store.UpdateFindingTriage(alpha, finding); err != nil {
+		t.Fatalf("failed to update finding: %v", err)
+	}
+	if err := store.UpdateFindingTriage(beta, &models.Finding{ID: findings[1].ID, Assignee: "mallory"}); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("expected updating another tenant's finding to fail, got %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		filter FindingFilter
+		want   []uint
+	}{
+		{"all", FindingFilter{}, []uint{findings[2].ID, findings[1].ID, findings[0].ID}},
+		{"assignee", FindingFilter{Assignee: "alice"}, []uint{findings[0].ID}},
+		{"unassigned", FindingFilter{Unassigned: true}, []uint{findings[2].ID, findings[1].ID}},
+		{"open", FindingFilter{Status: models.FindingOpen}, []uint{findings[2].ID, findings[1].ID}},
+		{"in progress", FindingFilter{Status: models.FindingInProgress}, []uint{findings[0].ID}},
+		{"severity and execution", FindingFilter{Severity: "high", ExecutionID: 1}, []uint{findings[0].ID}},
+		{"paginated", FindingFilter{Limit: 1, Offset: 1}, []uint{findings[1].ID}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found, total, err := store.QueryFindings(alpha, tt.filter)
+			if err != nil {
+				t.Fatalf("failed to query findings: %v", err)
+			}
+			ids := make([]uint, 0, len(found))
+			for _, finding := range found {
+				ids = append(ids, finding.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.want) {
+				t.Errorf("expected findings %v, got %v", tt.want, ids)
+			}
+			if tt.filter.Limit == 0 && total != int64(len(tt.want)) {
+				t.Errorf("expected total %d, got %d", len(tt.want), total)
+			}
+		})
+	}
+}
+
+func TestFindingEvidence(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	alpha := tenant.WithTenant(context.Background(), "alpha")
+	beta := tenant.WithTenant(context.Background(), "beta")
+
+	findings := []models.Finding{{
+		ExecutionID: 1,
+		Scanner:     "nuclei",
+		Severity:    "high",
+		Title:       "Exposed Git",
+		Evidence:    []models.Evidence{{Content: "GET /.git/config HTTP/1.1", Kind: models.EvidenceRequest, Source: "nuclei"}},
+		Assignee:    "alice",
+	}}
+	if err := store.CreateFindings(alpha, findings); err != nil {
+		t.Fatalf("failed to create findings: %v", err)
+	}
+
+	finding, err := store.GetFinding(alpha, findings[0].ID)
+	if err != nil {
+		t.Fatalf("failed to get finding: %v", err)
+	}
+	if !reflect.DeepEqual(finding.Evidence, findings[0].Evidence) {
+		t.Errorf("expected evidence %+v, got %+v", findings[0].Evidence, finding.Evidence)
+	}
+
+	finding.Evidence = append(finding.Evidence, models.Evidence{
+		AddedBy:   "alice",
+		Kind:      models.EvidenceArtifact,
+		Reference: "https://tickets.example.com/SEC-1",
+		Source:    models.EvidenceSourceManual,
+	})
+	finding.Assignee = "mallory"
+	if err := store.UpdateFindingEvidence(alpha, finding); err != nil {
+		t.Fatalf("failed to update evidence: %v", err)
+	}
+	if err := store.UpdateFindingEvidence(beta, finding); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("expected updating another tenant's finding to fail, got %v", err)
+	}
+
+	updated, err := store.GetFinding(alpha, findings[0].ID)
+	if err != nil {
+		t.Fatalf("failed to get finding: %v", err)
+	}
+	if len(updated.Evidence) != 2 || updated.Evidence[1].Reference != "https://tickets.example.com/SEC-1" {
+		t.Errorf("expected the attached evidence to be stored, got %+v", updated.Evidence)
+	}
+	if updated.Assignee != "alice" {
+		t.Errorf("expected the assignee to be left alone, got %q", updated.Assignee)
+	}
+}
+
+func TestDeleteToolExecution(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	// Create execution
+	exec := &models.ToolExecution{
+		ToolName: "nikto",
+		Success:  true,
+	}
+	if err := store.CreateToolExecution(ctx, exec); err != nil {
+		t.Fatalf("failed to create execution: %v", err)
+	}
+
+	// Delete it
+	if err := store.DeleteToolExecution(ctx, exec.ID); err != nil {
+		t.Fatalf("failed to delete execution: %v", err)
+	}
+
+	// Verify it's deleted (soft delete, so GetToolExecution should fail)
+	_, err := store.GetToolExecution(ctx, exec.ID)
+	if err == nil {
+		t.Error("expected error when getting deleted execution")
+	}
+}
+
+func TestRestoreToolExecution(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	exec := &models.ToolExecution{
+		ToolName: "nikto",
+		Success:  true,
+	}
+	if err := store.CreateToolExecution(ctx, exec); err != nil {
+		t.Fatalf("failed to create execution: %v", err)
+	}
+
+	// Restoring a live execution is an error
+	if err := store.RestoreToolExecution(ctx, exec.ID); err == nil {
+		t.Error("expected error when restoring a live execution")
+	}
+
+	if err := store.DeleteToolExecution(ctx, exec.ID); err != nil {
+		t.Fatalf("failed to delete execution: %v", err)
+	}
+
+	deleted, total, err := store.QueryToolExecutions(ctx, ExecutionFilter{Deleted: true})
+	if err != nil {
+		t.Fatalf("failed to query deleted executions: %v", err)
+	}
+	if total != 1 || len(deleted) != 1 || deleted[0].ID != exec.ID {
+		t.Errorf("expected the deleted execution to be listed, got %d", total)
+	}
+
+	if err := store.RestoreToolExecution(ctx, exec.ID); err != nil {
+		t.Fatalf("failed to restore execution: %v", err)
+	}
+	if _, err := store.GetToolExecution(ctx, exec.ID); err != nil {
+		t.Errorf("expected restored execution to be retrievable: %v", err)
+	}
+
+	if err := store.RestoreToolExecution(ctx, 999); err == nil {
+		t.Error("expected error when restoring non-existent execution")
+	}
+}
+
+func TestPurgeDeletedToolExecutions(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	live := &models.ToolExecution{ToolName: "nikto", Success: true}
+	deleted := &models.ToolExecution{ToolName: "wapiti", Success: true}
+	for _, exec := range []*models.ToolExecution{live, deleted} {
+		if err := store.CreateToolExecution(ctx, exec); err != nil {
+			t.Fatalf("failed to create execution: %v", err)
+		}
+	}
+	findings := []models.Finding{
+		{ExecutionID: live.ID, Scanner: "nikto", Severity: "low", Title: "live"},
+		{ExecutionID: deleted.ID, Scanner: "wapiti", Severity: "high", Title: "deleted"},
+	}
+	if err := store.CreateFindings(ctx, findings); err != nil {
+		t.Fatalf("failed to create findings: %v", err)
+	}
+	if err := store.DeleteToolExecution(ctx, deleted.ID); err != nil {
+		t.Fatalf("failed to delete execution: %v", err)
+	}
+
+	purged, err := store.PurgeDeletedToolExecutions(ctx)
+	if err != nil {
+		t.Fatalf("failed to purge executions: %v", err)
+	}
+	if purged != 1 {
+		t.Errorf("expected 1 purged execution, got %d", purged)
+	}
+
+	// Purged executions can no longer be restored
+	if err := store.RestoreToolExecution(ctx, deleted.ID); err == nil {
+		t.Error("expected error when restoring purged execution")
+	}
+
+	found, err := store.GetFindingsByExecutions(ctx, []uint{live.ID, deleted.ID})
+	if err != nil {
+		t.Fatalf("failed to get findings: %v", err)
+	}
+	if len(found) != 1 || found[0].ExecutionID != live.ID {
+		t.Errorf("expected only the live execution's finding to remain, got %+v", found)
+	}
+
+	if _, err := store.GetToolExecution(ctx, live.ID); err != nil {
+		t.Errorf("expected live execution to be kept: %v", err)
+	}
+}
+
+func TestPurgeDeletedToolExecutions_RemovesArtifacts(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	artifact := filepath.Join(t.TempDir(), "nikto-1.json")
+	capture := filepath.Join(t.TempDir(), "nikto-1.har")
+	stream := filepath.Join(t.TempDir(), "nikto-1.log")
+	for _, path := range []string{artifact, capture, stream} {
+		if err := os.WriteFile(path, []byte("{}"), 0o600); err != nil {
+			t.Fatalf("failed to write artifact: %v", err)
+		}
+	}
+
+	exec := &models.ToolExecution{ToolName: "nikto", OutputFile: artifact, CaptureFiles: []string{capture}, StreamFile: stream}
+	if err := store.CreateToolExecution(ctx, exec); err != nil {
+		t.Fatalf("failed to create execution: %v", err)
+	}
+	if err := store.DeleteToolExecution(ctx, exec.ID); err != nil {
+		t.Fatalf("failed to delete execution: %v", err)
+	}
+
+	// Soft deletes keep the artifact so the execution can be restored
+	if _, err := os.Stat(artifact); err != nil {
+		t.Fatalf("expected artifact to survive soft delete: %v", err)
+	}
+
+	if _, err := store.PurgeDeletedToolExecutions(ctx); err != nil {
+		t.Fatalf("failed to purge executions: %v", err)
+	}
+	for _, path := range []string{artifact, capture, stream} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("expected artifact %s to be removed, got: %v", path, err)
+		}
+	}
+}
+
+func TestHardDelete(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+	store.hardDelete = true
+	store.secureErase = true
+
+	ctx := context.Background()
+
+	artifact := filepath.Join(t.TempDir(), "nikto-1.json")
+	if err := os.WriteFile(artifact, []byte("{}"), 0o600); err != nil {
+		t.Fatalf("failed to write artifact: %v", err)
+	}
+	deleted := &models.ToolExecution{ToolName: "nikto", OutputFile: artifact}
+	cleared := &models.ToolExecution{ToolName: "wapiti"}
+	for _, exec := range []*models.ToolExecution{deleted, cleared} {
+		if err := store.CreateToolExecution(ctx, exec); err != nil {
+			t.Fatalf("failed to create execution: %v", err)
+		}
+	}
+	if err := store.CreateFindings(ctx, []models.Finding{{ExecutionID: deleted.ID, Scanner: "nikto", Severity: "low", Title: "deleted"}}); err != nil {
+		t.Fatalf("failed to create findings: %v", err)
+	}
+
+	if err := store.DeleteToolExecution(ctx, deleted.ID); err != nil {
+		t.Fatalf("failed to delete execution: %v", err)
+	}
+	if _, err := os.Stat(artifact); !os.IsNotExist(err) {
+		t.Errorf("expected artifact to be removed, got: %v", err)
+	}
+	found, err := store.GetFindingsByExecutions(ctx, []uint{deleted.ID})
+	if err != nil {
+		t.Fatalf("failed to get findings: %v", err)
+	}
+	if len(found) != 0 {
+		t.Errorf("expected findings to be removed, got %d", len(found))
+	}
+
+	if err := store.DeleteAllToolExecutions(ctx); err != nil {
+		t.Fatalf("failed to clear executions: %v", err)
+	}
+	remaining, total, err := store.QueryToolExecutions(ctx, ExecutionFilter{Deleted: true})
+	if err != nil {
+		t.Fatalf("failed to query deleted executions: %v", err)
+	}
+	if total != 0 {
+		t.Errorf("expected nothing left to restore, got %+v", remaining)
+	}
+	if err := store.RestoreToolExecution(ctx, cleared.ID); err == nil {
+		t.Error("expected error when restoring hard-deleted execution")
+	}
+}
+
+func TestDeleteAllToolExecutions(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	// Create multiple executions
+	for i := 0; i < 5; i++ {
+		exec := &models.ToolExecution{
+			ToolName: "nikto",
+			Success:  true,
+		}
+		if err := store.CreateToolExecution(ctx, exec); err != nil {
+			t.Fatalf("failed to create execution: %v", err)
+		}
+	}
+
+	// Verify they exist
+	_, total, _ := store.GetToolExecutions(ctx, 10, 0)
+	if total != 5 {
+		t.Fatalf("expected 5 executions before delete, got %d", total)
+	}
+
+	// Delete all
+	if err := store.DeleteAllToolExecutions(ctx); err != nil {
+		t.Fatalf("failed to delete all executions: %v", err)
+	}
+
+	// Verify all deleted
+	_, total, _ = store.GetToolExecutions(ctx, 10, 0)
+	if total != 0 {
+		t.Errorf("expected 0 executions after delete all, got %d", total)
+	}
+}
+
+func TestClose(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	err := store.Close()
+	if err != nil {
+		t.Fatalf("failed to close storage: %v", err)
+	}
+}
+
+func TestToolExecution_WithError(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	exec := &models.ToolExecution{
+		ToolName:     "nikto",
+		InputJSON:    `{"host": "invalid"}`,
+		ErrorMessage: "connection refused",
+		DurationMs:   50,
+		Success:      false,
+	}
+
+	err := store.CreateToolExecution(ctx, exec)
+	if err != nil {
+		t.Fatalf("failed to create failed execution: %v", err)
+	}
+
+	retrieved, err := store.GetToolExecution(ctx, exec.ID)
+	if err != nil {
+		t.Fatalf("failed to get execution: %v", err)
+	}
+
+	if retrieved.Success {
+		t.Error("expected Success to be false")
+	}
+	if retrieved.ErrorMessage != "connection refused" {
+		t.Errorf("expected error message 'connection refused', got '%s'", retrieved.ErrorMessage)
+	}
+}
+
+func TestPruneToolExecutions(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	cutoff := time.Now().Add(-24 * time.Hour)
+
+	old := &models.ToolExecution{ToolName: "nikto", Status: models.StatusCompleted}
+	oldDeleted := &models.ToolExecution{ToolName: "nikto", Status: models.StatusCompleted}
+	oldRunning := &models.ToolExecution{ToolName: "nuclei", Status: models.StatusRunning}
+	recent := &models.ToolExecution{ToolName: "wapiti", Status: models.StatusCompleted}
+	for _, exec := range []*models.ToolExecution{old, oldDeleted, oldRunning, recent} {
+		if exec != recent {
+			exec.CreatedAt = cutoff.Add(-time.Hour)
+		}
+		if err := store.CreateToolExecution(ctx, exec); err != nil {
+			t.Fatalf("failed to create execution: %v", err)
+		}
+	}
+	if err := store.CreateFindings(ctx, []models.Finding{{ExecutionID: old.ID, Scanner: "nikto", Title: "old"}}); err != nil {
+		t.Fatalf("failed to create findings: %v", err)
+	}
+	if err := store.DeleteToolExecution(ctx, oldDeleted.ID); err != nil {
+		t.Fatalf("failed to delete execution: %v", err)
+	}
+
+	pruned, err := store.PruneToolExecutions(ctx, cutoff)
+	if err != nil {
+		t.Fatalf("failed to prune executions: %v", err)
+	}
+	if pruned != 2 {
+		t.Errorf("expected 2 pruned executions, got %d", pruned)
+	}
+
+	if _, err := store.GetToolExecution(ctx, old.ID); err == nil {
+		t.Error("expected old execution to be pruned")
+	}
+	if err := store.RestoreToolExecution(ctx, oldDeleted.ID); err == nil {
+		t.Error("expected old soft-deleted execution to be pruned")
+	}
+	for _, kept := range []*models.ToolExecution{oldRunning, recent} {
+		if _, err := store.GetToolExecution(ctx, kept.ID); err != nil {
+			t.Errorf("expected execution %d to be kept: %v", kept.ID, err)
+		}
+	}
+
+	found, err := store.GetFindingsByExecutions(ctx, []uint{old.ID})
+	if err != nil {
+		t.Fatalf("failed to get findings: %v", err)
+	}
+	if len(found) != 0 {
+		t.Errorf("expected findings of pruned executions to be removed, got %+v", found)
+	}
+}
+
+func TestTenantScoping(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	alpha := tenant.WithTenant(context.Background(), "alpha")
+	beta := tenant.WithTenant(context.Background(), "beta")
+
+	alphaExec := &models.ToolExecution{ToolName: "nikto", Host: "example.com"}
+	betaExec := &models.ToolExecution{ToolName: "nikto", Host: "example.com"}
+	if err := store.CreateToolExecution(alpha, alphaExec); err != nil {
+		t.Fatalf("failed to create execution: %v", err)
+	}
+	if err := store.CreateToolExecution(beta, betaExec); err != nil {
+		t.Fatalf("failed to create execution: %v", err)
+	}
+	if alphaExec.Tenant != "alpha" {
+		t.Errorf("expected execution assigned to alpha, got %q", alphaExec.Tenant)
+	}
+	if err := store.CreateFindings(alpha, []models.Finding{{ExecutionID: alphaExec.ID, Title: "alpha"}}); err != nil {
+		t.Fatalf("failed to create findings: %v", err)
+	}
+	if err := store.CreateFindings(beta, []models.Finding{{ExecutionID: betaExec.ID, Title: "beta"}}); err != nil {
+		t.Fatalf("failed to create findings: %v", err)
+	}
+
+	executions, err := store.GetToolExecutionsByHost(alpha, "example.com", 0)
+	if err != nil {
+		t.Fatalf("failed to query executions: %v", err)
+	}
+	if len(executions) != 1 || executions[0].ID != alphaExec.ID {
+		t.Errorf("expected only the alpha execution, got %+v", executions)
+	}
+	if _, total, _ := store.GetToolExecutions(beta, 0, 0); total != 1 {
+		t.Errorf("expected 1 beta execution, got %d", total)
+	}
+	if _, err := store.GetToolExecution(alpha, betaExec.ID); err == nil {
+		t.Error("expected beta execution to be hidden from alpha")
+	}
+
+	found, err := store.GetFindingsByExecutions(alpha, []uint{alphaExec.ID, betaExec.ID})
+	if err != nil {
+		t.Fatalf("failed to get findings: %v", err)
+	}
+	if len(found) != 1 || found[0].Title != "alpha" {
+		t.Errorf("expected only the alpha finding, got %+v", found)
+	}
+
+	if err := store.DeleteAllToolExecutions(alpha); err != nil {
+		t.Fatalf("failed to delete executions: %v", err)
+	}
+	if purged, _ := store.PurgeDeletedToolExecutions(beta); purged != 0 {
+		t.Errorf("expected beta to purge nothing, got %d", purged)
+	}
+	if _, err := store.GetToolExecution(beta, betaExec.ID); err != nil {
+		t.Errorf("expected beta execution to survive alpha deletion: %v", err)
+	}
+
+	// Unscoped contexts see every tenant.
+	if _, total, _ := store.GetToolExecutions(context.Background(), 0, 0); total != 1 {
+		t.Errorf("expected 1 live execution across tenants, got %d", total)
+	}
+}
+
+func TestTargetGroups(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	alpha := tenant.WithTenant(context.Background(), "alpha")
+	beta := tenant.WithTenant(context.Background(), "beta")
+
+	staging := &models.TargetGroup{Name: "staging", Hosts: []string{"a.example.com", "b.example.com"}}
+	if err := store.SaveTargetGroup(alpha, staging); err != nil {
+		t.Fatalf("failed to save group: %v", err)
+	}
+	if staging.Tenant != "alpha" || staging.ID == 0 {
+		t.Errorf("expected a stored alpha group, got %+v", staging)
+	}
+	if err := store.SaveTargetGroup(beta, &models.TargetGroup{Name: "staging", Hosts: []string{"c.example.com"}}); err != nil {
+		t.Fatalf("failed to save beta group of the same name: %v", err)
+	}
+	if err := store.SaveTargetGroup(alpha, &models.TargetGroup{Name: "prod", Hosts: []string{"example.com"}}); err != nil {
+		t.Fatalf("failed to save group: %v", err)
+	}
+
+	// Saving a group of an existing name replaces it.
+	replaced := &models.TargetGroup{Name: "staging", Description: "cluster", Hosts: []string{"a.example.com"}}
+	if err := store.SaveTargetGroup(alpha, replaced); err != nil {
+		t.Fatalf("failed to replace group: %v", err)
+	}
+	if replaced.ID != staging.ID {
+		t.Errorf("expected the group to keep ID %d, got %d", staging.ID, replaced.ID)
+	}
+
+	group, err := store.GetTargetGroup(alpha, "staging")
+	if err != nil {
+		t.Fatalf("failed to get group: %v", err)
+	}
+	if group.Description != "cluster" || !reflect.DeepEqual(group.Hosts, []string{"a.example.com"}) {
+		t.Errorf("unexpected group: %+v", group)
+	}
+
+	groups, err := store.ListTargetGroups(alpha)
+	if err != nil {
+		t.Fatalf("failed to list groups: %v", err)
+	}
+	if len(groups) != 2 || groups[0].Name != "prod" || groups[1].Name != "staging" {
+		t.Errorf("expected prod and staging, got %+v", groups)
+	}
+
+	if err := store.DeleteTargetGroup(beta, "prod"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("expected ErrRecordNotFound deleting another tenant's group, got %v", err)
+	}
+	if err := store.DeleteTargetGroup(alpha, "staging"); err != nil {
+		t.Fatalf("failed to delete group: %v", err)
+	}
+	if _, err := store.GetTargetGroup(alpha, "staging"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("expected deleted group to be gone, got %v", err)
+	}
+	if group, err := store.GetTargetGroup(beta, "staging"); err != nil || group.Hosts[0] != "c.example.com" {
+		t.Errorf("expected beta group to survive, got %+v (err: %v)", group, err)
+	}
+}
+
+func TestTargetProfiles(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	alpha := tenant.WithTenant(context.Background(), "alpha")
+	beta := tenant.WithTenant(context.Background(), "beta")
+
+	profile := &models.TargetProfile{
+		Target:       "https://shop.example.com",
+		Host:         "shop.example.com",
+		Port:         443,
+		Scanner:      "whatweb",
+		Technologies: []models.Technology{{Category: models.TechnologyServer, Name: "nginx", Version: "1.18.0"}},
+	}
+	if err := store.SaveTargetProfile(alpha, profile); err != nil {
+		t.Fatalf("failed to save profile: %v", err)
+	}
+	if profile.Tenant != "alpha" || profile.ID == 0 {
+		t.Errorf("expected a stored alpha profile, got %+v", profile)
+	}
+	if err := store.SaveTargetProfile(alpha, &models.TargetProfile{Target: "http://shop.example.com:8080", Host: "shop.example.com", Port: 8080}); err != nil {
+		t.Fatalf("failed to save profile: %v", err)
+	}
+	if err := store.SaveTargetProfile(beta, &models.TargetProfile{Target: "https://shop.example.com", Host: "shop.example.com", Port: 443}); err != nil {
+		t.Fatalf("failed to save beta profile of the same target: %v", err)
+	}
+
+	// Saving a profile of an existing target replaces it.
+	replaced := &models.TargetProfile{
+		Target:       "https://shop.example.com",
+		Host:         "shop.example.com",
+		Port:         443,
+		Technologies: []models.Technology{{Category: models.TechnologyCMS, Name: "wordpress"}},
+	}
+	if err := store.SaveTargetProfile(alpha, replaced); err != nil {
+		t.Fatalf("failed to replace profile: %v", err)
+	}
+	if replaced.ID != profile.ID {
+		t.Errorf("expected the profile to keep ID %d, got %d", profile.ID, replaced.ID)
+	}
+
+	profiles, err := store.GetTargetProfiles(alpha, "shop.example.com")
+	if err != nil {
+		t.Fatalf("failed to get profiles: %v", err)
+	}
+	if len(profiles) != 2 || profiles[0].Target != "http://shop.example.com:8080" || profiles[1].Target != "https://shop.example.com" {
+		t.Fatalf("expected both alpha profiles ordered by target, got %+v", profiles)
+	}
+	if !reflect.DeepEqual(profiles[1].Technologies, replaced.Technologies) {
+		t.Errorf("expected the replaced technologies, got %+v", profiles[1].Technologies)
+	}
+
+	if profiles, err = store.GetTargetProfiles(beta, ""); err != nil || len(profiles) != 1 {
+		t.Errorf("expected the beta profile only, got %+v (err: %v)", profiles, err)
+	}
+	if profiles, err = store.GetTargetProfiles(alpha, "other.example.com"); err != nil || len(profiles) != 0 {
+		t.Errorf("expected no profiles of another host, got %+v (err: %v)", profiles, err)
+	}
+}
+
+func TestSuppressionRules(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	alpha := tenant.WithTenant(context.Background(), "alpha")
+	beta := tenant.WithTenant(context.Background(), "beta")
+
+	health := &models.SuppressionRule{Name: "health", Scanner: "shcheck", URLPattern: "/healthz$"}
+	if err := store.SaveSuppressionRule(alpha, health); err != nil {
+		t.Fatalf("failed to save rule: %v", err)
+	}
+	if health.Tenant != "alpha" || health.ID == 0 {
+		t.Errorf("expected a stored alpha rule, got %+v", health)
+	}
+	if err := store.SaveSuppressionRule(beta, &models.SuppressionRule{Name: "health", TitlePattern: "Server:"}); err != nil {
+		t.Fatalf("failed to save beta rule of the same name: %v", err)
+	}
+	if err := store.SaveSuppressionRule(alpha, &models.SuppressionRule{Name: "banner", TitlePattern: "^Server:"}); err != nil {
+		t.Fatalf("failed to save rule: %v", err)
+	}
+
+	// Saving a rule of an existing name replaces it.
+	replaced := &models.SuppressionRule{Name: "health", Scanner: "shcheck", URLPattern: "/health", Reason: "load balancer probe"}
+	if err := store.SaveSuppressionRule(alpha, replaced); err != nil {
+		t.Fatalf("failed to replace rule: %v", err)
+	}
+	if replaced.ID != health.ID {
+		t.Errorf("expected the rule to keep ID %d, got %d", health.ID, replaced.ID)
+	}
+
+	rule, err := store.GetSuppressionRule(alpha, "health")
+	if err != nil {
+		t.Fatalf("failed to get rule: %v", err)
+	}
+	if rule.URLPattern != "/health" || rule.Reason != "load balancer probe" {
+		t.Errorf("unexpected rule: %+v", rule)
+	}
+
+	rules, err := store.ListSuppressionRules(alpha)
+	if err != nil {
+		t.Fatalf("failed to list rules: %v", err)
+	}
+	if len(rules) != 2 || rules[0].Name != "banner" || rules[1].Name != "health" {
+		t.Errorf("expected banner and health, got %+v", rules)
+	}
+
+	if err := store.DeleteSuppressionRule(beta, "banner"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("expected ErrRecordNotFound deleting another tenant's rule, got %v", err)
+	}
+	if err := store.DeleteSuppressionRule(alpha, "health"); err != nil {
+		t.Fatalf("failed to delete rule: %v", err)
+	}
+	if _, err := store.GetSuppressionRule(alpha, "health"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("expected deleted rule to be gone, got %v", err)
+	}
+	if rule, err := store.GetSuppressionRule(beta, "health"); err != nil || rule.TitlePattern != "Server:" {
+		t.Errorf("expected beta rule to survive, got %+v (err: %v)", rule, err)
+	}
+}
+
+func TestCredentials(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	alpha := tenant.WithTenant(context.Background(), "alpha")
+	beta := tenant.WithTenant(context.Background(), "beta")
+
+	admin := &models.Credential{Name: "staging-admin", Type: "basic", Secret: []byte("sealed")}
+	if err := store.SaveCredential(alpha, admin); err != nil {
+		t.Fatalf("failed to save credential: %v", err)
+	}
+	if admin.Tenant != "alpha" || admin.ID == 0 {
+		t.Errorf("expected a stored alpha credential, got %+v", admin)
+	}
+	if err := store.SaveCredential(alpha, &models.Credential{Name: "api", Type: "bearer", Secret: []byte("token")}); err != nil {
+		t.Fatalf("failed to save credential: %v", err)
+	}
+
+	// Saving a credential of an existing name replaces it.
+	replaced := &models.Credential{Name: "staging-admin", Type: "cookie", Description: "rotated", Secret: []byte("resealed")}
+	if err := store.SaveCredential(alpha, replaced); err != nil {
+		t.Fatalf("failed to replace credential: %v", err)
+	}
+	if replaced.ID != admin.ID {
+		t.Errorf("expected the credential to keep ID %d, got %d", admin.ID, replaced.ID)
+	}
+
+	credential, err := store.GetCredential(alpha, "staging-admin")
+	if err != nil {
+		t.Fatalf("failed to get credential: %v", err)
+	}
+	if credential.Type != "cookie" || string(credential.Secret) != "resealed" {
+		t.Errorf("unexpected credential: %+v", credential)
+	}
+	if _, err := store.GetCredential(beta, "staging-admin"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("expected ErrRecordNotFound getting another tenant's credential, got %v", err)
+	}
+
+	credentials, err := store.ListCredentials(alpha)
+	if err != nil {
+		t.Fatalf("failed to list credentials: %v", err)
+	}
+	if len(credentials) != 2 || credentials[0].Name != "api" || credentials[1].Name != "staging-admin" {
+		t.Errorf("expected api and staging-admin, got %+v", credentials)
+	}
+	for _, listed := range credentials {
+		if listed.Secret != nil {
+			t.Errorf("expected listed credentials without secrets, got %q", listed.Secret)
+		}
+	}
+
+	if err := store.DeleteCredential(beta, "api"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("expected ErrRecordNotFound deleting another tenant's credential, got %v", err)
+	}
+	if err := store.DeleteCredential(alpha, "api"); err != nil {
+		t.Fatalf("failed to delete credential: %v", err)
+	}
+	if _, err := store.GetCredential(alpha, "api"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("expected deleted credential to be gone, got %v", err)
+	}
+}
+
+func TestScanTemplates(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	alpha := tenant.WithTenant(context.Background(), "alpha")
+	beta := tenant.WithTenant(context.Background(), "beta")
+
+	weekly := &models.ScanTemplate{
+		Name:     "weekly",
+		Group:    "staging",
+		Scanners: []string{"nikto", "nuclei"},
+		Profile:  models.ScanProfile{Ports: []int{80, 443}, Options: map[string]string{"user_agent": "wass"}},
+		Notify:   &models.Notification{WebhookURL: "https://hooks.example.com/scan", MinSeverity: "high"},
+	}
+	if err := store.SaveScanTemplate(alpha, weekly); err != nil {
+		t.Fatalf("failed to save template: %v", err)
+	}
+	if err := store.SaveScanTemplate(alpha, &models.ScanTemplate{Name: "adhoc", Host: "example.com"}); err != nil {
+		t.Fatalf("failed to save template: %v", err)
+	}
+
+	template, err := store.GetScanTemplate(alpha, "weekly")
+	if err != nil {
+		t.Fatalf("failed to get template: %v", err)
+	}
+	if !reflect.DeepEqual(template.Profile, weekly.Profile) || !reflect.DeepEqual(template.Notify, weekly.Notify) ||
+		!reflect.DeepEqual(template.Scanners, weekly.Scanners) {
+		t.Errorf("unexpected template: %+v", template)
+	}
+
+	// Saving a template of an existing name replaces it.
+	replaced := &models.ScanTemplate{Name: "weekly", Host: "example.org"}
+	if err := store.SaveScanTemplate(alpha, replaced); err != nil {
+		t.Fatalf("failed to replace template: %v", err)
+	}
+	if replaced.ID != weekly.ID {
+		t.Errorf("expected the template to keep ID %d, got %d", weekly.ID, replaced.ID)
+	}
+	if template, _ := store.GetScanTemplate(alpha, "weekly"); template.Notify != nil || template.Group != "" {
+		t.Errorf("expected the replaced template, got %+v", template)
+	}
+
+	templates, err := store.ListScanTemplates(alpha)
+	if err != nil {
+		t.Fatalf("failed to list templates: %v", err)
+	}
+	if len(templates) != 2 || templates[0].Name != "adhoc" || templates[1].Name != "weekly" {
+		t.Errorf("expected adhoc and weekly, got %+v", templates)
+	}
+	if templates, _ := store.ListScanTemplates(beta); len(templates) != 0 {
+		t.Errorf("expected no beta templates, got %+v", templates)
+	}
+
+	if err := store.DeleteScanTemplate(beta, "weekly"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("expected ErrRecordNotFound deleting another tenant's template, got %v", err)
+	}
+	if err := store.DeleteScanTemplate(alpha, "weekly"); err != nil {
+		t.Fatalf("failed to delete template: %v", err)
+	}
+	if _, err := store.GetScanTemplate(alpha, "weekly"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("expected deleted template to be gone, got %v", err)
+	}
+}
+
+func TestRewrapCredentials(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	for _, name := range []string{"alpha", "beta"} {
+		ctx := tenant.WithTenant(context.Background(), name)
+		for _, secret := range []string{"old", "new"} {
+			credential := &models.Credential{Name: secret, Type: "bearer", Secret: []byte(secret)}
+			if err := store.SaveCredential(ctx, credential); err != nil {
+				t.Fatalf("failed to save credential: %v", err)
+			}
+		}
+	}
+
+	rewrap := func(secret []byte) ([]byte, bool, error) {
+		if string(secret) != "old" {
+			return secret, false, nil
+		}
+		return []byte("rewrapped"), true, nil
+	}
+	alpha := tenant.WithTenant(context.Background(), "alpha")
+	changed, err := store.RewrapCredentials(alpha, rewrap)
+	if err != nil {
+		t.Fatalf("failed to rewrap credentials: %v", err)
+	}
+	if changed != 1 {
+		t.Errorf("expected 1 credential of alpha rewrapped, got %d", changed)
+	}
+	changed, err = store.RewrapCredentials(context.Background(), rewrap)
+	if err != nil {
+		t.Fatalf("failed to rewrap credentials: %v", err)
+	}
+	if changed != 1 {
+		t.Errorf("expected the remaining credential of beta rewrapped, got %d", changed)
+	}
+
+	for _, name := range []string{"alpha", "beta"} {
+		ctx := tenant.WithTenant(context.Background(), name)
+		if credential, err := store.GetCredential(ctx, "old"); err != nil || string(credential.Secret) != "rewrapped" {
+			t.Errorf("expected rewrapped secret of %s, got %v, %v", name, credential, err)
+		}
+		if credential, err := store.GetCredential(ctx, "new"); err != nil || string(credential.Secret) != "new" {
+			t.Errorf("expected unchanged secret of %s, got %v, %v", name, credential, err)
+		}
+	}
+
+	failing := func([]byte) ([]byte, bool, error) { return nil, false, errors.New("unknown key") }
+	if _, err := store.RewrapCredentials(context.Background(), failing); err == nil {
+		t.Error("expected the rewrap error")
+	}
+}
+
+func TestSeenFingerprints(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	alpha := tenant.WithTenant(context.Background(), "alpha")
+	beta := tenant.WithTenant(context.Background(), "beta")
+	earlier := &models.ToolExecution{ToolName: "full_scan", Target: "https://example.com"}
+	current := &models.ToolExecution{ToolName: "full_scan", Target: "https://example.com"}
+	for _, exec := range []*models.ToolExecution{earlier, current} {
+		if err := store.CreateToolExecution(alpha, exec); err != nil {
+			t.Fatalf("failed to create execution: %v", err)
+		}
+	}
+	err := store.CreateFindings(alpha, []models.Finding{
+		{ExecutionID: earlier.ID, Scanner: "nikto", Title: "old", Fingerprint: "a"},
+		{ExecutionID: current.ID, Scanner: "nikto", Title: "old", Fingerprint: "a"},
+		{ExecutionID: current.ID, Scanner: "nikto", Title: "new", Fingerprint: "b"},
+	})
+	if err != nil {
+		t.Fatalf("failed to create findings: %v", err)
+	}
+
+	seen, err := store.SeenFingerprints(alpha, []string{"a", "b", "c"}, current.ID)
+	if err != nil {
+		t.Fatalf("failed to look up fingerprints: %v", err)
+	}
+	if !seen["a"] || seen["b"] || seen["c"] || len(seen) != 1 {
+		t.Errorf("expected only a seen before the current execution, got %v", seen)
+	}
+
+	seen, err = store.SeenFingerprints(beta, []string{"a", "b"}, 0)
+	if err != nil {
+		t.Fatalf("failed to look up fingerprints: %v", err)
+	}
+	if len(seen) != 0 {
+		t.Errorf("expected no fingerprints seen by another tenant, got %v", seen)
+	}
+
+	seen, err = store.SeenFingerprints(alpha, nil, current.ID)
+	if err != nil || len(seen) != 0 {
+		t.Errorf("expected no fingerprints for an empty lookup, got %v, %v", seen, err)
+	}
+}
+
+func TestOutputCursors(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	alpha := tenant.WithTenant(context.Background(), "alpha")
+	beta := tenant.WithTenant(context.Background(), "beta")
+
+	exec := &models.ToolExecution{ToolName: "nikto", Success: true}
+	if err := store.CreateToolExecution(alpha, exec); err != nil {
+		t.Fatalf("failed to create execution: %v", err)
+	}
+	cursor := &models.OutputCursor{Token: "abc123", ExecutionID: exec.ID, Line: 10, MaxLines: 10}
+	if err := store.CreateOutputCursor(alpha, cursor); err != nil {
+		t.Fatalf("failed to create cursor: %v", err)
+	}
+	if cursor.Tenant != "alpha" {
+		t.Errorf("expected an alpha cursor, got %q", cursor.Tenant)
+	}
+	if err := store.CreateOutputCursor(alpha, &models.OutputCursor{Token: "abc123", ExecutionID: exec.ID}); err == nil {
+		t.Error("expected tokens to be unique")
+	}
+
+	got, err := store.GetOutputCursor(alpha, "abc123")
+	if err != nil || got.ExecutionID != exec.ID || got.Line != 10 {
+		t.Fatalf("expected the stored cursor, got %+v (err: %v)", got, err)
+	}
+	if _, err := store.GetOutputCursor(beta, "abc123"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("expected cursor hidden from another tenant, got: %v", err)
+	}
+
+	// Purging an execution removes its cursors.
+	if err := store.DeleteToolExecution(alpha, exec.ID); err != nil {
+		t.Fatalf("failed to delete execution: %v", err)
+	}
+	if _, err := store.PurgeDeletedToolExecutions(alpha); err != nil {
+		t.Fatalf("failed to purge executions: %v", err)
+	}
+	if _, err := store.GetOutputCursor(alpha, "abc123"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("expected cursor purged with its execution, got: %v", err)
+	}
+}
+
+func TestScanJobs(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	alpha := tenant.WithTenant(context.Background(), "alpha")
+	beta := tenant.WithTenant(context.Background(), "beta")
+
+	running := &models.ScanJob{ToolName: "nikto", Status: models.JobRunning}
+	queued := &models.ScanJob{ToolName: "nuclei", Status: models.JobQueued}
+	done := &models.ScanJob{ToolName: "wapiti", Status: models.JobCompleted}
+	for _, job := range []*models.ScanJob{running, queued, done} {
+		if err := store.CreateScanJob(alpha, job); err != nil {
+			t.Fatalf("failed to create scan job: %v", err)
+		}
+	}
+	if running.Tenant != "alpha" {
+		t.Errorf("expected an alpha scan job, got %q", running.Tenant)
+	}
+
+	if _, err := store.GetScanJob(beta, running.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("expected scan job hidden from another tenant, got: %v", err)
+	}
+
+	done.Error = "none"
+	if err := store.UpdateScanJob(alpha, done); err != nil {
+		t.Fatalf("failed to update scan job: %v", err)
+	}
+	got, err := store.GetScanJob(alpha, done.ID)
+	if err != nil || got.Error != "none" || got.Status != models.JobCompleted {
+		t.Fatalf("expected the updated scan job, got %+v (err: %v)", got, err)
+	}
+
+	jobs, err := store.GetQueuedScanJobs(context.Background())
+	if err != nil || len(jobs) != 1 || jobs[0].ID != queued.ID {
+		t.Errorf("expected the queued scan job, got %+v (err: %v)", jobs, err)
+	}
+
+	claimed, err := store.ClaimScanJob(context.Background(), queued.ID, "worker-b")
+	if err != nil || claimed.Status != models.JobRunning || claimed.StartedAt == nil || claimed.Worker != "worker-b" {
+		t.Fatalf("expected the queued scan job claimed, got %+v (err: %v)", claimed, err)
+	}
+	if _, err := store.ClaimScanJob(context.Background(), queued.ID, "worker-a"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("expected a scan job claimed once, got: %v", err)
+	}
+	if _, err := store.ClaimScanJob(beta, done.ID, "worker-a"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("expected finished scan jobs not claimed, got: %v", err)
+	}
+
+	// The job claimed by worker-b is left running when worker-a restarts.
+	interrupted, err := store.MarkInterruptedScanJobs(context.Background(), "worker-a")
+	if err != nil || len(interrupted) != 1 || interrupted[0].ID != running.ID {
+		t.Fatalf("expected the running scan job interrupted, got %+v (err: %v)", interrupted, err)
+	}
+	got, err = store.GetScanJob(alpha, running.ID)
+	if err != nil || got.Status != models.JobInterrupted || got.FinishedAt == nil || got.Error == "" {
+		t.Errorf("expected a stored interrupted scan job, got %+v (err: %v)", got, err)
+	}
+	if interrupted, err = store.MarkInterruptedScanJobs(context.Background(), "worker-a"); err != nil || len(interrupted) != 0 {
+		t.Errorf("expected no scan job of worker-a left running, got %+v (err: %v)", interrupted, err)
+	}
+	if interrupted, err = store.MarkInterruptedScanJobs(context.Background(), "worker-b"); err != nil || len(interrupted) != 1 {
+		t.Errorf("expected the scan job of worker-b interrupted, got %+v (err: %v)", interrupted, err)
 	}
 }
@@ -2,19 +2,82 @@ package storage
 
 import (
 	"context"
+	"time"
 
 	"github.com/tb0hdan/wass-mcp/pkg/models"
 )
 
+// Storage persists tool executions and findings. Operations on a context scoped to a tenant
+// (see tenant.WithTenant) only see and modify the data of that tenant.
 type Storage interface {
 	// Tool execution operations
 	CreateToolExecution(ctx context.Context, exec *models.ToolExecution) error
+	UpdateToolExecution(ctx context.Context, exec *models.ToolExecution) error
+	UpdateToolExecutionPhases(ctx context.Context, id uint, phases models.PhaseTimings) error
+	UpdateToolExecutionStream(ctx context.Context, id uint, path string) error
+	MarkInterruptedExecutions(ctx context.Context) ([]models.ToolExecution, error)
 	GetToolExecution(ctx context.Context, id uint) (*models.ToolExecution, error)
 	GetToolExecutions(ctx context.Context, limit, offset int) ([]models.ToolExecution, int64, error)
 	GetToolExecutionsBySession(ctx context.Context, sessionID string) ([]models.ToolExecution, error)
 	GetToolExecutionsByTool(ctx context.Context, toolName string, limit int) ([]models.ToolExecution, error)
+	GetToolExecutionsByHost(ctx context.Context, host string, limit int) ([]models.ToolExecution, error)
+	QueryToolExecutions(ctx context.Context, filter ExecutionFilter) ([]models.ToolExecution, int64, error)
+	ExportToolExecutions(ctx context.Context, filter ExecutionFilter, fn func(*models.ToolExecution) error) error
 	DeleteToolExecution(ctx context.Context, id uint) error
 	DeleteAllToolExecutions(ctx context.Context) error
+	RestoreToolExecution(ctx context.Context, id uint) error
+	PurgeDeletedToolExecutions(ctx context.Context) (int64, error)
+	PruneToolExecutions(ctx context.Context, before time.Time) (int64, error)
+
+	// Finding operations
+	CreateFindings(ctx context.Context, findings []models.Finding) error
+	GetFindingsByExecutions(ctx context.Context, executionIDs []uint) ([]models.Finding, error)
+	QueryFindings(ctx context.Context, filter FindingFilter) ([]models.Finding, int64, error)
+	GetFinding(ctx context.Context, id uint) (*models.Finding, error)
+	UpdateFindingTriage(ctx context.Context, finding *models.Finding) error
+	UpdateFindingEvidence(ctx context.Context, finding *models.Finding) error
+	SeenFingerprints(ctx context.Context, fingerprints []string, excludeExecutionID uint) (map[string]bool, error)
+
+	// Target group operations
+	SaveTargetGroup(ctx context.Context, group *models.TargetGroup) error
+	GetTargetGroup(ctx context.Context, name string) (*models.TargetGroup, error)
+	ListTargetGroups(ctx context.Context) ([]models.TargetGroup, error)
+	DeleteTargetGroup(ctx context.Context, name string) error
+
+	// Scan template operations
+	SaveScanTemplate(ctx context.Context, template *models.ScanTemplate) error
+	GetScanTemplate(ctx context.Context, name string) (*models.ScanTemplate, error)
+	ListScanTemplates(ctx context.Context) ([]models.ScanTemplate, error)
+	DeleteScanTemplate(ctx context.Context, name string) error
+
+	// Suppression rule operations
+	SaveSuppressionRule(ctx context.Context, rule *models.SuppressionRule) error
+	GetSuppressionRule(ctx context.Context, name string) (*models.SuppressionRule, error)
+	ListSuppressionRules(ctx context.Context) ([]models.SuppressionRule, error)
+	DeleteSuppressionRule(ctx context.Context, name string) error
+
+	// Target profile operations
+	SaveTargetProfile(ctx context.Context, profile *models.TargetProfile) error
+	GetTargetProfiles(ctx context.Context, host string) ([]models.TargetProfile, error)
+
+	// Credential operations
+	SaveCredential(ctx context.Context, credential *models.Credential) error
+	GetCredential(ctx context.Context, name string) (*models.Credential, error)
+	ListCredentials(ctx context.Context) ([]models.Credential, error)
+	DeleteCredential(ctx context.Context, name string) error
+	RewrapCredentials(ctx context.Context, rewrap func(secret []byte) ([]byte, bool, error)) (int, error)
+
+	// Output cursor operations
+	CreateOutputCursor(ctx context.Context, cursor *models.OutputCursor) error
+	GetOutputCursor(ctx context.Context, token string) (*models.OutputCursor, error)
+
+	// Scan job operations
+	CreateScanJob(ctx context.Context, job *models.ScanJob) error
+	UpdateScanJob(ctx context.Context, job *models.ScanJob) error
+	GetScanJob(ctx context.Context, id uint) (*models.ScanJob, error)
+	GetQueuedScanJobs(ctx context.Context) ([]models.ScanJob, error)
+	ClaimScanJob(ctx context.Context, id uint, worker string) (*models.ScanJob, error)
+	MarkInterruptedScanJobs(ctx context.Context, worker string) ([]models.ScanJob, error)
 
 	// Lifecycle
 	Close() error
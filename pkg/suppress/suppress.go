@@ -0,0 +1,173 @@
+// Package suppress matches findings against suppression rules, dropping known-accepted noise
+// before findings are stored, scored or summarized.
+package suppress
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/storage"
+)
+
+// ErrNoCriteria is returned for a rule that would match every finding.
+var ErrNoCriteria = errors.New("a suppression rule needs a scanner, template ID, URL pattern or title pattern")
+
+// compiled is a rule with its patterns compiled.
+type compiled struct {
+	rule  models.SuppressionRule
+	title *regexp.Regexp
+	url   *regexp.Regexp
+}
+
+// Matcher matches findings against a set of rules. A nil Matcher matches nothing.
+type Matcher struct {
+	rules []compiled
+}
+
+// Validate checks that rule has a name and at least one criterion and that its patterns compile.
+func Validate(rule models.SuppressionRule) error {
+	_, err := compile(rule)
+	return err
+}
+
+// compile validates rule and compiles its patterns.
+func compile(rule models.SuppressionRule) (compiled, error) {
+	if strings.TrimSpace(rule.Name) == "" {
+		return compiled{}, errors.New("a suppression rule needs a name")
+	}
+	if rule.Scanner == "" && rule.TemplateID == "" && rule.URLPattern == "" && rule.TitlePattern == "" {
+		return compiled{}, fmt.Errorf("rule %s: %w", rule.Name, ErrNoCriteria)
+	}
+
+	result := compiled{rule: rule}
+	var err error
+	if rule.URLPattern != "" {
+		if result.url, err = regexp.Compile(rule.URLPattern); err != nil {
+			return compiled{}, fmt.Errorf("rule %s: invalid url_pattern: %w", rule.Name, err)
+		}
+	}
+	if rule.TitlePattern != "" {
+		if result.title, err = regexp.Compile(rule.TitlePattern); err != nil {
+			return compiled{}, fmt.Errorf("rule %s: invalid title_pattern: %w", rule.Name, err)
+		}
+	}
+
+	return result, nil
+}
+
+// Compile returns a matcher for rules, failing on the first invalid rule.
+func Compile(rules []models.SuppressionRule) (*Matcher, error) {
+	matcher := &Matcher{rules: make([]compiled, 0, len(rules))}
+	for _, rule := range rules {
+		result, err := compile(rule)
+		if err != nil {
+			return nil, err
+		}
+		matcher.rules = append(matcher.rules, result)
+	}
+
+	return matcher, nil
+}
+
+// Load returns a matcher for the configured rules and the stored rules of the tenant of ctx.
+// Stored rules are validated when saved; one that no longer compiles is skipped.
+func Load(ctx context.Context, store storage.Storage, configured []models.SuppressionRule) (*Matcher, error) {
+	matcher, err := Compile(configured)
+	if err != nil {
+		return nil, err
+	}
+	stored, err := store.ListSuppressionRules(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list suppression rules: %w", err)
+	}
+	for _, rule := range stored {
+		if result, err := compile(rule); err == nil {
+			matcher.rules = append(matcher.rules, result)
+		}
+	}
+
+	return matcher, nil
+}
+
+// LoadFile reads a JSON array of rules from path, as configured with --suppressions, and
+// validates them.
+func LoadFile(path string) ([]models.SuppressionRule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var rules []models.SuppressionRule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("failed to parse suppression rules: %w", err)
+	}
+	seen := make(map[string]struct{}, len(rules))
+	for _, rule := range rules {
+		if err := Validate(rule); err != nil {
+			return nil, err
+		}
+		if _, ok := seen[rule.Name]; ok {
+			return nil, fmt.Errorf("duplicate suppression rule %s", rule.Name)
+		}
+		seen[rule.Name] = struct{}{}
+	}
+
+	return rules, nil
+}
+
+// Match returns the first rule matching finding.
+func (m *Matcher) Match(finding models.Finding) (models.SuppressionRule, bool) {
+	if m == nil {
+		return models.SuppressionRule{}, false
+	}
+	for _, rule := range m.rules {
+		if rule.matches(finding) {
+			return rule.rule, true
+		}
+	}
+
+	return models.SuppressionRule{}, false
+}
+
+// Suppressed reports whether a rule matches finding.
+func (m *Matcher) Suppressed(finding models.Finding) bool {
+	_, ok := m.Match(finding)
+	return ok
+}
+
+// Filter returns the findings no rule matches and the number of suppressed findings.
+func (m *Matcher) Filter(found []models.Finding) ([]models.Finding, int) {
+	if m == nil || len(m.rules) == 0 {
+		return found, 0
+	}
+
+	kept := make([]models.Finding, 0, len(found))
+	for _, finding := range found {
+		if !m.Suppressed(finding) {
+			kept = append(kept, finding)
+		}
+	}
+
+	return kept, len(found) - len(kept)
+}
+
+// matches reports whether every set criterion of the rule matches finding.
+func (c compiled) matches(finding models.Finding) bool {
+	switch {
+	case c.rule.Scanner != "" && !strings.EqualFold(c.rule.Scanner, finding.Scanner):
+		return false
+	case c.rule.TemplateID != "" && !strings.EqualFold(c.rule.TemplateID, finding.TemplateID):
+		return false
+	case c.url != nil && !c.url.MatchString(finding.URL):
+		return false
+	case c.title != nil && !c.title.MatchString(finding.Title):
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,108 @@
+package suppress
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/storage"
+	"github.com/tb0hdan/wass-mcp/pkg/tenant"
+	"github.com/tb0hdan/wass-mcp/pkg/types"
+)
+
+type SuppressTestSuite struct {
+	suite.Suite
+}
+
+func (s *SuppressTestSuite) TestValidate() {
+	s.NoError(Validate(models.SuppressionRule{Name: "health", URLPattern: `/health$`}))
+	s.ErrorContains(Validate(models.SuppressionRule{Scanner: "nikto"}), "needs a name")
+	s.ErrorIs(Validate(models.SuppressionRule{Name: "everything"}), ErrNoCriteria)
+	s.ErrorContains(Validate(models.SuppressionRule{Name: "broken", TitlePattern: `(`}), "invalid title_pattern")
+	s.ErrorContains(Validate(models.SuppressionRule{Name: "broken", URLPattern: `[`}), "invalid url_pattern")
+}
+
+func (s *SuppressTestSuite) TestFilter() {
+	matcher, err := Compile([]models.SuppressionRule{
+		{Name: "health-headers", Scanner: "shcheck", URLPattern: `/health$`, TitlePattern: `(?i)missing`},
+		{Name: "tech", TemplateID: "tech-detect"},
+	})
+	s.Require().NoError(err)
+
+	found := []models.Finding{
+		{Scanner: "shcheck", Severity: types.SeverityLow, Title: "Missing security header: X-Frame-Options", URL: "http://example.com/health"},
+		{Scanner: "shcheck", Severity: types.SeverityLow, Title: "Missing security header: X-Frame-Options", URL: "http://example.com/"},
+		{Scanner: "nikto", Severity: types.SeverityLow, Title: "Missing header", URL: "http://example.com/health"},
+		{Scanner: "nuclei", Severity: types.SeverityInfo, TemplateID: "Tech-Detect", Title: "nginx"},
+	}
+
+	kept, suppressed := matcher.Filter(found)
+	s.Equal(2, suppressed)
+	s.Equal([]models.Finding{found[1], found[2]}, kept)
+
+	rule, ok := matcher.Match(found[3])
+	s.True(ok)
+	s.Equal("tech", rule.Name)
+}
+
+func (s *SuppressTestSuite) TestNilMatcher() {
+	var matcher *Matcher
+	found := []models.Finding{{Title: "anything"}}
+
+	kept, suppressed := matcher.Filter(found)
+	s.Equal(found, kept)
+	s.Zero(suppressed)
+	s.False(matcher.Suppressed(found[0]))
+}
+
+func (s *SuppressTestSuite) TestLoad() {
+	store, err := storage.NewSQLiteStorage(storage.Config{DatabasePath: filepath.Join(s.T().TempDir(), "test.db")})
+	s.Require().NoError(err)
+	defer store.Close()
+
+	ctx := tenant.WithTenant(context.Background(), "acme")
+	s.Require().NoError(store.SaveSuppressionRule(ctx, &models.SuppressionRule{Name: "tech", TemplateID: "tech-detect"}))
+	s.Require().NoError(store.SaveSuppressionRule(tenant.WithTenant(context.Background(), "other"),
+		&models.SuppressionRule{Name: "all-nikto", Scanner: "nikto"}))
+
+	matcher, err := Load(ctx, store, []models.SuppressionRule{{Name: "health", URLPattern: `/health$`}})
+	s.Require().NoError(err)
+	s.True(matcher.Suppressed(models.Finding{URL: "http://example.com/health"}))
+	s.True(matcher.Suppressed(models.Finding{Scanner: "nuclei", TemplateID: "tech-detect"}))
+	s.False(matcher.Suppressed(models.Finding{Scanner: "nikto", Title: "Outdated server"}))
+
+	_, err = Load(ctx, store, []models.SuppressionRule{{Name: "empty"}})
+	s.ErrorIs(err, ErrNoCriteria)
+}
+
+func (s *SuppressTestSuite) TestLoadFile() {
+	dir := s.T().TempDir()
+	write := func(name, data string) string {
+		path := filepath.Join(dir, name)
+		s.Require().NoError(os.WriteFile(path, []byte(data), 0o600))
+		return path
+	}
+
+	rules, err := LoadFile(write("rules.json",
+		`[{"name":"health","url_pattern":"/health$","reason":"Load balancer probe"},{"name":"tech","template_id":"tech-detect"}]`))
+	s.Require().NoError(err)
+	s.Require().Len(rules, 2)
+	s.Equal("Load balancer probe", rules[0].Reason)
+	s.Equal("tech-detect", rules[1].TemplateID)
+
+	_, err = LoadFile(write("duplicate.json", `[{"name":"a","scanner":"nikto"},{"name":"a","scanner":"nuclei"}]`))
+	s.ErrorContains(err, "duplicate suppression rule a")
+
+	_, err = LoadFile(write("broken.json", `{`))
+	s.ErrorContains(err, "failed to parse suppression rules")
+
+	_, err = LoadFile(filepath.Join(dir, "missing.json"))
+	s.Error(err)
+}
+
+func TestSuppressTestSuite(t *testing.T) {
+	suite.Run(t, new(SuppressTestSuite))
+}
@@ -0,0 +1,138 @@
+package target
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrInvalidRange is returned for host range expressions whose bounds cannot be expanded.
+	ErrInvalidRange = errors.New("invalid host range")
+	// ErrTooManyTargets is returned when expressions expand to more targets than allowed.
+	ErrTooManyTargets = errors.New("too many targets")
+)
+
+var (
+	// ipRangeRe matches IPv4 ranges of the last octet, e.g. 10.0.0.1-20.
+	ipRangeRe = regexp.MustCompile(`^(\d{1,3}\.\d{1,3}\.\d{1,3}\.)(\d{1,3})-(\d{1,3})$`)
+	// nameRangeRe matches host names with one numeric range, e.g. web[1-3].example.com or
+	// node[01-12].
+	nameRangeRe = regexp.MustCompile(`^([^\[\]]*)\[(\d+)-(\d+)\]([^\[\]]*)$`)
+)
+
+// Expand returns the targets of expressions, in order and without duplicates. An expression is
+// a host input as accepted by Parse, a CIDR network (10.0.0.0/30), an IPv4 range of the last
+// octet (10.0.0.1-20) or a host name with a numeric range (web[1-3].example.com, zero-padded like
+// its lower bound: node[01-12]). IPv4 networks larger than /31 leave out their network and
+// broadcast addresses. It fails with ErrTooManyTargets past limit targets.
+func Expand(expressions []string, limit int) ([]string, error) {
+	var targets []string
+	seen := make(map[string]struct{})
+	add := func(target string) error {
+		if _, ok := seen[target]; ok {
+			return nil
+		}
+		if len(targets) >= limit {
+			return fmt.Errorf("%w: more than %d", ErrTooManyTargets, limit)
+		}
+		seen[target] = struct{}{}
+		targets = append(targets, target)
+		return nil
+	}
+
+	for _, expression := range expressions {
+		expression = strings.TrimSpace(expression)
+		expanded, err := expand(expression, limit)
+		if err != nil {
+			return nil, err
+		}
+		for _, target := range expanded {
+			if err := add(target); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return targets, nil
+}
+
+// expand returns the targets of a single expression, at most limit of them.
+func expand(expression string, limit int) ([]string, error) {
+	if !strings.Contains(expression, "://") {
+		if prefix, err := netip.ParsePrefix(expression); err == nil {
+			return expandPrefix(prefix.Masked(), limit)
+		}
+	}
+	if match := ipRangeRe.FindStringSubmatch(expression); match != nil {
+		return expandRange(expression, match[1], match[2], match[3], "", limit, func(target string) bool {
+			_, err := netip.ParseAddr(target)
+			return err == nil
+		})
+	}
+	if match := nameRangeRe.FindStringSubmatch(expression); match != nil {
+		return expandRange(expression, match[1], match[2], match[3], match[4], limit, nil)
+	}
+
+	return []string{expression}, nil
+}
+
+// expandPrefix returns the addresses of prefix, at most limit of them.
+func expandPrefix(prefix netip.Prefix, limit int) ([]string, error) {
+	hostBits := prefix.Addr().BitLen() - prefix.Bits()
+	if hostBits >= strconv.IntSize-1 || 1<<hostBits > limit+2 {
+		return nil, fmt.Errorf("%w: %s has more than %d addresses", ErrTooManyTargets, prefix, limit)
+	}
+	// The network and broadcast addresses of IPv4 networks are not hosts.
+	skipEnds := prefix.Addr().Is4() && hostBits > 1
+
+	var targets []string
+	for addr := prefix.Addr(); addr.IsValid() && prefix.Contains(addr); addr = addr.Next() {
+		targets = append(targets, addr.String())
+	}
+	if skipEnds {
+		targets = targets[1 : len(targets)-1]
+	}
+	if len(targets) > limit {
+		return nil, fmt.Errorf("%w: %s has more than %d addresses", ErrTooManyTargets, prefix, limit)
+	}
+
+	return targets, nil
+}
+
+// expandRange returns prefix+n+suffix for every n from low to high, zero-padded to the width of
+// low when it starts with 0, at most limit of them. valid, when set, checks each target.
+func expandRange(expression, prefix, low, high, suffix string, limit int, valid func(string) bool) ([]string, error) {
+	first, err := strconv.Atoi(low)
+	if err != nil {
+		return nil, fmt.Errorf("%w %q: %w", ErrInvalidRange, expression, err)
+	}
+	last, err := strconv.Atoi(high)
+	if err != nil {
+		return nil, fmt.Errorf("%w %q: %w", ErrInvalidRange, expression, err)
+	}
+	if first > last {
+		return nil, fmt.Errorf("%w %q: %d is above %d", ErrInvalidRange, expression, first, last)
+	}
+	if last-first >= limit {
+		return nil, fmt.Errorf("%w: %s has more than %d targets", ErrTooManyTargets, expression, limit)
+	}
+	width := 0
+	if len(low) > 1 && strings.HasPrefix(low, "0") {
+		width = len(low)
+	}
+
+	targets := make([]string, 0, last-first+1)
+	for n := first; n <= last; n++ {
+		target := fmt.Sprintf("%s%0*d%s", prefix, width, n, suffix)
+		if valid != nil && !valid(target) {
+			return nil, fmt.Errorf("%w %q: %s is not an address", ErrInvalidRange, expression, target)
+		}
+		targets = append(targets, target)
+	}
+
+	return targets, nil
+}
@@ -0,0 +1,56 @@
+package target
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ExpandTestSuite struct {
+	suite.Suite
+}
+
+func (s *ExpandTestSuite) TestExpand() {
+	for name, tc := range map[string]struct {
+		expressions []string
+		expected    []string
+	}{
+		"hosts":              {[]string{"example.com", " https://app.example.com:8443/ ", "[::1]"}, []string{"example.com", "https://app.example.com:8443/", "[::1]"}},
+		"IPv4 network":       {[]string{"10.0.0.0/30"}, []string{"10.0.0.1", "10.0.0.2"}},
+		"unmasked":           {[]string{"10.0.0.9/29"}, []string{"10.0.0.9", "10.0.0.10", "10.0.0.11", "10.0.0.12", "10.0.0.13", "10.0.0.14"}},
+		"point to point":     {[]string{"10.0.0.0/31"}, []string{"10.0.0.0", "10.0.0.1"}},
+		"single address":     {[]string{"10.0.0.7/32"}, []string{"10.0.0.7"}},
+		"IPv6 network":       {[]string{"fd00::/126"}, []string{"fd00::", "fd00::1", "fd00::2", "fd00::3"}},
+		"IPv4 range":         {[]string{"192.168.1.8-10"}, []string{"192.168.1.8", "192.168.1.9", "192.168.1.10"}},
+		"name range":         {[]string{"web[1-3].example.com"}, []string{"web1.example.com", "web2.example.com", "web3.example.com"}},
+		"zero padded":        {[]string{"node[08-10]"}, []string{"node08", "node09", "node10"}},
+		"duplicates dropped": {[]string{"10.0.0.1", "10.0.0.0/30", "10.0.0.1-3"}, []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"}},
+	} {
+		targets, err := Expand(tc.expressions, 16)
+		s.Require().NoError(err, name)
+		s.Equal(tc.expected, targets, name)
+	}
+}
+
+func (s *ExpandTestSuite) TestExpand_Errors() {
+	for expression, expected := range map[string]error{
+		"10.0.0.0/24":            ErrTooManyTargets,
+		"fd00::/64":              ErrTooManyTargets,
+		"::/0":                   ErrTooManyTargets,
+		"10.0.0.1-200":           ErrTooManyTargets,
+		"web[1-100].example.com": ErrTooManyTargets,
+		"10.0.0.20-10":           ErrInvalidRange,
+		"10.0.0.250-256":         ErrInvalidRange,
+		"web[3-1]":               ErrInvalidRange,
+	} {
+		_, err := Expand([]string{expression}, 16)
+		s.ErrorIs(err, expected, expression)
+	}
+
+	_, err := Expand([]string{"10.0.0.0/28", "10.0.1.1-3"}, 16)
+	s.ErrorIs(err, ErrTooManyTargets, "the limit applies to all expressions together")
+}
+
+func TestExpandTestSuite(t *testing.T) {
+	suite.Run(t, new(ExpandTestSuite))
+}
@@ -0,0 +1,131 @@
+// Package target parses scan target inputs and builds the URLs scanners are pointed at, so that
+// every scanner, full_scan, redirect normalization and port discovery address a target the same
+// way: scheme defaults, default port omission, bracketed IPv6 literals, paths and virtual hosts.
+package target
+
+import (
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/tb0hdan/wass-mcp/pkg/types"
+)
+
+// Target is a scan target.
+type Target struct {
+	// Host is a hostname or an IP address, IPv6 addresses without brackets.
+	Host string
+	// Path is the base path scanned, empty for the root.
+	Path string
+	// Port is the TCP port, 0 for the default port of the scheme.
+	Port int
+	// Scheme is types.SchemeHTTP or types.SchemeHTTPS, empty for HTTP.
+	Scheme string
+	// Vhost is the virtual host sent in the Host header instead of Host, if any.
+	Vhost string
+}
+
+// Parse parses a host input: a hostname, an IP address (IPv6 optionally in brackets), a
+// host:port pair or a URL with scheme, port and path. Fields missing from the input are left
+// empty, and inputs that cannot be parsed, including those with ports outside 1-65535, are
+// returned as the host, which host validation then rejects.
+func Parse(input string) Target {
+	if !strings.Contains(input, "://") {
+		if host, port, err := net.SplitHostPort(input); err == nil {
+			if number, ok := parsePort(port); ok {
+				return Target{Host: host, Port: number}
+			}
+		}
+		if strings.HasPrefix(input, "[") && strings.HasSuffix(input, "]") {
+			return Target{Host: strings.Trim(input, "[]")}
+		}
+		return Target{Host: input}
+	}
+
+	parsed, err := url.Parse(input)
+	if err != nil {
+		return Target{Host: input}
+	}
+
+	result := Target{
+		Host:   parsed.Hostname(),
+		Scheme: strings.ToLower(parsed.Scheme),
+	}
+	if path := parsed.EscapedPath(); path != "/" {
+		result.Path = path
+	}
+	if port := parsed.Port(); port != "" {
+		number, ok := parsePort(port)
+		if !ok {
+			return Target{Host: input}
+		}
+		result.Port = number
+	}
+
+	return result
+}
+
+// parsePort parses a TCP port number, reporting false for non-numeric and out of range ports.
+func parsePort(port string) (int, bool) {
+	number, err := strconv.Atoi(port)
+	if err != nil || number < 1 || number > 65535 {
+		return 0, false
+	}
+
+	return number, true
+}
+
+// DefaultPort returns the default port of a scheme: 443 for HTTPS, 80 otherwise.
+func DefaultPort(scheme string) int {
+	if scheme == types.SchemeHTTPS {
+		return types.HTTPSPort
+	}
+
+	return types.DefaultPort
+}
+
+// URL returns the target URL, omitting the port when it is the default for the scheme
+// (80 for HTTP, 443 for HTTPS) and bracketing IPv6 addresses.
+func (t Target) URL() string {
+	scheme := t.Scheme
+	if scheme == "" {
+		scheme = types.SchemeHTTP
+	}
+
+	host := t.Host
+	if t.Port != 0 && t.Port != DefaultPort(scheme) {
+		host = net.JoinHostPort(host, strconv.Itoa(t.Port))
+	} else if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+
+	path := t.Path
+	if path != "" && !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	if path == "/" {
+		path = ""
+	}
+
+	return scheme + "://" + host + path
+}
+
+// RequestURL returns the URL to send HTTP requests to, the target URL with at least a root path.
+func (t Target) RequestURL() string {
+	if t.Path == "" || t.Path == "/" {
+		return t.URL() + "/"
+	}
+
+	return t.URL()
+}
+
+// HostHeader returns the "Host: <vhost>" header line for scanners taking extra request headers,
+// or an empty string without a virtual host.
+func (t Target) HostHeader() string {
+	if t.Vhost == "" {
+		return ""
+	}
+
+	return "Host: " + t.Vhost
+}
@@ -0,0 +1,148 @@
+package target
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/types"
+)
+
+type TargetTestSuite struct {
+	suite.Suite
+}
+
+// Parse tests.
+
+func (s *TargetTestSuite) TestParse() {
+	for input, expected := range map[string]Target{
+		"":                              {},
+		"example.com":                   {Host: "example.com"},
+		"192.168.1.1":                   {Host: "192.168.1.1"},
+		"::1":                           {Host: "::1"},
+		"[::1]":                         {Host: "::1"},
+		"example.com:8080":              {Host: "example.com", Port: 8080},
+		"[::1]:8443":                    {Host: "::1", Port: 8443},
+		"https://example.com":           {Host: "example.com", Scheme: types.SchemeHTTPS},
+		"http://example.com":            {Host: "example.com", Scheme: types.SchemeHTTP},
+		"HTTPS://example.com/":          {Host: "example.com", Scheme: types.SchemeHTTPS},
+		"https://example.com:8443":      {Host: "example.com", Port: 8443, Scheme: types.SchemeHTTPS},
+		"https://example.com/path":      {Host: "example.com", Path: "/path", Scheme: types.SchemeHTTPS},
+		"http://[2001:db8::1]:81/a%20b": {Host: "2001:db8::1", Path: "/a%20b", Port: 81, Scheme: types.SchemeHTTP},
+	} {
+		s.Equal(expected, Parse(input), input)
+	}
+}
+
+func (s *TargetTestSuite) TestParse_Invalid() {
+	s.Equal(Target{Host: "http://exa mple.com:port"}, Parse("http://exa mple.com:port"))
+	for _, input := range []string{"example.com:0", "example.com:-1", "example.com:65536", "http://example.com:99999/"} {
+		s.Equal(Target{Host: input}, Parse(input), "out of range ports are not parsed")
+	}
+}
+
+// URL tests.
+
+func (s *TargetTestSuite) TestURL() {
+	for expected, target := range map[string]Target{
+		"http://localhost":             {Host: "localhost", Port: 80, Scheme: types.SchemeHTTP},
+		"https://example.com":          {Host: "example.com", Port: 443, Scheme: types.SchemeHTTPS},
+		"http://example.com:8080":      {Host: "example.com", Port: 8080, Scheme: types.SchemeHTTP},
+		"https://example.com:8443":     {Host: "example.com", Port: 8443, Scheme: types.SchemeHTTPS},
+		"http://example.com:443":       {Host: "example.com", Port: 443, Scheme: types.SchemeHTTP},
+		"https://[::1]":                {Host: "::1", Port: 443, Scheme: types.SchemeHTTPS},
+		"http://[::1]":                 {Host: "::1", Port: 80, Scheme: types.SchemeHTTP},
+		"http://[::1]:8080":            {Host: "::1", Port: 8080, Scheme: types.SchemeHTTP},
+		"http://example.com":           {Host: "example.com", Port: 80},
+		"https://example.com/app":      {Host: "example.com", Path: "/app", Scheme: types.SchemeHTTPS},
+		"http://example.com:81/app/":   {Host: "example.com", Path: "app/", Port: 81},
+		"https://[2001:db8::1]:8443/x": {Host: "2001:db8::1", Path: "/x", Port: 8443, Scheme: types.SchemeHTTPS},
+	} {
+		s.Equal(expected, target.URL(), "%+v", target)
+	}
+}
+
+func (s *TargetTestSuite) TestURL_RootPath() {
+	s.Equal("http://example.com", Target{Host: "example.com", Path: "/"}.URL())
+}
+
+func (s *TargetTestSuite) TestRequestURL() {
+	s.Equal("https://example.com/", Target{Host: "example.com", Scheme: types.SchemeHTTPS}.RequestURL())
+	s.Equal("http://[::1]:8080/", Target{Host: "::1", Port: 8080}.RequestURL())
+	s.Equal("http://example.com/app", Target{Host: "example.com", Path: "/app"}.RequestURL())
+}
+
+func (s *TargetTestSuite) TestURL_RoundTrip() {
+	for _, input := range []string{
+		"http://example.com", "https://example.com:8443/app", "http://[::1]:8080", "https://[2001:db8::1]/x",
+	} {
+		s.Equal(input, Parse(input).URL())
+	}
+}
+
+// Vhost and default port tests.
+
+func (s *TargetTestSuite) TestHostHeader() {
+	s.Empty(Target{Host: "192.0.2.1"}.HostHeader())
+	s.Equal("Host: example.com", Target{Host: "192.0.2.1", Vhost: "example.com"}.HostHeader())
+}
+
+func (s *TargetTestSuite) TestDefaultPort() {
+	s.Equal(types.HTTPSPort, DefaultPort(types.SchemeHTTPS))
+	s.Equal(types.DefaultPort, DefaultPort(types.SchemeHTTP))
+	s.Equal(types.DefaultPort, DefaultPort(""))
+}
+
+func TestTargetTestSuite(t *testing.T) {
+	suite.Run(t, new(TargetTestSuite))
+}
+
+// FuzzParse checks that any host input parses without panicking into a target with a valid port,
+// and that targets of valid hosts survive a round trip through their URL.
+func FuzzParse(f *testing.F) {
+	for _, seed := range []string{
+		"", "example.com", "example.com:8080", "[::1]", "[::1]:8443", "::1", "http://", "https://example.com:8443/app?q=1",
+		"http://example.com:99999", "example.com:-1", "HTTP://EXAMPLE.COM/a%2Fb", "http://[fe80::1%25eth0]/", "\x00:\xff",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		parsed := Parse(input)
+		if parsed.Port < 0 || parsed.Port > 65535 {
+			t.Fatalf("Parse(%q) port %d out of range", input, parsed.Port)
+		}
+		if parsed.Host == "" || !validHost(parsed.Host) || (parsed.Scheme != "" && parsed.Scheme != types.SchemeHTTP && parsed.Scheme != types.SchemeHTTPS) {
+			return
+		}
+
+		again := Parse(parsed.URL())
+		if again.Host != parsed.Host || again.Path != parsed.Path || effectivePort(again) != effectivePort(parsed) {
+			t.Fatalf("Parse(%q) = %+v, but its URL %q parses to %+v", input, parsed, parsed.URL(), again)
+		}
+	})
+}
+
+// validHost reports whether host is an IP address or a DNS name, as the scan tools validate.
+func validHost(host string) bool {
+	if net.ParseIP(host) != nil {
+		return true
+	}
+	for _, label := range strings.Split(host, ".") {
+		if label == "" || len(label) > 63 || strings.Trim(label, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-") != "" {
+			return false
+		}
+	}
+
+	return true
+}
+
+// effectivePort returns the port target is reached on.
+func effectivePort(target Target) int {
+	if target.Port != 0 {
+		return target.Port
+	}
+
+	return DefaultPort(target.Scheme)
+}
@@ -0,0 +1,229 @@
+// Package tenant isolates the data of teams sharing a server. Every tenant authenticates to the
+// MCP endpoint with its own API keys, and the storage layer scopes executions and findings to the
+// tenant found in the request context. Keys have a role: read-only keys may browse stored data
+// but not launch scans or modify history.
+package tenant
+
+import (
+	"bufio"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Roles of API keys.
+const (
+	// RoleOperator keys may use every tool.
+	RoleOperator = "operator"
+	// RoleReadOnly keys may browse history, findings and reports, but not launch scans or
+	// modify stored data.
+	RoleReadOnly = "read-only"
+)
+
+// CodeForbidden is the JSON-RPC error code of requests rejected because of the key role.
+const CodeForbidden = -32003
+
+const (
+	// extraKey is the TokenInfo.Extra key holding the tenant name.
+	extraKey = "tenant"
+	// roleKey is the TokenInfo.Extra key holding the key role.
+	roleKey = "role"
+	// tokenLifetime is how long a verified key is valid for. API keys do not expire, but the
+	// bearer token middleware rejects tokens without an expiration; keys are verified again on
+	// every request.
+	tokenLifetime = time.Hour
+)
+
+var (
+	// ErrInvalidKeys is returned for malformed API key lists.
+	ErrInvalidKeys = errors.New("invalid tenant keys")
+	// nameRe matches valid tenant names.
+	nameRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]{0,63}$`)
+)
+
+// contextKey is the context key for the tenant name.
+type contextKey struct{}
+
+// roleContextKey is the context key for the role of the request key.
+type roleContextKey struct{}
+
+// WithTenant returns a copy of ctx scoped to the named tenant.
+func WithTenant(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, contextKey{}, name)
+}
+
+// ValidName reports whether name can name a tenant: a short identifier that is also safe as a
+// path element.
+func ValidName(name string) bool {
+	return nameRe.MatchString(name)
+}
+
+// FromContext returns the tenant ctx is scoped to. Contexts without a tenant, such as those of
+// single-tenant deployments and of server maintenance, are not scoped.
+func FromContext(ctx context.Context) (string, bool) {
+	name, ok := ctx.Value(contextKey{}).(string)
+	return name, ok
+}
+
+// WithRole returns a copy of ctx carrying the role of the request key.
+func WithRole(ctx context.Context, role string) context.Context {
+	return context.WithValue(ctx, roleContextKey{}, role)
+}
+
+// RoleFromContext returns the role of the request key, or an empty string for requests not
+// authenticated with a key, which are not restricted.
+func RoleFromContext(ctx context.Context) string {
+	role, _ := ctx.Value(roleContextKey{}).(string)
+	return role
+}
+
+// Authorize rejects read-only requests attempting action, such as "launch scans", with a
+// structured JSON-RPC error carrying CodeForbidden.
+func Authorize(ctx context.Context, action string) error {
+	role := RoleFromContext(ctx)
+	if role != RoleReadOnly {
+		return nil
+	}
+
+	data, _ := json.Marshal(map[string]string{
+		"action":        action,
+		"required_role": RoleOperator,
+		"role":          role,
+	})
+
+	return &jsonrpc.Error{
+		Code:    CodeForbidden,
+		Message: fmt.Sprintf("forbidden: %s keys cannot %s", role, action),
+		Data:    data,
+	}
+}
+
+// RequireOperator wraps a tool handler so that read-only requests are rejected with
+// Authorize before the handler runs.
+func RequireOperator[In, Out any](action string, handler mcp.ToolHandlerFor[In, Out]) mcp.ToolHandlerFor[In, Out] {
+	return func(ctx context.Context, req *mcp.CallToolRequest, input In) (*mcp.CallToolResult, Out, error) {
+		if err := Authorize(ctx, action); err != nil {
+			var zero Out
+			return nil, zero, err
+		}
+		return handler(ctx, req, input)
+	}
+}
+
+// Key is the tenant and role an API key authenticates.
+type Key struct {
+	Role   string
+	Tenant string
+}
+
+// Keys maps API keys to the tenant and role they authenticate.
+type Keys map[string]Key
+
+// LoadKeys reads API keys from a file, see ParseKeys.
+func LoadKeys(path string) (Keys, error) {
+	file, err := os.Open(path) //nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("failed to open tenant keys: %w", err)
+	}
+	defer file.Close()
+
+	return ParseKeys(file)
+}
+
+// ParseKeys reads API keys, one "tenant:key[:role]" entry per line, where role is RoleOperator
+// (the default) or RoleReadOnly. Blank lines and lines starting with # are ignored. A tenant may
+// have several keys, but a key belongs to a single tenant.
+func ParseKeys(r io.Reader) (Keys, error) {
+	keys := make(Keys)
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Split(text, ":")
+		for i := range fields {
+			fields[i] = strings.TrimSpace(fields[i])
+		}
+		if len(fields) < 2 || len(fields) > 3 || fields[1] == "" {
+			return nil, fmt.Errorf("%w: line %d: expected tenant:key[:role]", ErrInvalidKeys, line)
+		}
+		name, key, role := fields[0], fields[1], RoleOperator
+		if len(fields) == 3 {
+			role = fields[2]
+		}
+		switch {
+		case !ValidName(name):
+			return nil, fmt.Errorf("%w: line %d: invalid tenant name %q", ErrInvalidKeys, line, name)
+		case role != RoleOperator && role != RoleReadOnly:
+			return nil, fmt.Errorf("%w: line %d: invalid role %q", ErrInvalidKeys, line, role)
+		}
+		if _, exists := keys[key]; exists {
+			return nil, fmt.Errorf("%w: line %d: duplicate key", ErrInvalidKeys, line)
+		}
+		keys[key] = Key{Role: role, Tenant: name}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read tenant keys: %w", err)
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%w: no keys", ErrInvalidKeys)
+	}
+
+	return keys, nil
+}
+
+// Verifier returns a bearer token verifier accepting the API keys. The tenant and role of a
+// verified key are carried in the token info, from which Middleware scopes the request.
+func (k Keys) Verifier() auth.TokenVerifier {
+	return func(_ context.Context, token string, _ *http.Request) (*auth.TokenInfo, error) {
+		// Compare against every key so that timing does not reveal which keys exist.
+		var found Key
+		for key, entry := range k {
+			if subtle.ConstantTimeCompare([]byte(token), []byte(key)) == 1 {
+				found = entry
+			}
+		}
+		if found.Tenant == "" {
+			return nil, auth.ErrInvalidToken
+		}
+
+		return &auth.TokenInfo{
+			Expiration: time.Now().Add(tokenLifetime),
+			Extra:      map[string]any{extraKey: found.Tenant, roleKey: found.Role},
+			UserID:     found.Tenant,
+		}, nil
+	}
+}
+
+// Middleware scopes MCP requests authenticated by a tenant key to that tenant and role. Requests
+// without tenant token info are passed through unscoped and unrestricted.
+func Middleware() mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if extra := req.GetExtra(); extra != nil && extra.TokenInfo != nil {
+				if name, ok := extra.TokenInfo.Extra[extraKey].(string); ok {
+					ctx = WithTenant(ctx, name)
+				}
+				if role, ok := extra.TokenInfo.Extra[roleKey].(string); ok {
+					ctx = WithRole(ctx, role)
+				}
+			}
+			return next(ctx, method, req)
+		}
+	}
+}
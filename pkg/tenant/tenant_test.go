@@ -0,0 +1,148 @@
+package tenant
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/suite"
+)
+
+type TenantTestSuite struct {
+	suite.Suite
+}
+
+func (s *TenantTestSuite) TestContext() {
+	_, ok := FromContext(context.Background())
+	s.False(ok)
+
+	name, ok := FromContext(WithTenant(context.Background(), "alpha"))
+	s.True(ok)
+	s.Equal("alpha", name)
+}
+
+func (s *TenantTestSuite) TestParseKeys() {
+	keys, err := ParseKeys(strings.NewReader(`
+# team keys
+alpha: alpha-key-1
+alpha:alpha-key-2:operator
+beta:beta-key:read-only
+`))
+	s.Require().NoError(err)
+	s.Equal(Keys{
+		"alpha-key-1": {Role: RoleOperator, Tenant: "alpha"},
+		"alpha-key-2": {Role: RoleOperator, Tenant: "alpha"},
+		"beta-key":    {Role: RoleReadOnly, Tenant: "beta"},
+	}, keys)
+}
+
+func (s *TenantTestSuite) TestParseKeys_Invalid() {
+	for _, input := range []string{
+		"",
+		"# only comments",
+		"alpha",
+		"alpha:",
+		"bad name:key",
+		"alpha:key\nbeta:key",
+		"alpha:key:admin",
+		"alpha:key:read-only:extra",
+	} {
+		_, err := ParseKeys(strings.NewReader(input))
+		s.ErrorIs(err, ErrInvalidKeys, input)
+	}
+}
+
+func (s *TenantTestSuite) TestLoadKeys() {
+	path := filepath.Join(s.T().TempDir(), "keys")
+	s.Require().NoError(os.WriteFile(path, []byte("alpha:secret\n"), 0o600))
+
+	keys, err := LoadKeys(path)
+	s.Require().NoError(err)
+	s.Equal(Keys{"secret": {Role: RoleOperator, Tenant: "alpha"}}, keys)
+
+	_, err = LoadKeys(filepath.Join(s.T().TempDir(), "missing"))
+	s.Error(err)
+}
+
+func (s *TenantTestSuite) TestVerifier() {
+	verify := Keys{
+		"alpha-key": {Role: RoleOperator, Tenant: "alpha"},
+		"beta-key":  {Role: RoleReadOnly, Tenant: "beta"},
+	}.Verifier()
+
+	info, err := verify(context.Background(), "beta-key", nil)
+	s.Require().NoError(err)
+	s.Equal("beta", info.UserID)
+	s.Equal("beta", info.Extra[extraKey])
+	s.Equal(RoleReadOnly, info.Extra[roleKey])
+	s.True(info.Expiration.After(time.Now()))
+
+	_, err = verify(context.Background(), "wrong-key", nil)
+	s.ErrorIs(err, auth.ErrInvalidToken)
+	_, err = verify(context.Background(), "", nil)
+	s.ErrorIs(err, auth.ErrInvalidToken)
+}
+
+func (s *TenantTestSuite) TestMiddleware() {
+	var (
+		scoped bool
+		name   string
+		role   string
+	)
+	handler := Middleware()(func(ctx context.Context, _ string, _ mcp.Request) (mcp.Result, error) {
+		name, scoped = FromContext(ctx)
+		role = RoleFromContext(ctx)
+		return nil, nil
+	})
+
+	info, err := Keys{"alpha-key": {Role: RoleReadOnly, Tenant: "alpha"}}.Verifier()(context.Background(), "alpha-key", nil)
+	s.Require().NoError(err)
+	_, err = handler(context.Background(), "tools/call", &mcp.CallToolRequest{Extra: &mcp.RequestExtra{TokenInfo: info}})
+	s.Require().NoError(err)
+	s.True(scoped)
+	s.Equal("alpha", name)
+	s.Equal(RoleReadOnly, role)
+
+	_, err = handler(context.Background(), "tools/call", &mcp.CallToolRequest{})
+	s.Require().NoError(err)
+	s.False(scoped)
+	s.Empty(role)
+}
+
+func (s *TenantTestSuite) TestAuthorize() {
+	s.NoError(Authorize(context.Background(), "launch scans"))
+	s.NoError(Authorize(WithRole(context.Background(), RoleOperator), "launch scans"))
+
+	err := Authorize(WithRole(context.Background(), RoleReadOnly), "launch scans")
+	var wireErr *jsonrpc.Error
+	s.Require().ErrorAs(err, &wireErr)
+	s.Equal(int64(CodeForbidden), wireErr.Code)
+	s.Equal("forbidden: read-only keys cannot launch scans", wireErr.Message)
+	s.JSONEq(`{"action":"launch scans","required_role":"operator","role":"read-only"}`, string(wireErr.Data))
+}
+
+func (s *TenantTestSuite) TestRequireOperator() {
+	var calls int
+	handler := RequireOperator("launch scans", func(context.Context, *mcp.CallToolRequest, string) (*mcp.CallToolResult, any, error) {
+		calls++
+		return &mcp.CallToolResult{}, nil, nil
+	})
+
+	_, _, err := handler(WithRole(context.Background(), RoleReadOnly), &mcp.CallToolRequest{}, "input")
+	s.Error(err)
+	s.Zero(calls)
+
+	_, _, err = handler(WithRole(context.Background(), RoleOperator), &mcp.CallToolRequest{}, "input")
+	s.NoError(err)
+	s.Equal(1, calls)
+}
+
+func TestTenantTestSuite(t *testing.T) {
+	suite.Run(t, new(TenantTestSuite))
+}
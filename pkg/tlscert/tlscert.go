@@ -0,0 +1,188 @@
+// Package tlscert provides the certificate the server listens with over HTTPS: a certificate and
+// key pair given by the operator, or a self-signed certificate generated in the data directory
+// and reused across restarts, for servers on shared machines where plaintext MCP traffic, API keys
+// included, would be readable by other users of the network.
+package tlscert
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Files of a generated certificate in its directory.
+const (
+	// CertFile is the PEM certificate.
+	CertFile = "cert.pem"
+	// KeyFile is the PEM private key.
+	KeyFile = "key.pem"
+)
+
+const (
+	// validity is how long generated certificates are valid for.
+	validity = 365 * 24 * time.Hour
+	// renewBefore is how long before their expiry generated certificates are replaced.
+	renewBefore = 30 * 24 * time.Hour
+	// dirPerms and keyPerms keep the generated key readable by the server user only; the
+	// certificate is public.
+	dirPerms  = 0o700
+	keyPerms  = 0o600
+	certPerms = 0o644
+	// organization names the issuer and subject of generated certificates.
+	organization = "wass-mcp self-signed"
+)
+
+// ErrIncompleteKeyPair is returned when only one of the certificate and key files is given.
+var ErrIncompleteKeyPair = errors.New("TLS certificate and key must be set together")
+
+// Config returns the TLS configuration of the server, serving the certificate and key of
+// certFile and keyFile with TLS 1.2 or later.
+func Config(certFile, keyFile string) (*tls.Config, error) {
+	if certFile == "" || keyFile == "" {
+		return nil, ErrIncompleteKeyPair
+	}
+	certificate, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+// SelfSigned returns the certificate and key files of a self-signed certificate for hosts, host
+// names and IP addresses, in dir. The certificate found there is reused while it covers hosts and
+// is not about to expire; otherwise a new ECDSA P-256 certificate is generated, valid for a year.
+// generated reports whether it was.
+func SelfSigned(dir string, hosts []string) (certFile, keyFile string, generated bool, err error) {
+	certFile, keyFile = filepath.Join(dir, CertFile), filepath.Join(dir, KeyFile)
+	if current, err := load(certFile, keyFile); err == nil && covers(current, hosts) && time.Until(current.NotAfter) > renewBefore {
+		return certFile, keyFile, false, nil
+	}
+
+	if err := os.MkdirAll(dir, dirPerms); err != nil {
+		return "", "", false, fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	certPEM, keyPEM, err := generate(hosts, time.Now())
+	if err != nil {
+		return "", "", false, err
+	}
+	if err := os.WriteFile(keyFile, keyPEM, keyPerms); err != nil {
+		return "", "", false, fmt.Errorf("failed to write TLS key: %w", err)
+	}
+	if err := os.WriteFile(certFile, certPEM, certPerms); err != nil {
+		return "", "", false, fmt.Errorf("failed to write TLS certificate: %w", err)
+	}
+
+	return certFile, keyFile, true, nil
+}
+
+// Fingerprint returns the SHA-256 fingerprint of the certificate in certFile, as colon-separated
+// uppercase hex, for clients pinning a self-signed certificate.
+func Fingerprint(certFile string) (string, error) {
+	data, err := os.ReadFile(certFile) //nolint:gosec
+	if err != nil {
+		return "", fmt.Errorf("failed to read TLS certificate: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return "", fmt.Errorf("no PEM certificate in %s", certFile)
+	}
+
+	sum := sha256.Sum256(block.Bytes)
+
+	return strings.ReplaceAll(fmt.Sprintf("% X", sum[:]), " ", ":"), nil
+}
+
+// Hosts returns the names a self-signed certificate for the server bound to bindAddr covers:
+// localhost and the loopback addresses, and the bind host unless it is a wildcard address.
+func Hosts(bindAddr string) []string {
+	hosts := []string{"localhost", "127.0.0.1", "::1"}
+	host, _, err := net.SplitHostPort(bindAddr)
+	if err != nil {
+		host = bindAddr
+	}
+	if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) || slices.Contains(hosts, host) {
+		return hosts
+	}
+
+	return append(hosts, host)
+}
+
+// generate returns a new self-signed certificate for hosts and its key, both PEM encoded.
+func generate(hosts []string, now time.Time) (certPEM, keyPEM []byte, err error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate TLS key: %w", err)
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate certificate serial number: %w", err)
+	}
+
+	template := &x509.Certificate{
+		BasicConstraintsValid: true,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		NotAfter:              now.Add(validity),
+		NotBefore:             now.Add(-time.Hour),
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: hosts[0], Organization: []string{organization}},
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create TLS certificate: %w", err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode TLS key: %w", err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), nil
+}
+
+// load returns the certificate of the key pair in certFile and keyFile.
+func load(certFile, keyFile string) (*x509.Certificate, error) {
+	pair, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return x509.ParseCertificate(pair.Certificate[0])
+}
+
+// covers reports whether certificate is valid for every host of hosts.
+func covers(certificate *x509.Certificate, hosts []string) bool {
+	for _, host := range hosts {
+		if certificate.VerifyHostname(host) != nil {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,106 @@
+package tlscert
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TLSCertTestSuite struct {
+	suite.Suite
+	dir string
+}
+
+func (s *TLSCertTestSuite) SetupTest() {
+	s.dir = filepath.Join(s.T().TempDir(), "tls")
+}
+
+func (s *TLSCertTestSuite) TestSelfSigned() {
+	hosts := []string{"localhost", "127.0.0.1", "::1", "scanner.internal"}
+	certFile, keyFile, generated, err := SelfSigned(s.dir, hosts)
+	s.Require().NoError(err)
+	s.True(generated)
+	s.Equal(filepath.Join(s.dir, CertFile), certFile)
+
+	info, err := os.Stat(keyFile)
+	s.Require().NoError(err)
+	s.Equal(os.FileMode(keyPerms), info.Mode().Perm(), "the key is readable by the server user only")
+
+	certificate, err := load(certFile, keyFile)
+	s.Require().NoError(err)
+	s.True(covers(certificate, hosts))
+	s.Equal([]string{"localhost", "scanner.internal"}, certificate.DNSNames)
+	s.Len(certificate.IPAddresses, 2)
+
+	_, _, generated, err = SelfSigned(s.dir, hosts)
+	s.Require().NoError(err)
+	s.False(generated, "the certificate is reused across restarts")
+
+	_, _, generated, err = SelfSigned(s.dir, []string{"localhost", "other.internal"})
+	s.Require().NoError(err)
+	s.True(generated, "a certificate not covering the hosts is replaced")
+}
+
+func (s *TLSCertTestSuite) TestConfig() {
+	_, err := Config("cert.pem", "")
+	s.ErrorIs(err, ErrIncompleteKeyPair)
+	_, err = Config(filepath.Join(s.dir, CertFile), filepath.Join(s.dir, KeyFile))
+	s.ErrorContains(err, "failed to load TLS certificate")
+
+	certFile, keyFile, _, err := SelfSigned(s.dir, Hosts("127.0.0.1:8989"))
+	s.Require().NoError(err)
+	config, err := Config(certFile, keyFile)
+	s.Require().NoError(err)
+	s.Equal(uint16(tls.VersionTLS12), config.MinVersion)
+
+	// A client trusting the certificate reaches a server using the configuration.
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	server.TLS = config
+	server.StartTLS()
+	defer server.Close()
+
+	pem, err := os.ReadFile(certFile)
+	s.Require().NoError(err)
+	pool := x509.NewCertPool()
+	s.Require().True(pool.AppendCertsFromPEM(pem))
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}}}
+	response, err := client.Get(server.URL) //nolint:noctx
+	s.Require().NoError(err)
+	_ = response.Body.Close()
+	s.Equal(http.StatusNoContent, response.StatusCode)
+}
+
+func (s *TLSCertTestSuite) TestFingerprint() {
+	certFile, _, _, err := SelfSigned(s.dir, Hosts("localhost:8989"))
+	s.Require().NoError(err)
+
+	fingerprint, err := Fingerprint(certFile)
+	s.Require().NoError(err)
+	s.Len(fingerprint, 32*3-1)
+	s.Regexp(`^([0-9A-F]{2}:){31}[0-9A-F]{2}$`, fingerprint)
+
+	_, err = Fingerprint(filepath.Join(s.dir, KeyFile+".missing"))
+	s.Error(err)
+}
+
+func (s *TLSCertTestSuite) TestHosts() {
+	loopback := []string{"localhost", "127.0.0.1", "::1"}
+	s.Equal(loopback, Hosts("localhost:8989"))
+	s.Equal(loopback, Hosts("0.0.0.0:8989"), "wildcard addresses are left out")
+	s.Equal(loopback, Hosts("[::]:8989"))
+	s.Equal(loopback, Hosts(":8989"))
+	s.Equal(append(loopback, "10.0.0.5"), Hosts("10.0.0.5:8989"))
+	s.Equal(append(loopback, "scanner.internal"), Hosts("scanner.internal"))
+}
+
+func TestTLSCertTestSuite(t *testing.T) {
+	suite.Run(t, new(TLSCertTestSuite))
+}
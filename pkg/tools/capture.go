@@ -0,0 +1,125 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/rs/zerolog"
+	"github.com/tb0hdan/wass-mcp/pkg/artifacts"
+	"github.com/tb0hdan/wass-mcp/pkg/capture"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/redact"
+	"github.com/tb0hdan/wass-mcp/pkg/types"
+)
+
+const (
+	// captureSubdir is the directory of the artifact directory holding HAR captures.
+	captureSubdir = "captures"
+	// maxCaptureEvidenceLines bounds the transactions listed in the capture evidence of a finding.
+	maxCaptureEvidenceLines = 5
+)
+
+// scanCapture is the HAR capture of one scanner run.
+type scanCapture struct {
+	file    string
+	har     *capture.HAR
+	scanner string
+}
+
+// CaptureDir returns the directory capture artifacts are written to under the artifact directory
+// dir, empty when dir is.
+func CaptureDir(dir string) string {
+	if dir == "" {
+		return ""
+	}
+
+	return filepath.Join(dir, captureSubdir)
+}
+
+// CaptureScan wraps scan so that runs with ScanParams.Capture set are routed through a capture
+// proxy. The recorded transactions are redacted with redactor, saved as a HAR artifact in dir and
+// recorded with the in-flight execution, which links them to the findings of the scanner.
+// A capture that cannot be saved is logged and the scan result kept.
+func CaptureScan(dir string, redactor *redact.Redactor, logger zerolog.Logger, scanner string, scan ScanFunc) ScanFunc {
+	return func(ctx context.Context, params ScanParams) ScanResult {
+		if !params.Capture {
+			return scan(ctx, params)
+		}
+		if dir == "" {
+			return ScanResult{Error: fmt.Errorf("capture requires an artifact directory")}
+		}
+
+		tlsConfig, err := TLSConfig(params)
+		if err != nil {
+			return ScanResult{Error: err}
+		}
+		proxy, err := capture.Start(tlsConfig)
+		if err != nil {
+			return ScanResult{Error: fmt.Errorf("failed to start capture proxy: %w", err)}
+		}
+		params.Proxy = proxy.URL()
+
+		result := scan(ctx, params)
+
+		har := proxy.Close()
+		har.Redact(redactor)
+		runLogger := ContextLogger(ctx, logger)
+		data, err := json.MarshalIndent(har, "", "  ")
+		if err != nil {
+			runLogger.Warn().Err(err).Msgf("Failed to encode %s capture", scanner)
+			return result
+		}
+		prefix := scanner
+		if id := CorrelationID(ctx); id != "" {
+			prefix += "-" + id
+		}
+		path, err := artifacts.SaveCapture(dir, prefix, data)
+		if err != nil {
+			runLogger.Warn().Err(err).Msgf("Failed to save %s capture", scanner)
+			return result
+		}
+		runLogger.Info().Msgf("Captured %d HTTP transactions of %s to %s", len(har.Log.Entries), scanner, path)
+		recordCapture(ctx, scanCapture{file: path, har: har, scanner: scanner})
+
+		return result
+	}
+}
+
+// recordCapture attaches a capture to the in-flight execution. It is a no-op outside WrapToolHandler.
+func recordCapture(ctx context.Context, scan scanCapture) {
+	if inFlight, ok := ctx.Value(executionKey{}).(*execution); ok {
+		inFlight.mu.Lock()
+		defer inFlight.mu.Unlock()
+
+		inFlight.captures = append(inFlight.captures, scan)
+	}
+}
+
+// attachCaptures adds the capture files to exec and a reference to the captures of its scanner to
+// each finding, listing the captured transactions of the finding URL.
+func attachCaptures(exec *models.ToolExecution, found []models.Finding, captures []scanCapture) {
+	for _, scan := range captures {
+		exec.CaptureFiles = append(exec.CaptureFiles, scan.file)
+	}
+
+	for i := range found {
+		for _, scan := range captures {
+			if scan.scanner != found[i].Scanner || len(found[i].Evidence) >= types.MaxEvidence {
+				continue
+			}
+			var lines []string
+			if found[i].URL != "" {
+				lines = scan.har.Transactions(found[i].URL, maxCaptureEvidenceLines)
+			}
+			found[i].Evidence = append(found[i].Evidence, models.Evidence{
+				Content:   strings.Join(lines, "\n"),
+				Kind:      models.EvidenceArtifact,
+				Reference: scan.file,
+				Source:    models.EvidenceSourceCapture,
+			})
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/capture"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/redact"
+	"github.com/tb0hdan/wass-mcp/pkg/types"
+)
+
+type CaptureTestSuite struct {
+	suite.Suite
+}
+
+func (s *CaptureTestSuite) TestCaptureDir() {
+	s.Empty(CaptureDir(""))
+	s.Equal(filepath.Join("/var/lib/wass", "captures"), CaptureDir("/var/lib/wass"))
+}
+
+func (s *CaptureTestSuite) TestCaptureScan_Disabled() {
+	scan := CaptureScan("", redact.Default(), zerolog.Nop(), "nikto", func(_ context.Context, params ScanParams) ScanResult {
+		s.Empty(params.Proxy)
+		return ScanResult{Output: "done"}
+	})
+
+	s.Equal("done", scan(context.Background(), ScanParams{}).Output)
+}
+
+func (s *CaptureTestSuite) TestCaptureScan_RequiresArtifactDir() {
+	called := false
+	scan := CaptureScan("", redact.Default(), zerolog.Nop(), "nikto", func(context.Context, ScanParams) ScanResult {
+		called = true
+		return ScanResult{}
+	})
+
+	result := scan(context.Background(), ScanParams{Capture: true})
+	s.Require().Error(result.Error)
+	s.False(called)
+}
+
+func (s *CaptureTestSuite) TestCaptureScan_SavesRedactedHAR() {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer target.Close()
+
+	dir := s.T().TempDir()
+	inFlight := &execution{}
+	ctx := context.WithValue(WithCorrelationID(context.Background(), "abc123"), executionKey{}, inFlight)
+	scan := CaptureScan(dir, redact.Default(), zerolog.Nop(), "nikto", func(_ context.Context, params ScanParams) ScanResult {
+		proxyURL, err := url.Parse(params.Proxy)
+		s.Require().NoError(err)
+		client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)}}
+		req, err := http.NewRequest(http.MethodGet, target.URL+"/admin", nil)
+		s.Require().NoError(err)
+		req.Header.Set("Authorization", "Bearer s3cret")
+		resp, err := client.Do(req)
+		s.Require().NoError(err)
+		resp.Body.Close()
+
+		return ScanResult{Output: "scanned"}
+	})
+
+	result := scan(ctx, ScanParams{Capture: true})
+	s.Require().NoError(result.Error)
+	s.Equal("scanned", result.Output)
+
+	s.Require().Len(inFlight.captures, 1)
+	file := inFlight.captures[0].file
+	s.Equal(dir, filepath.Dir(file))
+	s.Contains(filepath.Base(file), "nikto-abc123-")
+
+	data, err := os.ReadFile(file)
+	s.Require().NoError(err)
+	s.NotContains(string(data), "s3cret")
+	var har capture.HAR
+	s.Require().NoError(json.Unmarshal(data, &har))
+	s.Require().Len(har.Log.Entries, 1)
+	s.Equal(target.URL+"/admin", har.Log.Entries[0].Request.URL)
+}
+
+func (s *CaptureTestSuite) TestAttachCaptures() {
+	har := &capture.HAR{Log: capture.Log{Entries: []capture.Entry{
+		{Request: capture.Request{Method: http.MethodGet, URL: "http://example.com/admin"}, Response: capture.Response{Status: 200}},
+		{Request: capture.Request{Method: http.MethodGet, URL: "http://example.com/"}, Response: capture.Response{Status: 200}},
+	}}}
+	full := make([]models.Evidence, types.MaxEvidence)
+	found := []models.Finding{
+		{Scanner: "nikto", URL: "http://example.com/admin"},
+		{Scanner: "nuclei", URL: "http://example.com/admin"},
+		{Scanner: "nikto", URL: "http://example.com/", Evidence: full},
+	}
+	exec := &models.ToolExecution{}
+
+	attachCaptures(exec, found, []scanCapture{{file: "/captures/nikto.har", har: har, scanner: "nikto"}})
+	s.Equal([]string{"/captures/nikto.har"}, exec.CaptureFiles)
+
+	s.Require().Len(found[0].Evidence, 1)
+	evidence := found[0].Evidence[0]
+	s.Equal(models.EvidenceArtifact, evidence.Kind)
+	s.Equal(models.EvidenceSourceCapture, evidence.Source)
+	s.Equal("/captures/nikto.har", evidence.Reference)
+	s.Equal("GET http://example.com/admin -> 200", evidence.Content)
+
+	s.Empty(found[1].Evidence)
+	s.Len(found[2].Evidence, types.MaxEvidence)
+}
+
+func TestCaptureTestSuite(t *testing.T) {
+	suite.Run(t, new(CaptureTestSuite))
+}
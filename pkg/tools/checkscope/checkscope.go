@@ -0,0 +1,108 @@
+package checkscope
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+	"github.com/tb0hdan/wass-mcp/pkg/scope"
+	"github.com/tb0hdan/wass-mcp/pkg/server"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+)
+
+const toolName = "check_scope"
+
+// Input names the target to check: a hostname, IP or URL, on the input port or each listed port.
+type Input struct {
+	Host   string `json:"host" validate:"required,hostname_rfc1123|ip"`
+	Port   int    `json:"port,omitempty" validate:"min=0,max=65535"`
+	Ports  []int  `json:"ports,omitempty" validate:"omitempty,max=32,dive,min=1,max=65535"`
+	Scheme string `json:"scheme,omitempty" validate:"omitempty,oneof=http https"`
+}
+
+// Check is the verdict of a target port.
+type Check struct {
+	InScope bool `json:"in_scope"`
+	Port    int  `json:"port"`
+	// Rule is the scope rule permitting the port, if any.
+	Rule   string `json:"rule,omitempty"`
+	Target string `json:"target"`
+}
+
+// Result is the check_scope tool response.
+type Result struct {
+	Checks []Check `json:"checks"`
+	// Enforced is false when the server has no scope policy and permits every target.
+	Enforced bool   `json:"enforced"`
+	Host     string `json:"host"`
+	// Rules are the rules of the scope policy.
+	Rules []string `json:"rules,omitempty"`
+}
+
+type Tool struct {
+	logger    zerolog.Logger
+	scope     *scope.Policy
+	validator *validator.Validate
+}
+
+func (t *Tool) Register(srv *server.Server) error {
+	tool := &mcp.Tool{
+		Name: toolName,
+		Description: "Checks whether a target host and port are permitted by the server scope policy (--scope-file) " +
+			"before planning a scan. Accepts a hostname, IP or URL and optional ports; scans of targets out of scope " +
+			"are refused.",
+	}
+
+	t.scope = srv.Scope()
+
+	mcp.AddTool(&srv.Server, tool, t.Handler)
+	t.logger.Debug().Msgf("%s tool registered", toolName)
+
+	return nil
+}
+
+func (t *Tool) Handler(_ context.Context, _ *mcp.CallToolRequest, input Input) (*mcp.CallToolResult, any, error) {
+	// Parse URL-style hosts before validation.
+	scannerInput := tools.PrepareScannerInput(tools.ScannerInput{Host: input.Host, Port: input.Port, Scheme: input.Scheme})
+	input.Host, input.Port, input.Scheme = scannerInput.Host, scannerInput.Port, scannerInput.Scheme
+	if err := t.validator.Struct(input); err != nil {
+		return nil, nil, fmt.Errorf("validation error: %w", err)
+	}
+
+	ports := input.Ports
+	if len(ports) == 0 {
+		ports = []int{input.Port}
+	}
+
+	result := Result{Enforced: t.scope.Enabled(), Host: input.Host, Rules: t.scope.Rules()}
+	for _, port := range ports {
+		scannerInput.Port = port
+		params := tools.ResolveParams(scannerInput)
+		rule, ok := t.scope.Match(params.Host, params.Port)
+		result.Checks = append(result.Checks, Check{
+			InScope: ok,
+			Port:    params.Port,
+			Rule:    rule.Text,
+			Target:  params.Target().URL(),
+		})
+	}
+
+	data, _ := json.MarshalIndent(result, "", "  ")
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: string(data)},
+		},
+	}, nil, nil
+}
+
+// New creates a new check_scope tool.
+func New(logger zerolog.Logger) tools.Tool {
+	return &Tool{
+		logger:    logger.With().Str("tool", toolName).Logger(),
+		validator: validator.New(),
+	}
+}
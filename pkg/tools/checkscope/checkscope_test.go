@@ -0,0 +1,74 @@
+package checkscope
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/scope"
+)
+
+type CheckScopeTestSuite struct {
+	suite.Suite
+	tool *Tool
+}
+
+func (s *CheckScopeTestSuite) SetupTest() {
+	s.tool = New(zerolog.Nop()).(*Tool)
+}
+
+// check calls the handler and decodes its result.
+func (s *CheckScopeTestSuite) check(input Input) Result {
+	result, _, err := s.tool.Handler(context.Background(), &mcp.CallToolRequest{}, input)
+	s.Require().NoError(err)
+
+	var decoded Result
+	s.Require().NoError(json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &decoded))
+
+	return decoded
+}
+
+func (s *CheckScopeTestSuite) TestNoPolicy() {
+	result := s.check(Input{Host: "app.example.com"})
+	s.False(result.Enforced)
+	s.Empty(result.Rules)
+	s.Require().Len(result.Checks, 1)
+	s.True(result.Checks[0].InScope)
+	s.Equal(80, result.Checks[0].Port)
+	s.Equal("http://app.example.com", result.Checks[0].Target)
+}
+
+func (s *CheckScopeTestSuite) TestPorts() {
+	policy, err := scope.New("*.internal.example.com:8000-8999", "10.0.0.0/8")
+	s.Require().NoError(err)
+	s.tool.scope = policy
+
+	result := s.check(Input{Host: "api.internal.example.com", Ports: []int{443, 8080}})
+	s.True(result.Enforced)
+	s.Equal([]string{"*.internal.example.com:8000-8999", "10.0.0.0/8"}, result.Rules)
+	s.Equal([]Check{
+		{InScope: false, Port: 443, Target: "https://api.internal.example.com"},
+		{InScope: true, Port: 8080, Rule: "*.internal.example.com:8000-8999", Target: "http://api.internal.example.com:8080"},
+	}, result.Checks)
+
+	result = s.check(Input{Host: "https://10.1.2.3/admin"})
+	s.Require().Len(result.Checks, 1)
+	s.True(result.Checks[0].InScope)
+	s.Equal("10.0.0.0/8", result.Checks[0].Rule)
+	s.Equal(443, result.Checks[0].Port)
+}
+
+func (s *CheckScopeTestSuite) TestValidationError() {
+	_, _, err := s.tool.Handler(context.Background(), &mcp.CallToolRequest{}, Input{Host: "app.example.com", Ports: []int{70000}})
+	s.ErrorContains(err, "validation error")
+
+	_, _, err = s.tool.Handler(context.Background(), &mcp.CallToolRequest{}, Input{})
+	s.ErrorContains(err, "validation error")
+}
+
+func TestCheckScopeTestSuite(t *testing.T) {
+	suite.Run(t, new(CheckScopeTestSuite))
+}
@@ -0,0 +1,194 @@
+package compare
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+	"github.com/tb0hdan/wass-mcp/pkg/findings"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/server"
+	"github.com/tb0hdan/wass-mcp/pkg/storage"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+)
+
+const (
+	toolName     = "compare"
+	defaultLimit = 50
+)
+
+// ErrSameExecution is returned when both sides resolve to the same execution.
+var ErrSameExecution = errors.New("base and other are the same execution")
+
+type Input struct {
+	BaseHost  string `json:"base_host,omitempty" validate:"max=255"`
+	BaseID    uint   `json:"base_id,omitempty"`
+	Limit     int    `json:"limit,omitempty" validate:"min=0,max=500"`
+	OtherHost string `json:"other_host,omitempty" validate:"max=255"`
+	OtherID   uint   `json:"other_id,omitempty"`
+	Tool      string `json:"tool,omitempty"`
+}
+
+// Side describes the execution compared on one side.
+type Side struct {
+	CreatedAt      time.Time      `json:"created_at"`
+	ExecutionID    uint           `json:"execution_id"`
+	Host           string         `json:"host"`
+	RiskScore      float64        `json:"risk_score"`
+	SeverityCounts map[string]int `json:"severity_counts"`
+	Target         string         `json:"target"`
+	ToolName       string         `json:"tool_name"`
+	TotalFindings  int            `json:"total_findings"`
+	// Unique is the number of findings only this side has.
+	Unique int `json:"unique"`
+}
+
+// Result is the compare tool response. The finding lists are cut to the requested limit; the
+// Unique count of each side is the full number.
+type Result struct {
+	findings.Comparison
+
+	Base  Side `json:"base"`
+	Other Side `json:"other"`
+}
+
+type Tool struct {
+	logger    zerolog.Logger
+	validator *validator.Validate
+	store     storage.Storage
+}
+
+func (t *Tool) Register(srv *server.Server) error {
+	tool := &mcp.Tool{
+		Name: toolName,
+		Description: "Compares the stored findings of two different targets, e.g. staging and production of the " +
+			"same app, and lists the findings present in only one of them. Each side is an execution ID " +
+			"(base_id, other_id) or a host (base_host, other_host) whose latest successful scan is used, " +
+			"optionally of a given tool. Findings match by scanner, severity, check and URL path, ignoring " +
+			"the host, so compare scans run with the same scanners.",
+	}
+
+	t.store = srv.Storage()
+
+	mcp.AddTool(&srv.Server, tool, t.CompareHandler)
+	t.logger.Debug().Msgf("%s tool registered", toolName)
+
+	return nil
+}
+
+func (t *Tool) CompareHandler(ctx context.Context, _ *mcp.CallToolRequest, input Input) (*mcp.CallToolResult, any, error) {
+	if err := t.validator.Struct(input); err != nil {
+		return nil, nil, fmt.Errorf("validation error: %w", err)
+	}
+
+	base, err := t.resolve(ctx, "base", input.BaseID, input.BaseHost, input.Tool)
+	if err != nil {
+		return nil, nil, err
+	}
+	other, err := t.resolve(ctx, "other", input.OtherID, input.OtherHost, input.Tool)
+	if err != nil {
+		return nil, nil, err
+	}
+	if base.ID == other.ID {
+		return nil, nil, ErrSameExecution
+	}
+
+	stored, err := t.store.GetFindingsByExecutions(ctx, []uint{base.ID, other.ID})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get findings: %w", err)
+	}
+	var baseFindings, otherFindings []models.Finding
+	for _, finding := range stored {
+		if finding.ExecutionID == base.ID {
+			baseFindings = append(baseFindings, finding)
+		} else {
+			otherFindings = append(otherFindings, finding)
+		}
+	}
+
+	limit := input.Limit
+	if limit == 0 {
+		limit = defaultLimit
+	}
+
+	data, _ := json.MarshalIndent(buildResult(base, other, baseFindings, otherFindings, limit), "", "  ")
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: string(data)},
+		},
+	}, nil, nil
+}
+
+// resolve returns the execution with id, or otherwise the latest successful execution against
+// host, of toolName when set.
+func (t *Tool) resolve(ctx context.Context, side string, id uint, host, toolName string) (*models.ToolExecution, error) {
+	switch {
+	case id != 0 && host != "":
+		return nil, fmt.Errorf("validation error: %s_id and %s_host are mutually exclusive", side, side)
+	case id != 0:
+		exec, err := t.store.GetToolExecution(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("%s execution not found: %w", side, err)
+		}
+		return exec, nil
+	case host != "":
+		success := true
+		executions, _, err := t.store.QueryToolExecutions(ctx, storage.ExecutionFilter{
+			Host:     host,
+			Limit:    1,
+			Success:  &success,
+			ToolName: toolName,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get executions: %w", err)
+		}
+		if len(executions) == 0 {
+			return nil, fmt.Errorf("no successful scan of %s found for %s", host, side)
+		}
+		return &executions[0], nil
+	default:
+		return nil, fmt.Errorf("validation error: %s_id or %s_host is required", side, side)
+	}
+}
+
+// buildResult compares the findings of both executions, keeping at most limit findings per list.
+func buildResult(base, other *models.ToolExecution, baseFindings, otherFindings []models.Finding, limit int) Result {
+	comparison := findings.Compare(baseFindings, otherFindings)
+	result := Result{
+		Base:  describe(base, baseFindings, len(comparison.OnlyInBase)),
+		Other: describe(other, otherFindings, len(comparison.OnlyInOther)),
+	}
+	result.Shared = comparison.Shared
+	result.OnlyInBase = comparison.OnlyInBase[:min(limit, len(comparison.OnlyInBase))]
+	result.OnlyInOther = comparison.OnlyInOther[:min(limit, len(comparison.OnlyInOther))]
+
+	return result
+}
+
+// describe returns the side of exec with its findings.
+func describe(exec *models.ToolExecution, found []models.Finding, unique int) Side {
+	return Side{
+		CreatedAt:      exec.CreatedAt,
+		ExecutionID:    exec.ID,
+		Host:           exec.Host,
+		RiskScore:      exec.RiskScore,
+		SeverityCounts: findings.CountBySeverity(found),
+		Target:         exec.Target,
+		ToolName:       exec.ToolName,
+		TotalFindings:  len(found),
+		Unique:         unique,
+	}
+}
+
+func New(logger zerolog.Logger) tools.Tool {
+	return &Tool{
+		logger:    logger.With().Str("tool", toolName).Logger(),
+		validator: validator.New(),
+	}
+}
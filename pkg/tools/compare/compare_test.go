@@ -0,0 +1,158 @@
+package compare
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/storage"
+	"github.com/tb0hdan/wass-mcp/pkg/tenant"
+	"github.com/tb0hdan/wass-mcp/pkg/types"
+)
+
+type CompareTestSuite struct {
+	suite.Suite
+	store storage.Storage
+	tool  *Tool
+}
+
+func (s *CompareTestSuite) SetupTest() {
+	tmpFile, err := os.CreateTemp("", "compare-test-*.db")
+	s.Require().NoError(err)
+	s.Require().NoError(tmpFile.Close())
+	s.T().Cleanup(func() { _ = os.Remove(tmpFile.Name()) })
+
+	store, err := storage.NewSQLiteStorage(storage.Config{DatabasePath: tmpFile.Name()})
+	s.Require().NoError(err)
+	s.T().Cleanup(func() { _ = store.Close() })
+
+	s.store = store
+	s.tool = New(zerolog.Nop()).(*Tool)
+	s.tool.store = store
+}
+
+func (s *CompareTestSuite) call(ctx context.Context, input Input) (Result, error) {
+	result, _, err := s.tool.CompareHandler(ctx, nil, input)
+	if err != nil {
+		return Result{}, err
+	}
+
+	var response Result
+	s.Require().NoError(json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &response))
+
+	return response, nil
+}
+
+// addScan stores an execution against host with a low nikto finding at each of paths.
+func (s *CompareTestSuite) addScan(ctx context.Context, tool, host string, success bool, paths ...string) uint {
+	exec := &models.ToolExecution{
+		ToolName: tool,
+		Target:   "https://" + host,
+		Host:     host,
+		Port:     443,
+		Scheme:   "https",
+		Success:  success,
+	}
+	s.Require().NoError(s.store.CreateToolExecution(ctx, exec))
+
+	found := make([]models.Finding, 0, len(paths))
+	for _, path := range paths {
+		found = append(found, models.Finding{
+			ExecutionID: exec.ID,
+			Scanner:     "nikto",
+			Severity:    types.SeverityLow,
+			Title:       path + ": Interesting path.",
+			URL:         "https://" + host + path,
+		})
+	}
+	if len(found) > 0 {
+		s.Require().NoError(s.store.CreateFindings(ctx, found))
+	}
+
+	return exec.ID
+}
+
+func (s *CompareTestSuite) TestCompareHosts() {
+	ctx := context.Background()
+	s.addScan(ctx, "nikto", "staging.example.com", true, "/old/")
+	stagingID := s.addScan(ctx, "nikto", "staging.example.com", true, "/admin/", "/debug/", "/")
+	s.addScan(ctx, "nikto", "staging.example.com", false)
+	productionID := s.addScan(ctx, "nikto", "www.example.com", true, "/admin/", "/", "/backup/")
+
+	response, err := s.call(ctx, Input{BaseHost: "staging.example.com", OtherHost: "www.example.com"})
+	s.Require().NoError(err)
+
+	s.Equal(stagingID, response.Base.ExecutionID)
+	s.Equal(productionID, response.Other.ExecutionID)
+	s.Equal(3, response.Base.TotalFindings)
+	s.Equal(3, response.Base.SeverityCounts[types.SeverityLow])
+	s.Equal(2, response.Shared)
+	s.Equal(1, response.Base.Unique)
+	s.Equal(1, response.Other.Unique)
+	s.Require().Len(response.OnlyInBase, 1)
+	s.Equal("https://staging.example.com/debug/", response.OnlyInBase[0].URL)
+	s.Require().Len(response.OnlyInOther, 1)
+	s.Equal("https://www.example.com/backup/", response.OnlyInOther[0].URL)
+}
+
+func (s *CompareTestSuite) TestCompareIDsWithLimit() {
+	ctx := context.Background()
+	baseID := s.addScan(ctx, "nikto", "staging.example.com", true, "/a/", "/b/", "/c/")
+	otherID := s.addScan(ctx, "nikto", "www.example.com", true)
+
+	response, err := s.call(ctx, Input{BaseID: baseID, OtherID: otherID, Limit: 2})
+	s.Require().NoError(err)
+	s.Equal(3, response.Base.Unique)
+	s.Len(response.OnlyInBase, 2)
+	s.Empty(response.OnlyInOther)
+	s.Zero(response.Shared)
+}
+
+func (s *CompareTestSuite) TestToolFilter() {
+	ctx := context.Background()
+	niktoID := s.addScan(ctx, "nikto", "staging.example.com", true, "/admin/")
+	s.addScan(ctx, "nuclei", "staging.example.com", true)
+	otherID := s.addScan(ctx, "nikto", "www.example.com", true, "/admin/")
+
+	response, err := s.call(ctx, Input{BaseHost: "staging.example.com", OtherID: otherID, Tool: "nikto"})
+	s.Require().NoError(err)
+	s.Equal(niktoID, response.Base.ExecutionID)
+	s.Equal(1, response.Shared)
+}
+
+func (s *CompareTestSuite) TestValidation() {
+	ctx := context.Background()
+	id := s.addScan(ctx, "nikto", "staging.example.com", true)
+
+	_, err := s.call(ctx, Input{OtherID: id})
+	s.ErrorContains(err, "base_id or base_host is required")
+	_, err = s.call(ctx, Input{BaseID: id, BaseHost: "staging.example.com", OtherID: id})
+	s.ErrorContains(err, "mutually exclusive")
+	_, err = s.call(ctx, Input{BaseID: id, OtherHost: "staging.example.com"})
+	s.ErrorIs(err, ErrSameExecution)
+	_, err = s.call(ctx, Input{BaseID: id, OtherHost: "missing.example.com"})
+	s.ErrorContains(err, "no successful scan of missing.example.com found for other")
+	_, err = s.call(ctx, Input{BaseID: id, OtherID: 999})
+	s.ErrorContains(err, "other execution not found")
+	_, err = s.call(ctx, Input{BaseID: id, OtherID: 999, Limit: 501})
+	s.ErrorContains(err, "validation error")
+}
+
+func (s *CompareTestSuite) TestTenantScoping() {
+	alpha := tenant.WithTenant(context.Background(), "alpha")
+	beta := tenant.WithTenant(context.Background(), "beta")
+	baseID := s.addScan(alpha, "nikto", "staging.example.com", true)
+	s.addScan(beta, "nikto", "www.example.com", true)
+
+	_, err := s.call(alpha, Input{BaseID: baseID, OtherHost: "www.example.com"})
+	s.ErrorContains(err, "no successful scan of www.example.com")
+}
+
+func TestCompareTestSuite(t *testing.T) {
+	suite.Run(t, new(CompareTestSuite))
+}
@@ -0,0 +1,105 @@
+package tools
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	// CompressionGzip returns the output as a gzip-compressed embedded resource.
+	CompressionGzip = "gzip"
+	// CompressionNone always returns the output as text.
+	CompressionNone = "none"
+
+	// CompressedMIMEType is the MIME type of compressed output resources.
+	CompressedMIMEType = "application/gzip"
+	// OutputResourcePrefix prefixes the URIs of compressed output resources.
+	OutputResourcePrefix = "wass://output/"
+)
+
+// UseCompression reports whether a page of size bytes is returned compressed. An explicit
+// compression mode wins; otherwise pages larger than threshold are compressed, 0 (the default)
+// disabling automatic compression.
+func UseCompression(mode string, size, threshold int) bool {
+	switch mode {
+	case CompressionGzip:
+		return true
+	case CompressionNone:
+		return false
+	}
+
+	return threshold > 0 && size > threshold
+}
+
+// OutputResourceURI returns the URI of the compressed output of a tool call, named after the
+// correlation ID of the call.
+func OutputResourceURI(ctx context.Context, toolName string) string {
+	id := CorrelationID(ctx)
+	if id == "" {
+		id = NewCorrelationID()
+	}
+
+	return OutputResourcePrefix + toolName + "/" + id + ".txt.gz"
+}
+
+// CompressResource gzips text into an embedded resource blob. The blob is base64-encoded on the wire.
+func CompressResource(uri, text string) (*mcp.EmbeddedResource, error) {
+	var buffer bytes.Buffer
+	writer := gzip.NewWriter(&buffer)
+	if _, err := writer.Write([]byte(text)); err != nil {
+		return nil, fmt.Errorf("failed to compress output: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress output: %w", err)
+	}
+
+	return &mcp.EmbeddedResource{
+		Resource: &mcp.ResourceContents{
+			URI:      uri,
+			MIMEType: CompressedMIMEType,
+			Blob:     buffer.Bytes(),
+		},
+	}, nil
+}
+
+// CompressPage returns page, read from start within the byte budget maxBytes, as a compressed
+// resource, together with the notice describing it. The page keeps its Next cursor, so the
+// following pages are read like those of uncompressed responses.
+func CompressPage(ctx context.Context, toolName string, page ResponsePage, start Cursor, maxBytes int) (string, *mcp.EmbeddedResource, error) {
+	text := strings.TrimSpace(page.Text)
+
+	resource, err := CompressResource(OutputResourceURI(ctx, toolName), text)
+	if err != nil {
+		return "", nil, err
+	}
+
+	notice := PageNotice(page, start, maxBytes)
+	notice += fmt.Sprintf("[Output of %d bytes returned gzip-compressed (%d bytes) as resource %s.]\n",
+		len(text), len(resource.Resource.Blob), resource.Resource.URI)
+
+	return notice, resource, nil
+}
+
+// FormatCompressedPage is FormatScannerPage for compressed responses: it returns the header text
+// and the compressed resource holding the page, along with the page, whose Next is the cursor of
+// the next page when the byte budget cut the page short.
+func FormatCompressedPage(
+	ctx context.Context,
+	toolName, headerVerb, targetURL, output string,
+	maxLines int,
+	start Cursor,
+	maxBytes int,
+) (string, *mcp.EmbeddedResource, ResponsePage, error) {
+	page := PaginateResponse(output, maxLines, start, maxBytes)
+	notice, resource, err := CompressPage(ctx, toolName, page, start, maxBytes)
+	if err != nil {
+		return "", nil, page, err
+	}
+
+	return fmt.Sprintf("%s %s for %s:\n", toolName, headerVerb, targetURL) + notice, resource, page, nil
+}
@@ -0,0 +1,98 @@
+package tools
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+)
+
+type CompressTestSuite struct {
+	suite.Suite
+}
+
+func (s *CompressTestSuite) decompress(resource *mcp.EmbeddedResource) string {
+	reader, err := gzip.NewReader(bytes.NewReader(resource.Resource.Blob))
+	s.Require().NoError(err)
+	data, err := io.ReadAll(reader)
+	s.Require().NoError(err)
+
+	return string(data)
+}
+
+func (s *CompressTestSuite) TestUseCompression() {
+	s.True(UseCompression(CompressionGzip, 1, 0))
+	s.False(UseCompression(CompressionNone, 100, 10))
+	s.True(UseCompression("", 100, 10))
+	s.False(UseCompression("", 10, 10))
+	s.False(UseCompression("", 100, 0))
+}
+
+func (s *CompressTestSuite) TestOutputResourceURI() {
+	ctx := WithCorrelationID(context.Background(), "abc123")
+	s.Equal("wass://output/nikto/abc123.txt.gz", OutputResourceURI(ctx, "nikto"))
+	s.True(strings.HasPrefix(OutputResourceURI(context.Background(), "nikto"), "wass://output/nikto/"))
+}
+
+func (s *CompressTestSuite) TestCompressResource() {
+	text := strings.Repeat("a repeated finding\n", 100)
+	resource, err := CompressResource("wass://output/test/1.txt.gz", text)
+	s.Require().NoError(err)
+	s.Equal(CompressedMIMEType, resource.Resource.MIMEType)
+	s.Less(len(resource.Resource.Blob), len(text))
+	s.Equal(text, s.decompress(resource))
+}
+
+func (s *CompressTestSuite) TestCompressPage_Lines() {
+	start := Cursor{Line: 1}
+	page := PaginateResponse("a\nb\nc\nd", 2, start, 0)
+	notice, resource, err := CompressPage(context.Background(), "test", page, start, 0)
+	s.Require().NoError(err)
+	s.Equal("b\nc", s.decompress(resource))
+	s.Contains(notice, "Showing lines 2-3 of 4 lines")
+	s.Contains(notice, "[Output of 3 bytes returned gzip-compressed")
+}
+
+func (s *CompressTestSuite) TestCompressPage_Budget() {
+	page := PaginateResponse("aaaa\nbbbb\ncccc", 0, Cursor{}, 5)
+	s.Require().NotNil(page.Next)
+	_, resource, err := CompressPage(context.Background(), "test", page, Cursor{}, 5)
+	s.Require().NoError(err)
+	s.Equal("aaaa", s.decompress(resource))
+}
+
+func (s *CompressTestSuite) TestHandleScan_Compression() {
+	bs := NewBaseScanner("test", "test", zerolog.Nop())
+	bs.maxResponseBytes = 16
+	output := strings.Repeat("x", 100)
+	scan := func(context.Context, ScanParams) ScanResult {
+		return ScanResult{Output: output}
+	}
+
+	input := ScannerInput{Host: "example.com", Compression: CompressionGzip}
+	result, _, err := bs.HandleScan(context.Background(), input, "output", scan)
+	s.Require().NoError(err)
+	s.Require().Len(result.Content, 2)
+	s.Contains(result.Content[0].(*mcp.TextContent).Text, "test output for http://example.com:\n")
+	s.Equal(output[:16], s.decompress(result.Content[1].(*mcp.EmbeddedResource)), "compressed pages keep the byte budget")
+	s.Contains(result.Meta, NextCursorField)
+
+	// Scanners do not compress automatically.
+	result, _, err = bs.HandleScan(context.Background(), ScannerInput{Host: "example.com"}, "output", scan)
+	s.Require().NoError(err)
+	s.Len(result.Content, 1)
+
+	input.Compression = "zstd"
+	_, _, err = bs.HandleScan(context.Background(), input, "output", scan)
+	s.Error(err)
+}
+
+func TestCompressTestSuite(t *testing.T) {
+	suite.Run(t, new(CompressTestSuite))
+}
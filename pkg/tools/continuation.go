@@ -0,0 +1,103 @@
+package tools
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+)
+
+const (
+	// OutputCursorField is the result metadata key holding the opaque cursor a client passes to
+	// the continue_output tool for the next page of a truncated output.
+	OutputCursorField = "output_cursor"
+	// cursorTokenBytes is the number of random bytes in an output cursor token.
+	cursorTokenBytes = 16
+)
+
+// CursorStore stores output cursors, see storage.Storage.
+type CursorStore interface {
+	CreateOutputCursor(ctx context.Context, cursor *models.OutputCursor) error
+}
+
+// NextPosition returns where the page following p starts, and false when p reaches the end of
+// the output.
+func (p ResponsePage) NextPosition() (Cursor, bool) {
+	if p.Next != nil {
+		return *p.Next, true
+	}
+	if p.Truncated {
+		return Cursor{Line: p.EndLine}, true
+	}
+
+	return Cursor{}, false
+}
+
+// IssueContinuation stores an output cursor at the start of the page following page in the raw
+// output of the execution executionID, pages of maxLines lines, and returns its opaque token. It
+// returns an empty token when page ends the output or outside the execution wrapper
+// (executionID 0), where no output is stored. Pages of the in-flight execution are read from its
+// output as given, which is stored redacted; the cursor points at the same place in the stored
+// output, see storedPosition.
+func IssueContinuation(ctx context.Context, store CursorStore, executionID uint, page ResponsePage, maxLines int) (string, error) {
+	next, more := page.NextPosition()
+	if !more || executionID == 0 || store == nil {
+		return "", nil
+	}
+	next = storedPosition(ctx, executionID, next)
+
+	buf := make([]byte, cursorTokenBytes)
+	_, _ = rand.Read(buf)
+	cursor := &models.OutputCursor{
+		Byte:        next.Byte,
+		ExecutionID: executionID,
+		Line:        next.Line,
+		MaxLines:    maxLines,
+		Token:       hex.EncodeToString(buf),
+	}
+	if err := store.CreateOutputCursor(ctx, cursor); err != nil {
+		return "", fmt.Errorf("failed to store output cursor: %w", err)
+	}
+
+	return cursor.Token, nil
+}
+
+// storedPosition maps next, a position in the raw output of the in-flight execution executionID
+// as given, to the same position in the raw output as stored, redacted, where continue_output
+// reads it. Redaction keeps line breaks, so only a byte offset within a line redaction changes
+// moves: to the end of the redacted part of the line already returned, or to the line start,
+// repeating rather than skipping output, when that part is not a prefix of the redacted line.
+// Positions in the outputs of other executions, read stored, are returned as is.
+func storedPosition(ctx context.Context, executionID uint, next Cursor) Cursor {
+	inFlight, ok := ctx.Value(executionKey{}).(*execution)
+	if !ok || next.Byte == 0 || inFlight.record.ID != executionID || inFlight.redactor == nil {
+		return next
+	}
+	output := inFlight.record.RawOutput
+	if strings.Count(output, "\n") < next.Line {
+		return next
+	}
+	lines := pageLines(output, next.Line, 1)
+	if next.Byte > len(lines[0]) {
+		return next
+	}
+
+	line := inFlight.redactor.Text(lines[0])
+	returned := inFlight.redactor.Text(lines[0][:next.Byte])
+	if strings.HasPrefix(line, returned) {
+		next.Byte = len(returned)
+	} else {
+		next.Byte = 0
+	}
+
+	return next
+}
+
+// ContinuationNotice tells the client how to read the page following a response with the output
+// cursor token.
+func ContinuationNotice(token string) string {
+	return fmt.Sprintf("\n\n[More output is stored. Call continue_output with cursor %q for the next page.]", token)
+}
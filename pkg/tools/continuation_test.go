@@ -0,0 +1,135 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/redact"
+)
+
+// cursorStore records the output cursors created.
+type cursorStore struct {
+	cursors []models.OutputCursor
+	err     error
+}
+
+func (c *cursorStore) CreateOutputCursor(_ context.Context, cursor *models.OutputCursor) error {
+	if c.err != nil {
+		return c.err
+	}
+	c.cursors = append(c.cursors, *cursor)
+	return nil
+}
+
+type ContinuationTestSuite struct {
+	suite.Suite
+}
+
+func (s *ContinuationTestSuite) TestNextPosition() {
+	_, more := PaginateResponse("a\nb", 5, Cursor{}, 0).NextPosition()
+	s.False(more)
+
+	next, more := PaginateResponse("a\nb\nc", 2, Cursor{}, 0).NextPosition()
+	s.True(more)
+	s.Equal(Cursor{Line: 2}, next)
+
+	next, more = PaginateResponse("abcdef", 0, Cursor{}, 4).NextPosition()
+	s.True(more)
+	s.Equal(Cursor{Byte: 4}, next)
+}
+
+func (s *ContinuationTestSuite) TestIssueContinuation() {
+	store := &cursorStore{}
+	page := PaginateResponse("a\nb\nc", 2, Cursor{}, 0)
+
+	token, err := IssueContinuation(context.Background(), store, 0, page, 2)
+	s.Require().NoError(err)
+	s.Empty(token, "no cursor outside the execution wrapper")
+
+	token, err = IssueContinuation(context.Background(), store, 7, PaginateResponse("a", 2, Cursor{}, 0), 2)
+	s.Require().NoError(err)
+	s.Empty(token, "no cursor for complete outputs")
+
+	token, err = IssueContinuation(context.Background(), store, 7, page, 2)
+	s.Require().NoError(err)
+	s.Len(token, 2*cursorTokenBytes)
+	s.Require().Len(store.cursors, 1)
+	s.Equal(models.OutputCursor{ExecutionID: 7, Line: 2, MaxLines: 2, Token: token}, store.cursors[0])
+
+	again, err := IssueContinuation(context.Background(), store, 7, page, 2)
+	s.Require().NoError(err)
+	s.NotEqual(token, again)
+
+	store.err = errors.New("database is locked")
+	_, err = IssueContinuation(context.Background(), store, 7, page, 2)
+	s.ErrorContains(err, "failed to store output cursor")
+}
+
+func (s *ContinuationTestSuite) TestIssueContinuation_RedactedLine() {
+	output := "one\ncurl -H 'Authorization: Bearer s3cr3tvalue' https://example.com/api\nthree"
+	redactor := redact.Default()
+	stored := redactor.Text(output)
+	s.Require().NotEqual(output, stored)
+
+	// Page boundaries before, inside and after the secret of the second line.
+	for _, budget := range []int{5, 30, 50, 60} {
+		store := &cursorStore{}
+		ctx := context.WithValue(context.Background(), executionKey{},
+			&execution{record: &models.ToolExecution{ID: 7, RawOutput: output}, redactor: redactor})
+		page := PaginateResponse(output, 2, Cursor{Line: 1}, budget)
+		s.Require().NotNil(page.Next, "budget %d", budget)
+
+		_, err := IssueContinuation(ctx, store, 7, page, 2)
+		s.Require().NoError(err)
+		s.Require().Len(store.cursors, 1)
+		cursor := store.cursors[0]
+		s.Equal(1, cursor.Line)
+
+		next := PaginateResponse(stored, 2, Cursor{Byte: cursor.Byte, Line: cursor.Line}, 0)
+		s.Equal(strings.SplitN(stored, "\n", 2)[1], redactor.Text(page.Text)+next.Text, "budget %d", budget)
+		s.NotContains(next.Text, "s3cr3t", "budget %d", budget)
+	}
+
+	// Outside the in-flight execution the position is kept as is.
+	store := &cursorStore{}
+	page := PaginateResponse(output, 2, Cursor{Line: 1}, 30)
+	_, err := IssueContinuation(context.Background(), store, 7, page, 2)
+	s.Require().NoError(err)
+	s.Equal(*page.Next, Cursor{Byte: store.cursors[0].Byte, Line: store.cursors[0].Line})
+}
+
+func (s *ContinuationTestSuite) TestHandleScan_OutputCursor() {
+	store := &cursorStore{}
+	bs := NewBaseScanner("test", "test", zerolog.Nop())
+	scan := func(context.Context, ScanParams) ScanResult {
+		return ScanResult{Output: "one\ntwo\nthree"}
+	}
+	ctx := context.WithValue(context.Background(), executionKey{}, &execution{record: &models.ToolExecution{ID: 7}})
+
+	result, _, err := bs.HandleScan(ctx, ScannerInput{Host: "example.com", MaxLines: 2}, "output", scan)
+	s.Require().NoError(err)
+	s.NotContains(result.Meta, OutputCursorField, "no cursor without storage")
+
+	bs.cursors = store
+	result, _, err = bs.HandleScan(ctx, ScannerInput{Host: "example.com", MaxLines: 2}, "output", scan)
+	s.Require().NoError(err)
+	s.Require().Len(store.cursors, 1)
+	token := store.cursors[0].Token
+	s.Equal(token, result.Meta[OutputCursorField])
+	s.Contains(result.Content[0].(*mcp.TextContent).Text, ContinuationNotice(token))
+	s.Equal(2, store.cursors[0].Line)
+
+	result, _, err = bs.HandleScan(ctx, ScannerInput{Host: "example.com"}, "output", scan)
+	s.Require().NoError(err)
+	s.NotContains(result.Meta, OutputCursorField)
+}
+
+func TestContinuationTestSuite(t *testing.T) {
+	suite.Run(t, new(ContinuationTestSuite))
+}
@@ -0,0 +1,112 @@
+package continueoutput
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/server"
+	"github.com/tb0hdan/wass-mcp/pkg/storage"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+	"gorm.io/gorm"
+)
+
+const toolName = "continue_output"
+
+type Input struct {
+	Cursor string `json:"cursor" validate:"required,hexadecimal,max=64"`
+}
+
+type Tool struct {
+	logger zerolog.Logger
+	// maxResponseBytes bounds the output returned per call, set on registration.
+	maxResponseBytes int
+	store            storage.Storage
+	validator        *validator.Validate
+}
+
+func (t *Tool) Register(srv *server.Server) error {
+	tool := &mcp.Tool{
+		Name: toolName,
+		Description: "Returns the next page of a truncated scanner or full_scan output from the stored execution, " +
+			"given the opaque cursor of the previous response (its output_cursor, also quoted in its text). " +
+			"Pages keep the size of the original response and come with the cursor of the following page " +
+			"until the output ends, without running the scan again.",
+	}
+
+	t.maxResponseBytes = srv.MaxResponseBytes()
+	t.store = srv.Storage()
+
+	mcp.AddTool(&srv.Server, tool, t.ContinueHandler)
+	t.logger.Debug().Msgf("%s tool registered", toolName)
+
+	return nil
+}
+
+func (t *Tool) ContinueHandler(ctx context.Context, _ *mcp.CallToolRequest, input Input) (*mcp.CallToolResult, any, error) {
+	if err := t.validator.Struct(input); err != nil {
+		return nil, nil, fmt.Errorf("validation error: %w", err)
+	}
+
+	cursor, err := t.store.GetOutputCursor(ctx, input.Cursor)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, fmt.Errorf("cursor %s not found", input.Cursor)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load cursor: %w", err)
+	}
+
+	exec, err := t.store.GetToolExecution(ctx, cursor.ExecutionID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("execution %d not found: %w", cursor.ExecutionID, err)
+	}
+	if exec.Status == models.StatusRunning {
+		return nil, nil, fmt.Errorf("output of execution %d is still being stored, retry shortly", exec.ID)
+	}
+	if exec.RawOutput == "" {
+		return nil, nil, fmt.Errorf("execution %d has no stored output", exec.ID)
+	}
+
+	start := tools.Cursor{Byte: cursor.Byte, Line: cursor.Line}
+	page := tools.PaginateResponse(exec.RawOutput, cursor.MaxLines, start, t.maxResponseBytes)
+	resultText := fmt.Sprintf("%s output of execution %d, %s:\n\n%s",
+		exec.ToolName, exec.ID, pageRange(page, start), strings.TrimSpace(page.Text))
+
+	token, err := tools.IssueContinuation(ctx, t.store, exec.ID, page, cursor.MaxLines)
+	if err != nil {
+		return nil, nil, err
+	}
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: resultText},
+		},
+	}
+	if token != "" {
+		result.Content[0].(*mcp.TextContent).Text += tools.ContinuationNotice(token)
+		result.Meta = mcp.Meta{tools.OutputCursorField: token}
+	}
+
+	return result, nil, nil
+}
+
+// pageRange describes the lines page covers.
+func pageRange(page tools.ResponsePage, start tools.Cursor) string {
+	from := ""
+	if start.Byte > 0 && page.StartLine == start.Line {
+		from = fmt.Sprintf(" from byte %d of line %d", start.Byte, start.Line+1)
+	}
+
+	return fmt.Sprintf("lines %d-%d of %d%s", page.StartLine+1, page.EndLine, page.TotalLines, from)
+}
+
+func New(logger zerolog.Logger) tools.Tool {
+	return &Tool{
+		logger:    logger.With().Str("tool", toolName).Logger(),
+		validator: validator.New(),
+	}
+}
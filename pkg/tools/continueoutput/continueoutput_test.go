@@ -0,0 +1,128 @@
+package continueoutput
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/storage"
+	"github.com/tb0hdan/wass-mcp/pkg/tenant"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+)
+
+type ContinueOutputTestSuite struct {
+	suite.Suite
+	store storage.Storage
+	tool  *Tool
+}
+
+func (s *ContinueOutputTestSuite) SetupTest() {
+	tmpFile, err := os.CreateTemp("", "continueoutput-test-*.db")
+	s.Require().NoError(err)
+	s.Require().NoError(tmpFile.Close())
+	s.T().Cleanup(func() { _ = os.Remove(tmpFile.Name()) })
+
+	store, err := storage.NewSQLiteStorage(storage.Config{DatabasePath: tmpFile.Name()})
+	s.Require().NoError(err)
+	s.T().Cleanup(func() { _ = store.Close() })
+
+	s.store = store
+	s.tool = New(zerolog.Nop()).(*Tool)
+	s.tool.store = store
+}
+
+// issue stores an execution of output and a cursor after its first page of maxLines lines.
+func (s *ContinueOutputTestSuite) issue(ctx context.Context, exec *models.ToolExecution, maxLines int) string {
+	s.Require().NoError(s.store.CreateToolExecution(ctx, exec))
+	page := tools.PaginateResponse(exec.RawOutput, maxLines, tools.Cursor{}, 0)
+	token, err := tools.IssueContinuation(ctx, s.store, exec.ID, page, maxLines)
+	s.Require().NoError(err)
+	s.Require().NotEmpty(token)
+
+	return token
+}
+
+func (s *ContinueOutputTestSuite) TestPages() {
+	lines := make([]string, 25)
+	for i := range lines {
+		lines[i] = "line" + strings.Repeat("x", i)
+	}
+	exec := &models.ToolExecution{ToolName: "nikto", Success: true, RawOutput: strings.Join(lines, "\n")}
+	token := s.issue(context.Background(), exec, 10)
+
+	result, _, err := s.tool.ContinueHandler(context.Background(), nil, Input{Cursor: token})
+	s.Require().NoError(err)
+	text := result.Content[0].(*mcp.TextContent).Text
+	s.Contains(text, "lines 11-20 of 25")
+	s.Contains(text, lines[10])
+	s.NotContains(text, lines[20]+"\n")
+	next, ok := result.Meta[tools.OutputCursorField].(string)
+	s.Require().True(ok)
+	s.NotEqual(token, next)
+
+	result, _, err = s.tool.ContinueHandler(context.Background(), nil, Input{Cursor: next})
+	s.Require().NoError(err)
+	text = result.Content[0].(*mcp.TextContent).Text
+	s.Contains(text, "lines 21-25 of 25")
+	s.True(strings.HasSuffix(text, lines[24]))
+	s.NotContains(result.Meta, tools.OutputCursorField)
+
+	_, _, err = s.tool.ContinueHandler(context.Background(), nil, Input{Cursor: token})
+	s.Require().NoError(err, "cursors can be used again")
+}
+
+func (s *ContinueOutputTestSuite) TestByteBudget() {
+	exec := &models.ToolExecution{ToolName: "nuclei", Success: true, RawOutput: "a\n" + strings.Repeat("b", 30)}
+	token := s.issue(context.Background(), exec, 1)
+	s.tool.maxResponseBytes = 20
+
+	result, _, err := s.tool.ContinueHandler(context.Background(), nil, Input{Cursor: token})
+	s.Require().NoError(err)
+	s.True(strings.HasSuffix(result.Content[0].(*mcp.TextContent).Text, "\n"+strings.Repeat("b", 20)+
+		tools.ContinuationNotice(result.Meta[tools.OutputCursorField].(string))))
+
+	result, _, err = s.tool.ContinueHandler(context.Background(), nil, Input{Cursor: result.Meta[tools.OutputCursorField].(string)})
+	s.Require().NoError(err)
+	text := result.Content[0].(*mcp.TextContent).Text
+	s.Contains(text, "lines 2-2 of 2 from byte 20 of line 2")
+	s.True(strings.HasSuffix(text, "\n"+strings.Repeat("b", 10)))
+}
+
+func (s *ContinueOutputTestSuite) TestErrors() {
+	_, _, err := s.tool.ContinueHandler(context.Background(), nil, Input{})
+	s.ErrorContains(err, "validation error")
+	_, _, err = s.tool.ContinueHandler(context.Background(), nil, Input{Cursor: "not-hex"})
+	s.ErrorContains(err, "validation error")
+	_, _, err = s.tool.ContinueHandler(context.Background(), nil, Input{Cursor: "abcdef"})
+	s.ErrorContains(err, "cursor abcdef not found")
+
+	running := &models.ToolExecution{ToolName: "nikto", Status: models.StatusRunning, RawOutput: "a\nb"}
+	_, _, err = s.tool.ContinueHandler(context.Background(), nil, Input{Cursor: s.issue(context.Background(), running, 1)})
+	s.ErrorContains(err, "still being stored")
+
+	exec := &models.ToolExecution{ToolName: "nikto", Success: true, RawOutput: "a\nb"}
+	token := s.issue(context.Background(), exec, 1)
+	s.Require().NoError(s.store.DeleteToolExecution(context.Background(), exec.ID))
+	_, _, err = s.tool.ContinueHandler(context.Background(), nil, Input{Cursor: token})
+	s.ErrorContains(err, "not found")
+}
+
+func (s *ContinueOutputTestSuite) TestTenantScoping() {
+	alpha := tenant.WithTenant(context.Background(), "alpha")
+	beta := tenant.WithTenant(context.Background(), "beta")
+	token := s.issue(alpha, &models.ToolExecution{ToolName: "nikto", Success: true, RawOutput: "a\nb"}, 1)
+
+	_, _, err := s.tool.ContinueHandler(beta, nil, Input{Cursor: token})
+	s.ErrorContains(err, "not found")
+	_, _, err = s.tool.ContinueHandler(alpha, nil, Input{Cursor: token})
+	s.NoError(err)
+}
+
+func TestContinueOutputTestSuite(t *testing.T) {
+	suite.Run(t, new(ContinueOutputTestSuite))
+}
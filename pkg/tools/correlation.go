@@ -0,0 +1,64 @@
+package tools
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	// CorrelationField is the log field, result metadata key and execution column holding the
+	// correlation ID of a tool call.
+	CorrelationField = "correlation_id"
+	// correlationBytes is the number of random bytes in a correlation ID.
+	correlationBytes = 8
+)
+
+// correlationKey is the context key for the correlation ID of the current tool call.
+type correlationKey struct{}
+
+// sessionKey is the context key for the MCP session ID of the current tool call.
+type sessionKey struct{}
+
+// NewCorrelationID returns a random correlation ID identifying a single tool call.
+func NewCorrelationID() string {
+	buf := make([]byte, correlationBytes)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
+
+// WithCorrelationID returns a copy of ctx carrying the correlation ID of a tool call.
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationKey{}, id)
+}
+
+// CorrelationID returns the correlation ID of the tool call ctx belongs to, or an empty string
+// outside WrapToolHandler.
+func CorrelationID(ctx context.Context) string {
+	id, _ := ctx.Value(correlationKey{}).(string)
+	return id
+}
+
+// WithSessionID returns a copy of ctx carrying the MCP session ID of a tool call.
+func WithSessionID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, sessionKey{}, id)
+}
+
+// SessionID returns the MCP session ID of the tool call ctx belongs to, or an empty string
+// outside WrapToolHandler or when the client sent none.
+func SessionID(ctx context.Context) string {
+	id, _ := ctx.Value(sessionKey{}).(string)
+	return id
+}
+
+// ContextLogger returns logger with the correlation ID of ctx attached, so that every log line
+// of a tool call can be traced back to its execution.
+func ContextLogger(ctx context.Context, logger zerolog.Logger) zerolog.Logger {
+	id := CorrelationID(ctx)
+	if id == "" {
+		return logger
+	}
+	return logger.With().Str(CorrelationField, id).Logger()
+}
@@ -0,0 +1,47 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+)
+
+type CorrelationTestSuite struct {
+	suite.Suite
+}
+
+func (s *CorrelationTestSuite) TestNewCorrelationID() {
+	first, second := NewCorrelationID(), NewCorrelationID()
+	s.Len(first, 16)
+	s.NotEqual(first, second)
+}
+
+func (s *CorrelationTestSuite) TestCorrelationID() {
+	s.Empty(CorrelationID(context.Background()))
+	s.Equal("abc", CorrelationID(WithCorrelationID(context.Background(), "abc")))
+}
+
+func (s *CorrelationTestSuite) TestContextLogger() {
+	var buf bytes.Buffer
+	base := zerolog.New(&buf)
+
+	logger := ContextLogger(WithCorrelationID(context.Background(), "abc"), base)
+	logger.Info().Msg("scan started")
+
+	var entry map[string]any
+	s.Require().NoError(json.Unmarshal(buf.Bytes(), &entry))
+	s.Equal("abc", entry[CorrelationField])
+
+	buf.Reset()
+	logger = ContextLogger(context.Background(), base)
+	logger.Info().Msg("scan started")
+	s.NotContains(buf.String(), CorrelationField)
+}
+
+func TestCorrelationTestSuite(t *testing.T) {
+	suite.Run(t, new(CorrelationTestSuite))
+}
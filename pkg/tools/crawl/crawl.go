@@ -0,0 +1,160 @@
+package crawl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+	webcrawl "github.com/tb0hdan/wass-mcp/pkg/crawl"
+	"github.com/tb0hdan/wass-mcp/pkg/scope"
+	"github.com/tb0hdan/wass-mcp/pkg/server"
+	"github.com/tb0hdan/wass-mcp/pkg/storage"
+	"github.com/tb0hdan/wass-mcp/pkg/tenant"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+	"github.com/tb0hdan/wass-mcp/pkg/vault"
+)
+
+const toolName = "crawl"
+
+// Input names the target to crawl and bounds the crawl.
+type Input struct {
+	CABundle string `json:"ca_bundle,omitempty" validate:"omitempty,file"`
+	// Credential names the stored credential the crawl authenticates with, sent as request headers.
+	Credential string `json:"credential,omitempty" validate:"omitempty,max=64"`
+	// Engine is the crawl engine, the first available of katana and builtin when empty.
+	Engine             string `json:"engine,omitempty" validate:"omitempty,oneof=katana builtin"`
+	Host               string `json:"host" validate:"required,hostname_rfc1123|ip"`
+	InsecureSkipVerify bool   `json:"insecure_skip_verify,omitempty"`
+	// MaxDepth is the number of links followed from the start page, crawl.DefaultDepth when zero.
+	MaxDepth int `json:"max_depth,omitempty" validate:"min=0,max=10"`
+	// MaxURLs bounds the URLs returned, crawl.DefaultMaxURLs when zero.
+	MaxURLs int    `json:"max_urls,omitempty" validate:"min=0,max=1000"`
+	Path    string `json:"path,omitempty" validate:"omitempty,startswith=/,max=2048"`
+	Port    int    `json:"port,omitempty" validate:"min=0,max=65535"`
+	Scheme  string `json:"scheme,omitempty" validate:"omitempty,oneof=http https"`
+	// Timeout bounds the crawl in seconds, none when zero.
+	Timeout   int    `json:"timeout,omitempty" validate:"min=0,max=86400"`
+	UserAgent string `json:"user_agent,omitempty" validate:"omitempty,max=256"`
+	Vhost     string `json:"vhost,omitempty"`
+}
+
+// scannerInput returns the scanner input of the target of the input.
+func (i Input) scannerInput() tools.ScannerInput {
+	return tools.ScannerInput{
+		CABundle:           i.CABundle,
+		Host:               i.Host,
+		InsecureSkipVerify: i.InsecureSkipVerify,
+		Path:               i.Path,
+		Port:               i.Port,
+		Scheme:             i.Scheme,
+		Vhost:              i.Vhost,
+	}
+}
+
+// ScanTarget returns the crawled target, recorded with the execution of the crawl.
+func (i Input) ScanTarget() tools.ScanParams {
+	return tools.ResolveParams(i.scannerInput())
+}
+
+// Result is the crawl tool response.
+type Result struct {
+	// Engine is the crawl engine used.
+	Engine string `json:"engine"`
+	Target string `json:"target"`
+	// Truncated is set when URLs were left out to respect max_urls.
+	Truncated bool `json:"truncated,omitempty"`
+	// URLs are the URLs of the target found, the start URL first.
+	URLs []string `json:"urls"`
+}
+
+type Tool struct {
+	crawler   *webcrawl.Crawler
+	logger    zerolog.Logger
+	scope     *scope.Policy
+	store     storage.Storage
+	validator *validator.Validate
+	vault     *vault.Vault
+}
+
+func (t *Tool) Register(srv *server.Server) error {
+	tool := &mcp.Tool{
+		Name: toolName,
+		Description: "Crawls a target with katana or the built-in crawler and lists the URLs it links to, staying on the " +
+			"target host and port. Use it to review the attack surface before scanning; full_scan passes the crawled URLs " +
+			"to wapiti and nuclei with crawl_first, or scans each of them on its own with crawl_fan_out.",
+	}
+
+	t.scope = srv.Scope()
+	t.store = srv.Storage()
+	t.vault = srv.Vault()
+
+	wrappedHandler := tools.WrapToolHandler(srv.Storage(), toolName, t.Handler, tools.ServerWrapOptions(srv)...)
+	mcp.AddTool(&srv.Server, tool, tenant.RequireOperator(tools.ScanAction, wrappedHandler))
+	t.logger.Debug().Msgf("%s tool registered", toolName)
+
+	return nil
+}
+
+func (t *Tool) Handler(ctx context.Context, _ *mcp.CallToolRequest, input Input) (*mcp.CallToolResult, any, error) {
+	// Parse URL-style hosts before validation.
+	prepared := tools.PrepareScannerInput(input.scannerInput())
+	input.Host, input.Path, input.Port, input.Scheme = prepared.Host, prepared.Path, prepared.Port, prepared.Scheme
+	if err := t.validator.Struct(input); err != nil {
+		return nil, nil, fmt.Errorf("validation error: %w", err)
+	}
+
+	params := input.ScanTarget()
+	if err := t.scope.Check(params.Host, params.Port); err != nil {
+		return nil, nil, err
+	}
+	if input.UserAgent != "" {
+		params.Options = map[string]string{tools.OptionUserAgent: input.UserAgent}
+	}
+	if input.Credential != "" {
+		credential, err := t.vault.Resolve(ctx, t.store, input.Credential)
+		if err != nil {
+			return nil, nil, err
+		}
+		params.Credential = credential
+	}
+	if input.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(input.Timeout)*time.Second)
+		defer cancel()
+	}
+
+	crawled, err := t.crawler.Crawl(ctx, input.Engine, params, webcrawl.Limits{Depth: input.MaxDepth, MaxURLs: input.MaxURLs})
+	if err != nil {
+		return nil, nil, err
+	}
+	logger := tools.ContextLogger(ctx, t.logger)
+	logger.Info().Msgf("%s found %d URLs on %s", crawled.Engine, len(crawled.URLs), params.Target().URL())
+	tools.RecordRawOutput(ctx, strings.Join(crawled.URLs, "\n"))
+
+	data, _ := json.MarshalIndent(Result{
+		Engine:    crawled.Engine,
+		Target:    params.Target().URL(),
+		Truncated: crawled.Truncated,
+		URLs:      crawled.URLs,
+	}, "", "  ")
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: string(data)},
+		},
+	}, nil, nil
+}
+
+// New creates a new crawl tool.
+func New(logger zerolog.Logger) tools.Tool {
+	return &Tool{
+		crawler:   webcrawl.New(),
+		logger:    logger.With().Str("tool", toolName).Logger(),
+		validator: validator.New(),
+	}
+}
@@ -0,0 +1,102 @@
+package crawl
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+	webcrawl "github.com/tb0hdan/wass-mcp/pkg/crawl"
+	"github.com/tb0hdan/wass-mcp/pkg/scope"
+	"github.com/tb0hdan/wass-mcp/pkg/vault"
+)
+
+type CrawlTestSuite struct {
+	suite.Suite
+	server *httptest.Server
+	tool   *Tool
+}
+
+func (s *CrawlTestSuite) SetupTest() {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<a href="/login">Login</a> <a href="/search?q=1">Search</a> <a href="https://other.example/">Out</a>`))
+	})
+	s.server = httptest.NewServer(mux)
+	s.T().Cleanup(s.server.Close)
+
+	s.tool = New(zerolog.Nop()).(*Tool)
+	s.tool.crawler = &webcrawl.Crawler{Engines: []webcrawl.Engine{webcrawl.NewBuiltin()}}
+}
+
+// input returns the input crawling the test server.
+func (s *CrawlTestSuite) input() Input {
+	return Input{Host: s.server.URL}
+}
+
+func (s *CrawlTestSuite) TestCrawl() {
+	result, _, err := s.tool.Handler(context.Background(), nil, s.input())
+	s.Require().NoError(err)
+
+	var response Result
+	s.Require().NoError(json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &response))
+	s.Equal("builtin", response.Engine)
+	s.Equal(s.server.URL, response.Target)
+	s.Equal([]string{s.server.URL + "/", s.server.URL + "/login", s.server.URL + "/search?q=1"}, response.URLs)
+	s.False(response.Truncated)
+}
+
+func (s *CrawlTestSuite) TestCrawl_MaxURLs() {
+	input := s.input()
+	input.MaxURLs = 2
+
+	result, _, err := s.tool.Handler(context.Background(), nil, input)
+	s.Require().NoError(err)
+
+	var response Result
+	s.Require().NoError(json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &response))
+	s.Len(response.URLs, 2)
+	s.True(response.Truncated)
+}
+
+func (s *CrawlTestSuite) TestCrawl_OutOfScope() {
+	policy, err := scope.New("scanme.example.com")
+	s.Require().NoError(err)
+	s.tool.scope = policy
+
+	_, _, err = s.tool.Handler(context.Background(), nil, s.input())
+	s.ErrorIs(err, scope.ErrOutOfScope)
+}
+
+func (s *CrawlTestSuite) TestCrawl_CredentialWithoutVault() {
+	input := s.input()
+	input.Credential = "admin"
+
+	_, _, err := s.tool.Handler(context.Background(), nil, input)
+	s.ErrorIs(err, vault.ErrDisabled)
+}
+
+func (s *CrawlTestSuite) TestValidation() {
+	parsed, err := url.Parse(s.server.URL)
+	s.Require().NoError(err)
+
+	for name, input := range map[string]Input{
+		"invalid host":   {Host: "invalid host!!!"},
+		"unknown engine": {Host: parsed.Host, Engine: "gospider"},
+		"deep crawl":     {Host: parsed.Host, MaxDepth: webcrawl.MaxDepth + 1},
+		"large list":     {Host: parsed.Host, MaxURLs: webcrawl.MaxURLs + 1},
+	} {
+		_, _, err := s.tool.Handler(context.Background(), nil, input)
+		s.ErrorContains(err, "validation error", name)
+	}
+}
+
+func TestCrawlTestSuite(t *testing.T) {
+	suite.Run(t, new(CrawlTestSuite))
+}
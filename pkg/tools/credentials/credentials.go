@@ -0,0 +1,110 @@
+package credentials
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+	"github.com/tb0hdan/wass-mcp/pkg/server"
+	"github.com/tb0hdan/wass-mcp/pkg/storage"
+	"github.com/tb0hdan/wass-mcp/pkg/tenant"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+	"gorm.io/gorm"
+)
+
+const toolName = "credentials"
+
+type Input struct {
+	Action string `json:"action" validate:"required,oneof=list get delete"`
+	Name   string `json:"name,omitempty" validate:"omitempty,max=64"`
+}
+
+type Tool struct {
+	// enabled reports whether the server has encryption keys, without which credentials cannot be used.
+	enabled   bool
+	logger    zerolog.Logger
+	store     storage.Storage
+	validator *validator.Validate
+}
+
+func (t *Tool) Register(srv *server.Server) error {
+	tool := &mcp.Tool{
+		Name: toolName,
+		Description: "Lists the stored credentials scans can authenticate with by passing their name as the " +
+			"credential input of a scanner or full_scan, e.g. credential: \"staging-admin\". Types: basic, bearer, " +
+			"cookie, login_form. Secrets are set out of band through the admin API and are never returned. " +
+			"Actions: list, get (by name), delete (by name).",
+	}
+
+	t.enabled = srv.Vault() != nil
+	t.store = srv.Storage()
+
+	mcp.AddTool(&srv.Server, tool, t.Handler)
+	t.logger.Debug().Msgf("%s tool registered", toolName)
+
+	return nil
+}
+
+func (t *Tool) Handler(ctx context.Context, _ *mcp.CallToolRequest, input Input) (*mcp.CallToolResult, any, error) {
+	if err := t.validator.Struct(input); err != nil {
+		return nil, nil, fmt.Errorf("validation error: %w", err)
+	}
+	if input.Action != "list" && input.Name == "" {
+		return nil, nil, fmt.Errorf("name is required for %s action", input.Action)
+	}
+
+	var result any
+
+	switch input.Action {
+	case "list":
+		credentials, err := t.store.ListCredentials(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to list credentials: %w", err)
+		}
+		result = map[string]any{
+			"total":       len(credentials),
+			"credentials": credentials,
+			"enabled":     t.enabled,
+		}
+
+	case "get":
+		credential, err := t.store.GetCredential(ctx, input.Name)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, fmt.Errorf("credential %s not found", input.Name)
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load credential %s: %w", input.Name, err)
+		}
+		result = credential
+
+	case "delete":
+		if err := tenant.Authorize(ctx, "delete credentials"); err != nil {
+			return nil, nil, err
+		}
+		if err := t.store.DeleteCredential(ctx, input.Name); err != nil {
+			return nil, nil, fmt.Errorf("failed to delete credential %s: %w", input.Name, err)
+		}
+		t.logger.Debug().Msgf("Credential %s deleted", input.Name)
+		result = map[string]any{"deleted": input.Name}
+	}
+
+	data, _ := json.MarshalIndent(result, "", "  ")
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: string(data)},
+		},
+	}, nil, nil
+}
+
+// New creates a new credentials tool.
+func New(logger zerolog.Logger) tools.Tool {
+	return &Tool{
+		logger:    logger.With().Str("tool", toolName).Logger(),
+		validator: validator.New(),
+	}
+}
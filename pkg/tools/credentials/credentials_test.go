@@ -0,0 +1,107 @@
+package credentials
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/server"
+	"github.com/tb0hdan/wass-mcp/pkg/storage"
+	"github.com/tb0hdan/wass-mcp/pkg/tenant"
+)
+
+type CredentialsTestSuite struct {
+	suite.Suite
+	cleanup func()
+	store   storage.Storage
+	tool    *Tool
+}
+
+func (s *CredentialsTestSuite) SetupTest() {
+	tmpFile, err := os.CreateTemp("", "credentials-*.db")
+	s.Require().NoError(err)
+	tmpFile.Close()
+
+	store, err := storage.NewSQLiteStorage(storage.Config{DatabasePath: tmpFile.Name()})
+	s.Require().NoError(err)
+	srv := server.NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}, store)
+	s.cleanup = func() {
+		srv.Shutdown(context.Background())
+		os.Remove(tmpFile.Name())
+	}
+
+	s.store = store
+	s.tool = New(zerolog.Nop()).(*Tool)
+	s.Require().NoError(s.tool.Register(srv))
+}
+
+func (s *CredentialsTestSuite) TearDownTest() {
+	s.cleanup()
+}
+
+// call runs the handler and decodes its JSON response into out.
+func (s *CredentialsTestSuite) call(ctx context.Context, input Input, out any) error {
+	result, _, err := s.tool.Handler(ctx, &mcp.CallToolRequest{}, input)
+	if err != nil {
+		return err
+	}
+	s.Require().NoError(json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), out))
+
+	return nil
+}
+
+func (s *CredentialsTestSuite) TestLifecycle() {
+	ctx := tenant.WithTenant(context.Background(), "alpha")
+	s.Require().NoError(s.store.SaveCredential(ctx, &models.Credential{
+		Description: "Staging admin",
+		Name:        "staging-admin",
+		Secret:      []byte("sealed-secret"),
+		Type:        "basic",
+	}))
+
+	var list map[string]any
+	s.Require().NoError(s.call(ctx, Input{Action: "list"}, &list))
+	s.EqualValues(1, list["total"])
+	s.Equal(false, list["enabled"])
+
+	result, _, err := s.tool.Handler(ctx, &mcp.CallToolRequest{}, Input{Action: "get", Name: "staging-admin"})
+	s.Require().NoError(err)
+	text := result.Content[0].(*mcp.TextContent).Text
+	s.Contains(text, `"type": "basic"`)
+	s.NotContains(text, "secret")
+
+	s.ErrorContains(s.call(tenant.WithTenant(context.Background(), "beta"), Input{Action: "get", Name: "staging-admin"}, &list), "not found")
+
+	var deleted map[string]string
+	s.Require().NoError(s.call(ctx, Input{Action: "delete", Name: "staging-admin"}, &deleted))
+	s.Equal("staging-admin", deleted["deleted"])
+	s.ErrorContains(s.call(ctx, Input{Action: "get", Name: "staging-admin"}, &list), "credential staging-admin not found")
+}
+
+func (s *CredentialsTestSuite) TestValidation() {
+	var out map[string]any
+	s.ErrorContains(s.call(context.Background(), Input{Action: "get"}, &out), "name is required for get action")
+	s.ErrorContains(s.call(context.Background(), Input{Action: "set", Name: "api"}, &out), "validation error")
+}
+
+func (s *CredentialsTestSuite) TestReadOnly() {
+	readOnly := tenant.WithRole(context.Background(), tenant.RoleReadOnly)
+	var out map[string]any
+
+	err := s.call(readOnly, Input{Action: "delete", Name: "api"}, &out)
+	var wireErr *jsonrpc.Error
+	s.Require().ErrorAs(err, &wireErr)
+	s.EqualValues(tenant.CodeForbidden, wireErr.Code)
+
+	s.NoError(s.call(readOnly, Input{Action: "list"}, &out))
+}
+
+func TestCredentialsTestSuite(t *testing.T) {
+	suite.Run(t, new(CredentialsTestSuite))
+}
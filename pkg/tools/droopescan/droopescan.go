@@ -0,0 +1,102 @@
+package droopescan
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+	"github.com/tb0hdan/wass-mcp/pkg/server"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+)
+
+const (
+	binaryName  = "droopescan"
+	description = "droopescan is a CMS scanner identifying the versions, plugins and themes of Drupal, Joomla, SilverStripe and Moodle sites."
+	headerVerb  = "output"
+)
+
+// technologies are the CMSs droopescan applies to, in the order a CMS is picked from hints.
+// WordPress, which droopescan supports partially, is left to wpscan.
+var technologies = []string{"drupal", "joomla", "silverstripe", "moodle"}
+
+// supportedOptions are the scan options droopescan honours.
+var supportedOptions = []string{tools.OptionCABundle, tools.OptionUserAgent, tools.OptionVhost}
+
+// Tool implements the droopescan scanner.
+type Tool struct {
+	tools.BaseScanner
+}
+
+// Scan performs the droopescan scan and returns the output.
+func (t *Tool) Scan(ctx context.Context, params tools.ScanParams) tools.ScanResult {
+	targetURL := params.Target().URL()
+	logger := tools.ContextLogger(ctx, t.Logger)
+	logger.Info().Msgf("Running droopescan scan on %s", targetURL)
+
+	workDir, cleanup, err := t.WorkDir(ctx)
+	if err != nil {
+		return tools.ScanResult{
+			Error: err,
+		}
+	}
+	defer cleanup()
+
+	cmd := t.Command(ctx, buildArgs(targetURL, params)...)
+	cmd.Dir = workDir
+	cmd.Env = tools.ScanEnv(params, workDir)
+	output, err := t.CombinedOutput(ctx, cmd)
+
+	if err != nil {
+		return tools.ScanResult{
+			Output: string(output),
+			Error:  fmt.Errorf("failed to execute droopescan: %w", err),
+		}
+	}
+
+	return tools.ScanResult{
+		Output: string(output),
+		Error:  nil,
+	}
+}
+
+// buildArgs builds the droopescan command line arguments. The CMS scanned is the first of
+// technologies found in the hints of params; without one droopescan identifies the CMS itself.
+func buildArgs(targetURL string, params tools.ScanParams) []string {
+	args := []string{"scan"}
+	for _, cms := range technologies {
+		if slices.Contains(params.Hints, cms) {
+			args = append(args, cms)
+			break
+		}
+	}
+	args = append(args, "-u", targetURL, "--hide-progressbar")
+	if params.Vhost != "" {
+		args = append(args, "--host", params.Vhost)
+	}
+	if userAgent := params.Option(tools.OptionUserAgent); userAgent != "" {
+		args = append(args, "--user-agent", userAgent)
+	}
+
+	return args
+}
+
+// Register registers the droopescan tool with the MCP server.
+func (t *Tool) Register(srv *server.Server) error {
+	return t.RegisterTool(srv, t.Handler)
+}
+
+// Handler handles MCP tool requests.
+func (t *Tool) Handler(ctx context.Context, _ *mcp.CallToolRequest, input tools.ScannerInput) (*mcp.CallToolResult, any, error) {
+	return t.HandleScan(ctx, input, headerVerb, t.Scan)
+}
+
+// New creates a new droopescan scanner tool.
+func New(logger zerolog.Logger) tools.Scanner {
+	base := tools.NewBaseScanner(binaryName, description, logger, supportedOptions...)
+	base.WarmUpArgs = []string{"--help"}
+	base.ForTechnologies = technologies
+
+	return &Tool{BaseScanner: base}
+}
@@ -0,0 +1,53 @@
+package droopescan
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+)
+
+type DroopescanTestSuite struct {
+	suite.Suite
+	logger zerolog.Logger
+	tool   *Tool
+}
+
+func (s *DroopescanTestSuite) SetupTest() {
+	s.logger = zerolog.New(os.Stdout).With().Timestamp().Logger()
+	s.tool = New(s.logger).(*Tool)
+}
+
+func (s *DroopescanTestSuite) TestNew() {
+	s.Equal("droopescan", s.tool.Name())
+	s.Equal([]string{"drupal", "joomla", "silverstripe", "moodle"}, tools.Technologies(s.tool))
+}
+
+func (s *DroopescanTestSuite) TestBuildArgs_Default() {
+	args := buildArgs("https://cms.example.com", tools.ScanParams{})
+	s.Equal([]string{"scan", "-u", "https://cms.example.com", "--hide-progressbar"}, args)
+}
+
+func (s *DroopescanTestSuite) TestBuildArgs_Hints() {
+	args := buildArgs("https://cms.example.com", tools.ScanParams{Hints: []string{"nginx", "php", "joomla"}})
+	s.Equal([]string{"scan", "joomla", "-u", "https://cms.example.com", "--hide-progressbar"}, args)
+}
+
+func (s *DroopescanTestSuite) TestBuildArgs_Options() {
+	params := tools.ScanParams{Options: map[string]string{tools.OptionUserAgent: "wass"}, Vhost: "cms.example.com"}
+	args := buildArgs("http://10.0.0.1", params)
+	s.Equal([]string{"--host", "cms.example.com", "--user-agent", "wass"}, args[len(args)-4:])
+}
+
+func (s *DroopescanTestSuite) TestHandler_ValidationError() {
+	_, _, err := s.tool.Handler(context.Background(), &mcp.CallToolRequest{}, tools.ScannerInput{Host: "invalid host!!!"})
+	s.ErrorContains(err, "validation error")
+}
+
+func TestDroopescanTestSuite(t *testing.T) {
+	suite.Run(t, new(DroopescanTestSuite))
+}
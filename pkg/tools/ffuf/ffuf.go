@@ -0,0 +1,160 @@
+package ffuf
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+	"github.com/tb0hdan/wass-mcp/pkg/server"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+	"github.com/tb0hdan/wass-mcp/pkg/vault"
+)
+
+const (
+	binaryName  = "ffuf"
+	description = "ffuf brute-forces the paths of the target with a wordlist, reporting the files and directories it discovers " +
+		"as findings with their URL, status and size. Pass a stored wordlist as wordlist (a built-in list of common paths " +
+		"otherwise), options.extensions (e.g. .php,.bak) and options.threads."
+	headerVerb = "output"
+	// fuzzKeyword is the keyword of the target URL ffuf replaces with each wordlist entry.
+	fuzzKeyword = "FUZZ"
+	// defaultWordlistFile is the file, relative to the working directory, the default wordlist is
+	// written to for scans without a stored wordlist.
+	defaultWordlistFile = "wordlist.txt"
+)
+
+// defaultWordlist are the common paths brute-forced by scans without a stored wordlist.
+var defaultWordlist = []string{
+	".env", ".git/HEAD", ".git/config", ".htaccess", ".htpasswd", ".svn/entries", ".well-known/security.txt",
+	"admin", "administrator", "api", "app", "assets", "backup", "backups", "bin", "cgi-bin", "config", "console",
+	"dashboard", "data", "db", "debug", "dev", "docs", "download", "files", "graphql", "health", "images", "include",
+	"index.php", "info.php", "install", "js", "login", "logs", "manager", "metrics", "old", "phpinfo.php",
+	"phpmyadmin", "portal", "private", "robots.txt", "server-status", "setup", "sitemap.xml", "static", "status",
+	"swagger", "swagger.json", "temp", "test", "tmp", "upload", "uploads", "user", "v1", "v2", "web.config",
+	"wp-admin", "wp-login.php",
+}
+
+// supportedOptions are the scan options ffuf honours.
+var supportedOptions = []string{
+	tools.OptionCapture, tools.OptionCredential, tools.OptionExtensions, tools.OptionRateLimit, tools.OptionThreads,
+	tools.OptionUserAgent, tools.OptionVhost, tools.OptionWordlist,
+}
+
+// Tool implements the ffuf content discovery scanner.
+type Tool struct {
+	tools.BaseScanner
+}
+
+// Scan brute-forces the paths below the target URL and returns the ffuf JSON lines, one per
+// discovered path.
+func (t *Tool) Scan(ctx context.Context, params tools.ScanParams) tools.ScanResult {
+	logger := tools.ContextLogger(ctx, t.Logger)
+	logger.Info().Msgf("Running ffuf content discovery on %s", params.Target().URL())
+
+	if err := params.CheckCredential(binaryName, vault.HeaderTypes...); err != nil {
+		return tools.ScanResult{
+			Error: err,
+		}
+	}
+
+	workDir, cleanup, err := t.WorkDir(ctx)
+	if err != nil {
+		return tools.ScanResult{
+			Error: err,
+		}
+	}
+	defer cleanup()
+
+	wordlist := params.Wordlist
+	if wordlist == "" {
+		wordlist = filepath.Join(workDir, defaultWordlistFile)
+		if err := os.WriteFile(wordlist, []byte(strings.Join(defaultWordlist, "\n")+"\n"), 0o600); err != nil {
+			return tools.ScanResult{
+				Error: fmt.Errorf("failed to write ffuf wordlist: %w", err),
+			}
+		}
+	}
+
+	cmd := t.Command(ctx, buildArgs(params, wordlist)...)
+	cmd.Dir = workDir
+	cmd.Env = tools.ScanEnv(params, workDir)
+	output, err := t.CombinedOutput(ctx, cmd)
+	if err != nil {
+		return tools.ScanResult{
+			Output: string(output),
+			Error:  fmt.Errorf("failed to execute ffuf: %w", err),
+		}
+	}
+
+	return tools.ScanResult{
+		Output: string(output),
+		Error:  nil,
+	}
+}
+
+// buildArgs builds the ffuf command line arguments, fuzzing the path segment below the target URL
+// with wordlist. Auto-calibration filters the responses of catch-all pages that answer every path.
+func buildArgs(params tools.ScanParams, wordlist string) []string {
+	fuzzURL := strings.TrimSuffix(params.Target().RequestURL(), "/") + "/" + fuzzKeyword
+	args := []string{"-u", fuzzURL, "-w", wordlist, "-json", "-s", "-noninteractive", "-ac"}
+
+	if extensions := params.Option(tools.OptionExtensions); extensions != "" {
+		args = append(args, "-e", normalizeExtensions(extensions))
+	}
+	if threads := params.Option(tools.OptionThreads); threads != "" {
+		args = append(args, "-t", threads)
+	}
+	if rate, err := strconv.Atoi(params.Option(tools.OptionRateLimit)); err == nil && rate > 0 {
+		args = append(args, "-rate", strconv.Itoa(rate))
+	}
+	if header := params.Target().HostHeader(); header != "" {
+		args = append(args, "-H", header)
+	}
+	if userAgent := params.Option(tools.OptionUserAgent); userAgent != "" {
+		args = append(args, "-H", "User-Agent: "+userAgent)
+	}
+	if params.Credential != nil {
+		for _, header := range params.Credential.Headers() {
+			args = append(args, "-H", header)
+		}
+	}
+	if params.Proxy != "" {
+		args = append(args, "-x", params.Proxy)
+	}
+
+	return args
+}
+
+// normalizeExtensions returns the comma-separated extensions with a leading dot each, the form ffuf
+// appends to wordlist entries.
+func normalizeExtensions(value string) string {
+	var extensions []string
+	for _, extension := range strings.Split(value, ",") {
+		extensions = append(extensions, "."+strings.TrimPrefix(strings.TrimSpace(extension), "."))
+	}
+
+	return strings.Join(extensions, ",")
+}
+
+// Register registers the ffuf tool with the MCP server.
+func (t *Tool) Register(srv *server.Server) error {
+	return t.RegisterTool(srv, t.Handler)
+}
+
+// Handler handles MCP tool requests.
+func (t *Tool) Handler(ctx context.Context, _ *mcp.CallToolRequest, input tools.ScannerInput) (*mcp.CallToolResult, any, error) {
+	return t.HandleScan(ctx, input, headerVerb, t.Scan)
+}
+
+// New creates a new ffuf scanner tool.
+func New(logger zerolog.Logger) tools.Scanner {
+	base := tools.NewBaseScanner(binaryName, description, logger, supportedOptions...)
+	base.VersionArgs = []string{"-V"}
+
+	return &Tool{BaseScanner: base}
+}
@@ -0,0 +1,111 @@
+package ffuf
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+	"github.com/tb0hdan/wass-mcp/pkg/vault"
+)
+
+type FfufTestSuite struct {
+	suite.Suite
+	logger zerolog.Logger
+	tool   *Tool
+}
+
+func (s *FfufTestSuite) SetupTest() {
+	s.logger = zerolog.New(os.Stdout).With().Timestamp().Logger()
+	s.tool = New(s.logger).(*Tool)
+}
+
+// fakeBinary installs a fake ffuf running script.
+func (s *FfufTestSuite) fakeBinary(script string) {
+	binDir := s.T().TempDir()
+	s.Require().NoError(os.WriteFile(filepath.Join(binDir, binaryName), []byte("#!/bin/sh\n"+script+"\n"), 0o700)) //nolint:gosec
+	s.T().Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func (s *FfufTestSuite) TestNew() {
+	s.Equal("ffuf", s.tool.Name())
+	s.Empty(tools.Technologies(s.tool), "ffuf runs in full_scan")
+	s.False(tools.IsPassive(s.tool))
+	s.Contains(s.tool.SupportedOptions(), tools.OptionWordlist)
+}
+
+func (s *FfufTestSuite) TestBuildArgs_Default() {
+	args := buildArgs(tools.ScanParams{Host: "example.com", Scheme: "https"}, "/tmp/words.txt")
+	s.Equal([]string{"-u", "https://example.com/FUZZ", "-w", "/tmp/words.txt", "-json", "-s", "-noninteractive", "-ac"}, args)
+
+	args = buildArgs(tools.ScanParams{Host: "10.0.0.1", Port: 8080, Path: "/app/"}, "/tmp/words.txt")
+	s.Equal("http://10.0.0.1:8080/app/FUZZ", args[1], "paths below the base path are fuzzed")
+}
+
+func (s *FfufTestSuite) TestBuildArgs_Options() {
+	params := tools.ScanParams{
+		Host:  "10.0.0.1",
+		Port:  80,
+		Vhost: "shop.example.com",
+		Options: map[string]string{
+			tools.OptionExtensions: "php, .bak",
+			tools.OptionThreads:    "10",
+			tools.OptionRateLimit:  "50",
+			tools.OptionUserAgent:  "wass",
+		},
+		Credential: &vault.Credential{Type: vault.TypeBearer, Secret: vault.Secret{Token: "secret"}},
+		Proxy:      "http://127.0.0.1:8081",
+	}
+	s.Equal([]string{
+		"-u", "http://10.0.0.1/FUZZ", "-w", "words.txt", "-json", "-s", "-noninteractive", "-ac",
+		"-e", ".php,.bak", "-t", "10", "-rate", "50", "-H", "Host: shop.example.com", "-H", "User-Agent: wass",
+		"-H", "Authorization: Bearer secret", "-x", "http://127.0.0.1:8081",
+	}, buildArgs(params, "words.txt"))
+}
+
+func (s *FfufTestSuite) TestScan_DefaultWordlist() {
+	// The fake ffuf prints its wordlist argument and the entries of the wordlist.
+	s.fakeBinary(`echo "$4"; cat "$4"`)
+
+	result := s.tool.Scan(context.Background(), tools.ScanParams{Host: "localhost", Port: 8080})
+	s.Require().NoError(result.Error)
+	s.Contains(result.Output, defaultWordlistFile)
+	s.Contains(result.Output, "\nadmin\n")
+
+	wordlist := filepath.Join(s.T().TempDir(), "raft.txt")
+	s.Require().NoError(os.WriteFile(wordlist, []byte("custom\n"), 0o600))
+	result = s.tool.Scan(context.Background(), tools.ScanParams{Host: "localhost", Port: 8080, Wordlist: wordlist})
+	s.Require().NoError(result.Error)
+	s.Equal(wordlist+"\ncustom\n", result.Output)
+}
+
+func (s *FfufTestSuite) TestScan_Failure() {
+	s.fakeBinary("echo 'Encountered error(s): no such host'\nexit 1")
+
+	result := s.tool.Scan(context.Background(), tools.ScanParams{Host: "nowhere.invalid", Port: 80})
+	s.ErrorContains(result.Error, "failed to execute ffuf")
+	s.Contains(result.Output, "no such host")
+}
+
+func (s *FfufTestSuite) TestScan_UnsupportedCredential() {
+	params := tools.ScanParams{Host: "localhost", Credential: &vault.Credential{Type: vault.TypeLoginForm}}
+	s.ErrorIs(s.tool.Scan(context.Background(), params).Error, vault.ErrUnsupportedType)
+}
+
+func (s *FfufTestSuite) TestHandler_ValidationError() {
+	_, _, err := s.tool.Handler(context.Background(), &mcp.CallToolRequest{}, tools.ScannerInput{Host: "invalid host!!!"})
+	s.ErrorContains(err, "validation error")
+
+	_, _, err = s.tool.Handler(context.Background(), &mcp.CallToolRequest{}, tools.ScannerInput{
+		Host: "example.com", Options: map[string]string{tools.OptionExtensions: "-x"},
+	})
+	s.ErrorContains(err, "option extensions")
+}
+
+func TestFfufTestSuite(t *testing.T) {
+	suite.Run(t, new(FfufTestSuite))
+}
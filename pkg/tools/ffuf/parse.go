@@ -0,0 +1,68 @@
+package ffuf
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/tb0hdan/wass-mcp/pkg/findings"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/types"
+)
+
+// result is a discovered path of ffuf JSON lines output.
+type result struct {
+	ContentType      string `json:"content-type"`
+	Length           int    `json:"length"`
+	Lines            int    `json:"lines"`
+	RedirectLocation string `json:"redirectlocation"`
+	Status           int    `json:"status"`
+	URL              string `json:"url"`
+	Words            int    `json:"words"`
+}
+
+// ParseFindings parses ffuf JSON lines output into one informational finding per discovered path,
+// with the URL of the path, so that other scanners can target it, and its status, size and
+// redirect as evidence. Lines that are not JSON results, such as errors, are skipped.
+func (t *Tool) ParseFindings(output string) ([]models.Finding, error) {
+	var found []models.Finding
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "{") {
+			continue
+		}
+		var res result
+		if err := json.Unmarshal([]byte(line), &res); err != nil || res.URL == "" {
+			continue
+		}
+		found = append(found, res.finding())
+	}
+
+	return found, nil
+}
+
+// finding returns the finding of a discovered path.
+func (r result) finding() models.Finding {
+	path := r.URL
+	if parsed, err := url.Parse(r.URL); err == nil && parsed.Path != "" {
+		path = parsed.EscapedPath()
+	}
+
+	details := []string{fmt.Sprintf("status %d", r.Status), fmt.Sprintf("%d bytes", r.Length),
+		fmt.Sprintf("%d words", r.Words), fmt.Sprintf("%d lines", r.Lines)}
+	if r.ContentType != "" {
+		details = append(details, r.ContentType)
+	}
+	if r.RedirectLocation != "" {
+		details = append(details, "redirects to "+r.RedirectLocation)
+	}
+
+	return models.Finding{
+		Evidence: findings.AppendEvidence(nil, models.EvidenceExtracted, binaryName, strings.Join(details, ", ")),
+		Scanner:  binaryName,
+		Severity: types.SeverityInfo,
+		Title:    fmt.Sprintf("Discovered path %s (HTTP %d)", path, r.Status),
+		URL:      r.URL,
+	}
+}
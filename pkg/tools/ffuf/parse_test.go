@@ -0,0 +1,58 @@
+package ffuf
+
+import (
+	"os"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/types"
+)
+
+const jsonOutput = `{"input":{"FFUFHASH":"5b7a1","FUZZ":"admin"},"position":6,"status":301,"length":169,"words":5,"lines":8,"content-type":"text/html","redirectlocation":"http://shop.example.com/admin/","scraper":{},"duration":12345,"resultfile":"","url":"http://shop.example.com/admin","host":"shop.example.com"}
+{"input":{"FFUFHASH":"5b7a2","FUZZ":"robots.txt"},"position":44,"status":200,"length":68,"words":6,"lines":4,"content-type":"text/plain","redirectlocation":"","scraper":{},"duration":9876,"resultfile":"","url":"http://shop.example.com/robots.txt","host":"shop.example.com"}
+Encountered error(s): 1 errors occurred.
+	* Get "http://shop.example.com/.env": context deadline exceeded
+`
+
+type ParseTestSuite struct {
+	suite.Suite
+	tool *Tool
+}
+
+func (s *ParseTestSuite) SetupTest() {
+	s.tool = New(zerolog.New(os.Stdout)).(*Tool)
+}
+
+func (s *ParseTestSuite) TestParseFindings() {
+	found, err := s.tool.ParseFindings(jsonOutput)
+	s.Require().NoError(err)
+	s.Require().Len(found, 2, "error lines are skipped")
+
+	s.Equal("Discovered path /admin (HTTP 301)", found[0].Title)
+	s.Equal("http://shop.example.com/admin", found[0].URL)
+	s.Equal(types.SeverityInfo, found[0].Severity)
+	s.Equal("ffuf", found[0].Scanner)
+	s.Require().Len(found[0].Evidence, 1)
+	s.Equal(models.EvidenceExtracted, found[0].Evidence[0].Kind)
+	s.Equal("status 301, 169 bytes, 5 words, 8 lines, text/html, redirects to http://shop.example.com/admin/",
+		found[0].Evidence[0].Content)
+
+	s.Equal("Discovered path /robots.txt (HTTP 200)", found[1].Title)
+	s.Equal("status 200, 68 bytes, 6 words, 4 lines, text/plain", found[1].Evidence[0].Content)
+}
+
+func (s *ParseTestSuite) TestParseFindings_NothingFound() {
+	found, err := s.tool.ParseFindings("")
+	s.Require().NoError(err)
+	s.Empty(found)
+
+	found, err = s.tool.ParseFindings("{not json}\n{\"status\":200}\n")
+	s.Require().NoError(err)
+	s.Empty(found)
+}
+
+func TestParseTestSuite(t *testing.T) {
+	suite.Run(t, new(ParseTestSuite))
+}
@@ -0,0 +1,71 @@
+package tools
+
+import (
+	"encoding/json"
+	"slices"
+
+	"github.com/tb0hdan/wass-mcp/pkg/findings"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/types"
+)
+
+// FindingsParser is optionally implemented by scanners that parse their own raw output into findings.
+// Scanners without it fall back to the generic bracketed severity tag extraction.
+type FindingsParser interface {
+	ParseFindings(output string) ([]models.Finding, error)
+}
+
+// ParseFindings extracts findings from the raw output of scanner using its native parser when it
+// implements FindingsParser. Scanners without a parser, or whose parser fails, fall back to
+// bracketed severity tags in the raw text. The CWE IDs of the findings are normalized.
+func ParseFindings(scanner Scanner, output string) []models.Finding {
+	if parser, ok := scanner.(FindingsParser); ok {
+		if found, err := parser.ParseFindings(output); err == nil {
+			findings.SetCWEs(found)
+			return found
+		}
+	}
+
+	found := findings.ExtractGeneric(scanner.Name(), output)
+	findings.SetCWEs(found)
+
+	return found
+}
+
+// RegisterFindingsParsers registers the native parsers of scanners with the findings package,
+// so that stored outputs are parsed the same way as live results.
+func RegisterFindingsParsers(scanners ...Scanner) {
+	for _, scanner := range scanners {
+		if parser, ok := scanner.(FindingsParser); ok {
+			findings.RegisterParser(scanner.Name(), parser.ParseFindings)
+		}
+	}
+}
+
+// StructuredFindings is the structured content of wrapped tool results: the findings of the call
+// alongside its raw text, so that clients need not parse scanner output. Findings are listed
+// without their evidence, highest risk first (see findings.Prioritize), up to types.MaxStructuredFindings.
+type StructuredFindings struct {
+	Findings   []models.Finding `json:"findings"`
+	Severities map[string]int   `json:"severities"`
+	Total      int              `json:"total"`
+	Truncated  bool             `json:"truncated,omitempty"`
+}
+
+// structuredFindings returns the structured content of found as JSON. found is left unchanged.
+func structuredFindings(found []models.Finding) json.RawMessage {
+	listed := slices.Clone(found)
+	findings.Prioritize(listed)
+	content := StructuredFindings{
+		Findings:   listed[:min(len(listed), types.MaxStructuredFindings)],
+		Severities: findings.CountBySeverity(found),
+		Total:      len(found),
+		Truncated:  len(listed) > types.MaxStructuredFindings,
+	}
+	for i := range content.Findings {
+		content.Findings[i].Evidence = nil
+	}
+	data, _ := json.Marshal(content)
+
+	return data
+}
@@ -0,0 +1,83 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/findings"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/server"
+	"github.com/tb0hdan/wass-mcp/pkg/types"
+)
+
+// textScanner is a scanner without a native findings parser.
+type textScanner struct {
+	name string
+}
+
+func (t *textScanner) Register(_ *server.Server) error { return nil }
+
+func (t *textScanner) Name() string { return t.name }
+
+func (t *textScanner) IsAvailable() bool { return true }
+
+func (t *textScanner) Scan(_ context.Context, _ ScanParams) ScanResult { return ScanResult{} }
+
+// parsingScanner is a scanner with a native findings parser.
+type parsingScanner struct {
+	textScanner
+	err   error
+	found []models.Finding
+}
+
+func (p *parsingScanner) ParseFindings(_ string) ([]models.Finding, error) {
+	return p.found, p.err
+}
+
+type FindingsTestSuite struct {
+	suite.Suite
+}
+
+func (s *FindingsTestSuite) TestParseFindings_NativeParser() {
+	scanner := &parsingScanner{
+		textScanner: textScanner{name: "native"},
+		found:       []models.Finding{{Scanner: "native", Severity: types.SeverityHigh, Title: "Parsed"}},
+	}
+
+	found := ParseFindings(scanner, "[low] ignored")
+	s.Equal(scanner.found, found)
+}
+
+func (s *FindingsTestSuite) TestParseFindings_ParserErrorFallsBack() {
+	scanner := &parsingScanner{textScanner: textScanner{name: "native"}, err: errors.New("bad output")}
+
+	found := ParseFindings(scanner, "[low] https://example.com/x")
+	s.Require().Len(found, 1)
+	s.Equal(types.SeverityLow, found[0].Severity)
+	s.Equal("native", found[0].Scanner)
+}
+
+func (s *FindingsTestSuite) TestParseFindings_RawTextFallback() {
+	found := ParseFindings(&textScanner{name: "text"}, "[medium] https://example.com/y\nnoise")
+	s.Require().Len(found, 1)
+	s.Equal(types.SeverityMedium, found[0].Severity)
+	s.Equal("https://example.com/y", found[0].URL)
+}
+
+func (s *FindingsTestSuite) TestRegisterFindingsParsers() {
+	scanner := &parsingScanner{
+		textScanner: textScanner{name: "registered-native"},
+		found:       []models.Finding{{Scanner: "registered-native", Severity: types.SeverityCritical}},
+	}
+
+	RegisterFindingsParsers(scanner, &textScanner{name: "registered-text"})
+
+	s.Equal(scanner.found, findings.Extract("registered-native", "anything"))
+	s.Empty(findings.Extract("registered-text", "anything"))
+}
+
+func TestFindingsTestSuite(t *testing.T) {
+	suite.Run(t, new(FindingsTestSuite))
+}
@@ -0,0 +1,55 @@
+package fullscan
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+	"github.com/tb0hdan/wass-mcp/pkg/vault"
+)
+
+// crawlBranches crawls the target of a crawl fan-out scan and returns the branches the scan fans
+// out to: the crawled URLs without query or duplicates, at most maxTargets of them, with the note
+// describing the crawl. The scan fails when the crawl does, as there is nothing to fan out to.
+func (t *Tool) crawlBranches(ctx context.Context, input Input) ([]string, string, error) {
+	params := tools.ResolveParams(input.ScannerInput)
+	if err := t.scope.Check(params.Host, params.Port); err != nil {
+		return nil, "", err
+	}
+	params.Credential, _ = ctx.Value(credentialKey{}).(*vault.Credential)
+
+	urls, note := t.crawlTarget(ctx, params)
+	if len(urls) == 0 {
+		return nil, "", fmt.Errorf("crawl of %s %s", params.Target().URL(), note)
+	}
+
+	return branchTargets(urls), note, nil
+}
+
+// branchTargets returns the scan targets of the crawled urls: the URLs without query and
+// fragment, as scanners take a base path, without duplicates and at most maxTargets of them.
+func branchTargets(urls []string) []string {
+	branches := make([]string, 0, len(urls))
+	for _, raw := range urls {
+		parsed, err := url.Parse(raw)
+		if err != nil {
+			continue
+		}
+		parsed.RawQuery, parsed.ForceQuery, parsed.Fragment, parsed.RawFragment = "", false, "", ""
+		if branch := parsed.String(); !slices.Contains(branches, branch) {
+			branches = append(branches, branch)
+		}
+		if len(branches) == maxTargets {
+			break
+		}
+	}
+
+	return branches
+}
+
+// fanOutLines returns the header lines of the report of a crawl fan-out scan of targetURL.
+func fanOutLines(targetURL, note string, branches int) []string {
+	return []string{fmt.Sprintf("Crawl fan-out: %s (%s, %d branches)", targetURL, note, branches)}
+}
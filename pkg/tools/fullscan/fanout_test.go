@@ -0,0 +1,113 @@
+package fullscan
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/crawl"
+	"github.com/tb0hdan/wass-mcp/pkg/server"
+	"github.com/tb0hdan/wass-mcp/pkg/storage"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+)
+
+// branchScanner is a hostScanner failing on the URLs ending with /broken.
+type branchScanner struct {
+	hostScanner
+}
+
+func (b *branchScanner) Scan(ctx context.Context, params tools.ScanParams) tools.ScanResult {
+	if strings.HasSuffix(params.Target().URL(), "/broken") {
+		return tools.ScanResult{Error: errors.New("scanner crashed")}
+	}
+
+	return b.hostScanner.Scan(ctx, params)
+}
+
+type FanOutTestSuite struct {
+	suite.Suite
+	cleanup func()
+	scanner *branchScanner
+	site    *httptest.Server
+	tool    *Tool
+}
+
+func (s *FanOutTestSuite) SetupTest() {
+	tmpFile, err := os.CreateTemp("", "fullscan-fanout-*.db")
+	s.Require().NoError(err)
+	tmpFile.Close()
+
+	store, err := storage.NewSQLiteStorage(storage.Config{DatabasePath: tmpFile.Name()})
+	s.Require().NoError(err)
+	srv := server.NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}, store)
+	s.site = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		if r.URL.Path == "/" {
+			_, _ = w.Write([]byte(`<a href="/login">Login</a> <a href="/search?q=1">1</a> <a href="/search?q=2">2</a> <a href="/broken">x</a>`))
+		}
+	}))
+	s.cleanup = func() {
+		s.site.Close()
+		srv.Shutdown(context.Background())
+		os.Remove(tmpFile.Name())
+	}
+
+	s.scanner = &branchScanner{hostScanner{mockScanner: mockScanner{name: "mock1", available: true}}}
+	s.tool = New(zerolog.Nop(), tools.NewRegistry(s.scanner)).(*Tool)
+	s.tool.crawler = &crawl.Crawler{Engines: []crawl.Engine{crawl.NewBuiltin()}}
+	s.Require().NoError(s.tool.Register(srv))
+}
+
+func (s *FanOutTestSuite) TearDownTest() {
+	s.cleanup()
+}
+
+func (s *FanOutTestSuite) TestFanOutScan() {
+	input := Input{ScannerInput: tools.ScannerInput{Host: s.site.URL}, CrawlFanOut: true, Concurrency: 2}
+	result, _, err := s.tool.Run(context.Background(), &mcp.CallToolRequest{}, input)
+	s.Require().NoError(err)
+	s.ElementsMatch([]string{s.site.URL, s.site.URL + "/login", s.site.URL + "/search"}, s.scanner.scanned,
+		"each branch is scanned once, without its query")
+
+	text := result.Content[0].(*mcp.TextContent).Text
+	s.Contains(text, "Crawl fan-out: "+s.site.URL+" (5 URLs found by builtin, 4 branches)")
+	s.Contains(text, "Total hosts: 4 | Scanned: 4 | Failed: 0")
+	s.Contains(text, s.site.URL+"/broken: Successful: 0 | Failed: 1", "a failed branch does not fail the others")
+	s.Contains(text, "Total findings: 3 (critical: 0, high: 3, medium: 0, low: 0, info: 0)")
+}
+
+func (s *FanOutTestSuite) TestFanOutScan_CrawlFailed() {
+	s.site.Close()
+	_, _, err := s.tool.FullScanHandler(context.Background(), &mcp.CallToolRequest{},
+		Input{ScannerInput: tools.ScannerInput{Host: s.site.URL}, CrawlFanOut: true})
+	s.ErrorContains(err, "failed: builtin crawl failed")
+	s.Empty(s.scanner.scanned)
+}
+
+func (s *FanOutTestSuite) TestFanOutScan_Invalid() {
+	for name, input := range map[string]Input{
+		"hosts":   {Hosts: []string{"a.example.com"}, CrawlFanOut: true},
+		"crawl":   {ScannerInput: tools.ScannerInput{Host: "a.example.com"}, CrawlFanOut: true, CrawlFirst: true},
+		"ports":   {ScannerInput: tools.ScannerInput{Host: "a.example.com"}, CrawlFanOut: true, Ports: []int{80, 443}},
+		"passive": {ScannerInput: tools.ScannerInput{Host: "a.example.com", Passive: true}, CrawlFanOut: true},
+	} {
+		_, _, err := s.tool.FullScanHandler(context.Background(), &mcp.CallToolRequest{}, input)
+		s.ErrorContains(err, "crawl_fan_out", name)
+	}
+}
+
+func (s *FanOutTestSuite) TestBranchTargets() {
+	s.Equal([]string{"http://a/", "http://a/x", "http://a/y"},
+		branchTargets([]string{"http://a/", "http://a/x?q=1", "http://a/x?q=2", "http://a/y#top"}))
+}
+
+func TestFanOutTestSuite(t *testing.T) {
+	suite.Run(t, new(FanOutTestSuite))
+}
@@ -2,7 +2,11 @@ package fullscan
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -10,48 +14,281 @@ import (
 	"github.com/go-playground/validator/v10"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/rs/zerolog"
+	"github.com/tb0hdan/wass-mcp/pkg/crawl"
+	"github.com/tb0hdan/wass-mcp/pkg/discovery"
+	"github.com/tb0hdan/wass-mcp/pkg/findings"
+	"github.com/tb0hdan/wass-mcp/pkg/intel"
+	"github.com/tb0hdan/wass-mcp/pkg/limiter"
+	"github.com/tb0hdan/wass-mcp/pkg/metrics"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/notify"
+	"github.com/tb0hdan/wass-mcp/pkg/redact"
+	"github.com/tb0hdan/wass-mcp/pkg/scope"
 	"github.com/tb0hdan/wass-mcp/pkg/server"
+	"github.com/tb0hdan/wass-mcp/pkg/session"
+	"github.com/tb0hdan/wass-mcp/pkg/storage"
+	"github.com/tb0hdan/wass-mcp/pkg/target"
+	"github.com/tb0hdan/wass-mcp/pkg/tenant"
 	"github.com/tb0hdan/wass-mcp/pkg/tools"
+	"github.com/tb0hdan/wass-mcp/pkg/vault"
+	"github.com/tb0hdan/wass-mcp/pkg/wordlist"
 )
 
 const (
+	// crawlName is the timeline entry of the crawls of scans crawling first.
+	crawlName       = "crawl"
 	reportLineWidth = 78
 	toolName        = "full_scan"
 )
 
+// ErrNotRegistered is returned when running a full scan through a tool that was not registered,
+// because no scanner binary is available.
+var ErrNotRegistered = errors.New("full_scan is not available: no scanner binaries available")
+
+type scannersKey struct{}
+
+// runFirstKey holds the scanners a full scan runs before the others.
+type runFirstKey struct{}
+
+// autoKey holds whether a full scan selects technology scanners from the detected technologies.
+type autoKey struct{}
+
+// hintsKey holds the technologies the caller of a full scan hinted the target runs.
+type hintsKey struct{}
+
+// crawlFirstKey holds whether a full scan crawls its targets before scanning.
+type crawlFirstKey struct{}
+
+// adaptiveRateKey holds whether a full scan probes its targets for throttling before scanning.
+type adaptiveRateKey struct{}
+
+// passiveKey holds whether a full scan runs passive scanners only.
+type passiveKey struct{}
+
+// credentialKey holds the credential the scans of a full scan authenticate with.
+type credentialKey struct{}
+
+// wordlistKey holds the path of the wordlist the scans of a full scan brute-force paths with.
+type wordlistKey struct{}
+
 // scannerResult holds the result from a single scanner with timing.
 type scannerResult struct {
 	Duration time.Duration
 	Error    error
-	Name     string
-	Output   string
+	Findings []models.Finding
+	// Hints are the technologies detected by a fingerprinting scanner run first, passed to the
+	// scanners run after it.
+	Hints []string
+	// Ignored lists the requested options the scanner does not support.
+	Ignored []string
+	// Matched are the detected technologies an auto-selected scanner was added for.
+	Matched []string
+	Name    string
+	Output  string
+}
+
+// held reports whether the run was held because the job was paused.
+func (r scannerResult) held() bool {
+	return errors.Is(r.Error, tools.ErrScanHeld)
+}
+
+// timedOut reports whether the run failed because it ran past its deadline.
+func (r scannerResult) timedOut() bool {
+	return errors.Is(r.Error, tools.ErrScanTimedOut)
+}
+
+// reportMeta holds report header information.
+type reportMeta struct {
+	// Branding is the engagement metadata printed in the report header.
+	Branding models.ReportBranding
+	// Crawl describes the crawl of the target passed to the scanners taking a URL list, see
+	// crawlTarget.
+	Crawl string
+	// RequestedURL is the target as requested, set only when it differs from TargetURL.
+	RequestedURL string
+	TargetURL    string
+	// Throttling describes the rate limit adjustment made for a target found to throttle, see
+	// tools.ApplyThrottling.
+	Throttling string
+}
+
+// vhostResults groups scanner results collected for a single virtual host.
+type vhostResults struct {
+	Results []scannerResult
+	Vhost   string
+}
+
+// portResults groups the vhost results collected for a single port.
+type portResults struct {
+	Groups []vhostResults
+	// Host is the scanned host as requested, the group entry for target group scans.
+	Host string
+	Meta reportMeta
+	Port int
+}
+
+// hostResults groups the port results collected for a single host.
+type hostResults struct {
+	Discovered discovery.Result
+	// Error is set when the ports of the host could not be resolved.
+	Error error
+	// ExecutionID is the execution a target of a multi-target scan was logged as, see scanTargets.
+	ExecutionID uint
+	Host        string
+	Ports       []portResults
+}
+
+// Input is the full_scan tool input: the common scanner input plus a list of ports to scan.
+type Input struct {
+	tools.ScannerInput
+
+	// AdaptiveRate probes each target for rate limiting and web application firewalls before
+	// scanning, and lowers the rate limit of the scanners of targets found to throttle.
+	AdaptiveRate bool `json:"adaptive_rate,omitempty"`
+	// CrawlFirst crawls each target before scanning and passes the URLs found to the scanners
+	// taking a URL list, see crawl.Crawler.
+	CrawlFirst bool `json:"crawl_first,omitempty"`
+	// CrawlFanOut crawls the target and scans each URL found as a target of its own, in parallel,
+	// see crawlBranches. A failed branch does not fail the others.
+	CrawlFanOut bool `json:"crawl_fan_out,omitempty"`
+	// Concurrency bounds the targets of Hosts or the branches of CrawlFanOut scanned at once,
+	// defaultConcurrency when 0.
+	Concurrency int `json:"concurrency,omitempty" validate:"min=0,max=16"`
+	// Auto runs the fingerprinting scanners first, unless RunFirst is set, and adds the technology
+	// scanners of the technologies they detect, see tools.TechnologyScanner.
+	Auto bool `json:"auto,omitempty"`
+	// DiscoverPorts runs a port scanner first and scans every HTTP(S) service found,
+	// limited to Ports when given.
+	DiscoverPorts bool `json:"discover_ports,omitempty"`
+	// Group scans every host of the named target group instead of host.
+	Group string `json:"group,omitempty" validate:"omitempty,max=64"`
+	// Hints are the technologies the caller knows the target runs, keyed by kind, e.g.
+	// {"cms": "wordpress"}. They are passed to the scanners like detected technologies, and the
+	// technology scanners they match are added to the scan, see tools.TechnologyScanner.
+	Hints map[string]string `json:"hints,omitempty" validate:"omitempty,max=8,dive,keys,required,max=32,endkeys,required,max=64"`
+	// Hosts scans every target of the host expressions instead of host: hosts, CIDR networks and
+	// host ranges, see target.Expand. Each target is scanned as a full scan of its own.
+	Hosts []string `json:"hosts,omitempty" validate:"omitempty,max=32,dive,required,max=255"`
+	// Notify posts the outcome of the scan to a webhook once it completes.
+	Notify *models.Notification `json:"notify,omitempty"`
+	Ports  []int                `json:"ports,omitempty" validate:"omitempty,max=32,dive,min=1,max=65535"`
+	// RunFirst names the scanners that run before the others, e.g. fingerprinting scanners. The
+	// technologies they detect are passed to the others as hints, see tools.Fingerprinter.
+	RunFirst []string `json:"run_first,omitempty" validate:"omitempty,max=16,dive,required"`
+	// Report overrides the engagement metadata printed in the report header, see
+	// models.ReportBranding.
+	Report *models.ReportBranding `json:"report,omitempty"`
+	// ResumeExecutionID resumes a paused full_scan execution: its stored input is scanned again,
+	// running only the held scanners. Pagination, compression, priority and summary_only come from
+	// this call.
+	ResumeExecutionID uint `json:"resume_execution_id,omitempty"`
+	// Scanners limits the scan to the named scanners, all enabled scanners when empty.
+	Scanners []string `json:"scanners,omitempty" validate:"omitempty,max=16,dive,required"`
+	// SummaryOnly returns the outcome of each scanner run and the findings per severity instead of
+	// the report, which is stored with the execution for later retrieval.
+	SummaryOnly bool `json:"summary_only,omitempty"`
+	// Template is the name of the scan template the scan was launched from, if any.
+	Template string `json:"template,omitempty" validate:"omitempty,max=64"`
+	// TotalTimeout bounds the whole scan in seconds, the server default when 0. Scanner runs
+	// unfinished when it expires are reported as timed out.
+	TotalTimeout int `json:"total_timeout,omitempty" validate:"min=0,max=604800"`
+}
+
+// ScanTarget returns the scan target of the input, on the first requested port when a port list is given.
+func (i Input) ScanTarget() tools.ScanParams {
+	input := i.ScannerInput
+	if len(i.Ports) > 0 {
+		input.Port = i.Ports[0]
+	}
+
+	return tools.ResolveParams(input)
+}
+
+// TargetGroup returns the target group the input scans, if any.
+func (i Input) TargetGroup() string {
+	return i.Group
+}
+
+// TargetList returns the host expressions the input scans, if any.
+func (i Input) TargetList() []string {
+	return i.Hosts
 }
 
 // Tool implements the full scan tool.
 type Tool struct {
-	logger    zerolog.Logger
-	scanners  []tools.Scanner
+	// captureDir is the directory capture artifacts are written to, set on registration.
+	captureDir string
+	// compressThreshold is the report size above which the report is compressed, set on registration.
+	compressThreshold int
+	crawler           *crawl.Crawler
+	discoverer        *discovery.Discoverer
+	// enabled reports whether a scanner was disabled at runtime, set on registration.
+	enabled func(name string) bool
+	// intel enriches parsed findings with EPSS and KEV data, set on registration.
+	intel   *intel.Intel
+	limiter *limiter.Limiter
+	logger  zerolog.Logger
+	// maxResponseBytes bounds the report returned per call, set on registration.
+	maxResponseBytes int
+	metrics          *metrics.Metrics
+	notifier         *notify.Notifier
+	// fullScanTimeout bounds scans of inputs without a total timeout, set on registration.
+	fullScanTimeout time.Duration
+	// redactor scrubs capture artifacts, set on registration.
+	redactor *redact.Redactor
+	// branding is the default engagement metadata of reports, set on registration.
+	branding models.ReportBranding
+	// registry holds the scanners the scan runs, the available ones at the time of the scan.
+	registry *tools.Registry
+	// run is the registered, logged handler, set on registration.
+	run func(context.Context, *mcp.CallToolRequest, Input) (*mcp.CallToolResult, any, error)
+	// scanTimeout bounds scanner runs of inputs without a timeout, set on registration.
+	scanTimeout time.Duration
+	// scope is the allowlist of scan targets, set on registration.
+	scope *scope.Policy
+	// sessions holds the session defaults applied to inputs naming no host, set on registration.
+	sessions *session.Store
+	// storage loads paused executions to resume and stores output cursors, set on registration.
+	storage   storage.Storage
 	validator *validator.Validate
+	// vault decrypts the credential named by the input, set on registration.
+	vault *vault.Vault
+	// wordlists resolves the wordlist named by the input, set on registration.
+	wordlists *wordlist.Registry
 }
 
 // Register registers the full_scan tool with the MCP server.
 func (t *Tool) Register(srv *server.Server) error {
-	// Filter to only available scanners.
-	var availableScanners []tools.Scanner
-	for _, scanner := range t.scanners {
-		if scanner.IsAvailable() {
+	// Check the scanners again, warm-up may have found broken installs since they were registered.
+	available := t.registry.Refresh()
+	for _, scanner := range t.registry.Scanners() {
+		if t.registry.IsAvailable(scanner.Name()) {
 			t.logger.Debug().Msgf("scanner %s is available", scanner.Name())
-			availableScanners = append(availableScanners, scanner)
 		} else {
 			t.logger.Warn().Msgf("scanner %s not available, will be skipped", scanner.Name())
 		}
 	}
 
-	if len(availableScanners) == 0 {
+	if len(available) == 0 {
 		return fmt.Errorf("no scanner binaries available")
 	}
 
-	t.scanners = availableScanners
+	t.captureDir = tools.CaptureDir(srv.Artifacts().Dir)
+	t.enabled = srv.ScannerEnabled
+	t.fullScanTimeout = srv.FullScanTimeout()
+	t.intel = srv.Intel()
+	t.limiter = srv.ScanLimiter()
+	t.maxResponseBytes = srv.MaxResponseBytes()
+	t.compressThreshold = srv.CompressThreshold()
+	t.metrics = srv.Metrics()
+	t.redactor = srv.Redactor()
+	t.scanTimeout = srv.ScanTimeout()
+	t.branding = srv.ReportBranding()
+	t.scope = srv.Scope()
+	t.sessions = srv.Sessions()
+	t.storage = srv.Storage()
+	t.vault = srv.Vault()
+	t.wordlists = srv.Wordlists()
 
 	tool := &mcp.Tool{
 		Name:        toolName,
@@ -62,68 +299,897 @@ func (t *Tool) Register(srv *server.Server) error {
 		srv.Storage(),
 		toolName,
 		t.FullScanHandler,
+		tools.ServerWrapOptions(srv)...,
 	)
+	t.run = wrappedHandler
 
-	mcp.AddTool(&srv.Server, tool, wrappedHandler)
-	t.logger.Debug().Msgf("%s tool registered with %d scanners", toolName, len(t.scanners))
+	mcp.AddTool(&srv.Server, tool, tenant.RequireOperator(tools.ScanAction, wrappedHandler))
+	t.logger.Debug().Msgf("%s tool registered with %d scanners", toolName, len(available))
 
 	return nil
 }
 
-// FullScanHandler handles MCP tool requests.
-func (t *Tool) FullScanHandler(ctx context.Context, _ *mcp.CallToolRequest, input tools.ScannerInput) (*mcp.CallToolResult, any, error) {
-	// Parse URL-style hosts before validation.
-	parsed := tools.ParseHostInput(input.Host)
-	input.Host = parsed.Host
+// Run runs a full scan through the registered handler, logged like a full_scan call, for tools
+// launching full scans such as scan templates. Callers authorize the scan themselves.
+func (t *Tool) Run(ctx context.Context, req *mcp.CallToolRequest, input Input) (*mcp.CallToolResult, any, error) {
+	if t.run == nil {
+		return nil, nil, ErrNotRegistered
+	}
 
-	if input.Port == 0 && parsed.Port != 0 {
-		input.Port = parsed.Port
+	return t.run(ctx, req, input)
+}
+
+// FullScanHandler handles MCP tool requests.
+func (t *Tool) FullScanHandler(ctx context.Context, req *mcp.CallToolRequest, input Input) (*mcp.CallToolResult, any, error) {
+	// The full scan of a target of a multi-target scan reports its results to it.
+	sink := targetResults(ctx)
+	if sink != nil {
+		sink.ExecutionID = tools.ExecutionID(ctx)
+	}
+	// A resumed scan keeps the target of the paused one.
+	if input.ResumeExecutionID == 0 && input.Group == "" && len(input.Hosts) == 0 {
+		if withDefaults, ok := tools.ApplySessionDefaults(ctx, t.sessions, input.ScannerInput); ok {
+			input.ScannerInput = withDefaults
+			tools.RecordInput(ctx, input)
+		}
 	}
+	// Parse URL-style hosts before validation.
+	input.ScannerInput = tools.PrepareScannerInput(input.ScannerInput)
 
 	if err := t.validator.Struct(input); err != nil {
 		return nil, nil, fmt.Errorf("validation error: %w", err)
 	}
+	if err := tools.ValidateOptions(input.Options); err != nil {
+		return nil, nil, fmt.Errorf("validation error: %w", err)
+	}
+	if input.Group != "" && input.Host != "" {
+		return nil, nil, fmt.Errorf("validation error: group and host are mutually exclusive")
+	}
+	if len(input.Hosts) > 0 && (input.Host != "" || input.Group != "" || input.ResumeExecutionID != 0) {
+		return nil, nil, fmt.Errorf("validation error: hosts is exclusive with host, group and resume_execution_id")
+	}
+	if input.CrawlFanOut && (len(input.Hosts) > 0 || input.Group != "" || input.ResumeExecutionID != 0 ||
+		input.CrawlFirst || input.DiscoverPorts || len(input.Ports) > 0) {
+		return nil, nil, fmt.Errorf("validation error: crawl_fan_out is exclusive with hosts, group, resume_execution_id, " +
+			"crawl_first, discover_ports and ports")
+	}
+	if input.Concurrency != 0 && len(input.Hosts) == 0 && !input.CrawlFanOut {
+		return nil, nil, fmt.Errorf("validation error: concurrency is only allowed with hosts and crawl_fan_out")
+	}
+	if input.Passive && input.DiscoverPorts {
+		return nil, nil, fmt.Errorf("validation error: discover_ports is not allowed in passive mode")
+	}
+	if input.Passive && input.CrawlFirst {
+		return nil, nil, fmt.Errorf("validation error: crawl_first is not allowed in passive mode")
+	}
+	if input.Passive && input.CrawlFanOut {
+		return nil, nil, fmt.Errorf("validation error: crawl_fan_out is not allowed in passive mode")
+	}
+	if err := t.validateScanners(input.Scanners, input.Passive); err != nil {
+		return nil, nil, fmt.Errorf("validation error: %w", err)
+	}
+	if err := t.validateRunFirst(input); err != nil {
+		return nil, nil, fmt.Errorf("validation error: %w", err)
+	}
+	start, err := tools.StartCursor(input.Cursor, input.Offset)
+	if err != nil {
+		return nil, nil, fmt.Errorf("validation error: %w", err)
+	}
+	var targets []string
+	if (len(input.Hosts) > 0 || input.CrawlFanOut) && t.run == nil {
+		return nil, nil, ErrNotRegistered
+	}
+	if len(input.Hosts) > 0 {
+		if targets, err = target.Expand(input.Hosts, maxTargets); err != nil {
+			return nil, nil, fmt.Errorf("validation error: %w", err)
+		}
+	}
+
+	var (
+		previous resumeState
+		paused   *models.ToolExecution
+	)
+	if input.ResumeExecutionID != 0 {
+		input, previous, paused, err = t.loadPaused(ctx, input)
+		if err != nil {
+			return nil, nil, err
+		}
+		tools.RecordInput(ctx, input)
+	}
+	ctx = tools.PrioritizeScan(ctx, input.Priority)
+	ctx = context.WithValue(ctx, scannersKey{}, input.Scanners)
+	ctx = context.WithValue(ctx, passiveKey{}, input.Passive)
+	ctx = context.WithValue(ctx, runFirstKey{}, input.RunFirst)
+	ctx = context.WithValue(ctx, autoKey{}, input.Auto)
+	ctx = context.WithValue(ctx, hintsKey{}, hintedTechnologies(input.Hints))
+	ctx = context.WithValue(ctx, adaptiveRateKey{}, input.AdaptiveRate)
+	ctx = context.WithValue(ctx, crawlFirstKey{}, input.CrawlFirst)
+	ctx = context.WithValue(ctx, progressKey{}, newProgress(req, t.logger))
+	if input.Credential != "" {
+		credential, err := t.vault.Resolve(ctx, t.storage, input.Credential)
+		if err != nil {
+			return nil, nil, err
+		}
+		ctx = context.WithValue(ctx, credentialKey{}, credential)
+	}
+	if input.Wordlist != "" {
+		path, err := t.wordlists.Resolve(ctx, input.Wordlist)
+		if err != nil {
+			return nil, nil, err
+		}
+		ctx = context.WithValue(ctx, wordlistKey{}, path)
+	}
+
+	enabled := t.enabledScanners(ctx)
+	if len(enabled) == 0 && input.Passive {
+		return nil, nil, fmt.Errorf("no passive scanner is enabled")
+	}
+	if len(enabled) == 0 {
+		return nil, nil, fmt.Errorf("all scanners are disabled")
+	}
+
+	var (
+		branding    = t.branding.Merge(input.Report)
+		results     []portResults
+		targetLines []string
+		// writeReport writes the report of the scan, of about reportSize bytes.
+		writeReport func(w *reportWriter)
+		reportSize  int
+	)
+	// Only scanning is bounded by the total timeout, the report is built from what finished.
+	scanCtx := ctx
+	total := t.totalTimeout(input)
+	if total > 0 {
+		var cancel context.CancelFunc
+		scanCtx, cancel = context.WithTimeout(ctx, total)
+		defer cancel()
+	}
+	if input.Group != "" {
+		hosts, err := t.groupHosts(ctx, input.Group, paused)
+		if err != nil {
+			return nil, nil, err
+		}
+		logger := tools.ContextLogger(ctx, t.logger)
+		logger.Info().Msgf("Starting full scan of target group %s (%d hosts) with %d scanners", input.Group, len(hosts), len(enabled))
+
+		scanned := t.scanGroup(scanCtx, input, hosts, previous)
+		for _, host := range scanned {
+			results = append(results, host.Ports...)
+		}
+		writeReport = func(w *reportWriter) { t.writeGroupReport(w, branding, groupLines(input.Group, scanned), scanned) }
+		reportSize = hostsSize(scanned)
+		targetLines = []string{fmt.Sprintf("Target group: %s (%d hosts)", input.Group, len(hosts))}
+	} else if len(targets) > 0 {
+		logger := tools.ContextLogger(ctx, t.logger)
+		logger.Info().Msgf("Starting full scan of %d targets with %d scanners", len(targets), len(enabled))
+
+		scanned := t.scanTargets(scanCtx, req, input, targets)
+		for _, host := range scanned {
+			results = append(results, host.Ports...)
+		}
+		targetLines = []string{fmt.Sprintf("Targets: %s (%d hosts)", strings.Join(input.Hosts, ", "), len(targets))}
+		writeReport = func(w *reportWriter) { t.writeGroupReport(w, branding, targetLines, scanned) }
+		reportSize = hostsSize(scanned)
+	} else if input.CrawlFanOut {
+		var note string
+		if targets, note, err = t.crawlBranches(scanCtx, input); err != nil {
+			return nil, nil, err
+		}
+		targetURL := input.ScanTarget().Target().URL()
+		logger := tools.ContextLogger(ctx, t.logger)
+		logger.Info().Msgf("Starting crawl fan-out scan of %s (%d branches) with %d scanners", targetURL, len(targets), len(enabled))
+
+		scanned := t.scanTargets(scanCtx, req, input, targets)
+		for _, host := range scanned {
+			results = append(results, host.Ports...)
+		}
+		targetLines = fanOutLines(targetURL, note, len(targets))
+		writeReport = func(w *reportWriter) { t.writeGroupReport(w, branding, targetLines, scanned) }
+		reportSize = hostsSize(scanned)
+	} else {
+		scanned := t.scanHost(scanCtx, input, input.Host, previous)
+		if sink != nil {
+			scanned.ExecutionID = sink.ExecutionID
+			*sink = scanned
+		}
+		if scanned.Error != nil {
+			return nil, nil, scanned.Error
+		}
+		results = scanned.Ports
+		reportSize = portsSize(results)
+
+		switch {
+		case input.DiscoverPorts:
+			writeReport = func(w *reportWriter) {
+				t.writePortReport(w, branding, input.Host, results, discoveryLine(scanned.Discovered))
+			}
+			targetLines = []string{fmt.Sprintf("Target: %s", input.Host), discoveryLine(scanned.Discovered)}
+		case len(input.Ports) > 0:
+			writeReport = func(w *reportWriter) { t.writePortReport(w, branding, input.Host, results) }
+			targetLines = []string{fmt.Sprintf("Target: %s", input.Host), fmt.Sprintf("Ports: %s", joinPorts(portNumbers(results)))}
+		default:
+			meta := results[0].Meta
+			meta.Branding = branding
+			writeReport = func(w *reportWriter) { t.writeVhostReport(w, meta, results[0].Groups) }
+			targetLines = []string{fmt.Sprintf("Target: %s", meta.TargetURL)}
+		}
+	}
 
-	params := tools.ResolveParams(input)
-	targetURL := tools.BuildTargetURL(params)
-	t.logger.Info().Msgf("Starting full scan on %s with %d scanners", targetURL, len(t.scanners))
+	var groups []vhostResults
+	for _, result := range results {
+		groups = append(groups, result.Groups...)
+	}
 
-	// Run all scanners in parallel.
-	results := t.runScannersParallel(ctx, params)
+	found := collectFindings(groups)
+	// The report is streamed section by section to the stored raw output and to the page of the
+	// response, so that it is never built whole for the response. Neither write fails.
+	output := tools.RawOutputWriter(ctx, reportSize)
+	var pager *tools.PageWriter
+	if sink == nil && !input.SummaryOnly {
+		pager = tools.NewPageWriter(input.MaxLines, start, t.maxResponseBytes)
+		output = io.MultiWriter(output, pager)
+	}
+	writeReport(newReportWriter(output))
+	state := scanState(results)
+	// The targets of a multi-target scan are executions of their own, storing their findings and
+	// held runs themselves.
+	if len(targets) == 0 {
+		tools.RecordFindings(ctx, found)
+		tools.RecordScanState(ctx, state)
+	}
+	if paused != nil {
+		t.markResumed(ctx, paused)
+	}
+	notices := passiveNotice(t.skippedScanners(ctx))
+	if errors.Is(scanCtx.Err(), context.DeadlineExceeded) {
+		notices += fmt.Sprintf("[Scan stopped by its total timeout of %s; unfinished scanner runs timed out.]\n", total)
+	}
+	held := countHeld(state)
+	switch {
+	case held > 0 && len(targets) > 0:
+		notices += fmt.Sprintf("[Scan paused with %d scanner runs held. Resume each paused target with the execution of its section.]\n", held)
+	case held > 0:
+		notices += fmt.Sprintf("[Scan paused with %d scanner runs held. Resume with resume_execution_id %d.]\n",
+			held, tools.ExecutionID(ctx))
+	}
+	if input.Notify != nil {
+		t.notify(ctx, input, found, held > 0)
+	}
+	// The report of a target of a multi-target scan is part of the report of the multi-target scan.
+	if sink != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: notices + summaryReport(targetLines, results, found, tools.ExecutionID(ctx), "")},
+			},
+		}, nil, nil
+	}
 
-	// Merge results into report.
-	mergedOutput := t.mergeResults(targetURL, results)
+	// The summary replaces the report, which stays readable from the requested start through an
+	// output cursor of the execution.
+	if input.SummaryOnly {
+		token, err := tools.IssueContinuation(ctx, t.storage, tools.ExecutionID(ctx), tools.ResponsePage{Next: &start}, input.MaxLines)
+		if err != nil {
+			logger := tools.ContextLogger(ctx, t.logger)
+			logger.Warn().Err(err).Msg("Returning the summary without a report cursor")
+		}
+		summary := &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: notices + summaryReport(targetLines, results, found, tools.ExecutionID(ctx), token)},
+			},
+		}
+		if token != "" {
+			summary.Meta = mcp.Meta{tools.OutputCursorField: token}
+		}
 
-	// Apply pagination using the shared function.
-	resultText := t.applyPagination(mergedOutput, input.MaxLines, input.Offset)
+		return summary, nil, nil
+	}
 
-	return &mcp.CallToolResult{
+	// The page is returned compressed when the client asks for it, or when it exceeds the configured
+	// threshold.
+	page := pager.Page()
+	resultText := t.pageText(page, start)
+	var resource *mcp.EmbeddedResource
+	if tools.UseCompression(input.Compression, len(page.Text), t.compressThreshold) {
+		resultText, resource, err = tools.CompressPage(ctx, toolName, page, start, t.maxResponseBytes)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	resultText = notices + resultText
+	token, err := tools.IssueContinuation(ctx, t.storage, tools.ExecutionID(ctx), page, input.MaxLines)
+	if err != nil {
+		logger := tools.ContextLogger(ctx, t.logger)
+		logger.Warn().Err(err).Msg("Returning truncated report without an output cursor")
+	}
+	if token != "" {
+		resultText += tools.ContinuationNotice(token)
+	}
+
+	result := &mcp.CallToolResult{
 		Content: []mcp.Content{
 			&mcp.TextContent{Text: resultText},
 		},
-	}, nil, nil
+	}
+	if resource != nil {
+		result.Content = append(result.Content, resource)
+	}
+	if page.Next != nil {
+		result.Meta = mcp.Meta{tools.NextCursorField: page.Next.String()}
+	}
+	if token != "" {
+		if result.Meta == nil {
+			result.Meta = mcp.Meta{}
+		}
+		result.Meta[tools.OutputCursorField] = token
+	}
+
+	return result, nil, nil
+}
+
+// totalTimeout returns the bound of the whole scan requested by input, the server default when
+// the input sets none, 0 for no bound.
+func (t *Tool) totalTimeout(input Input) time.Duration {
+	if input.TotalTimeout > 0 {
+		return time.Duration(input.TotalTimeout) * time.Second
+	}
+
+	return t.fullScanTimeout
+}
+
+// enabledScanners returns the scanners that were not disabled at runtime, limited to the
+// scanners selected by the scanners input of the scan ctx belongs to, and to passive scanners in
+// passive mode. Technology scanners run only when selected by name or by auto mode.
+func (t *Tool) enabledScanners(ctx context.Context) []tools.Scanner {
+	selected, _ := ctx.Value(scannersKey{}).([]string)
+	passive, _ := ctx.Value(passiveKey{}).(bool)
+
+	available := t.registry.Available()
+	enabled := make([]tools.Scanner, 0, len(available))
+	for _, scanner := range available {
+		if t.enabled != nil && !t.enabled(scanner.Name()) {
+			continue
+		}
+		if len(selected) > 0 && !slices.Contains(selected, scanner.Name()) {
+			continue
+		}
+		if len(selected) == 0 && len(tools.Technologies(scanner)) > 0 {
+			continue
+		}
+		if passive && !tools.IsPassive(scanner) {
+			continue
+		}
+		enabled = append(enabled, scanner)
+	}
+
+	return enabled
+}
+
+// skippedScanners returns the names of the active scanners a passive scan ctx belongs to skipped,
+// among the scanners it would otherwise run. It is empty outside passive mode.
+func (t *Tool) skippedScanners(ctx context.Context) []string {
+	if passive, _ := ctx.Value(passiveKey{}).(bool); !passive {
+		return nil
+	}
+	selected, _ := ctx.Value(scannersKey{}).([]string)
+
+	var skipped []string
+	for _, scanner := range t.registry.Available() {
+		if tools.IsPassive(scanner) || (t.enabled != nil && !t.enabled(scanner.Name())) {
+			continue
+		}
+		if len(selected) > 0 && !slices.Contains(selected, scanner.Name()) {
+			continue
+		}
+		if len(selected) == 0 && len(tools.Technologies(scanner)) > 0 {
+			continue
+		}
+		skipped = append(skipped, scanner.Name())
+	}
+
+	return skipped
+}
+
+// passiveNotice describes the active scanners skipped by a passive scan, if any.
+func passiveNotice(skipped []string) string {
+	if len(skipped) == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("[Passive mode: active scanners skipped: %s]\n", strings.Join(skipped, ", "))
+}
+
+// validateScanners checks that every selected scanner is one of the available scanners, and a
+// passive scanner in passive mode.
+func (t *Tool) validateScanners(selected []string, passive bool) error {
+	for _, name := range selected {
+		scanner, err := t.registry.Lookup(name)
+		if err != nil || !t.registry.IsAvailable(name) {
+			return fmt.Errorf("unknown scanner %q (available: %s)", name, strings.Join(t.registry.AvailableNames(), ", "))
+		}
+		if passive && !tools.IsPassive(scanner) {
+			return fmt.Errorf("%w: %s", tools.ErrActiveScanner, name)
+		}
+	}
+
+	return nil
+}
+
+// validateRunFirst checks that the scanners input runs first are valid selected scanners.
+func (t *Tool) validateRunFirst(input Input) error {
+	if err := t.validateScanners(input.RunFirst, input.Passive); err != nil {
+		return fmt.Errorf("run_first: %w", err)
+	}
+	for _, name := range input.RunFirst {
+		if len(input.Scanners) > 0 && !slices.Contains(input.Scanners, name) {
+			return fmt.Errorf("run_first: scanner %q is not among the selected scanners", name)
+		}
+	}
+
+	return nil
+}
+
+// notify posts the completion event of the scan to the webhook of input in the background, when
+// its findings reach the minimum severity of the notification. Notifications of new findings only
+// report the findings earlier scans of their target did not.
+func (t *Tool) notify(ctx context.Context, input Input, found []models.Finding, paused bool) {
+	logger := tools.ContextLogger(ctx, t.logger)
+	settings := *input.Notify
+	target := input.ScanTarget().Target().URL()
+	switch {
+	case input.Group != "":
+		target = tools.GroupTarget(input.Group)
+	case len(input.Hosts) > 0:
+		target = tools.ListTarget(input.Hosts)
+	}
+
+	event := notify.NewEvent(toolName, target, found)
+	if settings.NewFindingsOnly {
+		seen, err := t.storage.SeenFingerprints(ctx, findings.Fingerprints(found), tools.ExecutionID(ctx))
+		if err != nil {
+			logger.Warn().Err(err).Msg("Failed to look up earlier findings, notification skipped")
+			return
+		}
+		event = notify.NewFindingsEvent(toolName, target, findings.Unseen(found, seen))
+	}
+	event.CorrelationID = tools.CorrelationID(ctx)
+	event.ExecutionID = tools.ExecutionID(ctx)
+	event.Template = input.Template
+	if paused {
+		event.Status = models.StatusPaused
+	}
+
+	if !notify.ShouldNotify(settings, event) {
+		if event.Type == notify.EventNewFindings && event.TotalFindings == 0 {
+			logger.Debug().Msg("No new finding, notification skipped")
+		} else {
+			logger.Debug().Msgf("No finding of at least %s severity, notification skipped", settings.MinSeverity)
+		}
+		return
+	}
+
+	go func() { //nolint:contextcheck
+		if err := t.notifier.Send(context.WithoutCancel(ctx), settings, event); err != nil {
+			logger.Warn().Err(err).Msg("Failed to send scan notification")
+			return
+		}
+		logger.Info().Msg("Scan notification sent")
+	}()
+}
+
+// scanHost runs the scanner matrix against every port of host, a hostname, IP or URL scanned with
+// the rest of input. Runs of host found in previous, the state of a resumed scan, are not repeated.
+func (t *Tool) scanHost(ctx context.Context, input Input, host string, previous resumeState) hostResults {
+	input.Host = host
+	input.ScannerInput = tools.PrepareScannerInput(input.ScannerInput)
+	scanned := hostResults{Host: host}
+
+	services, discovered, err := t.resolveTargets(ctx, input)
+	scanned.Discovered = discovered
+	if err != nil {
+		scanned.Error = err
+		return scanned
+	}
+	targets, err := t.portTargets(ctx, input.ScannerInput, services)
+	if err != nil {
+		scanned.Error = err
+		return scanned
+	}
+	logger := tools.ContextLogger(ctx, t.logger)
+	logger.Info().Msgf("Starting full scan on %s (%d ports) with %d scanners", input.Host, len(targets), len(t.enabledScanners(ctx)))
+
+	// Scan all ports in parallel, bounded by the shared scan limiter.
+	hostPrevious := previous.forHost(host)
+	scanned.Ports = make([]portResults, len(targets))
+	var waitGroup sync.WaitGroup
+	for i, target := range targets {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			scanned.Ports[i] = t.scanPort(ctx, input.ScannerInput, target, hostPrevious)
+			scanned.Ports[i].Host = host
+		}()
+	}
+	waitGroup.Wait()
+
+	return scanned
+}
+
+// resolveTargets returns the ports to scan: the discovered HTTP(S) services when port discovery
+// is requested, otherwise the requested ports or the single input port. Requested ports must be in
+// scope, and discovered services out of scope are left out.
+func (t *Tool) resolveTargets(ctx context.Context, input Input) ([]discovery.Service, discovery.Result, error) {
+	ports := uniquePorts(input.Ports)
+
+	if !input.DiscoverPorts {
+		if len(ports) == 0 {
+			ports = []int{input.Port}
+		}
+		targets := make([]discovery.Service, 0, len(ports))
+		for _, port := range ports {
+			portInput := input.ScannerInput
+			portInput.Port = port
+			params := tools.ResolveParams(portInput)
+			if err := t.scope.Check(params.Host, params.Port); err != nil {
+				return nil, discovery.Result{}, err
+			}
+			targets = append(targets, discovery.Service{Port: port})
+		}
+		return targets, discovery.Result{}, nil
+	}
+
+	if err := t.scope.CheckHost(input.Host); err != nil {
+		return nil, discovery.Result{}, err
+	}
+	discovered, err := t.discoverer.Discover(ctx, input.Host, ports)
+	if err != nil {
+		return nil, discovered, fmt.Errorf("port discovery failed: %w", err)
+	}
+	if len(discovered.Services) == 0 {
+		return nil, discovered, fmt.Errorf("no HTTP(S) services discovered on %s (open ports: %s)",
+			input.Host, joinPorts(discovered.OpenPorts))
+	}
+	logger := tools.ContextLogger(ctx, t.logger)
+	services := slices.DeleteFunc(slices.Clone(discovered.Services), func(service discovery.Service) bool {
+		if err := t.scope.Check(input.Host, service.Port); err != nil {
+			logger.Info().Msgf("Skipping discovered service on port %d: %v", service.Port, err)
+			return true
+		}
+		return false
+	})
+	if len(services) == 0 {
+		return nil, discovered, fmt.Errorf("no HTTP(S) service discovered on %s is in scope (ports: %s)",
+			input.Host, joinPorts(servicePorts(discovered.Services)))
+	}
+	logger.Info().Msgf("%s discovered %d HTTP(S) services on %s", discovered.Scanner, len(services), input.Host)
+
+	return services, discovered, nil
+}
+
+// servicePorts returns the port of each service.
+func servicePorts(services []discovery.Service) []int {
+	ports := make([]int, 0, len(services))
+	for _, service := range services {
+		ports = append(ports, service.Port)
+	}
+
+	return ports
+}
+
+// discoveryLine describes a port discovery run for the report header.
+func discoveryLine(discovered discovery.Result) string {
+	return fmt.Sprintf("Discovered by: %s (open ports: %s)", discovered.Scanner, joinPorts(discovered.OpenPorts))
+}
+
+// joinPorts formats ports as a comma-separated list, or "none".
+func joinPorts(ports []int) string {
+	if len(ports) == 0 {
+		return "none"
+	}
+	parts := make([]string, 0, len(ports))
+	for _, port := range ports {
+		parts = append(parts, strconv.Itoa(port))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// portTarget is a port to scan: the parameters of its effective target and the target as
+// requested.
+type portTarget struct {
+	Params       tools.ScanParams
+	RequestedURL string
+}
+
+// portTargets returns the port targets of services scanned with input, in parallel. A non-empty
+// service scheme overrides the scheme inferred from the input, e.g. for discovered services. With
+// follow_redirects, each target points at the effective target of its redirects, and a redirect
+// out of scope fails the host as it fails the scanner tools, see tools.FollowRedirects.
+func (t *Tool) portTargets(ctx context.Context, input tools.ScannerInput, services []discovery.Service) ([]portTarget, error) {
+	logger := tools.ContextLogger(ctx, t.logger)
+	targets := make([]portTarget, len(services))
+	errs := make([]error, len(services))
+	var waitGroup sync.WaitGroup
+	for i, service := range services {
+		portInput := input
+		portInput.Port = service.Port
+		params := tools.ResolveParams(portInput)
+		if params.Timeout == 0 {
+			params.Timeout = t.scanTimeout
+		}
+		if service.Scheme != "" {
+			params.Scheme = service.Scheme
+		}
+		params.Credential, _ = ctx.Value(credentialKey{}).(*vault.Credential)
+		params.Wordlist, _ = ctx.Value(wordlistKey{}).(string)
+		targets[i] = portTarget{Params: params, RequestedURL: params.Target().URL()}
+		if !input.FollowRedirects {
+			continue
+		}
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			targets[i].Params, errs[i] = tools.FollowRedirects(ctx, logger, params, t.scope)
+		}()
+	}
+	waitGroup.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	return targets, nil
+}
+
+// scanPort runs the scanner matrix against a single port target, once per vhost when a vhost list
+// is given. Runs found in previous, the state of a resumed scan, are not repeated. In adaptive rate
+// scans, the scanners of a port found to throttle run at a lowered rate limit. Scans crawling first
+// pass the URLs of the port to the scanners taking a URL list.
+func (t *Tool) scanPort(ctx context.Context, input tools.ScannerInput, target portTarget, previous resumeState) portResults {
+	params := target.Params
+	logger := tools.ContextLogger(ctx, t.logger)
+	targetURL := params.Target().URL()
+	result := portResults{Meta: reportMeta{TargetURL: targetURL}, Port: params.Port}
+	if target.RequestedURL != targetURL {
+		result.Meta.RequestedURL = target.RequestedURL
+	}
+	if adaptive, _ := ctx.Value(adaptiveRateKey{}).(bool); adaptive {
+		params, result.Meta.Throttling = tools.ApplyThrottling(ctx, logger, params)
+	}
+	if crawlFirst, _ := ctx.Value(crawlFirstKey{}).(bool); crawlFirst {
+		params.URLs, result.Meta.Crawl = t.crawlTarget(ctx, params)
+	}
+
+	if len(input.Vhosts) == 0 {
+		result.Groups = []vhostResults{{Results: t.runScannersParallel(ctx, params, previous)}}
+		return result
+	}
+
+	result.Groups = make([]vhostResults, 0, len(input.Vhosts))
+	for _, vhost := range input.Vhosts {
+		params.Vhost = vhost
+		logger.Info().Msgf("Scanning %s with vhost %s", targetURL, vhost)
+		result.Groups = append(result.Groups, vhostResults{
+			Results: t.runScannersParallel(ctx, params, previous),
+			Vhost:   vhost,
+		})
+	}
+
+	return result
+}
+
+// crawlTarget crawls the target of params with the first available crawl engine and returns the
+// URLs found, with a note describing the crawl for the report. The crawl is added to the timeline
+// of the scan. Crawl failures are logged and the target is scanned without a URL list.
+func (t *Tool) crawlTarget(ctx context.Context, params tools.ScanParams) ([]string, string) {
+	logger := tools.ContextLogger(ctx, t.logger)
+	var crawled crawl.Result
+	scan := tools.TimelineScan(crawlName, func(ctx context.Context, params tools.ScanParams) tools.ScanResult {
+		var err error
+		crawled, err = t.crawler.Crawl(ctx, "", params, crawl.Limits{})
+		return tools.ScanResult{Error: err}
+	})
+	if result := scan(ctx, params); result.Error != nil {
+		logger.Warn().Err(result.Error).Msgf("Crawl failed, scanning %s without a URL list", params.Target().URL())
+		return nil, "failed: " + result.Error.Error()
+	}
+
+	note := fmt.Sprintf("%d URLs found by %s", len(crawled.URLs), crawled.Engine)
+	if crawled.Truncated {
+		note += fmt.Sprintf(" (limited to %d)", len(crawled.URLs))
+	}
+	logger.Info().Msgf("Crawled %s: %s", params.Target().URL(), note)
+
+	return crawled.URLs, note
+}
+
+// uniquePorts returns ports without duplicates, keeping the requested order.
+func uniquePorts(ports []int) []int {
+	seen := make(map[int]struct{}, len(ports))
+	unique := make([]int, 0, len(ports))
+	for _, port := range ports {
+		if _, ok := seen[port]; ok {
+			continue
+		}
+		seen[port] = struct{}{}
+		unique = append(unique, port)
+	}
+
+	return unique
+}
+
+// hintedTechnologies returns the lowercase technologies of the hints of a full scan, sorted and
+// without duplicates.
+func hintedTechnologies(hints map[string]string) []string {
+	technologies := make([]string, 0, len(hints))
+	for _, technology := range hints {
+		technologies = append(technologies, strings.ToLower(strings.TrimSpace(technology)))
+	}
+	slices.Sort(technologies)
+
+	return slices.Compact(technologies)
+}
+
+// runScannersParallel runs all scanners in parallel and collects results. The scanners the scan
+// runs first run before the others, which get the technologies they detected as hints, together
+// with the technologies hinted by the caller.
+func (t *Tool) runScannersParallel(
+	ctx context.Context,
+	params tools.ScanParams,
+	previous resumeState,
+) []scannerResult {
+	hinted, _ := ctx.Value(hintsKey{}).([]string)
+	params.Hints = append(params.Hints, hinted...)
+	first, rest := t.scannerStages(ctx)
+	if len(first) == 0 && len(hinted) == 0 {
+		return t.runStage(ctx, rest, params, previous)
+	}
+
+	results := t.runStage(ctx, first, params, previous)
+	for i, result := range results {
+		index := slices.IndexFunc(first, func(scanner tools.Scanner) bool { return scanner.Name() == result.Name })
+		fingerprinter, ok := first[index].(tools.Fingerprinter)
+		if !ok || result.held() {
+			continue
+		}
+		results[i].Hints = fingerprinter.Fingerprint(result.Output)
+		for _, hint := range results[i].Hints {
+			if !slices.Contains(params.Hints, hint) {
+				params.Hints = append(params.Hints, hint)
+			}
+		}
+	}
+	logger := tools.ContextLogger(ctx, t.logger)
+	selected, matched := t.autoScanners(ctx, params.Hints)
+	for _, scanner := range selected {
+		logger.Info().Strs("technologies", matched[scanner.Name()]).Msgf("Auto-selected %s for the detected technologies", scanner.Name())
+	}
+	rest = append(rest, selected...)
+	if len(params.Hints) > 0 {
+		logger.Info().Strs("hints", params.Hints).Msgf("Passing detected technologies to %d scanners", len(rest))
+	}
+
+	later := t.runStage(ctx, rest, params, previous)
+	for i, result := range later {
+		later[i].Matched = matched[result.Name]
+	}
+
+	return append(results, later...)
 }
 
-// runScannersParallel runs all scanners in parallel and collects results.
-func (t *Tool) runScannersParallel(ctx context.Context, params tools.ScanParams) []scannerResult {
+// scannerStages splits the enabled scanners into the scanners the scan ctx belongs to runs first
+// and the others. In auto mode without run_first, the fingerprinting scanners run first.
+func (t *Tool) scannerStages(ctx context.Context) ([]tools.Scanner, []tools.Scanner) {
+	runFirst, _ := ctx.Value(runFirstKey{}).([]string)
+	auto, _ := ctx.Value(autoKey{}).(bool)
+
+	var first, rest []tools.Scanner
+	for _, scanner := range t.enabledScanners(ctx) {
+		_, fingerprinter := scanner.(tools.Fingerprinter)
+		if slices.Contains(runFirst, scanner.Name()) || (auto && len(runFirst) == 0 && fingerprinter) {
+			first = append(first, scanner)
+		} else {
+			rest = append(rest, scanner)
+		}
+	}
+
+	return first, rest
+}
+
+// autoScanners returns the technology scanners auto mode adds for the technologies in hints,
+// with the technologies each one matched, see tools.TechnologyScanner. Outside auto mode only the
+// technologies hinted by the caller add scanners. Scanners disabled at runtime and active scanners
+// in passive mode are left out, and none is added to scans naming their scanners.
+func (t *Tool) autoScanners(ctx context.Context, hints []string) ([]tools.Scanner, map[string][]string) {
+	auto, _ := ctx.Value(autoKey{}).(bool)
+	selected, _ := ctx.Value(scannersKey{}).([]string)
+	passive, _ := ctx.Value(passiveKey{}).(bool)
+	if !auto {
+		hints, _ = ctx.Value(hintsKey{}).([]string)
+	}
+	if len(hints) == 0 || len(selected) > 0 {
+		return nil, nil
+	}
+
+	var scanners []tools.Scanner
+	matched := make(map[string][]string)
+	for _, scanner := range t.registry.Available() {
+		if t.enabled != nil && !t.enabled(scanner.Name()) {
+			continue
+		}
+		if passive && !tools.IsPassive(scanner) {
+			continue
+		}
+		for _, technology := range tools.Technologies(scanner) {
+			if slices.Contains(hints, technology) {
+				matched[scanner.Name()] = append(matched[scanner.Name()], technology)
+			}
+		}
+		if len(matched[scanner.Name()]) > 0 {
+			scanners = append(scanners, scanner)
+		}
+	}
+
+	return scanners, matched
+}
+
+// runStage runs scanners in parallel and collects results.
+// Each scanner run holds a slot of the shared scan limiter and may run for params.Timeout, 0 for
+// no bound, once it has one. Runs not started when the job is paused are held, and results found
+// in previous are reused instead of running the scanner. Runs cut short by the total timeout of
+// the scan, including runs still waiting for a slot, are reported as timed out. The start and end
+// of each run are reported to the client when it asked for progress notifications.
+func (t *Tool) runStage(
+	ctx context.Context,
+	scanners []tools.Scanner,
+	params tools.ScanParams,
+	previous resumeState,
+) []scannerResult {
 	var waitGroup sync.WaitGroup
-	resultsChan := make(chan scannerResult, len(t.scanners))
+	resultsChan := make(chan scannerResult, len(scanners))
+	target := params.Target().URL()
+	reporter, _ := ctx.Value(progressKey{}).(*progress)
+	runs := 0
+	for _, scanner := range scanners {
+		if _, ok := previous.lookup(params.Port, params.Vhost, scanner.Name()); !ok {
+			runs++
+		}
+	}
+	reporter.expect(runs)
 
-	for _, scanner := range t.scanners {
+	for _, scanner := range scanners {
 		waitGroup.Add(1)
 		go func(currentScanner tools.Scanner) {
 			defer waitGroup.Done()
 
+			scanParams, ignored := tools.NegotiateOptions(currentScanner, params)
+			if restored, ok := previous.lookup(params.Port, params.Vhost, currentScanner.Name()); ok {
+				restored.Findings = t.parseFindings(ctx, currentScanner, params.Target().URL(), restored.Output)
+				restored.Ignored = ignored
+				resultsChan <- restored
+				return
+			}
+
 			start := time.Now()
-			scanResult := currentScanner.Scan(ctx, params)
+			// Held before waiting for a slot and again once it is granted.
+			scan := tools.HoldScan(tools.TimelineScan(currentScanner.Name(), tools.MeasureScan(t.metrics, currentScanner.Name(),
+				tools.LimitScan(t.limiter, tools.HoldScan(reporter.scan(currentScanner.Name(), target, tools.TimeoutScan(
+					tools.CaptureScan(t.captureDir, t.redactor, t.logger, currentScanner.Name(), tools.SanitizeScan(currentScanner.Scan)))))))))
+			scanResult := scan(ctx, scanParams)
 			duration := time.Since(start)
+			if scanResult.Error != nil && !errors.Is(scanResult.Error, tools.ErrScanTimedOut) &&
+				errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				scanResult.Error = fmt.Errorf("%w: %w", tools.ErrScanTimedOut, scanResult.Error)
+			}
 
-			resultsChan <- scannerResult{
+			result := scannerResult{
 				Name:     currentScanner.Name(),
 				Output:   scanResult.Output,
 				Duration: duration,
 				Error:    scanResult.Error,
+				Findings: t.parseFindings(ctx, currentScanner, params.Target().URL(), scanResult.Output),
+				Ignored:  ignored,
 			}
+			reporter.finished(ctx, target, result)
+			resultsChan <- result
 		}(scanner)
 	}
 
@@ -134,104 +1200,241 @@ func (t *Tool) runScannersParallel(ctx context.Context, params tools.ScanParams)
 	}()
 
 	// Collect results.
+	logger := tools.ContextLogger(ctx, t.logger)
 	var results []scannerResult
 	for result := range resultsChan {
 		results = append(results, result)
-		if result.Error != nil {
-			t.logger.Warn().Err(result.Error).Msgf("%s scan failed", result.Name)
+		if result.held() {
+			logger.Info().Msgf("%s scan held, job paused", result.Name)
+		} else if result.timedOut() {
+			logger.Warn().Err(result.Error).Dur("duration", result.Duration).Msgf("%s scan timed out", result.Name)
+		} else if result.Error != nil {
+			logger.Warn().Err(result.Error).Msgf("%s scan failed", result.Name)
 		} else {
-			t.logger.Info().Dur("duration", result.Duration).Msgf("%s scan completed", result.Name)
+			logger.Info().Dur("duration", result.Duration).Msgf("%s scan completed", result.Name)
 		}
 	}
 
 	return results
 }
 
-// mergeResults merges scanner results into a unified report.
-func (t *Tool) mergeResults(targetURL string, results []scannerResult) string {
-	var builder strings.Builder
+// parseFindings parses the findings of a scanner output of a scan of target, leaving out
+// suppressed findings, and fingerprints and enriches the others with exploit intelligence.
+func (t *Tool) parseFindings(ctx context.Context, scanner tools.Scanner, target, output string) []models.Finding {
+	start := time.Now()
+	defer func() { tools.RecordPhase(ctx, models.PhaseParse, time.Since(start)) }()
+
+	found := tools.SuppressFindings(ctx, tools.ParseFindings(scanner, output))
+	findings.SetFingerprints(target, found)
+	t.intel.Enrich(found)
+
+	return found
+}
 
-	separator := "=" + strings.Repeat("=", reportLineWidth)
-	dashLine := "-" + strings.Repeat("-", reportLineWidth)
+// collectFindings gathers the findings parsed from every scanner result.
+func collectFindings(groups []vhostResults) []models.Finding {
+	var found []models.Finding
+	for _, group := range groups {
+		for _, result := range group.Results {
+			found = append(found, result.Findings...)
+		}
+	}
 
-	builder.WriteString(separator + "\n")
-	builder.WriteString("                    FULL SECURITY SCAN REPORT\n")
-	builder.WriteString(separator + "\n")
-	builder.WriteString(fmt.Sprintf("Target: %s\n", targetURL))
-	builder.WriteString(fmt.Sprintf("Date: %s\n", time.Now().UTC().Format(time.RFC1123)))
-	builder.WriteString(separator + "\n\n")
+	return found
+}
 
+// writeVhostReport writes the report of scanner results to w, with one section per vhost. A group
+// with an empty Vhost is written without a vhost banner.
+func (t *Tool) writeVhostReport(w *reportWriter, meta reportMeta, groups []vhostResults) {
+	headerLines := []string{fmt.Sprintf("Target: %s", meta.TargetURL)}
+	if meta.RequestedURL != "" {
+		headerLines = append(headerLines, fmt.Sprintf("Requested target: %s (redirected)", meta.RequestedURL))
+	}
+	if meta.Throttling != "" {
+		headerLines = append(headerLines, "Throttling: "+meta.Throttling)
+	}
+	if meta.Crawl != "" {
+		headerLines = append(headerLines, "Crawl: "+meta.Crawl)
+	}
+	t.writeHeader(w, meta.Branding, headerLines)
+	t.writeGroups(w, groups)
+	t.writeFooter(w, meta.Branding)
+}
+
+// writePortReport writes the report of the results of several ports to w, with one section per
+// port, each grouped by vhost. extraLines are appended to the report header.
+func (t *Tool) writePortReport(w *reportWriter, branding models.ReportBranding, host string, ports []portResults, extraLines ...string) {
+	headerLines := []string{
+		fmt.Sprintf("Target: %s", host),
+		fmt.Sprintf("Ports: %s", joinPorts(portNumbers(ports))),
+	}
+	t.writeHeader(w, branding, append(headerLines, extraLines...))
+	t.writePorts(w, ports)
+	t.writeFooter(w, branding)
+}
+
+// portNumbers returns the port of each port result.
+func portNumbers(ports []portResults) []int {
+	numbers := make([]int, 0, len(ports))
+	for _, port := range ports {
+		numbers = append(numbers, port.Port)
+	}
+
+	return numbers
+}
+
+// writePorts writes the results of each port, preceded by a port banner.
+func (t *Tool) writePorts(w *reportWriter, ports []portResults) {
+	for _, port := range ports {
+		w.banner(fmt.Sprintf("PORT: %d (%s)", port.Port, port.Meta.TargetURL))
+		if port.Meta.RequestedURL != "" {
+			w.printf("Requested target: %s (redirected)\n\n", port.Meta.RequestedURL)
+		}
+		if port.Meta.Throttling != "" {
+			w.printf("Throttling: %s\n\n", port.Meta.Throttling)
+		}
+		if port.Meta.Crawl != "" {
+			w.printf("Crawl: %s\n\n", port.Meta.Crawl)
+		}
+		t.writeGroups(w, port.Groups)
+	}
+}
+
+// writeHeader writes the confidentiality banner and report title followed by the engagement
+// metadata, the given header lines and the report date.
+func (t *Tool) writeHeader(w *reportWriter, branding models.ReportBranding, lines []string) {
+	writeBanner(w, branding)
+	w.write(separatorLine)
+	w.write("                    FULL SECURITY SCAN REPORT\n")
+	w.write(separatorLine)
+	for _, line := range append(branding.HeaderLines(), lines...) {
+		w.line(line)
+	}
+	w.printf("Date: %s\n", time.Now().UTC().Format(time.RFC1123))
+	w.write(separatorLine + "\n")
+}
+
+// writeGroups writes the results of each vhost group, preceded by a vhost banner when set.
+func (t *Tool) writeGroups(w *reportWriter, groups []vhostResults) {
+	for _, group := range groups {
+		if group.Vhost != "" {
+			w.banner("VHOST: " + group.Vhost)
+		}
+		t.writeResults(w, group.Results)
+	}
+}
+
+// writeFooter writes the end of report banner followed by the confidentiality banner.
+func (t *Tool) writeFooter(w *reportWriter, branding models.ReportBranding) {
+	w.write(separatorLine)
+	w.write("                    END OF REPORT\n")
+	w.write(separatorLine)
+	writeBanner(w, branding)
+}
+
+// writeBanner writes the confidentiality banner of branding centered on its own line, if any.
+func writeBanner(w *reportWriter, branding models.ReportBranding) {
+	banner := branding.BannerLine()
+	if banner == "" {
+		return
+	}
+	padding := max(0, (reportLineWidth+1-len([]rune(banner)))/2)
+	w.line(strings.Repeat(" ", padding) + banner)
+}
+
+// writeResults writes the summary section followed by individual scanner results. Scanner outputs
+// are written as they are, without copying them into intermediate strings.
+func (t *Tool) writeResults(w *reportWriter, results []scannerResult) {
 	// Summary section.
-	builder.WriteString("SCAN SUMMARY\n")
-	builder.WriteString(dashLine + "\n")
+	w.write("SCAN SUMMARY\n")
+	w.write(dashLine)
 
 	var totalDuration time.Duration
 	failCount := 0
+	heldCount := 0
 	successCount := 0
+	timedOutCount := 0
 
 	for _, result := range results {
 		totalDuration += result.Duration
-		status := "SUCCESS"
-		if result.Error != nil {
-			status = "FAILED"
+		status := result.status()
+		switch status {
+		case statusHeld:
+			heldCount++
+		case statusTimedOut:
+			timedOutCount++
+		case statusFailed:
 			failCount++
-		} else {
+		default:
 			successCount++
 		}
-		builder.WriteString(fmt.Sprintf("  %-10s: %s (%.2fs)\n", result.Name, status, result.Duration.Seconds()))
+		w.printf("  %-10s: %s (%.2fs)\n", result.Name, status, result.Duration.Seconds())
 	}
 
-	builder.WriteString(fmt.Sprintf("\nTotal scanners: %d | Successful: %d | Failed: %d\n", len(results), successCount, failCount))
-	builder.WriteString(fmt.Sprintf("Total scan time: %.2fs\n", totalDuration.Seconds()))
-	builder.WriteString("\n")
+	w.printf("\nTotal scanners: %d | Successful: %d | Failed: %d", len(results), successCount, failCount)
+	if timedOutCount > 0 {
+		w.printf(" | Timed out: %d", timedOutCount)
+	}
+	if heldCount > 0 {
+		w.printf(" | Held: %d", heldCount)
+	}
+	w.write("\n")
+	w.printf("Total scan time: %.2fs\n", totalDuration.Seconds())
+	w.write("\n")
 
 	// Individual scanner results.
 	for _, result := range results {
-		builder.WriteString(separator + "\n")
-		builder.WriteString(fmt.Sprintf("                    %s RESULTS\n", strings.ToUpper(result.Name)))
-		builder.WriteString(separator + "\n\n")
+		w.banner(strings.ToUpper(result.Name) + " RESULTS")
 
-		if result.Error != nil {
-			builder.WriteString(fmt.Sprintf("ERROR: %s\n\n", result.Error.Error()))
+		if len(result.Ignored) > 0 {
+			w.printf("Ignored unsupported options: %s\n\n", strings.Join(result.Ignored, ", "))
+		}
+		if len(result.Matched) > 0 {
+			w.printf("Auto-selected for detected technologies: %s\n\n", strings.Join(result.Matched, ", "))
+		}
+		if len(result.Hints) > 0 {
+			w.printf("Detected technologies, passed to later scanners: %s\n\n", strings.Join(result.Hints, ", "))
+		}
+		if result.held() {
+			w.write("HELD: the scan was paused before this scanner started. Resume the scan to run it.\n")
+		} else if result.timedOut() {
+			w.printf("TIMED OUT after %.2fs: %s\n\n", result.Duration.Seconds(), result.Error.Error())
 			if result.Output != "" {
-				builder.WriteString("Output:\n")
-				builder.WriteString(result.Output)
-				builder.WriteString("\n")
+				w.write("Partial output:\n")
+				w.line(result.Output)
+			}
+		} else if result.Error != nil {
+			w.printf("ERROR: %s\n\n", result.Error.Error())
+			if result.Output != "" {
+				w.write("Output:\n")
+				w.line(result.Output)
 			}
 		} else {
-			builder.WriteString(strings.TrimSpace(result.Output))
-			builder.WriteString("\n")
+			w.line(strings.TrimSpace(result.Output))
 		}
-		builder.WriteString("\n")
+		w.write("\n")
 	}
-
-	builder.WriteString(separator + "\n")
-	builder.WriteString("                    END OF REPORT\n")
-	builder.WriteString(separator + "\n")
-
-	return builder.String()
 }
 
-// applyPagination applies pagination to the output using the shared pagination logic.
-func (t *Tool) applyPagination(output string, maxLines, offset int) string {
-	pagination := tools.ApplyPagination(output, maxLines, offset)
-	paginatedOutput := strings.Join(pagination.Lines, "\n")
-
-	resultText := ""
-	if pagination.Truncated || offset > 0 {
-		resultText = fmt.Sprintf("[Showing lines %d-%d of %d lines. Use offset parameter to view more.]\n\n",
-			pagination.StartLine+1, pagination.EndLine, pagination.TotalLines)
+// pageText returns the text of page, read from start, preceded by its notice when it is partial.
+func (t *Tool) pageText(page tools.ResponsePage, start tools.Cursor) string {
+	resultText := tools.PageNotice(page, start, t.maxResponseBytes)
+	if resultText != "" {
+		resultText += "\n"
 	}
-	resultText += paginatedOutput
+	resultText += page.Text
 
 	return resultText
 }
 
-// New creates a new full scan tool with the given scanners.
-func New(logger zerolog.Logger, scanners ...tools.Scanner) tools.Tool {
+// New creates a new full scan tool running the scanners of registry.
+func New(logger zerolog.Logger, registry *tools.Registry) tools.Tool {
 	return &Tool{
-		logger:    logger.With().Str("tool", toolName).Logger(),
-		scanners:  scanners,
-		validator: validator.New(),
+		crawler:    crawl.New(),
+		discoverer: discovery.New(),
+		logger:     logger.With().Str("tool", toolName).Logger(),
+		notifier:   notify.New(),
+		registry:   registry,
+		validator:  validator.New(),
 	}
 }
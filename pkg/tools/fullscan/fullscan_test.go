@@ -1,19 +1,44 @@
 package fullscan
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/crawl"
+	"github.com/tb0hdan/wass-mcp/pkg/crypto"
+	"github.com/tb0hdan/wass-mcp/pkg/discovery"
+	"github.com/tb0hdan/wass-mcp/pkg/findings"
+	"github.com/tb0hdan/wass-mcp/pkg/limiter"
+	"github.com/tb0hdan/wass-mcp/pkg/metrics"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/notify"
+	"github.com/tb0hdan/wass-mcp/pkg/scope"
 	"github.com/tb0hdan/wass-mcp/pkg/server"
+	"github.com/tb0hdan/wass-mcp/pkg/session"
 	"github.com/tb0hdan/wass-mcp/pkg/storage"
+	"github.com/tb0hdan/wass-mcp/pkg/tenant"
 	"github.com/tb0hdan/wass-mcp/pkg/tools"
+	"github.com/tb0hdan/wass-mcp/pkg/types"
+	"github.com/tb0hdan/wass-mcp/pkg/vault"
+	"github.com/tb0hdan/wass-mcp/pkg/wordlist"
 )
 
 // mockScanner is a mock implementation of tools.Scanner for testing.
@@ -56,6 +81,114 @@ func (m *mockScanner) Register(_ *server.Server) error {
 	return nil
 }
 
+// authScanner is a mock scanner that authenticates with stored credentials.
+type authScanner struct {
+	mockScanner
+}
+
+func (a *authScanner) SupportedOptions() []string {
+	return []string{tools.OptionCredential}
+}
+
+// discoveryScanner is a mock content discovery scanner brute-forcing paths with a wordlist.
+type discoveryScanner struct {
+	mockScanner
+}
+
+func (d *discoveryScanner) SupportedOptions() []string {
+	return []string{tools.OptionWordlist}
+}
+
+// rateScanner is a mock scanner honouring a rate limit.
+type rateScanner struct {
+	mockScanner
+}
+
+func (r *rateScanner) SupportedOptions() []string {
+	return []string{tools.OptionRateLimit}
+}
+
+// listScanner is a mock scanner taking a URL list.
+type listScanner struct {
+	mockScanner
+}
+
+func (l *listScanner) SupportedOptions() []string {
+	return []string{tools.OptionURLs}
+}
+
+// passiveScanner is a mock scanner that only runs non-intrusive checks.
+type passiveScanner struct {
+	mockScanner
+}
+
+func (p *passiveScanner) IsPassive() bool {
+	return true
+}
+
+// fingerprintScanner is a mock scanner detecting the technologies listed in its output.
+type fingerprintScanner struct {
+	mockScanner
+}
+
+func (f *fingerprintScanner) Fingerprint(output string) []string {
+	return strings.Fields(output)
+}
+
+// technologyScanner is a mock scanner applying to the listed technologies.
+type technologyScanner struct {
+	mockScanner
+	technologies []string
+}
+
+func (t *technologyScanner) Technologies() []string {
+	return t.technologies
+}
+
+// stalledScanner is a mock scanner that prints partial output and runs until its context is done.
+type stalledScanner struct {
+	mockScanner
+}
+
+func (m *stalledScanner) Scan(ctx context.Context, _ tools.ScanParams) tools.ScanResult {
+	<-ctx.Done()
+	return tools.ScanResult{Output: m.scanOutput, Error: errors.New("signal: killed")}
+}
+
+// parsingScanner is a mock scanner with a native findings parser.
+type parsingScanner struct {
+	mockScanner
+}
+
+func (p *parsingScanner) ParseFindings(output string) ([]models.Finding, error) {
+	return []models.Finding{{Scanner: p.name, Severity: types.SeverityHigh, Title: output}}, nil
+}
+
+// recordingScanner is a mock scanner that records the scanned ports and peak concurrency.
+type recordingScanner struct {
+	mockScanner
+	mu      sync.Mutex
+	peak    int
+	ports   []int
+	running int
+}
+
+func (r *recordingScanner) Scan(_ context.Context, params tools.ScanParams) tools.ScanResult {
+	r.mu.Lock()
+	r.ports = append(r.ports, params.Port)
+	r.running++
+	r.peak = max(r.peak, r.running)
+	r.mu.Unlock()
+
+	time.Sleep(r.scanDelay)
+
+	r.mu.Lock()
+	r.running--
+	r.mu.Unlock()
+
+	return tools.ScanResult{Output: fmt.Sprintf("%s on %s", r.name, params.Target().URL())}
+}
+
 type FullScanTestSuite struct {
 	suite.Suite
 	logger zerolog.Logger
@@ -69,12 +202,12 @@ func (s *FullScanTestSuite) TestNew() {
 	scanner1 := &mockScanner{name: "mock1", available: true}
 	scanner2 := &mockScanner{name: "mock2", available: true}
 
-	tool := New(s.logger, scanner1, scanner2)
+	tool := New(s.logger, tools.NewRegistry(scanner1, scanner2))
 	s.NotNil(tool)
 }
 
 func (s *FullScanTestSuite) TestNew_NoScanners() {
-	tool := New(s.logger)
+	tool := New(s.logger, tools.NewRegistry())
 	s.NotNil(tool)
 }
 
@@ -85,8 +218,7 @@ func (s *FullScanTestSuite) TestRunScannersParallel_SingleScanner() {
 		scanOutput: "test output",
 	}
 
-	tool := New(s.logger, scanner).(*Tool)
-	tool.scanners = []tools.Scanner{scanner}
+	tool := New(s.logger, tools.NewRegistry(scanner)).(*Tool)
 
 	ctx := context.Background()
 	params := tools.ScanParams{
@@ -96,7 +228,7 @@ func (s *FullScanTestSuite) TestRunScannersParallel_SingleScanner() {
 		Vhost:  "",
 	}
 
-	results := tool.runScannersParallel(ctx, params)
+	results := tool.runScannersParallel(ctx, params, nil)
 
 	s.Len(results, 1)
 	s.Equal("mock1", results[0].Name)
@@ -105,6 +237,140 @@ func (s *FullScanTestSuite) TestRunScannersParallel_SingleScanner() {
 	s.True(scanner.scanCalled)
 }
 
+func (s *FullScanTestSuite) TestRunScannersParallel_TimedOut() {
+	fast := &mockScanner{name: "fast", available: true, scanOutput: "fast output"}
+	broken := &mockScanner{name: "broken", available: true, scanError: errors.New("exit status 2")}
+	stalled := &stalledScanner{mockScanner{name: "stalled", available: true, scanOutput: "partial output"}}
+	tool := New(s.logger, tools.NewRegistry(fast, broken, stalled)).(*Tool)
+
+	params := tools.ScanParams{Host: "localhost", Port: 80, Scheme: "http", Timeout: 50 * time.Millisecond}
+	results := tool.runScannersParallel(context.Background(), params, nil)
+	s.Require().Len(results, 3)
+	byName := map[string]scannerResult{}
+	for _, result := range results {
+		byName[result.Name] = result
+	}
+	s.True(byName["stalled"].timedOut())
+	s.GreaterOrEqual(byName["stalled"].Duration, 50*time.Millisecond)
+	s.False(byName["broken"].timedOut())
+	s.NoError(byName["fast"].Error)
+
+	report := tool.mergeResults("http://localhost", []scannerResult{byName["fast"], byName["broken"], byName["stalled"]})
+	s.Contains(report, "stalled   : TIMED OUT (")
+	s.Contains(report, "broken    : FAILED (")
+	s.Contains(report, "Total scanners: 3 | Successful: 1 | Failed: 1 | Timed out: 1\n")
+	s.Contains(report, "TIMED OUT after ")
+	s.Contains(report, "scan timed out: signal: killed\n\nPartial output:\npartial output\n")
+}
+
+func (s *FullScanTestSuite) TestFullScanHandler_Timeout() {
+	stalled := &stalledScanner{mockScanner{name: "stalled", available: true, scanOutput: "partial output"}}
+	tool := New(s.logger, tools.NewRegistry(stalled)).(*Tool)
+
+	input := Input{ScannerInput: tools.ScannerInput{Host: "localhost", Timeout: 1, MaxLines: 1000}}
+	result, _, err := tool.FullScanHandler(context.Background(), &mcp.CallToolRequest{}, input)
+	s.Require().NoError(err)
+	text := result.Content[0].(*mcp.TextContent).Text
+	s.Contains(text, "stalled   : TIMED OUT (1.")
+	s.Contains(text, "Partial output:\npartial output")
+}
+
+func (s *FullScanTestSuite) TestFullScanHandler_DefaultScanTimeout() {
+	stalled := &stalledScanner{mockScanner{name: "stalled", available: true, scanOutput: "partial output"}}
+	tool := New(s.logger, tools.NewRegistry(stalled)).(*Tool)
+	tool.scanTimeout = 50 * time.Millisecond
+
+	input := Input{ScannerInput: tools.ScannerInput{Host: "localhost", MaxLines: 1000}}
+	result, _, err := tool.FullScanHandler(context.Background(), &mcp.CallToolRequest{}, input)
+	s.Require().NoError(err)
+	text := result.Content[0].(*mcp.TextContent).Text
+	s.Contains(text, "stalled   : TIMED OUT (")
+	s.NotContains(text, "total timeout")
+}
+
+func (s *FullScanTestSuite) TestFullScanHandler_TotalTimeout() {
+	fast := &mockScanner{name: "fast", available: true, scanOutput: "fast output"}
+	stalled := &stalledScanner{mockScanner{name: "stalled", available: true, scanOutput: "partial output"}}
+	tool := New(s.logger, tools.NewRegistry(fast, stalled)).(*Tool)
+
+	input := Input{ScannerInput: tools.ScannerInput{Host: "localhost", MaxLines: 1000}, TotalTimeout: 1}
+	result, _, err := tool.FullScanHandler(context.Background(), &mcp.CallToolRequest{}, input)
+	s.Require().NoError(err)
+	text := result.Content[0].(*mcp.TextContent).Text
+	s.Contains(text, "[Scan stopped by its total timeout of 1s; unfinished scanner runs timed out.]")
+	s.Contains(text, "stalled   : TIMED OUT (")
+	s.Contains(text, "fast output")
+
+	_, _, err = tool.FullScanHandler(context.Background(), &mcp.CallToolRequest{}, Input{
+		ScannerInput: tools.ScannerInput{Host: "localhost"}, TotalTimeout: -1,
+	})
+	s.ErrorContains(err, "validation error")
+}
+
+func (s *FullScanTestSuite) TestFullScanHandler_DefaultTotalTimeout() {
+	stalled := &stalledScanner{mockScanner{name: "stalled", available: true, scanOutput: "partial output"}}
+	waiting := &mockScanner{name: "waiting", available: true, scanOutput: "never run"}
+	tool := New(s.logger, tools.NewRegistry(stalled, waiting)).(*Tool)
+	tool.fullScanTimeout = 50 * time.Millisecond
+	// A single slot leaves one run waiting for it until the scan times out.
+	tool.limiter = limiter.New(1)
+
+	input := Input{ScannerInput: tools.ScannerInput{Host: "localhost", MaxLines: 1000}}
+	result, _, err := tool.FullScanHandler(context.Background(), &mcp.CallToolRequest{}, input)
+	s.Require().NoError(err)
+	text := result.Content[0].(*mcp.TextContent).Text
+	s.Contains(text, "[Scan stopped by its total timeout of 50ms; unfinished scanner runs timed out.]")
+	s.Contains(text, "Timed out: 2")
+	s.NotContains(text, "never run")
+}
+
+func (s *FullScanTestSuite) TestRunScannersParallel_ParsesFindings() {
+	native := &parsingScanner{mockScanner{name: "native", available: true, scanOutput: "native output"}}
+	text := &mockScanner{name: "text", available: true, scanOutput: "[low] http://localhost/x"}
+
+	tool := New(s.logger, tools.NewRegistry(native, text)).(*Tool)
+
+	results := tool.runScannersParallel(context.Background(), tools.ScanParams{Host: "localhost", Port: 80, Scheme: "http"}, nil)
+	s.Require().Len(results, 2)
+
+	found := collectFindings([]vhostResults{{Results: results}})
+	s.Require().Len(found, 2)
+	for _, finding := range found {
+		switch finding.Scanner {
+		case "native":
+			s.Equal("native output", finding.Title)
+			s.Equal(types.SeverityHigh, finding.Severity)
+		case "text":
+			s.Equal("http://localhost/x", finding.URL)
+			s.Equal(types.SeverityLow, finding.Severity)
+		default:
+			s.Failf("unexpected scanner", "scanner %s", finding.Scanner)
+		}
+	}
+}
+
+func (s *FullScanTestSuite) TestRunScannersParallel_NegotiatesOptions() {
+	scanner := &mockScanner{name: "legacy", available: true, scanOutput: "ok"}
+
+	tool := New(s.logger, tools.NewRegistry(scanner)).(*Tool)
+
+	params := tools.ScanParams{
+		Host:    "localhost",
+		Options: map[string]string{tools.OptionUserAgent: "wass"},
+		Port:    80,
+		Scheme:  "http",
+		Vhost:   "vhost.example.com",
+	}
+	results := tool.runScannersParallel(context.Background(), params, nil)
+	s.Require().Len(results, 1)
+	s.Equal([]string{tools.OptionUserAgent}, results[0].Ignored)
+	s.Empty(scanner.scanParams.Options)
+	s.Equal("vhost.example.com", scanner.scanParams.Vhost)
+
+	report := tool.mergeResults("http://localhost", results)
+	s.Contains(report, "Ignored unsupported options: user_agent")
+}
+
 func (s *FullScanTestSuite) TestRunScannersParallel_MultipleScanners() {
 	scanner1 := &mockScanner{
 		name:       "mock1",
@@ -117,8 +383,7 @@ func (s *FullScanTestSuite) TestRunScannersParallel_MultipleScanners() {
 		scanOutput: "output2",
 	}
 
-	tool := New(s.logger, scanner1, scanner2).(*Tool)
-	tool.scanners = []tools.Scanner{scanner1, scanner2}
+	tool := New(s.logger, tools.NewRegistry(scanner1, scanner2)).(*Tool)
 
 	ctx := context.Background()
 	params := tools.ScanParams{
@@ -128,7 +393,7 @@ func (s *FullScanTestSuite) TestRunScannersParallel_MultipleScanners() {
 		Vhost:  "test.example.com",
 	}
 
-	results := tool.runScannersParallel(ctx, params)
+	results := tool.runScannersParallel(ctx, params, nil)
 
 	s.Len(results, 2)
 	s.True(scanner1.scanCalled)
@@ -148,13 +413,12 @@ func (s *FullScanTestSuite) TestRunScannersParallel_WithError() {
 		scanError:  errors.New("scan failed"),
 	}
 
-	tool := New(s.logger, scanner).(*Tool)
-	tool.scanners = []tools.Scanner{scanner}
+	tool := New(s.logger, tools.NewRegistry(scanner)).(*Tool)
 
 	ctx := context.Background()
 	params := tools.ScanParams{Host: "localhost", Port: 80, Scheme: "http"}
 
-	results := tool.runScannersParallel(ctx, params)
+	results := tool.runScannersParallel(ctx, params, nil)
 
 	s.Len(results, 1)
 	s.Equal("mock1", results[0].Name)
@@ -178,14 +442,13 @@ func (s *FullScanTestSuite) TestRunScannersParallel_Concurrent() {
 		scanDelay:  50 * time.Millisecond,
 	}
 
-	tool := New(s.logger, scanner1, scanner2).(*Tool)
-	tool.scanners = []tools.Scanner{scanner1, scanner2}
+	tool := New(s.logger, tools.NewRegistry(scanner1, scanner2)).(*Tool)
 
 	ctx := context.Background()
 	params := tools.ScanParams{Host: "localhost", Port: 80, Scheme: "http"}
 
 	start := time.Now()
-	results := tool.runScannersParallel(ctx, params)
+	results := tool.runScannersParallel(ctx, params, nil)
 	duration := time.Since(start)
 
 	s.Len(results, 2)
@@ -195,7 +458,7 @@ func (s *FullScanTestSuite) TestRunScannersParallel_Concurrent() {
 }
 
 func (s *FullScanTestSuite) TestMergeResults_Success() {
-	tool := New(s.logger).(*Tool)
+	tool := New(s.logger, tools.NewRegistry()).(*Tool)
 
 	results := []scannerResult{
 		{
@@ -228,7 +491,7 @@ func (s *FullScanTestSuite) TestMergeResults_Success() {
 }
 
 func (s *FullScanTestSuite) TestMergeResults_WithFailure() {
-	tool := New(s.logger).(*Tool)
+	tool := New(s.logger, tools.NewRegistry()).(*Tool)
 
 	results := []scannerResult{
 		{
@@ -258,7 +521,7 @@ func (s *FullScanTestSuite) TestMergeResults_WithFailure() {
 }
 
 func (s *FullScanTestSuite) TestMergeResults_Empty() {
-	tool := New(s.logger).(*Tool)
+	tool := New(s.logger, tools.NewRegistry()).(*Tool)
 
 	results := []scannerResult{}
 
@@ -269,10 +532,10 @@ func (s *FullScanTestSuite) TestMergeResults_Empty() {
 }
 
 func (s *FullScanTestSuite) TestApplyPagination_NoTruncation() {
-	tool := New(s.logger).(*Tool)
+	tool := New(s.logger, tools.NewRegistry()).(*Tool)
 
 	output := "line1\nline2\nline3"
-	result := tool.applyPagination(output, 0, 0)
+	result, _ := tool.applyPagination(output, 0, tools.Cursor{Line: 0})
 
 	s.Contains(result, "line1")
 	s.Contains(result, "line2")
@@ -281,7 +544,7 @@ func (s *FullScanTestSuite) TestApplyPagination_NoTruncation() {
 }
 
 func (s *FullScanTestSuite) TestApplyPagination_WithTruncation() {
-	tool := New(s.logger).(*Tool)
+	tool := New(s.logger, tools.NewRegistry()).(*Tool)
 
 	var lines []string
 	for i := 0; i < 100; i++ {
@@ -289,13 +552,13 @@ func (s *FullScanTestSuite) TestApplyPagination_WithTruncation() {
 	}
 	output := strings.Join(lines, "\n")
 
-	result := tool.applyPagination(output, 10, 0)
+	result, _ := tool.applyPagination(output, 10, tools.Cursor{Line: 0})
 
 	s.Contains(result, "Showing lines 1-10 of 100 lines")
 }
 
 func (s *FullScanTestSuite) TestApplyPagination_WithOffset() {
-	tool := New(s.logger).(*Tool)
+	tool := New(s.logger, tools.NewRegistry()).(*Tool)
 
 	var lines []string
 	for i := 0; i < 50; i++ {
@@ -303,23 +566,37 @@ func (s *FullScanTestSuite) TestApplyPagination_WithOffset() {
 	}
 	output := strings.Join(lines, "\n")
 
-	result := tool.applyPagination(output, 10, 20)
+	result, _ := tool.applyPagination(output, 10, tools.Cursor{Line: 20})
 
 	s.Contains(result, "Showing lines 21-30 of 50 lines")
 }
 
 func (s *FullScanTestSuite) TestApplyPagination_OffsetBeyondEnd() {
-	tool := New(s.logger).(*Tool)
+	tool := New(s.logger, tools.NewRegistry()).(*Tool)
 
 	output := "line1\nline2\nline3"
-	result := tool.applyPagination(output, 10, 100)
+	result, _ := tool.applyPagination(output, 10, tools.Cursor{Line: 100})
 
 	// When offset is beyond totalLines, output should still be returned.
 	s.NotEmpty(result)
 }
 
+func (s *FullScanTestSuite) TestApplyPagination_ByteBudget() {
+	tool := New(s.logger, tools.NewRegistry()).(*Tool)
+	tool.maxResponseBytes = 8
+
+	result, page := tool.applyPagination("line1\nline2\nline3", 0, tools.Cursor{})
+	s.Contains(result, `Use cursor "1:0" to view more.`)
+	s.True(strings.HasSuffix(result, "\nline1"))
+	s.Require().NotNil(page.Next)
+
+	result, page = tool.applyPagination("line1\nline2\nline3", 0, *page.Next)
+	s.True(strings.HasSuffix(result, "\nline2"))
+	s.Equal(&tools.Cursor{Line: 2}, page.Next)
+}
+
 func (s *FullScanTestSuite) TestScannerInput_Validation() {
-	tool := New(s.logger).(*Tool)
+	tool := New(s.logger, tools.NewRegistry()).(*Tool)
 
 	// Test valid input.
 	input := tools.ScannerInput{
@@ -331,7 +608,7 @@ func (s *FullScanTestSuite) TestScannerInput_Validation() {
 }
 
 func (s *FullScanTestSuite) TestScannerInput_ValidationInvalidHost() {
-	tool := New(s.logger).(*Tool)
+	tool := New(s.logger, tools.NewRegistry()).(*Tool)
 
 	input := tools.ScannerInput{
 		Host: "not a valid host!!!",
@@ -342,7 +619,7 @@ func (s *FullScanTestSuite) TestScannerInput_ValidationInvalidHost() {
 }
 
 func (s *FullScanTestSuite) TestScannerInput_ValidationInvalidPort() {
-	tool := New(s.logger).(*Tool)
+	tool := New(s.logger, tools.NewRegistry()).(*Tool)
 
 	input := tools.ScannerInput{
 		Host: "localhost",
@@ -353,7 +630,7 @@ func (s *FullScanTestSuite) TestScannerInput_ValidationInvalidPort() {
 }
 
 func (s *FullScanTestSuite) TestScannerInput_ValidationEmptyHost() {
-	tool := New(s.logger).(*Tool)
+	tool := New(s.logger, tools.NewRegistry()).(*Tool)
 
 	// Empty host should be valid (uses default).
 	input := tools.ScannerInput{
@@ -364,7 +641,7 @@ func (s *FullScanTestSuite) TestScannerInput_ValidationEmptyHost() {
 }
 
 func (s *FullScanTestSuite) TestScannerInput_ValidationWithVhost() {
-	tool := New(s.logger).(*Tool)
+	tool := New(s.logger, tools.NewRegistry()).(*Tool)
 
 	input := tools.ScannerInput{
 		Host:  "192.168.1.1",
@@ -376,7 +653,7 @@ func (s *FullScanTestSuite) TestScannerInput_ValidationWithVhost() {
 }
 
 func (s *FullScanTestSuite) TestScannerInput_ValidationMaxLinesExceeded() {
-	tool := New(s.logger).(*Tool)
+	tool := New(s.logger, tools.NewRegistry()).(*Tool)
 
 	input := tools.ScannerInput{
 		Host:     "localhost",
@@ -419,7 +696,7 @@ func (s *FullScanTestSuite) TestRegister_NoScannersAvailable() {
 	scanner1 := &mockScanner{name: "mock1", available: false}
 	scanner2 := &mockScanner{name: "mock2", available: false}
 
-	tool := New(s.logger, scanner1, scanner2).(*Tool)
+	tool := New(s.logger, tools.NewRegistry(scanner1, scanner2)).(*Tool)
 
 	srv, cleanup := s.setupTestServer()
 	defer cleanup()
@@ -435,7 +712,7 @@ func (s *FullScanTestSuite) TestRegister_SomeScannersAvailable() {
 	scanner2 := &mockScanner{name: "mock2", available: false}
 	scanner3 := &mockScanner{name: "mock3", available: true}
 
-	tool := New(s.logger, scanner1, scanner2, scanner3).(*Tool)
+	tool := New(s.logger, tools.NewRegistry(scanner1, scanner2, scanner3)).(*Tool)
 
 	srv, cleanup := s.setupTestServer()
 	defer cleanup()
@@ -445,14 +722,14 @@ func (s *FullScanTestSuite) TestRegister_SomeScannersAvailable() {
 	s.NoError(err)
 
 	// Verify only available scanners are kept.
-	s.Len(tool.scanners, 2)
+	s.Len(tool.registry.Available(), 2)
 }
 
 func (s *FullScanTestSuite) TestRegister_AllScannersAvailable() {
 	scanner1 := &mockScanner{name: "mock1", available: true}
 	scanner2 := &mockScanner{name: "mock2", available: true}
 
-	tool := New(s.logger, scanner1, scanner2).(*Tool)
+	tool := New(s.logger, tools.NewRegistry(scanner1, scanner2)).(*Tool)
 
 	srv, cleanup := s.setupTestServer()
 	defer cleanup()
@@ -462,13 +739,12 @@ func (s *FullScanTestSuite) TestRegister_AllScannersAvailable() {
 	s.NoError(err)
 
 	// All scanners should be kept.
-	s.Len(tool.scanners, 2)
+	s.Len(tool.registry.Available(), 2)
 }
 
 func (s *FullScanTestSuite) TestFullScanHandler_ValidationError() {
 	scanner := &mockScanner{name: "mock1", available: true, scanOutput: "test"}
-	tool := New(s.logger, scanner).(*Tool)
-	tool.scanners = []tools.Scanner{scanner}
+	tool := New(s.logger, tools.NewRegistry(scanner)).(*Tool)
 
 	ctx := context.Background()
 	req := &mcp.CallToolRequest{}
@@ -477,7 +753,7 @@ func (s *FullScanTestSuite) TestFullScanHandler_ValidationError() {
 		Port: 80,
 	}
 
-	result, output, err := tool.FullScanHandler(ctx, req, input)
+	result, output, err := tool.FullScanHandler(ctx, req, Input{ScannerInput: input})
 	s.Nil(result)
 	s.Nil(output)
 	s.Error(err)
@@ -486,8 +762,7 @@ func (s *FullScanTestSuite) TestFullScanHandler_ValidationError() {
 
 func (s *FullScanTestSuite) TestFullScanHandler_ValidationErrorInvalidPort() {
 	scanner := &mockScanner{name: "mock1", available: true, scanOutput: "test"}
-	tool := New(s.logger, scanner).(*Tool)
-	tool.scanners = []tools.Scanner{scanner}
+	tool := New(s.logger, tools.NewRegistry(scanner)).(*Tool)
 
 	ctx := context.Background()
 	req := &mcp.CallToolRequest{}
@@ -496,19 +771,346 @@ func (s *FullScanTestSuite) TestFullScanHandler_ValidationErrorInvalidPort() {
 		Port: 70000,
 	}
 
-	result, output, err := tool.FullScanHandler(ctx, req, input)
+	result, output, err := tool.FullScanHandler(ctx, req, Input{ScannerInput: input})
 	s.Nil(result)
 	s.Nil(output)
 	s.Error(err)
 	s.Contains(err.Error(), "validation error")
 }
 
+func (s *FullScanTestSuite) TestFullScanHandler_InvalidOption() {
+	scanner := &mockScanner{name: "test-scanner", available: true}
+	tool := New(s.logger, tools.NewRegistry(scanner)).(*Tool)
+
+	input := tools.ScannerInput{Host: "localhost", Options: map[string]string{tools.OptionMaxAttackTime: "0"}}
+	_, _, err := tool.FullScanHandler(context.Background(), &mcp.CallToolRequest{}, Input{ScannerInput: input})
+	s.ErrorContains(err, "validation error: option max_attack_time")
+	s.False(scanner.scanCalled)
+}
+
+func (s *FullScanTestSuite) TestFullScanHandler_SessionDefaults() {
+	scanner := &mockScanner{name: "test-scanner", available: true, scanOutput: "ok"}
+	tool := New(s.logger, tools.NewRegistry(scanner)).(*Tool)
+	tool.sessions = session.NewStore(session.DefaultTTL)
+	tool.sessions.Set("", "s1", session.Defaults{Host: "example.com", Port: 8080})
+
+	ctx := tools.WithSessionID(context.Background(), "s1")
+	_, _, err := tool.FullScanHandler(ctx, &mcp.CallToolRequest{}, Input{})
+	s.Require().NoError(err)
+	s.Equal("example.com", scanner.scanParams.Host)
+	s.Equal(8080, scanner.scanParams.Port)
+}
+
+func (s *FullScanTestSuite) TestFullScanHandler_Credential() {
+	srv, cleanup := s.setupTestServer()
+	defer cleanup()
+	keyring, err := crypto.NewKeyring(make([]byte, crypto.KeySize))
+	s.Require().NoError(err)
+	credentialVault := vault.New(keyring)
+	srv.SetVault(credentialVault)
+
+	ctx := tenant.WithTenant(context.Background(), "alpha")
+	sealed, err := credentialVault.Seal(vault.Secret{Username: "admin", Password: "s3cret"})
+	s.Require().NoError(err)
+	s.Require().NoError(srv.Storage().SaveCredential(ctx, &models.Credential{Name: "staging-admin", Type: vault.TypeBasic, Secret: sealed}))
+
+	authenticating := &authScanner{mockScanner{name: "auth", available: true, scanOutput: "ok"}}
+	legacy := &mockScanner{name: "legacy", available: true, scanOutput: "ok"}
+	tool := New(s.logger, tools.NewRegistry(authenticating, legacy)).(*Tool)
+	s.Require().NoError(tool.Register(srv))
+
+	input := Input{ScannerInput: tools.ScannerInput{Host: "example.com", Credential: "staging-admin"}}
+	result, _, err := tool.FullScanHandler(ctx, &mcp.CallToolRequest{}, input)
+	s.Require().NoError(err)
+	s.Require().NotNil(authenticating.scanParams.Credential)
+	s.Equal("admin", authenticating.scanParams.Credential.Username)
+	s.Nil(legacy.scanParams.Credential)
+	s.Contains(result.Content[0].(*mcp.TextContent).Text, "Ignored unsupported options: credential")
+
+	input.Credential = "missing"
+	_, _, err = tool.FullScanHandler(ctx, &mcp.CallToolRequest{}, input)
+	s.ErrorContains(err, "credential missing not found")
+}
+
+func (s *FullScanTestSuite) TestFullScanHandler_Wordlist() {
+	srv, cleanup := s.setupTestServer()
+	defer cleanup()
+	registry := wordlist.New(s.T().TempDir(), 0)
+	srv.SetWordlists(registry)
+	ctx := tenant.WithTenant(context.Background(), "alpha")
+	_, err := registry.Save(ctx, "raft-medium", strings.NewReader("admin\n"))
+	s.Require().NoError(err)
+
+	discovering := &discoveryScanner{mockScanner{name: "discovery", available: true, scanOutput: "ok"}}
+	legacy := &mockScanner{name: "legacy", available: true, scanOutput: "ok"}
+	tool := New(s.logger, tools.NewRegistry(discovering, legacy)).(*Tool)
+	s.Require().NoError(tool.Register(srv))
+
+	input := Input{ScannerInput: tools.ScannerInput{Host: "example.com", Wordlist: "raft-medium"}}
+	result, _, err := tool.FullScanHandler(ctx, &mcp.CallToolRequest{}, input)
+	s.Require().NoError(err)
+	s.Equal("raft-medium.txt", filepath.Base(discovering.scanParams.Wordlist))
+	s.Empty(legacy.scanParams.Wordlist)
+	s.Contains(result.Content[0].(*mcp.TextContent).Text, "Ignored unsupported options: wordlist")
+
+	input.Wordlist = "missing"
+	_, _, err = tool.FullScanHandler(ctx, &mcp.CallToolRequest{}, input)
+	s.ErrorIs(err, wordlist.ErrNotFound)
+}
+
+func (s *FullScanTestSuite) TestFullScanHandler_SelectedScanners() {
+	scanner1 := &mockScanner{name: "scanner1", available: true, scanOutput: "one"}
+	scanner2 := &mockScanner{name: "scanner2", available: true, scanOutput: "two"}
+	tool := New(s.logger, tools.NewRegistry(scanner1, scanner2)).(*Tool)
+
+	input := Input{ScannerInput: tools.ScannerInput{Host: "example.com"}, Scanners: []string{"scanner2"}}
+	result, _, err := tool.FullScanHandler(context.Background(), &mcp.CallToolRequest{}, input)
+	s.Require().NoError(err)
+	s.False(scanner1.scanCalled)
+	s.True(scanner2.scanCalled)
+	s.Contains(result.Content[0].(*mcp.TextContent).Text, "Total scanners: 1")
+
+	input.Scanners = []string{"scanner3"}
+	_, _, err = tool.FullScanHandler(context.Background(), &mcp.CallToolRequest{}, input)
+	s.ErrorContains(err, `validation error: unknown scanner "scanner3" (available: scanner1, scanner2)`)
+}
+
+func (s *FullScanTestSuite) TestFullScanHandler_Passive() {
+	active := &mockScanner{name: "active", available: true, scanOutput: "one"}
+	passive := &passiveScanner{mockScanner{name: "passive", available: true, scanOutput: "two"}}
+	tool := New(s.logger, tools.NewRegistry(active, passive)).(*Tool)
+
+	input := Input{ScannerInput: tools.ScannerInput{Host: "example.com", Passive: true}}
+	result, _, err := tool.FullScanHandler(context.Background(), &mcp.CallToolRequest{}, input)
+	s.Require().NoError(err)
+	s.False(active.scanCalled)
+	s.True(passive.scanCalled)
+	text := result.Content[0].(*mcp.TextContent).Text
+	s.True(strings.HasPrefix(text, "[Passive mode: active scanners skipped: active]\n"), text)
+	s.Contains(text, "Total scanners: 1")
+
+	input.Scanners = []string{"active"}
+	_, _, err = tool.FullScanHandler(context.Background(), &mcp.CallToolRequest{}, input)
+	s.ErrorIs(err, tools.ErrActiveScanner)
+
+	input.Scanners = nil
+	input.DiscoverPorts = true
+	_, _, err = tool.FullScanHandler(context.Background(), &mcp.CallToolRequest{}, input)
+	s.ErrorContains(err, "validation error: discover_ports is not allowed in passive mode")
+
+	input.DiscoverPorts = false
+	input.CrawlFirst = true
+	_, _, err = tool.FullScanHandler(context.Background(), &mcp.CallToolRequest{}, input)
+	s.ErrorContains(err, "validation error: crawl_first is not allowed in passive mode")
+
+	activeOnly := New(s.logger, tools.NewRegistry(&mockScanner{name: "active", available: true})).(*Tool)
+	_, _, err = activeOnly.FullScanHandler(context.Background(), &mcp.CallToolRequest{}, Input{ScannerInput: input.ScannerInput})
+	s.ErrorContains(err, "no passive scanner is enabled")
+}
+
+func (s *FullScanTestSuite) TestFullScanHandler_RunFirst() {
+	fingerprinting := &fingerprintScanner{mockScanner{name: "fingerprint", available: true, scanOutput: "wordpress php"}}
+	later := &mockScanner{name: "later", available: true, scanOutput: "done"}
+	tool := New(s.logger, tools.NewRegistry(later, fingerprinting)).(*Tool)
+
+	input := Input{ScannerInput: tools.ScannerInput{Host: "example.com"}, RunFirst: []string{"fingerprint"}}
+	result, _, err := tool.FullScanHandler(context.Background(), &mcp.CallToolRequest{}, input)
+	s.Require().NoError(err)
+	s.Equal([]string{"wordpress", "php"}, later.scanParams.Hints)
+	s.Empty(fingerprinting.scanParams.Hints)
+	s.Contains(result.Content[0].(*mcp.TextContent).Text, "Detected technologies, passed to later scanners: wordpress, php")
+
+	input.RunFirst = nil
+	_, _, err = tool.FullScanHandler(context.Background(), &mcp.CallToolRequest{}, input)
+	s.Require().NoError(err)
+	s.Empty(later.scanParams.Hints, "scanners run in parallel without run_first")
+
+	input.RunFirst = []string{"unknown"}
+	_, _, err = tool.FullScanHandler(context.Background(), &mcp.CallToolRequest{}, input)
+	s.ErrorContains(err, `validation error: run_first: unknown scanner "unknown"`)
+
+	input.RunFirst = []string{"fingerprint"}
+	input.Scanners = []string{"later"}
+	_, _, err = tool.FullScanHandler(context.Background(), &mcp.CallToolRequest{}, input)
+	s.ErrorContains(err, `validation error: run_first: scanner "fingerprint" is not among the selected scanners`)
+}
+
+func (s *FullScanTestSuite) TestFullScanHandler_Auto() {
+	fingerprinting := &fingerprintScanner{mockScanner{name: "fingerprint", available: true, scanOutput: "wordpress php"}}
+	general := &mockScanner{name: "general", available: true, scanOutput: "done"}
+	wordpress := &technologyScanner{mockScanner{name: "wp", available: true, scanOutput: "wp done"}, []string{"wordpress"}}
+	drupal := &technologyScanner{mockScanner{name: "drupal", available: true}, []string{"drupal"}}
+	tool := New(s.logger, tools.NewRegistry(general, fingerprinting, wordpress, drupal)).(*Tool)
+
+	input := Input{ScannerInput: tools.ScannerInput{Host: "example.com"}}
+	result, _, err := tool.FullScanHandler(context.Background(), &mcp.CallToolRequest{}, input)
+	s.Require().NoError(err)
+	s.False(wordpress.scanCalled, "technology scanners only run when selected")
+	s.Contains(result.Content[0].(*mcp.TextContent).Text, "Total scanners: 2")
+
+	input.Auto = true
+	result, _, err = tool.FullScanHandler(context.Background(), &mcp.CallToolRequest{}, input)
+	s.Require().NoError(err)
+	s.True(wordpress.scanCalled)
+	s.False(drupal.scanCalled)
+	s.Equal([]string{"wordpress", "php"}, wordpress.scanParams.Hints)
+	s.Equal([]string{"wordpress", "php"}, general.scanParams.Hints, "fingerprinting scanners run first in auto mode")
+	text := result.Content[0].(*mcp.TextContent).Text
+	s.Contains(text, "Auto-selected for detected technologies: wordpress")
+	s.Contains(text, "Total scanners: 3")
+
+	named := Input{ScannerInput: tools.ScannerInput{Host: "example.com"}, Scanners: []string{"drupal"}}
+	_, _, err = tool.FullScanHandler(context.Background(), &mcp.CallToolRequest{}, named)
+	s.Require().NoError(err)
+	s.True(drupal.scanCalled, "technology scanners run when named")
+}
+
+func (s *FullScanTestSuite) TestFullScanHandler_Hints() {
+	general := &mockScanner{name: "general", available: true, scanOutput: "done"}
+	wordpress := &technologyScanner{mockScanner{name: "wp", available: true, scanOutput: "wp done"}, []string{"wordpress"}}
+	drupal := &technologyScanner{mockScanner{name: "drupal", available: true}, []string{"drupal"}}
+	tool := New(s.logger, tools.NewRegistry(general, wordpress, drupal)).(*Tool)
+
+	input := Input{ScannerInput: tools.ScannerInput{Host: "example.com"}, Hints: map[string]string{"cms": "WordPress", "lang": "php"}}
+	result, _, err := tool.FullScanHandler(context.Background(), &mcp.CallToolRequest{}, input)
+	s.Require().NoError(err)
+	s.True(wordpress.scanCalled)
+	s.False(drupal.scanCalled)
+	s.Equal([]string{"php", "wordpress"}, general.scanParams.Hints)
+	s.Equal([]string{"php", "wordpress"}, wordpress.scanParams.Hints)
+	text := result.Content[0].(*mcp.TextContent).Text
+	s.Contains(text, "Auto-selected for detected technologies: wordpress")
+	s.Contains(text, "Total scanners: 2")
+
+	wordpress.scanCalled = false
+	_, _, err = tool.FullScanHandler(context.Background(), &mcp.CallToolRequest{}, Input{
+		ScannerInput: tools.ScannerInput{Host: "example.com"}, Hints: map[string]string{"cms": "wordpress"},
+		Scanners: []string{"general"},
+	})
+	s.Require().NoError(err)
+	s.False(wordpress.scanCalled, "scans naming their scanners add none")
+}
+
+func (s *FullScanTestSuite) TestFullScanHandler_HintsValidation() {
+	tool := New(s.logger, tools.NewRegistry(&mockScanner{name: "mock1", available: true})).(*Tool)
+
+	input := Input{ScannerInput: tools.ScannerInput{Host: "example.com"}, Hints: map[string]string{"cms": ""}}
+	_, _, err := tool.FullScanHandler(context.Background(), &mcp.CallToolRequest{}, input)
+	s.ErrorContains(err, "validation error")
+}
+
+func (s *FullScanTestSuite) TestFullScanHandler_Notify() {
+	received := make(chan notify.Event, 1)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event notify.Event
+		s.NoError(json.NewDecoder(r.Body).Decode(&event))
+		received <- event
+	}))
+	defer webhook.Close()
+
+	scanner := &mockScanner{name: "mock1", available: true, scanOutput: "[high] http://example.com/admin"}
+	tool := New(s.logger, tools.NewRegistry(scanner)).(*Tool)
+
+	input := Input{
+		ScannerInput: tools.ScannerInput{Host: "example.com"},
+		Notify:       &models.Notification{WebhookURL: webhook.URL, MinSeverity: types.SeverityHigh},
+		Template:     "weekly",
+	}
+	_, _, err := tool.FullScanHandler(context.Background(), &mcp.CallToolRequest{}, input)
+	s.Require().NoError(err)
+
+	select {
+	case event := <-received:
+		s.Equal("full_scan", event.Tool)
+		s.Equal("weekly", event.Template)
+		s.Equal("http://example.com", event.Target)
+		s.Equal(models.StatusCompleted, event.Status)
+		s.Equal(1, event.SeverityCounts[types.SeverityHigh])
+	case <-time.After(2 * time.Second):
+		s.Fail("expected a notification")
+	}
+
+	// Scans without a finding of the minimum severity are not notified.
+	scanner.scanOutput = "[low] http://example.com/x"
+	_, _, err = tool.FullScanHandler(context.Background(), &mcp.CallToolRequest{}, input)
+	s.Require().NoError(err)
+	select {
+	case event := <-received:
+		s.Failf("unexpected notification", "%+v", event)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	input.Notify.WebhookURL = "ftp://example.com/hook"
+	_, _, err = tool.FullScanHandler(context.Background(), &mcp.CallToolRequest{}, input)
+	s.ErrorContains(err, "validation error")
+}
+
+func (s *FullScanTestSuite) TestFullScanHandler_NotifyNewFindings() {
+	srv, cleanup := s.setupTestServer()
+	defer cleanup()
+	received := make(chan notify.Event, 1)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event notify.Event
+		s.NoError(json.NewDecoder(r.Body).Decode(&event))
+		received <- event
+	}))
+	defer webhook.Close()
+
+	scanner := &mockScanner{name: "mock1", available: true, scanOutput: "[high] http://example.com/admin"}
+	tool := New(s.logger, tools.NewRegistry(scanner)).(*Tool)
+	tool.storage = srv.Storage()
+	input := Input{
+		ScannerInput: tools.ScannerInput{Host: "example.com"},
+		Notify:       &models.Notification{WebhookURL: webhook.URL, NewFindingsOnly: true},
+	}
+	receive := func() notify.Event {
+		select {
+		case event := <-received:
+			return event
+		case <-time.After(2 * time.Second):
+			s.Fail("expected a notification")
+			return notify.Event{}
+		}
+	}
+
+	ctx := context.Background()
+	_, _, err := tool.FullScanHandler(ctx, &mcp.CallToolRequest{}, input)
+	s.Require().NoError(err)
+	first := receive()
+	s.Equal(notify.EventNewFindings, first.Type)
+	s.Require().Len(first.NewFindings, 1)
+	s.NotEmpty(first.NewFindings[0].Fingerprint)
+
+	// Store the first scan, as the execution logger does.
+	exec := &models.ToolExecution{ToolName: toolName, Target: "http://example.com"}
+	s.Require().NoError(srv.Storage().CreateToolExecution(ctx, exec))
+	first.NewFindings[0].ExecutionID = exec.ID
+	s.Require().NoError(srv.Storage().CreateFindings(ctx, first.NewFindings))
+
+	scanner.scanOutput = "[high] http://example.com/admin\n[medium] http://example.com/debug"
+	_, _, err = tool.FullScanHandler(ctx, &mcp.CallToolRequest{}, input)
+	s.Require().NoError(err)
+	second := receive()
+	s.Equal(1, second.TotalFindings)
+	s.Require().Len(second.NewFindings, 1)
+	s.Equal(types.SeverityMedium, second.NewFindings[0].Severity)
+
+	// Rescans finding nothing new are not notified.
+	scanner.scanOutput = "[high] http://example.com/admin"
+	_, _, err = tool.FullScanHandler(ctx, &mcp.CallToolRequest{}, input)
+	s.Require().NoError(err)
+	select {
+	case event := <-received:
+		s.Failf("unexpected notification", "%+v", event)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
 func (s *FullScanTestSuite) TestFullScanHandler_Success() {
 	scanner1 := &mockScanner{name: "scanner1", available: true, scanOutput: "findings from scanner1"}
 	scanner2 := &mockScanner{name: "scanner2", available: true, scanOutput: "findings from scanner2"}
 
-	tool := New(s.logger, scanner1, scanner2).(*Tool)
-	tool.scanners = []tools.Scanner{scanner1, scanner2}
+	tool := New(s.logger, tools.NewRegistry(scanner1, scanner2)).(*Tool)
 
 	ctx := context.Background()
 	req := &mcp.CallToolRequest{}
@@ -517,7 +1119,7 @@ func (s *FullScanTestSuite) TestFullScanHandler_Success() {
 		Port: 8080,
 	}
 
-	result, _, err := tool.FullScanHandler(ctx, req, input)
+	result, _, err := tool.FullScanHandler(ctx, req, Input{ScannerInput: input})
 	s.NoError(err)
 	s.NotNil(result)
 	s.Len(result.Content, 1)
@@ -530,16 +1132,72 @@ func (s *FullScanTestSuite) TestFullScanHandler_Success() {
 	s.Contains(textContent.Text, "findings from scanner2")
 }
 
+func (s *FullScanTestSuite) TestFullScanHandler_SummaryOnly() {
+	srv, cleanup := s.setupTestServer()
+	defer cleanup()
+
+	scanner1 := &mockScanner{name: "scanner1", available: true, scanOutput: "[high] http://example.com/admin\n[low] http://example.com/x"}
+	scanner2 := &mockScanner{name: "scanner2", available: true, scanError: errors.New("boom")}
+	tool := New(s.logger, tools.NewRegistry(scanner1, scanner2)).(*Tool)
+	tool.storage = srv.Storage()
+	handler := tools.WrapToolHandler(srv.Storage(), toolName, tool.FullScanHandler, tools.ServerWrapOptions(srv)...)
+
+	input := Input{ScannerInput: tools.ScannerInput{Host: "example.com"}, SummaryOnly: true}
+	result, _, err := handler(context.Background(), &mcp.CallToolRequest{}, input)
+	s.Require().NoError(err)
+
+	text := result.Content[0].(*mcp.TextContent).Text
+	s.Contains(text, "FULL SCAN SUMMARY")
+	s.Contains(text, "Target: http://example.com\n")
+	s.Contains(text, "  scanner1: SUCCESS")
+	s.Contains(text, "  scanner2: FAILED")
+	s.Contains(text, "Scanner runs: 2 | Successful: 1 | Failed: 1\n")
+	s.Contains(text, "Findings: 2 (critical: 0, high: 1, medium: 0, low: 1, info: 0)")
+	s.NotContains(text, "FULL SECURITY SCAN REPORT")
+	s.NotContains(text, "http://example.com/admin", "raw outputs are left out")
+
+	// The report is stored with the execution and read through the output cursor of the summary.
+	token, ok := result.Meta[tools.OutputCursorField].(string)
+	s.Require().True(ok)
+	s.Contains(text, fmt.Sprintf("continue_output {\"cursor\": %q}", token))
+	cursor, err := srv.Storage().GetOutputCursor(context.Background(), token)
+	s.Require().NoError(err)
+	s.Equal(0, cursor.Line)
+	s.Contains(text, fmt.Sprintf("summarize {\"id\": %d}", cursor.ExecutionID))
+	s.Contains(text, fmt.Sprintf("triage {\"action\": \"list\", \"execution_id\": %d}", cursor.ExecutionID))
+	s.Eventually(func() bool {
+		exec, err := srv.Storage().GetToolExecution(context.Background(), cursor.ExecutionID)
+		return err == nil && exec.Status == models.StatusCompleted && strings.Contains(exec.RawOutput, "FULL SECURITY SCAN REPORT")
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func (s *FullScanTestSuite) TestSummaryReport() {
+	ports := []portResults{
+		{Meta: reportMeta{TargetURL: "http://192.0.2.1"}, Groups: []vhostResults{
+			{Vhost: "a.example", Results: []scannerResult{{Name: "mock1", Duration: time.Second}}},
+		}},
+		{Meta: reportMeta{TargetURL: "https://192.0.2.1", Throttling: "Cloudflare WAF detected, rate_limit lowered to 2 requests/s"}, Groups: []vhostResults{
+			{Results: []scannerResult{{Name: "mock1", Error: tools.ErrScanTimedOut}}},
+		}},
+	}
+	report := summaryReport([]string{"Target: 192.0.2.1", "Ports: 80, 443"}, ports, nil, 0, "")
+	s.Contains(report, "  mock1 [http://192.0.2.1, vhost a.example]: SUCCESS (1.00s)\n")
+	s.Contains(report, "  mock1 [https://192.0.2.1]: TIMED OUT")
+	s.Contains(report, "Throttling [https://192.0.2.1]: Cloudflare WAF detected, rate_limit lowered to 2 requests/s\n")
+	s.Contains(report, "Scanner runs: 2 | Successful: 1 | Failed: 0 | Timed out: 1\n")
+	s.Contains(report, "Findings: 0 (")
+	s.Contains(report, "The full report was not stored", "scans outside the execution wrapper have no details")
+}
+
 func (s *FullScanTestSuite) TestFullScanHandler_DefaultsApplied() {
 	scanner := &mockScanner{name: "mock1", available: true, scanOutput: "test output"}
-	tool := New(s.logger, scanner).(*Tool)
-	tool.scanners = []tools.Scanner{scanner}
+	tool := New(s.logger, tools.NewRegistry(scanner)).(*Tool)
 
 	ctx := context.Background()
 	req := &mcp.CallToolRequest{}
 	input := tools.ScannerInput{} // All defaults.
 
-	result, _, err := tool.FullScanHandler(ctx, req, input)
+	result, _, err := tool.FullScanHandler(ctx, req, Input{ScannerInput: input})
 	s.NoError(err)
 	s.NotNil(result)
 
@@ -559,8 +1217,7 @@ func (s *FullScanTestSuite) TestFullScanHandler_WithPagination() {
 	output := strings.Join(lines, "\n")
 
 	scanner := &mockScanner{name: "mock1", available: true, scanOutput: output}
-	tool := New(s.logger, scanner).(*Tool)
-	tool.scanners = []tools.Scanner{scanner}
+	tool := New(s.logger, tools.NewRegistry(scanner)).(*Tool)
 
 	ctx := context.Background()
 	req := &mcp.CallToolRequest{}
@@ -571,7 +1228,7 @@ func (s *FullScanTestSuite) TestFullScanHandler_WithPagination() {
 		Offset:   10,
 	}
 
-	result, _, err := tool.FullScanHandler(ctx, req, input)
+	result, _, err := tool.FullScanHandler(ctx, req, Input{ScannerInput: input})
 	s.NoError(err)
 	s.NotNil(result)
 
@@ -579,10 +1236,60 @@ func (s *FullScanTestSuite) TestFullScanHandler_WithPagination() {
 	s.Contains(textContent.Text, "Showing lines")
 }
 
+func (s *FullScanTestSuite) TestFullScanHandler_CompressesLargeReport() {
+	scanner := &mockScanner{name: "mock1", available: true, scanOutput: strings.Repeat("finding line\n", 200)}
+	tool := New(s.logger, tools.NewRegistry(scanner)).(*Tool)
+	tool.compressThreshold = 1024
+	ctx := tools.WithCorrelationID(context.Background(), "abc123")
+
+	result, _, err := tool.FullScanHandler(ctx, &mcp.CallToolRequest{}, Input{ScannerInput: tools.ScannerInput{Host: "localhost"}})
+	s.Require().NoError(err)
+	s.Require().Len(result.Content, 2)
+	s.Contains(result.Content[0].(*mcp.TextContent).Text, "returned gzip-compressed")
+
+	resource := result.Content[1].(*mcp.EmbeddedResource).Resource
+	s.Equal("wass://output/full_scan/abc123.txt.gz", resource.URI)
+	s.Equal(tools.CompressedMIMEType, resource.MIMEType)
+	reader, err := gzip.NewReader(bytes.NewReader(resource.Blob))
+	s.Require().NoError(err)
+	report, err := io.ReadAll(reader)
+	s.Require().NoError(err)
+	s.Contains(string(report), "FULL SECURITY SCAN REPORT")
+	s.Contains(string(report), "finding line")
+	s.NotContains(result.Meta, tools.NextCursorField)
+
+	// Compressed pages keep the byte budget and are continued by cursor.
+	tool.maxResponseBytes = 2048
+	result, _, err = tool.FullScanHandler(ctx, &mcp.CallToolRequest{}, Input{ScannerInput: tools.ScannerInput{Host: "localhost"}})
+	s.Require().NoError(err)
+	s.Require().Len(result.Content, 2)
+	s.Contains(result.Meta, tools.NextCursorField)
+	reader, err = gzip.NewReader(bytes.NewReader(result.Content[1].(*mcp.EmbeddedResource).Resource.Blob))
+	s.Require().NoError(err)
+	report, err = io.ReadAll(reader)
+	s.Require().NoError(err)
+	s.LessOrEqual(len(report), 2048)
+
+	// Clients can opt out of compression.
+	input := Input{ScannerInput: tools.ScannerInput{Host: "localhost", Compression: tools.CompressionNone}}
+	result, _, err = tool.FullScanHandler(ctx, &mcp.CallToolRequest{}, input)
+	s.Require().NoError(err)
+	s.Len(result.Content, 1)
+}
+
+func (s *FullScanTestSuite) TestFullScanHandler_SmallReportNotCompressed() {
+	scanner := &mockScanner{name: "mock1", available: true, scanOutput: "finding"}
+	tool := New(s.logger, tools.NewRegistry(scanner)).(*Tool)
+	tool.compressThreshold = 1 << 20
+
+	result, _, err := tool.FullScanHandler(context.Background(), &mcp.CallToolRequest{}, Input{ScannerInput: tools.ScannerInput{Host: "localhost"}})
+	s.Require().NoError(err)
+	s.Len(result.Content, 1)
+}
+
 func (s *FullScanTestSuite) TestFullScanHandler_WithVhost() {
 	scanner := &mockScanner{name: "mock1", available: true, scanOutput: "test"}
-	tool := New(s.logger, scanner).(*Tool)
-	tool.scanners = []tools.Scanner{scanner}
+	tool := New(s.logger, tools.NewRegistry(scanner)).(*Tool)
 
 	ctx := context.Background()
 	req := &mcp.CallToolRequest{}
@@ -592,7 +1299,7 @@ func (s *FullScanTestSuite) TestFullScanHandler_WithVhost() {
 		Vhost: "example.com",
 	}
 
-	result, _, err := tool.FullScanHandler(ctx, req, input)
+	result, _, err := tool.FullScanHandler(ctx, req, Input{ScannerInput: input})
 	s.NoError(err)
 	s.NotNil(result)
 
@@ -607,15 +1314,14 @@ func (s *FullScanTestSuite) TestFullScanHandler_WithScannerError() {
 		scanOutput: "partial output",
 		scanError:  errors.New("scan failed"),
 	}
-	tool := New(s.logger, scanner).(*Tool)
-	tool.scanners = []tools.Scanner{scanner}
+	tool := New(s.logger, tools.NewRegistry(scanner)).(*Tool)
 
 	ctx := context.Background()
 	req := &mcp.CallToolRequest{}
 	input := tools.ScannerInput{Host: "localhost", Port: 80}
 
 	// Handler should still return results even if scanner fails.
-	result, _, err := tool.FullScanHandler(ctx, req, input)
+	result, _, err := tool.FullScanHandler(ctx, req, Input{ScannerInput: input})
 	s.NoError(err)
 	s.NotNil(result)
 
@@ -624,6 +1330,404 @@ func (s *FullScanTestSuite) TestFullScanHandler_WithScannerError() {
 	s.Contains(textContent.Text, "scan failed")
 }
 
+func (s *FullScanTestSuite) TestFullScanHandler_WithVhosts() {
+	scanner := &mockScanner{name: "mock1", available: true, scanOutput: "test"}
+	tool := New(s.logger, tools.NewRegistry(scanner)).(*Tool)
+
+	ctx := context.Background()
+	req := &mcp.CallToolRequest{}
+	input := tools.ScannerInput{
+		Host:   "192.168.1.1",
+		Port:   8080,
+		Vhosts: []string{"a.example.com", "b.example.com"},
+	}
+
+	result, _, err := tool.FullScanHandler(ctx, req, Input{ScannerInput: input})
+	s.NoError(err)
+	s.NotNil(result)
+
+	textContent := result.Content[0].(*mcp.TextContent)
+	s.Contains(textContent.Text, "VHOST: a.example.com")
+	s.Contains(textContent.Text, "VHOST: b.example.com")
+	s.Equal(1, strings.Count(textContent.Text, "FULL SECURITY SCAN REPORT"))
+	s.Equal(2, strings.Count(textContent.Text, "SCAN SUMMARY"))
+
+	// Last scanned vhost is the final one in the list.
+	s.Equal("b.example.com", scanner.scanParams.Vhost)
+}
+
+func (s *FullScanTestSuite) TestFullScanHandler_WithPorts() {
+	scanner := &recordingScanner{mockScanner: mockScanner{name: "mock1", available: true}}
+	tool := New(s.logger, tools.NewRegistry(scanner)).(*Tool)
+
+	input := Input{
+		ScannerInput: tools.ScannerInput{Host: "192.168.1.1"},
+		Ports:        []int{80, 443, 8080, 443},
+	}
+
+	result, _, err := tool.FullScanHandler(context.Background(), &mcp.CallToolRequest{}, input)
+	s.Require().NoError(err)
+
+	s.ElementsMatch([]int{80, 443, 8080}, scanner.ports)
+
+	text := result.Content[0].(*mcp.TextContent).Text
+	s.Contains(text, "Ports: 80, 443, 8080")
+	s.Contains(text, "PORT: 80 (http://192.168.1.1)")
+	s.Contains(text, "PORT: 443 (https://192.168.1.1)")
+	s.Contains(text, "PORT: 8080 (http://192.168.1.1:8080)")
+	s.Contains(text, "mock1 on https://192.168.1.1")
+	s.Equal(3, strings.Count(text, "SCAN SUMMARY"))
+	// Port sections are rendered in the requested order.
+	s.Less(strings.Index(text, "PORT: 80 "), strings.Index(text, "PORT: 443 "))
+	s.Less(strings.Index(text, "PORT: 443 "), strings.Index(text, "PORT: 8080 "))
+
+	sections := findings.Sections(toolName, text)
+	s.Len(sections, 3)
+}
+
+func (s *FullScanTestSuite) TestFullScanHandler_PortsShareLimiter() {
+	scanner1 := &recordingScanner{mockScanner: mockScanner{name: "mock1", available: true, scanDelay: 20 * time.Millisecond}}
+	scanner2 := &recordingScanner{mockScanner: mockScanner{name: "mock2", available: true, scanDelay: 20 * time.Millisecond}}
+	tool := New(s.logger, tools.NewRegistry(scanner1, scanner2)).(*Tool)
+	tool.limiter = limiter.New(1)
+
+	input := Input{ScannerInput: tools.ScannerInput{Host: "192.168.1.1"}, Ports: []int{80, 8080}}
+	_, _, err := tool.FullScanHandler(context.Background(), &mcp.CallToolRequest{}, input)
+	s.Require().NoError(err)
+
+	s.Len(scanner1.ports, 2)
+	s.Len(scanner2.ports, 2)
+	s.Equal(1, scanner1.peak)
+	s.Equal(1, scanner2.peak)
+}
+
+func (s *FullScanTestSuite) TestFullScanHandler_SkipsDisabledScanners() {
+	scanner1 := &mockScanner{name: "mock1", available: true, scanOutput: "mock1 output"}
+	scanner2 := &mockScanner{name: "mock2", available: true, scanOutput: "mock2 output"}
+	tool := New(s.logger, tools.NewRegistry(scanner1, scanner2)).(*Tool)
+	tool.enabled = func(name string) bool { return name != "mock2" }
+
+	result, _, err := tool.FullScanHandler(context.Background(), &mcp.CallToolRequest{}, Input{ScannerInput: tools.ScannerInput{Host: "192.168.1.1"}})
+	s.Require().NoError(err)
+
+	text := result.Content[0].(*mcp.TextContent).Text
+	s.Contains(text, "mock1 output")
+	s.NotContains(text, "mock2")
+}
+
+func (s *FullScanTestSuite) TestRunScannersParallel_RecordsMetrics() {
+	failing := &mockScanner{name: "mock1", available: true, scanError: errors.New("scan failed")}
+	succeeding := &mockScanner{name: "mock2", available: true, scanOutput: "ok"}
+	tool := New(s.logger, tools.NewRegistry(failing, succeeding)).(*Tool)
+	tool.metrics = metrics.New()
+
+	params := tools.ScanParams{Host: "192.168.1.1", Port: 80, Scheme: "http"}
+	tool.runScannersParallel(context.Background(), params, nil)
+	tool.runScannersParallel(context.Background(), params, nil)
+
+	s.Equal(2, tool.metrics.ConsecutiveFailures("mock1"))
+	s.Equal(2, tool.metrics.TargetFailures("mock1", "http://192.168.1.1"))
+	s.Equal(0, tool.metrics.ConsecutiveFailures("mock2"))
+}
+
+func (s *FullScanTestSuite) TestFullScanHandler_AllScannersDisabled() {
+	tool := New(s.logger, tools.NewRegistry(&mockScanner{name: "mock1", available: true})).(*Tool)
+	tool.enabled = func(string) bool { return false }
+
+	_, _, err := tool.FullScanHandler(context.Background(), &mcp.CallToolRequest{}, Input{ScannerInput: tools.ScannerInput{Host: "192.168.1.1"}})
+	s.ErrorContains(err, "all scanners are disabled")
+}
+
+// fakePortScanner is a discovery.PortScanner returning fixed open ports.
+type fakePortScanner struct {
+	open []int
+}
+
+func (f *fakePortScanner) Name() string { return "fakescan" }
+
+func (f *fakePortScanner) IsAvailable() bool { return true }
+
+func (f *fakePortScanner) OpenPorts(_ context.Context, _ string, _ []int) ([]int, error) {
+	return f.open, nil
+}
+
+func (s *FullScanTestSuite) TestFullScanHandler_DiscoverPorts() {
+	scanner := &recordingScanner{mockScanner: mockScanner{name: "mock1", available: true}}
+	tool := New(s.logger, tools.NewRegistry(scanner)).(*Tool)
+	tool.discoverer = &discovery.Discoverer{
+		Probe: func(_ context.Context, _ string, port int) (string, bool) {
+			switch port {
+			case 8443:
+				return "https", true
+			case 8080:
+				return "http", true
+			default:
+				return "", false
+			}
+		},
+		Scanners: []discovery.PortScanner{&fakePortScanner{open: []int{22, 8080, 8443}}},
+	}
+
+	input := Input{ScannerInput: tools.ScannerInput{Host: "192.168.1.1"}, DiscoverPorts: true}
+	result, _, err := tool.FullScanHandler(context.Background(), &mcp.CallToolRequest{}, input)
+	s.Require().NoError(err)
+
+	s.ElementsMatch([]int{8080, 8443}, scanner.ports)
+
+	text := result.Content[0].(*mcp.TextContent).Text
+	s.Contains(text, "Ports: 8080, 8443")
+	s.Contains(text, "Discovered by: fakescan (open ports: 22, 8080, 8443)")
+	s.Contains(text, "PORT: 8443 (https://192.168.1.1:8443)")
+	s.Contains(text, "mock1 on https://192.168.1.1:8443")
+	s.Contains(text, "PORT: 8080 (http://192.168.1.1:8080)")
+}
+
+func (s *FullScanTestSuite) TestFullScanHandler_DiscoverPortsNoServices() {
+	scanner := &recordingScanner{mockScanner: mockScanner{name: "mock1", available: true}}
+	tool := New(s.logger, tools.NewRegistry(scanner)).(*Tool)
+	tool.discoverer = &discovery.Discoverer{
+		Probe:    func(_ context.Context, _ string, _ int) (string, bool) { return "", false },
+		Scanners: []discovery.PortScanner{&fakePortScanner{open: []int{22}}},
+	}
+
+	input := Input{ScannerInput: tools.ScannerInput{Host: "192.168.1.1"}, DiscoverPorts: true}
+	_, _, err := tool.FullScanHandler(context.Background(), &mcp.CallToolRequest{}, input)
+	s.Require().Error(err)
+	s.Contains(err.Error(), "no HTTP(S) services discovered on 192.168.1.1 (open ports: 22)")
+	s.Empty(scanner.ports)
+}
+
+func (s *FullScanTestSuite) TestFullScanHandler_Scope() {
+	scanner := &recordingScanner{mockScanner: mockScanner{name: "mock1", available: true}}
+	tool := New(s.logger, tools.NewRegistry(scanner)).(*Tool)
+	policy, err := scope.New("192.168.1.0/24:8000-8999")
+	s.Require().NoError(err)
+	tool.scope = policy
+
+	input := Input{ScannerInput: tools.ScannerInput{Host: "192.168.1.1"}, Ports: []int{8080, 443}}
+	_, _, err = tool.FullScanHandler(context.Background(), &mcp.CallToolRequest{}, input)
+	s.ErrorIs(err, scope.ErrOutOfScope)
+	s.ErrorContains(err, "192.168.1.1:443")
+	s.Empty(scanner.ports)
+
+	input.Ports = []int{8080}
+	_, _, err = tool.FullScanHandler(context.Background(), &mcp.CallToolRequest{}, input)
+	s.Require().NoError(err)
+	s.Equal([]int{8080}, scanner.ports)
+}
+
+func (s *FullScanTestSuite) TestFullScanHandler_RedirectOutOfScope() {
+	final := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer final.Close()
+	start := httptest.NewServer(http.RedirectHandler(final.URL, http.StatusMovedPermanently))
+	defer start.Close()
+	startPort, err := strconv.Atoi(start.URL[strings.LastIndex(start.URL, ":")+1:])
+	s.Require().NoError(err)
+	finalPort, err := strconv.Atoi(final.URL[strings.LastIndex(final.URL, ":")+1:])
+	s.Require().NoError(err)
+
+	scanner := &recordingScanner{mockScanner: mockScanner{name: "mock1", available: true}}
+	tool := New(s.logger, tools.NewRegistry(scanner)).(*Tool)
+	tool.scope, err = scope.New(fmt.Sprintf("127.0.0.1:%d", startPort))
+	s.Require().NoError(err)
+
+	input := Input{ScannerInput: tools.ScannerInput{Host: "127.0.0.1", Port: startPort, FollowRedirects: true}}
+	_, _, err = tool.FullScanHandler(context.Background(), &mcp.CallToolRequest{}, input)
+	s.ErrorIs(err, scope.ErrOutOfScope, "full_scan refuses redirects out of scope like the scanner tools")
+	s.ErrorContains(err, "effective target "+final.URL)
+	s.Empty(scanner.ports)
+
+	tool.scope, err = scope.New(fmt.Sprintf("127.0.0.1:%d", startPort), fmt.Sprintf("127.0.0.1:%d", finalPort))
+	s.Require().NoError(err)
+	result, _, err := tool.FullScanHandler(context.Background(), &mcp.CallToolRequest{}, input)
+	s.Require().NoError(err)
+	s.Equal([]int{finalPort}, scanner.ports)
+	s.Contains(result.Content[0].(*mcp.TextContent).Text, "Requested target: "+start.URL+" (redirected)")
+}
+
+func (s *FullScanTestSuite) TestFullScanHandler_DiscoverPortsScope() {
+	scanner := &recordingScanner{mockScanner: mockScanner{name: "mock1", available: true}}
+	tool := New(s.logger, tools.NewRegistry(scanner)).(*Tool)
+	tool.discoverer = &discovery.Discoverer{
+		Probe: func(_ context.Context, _ string, port int) (string, bool) {
+			return "http", port != 22
+		},
+		Scanners: []discovery.PortScanner{&fakePortScanner{open: []int{22, 80, 8080}}},
+	}
+	policy, err := scope.New("192.168.1.1:8000-8999")
+	s.Require().NoError(err)
+	tool.scope = policy
+
+	input := Input{ScannerInput: tools.ScannerInput{Host: "192.168.1.1"}, DiscoverPorts: true}
+	result, _, err := tool.FullScanHandler(context.Background(), &mcp.CallToolRequest{}, input)
+	s.Require().NoError(err)
+	s.Equal([]int{8080}, scanner.ports)
+	s.Contains(result.Content[0].(*mcp.TextContent).Text, "Ports: 8080")
+
+	tool.scope, err = scope.New("192.168.1.1:9000")
+	s.Require().NoError(err)
+	_, _, err = tool.FullScanHandler(context.Background(), &mcp.CallToolRequest{}, input)
+	s.ErrorContains(err, "no HTTP(S) service discovered on 192.168.1.1 is in scope (ports: 80, 8080)")
+
+	input.Host = "10.0.0.1"
+	_, _, err = tool.FullScanHandler(context.Background(), &mcp.CallToolRequest{}, input)
+	s.ErrorIs(err, scope.ErrOutOfScope)
+}
+
+func (s *FullScanTestSuite) TestFullScanHandler_DiscoverPortsNoPortScanner() {
+	tool := New(s.logger, tools.NewRegistry(&mockScanner{name: "mock1", available: true})).(*Tool)
+	tool.discoverer = &discovery.Discoverer{}
+
+	input := Input{ScannerInput: tools.ScannerInput{Host: "192.168.1.1"}, DiscoverPorts: true}
+	_, _, err := tool.FullScanHandler(context.Background(), &mcp.CallToolRequest{}, input)
+	s.Require().Error(err)
+	s.ErrorIs(err, discovery.ErrNoPortScanner)
+}
+
+func (s *FullScanTestSuite) TestFullScanHandler_InvalidPorts() {
+	tool := New(s.logger, tools.NewRegistry(&mockScanner{name: "mock1", available: true})).(*Tool)
+
+	input := Input{ScannerInput: tools.ScannerInput{Host: "192.168.1.1"}, Ports: []int{80, 70000}}
+	_, _, err := tool.FullScanHandler(context.Background(), &mcp.CallToolRequest{}, input)
+	s.Error(err)
+	s.Contains(err.Error(), "validation error")
+}
+
+func (s *FullScanTestSuite) TestInput_ScanTarget() {
+	input := Input{ScannerInput: tools.ScannerInput{Host: "example.com"}, Ports: []int{443, 80}}
+	target := input.ScanTarget()
+	s.Equal(443, target.Port)
+	s.Equal("https", target.Scheme)
+
+	s.Equal(80, Input{ScannerInput: tools.ScannerInput{Host: "example.com"}}.ScanTarget().Port)
+}
+
+func (s *FullScanTestSuite) TestUniquePorts() {
+	s.Equal([]int{443, 80}, uniquePorts([]int{443, 80, 443, 80}))
+	s.Empty(uniquePorts(nil))
+}
+
+func (s *FullScanTestSuite) TestMergeVhostResults() {
+	tool := New(s.logger, tools.NewRegistry()).(*Tool)
+
+	groups := []vhostResults{
+		{Vhost: "a.example.com", Results: []scannerResult{{Name: "scanner1", Output: "findings a"}}},
+		{Vhost: "b.example.com", Results: []scannerResult{{Name: "scanner1", Error: errors.New("refused")}}},
+	}
+
+	merged := tool.mergeVhostResults(reportMeta{TargetURL: "http://10.0.0.1"}, groups)
+
+	s.Contains(merged, "VHOST: a.example.com")
+	s.Contains(merged, "findings a")
+	s.Contains(merged, "VHOST: b.example.com")
+	s.Contains(merged, "refused")
+	s.Contains(merged, "END OF REPORT")
+}
+
+func (s *FullScanTestSuite) TestMergeVhostResults_RequestedURL() {
+	tool := New(s.logger, tools.NewRegistry()).(*Tool)
+
+	meta := reportMeta{TargetURL: "https://www.example.com", RequestedURL: "http://example.com"}
+	merged := tool.mergeVhostResults(meta, []vhostResults{{Results: []scannerResult{{Name: "scanner1"}}}})
+
+	s.Contains(merged, "Target: https://www.example.com")
+	s.Contains(merged, "Requested target: http://example.com (redirected)")
+}
+
+func (s *FullScanTestSuite) TestFullScanHandler_AdaptiveRate() {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+	port, err := strconv.Atoi(srv.URL[strings.LastIndex(srv.URL, ":")+1:])
+	s.Require().NoError(err)
+
+	scanner := &rateScanner{mockScanner: mockScanner{name: "mock1", available: true}}
+	tool := New(s.logger, tools.NewRegistry(scanner)).(*Tool)
+	input := Input{ScannerInput: tools.ScannerInput{
+		Host:    "127.0.0.1",
+		Options: map[string]string{tools.OptionRateLimit: "50"},
+		Port:    port,
+	}}
+
+	// Targets are not probed unless requested.
+	result, _, err := tool.FullScanHandler(context.Background(), &mcp.CallToolRequest{}, input)
+	s.Require().NoError(err)
+	s.Zero(requests.Load())
+	s.Equal("50", scanner.scanParams.Option(tools.OptionRateLimit))
+	s.NotContains(result.Content[0].(*mcp.TextContent).Text, "Throttling:")
+
+	input.AdaptiveRate = true
+	result, _, err = tool.FullScanHandler(context.Background(), &mcp.CallToolRequest{}, input)
+	s.Require().NoError(err)
+	s.Positive(requests.Load())
+	s.Equal("2", scanner.scanParams.Option(tools.OptionRateLimit))
+	s.Equal("50", input.Options[tools.OptionRateLimit], "the input options are left untouched")
+	s.Contains(result.Content[0].(*mcp.TextContent).Text,
+		"Throttling: 429 Too Many Requests, rate_limit lowered to 2 requests/s")
+}
+
+func (s *FullScanTestSuite) TestFullScanHandler_CrawlFirst() {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		if r.URL.Path == "/" {
+			_, _ = w.Write([]byte(`<a href="/login">Login</a> <a href="/search?q=1">Search</a>`))
+		}
+	}))
+	defer srv.Close()
+	port, err := strconv.Atoi(srv.URL[strings.LastIndex(srv.URL, ":")+1:])
+	s.Require().NoError(err)
+
+	lister := &listScanner{mockScanner: mockScanner{name: "lister", available: true}}
+	other := &mockScanner{name: "other", available: true}
+	tool := New(s.logger, tools.NewRegistry(lister, other)).(*Tool)
+	tool.crawler = &crawl.Crawler{Engines: []crawl.Engine{crawl.NewBuiltin()}}
+	input := Input{ScannerInput: tools.ScannerInput{Host: "127.0.0.1", Port: port}}
+
+	// Targets are not crawled unless requested.
+	_, _, err = tool.FullScanHandler(context.Background(), &mcp.CallToolRequest{}, input)
+	s.Require().NoError(err)
+	s.Empty(lister.scanParams.URLs)
+
+	input.CrawlFirst = true
+	result, _, err := tool.FullScanHandler(context.Background(), &mcp.CallToolRequest{}, input)
+	s.Require().NoError(err)
+	s.Equal([]string{srv.URL + "/", srv.URL + "/login", srv.URL + "/search?q=1"}, lister.scanParams.URLs)
+	s.Empty(other.scanParams.URLs, "scanners without URL list support scan the target only")
+	text := result.Content[0].(*mcp.TextContent).Text
+	s.Contains(text, "Crawl: 3 URLs found by builtin")
+	s.Contains(text, "Ignored unsupported options: urls")
+
+	// Unreachable targets are scanned without a URL list.
+	srv.Close()
+	result, _, err = tool.FullScanHandler(context.Background(), &mcp.CallToolRequest{}, input)
+	s.Require().NoError(err)
+	s.Empty(lister.scanParams.URLs)
+	s.Contains(result.Content[0].(*mcp.TextContent).Text, "Crawl: failed: builtin crawl failed")
+}
+
+func (s *FullScanTestSuite) TestReportBranding() {
+	tool := New(s.logger, tools.NewRegistry()).(*Tool)
+	tool.branding = models.ReportBranding{Organization: "Example Security", Assessor: "J. Doe", Banner: "CONFIDENTIAL"}
+
+	branding := tool.branding.Merge(&models.ReportBranding{EngagementID: "ENG-42", Assessor: "A. Smith"})
+	merged := tool.mergeVhostResults(reportMeta{TargetURL: "http://10.0.0.1", Branding: branding},
+		[]vhostResults{{Results: []scannerResult{{Name: "scanner1"}}}})
+
+	s.True(strings.HasPrefix(merged, strings.Repeat(" ", 33)+"CONFIDENTIAL\n"), "the banner opens the report")
+	s.True(strings.HasSuffix(merged, "END OF REPORT\n"+"="+strings.Repeat("=", reportLineWidth)+"\n"+
+		strings.Repeat(" ", 33)+"CONFIDENTIAL\n"), "the banner closes the report")
+	s.Contains(merged, "Organization: Example Security\nEngagement ID: ENG-42\nAssessor: A. Smith\nTarget: http://10.0.0.1\n")
+
+	merged = tool.mergePortResults(models.ReportBranding{}, "10.0.0.1", []portResults{{Port: 80}})
+	s.NotContains(merged, "Organization:")
+	s.True(strings.HasPrefix(merged, "="), "reports without a banner start with their title")
+}
+
 func TestFullScanTestSuite(t *testing.T) {
 	suite.Run(t, new(FullScanTestSuite))
 }
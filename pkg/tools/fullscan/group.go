@@ -0,0 +1,163 @@
+package fullscan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/tb0hdan/wass-mcp/pkg/findings"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+	"gorm.io/gorm"
+)
+
+// groupHosts returns the hosts of the named target group. A resumed group scan scans the hosts of
+// the paused one, even when the group changed since.
+func (t *Tool) groupHosts(ctx context.Context, name string, paused *models.ToolExecution) ([]string, error) {
+	if paused != nil {
+		if found := hosts(paused.ScanState); len(found) > 0 {
+			return found, nil
+		}
+	}
+	if t.storage == nil {
+		return nil, fmt.Errorf("target group %s: no target group storage", name)
+	}
+
+	group, err := t.storage.GetTargetGroup(ctx, name)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("target group %s not found", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load target group %s: %w", name, err)
+	}
+	if len(group.Hosts) == 0 {
+		return nil, fmt.Errorf("target group %s has no hosts", name)
+	}
+
+	return group.Hosts, nil
+}
+
+// scanGroup scans every host in parallel with the rest of input, bounded by the shared scan
+// limiter, and returns their results in the order of hosts.
+func (t *Tool) scanGroup(ctx context.Context, input Input, hosts []string, previous resumeState) []hostResults {
+	results := make([]hostResults, len(hosts))
+	var waitGroup sync.WaitGroup
+	for i, host := range hosts {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			results[i] = t.scanHost(ctx, input, host, previous)
+			if results[i].Error != nil {
+				logger := tools.ContextLogger(ctx, t.logger)
+				logger.Warn().Err(results[i].Error).Msgf("Skipping %s of target group %s", host, input.Group)
+			}
+		}()
+	}
+	waitGroup.Wait()
+
+	return results
+}
+
+// hostSummary aggregates the scanner runs and findings of a host of a group scan.
+type hostSummary struct {
+	failed     int
+	findings   []models.Finding
+	held       int
+	successful int
+	timedOut   int
+}
+
+// summarizeHost counts the runs of host by outcome and gathers its findings.
+func summarizeHost(host hostResults) hostSummary {
+	var summary hostSummary
+	for _, port := range host.Ports {
+		for _, group := range port.Groups {
+			for _, result := range group.Results {
+				switch result.status() {
+				case statusHeld:
+					summary.held++
+				case statusTimedOut:
+					summary.timedOut++
+				case statusFailed:
+					summary.failed++
+				default:
+					summary.successful++
+				}
+			}
+		}
+		summary.findings = append(summary.findings, collectFindings(port.Groups)...)
+	}
+
+	return summary
+}
+
+// groupLines returns the header lines of the report of the hosts of a target group.
+func groupLines(group string, hosts []hostResults) []string {
+	names := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		names = append(names, host.Host)
+	}
+
+	return []string{
+		fmt.Sprintf("Target group: %s", group),
+		fmt.Sprintf("Targets: %s", strings.Join(names, ", ")),
+	}
+}
+
+// writeGroupReport writes the report of several hosts to w, below the given header lines: a
+// group summary with the outcome, findings and risk score of each host, then one section per
+// host, naming the execution a host was logged as, if any.
+func (t *Tool) writeGroupReport(w *reportWriter, branding models.ReportBranding, headerLines []string, hosts []hostResults) {
+	t.writeHeader(w, branding, headerLines)
+	t.writeGroupSummary(w, hosts)
+
+	for _, host := range hosts {
+		w.banner("HOST: " + host.Host)
+		if host.ExecutionID != 0 {
+			w.printf("Execution: %d\n\n", host.ExecutionID)
+		}
+		if host.Error != nil {
+			w.printf("ERROR: %s\n\n", host.Error.Error())
+			continue
+		}
+		if host.Discovered.Scanner != "" {
+			w.line(discoveryLine(host.Discovered) + "\n")
+		}
+		t.writePorts(w, host.Ports)
+	}
+	t.writeFooter(w, branding)
+}
+
+// writeGroupSummary writes the per-host outcome of a group scan and the group totals.
+func (t *Tool) writeGroupSummary(w *reportWriter, hosts []hostResults) {
+	w.write("GROUP SUMMARY\n")
+	w.write(dashLine)
+
+	var all []models.Finding
+	failedHosts := 0
+	for _, host := range hosts {
+		if host.Error != nil {
+			failedHosts++
+			w.printf("  %s: ERROR\n", host.Host)
+			continue
+		}
+		summary := summarizeHost(host)
+		all = append(all, summary.findings...)
+		w.printf("  %s: Successful: %d | Failed: %d", host.Host, summary.successful, summary.failed)
+		if summary.timedOut > 0 {
+			w.printf(" | Timed out: %d", summary.timedOut)
+		}
+		if summary.held > 0 {
+			w.printf(" | Held: %d", summary.held)
+		}
+		w.printf(" | Findings: %d | Risk score: %.1f\n",
+			len(summary.findings), findings.ScoreFindings(summary.findings))
+	}
+
+	w.printf("\nTotal hosts: %d | Scanned: %d | Failed: %d\n", len(hosts), len(hosts)-failedHosts, failedHosts)
+	w.printf("Total findings: %d (%s) | Risk score: %.1f\n",
+		len(all), severityCounts(all), findings.ScoreFindings(all))
+	w.write("\n")
+}
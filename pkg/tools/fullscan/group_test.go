@@ -0,0 +1,167 @@
+package fullscan
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/discovery"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/server"
+	"github.com/tb0hdan/wass-mcp/pkg/storage"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+)
+
+// hostScanner is a mock scanner reporting a high finding on every scanned URL and recording them.
+type hostScanner struct {
+	mockScanner
+	mu      sync.Mutex
+	scanned []string
+}
+
+func (h *hostScanner) Scan(_ context.Context, params tools.ScanParams) tools.ScanResult {
+	url := params.Target().URL()
+	h.mu.Lock()
+	h.scanned = append(h.scanned, url)
+	h.mu.Unlock()
+
+	return tools.ScanResult{Output: fmt.Sprintf("[high] %s/admin", url)}
+}
+
+type GroupTestSuite struct {
+	suite.Suite
+	cleanup func()
+	scanner *hostScanner
+	srv     *server.Server
+	tool    *Tool
+}
+
+func (s *GroupTestSuite) SetupTest() {
+	tmpFile, err := os.CreateTemp("", "fullscan-group-*.db")
+	s.Require().NoError(err)
+	tmpFile.Close()
+
+	store, err := storage.NewSQLiteStorage(storage.Config{DatabasePath: tmpFile.Name()})
+	s.Require().NoError(err)
+	s.srv = server.NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}, store)
+	s.cleanup = func() {
+		s.srv.Shutdown(context.Background())
+		os.Remove(tmpFile.Name())
+	}
+
+	s.scanner = &hostScanner{mockScanner: mockScanner{name: "mock1", available: true}}
+	s.tool = New(zerolog.Nop(), tools.NewRegistry(s.scanner)).(*Tool)
+	s.Require().NoError(s.tool.Register(s.srv))
+	s.Require().NoError(store.SaveTargetGroup(context.Background(), &models.TargetGroup{
+		Name:  "staging",
+		Hosts: []string{"a.example.com", "https://b.example.com:8443"},
+	}))
+}
+
+func (s *GroupTestSuite) TearDownTest() {
+	s.cleanup()
+}
+
+func (s *GroupTestSuite) TestGroupScan() {
+	handler := tools.WrapToolHandler(s.srv.Storage(), toolName, s.tool.FullScanHandler)
+
+	result, _, err := handler(context.Background(), &mcp.CallToolRequest{}, Input{Group: "staging"})
+	s.Require().NoError(err)
+	s.ElementsMatch([]string{"http://a.example.com", "https://b.example.com:8443"}, s.scanner.scanned)
+
+	text := result.Content[0].(*mcp.TextContent).Text
+	s.Contains(text, "Target group: staging")
+	s.Contains(text, "Targets: a.example.com, https://b.example.com:8443")
+	s.Contains(text, "GROUP SUMMARY")
+	s.Contains(text, "  a.example.com: Successful: 1 | Failed: 0 | Findings: 1 | Risk score: 5.0")
+	s.Contains(text, "Total hosts: 2 | Scanned: 2 | Failed: 0")
+	s.Contains(text, "Total findings: 2 (critical: 0, high: 2, medium: 0, low: 0, info: 0) | Risk score: 10.0")
+	s.Contains(text, "HOST: https://b.example.com:8443")
+	s.Contains(text, "PORT: 8443 (https://b.example.com:8443)")
+
+	var exec models.ToolExecution
+	s.Require().Eventually(func() bool {
+		executions, err := s.srv.Storage().GetToolExecutionsByTool(context.Background(), toolName, 1)
+		if err != nil || len(executions) == 0 || executions[0].Status != models.StatusCompleted {
+			return false
+		}
+		exec = executions[0]
+		return true
+	}, 2*time.Second, 10*time.Millisecond)
+	s.Equal(tools.GroupTarget("staging"), exec.Target)
+	s.Empty(exec.Host)
+	s.Contains(exec.InputJSON, `"group":"staging"`)
+}
+
+func (s *GroupTestSuite) TestGroupScan_HostFailure() {
+	s.tool.discoverer = &discovery.Discoverer{
+		Probe: func(_ context.Context, host string, _ int) (string, bool) {
+			return "http", host == "a.example.com"
+		},
+		Scanners: []discovery.PortScanner{&fakePortScanner{open: []int{8080}}},
+	}
+
+	result, _, err := s.tool.FullScanHandler(context.Background(), &mcp.CallToolRequest{}, Input{Group: "staging", DiscoverPorts: true})
+	s.Require().NoError(err)
+	s.Equal([]string{"http://a.example.com:8080"}, s.scanner.scanned)
+
+	text := result.Content[0].(*mcp.TextContent).Text
+	s.Contains(text, "  https://b.example.com:8443: ERROR")
+	s.Contains(text, "Total hosts: 2 | Scanned: 1 | Failed: 1")
+	s.Contains(text, "ERROR: no HTTP(S) services discovered on b.example.com")
+	s.Contains(text, "Discovered by: fakescan (open ports: 8080)")
+}
+
+func (s *GroupTestSuite) TestGroupScan_Invalid() {
+	_, _, err := s.tool.FullScanHandler(context.Background(), &mcp.CallToolRequest{},
+		Input{Group: "staging", ScannerInput: tools.ScannerInput{Host: "example.com"}})
+	s.ErrorContains(err, "group and host are mutually exclusive")
+
+	_, _, err = s.tool.FullScanHandler(context.Background(), &mcp.CallToolRequest{}, Input{Group: "missing"})
+	s.ErrorContains(err, "target group missing not found")
+	s.Empty(s.scanner.scanned)
+}
+
+func (s *GroupTestSuite) TestGroupHosts_Resumed() {
+	paused := &models.ToolExecution{ScanState: []models.ScannerState{
+		{Host: "a.example.com", Port: 80, Scanner: "mock1", Status: models.ScannerCompleted},
+		{Host: "c.example.com", Port: 80, Scanner: "mock1", Status: models.ScannerHeld},
+	}}
+
+	found, err := s.tool.groupHosts(context.Background(), "staging", paused)
+	s.Require().NoError(err)
+	s.Equal([]string{"a.example.com", "c.example.com"}, found)
+
+	found, err = s.tool.groupHosts(context.Background(), "staging", nil)
+	s.Require().NoError(err)
+	s.Equal([]string{"a.example.com", "https://b.example.com:8443"}, found)
+}
+
+func (s *GroupTestSuite) TestResumeState_ForHost() {
+	previous := newResumeState([]models.ScannerState{
+		{Host: "a.example.com", Port: 80, Scanner: "mock1", Output: "a"},
+		{Host: "b.example.com", Port: 80, Scanner: "mock1", Output: "b"},
+		{Port: 443, Scanner: "mock1", Output: "legacy"},
+	})
+
+	scoped := previous.forHost("b.example.com")
+	result, ok := scoped.lookup(80, "", "mock1")
+	s.Require().True(ok)
+	s.Equal("b", result.Output)
+	result, ok = scoped.lookup(443, "", "mock1")
+	s.Require().True(ok)
+	s.Equal("legacy", result.Output)
+
+	var none resumeState
+	s.Nil(none.forHost("a.example.com"))
+}
+
+func TestGroupTestSuite(t *testing.T) {
+	suite.Run(t, new(GroupTestSuite))
+}
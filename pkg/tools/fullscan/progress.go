@@ -0,0 +1,133 @@
+package fullscan
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+)
+
+// progressKey holds the progress reporter of a full scan.
+type progressKey struct{}
+
+// progress reports the scanner runs of a full scan to the client as MCP progress notifications,
+// when the client asked for them with a progress token. Each run counts two steps, its start and
+// its end, so that every notification advances the progress as the protocol requires. The total
+// grows as stages are planned, e.g. once auto mode selected its scanners. It is safe for
+// concurrent use, and a nil *progress reports nothing.
+type progress struct {
+	logger zerolog.Logger
+	// notify sends a notification, the NotifyProgress of the session of the call.
+	notify func(context.Context, *mcp.ProgressNotificationParams) error
+	start  time.Time
+	token  any
+
+	mu    sync.Mutex
+	done  int
+	steps int
+	total int
+	// pending are the notifications not sent yet, oldest first, and sending is set while a
+	// goroutine sends them, see report.
+	pending []*mcp.ProgressNotificationParams
+	sending bool
+}
+
+// newProgress returns the progress reporter of the call req, nil when the client sent no progress
+// token or the call has no session, e.g. for background jobs.
+func newProgress(req *mcp.CallToolRequest, logger zerolog.Logger) *progress {
+	if req == nil || req.Session == nil || req.Params == nil {
+		return nil
+	}
+	token := req.Params.GetProgressToken()
+	if token == nil {
+		return nil
+	}
+
+	return &progress{logger: logger, notify: req.Session.NotifyProgress, start: time.Now(), token: token}
+}
+
+// expect adds runs scanner runs to the total of the scan.
+func (p *progress) expect(runs int) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.total += runs
+}
+
+// scan wraps scan so that the start of each run against target is reported as the named scanner
+// starting.
+func (p *progress) scan(name, target string, scan tools.ScanFunc) tools.ScanFunc {
+	if p == nil {
+		return scan
+	}
+
+	return func(ctx context.Context, params tools.ScanParams) tools.ScanResult {
+		p.report(ctx, false, "%s started on %s", name, target)
+		return scan(ctx, params)
+	}
+}
+
+// finished reports the end of the run of result against target.
+func (p *progress) finished(ctx context.Context, target string, result scannerResult) {
+	if p == nil {
+		return
+	}
+	p.report(ctx, true, "%s finished on %s: %s (%.2fs)", result.Name, target, result.status(), result.Duration.Seconds())
+}
+
+// report queues a progress notification with the formatted message, advancing the progress by a
+// step and counting a finished run when done is set. Notifications are sent in order by a single
+// goroutine, so that scanners reporting progress never wait for a slow client, and failures to
+// send them are logged without failing the scan.
+func (p *progress) report(ctx context.Context, done bool, format string, args ...any) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.steps++
+	if done {
+		p.done++
+	}
+	percent := 0
+	if p.total > 0 {
+		percent = p.done * 100 / p.total
+	}
+	message := fmt.Sprintf(format, args...) + fmt.Sprintf(" - %d/%d scanner runs done, %d%% complete, %.0fs elapsed",
+		p.done, p.total, percent, time.Since(p.start).Seconds())
+
+	p.pending = append(p.pending, &mcp.ProgressNotificationParams{
+		ProgressToken: p.token,
+		Message:       message,
+		Progress:      float64(p.steps),
+		Total:         float64(2 * p.total),
+	})
+	if !p.sending {
+		p.sending = true
+		// Runs ending on the deadline of the scan are still reported.
+		go p.send(context.WithoutCancel(ctx))
+	}
+}
+
+// send sends the pending notifications in order, without holding the lock while sending, until
+// none is left.
+func (p *progress) send(ctx context.Context) {
+	for {
+		p.mu.Lock()
+		if len(p.pending) == 0 {
+			p.sending = false
+			p.mu.Unlock()
+			return
+		}
+		params := p.pending[0]
+		p.pending = p.pending[1:]
+		p.mu.Unlock()
+
+		if err := p.notify(ctx, params); err != nil {
+			p.logger.Debug().Err(err).Msg("Failed to send scan progress notification")
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package fullscan
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+)
+
+type ProgressTestSuite struct {
+	suite.Suite
+}
+
+// callFullScan calls tool through an in-memory MCP session with the given progress token, nil
+// for none, and returns the progress notifications received, once want of them arrived.
+func (s *ProgressTestSuite) callFullScan(tool *Tool, token any, want int) []*mcp.ProgressNotificationParams {
+	srv := mcp.NewServer(&mcp.Implementation{Name: "wass-mcp", Version: "test"}, nil)
+	mcp.AddTool(srv, &mcp.Tool{Name: toolName}, tool.FullScanHandler)
+
+	var (
+		mu       sync.Mutex
+		received []*mcp.ProgressNotificationParams
+	)
+	client := mcp.NewClient(&mcp.Implementation{Name: "agent", Version: "test"}, &mcp.ClientOptions{
+		ProgressNotificationHandler: func(_ context.Context, req *mcp.ProgressNotificationClientRequest) {
+			mu.Lock()
+			defer mu.Unlock()
+			received = append(received, req.Params)
+		},
+	})
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := srv.Connect(ctx, serverTransport, nil)
+	s.Require().NoError(err)
+	defer serverSession.Close()
+	session, err := client.Connect(ctx, clientTransport, nil)
+	s.Require().NoError(err)
+	defer session.Close()
+
+	params := &mcp.CallToolParams{Name: toolName, Arguments: map[string]any{"host": "localhost", "port": 80}}
+	if token != nil {
+		params.Meta = mcp.Meta{"progressToken": token}
+	}
+	result, err := session.CallTool(ctx, params)
+	s.Require().NoError(err)
+	s.Require().False(result.IsError)
+
+	// Notifications are handled apart from the call result.
+	s.Eventually(func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(received) >= want
+	}, time.Second, 5*time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+
+	return received
+}
+
+func (s *ProgressTestSuite) TestFullScan_ReportsProgress() {
+	nikto := &mockScanner{name: "nikto", available: true, scanOutput: "nikto output"}
+	nuclei := &mockScanner{name: "nuclei", available: true, scanOutput: "nuclei output"}
+	tool := New(zerolog.Nop(), tools.NewRegistry(nikto, nuclei)).(*Tool)
+
+	received := s.callFullScan(tool, "scan-1", 4)
+	s.Require().Len(received, 4, "each run reports its start and its end")
+
+	var started, finished int
+	for i, notification := range received {
+		s.Equal("scan-1", notification.ProgressToken)
+		s.Equal(float64(i+1), notification.Progress, "every notification advances the progress")
+		s.Equal(float64(4), notification.Total)
+		s.Contains(notification.Message, "complete")
+		s.Contains(notification.Message, "elapsed")
+		switch {
+		case strings.Contains(notification.Message, "started on http://localhost"):
+			started++
+		case strings.Contains(notification.Message, "finished on http://localhost: SUCCESS ("):
+			finished++
+		}
+	}
+	s.Equal(2, started)
+	s.Equal(2, finished)
+	s.Contains(received[3].Message, "2/2 scanner runs done, 100% complete")
+}
+
+func (s *ProgressTestSuite) TestFullScan_NoProgressToken() {
+	tool := New(zerolog.Nop(), tools.NewRegistry(&mockScanner{name: "nikto", available: true, scanOutput: "nikto output"})).(*Tool)
+
+	s.Empty(s.callFullScan(tool, nil, 0), "clients asking for no progress get none")
+}
+
+func (s *ProgressTestSuite) TestNewProgress_NoSession() {
+	s.Nil(newProgress(nil, zerolog.Nop()))
+	s.Nil(newProgress(&mcp.CallToolRequest{}, zerolog.Nop()), "background scans have no session")
+
+	// A nil reporter reports nothing.
+	var reporter *progress
+	reporter.expect(3)
+	reporter.finished(context.Background(), "http://localhost", scannerResult{Name: "nikto"})
+}
+
+func (s *ProgressTestSuite) TestReport_SlowClient() {
+	release := make(chan struct{})
+	sent := make(chan float64, 3)
+	reporter := &progress{
+		logger: zerolog.Nop(),
+		notify: func(_ context.Context, params *mcp.ProgressNotificationParams) error {
+			<-release
+			sent <- params.Progress
+			return nil
+		},
+		start: time.Now(),
+		token: "scan-1",
+	}
+	reporter.expect(2)
+
+	reported := make(chan struct{})
+	go func() {
+		reporter.report(context.Background(), false, "nikto started")
+		reporter.report(context.Background(), false, "nuclei started")
+		reporter.finished(context.Background(), "http://localhost", scannerResult{Name: "nikto"})
+		close(reported)
+	}()
+	select {
+	case <-reported:
+	case <-time.After(time.Second):
+		s.FailNow("reporting progress waited for the client")
+	}
+
+	close(release)
+	for want := 1.0; want <= 3; want++ {
+		select {
+		case progress := <-sent:
+			s.Equal(want, progress, "notifications are sent in order")
+		case <-time.After(time.Second):
+			s.FailNow("pending notifications were not sent")
+		}
+	}
+}
+
+func TestProgressTestSuite(t *testing.T) {
+	suite.Run(t, new(ProgressTestSuite))
+}
@@ -0,0 +1,95 @@
+package fullscan
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	// reportOverhead is the room the header, summaries and footer of a report are estimated to
+	// take, and resultOverhead the room of the banner and notes of each scanner result.
+	reportOverhead = 2048
+	resultOverhead = 512
+)
+
+var (
+	// separatorLine and dashLine are the full-width rules between report sections.
+	separatorLine = "=" + strings.Repeat("=", reportLineWidth) + "\n"
+	dashLine      = "-" + strings.Repeat("-", reportLineWidth) + "\n"
+)
+
+// reportWriter writes the sections of a report straight to an io.Writer, e.g. the stored output
+// and the response page of a scan, so that the report is never built whole in memory. It keeps
+// the first write error so that section writers don't have to check every write.
+type reportWriter struct {
+	out io.Writer
+	err error
+}
+
+// newReportWriter returns a report writer writing to out.
+func newReportWriter(out io.Writer) *reportWriter {
+	return &reportWriter{out: out}
+}
+
+// write writes text.
+func (w *reportWriter) write(text string) {
+	if w.err == nil {
+		_, w.err = io.WriteString(w.out, text)
+	}
+}
+
+// line writes text followed by a newline.
+func (w *reportWriter) line(text string) {
+	w.write(text)
+	w.write("\n")
+}
+
+// printf writes text formatted from format and args.
+func (w *reportWriter) printf(format string, args ...any) {
+	if w.err == nil {
+		_, w.err = fmt.Fprintf(w.out, format, args...)
+	}
+}
+
+// banner writes title between two separator lines, followed by an empty line.
+func (w *reportWriter) banner(title string) {
+	w.write(separatorLine)
+	w.write("                    ")
+	w.line(title)
+	w.write(separatorLine)
+	w.write("\n")
+}
+
+// groupsSize estimates the size of the report of groups.
+func groupsSize(groups []vhostResults) int {
+	size := reportOverhead
+	for _, group := range groups {
+		size += resultOverhead
+		for _, result := range group.Results {
+			size += resultOverhead + len(result.Output)
+		}
+	}
+
+	return size
+}
+
+// portsSize estimates the size of the report of ports.
+func portsSize(ports []portResults) int {
+	size := 0
+	for _, port := range ports {
+		size += resultOverhead + groupsSize(port.Groups)
+	}
+
+	return size
+}
+
+// hostsSize estimates the size of the report of a target group of hosts.
+func hostsSize(hosts []hostResults) int {
+	size := reportOverhead
+	for _, host := range hosts {
+		size += resultOverhead + portsSize(host.Ports)
+	}
+
+	return size
+}
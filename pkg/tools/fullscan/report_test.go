@@ -0,0 +1,182 @@
+package fullscan
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+	"github.com/tb0hdan/wass-mcp/pkg/types"
+)
+
+// hugeOutputLines is the number of lines of the nuclei output of the benchmarks.
+const hugeOutputLines = 100_000
+
+// hugeOutput returns a nuclei JSONL output of lines lines.
+func hugeOutput(lines int) string {
+	var builder strings.Builder
+	for i := range lines {
+		fmt.Fprintf(&builder, `{"template-id":"tech-detect-%d","info":{"name":"Technology %d","severity":"info"},"matched-at":"http://localhost/%d"}`+"\n", i, i, i)
+	}
+
+	return builder.String()
+}
+
+// hugeResults returns the results of a scan whose nuclei output has lines lines.
+func hugeResults(lines int) []scannerResult {
+	return []scannerResult{
+		{Name: "nikto", Output: "+ Server: nginx\n+ No CGI Directories found\n", Duration: time.Second},
+		{Name: "nuclei", Output: hugeOutput(lines), Duration: time.Minute},
+		{Name: "zap", Error: errors.New("exit status 1"), Output: "partial", Duration: time.Second},
+	}
+}
+
+// failingWriter fails every write after the first limit bytes.
+type failingWriter struct {
+	limit   int
+	written int
+}
+
+func (f *failingWriter) Write(p []byte) (int, error) {
+	if f.written+len(p) > f.limit {
+		return 0, errors.New("disk full")
+	}
+	f.written += len(p)
+
+	return len(p), nil
+}
+
+// renderReport returns the report written by write, built whole as the tests read it.
+func renderReport(write func(w *reportWriter)) string {
+	var builder strings.Builder
+	write(newReportWriter(&builder))
+
+	return builder.String()
+}
+
+// mergeResults returns the report of scanner results.
+func (t *Tool) mergeResults(targetURL string, results []scannerResult) string {
+	return t.mergeVhostResults(reportMeta{TargetURL: targetURL}, []vhostResults{{Results: results}})
+}
+
+// mergeVhostResults returns the report of scanner results grouped by vhost.
+func (t *Tool) mergeVhostResults(meta reportMeta, groups []vhostResults) string {
+	return renderReport(func(w *reportWriter) { t.writeVhostReport(w, meta, groups) })
+}
+
+// mergePortResults returns the report of the results of several ports.
+func (t *Tool) mergePortResults(branding models.ReportBranding, host string, ports []portResults, extraLines ...string) string {
+	return renderReport(func(w *reportWriter) { t.writePortReport(w, branding, host, ports, extraLines...) })
+}
+
+// mergeGroupResults returns the report of the hosts of a target group.
+func (t *Tool) mergeGroupResults(branding models.ReportBranding, group string, hosts []hostResults) string {
+	return renderReport(func(w *reportWriter) { t.writeGroupReport(w, branding, groupLines(group, hosts), hosts) })
+}
+
+// applyPagination returns the response text and page of output written to the page writer of
+// a scan.
+func (t *Tool) applyPagination(output string, maxLines int, start tools.Cursor) (string, tools.ResponsePage) {
+	pager := tools.NewPageWriter(maxLines, start, t.maxResponseBytes)
+	_, _ = io.WriteString(pager, output)
+	page := pager.Page()
+
+	return t.pageText(page, start), page
+}
+
+type ReportTestSuite struct {
+	suite.Suite
+	tool *Tool
+}
+
+func (s *ReportTestSuite) SetupTest() {
+	s.tool = New(zerolog.Nop(), tools.NewRegistry()).(*Tool)
+}
+
+func (s *ReportTestSuite) TestReportSize() {
+	results := hugeResults(1000)
+	groups := []vhostResults{{Results: results}, {Vhost: "admin.localhost", Results: results}}
+
+	report := s.tool.mergeVhostResults(reportMeta{TargetURL: "http://localhost"}, groups)
+	s.LessOrEqual(len(report), groupsSize(groups), "the stored output is sized for the whole report")
+	s.Contains(report, "VHOST: admin.localhost")
+	s.Equal(2*1000, strings.Count(report, `"template-id"`))
+
+	ports := []portResults{{Port: 80, Groups: groups}, {Port: 443, Groups: groups}}
+	report = s.tool.mergeGroupResults(s.tool.branding, "web", []hostResults{{Host: "localhost", Ports: ports}})
+	s.LessOrEqual(len(report), hostsSize([]hostResults{{Host: "localhost", Ports: ports}}))
+	s.Contains(report, "HOST: localhost")
+}
+
+func (s *ReportTestSuite) TestReportStreamed() {
+	meta := reportMeta{TargetURL: "http://localhost"}
+	groups := []vhostResults{{Results: hugeResults(1000)}}
+	report := s.tool.mergeVhostResults(meta, groups)
+
+	for _, start := range []tools.Cursor{{}, {Line: 500}, {Line: 800, Byte: 20}} {
+		pager := tools.NewPageWriter(100, start, 4096)
+		s.tool.writeVhostReport(newReportWriter(pager), meta, groups)
+		s.Equal(tools.PaginateResponse(report, 100, start, 4096), pager.Page(), "the streamed page is the page of the whole report")
+	}
+}
+
+func (s *ReportTestSuite) TestReportWriter_Error() {
+	out := &failingWriter{limit: 100}
+	w := newReportWriter(out)
+	s.tool.writeVhostReport(w, reportMeta{TargetURL: "http://localhost"}, []vhostResults{{Results: hugeResults(10)}})
+	s.EqualError(w.err, "disk full")
+	s.LessOrEqual(out.written, 100, "nothing is written after the first error")
+}
+
+func TestReportTestSuite(t *testing.T) {
+	suite.Run(t, new(ReportTestSuite))
+}
+
+// benchmarkPage measures the response page of the report written by write, read from the start
+// within the default byte budget, of about size bytes. "built" builds the whole report in a buffer
+// sized upfront and pages it, as full_scan did; "streamed" writes the report to the page writer.
+func benchmarkPage(b *testing.B, size int, write func(w *reportWriter)) {
+	b.Run("built", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			var builder strings.Builder
+			builder.Grow(size)
+			write(newReportWriter(&builder))
+			_ = tools.PaginateResponse(builder.String(), 0, tools.Cursor{}, types.DefaultMaxResponseBytes)
+		}
+	})
+	b.Run("streamed", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			pager := tools.NewPageWriter(0, tools.Cursor{}, types.DefaultMaxResponseBytes)
+			write(newReportWriter(pager))
+			_ = pager.Page()
+		}
+	})
+}
+
+func BenchmarkMergeResults(b *testing.B) {
+	tool := New(zerolog.Nop(), tools.NewRegistry()).(*Tool)
+	groups := []vhostResults{{Results: hugeResults(hugeOutputLines)}}
+	meta := reportMeta{TargetURL: "http://localhost"}
+
+	benchmarkPage(b, groupsSize(groups), func(w *reportWriter) { tool.writeVhostReport(w, meta, groups) })
+}
+
+func BenchmarkMergeGroupResults(b *testing.B) {
+	tool := New(zerolog.Nop(), tools.NewRegistry()).(*Tool)
+	ports := []portResults{{Port: 80, Groups: []vhostResults{{Results: hugeResults(hugeOutputLines / 10)}}}}
+	hosts := make([]hostResults, 10)
+	for i := range hosts {
+		hosts[i] = hostResults{Host: fmt.Sprintf("10.0.0.%d", i+1), Ports: ports}
+	}
+	headerLines := groupLines("web", hosts)
+
+	benchmarkPage(b, hostsSize(hosts), func(w *reportWriter) { tool.writeGroupReport(w, tool.branding, headerLines, hosts) })
+}
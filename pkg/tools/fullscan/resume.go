@@ -0,0 +1,186 @@
+package fullscan
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+)
+
+// ErrNotResumable is returned when resuming an execution that is not a paused full_scan.
+var ErrNotResumable = errors.New("execution cannot be resumed")
+
+// stateKey identifies a scanner run of a full scan.
+type stateKey struct {
+	host    string
+	port    int
+	scanner string
+	vhost   string
+}
+
+// resumeState holds the finished scanner runs of a paused scan. A nil state holds nothing.
+type resumeState map[stateKey]scannerResult
+
+// newResumeState returns the finished runs of state, leaving out the held ones.
+func newResumeState(state []models.ScannerState) resumeState {
+	previous := make(resumeState, len(state))
+	for _, run := range state {
+		if run.Status == models.ScannerHeld {
+			continue
+		}
+		result := scannerResult{
+			Duration: time.Duration(run.DurationMs) * time.Millisecond,
+			Name:     run.Scanner,
+			Output:   run.Output,
+		}
+		switch {
+		case run.Status == models.ScannerTimedOut:
+			message := strings.TrimPrefix(run.Error, tools.ErrScanTimedOut.Error()+": ")
+			result.Error = fmt.Errorf("%w: %s", tools.ErrScanTimedOut, message)
+		case run.Error != "":
+			result.Error = errors.New(run.Error)
+		}
+		previous[stateKey{host: run.Host, port: run.Port, scanner: run.Scanner, vhost: run.Vhost}] = result
+	}
+
+	return previous
+}
+
+// forHost returns the finished runs against host, for lookup without host. Runs stored without a
+// host, by scans paused before hosts were recorded, match every host.
+func (r resumeState) forHost(host string) resumeState {
+	if len(r) == 0 {
+		return nil
+	}
+
+	scoped := make(resumeState, len(r))
+	for key, result := range r {
+		if key.host != host && key.host != "" {
+			continue
+		}
+		key.host = ""
+		scoped[key] = result
+	}
+
+	return scoped
+}
+
+// hosts returns the hosts of the runs of a paused scan, in report order.
+func hosts(state []models.ScannerState) []string {
+	var found []string
+	for _, run := range state {
+		if run.Host != "" && !slices.Contains(found, run.Host) {
+			found = append(found, run.Host)
+		}
+	}
+
+	return found
+}
+
+// lookup returns the finished run of scanner against port and vhost, if any. Runs are looked up
+// in the state returned by forHost.
+func (r resumeState) lookup(port int, vhost, scanner string) (scannerResult, bool) {
+	result, ok := r[stateKey{port: port, scanner: scanner, vhost: vhost}]
+
+	return result, ok
+}
+
+// scanState returns the state of every scanner run of a scan, in report order.
+func scanState(ports []portResults) []models.ScannerState {
+	var state []models.ScannerState
+	for _, port := range ports {
+		for _, group := range port.Groups {
+			for _, result := range group.Results {
+				run := models.ScannerState{
+					DurationMs: result.Duration.Milliseconds(),
+					Host:       port.Host,
+					Output:     result.Output,
+					Port:       port.Port,
+					Scanner:    result.Name,
+					Status:     models.ScannerCompleted,
+					Vhost:      group.Vhost,
+				}
+				switch {
+				case result.held():
+					run.Status = models.ScannerHeld
+				case result.timedOut():
+					run.Error = result.Error.Error()
+					run.Status = models.ScannerTimedOut
+				case result.Error != nil:
+					run.Error = result.Error.Error()
+					run.Status = models.ScannerFailed
+				}
+				state = append(state, run)
+			}
+		}
+	}
+
+	return state
+}
+
+// countHeld returns the number of held runs in state.
+func countHeld(state []models.ScannerState) int {
+	held := 0
+	for _, run := range state {
+		if run.Status == models.ScannerHeld {
+			held++
+		}
+	}
+
+	return held
+}
+
+// loadPaused loads the paused execution input asks to resume. It returns the stored input, with
+// the pagination, compression and priority of input, the finished runs and the execution.
+func (t *Tool) loadPaused(ctx context.Context, input Input) (Input, resumeState, *models.ToolExecution, error) {
+	if t.storage == nil {
+		return input, nil, nil, fmt.Errorf("%w: no execution storage", ErrNotResumable)
+	}
+
+	id := input.ResumeExecutionID
+	exec, err := t.storage.GetToolExecution(ctx, id)
+	if err != nil {
+		return input, nil, nil, fmt.Errorf("failed to load execution %d: %w", id, err)
+	}
+	if exec.ToolName != toolName || exec.Status != models.StatusPaused {
+		return input, nil, nil, fmt.Errorf("%w: execution %d is not a paused %s", ErrNotResumable, id, toolName)
+	}
+
+	var stored Input
+	inputJSON, err := tools.UpgradeInput(exec.InputJSON, exec.InputSchemaVersion(), stored)
+	if err != nil {
+		return input, nil, nil, fmt.Errorf("failed to upgrade stored input of execution %d: %w", id, err)
+	}
+	if err := json.Unmarshal([]byte(inputJSON), &stored); err != nil {
+		return input, nil, nil, fmt.Errorf("failed to decode stored input of execution %d: %w", id, err)
+	}
+	stored.ScannerInput = tools.PrepareScannerInput(stored.ScannerInput)
+	stored.Compression = input.Compression
+	stored.Cursor = input.Cursor
+	stored.MaxLines = input.MaxLines
+	stored.Offset = input.Offset
+	stored.Priority = input.Priority
+	stored.SummaryOnly = input.SummaryOnly
+	stored.ResumeExecutionID = id
+	if err := t.validator.Struct(stored); err != nil {
+		return input, nil, nil, fmt.Errorf("validation error: stored input of execution %d: %w", id, err)
+	}
+
+	return stored, newResumeState(exec.ScanState), exec, nil
+}
+
+// markResumed marks a paused execution as resumed so that it is not resumed twice. The resuming
+// execution carries the scan state from now on.
+func (t *Tool) markResumed(ctx context.Context, exec *models.ToolExecution) {
+	exec.Status = models.StatusResumed
+	if err := t.storage.UpdateToolExecution(context.WithoutCancel(ctx), exec); err != nil {
+		logger := tools.ContextLogger(ctx, t.logger)
+		logger.Warn().Err(err).Msgf("Failed to mark execution %d as resumed", exec.ID)
+	}
+}
@@ -0,0 +1,202 @@
+package fullscan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/server"
+	"github.com/tb0hdan/wass-mcp/pkg/storage"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+)
+
+// gatedScanner reports each run on started and blocks it until release is closed.
+type gatedScanner struct {
+	mockScanner
+	calls   atomic.Int32
+	release chan struct{}
+	started chan string
+}
+
+func (g *gatedScanner) Scan(_ context.Context, params tools.ScanParams) tools.ScanResult {
+	g.calls.Add(1)
+	g.started <- params.Vhost
+	<-g.release
+
+	return tools.ScanResult{Output: g.name + " output for " + params.Vhost}
+}
+
+type ResumeTestSuite struct {
+	suite.Suite
+	cleanup func()
+	srv     *server.Server
+}
+
+func (s *ResumeTestSuite) SetupTest() {
+	tmpFile, err := os.CreateTemp("", "fullscan-resume-*.db")
+	s.Require().NoError(err)
+	tmpFile.Close()
+
+	store, err := storage.NewSQLiteStorage(storage.Config{DatabasePath: tmpFile.Name()})
+	s.Require().NoError(err)
+	s.srv = server.NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}, store)
+	s.cleanup = func() {
+		s.srv.Shutdown(context.Background())
+		os.Remove(tmpFile.Name())
+	}
+}
+
+func (s *ResumeTestSuite) TearDownTest() {
+	s.cleanup()
+}
+
+// execution waits for the execution with the given ID to be stored with status.
+func (s *ResumeTestSuite) execution(id uint, status string) *models.ToolExecution {
+	var exec *models.ToolExecution
+	s.Require().Eventually(func() bool {
+		var err error
+		exec, err = s.srv.Storage().GetToolExecution(context.Background(), id)
+		return err == nil && exec.Status == status
+	}, 2*time.Second, 10*time.Millisecond)
+
+	return exec
+}
+
+func (s *ResumeTestSuite) TestPauseAndResume() {
+	release := make(chan struct{})
+	started := make(chan string, 8)
+	alpha := &gatedScanner{mockScanner: mockScanner{name: "alpha", available: true}, release: release, started: started}
+	beta := &gatedScanner{mockScanner: mockScanner{name: "beta", available: true}, release: release, started: started}
+	tool := New(zerolog.Nop(), tools.NewRegistry(alpha, beta)).(*Tool)
+	s.Require().NoError(tool.Register(s.srv))
+	handler := tools.WrapToolHandler(s.srv.Storage(), toolName, tool.FullScanHandler, tools.ServerWrapOptions(s.srv)...)
+
+	// Pause while both scanners run against the first vhost: they complete, the second vhost is held.
+	input := Input{ScannerInput: tools.ScannerInput{Host: "192.0.2.1", Vhosts: []string{"a.example", "b.example"}}}
+	type response struct {
+		result *mcp.CallToolResult
+		err    error
+	}
+	done := make(chan response)
+	go func() {
+		result, _, err := handler(context.Background(), &mcp.CallToolRequest{}, input)
+		done <- response{result: result, err: err}
+	}()
+	<-started
+	<-started
+	jobs := s.srv.Jobs().List()
+	s.Require().Len(jobs, 1)
+	s.Require().NoError(s.srv.Jobs().Pause(jobs[0].ID))
+	close(release)
+
+	first := <-done
+	s.Require().NoError(first.err)
+	text := first.result.Content[0].(*mcp.TextContent).Text
+	s.Contains(text, "[Scan paused with 2 scanner runs held. Resume with resume_execution_id")
+	s.Contains(text, "alpha     : HELD")
+	s.Contains(text, "Total scanners: 2 | Successful: 0 | Failed: 0 | Held: 2")
+	s.Contains(text, "alpha output for a.example")
+
+	paused := s.execution(jobs[0].ExecutionID, models.StatusPaused)
+	s.Require().Len(paused.ScanState, 4)
+	statuses := map[string]string{}
+	for _, run := range paused.ScanState {
+		statuses[run.Scanner+"@"+run.Vhost] = run.Status
+	}
+	s.Equal(map[string]string{
+		"alpha@a.example": models.ScannerCompleted,
+		"beta@a.example":  models.ScannerCompleted,
+		"alpha@b.example": models.ScannerHeld,
+		"beta@b.example":  models.ScannerHeld,
+	}, statuses)
+
+	// Resuming runs only the held scanners and reports every run.
+	result, _, err := handler(context.Background(), &mcp.CallToolRequest{},
+		Input{ResumeExecutionID: paused.ID, ScannerInput: tools.ScannerInput{MaxLines: 1000}})
+	s.Require().NoError(err)
+	s.Equal(int32(2), alpha.calls.Load())
+	s.Equal(int32(2), beta.calls.Load())
+	text = result.Content[0].(*mcp.TextContent).Text
+	s.NotContains(text, "HELD")
+	s.Contains(text, "alpha output for a.example")
+	s.Contains(text, "alpha output for b.example")
+	s.Contains(text, "beta output for b.example")
+
+	s.execution(paused.ID, models.StatusResumed)
+	executions, _, err := s.srv.Storage().GetToolExecutions(context.Background(), 10, 0)
+	s.Require().NoError(err)
+	s.Require().Len(executions, 2)
+	resumedID := executions[0].ID
+	if resumedID == paused.ID {
+		resumedID = executions[1].ID
+	}
+	resumed := s.execution(resumedID, models.StatusCompleted)
+	s.Equal("http://192.0.2.1", resumed.Target)
+	s.Contains(resumed.InputJSON, `"host":"192.0.2.1"`)
+	s.Contains(resumed.InputJSON, `"max_lines":1000`)
+
+	// A resumed execution cannot be resumed again.
+	_, _, err = tool.FullScanHandler(context.Background(), &mcp.CallToolRequest{}, Input{ResumeExecutionID: paused.ID})
+	s.ErrorIs(err, ErrNotResumable)
+}
+
+func (s *ResumeTestSuite) TestResume_NotPaused() {
+	exec := &models.ToolExecution{ToolName: toolName, Status: models.StatusCompleted, InputJSON: `{}`}
+	s.Require().NoError(s.srv.Storage().CreateToolExecution(context.Background(), exec))
+	tool := New(zerolog.Nop(), tools.NewRegistry(&mockScanner{name: "alpha", available: true})).(*Tool)
+	tool.storage = s.srv.Storage()
+
+	_, _, err := tool.FullScanHandler(context.Background(), &mcp.CallToolRequest{}, Input{ResumeExecutionID: exec.ID})
+	s.ErrorIs(err, ErrNotResumable)
+
+	_, _, err = tool.FullScanHandler(context.Background(), &mcp.CallToolRequest{}, Input{ResumeExecutionID: 42})
+	s.Error(err)
+	s.False(errors.Is(err, ErrNotResumable))
+}
+
+func (s *ResumeTestSuite) TestScanState_RoundTrip() {
+	ports := []portResults{{Port: 80, Groups: []vhostResults{{Results: []scannerResult{
+		{Name: "alpha", Output: "ok", Duration: 1500 * time.Millisecond},
+		{Name: "beta", Error: errors.New("boom"), Output: "partial"},
+		{Name: "gamma", Error: tools.ErrScanHeld},
+		{Name: "delta", Error: fmt.Errorf("%w: signal: killed", tools.ErrScanTimedOut), Output: "partial"},
+	}}}}}
+
+	state := scanState(ports)
+	s.Equal(1, countHeld(state))
+	s.Equal(models.ScannerFailed, state[1].Status)
+	s.Equal("boom", state[1].Error)
+	s.Equal(models.ScannerTimedOut, state[3].Status)
+
+	previous := newResumeState(state)
+	alpha, ok := previous.lookup(80, "", "alpha")
+	s.Require().True(ok)
+	s.Equal("ok", alpha.Output)
+	s.Equal(1500*time.Millisecond, alpha.Duration)
+	beta, ok := previous.lookup(80, "", "beta")
+	s.Require().True(ok)
+	s.EqualError(beta.Error, "boom")
+	_, ok = previous.lookup(80, "", "gamma")
+	s.False(ok)
+	delta, ok := previous.lookup(80, "", "delta")
+	s.Require().True(ok)
+	s.True(delta.timedOut())
+	s.EqualError(delta.Error, "scan timed out: signal: killed")
+	s.Equal("partial", delta.Output)
+
+	var none resumeState
+	_, ok = none.lookup(80, "", "alpha")
+	s.False(ok)
+}
+
+func TestResumeTestSuite(t *testing.T) {
+	suite.Run(t, new(ResumeTestSuite))
+}
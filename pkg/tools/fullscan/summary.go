@@ -0,0 +1,120 @@
+package fullscan
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tb0hdan/wass-mcp/pkg/findings"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+)
+
+// Scanner run outcomes, as printed in reports.
+const (
+	statusFailed   = "FAILED"
+	statusHeld     = "HELD"
+	statusSuccess  = "SUCCESS"
+	statusTimedOut = "TIMED OUT"
+)
+
+// status returns the outcome of the run.
+func (r scannerResult) status() string {
+	switch {
+	case r.held():
+		return statusHeld
+	case r.timedOut():
+		return statusTimedOut
+	case r.Error != nil:
+		return statusFailed
+	default:
+		return statusSuccess
+	}
+}
+
+// severityCounts formats the number of findings per severity, e.g. "critical: 0, high: 2, ...".
+func severityCounts(found []models.Finding) string {
+	counts := findings.CountBySeverity(found)
+	severities := make([]string, 0, len(findings.Severities))
+	for _, severity := range findings.Severities {
+		severities = append(severities, fmt.Sprintf("%s: %d", severity, counts[severity]))
+	}
+
+	return strings.Join(severities, ", ")
+}
+
+// summaryReport returns the report of a summary_only scan: the target lines, the rate limit
+// adjustments of throttling targets and the crawls of targets crawled first, the outcome of every
+// scanner run, the findings per severity with the risk score, and the tool calls retrieving the
+// details of the execution executionID, which stores the full report, read from its start with the
+// output cursor reportCursor. Runs are labelled with their target URL when several ports or hosts
+// were scanned, and with their vhost.
+func summaryReport(targetLines []string, ports []portResults, found []models.Finding, executionID uint, reportCursor string) string {
+	var builder strings.Builder
+
+	builder.WriteString("FULL SCAN SUMMARY\n")
+	builder.WriteString(dashLine)
+	for _, line := range targetLines {
+		builder.WriteString(line + "\n")
+	}
+	for _, port := range ports {
+		for _, note := range []struct{ label, text string }{{"Throttling", port.Meta.Throttling}, {"Crawl", port.Meta.Crawl}} {
+			if note.text == "" {
+				continue
+			}
+			if len(ports) > 1 {
+				builder.WriteString(fmt.Sprintf("%s [%s]: %s\n", note.label, port.Meta.TargetURL, note.text))
+			} else {
+				builder.WriteString(note.label + ": " + note.text + "\n")
+			}
+		}
+	}
+	builder.WriteString("\n")
+
+	outcomes := make(map[string]int)
+	runs := 0
+	for _, port := range ports {
+		for _, group := range port.Groups {
+			var labels []string
+			if len(ports) > 1 {
+				labels = append(labels, port.Meta.TargetURL)
+			}
+			if group.Vhost != "" {
+				labels = append(labels, "vhost "+group.Vhost)
+			}
+			for _, result := range group.Results {
+				name := result.Name
+				if len(labels) > 0 {
+					name += " [" + strings.Join(labels, ", ") + "]"
+				}
+				status := result.status()
+				outcomes[status]++
+				runs++
+				builder.WriteString(fmt.Sprintf("  %s: %s (%.2fs)\n", name, status, result.Duration.Seconds()))
+			}
+		}
+	}
+
+	builder.WriteString(fmt.Sprintf("\nScanner runs: %d | Successful: %d | Failed: %d", runs, outcomes[statusSuccess], outcomes[statusFailed]))
+	if outcomes[statusTimedOut] > 0 {
+		builder.WriteString(fmt.Sprintf(" | Timed out: %d", outcomes[statusTimedOut]))
+	}
+	if outcomes[statusHeld] > 0 {
+		builder.WriteString(fmt.Sprintf(" | Held: %d", outcomes[statusHeld]))
+	}
+	builder.WriteString("\n")
+	builder.WriteString(fmt.Sprintf("Findings: %d (%s) | Risk score: %.1f\n",
+		len(found), severityCounts(found), findings.ScoreFindings(found)))
+
+	builder.WriteString("\n")
+	if executionID == 0 {
+		builder.WriteString("The full report was not stored. Run the scan without summary_only for the details.\n")
+		return builder.String()
+	}
+	builder.WriteString(fmt.Sprintf("Details of execution %d:\n", executionID))
+	if reportCursor != "" {
+		builder.WriteString(fmt.Sprintf("  - Full report: continue_output {\"cursor\": %q}\n", reportCursor))
+	}
+	builder.WriteString(fmt.Sprintf("  - Top findings: summarize {\"id\": %d}\n", executionID))
+	builder.WriteString(fmt.Sprintf("  - Findings: triage {\"action\": \"list\", \"execution_id\": %d}\n", executionID))
+
+	return builder.String()
+}
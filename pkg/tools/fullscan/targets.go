@@ -0,0 +1,91 @@
+package fullscan
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+)
+
+const (
+	// defaultConcurrency is the number of targets of a multi-target scan scanned at once when the
+	// input does not set it.
+	defaultConcurrency = 4
+	// maxTargets bounds the targets the host expressions of a multi-target scan expand to.
+	maxTargets = 256
+)
+
+// targetKey holds the results of a target scanned by a multi-target scan, filled in by the full
+// scan of the target.
+type targetKey struct{}
+
+// scanTargets scans every target of a multi-target scan, or every branch of a crawl fan-out scan,
+// with the rest of input, concurrency targets at a time, and returns their results in the order
+// of targets. Each target is scanned
+// by a full scan of its own through the registered handler, so that it is logged as an execution
+// of its own, with its findings, sharing the correlation ID of the multi-target scan.
+func (t *Tool) scanTargets(ctx context.Context, req *mcp.CallToolRequest, input Input, targets []string) []hostResults {
+	concurrency := input.Concurrency
+	if concurrency == 0 {
+		concurrency = defaultConcurrency
+	}
+	input.Concurrency = 0
+	input.Hosts = nil
+	// The branches of a crawl fan-out scan are URLs, which carry their scheme, port and path.
+	if input.CrawlFanOut {
+		input.CrawlFanOut = false
+		input.Path, input.Port, input.Scheme = "", 0, ""
+	}
+	// The multi-target scan notifies once for all of its targets.
+	input.Notify = nil
+	targetReq := &mcp.CallToolRequest{}
+	if req != nil {
+		targetReq.Session = req.Session
+	}
+
+	results := make([]hostResults, len(targets))
+	slots := make(chan struct{}, concurrency)
+	var waitGroup sync.WaitGroup
+	for i, host := range targets {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+				results[i] = t.scanTarget(ctx, targetReq, input, host)
+			case <-ctx.Done():
+				results[i] = hostResults{Host: host, Error: fmt.Errorf("not scanned: %w", context.Cause(ctx))}
+			}
+			if results[i].Error != nil {
+				logger := tools.ContextLogger(ctx, t.logger)
+				logger.Warn().Err(results[i].Error).Msgf("Skipping target %s", host)
+			}
+		}()
+	}
+	waitGroup.Wait()
+
+	return results
+}
+
+// scanTarget scans host, a target of a multi-target scan, by a full scan of its own. The results
+// keep host as requested, e.g. the URL of a crawl fan-out branch.
+func (t *Tool) scanTarget(ctx context.Context, req *mcp.CallToolRequest, input Input, host string) hostResults {
+	input.Host = host
+	scanned := &hostResults{Host: host}
+	if _, _, err := t.Run(context.WithValue(ctx, targetKey{}, scanned), req, input); err != nil && scanned.Error == nil {
+		scanned.Error = err
+	}
+	scanned.Host = host
+
+	return *scanned
+}
+
+// targetResults returns where the full scan of a target of a multi-target scan reports its
+// results to, nil for other scans.
+func targetResults(ctx context.Context) *hostResults {
+	scanned, _ := ctx.Value(targetKey{}).(*hostResults)
+	return scanned
+}
@@ -0,0 +1,126 @@
+package fullscan
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/server"
+	"github.com/tb0hdan/wass-mcp/pkg/storage"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+)
+
+type TargetsTestSuite struct {
+	suite.Suite
+	cleanup func()
+	scanner *hostScanner
+	srv     *server.Server
+	tool    *Tool
+}
+
+func (s *TargetsTestSuite) SetupTest() {
+	tmpFile, err := os.CreateTemp("", "fullscan-targets-*.db")
+	s.Require().NoError(err)
+	tmpFile.Close()
+
+	store, err := storage.NewSQLiteStorage(storage.Config{DatabasePath: tmpFile.Name()})
+	s.Require().NoError(err)
+	s.srv = server.NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}, store)
+	s.cleanup = func() {
+		s.srv.Shutdown(context.Background())
+		os.Remove(tmpFile.Name())
+	}
+
+	s.scanner = &hostScanner{mockScanner: mockScanner{name: "mock1", available: true}}
+	s.tool = New(zerolog.Nop(), tools.NewRegistry(s.scanner)).(*Tool)
+	s.Require().NoError(s.tool.Register(s.srv))
+}
+
+func (s *TargetsTestSuite) TearDownTest() {
+	s.cleanup()
+}
+
+func (s *TargetsTestSuite) TestTargetsScan() {
+	input := Input{Hosts: []string{"a.example.com", "10.0.0.0/30"}, Concurrency: 2}
+	result, _, err := s.tool.Run(context.Background(), &mcp.CallToolRequest{}, input)
+	s.Require().NoError(err)
+	s.ElementsMatch([]string{"http://a.example.com", "http://10.0.0.1", "http://10.0.0.2"}, s.scanner.scanned)
+
+	var executions []models.ToolExecution
+	s.Require().Eventually(func() bool {
+		executions, err = s.srv.Storage().GetToolExecutionsByTool(context.Background(), toolName, 10)
+		if err != nil || len(executions) != 4 {
+			return false
+		}
+		for _, exec := range executions {
+			if exec.Status != models.StatusCompleted {
+				return false
+			}
+		}
+		return true
+	}, 2*time.Second, 10*time.Millisecond)
+
+	hosts := make(map[string]uint)
+	var parent models.ToolExecution
+	for _, exec := range executions {
+		s.Equal(executions[0].CorrelationID, exec.CorrelationID, "the targets share the correlation ID of the scan")
+		if exec.Host == "" {
+			parent = exec
+			continue
+		}
+		hosts[exec.Host] = exec.ID
+	}
+	s.Equal(tools.ListTarget(input.Hosts), parent.Target)
+	s.Contains(parent.InputJSON, `"hosts":["a.example.com","10.0.0.0/30"]`)
+	s.Len(hosts, 3)
+
+	text := result.Content[0].(*mcp.TextContent).Text
+	s.Contains(text, "Targets: a.example.com, 10.0.0.0/30 (3 hosts)")
+	s.Contains(text, "Total hosts: 3 | Scanned: 3 | Failed: 0")
+	s.Contains(text, "Total findings: 3 (critical: 0, high: 3, medium: 0, low: 0, info: 0)")
+	for host, id := range hosts {
+		s.Contains(text, fmt.Sprintf("HOST: %s\n%s\nExecution: %d\n", host, separatorLine, id))
+	}
+}
+
+func (s *TargetsTestSuite) TestTargetsScan_SummaryOnly() {
+	result, _, err := s.tool.Run(context.Background(), &mcp.CallToolRequest{},
+		Input{Hosts: []string{"web[1-2].example.com"}, SummaryOnly: true})
+	s.Require().NoError(err)
+
+	text := result.Content[0].(*mcp.TextContent).Text
+	s.Contains(text, "FULL SCAN SUMMARY")
+	s.Contains(text, "Targets: web[1-2].example.com (2 hosts)")
+}
+
+func (s *TargetsTestSuite) TestTargetsScan_Invalid() {
+	for name, tc := range map[string]struct {
+		input    Input
+		expected string
+	}{
+		"host":        {Input{Hosts: []string{"a.example.com"}, ScannerInput: tools.ScannerInput{Host: "b.example.com"}}, "hosts is exclusive"},
+		"group":       {Input{Hosts: []string{"a.example.com"}, Group: "staging"}, "hosts is exclusive"},
+		"resume":      {Input{Hosts: []string{"a.example.com"}, ResumeExecutionID: 1}, "hosts is exclusive"},
+		"concurrency": {Input{Concurrency: 2, ScannerInput: tools.ScannerInput{Host: "a.example.com"}}, "concurrency is only allowed with hosts"},
+		"too many":    {Input{Hosts: []string{"10.0.0.0/16"}}, "too many targets"},
+		"range":       {Input{Hosts: []string{"10.0.0.9-1"}}, "invalid host range"},
+	} {
+		_, _, err := s.tool.FullScanHandler(context.Background(), &mcp.CallToolRequest{}, tc.input)
+		s.ErrorContains(err, tc.expected, name)
+	}
+	s.Empty(s.scanner.scanned)
+
+	_, _, err := New(zerolog.Nop(), tools.NewRegistry(s.scanner)).(*Tool).FullScanHandler(context.Background(), &mcp.CallToolRequest{},
+		Input{Hosts: []string{"a.example.com"}})
+	s.ErrorIs(err, ErrNotRegistered)
+}
+
+func TestTargetsTestSuite(t *testing.T) {
+	suite.Run(t, new(TargetsTestSuite))
+}
@@ -0,0 +1,131 @@
+package graphqlcop
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+	"github.com/tb0hdan/wass-mcp/pkg/server"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+	"github.com/tb0hdan/wass-mcp/pkg/vault"
+)
+
+const (
+	binaryName  = "graphql-cop"
+	description = "GraphQL Cop is a security auditor of GraphQL endpoints testing for common misconfigurations such as introspection, batching and denial of service vectors."
+	headerVerb  = "output"
+)
+
+// defaultEndpoint is the path scanned for targets without a path.
+const defaultEndpoint = "/graphql"
+
+// technologies are the technologies graphql-cop applies to.
+var technologies = []string{"graphql"}
+
+// supportedOptions are the scan options graphql-cop honours.
+var supportedOptions = []string{tools.OptionCABundle, tools.OptionCredential, tools.OptionUserAgent, tools.OptionVhost}
+
+// Tool implements the graphql-cop scanner.
+type Tool struct {
+	tools.BaseScanner
+}
+
+// Scan performs the graphql-cop scan and returns its JSON report.
+func (t *Tool) Scan(ctx context.Context, params tools.ScanParams) tools.ScanResult {
+	if params.Path == "" || params.Path == "/" {
+		params.Path = defaultEndpoint
+	}
+	targetURL := params.Target().URL()
+	logger := tools.ContextLogger(ctx, t.Logger)
+	logger.Info().Msgf("Running graphql-cop scan on %s", targetURL)
+
+	if err := params.CheckCredential(binaryName, vault.HeaderTypes...); err != nil {
+		return tools.ScanResult{
+			Error: err,
+		}
+	}
+
+	args, err := buildArgs(targetURL, params)
+	if err != nil {
+		return tools.ScanResult{
+			Error: err,
+		}
+	}
+
+	workDir, cleanup, err := t.WorkDir(ctx)
+	if err != nil {
+		return tools.ScanResult{
+			Error: err,
+		}
+	}
+	defer cleanup()
+
+	cmd := t.Command(ctx, args...)
+	cmd.Dir = workDir
+	cmd.Env = tools.ScanEnv(params, workDir)
+	output, err := t.CombinedOutput(ctx, cmd)
+
+	if err != nil {
+		return tools.ScanResult{
+			Output: string(output),
+			Error:  fmt.Errorf("failed to execute graphql-cop: %w", err),
+		}
+	}
+
+	return tools.ScanResult{
+		Output: string(output),
+		Error:  nil,
+	}
+}
+
+// buildArgs builds the graphql-cop command line arguments. graphql-cop takes its request headers
+// as a single JSON object.
+func buildArgs(targetURL string, params tools.ScanParams) ([]string, error) {
+	args := []string{"-t", targetURL, "-o", "json"}
+
+	headers := map[string]string{}
+	if params.Vhost != "" {
+		headers["Host"] = params.Vhost
+	}
+	if userAgent := params.Option(tools.OptionUserAgent); userAgent != "" {
+		headers["User-Agent"] = userAgent
+	}
+	if params.Credential != nil {
+		for _, header := range params.Credential.Headers() {
+			name, value, _ := strings.Cut(header, ": ")
+			headers[name] = value
+		}
+	}
+	if len(headers) == 0 {
+		return args, nil
+	}
+
+	encoded, err := json.Marshal(headers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode graphql-cop headers: %w", err)
+	}
+
+	return append(args, "-H", string(encoded)), nil
+}
+
+// Register registers the graphql-cop tool with the MCP server.
+func (t *Tool) Register(srv *server.Server) error {
+	return t.RegisterTool(srv, t.Handler)
+}
+
+// Handler handles MCP tool requests.
+func (t *Tool) Handler(ctx context.Context, _ *mcp.CallToolRequest, input tools.ScannerInput) (*mcp.CallToolResult, any, error) {
+	return t.HandleScan(ctx, input, headerVerb, t.Scan)
+}
+
+// New creates a new graphql-cop scanner tool.
+func New(logger zerolog.Logger) tools.Scanner {
+	base := tools.NewBaseScanner(binaryName, description, logger, supportedOptions...)
+	base.WarmUpArgs = []string{"--help"}
+	base.ForTechnologies = technologies
+
+	return &Tool{BaseScanner: base}
+}
@@ -0,0 +1,75 @@
+package graphqlcop
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+	"github.com/tb0hdan/wass-mcp/pkg/vault"
+)
+
+type GraphQLCopTestSuite struct {
+	suite.Suite
+	logger zerolog.Logger
+	tool   *Tool
+}
+
+func (s *GraphQLCopTestSuite) SetupTest() {
+	s.logger = zerolog.New(os.Stdout).With().Timestamp().Logger()
+	s.tool = New(s.logger).(*Tool)
+}
+
+func (s *GraphQLCopTestSuite) TestNew() {
+	s.Equal("graphql-cop", s.tool.Name())
+	s.Equal([]string{"graphql"}, tools.Technologies(s.tool))
+}
+
+func (s *GraphQLCopTestSuite) TestBuildArgs_Default() {
+	args, err := buildArgs("https://api.example.com/graphql", tools.ScanParams{})
+	s.Require().NoError(err)
+	s.Equal([]string{"-t", "https://api.example.com/graphql", "-o", "json"}, args)
+}
+
+func (s *GraphQLCopTestSuite) TestBuildArgs_Headers() {
+	params := tools.ScanParams{
+		Credential: &vault.Credential{Type: vault.TypeBearer, Secret: vault.Secret{Token: "abc"}},
+		Options:    map[string]string{tools.OptionUserAgent: "wass"},
+		Vhost:      "api.example.com",
+	}
+	args, err := buildArgs("https://10.0.0.1/graphql", params)
+	s.Require().NoError(err)
+	s.Equal([]string{
+		"-t", "https://10.0.0.1/graphql", "-o", "json",
+		"-H", `{"Authorization":"Bearer abc","Host":"api.example.com","User-Agent":"wass"}`,
+	}, args)
+}
+
+func (s *GraphQLCopTestSuite) TestScan_DefaultEndpoint() {
+	// The fake graphql-cop prints the endpoint it was given.
+	binDir := s.T().TempDir()
+	script := "#!/bin/sh\necho \"$2\"\n"
+	s.Require().NoError(os.WriteFile(filepath.Join(binDir, binaryName), []byte(script), 0o700)) //nolint:gosec
+	s.T().Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	result := s.tool.Scan(context.Background(), tools.ScanParams{Host: "api.example.com", Port: 443, Scheme: "https"})
+	s.Require().NoError(result.Error)
+	s.Equal("https://api.example.com/graphql\n", result.Output)
+
+	result = s.tool.Scan(context.Background(), tools.ScanParams{Host: "api.example.com", Path: "/v1/query", Port: 443, Scheme: "https"})
+	s.Require().NoError(result.Error)
+	s.Equal("https://api.example.com/v1/query\n", result.Output)
+}
+
+func (s *GraphQLCopTestSuite) TestHandler_ValidationError() {
+	_, _, err := s.tool.Handler(context.Background(), &mcp.CallToolRequest{}, tools.ScannerInput{Host: "invalid host!!!"})
+	s.ErrorContains(err, "validation error")
+}
+
+func TestGraphQLCopTestSuite(t *testing.T) {
+	suite.Run(t, new(GraphQLCopTestSuite))
+}
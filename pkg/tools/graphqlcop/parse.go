@@ -0,0 +1,54 @@
+package graphqlcop
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/tb0hdan/wass-mcp/pkg/findings"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+)
+
+// result is a test result of graphql-cop JSON output.
+type result struct {
+	CurlVerify  string `json:"curl_verify"`
+	Description string `json:"description"`
+	Impact      string `json:"impact"`
+	Result      bool   `json:"result"`
+	Severity    string `json:"severity"`
+	Title       string `json:"title"`
+}
+
+// ParseFindings parses graphql-cop JSON output and reports each failed test, the tests whose
+// result is true, with its curl reproduction command as evidence. Output that is not JSON falls
+// back to bracketed severity tags.
+func (t *Tool) ParseFindings(output string) ([]models.Finding, error) {
+	start := strings.Index(output, "[")
+	end := strings.LastIndex(output, "]")
+	if start < 0 || end <= start {
+		return findings.ExtractGeneric(binaryName, output), nil
+	}
+
+	var results []result
+	if err := json.Unmarshal([]byte(output[start:end+1]), &results); err != nil {
+		return findings.ExtractGeneric(binaryName, output), nil //nolint:nilerr
+	}
+
+	var found []models.Finding
+	for _, res := range results {
+		if !res.Result {
+			continue
+		}
+		title := res.Title
+		if res.Impact != "" {
+			title += " (" + res.Impact + ")"
+		}
+		found = append(found, models.Finding{
+			Evidence: findings.AppendEvidence(nil, models.EvidenceCurl, binaryName, res.CurlVerify),
+			Scanner:  binaryName,
+			Severity: findings.NormalizeSeverity(res.Severity),
+			Title:    title,
+		})
+	}
+
+	return found, nil
+}
@@ -0,0 +1,47 @@
+package graphqlcop
+
+import (
+	"os"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/types"
+)
+
+const jsonOutput = `[{"result": true, "title": "Alias Overloading", "description": "Alias Overloading with 100+ aliases is allowed", "impact": "Denial of Service - /graphql", "severity": "HIGH", "color": "red", "curl_verify": "curl -X POST https://api.example.com/graphql"},
+{"result": false, "title": "Trace Mode", "description": "Tracing is Enabled", "impact": "Information Leakage - /graphql", "severity": "INFO", "curl_verify": "curl -X POST https://api.example.com/graphql"},
+{"result": true, "title": "Introspection", "description": "Introspection Query Enabled", "impact": "Information Leakage - /graphql", "severity": "HIGH", "curl_verify": ""}]`
+
+type ParseTestSuite struct {
+	suite.Suite
+	tool *Tool
+}
+
+func (s *ParseTestSuite) SetupTest() {
+	s.tool = New(zerolog.New(os.Stdout)).(*Tool)
+}
+
+func (s *ParseTestSuite) TestParseFindings() {
+	found, err := s.tool.ParseFindings(jsonOutput)
+	s.Require().NoError(err)
+	s.Require().Len(found, 2)
+	s.Equal("Alias Overloading (Denial of Service - /graphql)", found[0].Title)
+	s.Equal(types.SeverityHigh, found[0].Severity)
+	s.Equal("graphql-cop", found[0].Scanner)
+	s.Require().Len(found[0].Evidence, 1)
+	s.Equal(models.EvidenceCurl, found[0].Evidence[0].Kind)
+	s.Equal("Introspection (Information Leakage - /graphql)", found[1].Title)
+	s.Empty(found[1].Evidence)
+}
+
+func (s *ParseTestSuite) TestParseFindings_TextFallback() {
+	found, err := s.tool.ParseFindings("[high] introspection enabled at https://api.example.com/graphql")
+	s.Require().NoError(err)
+	s.Len(found, 1)
+}
+
+func TestParseTestSuite(t *testing.T) {
+	suite.Run(t, new(ParseTestSuite))
+}
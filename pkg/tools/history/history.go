@@ -3,35 +3,100 @@ package history
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/rs/zerolog"
+	"github.com/tb0hdan/wass-mcp/pkg/artifacts"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
 	"github.com/tb0hdan/wass-mcp/pkg/server"
 	"github.com/tb0hdan/wass-mcp/pkg/storage"
+	"github.com/tb0hdan/wass-mcp/pkg/tenant"
 	"github.com/tb0hdan/wass-mcp/pkg/tools"
+	"gorm.io/gorm"
 )
 
 type Input struct {
-	Action string `json:"action" validate:"required,oneof=list get delete clear"`
-	ID     uint   `json:"id,omitempty"`
-	Limit  int    `json:"limit,omitempty" validate:"min=0,max=100"`
-	Offset int    `json:"offset,omitempty" validate:"min=0"`
+	Action        string `json:"action" validate:"required,oneof=list get delete clear stats deleted restore purge rerun"`
+	AfterID       uint   `json:"after_id,omitempty"`
+	CorrelationID string `json:"correlation_id,omitempty"`
+	Hard          bool   `json:"hard,omitempty"`
+	Host          string `json:"host,omitempty"`
+	ID            uint   `json:"id,omitempty"`
+	IDs           []uint `json:"ids,omitempty" validate:"omitempty,max=100,unique,dive,min=1"`
+	Limit         int    `json:"limit,omitempty" validate:"min=0,max=100"`
+	Offset        int    `json:"offset,omitempty" validate:"min=0"`
+	Order         string `json:"order,omitempty" validate:"omitempty,oneof=asc desc"`
+	SessionID     string `json:"session_id,omitempty"`
+	Since         string `json:"since,omitempty" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	Sort          string `json:"sort,omitempty" validate:"omitempty,oneof=created_at duration_ms id risk_score tool_name"`
+	Success       *bool  `json:"success,omitempty"`
+	Tool          string `json:"tool,omitempty"`
+	Until         string `json:"until,omitempty" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+}
+
+// modifyingActions are the actions that change stored history or launch scans, refused to
+// read-only keys.
+var modifyingActions = map[string]struct{}{
+	"clear":   {},
+	"delete":  {},
+	"purge":   {},
+	"restore": {},
+	"rerun":   {},
+}
+
+// idActions are the actions taking a single execution id, batchActions those also taking a list
+// of ids, and pageActions those paginating by offset or after_id.
+var (
+	idActions    = []string{"delete", "get", "rerun", "restore"}
+	batchActions = []string{"delete", "get"}
+	pageActions  = []string{"deleted", "list"}
+)
+
+// batchResult is the result of a batch get or delete.
+type batchResult struct {
+	Deleted    []uint                 `json:"deleted,omitempty"`
+	Executions []models.ToolExecution `json:"executions,omitempty"`
+	Missing    []uint                 `json:"missing,omitempty"`
+}
+
+// riskPoint is a single execution in a risk score series.
+type riskPoint struct {
+	CreatedAt time.Time `json:"created_at"`
+	ID        uint      `json:"id"`
+	RiskScore float64   `json:"risk_score"`
+	Success   bool      `json:"success"`
+	ToolName  string    `json:"tool_name"`
 }
 
 type Tool struct {
 	logger    zerolog.Logger
 	validator *validator.Validate
+	server    *server.Server
 	store     storage.Storage
 }
 
 func (t *Tool) Register(srv *server.Server) error {
 	tool := &mcp.Tool{
-		Name:        "history",
-		Description: "Browse and manage tool execution history. Actions: list (paginated), get (by ID), delete (by ID), clear (all).",
+		Name: "history",
+		Description: "Browse and manage tool execution history. Actions: list (paginated by offset, or by after_id set to the next_after_id of the previous page, " +
+			"filterable by tool, session, correlation ID, success, " +
+			"target substring and since/until RFC3339 time range, sortable), get (by id, or ids for a batch), " +
+			"delete (by id, or ids for a batch, soft unless the server runs with --hard-delete), clear (all, soft unless hard=true), " +
+			"stats (risk score over the last scans of a host), deleted (list soft-deleted), " +
+			"restore (soft-deleted by id), purge (permanently remove soft-deleted), " +
+			"rerun (run the tool of an execution again with its stored input, by id). " +
+			"Invalid input is rejected with a JSON-RPC invalid params error naming the field.",
 	}
 
+	t.server = srv
 	t.store = srv.Storage()
 
 	mcp.AddTool(&srv.Server, tool, t.HistoryHandler)
@@ -41,8 +106,13 @@ func (t *Tool) Register(srv *server.Server) error {
 }
 
 func (t *Tool) HistoryHandler(ctx context.Context, _ *mcp.CallToolRequest, input Input) (*mcp.CallToolResult, any, error) {
-	if err := t.validator.Struct(input); err != nil {
-		return nil, nil, fmt.Errorf("validation error: %w", err)
+	if err := t.validate(input); err != nil {
+		return nil, nil, err
+	}
+	if _, modifies := modifyingActions[input.Action]; modifies {
+		if err := tenant.Authorize(ctx, input.Action+" executions"); err != nil {
+			return nil, nil, err
+		}
 	}
 
 	var resultText string
@@ -53,43 +123,112 @@ func (t *Tool) HistoryHandler(ctx context.Context, _ *mcp.CallToolRequest, input
 		if limit == 0 {
 			limit = 10
 		}
-		executions, total, err := t.store.GetToolExecutions(ctx, limit, input.Offset)
+		executions, total, err := t.store.QueryToolExecutions(ctx, listFilter(input, limit))
+		if errors.Is(err, storage.ErrCursorNotFound) {
+			return nil, nil, invalidInput(input.Action, "after_id", "does not match an execution")
+		}
 		if err != nil {
 			return nil, nil, fmt.Errorf("failed to list executions: %w", err)
 		}
-		data, _ := json.MarshalIndent(map[string]any{
-			"total":      total,
-			"limit":      limit,
-			"offset":     input.Offset,
-			"executions": executions,
-		}, "", "  ")
+		data, _ := json.MarshalIndent(listPage(executions, total, limit, input), "", "  ")
 		resultText = string(data)
 
 	case "get":
-		if input.ID == 0 {
-			return nil, nil, fmt.Errorf("id is required for get action")
+		if len(input.IDs) > 0 {
+			result, err := t.getBatch(ctx, input.IDs)
+			if err != nil {
+				return nil, nil, err
+			}
+			data, _ := json.MarshalIndent(result, "", "  ")
+			resultText = string(data)
+			break
 		}
 		exec, err := t.store.GetToolExecution(ctx, input.ID)
 		if err != nil {
 			return nil, nil, fmt.Errorf("execution not found: %w", err)
 		}
+		t.loadOutput(exec)
 		data, _ := json.MarshalIndent(exec, "", "  ")
 		resultText = string(data)
 
 	case "delete":
-		if input.ID == 0 {
-			return nil, nil, fmt.Errorf("id is required for delete action")
+		if len(input.IDs) > 0 {
+			result, err := t.deleteBatch(ctx, input.IDs)
+			if err != nil {
+				return nil, nil, err
+			}
+			data, _ := json.MarshalIndent(result, "", "  ")
+			resultText = string(data)
+			break
 		}
 		if err := t.store.DeleteToolExecution(ctx, input.ID); err != nil {
 			return nil, nil, fmt.Errorf("failed to delete execution: %w", err)
 		}
 		resultText = fmt.Sprintf("Execution %d deleted successfully", input.ID)
 
+	case "rerun":
+		exec, err := t.store.GetToolExecution(ctx, input.ID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("execution not found: %w", err)
+		}
+		if err := t.server.Rerun(ctx, *exec); err != nil {
+			return nil, nil, fmt.Errorf("failed to rerun execution %d: %w", exec.ID, err)
+		}
+		resultText = fmt.Sprintf("Execution %d (%s) re-run, see the latest %s execution", exec.ID, exec.ToolName, exec.ToolName)
+
 	case "clear":
 		if err := t.store.DeleteAllToolExecutions(ctx); err != nil {
 			return nil, nil, fmt.Errorf("failed to clear executions: %w", err)
 		}
 		resultText = "All execution history cleared"
+		if input.Hard {
+			if _, err := t.store.PurgeDeletedToolExecutions(ctx); err != nil {
+				return nil, nil, fmt.Errorf("failed to purge executions: %w", err)
+			}
+			resultText = "All execution history permanently deleted"
+		}
+
+	case "deleted":
+		limit := input.Limit
+		if limit == 0 {
+			limit = 10
+		}
+		filter := listFilter(input, limit)
+		filter.Deleted = true
+		executions, total, err := t.store.QueryToolExecutions(ctx, filter)
+		if errors.Is(err, storage.ErrCursorNotFound) {
+			return nil, nil, invalidInput(input.Action, "after_id", "does not match an execution")
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to list deleted executions: %w", err)
+		}
+		data, _ := json.MarshalIndent(listPage(executions, total, limit, input), "", "  ")
+		resultText = string(data)
+
+	case "restore":
+		if err := t.store.RestoreToolExecution(ctx, input.ID); err != nil {
+			return nil, nil, fmt.Errorf("failed to restore execution: %w", err)
+		}
+		resultText = fmt.Sprintf("Execution %d restored successfully", input.ID)
+
+	case "purge":
+		purged, err := t.store.PurgeDeletedToolExecutions(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to purge executions: %w", err)
+		}
+		resultText = fmt.Sprintf("%d deleted executions permanently purged", purged)
+
+	case "stats":
+		limit := input.Limit
+		if limit == 0 {
+			limit = 10
+		}
+		executions, err := t.store.GetToolExecutionsByHost(ctx, input.Host, limit)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get executions: %w", err)
+		}
+		data, _ := json.MarshalIndent(riskStats(input.Host, executions), "", "  ")
+		resultText = string(data)
 	}
 
 	return &mcp.CallToolResult{
@@ -99,9 +238,200 @@ func (t *Tool) HistoryHandler(ctx context.Context, _ *mcp.CallToolRequest, input
 	}, nil, nil
 }
 
+// validate checks input, returning a structured invalid params error, see invalidInput. The id is
+// only accepted by idActions and the ids by batchActions, one of them being required there, and
+// stats requires a host.
+func (t *Tool) validate(input Input) error {
+	if err := t.validator.Struct(input); err != nil {
+		var fieldErrors validator.ValidationErrors
+		if errors.As(err, &fieldErrors) && len(fieldErrors) > 0 {
+			field := fieldErrors[0]
+			return invalidInput(input.Action, field.Field(), fmt.Sprintf("failed %s validation", field.Tag()))
+		}
+		return invalidInput(input.Action, "", err.Error())
+	}
+
+	takesID := slices.Contains(idActions, input.Action)
+	takesIDs := slices.Contains(batchActions, input.Action)
+	switch {
+	case input.ID != 0 && !takesID:
+		return invalidInput(input.Action, "id", "only accepted by the "+strings.Join(idActions, ", ")+" actions")
+	case len(input.IDs) > 0 && !takesIDs:
+		return invalidInput(input.Action, "ids", "only accepted by the "+strings.Join(batchActions, ", ")+" actions")
+	case input.ID != 0 && len(input.IDs) > 0:
+		return invalidInput(input.Action, "ids", "cannot be combined with id")
+	case takesIDs && input.ID == 0 && len(input.IDs) == 0:
+		return invalidInput(input.Action, "id", "id or ids is required")
+	case takesID && !takesIDs && input.ID == 0:
+		return invalidInput(input.Action, "id", "required")
+	case input.Action == "stats" && input.Host == "":
+		return invalidInput(input.Action, "host", "required")
+	case input.AfterID != 0 && !slices.Contains(pageActions, input.Action):
+		return invalidInput(input.Action, "after_id", "only accepted by the "+strings.Join(pageActions, ", ")+" actions")
+	case input.AfterID != 0 && input.Offset != 0:
+		return invalidInput(input.Action, "after_id", "cannot be combined with offset")
+	}
+
+	return nil
+}
+
+// invalidInput returns a JSON-RPC invalid params error whose data names the action, the input
+// field and the reason, so that clients can tell what to fix.
+func invalidInput(action, field, reason string) error {
+	data, _ := json.Marshal(map[string]string{
+		"action": action,
+		"field":  field,
+		"reason": reason,
+	})
+
+	message := fmt.Sprintf("invalid history input: %s", reason)
+	if field != "" {
+		message = fmt.Sprintf("invalid history input: %s: %s", field, reason)
+	}
+
+	return &jsonrpc.Error{
+		Code:    jsonrpc.CodeInvalidParams,
+		Message: message,
+		Data:    data,
+	}
+}
+
+// getBatch loads the executions of ids, listing the IDs not found as missing.
+func (t *Tool) getBatch(ctx context.Context, ids []uint) (batchResult, error) {
+	var result batchResult
+	for _, id := range ids {
+		exec, err := t.store.GetToolExecution(ctx, id)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			result.Missing = append(result.Missing, id)
+			continue
+		}
+		if err != nil {
+			return batchResult{}, fmt.Errorf("failed to get execution %d: %w", id, err)
+		}
+		t.loadOutput(exec)
+		result.Executions = append(result.Executions, *exec)
+	}
+
+	return result, nil
+}
+
+// deleteBatch deletes the executions of ids, listing the IDs not found as missing.
+func (t *Tool) deleteBatch(ctx context.Context, ids []uint) (batchResult, error) {
+	var result batchResult
+	for _, id := range ids {
+		_, err := t.store.GetToolExecution(ctx, id)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			result.Missing = append(result.Missing, id)
+			continue
+		}
+		if err == nil {
+			err = t.store.DeleteToolExecution(ctx, id)
+		}
+		if err != nil {
+			return batchResult{}, fmt.Errorf("failed to delete execution %d: %w", id, err)
+		}
+		result.Deleted = append(result.Deleted, id)
+	}
+
+	return result, nil
+}
+
+// loadOutput replaces the output preview of a spilled execution with the full output.
+func (t *Tool) loadOutput(exec *models.ToolExecution) {
+	if exec.OutputFile == "" {
+		return
+	}
+	output, err := artifacts.LoadOutput(exec)
+	if err != nil {
+		t.logger.Warn().Err(err).Msgf("Returning output preview for execution %d", exec.ID)
+	}
+	exec.OutputJSON = output
+}
+
+// listPage returns the response of a page of the list and deleted actions: the executions, their
+// total and the pagination, with the next_after_id to continue from when the page is full.
+func listPage(executions []models.ToolExecution, total int64, limit int, input Input) map[string]any {
+	page := map[string]any{
+		"total":      total,
+		"limit":      limit,
+		"offset":     input.Offset,
+		"executions": executions,
+	}
+	if input.AfterID > 0 {
+		delete(page, "offset")
+		page["after_id"] = input.AfterID
+	}
+	if len(executions) == limit {
+		page["next_after_id"] = executions[len(executions)-1].ID
+	}
+
+	return page
+}
+
+// listFilter builds the storage filter for the list action. Time bounds are validated as RFC3339.
+func listFilter(input Input, limit int) storage.ExecutionFilter {
+	filter := storage.ExecutionFilter{
+		AfterID:       input.AfterID,
+		Ascending:     input.Order == "asc",
+		CorrelationID: input.CorrelationID,
+		Limit:         limit,
+		Offset:        input.Offset,
+		SessionID:     input.SessionID,
+		SortBy:        input.Sort,
+		Success:       input.Success,
+		Target:        input.Host,
+		ToolName:      input.Tool,
+	}
+	if since, err := time.Parse(time.RFC3339, input.Since); err == nil {
+		filter.Since = since
+	}
+	if until, err := time.Parse(time.RFC3339, input.Until); err == nil {
+		filter.Until = until
+	}
+
+	return filter
+}
+
+// riskStats builds the risk score series (oldest first) and aggregates for a host.
+func riskStats(host string, executions []models.ToolExecution) map[string]any {
+	points := make([]riskPoint, 0, len(executions))
+	var total float64
+	for i := len(executions) - 1; i >= 0; i-- {
+		exec := executions[i]
+		points = append(points, riskPoint{
+			CreatedAt: exec.CreatedAt,
+			ID:        exec.ID,
+			RiskScore: exec.RiskScore,
+			Success:   exec.Success,
+			ToolName:  exec.ToolName,
+		})
+		total += exec.RiskScore
+	}
+
+	stats := map[string]any{
+		"host":   host,
+		"count":  len(points),
+		"series": points,
+	}
+	if len(points) > 0 {
+		latest := points[len(points)-1].RiskScore
+		stats["latest"] = latest
+		stats["average"] = total / float64(len(points))
+		stats["change"] = latest - points[0].RiskScore
+	}
+
+	return stats
+}
+
 func New(logger zerolog.Logger) tools.Tool {
+	validate := validator.New()
+	// Report input fields by their JSON names.
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		return strings.Split(field.Tag.Get("json"), ",")[0]
+	})
+
 	return &Tool{
 		logger:    logger.With().Str("tool", "history").Logger(),
-		validator: validator.New(),
+		validator: validate,
 	}
 }
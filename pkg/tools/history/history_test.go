@@ -3,14 +3,21 @@ package history
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"os"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/rs/zerolog"
+	"github.com/tb0hdan/wass-mcp/pkg/artifacts"
 	"github.com/tb0hdan/wass-mcp/pkg/models"
 	"github.com/tb0hdan/wass-mcp/pkg/server"
 	"github.com/tb0hdan/wass-mcp/pkg/storage"
+	"github.com/tb0hdan/wass-mcp/pkg/tenant"
 )
 
 func setupTestServer(t *testing.T) (*server.Server, func()) {
@@ -138,6 +145,78 @@ func TestHistoryHandler_List_WithData(t *testing.T) {
 	}
 }
 
+func TestHistoryHandler_List_Filters(t *testing.T) {
+	srv, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	store := srv.Storage()
+
+	executions := []*models.ToolExecution{
+		{ToolName: "nikto", Target: "http://example.com", Host: "example.com", Success: true},
+		{ToolName: "nikto", Target: "http://other.org", Host: "other.org", Success: false},
+		{ToolName: "wapiti", Target: "http://example.com", Host: "example.com", Success: true},
+	}
+	for _, exec := range executions {
+		if err := store.CreateToolExecution(ctx, exec); err != nil {
+			t.Fatalf("failed to create execution: %v", err)
+		}
+	}
+
+	logger := zerolog.New(os.Stdout)
+	tool := New(logger).(*Tool)
+	tool.store = store
+
+	success := true
+	input := Input{Action: "list", Tool: "nikto", Host: "example", Success: &success, Order: "asc", Sort: "id"}
+
+	result, _, err := tool.HistoryHandler(ctx, nil, input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var response map[string]any
+	if err := json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &response); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if response["total"].(float64) != 1 {
+		t.Errorf("expected total 1, got %v", response["total"])
+	}
+
+	// Time range excluding everything
+	input = Input{Action: "list", Until: time.Now().Add(-time.Hour).Format(time.RFC3339)}
+	result, _, err = tool.HistoryHandler(ctx, nil, input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &response); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if response["total"].(float64) != 0 {
+		t.Errorf("expected total 0, got %v", response["total"])
+	}
+}
+
+func TestHistoryHandler_List_InvalidFilters(t *testing.T) {
+	srv, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	logger := zerolog.New(os.Stdout)
+	tool := New(logger).(*Tool)
+	tool.store = srv.Storage()
+
+	inputs := []Input{
+		{Action: "list", Since: "yesterday"},
+		{Action: "list", Sort: "input_json"},
+		{Action: "list", Order: "sideways"},
+	}
+	for _, input := range inputs {
+		if _, _, err := tool.HistoryHandler(context.Background(), nil, input); err == nil {
+			t.Errorf("expected validation error for %+v", input)
+		}
+	}
+}
+
 func TestHistoryHandler_List_Pagination(t *testing.T) {
 	srv, cleanup := setupTestServer(t)
 	defer cleanup()
@@ -177,6 +256,65 @@ func TestHistoryHandler_List_Pagination(t *testing.T) {
 	}
 }
 
+func TestHistoryHandler_List_AfterID(t *testing.T) {
+	srv, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	store := srv.Storage()
+	for i := 0; i < 5; i++ {
+		if err := store.CreateToolExecution(ctx, &models.ToolExecution{ToolName: "nikto"}); err != nil {
+			t.Fatalf("failed to create execution: %v", err)
+		}
+	}
+
+	tool := New(zerolog.New(os.Stdout)).(*Tool)
+	tool.store = store
+
+	var ids []float64
+	input := Input{Action: "list", Limit: 2}
+	for pages := 0; pages < 5; pages++ {
+		result, _, err := tool.HistoryHandler(ctx, nil, input)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var response struct {
+			AfterID    *uint            `json:"after_id"`
+			Executions []map[string]any `json:"executions"`
+			NextAfter  uint             `json:"next_after_id"`
+			Offset     *int             `json:"offset"`
+			Total      int64            `json:"total"`
+		}
+		if err := json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &response); err != nil {
+			t.Fatalf("failed to parse response: %v", err)
+		}
+		if response.Total != 5 {
+			t.Errorf("expected total 5, got %d", response.Total)
+		}
+		if input.AfterID > 0 && (response.AfterID == nil || response.Offset != nil) {
+			t.Errorf("expected after_id instead of offset in the response, got %+v", response)
+		}
+		for _, exec := range response.Executions {
+			ids = append(ids, exec["id"].(float64))
+		}
+		if response.NextAfter == 0 {
+			break
+		}
+		input.AfterID = response.NextAfter
+	}
+
+	if want := []float64{5, 4, 3, 2, 1}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("expected pages %v, got %v", want, ids)
+	}
+
+	for _, action := range []string{"list", "deleted"} {
+		_, _, err := tool.HistoryHandler(ctx, nil, Input{Action: action, AfterID: 99})
+		if err == nil || !strings.Contains(err.Error(), "after_id: does not match an execution") {
+			t.Errorf("%s: expected an invalid after_id error, got %v", action, err)
+		}
+	}
+}
+
 func TestHistoryHandler_Get(t *testing.T) {
 	srv, cleanup := setupTestServer(t)
 	defer cleanup()
@@ -217,6 +355,47 @@ func TestHistoryHandler_Get(t *testing.T) {
 	}
 }
 
+func TestHistoryHandler_Get_SpilledOutput(t *testing.T) {
+	srv, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	store := srv.Storage()
+
+	exec := &models.ToolExecution{
+		ToolName:   "nikto",
+		OutputJSON: `{"content":[{"type":"text","text":"full output"}]}`,
+		Phases:     models.PhaseTimings{ExecMs: 1200, ParseMs: 3},
+		Success:    true,
+	}
+	if err := artifacts.SpillOutput(artifacts.Config{Dir: t.TempDir(), MaxOutputBytes: 10}, exec); err != nil {
+		t.Fatalf("failed to spill output: %v", err)
+	}
+	if err := store.CreateToolExecution(ctx, exec); err != nil {
+		t.Fatalf("failed to create execution: %v", err)
+	}
+
+	logger := zerolog.New(os.Stdout)
+	tool := New(logger).(*Tool)
+	tool.store = store
+
+	result, _, err := tool.HistoryHandler(ctx, nil, Input{Action: "get", ID: exec.ID})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var response models.ToolExecution
+	if err := json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &response); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if response.OutputJSON != `{"content":[{"type":"text","text":"full output"}]}` {
+		t.Errorf("expected full output from artifact, got %s", response.OutputJSON)
+	}
+	if response.Phases != exec.Phases {
+		t.Errorf("expected phase timings %+v, got %+v", exec.Phases, response.Phases)
+	}
+}
+
 func TestHistoryHandler_Get_NotFound(t *testing.T) {
 	srv, cleanup := setupTestServer(t)
 	defer cleanup()
@@ -344,6 +523,108 @@ func TestHistoryHandler_Clear(t *testing.T) {
 	}
 }
 
+func TestHistoryHandler_Clear_Hard(t *testing.T) {
+	srv, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	store := srv.Storage()
+
+	for i := 0; i < 3; i++ {
+		exec := &models.ToolExecution{
+			ToolName: "nikto",
+			Success:  true,
+		}
+		store.CreateToolExecution(ctx, exec)
+	}
+
+	logger := zerolog.New(os.Stdout)
+	tool := New(logger).(*Tool)
+	tool.store = store
+
+	result, _, err := tool.HistoryHandler(ctx, nil, Input{Action: "clear", Hard: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	textContent := result.Content[0].(*mcp.TextContent)
+	if textContent.Text != "All execution history permanently deleted" {
+		t.Errorf("unexpected message: %s", textContent.Text)
+	}
+
+	// Nothing is left to restore
+	_, total, _ := store.QueryToolExecutions(ctx, storage.ExecutionFilter{Deleted: true})
+	if total != 0 {
+		t.Errorf("expected 0 deleted executions after hard clear, got %d", total)
+	}
+}
+
+func TestHistoryHandler_DeletedRestorePurge(t *testing.T) {
+	srv, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	store := srv.Storage()
+
+	var ids []uint
+	for i := 0; i < 2; i++ {
+		exec := &models.ToolExecution{
+			ToolName: "nikto",
+			Success:  true,
+		}
+		if err := store.CreateToolExecution(ctx, exec); err != nil {
+			t.Fatalf("failed to create execution: %v", err)
+		}
+		ids = append(ids, exec.ID)
+	}
+
+	logger := zerolog.New(os.Stdout)
+	tool := New(logger).(*Tool)
+	tool.store = store
+
+	for _, id := range ids {
+		if _, _, err := tool.HistoryHandler(ctx, nil, Input{Action: "delete", ID: id}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// List deleted
+	result, _, err := tool.HistoryHandler(ctx, nil, Input{Action: "deleted"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var response map[string]any
+	if err := json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &response); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if response["total"].(float64) != 2 {
+		t.Errorf("expected 2 deleted executions, got %v", response["total"])
+	}
+
+	// Restore one
+	if _, _, err := tool.HistoryHandler(ctx, nil, Input{Action: "restore"}); err == nil {
+		t.Error("expected error for restore without ID")
+	}
+	if _, _, err := tool.HistoryHandler(ctx, nil, Input{Action: "restore", ID: ids[0]}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := store.GetToolExecution(ctx, ids[0]); err != nil {
+		t.Errorf("expected restored execution to be retrievable: %v", err)
+	}
+
+	// Purge the other
+	result, _, err = tool.HistoryHandler(ctx, nil, Input{Action: "purge"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := result.Content[0].(*mcp.TextContent).Text; text != "1 deleted executions permanently purged" {
+		t.Errorf("unexpected message: %s", text)
+	}
+	if _, _, err := tool.HistoryHandler(ctx, nil, Input{Action: "restore", ID: ids[1]}); err == nil {
+		t.Error("expected error when restoring purged execution")
+	}
+}
+
 func TestHistoryHandler_InvalidAction(t *testing.T) {
 	srv, cleanup := setupTestServer(t)
 	defer cleanup()
@@ -446,3 +727,255 @@ func TestRegister_SetsStorage(t *testing.T) {
 		t.Errorf("expected 0 executions, got %d", total)
 	}
 }
+
+func TestHistoryHandler_Stats(t *testing.T) {
+	srv, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	store := srv.Storage()
+
+	scores := []float64{12, 7, 3}
+	for _, score := range scores {
+		exec := &models.ToolExecution{
+			ToolName:  "full_scan",
+			Host:      "example.com",
+			RiskScore: score,
+			Success:   true,
+		}
+		if err := store.CreateToolExecution(ctx, exec); err != nil {
+			t.Fatalf("failed to create execution: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	other := &models.ToolExecution{ToolName: "nikto", Host: "other.com", RiskScore: 50}
+	if err := store.CreateToolExecution(ctx, other); err != nil {
+		t.Fatalf("failed to create execution: %v", err)
+	}
+
+	tool := New(zerolog.Nop()).(*Tool)
+	tool.store = store
+
+	result, _, err := tool.HistoryHandler(ctx, nil, Input{Action: "stats", Host: "example.com"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var response map[string]any
+	if err := json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &response); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+
+	if response["count"].(float64) != 3 {
+		t.Errorf("expected 3 executions, got %v", response["count"])
+	}
+	series := response["series"].([]any)
+	if first := series[0].(map[string]any)["risk_score"].(float64); first != 12 {
+		t.Errorf("expected oldest score first (12), got %v", first)
+	}
+	if response["latest"].(float64) != 3 {
+		t.Errorf("expected latest score 3, got %v", response["latest"])
+	}
+	if response["change"].(float64) != -9 {
+		t.Errorf("expected change -9, got %v", response["change"])
+	}
+}
+
+func TestHistoryHandler_Stats_MissingHost(t *testing.T) {
+	srv, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	tool := New(zerolog.Nop()).(*Tool)
+	tool.store = srv.Storage()
+
+	_, _, err := tool.HistoryHandler(context.Background(), nil, Input{Action: "stats"})
+	if err == nil {
+		t.Fatal("expected error for stats without host")
+	}
+}
+
+func TestHistoryHandler_ReadOnlyKey(t *testing.T) {
+	srv, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	logger := zerolog.New(os.Stdout)
+	tool := New(logger).(*Tool)
+	tool.store = srv.Storage()
+
+	exec := &models.ToolExecution{ToolName: "nikto", InputJSON: "{}", Success: true}
+	if err := srv.Storage().CreateToolExecution(context.Background(), exec); err != nil {
+		t.Fatalf("failed to create execution: %v", err)
+	}
+
+	ctx := tenant.WithRole(context.Background(), tenant.RoleReadOnly)
+
+	for _, input := range []Input{
+		{Action: "list"},
+		{Action: "get", ID: exec.ID},
+		{Action: "stats", Host: "example.com"},
+		{Action: "deleted"},
+	} {
+		if _, _, err := tool.HistoryHandler(ctx, nil, input); err != nil {
+			t.Errorf("expected read-only key to %s, got: %v", input.Action, err)
+		}
+	}
+
+	for _, input := range []Input{
+		{Action: "delete", ID: exec.ID},
+		{Action: "clear"},
+		{Action: "restore", ID: exec.ID},
+		{Action: "purge"},
+		{Action: "rerun", ID: exec.ID},
+	} {
+		action := input.Action
+		_, _, err := tool.HistoryHandler(ctx, nil, input)
+		var wireErr *jsonrpc.Error
+		if !errors.As(err, &wireErr) || wireErr.Code != tenant.CodeForbidden {
+			t.Errorf("expected read-only key to be forbidden to %s, got: %v", action, err)
+		}
+	}
+
+	if _, err := srv.Storage().GetToolExecution(context.Background(), exec.ID); err != nil {
+		t.Errorf("expected execution to be kept: %v", err)
+	}
+}
+
+func TestHistoryHandler_InvalidInput(t *testing.T) {
+	srv, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	logger := zerolog.New(os.Stdout)
+	tool := New(logger).(*Tool)
+	tool.store = srv.Storage()
+
+	tests := []struct {
+		input  Input
+		field  string
+		reason string
+	}{
+		{Input{Action: "list", ID: 1}, "id", "only accepted by the delete, get, rerun, restore actions"},
+		{Input{Action: "clear", ID: 1}, "id", "only accepted by the delete, get, rerun, restore actions"},
+		{Input{Action: "restore", IDs: []uint{1, 2}}, "ids", "only accepted by the delete, get actions"},
+		{Input{Action: "get", ID: 1, IDs: []uint{2}}, "ids", "cannot be combined with id"},
+		{Input{Action: "get"}, "id", "id or ids is required"},
+		{Input{Action: "rerun"}, "id", "required"},
+		{Input{Action: "stats"}, "host", "required"},
+		{Input{Action: "get", ID: 1, AfterID: 2}, "after_id", "only accepted by the deleted, list actions"},
+		{Input{Action: "list", AfterID: 2, Offset: 5}, "after_id", "cannot be combined with offset"},
+		{Input{Action: "get", IDs: []uint{1, 1}}, "ids", "failed unique validation"},
+		{Input{Action: "delete", IDs: []uint{1, 0}}, "ids[1]", "failed min validation"},
+		{Input{Action: "list", Limit: 101}, "limit", "failed max validation"},
+		{Input{Action: "invalid"}, "action", "failed oneof validation"},
+	}
+
+	for _, tt := range tests {
+		_, _, err := tool.HistoryHandler(context.Background(), nil, tt.input)
+		var wireErr *jsonrpc.Error
+		if !errors.As(err, &wireErr) || wireErr.Code != jsonrpc.CodeInvalidParams {
+			t.Errorf("%+v: expected invalid params error, got: %v", tt.input, err)
+			continue
+		}
+		var data map[string]string
+		if err := json.Unmarshal(wireErr.Data, &data); err != nil {
+			t.Fatalf("failed to parse error data: %v", err)
+		}
+		if data["action"] != tt.input.Action || data["field"] != tt.field || data["reason"] != tt.reason {
+			t.Errorf("%+v: unexpected error data %v", tt.input, data)
+		}
+	}
+}
+
+func TestHistoryHandler_Batch(t *testing.T) {
+	srv, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	store := srv.Storage()
+
+	var ids []uint
+	for i := 0; i < 3; i++ {
+		exec := &models.ToolExecution{ToolName: "nikto", Success: true}
+		if err := store.CreateToolExecution(ctx, exec); err != nil {
+			t.Fatalf("failed to create execution: %v", err)
+		}
+		ids = append(ids, exec.ID)
+	}
+
+	logger := zerolog.New(os.Stdout)
+	tool := New(logger).(*Tool)
+	tool.store = store
+
+	result, _, err := tool.HistoryHandler(ctx, nil, Input{Action: "get", IDs: []uint{ids[0], 99999, ids[2]}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got batchResult
+	if err := json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &got); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if len(got.Executions) != 2 || got.Executions[0].ID != ids[0] || got.Executions[1].ID != ids[2] {
+		t.Errorf("expected executions %d and %d, got %+v", ids[0], ids[2], got.Executions)
+	}
+	if len(got.Missing) != 1 || got.Missing[0] != 99999 {
+		t.Errorf("expected 99999 missing, got %v", got.Missing)
+	}
+
+	result, _, err = tool.HistoryHandler(ctx, nil, Input{Action: "delete", IDs: []uint{ids[0], ids[1], 99999}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got = batchResult{}
+	if err := json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &got); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if len(got.Deleted) != 2 || len(got.Missing) != 1 {
+		t.Errorf("expected 2 deleted and 1 missing, got %+v", got)
+	}
+
+	executions, total, err := store.GetToolExecutions(ctx, 10, 0)
+	if err != nil || total != 1 || executions[0].ID != ids[2] {
+		t.Errorf("expected only execution %d kept, got %d (err: %v)", ids[2], total, err)
+	}
+}
+
+func TestHistoryHandler_Rerun(t *testing.T) {
+	srv, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	store := srv.Storage()
+
+	var rerunInput string
+	srv.RegisterRerun("nikto", func(_ context.Context, inputJSON string, _ int) error {
+		rerunInput = inputJSON
+		return nil
+	})
+
+	exec := &models.ToolExecution{ToolName: "nikto", InputJSON: `{"host":"example.com"}`, Success: true}
+	other := &models.ToolExecution{ToolName: "unknown", InputJSON: "{}", Success: true}
+	for _, e := range []*models.ToolExecution{exec, other} {
+		if err := store.CreateToolExecution(ctx, e); err != nil {
+			t.Fatalf("failed to create execution: %v", err)
+		}
+	}
+
+	logger := zerolog.New(os.Stdout)
+	tool := New(logger).(*Tool)
+	if err := tool.Register(srv); err != nil {
+		t.Fatalf("failed to register: %v", err)
+	}
+
+	if _, _, err := tool.HistoryHandler(ctx, nil, Input{Action: "rerun", ID: exec.ID}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rerunInput != exec.InputJSON {
+		t.Errorf("expected rerun with stored input, got %q", rerunInput)
+	}
+
+	if _, _, err := tool.HistoryHandler(ctx, nil, Input{Action: "rerun", ID: other.ID}); err == nil {
+		t.Error("expected error for unregistered tool")
+	}
+	if _, _, err := tool.HistoryHandler(ctx, nil, Input{Action: "rerun", ID: 99999}); err == nil {
+		t.Error("expected error for non-existent ID")
+	}
+}
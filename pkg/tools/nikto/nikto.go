@@ -3,13 +3,13 @@ package nikto
 import (
 	"context"
 	"fmt"
-	"os/exec"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/rs/zerolog"
 	"github.com/tb0hdan/wass-mcp/pkg/server"
 	"github.com/tb0hdan/wass-mcp/pkg/tools"
 	"github.com/tb0hdan/wass-mcp/pkg/types"
+	"github.com/tb0hdan/wass-mcp/pkg/vault"
 )
 
 const (
@@ -18,6 +18,11 @@ const (
 	headerVerb  = "output"
 )
 
+// supportedOptions are the scan options nikto honours.
+var supportedOptions = []string{
+	tools.OptionCapture, tools.OptionConfig, tools.OptionCredential, tools.OptionUserAgent, tools.OptionVhost,
+}
+
 // Tool implements the nikto scanner.
 type Tool struct {
 	tools.BaseScanner
@@ -25,19 +30,36 @@ type Tool struct {
 
 // Scan performs the nikto scan and returns the output.
 func (t *Tool) Scan(ctx context.Context, params tools.ScanParams) tools.ScanResult {
-	targetURL := tools.BuildTargetURL(params)
-	t.Logger.Info().Msgf("Running nikto scan on %s", targetURL)
+	targetURL := params.Target().URL()
+	logger := tools.ContextLogger(ctx, t.Logger)
+	logger.Info().Msgf("Running nikto scan on %s", targetURL)
 
-	args := []string{"-host", params.Host, "-port", fmt.Sprint(params.Port)}
-	if params.Scheme == types.SchemeHTTPS {
-		args = append(args, "-ssl")
+	// Nikto sends no custom headers, only basic authentication.
+	if err := params.CheckCredential(binaryName, vault.TypeBasic); err != nil {
+		return tools.ScanResult{
+			Error: err,
+		}
 	}
-	if params.Vhost != "" {
-		args = append(args, "-vhost", params.Vhost)
+
+	configFile, err := t.ConfigFile(params)
+	if err != nil {
+		return tools.ScanResult{
+			Error: err,
+		}
+	}
+
+	workDir, cleanup, err := t.WorkDir(ctx)
+	if err != nil {
+		return tools.ScanResult{
+			Error: err,
+		}
 	}
+	defer cleanup()
 
-	cmd := exec.CommandContext(ctx, binaryName, args...) //nolint:gosec
-	output, err := cmd.CombinedOutput()
+	cmd := t.Command(ctx, buildArgs(params, configFile)...)
+	cmd.Dir = workDir
+	cmd.Env = tools.ScanEnv(params, workDir)
+	output, err := t.CombinedOutput(ctx, cmd)
 
 	if err != nil {
 		return tools.ScanResult{
@@ -52,6 +74,35 @@ func (t *Tool) Scan(ctx context.Context, params tools.ScanParams) tools.ScanResu
 	}
 }
 
+// buildArgs builds the nikto command line arguments, reading configFile instead of the default
+// nikto.conf when set.
+func buildArgs(params tools.ScanParams, configFile string) []string {
+	args := []string{"-host", params.Host, "-port", fmt.Sprint(params.Port)}
+	if configFile != "" {
+		args = append(args, "-config", configFile)
+	}
+	if params.Scheme == types.SchemeHTTPS {
+		args = append(args, "-ssl")
+	}
+	if params.Vhost != "" {
+		args = append(args, "-vhost", params.Vhost)
+	}
+	if params.Path != "" {
+		args = append(args, "-root", params.Path)
+	}
+	if userAgent := params.Option(tools.OptionUserAgent); userAgent != "" {
+		args = append(args, "-useragent", userAgent)
+	}
+	if params.Proxy != "" {
+		args = append(args, "-useproxy", params.Proxy)
+	}
+	if params.Credential != nil {
+		args = append(args, "-id", params.Credential.Username+":"+params.Credential.Password)
+	}
+
+	return args
+}
+
 // Register registers the nikto tool with the MCP server.
 func (t *Tool) Register(srv *server.Server) error {
 	return t.RegisterTool(srv, t.Handler)
@@ -59,32 +110,13 @@ func (t *Tool) Register(srv *server.Server) error {
 
 // Handler handles MCP tool requests.
 func (t *Tool) Handler(ctx context.Context, _ *mcp.CallToolRequest, input tools.ScannerInput) (*mcp.CallToolResult, any, error) {
-	input = t.PrepareInput(input)
-
-	if err := t.ValidateInput(input); err != nil {
-		return nil, nil, err
-	}
-
-	params := t.ResolveInput(input)
-
-	scanResult := t.Scan(ctx, params)
-	if scanResult.Error != nil {
-		return nil, nil, fmt.Errorf("%w\nOutput: %s", scanResult.Error, scanResult.Output)
-	}
-
-	targetURL := tools.BuildTargetURL(params)
-	resultText := tools.FormatScannerOutput(binaryName, headerVerb, targetURL, scanResult.Output, input.MaxLines, input.Offset)
-
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{
-			&mcp.TextContent{Text: resultText},
-		},
-	}, nil, nil
+	return t.HandleScan(ctx, input, headerVerb, t.Scan)
 }
 
 // New creates a new nikto scanner tool.
 func New(logger zerolog.Logger) tools.Scanner {
-	return &Tool{
-		BaseScanner: tools.NewBaseScanner(binaryName, description, logger),
-	}
+	base := tools.NewBaseScanner(binaryName, description, logger, supportedOptions...)
+	base.VersionArgs = []string{"-Version"}
+
+	return &Tool{BaseScanner: base}
 }
@@ -3,6 +3,7 @@ package nikto
 import (
 	"context"
 	"os"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -10,7 +11,9 @@ import (
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/scanconfig"
 	"github.com/tb0hdan/wass-mcp/pkg/tools"
+	"github.com/tb0hdan/wass-mcp/pkg/vault"
 )
 
 // scanTestTimeout is a short timeout for tests that invoke the actual scanner.
@@ -313,6 +316,54 @@ func (s *NiktoTestSuite) TestScan_WithVhost() {
 	}
 }
 
+func (s *NiktoTestSuite) TestBuildArgs() {
+	args := buildArgs(tools.ScanParams{Host: "localhost", Port: 443, Scheme: "https"}, "")
+	s.Equal([]string{"-host", "localhost", "-port", "443", "-ssl"}, args)
+
+	args = buildArgs(tools.ScanParams{Host: "localhost", Port: 80}, "/etc/wass/scanners/nikto/tuned.conf")
+	s.Equal([]string{"-host", "localhost", "-port", "80", "-config", "/etc/wass/scanners/nikto/tuned.conf"}, args)
+}
+
+func (s *NiktoTestSuite) TestBuildArgs_SSL() {
+	for name, tc := range map[string]struct {
+		input tools.ScannerInput
+		ssl   bool
+	}{
+		"https scheme":    {tools.ScannerInput{Host: "localhost", Port: 8443, Scheme: "https"}, true},
+		"https URL":       {tools.ScannerInput{Host: "https://localhost:9443/app"}, true},
+		"port 443":        {tools.ScannerInput{Host: "localhost", Port: 443}, true},
+		"http on 443":     {tools.ScannerInput{Host: "localhost", Port: 443, Scheme: "http"}, false},
+		"http by default": {tools.ScannerInput{Host: "localhost", Port: 8080}, false},
+	} {
+		args := buildArgs(tools.ResolveParams(tc.input), "")
+		s.Equal(tc.ssl, slices.Contains(args, "-ssl"), name)
+	}
+}
+
+func (s *NiktoTestSuite) TestBuildArgs_Credential() {
+	credential := &vault.Credential{Type: vault.TypeBasic, Secret: vault.Secret{Username: "admin", Password: "s3cret"}}
+	args := buildArgs(tools.ScanParams{Host: "localhost", Port: 80, Credential: credential}, "")
+	s.Equal([]string{"-host", "localhost", "-port", "80", "-id", "admin:s3cret"}, args)
+}
+
+func (s *NiktoTestSuite) TestScan_UnsupportedCredential() {
+	result := s.tool.Scan(context.Background(), tools.ScanParams{
+		Host:       "localhost",
+		Port:       80,
+		Credential: &vault.Credential{Type: vault.TypeBearer, Secret: vault.Secret{Token: "abc"}},
+	})
+	s.ErrorIs(result.Error, vault.ErrUnsupportedType)
+}
+
+func (s *NiktoTestSuite) TestScan_ConfigOverrideDisabled() {
+	result := s.tool.Scan(context.Background(), tools.ScanParams{
+		Host:    "localhost",
+		Port:    80,
+		Options: map[string]string{tools.OptionConfig: "tuned.conf"},
+	})
+	s.ErrorIs(result.Error, scanconfig.ErrOverridesDisabled)
+}
+
 func TestNiktoTestSuite(t *testing.T) {
 	suite.Run(t, new(NiktoTestSuite))
 }
@@ -0,0 +1,107 @@
+package nikto
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/types"
+)
+
+var (
+	// pathRe matches the leading path of a nikto finding, optionally after a reference ID.
+	pathRe = regexp.MustCompile(`^(?:[A-Z]+-\d+: )?(/\S*): `)
+	// leadingIDRe matches the reference ID older nikto versions start findings with.
+	leadingIDRe = regexp.MustCompile(`^([A-Z]+-\d+): `)
+	// urlRe matches absolute HTTP(S) URLs.
+	urlRe = regexp.MustCompile(`https?://[^\s"'<>\]]+`)
+	// idRe matches OSVDB and CVE identifiers.
+	idRe = regexp.MustCompile(`\b(?:OSVDB-\d+|CVE-\d{4}-\d{4,})\b`)
+	// seeRe matches the trailing "See: ..." reference list of nikto 2.5 findings.
+	seeRe = regexp.MustCompile(`\s*See: (.+)$`)
+)
+
+// boilerplate lists nikto line fragments that describe the scan, not findings.
+var boilerplate = []string{
+	"Target IP:", "Target Hostname:", "Target Port:", "Start Time:", "End Time:",
+	"host(s) tested", "requests:", "No CGI Directories found", "SSL Info:",
+	"Multiple IPs found:", "Platform:", "Scan terminated:", "Error limit",
+}
+
+// ParseFindings parses nikto "+ " finding lines, skipping the scan banner. OSVDB and CVE IDs and
+// the URLs of a trailing "See:" list become references; the affected path, or otherwise a URL in
+// the message, becomes the finding URL, and a leading reference ID the template ID. Nikto repeats the banner, and sometimes findings, for
+// every host and port, so identical findings are reported once.
+func (t *Tool) ParseFindings(output string) ([]models.Finding, error) {
+	var found []models.Finding
+	seen := make(map[string]bool)
+
+	for _, line := range strings.Split(output, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if !strings.HasPrefix(trimmed, "+ ") {
+			continue
+		}
+		message := strings.TrimSpace(strings.TrimPrefix(trimmed, "+ "))
+		if message == "" || isBoilerplate(message) {
+			continue
+		}
+
+		references := idRe.FindAllString(message, -1)
+		if match := seeRe.FindStringSubmatch(message); match != nil {
+			references = append(references, urlRe.FindAllString(match[1], -1)...)
+			message = strings.TrimSpace(strings.TrimSuffix(message, match[0]))
+		}
+
+		finding := models.Finding{
+			References: unique(references),
+			Scanner:    binaryName,
+			Severity:   types.SeverityLow,
+			Title:      message,
+		}
+		if strings.HasPrefix(message, "Server:") {
+			finding.Severity = types.SeverityInfo
+		}
+		if match := leadingIDRe.FindStringSubmatch(message); match != nil {
+			finding.TemplateID = match[1]
+		}
+		if match := pathRe.FindStringSubmatch(message); match != nil {
+			finding.URL = match[1]
+		} else if match := urlRe.FindString(message); match != "" {
+			finding.URL = match
+		}
+
+		key := finding.Title + "\x00" + finding.URL
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		found = append(found, finding)
+	}
+
+	return found, nil
+}
+
+// isBoilerplate reports whether a nikto message describes the scan rather than a finding.
+func isBoilerplate(message string) bool {
+	for _, fragment := range boilerplate {
+		if strings.Contains(message, fragment) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// unique returns values without duplicates, keeping their order, or nil when empty.
+func unique(values []string) []string {
+	var result []string
+	seen := make(map[string]bool, len(values))
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			result = append(result, value)
+		}
+	}
+
+	return result
+}
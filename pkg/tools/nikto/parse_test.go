@@ -0,0 +1,98 @@
+package nikto
+
+import (
+	"os"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/types"
+)
+
+const reportOutput = `- Nikto v2.5.0
+---------------------------------------------------------------------------
++ Target IP:          127.0.0.1
++ Target Hostname:    example.com
++ Target Port:        80
++ Start Time:         2026-01-01 00:00:00 (GMT0)
+---------------------------------------------------------------------------
++ Server: Apache/2.4.41 (Ubuntu)
++ /: The anti-clickjacking X-Frame-Options header is not present.
++ OSVDB-3092: /admin/: This might be interesting.
++ Redirects to https://example.com/login
++ 8102 requests: 0 error(s) and 3 item(s) reported on remote host
++ End Time:           2026-01-01 00:01:00 (GMT0) (60 seconds)
++ 1 host(s) tested`
+
+type ParseTestSuite struct {
+	suite.Suite
+	tool *Tool
+}
+
+func (s *ParseTestSuite) SetupTest() {
+	s.tool = New(zerolog.New(os.Stdout)).(*Tool)
+}
+
+func (s *ParseTestSuite) TestParseFindings() {
+	found, err := s.tool.ParseFindings(reportOutput)
+	s.Require().NoError(err)
+	s.Require().Len(found, 4)
+	s.Equal(types.SeverityInfo, found[0].Severity)
+	s.Empty(found[0].URL)
+	s.Equal("/", found[1].URL)
+	s.Equal("/admin/", found[2].URL)
+	s.Equal(types.SeverityLow, found[2].Severity)
+	s.Equal("https://example.com/login", found[3].URL)
+	for _, finding := range found {
+		s.Equal("nikto", finding.Scanner)
+	}
+}
+
+func (s *ParseTestSuite) TestParseFindings_References() {
+	found, err := s.tool.ParseFindings(reportOutput)
+	s.Require().NoError(err)
+	s.Require().Len(found, 4)
+	s.Nil(found[1].References)
+	s.Equal([]string{"OSVDB-3092"}, found[2].References)
+}
+
+func (s *ParseTestSuite) TestParseFindings_SeeReferences() {
+	output := `+ /: The anti-clickjacking X-Frame-Options header is not present. See: https://developer.mozilla.org/en-US/docs/Web/HTTP/Headers/X-Frame-Options
++ /icons/README: Apache default file found. See: https://www.vntweb.co.uk/apache-restricting-access-to-iconsreadme/
++ /cgi-bin/test.cgi: Site appears vulnerable to the 'shellshock' vulnerability. See: CVE-2014-6271, https://nvd.nist.gov/vuln/detail/CVE-2014-6271`
+
+	found, err := s.tool.ParseFindings(output)
+	s.Require().NoError(err)
+	s.Require().Len(found, 3)
+	s.Equal("/: The anti-clickjacking X-Frame-Options header is not present.", found[0].Title)
+	s.Equal("/", found[0].URL)
+	s.Equal([]string{"https://developer.mozilla.org/en-US/docs/Web/HTTP/Headers/X-Frame-Options"}, found[0].References)
+	s.Equal("/icons/README", found[1].URL)
+	s.Equal("/cgi-bin/test.cgi", found[2].URL)
+	s.Equal([]string{"CVE-2014-6271", "https://nvd.nist.gov/vuln/detail/CVE-2014-6271"}, found[2].References)
+}
+
+func (s *ParseTestSuite) TestParseFindings_Deduplicates() {
+	output := reportOutput + "\n" + `+ Target IP:          127.0.0.1
++ Target Port:        8080
++ Multiple IPs found: 127.0.0.1, ::1
++ Platform:           Unknown
++ Server: Apache/2.4.41 (Ubuntu)
++ /: The anti-clickjacking X-Frame-Options header is not present.
++ /backup/: Directory indexing found.`
+
+	found, err := s.tool.ParseFindings(output)
+	s.Require().NoError(err)
+	s.Require().Len(found, 5)
+	s.Equal("/backup/", found[4].URL)
+}
+
+func (s *ParseTestSuite) TestParseFindings_BannerOnly() {
+	found, err := s.tool.ParseFindings("+ Target IP: 127.0.0.1\n+ 1 host(s) tested")
+	s.Require().NoError(err)
+	s.Empty(found)
+}
+
+func TestParseTestSuite(t *testing.T) {
+	suite.Run(t, new(ParseTestSuite))
+}
@@ -0,0 +1,129 @@
+package nmap
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+	"github.com/tb0hdan/wass-mcp/pkg/server"
+	"github.com/tb0hdan/wass-mcp/pkg/target"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+)
+
+const (
+	binaryName  = "nmap"
+	description = "Nmap runs its HTTP NSE scripts against the target port, reporting the server, methods, headers, known vulnerabilities and other details of the HTTP service."
+	headerVerb  = "output"
+	// defaultScripts are the HTTP scripts run without script categories: every http-* script but
+	// brute-force and denial of service ones, which lock accounts and take targets down.
+	defaultScripts = "http-* and not (brute or dos)"
+)
+
+// supportedOptions are the scan options nmap honours.
+var supportedOptions = []string{tools.OptionScriptCategories, tools.OptionUserAgent, tools.OptionVhost}
+
+// Tool implements the nmap HTTP scripts scanner.
+type Tool struct {
+	tools.BaseScanner
+}
+
+// Scan runs the nmap HTTP scripts against the target port and returns the nmap output.
+func (t *Tool) Scan(ctx context.Context, params tools.ScanParams) tools.ScanResult {
+	logger := tools.ContextLogger(ctx, t.Logger)
+	logger.Info().Msgf("Running nmap HTTP scripts on %s", params.Target().URL())
+
+	workDir, cleanup, err := t.WorkDir(ctx)
+	if err != nil {
+		return tools.ScanResult{
+			Error: err,
+		}
+	}
+	defer cleanup()
+
+	cmd := t.Command(ctx, buildArgs(params)...)
+	cmd.Dir = workDir
+	cmd.Env = tools.ScanEnv(params, workDir)
+	output, err := t.CombinedOutput(ctx, cmd)
+	if err != nil {
+		return tools.ScanResult{
+			Output: string(output),
+			Error:  fmt.Errorf("failed to execute nmap: %w", err),
+		}
+	}
+
+	return tools.ScanResult{
+		Output: string(output),
+		Error:  nil,
+	}
+}
+
+// buildArgs builds the nmap command line arguments: a TCP connect scan of the target port without
+// host discovery, with service detection so that the HTTP scripts recognize HTTP(S) services on
+// any port.
+func buildArgs(params tools.ScanParams) []string {
+	port := params.Port
+	if port == 0 {
+		port = target.DefaultPort(params.Scheme)
+	}
+
+	args := []string{"-Pn", "-sT", "-sV", "-p", strconv.Itoa(port), "--script", scriptExpression(params)}
+	var scriptArgs []string
+	if params.Vhost != "" {
+		scriptArgs = append(scriptArgs, scriptArg("http.host", params.Vhost))
+	}
+	if userAgent := params.Option(tools.OptionUserAgent); userAgent != "" {
+		scriptArgs = append(scriptArgs, scriptArg("http.useragent", userAgent))
+	}
+	if len(scriptArgs) > 0 {
+		args = append(args, "--script-args", strings.Join(scriptArgs, ","))
+	}
+	if strings.Contains(params.Host, ":") {
+		args = append(args, "-6")
+	}
+
+	return append(args, params.Host)
+}
+
+// scriptExpression returns the --script expression selecting the HTTP scripts of the script
+// categories option, e.g. "http-* and (safe or vuln)", or defaultScripts without it.
+func scriptExpression(params tools.ScanParams) string {
+	value := params.Option(tools.OptionScriptCategories)
+	if value == "" {
+		return defaultScripts
+	}
+
+	var categories []string
+	for _, category := range strings.Split(value, ",") {
+		categories = append(categories, strings.TrimSpace(category))
+	}
+
+	return "http-* and (" + strings.Join(categories, " or ") + ")"
+}
+
+// scriptArg formats a --script-args argument, quoting the value so that commas and equal signs
+// in it are not read as further arguments.
+func scriptArg(name, value string) string {
+	escaped := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value)
+	return name + `="` + escaped + `"`
+}
+
+// Register registers the nmap tool with the MCP server.
+func (t *Tool) Register(srv *server.Server) error {
+	return t.RegisterTool(srv, t.Handler)
+}
+
+// Handler handles MCP tool requests.
+func (t *Tool) Handler(ctx context.Context, _ *mcp.CallToolRequest, input tools.ScannerInput) (*mcp.CallToolResult, any, error) {
+	return t.HandleScan(ctx, input, headerVerb, t.Scan)
+}
+
+// New creates a new nmap scanner tool.
+func New(logger zerolog.Logger) tools.Scanner {
+	base := tools.NewBaseScanner(binaryName, description, logger, supportedOptions...)
+	base.VersionArgs = []string{"--version"}
+
+	return &Tool{BaseScanner: base}
+}
@@ -0,0 +1,79 @@
+package nmap
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+)
+
+type NmapTestSuite struct {
+	suite.Suite
+	logger zerolog.Logger
+	tool   *Tool
+}
+
+func (s *NmapTestSuite) SetupTest() {
+	s.logger = zerolog.New(os.Stdout).With().Timestamp().Logger()
+	s.tool = New(s.logger).(*Tool)
+}
+
+func (s *NmapTestSuite) TestNew() {
+	s.Equal("nmap", s.tool.Name())
+	s.Empty(tools.Technologies(s.tool), "nmap runs in full_scan")
+	s.False(tools.IsPassive(s.tool))
+}
+
+func (s *NmapTestSuite) TestBuildArgs_Default() {
+	args := buildArgs(tools.ScanParams{Host: "example.com", Scheme: "https"})
+	s.Equal([]string{"-Pn", "-sT", "-sV", "-p", "443", "--script", "http-* and not (brute or dos)", "example.com"}, args)
+
+	args = buildArgs(tools.ScanParams{Host: "::1", Port: 8080})
+	s.Equal([]string{"-p", "8080"}, args[3:5])
+	s.Equal([]string{"-6", "::1"}, args[len(args)-2:], "IPv6 targets need -6")
+}
+
+func (s *NmapTestSuite) TestBuildArgs_Options() {
+	params := tools.ScanParams{
+		Host:    "10.0.0.1",
+		Port:    80,
+		Vhost:   "shop.example.com",
+		Options: map[string]string{tools.OptionScriptCategories: "safe, vuln", tools.OptionUserAgent: `wass "scan", v1`},
+	}
+	s.Equal([]string{
+		"-Pn", "-sT", "-sV", "-p", "80", "--script", "http-* and (safe or vuln)",
+		"--script-args", `http.host="shop.example.com",http.useragent="wass \"scan\", v1"`, "10.0.0.1",
+	}, buildArgs(params))
+}
+
+func (s *NmapTestSuite) TestScan() {
+	// The fake nmap echoes its arguments.
+	binDir := s.T().TempDir()
+	script := "#!/bin/sh\necho \"$@\"\n"
+	s.Require().NoError(os.WriteFile(filepath.Join(binDir, binaryName), []byte(script), 0o700)) //nolint:gosec
+	s.T().Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	result := s.tool.Scan(context.Background(), tools.ScanParams{Host: "localhost", Port: 8080, Scheme: "http"})
+	s.Require().NoError(result.Error)
+	s.Contains(result.Output, "-p 8080 --script http-* and not (brute or dos) localhost")
+
+	script = "#!/bin/sh\necho 'Failed to resolve \"nowhere.invalid\".'\nexit 1\n"
+	s.Require().NoError(os.WriteFile(filepath.Join(binDir, binaryName), []byte(script), 0o700)) //nolint:gosec
+	result = s.tool.Scan(context.Background(), tools.ScanParams{Host: "nowhere.invalid", Port: 80, Scheme: "http"})
+	s.ErrorContains(result.Error, "failed to execute nmap")
+	s.Contains(result.Output, "Failed to resolve")
+}
+
+func (s *NmapTestSuite) TestHandler_ValidationError() {
+	_, _, err := s.tool.Handler(context.Background(), &mcp.CallToolRequest{}, tools.ScannerInput{Host: "invalid host!!!"})
+	s.ErrorContains(err, "validation error")
+}
+
+func TestNmapTestSuite(t *testing.T) {
+	suite.Run(t, new(NmapTestSuite))
+}
@@ -0,0 +1,141 @@
+package nmap
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/tb0hdan/wass-mcp/pkg/findings"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/target"
+	"github.com/tb0hdan/wass-mcp/pkg/types"
+)
+
+var (
+	// reportRe matches the host line of nmap output, e.g. "Nmap scan report for example.com (192.0.2.1)".
+	reportRe = regexp.MustCompile(`^Nmap scan report for (\S+)`)
+	// portRe matches a port line, e.g. "443/tcp open  ssl/http nginx 1.18.0".
+	portRe = regexp.MustCompile(`^(\d+)/tcp\s+\S+\s+(\S+)`)
+	// scriptRe matches the first line of a script result, e.g. "| http-methods: " or
+	// "|_http-title: Example Domain"; the lines that follow are indented further.
+	scriptRe = regexp.MustCompile(`^\|[ _]([A-Za-z0-9][\w.-]*):(.*)$`)
+	// stateRe matches the state of a vulnerability reported by the NSE vulns library.
+	stateRe = regexp.MustCompile(`State: (LIKELY )?VULNERABLE`)
+	// riskRe matches the risk factor of a vulnerability reported by the NSE vulns library.
+	riskRe = regexp.MustCompile(`Risk factor: (High|Medium|Low)`)
+	// cveRe matches the CVE IDs of a vulnerability.
+	cveRe = regexp.MustCompile(`CVE-\d{4}-\d{4,}`)
+	// referenceRe matches the reference URLs of a vulnerability.
+	referenceRe = regexp.MustCompile(`https?://\S+`)
+)
+
+// scriptFailed is the output of NSE scripts that failed to run.
+const scriptFailed = "ERROR: Script execution failed"
+
+// script is a script result of nmap output.
+type script struct {
+	id    string
+	url   string
+	lines []string
+}
+
+// ParseFindings parses the script results of nmap output, one finding per script named after the
+// script as template ID, with the script output as evidence. Scripts reporting a vulnerability
+// (State: VULNERABLE) are high severity findings, likely ones medium, unless they state their risk
+// factor, and carry its title, CVE IDs and references; other results are informational.
+func (t *Tool) ParseFindings(output string) ([]models.Finding, error) {
+	var (
+		scripts []*script
+		current *script
+		host    string
+		portURL string
+	)
+	for _, line := range strings.Split(strings.ReplaceAll(output, "\r\n", "\n"), "\n") {
+		if match := reportRe.FindStringSubmatch(line); match != nil {
+			host = match[1]
+			portURL = target.Target{Host: host}.URL()
+			current = nil
+			continue
+		}
+		if match := portRe.FindStringSubmatch(line); match != nil {
+			port, _ := strconv.Atoi(match[1])
+			scheme := types.SchemeHTTP
+			if strings.Contains(match[2], "ssl") || strings.Contains(match[2], "https") {
+				scheme = types.SchemeHTTPS
+			}
+			portURL = target.Target{Host: host, Port: port, Scheme: scheme}.URL()
+			current = nil
+			continue
+		}
+		if !strings.HasPrefix(line, "|") {
+			current = nil
+			continue
+		}
+		match := scriptRe.FindStringSubmatch(line)
+		switch {
+		case match != nil:
+			current = &script{id: match[1], url: portURL}
+			scripts = append(scripts, current)
+			line = match[2]
+		case current == nil:
+			continue
+		default:
+			line = strings.TrimPrefix(strings.TrimPrefix(line, "|_"), "|")
+		}
+		if text := strings.TrimSpace(line); text != "" {
+			current.lines = append(current.lines, text)
+		}
+	}
+
+	var found []models.Finding
+	for _, result := range scripts {
+		if len(result.lines) == 0 || strings.Contains(result.lines[0], scriptFailed) {
+			continue
+		}
+		found = append(found, result.finding())
+	}
+
+	return found, nil
+}
+
+// finding returns the finding of a script result.
+func (s *script) finding() models.Finding {
+	text := strings.Join(s.lines, "\n")
+	finding := models.Finding{
+		Evidence:   findings.AppendEvidence(nil, models.EvidenceExtracted, binaryName, text),
+		Scanner:    binaryName,
+		Severity:   types.SeverityInfo,
+		TemplateID: s.id,
+		Title:      s.id + ": " + s.lines[0],
+		URL:        s.url,
+	}
+
+	match := stateRe.FindStringSubmatch(text)
+	if match == nil {
+		return finding
+	}
+	finding.Severity = types.SeverityHigh
+	if match[1] != "" {
+		finding.Severity = types.SeverityMedium
+	}
+	if risk := riskRe.FindStringSubmatch(text); risk != nil {
+		finding.Severity = findings.NormalizeSeverity(risk[1])
+	}
+	// The vulns library prints the vulnerability title after the "VULNERABLE:" line.
+	for i, line := range s.lines[:len(s.lines)-1] {
+		if strings.HasSuffix(line, "VULNERABLE:") {
+			finding.Title = s.id + ": " + s.lines[i+1]
+			break
+		}
+	}
+	seen := make(map[string]struct{})
+	for _, id := range cveRe.FindAllString(text, -1) {
+		if _, ok := seen[id]; !ok {
+			seen[id] = struct{}{}
+			finding.CVEs = append(finding.CVEs, id)
+		}
+	}
+	finding.References = referenceRe.FindAllString(text, -1)
+
+	return finding
+}
@@ -0,0 +1,105 @@
+package nmap
+
+import (
+	"os"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/types"
+)
+
+const scriptsOutput = `Starting Nmap 7.94SVN ( https://nmap.org ) at 2026-10-16 12:00 UTC
+Nmap scan report for shop.example.com (192.0.2.10)
+Host is up (0.010s latency).
+
+PORT    STATE SERVICE  VERSION
+443/tcp open  ssl/http Apache httpd 2.2.8
+|_http-title: Example Shop
+| http-methods: 
+|   Supported Methods: GET HEAD POST OPTIONS TRACE
+|_  Potentially risky methods: TRACE
+|_http-vuln-cve2017-1001000: ERROR: Script execution failed (use -d to debug)
+| http-vuln-cve2011-3192: 
+|   VULNERABLE:
+|   Apache byterange filter DoS
+|     State: VULNERABLE
+|     IDs:  BID:49303  CVE:CVE-2011-3192
+|       The Apache web server is vulnerable to a denial of service attack when numerous
+|       overlapping byte ranges are requested.
+|     Disclosure date: 2011-08-19
+|     References:
+|       https://www.tenable.com/plugins/nessus/55976
+|_      https://cve.mitre.org/cgi-bin/cvename.cgi?name=CVE-2011-3192
+| http-slowloris-check: 
+|   VULNERABLE:
+|   Slowloris DOS attack
+|     State: LIKELY VULNERABLE
+|     IDs:  CVE:CVE-2007-6750
+|_      http://ha.ckers.org/slowloris/
+
+Service detection performed. Please report any incorrect results at https://nmap.org/submit/ .
+Nmap done: 1 IP address (1 host up) scanned in 12.34 seconds
+`
+
+type ParseTestSuite struct {
+	suite.Suite
+	tool *Tool
+}
+
+func (s *ParseTestSuite) SetupTest() {
+	s.tool = New(zerolog.New(os.Stdout)).(*Tool)
+}
+
+func (s *ParseTestSuite) TestParseFindings() {
+	found, err := s.tool.ParseFindings(scriptsOutput)
+	s.Require().NoError(err)
+	s.Require().Len(found, 4, "failed scripts are skipped")
+
+	s.Equal("http-title", found[0].TemplateID)
+	s.Equal("http-title: Example Shop", found[0].Title)
+	s.Equal(types.SeverityInfo, found[0].Severity)
+	s.Equal("https://shop.example.com", found[0].URL)
+
+	s.Equal("http-methods: Supported Methods: GET HEAD POST OPTIONS TRACE", found[1].Title)
+	s.Require().Len(found[1].Evidence, 1)
+	s.Equal(models.EvidenceExtracted, found[1].Evidence[0].Kind)
+	s.Equal("Supported Methods: GET HEAD POST OPTIONS TRACE\nPotentially risky methods: TRACE", found[1].Evidence[0].Content)
+
+	s.Equal("http-vuln-cve2011-3192: Apache byterange filter DoS", found[2].Title)
+	s.Equal(types.SeverityHigh, found[2].Severity)
+	s.Equal([]string{"CVE-2011-3192"}, found[2].CVEs)
+	s.Equal([]string{
+		"https://www.tenable.com/plugins/nessus/55976", "https://cve.mitre.org/cgi-bin/cvename.cgi?name=CVE-2011-3192",
+	}, found[2].References)
+
+	s.Equal("http-slowloris-check: Slowloris DOS attack", found[3].Title)
+	s.Equal(types.SeverityMedium, found[3].Severity, "likely vulnerabilities are medium")
+}
+
+func (s *ParseTestSuite) TestParseFindings_RiskFactor() {
+	output := "Nmap scan report for 10.0.0.1\n" +
+		"8080/tcp open  http    Jetty\n" +
+		"| http-vuln-misfortune-cookie: \n" +
+		"|   VULNERABLE:\n" +
+		"|   RomPager 4.07 Misfortune Cookie\n" +
+		"|     State: VULNERABLE\n" +
+		"|_    Risk factor: Low\n"
+	found, err := s.tool.ParseFindings(output)
+	s.Require().NoError(err)
+	s.Require().Len(found, 1)
+	s.Equal(types.SeverityLow, found[0].Severity)
+	s.Equal("http://10.0.0.1:8080", found[0].URL)
+}
+
+func (s *ParseTestSuite) TestParseFindings_NoScripts() {
+	output := "Nmap scan report for 10.0.0.1\nPORT   STATE  SERVICE\n80/tcp closed http\n"
+	found, err := s.tool.ParseFindings(output)
+	s.Require().NoError(err)
+	s.Empty(found)
+}
+
+func TestParseTestSuite(t *testing.T) {
+	suite.Run(t, new(ParseTestSuite))
+}
@@ -0,0 +1,123 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog"
+	"github.com/tb0hdan/wass-mcp/pkg/httpclient"
+	"github.com/tb0hdan/wass-mcp/pkg/scope"
+	"github.com/tb0hdan/wass-mcp/pkg/target"
+	"github.com/tb0hdan/wass-mcp/pkg/types"
+)
+
+// NormalizeResult contains the outcome of pre-scan target normalization.
+type NormalizeResult struct {
+	// FinalURL is the effective URL reached after following redirects.
+	FinalURL string
+	// Params are the scan parameters pointing at the effective target.
+	Params ScanParams
+	// Redirected is true when the effective target differs from the requested one.
+	Redirected bool
+}
+
+// NormalizeTarget follows HTTP redirects (e.g. http to https, apex to www) starting from the
+// target described by params and returns parameters pointing at the effective final target.
+// Only scheme, host and port are adopted from the final URL; the requested path is kept.
+// When the redirect chain stays on the requested vhost, the original host and vhost are kept.
+func NormalizeTarget(ctx context.Context, params ScanParams) (NormalizeResult, error) {
+	startURL := params.Target().URL()
+
+	tlsConfig, err := TLSConfig(params)
+	if err != nil {
+		return NormalizeResult{Params: params, FinalURL: startURL}, err
+	}
+
+	// The target itself is in scope even when it is a link-local address; redirects elsewhere are
+	// held to the SSRF policy.
+	client := httpclient.New(httpclient.Config{
+		FollowRedirects: true,
+		Proxy:           http.ProxyFromEnvironment,
+		Scope:           []string{params.Host},
+		TLS:             tlsConfig,
+		Timeout:         types.NormalizeTimeout,
+		Redirect: func(req *http.Request, _ []*http.Request) error {
+			// Keep addressing the vhost while the chain stays on the original host.
+			if params.Vhost != "" && req.URL.Hostname() == params.Host {
+				req.Host = params.Vhost
+			}
+			return nil
+		},
+	})
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, params.Target().RequestURL(), nil)
+	if err != nil {
+		return NormalizeResult{Params: params, FinalURL: startURL}, fmt.Errorf("failed to create request: %w", err)
+	}
+	if params.Vhost != "" {
+		req.Host = params.Vhost
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return NormalizeResult{Params: params, FinalURL: startURL}, fmt.Errorf("failed to follow redirects: %w", err)
+	}
+	_ = resp.Body.Close()
+
+	final := resp.Request.URL
+	normalized := params
+	normalized.Scheme = final.Scheme
+
+	normalized.Port = target.DefaultPort(final.Scheme)
+	if port, err := strconv.Atoi(final.Port()); err == nil {
+		normalized.Port = port
+	}
+
+	finalHost := final.Hostname()
+	onVhost := params.Vhost != "" && strings.EqualFold(finalHost, params.Vhost)
+	if finalHost != params.Host && !onVhost {
+		normalized.Host = finalHost
+		normalized.Vhost = ""
+	}
+
+	finalURL := normalized.Target().URL()
+
+	return NormalizeResult{
+		FinalURL:   finalURL,
+		Params:     normalized,
+		Redirected: finalURL != startURL || normalized.Vhost != params.Vhost,
+	}, nil
+}
+
+// ApplyNormalization follows redirects for params and returns parameters for the effective target.
+// Normalization failures are logged and the original parameters are kept, leaving error
+// reporting to the scanners themselves.
+func ApplyNormalization(ctx context.Context, logger zerolog.Logger, params ScanParams) ScanParams {
+	result, err := NormalizeTarget(ctx, params)
+	if err != nil {
+		logger.Warn().Err(err).Msgf("Target normalization failed, scanning %s as requested", params.Target().URL())
+		return params
+	}
+
+	if result.Redirected {
+		logger.Info().Msgf("Target %s redirects to %s", params.Target().URL(), result.FinalURL)
+	}
+
+	return result.Params
+}
+
+// FollowRedirects points params at the effective target reached by following redirects, see
+// ApplyNormalization, and checks it against policy. Every tool following redirects refuses an
+// effective target out of scope with an error naming it, rather than scanning another target than
+// the one it resolved to.
+func FollowRedirects(ctx context.Context, logger zerolog.Logger, params ScanParams, policy *scope.Policy) (ScanParams, error) {
+	normalized := ApplyNormalization(ctx, logger, params)
+	if err := policy.Check(normalized.Host, normalized.Port); err != nil {
+		return params, fmt.Errorf("effective target %s: %w", normalized.Target().URL(), err)
+	}
+
+	return normalized, nil
+}
@@ -0,0 +1,172 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/httpclient"
+	"github.com/tb0hdan/wass-mcp/pkg/scope"
+	"github.com/tb0hdan/wass-mcp/pkg/types"
+)
+
+type NormalizeTestSuite struct {
+	suite.Suite
+}
+
+// paramsFor converts a test server URL into ScanParams.
+func (s *NormalizeTestSuite) paramsFor(rawURL string) ScanParams {
+	parsed, err := url.Parse(rawURL)
+	s.Require().NoError(err)
+	port, err := strconv.Atoi(parsed.Port())
+	s.Require().NoError(err)
+
+	return ScanParams{Host: parsed.Hostname(), Port: port, Scheme: parsed.Scheme}
+}
+
+func (s *NormalizeTestSuite) TestNormalizeTarget_NoRedirect() {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	params := s.paramsFor(srv.URL)
+	result, err := NormalizeTarget(context.Background(), params)
+	s.Require().NoError(err)
+	s.False(result.Redirected)
+	s.Equal(params, result.Params)
+	s.Equal(srv.URL, result.FinalURL)
+}
+
+func (s *NormalizeTestSuite) TestNormalizeTarget_FollowsRedirect() {
+	final := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer final.Close()
+
+	start := httptest.NewServer(http.RedirectHandler(final.URL+"/landing", http.StatusMovedPermanently))
+	defer start.Close()
+
+	result, err := NormalizeTarget(context.Background(), s.paramsFor(start.URL))
+	s.Require().NoError(err)
+	s.True(result.Redirected)
+	s.Equal(s.paramsFor(final.URL), result.Params)
+	s.Equal(final.URL, result.FinalURL)
+}
+
+func (s *NormalizeTestSuite) TestNormalizeTarget_KeepsVhost() {
+	var hosts []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hosts = append(hosts, r.Host)
+		if r.URL.Path == "/" {
+			http.Redirect(w, r, "/login", http.StatusFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	params := s.paramsFor(srv.URL)
+	params.Vhost = "app.example.com"
+
+	result, err := NormalizeTarget(context.Background(), params)
+	s.Require().NoError(err)
+	s.False(result.Redirected)
+	s.Equal(params, result.Params)
+	s.Equal([]string{"app.example.com", "app.example.com"}, hosts)
+}
+
+func (s *NormalizeTestSuite) TestNormalizeTarget_TooManyRedirects() {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/", http.StatusFound)
+	}))
+	defer srv.Close()
+
+	params := s.paramsFor(srv.URL)
+	result, err := NormalizeTarget(context.Background(), params)
+	s.Error(err)
+	s.Equal(params, result.Params)
+}
+
+func (s *NormalizeTestSuite) TestNormalizeTarget_BlocksMetadataRedirect() {
+	start := httptest.NewServer(http.RedirectHandler("http://169.254.169.254/latest/meta-data/", http.StatusFound))
+	defer start.Close()
+
+	params := s.paramsFor(start.URL)
+	result, err := NormalizeTarget(context.Background(), params)
+	s.ErrorIs(err, httpclient.ErrBlockedAddress)
+	s.Equal(params, result.Params)
+}
+
+func (s *NormalizeTestSuite) TestApplyNormalization_UnreachableKeepsParams() {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	params := s.paramsFor(srv.URL)
+	srv.Close()
+
+	s.Equal(params, ApplyNormalization(context.Background(), zerolog.Nop(), params))
+}
+
+func (s *NormalizeTestSuite) TestHandleScan_FollowRedirects() {
+	final := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer final.Close()
+
+	start := httptest.NewServer(http.RedirectHandler(final.URL, http.StatusMovedPermanently))
+	defer start.Close()
+
+	var scanned ScanParams
+	scan := func(_ context.Context, params ScanParams) ScanResult {
+		scanned = params
+		return ScanResult{Output: "done"}
+	}
+
+	startParams := s.paramsFor(start.URL)
+	input := ScannerInput{Host: startParams.Host, Port: startParams.Port, FollowRedirects: true}
+
+	bs := NewBaseScanner("test", "test", zerolog.Nop())
+	result, _, err := bs.HandleScan(context.Background(), input, "output", scan)
+	s.Require().NoError(err)
+
+	s.Equal(s.paramsFor(final.URL), scanned)
+	s.Equal(types.SchemeHTTP, scanned.Scheme)
+	text := result.Content[0].(*mcp.TextContent).Text
+	s.Contains(text, "redirected to effective target "+final.URL)
+}
+
+func (s *NormalizeTestSuite) TestHandleScan_RedirectOutOfScope() {
+	final := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer final.Close()
+
+	start := httptest.NewServer(http.RedirectHandler(final.URL, http.StatusMovedPermanently))
+	defer start.Close()
+
+	startParams := s.paramsFor(start.URL)
+	policy, err := scope.New(fmt.Sprintf("%s:%d", startParams.Host, startParams.Port))
+	s.Require().NoError(err)
+
+	bs := NewBaseScanner("test", "test", zerolog.Nop())
+	bs.scope = policy
+	scan := func(_ context.Context, _ ScanParams) ScanResult {
+		s.Fail("a redirect out of scope must not be scanned")
+		return ScanResult{}
+	}
+
+	input := ScannerInput{Host: startParams.Host, Port: startParams.Port, FollowRedirects: true}
+	_, _, err = bs.HandleScan(context.Background(), input, "output", scan)
+	s.ErrorIs(err, scope.ErrOutOfScope)
+	s.ErrorContains(err, "effective target")
+}
+
+func TestNormalizeTestSuite(t *testing.T) {
+	suite.Run(t, new(NormalizeTestSuite))
+}
@@ -0,0 +1,59 @@
+package nuclei
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+)
+
+// techTag is the template tag of nuclei technology detection templates.
+const techTag = "tech"
+
+// detectionSuffixes are the template ID suffixes of detection templates named after what they
+// detect, e.g. "wordpress-detect" or "graphql-detect".
+var detectionSuffixes = []string{"-detection", "-detect"}
+
+// versionSuffix is the template ID suffix of version detection templates, e.g. "nginx-version".
+const versionSuffix = "-version"
+
+// isDetection reports whether templateID names a detection template.
+func isDetection(templateID string) bool {
+	return slices.ContainsFunc(detectionSuffixes, func(suffix string) bool { return strings.HasSuffix(templateID, suffix) })
+}
+
+// Fingerprint returns the technologies detected by the technology detection templates in nuclei
+// JSONL output, the templates tagged tech or named as detection templates, sorted and
+// lower-cased: the matcher name of results of multi-technology templates such as tech-detect, or
+// the template ID without its detection suffix otherwise.
+func (t *Tool) Fingerprint(output string) []string {
+	var technologies []string
+	for _, line := range strings.Split(output, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if !strings.HasPrefix(trimmed, "{") {
+			continue
+		}
+		var res result
+		if err := json.Unmarshal([]byte(trimmed), &res); err != nil || res.TemplateID == "" {
+			continue
+		}
+		if !slices.Contains(res.Info.Tags, techTag) && !isDetection(res.TemplateID) {
+			continue
+		}
+
+		technology := res.MatcherName
+		if technology == "" {
+			technology = strings.TrimSuffix(res.TemplateID, versionSuffix)
+			for _, suffix := range detectionSuffixes {
+				technology = strings.TrimSuffix(technology, suffix)
+			}
+		}
+		technology = strings.ToLower(strings.TrimSpace(technology))
+		// tech-detect results without a matcher name do not name a technology.
+		if technology != "" && technology != techTag && !slices.Contains(technologies, technology) {
+			technologies = append(technologies, technology)
+		}
+	}
+	slices.Sort(technologies)
+
+	return technologies
+}
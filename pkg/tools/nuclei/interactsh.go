@@ -0,0 +1,55 @@
+package nuclei
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+)
+
+// Interactsh configures the interactsh server that nuclei's out-of-band (OOB) templates, such as
+// blind SSRF and blind XXE checks, report interactions to. By default nuclei uses its public
+// servers, which targets in restricted networks cannot reach.
+type Interactsh struct {
+	// Disabled turns OOB testing off for every scan; scans cannot turn it back on.
+	Disabled bool
+	// Server is the interactsh server URL, nuclei's public servers when empty.
+	Server string
+	// Token authenticates with Server. It is never sent to a server named by a scan.
+	Token string
+}
+
+// Validate checks the server URL.
+func (i Interactsh) Validate() error {
+	if i.Server != "" && !tools.ValidServerURL(i.Server) {
+		return fmt.Errorf("interactsh server must be an http(s) URL or host name, got %q", i.Server)
+	}
+	if i.Token != "" && i.Server == "" {
+		return fmt.Errorf("interactsh token requires an interactsh server")
+	}
+
+	return nil
+}
+
+// interactshArgs returns the nuclei arguments configuring OOB testing for params: off when the
+// server or the scan disables it, otherwise the server named by the interactsh_server option or
+// the configured server with its token.
+func interactshArgs(cfg Interactsh, params tools.ScanParams) []string {
+	if enabled, err := strconv.ParseBool(params.Option(tools.OptionInteractsh)); cfg.Disabled || (err == nil && !enabled) {
+		return []string{"-no-interactsh"}
+	}
+
+	if server := params.Option(tools.OptionInteractshServer); server != "" && server != cfg.Server {
+		return []string{"-interactsh-server", server}
+	}
+
+	var args []string
+	if cfg.Server != "" {
+		args = append(args, "-interactsh-server", cfg.Server)
+	}
+	if cfg.Token != "" {
+		args = append(args, "-interactsh-token", cfg.Token)
+	}
+
+	return args
+}
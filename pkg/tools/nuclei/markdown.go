@@ -0,0 +1,47 @@
+package nuclei
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+// severityBadges are the markdown labels of nuclei severities, colored like the severities of the
+// nuclei terminal output.
+var severityBadges = map[string]string{
+	"critical": "🟣 **CRITICAL**",
+	"high":     "🔴 **HIGH**",
+	"medium":   "🟡 **MEDIUM**",
+	"low":      "🟢 **LOW**",
+	"info":     "🔵 **INFO**",
+	"unknown":  "⚪ **UNKNOWN**",
+}
+
+// severityTagRe matches the bracketed severity of nuclei text results, e.g. "[high]". nuclei
+// colors the severity inside the brackets, so the tag is what remains once colors are stripped.
+var severityTagRe = regexp.MustCompile(`(?i)\[(critical|high|medium|low|info|unknown)\]`)
+
+// RenderMarkdown renders the severities of the nuclei results in text as markdown badges instead
+// of the terminal colors stripped from the output: severity tags of text results are replaced by
+// their badge and JSONL results are prefixed with the badge of their severity.
+func RenderMarkdown(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "{") {
+			var res result
+			if err := json.Unmarshal([]byte(trimmed), &res); err != nil || res.TemplateID == "" {
+				continue
+			}
+			if badge, ok := severityBadges[strings.ToLower(res.Info.Severity)]; ok {
+				lines[i] = badge + " " + line
+			}
+			continue
+		}
+		lines[i] = severityTagRe.ReplaceAllStringFunc(line, func(tag string) string {
+			return severityBadges[strings.ToLower(tag[1:len(tag)-1])]
+		})
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -3,12 +3,14 @@ package nuclei
 import (
 	"context"
 	"fmt"
-	"os/exec"
+	"os"
+	"path/filepath"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/rs/zerolog"
 	"github.com/tb0hdan/wass-mcp/pkg/server"
 	"github.com/tb0hdan/wass-mcp/pkg/tools"
+	"github.com/tb0hdan/wass-mcp/pkg/vault"
 )
 
 const (
@@ -17,70 +19,141 @@ const (
 	headerVerb  = "output"
 )
 
+// supportedOptions are the scan options nuclei honours.
+var supportedOptions = []string{
+	tools.OptionCapture, tools.OptionCredential, tools.OptionInteractsh, tools.OptionInteractshServer,
+	tools.OptionRateLimit, tools.OptionURLs, tools.OptionUserAgent, tools.OptionVhost,
+}
+
 // Tool implements the nuclei scanner.
 type Tool struct {
 	tools.BaseScanner
+	// interactsh configures out-of-band testing, see SetInteractsh.
+	interactsh Interactsh
+	// resumeDir holds the resume state of interrupted scans, empty to disable resuming.
+	resumeDir string
+}
+
+// buildArgs returns the nuclei arguments for a scan of targetURL, or of the URLs listed in listFile
+// when set, resuming from resumeFile if set.
+func buildArgs(targetURL string, params tools.ScanParams, resumeFile, listFile string) []string {
+	args := []string{"-u", targetURL, "-jsonl"}
+	if listFile != "" {
+		args = []string{"-list", listFile, "-jsonl"}
+	}
+	if header := params.Target().HostHeader(); header != "" {
+		args = append(args, "-H", header)
+	}
+	if userAgent := params.Option(tools.OptionUserAgent); userAgent != "" {
+		args = append(args, "-H", fmt.Sprintf("User-Agent: %s", userAgent))
+	}
+	if params.Credential != nil {
+		for _, header := range params.Credential.Headers() {
+			args = append(args, "-H", header)
+		}
+	}
+	if rate := params.Option(tools.OptionRateLimit); rate != "" {
+		args = append(args, "-rate-limit", rate)
+	}
+	if params.Proxy != "" {
+		args = append(args, "-proxy", params.Proxy)
+	}
+	if resumeFile != "" {
+		args = append(args, "-resume", resumeFile)
+	}
+
+	return args
 }
 
-// Scan performs the nuclei scan and returns the output.
+// Scan performs the nuclei scan and returns the output. An interrupted scan saves its resume
+// state, and the next scan of the same target continues from it.
 func (t *Tool) Scan(ctx context.Context, params tools.ScanParams) tools.ScanResult {
-	targetURL := tools.BuildTargetURL(params)
-	t.Logger.Info().Msgf("Running nuclei scan on %s", targetURL)
+	targetURL := params.Target().URL()
+	logger := tools.ContextLogger(ctx, t.Logger)
+	logger.Info().Msgf("Running nuclei scan on %s", targetURL)
 
-	args := []string{"-u", targetURL, "-jsonl"}
-	if params.Vhost != "" {
-		args = append(args, "-H", fmt.Sprintf("Host: %s", params.Vhost))
+	if err := params.CheckCredential(binaryName, vault.HeaderTypes...); err != nil {
+		return tools.ScanResult{
+			Error: err,
+		}
+	}
+
+	resume := t.resumeStateFor(ctx, params)
+	previous, resuming := resume.load()
+	resumeFile := ""
+	if resuming {
+		resumeFile = resume.Config
+		logger.Info().Msgf("Resuming interrupted nuclei scan of %s", targetURL)
+	}
+
+	workDir, cleanup, err := t.WorkDir(ctx)
+	if err != nil {
+		return tools.ScanResult{
+			Error: err,
+		}
+	}
+	defer cleanup()
+	listFile, err := tools.WriteURLList(workDir, params)
+	if err != nil {
+		return tools.ScanResult{
+			Error: err,
+		}
 	}
 
-	cmd := exec.CommandContext(ctx, binaryName, args...) //nolint:gosec
-	output, err := cmd.CombinedOutput()
+	cmd := t.Command(ctx, append(buildArgs(targetURL, params, resumeFile, listFile), interactshArgs(t.interactsh, params)...)...)
+	cmd.Dir = workDir
+	cmd.Env = tools.ScanEnv(params, workDir)
+	// Interrupt rather than kill nuclei on cancellation, so that it saves a resume file.
+	cmd.Cancel = func() error {
+		return cmd.Process.Signal(os.Interrupt)
+	}
+	cmd.WaitDelay = interruptGrace
+	raw, err := t.CombinedOutput(ctx, cmd)
+
+	output := string(raw)
+	if resuming {
+		output = previous + "\n[Resumed interrupted nuclei scan]\n" + output
+	}
+	resume.finish(logger, output, err == nil)
 
 	if err != nil {
 		return tools.ScanResult{
-			Output: string(output),
+			Output: output,
 			Error:  fmt.Errorf("failed to execute nuclei: %w", err),
 		}
 	}
 
 	return tools.ScanResult{
-		Output: string(output),
+		Output: output,
 		Error:  nil,
 	}
 }
 
+// SetInteractsh configures the interactsh server of out-of-band testing for every scan.
+func (t *Tool) SetInteractsh(cfg Interactsh) {
+	t.interactsh = cfg
+}
+
 // Register registers the nuclei tool with the MCP server.
 func (t *Tool) Register(srv *server.Server) error {
+	if dir := srv.Artifacts().Dir; dir != "" {
+		t.resumeDir = filepath.Join(dir, resumeSubdir)
+	}
+
 	return t.RegisterTool(srv, t.Handler)
 }
 
 // Handler handles MCP tool requests.
 func (t *Tool) Handler(ctx context.Context, _ *mcp.CallToolRequest, input tools.ScannerInput) (*mcp.CallToolResult, any, error) {
-	input = t.PrepareInput(input)
-
-	if err := t.ValidateInput(input); err != nil {
-		return nil, nil, err
-	}
-
-	params := t.ResolveInput(input)
-
-	scanResult := t.Scan(ctx, params)
-	if scanResult.Error != nil {
-		return nil, nil, fmt.Errorf("%w\nOutput: %s", scanResult.Error, scanResult.Output)
-	}
-
-	targetURL := tools.BuildTargetURL(params)
-	resultText := tools.FormatScannerOutput(binaryName, headerVerb, targetURL, scanResult.Output, input.MaxLines, input.Offset)
-
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{
-			&mcp.TextContent{Text: resultText},
-		},
-	}, nil, nil
+	return t.HandleScan(ctx, input, headerVerb, t.Scan)
 }
 
 // New creates a new nuclei scanner tool.
 func New(logger zerolog.Logger) tools.Scanner {
-	return &Tool{
-		BaseScanner: tools.NewBaseScanner(binaryName, description, logger),
-	}
+	base := tools.NewBaseScanner(binaryName, description, logger, supportedOptions...)
+	base.VersionArgs = []string{"-version"}
+	base.RenderPage = RenderMarkdown
+	base.FormatOutput = GroupBySeverity
+
+	return &Tool{BaseScanner: base}
 }
@@ -303,6 +303,40 @@ func (s *NucleiTestSuite) TestScan_WithVhost() {
 	}
 }
 
+func (s *NucleiTestSuite) TestInteractshArgs() {
+	withOptions := func(options map[string]string) tools.ScanParams {
+		return tools.ScanParams{Host: "example.com", Options: options}
+	}
+	configured := Interactsh{Server: "https://oast.internal", Token: "secret"}
+
+	s.Empty(interactshArgs(Interactsh{}, withOptions(nil)), "nuclei defaults apply without configuration")
+	s.Equal([]string{"-interactsh-server", "https://oast.internal", "-interactsh-token", "secret"},
+		interactshArgs(configured, withOptions(nil)))
+	s.Equal([]string{"-no-interactsh"},
+		interactshArgs(configured, withOptions(map[string]string{tools.OptionInteractsh: "false"})))
+	s.Equal([]string{"-no-interactsh"},
+		interactshArgs(Interactsh{Disabled: true}, withOptions(map[string]string{tools.OptionInteractsh: "true"})),
+		"scans cannot turn OOB testing back on")
+	s.Equal([]string{"-interactsh-server", "oast.example.com"},
+		interactshArgs(configured, withOptions(map[string]string{tools.OptionInteractshServer: "oast.example.com"})),
+		"the configured token is never sent to another server")
+	s.Equal([]string{"-interactsh-server", "https://oast.internal", "-interactsh-token", "secret"},
+		interactshArgs(configured, withOptions(map[string]string{tools.OptionInteractshServer: "https://oast.internal"})))
+}
+
+func (s *NucleiTestSuite) TestInteractshValidate() {
+	s.NoError(Interactsh{}.Validate())
+	s.NoError(Interactsh{Disabled: true}.Validate())
+	s.NoError(Interactsh{Server: "https://oast.internal", Token: "secret"}.Validate())
+	s.Error(Interactsh{Server: "ftp://oast.internal"}.Validate())
+	s.Error(Interactsh{Token: "secret"}.Validate())
+}
+
+func (s *NucleiTestSuite) TestSupportedOptions() {
+	s.Contains(s.tool.SupportedOptions(), tools.OptionInteractsh)
+	s.Contains(s.tool.SupportedOptions(), tools.OptionInteractshServer)
+}
+
 func TestNucleiTestSuite(t *testing.T) {
 	suite.Run(t, new(NucleiTestSuite))
 }
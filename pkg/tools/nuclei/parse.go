@@ -0,0 +1,109 @@
+package nuclei
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/tb0hdan/wass-mcp/pkg/findings"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+)
+
+// result is the subset of a nuclei JSONL result used for findings.
+type result struct {
+	Host string `json:"host"`
+	Info struct {
+		Classification struct {
+			CVEID stringList `json:"cve-id"`
+			CWEID stringList `json:"cwe-id"`
+		} `json:"classification"`
+		Name      string     `json:"name"`
+		Reference stringList `json:"reference"`
+		Severity  string     `json:"severity"`
+		Tags      stringList `json:"tags"`
+	} `json:"info"`
+	CurlCommand      string   `json:"curl-command"`
+	ExtractedResults []string `json:"extracted-results"`
+	FuzzingParameter string   `json:"fuzzing_parameter"`
+	MatchedAt        string   `json:"matched-at"`
+	MatcherName      string   `json:"matcher-name"`
+	Request          string   `json:"request"`
+	Response         string   `json:"response"`
+	TemplateID       string   `json:"template-id"`
+}
+
+// stringList is a nuclei template field holding either a single string or a list of strings.
+type stringList []string
+
+// UnmarshalJSON accepts a string, a list of strings or null.
+func (l *stringList) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		if single != "" {
+			*l = stringList{single}
+		}
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*l = list
+
+	return nil
+}
+
+// evidence returns the request, response, reproduction command and extracted data of a result.
+func (r result) evidence() []models.Evidence {
+	var evidence []models.Evidence
+	evidence = findings.AppendEvidence(evidence, models.EvidenceRequest, binaryName, r.Request)
+	evidence = findings.AppendEvidence(evidence, models.EvidenceResponse, binaryName, r.Response)
+	evidence = findings.AppendEvidence(evidence, models.EvidenceCurl, binaryName, r.CurlCommand)
+	evidence = findings.AppendEvidence(evidence, models.EvidenceExtracted, binaryName, strings.Join(r.ExtractedResults, "\n"))
+
+	return evidence
+}
+
+// ParseFindings parses nuclei JSONL output, keeping the CVE and CWE IDs and references of the template and
+// the request, response, curl command and extracted results of each result as evidence. It falls back to bracketed severity tags for other lines.
+func (t *Tool) ParseFindings(output string) ([]models.Finding, error) {
+	var found []models.Finding
+	var rest strings.Builder
+
+	for _, line := range strings.Split(output, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if !strings.HasPrefix(trimmed, "{") {
+			rest.WriteString(line)
+			rest.WriteString("\n")
+			continue
+		}
+
+		var res result
+		if err := json.Unmarshal([]byte(trimmed), &res); err != nil || res.TemplateID == "" {
+			continue
+		}
+
+		title := res.Info.Name
+		if title == "" {
+			title = res.TemplateID
+		}
+		target := res.MatchedAt
+		if target == "" {
+			target = res.Host
+		}
+		found = append(found, models.Finding{
+			CVEs:       res.Info.Classification.CVEID,
+			CWEs:       res.Info.Classification.CWEID,
+			Evidence:   res.evidence(),
+			Parameter:  res.FuzzingParameter,
+			References: res.Info.Reference,
+			Scanner:    binaryName,
+			Severity:   findings.NormalizeSeverity(res.Info.Severity),
+			TemplateID: res.TemplateID,
+			Title:      title,
+			URL:        target,
+		})
+	}
+
+	return append(found, findings.ExtractGeneric(binaryName, rest.String())...), nil
+}
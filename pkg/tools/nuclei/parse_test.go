@@ -0,0 +1,155 @@
+package nuclei
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+	"github.com/tb0hdan/wass-mcp/pkg/types"
+)
+
+const jsonlOutput = `{"template-id":"exposed-git","info":{"name":"Exposed Git","severity":"high"},"host":"http://example.com","matched-at":"http://example.com/.git/config"}
+{"template-id":"tech-detect","info":{"severity":"info"},"host":"http://example.com"}
+not json {
+[template] [http] [critical] https://example.com/x
+[INF] Templates loaded for current scan: 1000`
+
+type ParseTestSuite struct {
+	suite.Suite
+	tool *Tool
+}
+
+func (s *ParseTestSuite) SetupTest() {
+	s.tool = New(zerolog.New(os.Stdout)).(*Tool)
+}
+
+func (s *ParseTestSuite) TestParseFindings() {
+	found, err := s.tool.ParseFindings(jsonlOutput)
+	s.Require().NoError(err)
+	s.Require().Len(found, 3)
+	s.Equal(models.Finding{
+		Scanner:    "nuclei",
+		Severity:   types.SeverityHigh,
+		TemplateID: "exposed-git",
+		Title:      "Exposed Git",
+		URL:        "http://example.com/.git/config",
+	}, found[0])
+	s.Equal("tech-detect", found[1].Title)
+	s.Equal("http://example.com", found[1].URL)
+	s.Equal(types.SeverityInfo, found[1].Severity)
+	s.Equal(types.SeverityCritical, found[2].Severity)
+	s.Equal("https://example.com/x", found[2].URL)
+}
+
+func (s *ParseTestSuite) TestParseFindings_Evidence() {
+	line := `{"template-id":"git-config","info":{"name":"Git Config","severity":"medium"},` +
+		`"matched-at":"http://example.com/.git/config","request":"GET /.git/config HTTP/1.1\r\nHost: example.com\r\n\r\n",` +
+		`"response":"HTTP/1.1 200 OK\r\n\r\n[core]\n","curl-command":"curl -X 'GET' 'http://example.com/.git/config'",` +
+		`"extracted-results":["[core]","bare = false"]}`
+
+	found, err := s.tool.ParseFindings(line)
+	s.Require().NoError(err)
+	s.Require().Len(found, 1)
+	s.Equal([]models.Evidence{
+		{Content: "GET /.git/config HTTP/1.1\r\nHost: example.com", Kind: models.EvidenceRequest, Source: "nuclei"},
+		{Content: "HTTP/1.1 200 OK\r\n\r\n[core]", Kind: models.EvidenceResponse, Source: "nuclei"},
+		{Content: "curl -X 'GET' 'http://example.com/.git/config'", Kind: models.EvidenceCurl, Source: "nuclei"},
+		{Content: "[core]\nbare = false", Kind: models.EvidenceExtracted, Source: "nuclei"},
+	}, found[0].Evidence)
+}
+
+func (s *ParseTestSuite) TestParseFindings_Classification() {
+	output := `{"template-id":"CVE-2021-44228","info":{"name":"Log4j RCE","severity":"critical",` +
+		`"classification":{"cve-id":["cve-2021-44228"],"cwe-id":["cwe-502"]},"reference":"https://logging.apache.org/log4j/2.x/security.html"},` +
+		`"matched-at":"http://example.com/"}
+{"template-id":"CVE-2017-5638","info":{"name":"Struts RCE","severity":"critical","classification":{"cve-id":"CVE-2017-5638"},` +
+		`"reference":["https://example.com/a","https://example.com/b"]},"matched-at":"http://example.com/struts"}`
+
+	found, err := s.tool.ParseFindings(output)
+	s.Require().NoError(err)
+	s.Require().Len(found, 2)
+	s.Equal([]string{"cve-2021-44228"}, found[0].CVEs)
+	s.Equal([]string{"cwe-502"}, found[0].CWEs)
+	s.Equal([]string{"https://logging.apache.org/log4j/2.x/security.html"}, found[0].References)
+	s.Equal([]string{"CVE-2017-5638"}, found[1].CVEs)
+	s.Equal([]string{"https://example.com/a", "https://example.com/b"}, found[1].References)
+}
+
+func (s *ParseTestSuite) TestParseFindings_FuzzingParameter() {
+	output := `{"template-id":"dast-xss","info":{"name":"Reflected XSS","severity":"medium"},` +
+		`"matched-at":"http://example.com/search?q=%3Cx%3E","fuzzing_parameter":"q"}`
+
+	found, err := s.tool.ParseFindings(output)
+	s.Require().NoError(err)
+	s.Require().Len(found, 1)
+	s.Equal("q", found[0].Parameter)
+}
+
+func (s *ParseTestSuite) TestParseFindings_Empty() {
+	found, err := s.tool.ParseFindings("")
+	s.Require().NoError(err)
+	s.Empty(found)
+}
+
+func (s *ParseTestSuite) TestFingerprint() {
+	output := `{"template-id":"tech-detect","matcher-name":"Nginx","info":{"severity":"info","tags":"tech"}}
+{"template-id":"wordpress-detect","info":{"severity":"info","tags":["tech","wordpress"]}}
+{"template-id":"tech-detect","matcher-name":"nginx","info":{"severity":"info"}}
+{"template-id":"exposed-git","info":{"severity":"high","tags":["exposure"]}}
+[INF] Templates loaded for current scan: 1000`
+
+	s.Equal([]string{"nginx", "wordpress"}, s.tool.Fingerprint(output))
+	s.Empty(s.tool.Fingerprint(jsonlOutput))
+}
+
+func (s *ParseTestSuite) TestRenderMarkdown() {
+	rendered := RenderMarkdown(jsonlOutput)
+	lines := strings.Split(rendered, "\n")
+	s.Require().Len(lines, 5)
+	s.True(strings.HasPrefix(lines[0], "🔴 **HIGH** {"), lines[0])
+	s.True(strings.HasPrefix(lines[1], "🔵 **INFO** {"), lines[1])
+	s.Equal("not json {", lines[2])
+	s.Equal("[template] [http] 🟣 **CRITICAL** https://example.com/x", lines[3])
+	s.Equal("[INF] Templates loaded for current scan: 1000", lines[4])
+}
+
+func (s *ParseTestSuite) TestHandleScan_RendersSeverities() {
+	scan := func(_ context.Context, _ tools.ScanParams) tools.ScanResult {
+		return tools.ScanResult{Output: "[exposed-git] [http] [\x1b[31mhigh\x1b[0m] http://example.com/.git/config"}
+	}
+
+	result, _, err := s.tool.HandleScan(context.Background(), tools.ScannerInput{Host: "example.com"}, headerVerb, scan)
+	s.Require().NoError(err)
+	text := result.Content[0].(*mcp.TextContent).Text
+	s.True(strings.HasSuffix(text, "\n[critical: 0, high: 1, medium: 0, low: 0, info: 0]\n"+
+		"[exposed-git] [http] 🔴 **HIGH** http://example.com/.git/config"), text)
+}
+
+func (s *ParseTestSuite) TestGroupBySeverity() {
+	grouped := GroupBySeverity(jsonlOutput + "\n")
+	lines := strings.Split(grouped, "\n")
+	s.Require().Len(lines, 6)
+	s.Equal("[critical: 1, high: 1, medium: 0, low: 0, info: 1]", lines[0])
+	s.Equal("[template] [http] [critical] https://example.com/x", lines[1])
+	s.True(strings.HasPrefix(lines[2], `{"template-id":"exposed-git"`), lines[2])
+	s.True(strings.HasPrefix(lines[3], `{"template-id":"tech-detect"`), lines[3])
+	s.Equal("not json {", lines[4])
+	s.Equal("[INF] Templates loaded for current scan: 1000", lines[5])
+
+	found, err := s.tool.ParseFindings(grouped)
+	s.Require().NoError(err)
+	s.Len(found, 3, "the summary line is not a finding")
+
+	s.Equal("[critical: 0, high: 0, medium: 0, low: 0, info: 0]\n[INF] No results found.",
+		GroupBySeverity("\n[INF] No results found.\n"))
+}
+
+func TestParseTestSuite(t *testing.T) {
+	suite.Run(t, new(ParseTestSuite))
+}
@@ -0,0 +1,158 @@
+package nuclei
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/tb0hdan/wass-mcp/pkg/tenant"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+)
+
+const (
+	// resumeSubdir is the directory, under the artifact directory, holding resume state.
+	resumeSubdir = "nuclei-resume"
+	// interruptGrace is how long an interrupted nuclei run may take to save its resume file
+	// before it is killed.
+	interruptGrace = 30 * time.Second
+
+	dirPerms  = 0o750
+	filePerms = 0o600
+)
+
+// resumeLineRe matches the line nuclei logs when it saves its resume file on interrupt.
+var resumeLineRe = regexp.MustCompile(`Creating resume file: (\S+)`)
+
+// resumeState is where the resume file and partial output of a scan are kept between runs.
+type resumeState struct {
+	// Config is the nuclei resume file passed to -resume.
+	Config string
+	// Output is the output of the interrupted runs.
+	Output string
+}
+
+// resumeStateFor returns the resume state location of a scan. Scans of the same target with the
+// same headers by the same tenant share it, so a re-run continues an interrupted one.
+// It returns nil when resuming is disabled.
+func (t *Tool) resumeStateFor(ctx context.Context, params tools.ScanParams) *resumeState {
+	if t.resumeDir == "" {
+		return nil
+	}
+
+	tenantName, _ := tenant.FromContext(ctx)
+	hash := sha256.New()
+	for _, part := range []string{tenantName, params.Target().URL(), params.Target().HostHeader(), params.Option(tools.OptionUserAgent)} {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	key := hex.EncodeToString(hash.Sum(nil))[:32]
+
+	return &resumeState{
+		Config: filepath.Join(t.resumeDir, key+".cfg"),
+		Output: filepath.Join(t.resumeDir, key+".out"),
+	}
+}
+
+// load returns the output of the interrupted runs when a resume file is present.
+func (r *resumeState) load() (string, bool) {
+	if r == nil {
+		return "", false
+	}
+	if _, err := os.Stat(r.Config); err != nil {
+		return "", false
+	}
+	output, _ := os.ReadFile(r.Output)
+
+	return string(output), true
+}
+
+// save keeps the resume file nuclei reported in output together with output, which includes the
+// output of earlier interrupted runs. It reports whether nuclei saved a resume file.
+func (r *resumeState) save(output string) (bool, error) {
+	match := resumeLineRe.FindStringSubmatch(output)
+	if r == nil || match == nil {
+		return false, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(r.Config), dirPerms); err != nil {
+		return false, fmt.Errorf("failed to create resume directory: %w", err)
+	}
+	if err := moveFile(match[1], r.Config); err != nil {
+		return false, fmt.Errorf("failed to keep nuclei resume file: %w", err)
+	}
+	if err := os.WriteFile(r.Output, []byte(output), filePerms); err != nil {
+		return false, fmt.Errorf("failed to keep interrupted nuclei output: %w", err)
+	}
+
+	return true, nil
+}
+
+// clear removes the resume state once a scan completed.
+func (r *resumeState) clear() error {
+	if r == nil {
+		return nil
+	}
+
+	var errs []error
+	for _, path := range []string{r.Config, r.Output} {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// finish updates the resume state after a nuclei run: kept when nuclei saved a resume file on
+// interrupt, cleared otherwise, so that failed resumes start over next time.
+func (r *resumeState) finish(logger zerolog.Logger, output string, completed bool) {
+	if !completed {
+		saved, err := r.save(output)
+		if err != nil {
+			logger.Warn().Err(err).Msg("Failed to keep nuclei resume state")
+		}
+		if saved {
+			logger.Info().Msgf("Nuclei resume file kept at %s", r.Config)
+			return
+		}
+	}
+
+	if err := r.clear(); err != nil {
+		logger.Warn().Err(err).Msg("Failed to remove nuclei resume state")
+	}
+}
+
+// moveFile moves src to dst, copying when they are on different file systems.
+func moveFile(src, dst string) error {
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+
+	in, err := os.Open(src) //nolint:gosec
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, filePerms) //nolint:gosec
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+
+	return os.Remove(src)
+}
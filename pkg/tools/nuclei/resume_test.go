@@ -0,0 +1,141 @@
+package nuclei
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/tenant"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+	"github.com/tb0hdan/wass-mcp/pkg/vault"
+)
+
+// fakeNuclei reports a finding and waits to be interrupted, saving a resume file in dir when it is.
+// Given -resume, it reports the saved progress and a second finding.
+const fakeNuclei = `#!/bin/sh
+resume=""
+while [ $# -gt 0 ]; do
+  if [ "$1" = "-resume" ]; then resume="$2"; fi
+  shift
+done
+if [ -n "$resume" ]; then
+  echo "resumed with $(cat "$resume")"
+  echo '{"template-id":"second","info":{"name":"Second","severity":"low"}}'
+  exit 0
+fi
+echo '{"template-id":"first","info":{"name":"First","severity":"high"}}'
+trap 'echo progress > "%[1]s/resume-x.cfg"; echo "[INF] Creating resume file: %[1]s/resume-x.cfg" >&2; kill $pid; exit 1' INT
+sleep 10 >/dev/null 2>&1 &
+pid=$!
+wait $pid
+`
+
+type ResumeTestSuite struct {
+	suite.Suite
+	tool *Tool
+}
+
+func (s *ResumeTestSuite) SetupTest() {
+	binDir := s.T().TempDir()
+	script := fmt.Sprintf(fakeNuclei, s.T().TempDir())
+	s.Require().NoError(os.WriteFile(filepath.Join(binDir, binaryName), []byte(script), 0o700)) //nolint:gosec
+	s.T().Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	s.tool = New(zerolog.Nop()).(*Tool)
+	s.tool.resumeDir = filepath.Join(s.T().TempDir(), resumeSubdir)
+}
+
+func (s *ResumeTestSuite) TestScan_ResumesInterruptedRun() {
+	params := tools.ScanParams{Host: "example.com", Port: 80, Scheme: "http"}
+	state := s.tool.resumeStateFor(context.Background(), params)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	interrupted := s.tool.Scan(ctx, params)
+	s.Require().Error(interrupted.Error)
+	s.FileExists(state.Config)
+	saved, err := os.ReadFile(state.Output)
+	s.Require().NoError(err)
+	s.Contains(string(saved), `"template-id":"first"`)
+
+	resumed := s.tool.Scan(context.Background(), params)
+	s.Require().NoError(resumed.Error)
+	s.Contains(resumed.Output, `"template-id":"first"`)
+	s.Contains(resumed.Output, "[Resumed interrupted nuclei scan]")
+	s.Contains(resumed.Output, "resumed with progress")
+	s.Contains(resumed.Output, `"template-id":"second"`)
+	s.NoFileExists(state.Config)
+	s.NoFileExists(state.Output)
+
+	found, err := s.tool.ParseFindings(resumed.Output)
+	s.Require().NoError(err)
+	s.Len(found, 2)
+}
+
+func (s *ResumeTestSuite) TestScan_FailureWithoutResumeFileClearsState() {
+	params := tools.ScanParams{Host: "example.com", Port: 80, Scheme: "http"}
+	state := s.tool.resumeStateFor(context.Background(), params)
+	s.Require().NoError(os.MkdirAll(s.tool.resumeDir, dirPerms))
+	s.Require().NoError(os.WriteFile(state.Config, []byte("stale"), filePerms))
+
+	state.finish(zerolog.Nop(), "nuclei crashed", false)
+	s.NoFileExists(state.Config)
+}
+
+func (s *ResumeTestSuite) TestResumeStateFor() {
+	params := tools.ScanParams{Host: "example.com", Port: 80, Scheme: "http"}
+	base := s.tool.resumeStateFor(context.Background(), params)
+	s.Equal(base, s.tool.resumeStateFor(context.Background(), params))
+	s.Equal(s.tool.resumeDir, filepath.Dir(base.Config))
+
+	other := params
+	other.Vhost = "app.example.com"
+	s.NotEqual(base.Config, s.tool.resumeStateFor(context.Background(), other).Config)
+	tenantCtx := tenant.WithTenant(context.Background(), "acme")
+	s.NotEqual(base.Config, s.tool.resumeStateFor(tenantCtx, params).Config)
+
+	s.tool.resumeDir = ""
+	s.Nil(s.tool.resumeStateFor(context.Background(), params))
+}
+
+func (s *ResumeTestSuite) TestBuildArgs_Resume() {
+	params := tools.ScanParams{Host: "example.com"}
+	s.Equal([]string{"-u", "http://example.com", "-jsonl"}, buildArgs("http://example.com", params, "", ""))
+	s.Equal([]string{"-u", "http://example.com", "-jsonl", "-resume", "/state/a.cfg"},
+		buildArgs("http://example.com", params, "/state/a.cfg", ""))
+
+	params.Proxy = "http://127.0.0.1:8080"
+	s.Equal([]string{"-u", "http://example.com", "-jsonl", "-proxy", "http://127.0.0.1:8080"},
+		buildArgs("http://example.com", params, "", ""))
+
+	params.Options = map[string]string{tools.OptionRateLimit: "2"}
+	s.Equal([]string{"-u", "http://example.com", "-jsonl", "-rate-limit", "2", "-proxy", "http://127.0.0.1:8080"},
+		buildArgs("http://example.com", params, "", ""))
+}
+
+func (s *ResumeTestSuite) TestBuildArgs_URLList() {
+	params := tools.ScanParams{Host: "example.com", URLs: []string{"http://example.com/", "http://example.com/login"}}
+	s.Equal([]string{"-list", "/scans/urls.txt", "-jsonl"}, buildArgs("http://example.com", params, "", "/scans/urls.txt"))
+}
+
+func (s *ResumeTestSuite) TestBuildArgs_Credential() {
+	params := tools.ScanParams{
+		Host:       "example.com",
+		Credential: &vault.Credential{Type: vault.TypeBearer, Secret: vault.Secret{Token: "abc"}},
+	}
+	s.Equal([]string{"-u", "http://example.com", "-jsonl", "-H", "Authorization: Bearer abc"},
+		buildArgs("http://example.com", params, "", ""))
+
+	params.Credential = &vault.Credential{Type: vault.TypeLoginForm}
+	result := s.tool.Scan(context.Background(), params)
+	s.ErrorIs(result.Error, vault.ErrUnsupportedType)
+}
+
+func TestResumeTestSuite(t *testing.T) {
+	suite.Run(t, new(ResumeTestSuite))
+}
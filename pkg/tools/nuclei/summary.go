@@ -0,0 +1,59 @@
+package nuclei
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/tb0hdan/wass-mcp/pkg/findings"
+)
+
+// resultSeverity returns the normalized severity of a nuclei result line, JSONL or text, and
+// whether the line is a result at all.
+func resultSeverity(line string) (string, bool) {
+	trimmed := strings.TrimSpace(line)
+	if strings.HasPrefix(trimmed, "{") {
+		var res result
+		if err := json.Unmarshal([]byte(trimmed), &res); err != nil || res.TemplateID == "" {
+			return "", false
+		}
+		return findings.NormalizeSeverity(res.Info.Severity), true
+	}
+	if match := severityTagRe.FindStringSubmatch(trimmed); match != nil {
+		return findings.NormalizeSeverity(match[1]), true
+	}
+
+	return "", false
+}
+
+// GroupBySeverity formats the output of the nuclei tool: a "[critical: 1, high: 0, ...]" count of
+// the results per severity, then the results grouped from the most to the least severe, keeping
+// their order within a severity, then the other lines, such as the nuclei log, in their order.
+// Unknown severities count as info, like their findings.
+func GroupBySeverity(output string) string {
+	groups := make(map[string][]string, len(findings.Severities))
+	var rest []string
+	for _, line := range strings.Split(output, "\n") {
+		if severity, ok := resultSeverity(line); ok {
+			groups[severity] = append(groups[severity], line)
+			continue
+		}
+		rest = append(rest, line)
+	}
+
+	counts := make([]string, 0, len(findings.Severities))
+	lines := make([]string, 1, len(rest)+1)
+	for _, severity := range findings.Severities {
+		counts = append(counts, fmt.Sprintf("%s: %d", severity, len(groups[severity])))
+		lines = append(lines, groups[severity]...)
+	}
+	lines[0] = "[" + strings.Join(counts, ", ") + "]"
+
+	// The log keeps its own line breaks, only the blank lines around it are dropped.
+	other := strings.Trim(strings.Join(rest, "\n"), "\n")
+	if other != "" {
+		lines = append(lines, other)
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,397 @@
+package tools
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/tb0hdan/wass-mcp/pkg/scanconfig"
+)
+
+// Scan option names. Typed ScanParams fields and generic ScanParams.Options entries share one
+// namespace so that scanners can declare support for either kind.
+const (
+	// OptionActiveScan is the generic option adding an active scan to a baseline scan.
+	OptionActiveScan = "active_scan"
+	// OptionAPIToken is the generic option passing the token of a vulnerability database API, such
+	// as the WPScan API, overriding the token configured on the server.
+	OptionAPIToken = "api_token"
+	// OptionCABundle is the ScanParams.CABundle field.
+	OptionCABundle = "ca_bundle"
+	// OptionConfig is the generic option naming a scanner config file in the allowlisted
+	// directory, see scanconfig.Config.
+	OptionConfig = "config"
+	// OptionCredential is the ScanParams.Credential field, the stored credential a scan
+	// authenticates with.
+	OptionCredential = "credential"
+	// OptionCapture is the ScanParams.Capture field, honoured by scanners that accept an HTTP proxy.
+	OptionCapture = "capture"
+	// OptionDepth is the generic option setting how deep a scanner explores the site from the start
+	// URL, such as the wapiti crawl depth.
+	OptionDepth = "depth"
+	// OptionEnumerate is the generic option choosing what a CMS scanner enumerates, comma-separated
+	// WPScanEnumerations such as "vp,vt,u".
+	OptionEnumerate = "enumerate"
+	// OptionExtensions is the generic option adding comma-separated file extensions, such as
+	// ".php,.bak", to every entry a content discovery scanner brute-forces.
+	OptionExtensions = "extensions"
+	// OptionInteractsh is the generic option turning out-of-band (OOB) interaction testing on or
+	// off, e.g. "false" for targets that must not be probed for blind SSRF.
+	OptionInteractsh = "interactsh"
+	// OptionInteractshServer is the generic option naming the interactsh server OOB interactions
+	// are reported to, e.g. a self-hosted server reachable from a restricted network.
+	OptionInteractshServer = "interactsh_server"
+	// OptionInsecureSkipVerify is the ScanParams.InsecureSkipVerify field.
+	OptionInsecureSkipVerify = "insecure_skip_verify"
+	// OptionMaxAttackTime is the generic option bounding the seconds spent per attack module.
+	OptionMaxAttackTime = "max_attack_time"
+	// OptionMaxDepth is the generic option bounding the crawl depth.
+	OptionMaxDepth = "max_depth"
+	// OptionLevel is the generic option setting the thoroughness of injection tests, 1 to 5.
+	OptionLevel = "level"
+	// OptionMaxLinksPerPage is the generic option bounding the links followed per crawled page.
+	OptionMaxLinksPerPage = "max_links_per_page"
+	// OptionMaxScanTime is the generic option bounding the seconds spent on the whole scan.
+	OptionMaxScanTime = "max_scan_time"
+	// OptionModules is the generic option choosing the attack modules of a scanner, comma-separated
+	// module names such as "sql,xss", a leading "-" excluding a module, e.g. "common,-nikto".
+	OptionModules = "modules"
+	// OptionParameter is the generic option restricting injection tests to comma-separated
+	// parameter names.
+	OptionParameter = "parameter"
+	// OptionRateLimit is the generic option bounding the requests per second a scanner sends.
+	OptionRateLimit = "rate_limit"
+	// OptionRisk is the generic option setting the risk of injection payloads, 1 to 3. Higher
+	// risks add payloads that may modify data.
+	OptionRisk = "risk"
+	// OptionScope is the generic option choosing which crawled URLs a scanner attacks, one of
+	// CrawlScopes.
+	OptionScope = "scope"
+	// OptionScriptCategories is the generic option restricting NSE scripts to comma-separated
+	// nmap script categories, such as "safe,vuln".
+	OptionScriptCategories = "script_categories"
+	// OptionThreads is the generic option setting the number of concurrent requests of a scanner.
+	OptionThreads = "threads"
+	// OptionURLs is the ScanParams.URLs field, the crawled URLs of the target.
+	OptionURLs = "urls"
+	// OptionUserAgent is the generic option overriding the scanner User-Agent header.
+	OptionUserAgent = "user_agent"
+	// OptionVhost is the ScanParams.Vhost field.
+	OptionVhost = "vhost"
+	// OptionWordlist is the ScanParams.Wordlist field, the stored wordlist content discovery
+	// scanners brute-force paths with.
+	OptionWordlist = "wordlist"
+)
+
+// legacyOptions are the options assumed for scanners that do not declare their supported options.
+var legacyOptions = []string{OptionCABundle, OptionInsecureSkipVerify, OptionVhost}
+
+// intRange is the range of an integer option value.
+type intRange struct {
+	min, max int
+}
+
+// intOptions are the generic options taking an integer, with their allowed range.
+var intOptions = map[string]intRange{
+	OptionDepth:           {min: 1, max: 1000},
+	OptionLevel:           {min: 1, max: 5},
+	OptionMaxAttackTime:   {min: 1, max: 86400},
+	OptionMaxDepth:        {min: 1, max: 1000},
+	OptionMaxLinksPerPage: {min: 1, max: 10000},
+	OptionMaxScanTime:     {min: 1, max: 86400},
+	OptionRateLimit:       {min: 1, max: 1000},
+	OptionRisk:            {min: 1, max: 3},
+	OptionThreads:         {min: 1, max: 200},
+}
+
+// boolOptions are the generic options taking a boolean, parsed with strconv.ParseBool.
+var boolOptions = map[string]struct{}{
+	OptionActiveScan: {},
+	OptionInteractsh: {},
+}
+
+// urlOptions are the generic options taking a server URL, see ValidServerURL.
+var urlOptions = map[string]struct{}{
+	OptionInteractshServer: {},
+}
+
+// ValidServerURL reports whether value is an http(s) URL or a bare host name, optionally with a
+// port, such as "https://oast.example.com" or "oast.example.com:8443".
+func ValidServerURL(value string) bool {
+	if strings.HasPrefix(value, "-") {
+		return false
+	}
+	if !strings.Contains(value, "://") {
+		value = "https://" + value
+	}
+	parsed, err := url.Parse(value)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return false
+	}
+
+	return parsed.Hostname() != "" && parsed.User == nil && parsed.RawQuery == "" && parsed.Fragment == "" &&
+		!strings.ContainsAny(value, " \t\n")
+}
+
+// parameterNamePattern matches a request parameter name, such as "id" or "user[name]".
+var parameterNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.\[\]][A-Za-z0-9_.\[\]-]*$`)
+
+// validParameterNames reports whether value is a comma-separated list of parameter names.
+func validParameterNames(value string) bool {
+	for _, name := range strings.Split(value, ",") {
+		if !parameterNamePattern.MatchString(strings.TrimSpace(name)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// apiTokenPattern matches an API token: letters, digits, "-" and "_".
+var apiTokenPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,128}$`)
+
+// WPScanEnumerations are the wpscan enumeration values accepted by OptionEnumerate: vulnerable,
+// all or popular plugins (vp, ap, p) and themes (vt, at, t), timthumbs, config backups, database
+// exports, users and media. Users and media take an optional ID range, e.g. "u1-20".
+var WPScanEnumerations = []string{"vp", "ap", "p", "vt", "at", "t", "tt", "cb", "dbe", "u", "m"}
+
+// enumerationRangePattern matches the user and media enumerations with an ID range.
+var enumerationRangePattern = regexp.MustCompile(`^[um][0-9]+-[0-9]+$`)
+
+// validEnumerations reports whether value is a comma-separated list of WPScanEnumerations naming
+// at most one of the plugin and one of the theme enumerations, which wpscan rejects together.
+func validEnumerations(value string) bool {
+	var plugins, themes int
+	for _, enumeration := range strings.Split(value, ",") {
+		enumeration = strings.TrimSpace(enumeration)
+		if !slices.Contains(WPScanEnumerations, enumeration) && !enumerationRangePattern.MatchString(enumeration) {
+			return false
+		}
+		switch enumeration {
+		case "vp", "ap", "p":
+			plugins++
+		case "vt", "at", "t":
+			themes++
+		}
+	}
+
+	return plugins <= 1 && themes <= 1
+}
+
+// maxExtensions bounds the extensions of OptionExtensions, each of which multiplies the requests
+// of a content discovery scan.
+const maxExtensions = 10
+
+// extensionPattern matches a file extension with an optional leading dot, such as ".php" or "bak".
+var extensionPattern = regexp.MustCompile(`^\.?[A-Za-z0-9][A-Za-z0-9_-]{0,15}$`)
+
+// validExtensions reports whether value is a comma-separated list of at most maxExtensions file
+// extensions.
+func validExtensions(value string) bool {
+	extensions := strings.Split(value, ",")
+	if len(extensions) > maxExtensions {
+		return false
+	}
+	for _, extension := range extensions {
+		if !extensionPattern.MatchString(strings.TrimSpace(extension)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ScriptCategories are the nmap script categories accepted by OptionScriptCategories.
+var ScriptCategories = []string{
+	"auth", "broadcast", "brute", "default", "discovery", "dos", "exploit", "external", "fuzzer",
+	"intrusive", "malware", "safe", "version", "vuln",
+}
+
+// CrawlScopes are the crawl scopes accepted by OptionScope, from the narrowest: the start URL only,
+// the URLs of its page with other query strings, its folder, and its subdomain or domain. The
+// wapiti "punk" scope, following links to any host, is not accepted as it leaves the scan target.
+var CrawlScopes = []string{"url", "page", "folder", "subdomain", "domain"}
+
+// maxModules bounds the modules of OptionModules.
+const maxModules = 50
+
+// modulePattern matches a scanner module name with an optional leading "-" excluding it.
+var modulePattern = regexp.MustCompile(`^-?[a-z][a-z0-9_]{0,31}$`)
+
+// validModules reports whether value is a comma-separated list of at most maxModules module
+// names, the first of which is not an exclusion so that the list cannot be read as a flag.
+func validModules(value string) bool {
+	modules := strings.Split(value, ",")
+	if len(modules) > maxModules || strings.HasPrefix(value, "-") {
+		return false
+	}
+	for _, module := range modules {
+		if !modulePattern.MatchString(module) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// validScriptCategories reports whether value is a comma-separated list of ScriptCategories.
+func validScriptCategories(value string) bool {
+	for _, category := range strings.Split(value, ",") {
+		if !slices.Contains(ScriptCategories, strings.TrimSpace(category)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ValidateOptions checks the values of the known generic options. Unknown options are accepted
+// and left to negotiation.
+func ValidateOptions(options map[string]string) error {
+	if name, ok := options[OptionConfig]; ok && !scanconfig.ValidName(name) {
+		return fmt.Errorf("option %s: %w, got %q", OptionConfig, scanconfig.ErrInvalidName, name)
+	}
+	if names, ok := options[OptionParameter]; ok && !validParameterNames(names) {
+		return fmt.Errorf("option %s must be comma-separated parameter names, got %q", OptionParameter, names)
+	}
+	if token, ok := options[OptionAPIToken]; ok && !apiTokenPattern.MatchString(token) {
+		return fmt.Errorf("option %s must be letters, digits, - and _, up to 128 characters", OptionAPIToken)
+	}
+	if enumerations, ok := options[OptionEnumerate]; ok && !validEnumerations(enumerations) {
+		return fmt.Errorf("option %s must be comma-separated wpscan enumerations (%s) with at most one plugin "+
+			"and one theme enumeration, got %q", OptionEnumerate, strings.Join(WPScanEnumerations, ", "), enumerations)
+	}
+	if extensions, ok := options[OptionExtensions]; ok && !validExtensions(extensions) {
+		return fmt.Errorf("option %s must be up to %d comma-separated file extensions such as .php, got %q",
+			OptionExtensions, maxExtensions, extensions)
+	}
+	if modules, ok := options[OptionModules]; ok && !validModules(modules) {
+		return fmt.Errorf("option %s must be up to %d comma-separated module names, a leading - excluding one, got %q",
+			OptionModules, maxModules, modules)
+	}
+	if scope, ok := options[OptionScope]; ok && !slices.Contains(CrawlScopes, scope) {
+		return fmt.Errorf("option %s must be one of %s, got %q", OptionScope, strings.Join(CrawlScopes, ", "), scope)
+	}
+	if categories, ok := options[OptionScriptCategories]; ok && !validScriptCategories(categories) {
+		return fmt.Errorf("option %s must be comma-separated nmap script categories (%s), got %q",
+			OptionScriptCategories, strings.Join(ScriptCategories, ", "), categories)
+	}
+
+	names := make([]string, 0, len(options))
+	for name := range options {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if _, ok := boolOptions[name]; ok {
+			if _, err := strconv.ParseBool(options[name]); err != nil {
+				return fmt.Errorf("option %s must be true or false, got %q", name, options[name])
+			}
+		}
+		if _, ok := urlOptions[name]; ok && !ValidServerURL(options[name]) {
+			return fmt.Errorf("option %s must be an http(s) URL or host name, got %q", name, options[name])
+		}
+
+		bounds, ok := intOptions[name]
+		if !ok {
+			continue
+		}
+		value, err := strconv.Atoi(options[name])
+		if err != nil || value < bounds.min || value > bounds.max {
+			return fmt.Errorf("option %s must be an integer between %d and %d, got %q",
+				name, bounds.min, bounds.max, options[name])
+		}
+	}
+
+	return nil
+}
+
+// OptionSupporter is implemented by scanners that declare which scan options they honour.
+// Options a scanner does not declare are removed before it runs instead of failing the scan.
+type OptionSupporter interface {
+	SupportedOptions() []string
+}
+
+// Option returns the value of a generic scan option, or an empty string when it is not set.
+func (p ScanParams) Option(name string) string {
+	return p.Options[name]
+}
+
+// Restrict returns a copy of the parameters keeping only the supported options, together with
+// the sorted names of the options that were set but removed.
+func (p ScanParams) Restrict(supported []string) (ScanParams, []string) {
+	allowed := make(map[string]struct{}, len(supported))
+	for _, name := range supported {
+		allowed[name] = struct{}{}
+	}
+	isAllowed := func(name string) bool {
+		_, ok := allowed[name]
+		return ok
+	}
+
+	var ignored []string
+	if p.CABundle != "" && !isAllowed(OptionCABundle) {
+		p.CABundle = ""
+		ignored = append(ignored, OptionCABundle)
+	}
+	if p.Capture && !isAllowed(OptionCapture) {
+		p.Capture = false
+		ignored = append(ignored, OptionCapture)
+	}
+	if p.Credential != nil && !isAllowed(OptionCredential) {
+		p.Credential = nil
+		ignored = append(ignored, OptionCredential)
+	}
+	if p.InsecureSkipVerify && !isAllowed(OptionInsecureSkipVerify) {
+		p.InsecureSkipVerify = false
+		ignored = append(ignored, OptionInsecureSkipVerify)
+	}
+	if len(p.URLs) > 0 && !isAllowed(OptionURLs) {
+		p.URLs = nil
+		ignored = append(ignored, OptionURLs)
+	}
+	if p.Vhost != "" && !isAllowed(OptionVhost) {
+		p.Vhost = ""
+		ignored = append(ignored, OptionVhost)
+	}
+	if p.Wordlist != "" && !isAllowed(OptionWordlist) {
+		p.Wordlist = ""
+		ignored = append(ignored, OptionWordlist)
+	}
+
+	if len(p.Options) > 0 {
+		options := make(map[string]string, len(p.Options))
+		for name, value := range p.Options {
+			if isAllowed(name) {
+				options[name] = value
+			} else {
+				ignored = append(ignored, name)
+			}
+		}
+		p.Options = options
+	}
+	sort.Strings(ignored)
+
+	return p, ignored
+}
+
+// NegotiateOptions restricts params to the options supported by scanner and returns the names of
+// the removed options. Scanners that do not implement OptionSupporter keep the typed TLS and
+// vhost parameters and lose every generic option.
+func NegotiateOptions(scanner Scanner, params ScanParams) (ScanParams, []string) {
+	return params.Restrict(SupportedOptions(scanner))
+}
+
+// SupportedOptions returns the scan options honoured by scanner. Scanners that do not implement
+// OptionSupporter honour the typed TLS and vhost parameters only.
+func SupportedOptions(scanner Scanner) []string {
+	if supporter, ok := scanner.(OptionSupporter); ok {
+		return supporter.SupportedOptions()
+	}
+	return legacyOptions
+}
@@ -0,0 +1,182 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/scanconfig"
+	"github.com/tb0hdan/wass-mcp/pkg/vault"
+)
+
+type OptionsTestSuite struct {
+	suite.Suite
+}
+
+func (s *OptionsTestSuite) TestRestrict_KeepsSupported() {
+	params := ScanParams{
+		CABundle: "/etc/ssl/ca.pem",
+		Host:     "example.com",
+		Options:  map[string]string{OptionUserAgent: "wass"},
+		Vhost:    "vhost.example.com",
+	}
+
+	restricted, ignored := params.Restrict([]string{OptionCABundle, OptionUserAgent, OptionVhost})
+	s.Empty(ignored)
+	s.Equal(params, restricted)
+}
+
+func (s *OptionsTestSuite) TestRestrict_DropsUnsupported() {
+	params := ScanParams{
+		CABundle:           "/etc/ssl/ca.pem",
+		Capture:            true,
+		Credential:         &vault.Credential{Name: "staging-admin", Type: vault.TypeBasic},
+		Host:               "example.com",
+		InsecureSkipVerify: true,
+		Options:            map[string]string{OptionUserAgent: "wass", "future": "x"},
+		URLs:               []string{"http://example.com/login"},
+		Vhost:              "vhost.example.com",
+		Wordlist:           "/data/wordlists/common.txt",
+	}
+
+	restricted, ignored := params.Restrict([]string{OptionVhost})
+	s.Equal([]string{
+		OptionCABundle, OptionCapture, OptionCredential, "future", OptionInsecureSkipVerify, OptionURLs, OptionUserAgent, OptionWordlist,
+	}, ignored)
+	s.Empty(restricted.CABundle)
+	s.False(restricted.Capture)
+	s.Nil(restricted.Credential)
+	s.False(restricted.InsecureSkipVerify)
+	s.Empty(restricted.Options)
+	s.Empty(restricted.URLs)
+	s.Empty(restricted.Wordlist)
+	s.Equal("vhost.example.com", restricted.Vhost)
+	s.Equal("example.com", restricted.Host)
+
+	// The original options are left untouched.
+	s.Len(params.Options, 2)
+}
+
+func (s *OptionsTestSuite) TestOption() {
+	params := ScanParams{Options: map[string]string{OptionUserAgent: "wass"}}
+	s.Equal("wass", params.Option(OptionUserAgent))
+	s.Empty(params.Option("missing"))
+	s.Empty(ScanParams{}.Option(OptionUserAgent))
+}
+
+func (s *OptionsTestSuite) TestNegotiateOptions_DeclaredOptions() {
+	scanner := &optionScanner{textScanner: textScanner{name: "declared"}, options: []string{OptionUserAgent}}
+	params := ScanParams{Vhost: "vhost.example.com", Options: map[string]string{OptionUserAgent: "wass"}}
+
+	restricted, ignored := NegotiateOptions(scanner, params)
+	s.Equal([]string{OptionVhost}, ignored)
+	s.Empty(restricted.Vhost)
+	s.Equal("wass", restricted.Option(OptionUserAgent))
+}
+
+func (s *OptionsTestSuite) TestNegotiateOptions_UndeclaredKeepsTypedParams() {
+	params := ScanParams{
+		CABundle:           "/etc/ssl/ca.pem",
+		InsecureSkipVerify: true,
+		Options:            map[string]string{OptionUserAgent: "wass"},
+		Vhost:              "vhost.example.com",
+	}
+
+	restricted, ignored := NegotiateOptions(&textScanner{name: "legacy"}, params)
+	s.Equal([]string{OptionUserAgent}, ignored)
+	s.Equal("/etc/ssl/ca.pem", restricted.CABundle)
+	s.True(restricted.InsecureSkipVerify)
+	s.Equal("vhost.example.com", restricted.Vhost)
+}
+
+func (s *OptionsTestSuite) TestSupportedOptions() {
+	declared := &optionScanner{textScanner: textScanner{name: "declared"}, options: []string{OptionUserAgent}}
+	s.Equal([]string{OptionUserAgent}, SupportedOptions(declared))
+	s.Equal(legacyOptions, SupportedOptions(&textScanner{name: "legacy"}))
+}
+
+func (s *OptionsTestSuite) TestBaseScanner_SupportedOptions() {
+	s.Equal(legacyOptions, (&BaseScanner{}).SupportedOptions())
+
+	scanner := NewBaseScanner("test", "test", zerolog.Nop(), OptionVhost)
+	s.Equal([]string{OptionVhost}, scanner.SupportedOptions())
+}
+
+func (s *OptionsTestSuite) TestValidateOptions() {
+	s.NoError(ValidateOptions(nil))
+	s.NoError(ValidateOptions(map[string]string{
+		OptionMaxDepth: "5", OptionMaxLinksPerPage: "10000", OptionMaxAttackTime: "60", OptionUserAgent: "any", "unknown": "x",
+	}))
+	s.NoError(ValidateOptions(map[string]string{OptionRateLimit: "1000"}))
+	s.ErrorContains(ValidateOptions(map[string]string{OptionRateLimit: "0"}), "option rate_limit")
+
+	s.EqualError(ValidateOptions(map[string]string{OptionMaxDepth: "0"}),
+		`option max_depth must be an integer between 1 and 1000, got "0"`)
+	s.ErrorContains(ValidateOptions(map[string]string{OptionMaxLinksPerPage: "many"}), "option max_links_per_page")
+	s.ErrorContains(ValidateOptions(map[string]string{OptionMaxAttackTime: "86401"}), "option max_attack_time")
+	s.ErrorContains(ValidateOptions(map[string]string{OptionMaxDepth: "-1", OptionMaxAttackTime: "1.5"}),
+		"option max_attack_time")
+
+	s.NoError(ValidateOptions(map[string]string{
+		OptionDepth: "40", OptionMaxScanTime: "3600", OptionModules: "common,-nikto,wp_enum", OptionScope: "folder",
+	}))
+	s.ErrorContains(ValidateOptions(map[string]string{OptionMaxScanTime: "0"}), "option max_scan_time")
+	for _, modules := range []string{"", "-sql", "sql,,xss", "sql;xss", "SQL", strings.Repeat("a,", maxModules) + "a"} {
+		s.ErrorContains(ValidateOptions(map[string]string{OptionModules: modules}), "option modules", modules)
+	}
+	s.EqualError(ValidateOptions(map[string]string{OptionScope: "punk"}),
+		`option scope must be one of url, page, folder, subdomain, domain, got "punk"`)
+
+	s.NoError(ValidateOptions(map[string]string{OptionConfig: "tuned.conf"}))
+	for _, name := range []string{"", "../nikto.conf", "nikto/tuned.conf", ".hidden", ".."} {
+		s.ErrorIs(ValidateOptions(map[string]string{OptionConfig: name}), scanconfig.ErrInvalidName, name)
+	}
+
+	s.NoError(ValidateOptions(map[string]string{OptionInteractsh: "false", OptionInteractshServer: "https://oast.example.com"}))
+	s.NoError(ValidateOptions(map[string]string{OptionInteractshServer: "oast.example.com:8443"}))
+	s.EqualError(ValidateOptions(map[string]string{OptionInteractsh: "off"}), `option interactsh must be true or false, got "off"`)
+	for _, server := range []string{"", "-interactsh-token", "ftp://oast.example.com", "https://user:pw@oast.example.com", "https://oast.example.com?x=1"} {
+		s.ErrorContains(ValidateOptions(map[string]string{OptionInteractshServer: server}), "option interactsh_server", server)
+	}
+
+	s.NoError(ValidateOptions(map[string]string{OptionLevel: "5", OptionRisk: "1", OptionParameter: "id, user[name],q"}))
+	s.ErrorContains(ValidateOptions(map[string]string{OptionRisk: "4"}), "option risk")
+	s.ErrorContains(ValidateOptions(map[string]string{OptionLevel: "0"}), "option level")
+	for _, names := range []string{"", "id,", "--os-shell", "id;ls", "a b"} {
+		s.ErrorContains(ValidateOptions(map[string]string{OptionParameter: names}), "option parameter", names)
+	}
+
+	s.NoError(ValidateOptions(map[string]string{OptionExtensions: ".php, bak,.tar-gz", OptionThreads: "40"}))
+	for _, extensions := range []string{"", ".php,", "..php", ".php;ls", "-e", ".a,.b,.c,.d,.e,.f,.g,.h,.i,.j,.k"} {
+		s.ErrorContains(ValidateOptions(map[string]string{OptionExtensions: extensions}), "option extensions", extensions)
+	}
+	s.ErrorContains(ValidateOptions(map[string]string{OptionThreads: "500"}), "option threads must be an integer between 1 and 200")
+
+	s.NoError(ValidateOptions(map[string]string{OptionScriptCategories: "safe, vuln,discovery"}))
+	for _, categories := range []string{"", "safe,", "http-*", "safe or dos", "Safe"} {
+		s.ErrorContains(ValidateOptions(map[string]string{OptionScriptCategories: categories}), "option script_categories", categories)
+	}
+
+	s.NoError(ValidateOptions(map[string]string{OptionAPIToken: "kMAeW1lKFxq2c0vAUnoq9Y2ilRE4Wr-_5h7pqb3dJzE", OptionEnumerate: "vp,vt, u1-20,m,cb"}))
+	for _, token := range []string{"", "abc def", "abc\ncli_options:", strings.Repeat("a", 129)} {
+		s.ErrorContains(ValidateOptions(map[string]string{OptionAPIToken: token}), "option api_token")
+	}
+	for _, enumerations := range []string{"", "vp,", "vp,ap", "t,vt", "users", "u1", "--random-user-agent"} {
+		s.ErrorContains(ValidateOptions(map[string]string{OptionEnumerate: enumerations}), "option enumerate", enumerations)
+	}
+}
+
+// optionScanner is a scanner declaring its supported options.
+type optionScanner struct {
+	textScanner
+	options []string
+}
+
+func (o *optionScanner) SupportedOptions() []string {
+	return o.options
+}
+
+func TestOptionsTestSuite(t *testing.T) {
+	suite.Run(t, new(OptionsTestSuite))
+}
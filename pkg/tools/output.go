@@ -0,0 +1,83 @@
+package tools
+
+import (
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Stored content types.
+const (
+	ContentAudio        = "audio"
+	ContentImage        = "image"
+	ContentResource     = "resource"
+	ContentResourceLink = "resource_link"
+	ContentText         = "text"
+	ContentUnknown      = "unknown"
+)
+
+// StoredOutput is the normalized result of a tool call stored as the output of its execution.
+// Text is kept, while the data of other content, such as images and compressed report resources,
+// is replaced by its type, MIME type, URI and size: the reports compressed into resources are
+// stored as the raw output of the execution. Field names match CallToolResult, so that stored
+// outputs read the same as before.
+type StoredOutput struct {
+	Content           []StoredContent `json:"content"`
+	IsError           bool            `json:"isError,omitempty"`
+	Meta              mcp.Meta        `json:"_meta,omitempty"`
+	StructuredContent any             `json:"structuredContent,omitempty"`
+}
+
+// StoredContent is a content item of a StoredOutput.
+type StoredContent struct {
+	MIMEType string `json:"mimeType,omitempty"`
+	// Size is the size in bytes of the data left out, for content other than text.
+	Size int    `json:"size,omitempty"`
+	Text string `json:"text,omitempty"`
+	Type string `json:"type"`
+	URI  string `json:"uri,omitempty"`
+}
+
+// StoreResult returns the stored output of result and the size in bytes of the content
+// returned to the client: the text and the decoded data of other content.
+func StoreResult(result *mcp.CallToolResult) (StoredOutput, int) {
+	output := StoredOutput{
+		Content:           make([]StoredContent, 0, len(result.Content)),
+		IsError:           result.IsError,
+		Meta:              result.Meta,
+		StructuredContent: result.StructuredContent,
+	}
+
+	size := 0
+	for _, content := range result.Content {
+		stored := normalizeContent(content)
+		size += len(stored.Text) + stored.Size
+		output.Content = append(output.Content, stored)
+	}
+
+	return output, size
+}
+
+// normalizeContent returns the stored form of a content item.
+func normalizeContent(content mcp.Content) StoredContent {
+	switch typed := content.(type) {
+	case *mcp.TextContent:
+		return StoredContent{Text: typed.Text, Type: ContentText}
+	case *mcp.ImageContent:
+		return StoredContent{MIMEType: typed.MIMEType, Size: len(typed.Data), Type: ContentImage}
+	case *mcp.AudioContent:
+		return StoredContent{MIMEType: typed.MIMEType, Size: len(typed.Data), Type: ContentAudio}
+	case *mcp.ResourceLink:
+		return StoredContent{MIMEType: typed.MIMEType, Type: ContentResourceLink, URI: typed.URI}
+	case *mcp.EmbeddedResource:
+		if typed.Resource == nil {
+			return StoredContent{Type: ContentResource}
+		}
+		return StoredContent{
+			MIMEType: typed.Resource.MIMEType,
+			Size:     len(typed.Resource.Blob) + len(typed.Resource.Text),
+			Type:     ContentResource,
+			URI:      typed.Resource.URI,
+		}
+	default:
+		return StoredContent{Type: ContentUnknown}
+	}
+}
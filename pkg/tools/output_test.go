@@ -0,0 +1,48 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/suite"
+)
+
+type OutputTestSuite struct {
+	suite.Suite
+}
+
+func (s *OutputTestSuite) TestStoreResult() {
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: "scan completed"},
+			&mcp.ImageContent{Data: make([]byte, 100), MIMEType: "image/png"},
+			&mcp.EmbeddedResource{Resource: &mcp.ResourceContents{
+				Blob:     make([]byte, 1000),
+				MIMEType: "application/gzip",
+				URI:      "wass://reports/nuclei.txt.gz",
+			}},
+			&mcp.ResourceLink{MIMEType: "text/plain", Name: "report", URI: "file:///tmp/report.txt"},
+		},
+		IsError: true,
+	}
+
+	stored, size := StoreResult(result)
+	s.Equal(len("scan completed")+100+1000, size)
+	s.True(stored.IsError)
+	s.Equal([]StoredContent{
+		{Text: "scan completed", Type: ContentText},
+		{MIMEType: "image/png", Size: 100, Type: ContentImage},
+		{MIMEType: "application/gzip", Size: 1000, Type: ContentResource, URI: "wass://reports/nuclei.txt.gz"},
+		{MIMEType: "text/plain", Type: ContentResourceLink, URI: "file:///tmp/report.txt"},
+	}, stored.Content)
+}
+
+func (s *OutputTestSuite) TestStoreResult_Empty() {
+	stored, size := StoreResult(&mcp.CallToolResult{Content: []mcp.Content{&mcp.EmbeddedResource{}}})
+	s.Zero(size)
+	s.Equal([]StoredContent{{Type: ContentResource}}, stored.Content)
+}
+
+func TestOutputTestSuite(t *testing.T) {
+	suite.Run(t, new(OutputTestSuite))
+}
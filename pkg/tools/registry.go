@@ -0,0 +1,126 @@
+package tools
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var (
+	// ErrDuplicateScanner is returned when registering a scanner under a name already taken.
+	ErrDuplicateScanner = errors.New("scanner already registered")
+	// ErrUnknownScanner is returned when looking up a scanner that is not registered.
+	ErrUnknownScanner = errors.New("unknown scanner")
+)
+
+// Registry owns the scanners of the server: their registration, their availability and their
+// lookup by name. Availability is checked when a scanner is registered and again by Refresh, so
+// that binaries installed or repaired after startup become usable without a restart. Scanners are
+// listed in registration order. It is safe for concurrent use.
+type Registry struct {
+	mu        sync.RWMutex
+	available map[string]bool
+	byName    map[string]Scanner
+	scanners  []Scanner
+}
+
+// NewRegistry creates a registry of scanners. It panics when two scanners share a name, a
+// programming error like registering a pattern twice with http.ServeMux.
+func NewRegistry(scanners ...Scanner) *Registry {
+	registry := &Registry{available: make(map[string]bool), byName: make(map[string]Scanner)}
+	for _, scanner := range scanners {
+		if err := registry.Register(scanner); err != nil {
+			panic(err)
+		}
+	}
+
+	return registry
+}
+
+// Register adds scanner to the registry and checks its availability.
+func (r *Registry) Register(scanner Scanner) error {
+	available := scanner.IsAvailable()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.byName[scanner.Name()]; ok {
+		return fmt.Errorf("%w: %s", ErrDuplicateScanner, scanner.Name())
+	}
+	r.byName[scanner.Name()] = scanner
+	r.scanners = append(r.scanners, scanner)
+	r.available[scanner.Name()] = available
+
+	return nil
+}
+
+// Lookup returns the scanner registered under name.
+func (r *Registry) Lookup(name string) (Scanner, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	scanner, ok := r.byName[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownScanner, name)
+	}
+
+	return scanner, nil
+}
+
+// Scanners returns every registered scanner, available or not.
+func (r *Registry) Scanners() []Scanner {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append([]Scanner(nil), r.scanners...)
+}
+
+// Available returns the scanners found available by their last check.
+func (r *Registry) Available() []Scanner {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	available := make([]Scanner, 0, len(r.scanners))
+	for _, scanner := range r.scanners {
+		if r.available[scanner.Name()] {
+			available = append(available, scanner)
+		}
+	}
+
+	return available
+}
+
+// IsAvailable reports whether the named scanner was found available by its last check.
+func (r *Registry) IsAvailable(name string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.available[name]
+}
+
+// AvailableNames returns the names of the scanners found available by their last check.
+func (r *Registry) AvailableNames() []string {
+	available := r.Available()
+	names := make([]string, 0, len(available))
+	for _, scanner := range available {
+		names = append(names, scanner.Name())
+	}
+
+	return names
+}
+
+// Refresh checks the availability of every scanner again and returns the available ones. The
+// checks run without holding the registry, as they may look up binaries and read diagnostics.
+func (r *Registry) Refresh() []Scanner {
+	scanners := r.Scanners()
+	found := make(map[string]bool, len(scanners))
+	for _, scanner := range scanners {
+		found[scanner.Name()] = scanner.IsAvailable()
+	}
+
+	r.mu.Lock()
+	for name, available := range found {
+		r.available[name] = available
+	}
+	r.mu.Unlock()
+
+	return r.Available()
+}
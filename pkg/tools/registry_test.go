@@ -0,0 +1,106 @@
+package tools
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// switchScanner is a scanner whose availability can be switched.
+type switchScanner struct {
+	textScanner
+	available atomic.Bool
+}
+
+func newSwitchScanner(name string, available bool) *switchScanner {
+	scanner := &switchScanner{textScanner: textScanner{name: name}}
+	scanner.available.Store(available)
+
+	return scanner
+}
+
+func (s *switchScanner) IsAvailable() bool { return s.available.Load() }
+
+type RegistryTestSuite struct {
+	suite.Suite
+}
+
+func (s *RegistryTestSuite) TestRegister() {
+	registry := NewRegistry(newSwitchScanner("nikto", true))
+	s.Require().NoError(registry.Register(newSwitchScanner("nuclei", false)))
+
+	err := registry.Register(newSwitchScanner("nikto", true))
+	s.ErrorIs(err, ErrDuplicateScanner)
+	s.ErrorContains(err, "nikto")
+
+	names := make([]string, 0, 2)
+	for _, scanner := range registry.Scanners() {
+		names = append(names, scanner.Name())
+	}
+	s.Equal([]string{"nikto", "nuclei"}, names, "scanners are listed in registration order")
+	s.Equal([]string{"nikto"}, registry.AvailableNames())
+}
+
+func (s *RegistryTestSuite) TestNewRegistry_Duplicate() {
+	s.Panics(func() { NewRegistry(newSwitchScanner("nikto", true), newSwitchScanner("nikto", false)) })
+}
+
+func (s *RegistryTestSuite) TestLookup() {
+	nikto := newSwitchScanner("nikto", false)
+	registry := NewRegistry(nikto)
+
+	scanner, err := registry.Lookup("nikto")
+	s.Require().NoError(err)
+	s.Same(nikto, scanner, "unavailable scanners are looked up too")
+
+	_, err = registry.Lookup("zap")
+	s.ErrorIs(err, ErrUnknownScanner)
+	s.ErrorContains(err, "zap")
+}
+
+func (s *RegistryTestSuite) TestRefresh() {
+	nikto := newSwitchScanner("nikto", false)
+	registry := NewRegistry(nikto)
+	s.False(registry.IsAvailable("nikto"))
+	s.Empty(registry.Available())
+
+	nikto.available.Store(true)
+	s.False(registry.IsAvailable("nikto"), "availability is kept until refreshed")
+	s.Len(registry.Refresh(), 1)
+	s.True(registry.IsAvailable("nikto"))
+
+	nikto.available.Store(false)
+	s.Empty(registry.Refresh())
+	s.False(registry.IsAvailable("unknown"))
+}
+
+func (s *RegistryTestSuite) TestConcurrentAccess() {
+	registry := NewRegistry()
+	nikto := newSwitchScanner("nikto", true)
+	s.Require().NoError(registry.Register(nikto))
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				nikto.available.Store(i%2 == 0)
+				registry.Refresh()
+				_, _ = registry.Lookup("nikto")
+				registry.Available()
+				registry.AvailableNames()
+			}
+		}()
+	}
+	s.Require().NoError(registry.Register(newSwitchScanner("nuclei", true)))
+	wg.Wait()
+
+	s.Len(registry.Scanners(), 2)
+}
+
+func TestRegistryTestSuite(t *testing.T) {
+	suite.Run(t, new(RegistryTestSuite))
+}
@@ -0,0 +1,245 @@
+package tools
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/tb0hdan/wass-mcp/pkg/types"
+)
+
+// NextCursorField is the result metadata key holding the cursor of the next page when the
+// response byte budget cut the output short.
+const NextCursorField = "next_cursor"
+
+// ErrInvalidCursor is returned for malformed continuation cursors.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor is a position in a tool output: a line and a byte offset within that line. It lets
+// clients resume a page cut in the middle of a long line.
+type Cursor struct {
+	Byte int
+	Line int
+}
+
+// ParseCursor parses a cursor in the "line:byte" form returned by String. An empty value is the
+// start of the output.
+func ParseCursor(value string) (Cursor, error) {
+	if value == "" {
+		return Cursor{}, nil
+	}
+
+	lineValue, byteValue, ok := strings.Cut(value, ":")
+	line, lineErr := strconv.Atoi(lineValue)
+	offset, byteErr := strconv.Atoi(byteValue)
+	if !ok || lineErr != nil || byteErr != nil || line < 0 || offset < 0 {
+		return Cursor{}, fmt.Errorf("%w: %q", ErrInvalidCursor, value)
+	}
+
+	return Cursor{Byte: offset, Line: line}, nil
+}
+
+// String returns the cursor in the "line:byte" form accepted by ParseCursor.
+func (c Cursor) String() string {
+	return strconv.Itoa(c.Line) + ":" + strconv.Itoa(c.Byte)
+}
+
+// StartCursor returns the cursor a page starts at: cursor when set, otherwise the line offset.
+func StartCursor(cursor string, offset int) (Cursor, error) {
+	if cursor == "" {
+		return Cursor{Line: offset}, nil
+	}
+
+	return ParseCursor(cursor)
+}
+
+// ResponsePage is a page of output limited by lines and then by bytes.
+type ResponsePage struct {
+	PaginationResult
+	// Next is where the following page starts when the byte budget cut the page short.
+	Next *Cursor
+	// Text is the page content.
+	Text string
+}
+
+// PaginateResponse applies line pagination from start and then limits the page to maxBytes,
+// 0 meaning unlimited. Whole lines are kept while they fit; a first line larger than the budget is
+// cut at a UTF-8 boundary. Next is set whenever the budget dropped part of the line page.
+func PaginateResponse(output string, maxLines int, start Cursor, maxBytes int) ResponsePage {
+	return limitPage(ApplyPagination(output, maxLines, start.Line), start, maxBytes)
+}
+
+// limitPage returns the line page pagination, read from start, limited to maxBytes as described
+// by PaginateResponse.
+func limitPage(pagination PaginationResult, start Cursor, maxBytes int) ResponsePage {
+	lines := pagination.Lines
+	if len(lines) > 0 && start.Byte > 0 && pagination.StartLine == start.Line {
+		lines[0] = lines[0][min(start.Byte, len(lines[0])):]
+	}
+
+	page := ResponsePage{PaginationResult: pagination}
+	if maxBytes <= 0 {
+		page.Text = strings.Join(lines, "\n")
+		return page
+	}
+
+	var builder strings.Builder
+	for i, line := range lines {
+		separator := 0
+		if i > 0 {
+			separator = 1
+		}
+		if builder.Len()+separator+len(line) <= maxBytes {
+			if i > 0 {
+				builder.WriteString("\n")
+			}
+			builder.WriteString(line)
+			continue
+		}
+
+		next := Cursor{Line: pagination.StartLine + i}
+		if i == 0 {
+			cut := maxBytes
+			for cut > 0 && !utf8.RuneStart(line[cut]) {
+				cut--
+			}
+			if cut == 0 {
+				// A single rune larger than the budget still has to make progress.
+				_, cut = utf8.DecodeRuneInString(line)
+			}
+			builder.WriteString(line[:cut])
+			next.Byte = cut
+			if next.Line == start.Line {
+				next.Byte += start.Byte
+			}
+		}
+		page.Next = &next
+		page.EndLine = next.Line
+		if next.Byte > 0 {
+			page.EndLine++
+		}
+		break
+	}
+	page.Text = builder.String()
+
+	return page
+}
+
+// PageWriter paginates the output written to it like PaginateResponse, keeping only the lines
+// the page can be made of, so that an output streamed to it is never held whole. Writes never
+// fail.
+type PageWriter struct {
+	// current is the line being written, when it can be part of the page.
+	current []byte
+	// first are the first lines, the page when start is past the end of the output.
+	first []string
+	// line is the index of the line being written.
+	line int
+	// lines are the lines from start.
+	lines    []string
+	maxBytes int
+	maxLines int
+	start    Cursor
+}
+
+// NewPageWriter returns a PageWriter for the page of maxLines lines read from start, limited to
+// maxBytes.
+func NewPageWriter(maxLines int, start Cursor, maxBytes int) *PageWriter {
+	if maxLines <= 0 {
+		maxLines = types.MaxDefaultLines
+	}
+
+	return &PageWriter{maxBytes: maxBytes, maxLines: maxLines, start: start}
+}
+
+// Write adds data to the output.
+func (p *PageWriter) Write(data []byte) (int, error) {
+	return p.WriteString(string(data))
+}
+
+// WriteString adds data to the output without copying it, as writers of huge outputs through
+// io.WriteString do.
+func (p *PageWriter) WriteString(data string) (int, error) {
+	written := len(data)
+	for {
+		end := strings.IndexByte(data, '\n')
+		if end < 0 {
+			if p.inFirst(p.line) || p.inPage(p.line) {
+				p.current = append(p.current, data...)
+			}
+			return written, nil
+		}
+		if p.inFirst(p.line) || p.inPage(p.line) {
+			p.current = append(p.current, data[:end]...)
+		}
+		p.first, p.lines = p.appendLine(p.first, p.lines)
+		p.current = p.current[:0]
+		p.line++
+		data = data[end+1:]
+	}
+}
+
+// inFirst reports whether line is one of the first lines of the output.
+func (p *PageWriter) inFirst(line int) bool {
+	return line < p.maxLines
+}
+
+// inPage reports whether line is one of the lines of the page read from start.
+func (p *PageWriter) inPage(line int) bool {
+	return line >= p.start.Line && line-p.start.Line < p.maxLines
+}
+
+// appendLine returns first and lines with the line being written appended to those it belongs to.
+func (p *PageWriter) appendLine(first, lines []string) ([]string, []string) {
+	if !p.inFirst(p.line) && !p.inPage(p.line) {
+		return first, lines
+	}
+	text := string(p.current)
+	if p.inFirst(p.line) {
+		first = append(first, text)
+	}
+	if p.inPage(p.line) {
+		lines = append(lines, text)
+	}
+
+	return first, lines
+}
+
+// Page returns the page of the output written so far, as PaginateResponse returns it.
+func (p *PageWriter) Page() ResponsePage {
+	// The line being written is the last line of the output, even when empty.
+	first, lines := p.appendLine(slices.Clip(p.first), slices.Clip(p.lines))
+	totalLines := p.line + 1
+
+	pagination := PaginationResult{Lines: first, TotalLines: totalLines}
+	if p.start.Line > 0 && p.start.Line < totalLines {
+		pagination.Lines = lines
+		pagination.StartLine = p.start.Line
+	}
+	pagination.EndLine = pagination.StartLine + len(pagination.Lines)
+	pagination.Truncated = pagination.EndLine < totalLines
+
+	return limitPage(pagination, p.start, p.maxBytes)
+}
+
+// PageNotice describes a partial page for the client, or returns an empty string for a
+// complete output.
+func PageNotice(page ResponsePage, start Cursor, maxBytes int) string {
+	if page.Next != nil {
+		return fmt.Sprintf("[Response limited to %d bytes, showing from line %d. Use cursor %q to view more.]\n",
+			maxBytes, page.StartLine+1, page.Next.String())
+	}
+	if page.Truncated || start.Line > 0 || start.Byte > 0 {
+		from := ""
+		if start.Byte > 0 {
+			from = fmt.Sprintf(", from byte %d of line %d", start.Byte, start.Line+1)
+		}
+		return fmt.Sprintf("[Showing lines %d-%d of %d lines%s. Use offset parameter to view more.]\n",
+			page.StartLine+1, page.EndLine, page.TotalLines, from)
+	}
+
+	return ""
+}
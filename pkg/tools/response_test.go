@@ -0,0 +1,223 @@
+package tools
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+)
+
+type ResponseTestSuite struct {
+	suite.Suite
+}
+
+func (s *ResponseTestSuite) TestParseCursor() {
+	cursor, err := ParseCursor("")
+	s.Require().NoError(err)
+	s.Equal(Cursor{}, cursor)
+
+	cursor, err = ParseCursor("12:4096")
+	s.Require().NoError(err)
+	s.Equal(Cursor{Byte: 4096, Line: 12}, cursor)
+	s.Equal("12:4096", cursor.String())
+
+	for _, value := range []string{"12", "a:1", "1:b", "-1:0", "1:-1", "1:2:3"} {
+		_, err := ParseCursor(value)
+		s.ErrorIs(err, ErrInvalidCursor, value)
+	}
+}
+
+func (s *ResponseTestSuite) TestStartCursor() {
+	cursor, err := StartCursor("", 7)
+	s.Require().NoError(err)
+	s.Equal(Cursor{Line: 7}, cursor)
+
+	cursor, err = StartCursor("3:10", 7)
+	s.Require().NoError(err)
+	s.Equal(Cursor{Byte: 10, Line: 3}, cursor)
+}
+
+func (s *ResponseTestSuite) TestPaginateResponse_Unlimited() {
+	page := PaginateResponse("a\nb\nc", 0, Cursor{}, 0)
+	s.Equal("a\nb\nc", page.Text)
+	s.Nil(page.Next)
+	s.Empty(PageNotice(page, Cursor{}, 0))
+}
+
+func (s *ResponseTestSuite) TestPaginateResponse_WholeLines() {
+	page := PaginateResponse("aaaa\nbbbb\ncccc", 0, Cursor{}, 10)
+	s.Equal("aaaa\nbbbb", page.Text)
+	s.Equal(&Cursor{Line: 2}, page.Next)
+	s.Equal(2, page.EndLine)
+	s.Contains(PageNotice(page, Cursor{}, 10), `Use cursor "2:0" to view more.`)
+}
+
+func (s *ResponseTestSuite) TestPaginateResponse_LongLine() {
+	long := strings.Repeat("x", 25)
+	output := "short\n" + long + "\nend"
+
+	page := PaginateResponse(output, 0, Cursor{Line: 1}, 10)
+	s.Equal(strings.Repeat("x", 10), page.Text)
+	s.Equal(&Cursor{Byte: 10, Line: 1}, page.Next)
+
+	page = PaginateResponse(output, 0, *page.Next, 10)
+	s.Equal(strings.Repeat("x", 10), page.Text)
+	s.Equal(&Cursor{Byte: 20, Line: 1}, page.Next)
+
+	page = PaginateResponse(output, 0, *page.Next, 10)
+	s.Equal("xxxxx\nend", page.Text)
+	s.Nil(page.Next)
+}
+
+func (s *ResponseTestSuite) TestPaginateResponse_UTF8Boundary() {
+	page := PaginateResponse("ééééé", 0, Cursor{}, 5)
+	s.Equal("éé", page.Text)
+	s.Equal(&Cursor{Byte: 4}, page.Next)
+
+	page = PaginateResponse("ééééé", 0, Cursor{}, 1)
+	s.Equal("é", page.Text)
+	s.Equal(&Cursor{Byte: 2}, page.Next)
+}
+
+func (s *ResponseTestSuite) TestPaginateResponse_LinesThenBytes() {
+	page := PaginateResponse("a\nb\nc\nd", 2, Cursor{Line: 1}, 0)
+	s.Equal("b\nc", page.Text)
+	s.Nil(page.Next)
+	s.Contains(PageNotice(page, Cursor{Line: 1}, 0), "Showing lines 2-3 of 4 lines")
+}
+
+func (s *ResponseTestSuite) TestPaginateResponse_OutOfRangeLimits() {
+	page := PaginateResponse("a\nb", -1, Cursor{}, 0)
+	s.Equal("a\nb", page.Text, "negative line limits show the default number of lines")
+
+	page = PaginateResponse("a\nb\nc", math.MaxInt, Cursor{Line: 1}, 0)
+	s.Equal("b\nc", page.Text, "huge line limits do not overflow")
+	s.False(page.Truncated)
+}
+
+func (s *ResponseTestSuite) TestPageWriter() {
+	output := "line 1\nline 2\nline 3\nline 4\n"
+	for name, start := range map[string]Cursor{
+		"first page": {},
+		"from line":  {Line: 2},
+		"from byte":  {Line: 1, Byte: 3},
+		"past end":   {Line: 10},
+	} {
+		writer := NewPageWriter(2, start, 8)
+		for _, piece := range strings.SplitAfter(output, "ne") {
+			_, err := writer.Write([]byte(piece))
+			s.Require().NoError(err)
+		}
+		s.Equal(PaginateResponse(output, 2, start, 8), writer.Page(), name)
+	}
+}
+
+func (s *ResponseTestSuite) TestHandleScan_NextCursor() {
+	bs := NewBaseScanner("test", "test", zerolog.Nop())
+	bs.maxResponseBytes = 16
+	output := `{"template-id":"one","info":{"name":"a very long nuclei result"}}`
+	scan := func(context.Context, ScanParams) ScanResult {
+		return ScanResult{Output: output}
+	}
+
+	result, _, err := bs.HandleScan(context.Background(), ScannerInput{Host: "example.com"}, "output", scan)
+	s.Require().NoError(err)
+	s.Equal("0:16", result.Meta[NextCursorField])
+	s.Contains(result.Content[0].(*mcp.TextContent).Text, "[Response limited to 16 bytes")
+
+	result, _, err = bs.HandleScan(context.Background(), ScannerInput{Host: "example.com", Cursor: "0:60"}, "output", scan)
+	s.Require().NoError(err)
+	s.NotContains(result.Meta, NextCursorField)
+	text := result.Content[0].(*mcp.TextContent).Text
+	s.Contains(text, "[Showing lines 1-1 of 1 lines, from byte 60 of line 1.")
+	s.True(strings.HasSuffix(text, "\n"+output[60:]))
+
+	_, _, err = bs.HandleScan(context.Background(), ScannerInput{Host: "example.com", Cursor: "bogus"}, "output", scan)
+	s.ErrorIs(err, ErrInvalidCursor)
+}
+
+func TestResponseTestSuite(t *testing.T) {
+	suite.Run(t, new(ResponseTestSuite))
+}
+
+// FuzzParseCursor checks that cursors parse without panicking and that parsed cursors survive a
+// round trip through their string form.
+func FuzzParseCursor(f *testing.F) {
+	for _, seed := range []string{"", "0:0", "12:345", "1:", ":1", "-1:0", "1:2:3", "+1:2", "99999999999999999999:0"} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, value string) {
+		cursor, err := ParseCursor(value)
+		if err != nil {
+			return
+		}
+		if cursor.Line < 0 || cursor.Byte < 0 {
+			t.Fatalf("ParseCursor(%q) = %+v is negative", value, cursor)
+		}
+		if again, err := ParseCursor(cursor.String()); err != nil || again != cursor {
+			t.Fatalf("ParseCursor(%q) = %+v, but its string %q parses to %+v, %v", value, cursor, cursor.String(), again, err)
+		}
+	})
+}
+
+// FuzzPaginateResponse checks that any output, binary included, pages without panicking within
+// the byte budget, and that following the next cursors from the start returns the whole output.
+func FuzzPaginateResponse(f *testing.F) {
+	f.Add("line 1\nline 2\nline 3", 2, 0, 0, 10)
+	f.Add("héllo wörld\n\n", 0, 0, 3, 4)
+	f.Add("\xff\xfe\x00\n\x80\x80", -1, 1, 1, 1)
+	f.Add("", 5, 10, 0, 0)
+
+	f.Fuzz(func(t *testing.T, output string, maxLines, line, offset, maxBytes int) {
+		if line < 0 || offset < 0 {
+			return
+		}
+		start := Cursor{Byte: offset, Line: line}
+		page := PaginateResponse(output, maxLines, start, maxBytes)
+		if maxBytes > 0 && len(page.Text) > max(maxBytes, utf8.UTFMax) {
+			t.Fatalf("page of %d bytes exceeds the budget of %d", len(page.Text), maxBytes)
+		}
+		_, _ = FormatScannerPage("nikto", "output", "http://localhost", output, maxLines, start, maxBytes)
+		writer := NewPageWriter(maxLines, start, maxBytes)
+		for chunk := range slices.Chunk([]byte(output), 3) {
+			_, _ = writer.Write(chunk)
+		}
+		if streamed := writer.Page(); !reflect.DeepEqual(streamed, page) {
+			t.Fatalf("streamed page %+v differs from %+v", streamed, page)
+		}
+		if maxBytes <= 0 {
+			return
+		}
+
+		var rebuilt strings.Builder
+		cursor := Cursor{}
+		for pages := 0; ; pages++ {
+			if pages > len(output)+1 {
+				t.Fatalf("paging %q by %d bytes does not end", output, maxBytes)
+			}
+			page := PaginateResponse(output, strings.Count(output, "\n")+1, cursor, maxBytes)
+			rebuilt.WriteString(page.Text)
+			if page.Next == nil {
+				break
+			}
+			if page.Next.Line < cursor.Line || (page.Next.Line == cursor.Line && page.Next.Byte <= cursor.Byte) {
+				t.Fatalf("cursor %+v does not advance past %+v", *page.Next, cursor)
+			}
+			if page.Next.Byte == 0 {
+				rebuilt.WriteString("\n")
+			}
+			cursor = *page.Next
+		}
+		if rebuilt.String() != output {
+			t.Fatalf("paging %q by %d bytes returned %q", output, maxBytes, rebuilt.String())
+		}
+	})
+}
@@ -0,0 +1,244 @@
+package scanjobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+	"github.com/tb0hdan/wass-mcp/pkg/artifacts"
+	"github.com/tb0hdan/wass-mcp/pkg/jobs"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/server"
+	"github.com/tb0hdan/wass-mcp/pkg/storage"
+	"github.com/tb0hdan/wass-mcp/pkg/tenant"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+)
+
+const (
+	startToolName  = "scan_start"
+	statusToolName = "scan_status"
+	resultToolName = "scan_result"
+	cancelToolName = "scan_cancel"
+)
+
+type StartInput struct {
+	Tool  string         `json:"tool" validate:"required,max=64"`
+	Input map[string]any `json:"input,omitempty"`
+}
+
+type JobInput struct {
+	JobID uint `json:"job_id" validate:"required"`
+}
+
+type StatusInput struct {
+	JobID uint `json:"job_id" validate:"required"`
+	// TailLines returns the last lines of the output of the job so far, streamed while it runs.
+	TailLines int `json:"tail_lines,omitempty" validate:"min=0,max=1000"`
+}
+
+// statusResult is a scan job with the tail of its output.
+type statusResult struct {
+	*models.ScanJob
+	OutputTail string `json:"output_tail,omitempty"`
+}
+
+type ResultInput struct {
+	JobID    uint `json:"job_id" validate:"required"`
+	MaxLines int  `json:"max_lines,omitempty" validate:"min=0,max=100000"`
+}
+
+type Tool struct {
+	logger  zerolog.Logger
+	manager *jobs.Manager
+	// maxResponseBytes bounds the output returned per call, set on registration.
+	maxResponseBytes int
+	store            storage.Storage
+	validator        *validator.Validate
+}
+
+func (t *Tool) Register(srv *server.Server) error {
+	t.maxResponseBytes = srv.MaxResponseBytes()
+	t.store = srv.Storage()
+
+	mcp.AddTool(&srv.Server, &mcp.Tool{
+		Name: startToolName,
+		Description: "Starts a scanner or full_scan in the background and returns its job at once, for scans " +
+			"outlasting the tool call timeout of the client. tool is the tool name (e.g. nikto, full_scan) and " +
+			"input its usual arguments. Poll the job with scan_status and collect its output with scan_result.",
+	}, tenant.RequireOperator(tools.ScanAction, t.StartHandler))
+	mcp.AddTool(&srv.Server, &mcp.Tool{
+		Name: statusToolName,
+		Description: "Returns a background scan job by job_id: its status (queued, running, completed, failed, " +
+			"canceled or interrupted by a restart), timestamps, error and the execution that ran it. With tail_lines, " +
+			"also the last lines of the scanner output so far, streamed to storage while the job runs.",
+	}, t.StatusHandler)
+	mcp.AddTool(&srv.Server, &mcp.Tool{
+		Name: resultToolName,
+		Description: "Returns the output of a finished background scan job by job_id, paginated by max_lines " +
+			"like the scan itself, with a continue_output cursor when the output is truncated.",
+	}, t.ResultHandler)
+	mcp.AddTool(&srv.Server, &mcp.Tool{
+		Name:        cancelToolName,
+		Description: "Cancels a queued or running background scan job by job_id.",
+	}, tenant.RequireOperator("cancel scans", t.CancelHandler))
+	t.logger.Debug().Msgf("%s, %s, %s and %s tools registered", startToolName, statusToolName, resultToolName, cancelToolName)
+
+	return nil
+}
+
+func (t *Tool) StartHandler(ctx context.Context, _ *mcp.CallToolRequest, input StartInput) (*mcp.CallToolResult, any, error) {
+	if err := t.validator.Struct(input); err != nil {
+		return nil, nil, fmt.Errorf("validation error: %w", err)
+	}
+
+	job, err := t.manager.Start(ctx, input.Tool, input.Input)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return jobResult(job), nil, nil
+}
+
+func (t *Tool) StatusHandler(ctx context.Context, _ *mcp.CallToolRequest, input StatusInput) (*mcp.CallToolResult, any, error) {
+	if err := t.validator.Struct(input); err != nil {
+		return nil, nil, fmt.Errorf("validation error: %w", err)
+	}
+
+	job, err := t.manager.Get(ctx, input.JobID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if input.TailLines == 0 {
+		return jobResult(job), nil, nil
+	}
+
+	tail, err := t.outputTail(ctx, job, input.TailLines)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return jobResult(statusResult{ScanJob: job, OutputTail: tail}), nil, nil
+}
+
+// outputTail returns the last lines of the output of job: from the output stream of its execution
+// while it runs or once it was interrupted, else from its stored output. It is empty before the
+// job started.
+func (t *Tool) outputTail(ctx context.Context, job *models.ScanJob, lines int) (string, error) {
+	var exec *models.ToolExecution
+	if job.ExecutionID != 0 {
+		found, err := t.store.GetToolExecution(ctx, job.ExecutionID)
+		if err != nil {
+			return "", fmt.Errorf("execution %d not found: %w", job.ExecutionID, err)
+		}
+		exec = found
+	} else if job.CorrelationID != "" {
+		executions, _, err := t.store.QueryToolExecutions(ctx, storage.ExecutionFilter{CorrelationID: job.CorrelationID, Limit: 1})
+		if err != nil {
+			return "", fmt.Errorf("failed to find the execution of scan job %d: %w", job.ID, err)
+		}
+		if len(executions) > 0 {
+			exec = &executions[0]
+		}
+	}
+
+	switch {
+	case exec == nil:
+		return "", nil
+	case exec.StreamFile != "":
+		return artifacts.TailStream(exec.StreamFile, lines)
+	default:
+		return artifacts.TailLines(exec.RawOutput, lines), nil
+	}
+}
+
+func (t *Tool) CancelHandler(ctx context.Context, _ *mcp.CallToolRequest, input JobInput) (*mcp.CallToolResult, any, error) {
+	if err := t.validator.Struct(input); err != nil {
+		return nil, nil, fmt.Errorf("validation error: %w", err)
+	}
+
+	job, err := t.manager.Cancel(ctx, input.JobID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: fmt.Sprintf("Cancellation of scan job %d requested", job.ID)},
+		},
+	}, nil, nil
+}
+
+func (t *Tool) ResultHandler(ctx context.Context, _ *mcp.CallToolRequest, input ResultInput) (*mcp.CallToolResult, any, error) {
+	if err := t.validator.Struct(input); err != nil {
+		return nil, nil, fmt.Errorf("validation error: %w", err)
+	}
+
+	job, err := t.manager.Get(ctx, input.JobID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !job.Finished() {
+		return nil, nil, fmt.Errorf("scan job %d is %s, poll scan_status until it finished", job.ID, job.Status)
+	}
+
+	var exec *models.ToolExecution
+	if job.ExecutionID != 0 {
+		exec, err = t.store.GetToolExecution(ctx, job.ExecutionID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("execution %d not found: %w", job.ExecutionID, err)
+		}
+		if exec.Status == models.StatusRunning {
+			return nil, nil, fmt.Errorf("output of execution %d is still being stored, retry shortly", exec.ID)
+		}
+	}
+	if exec == nil || exec.RawOutput == "" {
+		if job.Error != "" {
+			return nil, nil, fmt.Errorf("scan job %d %s: %s", job.ID, job.Status, job.Error)
+		}
+		return nil, nil, fmt.Errorf("scan job %d %s without output", job.ID, job.Status)
+	}
+
+	page := tools.PaginateResponse(exec.RawOutput, input.MaxLines, tools.Cursor{}, t.maxResponseBytes)
+	resultText := fmt.Sprintf("Scan job %d %s, %s output of execution %d, lines %d-%d of %d:\n\n%s",
+		job.ID, job.Status, exec.ToolName, exec.ID, page.StartLine+1, page.EndLine, page.TotalLines,
+		strings.TrimSpace(page.Text))
+
+	token, err := tools.IssueContinuation(ctx, t.store, exec.ID, page, input.MaxLines)
+	if err != nil {
+		return nil, nil, err
+	}
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: resultText},
+		},
+	}
+	if token != "" {
+		result.Content[0].(*mcp.TextContent).Text += tools.ContinuationNotice(token)
+		result.Meta = mcp.Meta{tools.OutputCursorField: token}
+	}
+
+	return result, nil, nil
+}
+
+// jobResult returns job as indented JSON.
+func jobResult(job any) *mcp.CallToolResult {
+	data, _ := json.MarshalIndent(job, "", "  ")
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: string(data)},
+		},
+	}
+}
+
+func New(logger zerolog.Logger, manager *jobs.Manager) tools.Tool {
+	return &Tool{
+		logger:    logger.With().Str("tool", "scan_jobs").Logger(),
+		manager:   manager,
+		validator: validator.New(),
+	}
+}
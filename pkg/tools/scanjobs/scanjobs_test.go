@@ -0,0 +1,193 @@
+package scanjobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/jobs"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/server"
+	"github.com/tb0hdan/wass-mcp/pkg/storage"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+)
+
+type ScanJobsTestSuite struct {
+	suite.Suite
+	manager *jobs.Manager
+	srv     *server.Server
+	// stream is the output stream file of the running zap job.
+	stream string
+	tool   *Tool
+}
+
+func (s *ScanJobsTestSuite) SetupTest() {
+	tmpFile, err := os.CreateTemp("", "scanjobs-test-*.db")
+	s.Require().NoError(err)
+	s.Require().NoError(tmpFile.Close())
+	s.T().Cleanup(func() { _ = os.Remove(tmpFile.Name()) })
+
+	store, err := storage.NewSQLiteStorage(storage.Config{DatabasePath: tmpFile.Name()})
+	s.Require().NoError(err)
+	s.T().Cleanup(func() { _ = store.Close() })
+
+	lines := make([]string, 25)
+	for i := range lines {
+		lines[i] = "+ finding " + strings.Repeat("x", i)
+	}
+	s.srv = server.NewServer(&mcp.Implementation{Name: "test"}, store)
+	s.srv.RegisterRerun("nikto", func(ctx context.Context, _ string, _ int) error {
+		return store.CreateToolExecution(ctx, &models.ToolExecution{
+			CorrelationID: tools.CorrelationID(ctx),
+			ToolName:      "nikto",
+			Status:        models.StatusCompleted,
+			Success:       true,
+			RawOutput:     strings.Join(lines, "\n"),
+		})
+	})
+	s.srv.RegisterRerun("nuclei", func(context.Context, string, int) error {
+		return errors.New("nuclei exited with status 2")
+	})
+	s.srv.RegisterRerun("wapiti", func(ctx context.Context, _ string, _ int) error {
+		<-ctx.Done()
+		return context.Cause(ctx)
+	})
+	s.stream = filepath.Join(s.T().TempDir(), "zap.log")
+	s.Require().NoError(os.WriteFile(s.stream, []byte("[zap] PASS: Cookie No HttpOnly Flag\n[zap] WARN-NEW: CSP Header Not Set\n"), 0o600))
+	s.srv.RegisterRerun("zap", func(ctx context.Context, _ string, _ int) error {
+		err := store.CreateToolExecution(ctx, &models.ToolExecution{
+			CorrelationID: tools.CorrelationID(ctx),
+			ToolName:      "zap",
+			Status:        models.StatusRunning,
+			StreamFile:    s.stream,
+		})
+		if err != nil {
+			return err
+		}
+		<-ctx.Done()
+		return context.Cause(ctx)
+	})
+
+	s.manager = jobs.New(s.srv, 0, zerolog.Nop())
+	ctx, cancel := context.WithCancel(context.Background())
+	go s.manager.Work(ctx)
+	s.T().Cleanup(cancel)
+	s.tool = New(zerolog.Nop(), s.manager).(*Tool)
+	s.Require().NoError(s.tool.Register(s.srv))
+}
+
+// start starts a job running toolName and returns its ID.
+func (s *ScanJobsTestSuite) start(toolName string) uint {
+	result, _, err := s.tool.StartHandler(context.Background(), nil, StartInput{Tool: toolName, Input: map[string]any{"host": "example.com"}})
+	s.Require().NoError(err)
+	text := result.Content[0].(*mcp.TextContent).Text
+	s.Contains(text, `"status": "queued"`)
+
+	var job models.ScanJob
+	s.Require().NoError(json.Unmarshal([]byte(text), &job))
+	s.Require().NotZero(job.ID)
+
+	return job.ID
+}
+
+func (s *ScanJobsTestSuite) TestRegister() {
+	list, err := s.srv.Tools(context.Background())
+	s.Require().NoError(err)
+	var names []string
+	for _, tool := range list {
+		names = append(names, tool.Name)
+	}
+	s.ElementsMatch([]string{"scan_start", "scan_status", "scan_result", "scan_cancel"}, names)
+}
+
+func (s *ScanJobsTestSuite) TestStartStatusResult() {
+	id := s.start("nikto")
+	s.manager.Wait()
+
+	result, _, err := s.tool.StatusHandler(context.Background(), nil, StatusInput{JobID: id})
+	s.Require().NoError(err)
+	s.Contains(result.Content[0].(*mcp.TextContent).Text, `"status": "completed"`)
+
+	result, _, err = s.tool.ResultHandler(context.Background(), nil, ResultInput{JobID: id, MaxLines: 10})
+	s.Require().NoError(err)
+	text := result.Content[0].(*mcp.TextContent).Text
+	s.Contains(text, "Scan job 1 completed, nikto output of execution 1, lines 1-10 of 25")
+	s.Contains(text, "+ finding xxxxxxxxx\n")
+	s.NotContains(text, "+ finding xxxxxxxxxx\n")
+	s.Contains(result.Meta, tools.OutputCursorField, "truncated outputs continue with continue_output")
+}
+
+func (s *ScanJobsTestSuite) TestStatus_TailLines() {
+	id := s.start("zap")
+	var status statusResult
+	s.Eventually(func() bool {
+		result, _, err := s.tool.StatusHandler(context.Background(), nil, StatusInput{JobID: id, TailLines: 1})
+		s.Require().NoError(err)
+		status = statusResult{}
+		s.Require().NoError(json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &status))
+		return status.OutputTail != ""
+	}, time.Second, 10*time.Millisecond)
+	s.Equal(models.JobRunning, status.Status)
+	s.Equal("[zap] WARN-NEW: CSP Header Not Set", status.OutputTail, "running jobs are tailed from their output stream")
+
+	_, _, err := s.tool.CancelHandler(context.Background(), nil, JobInput{JobID: id})
+	s.Require().NoError(err)
+	s.manager.Wait()
+
+	id = s.start("nikto")
+	s.manager.Wait()
+	result, _, err := s.tool.StatusHandler(context.Background(), nil, StatusInput{JobID: id, TailLines: 2})
+	s.Require().NoError(err)
+	text := result.Content[0].(*mcp.TextContent).Text
+	s.Contains(text, `"status": "completed"`)
+	s.Contains(text, `"output_tail": "+ finding `+strings.Repeat("x", 23)+`\n+ finding `+strings.Repeat("x", 24)+`"`,
+		"finished jobs are tailed from their stored output")
+}
+
+func (s *ScanJobsTestSuite) TestResult_Failed() {
+	id := s.start("nuclei")
+	s.manager.Wait()
+
+	_, _, err := s.tool.ResultHandler(context.Background(), nil, ResultInput{JobID: id})
+	s.ErrorContains(err, "scan job 1 failed: nuclei exited with status 2")
+}
+
+func (s *ScanJobsTestSuite) TestResult_NotFinished() {
+	id := s.start("wapiti")
+
+	_, _, err := s.tool.ResultHandler(context.Background(), nil, ResultInput{JobID: id})
+	s.ErrorContains(err, "poll scan_status")
+
+	result, _, err := s.tool.CancelHandler(context.Background(), nil, JobInput{JobID: id})
+	s.Require().NoError(err)
+	s.Contains(result.Content[0].(*mcp.TextContent).Text, "Cancellation of scan job 1 requested")
+	s.manager.Wait()
+
+	_, _, err = s.tool.ResultHandler(context.Background(), nil, ResultInput{JobID: id})
+	s.ErrorContains(err, "scan job 1 canceled: canceled by the client")
+	_, _, err = s.tool.CancelHandler(context.Background(), nil, JobInput{JobID: id})
+	s.ErrorIs(err, jobs.ErrFinished)
+}
+
+func (s *ScanJobsTestSuite) TestValidation() {
+	_, _, err := s.tool.StartHandler(context.Background(), nil, StartInput{})
+	s.ErrorContains(err, "validation error")
+	_, _, err = s.tool.StartHandler(context.Background(), nil, StartInput{Tool: "history"})
+	s.ErrorIs(err, jobs.ErrUnknownTool)
+	_, _, err = s.tool.StatusHandler(context.Background(), nil, StatusInput{JobID: 42})
+	s.ErrorIs(err, jobs.ErrNotFound)
+	_, _, err = s.tool.StatusHandler(context.Background(), nil, StatusInput{JobID: 1, TailLines: 1001})
+	s.ErrorContains(err, "validation error")
+}
+
+func TestScanJobsTestSuite(t *testing.T) {
+	suite.Run(t, new(ScanJobsTestSuite))
+}
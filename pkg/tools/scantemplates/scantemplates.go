@@ -0,0 +1,236 @@
+package scantemplates
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/server"
+	"github.com/tb0hdan/wass-mcp/pkg/storage"
+	"github.com/tb0hdan/wass-mcp/pkg/tenant"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+	"github.com/tb0hdan/wass-mcp/pkg/tools/fullscan"
+	"gorm.io/gorm"
+)
+
+const toolName = "scan_templates"
+
+// ErrNoTarget is returned when a template names neither or both of a host and a target group.
+var ErrNoTarget = errors.New("a scan template needs exactly one of host and group")
+
+// Runner runs full scans, see fullscan.Tool.Run.
+type Runner interface {
+	Run(ctx context.Context, req *mcp.CallToolRequest, input fullscan.Input) (*mcp.CallToolResult, any, error)
+}
+
+// Input manages scan templates. The profile fields, scanners, notify and report define the
+// template on set; max_lines, offset, cursor and compression page the report of run.
+type Input struct {
+	models.ScanProfile
+
+	Action      string                 `json:"action" validate:"required,oneof=list get set delete run"`
+	Compression string                 `json:"compression,omitempty" validate:"omitempty,oneof=gzip none"`
+	Cursor      string                 `json:"cursor,omitempty" validate:"omitempty,max=64"`
+	Description string                 `json:"description,omitempty" validate:"max=1024"`
+	Group       string                 `json:"group,omitempty" validate:"omitempty,max=64"`
+	Host        string                 `json:"host,omitempty"`
+	MaxLines    int                    `json:"max_lines,omitempty" validate:"min=0,max=100000"`
+	Name        string                 `json:"name,omitempty" validate:"omitempty,max=64"`
+	Notify      *models.Notification   `json:"notify,omitempty"`
+	Offset      int                    `json:"offset,omitempty" validate:"min=0"`
+	Report      *models.ReportBranding `json:"report,omitempty"`
+	Scanners    []string               `json:"scanners,omitempty" validate:"omitempty,max=16,dive,required"`
+}
+
+type Tool struct {
+	logger    zerolog.Logger
+	runner    Runner
+	store     storage.Storage
+	validator *validator.Validate
+}
+
+func (t *Tool) Register(srv *server.Server) error {
+	tool := &mcp.Tool{
+		Name: toolName,
+		Description: "Manage and run named full_scan setups. Actions: list, get (by name), set (create or replace name " +
+			"with a host or target group, scanners, scan profile such as ports, options and timeout, a webhook " +
+			"notification and report metadata), delete (by name), run (launch the full_scan of the template by name).",
+	}
+
+	t.store = srv.Storage()
+
+	mcp.AddTool(&srv.Server, tool, t.Handler)
+	t.logger.Debug().Msgf("%s tool registered", toolName)
+
+	return nil
+}
+
+func (t *Tool) Handler(ctx context.Context, req *mcp.CallToolRequest, input Input) (*mcp.CallToolResult, any, error) {
+	if err := t.validator.Struct(input); err != nil {
+		return nil, nil, fmt.Errorf("validation error: %w", err)
+	}
+	if input.Action != "list" && input.Name == "" {
+		return nil, nil, fmt.Errorf("name is required for %s action", input.Action)
+	}
+
+	var result any
+
+	switch input.Action {
+	case "list":
+		templates, err := t.store.ListScanTemplates(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to list scan templates: %w", err)
+		}
+		result = map[string]any{
+			"total":     len(templates),
+			"templates": templates,
+		}
+
+	case "get":
+		template, err := t.load(ctx, input.Name)
+		if err != nil {
+			return nil, nil, err
+		}
+		result = template
+
+	case "set":
+		if err := tenant.Authorize(ctx, "set scan templates"); err != nil {
+			return nil, nil, err
+		}
+		template := &models.ScanTemplate{
+			Description: input.Description,
+			Group:       input.Group,
+			Host:        input.Host,
+			Name:        input.Name,
+			Notify:      input.Notify,
+			Report:      input.Report,
+			Profile:     input.ScanProfile,
+			Scanners:    input.Scanners,
+		}
+		if err := t.validate(ctx, template); err != nil {
+			return nil, nil, err
+		}
+		if err := t.store.SaveScanTemplate(ctx, template); err != nil {
+			return nil, nil, fmt.Errorf("failed to save scan template %s: %w", template.Name, err)
+		}
+		t.logger.Debug().Msgf("Scan template %s saved", template.Name)
+		result = template
+
+	case "delete":
+		if err := tenant.Authorize(ctx, "delete scan templates"); err != nil {
+			return nil, nil, err
+		}
+		if err := t.store.DeleteScanTemplate(ctx, input.Name); err != nil {
+			return nil, nil, fmt.Errorf("failed to delete scan template %s: %w", input.Name, err)
+		}
+		result = map[string]any{"deleted": input.Name}
+
+	case "run":
+		if err := tenant.Authorize(ctx, tools.ScanAction); err != nil {
+			return nil, nil, err
+		}
+		template, err := t.load(ctx, input.Name)
+		if err != nil {
+			return nil, nil, err
+		}
+		scan := scanInput(template)
+		scan.Compression = input.Compression
+		scan.Cursor = input.Cursor
+		scan.MaxLines = input.MaxLines
+		scan.Offset = input.Offset
+		t.logger.Info().Msgf("Running scan template %s", template.Name)
+
+		return t.runner.Run(ctx, req, scan)
+	}
+
+	data, _ := json.MarshalIndent(result, "", "  ")
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: string(data)},
+		},
+	}, nil, nil
+}
+
+// validate checks that template names one target and describes a valid full scan.
+func (t *Tool) validate(ctx context.Context, template *models.ScanTemplate) error {
+	if (template.Host == "") == (template.Group == "") {
+		return ErrNoTarget
+	}
+
+	scan := scanInput(template)
+	scan.ScannerInput = tools.PrepareScannerInput(scan.ScannerInput)
+	if err := t.validator.Struct(scan); err != nil {
+		return fmt.Errorf("validation error: %w", err)
+	}
+	if err := tools.ValidateOptions(scan.Options); err != nil {
+		return fmt.Errorf("validation error: %w", err)
+	}
+	if template.Group != "" {
+		_, err := t.store.GetTargetGroup(ctx, template.Group)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("validation error: target group %s not found", template.Group)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to load target group %s: %w", template.Group, err)
+		}
+	}
+
+	return nil
+}
+
+// load returns the template named name.
+func (t *Tool) load(ctx context.Context, name string) (*models.ScanTemplate, error) {
+	template, err := t.store.GetScanTemplate(ctx, name)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("scan template %s not found", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load scan template %s: %w", name, err)
+	}
+
+	return template, nil
+}
+
+// scanInput returns the full_scan input of template.
+func scanInput(template *models.ScanTemplate) fullscan.Input {
+	profile := template.Profile
+
+	return fullscan.Input{
+		ScannerInput: tools.ScannerInput{
+			CABundle:           profile.CABundle,
+			FollowRedirects:    profile.FollowRedirects,
+			Host:               template.Host,
+			InsecureSkipVerify: profile.InsecureSkipVerify,
+			Options:            profile.Options,
+			Path:               profile.Path,
+			Port:               profile.Port,
+			Priority:           profile.Priority,
+			Scheme:             profile.Scheme,
+			Timeout:            profile.Timeout,
+			Vhost:              profile.Vhost,
+			Vhosts:             profile.Vhosts,
+		},
+		DiscoverPorts: profile.DiscoverPorts,
+		Group:         template.Group,
+		Notify:        template.Notify,
+		Ports:         profile.Ports,
+		Report:        template.Report,
+		Scanners:      template.Scanners,
+		Template:      template.Name,
+	}
+}
+
+// New creates a new scan_templates tool running templates with runner.
+func New(logger zerolog.Logger, runner Runner) tools.Tool {
+	return &Tool{
+		logger:    logger.With().Str("tool", toolName).Logger(),
+		runner:    runner,
+		validator: validator.New(),
+	}
+}
@@ -0,0 +1,206 @@
+package scantemplates
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/server"
+	"github.com/tb0hdan/wass-mcp/pkg/storage"
+	"github.com/tb0hdan/wass-mcp/pkg/tenant"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+	"github.com/tb0hdan/wass-mcp/pkg/tools/fullscan"
+)
+
+// fakeScanner is a scanner recording the parameters of its scans.
+type fakeScanner struct {
+	mu     sync.Mutex
+	name   string
+	params []tools.ScanParams
+}
+
+func (f *fakeScanner) Name() string                    { return f.name }
+func (f *fakeScanner) IsAvailable() bool               { return true }
+func (f *fakeScanner) Register(_ *server.Server) error { return nil }
+
+func (f *fakeScanner) Scan(_ context.Context, params tools.ScanParams) tools.ScanResult {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.params = append(f.params, params)
+
+	return tools.ScanResult{Output: f.name + " on " + params.Target().URL()}
+}
+
+type ScanTemplatesTestSuite struct {
+	suite.Suite
+	cleanup func()
+	nikto   *fakeScanner
+	nuclei  *fakeScanner
+	srv     *server.Server
+	tool    *Tool
+}
+
+func (s *ScanTemplatesTestSuite) SetupTest() {
+	tmpFile, err := os.CreateTemp("", "scantemplates-*.db")
+	s.Require().NoError(err)
+	tmpFile.Close()
+
+	store, err := storage.NewSQLiteStorage(storage.Config{DatabasePath: tmpFile.Name()})
+	s.Require().NoError(err)
+	s.srv = server.NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}, store)
+	s.cleanup = func() {
+		s.srv.Shutdown(context.Background())
+		os.Remove(tmpFile.Name())
+	}
+
+	s.nikto = &fakeScanner{name: "nikto"}
+	s.nuclei = &fakeScanner{name: "nuclei"}
+	fullScan := fullscan.New(zerolog.Nop(), tools.NewRegistry(s.nikto, s.nuclei)).(*fullscan.Tool)
+	s.Require().NoError(fullScan.Register(s.srv))
+	s.tool = New(zerolog.Nop(), fullScan).(*Tool)
+	s.Require().NoError(s.tool.Register(s.srv))
+}
+
+func (s *ScanTemplatesTestSuite) TearDownTest() {
+	s.cleanup()
+}
+
+// call runs the handler and decodes its JSON response into out.
+func (s *ScanTemplatesTestSuite) call(ctx context.Context, input Input, out any) error {
+	result, _, err := s.tool.Handler(ctx, &mcp.CallToolRequest{}, input)
+	if err != nil {
+		return err
+	}
+	s.Require().NoError(json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), out))
+
+	return nil
+}
+
+func (s *ScanTemplatesTestSuite) TestLifecycle() {
+	ctx := context.Background()
+
+	var template models.ScanTemplate
+	s.Require().NoError(s.call(ctx, Input{
+		Action:      "set",
+		Name:        "weekly",
+		Host:        "https://example.com",
+		Scanners:    []string{"nuclei"},
+		ScanProfile: models.ScanProfile{Ports: []int{443, 8443}, Timeout: 600},
+		Notify:      &models.Notification{WebhookURL: "https://hooks.example.com/scan", MinSeverity: "high"},
+	}, &template))
+	s.Equal("weekly", template.Name)
+	s.Equal([]int{443, 8443}, template.Profile.Ports)
+
+	s.Require().NoError(s.call(ctx, Input{Action: "get", Name: "weekly"}, &template))
+	s.Equal("https://example.com", template.Host)
+	s.Equal("https://hooks.example.com/scan", template.Notify.WebhookURL)
+
+	var list struct {
+		Templates []models.ScanTemplate `json:"templates"`
+		Total     int                   `json:"total"`
+	}
+	s.Require().NoError(s.call(ctx, Input{Action: "list"}, &list))
+	s.Equal(1, list.Total)
+
+	var deleted map[string]string
+	s.Require().NoError(s.call(ctx, Input{Action: "delete", Name: "weekly"}, &deleted))
+	s.ErrorContains(s.call(ctx, Input{Action: "get", Name: "weekly"}, &template), "scan template weekly not found")
+}
+
+func (s *ScanTemplatesTestSuite) TestValidation() {
+	ctx := context.Background()
+	var template models.ScanTemplate
+
+	s.ErrorContains(s.call(ctx, Input{Action: "run"}, &template), "name is required for run action")
+	s.ErrorIs(s.call(ctx, Input{Action: "set", Name: "t"}, &template), ErrNoTarget)
+	s.ErrorIs(s.call(ctx, Input{Action: "set", Name: "t", Host: "example.com", Group: "staging"}, &template), ErrNoTarget)
+	s.ErrorContains(s.call(ctx, Input{Action: "set", Name: "t", Group: "staging"}, &template),
+		"validation error: target group staging not found")
+	s.ErrorContains(s.call(ctx, Input{Action: "set", Name: "t", Host: "example.com",
+		ScanProfile: models.ScanProfile{Priority: "urgent"}}, &template), "validation error")
+	s.ErrorContains(s.call(ctx, Input{Action: "set", Name: "t", Host: "example.com",
+		Notify: &models.Notification{WebhookURL: "not a url"}}, &template), "validation error")
+	s.ErrorContains(s.call(ctx, Input{Action: "set", Name: "t", Host: "example.com",
+		Report: &models.ReportBranding{EngagementID: strings.Repeat("x", 129)}}, &template), "validation error")
+	s.ErrorContains(s.call(ctx, Input{Action: "run", Name: "missing"}, &template), "scan template missing not found")
+}
+
+func (s *ScanTemplatesTestSuite) TestRun() {
+	ctx := context.Background()
+	s.Require().NoError(s.srv.Storage().SaveTargetGroup(ctx, &models.TargetGroup{
+		Name:  "staging",
+		Hosts: []string{"a.example.com", "b.example.com"},
+	}))
+	var template models.ScanTemplate
+	s.Require().NoError(s.call(ctx, Input{
+		Action:      "set",
+		Name:        "staging-nuclei",
+		Group:       "staging",
+		Scanners:    []string{"nuclei"},
+		ScanProfile: models.ScanProfile{Options: map[string]string{"user_agent": "wass"}, Port: 8080},
+		Report:      &models.ReportBranding{EngagementID: "ENG-42", Banner: "CONFIDENTIAL"},
+	}, &template))
+
+	result, _, err := s.tool.Handler(ctx, &mcp.CallToolRequest{}, Input{Action: "run", Name: "staging-nuclei"})
+	s.Require().NoError(err)
+	text := result.Content[0].(*mcp.TextContent).Text
+	s.Contains(text, "Target group: staging")
+	s.Contains(text, "Engagement ID: ENG-42", "templates carry their report metadata")
+	s.Contains(text, "CONFIDENTIAL\n")
+	s.Contains(text, "nuclei on http://a.example.com:8080")
+	s.Contains(text, "nuclei on http://b.example.com:8080")
+	s.Empty(s.nikto.params)
+	s.Require().Len(s.nuclei.params, 2)
+	s.Equal(8080, s.nuclei.params[0].Port)
+
+	// The run is logged as a full_scan execution of the group, naming the template.
+	s.Require().Eventually(func() bool {
+		executions, err := s.srv.Storage().GetToolExecutionsByTool(ctx, "full_scan", 1)
+		return err == nil && len(executions) == 1 && executions[0].Status == models.StatusCompleted &&
+			executions[0].Target == tools.GroupTarget("staging")
+	}, 2*time.Second, 10*time.Millisecond)
+	executions, _ := s.srv.Storage().GetToolExecutionsByTool(ctx, "full_scan", 1)
+	s.Contains(executions[0].InputJSON, `"template":"staging-nuclei"`)
+}
+
+func (s *ScanTemplatesTestSuite) TestRun_NotRegistered() {
+	ctx := context.Background()
+	var template models.ScanTemplate
+	s.Require().NoError(s.call(ctx, Input{Action: "set", Name: "t", Host: "example.com"}, &template))
+
+	s.tool.runner = fullscan.New(zerolog.Nop(), tools.NewRegistry()).(*fullscan.Tool)
+	_, _, err := s.tool.Handler(ctx, &mcp.CallToolRequest{}, Input{Action: "run", Name: "t"})
+	s.ErrorIs(err, fullscan.ErrNotRegistered)
+}
+
+func (s *ScanTemplatesTestSuite) TestReadOnly() {
+	var template models.ScanTemplate
+	s.Require().NoError(s.call(context.Background(), Input{Action: "set", Name: "t", Host: "example.com"}, &template))
+
+	readOnly := tenant.WithRole(context.Background(), tenant.RoleReadOnly)
+	for _, input := range []Input{
+		{Action: "set", Name: "t", Host: "example.org"},
+		{Action: "delete", Name: "t"},
+		{Action: "run", Name: "t"},
+	} {
+		err := s.call(readOnly, input, &template)
+		var wireErr *jsonrpc.Error
+		s.Require().ErrorAs(err, &wireErr, input.Action)
+		s.EqualValues(tenant.CodeForbidden, wireErr.Code)
+	}
+	s.NoError(s.call(readOnly, Input{Action: "get", Name: "t"}, &template))
+	s.Empty(s.nikto.params)
+}
+
+func TestScanTemplatesTestSuite(t *testing.T) {
+	suite.Run(t, new(ScanTemplatesTestSuite))
+}
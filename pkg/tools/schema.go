@@ -0,0 +1,84 @@
+package tools
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+)
+
+// ErrInputSchema is returned for stored inputs that cannot be converted to the current input schema
+// of their tool.
+var ErrInputSchema = errors.New("unsupported input schema version")
+
+// InputMigration upgrades a stored input from schema version From to From+1, editing its decoded
+// JSON object in place, e.g. renaming a field or moving it into options.
+type InputMigration struct {
+	From    int
+	Upgrade func(input map[string]any) error
+}
+
+// VersionedInput is implemented by tool inputs whose JSON shape changed since their executions
+// were first stored. InputSchemaVersion is the current version, recorded with every execution, and
+// InputMigrations upgrade inputs stored with older versions one version at a time, so that re-runs
+// of old executions keep working. Inputs without it are at models.BaseSchemaVersion.
+type VersionedInput interface {
+	InputSchemaVersion() int
+	InputMigrations() []InputMigration
+}
+
+// InputSchemaVersion returns the current schema version of input, see VersionedInput.
+func InputSchemaVersion(input any) int {
+	if versioned, ok := input.(VersionedInput); ok {
+		return versioned.InputSchemaVersion()
+	}
+
+	return models.BaseSchemaVersion
+}
+
+// UpgradeInput converts inputJSON, stored with schema version version, to the current schema of
+// input, a value of the input type of the tool, applying its migrations in order. Inputs already
+// at the current version are returned unchanged. An error wrapping ErrInputSchema is returned for
+// versions newer than the current one or without a migration.
+func UpgradeInput(inputJSON string, version int, input any) (string, error) {
+	current := InputSchemaVersion(input)
+	if version == current {
+		return inputJSON, nil
+	}
+	if version > current || version < models.BaseSchemaVersion {
+		return "", fmt.Errorf("%w: stored input has version %d, the current version is %d", ErrInputSchema, version, current)
+	}
+
+	var migrations []InputMigration
+	if versioned, ok := input.(VersionedInput); ok {
+		migrations = versioned.InputMigrations()
+	}
+
+	var document map[string]any
+	if err := json.Unmarshal([]byte(inputJSON), &document); err != nil {
+		return "", fmt.Errorf("failed to decode stored input: %w", err)
+	}
+	for ; version < current; version++ {
+		index := -1
+		for i, migration := range migrations {
+			if migration.From == version {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			return "", fmt.Errorf("%w: no migration from version %d", ErrInputSchema, version)
+		}
+		if err := migrations[index].Upgrade(document); err != nil {
+			return "", fmt.Errorf("failed to upgrade stored input from version %d: %w", version, err)
+		}
+	}
+
+	upgraded, err := json.Marshal(document)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode upgraded input: %w", err)
+	}
+
+	return string(upgraded), nil
+}
@@ -0,0 +1,94 @@
+package tools
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+)
+
+// versionedInput is an input at schema version 3: version 2 renamed "hostname" to "host" and
+// version 3 moved "threads" into options.
+type versionedInput struct {
+	Host    string            `json:"host"`
+	Options map[string]string `json:"options,omitempty"`
+}
+
+func (versionedInput) InputSchemaVersion() int { return 3 }
+
+func (versionedInput) InputMigrations() []InputMigration {
+	return []InputMigration{
+		{From: 2, Upgrade: func(input map[string]any) error {
+			threads, ok := input["threads"]
+			if !ok {
+				return nil
+			}
+			count, ok := threads.(float64)
+			if !ok {
+				return errors.New("threads is not a number")
+			}
+			delete(input, "threads")
+			input["options"] = map[string]any{"threads": strconv.Itoa(int(count))}
+			return nil
+		}},
+		{From: 1, Upgrade: func(input map[string]any) error {
+			input["host"] = input["hostname"]
+			delete(input, "hostname")
+			return nil
+		}},
+	}
+}
+
+type SchemaTestSuite struct {
+	suite.Suite
+}
+
+func (s *SchemaTestSuite) TestInputSchemaVersion() {
+	s.Equal(models.BaseSchemaVersion, InputSchemaVersion(ScannerInput{}))
+	s.Equal(3, InputSchemaVersion(versionedInput{}))
+}
+
+func (s *SchemaTestSuite) TestUpgradeInput_Current() {
+	inputJSON := `{"host":"example.com"}`
+	upgraded, err := UpgradeInput(inputJSON, 3, versionedInput{})
+	s.Require().NoError(err)
+	s.Equal(inputJSON, upgraded)
+
+	upgraded, err = UpgradeInput(inputJSON, models.BaseSchemaVersion, ScannerInput{})
+	s.Require().NoError(err)
+	s.Equal(inputJSON, upgraded)
+}
+
+func (s *SchemaTestSuite) TestUpgradeInput_Migrations() {
+	upgraded, err := UpgradeInput(`{"hostname":"example.com","threads":4}`, 1, versionedInput{})
+	s.Require().NoError(err)
+
+	var input versionedInput
+	s.Require().NoError(json.Unmarshal([]byte(upgraded), &input))
+	s.Equal(versionedInput{Host: "example.com", Options: map[string]string{"threads": "4"}}, input)
+
+	upgraded, err = UpgradeInput(`{"host":"example.com"}`, 2, versionedInput{})
+	s.Require().NoError(err)
+	s.JSONEq(`{"host":"example.com"}`, upgraded)
+}
+
+func (s *SchemaTestSuite) TestUpgradeInput_Errors() {
+	_, err := UpgradeInput(`{"host":"example.com"}`, 4, versionedInput{})
+	s.ErrorIs(err, ErrInputSchema)
+
+	_, err = UpgradeInput(`{"host":"example.com"}`, 2, ScannerInput{})
+	s.ErrorIs(err, ErrInputSchema)
+
+	_, err = UpgradeInput(`{"host":"example.com","threads":"4"}`, 2, versionedInput{})
+	s.ErrorContains(err, "failed to upgrade stored input from version 2")
+
+	_, err = UpgradeInput(`not json`, 1, versionedInput{})
+	s.ErrorContains(err, "failed to decode stored input")
+}
+
+func TestSchemaTestSuite(t *testing.T) {
+	suite.Run(t, new(SchemaTestSuite))
+}
@@ -0,0 +1,49 @@
+package tools
+
+import (
+	"context"
+	"maps"
+
+	"github.com/tb0hdan/wass-mcp/pkg/session"
+	"github.com/tb0hdan/wass-mcp/pkg/tenant"
+)
+
+// ApplySessionDefaults fills an input naming no host from the defaults set with set_context by
+// the session of ctx. Fields set on the input win and its options override the default ones.
+// Inputs naming a host are returned unchanged. It reports whether defaults were applied.
+func ApplySessionDefaults(ctx context.Context, sessions *session.Store, input ScannerInput) (ScannerInput, bool) {
+	if input.Host != "" {
+		return input, false
+	}
+
+	tenantName, _ := tenant.FromContext(ctx)
+	defaults, ok := sessions.Get(tenantName, SessionID(ctx))
+	if !ok {
+		return input, false
+	}
+
+	input.Host = defaults.Host
+	if input.Port == 0 {
+		input.Port = defaults.Port
+	}
+	if input.Scheme == "" {
+		input.Scheme = defaults.Scheme
+	}
+	if input.Path == "" {
+		input.Path = defaults.Path
+	}
+	if input.Vhost == "" && len(input.Vhosts) == 0 {
+		input.Vhost = defaults.Vhost
+	}
+	if input.CABundle == "" {
+		input.CABundle = defaults.CABundle
+	}
+	input.InsecureSkipVerify = input.InsecureSkipVerify || defaults.InsecureSkipVerify
+	if len(defaults.Options) > 0 {
+		options := maps.Clone(defaults.Options)
+		maps.Copy(options, input.Options)
+		input.Options = options
+	}
+
+	return input, true
+}
@@ -0,0 +1,72 @@
+package tools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/session"
+	"github.com/tb0hdan/wass-mcp/pkg/tenant"
+)
+
+type SessionDefaultsTestSuite struct {
+	suite.Suite
+	sessions *session.Store
+}
+
+func (s *SessionDefaultsTestSuite) SetupTest() {
+	s.sessions = session.NewStore(session.DefaultTTL)
+	s.sessions.Set("", "s1", session.Defaults{
+		CABundle: "/etc/ca.pem",
+		Host:     "example.com",
+		Options:  map[string]string{OptionUserAgent: "agent", OptionMaxDepth: "5"},
+		Path:     "/app",
+		Port:     8443,
+		Scheme:   "https",
+		Vhost:    "internal.example.com",
+	})
+}
+
+func (s *SessionDefaultsTestSuite) TestApplied() {
+	ctx := WithSessionID(context.Background(), "s1")
+	input, ok := ApplySessionDefaults(ctx, s.sessions, ScannerInput{
+		Port:    9443,
+		Options: map[string]string{OptionMaxDepth: "2"},
+	})
+	s.Require().True(ok)
+	s.Equal("example.com", input.Host)
+	s.Equal(9443, input.Port)
+	s.Equal("https", input.Scheme)
+	s.Equal("/app", input.Path)
+	s.Equal("internal.example.com", input.Vhost)
+	s.Equal("/etc/ca.pem", input.CABundle)
+	s.Equal(map[string]string{OptionUserAgent: "agent", OptionMaxDepth: "2"}, input.Options)
+}
+
+func (s *SessionDefaultsTestSuite) TestVhostListWins() {
+	ctx := WithSessionID(context.Background(), "s1")
+	input, ok := ApplySessionDefaults(ctx, s.sessions, ScannerInput{Vhosts: []string{"a.example"}})
+	s.Require().True(ok)
+	s.Empty(input.Vhost)
+}
+
+func (s *SessionDefaultsTestSuite) TestNotApplied() {
+	ctx := WithSessionID(context.Background(), "s1")
+	input, ok := ApplySessionDefaults(ctx, s.sessions, ScannerInput{Host: "other.example"})
+	s.False(ok)
+	s.Equal(ScannerInput{Host: "other.example"}, input)
+
+	// Other sessions, other tenants and calls without session have no defaults.
+	_, ok = ApplySessionDefaults(WithSessionID(context.Background(), "s2"), s.sessions, ScannerInput{})
+	s.False(ok)
+	_, ok = ApplySessionDefaults(tenant.WithTenant(ctx, "acme"), s.sessions, ScannerInput{})
+	s.False(ok)
+	_, ok = ApplySessionDefaults(context.Background(), s.sessions, ScannerInput{})
+	s.False(ok)
+	_, ok = ApplySessionDefaults(ctx, nil, ScannerInput{})
+	s.False(ok)
+}
+
+func TestSessionDefaultsTestSuite(t *testing.T) {
+	suite.Run(t, new(SessionDefaultsTestSuite))
+}
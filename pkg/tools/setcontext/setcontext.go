@@ -0,0 +1,167 @@
+package setcontext
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+	"github.com/tb0hdan/wass-mcp/pkg/server"
+	"github.com/tb0hdan/wass-mcp/pkg/session"
+	"github.com/tb0hdan/wass-mcp/pkg/tenant"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+)
+
+const toolName = "set_context"
+
+var (
+	// ErrNoSession is returned when the request carries no MCP session ID to attach the context to.
+	ErrNoSession = errors.New("set_context needs an MCP session: send the Mcp-Session-Id header returned by initialize")
+	// ErrUnknownSession is returned for session IDs the server process did not issue: made up by
+	// the client, or issued before a restart or by another server instance.
+	ErrUnknownSession = errors.New("session ID not issued by this server process: initialize a new session, " +
+		"session contexts are lost on restart and not shared between server instances")
+)
+
+// Input sets, shows or clears the default target of the session. Without host and clear it
+// shows the current default.
+type Input struct {
+	CABundle           string            `json:"ca_bundle,omitempty"`
+	Clear              bool              `json:"clear,omitempty"`
+	Host               string            `json:"host,omitempty"`
+	InsecureSkipVerify bool              `json:"insecure_skip_verify,omitempty"`
+	Options            map[string]string `json:"options,omitempty"`
+	Path               string            `json:"path,omitempty"`
+	Port               int               `json:"port,omitempty"`
+	Scheme             string            `json:"scheme,omitempty"`
+	Vhost              string            `json:"vhost,omitempty"`
+}
+
+// Result is the set_context tool response.
+type Result struct {
+	Cleared   bool              `json:"cleared,omitempty"`
+	Context   *session.Defaults `json:"context,omitempty"`
+	SessionID string            `json:"session_id"`
+	// Target is the URL scanner calls without host scan.
+	Target string `json:"target,omitempty"`
+}
+
+type Tool struct {
+	logger    zerolog.Logger
+	sessions  *session.Store
+	validator *validator.Validate
+}
+
+func (t *Tool) Register(srv *server.Server) error {
+	tool := &mcp.Tool{
+		Name: toolName,
+		Description: "Sets the default scan target of this MCP session (host, port, scheme, path, vhost, TLS options and " +
+			"scanner options). Scanner tools and full_scan called without host use it. Without host it shows the " +
+			"current default; clear=true removes it. Requires the Mcp-Session-Id issued by initialize. The context is " +
+			"kept in the memory of this server process: it is lost on restart and not shared between server instances.",
+	}
+
+	t.sessions = srv.Sessions()
+
+	mcp.AddTool(&srv.Server, tool, t.Handler)
+	t.logger.Debug().Msgf("%s tool registered", toolName)
+
+	return nil
+}
+
+func (t *Tool) Handler(ctx context.Context, req *mcp.CallToolRequest, input Input) (*mcp.CallToolResult, any, error) {
+	sessionID := ""
+	if req != nil && req.Session != nil {
+		sessionID = req.Session.ID()
+	}
+	if sessionID == "" {
+		return nil, nil, ErrNoSession
+	}
+	if !t.sessions.Issued(sessionID) {
+		return nil, nil, ErrUnknownSession
+	}
+	tenantName, _ := tenant.FromContext(ctx)
+	result := Result{SessionID: sessionID}
+
+	switch {
+	case input.Clear:
+		result.Cleared = t.sessions.Clear(tenantName, sessionID)
+		t.logger.Debug().Msgf("Session %s context cleared", sessionID)
+	case input.Host == "":
+		if defaults, ok := t.sessions.Get(tenantName, sessionID); ok {
+			result.Context = &defaults
+			result.Target = target(defaults)
+		}
+	default:
+		defaults, err := t.defaults(input)
+		if err != nil {
+			return nil, nil, err
+		}
+		t.sessions.Set(tenantName, sessionID, defaults)
+		result.Context = &defaults
+		result.Target = target(defaults)
+		t.logger.Debug().Msgf("Session %s context set to %s", sessionID, result.Target)
+	}
+
+	data, _ := json.MarshalIndent(result, "", "  ")
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: string(data)},
+		},
+	}, nil, nil
+}
+
+// defaults validates input like a scanner input and returns the session defaults it sets.
+func (t *Tool) defaults(input Input) (session.Defaults, error) {
+	scannerInput := tools.PrepareScannerInput(tools.ScannerInput{
+		CABundle:           input.CABundle,
+		Host:               input.Host,
+		InsecureSkipVerify: input.InsecureSkipVerify,
+		Options:            input.Options,
+		Path:               input.Path,
+		Port:               input.Port,
+		Scheme:             input.Scheme,
+		Vhost:              input.Vhost,
+	})
+	if err := t.validator.Struct(scannerInput); err != nil {
+		return session.Defaults{}, fmt.Errorf("validation error: %w", err)
+	}
+	if err := tools.ValidateOptions(scannerInput.Options); err != nil {
+		return session.Defaults{}, fmt.Errorf("validation error: %w", err)
+	}
+
+	return session.Defaults{
+		CABundle:           scannerInput.CABundle,
+		Host:               scannerInput.Host,
+		InsecureSkipVerify: scannerInput.InsecureSkipVerify,
+		Options:            scannerInput.Options,
+		Path:               scannerInput.Path,
+		Port:               scannerInput.Port,
+		Scheme:             scannerInput.Scheme,
+		Vhost:              scannerInput.Vhost,
+	}, nil
+}
+
+// target returns the URL scanned with defaults.
+func target(defaults session.Defaults) string {
+	params := tools.ResolveParams(tools.ScannerInput{
+		Host:   defaults.Host,
+		Path:   defaults.Path,
+		Port:   defaults.Port,
+		Scheme: defaults.Scheme,
+	})
+
+	return params.Target().URL()
+}
+
+// New creates a new set_context tool.
+func New(logger zerolog.Logger) tools.Tool {
+	return &Tool{
+		logger:    logger.With().Str("tool", toolName).Logger(),
+		validator: validator.New(),
+	}
+}
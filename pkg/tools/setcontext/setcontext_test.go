@@ -0,0 +1,163 @@
+package setcontext
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/server"
+	"github.com/tb0hdan/wass-mcp/pkg/storage"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+)
+
+type SetContextTestSuite struct {
+	suite.Suite
+	client  *mcp.ClientSession
+	cleanup func()
+	scanned chan tools.ScanParams
+	url     string
+}
+
+// sessionOverride is a transport replacing the Mcp-Session-Id header of requests, as a client
+// making up its session ID would.
+type sessionOverride struct {
+	id string
+}
+
+func (o sessionOverride) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("Mcp-Session-Id") != "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("Mcp-Session-Id", o.id)
+	}
+
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func (s *SetContextTestSuite) SetupTest() {
+	tmpFile, err := os.CreateTemp("", "setcontext-*.db")
+	s.Require().NoError(err)
+	tmpFile.Close()
+	store, err := storage.NewSQLiteStorage(storage.Config{DatabasePath: tmpFile.Name()})
+	s.Require().NoError(err)
+
+	srv := server.NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}, store)
+	s.Require().NoError(New(zerolog.Nop()).Register(srv))
+
+	// A scanner tool named after a binary that is always available, recording what it scans.
+	s.scanned = make(chan tools.ScanParams, 4)
+	scanner := tools.NewBaseScanner("sh", "probe", zerolog.Nop(), tools.OptionUserAgent, tools.OptionVhost)
+	scan := func(_ context.Context, params tools.ScanParams) tools.ScanResult {
+		s.scanned <- params
+		return tools.ScanResult{Output: "ok"}
+	}
+	s.Require().NoError(scanner.RegisterTool(srv, func(ctx context.Context, _ *mcp.CallToolRequest, input tools.ScannerInput) (*mcp.CallToolResult, any, error) {
+		return scanner.HandleScan(ctx, input, "output", scan)
+	}))
+
+	handler := mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server {
+		return &srv.Server
+	}, &mcp.StreamableHTTPOptions{Stateless: true})
+	httpServer := httptest.NewServer(handler)
+	s.url = httpServer.URL
+
+	s.client, err = mcp.NewClient(&mcp.Implementation{Name: "agent", Version: "1.0.0"}, nil).
+		Connect(context.Background(), &mcp.StreamableClientTransport{Endpoint: httpServer.URL}, nil)
+	s.Require().NoError(err)
+
+	s.cleanup = func() {
+		s.client.Close()
+		httpServer.Close()
+		srv.Shutdown(context.Background())
+		os.Remove(tmpFile.Name())
+	}
+}
+
+func (s *SetContextTestSuite) TearDownTest() {
+	s.cleanup()
+}
+
+// call calls a tool and returns its text response.
+func (s *SetContextTestSuite) call(name string, args any) (string, bool) {
+	result, err := s.client.CallTool(context.Background(), &mcp.CallToolParams{Name: name, Arguments: args})
+	s.Require().NoError(err)
+	s.Require().NotEmpty(result.Content)
+
+	return result.Content[0].(*mcp.TextContent).Text, result.IsError
+}
+
+func (s *SetContextTestSuite) TestScanWithSessionDefaults() {
+	text, isError := s.call(toolName, map[string]any{
+		"host": "https://app.example.com:8443/admin", "vhost": "internal.example.com",
+		"options": map[string]string{"user_agent": "agent/1.0"},
+	})
+	s.Require().False(isError, text)
+	var result Result
+	s.Require().NoError(json.Unmarshal([]byte(text), &result))
+	s.NotEmpty(result.SessionID)
+	s.Equal("https://app.example.com:8443/admin", result.Target)
+
+	// A call without host scans the session default.
+	_, isError = s.call("sh", map[string]any{})
+	s.Require().False(isError)
+	params := <-s.scanned
+	s.Equal("app.example.com", params.Host)
+	s.Equal(8443, params.Port)
+	s.Equal("https", params.Scheme)
+	s.Equal("/admin", params.Path)
+	s.Equal("internal.example.com", params.Vhost)
+	s.Equal("agent/1.0", params.Option(tools.OptionUserAgent))
+
+	// A call naming a host ignores the defaults.
+	_, isError = s.call("sh", map[string]any{"host": "other.example.com"})
+	s.Require().False(isError)
+	params = <-s.scanned
+	s.Equal("other.example.com", params.Host)
+	s.Empty(params.Vhost)
+	s.Empty(params.Option(tools.OptionUserAgent))
+
+	// Without host, set_context shows the current default; clear removes it.
+	text, _ = s.call(toolName, map[string]any{})
+	s.Contains(text, `"target": "https://app.example.com:8443/admin"`)
+	text, _ = s.call(toolName, map[string]any{"clear": true})
+	s.Contains(text, `"cleared": true`)
+	text, _ = s.call(toolName, map[string]any{})
+	s.NotContains(text, "target")
+}
+
+func (s *SetContextTestSuite) TestValidation() {
+	text, isError := s.call(toolName, map[string]any{"host": "example.com", "port": 70000})
+	s.True(isError)
+	s.Contains(text, "validation error")
+
+	text, isError = s.call(toolName, map[string]any{"host": "example.com", "options": map[string]string{"max_depth": "0"}})
+	s.True(isError)
+	s.Contains(text, "option max_depth")
+}
+
+func (s *SetContextTestSuite) TestClientChosenSession() {
+	transport := &mcp.StreamableClientTransport{Endpoint: s.url, HTTPClient: &http.Client{Transport: sessionOverride{id: "client-chosen"}}}
+	client, err := mcp.NewClient(&mcp.Implementation{Name: "agent", Version: "1.0.0"}, nil).
+		Connect(context.Background(), transport, nil)
+	s.Require().NoError(err)
+	defer client.Close()
+
+	result, err := client.CallTool(context.Background(), &mcp.CallToolParams{Name: toolName, Arguments: map[string]any{"host": "example.com"}})
+	s.Require().NoError(err)
+	s.True(result.IsError)
+	s.Contains(result.Content[0].(*mcp.TextContent).Text, ErrUnknownSession.Error())
+}
+
+func (s *SetContextTestSuite) TestHandler_NoSession() {
+	_, _, err := New(zerolog.Nop()).(*Tool).Handler(context.Background(), &mcp.CallToolRequest{}, Input{Host: "example.com"})
+	s.ErrorIs(err, ErrNoSession)
+}
+
+func TestSetContextTestSuite(t *testing.T) {
+	suite.Run(t, new(SetContextTestSuite))
+}
@@ -0,0 +1,51 @@
+package shcheck
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/tb0hdan/wass-mcp/pkg/findings"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/types"
+)
+
+// result is the per-URL section of shcheck JSON output.
+type result struct {
+	Missing []string `json:"missing"`
+}
+
+// ParseFindings parses shcheck JSON output and reports each missing security header.
+// Output that is not JSON falls back to bracketed severity tags.
+func (t *Tool) ParseFindings(output string) ([]models.Finding, error) {
+	start := strings.Index(output, "{")
+	end := strings.LastIndex(output, "}")
+	if start < 0 || end <= start {
+		return findings.ExtractGeneric(binaryName, output), nil
+	}
+
+	var results map[string]result
+	if err := json.Unmarshal([]byte(output[start:end+1]), &results); err != nil {
+		return findings.ExtractGeneric(binaryName, output), nil //nolint:nilerr
+	}
+
+	targets := make([]string, 0, len(results))
+	for target := range results {
+		targets = append(targets, target)
+	}
+	sort.Strings(targets)
+
+	var found []models.Finding
+	for _, target := range targets {
+		for _, header := range results[target].Missing {
+			found = append(found, models.Finding{
+				Scanner:  binaryName,
+				Severity: types.SeverityLow,
+				Title:    "Missing security header: " + header,
+				URL:      target,
+			})
+		}
+	}
+
+	return found, nil
+}
@@ -0,0 +1,52 @@
+package shcheck
+
+import (
+	"os"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/types"
+)
+
+const jsonOutput = `[*] Analyzing headers of http://example.com
+{"http://example.com": {"present": {"X-Frame-Options": "DENY"}, "missing": ["Content-Security-Policy", "Strict-Transport-Security"]},
+ "http://a.example.com": {"present": {}, "missing": ["X-Frame-Options"]}}`
+
+type ParseTestSuite struct {
+	suite.Suite
+	tool *Tool
+}
+
+func (s *ParseTestSuite) SetupTest() {
+	s.tool = New(zerolog.New(os.Stdout)).(*Tool)
+}
+
+func (s *ParseTestSuite) TestParseFindings() {
+	found, err := s.tool.ParseFindings(jsonOutput)
+	s.Require().NoError(err)
+	s.Require().Len(found, 3)
+	// Targets are reported in sorted order.
+	s.Equal("http://a.example.com", found[0].URL)
+	s.Equal("Missing security header: X-Frame-Options", found[0].Title)
+	s.Equal("Missing security header: Content-Security-Policy", found[1].Title)
+	s.Equal("http://example.com", found[1].URL)
+	s.Equal(types.SeverityLow, found[2].Severity)
+	s.Equal("shcheck.py", found[2].Scanner)
+}
+
+func (s *ParseTestSuite) TestParseFindings_TextFallback() {
+	found, err := s.tool.ParseFindings("[!] Missing security header: X-XSS-Protection [low]")
+	s.Require().NoError(err)
+	s.Require().Len(found, 1)
+	s.Equal(types.SeverityLow, found[0].Severity)
+
+	found, err = s.tool.ParseFindings("{not json} [medium] http://example.com")
+	s.Require().NoError(err)
+	s.Require().Len(found, 1)
+	s.Equal(types.SeverityMedium, found[0].Severity)
+}
+
+func TestParseTestSuite(t *testing.T) {
+	suite.Run(t, new(ParseTestSuite))
+}
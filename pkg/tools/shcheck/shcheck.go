@@ -3,12 +3,12 @@ package shcheck
 import (
 	"context"
 	"fmt"
-	"os/exec"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/rs/zerolog"
 	"github.com/tb0hdan/wass-mcp/pkg/server"
 	"github.com/tb0hdan/wass-mcp/pkg/tools"
+	"github.com/tb0hdan/wass-mcp/pkg/vault"
 )
 
 const (
@@ -17,6 +17,12 @@ const (
 	headerVerb  = "output"
 )
 
+// supportedOptions are the scan options shcheck honours.
+var supportedOptions = []string{
+	tools.OptionCABundle, tools.OptionCapture, tools.OptionCredential, tools.OptionInsecureSkipVerify, tools.OptionUserAgent,
+	tools.OptionVhost,
+}
+
 // Tool implements the shcheck security headers scanner.
 type Tool struct {
 	tools.BaseScanner
@@ -24,16 +30,28 @@ type Tool struct {
 
 // Scan performs the shcheck scan and returns the output.
 func (t *Tool) Scan(ctx context.Context, params tools.ScanParams) tools.ScanResult {
-	targetURL := tools.BuildTargetURL(params)
-	t.Logger.Info().Msgf("Running shcheck scan on %s", targetURL)
+	targetURL := params.Target().URL()
+	logger := tools.ContextLogger(ctx, t.Logger)
+	logger.Info().Msgf("Running shcheck scan on %s", targetURL)
 
-	args := []string{"-j", "-d", targetURL}
-	if params.Vhost != "" {
-		args = append(args, "-a", fmt.Sprintf("Host: %s", params.Vhost))
+	if err := params.CheckCredential(binaryName, vault.HeaderTypes...); err != nil {
+		return tools.ScanResult{
+			Error: err,
+		}
+	}
+
+	workDir, cleanup, err := t.WorkDir(ctx)
+	if err != nil {
+		return tools.ScanResult{
+			Error: err,
+		}
 	}
+	defer cleanup()
 
-	cmd := exec.CommandContext(ctx, binaryName, args...) //nolint:gosec
-	output, err := cmd.CombinedOutput()
+	cmd := t.Command(ctx, buildArgs(targetURL, params)...)
+	cmd.Dir = workDir
+	cmd.Env = tools.ScanEnv(params, workDir)
+	output, err := t.CombinedOutput(ctx, cmd)
 
 	if err != nil {
 		return tools.ScanResult{
@@ -48,6 +66,32 @@ func (t *Tool) Scan(ctx context.Context, params tools.ScanParams) tools.ScanResu
 	}
 }
 
+// buildArgs builds the shcheck command line arguments.
+// Certificate validation stays disabled (-d) unless a CA bundle is provided to verify against.
+func buildArgs(targetURL string, params tools.ScanParams) []string {
+	args := []string{"-j"}
+	if params.CABundle == "" || params.InsecureSkipVerify {
+		args = append(args, "-d")
+	}
+	args = append(args, targetURL)
+	if header := params.Target().HostHeader(); header != "" {
+		args = append(args, "-a", header)
+	}
+	if userAgent := params.Option(tools.OptionUserAgent); userAgent != "" {
+		args = append(args, "-a", fmt.Sprintf("User-Agent: %s", userAgent))
+	}
+	if params.Credential != nil {
+		for _, header := range params.Credential.Headers() {
+			args = append(args, "-a", header)
+		}
+	}
+	if params.Proxy != "" {
+		args = append(args, "--proxy", params.Proxy)
+	}
+
+	return args
+}
+
 // Register registers the shcheck tool with the MCP server.
 func (t *Tool) Register(srv *server.Server) error {
 	return t.RegisterTool(srv, t.Handler)
@@ -55,32 +99,15 @@ func (t *Tool) Register(srv *server.Server) error {
 
 // Handler handles MCP tool requests.
 func (t *Tool) Handler(ctx context.Context, _ *mcp.CallToolRequest, input tools.ScannerInput) (*mcp.CallToolResult, any, error) {
-	input = t.PrepareInput(input)
-
-	if err := t.ValidateInput(input); err != nil {
-		return nil, nil, err
-	}
-
-	params := t.ResolveInput(input)
-
-	scanResult := t.Scan(ctx, params)
-	if scanResult.Error != nil {
-		return nil, nil, fmt.Errorf("%w\nOutput: %s", scanResult.Error, scanResult.Output)
-	}
-
-	targetURL := tools.BuildTargetURL(params)
-	resultText := tools.FormatScannerOutput(binaryName, headerVerb, targetURL, scanResult.Output, input.MaxLines, input.Offset)
-
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{
-			&mcp.TextContent{Text: resultText},
-		},
-	}, nil, nil
+	return t.HandleScan(ctx, input, headerVerb, t.Scan)
 }
 
 // New creates a new shcheck scanner tool.
 func New(logger zerolog.Logger) tools.Scanner {
-	return &Tool{
-		BaseScanner: tools.NewBaseScanner(binaryName, description, logger),
-	}
+	base := tools.NewBaseScanner(binaryName, description, logger, supportedOptions...)
+	base.WarmUpArgs = []string{"--help"}
+	// shcheck inspects the headers of a single response.
+	base.Passive = true
+
+	return &Tool{BaseScanner: base}
 }
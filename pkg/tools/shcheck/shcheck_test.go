@@ -11,6 +11,7 @@ import (
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/suite"
 	"github.com/tb0hdan/wass-mcp/pkg/tools"
+	"github.com/tb0hdan/wass-mcp/pkg/vault"
 )
 
 // scanTestTimeout is a short timeout for tests that invoke the actual scanner.
@@ -39,6 +40,10 @@ func (s *ShcheckTestSuite) TestName() {
 	s.Equal("shcheck.py", s.tool.Name())
 }
 
+func (s *ShcheckTestSuite) TestIsPassive() {
+	s.True(tools.IsPassive(s.tool))
+}
+
 func (s *ShcheckTestSuite) TestIsAvailable() {
 	// This test just ensures IsAvailable doesn't panic.
 	// It may return true or false depending on if shcheck is installed.
@@ -312,6 +317,43 @@ func (s *ShcheckTestSuite) TestScan_WithVhost() {
 	}
 }
 
+func (s *ShcheckTestSuite) TestBuildArgs_Default() {
+	args := buildArgs("https://localhost", tools.ScanParams{})
+	s.Equal([]string{"-j", "-d", "https://localhost"}, args)
+}
+
+func (s *ShcheckTestSuite) TestBuildArgs_CABundleVerifies() {
+	args := buildArgs("https://localhost", tools.ScanParams{CABundle: "/etc/ssl/ca.pem"})
+	s.Equal([]string{"-j", "https://localhost"}, args)
+}
+
+func (s *ShcheckTestSuite) TestBuildArgs_Vhost() {
+	args := buildArgs("http://10.0.0.1", tools.ScanParams{Vhost: "example.com"})
+	s.Equal([]string{"-j", "-d", "http://10.0.0.1", "-a", "Host: example.com"}, args)
+}
+
+func (s *ShcheckTestSuite) TestBuildArgs_UserAgent() {
+	args := buildArgs("http://localhost", tools.ScanParams{Options: map[string]string{tools.OptionUserAgent: "wass"}})
+	s.Equal([]string{"-j", "-d", "http://localhost", "-a", "User-Agent: wass"}, args)
+}
+
+func (s *ShcheckTestSuite) TestBuildArgs_Proxy() {
+	args := buildArgs("http://localhost", tools.ScanParams{Proxy: "http://127.0.0.1:8080"})
+	s.Equal([]string{"--proxy", "http://127.0.0.1:8080"}, args[len(args)-2:])
+}
+
+func (s *ShcheckTestSuite) TestBuildArgs_Credential() {
+	credential := &vault.Credential{Type: vault.TypeCookie, Secret: vault.Secret{Cookie: "session=abc"}}
+	args := buildArgs("http://localhost", tools.ScanParams{Credential: credential})
+	s.Equal([]string{"-j", "-d", "http://localhost", "-a", "Cookie: session=abc"}, args)
+}
+
+func (s *ShcheckTestSuite) TestSupportedOptions() {
+	s.Contains(s.tool.SupportedOptions(), tools.OptionCABundle)
+	s.Contains(s.tool.SupportedOptions(), tools.OptionCapture)
+	s.Contains(s.tool.SupportedOptions(), tools.OptionUserAgent)
+}
+
 func TestShcheckTestSuite(t *testing.T) {
 	suite.Run(t, new(ShcheckTestSuite))
 }
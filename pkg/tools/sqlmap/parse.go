@@ -0,0 +1,134 @@
+package sqlmap
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/tb0hdan/wass-mcp/pkg/findings"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/types"
+)
+
+const (
+	// injectionTemplate is the template ID of SQL injection findings.
+	injectionTemplate = "sql-injection"
+	// dbmsTemplate is the template ID of back-end DBMS findings.
+	dbmsTemplate = "dbms-detect"
+	// injectionCWE is the weakness class of SQL injection.
+	injectionCWE = "CWE-89"
+)
+
+var (
+	// parameterRe matches the "Parameter: id (GET)" header of an injection point.
+	parameterRe = regexp.MustCompile(`^Parameter: (.+?) \((.+)\)$`)
+	// targetRe matches the URL of the target or form under test.
+	targetRe = regexp.MustCompile(`^(?:(?:GET|POST|PUT|DELETE|PATCH) |.*\[INFO\] testing URL ')(https?://[^\s']+)`)
+	// dbmsRe matches the back-end DBMS reported once the injection is confirmed.
+	dbmsRe = regexp.MustCompile(`^back-end DBMS: (.+)$`)
+)
+
+// injectionPoint is an injectable parameter of sqlmap output.
+type injectionPoint struct {
+	parameter string
+	place     string
+	// techniques are the injection types, payloads their "Type:", "Title:" and "Payload:" blocks.
+	techniques []string
+	payloads   []string
+}
+
+// ParseFindings parses the injection points sqlmap reports between "---" lines into high
+// severity SQL injection findings, one per parameter with its techniques in the title and its
+// payloads as evidence, and the back-end DBMS into an info finding. The URL is the last target or
+// form sqlmap tested, empty for single URL scans. sqlmap log levels are not finding severities,
+// so output without injection points has no findings.
+func (t *Tool) ParseFindings(output string) ([]models.Finding, error) {
+	var found []models.Finding
+	var targetURL string
+	var point *injectionPoint
+	var payload []string
+	inBlock := false
+
+	flushPayload := func() {
+		if point != nil && len(payload) > 0 {
+			point.payloads = append(point.payloads, strings.Join(payload, "\n"))
+		}
+		payload = nil
+	}
+	flushPoint := func() {
+		flushPayload()
+		if point != nil {
+			found = append(found, point.finding(targetURL))
+		}
+		point = nil
+	}
+
+	for _, line := range strings.Split(output, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "---" {
+			flushPoint()
+			inBlock = !inBlock
+			continue
+		}
+		if !inBlock {
+			if match := targetRe.FindStringSubmatch(trimmed); match != nil {
+				targetURL = match[1]
+			}
+			if match := dbmsRe.FindStringSubmatch(trimmed); match != nil {
+				found = append(found, models.Finding{
+					Scanner:    binaryName,
+					Severity:   types.SeverityInfo,
+					TemplateID: dbmsTemplate,
+					Title:      "Back-end DBMS: " + match[1],
+					URL:        targetURL,
+				})
+			}
+			continue
+		}
+
+		if match := parameterRe.FindStringSubmatch(trimmed); match != nil {
+			flushPoint()
+			point = &injectionPoint{parameter: match[1], place: match[2]}
+			continue
+		}
+		if point == nil {
+			continue
+		}
+		switch {
+		case trimmed == "":
+			flushPayload()
+		case strings.HasPrefix(trimmed, "Type: "):
+			flushPayload()
+			point.techniques = append(point.techniques, strings.TrimPrefix(trimmed, "Type: "))
+			payload = append(payload, trimmed)
+		default:
+			payload = append(payload, trimmed)
+		}
+	}
+	flushPoint()
+
+	return found, nil
+}
+
+// finding returns the SQL injection finding of the injection point found at targetURL.
+func (p *injectionPoint) finding(targetURL string) models.Finding {
+	title := "SQL injection in " + p.place + " parameter " + p.parameter
+	if len(p.techniques) > 0 {
+		title += " (" + strings.Join(p.techniques, ", ") + ")"
+	}
+
+	var evidence []models.Evidence
+	for _, payload := range p.payloads {
+		evidence = findings.AppendEvidence(evidence, models.EvidenceRequest, binaryName, payload)
+	}
+
+	return models.Finding{
+		CWEs:       []string{injectionCWE},
+		Evidence:   evidence,
+		Parameter:  p.parameter,
+		Scanner:    binaryName,
+		Severity:   types.SeverityHigh,
+		TemplateID: injectionTemplate,
+		Title:      title,
+		URL:        targetURL,
+	}
+}
@@ -0,0 +1,102 @@
+package sqlmap
+
+import (
+	"os"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/types"
+)
+
+const injectableOutput = `[12:00:01] [INFO] testing connection to the target URL
+[12:00:02] [INFO] testing if GET parameter 'id' is dynamic
+[12:00:04] [INFO] GET parameter 'id' is 'Generic UNION query (NULL) - 1 to 20 columns' injectable
+[12:00:04] [WARNING] GET parameter 'q' does not seem to be injectable
+sqlmap identified the following injection point(s) with a total of 46 HTTP(s) requests:
+---
+Parameter: id (GET)
+    Type: boolean-based blind
+    Title: AND boolean-based blind - WHERE or HAVING clause
+    Payload: id=1 AND 8523=8523
+
+    Type: UNION query
+    Title: Generic UNION query (NULL) - 3 columns
+    Payload: id=1 UNION ALL SELECT NULL,CONCAT(0x71,0x71),NULL-- -
+
+Parameter: User-Agent (User-Agent)
+    Type: time-based blind
+    Title: MySQL >= 5.0.12 AND time-based blind (query SLEEP)
+    Payload: sqlmap' AND (SELECT 1 FROM (SELECT(SLEEP(5)))a)-- x
+---
+[12:00:05] [INFO] the back-end DBMS is MySQL
+web server operating system: Linux Ubuntu
+back-end DBMS: MySQL >= 5.0.12
+`
+
+type ParseTestSuite struct {
+	suite.Suite
+	tool *Tool
+}
+
+func (s *ParseTestSuite) SetupTest() {
+	s.tool = New(zerolog.New(os.Stdout)).(*Tool)
+}
+
+func (s *ParseTestSuite) TestParseFindings() {
+	found, err := s.tool.ParseFindings(injectableOutput)
+	s.Require().NoError(err)
+	s.Require().Len(found, 3)
+
+	s.Equal("SQL injection in GET parameter id (boolean-based blind, UNION query)", found[0].Title)
+	s.Equal(types.SeverityHigh, found[0].Severity)
+	s.Equal("id", found[0].Parameter)
+	s.Equal(injectionTemplate, found[0].TemplateID)
+	s.Equal([]string{"CWE-89"}, found[0].CWEs)
+	s.Empty(found[0].URL)
+	s.Require().Len(found[0].Evidence, 2)
+	s.Equal(models.EvidenceRequest, found[0].Evidence[0].Kind)
+	s.Equal("Type: boolean-based blind\nTitle: AND boolean-based blind - WHERE or HAVING clause\nPayload: id=1 AND 8523=8523",
+		found[0].Evidence[0].Content)
+
+	s.Equal("SQL injection in User-Agent parameter User-Agent (time-based blind)", found[1].Title)
+	s.Equal("User-Agent", found[1].Parameter)
+	s.Len(found[1].Evidence, 1)
+
+	s.Equal("Back-end DBMS: MySQL >= 5.0.12", found[2].Title)
+	s.Equal(types.SeverityInfo, found[2].Severity)
+}
+
+func (s *ParseTestSuite) TestParseFindings_Forms() {
+	output := `[12:00:01] [INFO] searching for forms
+[1/1] Form:
+POST http://localhost/login.php
+POST data: user=&pass=
+do you want to test this form? [Y/n/q]
+> Y
+---
+Parameter: user (POST)
+    Type: error-based
+    Title: MySQL >= 5.0 AND error-based - WHERE, HAVING, ORDER BY or GROUP BY clause (FLOOR)
+    Payload: user=a' AND (SELECT 1 FROM (SELECT COUNT(*))x)-- abcd&pass=
+---
+`
+	found, err := s.tool.ParseFindings(output)
+	s.Require().NoError(err)
+	s.Require().Len(found, 1)
+	s.Equal("http://localhost/login.php", found[0].URL)
+	s.Equal("user", found[0].Parameter)
+}
+
+func (s *ParseTestSuite) TestParseFindings_NotInjectable() {
+	output := "[12:00:04] [WARNING] GET parameter 'id' does not seem to be injectable\n" +
+		"[12:00:04] [CRITICAL] all tested parameters do not appear to be injectable.\n"
+	found, err := s.tool.ParseFindings(output)
+	s.Require().NoError(err)
+	s.Empty(found, "sqlmap log levels are not finding severities")
+}
+
+func TestParseTestSuite(t *testing.T) {
+	suite.Run(t, new(ParseTestSuite))
+}
@@ -0,0 +1,134 @@
+package sqlmap
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+	"github.com/tb0hdan/wass-mcp/pkg/server"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+	"github.com/tb0hdan/wass-mcp/pkg/vault"
+)
+
+const (
+	binaryName  = "sqlmap"
+	description = "sqlmap is an SQL injection scanner detecting injectable parameters of web applications and the database behind them."
+	headerVerb  = "output"
+)
+
+// supportedOptions are the scan options sqlmap honours. sqlmap does not verify TLS certificates.
+var supportedOptions = []string{
+	tools.OptionCapture, tools.OptionCredential, tools.OptionLevel, tools.OptionMaxDepth, tools.OptionParameter,
+	tools.OptionRateLimit, tools.OptionRisk, tools.OptionUserAgent, tools.OptionVhost,
+}
+
+// Tool implements the sqlmap scanner.
+type Tool struct {
+	tools.BaseScanner
+}
+
+// Scan performs the sqlmap scan and returns its output.
+func (t *Tool) Scan(ctx context.Context, params tools.ScanParams) tools.ScanResult {
+	targetURL := params.Target().URL()
+	logger := tools.ContextLogger(ctx, t.Logger)
+	logger.Info().Msgf("Running sqlmap scan on %s", targetURL)
+
+	if err := params.CheckCredential(binaryName, vault.HeaderTypes...); err != nil {
+		return tools.ScanResult{
+			Error: err,
+		}
+	}
+
+	workDir, cleanup, err := t.WorkDir(ctx)
+	if err != nil {
+		return tools.ScanResult{
+			Error: err,
+		}
+	}
+	defer cleanup()
+
+	cmd := t.Command(ctx, buildArgs(targetURL, workDir, params)...)
+	cmd.Dir = workDir
+	cmd.Env = tools.ScanEnv(params, workDir)
+	output, err := t.CombinedOutput(ctx, cmd)
+	if err != nil {
+		return tools.ScanResult{
+			Output: string(output),
+			Error:  fmt.Errorf("failed to execute sqlmap: %w", err),
+		}
+	}
+
+	return tools.ScanResult{
+		Output: string(output),
+		Error:  nil,
+	}
+}
+
+// buildArgs builds the sqlmap command line arguments. Scans always run in batch mode, answering
+// sqlmap prompts with their defaults, since no one can answer them. Session files are written to
+// outputDir, so that each run starts afresh. Targets without a query string have their forms tested.
+func buildArgs(targetURL, outputDir string, params tools.ScanParams) []string {
+	args := []string{"-u", targetURL, "--batch", "--disable-coloring", "--output-dir", outputDir}
+	if parsed, err := url.Parse(targetURL); err == nil && parsed.RawQuery == "" {
+		args = append(args, "--forms")
+	}
+	if names := params.Option(tools.OptionParameter); names != "" {
+		args = append(args, "-p", strings.ReplaceAll(names, " ", ""))
+	}
+	if level := params.Option(tools.OptionLevel); level != "" {
+		args = append(args, "--level", level)
+	}
+	if risk := params.Option(tools.OptionRisk); risk != "" {
+		args = append(args, "--risk", risk)
+	}
+	if depth := params.Option(tools.OptionMaxDepth); depth != "" {
+		args = append(args, "--crawl", depth)
+	}
+	if rate, err := strconv.Atoi(params.Option(tools.OptionRateLimit)); err == nil && rate > 0 {
+		// sqlmap sends requests one at a time, waiting --delay seconds between them.
+		args = append(args, "--delay", fmt.Sprintf("%.3g", 1/float64(rate)))
+	}
+	if params.Vhost != "" {
+		args = append(args, "--host", params.Vhost)
+	}
+	if userAgent := params.Option(tools.OptionUserAgent); userAgent != "" {
+		args = append(args, "--user-agent", userAgent)
+	}
+	if params.Proxy != "" {
+		args = append(args, "--proxy", params.Proxy)
+	}
+	if params.Credential != nil {
+		switch params.Credential.Type {
+		case vault.TypeBasic:
+			args = append(args, "--auth-type", "Basic", "--auth-cred", params.Credential.Username+":"+params.Credential.Password)
+		case vault.TypeCookie:
+			args = append(args, "--cookie", params.Credential.Cookie)
+		default:
+			args = append(args, "--headers", strings.Join(params.Credential.Headers(), "\n"))
+		}
+	}
+
+	return args
+}
+
+// Register registers the sqlmap tool with the MCP server.
+func (t *Tool) Register(srv *server.Server) error {
+	return t.RegisterTool(srv, t.Handler)
+}
+
+// Handler handles MCP tool requests.
+func (t *Tool) Handler(ctx context.Context, _ *mcp.CallToolRequest, input tools.ScannerInput) (*mcp.CallToolResult, any, error) {
+	return t.HandleScan(ctx, input, headerVerb, t.Scan)
+}
+
+// New creates a new sqlmap scanner tool.
+func New(logger zerolog.Logger) tools.Scanner {
+	base := tools.NewBaseScanner(binaryName, description, logger, supportedOptions...)
+	base.VersionArgs = []string{"--version"}
+
+	return &Tool{BaseScanner: base}
+}
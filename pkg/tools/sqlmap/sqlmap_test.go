@@ -0,0 +1,98 @@
+package sqlmap
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+	"github.com/tb0hdan/wass-mcp/pkg/vault"
+)
+
+type SqlmapTestSuite struct {
+	suite.Suite
+	logger zerolog.Logger
+	tool   *Tool
+}
+
+func (s *SqlmapTestSuite) SetupTest() {
+	s.logger = zerolog.New(os.Stdout).With().Timestamp().Logger()
+	s.tool = New(s.logger).(*Tool)
+}
+
+func (s *SqlmapTestSuite) TestNew() {
+	s.Equal("sqlmap", s.tool.Name())
+	s.Empty(tools.Technologies(s.tool), "sqlmap runs in full_scan")
+	s.False(tools.IsPassive(s.tool))
+}
+
+func (s *SqlmapTestSuite) TestBuildArgs_Default() {
+	args := buildArgs("http://localhost/item.php?id=1", "/tmp/out", tools.ScanParams{})
+	s.Equal([]string{"-u", "http://localhost/item.php?id=1", "--batch", "--disable-coloring", "--output-dir", "/tmp/out"}, args)
+
+	args = buildArgs("http://localhost", "/tmp/out", tools.ScanParams{})
+	s.Contains(args, "--forms", "targets without a query string have their forms tested")
+}
+
+func (s *SqlmapTestSuite) TestBuildArgs_Options() {
+	params := tools.ScanParams{
+		Options: map[string]string{
+			tools.OptionLevel: "3", tools.OptionRisk: "2", tools.OptionParameter: "id, q",
+			tools.OptionMaxDepth: "2", tools.OptionRateLimit: "4", tools.OptionUserAgent: "wass",
+		},
+		Proxy: "http://127.0.0.1:8080",
+		Vhost: "shop.example.com",
+	}
+	args := buildArgs("https://10.0.0.1/?id=1", "/tmp/out", params)
+	s.Equal([]string{
+		"-u", "https://10.0.0.1/?id=1", "--batch", "--disable-coloring", "--output-dir", "/tmp/out",
+		"-p", "id,q", "--level", "3", "--risk", "2", "--crawl", "2", "--delay", "0.25",
+		"--host", "shop.example.com",
+		"--user-agent", "wass", "--proxy", "http://127.0.0.1:8080",
+	}, args)
+}
+
+func (s *SqlmapTestSuite) TestBuildArgs_Credential() {
+	basic := &vault.Credential{Type: vault.TypeBasic, Secret: vault.Secret{Username: "user", Password: "pass"}}
+	args := buildArgs("http://localhost/?id=1", "/tmp/out", tools.ScanParams{Credential: basic})
+	s.Equal([]string{"--auth-type", "Basic", "--auth-cred", "user:pass"}, args[len(args)-4:])
+
+	cookie := &vault.Credential{Type: vault.TypeCookie, Secret: vault.Secret{Cookie: "session=abc"}}
+	args = buildArgs("http://localhost/?id=1", "/tmp/out", tools.ScanParams{Credential: cookie})
+	s.Equal([]string{"--cookie", "session=abc"}, args[len(args)-2:])
+
+	bearer := &vault.Credential{Type: vault.TypeBearer, Secret: vault.Secret{Token: "abc"}}
+	args = buildArgs("http://localhost/?id=1", "/tmp/out", tools.ScanParams{Credential: bearer})
+	s.Equal([]string{"--headers", "Authorization: Bearer abc"}, args[len(args)-2:])
+}
+
+func (s *SqlmapTestSuite) TestScan() {
+	// The fake sqlmap echoes its arguments.
+	binDir := s.T().TempDir()
+	script := "#!/bin/sh\necho \"$@\"\n"
+	s.Require().NoError(os.WriteFile(filepath.Join(binDir, binaryName), []byte(script), 0o700)) //nolint:gosec
+	s.T().Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	result := s.tool.Scan(context.Background(), tools.ScanParams{Host: "localhost", Port: 80, Scheme: "http", Path: "/item.php?id=1"})
+	s.Require().NoError(result.Error)
+	s.Contains(result.Output, "-u http://localhost/item.php?id=1 --batch")
+
+	script = "#!/bin/sh\necho '[CRITICAL] unable to connect to the target URL'\nexit 1\n"
+	s.Require().NoError(os.WriteFile(filepath.Join(binDir, binaryName), []byte(script), 0o700)) //nolint:gosec
+	result = s.tool.Scan(context.Background(), tools.ScanParams{Host: "localhost", Port: 80, Scheme: "http"})
+	s.ErrorContains(result.Error, "failed to execute sqlmap")
+	s.Contains(result.Output, "unable to connect")
+}
+
+func (s *SqlmapTestSuite) TestHandler_ValidationError() {
+	_, _, err := s.tool.Handler(context.Background(), &mcp.CallToolRequest{}, tools.ScannerInput{Host: "invalid host!!!"})
+	s.ErrorContains(err, "validation error")
+}
+
+func TestSqlmapTestSuite(t *testing.T) {
+	suite.Run(t, new(SqlmapTestSuite))
+}
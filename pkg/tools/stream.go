@@ -0,0 +1,107 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os/exec"
+
+	"github.com/tb0hdan/wass-mcp/pkg/sanitize"
+	"github.com/tb0hdan/wass-mcp/pkg/types"
+)
+
+// CombinedOutput runs cmd and returns its combined standard output and standard error, like
+// exec.Cmd.CombinedOutput. Within WrapToolHandler, the output is also streamed to the output
+// stream artifact of the in-flight execution line by line as it arrives, each line prefixed with
+// the scanner name, so that it survives a crash of the server and can be tailed by scan_status.
+func (b *BaseScanner) CombinedOutput(ctx context.Context, cmd *exec.Cmd) ([]byte, error) {
+	var output bytes.Buffer
+	writer := io.Writer(&output)
+	if inFlight, ok := ctx.Value(executionKey{}).(*execution); ok {
+		lines := &lineWriter{prefix: []byte("[" + b.BinaryName + "] "), write: inFlight.streamLines}
+		defer lines.Flush()
+		writer = io.MultiWriter(&output, lines)
+	}
+	// A single writer for both makes exec copy them through one pipe, in the order written.
+	cmd.Stdout = writer
+	cmd.Stderr = writer
+	err := cmd.Run()
+
+	return output.Bytes(), err
+}
+
+// streamLines appends complete output lines of a scanner run to the output stream of the
+// execution, opened on first use. Lines are sanitized and redacted like stored outputs. Streaming
+// is best effort: output that cannot be streamed is still returned by the scanner run.
+func (e *execution) streamLines(lines []byte) {
+	e.streamOnce.Do(func() {
+		if e.openStream != nil {
+			e.stream = e.openStream()
+		}
+	})
+	if e.stream == nil {
+		return
+	}
+	_, _ = e.stream.Write([]byte(e.redactor.Text(sanitize.Output(string(lines)))))
+}
+
+// closeStream closes the output stream of the execution and returns the path of its file, empty
+// when nothing was streamed.
+func (e *execution) closeStream() string {
+	// Later output is not streamed.
+	e.streamOnce.Do(func() {})
+	if e.stream == nil {
+		return ""
+	}
+	_ = e.stream.Close()
+
+	return e.stream.Path()
+}
+
+// lineWriter passes the complete lines written to it, prefixed with prefix, to write, holding back
+// a trailing partial line until it is completed or Flush is called. Lines longer than
+// types.StreamChunkBytes are passed on in parts.
+type lineWriter struct {
+	partial []byte
+	prefix  []byte
+	write   func([]byte)
+}
+
+// Write passes on the lines completed by p.
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.partial = append(w.partial, p...)
+	end := bytes.LastIndexByte(w.partial, '\n') + 1
+	if end == 0 && len(w.partial) >= types.StreamChunkBytes {
+		end = len(w.partial)
+	}
+	if end > 0 {
+		w.emit(w.partial[:end])
+		w.partial = append(w.partial[:0], w.partial[end:]...)
+	}
+
+	return len(p), nil
+}
+
+// Flush passes on the partial line held back.
+func (w *lineWriter) Flush() {
+	if len(w.partial) > 0 {
+		w.emit(w.partial)
+		w.partial = w.partial[:0]
+	}
+}
+
+// emit passes the lines of text to write, each prefixed with prefix and ending with a newline.
+func (w *lineWriter) emit(text []byte) {
+	var out bytes.Buffer
+	for _, line := range bytes.SplitAfter(text, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		out.Write(w.prefix)
+		out.Write(line)
+		if line[len(line)-1] != '\n' {
+			out.WriteByte('\n')
+		}
+	}
+	w.write(out.Bytes())
+}
@@ -0,0 +1,117 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/artifacts"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/types"
+)
+
+type StreamTestSuite struct {
+	suite.Suite
+}
+
+func (s *StreamTestSuite) TestLineWriter() {
+	var written []string
+	writer := &lineWriter{prefix: []byte("[nikto] "), write: func(lines []byte) { written = append(written, string(lines)) }}
+
+	_, err := writer.Write([]byte("+ Server: nginx\n+ Target"))
+	s.Require().NoError(err)
+	s.Equal([]string{"[nikto] + Server: nginx\n"}, written, "the partial line is held back")
+
+	_, err = writer.Write([]byte(" IP: 10.0.0.1\n\n+ Start"))
+	s.Require().NoError(err)
+	writer.Flush()
+	s.Equal([]string{"[nikto] + Server: nginx\n", "[nikto] + Target IP: 10.0.0.1\n[nikto] \n", "[nikto] + Start\n"}, written)
+
+	written = nil
+	_, err = writer.Write([]byte(strings.Repeat("x", types.StreamChunkBytes)))
+	s.Require().NoError(err)
+	s.Len(written, 1, "lines longer than a chunk are passed on in parts")
+}
+
+func (s *StreamTestSuite) TestCombinedOutput_OutsideWrapper() {
+	scanner := NewBaseScanner("sh", "shell", zerolog.Nop())
+
+	output, err := scanner.CombinedOutput(context.Background(), exec.Command("sh", "-c", "echo out; echo err >&2"))
+	s.Require().NoError(err)
+	s.Equal("out\nerr\n", string(output))
+
+	output, err = scanner.CombinedOutput(context.Background(), exec.Command("sh", "-c", "echo partial; exit 3"))
+	s.Error(err)
+	s.Equal("partial\n", string(output))
+}
+
+func (s *StreamTestSuite) TestCombinedOutput_StreamsExecutionOutput() {
+	store, cleanup := setupTestStorage(s.T())
+	defer cleanup()
+	dir := s.T().TempDir()
+	scanner := NewBaseScanner("sh", "shell", zerolog.Nop())
+
+	var running *models.ToolExecution
+	var streamed string
+	handler := func(ctx context.Context, _ *mcp.CallToolRequest, _ testInput) (*mcp.CallToolResult, any, error) {
+		output, err := scanner.CombinedOutput(ctx, exec.Command("sh", "-c",
+			`printf '\033[32m+ found /admin\033[0m\nAuthorization: Bearer s3cr3t\n'`))
+		s.Require().NoError(err)
+		s.Contains(string(output), "s3cr3t", "the scanner run gets its output unchanged")
+
+		inFlight := ctx.Value(executionKey{}).(*execution)
+		s.Require().NoError(inFlight.stream.Flush())
+		running, err = store.GetToolExecution(ctx, inFlight.record.ID)
+		s.Require().NoError(err)
+		data, err := os.ReadFile(running.StreamFile)
+		s.Require().NoError(err)
+		streamed = string(data)
+
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: string(output)}}}, nil, nil
+	}
+
+	wrapped := WrapToolHandler(store, "test-tool", handler, WithArtifacts(artifacts.Config{Dir: dir}))
+	_, _, err := wrapped(context.Background(), &mcp.CallToolRequest{}, testInput{Host: "localhost", Port: 80})
+	s.Require().NoError(err)
+
+	s.Equal(models.StatusRunning, running.Status)
+	s.Contains(running.StreamFile, dir)
+	s.Contains(streamed, "[sh] + found /admin\n", "lines are prefixed and sanitized")
+	s.NotContains(streamed, "s3cr3t", "lines are redacted")
+
+	s.Eventually(func() bool {
+		stored, err := store.GetToolExecution(context.Background(), running.ID)
+		return err == nil && stored.Status == models.StatusCompleted && stored.StreamFile == ""
+	}, time.Second, 10*time.Millisecond, "the stream is cleared once the output is stored")
+	_, err = os.Stat(running.StreamFile)
+	s.True(os.IsNotExist(err), "the stream file is removed")
+}
+
+func (s *StreamTestSuite) TestCombinedOutput_NoArtifactDir() {
+	store, cleanup := setupTestStorage(s.T())
+	defer cleanup()
+	scanner := NewBaseScanner("sh", "shell", zerolog.Nop())
+
+	handler := func(ctx context.Context, _ *mcp.CallToolRequest, _ testInput) (*mcp.CallToolResult, any, error) {
+		output, err := scanner.CombinedOutput(ctx, exec.Command("sh", "-c", "echo done"))
+		s.Require().NoError(err)
+		s.Equal("done\n", string(output))
+		s.Nil(ctx.Value(executionKey{}).(*execution).stream, "nothing is streamed without an artifact directory")
+
+		return &mcp.CallToolResult{}, nil, nil
+	}
+
+	wrapped := WrapToolHandler(store, "test-tool", handler)
+	_, _, err := wrapped(context.Background(), &mcp.CallToolRequest{}, testInput{Host: "localhost", Port: 80})
+	s.Require().NoError(err)
+}
+
+func TestStreamTestSuite(t *testing.T) {
+	suite.Run(t, new(StreamTestSuite))
+}
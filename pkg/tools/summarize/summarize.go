@@ -0,0 +1,134 @@
+package summarize
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+	"github.com/tb0hdan/wass-mcp/pkg/artifacts"
+	"github.com/tb0hdan/wass-mcp/pkg/findings"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/server"
+	"github.com/tb0hdan/wass-mcp/pkg/storage"
+	"github.com/tb0hdan/wass-mcp/pkg/suppress"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+)
+
+const toolName = "summarize"
+
+type Input struct {
+	ID  uint `json:"id" validate:"required"`
+	Top int  `json:"top,omitempty" validate:"min=0,max=100"`
+}
+
+// Result is the summarize tool response.
+type Result struct {
+	findings.Summary
+
+	CreatedAt   time.Time `json:"created_at"`
+	ExecutionID uint      `json:"execution_id"`
+	Success     bool      `json:"success"`
+	ToolName    string    `json:"tool_name"`
+}
+
+type Tool struct {
+	logger    zerolog.Logger
+	validator *validator.Validate
+	store     storage.Storage
+	// suppressions are the configured suppression rules, set on registration.
+	suppressions []models.SuppressionRule
+}
+
+func (t *Tool) Register(srv *server.Server) error {
+	tool := &mcp.Tool{
+		Name: toolName,
+		Description: "Produces a compact summary of a stored execution by ID: severity counts, top findings " +
+			"ranked by risk with their evidence, triage status and EPSS/KEV exploit intelligence, affected URLs " +
+			"and scanner disagreements, without returning the full report.",
+	}
+
+	t.store = srv.Storage()
+	t.suppressions = srv.SuppressionRules()
+
+	mcp.AddTool(&srv.Server, tool, t.SummarizeHandler)
+	t.logger.Debug().Msgf("%s tool registered", toolName)
+
+	return nil
+}
+
+func (t *Tool) SummarizeHandler(ctx context.Context, _ *mcp.CallToolRequest, input Input) (*mcp.CallToolResult, any, error) {
+	if err := t.validator.Struct(input); err != nil {
+		return nil, nil, fmt.Errorf("validation error: %w", err)
+	}
+
+	exec, err := t.store.GetToolExecution(ctx, input.ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("execution not found: %w", err)
+	}
+
+	sections := findings.Sections(exec.ToolName, executionText(exec))
+	if !exec.Success && exec.ToolName != findings.FullScanTool {
+		for i := range sections {
+			sections[i].Status = findings.StatusFailed
+		}
+	}
+	matcher, err := suppress.Load(ctx, t.store, t.suppressions)
+	if err != nil {
+		return nil, nil, err
+	}
+	stored, _ := t.store.GetFindingsByExecutions(ctx, []uint{exec.ID})
+	summary := findings.Summarize(sections, input.Top, stored, matcher)
+	result := Result{
+		Summary:     summary,
+		CreatedAt:   exec.CreatedAt,
+		ExecutionID: exec.ID,
+		Success:     exec.Success,
+		ToolName:    exec.ToolName,
+	}
+
+	data, _ := json.MarshalIndent(result, "", "  ")
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: string(data)},
+		},
+	}, nil, nil
+}
+
+// executionText returns the most complete text available for an execution: the raw output
+// when captured, otherwise the text content of the stored result (read from its artifact file when
+// spilled), otherwise the error message.
+func executionText(exec *models.ToolExecution) string {
+	if exec.RawOutput != "" {
+		return exec.RawOutput
+	}
+
+	if output, _ := artifacts.LoadOutput(exec); output != "" {
+		var result struct {
+			Content []struct {
+				Text string `json:"text"`
+			} `json:"content"`
+		}
+		if err := json.Unmarshal([]byte(output), &result); err == nil {
+			texts := make([]string, 0, len(result.Content))
+			for _, content := range result.Content {
+				texts = append(texts, content.Text)
+			}
+			return strings.Join(texts, "\n")
+		}
+	}
+
+	return exec.ErrorMessage
+}
+
+func New(logger zerolog.Logger) tools.Tool {
+	return &Tool{
+		logger:    logger.With().Str("tool", toolName).Logger(),
+		validator: validator.New(),
+	}
+}
@@ -0,0 +1,159 @@
+package summarize
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/artifacts"
+	"github.com/tb0hdan/wass-mcp/pkg/findings"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/storage"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+	"github.com/tb0hdan/wass-mcp/pkg/tools/nikto"
+	"github.com/tb0hdan/wass-mcp/pkg/tools/nuclei"
+	"github.com/tb0hdan/wass-mcp/pkg/types"
+)
+
+type SummarizeTestSuite struct {
+	suite.Suite
+	store storage.Storage
+	tool  *Tool
+}
+
+func (s *SummarizeTestSuite) SetupSuite() {
+	tools.RegisterFindingsParsers(nikto.New(zerolog.Nop()), nuclei.New(zerolog.Nop()))
+}
+
+func (s *SummarizeTestSuite) SetupTest() {
+	tmpFile, err := os.CreateTemp("", "summarize-test-*.db")
+	s.Require().NoError(err)
+	s.Require().NoError(tmpFile.Close())
+	s.T().Cleanup(func() { _ = os.Remove(tmpFile.Name()) })
+
+	store, err := storage.NewSQLiteStorage(storage.Config{DatabasePath: tmpFile.Name()})
+	s.Require().NoError(err)
+	s.T().Cleanup(func() { _ = store.Close() })
+
+	s.store = store
+	s.tool = New(zerolog.Nop()).(*Tool)
+	s.tool.store = store
+}
+
+func (s *SummarizeTestSuite) call(input Input) Result {
+	result, _, err := s.tool.SummarizeHandler(context.Background(), nil, input)
+	s.Require().NoError(err)
+
+	var response Result
+	s.Require().NoError(json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &response))
+
+	return response
+}
+
+func (s *SummarizeTestSuite) TestValidation_MissingID() {
+	_, _, err := s.tool.SummarizeHandler(context.Background(), nil, Input{})
+	s.Error(err)
+	s.Contains(err.Error(), "validation error")
+}
+
+func (s *SummarizeTestSuite) TestNotFound() {
+	_, _, err := s.tool.SummarizeHandler(context.Background(), nil, Input{ID: 999})
+	s.Error(err)
+	s.Contains(err.Error(), "execution not found")
+}
+
+func (s *SummarizeTestSuite) TestRawOutput() {
+	exec := &models.ToolExecution{
+		ToolName:  "nuclei",
+		Success:   true,
+		RawOutput: `{"template-id":"exposed-git","info":{"name":"Exposed Git","severity":"high"},"matched-at":"http://example.com/.git/config"}`,
+	}
+	s.Require().NoError(s.store.CreateToolExecution(context.Background(), exec))
+
+	response := s.call(Input{ID: exec.ID})
+	s.Equal(exec.ID, response.ExecutionID)
+	s.Equal("nuclei", response.ToolName)
+	s.Equal(1, response.TotalFindings)
+	s.Equal(1, response.SeverityCounts[types.SeverityHigh])
+	s.Equal("Exposed Git", response.TopFindings[0].Title)
+	s.Equal([]string{"http://example.com/.git/config"}, response.AffectedURLs)
+}
+
+func (s *SummarizeTestSuite) TestMergesStoredFindings() {
+	exec := &models.ToolExecution{
+		ToolName: "nuclei",
+		Success:  true,
+		RawOutput: `{"template-id":"exposed-git","info":{"name":"Exposed Git","severity":"high"},` +
+			`"matched-at":"http://example.com/.git/config","request":"GET /.git/config HTTP/1.1"}`,
+	}
+	s.Require().NoError(s.store.CreateToolExecution(context.Background(), exec))
+
+	stored := findings.ExtractAll(exec.ToolName, exec.RawOutput)
+	s.Require().Len(stored, 1)
+	stored[0].ExecutionID = exec.ID
+	stored[0].Assignee = "alice"
+	stored[0].Status = models.FindingInProgress
+	stored[0].Evidence = append(stored[0].Evidence, models.Evidence{
+		Kind:      models.EvidenceArtifact,
+		Reference: "/srv/artifacts/git-config.txt",
+		Source:    models.EvidenceSourceManual,
+	})
+	s.Require().NoError(s.store.CreateFindings(context.Background(), stored))
+
+	response := s.call(Input{ID: exec.ID})
+	s.Require().Len(response.TopFindings, 1)
+	top := response.TopFindings[0]
+	s.Equal(stored[0].ID, top.ID)
+	s.Equal("alice", top.Assignee)
+	s.Equal(models.FindingInProgress, top.Status)
+	s.Require().Len(top.Evidence, 2)
+	s.Equal("GET /.git/config HTTP/1.1", top.Evidence[0].Content)
+	s.Equal("/srv/artifacts/git-config.txt", top.Evidence[1].Reference)
+}
+
+func (s *SummarizeTestSuite) TestFallsBackToOutputJSON() {
+	output, err := json.Marshal(&mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: "nikto output for http://example.com:\n\n+ /admin/: Admin area."}},
+	})
+	s.Require().NoError(err)
+
+	exec := &models.ToolExecution{ToolName: "nikto", Success: true, OutputJSON: string(output)}
+	s.Require().NoError(s.store.CreateToolExecution(context.Background(), exec))
+
+	response := s.call(Input{ID: exec.ID})
+	s.Equal(1, response.TotalFindings)
+	s.Equal("/admin/", response.TopFindings[0].URL)
+}
+
+func (s *SummarizeTestSuite) TestReadsSpilledOutput() {
+	output, err := json.Marshal(&mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: "+ /admin/: Admin area.\n+ /backup/: Backup directory."}},
+	})
+	s.Require().NoError(err)
+
+	exec := &models.ToolExecution{ToolName: "nikto", Success: true, OutputJSON: string(output)}
+	s.Require().NoError(artifacts.SpillOutput(artifacts.Config{Dir: s.T().TempDir(), MaxOutputBytes: 10}, exec))
+	s.Require().NotEmpty(exec.OutputFile)
+	s.Require().NoError(s.store.CreateToolExecution(context.Background(), exec))
+
+	response := s.call(Input{ID: exec.ID})
+	s.Equal(2, response.TotalFindings)
+}
+
+func (s *SummarizeTestSuite) TestFailedExecution() {
+	exec := &models.ToolExecution{ToolName: "nikto", Success: false, ErrorMessage: "failed to execute nikto"}
+	s.Require().NoError(s.store.CreateToolExecution(context.Background(), exec))
+
+	response := s.call(Input{ID: exec.ID})
+	s.False(response.Success)
+	s.Require().Len(response.Scanners, 1)
+	s.Equal(findings.StatusFailed, response.Scanners[0].Status)
+}
+
+func TestSummarizeTestSuite(t *testing.T) {
+	suite.Run(t, new(SummarizeTestSuite))
+}
@@ -0,0 +1,143 @@
+package suppressions
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/server"
+	"github.com/tb0hdan/wass-mcp/pkg/storage"
+	"github.com/tb0hdan/wass-mcp/pkg/suppress"
+	"github.com/tb0hdan/wass-mcp/pkg/tenant"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+	"gorm.io/gorm"
+)
+
+const toolName = "suppressions"
+
+type Input struct {
+	Action       string `json:"action" validate:"required,oneof=list get set delete"`
+	Name         string `json:"name,omitempty" validate:"omitempty,max=64"`
+	Reason       string `json:"reason,omitempty" validate:"max=1024"`
+	Scanner      string `json:"scanner,omitempty" validate:"max=255"`
+	TemplateID   string `json:"template_id,omitempty" validate:"max=255"`
+	TitlePattern string `json:"title_pattern,omitempty" validate:"max=1024"`
+	URLPattern   string `json:"url_pattern,omitempty" validate:"max=1024"`
+}
+
+// modifyingActions are the actions that change stored rules, refused to read-only keys.
+var modifyingActions = map[string]struct{}{
+	"delete": {},
+	"set":    {},
+}
+
+type Tool struct {
+	configured []models.SuppressionRule
+	logger     zerolog.Logger
+	store      storage.Storage
+	validator  *validator.Validate
+}
+
+func (t *Tool) Register(srv *server.Server) error {
+	tool := &mcp.Tool{
+		Name: toolName,
+		Description: "Manage finding suppression rules that drop accepted noise (e.g. a missing header on a health " +
+			"endpoint) when scanner output is parsed, before findings are stored, scored or summarized. A rule " +
+			"matches a finding when all of its set criteria do: scanner and template_id exactly, url_pattern and " +
+			"title_pattern as regular expressions. Actions: list (stored and server-configured rules), get (by " +
+			"name), set (create or replace name), delete (by name). Suppressed findings are counted in the " +
+			"suppressed field of executions and summaries.",
+	}
+
+	t.store = srv.Storage()
+	t.configured = srv.SuppressionRules()
+
+	mcp.AddTool(&srv.Server, tool, t.Handler)
+	t.logger.Debug().Msgf("%s tool registered", toolName)
+
+	return nil
+}
+
+func (t *Tool) Handler(ctx context.Context, _ *mcp.CallToolRequest, input Input) (*mcp.CallToolResult, any, error) {
+	if err := t.validator.Struct(input); err != nil {
+		return nil, nil, fmt.Errorf("validation error: %w", err)
+	}
+	if input.Action != "list" && input.Name == "" {
+		return nil, nil, fmt.Errorf("name is required for %s action", input.Action)
+	}
+	if _, modifies := modifyingActions[input.Action]; modifies {
+		if err := tenant.Authorize(ctx, input.Action+" suppression rules"); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var result any
+
+	switch input.Action {
+	case "list":
+		rules, err := t.store.ListSuppressionRules(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to list suppression rules: %w", err)
+		}
+		result = map[string]any{
+			"total":      len(rules),
+			"rules":      rules,
+			"configured": t.configured,
+		}
+
+	case "get":
+		rule, err := t.store.GetSuppressionRule(ctx, input.Name)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, fmt.Errorf("suppression rule %s not found", input.Name)
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load suppression rule %s: %w", input.Name, err)
+		}
+		result = rule
+
+	case "set":
+		rule := &models.SuppressionRule{
+			Name:         input.Name,
+			Reason:       input.Reason,
+			Scanner:      input.Scanner,
+			TemplateID:   input.TemplateID,
+			TitlePattern: input.TitlePattern,
+			URLPattern:   input.URLPattern,
+		}
+		if err := suppress.Validate(*rule); err != nil {
+			return nil, nil, fmt.Errorf("validation error: %w", err)
+		}
+		if err := t.store.SaveSuppressionRule(ctx, rule); err != nil {
+			return nil, nil, fmt.Errorf("failed to save suppression rule %s: %w", rule.Name, err)
+		}
+		t.logger.Debug().Msgf("Suppression rule %s saved", rule.Name)
+		result = rule
+
+	case "delete":
+		if err := t.store.DeleteSuppressionRule(ctx, input.Name); err != nil {
+			return nil, nil, fmt.Errorf("failed to delete suppression rule %s: %w", input.Name, err)
+		}
+		result = map[string]any{"deleted": input.Name}
+	}
+
+	data, _ := json.MarshalIndent(result, "", "  ")
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: string(data)},
+		},
+	}, nil, nil
+}
+
+// New creates a new suppressions tool.
+func New(logger zerolog.Logger) tools.Tool {
+	return &Tool{
+		logger:    logger.With().Str("tool", toolName).Logger(),
+		validator: validator.New(),
+	}
+}
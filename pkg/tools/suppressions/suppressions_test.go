@@ -0,0 +1,130 @@
+package suppressions
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/server"
+	"github.com/tb0hdan/wass-mcp/pkg/storage"
+	"github.com/tb0hdan/wass-mcp/pkg/suppress"
+	"github.com/tb0hdan/wass-mcp/pkg/tenant"
+)
+
+type SuppressionsTestSuite struct {
+	suite.Suite
+	cleanup func()
+	tool    *Tool
+}
+
+func (s *SuppressionsTestSuite) SetupTest() {
+	tmpFile, err := os.CreateTemp("", "suppressions-*.db")
+	s.Require().NoError(err)
+	tmpFile.Close()
+
+	store, err := storage.NewSQLiteStorage(storage.Config{DatabasePath: tmpFile.Name()})
+	s.Require().NoError(err)
+	srv := server.NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}, store)
+	srv.SetSuppressionRules([]models.SuppressionRule{{Name: "health", URLPattern: `/health$`}})
+	s.cleanup = func() {
+		srv.Shutdown(context.Background())
+		os.Remove(tmpFile.Name())
+	}
+
+	s.tool = New(zerolog.Nop()).(*Tool)
+	s.Require().NoError(s.tool.Register(srv))
+}
+
+func (s *SuppressionsTestSuite) TearDownTest() {
+	s.cleanup()
+}
+
+// call runs the handler and decodes its JSON response into out.
+func (s *SuppressionsTestSuite) call(ctx context.Context, input Input, out any) error {
+	result, _, err := s.tool.Handler(ctx, &mcp.CallToolRequest{}, input)
+	if err != nil {
+		return err
+	}
+	s.Require().NoError(json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), out))
+
+	return nil
+}
+
+func (s *SuppressionsTestSuite) TestLifecycle() {
+	ctx := context.Background()
+
+	var rule models.SuppressionRule
+	s.Require().NoError(s.call(ctx, Input{
+		Action:       "set",
+		Name:         "health-headers",
+		Reason:       "Load balancer probe",
+		Scanner:      "shcheck",
+		TitlePattern: "(?i)missing",
+	}, &rule))
+	s.Equal("shcheck", rule.Scanner)
+
+	s.Require().NoError(s.call(ctx, Input{Action: "set", Name: "health-headers", URLPattern: `/healthz$`}, &rule))
+	var replaced models.SuppressionRule
+	s.Require().NoError(s.call(ctx, Input{Action: "get", Name: "health-headers"}, &replaced))
+	s.Empty(replaced.Scanner)
+	s.Equal(`/healthz$`, replaced.URLPattern)
+
+	var list struct {
+		Configured []models.SuppressionRule `json:"configured"`
+		Rules      []models.SuppressionRule `json:"rules"`
+		Total      int                      `json:"total"`
+	}
+	s.Require().NoError(s.call(ctx, Input{Action: "list"}, &list))
+	s.Equal(1, list.Total)
+	s.Equal("health-headers", list.Rules[0].Name)
+	s.Require().Len(list.Configured, 1)
+	s.Equal("health", list.Configured[0].Name)
+
+	var deleted map[string]string
+	s.Require().NoError(s.call(ctx, Input{Action: "delete", Name: "health-headers"}, &deleted))
+	s.Equal("health-headers", deleted["deleted"])
+	s.ErrorContains(s.call(ctx, Input{Action: "get", Name: "health-headers"}, &rule), "suppression rule health-headers not found")
+	s.Error(s.call(ctx, Input{Action: "delete", Name: "health-headers"}, &deleted))
+}
+
+func (s *SuppressionsTestSuite) TestValidation() {
+	ctx := context.Background()
+	var rule models.SuppressionRule
+
+	s.ErrorContains(s.call(ctx, Input{Action: "get"}, &rule), "name is required for get action")
+	s.ErrorContains(s.call(ctx, Input{Action: "rename", Name: "noise"}, &rule), "validation error")
+	s.ErrorIs(s.call(ctx, Input{Action: "set", Name: "noise"}, &rule), suppress.ErrNoCriteria)
+	s.ErrorContains(s.call(ctx, Input{Action: "set", Name: "noise", TitlePattern: "("}, &rule), "invalid title_pattern")
+}
+
+func (s *SuppressionsTestSuite) TestReadOnly() {
+	readOnly := tenant.WithRole(context.Background(), tenant.RoleReadOnly)
+	var rule models.SuppressionRule
+
+	err := s.call(readOnly, Input{Action: "set", Name: "noise", Scanner: "nikto"}, &rule)
+	var wireErr *jsonrpc.Error
+	s.Require().ErrorAs(err, &wireErr)
+	s.EqualValues(tenant.CodeForbidden, wireErr.Code)
+
+	var list map[string]any
+	s.NoError(s.call(readOnly, Input{Action: "list"}, &list))
+}
+
+func (s *SuppressionsTestSuite) TestTenantScoping() {
+	alpha := tenant.WithTenant(context.Background(), "alpha")
+	beta := tenant.WithTenant(context.Background(), "beta")
+	var rule models.SuppressionRule
+
+	s.Require().NoError(s.call(alpha, Input{Action: "set", Name: "noise", Scanner: "nikto"}, &rule))
+	s.ErrorContains(s.call(beta, Input{Action: "get", Name: "noise"}, &rule), "not found")
+}
+
+func TestSuppressionsTestSuite(t *testing.T) {
+	suite.Run(t, new(SuppressionsTestSuite))
+}
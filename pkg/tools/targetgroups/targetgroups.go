@@ -0,0 +1,205 @@
+package targetgroups
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/server"
+	"github.com/tb0hdan/wass-mcp/pkg/storage"
+	"github.com/tb0hdan/wass-mcp/pkg/tenant"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+	"gorm.io/gorm"
+)
+
+const (
+	toolName = "target_groups"
+	// maxHosts bounds the number of targets of a group.
+	maxHosts = 256
+)
+
+// ErrEmptyGroup is returned when an action would leave a group without targets.
+var ErrEmptyGroup = errors.New("a target group needs at least one host")
+
+type Input struct {
+	Action      string   `json:"action" validate:"required,oneof=list get set add remove delete"`
+	Description string   `json:"description,omitempty" validate:"max=1024"`
+	Hosts       []string `json:"hosts,omitempty" validate:"omitempty,max=256,dive,required,max=2048"`
+	Name        string   `json:"name,omitempty" validate:"omitempty,max=64"`
+}
+
+// modifyingActions are the actions that change stored groups, refused to read-only keys.
+var modifyingActions = map[string]struct{}{
+	"add":    {},
+	"delete": {},
+	"remove": {},
+	"set":    {},
+}
+
+type Tool struct {
+	logger    zerolog.Logger
+	store     storage.Storage
+	validator *validator.Validate
+}
+
+func (t *Tool) Register(srv *server.Server) error {
+	tool := &mcp.Tool{
+		Name: toolName,
+		Description: "Manage named groups of scan targets (e.g. staging-cluster) that full_scan scans together with " +
+			"its group parameter. Actions: list, get (by name), set (create or replace name with hosts and " +
+			"description), add (hosts to name), remove (hosts from name), delete (by name). Hosts are hostnames, " +
+			"IPs or URLs.",
+	}
+
+	t.store = srv.Storage()
+
+	mcp.AddTool(&srv.Server, tool, t.Handler)
+	t.logger.Debug().Msgf("%s tool registered", toolName)
+
+	return nil
+}
+
+func (t *Tool) Handler(ctx context.Context, _ *mcp.CallToolRequest, input Input) (*mcp.CallToolResult, any, error) {
+	if err := t.validator.Struct(input); err != nil {
+		return nil, nil, fmt.Errorf("validation error: %w", err)
+	}
+	if input.Action != "list" && input.Name == "" {
+		return nil, nil, fmt.Errorf("name is required for %s action", input.Action)
+	}
+	if _, modifies := modifyingActions[input.Action]; modifies {
+		if err := tenant.Authorize(ctx, input.Action+" target groups"); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := t.validateHosts(input.Hosts); err != nil {
+		return nil, nil, err
+	}
+
+	var result any
+
+	switch input.Action {
+	case "list":
+		groups, err := t.store.ListTargetGroups(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to list target groups: %w", err)
+		}
+		result = map[string]any{
+			"total":  len(groups),
+			"groups": groups,
+		}
+
+	case "get":
+		group, err := t.load(ctx, input.Name)
+		if err != nil {
+			return nil, nil, err
+		}
+		result = group
+
+	case "set":
+		group := &models.TargetGroup{Name: input.Name, Description: input.Description, Hosts: uniqueHosts(nil, input.Hosts)}
+		if err := t.save(ctx, group); err != nil {
+			return nil, nil, err
+		}
+		result = group
+
+	case "add", "remove":
+		group, err := t.load(ctx, input.Name)
+		if err != nil {
+			return nil, nil, err
+		}
+		if input.Action == "add" {
+			group.Hosts = uniqueHosts(group.Hosts, input.Hosts)
+		} else {
+			group.Hosts = slices.DeleteFunc(group.Hosts, func(host string) bool {
+				return slices.Contains(input.Hosts, host)
+			})
+		}
+		if input.Description != "" {
+			group.Description = input.Description
+		}
+		if err := t.save(ctx, group); err != nil {
+			return nil, nil, err
+		}
+		result = group
+
+	case "delete":
+		if err := t.store.DeleteTargetGroup(ctx, input.Name); err != nil {
+			return nil, nil, fmt.Errorf("failed to delete target group %s: %w", input.Name, err)
+		}
+		result = map[string]any{"deleted": input.Name}
+	}
+
+	data, _ := json.MarshalIndent(result, "", "  ")
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: string(data)},
+		},
+	}, nil, nil
+}
+
+// validateHosts validates each host like the host of a scanner input.
+func (t *Tool) validateHosts(hosts []string) error {
+	for _, host := range hosts {
+		input := tools.PrepareScannerInput(tools.ScannerInput{Host: host})
+		if err := t.validator.Struct(input); err != nil {
+			return fmt.Errorf("validation error: host %q: %w", host, err)
+		}
+	}
+
+	return nil
+}
+
+// load returns the group named name.
+func (t *Tool) load(ctx context.Context, name string) (*models.TargetGroup, error) {
+	group, err := t.store.GetTargetGroup(ctx, name)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("target group %s not found", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load target group %s: %w", name, err)
+	}
+
+	return group, nil
+}
+
+// save stores group, refusing to leave it without targets or above the host limit.
+func (t *Tool) save(ctx context.Context, group *models.TargetGroup) error {
+	if len(group.Hosts) == 0 {
+		return ErrEmptyGroup
+	}
+	if len(group.Hosts) > maxHosts {
+		return fmt.Errorf("validation error: a target group holds at most %d hosts, got %d", maxHosts, len(group.Hosts))
+	}
+	if err := t.store.SaveTargetGroup(ctx, group); err != nil {
+		return fmt.Errorf("failed to save target group %s: %w", group.Name, err)
+	}
+	t.logger.Debug().Msgf("Target group %s saved with %d hosts", group.Name, len(group.Hosts))
+
+	return nil
+}
+
+// uniqueHosts appends the hosts not yet in hosts, keeping their order.
+func uniqueHosts(hosts, added []string) []string {
+	for _, host := range added {
+		if !slices.Contains(hosts, host) {
+			hosts = append(hosts, host)
+		}
+	}
+
+	return hosts
+}
+
+// New creates a new target_groups tool.
+func New(logger zerolog.Logger) tools.Tool {
+	return &Tool{
+		logger:    logger.With().Str("tool", toolName).Logger(),
+		validator: validator.New(),
+	}
+}
@@ -0,0 +1,133 @@
+package targetgroups
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/server"
+	"github.com/tb0hdan/wass-mcp/pkg/storage"
+	"github.com/tb0hdan/wass-mcp/pkg/tenant"
+)
+
+type TargetGroupsTestSuite struct {
+	suite.Suite
+	cleanup func()
+	tool    *Tool
+}
+
+func (s *TargetGroupsTestSuite) SetupTest() {
+	tmpFile, err := os.CreateTemp("", "targetgroups-*.db")
+	s.Require().NoError(err)
+	tmpFile.Close()
+
+	store, err := storage.NewSQLiteStorage(storage.Config{DatabasePath: tmpFile.Name()})
+	s.Require().NoError(err)
+	srv := server.NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}, store)
+	s.cleanup = func() {
+		srv.Shutdown(context.Background())
+		os.Remove(tmpFile.Name())
+	}
+
+	s.tool = New(zerolog.Nop()).(*Tool)
+	s.Require().NoError(s.tool.Register(srv))
+}
+
+func (s *TargetGroupsTestSuite) TearDownTest() {
+	s.cleanup()
+}
+
+// call runs the handler and decodes its JSON response into out.
+func (s *TargetGroupsTestSuite) call(ctx context.Context, input Input, out any) error {
+	result, _, err := s.tool.Handler(ctx, &mcp.CallToolRequest{}, input)
+	if err != nil {
+		return err
+	}
+	s.Require().NoError(json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), out))
+
+	return nil
+}
+
+func (s *TargetGroupsTestSuite) TestLifecycle() {
+	ctx := context.Background()
+
+	var group models.TargetGroup
+	s.Require().NoError(s.call(ctx, Input{
+		Action:      "set",
+		Description: "staging cluster",
+		Hosts:       []string{"a.example.com", "https://b.example.com:8443", "a.example.com"},
+		Name:        "staging",
+	}, &group))
+	s.Equal([]string{"a.example.com", "https://b.example.com:8443"}, group.Hosts)
+
+	s.Require().NoError(s.call(ctx, Input{Action: "add", Hosts: []string{"10.0.0.5", "a.example.com"}, Name: "staging"}, &group))
+	s.Equal([]string{"a.example.com", "https://b.example.com:8443", "10.0.0.5"}, group.Hosts)
+
+	s.Require().NoError(s.call(ctx, Input{Action: "remove", Hosts: []string{"a.example.com"}, Name: "staging"}, &group))
+	s.Equal([]string{"https://b.example.com:8443", "10.0.0.5"}, group.Hosts)
+	s.Equal("staging cluster", group.Description)
+
+	s.Require().NoError(s.call(ctx, Input{Action: "get", Name: "staging"}, &group))
+	s.Equal([]string{"https://b.example.com:8443", "10.0.0.5"}, group.Hosts)
+
+	var list struct {
+		Groups []models.TargetGroup `json:"groups"`
+		Total  int                  `json:"total"`
+	}
+	s.Require().NoError(s.call(ctx, Input{Action: "list"}, &list))
+	s.Equal(1, list.Total)
+	s.Equal("staging", list.Groups[0].Name)
+
+	var deleted map[string]string
+	s.Require().NoError(s.call(ctx, Input{Action: "delete", Name: "staging"}, &deleted))
+	s.Equal("staging", deleted["deleted"])
+	s.ErrorContains(s.call(ctx, Input{Action: "get", Name: "staging"}, &group), "target group staging not found")
+}
+
+func (s *TargetGroupsTestSuite) TestValidation() {
+	ctx := context.Background()
+	var group models.TargetGroup
+
+	s.ErrorContains(s.call(ctx, Input{Action: "get"}, &group), "name is required for get action")
+	s.ErrorContains(s.call(ctx, Input{Action: "rename", Name: "staging"}, &group), "validation error")
+	s.ErrorContains(s.call(ctx, Input{Action: "set", Hosts: []string{"not a host"}, Name: "staging"}, &group),
+		`validation error: host "not a host"`)
+	s.ErrorIs(s.call(ctx, Input{Action: "set", Name: "staging"}, &group), ErrEmptyGroup)
+
+	s.Require().NoError(s.call(ctx, Input{Action: "set", Hosts: []string{"a.example.com"}, Name: "staging"}, &group))
+	s.ErrorIs(s.call(ctx, Input{Action: "remove", Hosts: []string{"a.example.com"}, Name: "staging"}, &group), ErrEmptyGroup)
+	s.ErrorContains(s.call(ctx, Input{Action: "add", Hosts: []string{"b.example.com"}, Name: "missing"}, &group),
+		"target group missing not found")
+}
+
+func (s *TargetGroupsTestSuite) TestReadOnly() {
+	readOnly := tenant.WithRole(context.Background(), tenant.RoleReadOnly)
+	var group models.TargetGroup
+
+	err := s.call(readOnly, Input{Action: "set", Hosts: []string{"a.example.com"}, Name: "staging"}, &group)
+	var wireErr *jsonrpc.Error
+	s.Require().ErrorAs(err, &wireErr)
+	s.EqualValues(tenant.CodeForbidden, wireErr.Code)
+
+	var list map[string]any
+	s.NoError(s.call(readOnly, Input{Action: "list"}, &list))
+}
+
+func (s *TargetGroupsTestSuite) TestTenantScoping() {
+	alpha := tenant.WithTenant(context.Background(), "alpha")
+	beta := tenant.WithTenant(context.Background(), "beta")
+	var group models.TargetGroup
+
+	s.Require().NoError(s.call(alpha, Input{Action: "set", Hosts: []string{"a.example.com"}, Name: "staging"}, &group))
+	s.ErrorContains(s.call(beta, Input{Action: "get", Name: "staging"}, &group), "not found")
+}
+
+func TestTargetGroupsTestSuite(t *testing.T) {
+	suite.Run(t, new(TargetGroupsTestSuite))
+}
@@ -0,0 +1,84 @@
+package targetprofile
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/server"
+	"github.com/tb0hdan/wass-mcp/pkg/storage"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+)
+
+const toolName = "target_profile"
+
+// Input names the host whose profiles are returned, every profiled target without it.
+type Input struct {
+	Host string `json:"host,omitempty" validate:"omitempty,hostname_rfc1123|ip"`
+}
+
+// Result is the target_profile tool response.
+type Result struct {
+	Host     string                 `json:"host,omitempty"`
+	Profiles []models.TargetProfile `json:"profiles"`
+}
+
+type Tool struct {
+	logger    zerolog.Logger
+	validator *validator.Validate
+	store     storage.Storage
+}
+
+func (t *Tool) Register(srv *server.Server) error {
+	tool := &mcp.Tool{
+		Name: toolName,
+		Description: "Returns the technology profiles of scanned targets: the web server, CMS, frameworks, JavaScript " +
+			"libraries and languages detected by the last whatweb scan of each target, with their versions. Use it to " +
+			"pick technology scanners and templates before scanning. Optionally filtered by host.",
+	}
+
+	t.store = srv.Storage()
+
+	mcp.AddTool(&srv.Server, tool, t.Handler)
+	t.logger.Debug().Msgf("%s tool registered", toolName)
+
+	return nil
+}
+
+func (t *Tool) Handler(ctx context.Context, _ *mcp.CallToolRequest, input Input) (*mcp.CallToolResult, any, error) {
+	// Parse URL-style hosts before validation.
+	if input.Host != "" {
+		input.Host = tools.PrepareScannerInput(tools.ScannerInput{Host: input.Host}).Host
+	}
+	if err := t.validator.Struct(input); err != nil {
+		return nil, nil, fmt.Errorf("validation error: %w", err)
+	}
+
+	profiles, err := t.store.GetTargetProfiles(ctx, input.Host)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get target profiles: %w", err)
+	}
+	if profiles == nil {
+		profiles = []models.TargetProfile{}
+	}
+
+	data, _ := json.MarshalIndent(Result{Host: input.Host, Profiles: profiles}, "", "  ")
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: string(data)},
+		},
+	}, nil, nil
+}
+
+// New creates a new target_profile tool.
+func New(logger zerolog.Logger) tools.Tool {
+	return &Tool{
+		logger:    logger.With().Str("tool", toolName).Logger(),
+		validator: validator.New(),
+	}
+}
@@ -0,0 +1,80 @@
+package targetprofile
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/storage"
+)
+
+type TargetProfileTestSuite struct {
+	suite.Suite
+	store storage.Storage
+	tool  *Tool
+}
+
+func (s *TargetProfileTestSuite) SetupTest() {
+	tmpFile, err := os.CreateTemp("", "targetprofile-test-*.db")
+	s.Require().NoError(err)
+	s.Require().NoError(tmpFile.Close())
+	s.T().Cleanup(func() { _ = os.Remove(tmpFile.Name()) })
+
+	store, err := storage.NewSQLiteStorage(storage.Config{DatabasePath: tmpFile.Name()})
+	s.Require().NoError(err)
+	s.T().Cleanup(func() { _ = store.Close() })
+
+	s.store = store
+	s.tool = New(zerolog.Nop()).(*Tool)
+	s.tool.store = store
+}
+
+func (s *TargetProfileTestSuite) call(input Input) Result {
+	result, _, err := s.tool.Handler(context.Background(), nil, input)
+	s.Require().NoError(err)
+
+	var response Result
+	s.Require().NoError(json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &response))
+
+	return response
+}
+
+func (s *TargetProfileTestSuite) TestProfiles() {
+	for _, profile := range []models.TargetProfile{
+		{Target: "https://shop.example.com", Host: "shop.example.com", Port: 443, Scanner: "whatweb",
+			Technologies: []models.Technology{{Category: models.TechnologyCMS, Name: "wordpress", Version: "6.4.2"}}},
+		{Target: "http://10.0.0.1", Host: "10.0.0.1", Port: 80, Scanner: "whatweb",
+			Technologies: []models.Technology{{Category: models.TechnologyServer, Name: "nginx"}}},
+	} {
+		s.Require().NoError(s.store.SaveTargetProfile(context.Background(), &profile))
+	}
+
+	response := s.call(Input{})
+	s.Len(response.Profiles, 2)
+
+	response = s.call(Input{Host: "https://shop.example.com/cart"})
+	s.Equal("shop.example.com", response.Host, "URL-style hosts are accepted")
+	s.Require().Len(response.Profiles, 1)
+	s.Equal("wordpress", response.Profiles[0].Technologies[0].Name)
+	s.Equal("6.4.2", response.Profiles[0].Technologies[0].Version)
+}
+
+func (s *TargetProfileTestSuite) TestNoProfiles() {
+	response := s.call(Input{Host: "example.com"})
+	s.NotNil(response.Profiles)
+	s.Empty(response.Profiles)
+}
+
+func (s *TargetProfileTestSuite) TestValidation_InvalidHost() {
+	_, _, err := s.tool.Handler(context.Background(), nil, Input{Host: "invalid host!!!"})
+	s.ErrorContains(err, "validation error")
+}
+
+func TestTargetProfileTestSuite(t *testing.T) {
+	suite.Run(t, new(TargetProfileTestSuite))
+}
@@ -0,0 +1,336 @@
+package targetstatus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+	"github.com/tb0hdan/wass-mcp/pkg/findings"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/server"
+	"github.com/tb0hdan/wass-mcp/pkg/storage"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+	"gorm.io/gorm"
+)
+
+const (
+	toolName = "target_status"
+	// defaultStaleDays is the age of the last scan after which a covered target is due.
+	defaultStaleDays = 30
+	// scanPage is the number of executions read at a time when looking for the last scan.
+	scanPage = 20
+)
+
+// Schedule statuses of a target.
+const (
+	// ScheduleUnscheduled marks targets no scan template covers.
+	ScheduleUnscheduled = "unscheduled"
+	// ScheduleDue marks covered targets never scanned or last scanned before the stale age.
+	ScheduleDue = "due"
+	// ScheduleCurrent marks covered targets scanned within the stale age.
+	ScheduleCurrent = "current"
+)
+
+// Input limits the dashboard to a target group and sets the age at which scans are stale.
+type Input struct {
+	Group     string `json:"group,omitempty" validate:"omitempty,max=64"`
+	StaleDays int    `json:"stale_days,omitempty" validate:"min=0,max=365"`
+}
+
+// Schedule is the scan template coverage of a target.
+type Schedule struct {
+	Status    string   `json:"status"`
+	Templates []string `json:"templates"`
+}
+
+// Target is the status of an inventoried target.
+type Target struct {
+	Groups          []string       `json:"groups"`
+	Host            string         `json:"host"`
+	LastExecutionID uint           `json:"last_execution_id,omitempty"`
+	LastRiskScore   float64        `json:"last_risk_score"`
+	LastScanAt      *time.Time     `json:"last_scan_at,omitempty"`
+	LastTool        string         `json:"last_tool,omitempty"`
+	OpenFindings    map[string]int `json:"open_findings"`
+	Schedule        Schedule       `json:"schedule"`
+}
+
+// Summary aggregates the status of the targets.
+type Summary struct {
+	Due          int            `json:"due"`
+	NeverScanned int            `json:"never_scanned"`
+	OpenFindings map[string]int `json:"open_findings"`
+	Targets      int            `json:"targets"`
+	Unscheduled  int            `json:"unscheduled"`
+}
+
+// Result is the target_status tool response.
+type Result struct {
+	Group   string   `json:"group,omitempty"`
+	Summary Summary  `json:"summary"`
+	Targets []Target `json:"targets"`
+}
+
+type Tool struct {
+	logger    zerolog.Logger
+	validator *validator.Validate
+	store     storage.Storage
+}
+
+func (t *Tool) Register(srv *server.Server) error {
+	tool := &mcp.Tool{
+		Name: toolName,
+		Description: "Returns a status dashboard of the inventoried targets, the hosts of target groups and scan templates: " +
+			"per target its last scan time, last risk score, open finding counts by severity and schedule status " +
+			"(unscheduled, due or current, from the scan templates covering it). Optionally limited to a target group.",
+	}
+
+	t.store = srv.Storage()
+
+	mcp.AddTool(&srv.Server, tool, t.Handler)
+	t.logger.Debug().Msgf("%s tool registered", toolName)
+
+	return nil
+}
+
+func (t *Tool) Handler(ctx context.Context, _ *mcp.CallToolRequest, input Input) (*mcp.CallToolResult, any, error) {
+	if err := t.validator.Struct(input); err != nil {
+		return nil, nil, fmt.Errorf("validation error: %w", err)
+	}
+	staleDays := input.StaleDays
+	if staleDays == 0 {
+		staleDays = defaultStaleDays
+	}
+
+	groups, err := t.groups(ctx, input.Group)
+	if err != nil {
+		return nil, nil, err
+	}
+	templates, err := t.store.ListScanTemplates(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list scan templates: %w", err)
+	}
+
+	targets := inventory(groups, templates, input.Group == "")
+	groupScans := make(map[string]*models.ToolExecution, len(groups))
+	for _, group := range groups {
+		// The target filter matches substrings, e.g. the scans of "staging-eu" for "staging".
+		target := tools.GroupTarget(group.Name)
+		groupScans[group.Name], err = t.lastScan(ctx, storage.ExecutionFilter{Target: target}, func(exec models.ToolExecution) bool {
+			return exec.Target == target
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	staleBefore := time.Now().Add(-time.Duration(staleDays) * 24 * time.Hour)
+	result := Result{Group: input.Group, Targets: make([]Target, 0, len(targets))}
+	for _, target := range targets {
+		if err := t.fill(ctx, &target, groupScans); err != nil {
+			return nil, nil, err
+		}
+		target.Schedule.Status = scheduleStatus(target, staleBefore)
+		result.Targets = append(result.Targets, target)
+	}
+	result.Summary = summarize(result.Targets)
+
+	data, _ := json.MarshalIndent(result, "", "  ")
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: string(data)},
+		},
+	}, nil, nil
+}
+
+// groups returns the named target group, every target group of the tenant without a name.
+func (t *Tool) groups(ctx context.Context, name string) ([]models.TargetGroup, error) {
+	if name == "" {
+		groups, err := t.store.ListTargetGroups(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list target groups: %w", err)
+		}
+		return groups, nil
+	}
+
+	group, err := t.store.GetTargetGroup(ctx, name)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("target group %q not found", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get target group: %w", err)
+	}
+
+	return []models.TargetGroup{*group}, nil
+}
+
+// lastScan returns the latest successful execution matching filter and match, nil when there is
+// none.
+func (t *Tool) lastScan(
+	ctx context.Context,
+	filter storage.ExecutionFilter,
+	match func(models.ToolExecution) bool,
+) (*models.ToolExecution, error) {
+	success := true
+	filter.Success = &success
+	filter.Limit = scanPage
+	for {
+		executions, _, err := t.store.QueryToolExecutions(ctx, filter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get executions: %w", err)
+		}
+		for i := range executions {
+			if match(executions[i]) {
+				return &executions[i], nil
+			}
+		}
+		if len(executions) < scanPage {
+			return nil, nil
+		}
+		filter.Offset += scanPage
+	}
+}
+
+// fill sets the last scan of target, the latest of its own scans and the scans of its groups in
+// groupScans, with its risk score and open findings. Group scans are scored on the findings of
+// the target only.
+func (t *Tool) fill(ctx context.Context, target *Target, groupScans map[string]*models.ToolExecution) error {
+	last, err := t.lastScan(ctx, storage.ExecutionFilter{Host: target.Host}, func(models.ToolExecution) bool { return true })
+	if err != nil {
+		return err
+	}
+	grouped := false
+	for _, name := range target.Groups {
+		if scan := groupScans[name]; scan != nil && (last == nil || scan.ID > last.ID) {
+			last, grouped = scan, true
+		}
+	}
+	target.OpenFindings = findings.CountBySeverity(nil)
+	if last == nil {
+		return nil
+	}
+
+	stored, err := t.store.GetFindingsByExecutions(ctx, []uint{last.ID})
+	if err != nil {
+		return fmt.Errorf("failed to get findings: %w", err)
+	}
+	if grouped {
+		stored = slices.DeleteFunc(stored, func(finding models.Finding) bool { return findingHost(finding) != target.Host })
+	}
+	open := slices.DeleteFunc(slices.Clone(stored), func(finding models.Finding) bool {
+		return finding.Status != "" && finding.Status != models.FindingOpen && finding.Status != models.FindingInProgress
+	})
+
+	createdAt := last.CreatedAt
+	target.LastExecutionID = last.ID
+	target.LastScanAt = &createdAt
+	target.LastTool = last.ToolName
+	target.LastRiskScore = last.RiskScore
+	if grouped {
+		target.LastRiskScore = findings.ScoreFindings(stored)
+	}
+	target.OpenFindings = findings.CountBySeverity(open)
+
+	return nil
+}
+
+// inventory returns the inventoried targets, the hosts of groups and, with ungrouped set, of the
+// scan templates naming a host, in the order they are listed. Each target lists its groups and
+// the templates covering it, directly or through one of its groups.
+func inventory(groups []models.TargetGroup, templates []models.ScanTemplate, ungrouped bool) []Target {
+	var targets []Target
+	index := make(map[string]int)
+	add := func(host string) *Target {
+		if i, ok := index[host]; ok {
+			return &targets[i]
+		}
+		index[host] = len(targets)
+		targets = append(targets, Target{Groups: []string{}, Host: host, Schedule: Schedule{Templates: []string{}}})
+		return &targets[len(targets)-1]
+	}
+
+	for _, group := range groups {
+		for _, host := range group.Hosts {
+			target := add(host)
+			target.Groups = append(target.Groups, group.Name)
+		}
+	}
+	if ungrouped {
+		for _, template := range templates {
+			if template.Host != "" {
+				add(tools.PrepareScannerInput(tools.ScannerInput{Host: template.Host}).Host)
+			}
+		}
+	}
+
+	for i := range targets {
+		for _, template := range templates {
+			covered := template.Host != "" && tools.PrepareScannerInput(tools.ScannerInput{Host: template.Host}).Host == targets[i].Host
+			if covered || slices.Contains(targets[i].Groups, template.Group) {
+				targets[i].Schedule.Templates = append(targets[i].Schedule.Templates, template.Name)
+			}
+		}
+	}
+
+	return targets
+}
+
+// scheduleStatus returns the schedule status of target, due when its last scan is older than
+// staleBefore.
+func scheduleStatus(target Target, staleBefore time.Time) string {
+	switch {
+	case len(target.Schedule.Templates) == 0:
+		return ScheduleUnscheduled
+	case target.LastScanAt == nil || target.LastScanAt.Before(staleBefore):
+		return ScheduleDue
+	default:
+		return ScheduleCurrent
+	}
+}
+
+// summarize aggregates the status of targets.
+func summarize(targets []Target) Summary {
+	summary := Summary{OpenFindings: findings.CountBySeverity(nil), Targets: len(targets)}
+	for _, target := range targets {
+		if target.LastScanAt == nil {
+			summary.NeverScanned++
+		}
+		switch target.Schedule.Status {
+		case ScheduleDue:
+			summary.Due++
+		case ScheduleUnscheduled:
+			summary.Unscheduled++
+		}
+		for severity, count := range target.OpenFindings {
+			summary.OpenFindings[severity] += count
+		}
+	}
+
+	return summary
+}
+
+// findingHost returns the host of the URL of finding, empty without one.
+func findingHost(finding models.Finding) string {
+	parsed, err := url.Parse(finding.URL)
+	if err != nil {
+		return ""
+	}
+
+	return parsed.Hostname()
+}
+
+// New creates a new target_status tool.
+func New(logger zerolog.Logger) tools.Tool {
+	return &Tool{
+		logger:    logger.With().Str("tool", toolName).Logger(),
+		validator: validator.New(),
+	}
+}
@@ -0,0 +1,177 @@
+package targetstatus
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/storage"
+	"github.com/tb0hdan/wass-mcp/pkg/types"
+)
+
+type TargetStatusTestSuite struct {
+	suite.Suite
+	store storage.Storage
+	tool  *Tool
+}
+
+func (s *TargetStatusTestSuite) SetupTest() {
+	tmpFile, err := os.CreateTemp("", "target-status-test-*.db")
+	s.Require().NoError(err)
+	s.Require().NoError(tmpFile.Close())
+	s.T().Cleanup(func() { _ = os.Remove(tmpFile.Name()) })
+
+	store, err := storage.NewSQLiteStorage(storage.Config{DatabasePath: tmpFile.Name()})
+	s.Require().NoError(err)
+	s.T().Cleanup(func() { _ = store.Close() })
+
+	s.store = store
+	s.tool = New(zerolog.Nop()).(*Tool)
+	s.tool.store = store
+}
+
+func (s *TargetStatusTestSuite) call(input Input) Result {
+	result, _, err := s.tool.Handler(context.Background(), nil, input)
+	s.Require().NoError(err)
+
+	var response Result
+	s.Require().NoError(json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &response))
+
+	return response
+}
+
+// addScan stores a successful execution against target, a host or a group target, with the given
+// findings.
+func (s *TargetStatusTestSuite) addScan(host, target string, riskScore float64, found ...models.Finding) uint {
+	exec := &models.ToolExecution{ToolName: "full_scan", Target: target, Host: host, RiskScore: riskScore, Success: true}
+	s.Require().NoError(s.store.CreateToolExecution(context.Background(), exec))
+
+	for i := range found {
+		found[i].ExecutionID = exec.ID
+		found[i].Scanner = "nuclei"
+	}
+	if len(found) > 0 {
+		s.Require().NoError(s.store.CreateFindings(context.Background(), found))
+	}
+
+	return exec.ID
+}
+
+// byHost indexes the targets of response by host.
+func byHost(response Result) map[string]Target {
+	targets := make(map[string]Target, len(response.Targets))
+	for _, target := range response.Targets {
+		targets[target.Host] = target
+	}
+
+	return targets
+}
+
+func (s *TargetStatusTestSuite) TestValidation() {
+	_, _, err := s.tool.Handler(context.Background(), nil, Input{StaleDays: 366})
+	s.ErrorContains(err, "validation error")
+
+	_, _, err = s.tool.Handler(context.Background(), nil, Input{Group: "missing"})
+	s.ErrorContains(err, `target group "missing" not found`)
+}
+
+func (s *TargetStatusTestSuite) TestEmptyInventory() {
+	response := s.call(Input{})
+	s.Empty(response.Targets)
+	s.Equal(0, response.Summary.Targets)
+	s.Equal(0, response.Summary.OpenFindings[types.SeverityHigh])
+}
+
+func (s *TargetStatusTestSuite) TestHostScans() {
+	ctx := context.Background()
+	s.Require().NoError(s.store.SaveTargetGroup(ctx, &models.TargetGroup{Name: "web", Hosts: []string{"a.example.com", "b.example.com"}}))
+	s.Require().NoError(s.store.SaveScanTemplate(ctx, &models.ScanTemplate{Name: "weekly", Group: "web"}))
+	s.Require().NoError(s.store.SaveScanTemplate(ctx, &models.ScanTemplate{Name: "api", Host: "https://api.example.com:8443"}))
+
+	s.addScan("a.example.com", "http://a.example.com", 3, models.Finding{Severity: types.SeverityLow, Title: "old"})
+	id := s.addScan("a.example.com", "http://a.example.com", 12,
+		models.Finding{Severity: types.SeverityHigh, Title: "open"},
+		models.Finding{Severity: types.SeverityHigh, Title: "in progress", Status: models.FindingInProgress},
+		models.Finding{Severity: types.SeverityMedium, Title: "fixed", Status: models.FindingResolved},
+	)
+
+	response := s.call(Input{})
+	s.Require().Len(response.Targets, 3)
+	targets := byHost(response)
+
+	scanned := targets["a.example.com"]
+	s.Equal([]string{"web"}, scanned.Groups)
+	s.Equal(id, scanned.LastExecutionID)
+	s.Equal("full_scan", scanned.LastTool)
+	s.Require().NotNil(scanned.LastScanAt)
+	s.InDelta(12, scanned.LastRiskScore, 0.001)
+	s.Equal(2, scanned.OpenFindings[types.SeverityHigh], "open and in progress findings count")
+	s.Equal(0, scanned.OpenFindings[types.SeverityMedium], "resolved findings do not")
+	s.Equal(0, scanned.OpenFindings[types.SeverityLow], "findings of earlier scans do not")
+	s.Equal(Schedule{Status: ScheduleCurrent, Templates: []string{"weekly"}}, scanned.Schedule)
+
+	never := targets["b.example.com"]
+	s.Nil(never.LastScanAt)
+	s.Equal(ScheduleDue, never.Schedule.Status)
+
+	api := targets["api.example.com"]
+	s.Empty(api.Groups)
+	s.Equal([]string{"api"}, api.Schedule.Templates, "template hosts are inventoried")
+
+	s.Equal(Summary{
+		Due:          2,
+		NeverScanned: 2,
+		OpenFindings: map[string]int{types.SeverityCritical: 0, types.SeverityHigh: 2, types.SeverityMedium: 0, types.SeverityLow: 0, types.SeverityInfo: 0},
+		Targets:      3,
+	}, response.Summary)
+}
+
+func (s *TargetStatusTestSuite) TestGroupScans() {
+	ctx := context.Background()
+	s.Require().NoError(s.store.SaveTargetGroup(ctx, &models.TargetGroup{Name: "staging", Hosts: []string{"a.example.com", "b.example.com"}}))
+	s.Require().NoError(s.store.SaveTargetGroup(ctx, &models.TargetGroup{Name: "staging-eu", Hosts: []string{"eu.example.com"}}))
+	s.Require().NoError(s.store.SaveScanTemplate(ctx, &models.ScanTemplate{Name: "solo", Host: "solo.example.com"}))
+
+	s.addScan("a.example.com", "http://a.example.com", 5)
+	id := s.addScan("", "group:staging", 20,
+		models.Finding{Severity: types.SeverityCritical, Title: "a", URL: "http://a.example.com/admin"},
+		models.Finding{Severity: types.SeverityHigh, Title: "b", URL: "https://b.example.com:8443/"},
+	)
+	s.addScan("", "group:staging-eu", 1)
+
+	response := s.call(Input{Group: "staging"})
+	s.Equal("staging", response.Group)
+	s.Require().Len(response.Targets, 2, "hosts of other groups and templates are left out")
+	targets := byHost(response)
+
+	for host, severity := range map[string]string{"a.example.com": types.SeverityCritical, "b.example.com": types.SeverityHigh} {
+		target := targets[host]
+		s.Equal(id, target.LastExecutionID, "the later group scan is the last scan of %s", host)
+		s.Equal(1, target.OpenFindings[severity], "only the findings of %s count", host)
+		s.Equal(ScheduleUnscheduled, target.Schedule.Status)
+	}
+	s.InDelta(10, targets["a.example.com"].LastRiskScore, 0.001, "group scans are scored on the findings of the host")
+	s.Equal(2, response.Summary.Unscheduled)
+}
+
+func (s *TargetStatusTestSuite) TestScheduleStatus() {
+	old := time.Now().Add(-40 * 24 * time.Hour)
+	recent := time.Now().Add(-time.Hour)
+	staleBefore := time.Now().Add(-30 * 24 * time.Hour)
+	covered := Schedule{Templates: []string{"weekly"}}
+
+	s.Equal(ScheduleUnscheduled, scheduleStatus(Target{}, staleBefore))
+	s.Equal(ScheduleDue, scheduleStatus(Target{Schedule: covered}, staleBefore))
+	s.Equal(ScheduleDue, scheduleStatus(Target{Schedule: covered, LastScanAt: &old}, staleBefore))
+	s.Equal(ScheduleCurrent, scheduleStatus(Target{Schedule: covered, LastScanAt: &recent}, staleBefore))
+}
+
+func TestTargetStatusTestSuite(t *testing.T) {
+	suite.Run(t, new(TargetStatusTestSuite))
+}
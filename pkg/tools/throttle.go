@@ -0,0 +1,161 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/rs/zerolog"
+	"github.com/tb0hdan/wass-mcp/pkg/httpclient"
+	"github.com/tb0hdan/wass-mcp/pkg/types"
+)
+
+// maxProbeBodyBytes bounds the response body read per throttling probe request, so that the
+// connection can be reused without downloading large pages.
+const maxProbeBodyBytes = 64 << 10
+
+// wafSignature identifies a web application firewall by a response header.
+type wafSignature struct {
+	name   string
+	header string
+	// value is a lowercase substring of the header value, any value when empty.
+	value string
+}
+
+// wafSignatures are the response headers of common web application firewalls and CDNs enforcing
+// rate limits.
+var wafSignatures = []wafSignature{
+	{name: "Akamai", header: "Server", value: "akamaighost"},
+	{name: "AWS WAF", header: "X-Amzn-Waf-Action"},
+	{name: "BIG-IP ASM", header: "Server", value: "bigip"},
+	{name: "Cloudflare", header: "Cf-Ray"},
+	{name: "Imperva", header: "X-Iinfo"},
+	{name: "ModSecurity", header: "Server", value: "mod_security"},
+	{name: "Sucuri", header: "X-Sucuri-Id"},
+}
+
+// ThrottleResult contains the outcome of a throttling probe.
+type ThrottleResult struct {
+	// Reason describes what revealed throttling, e.g. "429 Too Many Requests"; empty when the
+	// target was not found to throttle.
+	Reason string
+}
+
+// Throttled reports whether the probe found the target to throttle requests.
+func (r ThrottleResult) Throttled() bool {
+	return r.Reason != ""
+}
+
+// ProbeThrottling sends a burst of types.ThrottleProbeRequests requests to the target described by
+// params and reports whether it throttles them: a 429 response, a 503 response with Retry-After,
+// a reset connection, requests failing once the first succeeded, or the headers of a web
+// application firewall. An error is returned when the first request fails otherwise.
+func ProbeThrottling(ctx context.Context, params ScanParams) (ThrottleResult, error) {
+	tlsConfig, err := TLSConfig(params)
+	if err != nil {
+		return ThrottleResult{}, err
+	}
+
+	client := httpclient.New(httpclient.Config{
+		Proxy:   http.ProxyFromEnvironment,
+		Scope:   []string{params.Host},
+		TLS:     tlsConfig,
+		Timeout: types.ProbeTimeout,
+	})
+	defer client.CloseIdleConnections()
+
+	for i := range types.ThrottleProbeRequests {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, params.Target().RequestURL(), nil)
+		if err != nil {
+			return ThrottleResult{}, fmt.Errorf("failed to create request: %w", err)
+		}
+		if params.Vhost != "" {
+			req.Host = params.Vhost
+		}
+		if userAgent := params.Option(OptionUserAgent); userAgent != "" {
+			req.Header.Set("User-Agent", userAgent)
+		}
+
+		resp, err := client.Do(req)
+		switch {
+		case err != nil && errors.Is(err, syscall.ECONNRESET):
+			return ThrottleResult{Reason: "connection reset"}, nil
+		case err != nil && (i == 0 || ctx.Err() != nil):
+			return ThrottleResult{}, fmt.Errorf("failed to probe throttling: %w", err)
+		case err != nil:
+			return ThrottleResult{Reason: fmt.Sprintf("requests failing after %d succeeded", i)}, nil
+		}
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxProbeBodyBytes))
+		_ = resp.Body.Close()
+
+		if reason := throttleReason(resp); reason != "" {
+			return ThrottleResult{Reason: reason}, nil
+		}
+	}
+
+	return ThrottleResult{}, nil
+}
+
+// throttleReason describes how resp reveals throttling, or returns an empty string.
+func throttleReason(resp *http.Response) string {
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return "429 Too Many Requests"
+	case resp.StatusCode == http.StatusServiceUnavailable && resp.Header.Get("Retry-After") != "":
+		return "503 Service Unavailable with Retry-After"
+	}
+	for _, signature := range wafSignatures {
+		value := resp.Header.Get(signature.header)
+		if value != "" && strings.Contains(strings.ToLower(value), signature.value) {
+			return fmt.Sprintf("%s WAF detected", signature.name)
+		}
+	}
+
+	return ""
+}
+
+// LowerRateLimit returns a copy of params whose rate limit option is at most rate requests per
+// second, together with the effective rate. A lower rate limit already set is kept.
+func LowerRateLimit(params ScanParams, rate int) (ScanParams, int) {
+	if current, err := strconv.Atoi(params.Option(OptionRateLimit)); err == nil && current <= rate {
+		return params, current
+	}
+
+	params.Options = maps.Clone(params.Options)
+	if params.Options == nil {
+		params.Options = make(map[string]string, 1)
+	}
+	params.Options[OptionRateLimit] = strconv.Itoa(rate)
+
+	return params, rate
+}
+
+// ApplyThrottling probes params for throttling and, when the target throttles, returns parameters
+// lowering the rate limit of the scanners to types.ThrottledRateLimit, see LowerRateLimit, with a
+// note describing the adjustment for the report. Probe failures are logged and the original
+// parameters are kept.
+func ApplyThrottling(ctx context.Context, logger zerolog.Logger, params ScanParams) (ScanParams, string) {
+	result, err := ProbeThrottling(ctx, params)
+	if err != nil {
+		logger.Warn().Err(err).Msgf("Throttling probe failed, scanning %s at the requested rate", params.Target().URL())
+		return params, ""
+	}
+	if !result.Throttled() {
+		return params, ""
+	}
+
+	lowered, rate := LowerRateLimit(params, types.ThrottledRateLimit)
+	note := fmt.Sprintf("%s, %s lowered to %d requests/s", result.Reason, OptionRateLimit, rate)
+	if lowered.Option(OptionRateLimit) == params.Option(OptionRateLimit) {
+		note = fmt.Sprintf("%s, %s kept at %d requests/s", result.Reason, OptionRateLimit, rate)
+	}
+	logger.Warn().Msgf("Target %s throttles requests: %s", params.Target().URL(), note)
+
+	return lowered, note
+}
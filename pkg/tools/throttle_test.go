@@ -0,0 +1,163 @@
+package tools
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/types"
+)
+
+type ThrottleTestSuite struct {
+	suite.Suite
+}
+
+// paramsFor converts a test server URL into ScanParams.
+func (s *ThrottleTestSuite) paramsFor(rawURL string) ScanParams {
+	parsed, err := url.Parse(rawURL)
+	s.Require().NoError(err)
+	port, err := strconv.Atoi(parsed.Port())
+	s.Require().NoError(err)
+
+	return ScanParams{Host: parsed.Hostname(), Port: port, Scheme: parsed.Scheme}
+}
+
+func (s *ThrottleTestSuite) TestProbeThrottling_NotThrottled() {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	result, err := ProbeThrottling(context.Background(), s.paramsFor(srv.URL))
+	s.Require().NoError(err)
+	s.False(result.Throttled())
+	s.Equal(int32(types.ThrottleProbeRequests), requests.Load())
+}
+
+func (s *ThrottleTestSuite) TestProbeThrottling_TooManyRequests() {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if requests.Add(1) > 2 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	result, err := ProbeThrottling(context.Background(), s.paramsFor(srv.URL))
+	s.Require().NoError(err)
+	s.Equal("429 Too Many Requests", result.Reason)
+	s.Equal(int32(3), requests.Load())
+}
+
+func (s *ThrottleTestSuite) TestProbeThrottling_RetryAfter() {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	result, err := ProbeThrottling(context.Background(), s.paramsFor(srv.URL))
+	s.Require().NoError(err)
+	s.Equal("503 Service Unavailable with Retry-After", result.Reason)
+}
+
+func (s *ThrottleTestSuite) TestProbeThrottling_WAF() {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Server", "AkamaiGHost")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	result, err := ProbeThrottling(context.Background(), s.paramsFor(srv.URL))
+	s.Require().NoError(err)
+	s.Equal("Akamai WAF detected", result.Reason)
+}
+
+func (s *ThrottleTestSuite) TestProbeThrottling_ConnectionReset() {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if requests.Add(1) == 1 {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		conn, _, err := http.NewResponseController(w).Hijack()
+		s.Require().NoError(err)
+		// Closing with a zero linger time resets the connection.
+		_ = conn.(*net.TCPConn).SetLinger(0)
+		_ = conn.Close()
+	}))
+	defer srv.Close()
+
+	result, err := ProbeThrottling(context.Background(), s.paramsFor(srv.URL))
+	s.Require().NoError(err)
+	s.True(result.Throttled())
+}
+
+func (s *ThrottleTestSuite) TestProbeThrottling_Unreachable() {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	params := s.paramsFor(srv.URL)
+	srv.Close()
+
+	_, err := ProbeThrottling(context.Background(), params)
+	s.Error(err)
+}
+
+func (s *ThrottleTestSuite) TestLowerRateLimit() {
+	options := map[string]string{OptionRateLimit: "50", OptionUserAgent: "wass"}
+	params := ScanParams{Options: options}
+
+	lowered, rate := LowerRateLimit(params, 2)
+	s.Equal(2, rate)
+	s.Equal("2", lowered.Option(OptionRateLimit))
+	s.Equal("wass", lowered.Option(OptionUserAgent))
+	s.Equal("50", options[OptionRateLimit], "the original options are left untouched")
+
+	kept, rate := LowerRateLimit(ScanParams{Options: map[string]string{OptionRateLimit: "1"}}, 2)
+	s.Equal(1, rate)
+	s.Equal("1", kept.Option(OptionRateLimit))
+
+	unset, rate := LowerRateLimit(ScanParams{}, 2)
+	s.Equal(2, rate)
+	s.Equal("2", unset.Option(OptionRateLimit))
+}
+
+func (s *ThrottleTestSuite) TestApplyThrottling() {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	params, note := ApplyThrottling(context.Background(), zerolog.Nop(), s.paramsFor(srv.URL))
+	s.Equal("2", params.Option(OptionRateLimit))
+	s.Equal("429 Too Many Requests, rate_limit lowered to 2 requests/s", note)
+
+	slow := s.paramsFor(srv.URL)
+	slow.Options = map[string]string{OptionRateLimit: "1"}
+	_, note = ApplyThrottling(context.Background(), zerolog.Nop(), slow)
+	s.Equal("429 Too Many Requests, rate_limit kept at 1 requests/s", note)
+}
+
+func (s *ThrottleTestSuite) TestApplyThrottling_UnreachableKeepsParams() {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	params := s.paramsFor(srv.URL)
+	srv.Close()
+
+	applied, note := ApplyThrottling(context.Background(), zerolog.Nop(), params)
+	s.Equal(params, applied)
+	s.Empty(note)
+}
+
+func TestThrottleTestSuite(t *testing.T) {
+	suite.Run(t, new(ThrottleTestSuite))
+}
@@ -0,0 +1,53 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+)
+
+// runKey holds the timeline entry of the scanner run in progress, see TimelineScan.
+type runKey struct{}
+
+// TimelineScan wraps scan so that each run of scanner is added to the timeline of the in-flight
+// execution, see RecordScannerRun. It wraps LimitScan, which marks when the run got its slot, so
+// that the time spent waiting for a slot shows on the timeline.
+func TimelineScan(scanner string, scan ScanFunc) ScanFunc {
+	return func(ctx context.Context, params ScanParams) ScanResult {
+		run := &models.ScannerRun{
+			Host:     params.Host,
+			Port:     params.Port,
+			QueuedAt: time.Now(),
+			Scanner:  scanner,
+			Vhost:    params.Vhost,
+		}
+		result := scan(context.WithValue(ctx, runKey{}, run), params)
+
+		run.FinishedAt = time.Now()
+		if run.StartedAt.IsZero() {
+			run.StartedAt = run.FinishedAt
+		}
+		switch {
+		case errors.Is(result.Error, ErrScanHeld):
+			run.Status = models.ScannerHeld
+		case errors.Is(result.Error, ErrScanTimedOut):
+			run.Status = models.ScannerTimedOut
+		case result.Error != nil:
+			run.Status = models.ScannerFailed
+		default:
+			run.Status = models.ScannerCompleted
+		}
+		RecordScannerRun(ctx, *run)
+
+		return result
+	}
+}
+
+// markRunStarted sets the start of the scanner run of ctx, see TimelineScan.
+func markRunStarted(ctx context.Context) {
+	if run, ok := ctx.Value(runKey{}).(*models.ScannerRun); ok {
+		run.StartedAt = time.Now()
+	}
+}
@@ -0,0 +1,178 @@
+package timeline
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/server"
+	"github.com/tb0hdan/wass-mcp/pkg/storage"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+)
+
+const toolName = "timeline"
+
+type Input struct {
+	ID uint `json:"id" validate:"required"`
+}
+
+// Span is a scanner run on the chart, with offsets in milliseconds from the start of the
+// execution.
+type Span struct {
+	DurationMs int64  `json:"duration_ms"`
+	EndMs      int64  `json:"end_ms"`
+	Host       string `json:"host,omitempty"`
+	// Lane is the chart row of the run: runs overlapping in time are on different lanes.
+	Lane        int    `json:"lane"`
+	Port        int    `json:"port,omitempty"`
+	QueueWaitMs int64  `json:"queue_wait_ms"`
+	QueuedMs    int64  `json:"queued_ms"`
+	Scanner     string `json:"scanner"`
+	StartMs     int64  `json:"start_ms"`
+	Status      string `json:"status"`
+	Vhost       string `json:"vhost,omitempty"`
+}
+
+// Result is the timeline tool response.
+type Result struct {
+	CreatedAt   time.Time `json:"created_at"`
+	DurationMs  int64     `json:"duration_ms"`
+	ExecutionID uint      `json:"execution_id"`
+	// IdleMs is the time of the execution during which no scanner ran, such as port discovery,
+	// redirect normalization or waiting for scan slots.
+	IdleMs int64 `json:"idle_ms"`
+	// MaxConcurrency is the most scanner runs running at once, the number of lanes.
+	MaxConcurrency int `json:"max_concurrency"`
+	// Parallelism is the summed run time of the scanners over the time any of them ran, 1 for
+	// runs one after another.
+	Parallelism float64 `json:"parallelism"`
+	// QueueWaitMs is the summed time the runs waited for a scan slot.
+	QueueWaitMs int64  `json:"queue_wait_ms"`
+	Spans       []Span `json:"spans"`
+	Target      string `json:"target,omitempty"`
+	ToolName    string `json:"tool_name"`
+}
+
+type Tool struct {
+	logger    zerolog.Logger
+	validator *validator.Validate
+	store     storage.Storage
+}
+
+func (t *Tool) Register(srv *server.Server) error {
+	tool := &mcp.Tool{
+		Name: toolName,
+		Description: "Returns Gantt-style timeline data of a stored execution by ID: when each scanner run was " +
+			"queued, started and finished, on chart lanes, with the time spent waiting for scan slots, the peak " +
+			"concurrency and the idle time, to see what ran when and where parallelism was lost.",
+	}
+
+	t.store = srv.Storage()
+
+	mcp.AddTool(&srv.Server, tool, t.Handler)
+	t.logger.Debug().Msgf("%s tool registered", toolName)
+
+	return nil
+}
+
+func (t *Tool) Handler(ctx context.Context, _ *mcp.CallToolRequest, input Input) (*mcp.CallToolResult, any, error) {
+	if err := t.validator.Struct(input); err != nil {
+		return nil, nil, fmt.Errorf("validation error: %w", err)
+	}
+
+	exec, err := t.store.GetToolExecution(ctx, input.ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("execution not found: %w", err)
+	}
+
+	data, _ := json.MarshalIndent(buildTimeline(exec), "", "  ")
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: string(data)},
+		},
+	}, nil, nil
+}
+
+// buildTimeline lays the scanner runs of exec out on lanes, ordered by start, and measures where
+// the execution ran scanners in parallel. Executions stored before runs were recorded have no
+// spans.
+func buildTimeline(exec *models.ToolExecution) Result {
+	result := Result{
+		CreatedAt:   exec.CreatedAt,
+		DurationMs:  exec.DurationMs,
+		ExecutionID: exec.ID,
+		Spans:       make([]Span, 0, len(exec.Timeline)),
+		Target:      exec.Target,
+		ToolName:    exec.ToolName,
+	}
+
+	// Runs are measured from the creation of the execution, or from the first run queued before it.
+	origin := exec.CreatedAt
+	for _, run := range exec.Timeline {
+		if origin.IsZero() || run.QueuedAt.Before(origin) {
+			origin = run.QueuedAt
+		}
+	}
+	offset := func(at time.Time) int64 { return at.Sub(origin).Milliseconds() }
+
+	for _, run := range exec.Timeline {
+		result.Spans = append(result.Spans, Span{
+			DurationMs:  run.FinishedAt.Sub(run.StartedAt).Milliseconds(),
+			EndMs:       offset(run.FinishedAt),
+			Host:        run.Host,
+			Port:        run.Port,
+			QueueWaitMs: run.StartedAt.Sub(run.QueuedAt).Milliseconds(),
+			QueuedMs:    offset(run.QueuedAt),
+			Scanner:     run.Scanner,
+			StartMs:     offset(run.StartedAt),
+			Status:      run.Status,
+			Vhost:       run.Vhost,
+		})
+	}
+	slices.SortStableFunc(result.Spans, func(a, b Span) int { return cmp.Compare(a.StartMs, b.StartMs) })
+
+	// Each run takes the first lane free when it starts, so that the lanes are as many as the most
+	// runs running at once.
+	var laneEnds []int64
+	var busyMs, runMs, coveredUntil int64
+	for i := range result.Spans {
+		span := &result.Spans[i]
+		span.Lane = slices.IndexFunc(laneEnds, func(end int64) bool { return end <= span.StartMs })
+		if span.Lane < 0 {
+			span.Lane = len(laneEnds)
+			laneEnds = append(laneEnds, 0)
+		}
+		laneEnds[span.Lane] = span.EndMs
+
+		result.QueueWaitMs += span.QueueWaitMs
+		runMs += span.DurationMs
+		// Spans are sorted by start, so the time any run was running is their union.
+		if span.EndMs > coveredUntil {
+			busyMs += span.EndMs - max(span.StartMs, coveredUntil)
+			coveredUntil = span.EndMs
+		}
+	}
+	result.MaxConcurrency = len(laneEnds)
+	if busyMs > 0 {
+		result.Parallelism = float64(runMs*100/busyMs) / 100
+	}
+	result.IdleMs = max(max(result.DurationMs, coveredUntil)-busyMs, 0)
+
+	return result
+}
+
+// New creates a new timeline tool.
+func New(logger zerolog.Logger) tools.Tool {
+	return &Tool{
+		logger:    logger.With().Str("tool", toolName).Logger(),
+		validator: validator.New(),
+	}
+}
@@ -0,0 +1,150 @@
+package timeline
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/storage"
+)
+
+type TimelineTestSuite struct {
+	suite.Suite
+	store storage.Storage
+	tool  *Tool
+}
+
+func (s *TimelineTestSuite) SetupTest() {
+	tmpFile, err := os.CreateTemp("", "timeline-test-*.db")
+	s.Require().NoError(err)
+	s.Require().NoError(tmpFile.Close())
+	s.T().Cleanup(func() { _ = os.Remove(tmpFile.Name()) })
+
+	store, err := storage.NewSQLiteStorage(storage.Config{DatabasePath: tmpFile.Name()})
+	s.Require().NoError(err)
+	s.T().Cleanup(func() { _ = store.Close() })
+
+	s.store = store
+	s.tool = New(zerolog.Nop()).(*Tool)
+	s.tool.store = store
+}
+
+// run returns a scanner run queued at queued, started at started and finished at finished
+// milliseconds from origin.
+func run(origin time.Time, scanner string, queued, started, finished int) models.ScannerRun {
+	at := func(ms int) time.Time { return origin.Add(time.Duration(ms) * time.Millisecond) }
+
+	return models.ScannerRun{
+		FinishedAt: at(finished),
+		Host:       "localhost",
+		Port:       80,
+		QueuedAt:   at(queued),
+		Scanner:    scanner,
+		StartedAt:  at(started),
+		Status:     models.ScannerCompleted,
+	}
+}
+
+func (s *TimelineTestSuite) TestBuildTimeline() {
+	origin := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	exec := &models.ToolExecution{
+		ID:         7,
+		CreatedAt:  origin,
+		DurationMs: 1000,
+		ToolName:   "full_scan",
+		Timeline: []models.ScannerRun{
+			run(origin, "nikto", 100, 100, 500),
+			run(origin, "nuclei", 100, 200, 400),
+			// Waits for a slot until nikto finishes.
+			run(origin, "wapiti", 100, 500, 800),
+		},
+	}
+
+	result := buildTimeline(exec)
+	s.Equal(uint(7), result.ExecutionID)
+	s.Require().Len(result.Spans, 3)
+
+	s.Equal("nikto", result.Spans[0].Scanner)
+	s.Equal(0, result.Spans[0].Lane)
+	s.Equal(int64(100), result.Spans[0].StartMs)
+	s.Equal(int64(400), result.Spans[0].DurationMs)
+
+	s.Equal("nuclei", result.Spans[1].Scanner)
+	s.Equal(1, result.Spans[1].Lane, "overlapping runs are on different lanes")
+	s.Equal(int64(100), result.Spans[1].QueueWaitMs)
+
+	s.Equal("wapiti", result.Spans[2].Scanner)
+	s.Equal(0, result.Spans[2].Lane, "the lane nikto freed is reused")
+	s.Equal(int64(400), result.Spans[2].QueueWaitMs)
+	s.Equal(int64(800), result.Spans[2].EndMs)
+
+	s.Equal(2, result.MaxConcurrency)
+	s.Equal(int64(500), result.QueueWaitMs)
+	s.InDelta(1.28, result.Parallelism, 0.001, "900ms of runs over 700ms")
+	s.Equal(int64(300), result.IdleMs, "nothing ran before 100ms and after 800ms")
+}
+
+func (s *TimelineTestSuite) TestBuildTimeline_Sequential() {
+	origin := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	exec := &models.ToolExecution{
+		CreatedAt:  origin,
+		DurationMs: 600,
+		Timeline:   []models.ScannerRun{run(origin, "nikto", 0, 0, 200), run(origin, "nikto", 300, 300, 600)},
+	}
+
+	result := buildTimeline(exec)
+	s.Equal(1, result.MaxConcurrency)
+	s.InDelta(1.0, result.Parallelism, 0.001)
+	s.Equal(int64(100), result.IdleMs)
+}
+
+func (s *TimelineTestSuite) TestHandler() {
+	exec := &models.ToolExecution{ToolName: "full_scan", Target: "http://localhost", Success: true}
+	s.Require().NoError(s.store.CreateToolExecution(context.Background(), exec))
+	exec.Timeline = []models.ScannerRun{run(exec.CreatedAt, "nikto", 0, 10, 50)}
+	exec.DurationMs = 60
+	s.Require().NoError(s.store.UpdateToolExecution(context.Background(), exec))
+
+	result, _, err := s.tool.Handler(context.Background(), nil, Input{ID: exec.ID})
+	s.Require().NoError(err)
+
+	var response Result
+	s.Require().NoError(json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &response))
+	s.Equal(exec.ID, response.ExecutionID)
+	s.Equal("http://localhost", response.Target)
+	s.Require().Len(response.Spans, 1)
+	s.Equal(int64(40), response.Spans[0].DurationMs)
+	s.Equal(int64(10), response.Spans[0].QueueWaitMs)
+}
+
+func (s *TimelineTestSuite) TestHandler_NoRuns() {
+	exec := &models.ToolExecution{ToolName: "nikto", Success: true}
+	s.Require().NoError(s.store.CreateToolExecution(context.Background(), exec))
+
+	result, _, err := s.tool.Handler(context.Background(), nil, Input{ID: exec.ID})
+	s.Require().NoError(err)
+
+	var response Result
+	s.Require().NoError(json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &response))
+	s.NotNil(response.Spans)
+	s.Empty(response.Spans)
+	s.Equal(0, response.MaxConcurrency)
+}
+
+func (s *TimelineTestSuite) TestHandler_Errors() {
+	_, _, err := s.tool.Handler(context.Background(), nil, Input{})
+	s.ErrorContains(err, "validation error")
+
+	_, _, err = s.tool.Handler(context.Background(), nil, Input{ID: 999})
+	s.ErrorContains(err, "execution not found")
+}
+
+func TestTimelineTestSuite(t *testing.T) {
+	suite.Run(t, new(TimelineTestSuite))
+}
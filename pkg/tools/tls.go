@@ -0,0 +1,52 @@
+package tools
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+// TLSConfig builds a client TLS configuration from the TLS options in params.
+// It returns nil when the defaults (system roots, verification enabled) apply.
+func TLSConfig(params ScanParams) (*tls.Config, error) {
+	if !params.InsecureSkipVerify && params.CABundle == "" {
+		return nil, nil //nolint:nilnil
+	}
+
+	cfg := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: params.InsecureSkipVerify, //nolint:gosec
+	}
+
+	if params.CABundle != "" {
+		pemData, err := os.ReadFile(params.CABundle)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pemData) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", params.CABundle)
+		}
+		cfg.RootCAs = pool
+	}
+
+	return cfg, nil
+}
+
+// TLSEnv returns extra environment variables that point OpenSSL/Python based scanners
+// at the custom CA bundle. It returns nil when no CA bundle is configured.
+func TLSEnv(params ScanParams) []string {
+	if params.CABundle == "" {
+		return nil
+	}
+
+	return []string{
+		"SSL_CERT_FILE=" + params.CABundle,
+		"REQUESTS_CA_BUNDLE=" + params.CABundle,
+	}
+}
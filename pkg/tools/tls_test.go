@@ -0,0 +1,95 @@
+package tools
+
+import (
+	"context"
+	"encoding/pem"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/types"
+)
+
+type TLSTestSuite struct {
+	suite.Suite
+	server *httptest.Server
+	bundle string
+}
+
+func (s *TLSTestSuite) SetupTest() {
+	s.server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	pemData := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: s.server.Certificate().Raw})
+	s.bundle = filepath.Join(s.T().TempDir(), "ca.pem")
+	s.Require().NoError(os.WriteFile(s.bundle, pemData, 0o600))
+}
+
+func (s *TLSTestSuite) TearDownTest() {
+	s.server.Close()
+}
+
+func (s *TLSTestSuite) TestTLSConfig_Defaults() {
+	cfg, err := TLSConfig(ScanParams{})
+	s.NoError(err)
+	s.Nil(cfg)
+}
+
+func (s *TLSTestSuite) TestTLSConfig_Insecure() {
+	cfg, err := TLSConfig(ScanParams{InsecureSkipVerify: true})
+	s.Require().NoError(err)
+	s.True(cfg.InsecureSkipVerify)
+	s.Nil(cfg.RootCAs)
+}
+
+func (s *TLSTestSuite) TestTLSConfig_CABundle() {
+	cfg, err := TLSConfig(ScanParams{CABundle: s.bundle})
+	s.Require().NoError(err)
+	s.False(cfg.InsecureSkipVerify)
+	s.NotNil(cfg.RootCAs)
+}
+
+func (s *TLSTestSuite) TestTLSConfig_InvalidBundle() {
+	invalid := filepath.Join(s.T().TempDir(), "invalid.pem")
+	s.Require().NoError(os.WriteFile(invalid, []byte("not a certificate"), 0o600))
+
+	_, err := TLSConfig(ScanParams{CABundle: invalid})
+	s.Error(err)
+}
+
+func (s *TLSTestSuite) TestTLSConfig_MissingBundle() {
+	_, err := TLSConfig(ScanParams{CABundle: filepath.Join(s.T().TempDir(), "missing.pem")})
+	s.Error(err)
+}
+
+func (s *TLSTestSuite) TestTLSEnv() {
+	s.Nil(TLSEnv(ScanParams{}))
+	s.Contains(TLSEnv(ScanParams{CABundle: s.bundle}), "SSL_CERT_FILE="+s.bundle)
+}
+
+func (s *TLSTestSuite) TestNormalizeTarget_SelfSigned() {
+	addr := s.server.Listener.Addr().(*net.TCPAddr)
+	params := ScanParams{Host: "127.0.0.1", Port: addr.Port, Scheme: types.SchemeHTTPS}
+
+	_, err := NormalizeTarget(context.Background(), params)
+	s.Error(err, "self-signed certificate must fail verification by default")
+
+	insecure := params
+	insecure.InsecureSkipVerify = true
+	_, err = NormalizeTarget(context.Background(), insecure)
+	s.NoError(err)
+
+	trusted := params
+	trusted.CABundle = s.bundle
+	_, err = NormalizeTarget(context.Background(), trusted)
+	s.NoError(err)
+}
+
+func TestTLSTestSuite(t *testing.T) {
+	suite.Run(t, new(TLSTestSuite))
+}
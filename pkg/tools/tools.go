@@ -2,18 +2,34 @@ package tools
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"net"
-	"net/url"
+	"maps"
 	"os/exec"
-	"strconv"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/rs/zerolog"
+	"github.com/tb0hdan/wass-mcp/pkg/bundle"
+	"github.com/tb0hdan/wass-mcp/pkg/limiter"
+	"github.com/tb0hdan/wass-mcp/pkg/metrics"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/redact"
+	"github.com/tb0hdan/wass-mcp/pkg/running"
+	"github.com/tb0hdan/wass-mcp/pkg/sanitize"
+	"github.com/tb0hdan/wass-mcp/pkg/scanconfig"
+	"github.com/tb0hdan/wass-mcp/pkg/scope"
 	"github.com/tb0hdan/wass-mcp/pkg/server"
+	"github.com/tb0hdan/wass-mcp/pkg/session"
+	"github.com/tb0hdan/wass-mcp/pkg/storage"
+	"github.com/tb0hdan/wass-mcp/pkg/target"
+	"github.com/tb0hdan/wass-mcp/pkg/tenant"
 	"github.com/tb0hdan/wass-mcp/pkg/types"
+	"github.com/tb0hdan/wass-mcp/pkg/vault"
+	"github.com/tb0hdan/wass-mcp/pkg/wordlist"
 )
 
 // Tool is the interface that all MCP tools must implement.
@@ -21,12 +37,69 @@ type Tool interface {
 	Register(srv *server.Server) error
 }
 
+// ErrScannerDisabled is returned by scanners disabled at runtime.
+var ErrScannerDisabled = errors.New("scanner is disabled")
+
+// ErrActiveScanner is returned by active scanners called in passive mode, see PassiveScanner.
+var ErrActiveScanner = errors.New("active scanner refused in passive mode")
+
+// ErrScanHeld is returned for scanner runs held because their job was paused.
+var ErrScanHeld = errors.New("held, job paused")
+
+// ErrScanTimedOut wraps the error of scanner runs that failed once their deadline passed.
+var ErrScanTimedOut = errors.New("scan timed out")
+
+// ScanAction is the action read-only keys are refused by scanning tools.
+const ScanAction = "launch scans"
+
 // ScanParams contains common parameters for scanner tools.
 type ScanParams struct {
-	Host   string
-	Port   int
+	// CABundle is an optional PEM file with CA certificates trusted for the target.
+	CABundle string
+	// Capture routes the scanner traffic through a capture proxy recording it as a HAR artifact,
+	// see CaptureScan.
+	Capture bool
+	// Credential is the stored credential the scan authenticates with, resolved from the credential
+	// named by the input.
+	Credential *vault.Credential
+	Host       string
+	// Hints are the technologies, e.g. "wordpress", detected by the fingerprinting scanners a full
+	// scan ran first, see Fingerprinter. Scanners may use them to select their checks.
+	Hints []string
+	// InsecureSkipVerify disables TLS certificate verification for scanners that support it.
+	InsecureSkipVerify bool
+	// Options are generic scanner options keyed by option name, see OptionSupporter.
+	Options map[string]string
+	// Path is the base path scanned, empty for the root.
+	Path string
+	Port int
+	// Proxy is the URL of the HTTP proxy the scanner routes its traffic through, set by CaptureScan.
+	Proxy  string
 	Scheme string
-	Vhost  string
+	// Timeout bounds each scanner run, 0 for no bound, see TimeoutScan.
+	Timeout time.Duration
+	// URLs are the URLs of the target found by a crawl, which scanners taking a URL list scan
+	// besides the target, see WriteURLList.
+	URLs  []string
+	Vhost string
+	// Wordlist is the path of the stored wordlist the scan brute-forces paths with, resolved from
+	// the wordlist named by the input, see wordlist.Registry.
+	Wordlist string
+}
+
+// Target returns the scan target of the parameters.
+func (p ScanParams) Target() target.Target {
+	return target.Target{Host: p.Host, Path: p.Path, Port: p.Port, Scheme: p.Scheme, Vhost: p.Vhost}
+}
+
+// CheckCredential returns an error when the scan authenticates with a credential of a type other
+// than supported, which scanner cannot use.
+func (p ScanParams) CheckCredential(scanner string, supported ...string) error {
+	if p.Credential == nil || slices.Contains(supported, p.Credential.Type) {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s does not support %s credentials", vault.ErrUnsupportedType, scanner, p.Credential.Type)
 }
 
 // ScanResult contains the result of a scan operation.
@@ -35,6 +108,9 @@ type ScanResult struct {
 	Output string
 }
 
+// ScanFunc performs a single scan against the given parameters.
+type ScanFunc func(ctx context.Context, params ScanParams) ScanResult
+
 // Scanner is the interface that scanner tools implement for reuse.
 type Scanner interface {
 	Tool
@@ -46,14 +122,69 @@ type Scanner interface {
 	Scan(ctx context.Context, params ScanParams) ScanResult
 }
 
+// Fingerprinter is implemented by scanners that detect the technologies of the target, such as
+// its CMS or web server, in the output of their scans. full_scan passes the technologies detected
+// by the scanners it runs first to the others as ScanParams.Hints.
+type Fingerprinter interface {
+	Fingerprint(output string) []string
+}
+
+// TechnologyScanner is implemented by scanners that apply to targets running one of the
+// technologies they report, such as a WordPress scanner. full_scan runs them only when they are
+// named by its scanners input or, in auto mode, when a fingerprinting scanner detected one of
+// their technologies, see Fingerprinter.
+type TechnologyScanner interface {
+	Technologies() []string
+}
+
+// Technologies returns the technologies scanner applies to, empty for scanners of any target.
+func Technologies(scanner Scanner) []string {
+	if technology, ok := scanner.(TechnologyScanner); ok {
+		return technology.Technologies()
+	}
+
+	return nil
+}
+
+// PassiveScanner is implemented by scanners that can report whether they only run non-intrusive
+// checks, such as a single request inspecting response headers, TLS and cookies. Passive scans run
+// passive scanners only, for production targets where active scanning is prohibited.
+type PassiveScanner interface {
+	IsPassive() bool
+}
+
+// IsPassive reports whether scanner only runs non-intrusive checks.
+func IsPassive(scanner Scanner) bool {
+	passive, ok := scanner.(PassiveScanner)
+	return ok && passive.IsPassive()
+}
+
 // ScannerInput defines common MCP tool input parameters for all scanners.
 // This eliminates duplicate Input struct definitions across scanner packages.
 type ScannerInput struct {
-	Host     string `json:"host,omitempty" validate:"omitempty,hostname_rfc1123|ip"`
-	MaxLines int    `json:"max_lines,omitempty" validate:"min=0,max=100000"`
-	Offset   int    `json:"offset,omitempty" validate:"min=0"`
-	Port     int    `json:"port,omitempty" validate:"min=0,max=65535"`
-	Vhost    string `json:"vhost,omitempty"`
+	CABundle           string            `json:"ca_bundle,omitempty" validate:"omitempty,file"`
+	Capture            bool              `json:"capture,omitempty"`
+	Credential         string            `json:"credential,omitempty" validate:"omitempty,max=64"`
+	FollowRedirects    bool              `json:"follow_redirects,omitempty"`
+	Host               string            `json:"host,omitempty" validate:"omitempty,hostname_rfc1123|ip"`
+	InsecureSkipVerify bool              `json:"insecure_skip_verify,omitempty"`
+	MaxLines           int               `json:"max_lines,omitempty" validate:"min=0,max=100000"`
+	Offset             int               `json:"offset,omitempty" validate:"min=0"`
+	Options            map[string]string `json:"options,omitempty" validate:"omitempty,max=32"`
+	// Passive restricts the scan to passive scanners, see PassiveScanner.
+	Passive     bool     `json:"passive,omitempty"`
+	Cursor      string   `json:"cursor,omitempty" validate:"omitempty,max=64"`
+	Compression string   `json:"compression,omitempty" validate:"omitempty,oneof=gzip none"`
+	Path        string   `json:"path,omitempty" validate:"omitempty,startswith=/,max=2048"`
+	Port        int      `json:"port,omitempty" validate:"min=0,max=65535"`
+	Priority    string   `json:"priority,omitempty" validate:"omitempty,oneof=low normal high"`
+	Retryable   bool     `json:"retry_on_restart,omitempty"`
+	Scheme      string   `json:"scheme,omitempty" validate:"omitempty,oneof=http https"`
+	Timeout     int      `json:"timeout,omitempty" validate:"min=0,max=86400"`
+	Vhost       string   `json:"vhost,omitempty"`
+	Vhosts      []string `json:"vhosts,omitempty" validate:"omitempty,max=32,dive,required"`
+	// Wordlist names the stored wordlist content discovery scanners brute-force paths with.
+	Wordlist string `json:"wordlist,omitempty" validate:"omitempty,max=64"`
 }
 
 // PaginationResult contains the result of pagination applied to output.
@@ -66,134 +197,158 @@ type PaginationResult struct {
 }
 
 // ApplyPagination applies pagination to the given output string.
-// It returns the paginated lines and metadata about the pagination.
+// It returns the paginated lines and metadata about the pagination. A maxLines of 0 or less shows
+// the default number of lines, and an offset outside the output shows the first page. Only the
+// lines of the page are split out, so paginating huge outputs does not allocate a slice of all
+// their lines.
 func ApplyPagination(output string, maxLines, offset int) PaginationResult {
-	if maxLines == 0 {
+	if maxLines <= 0 {
 		maxLines = types.MaxDefaultLines
 	}
 
-	lines := strings.Split(output, "\n")
-	totalLines := len(lines)
-
-	truncated := false
-	startLine := offset
+	totalLines := strings.Count(output, "\n") + 1
 
+	startLine := 0
 	if offset > 0 && offset < totalLines {
-		end := totalLines
-		if offset+maxLines < totalLines {
-			end = offset + maxLines
-			truncated = true
-		}
-		lines = lines[offset:end]
-	} else if totalLines > maxLines {
-		lines = lines[:maxLines]
-		startLine = 0
+		startLine = offset
+	}
+	// Compared without adding, which overflows for huge offsets and limits.
+	count := totalLines - startLine
+	truncated := false
+	if maxLines < count {
+		count = maxLines
 		truncated = true
-	} else {
-		startLine = 0
 	}
 
 	return PaginationResult{
-		EndLine:    startLine + len(lines),
-		Lines:      lines,
+		EndLine:    startLine + count,
+		Lines:      pageLines(output, startLine, count),
 		StartLine:  startLine,
 		TotalLines: totalLines,
 		Truncated:  truncated,
 	}
 }
 
+// pageLines returns count lines of output from line start, which must exist.
+func pageLines(output string, start, count int) []string {
+	for range start {
+		output = output[strings.IndexByte(output, '\n')+1:]
+	}
+
+	lines := make([]string, 0, count)
+	for range count - 1 {
+		end := strings.IndexByte(output, '\n')
+		lines = append(lines, output[:end])
+		output = output[end+1:]
+	}
+	if end := strings.IndexByte(output, '\n'); end >= 0 {
+		output = output[:end]
+	}
+
+	return append(lines, output)
+}
+
 // FormatScannerOutput formats scanner output with pagination information.
 // toolName is used in the header (e.g., "nikto output for", "wapiti report for").
 // headerVerb allows customization (e.g., "output" vs "report").
 func FormatScannerOutput(toolName, headerVerb, targetURL, output string, maxLines, offset int) string {
-	pagination := ApplyPagination(output, maxLines, offset)
-	paginatedOutput := strings.Join(pagination.Lines, "\n")
+	resultText, _ := FormatScannerPage(toolName, headerVerb, targetURL, output, maxLines, Cursor{Line: offset}, 0)
+	return resultText
+}
+
+// FormatScannerPage formats a page of scanner output starting at start and limited to maxLines
+// lines and maxBytes bytes (0 for unlimited), see PaginateResponse. It also returns the page, whose
+// Next is the cursor of the next page when the byte budget cut the page short.
+func FormatScannerPage(toolName, headerVerb, targetURL, output string, maxLines int, start Cursor, maxBytes int) (string, ResponsePage) {
+	page := PaginateResponse(output, maxLines, start, maxBytes)
 
 	resultText := fmt.Sprintf("%s %s for %s:\n", toolName, headerVerb, targetURL)
-	if pagination.Truncated || offset > 0 {
-		resultText += fmt.Sprintf("[Showing lines %d-%d of %d lines. Use offset parameter to view more.]\n",
-			pagination.StartLine+1, pagination.EndLine, pagination.TotalLines)
-	}
-	resultText += "\n" + strings.TrimSpace(paginatedOutput)
+	resultText += PageNotice(page, start, maxBytes)
+	resultText += "\n" + strings.TrimSpace(page.Text)
 
-	return resultText
+	return resultText, page
 }
 
-// HostParseResult contains the result of parsing a host input string.
-type HostParseResult struct {
-	Host   string
-	Port   int
-	Scheme string
+// TargetProvider is implemented by tool inputs that describe a scan target.
+// The execution logger uses it to store the target alongside the execution record.
+type TargetProvider interface {
+	ScanTarget() ScanParams
 }
 
-// ParseHostInput detects URL-style host strings and extracts scheme, hostname, and port.
-// Plain hostnames or IPs are returned as-is with an empty scheme.
-func ParseHostInput(host string) HostParseResult {
-	if !strings.Contains(host, "://") {
-		return HostParseResult{Host: host}
-	}
+// GroupProvider is implemented by tool inputs that can scan a target group instead of a single
+// target. The execution logger stores GroupTarget of the group as the target of group scans.
+type GroupProvider interface {
+	TargetGroup() string
+}
 
-	parsed, err := url.Parse(host)
-	if err != nil {
-		return HostParseResult{Host: host}
-	}
+// GroupTarget returns the execution target recorded for a scan of the named target group.
+func GroupTarget(name string) string {
+	return "group:" + name
+}
 
-	result := HostParseResult{
-		Host:   parsed.Hostname(),
-		Scheme: parsed.Scheme,
-	}
+// TargetListProvider is implemented by tool inputs that can scan a list of host expressions
+// instead of a single target. The execution logger stores ListTarget of the list as the target of
+// such scans.
+type TargetListProvider interface {
+	TargetList() []string
+}
 
-	if portStr := parsed.Port(); portStr != "" {
-		if p, err := strconv.Atoi(portStr); err == nil {
-			result.Port = p
-		}
-	}
+// ListTarget returns the execution target recorded for a scan of the host expressions hosts.
+func ListTarget(hosts []string) string {
+	return "hosts:" + strings.Join(hosts, ",")
+}
 
-	return result
+// RetryableInput is implemented by tool inputs that can ask to be re-run when the server
+// restarts while the tool is running.
+type RetryableInput interface {
+	IsRetryable() bool
 }
 
-// BuildTargetURL constructs a URL from ScanParams, omitting the port when it is
-// the default for the scheme (80 for HTTP, 443 for HTTPS).
-func BuildTargetURL(params ScanParams) string {
-	scheme := params.Scheme
-	if scheme == "" {
-		scheme = types.SchemeHTTP
-	}
+// IsRetryable reports whether the scan should be re-run after an interrupted server restart.
+func (i ScannerInput) IsRetryable() bool {
+	return i.Retryable
+}
 
-	host := params.Host
+// ScanTarget returns the resolved scan target of the input.
+func (i ScannerInput) ScanTarget() ScanParams {
+	return ResolveParams(i)
+}
 
-	// Omit port when it matches the scheme default.
-	if (scheme == types.SchemeHTTP && params.Port == types.DefaultPort) ||
-		(scheme == types.SchemeHTTPS && params.Port == types.HTTPSPort) {
-		// For IPv6 without port, we still need brackets.
-		if strings.Contains(host, ":") {
-			host = "[" + host + "]"
-		}
+// PrepareScannerInput parses URL-style hosts in the input and replaces the Host field with the
+// plain hostname so that validation (hostname|ip) passes. The scheme, port and path of the URL are
+// moved to the corresponding fields unless they were set explicitly.
+// This is a standalone function for use by tools that don't embed BaseScanner (e.g. fullscan).
+func PrepareScannerInput(input ScannerInput) ScannerInput {
+	parsed := target.Parse(input.Host)
+	input.Host = parsed.Host
 
-		return scheme + "://" + host
+	if input.Port == 0 {
+		input.Port = parsed.Port
+	}
+	if input.Scheme == "" {
+		input.Scheme = parsed.Scheme
+	}
+	if input.Path == "" {
+		input.Path = parsed.Path
 	}
 
-	return scheme + "://" + net.JoinHostPort(host, strconv.Itoa(params.Port))
+	return input
 }
 
 // ResolveParams resolves a ScannerInput into a ScanParams with defaults applied.
 // This is a standalone function for use by tools that don't embed BaseScanner (e.g. fullscan).
 func ResolveParams(input ScannerInput) ScanParams {
-	parsed := ParseHostInput(input.Host)
+	input = PrepareScannerInput(input)
 
-	host := parsed.Host
+	host := input.Host
 	if host == "" {
 		host = types.DefaultHost
 	}
 
 	port := input.Port
-	if port == 0 {
-		port = parsed.Port
-	}
+	scheme := input.Scheme
 
-	scheme := parsed.Scheme
-
-	// Infer scheme from port if not set by URL.
+	// Infer scheme from port if not set explicitly or by URL.
 	if scheme == "" {
 		if port == types.HTTPSPort {
 			scheme = types.SchemeHTTPS
@@ -202,40 +357,239 @@ func ResolveParams(input ScannerInput) ScanParams {
 		}
 	}
 
-	// When scheme is HTTPS from URL but no port was set anywhere, default to 443.
-	if scheme == types.SchemeHTTPS && port == 0 {
-		port = types.HTTPSPort
-	}
-
-	// Fallback to default port.
+	// Fallback to the default port of the scheme.
 	if port == 0 {
-		port = types.DefaultPort
+		port = target.DefaultPort(scheme)
 	}
 
 	return ScanParams{
-		Host:   host,
-		Port:   port,
-		Scheme: scheme,
-		Vhost:  input.Vhost,
+		CABundle:           input.CABundle,
+		Capture:            input.Capture,
+		Host:               host,
+		InsecureSkipVerify: input.InsecureSkipVerify,
+		Options:            maps.Clone(input.Options),
+		Path:               input.Path,
+		Port:               port,
+		Scheme:             scheme,
+		Timeout:            ScanTimeout(input),
+		Vhost:              input.Vhost,
+	}
+}
+
+// ScanTimeout returns the run time allowed to each scanner run of input, 0 for no bound.
+func ScanTimeout(input ScannerInput) time.Duration {
+	return time.Duration(input.Timeout) * time.Second
+}
+
+// LimitScan wraps scan so that each run holds a slot of scanLimiter. A nil limiter is unlimited.
+// The time waited for a slot and the run time are recorded as the queue wait and exec phases of
+// the in-flight execution, see RecordPhase, and the start of the run on its timeline, see
+// TimelineScan.
+func LimitScan(scanLimiter *limiter.Limiter, scan ScanFunc) ScanFunc {
+	return func(ctx context.Context, params ScanParams) ScanResult {
+		queued := time.Now()
+		err := scanLimiter.Acquire(ctx)
+		RecordPhase(ctx, models.PhaseQueueWait, time.Since(queued))
+		if err != nil {
+			return ScanResult{Error: err}
+		}
+		defer scanLimiter.Release()
+
+		markRunStarted(ctx)
+		start := time.Now()
+		defer func() { RecordPhase(ctx, models.PhaseExec, time.Since(start)) }()
+
+		return scan(ctx, params)
+	}
+}
+
+// TimeoutScan wraps scan so that each run is bounded to params.Timeout, 0 for no bound. The
+// context bounds the scanner process started with exec.CommandContext. Runs failing once their
+// deadline passed, this one or an earlier one of ctx, fail with ErrScanTimedOut and keep their
+// partial output.
+func TimeoutScan(scan ScanFunc) ScanFunc {
+	return func(ctx context.Context, params ScanParams) ScanResult {
+		if params.Timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, params.Timeout)
+			defer cancel()
+		}
+
+		result := scan(ctx, params)
+		if result.Error != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			result.Error = fmt.Errorf("%w: %w", ErrScanTimedOut, result.Error)
+		}
+
+		return result
 	}
 }
 
+// HoldScan wraps scan so that runs not started yet when their job is paused are held, returning
+// ErrScanHeld, while runs already in progress complete.
+func HoldScan(scan ScanFunc) ScanFunc {
+	return func(ctx context.Context, params ScanParams) ScanResult {
+		if running.Paused(ctx) {
+			return ScanResult{Error: ErrScanHeld}
+		}
+
+		return scan(ctx, params)
+	}
+}
+
+// PrioritizeScan returns ctx carrying the scan priority requested by the client, used when runs
+// wait for a limiter slot. A priority already set on ctx, as for background re-runs, is kept.
+func PrioritizeScan(ctx context.Context, priority string) context.Context {
+	if priority == "" || limiter.PriorityFrom(ctx) != "" {
+		return ctx
+	}
+
+	return limiter.WithPriority(ctx, priority)
+}
+
+// SanitizeScan wraps scan so that its output is normalized to clean UTF-8 text, see
+// sanitize.Output, before it is parsed, stored or returned.
+func SanitizeScan(scan ScanFunc) ScanFunc {
+	return func(ctx context.Context, params ScanParams) ScanResult {
+		result := scan(ctx, params)
+		result.Output = sanitize.Output(result.Output)
+
+		return result
+	}
+}
+
+// FormatScan wraps scan so that its output is rewritten by format. A nil format leaves scan as is.
+func FormatScan(format func(output string) string, scan ScanFunc) ScanFunc {
+	if format == nil {
+		return scan
+	}
+
+	return func(ctx context.Context, params ScanParams) ScanResult {
+		result := scan(ctx, params)
+		result.Output = format(result.Output)
+
+		return result
+	}
+}
+
+// MeasureScan wraps scan so that the outcome of each run is recorded in scanMetrics under the
+// scanner name and target URL. Runs ended by the caller's context, such as cancelled jobs, are not
+// recorded, nor are held runs. A nil scanMetrics records nothing.
+func MeasureScan(scanMetrics *metrics.Metrics, scanner string, scan ScanFunc) ScanFunc {
+	return func(ctx context.Context, params ScanParams) ScanResult {
+		result := scan(ctx, params)
+		if ctx.Err() == nil && !errors.Is(result.Error, ErrScanHeld) {
+			scanMetrics.RecordScan(scanner, params.Target().URL(), result.Error)
+		}
+
+		return result
+	}
+}
+
+// ScanVhosts runs scan sequentially once per virtual host against the same host and port
+// and merges the outputs into a single report with one section per vhost.
+// The merged result carries an error only when every vhost scan failed.
+func ScanVhosts(ctx context.Context, scan ScanFunc, params ScanParams, vhosts []string) ScanResult {
+	var (
+		builder strings.Builder
+		errs    []error
+	)
+
+	for i, vhost := range vhosts {
+		params.Vhost = vhost
+		result := scan(ctx, params)
+
+		if i > 0 {
+			builder.WriteString("\n")
+		}
+		builder.WriteString(fmt.Sprintf("=== vhost: %s ===\n", vhost))
+		if result.Error != nil {
+			errs = append(errs, fmt.Errorf("vhost %s: %w", vhost, result.Error))
+			builder.WriteString(fmt.Sprintf("ERROR: %s\n", result.Error.Error()))
+		}
+		builder.WriteString(strings.TrimSpace(result.Output))
+		builder.WriteString("\n")
+	}
+
+	merged := ScanResult{Output: builder.String()}
+	if len(errs) == len(vhosts) {
+		merged.Error = errors.Join(errs...)
+	}
+
+	return merged
+}
+
 // BaseScanner provides common functionality for scanner tools.
 // Embed this struct in concrete scanner implementations to reduce code duplication.
 type BaseScanner struct {
 	BinaryName  string
 	Description string
 	Logger      zerolog.Logger
-	Validator   *validator.Validate
+	// enabled reports whether a scanner was disabled at runtime, set on registration.
+	enabled func(name string) bool
+	// limiter bounds concurrent scans across tools, set on registration.
+	limiter *limiter.Limiter
+	// maxResponseBytes bounds the output returned per call, set on registration.
+	maxResponseBytes int
+	// metrics records scan outcomes, set on registration.
+	metrics *metrics.Metrics
+	// captureDir is the directory HAR captures are written to, set on registration.
+	captureDir string
+	// redactor scrubs HAR captures before they are written, set on registration.
+	redactor *redact.Redactor
+	// Options are the scan options the scanner honours, see OptionSupporter.
+	Options   []string
+	Validator *validator.Validate
+	// VersionArgs are the arguments that make the binary print its version, see VersionReporter.
+	VersionArgs []string
+	// WarmUpArgs are benign arguments the binary is run with at startup to verify it works, such
+	// as --help; VersionArgs when unset. See WarmUpChecker.
+	WarmUpArgs []string
+	// warmUp holds the outcome of the last warm-up.
+	warmUp *warmUpState
+	// Passive marks scanners that only run non-intrusive checks, see PassiveScanner.
+	Passive bool
+	// Remote marks scanners that run through a remote service, such as a ZAP daemon, so that they
+	// are available without their binary.
+	Remote bool
+	// ForTechnologies are the technologies the scanner applies to, see TechnologyScanner.
+	ForTechnologies []string
+	// RenderPage renders the output page returned to the client, e.g. as markdown. It is applied
+	// to uncompressed pages only; the stored raw output is left as is.
+	RenderPage func(text string) string
+	// FormatOutput formats the output of scanner tool runs before it is stored and paged, e.g.
+	// grouping results by severity, see FormatScan. full_scan reports the unformatted output.
+	FormatOutput func(output string) string
+	// scanTimeout bounds the runs of inputs without a timeout, 0 for no bound, set on registration.
+	scanTimeout time.Duration
+	// sessions holds the session defaults applied to inputs naming no host, set on registration.
+	sessions *session.Store
+	// workDir is the directory holding the working directories of scanner runs, set on registration.
+	workDir string
+	// configs locates the config files of the scanner, set on registration.
+	configs scanconfig.Config
+	// scope is the allowlist of scan targets, set on registration.
+	scope *scope.Policy
+	// vault decrypts the credentials scans authenticate with, set on registration.
+	vault *vault.Vault
+	// store holds the stored credentials, set on registration.
+	store storage.Storage
+	// wordlists holds the wordlists scans reference by name, set on registration.
+	wordlists *wordlist.Registry
+	// cursors stores the output cursors of truncated outputs, set on registration.
+	cursors CursorStore
 }
 
 // NewBaseScanner creates a new BaseScanner with the given configuration.
-func NewBaseScanner(binaryName, description string, logger zerolog.Logger) BaseScanner {
+// options lists the scan options the scanner honours; any other option is ignored.
+// Without options the scanner keeps the typed TLS and vhost parameters only.
+func NewBaseScanner(binaryName, description string, logger zerolog.Logger, options ...string) BaseScanner {
 	return BaseScanner{
 		BinaryName:  binaryName,
 		Description: description,
 		Logger:      logger.With().Str("tool", binaryName).Logger(),
+		Options:     options,
 		Validator:   validator.New(),
+		warmUp:      &warmUpState{},
 	}
 }
 
@@ -244,10 +598,66 @@ func (b *BaseScanner) Name() string {
 	return b.BinaryName
 }
 
-// IsAvailable checks if the scanner binary is available in PATH.
+// IsPassive reports whether the scanner only runs non-intrusive checks.
+func (b *BaseScanner) IsPassive() bool {
+	return b.Passive
+}
+
+// Technologies returns the technologies the scanner applies to, empty for any target.
+func (b *BaseScanner) Technologies() []string {
+	return b.ForTechnologies
+}
+
+// SupportedOptions returns the scan options the scanner honours. Scanners that declare no options
+// keep the typed TLS and vhost parameters, as with NegotiateOptions.
+func (b *BaseScanner) SupportedOptions() []string {
+	if b.Options == nil {
+		return legacyOptions
+	}
+	return b.Options
+}
+
+// IsAvailable checks if the scanner binary is bundled or available in PATH, or the scanner is
+// Remote, and did not fail its warm-up, see WarmUp.
 func (b *BaseScanner) IsAvailable() bool {
-	_, err := exec.LookPath(b.BinaryName)
-	return err == nil
+	if b.Remote {
+		return b.Diagnostics() == ""
+	}
+	_, _, err := bundle.LookPath(b.BinaryName)
+	return err == nil && b.Diagnostics() == ""
+}
+
+// Path returns the path the scanner binary runs from: the bundled binary when a tool bundle is in
+// use and has it, otherwise the binary found in PATH. It falls back to the binary name, so that
+// running it reports the lookup error.
+func (b *BaseScanner) Path() string {
+	path, _, err := bundle.LookPath(b.BinaryName)
+	if err != nil {
+		return b.BinaryName
+	}
+	return path
+}
+
+// Command returns the command running the scanner binary from Path with args. The binary lookup
+// is recorded as the availability phase of the in-flight execution, see RecordPhase.
+func (b *BaseScanner) Command(ctx context.Context, args ...string) *exec.Cmd {
+	start := time.Now()
+	path := b.Path()
+	RecordPhase(ctx, models.PhaseAvailability, time.Since(start))
+
+	return exec.CommandContext(ctx, path, args...) //nolint:gosec
+}
+
+// WorkDir creates the isolated working directory of a scanner run, see ScanWorkDir.
+func (b *BaseScanner) WorkDir(ctx context.Context) (string, func(), error) {
+	return ScanWorkDir(ctx, b.workDir, b.BinaryName)
+}
+
+// ConfigFile returns the config file the scanner runs with for params: the file named by the
+// config option, or otherwise the server default, see scanconfig.Config.Resolve. It is empty when
+// the scanner runs with its built-in configuration.
+func (b *BaseScanner) ConfigFile(params ScanParams) (string, error) {
+	return b.configs.Resolve(b.BinaryName, params.Option(OptionConfig))
 }
 
 // ValidateInput validates the scanner input using the validator.
@@ -258,18 +668,9 @@ func (b *BaseScanner) ValidateInput(input any) error {
 	return nil
 }
 
-// PrepareInput parses URL-style hosts in the input and replaces the Host field
-// with the plain hostname so that validation (hostname|ip) passes.
-// It also copies a URL-embedded port to input.Port when port was not explicitly set.
+// PrepareInput prepares URL-style hosts in the input for validation, see PrepareScannerInput.
 func (b *BaseScanner) PrepareInput(input ScannerInput) ScannerInput {
-	parsed := ParseHostInput(input.Host)
-	input.Host = parsed.Host
-
-	if input.Port == 0 && parsed.Port != 0 {
-		input.Port = parsed.Port
-	}
-
-	return input
+	return PrepareScannerInput(input)
 }
 
 // ResolveInput resolves a ScannerInput into a ScanParams with defaults applied.
@@ -277,17 +678,172 @@ func (b *BaseScanner) ResolveInput(input ScannerInput) ScanParams {
 	return ResolveParams(input)
 }
 
+// HandleScan implements the common MCP handler flow for scanner tools: input preparation,
+// validation, resolution, optional redirect normalization, scanning (once per vhost when a
+// vhost list is given) and output formatting.
+func (b *BaseScanner) HandleScan(
+	ctx context.Context,
+	input ScannerInput,
+	headerVerb string,
+	scan ScanFunc,
+) (*mcp.CallToolResult, any, error) {
+	if b.enabled != nil && !b.enabled(b.BinaryName) {
+		return nil, nil, fmt.Errorf("%w: %s", ErrScannerDisabled, b.BinaryName)
+	}
+
+	if withDefaults, ok := ApplySessionDefaults(ctx, b.sessions, input); ok {
+		input = withDefaults
+		RecordInput(ctx, input)
+	}
+	input = b.PrepareInput(input)
+
+	if err := b.ValidateInput(input); err != nil {
+		return nil, nil, err
+	}
+	if err := ValidateOptions(input.Options); err != nil {
+		return nil, nil, fmt.Errorf("validation error: %w", err)
+	}
+	start, err := StartCursor(input.Cursor, input.Offset)
+	if err != nil {
+		return nil, nil, fmt.Errorf("validation error: %w", err)
+	}
+	if input.Passive && !b.Passive {
+		return nil, nil, fmt.Errorf("%w: %s", ErrActiveScanner, b.BinaryName)
+	}
+
+	ctx = PrioritizeScan(ctx, input.Priority)
+	logger := ContextLogger(ctx, b.Logger)
+	params := b.ResolveInput(input)
+	if params.Timeout == 0 {
+		params.Timeout = b.scanTimeout
+	}
+	if err := b.scope.Check(params.Host, params.Port); err != nil {
+		return nil, nil, err
+	}
+	if input.Credential != "" {
+		if params.Credential, err = b.vault.Resolve(ctx, b.store, input.Credential); err != nil {
+			return nil, nil, err
+		}
+	}
+	if input.Wordlist != "" {
+		if params.Wordlist, err = b.wordlists.Resolve(ctx, input.Wordlist); err != nil {
+			return nil, nil, err
+		}
+	}
+	requestedURL := params.Target().URL()
+	if input.FollowRedirects {
+		if params, err = FollowRedirects(ctx, logger, params, b.scope); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	supported := b.SupportedOptions()
+	params, ignored := params.Restrict(supported)
+	vhosts := input.Vhosts
+	if len(vhosts) > 0 && !slices.Contains(supported, OptionVhost) {
+		ignored = append(ignored, "vhosts")
+		vhosts = nil
+	}
+	if len(ignored) > 0 {
+		logger.Warn().Strs("options", ignored).Msgf("Options not supported by %s, ignoring", b.BinaryName)
+	}
+
+	scan = TimelineScan(b.BinaryName, MeasureScan(b.metrics, b.BinaryName, LimitScan(b.limiter, TimeoutScan(
+		CaptureScan(b.captureDir, b.redactor, b.Logger, b.BinaryName, FormatScan(b.FormatOutput, SanitizeScan(scan)))))))
+
+	var scanResult ScanResult
+	if len(vhosts) > 0 {
+		scanResult = ScanVhosts(ctx, scan, params, vhosts)
+	} else {
+		scanResult = scan(ctx, params)
+	}
+	if scanResult.Error != nil {
+		return nil, nil, fmt.Errorf("%w\nOutput: %s", scanResult.Error, scanResult.Output)
+	}
+
+	RecordRawOutput(ctx, scanResult.Output)
+
+	targetURL := params.Target().URL()
+	var (
+		resultText string
+		page       ResponsePage
+		resource   *mcp.EmbeddedResource
+	)
+	if UseCompression(input.Compression, len(scanResult.Output), 0) {
+		resultText, resource, page, err = FormatCompressedPage(ctx, b.BinaryName, headerVerb, targetURL, scanResult.Output, input.MaxLines, start, b.maxResponseBytes)
+		if err != nil {
+			return nil, nil, err
+		}
+	} else {
+		resultText, page = FormatScannerPage(b.BinaryName, headerVerb, targetURL, scanResult.Output, input.MaxLines, start, b.maxResponseBytes)
+		if b.RenderPage != nil {
+			resultText = b.RenderPage(resultText)
+		}
+	}
+	token, err := IssueContinuation(ctx, b.cursors, ExecutionID(ctx), page, input.MaxLines)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Returning truncated output without an output cursor")
+	}
+	if token != "" {
+		resultText += ContinuationNotice(token)
+	}
+	if targetURL != requestedURL {
+		resultText = fmt.Sprintf("[Requested target %s redirected to effective target %s]\n", requestedURL, targetURL) + resultText
+	}
+	if len(ignored) > 0 {
+		resultText = fmt.Sprintf("[Options not supported by %s were ignored: %s]\n", b.BinaryName, strings.Join(ignored, ", ")) + resultText
+	}
+
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: resultText},
+		},
+	}
+	if resource != nil {
+		result.Content = append(result.Content, resource)
+	}
+	if page.Next != nil {
+		result.Meta = mcp.Meta{NextCursorField: page.Next.String()}
+	}
+	if token != "" {
+		if result.Meta == nil {
+			result.Meta = mcp.Meta{}
+		}
+		result.Meta[OutputCursorField] = token
+	}
+
+	return result, nil, nil
+}
+
 // RegisterTool is a helper to register a scanner tool with the MCP server.
 // It handles availability check, tool creation, and handler wrapping.
 func (b *BaseScanner) RegisterTool(
 	srv *server.Server,
 	handler func(context.Context, *mcp.CallToolRequest, ScannerInput) (*mcp.CallToolResult, any, error),
 ) error {
+	if diagnostics := b.Diagnostics(); diagnostics != "" {
+		return fmt.Errorf("%s is unavailable: %s", b.BinaryName, diagnostics)
+	}
 	if !b.IsAvailable() {
 		return fmt.Errorf("%s binary not found", b.BinaryName)
 	}
 
 	b.Logger.Debug().Msgf("%s binary found", b.BinaryName)
+	b.enabled = srv.ScannerEnabled
+	b.limiter = srv.ScanLimiter()
+	b.maxResponseBytes = srv.MaxResponseBytes()
+	b.metrics = srv.Metrics()
+	b.captureDir = CaptureDir(srv.Artifacts().Dir)
+	b.redactor = srv.Redactor()
+	b.sessions = srv.Sessions()
+	b.scanTimeout = srv.ScanTimeout()
+	b.workDir = srv.WorkDir()
+	b.configs = srv.ScannerConfigs()
+	b.scope = srv.Scope()
+	b.vault = srv.Vault()
+	b.store = srv.Storage()
+	b.wordlists = srv.Wordlists()
+	b.cursors = srv.Storage()
 
 	tool := &mcp.Tool{
 		Name:        b.BinaryName,
@@ -298,9 +854,10 @@ func (b *BaseScanner) RegisterTool(
 		srv.Storage(),
 		b.BinaryName,
 		handler,
+		ServerWrapOptions(srv)...,
 	)
 
-	mcp.AddTool(&srv.Server, tool, wrappedHandler)
+	mcp.AddTool(&srv.Server, tool, tenant.RequireOperator(ScanAction, wrappedHandler))
 	b.Logger.Debug().Msgf("%s tool registered", b.BinaryName)
 
 	return nil
@@ -1,18 +1,34 @@
 package tools
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/crypto"
+	"github.com/tb0hdan/wass-mcp/pkg/limiter"
+	"github.com/tb0hdan/wass-mcp/pkg/metrics"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/scope"
+	"github.com/tb0hdan/wass-mcp/pkg/tenant"
 	"github.com/tb0hdan/wass-mcp/pkg/types"
+	"github.com/tb0hdan/wass-mcp/pkg/vault"
+	"github.com/tb0hdan/wass-mcp/pkg/wordlist"
 )
 
 type ToolsTestSuite struct {
 	suite.Suite
 }
 
-// ParseHostInput tests.
+// PrepareScannerInput tests.
 
 func (s *ToolsTestSuite) TestValidateInput_HostnameStartingWithDigit() {
 	bs := NewBaseScanner("test", "test", zerolog.Nop())
@@ -21,53 +37,28 @@ func (s *ToolsTestSuite) TestValidateInput_HostnameStartingWithDigit() {
 	s.NoError(bs.ValidateInput(&input))
 }
 
-func (s *ToolsTestSuite) TestParseHostInput_PlainHostname() {
-	result := ParseHostInput("example.com")
-	s.Equal("example.com", result.Host)
-	s.Equal("", result.Scheme)
-	s.Equal(0, result.Port)
+func (s *ToolsTestSuite) TestPrepareScannerInput_URL() {
+	input := PrepareScannerInput(ScannerInput{Host: "https://example.com:8443/app"})
+	s.Equal("example.com", input.Host)
+	s.Equal(8443, input.Port)
+	s.Equal(types.SchemeHTTPS, input.Scheme)
+	s.Equal("/app", input.Path)
 }
 
-func (s *ToolsTestSuite) TestParseHostInput_PlainIP() {
-	result := ParseHostInput("192.168.1.1")
-	s.Equal("192.168.1.1", result.Host)
-	s.Equal("", result.Scheme)
-	s.Equal(0, result.Port)
+func (s *ToolsTestSuite) TestPrepareScannerInput_ExplicitFieldsWin() {
+	input := PrepareScannerInput(ScannerInput{Host: "https://example.com:8443/app", Path: "/api", Port: 9443, Scheme: "http"})
+	s.Equal("example.com", input.Host)
+	s.Equal(9443, input.Port)
+	s.Equal(types.SchemeHTTP, input.Scheme)
+	s.Equal("/api", input.Path)
 }
 
-func (s *ToolsTestSuite) TestParseHostInput_Empty() {
-	result := ParseHostInput("")
-	s.Equal("", result.Host)
-	s.Equal("", result.Scheme)
-	s.Equal(0, result.Port)
-}
-
-func (s *ToolsTestSuite) TestParseHostInput_HTTPSUrl() {
-	result := ParseHostInput("https://example.com")
-	s.Equal("example.com", result.Host)
-	s.Equal("https", result.Scheme)
-	s.Equal(0, result.Port)
-}
-
-func (s *ToolsTestSuite) TestParseHostInput_HTTPUrl() {
-	result := ParseHostInput("http://example.com")
-	s.Equal("example.com", result.Host)
-	s.Equal("http", result.Scheme)
-	s.Equal(0, result.Port)
-}
-
-func (s *ToolsTestSuite) TestParseHostInput_URLWithPort() {
-	result := ParseHostInput("https://example.com:8443")
-	s.Equal("example.com", result.Host)
-	s.Equal("https", result.Scheme)
-	s.Equal(8443, result.Port)
-}
-
-func (s *ToolsTestSuite) TestParseHostInput_URLWithPath() {
-	result := ParseHostInput("https://example.com/path")
-	s.Equal("example.com", result.Host)
-	s.Equal("https", result.Scheme)
-	s.Equal(0, result.Port)
+func (s *ToolsTestSuite) TestPrepareScannerInput_KeepsSchemeThroughResolve() {
+	// Handlers prepare the input before resolving it; the URL scheme must survive both steps.
+	params := ResolveParams(PrepareScannerInput(ScannerInput{Host: "https://example.com:8443"}))
+	s.Equal(types.SchemeHTTPS, params.Scheme)
+	s.Equal(8443, params.Port)
+	s.Equal("https://example.com:8443", params.Target().URL())
 }
 
 // ResolveParams tests.
@@ -121,53 +112,478 @@ func (s *ToolsTestSuite) TestResolveParams_ExplicitPortOverridesURLPort() {
 	s.Equal(types.SchemeHTTPS, params.Scheme)
 }
 
+func (s *ToolsTestSuite) TestResolveParams_Path() {
+	params := ResolveParams(ScannerInput{Host: "https://example.com/app/"})
+	s.Equal("/app/", params.Path)
+	s.Equal("https://example.com/app/", params.Target().URL())
+}
+
+func (s *ToolsTestSuite) TestResolveParams_ExplicitScheme() {
+	params := ResolveParams(ScannerInput{Host: "example.com", Scheme: types.SchemeHTTPS})
+	s.Equal(443, params.Port)
+	s.Equal(types.SchemeHTTPS, params.Scheme)
+}
+
 func (s *ToolsTestSuite) TestResolveParams_Vhost() {
 	params := ResolveParams(ScannerInput{Host: "192.168.1.1", Port: 80, Vhost: "test.com"})
 	s.Equal("test.com", params.Vhost)
 }
 
-// BuildTargetURL tests.
+// ScanParams.Target tests.
+
+func (s *ToolsTestSuite) TestScanParams_Target() {
+	params := ScanParams{Host: "::1", Path: "/app", Port: 8443, Scheme: types.SchemeHTTPS, Vhost: "example.com"}
+	s.Equal("https://[::1]:8443/app", params.Target().URL())
+	s.Equal("Host: example.com", params.Target().HostHeader())
+}
+
+// ScanVhosts tests.
+
+func (s *ToolsTestSuite) TestScanVhosts_MergesSections() {
+	var seen []string
+	scan := func(_ context.Context, params ScanParams) ScanResult {
+		seen = append(seen, params.Vhost)
+		return ScanResult{Output: "output for " + params.Vhost}
+	}
+
+	result := ScanVhosts(context.Background(), scan, ScanParams{Host: "10.0.0.1", Port: 80}, []string{"a.example.com", "b.example.com"})
+	s.NoError(result.Error)
+	s.Equal([]string{"a.example.com", "b.example.com"}, seen)
+	s.Contains(result.Output, "=== vhost: a.example.com ===\noutput for a.example.com")
+	s.Contains(result.Output, "=== vhost: b.example.com ===\noutput for b.example.com")
+}
+
+func (s *ToolsTestSuite) TestScanVhosts_PartialFailure() {
+	scan := func(_ context.Context, params ScanParams) ScanResult {
+		if params.Vhost == "bad.example.com" {
+			return ScanResult{Output: "partial", Error: errors.New("boom")}
+		}
+		return ScanResult{Output: "ok"}
+	}
+
+	result := ScanVhosts(context.Background(), scan, ScanParams{Host: "10.0.0.1", Port: 80}, []string{"good.example.com", "bad.example.com"})
+	s.NoError(result.Error)
+	s.Contains(result.Output, "ERROR: boom")
+}
+
+func (s *ToolsTestSuite) TestScanVhosts_AllFailed() {
+	scan := func(_ context.Context, _ ScanParams) ScanResult {
+		return ScanResult{Error: errors.New("boom")}
+	}
+
+	result := ScanVhosts(context.Background(), scan, ScanParams{Host: "10.0.0.1", Port: 80}, []string{"a.example.com", "b.example.com"})
+	s.Error(result.Error)
+	s.Contains(result.Error.Error(), "vhost a.example.com")
+	s.Contains(result.Error.Error(), "vhost b.example.com")
+}
+
+// HandleScan tests.
+
+func (s *ToolsTestSuite) TestHandleScan_WithVhosts() {
+	bs := NewBaseScanner("test", "test", zerolog.Nop())
+	scan := func(_ context.Context, params ScanParams) ScanResult {
+		return ScanResult{Output: "scanned " + params.Vhost}
+	}
+
+	input := ScannerInput{Host: "10.0.0.1", Vhosts: []string{"a.example.com", "b.example.com"}}
+	result, _, err := bs.HandleScan(context.Background(), input, "output", scan)
+	s.Require().NoError(err)
 
-func (s *ToolsTestSuite) TestBuildTargetURL_HTTP() {
-	result := BuildTargetURL(ScanParams{Host: "localhost", Port: 80, Scheme: types.SchemeHTTP})
-	s.Equal("http://localhost", result)
+	text := result.Content[0].(*mcp.TextContent).Text
+	s.Contains(text, "test output for http://10.0.0.1:")
+	s.Contains(text, "scanned a.example.com")
+	s.Contains(text, "scanned b.example.com")
 }
 
-func (s *ToolsTestSuite) TestBuildTargetURL_HTTPS() {
-	result := BuildTargetURL(ScanParams{Host: "example.com", Port: 443, Scheme: types.SchemeHTTPS})
-	s.Equal("https://example.com", result)
+func (s *ToolsTestSuite) TestHandleScan_URLHost() {
+	bs := NewBaseScanner("test", "test", zerolog.Nop())
+	var scanned ScanParams
+	scan := func(_ context.Context, params ScanParams) ScanResult {
+		scanned = params
+		return ScanResult{Output: "ok"}
+	}
+
+	result, _, err := bs.HandleScan(context.Background(), ScannerInput{Host: "https://example.com:8443/app"}, "output", scan)
+	s.Require().NoError(err)
+	s.Equal(ScanParams{Host: "example.com", Path: "/app", Port: 8443, Scheme: types.SchemeHTTPS}, scanned)
+	s.Contains(result.Content[0].(*mcp.TextContent).Text, "test output for https://example.com:8443/app:")
 }
 
-func (s *ToolsTestSuite) TestBuildTargetURL_CustomPort() {
-	result := BuildTargetURL(ScanParams{Host: "example.com", Port: 8080, Scheme: types.SchemeHTTP})
-	s.Equal("http://example.com:8080", result)
+func (s *ToolsTestSuite) TestHandleScan_IgnoresUnsupportedOptions() {
+	bs := NewBaseScanner("test", "test", zerolog.Nop(), OptionUserAgent)
+	var scanned ScanParams
+	scan := func(_ context.Context, params ScanParams) ScanResult {
+		scanned = params
+		return ScanResult{Output: "scanned " + params.Vhost}
+	}
+
+	input := ScannerInput{
+		Host:               "10.0.0.1",
+		InsecureSkipVerify: true,
+		Options:            map[string]string{OptionUserAgent: "wass", "future": "x"},
+		Vhosts:             []string{"a.example.com", "b.example.com"},
+	}
+	result, _, err := bs.HandleScan(context.Background(), input, "output", scan)
+	s.Require().NoError(err)
+
+	s.False(scanned.InsecureSkipVerify)
+	s.Empty(scanned.Vhost)
+	s.Equal(map[string]string{OptionUserAgent: "wass"}, scanned.Options)
+
+	text := result.Content[0].(*mcp.TextContent).Text
+	s.Contains(text, "[Options not supported by test were ignored: future, insecure_skip_verify, vhosts]")
+	s.Equal(1, strings.Count(text, "scanned"))
 }
 
-func (s *ToolsTestSuite) TestBuildTargetURL_HTTPSCustomPort() {
-	result := BuildTargetURL(ScanParams{Host: "example.com", Port: 8443, Scheme: types.SchemeHTTPS})
-	s.Equal("https://example.com:8443", result)
+func (s *ToolsTestSuite) TestHandleScan_Credential() {
+	store, cleanup := setupTestStorage(s.T())
+	defer cleanup()
+	keyring, err := crypto.NewKeyring(make([]byte, crypto.KeySize))
+	s.Require().NoError(err)
+	credentialVault := vault.New(keyring)
+	ctx := tenant.WithTenant(context.Background(), "alpha")
+	sealed, err := credentialVault.Seal(vault.Secret{Token: "abc"})
+	s.Require().NoError(err)
+	s.Require().NoError(store.SaveCredential(ctx, &models.Credential{Name: "api", Type: vault.TypeBearer, Secret: sealed}))
+
+	var scanned ScanParams
+	scan := func(_ context.Context, params ScanParams) ScanResult {
+		scanned = params
+		return ScanResult{Output: "done"}
+	}
+	input := ScannerInput{Host: "example.com", Credential: "api"}
+
+	bs := NewBaseScanner("test", "test", zerolog.Nop(), OptionCredential)
+	_, _, err = bs.HandleScan(ctx, input, "output", scan)
+	s.ErrorIs(err, vault.ErrDisabled)
+
+	bs.vault = credentialVault
+	bs.store = store
+	_, _, err = bs.HandleScan(ctx, input, "output", scan)
+	s.Require().NoError(err)
+	s.Require().NotNil(scanned.Credential)
+	s.Equal("abc", scanned.Credential.Token)
+
+	_, _, err = bs.HandleScan(tenant.WithTenant(context.Background(), "beta"), input, "output", scan)
+	s.ErrorContains(err, "credential api not found")
 }
 
-func (s *ToolsTestSuite) TestBuildTargetURL_IPv6() {
-	result := BuildTargetURL(ScanParams{Host: "::1", Port: 443, Scheme: types.SchemeHTTPS})
-	s.Equal("https://[::1]", result)
+func (s *ToolsTestSuite) TestHandleScan_Wordlist() {
+	ctx := tenant.WithTenant(context.Background(), "alpha")
+	var scanned ScanParams
+	scan := func(_ context.Context, params ScanParams) ScanResult {
+		scanned = params
+		return ScanResult{Output: "done"}
+	}
+	input := ScannerInput{Host: "example.com", Wordlist: "raft-medium"}
+
+	bs := NewBaseScanner("test", "test", zerolog.Nop(), OptionWordlist)
+	_, _, err := bs.HandleScan(ctx, input, "output", scan)
+	s.ErrorIs(err, wordlist.ErrDisabled)
+
+	bs.wordlists = wordlist.New(s.T().TempDir(), 0)
+	_, _, err = bs.HandleScan(ctx, input, "output", scan)
+	s.ErrorIs(err, wordlist.ErrNotFound)
+
+	_, err = bs.wordlists.Save(ctx, "raft-medium", strings.NewReader("admin\n"))
+	s.Require().NoError(err)
+	_, _, err = bs.HandleScan(ctx, input, "output", scan)
+	s.Require().NoError(err)
+	s.Equal("raft-medium.txt", filepath.Base(scanned.Wordlist))
+	data, err := os.ReadFile(scanned.Wordlist)
+	s.Require().NoError(err)
+	s.Equal("admin\n", string(data))
 }
 
-func (s *ToolsTestSuite) TestBuildTargetURL_IPv6HTTP() {
-	result := BuildTargetURL(ScanParams{Host: "::1", Port: 80, Scheme: types.SchemeHTTP})
-	s.Equal("http://[::1]", result)
+func (s *ToolsTestSuite) TestHandleScan_Disabled() {
+	bs := NewBaseScanner("test", "test", zerolog.Nop())
+	bs.enabled = func(name string) bool { return name != "test" }
+	scan := func(_ context.Context, _ ScanParams) ScanResult {
+		s.Fail("disabled scanner must not scan")
+		return ScanResult{}
+	}
+
+	_, _, err := bs.HandleScan(context.Background(), ScannerInput{Host: "10.0.0.1"}, "output", scan)
+	s.ErrorIs(err, ErrScannerDisabled)
+	s.ErrorContains(err, "scanner is disabled: test")
 }
 
-func (s *ToolsTestSuite) TestBuildTargetURL_IPv6CustomPort() {
-	result := BuildTargetURL(ScanParams{Host: "::1", Port: 8080, Scheme: types.SchemeHTTP})
-	s.Equal("http://[::1]:8080", result)
+func (s *ToolsTestSuite) TestHandleScan_Scope() {
+	policy, err := scope.New("*.internal.example.com:8000-8999")
+	s.Require().NoError(err)
+	bs := NewBaseScanner("test", "test", zerolog.Nop())
+	bs.scope = policy
+	var scans int
+	scan := func(_ context.Context, _ ScanParams) ScanResult {
+		scans++
+		return ScanResult{Output: "done"}
+	}
+
+	_, _, err = bs.HandleScan(context.Background(), ScannerInput{Host: "api.internal.example.com", Port: 8080}, "output", scan)
+	s.Require().NoError(err)
+
+	_, _, err = bs.HandleScan(context.Background(), ScannerInput{Host: "https://api.internal.example.com"}, "output", scan)
+	s.ErrorIs(err, scope.ErrOutOfScope)
+	s.ErrorContains(err, "api.internal.example.com:443")
+
+	_, _, err = bs.HandleScan(context.Background(), ScannerInput{Host: "example.com", Port: 8080}, "output", scan)
+	s.ErrorIs(err, scope.ErrOutOfScope)
+	s.Equal(1, scans)
 }
 
-func (s *ToolsTestSuite) TestBuildTargetURL_EmptySchemeDefaultsHTTP() {
-	result := BuildTargetURL(ScanParams{Host: "example.com", Port: 80})
-	s.Equal("http://example.com", result)
+func (s *ToolsTestSuite) TestMeasureScan() {
+	scanMetrics := metrics.New()
+	failing := MeasureScan(scanMetrics, "test", func(_ context.Context, _ ScanParams) ScanResult {
+		return ScanResult{Error: errors.New("boom")}
+	})
+	params := ScanParams{Host: "example.com", Port: 443, Scheme: "https"}
+
+	s.Error(failing(context.Background(), params).Error)
+	s.Error(failing(context.Background(), params).Error)
+	s.Equal(2, scanMetrics.ConsecutiveFailures("test"))
+	s.Equal(2, scanMetrics.TargetFailures("test", "https://example.com"))
+
+	// Runs ended by the caller's context are not the scanner's failure.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s.Error(failing(ctx, params).Error)
+	s.Equal(2, scanMetrics.ConsecutiveFailures("test"))
+
+	succeeding := MeasureScan(scanMetrics, "test", func(_ context.Context, _ ScanParams) ScanResult {
+		return ScanResult{Output: "ok"}
+	})
+	s.Equal("ok", succeeding(context.Background(), params).Output)
+	s.Equal(0, scanMetrics.ConsecutiveFailures("test"))
+
+	// A nil recorder only runs the scan.
+	s.Equal("ok", MeasureScan(nil, "test", succeeding)(context.Background(), params).Output)
+}
+
+func (s *ToolsTestSuite) TestLimitScan() {
+	scanLimiter := limiter.New(1)
+	scan := LimitScan(scanLimiter, func(_ context.Context, _ ScanParams) ScanResult {
+		return ScanResult{Output: fmt.Sprintf("in flight %d", scanLimiter.InFlight())}
+	})
+
+	s.Equal("in flight 1", scan(context.Background(), ScanParams{}).Output)
+	s.Equal(0, scanLimiter.InFlight())
+
+	// A run waiting for a slot fails once its context is done.
+	s.Require().NoError(scanLimiter.Acquire(context.Background()))
+	defer scanLimiter.Release()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	result := scan(ctx, ScanParams{})
+	s.Require().Error(result.Error)
+	s.ErrorIs(result.Error, context.Canceled)
+}
+
+func (s *ToolsTestSuite) TestTimeoutScan() {
+	waiting := func(ctx context.Context, _ ScanParams) ScanResult {
+		<-ctx.Done()
+		return ScanResult{Output: "partial", Error: errors.New("signal: killed")}
+	}
+
+	result := TimeoutScan(waiting)(context.Background(), ScanParams{Timeout: 20 * time.Millisecond})
+	s.ErrorIs(result.Error, ErrScanTimedOut)
+	s.EqualError(result.Error, "scan timed out: signal: killed")
+	s.Equal("partial", result.Output)
+
+	// The deadline of the caller counts as well.
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	s.ErrorIs(TimeoutScan(waiting)(ctx, ScanParams{}).Error, ErrScanTimedOut)
+
+	// Cancelled runs and failures before the deadline are not timeouts.
+	cancelled, cancelRun := context.WithCancel(context.Background())
+	cancelRun()
+	s.NotErrorIs(TimeoutScan(waiting)(cancelled, ScanParams{Timeout: time.Minute}).Error, ErrScanTimedOut)
+	failing := func(_ context.Context, _ ScanParams) ScanResult {
+		return ScanResult{Error: errors.New("boom")}
+	}
+	s.EqualError(TimeoutScan(failing)(context.Background(), ScanParams{Timeout: time.Minute}).Error, "boom")
+}
+
+func (s *ToolsTestSuite) TestHandleScan_Timeout() {
+	bs := NewBaseScanner("test", "test", zerolog.Nop())
+	scan := func(ctx context.Context, _ ScanParams) ScanResult {
+		<-ctx.Done()
+		return ScanResult{Output: "partial", Error: errors.New("signal: killed")}
+	}
+
+	_, _, err := bs.HandleScan(context.Background(), ScannerInput{Host: "10.0.0.1", Timeout: 1}, "output", scan)
+	s.ErrorIs(err, ErrScanTimedOut)
+	s.ErrorContains(err, "Output: partial")
+
+	_, _, err = bs.HandleScan(context.Background(), ScannerInput{Host: "10.0.0.1", Timeout: -1}, "output", scan)
+	s.ErrorContains(err, "validation error")
+
+	// Inputs without a timeout run with the server default.
+	bs.scanTimeout = 20 * time.Millisecond
+	_, _, err = bs.HandleScan(context.Background(), ScannerInput{Host: "10.0.0.1"}, "output", scan)
+	s.ErrorIs(err, ErrScanTimedOut)
+}
+
+func (s *ToolsTestSuite) TestPrioritizeScan() {
+	s.Empty(limiter.PriorityFrom(PrioritizeScan(context.Background(), "")))
+	s.Equal(limiter.PriorityHigh, limiter.PriorityFrom(PrioritizeScan(context.Background(), limiter.PriorityHigh)))
+
+	// Background runs keep their priority whatever the stored input asks for.
+	background := limiter.WithPriority(context.Background(), limiter.PriorityLow)
+	s.Equal(limiter.PriorityLow, limiter.PriorityFrom(PrioritizeScan(background, limiter.PriorityHigh)))
+}
+
+func (s *ToolsTestSuite) TestHandleScan_Priority() {
+	bs := NewBaseScanner("test", "test", zerolog.Nop())
+	var priority string
+	scan := func(ctx context.Context, _ ScanParams) ScanResult {
+		priority = limiter.PriorityFrom(ctx)
+		return ScanResult{}
+	}
+
+	_, _, err := bs.HandleScan(context.Background(), ScannerInput{Host: "10.0.0.1", Priority: "high"}, "output", scan)
+	s.Require().NoError(err)
+	s.Equal(limiter.PriorityHigh, priority)
+
+	_, _, err = bs.HandleScan(context.Background(), ScannerInput{Host: "10.0.0.1", Priority: "urgent"}, "output", scan)
+	s.ErrorContains(err, "validation error")
+}
+
+func (s *ToolsTestSuite) TestHandleScan_InvalidOption() {
+	bs := NewBaseScanner("test", "test", zerolog.Nop(), OptionMaxDepth)
+	scanned := false
+	scan := func(_ context.Context, _ ScanParams) ScanResult {
+		scanned = true
+		return ScanResult{}
+	}
+
+	input := ScannerInput{Host: "10.0.0.1", Options: map[string]string{OptionMaxDepth: "deep"}}
+	_, _, err := bs.HandleScan(context.Background(), input, "output", scan)
+	s.ErrorContains(err, "validation error: option max_depth")
+	s.False(scanned)
+}
+
+func (s *ToolsTestSuite) TestHandleScan_ValidationErrorEmptyVhost() {
+	bs := NewBaseScanner("test", "test", zerolog.Nop())
+	scan := func(_ context.Context, _ ScanParams) ScanResult {
+		return ScanResult{}
+	}
+
+	input := ScannerInput{Host: "10.0.0.1", Vhosts: []string{""}}
+	_, _, err := bs.HandleScan(context.Background(), input, "output", scan)
+	s.Error(err)
+	s.Contains(err.Error(), "validation error")
+}
+
+func (s *ToolsTestSuite) TestHandleScan_ScanError() {
+	bs := NewBaseScanner("test", "test", zerolog.Nop())
+	scan := func(_ context.Context, _ ScanParams) ScanResult {
+		return ScanResult{Output: "partial", Error: errors.New("boom")}
+	}
+
+	result, _, err := bs.HandleScan(context.Background(), ScannerInput{}, "output", scan)
+	s.Nil(result)
+	s.Error(err)
+	s.Contains(err.Error(), "boom")
+	s.Contains(err.Error(), "Output: partial")
+}
+
+func (s *ToolsTestSuite) TestSanitizeScan() {
+	scan := func(_ context.Context, _ ScanParams) ScanResult {
+		return ScanResult{Output: "\x1b[31m+ /admin/\x1b[0m found\r\n\xff", Error: errors.New("boom")}
+	}
+
+	result := SanitizeScan(scan)(context.Background(), ScanParams{})
+	s.Equal("+ /admin/ found\n", result.Output)
+	s.EqualError(result.Error, "boom")
+}
+
+func (s *ToolsTestSuite) TestHandleScan_SanitizesOutput() {
+	bs := NewBaseScanner("test", "test", zerolog.Nop())
+	scan := func(_ context.Context, _ ScanParams) ScanResult {
+		return ScanResult{Output: "\x1b[1;32m+ Server: nginx\x1b[0m\n50%\r100%"}
+	}
+
+	result, _, err := bs.HandleScan(context.Background(), ScannerInput{Host: "example.com"}, "output", scan)
+	s.Require().NoError(err)
+	text := result.Content[0].(*mcp.TextContent).Text
+	s.True(strings.HasSuffix(text, "\n+ Server: nginx\n100%"), text)
+}
+
+func (s *ToolsTestSuite) TestHandleScan_FormatsOutput() {
+	bs := NewBaseScanner("test", "test", zerolog.Nop())
+	bs.FormatOutput = strings.ToUpper
+	scan := func(_ context.Context, _ ScanParams) ScanResult {
+		return ScanResult{Output: "\x1b[1;32m+ server: nginx\x1b[0m"}
+	}
+
+	result, _, err := bs.HandleScan(context.Background(), ScannerInput{Host: "example.com"}, "output", scan)
+	s.Require().NoError(err)
+	text := result.Content[0].(*mcp.TextContent).Text
+	s.True(strings.HasSuffix(text, "\n+ SERVER: NGINX"), "output is formatted once sanitized: %s", text)
+
+	s.Equal("x", FormatScan(nil, func(context.Context, ScanParams) ScanResult { return ScanResult{Output: "x"} })(
+		context.Background(), ScanParams{}).Output)
+}
+
+func (s *ToolsTestSuite) TestHandleScan_Passive() {
+	bs := NewBaseScanner("test", "test", zerolog.Nop())
+	called := false
+	scan := func(_ context.Context, _ ScanParams) ScanResult {
+		called = true
+		return ScanResult{Output: "ok"}
+	}
+
+	_, _, err := bs.HandleScan(context.Background(), ScannerInput{Host: "example.com", Passive: true}, "output", scan)
+	s.ErrorIs(err, ErrActiveScanner)
+	s.False(called)
+	s.False(bs.IsPassive())
+
+	bs.Passive = true
+	_, _, err = bs.HandleScan(context.Background(), ScannerInput{Host: "example.com", Passive: true}, "output", scan)
+	s.Require().NoError(err)
+	s.True(called)
+}
+
+func (s *ToolsTestSuite) TestApplyPagination() {
+	output := "line 1\nline 2\nline 3\nline 4\n"
+	for _, tc := range []struct {
+		maxLines, offset int
+		start            int
+		lines            []string
+		truncated        bool
+	}{
+		{maxLines: 2, offset: 0, start: 0, lines: []string{"line 1", "line 2"}, truncated: true},
+		{maxLines: 2, offset: 3, start: 3, lines: []string{"line 4", ""}},
+		{maxLines: 10, offset: 1, start: 1, lines: []string{"line 2", "line 3", "line 4", ""}},
+		{maxLines: 1, offset: 4, start: 4, lines: []string{""}},
+		{maxLines: 2, offset: 5, start: 0, lines: []string{"line 1", "line 2"}, truncated: true},
+	} {
+		pagination := ApplyPagination(output, tc.maxLines, tc.offset)
+		s.Equal(tc.lines, pagination.Lines, "offset %d", tc.offset)
+		s.Equal(tc.start, pagination.StartLine)
+		s.Equal(tc.start+len(tc.lines), pagination.EndLine)
+		s.Equal(5, pagination.TotalLines)
+		s.Equal(tc.truncated, pagination.Truncated)
+	}
+
+	s.Equal([]string{""}, ApplyPagination("", 10, 0).Lines)
 }
 
 func TestToolsTestSuite(t *testing.T) {
 	suite.Run(t, new(ToolsTestSuite))
 }
+
+func BenchmarkApplyPagination(b *testing.B) {
+	var builder strings.Builder
+	for i := range 100_000 {
+		fmt.Fprintf(&builder, `{"template-id":"tech-detect-%d","matched-at":"http://localhost/%d"}`+"\n", i, i)
+	}
+	output := builder.String()
+	b.SetBytes(int64(len(output)))
+	b.ReportAllocs()
+
+	for b.Loop() {
+		_ = ApplyPagination(output, types.MaxDefaultLines, 50_000)
+	}
+}
@@ -0,0 +1,143 @@
+package trends
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+	"github.com/tb0hdan/wass-mcp/pkg/findings"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/server"
+	"github.com/tb0hdan/wass-mcp/pkg/storage"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+)
+
+const (
+	toolName     = "trends"
+	defaultLimit = 10
+)
+
+type Input struct {
+	Host  string `json:"host" validate:"required"`
+	Limit int    `json:"limit,omitempty" validate:"min=0,max=100"`
+	Tool  string `json:"tool,omitempty"`
+}
+
+// Point is the finding counts of a single scan of the target.
+type Point struct {
+	CreatedAt      time.Time      `json:"created_at"`
+	ExecutionID    uint           `json:"execution_id"`
+	SeverityCounts map[string]int `json:"severity_counts"`
+	Success        bool           `json:"success"`
+	ToolName       string         `json:"tool_name"`
+	TotalFindings  int            `json:"total_findings"`
+}
+
+// Result is the trends tool response.
+type Result struct {
+	// Change is the per-severity difference between the latest and the oldest scan.
+	Change map[string]int `json:"change,omitempty"`
+	Host   string         `json:"host"`
+	Points []Point        `json:"points"`
+}
+
+type Tool struct {
+	logger    zerolog.Logger
+	validator *validator.Validate
+	store     storage.Storage
+}
+
+func (t *Tool) Register(srv *server.Server) error {
+	tool := &mcp.Tool{
+		Name: toolName,
+		Description: "Reports finding counts per severity over the last scans of a host (oldest first) " +
+			"from the findings store, to track remediation progress. Optionally filtered by tool name.",
+	}
+
+	t.store = srv.Storage()
+
+	mcp.AddTool(&srv.Server, tool, t.TrendsHandler)
+	t.logger.Debug().Msgf("%s tool registered", toolName)
+
+	return nil
+}
+
+func (t *Tool) TrendsHandler(ctx context.Context, _ *mcp.CallToolRequest, input Input) (*mcp.CallToolResult, any, error) {
+	if err := t.validator.Struct(input); err != nil {
+		return nil, nil, fmt.Errorf("validation error: %w", err)
+	}
+
+	limit := input.Limit
+	if limit == 0 {
+		limit = defaultLimit
+	}
+
+	executions, _, err := t.store.QueryToolExecutions(ctx, storage.ExecutionFilter{
+		Host:     input.Host,
+		Limit:    limit,
+		ToolName: input.Tool,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get executions: %w", err)
+	}
+
+	ids := make([]uint, 0, len(executions))
+	for _, exec := range executions {
+		ids = append(ids, exec.ID)
+	}
+	stored, err := t.store.GetFindingsByExecutions(ctx, ids)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get findings: %w", err)
+	}
+
+	data, _ := json.MarshalIndent(buildTrend(input.Host, executions, stored), "", "  ")
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: string(data)},
+		},
+	}, nil, nil
+}
+
+// buildTrend groups stored findings by execution and orders the points oldest first.
+func buildTrend(host string, executions []models.ToolExecution, stored []models.Finding) Result {
+	byExecution := make(map[uint][]models.Finding, len(executions))
+	for _, finding := range stored {
+		byExecution[finding.ExecutionID] = append(byExecution[finding.ExecutionID], finding)
+	}
+
+	result := Result{Host: host, Points: make([]Point, 0, len(executions))}
+	for i := len(executions) - 1; i >= 0; i-- {
+		exec := executions[i]
+		result.Points = append(result.Points, Point{
+			CreatedAt:      exec.CreatedAt,
+			ExecutionID:    exec.ID,
+			SeverityCounts: findings.CountBySeverity(byExecution[exec.ID]),
+			Success:        exec.Success,
+			ToolName:       exec.ToolName,
+			TotalFindings:  len(byExecution[exec.ID]),
+		})
+	}
+
+	if len(result.Points) > 0 {
+		first := result.Points[0].SeverityCounts
+		latest := result.Points[len(result.Points)-1].SeverityCounts
+		result.Change = make(map[string]int, len(findings.Severities))
+		for _, severity := range findings.Severities {
+			result.Change[severity] = latest[severity] - first[severity]
+		}
+	}
+
+	return result
+}
+
+func New(logger zerolog.Logger) tools.Tool {
+	return &Tool{
+		logger:    logger.With().Str("tool", toolName).Logger(),
+		validator: validator.New(),
+	}
+}
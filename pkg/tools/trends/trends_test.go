@@ -0,0 +1,116 @@
+package trends
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/storage"
+	"github.com/tb0hdan/wass-mcp/pkg/types"
+)
+
+type TrendsTestSuite struct {
+	suite.Suite
+	store storage.Storage
+	tool  *Tool
+}
+
+func (s *TrendsTestSuite) SetupTest() {
+	tmpFile, err := os.CreateTemp("", "trends-test-*.db")
+	s.Require().NoError(err)
+	s.Require().NoError(tmpFile.Close())
+	s.T().Cleanup(func() { _ = os.Remove(tmpFile.Name()) })
+
+	store, err := storage.NewSQLiteStorage(storage.Config{DatabasePath: tmpFile.Name()})
+	s.Require().NoError(err)
+	s.T().Cleanup(func() { _ = store.Close() })
+
+	s.store = store
+	s.tool = New(zerolog.Nop()).(*Tool)
+	s.tool.store = store
+}
+
+func (s *TrendsTestSuite) call(input Input) Result {
+	result, _, err := s.tool.TrendsHandler(context.Background(), nil, input)
+	s.Require().NoError(err)
+
+	var response Result
+	s.Require().NoError(json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &response))
+
+	return response
+}
+
+// addScan stores an execution against host with findings of the given severities.
+func (s *TrendsTestSuite) addScan(tool, host string, severities ...string) uint {
+	exec := &models.ToolExecution{
+		ToolName: tool,
+		Target:   "http://" + host,
+		Host:     host,
+		Port:     80,
+		Scheme:   "http",
+		Success:  true,
+	}
+	s.Require().NoError(s.store.CreateToolExecution(context.Background(), exec))
+
+	found := make([]models.Finding, 0, len(severities))
+	for _, severity := range severities {
+		found = append(found, models.Finding{ExecutionID: exec.ID, Scanner: tool, Severity: severity, Title: severity})
+	}
+	s.Require().NoError(s.store.CreateFindings(context.Background(), found))
+
+	return exec.ID
+}
+
+func (s *TrendsTestSuite) TestValidation_MissingHost() {
+	_, _, err := s.tool.TrendsHandler(context.Background(), nil, Input{})
+	s.Error(err)
+	s.Contains(err.Error(), "validation error")
+}
+
+func (s *TrendsTestSuite) TestNoScans() {
+	response := s.call(Input{Host: "example.com"})
+	s.Equal("example.com", response.Host)
+	s.Empty(response.Points)
+	s.Nil(response.Change)
+}
+
+func (s *TrendsTestSuite) TestRemediationProgress() {
+	first := s.addScan("nuclei", "example.com", types.SeverityHigh, types.SeverityHigh, types.SeverityLow)
+	s.addScan("nuclei", "other.org", types.SeverityCritical)
+	latest := s.addScan("nuclei", "example.com", types.SeverityLow)
+
+	response := s.call(Input{Host: "example.com"})
+	s.Require().Len(response.Points, 2)
+	s.Equal(first, response.Points[0].ExecutionID)
+	s.Equal(2, response.Points[0].SeverityCounts[types.SeverityHigh])
+	s.Equal(3, response.Points[0].TotalFindings)
+	s.Equal(latest, response.Points[1].ExecutionID)
+	s.Equal(1, response.Points[1].TotalFindings)
+	s.Equal(-2, response.Change[types.SeverityHigh])
+	s.Equal(0, response.Change[types.SeverityLow])
+}
+
+func (s *TrendsTestSuite) TestToolFilterAndLimit() {
+	s.addScan("nuclei", "example.com", types.SeverityHigh)
+	s.addScan("nikto", "example.com", types.SeverityLow)
+	s.addScan("nuclei", "example.com", types.SeverityMedium)
+
+	response := s.call(Input{Host: "example.com", Tool: "nuclei"})
+	s.Require().Len(response.Points, 2)
+	for _, point := range response.Points {
+		s.Equal("nuclei", point.ToolName)
+	}
+
+	response = s.call(Input{Host: "example.com", Limit: 1})
+	s.Require().Len(response.Points, 1)
+	s.Equal(1, response.Points[0].SeverityCounts[types.SeverityMedium])
+}
+
+func TestTrendsTestSuite(t *testing.T) {
+	suite.Run(t, new(TrendsTestSuite))
+}
@@ -0,0 +1,298 @@
+package triage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+	"github.com/tb0hdan/wass-mcp/pkg/findings"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/server"
+	"github.com/tb0hdan/wass-mcp/pkg/storage"
+	"github.com/tb0hdan/wass-mcp/pkg/tenant"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+	"github.com/tb0hdan/wass-mcp/pkg/types"
+	"gorm.io/gorm"
+)
+
+const (
+	toolName = "triage"
+	// defaultLimit is the page size of list and my_findings.
+	defaultLimit = 20
+)
+
+// ErrInvalidTransition is returned when a finding cannot move to the requested status.
+var ErrInvalidTransition = errors.New("invalid status transition")
+
+type Input struct {
+	Action      string `json:"action" validate:"required,oneof=list my_findings get assign status attach"`
+	Assignee    string `json:"assignee,omitempty" validate:"max=255"`
+	Content     string `json:"content,omitempty" validate:"max=65536"`
+	ExecutionID uint   `json:"execution_id,omitempty"`
+	IDs         []uint `json:"ids,omitempty" validate:"omitempty,max=100,dive,min=1"`
+	Kind        string `json:"kind,omitempty" validate:"omitempty,oneof=request response curl extracted artifact note"`
+	Limit       int    `json:"limit,omitempty" validate:"min=0,max=100"`
+	Offset      int    `json:"offset,omitempty" validate:"min=0"`
+	Reference   string `json:"reference,omitempty" validate:"max=2048"`
+	Severity    string `json:"severity,omitempty" validate:"omitempty,oneof=critical high medium low info"`
+	Status      string `json:"status,omitempty" validate:"omitempty,oneof=open in_progress resolved false_positive accepted"`
+	Unassigned  bool   `json:"unassigned,omitempty"`
+}
+
+// modifyingActions are the actions that change stored findings, refused to read-only keys.
+var modifyingActions = map[string]struct{}{
+	"assign": {},
+	"attach": {},
+	"status": {},
+}
+
+type Tool struct {
+	logger    zerolog.Logger
+	store     storage.Storage
+	validator *validator.Validate
+}
+
+func (t *Tool) Register(srv *server.Server) error {
+	tool := &mcp.Tool{
+		Name: toolName,
+		Description: "Triage stored findings: assign them to an owner and track their status (open, in_progress, " +
+			"resolved, false_positive, accepted). Actions: list (paginated, filterable by assignee, unassigned, " +
+			"status, severity and execution_id), my_findings (findings assigned to assignee, defaulting to the " +
+			"calling client's name), get (by ids), assign (ids to assignee, an empty assignee unassigns), " +
+			"status (move ids to status; resolved, false_positive and accepted findings can only be reopened), " +
+			"attach (add evidence of kind request, response, curl, extracted, artifact or note to ids: a content " +
+			"snippet and/or a reference such as a file path, URL or output resource).",
+	}
+
+	t.store = srv.Storage()
+
+	mcp.AddTool(&srv.Server, tool, t.Handler)
+	t.logger.Debug().Msgf("%s tool registered", toolName)
+
+	return nil
+}
+
+func (t *Tool) Handler(ctx context.Context, req *mcp.CallToolRequest, input Input) (*mcp.CallToolResult, any, error) {
+	if err := t.validator.Struct(input); err != nil {
+		return nil, nil, fmt.Errorf("validation error: %w", err)
+	}
+	if _, modifies := modifyingActions[input.Action]; modifies {
+		if err := tenant.Authorize(ctx, input.Action+" findings"); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var result any
+
+	switch input.Action {
+	case "list", "my_findings":
+		filter := listFilter(input)
+		if input.Action == "my_findings" {
+			filter.Assignee = callerIdentity(ctx, req, input.Assignee)
+			filter.Unassigned = false
+			if filter.Assignee == "" {
+				return nil, nil, fmt.Errorf("assignee is required for my_findings action: the caller has no identity")
+			}
+		}
+		found, total, err := t.store.QueryFindings(ctx, filter)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to list findings: %w", err)
+		}
+		listing := map[string]any{
+			"total":    total,
+			"limit":    filter.Limit,
+			"offset":   filter.Offset,
+			"findings": found,
+		}
+		if input.Action == "my_findings" {
+			listing["assignee"] = filter.Assignee
+		}
+		result = listing
+
+	case "get":
+		found, err := t.load(ctx, input.IDs, input.Action)
+		if err != nil {
+			return nil, nil, err
+		}
+		result = map[string]any{"findings": found}
+
+	case "assign":
+		found, err := t.load(ctx, input.IDs, input.Action)
+		if err != nil {
+			return nil, nil, err
+		}
+		for i := range found {
+			found[i].Assignee = input.Assignee
+		}
+		if err := t.save(ctx, found); err != nil {
+			return nil, nil, err
+		}
+		result = map[string]any{"findings": found}
+
+	case "status":
+		if input.Status == "" {
+			return nil, nil, fmt.Errorf("status is required for status action")
+		}
+		found, err := t.load(ctx, input.IDs, input.Action)
+		if err != nil {
+			return nil, nil, err
+		}
+		// Check every transition first so that a bad one leaves all findings unchanged.
+		for _, finding := range found {
+			if !models.CanTransition(finding.Status, input.Status) {
+				return nil, nil, fmt.Errorf("%w: finding %d is %s and cannot move to %s",
+					ErrInvalidTransition, finding.ID, status(finding), input.Status)
+			}
+		}
+		for i := range found {
+			found[i].Status = input.Status
+		}
+		if err := t.save(ctx, found); err != nil {
+			return nil, nil, err
+		}
+		result = map[string]any{"findings": found}
+
+	case "attach":
+		evidence, err := manualEvidence(ctx, req, input)
+		if err != nil {
+			return nil, nil, err
+		}
+		found, err := t.load(ctx, input.IDs, input.Action)
+		if err != nil {
+			return nil, nil, err
+		}
+		// Check every finding first so that a full one leaves all findings unchanged.
+		for _, finding := range found {
+			if len(finding.Evidence) >= types.MaxEvidence {
+				return nil, nil, fmt.Errorf("finding %d already has the maximum of %d evidence entries",
+					finding.ID, types.MaxEvidence)
+			}
+		}
+		for i := range found {
+			found[i].Evidence = append(found[i].Evidence, evidence)
+			if err := t.store.UpdateFindingEvidence(ctx, &found[i]); err != nil {
+				return nil, nil, fmt.Errorf("failed to update finding %d: %w", found[i].ID, err)
+			}
+			t.logger.Debug().Msgf("Attached %s evidence to finding %d", evidence.Kind, found[i].ID)
+		}
+		result = map[string]any{"findings": found}
+	}
+
+	data, _ := json.MarshalIndent(result, "", "  ")
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: string(data)},
+		},
+	}, nil, nil
+}
+
+// listFilter returns the finding filter of a list input.
+func listFilter(input Input) storage.FindingFilter {
+	limit := input.Limit
+	if limit == 0 {
+		limit = defaultLimit
+	}
+
+	return storage.FindingFilter{
+		Assignee:    input.Assignee,
+		ExecutionID: input.ExecutionID,
+		Limit:       limit,
+		Offset:      input.Offset,
+		Severity:    input.Severity,
+		Status:      input.Status,
+		Unassigned:  input.Unassigned,
+	}
+}
+
+// manualEvidence returns the evidence an attach input adds, cutting its content to
+// types.MaxEvidenceBytes and crediting it to the caller.
+func manualEvidence(ctx context.Context, req *mcp.CallToolRequest, input Input) (models.Evidence, error) {
+	if input.Kind == "" {
+		return models.Evidence{}, fmt.Errorf("kind is required for attach action")
+	}
+	content := strings.TrimSpace(input.Content)
+	if content == "" && input.Reference == "" {
+		return models.Evidence{}, fmt.Errorf("content or reference is required for attach action")
+	}
+
+	return models.Evidence{
+		AddedAt:   time.Now().UTC(),
+		AddedBy:   callerIdentity(ctx, req, ""),
+		Content:   findings.TruncateEvidence(content),
+		Kind:      input.Kind,
+		Reference: input.Reference,
+		Source:    models.EvidenceSourceManual,
+	}, nil
+}
+
+// callerIdentity returns the identity my_findings looks up: the explicit assignee, else the name
+// of the calling MCP client, else the tenant of the API key.
+func callerIdentity(ctx context.Context, req *mcp.CallToolRequest, assignee string) string {
+	if assignee != "" {
+		return assignee
+	}
+	if client := server.ClientFromRequest(ctx, req); client.Name != "" {
+		return client.Name
+	}
+	name, _ := tenant.FromContext(ctx)
+
+	return name
+}
+
+// load returns the findings with the given IDs, in order.
+func (t *Tool) load(ctx context.Context, ids []uint, action string) ([]models.Finding, error) {
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("ids are required for %s action", action)
+	}
+
+	found := make([]models.Finding, 0, len(ids))
+	for _, id := range ids {
+		finding, err := t.store.GetFinding(ctx, id)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("finding %d not found", id)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to load finding %d: %w", id, err)
+		}
+		finding.Status = status(*finding)
+		found = append(found, *finding)
+	}
+
+	return found, nil
+}
+
+// save stores the assignee and status of found.
+func (t *Tool) save(ctx context.Context, found []models.Finding) error {
+	for i := range found {
+		if err := t.store.UpdateFindingTriage(ctx, &found[i]); err != nil {
+			return fmt.Errorf("failed to update finding %d: %w", found[i].ID, err)
+		}
+		t.logger.Debug().Msgf("Finding %d is %s, assigned to %q", found[i].ID, found[i].Status, found[i].Assignee)
+	}
+
+	return nil
+}
+
+// status returns the triage status of finding, open when it has none.
+func status(finding models.Finding) string {
+	if finding.Status == "" {
+		return models.FindingOpen
+	}
+
+	return finding.Status
+}
+
+// New creates a new triage tool.
+func New(logger zerolog.Logger) tools.Tool {
+	return &Tool{
+		logger:    logger.With().Str("tool", toolName).Logger(),
+		validator: validator.New(),
+	}
+}
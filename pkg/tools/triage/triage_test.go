@@ -0,0 +1,210 @@
+package triage
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/server"
+	"github.com/tb0hdan/wass-mcp/pkg/storage"
+	"github.com/tb0hdan/wass-mcp/pkg/tenant"
+	"github.com/tb0hdan/wass-mcp/pkg/types"
+)
+
+type TriageTestSuite struct {
+	suite.Suite
+	cleanup  func()
+	findings []models.Finding
+	srv      *server.Server
+	tool     *Tool
+}
+
+// listing is the response of the list and my_findings actions.
+type listing struct {
+	Assignee string           `json:"assignee"`
+	Findings []models.Finding `json:"findings"`
+	Total    int64            `json:"total"`
+}
+
+func (s *TriageTestSuite) SetupTest() {
+	tmpFile, err := os.CreateTemp("", "triage-*.db")
+	s.Require().NoError(err)
+	tmpFile.Close()
+
+	store, err := storage.NewSQLiteStorage(storage.Config{DatabasePath: tmpFile.Name()})
+	s.Require().NoError(err)
+	s.srv = server.NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}, store)
+	s.cleanup = func() {
+		s.srv.Shutdown(context.Background())
+		os.Remove(tmpFile.Name())
+	}
+
+	s.tool = New(zerolog.Nop()).(*Tool)
+	s.Require().NoError(s.tool.Register(s.srv))
+
+	s.findings = []models.Finding{
+		{ExecutionID: 1, Scanner: "nuclei", Severity: "high", Title: "Exposed Git"},
+		{ExecutionID: 1, Scanner: "nikto", Severity: "low", Title: "Missing header"},
+	}
+	s.Require().NoError(store.CreateFindings(tenant.WithTenant(context.Background(), "alpha"), s.findings))
+}
+
+func (s *TriageTestSuite) TearDownTest() {
+	s.cleanup()
+}
+
+// call runs the handler for req and decodes its JSON response into out.
+func (s *TriageTestSuite) call(ctx context.Context, req *mcp.CallToolRequest, input Input, out any) error {
+	result, _, err := s.tool.Handler(ctx, req, input)
+	if err != nil {
+		return err
+	}
+	s.Require().NoError(json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), out))
+
+	return nil
+}
+
+func (s *TriageTestSuite) TestAssignAndStatus() {
+	ctx := tenant.WithTenant(context.Background(), "alpha")
+	first, second := s.findings[0].ID, s.findings[1].ID
+
+	var list listing
+	s.Require().NoError(s.call(ctx, &mcp.CallToolRequest{}, Input{Action: "list", Status: models.FindingOpen}, &list))
+	s.EqualValues(2, list.Total)
+
+	var changed listing
+	s.Require().NoError(s.call(ctx, &mcp.CallToolRequest{}, Input{Action: "assign", Assignee: "alice", IDs: []uint{first}}, &changed))
+	s.Equal("alice", changed.Findings[0].Assignee)
+	s.Require().NoError(s.call(ctx, &mcp.CallToolRequest{}, Input{Action: "status", IDs: []uint{first}, Status: models.FindingInProgress}, &changed))
+	s.Equal(models.FindingInProgress, changed.Findings[0].Status)
+	s.Equal("alice", changed.Findings[0].Assignee)
+
+	s.Require().NoError(s.call(ctx, &mcp.CallToolRequest{}, Input{Action: "list", Assignee: "alice"}, &list))
+	s.Require().Len(list.Findings, 1)
+	s.Equal(first, list.Findings[0].ID)
+	s.Require().NoError(s.call(ctx, &mcp.CallToolRequest{}, Input{Action: "list", Unassigned: true}, &list))
+	s.Require().Len(list.Findings, 1)
+	s.Equal(second, list.Findings[0].ID)
+
+	// Resolved findings can only be reopened, and a bad transition changes nothing.
+	s.Require().NoError(s.call(ctx, &mcp.CallToolRequest{}, Input{Action: "status", IDs: []uint{first}, Status: models.FindingResolved}, &changed))
+	err := s.call(ctx, &mcp.CallToolRequest{}, Input{Action: "status", IDs: []uint{second, first}, Status: models.FindingInProgress}, &changed)
+	s.ErrorIs(err, ErrInvalidTransition)
+	s.ErrorContains(err, "is resolved and cannot move to in_progress")
+	s.Require().NoError(s.call(ctx, &mcp.CallToolRequest{}, Input{Action: "get", IDs: []uint{second}}, &changed))
+	s.Equal(models.FindingOpen, changed.Findings[0].Status)
+	s.Require().NoError(s.call(ctx, &mcp.CallToolRequest{}, Input{Action: "status", IDs: []uint{first}, Status: models.FindingOpen}, &changed))
+
+	// An empty assignee unassigns.
+	var unassigned listing
+	s.Require().NoError(s.call(ctx, &mcp.CallToolRequest{}, Input{Action: "assign", IDs: []uint{first}}, &unassigned))
+	s.Empty(unassigned.Findings[0].Assignee)
+}
+
+func (s *TriageTestSuite) TestMyFindings() {
+	ctx := tenant.WithTenant(context.Background(), "alpha")
+	var changed, list listing
+	s.Require().NoError(s.call(ctx, &mcp.CallToolRequest{}, Input{Action: "assign", Assignee: "scanner-bot", IDs: []uint{s.findings[0].ID}}, &changed))
+	s.Require().NoError(s.call(ctx, &mcp.CallToolRequest{}, Input{Action: "assign", Assignee: "alpha", IDs: []uint{s.findings[1].ID}}, &changed))
+
+	// The calling client's name identifies the caller.
+	req := &mcp.CallToolRequest{Extra: &mcp.RequestExtra{Header: http.Header{"User-Agent": []string{"scanner-bot/1.2"}}}}
+	s.Require().NoError(s.call(ctx, req, Input{Action: "my_findings"}, &list))
+	s.Equal("scanner-bot", list.Assignee)
+	s.Require().Len(list.Findings, 1)
+	s.Equal(s.findings[0].ID, list.Findings[0].ID)
+
+	// Without a client name the tenant does, and an explicit assignee wins.
+	s.Require().NoError(s.call(ctx, &mcp.CallToolRequest{}, Input{Action: "my_findings"}, &list))
+	s.Equal("alpha", list.Assignee)
+	s.Require().Len(list.Findings, 1)
+	s.Equal(s.findings[1].ID, list.Findings[0].ID)
+	s.Require().NoError(s.call(ctx, req, Input{Action: "my_findings", Assignee: "alpha"}, &list))
+	s.Equal("alpha", list.Assignee)
+
+	s.ErrorContains(s.call(context.Background(), &mcp.CallToolRequest{}, Input{Action: "my_findings"}, &list),
+		"assignee is required for my_findings action")
+}
+
+func (s *TriageTestSuite) TestAttach() {
+	ctx := tenant.WithTenant(context.Background(), "alpha")
+	id := s.findings[0].ID
+	req := &mcp.CallToolRequest{Extra: &mcp.RequestExtra{Header: http.Header{"User-Agent": []string{"scanner-bot/1.2"}}}}
+
+	var changed listing
+	s.Require().NoError(s.call(ctx, req, Input{Action: "attach", IDs: []uint{id}, Kind: models.EvidenceArtifact,
+		Reference: "/srv/artifacts/git-config.txt"}, &changed))
+	s.Require().NoError(s.call(ctx, &mcp.CallToolRequest{}, Input{Action: "attach", IDs: []uint{id}, Kind: models.EvidenceResponse,
+		Content: "  " + strings.Repeat("x", types.MaxEvidenceBytes+10)}, &changed))
+
+	var got listing
+	s.Require().NoError(s.call(ctx, &mcp.CallToolRequest{}, Input{Action: "get", IDs: []uint{id}}, &got))
+	evidence := got.Findings[0].Evidence
+	s.Require().Len(evidence, 2)
+	s.Equal(models.EvidenceArtifact, evidence[0].Kind)
+	s.Equal("/srv/artifacts/git-config.txt", evidence[0].Reference)
+	s.Equal(models.EvidenceSourceManual, evidence[0].Source)
+	s.Equal("scanner-bot", evidence[0].AddedBy)
+	s.False(evidence[0].AddedAt.IsZero())
+	s.Equal("alpha", evidence[1].AddedBy)
+	s.Len(evidence[1].Content, types.MaxEvidenceBytes)
+	s.True(strings.HasSuffix(evidence[1].Content, "[truncated]"))
+
+	s.ErrorContains(s.call(ctx, req, Input{Action: "attach", IDs: []uint{id}, Content: "x"}, &changed), "kind is required")
+	s.ErrorContains(s.call(ctx, req, Input{Action: "attach", IDs: []uint{id}, Kind: models.EvidenceNote, Content: " "}, &changed),
+		"content or reference is required")
+	s.ErrorContains(s.call(ctx, req, Input{Action: "attach", Kind: models.EvidenceNote, Content: "x"}, &changed),
+		"ids are required for attach action")
+
+	// A finding holds at most types.MaxEvidence entries.
+	for range types.MaxEvidence - 2 {
+		s.Require().NoError(s.call(ctx, req, Input{Action: "attach", IDs: []uint{id}, Kind: models.EvidenceNote, Content: "seen"}, &changed))
+	}
+	s.ErrorContains(s.call(ctx, req, Input{Action: "attach", IDs: []uint{s.findings[1].ID, id}, Kind: models.EvidenceNote, Content: "seen"}, &changed),
+		"already has the maximum")
+	var untouched listing
+	s.Require().NoError(s.call(ctx, &mcp.CallToolRequest{}, Input{Action: "get", IDs: []uint{s.findings[1].ID}}, &untouched))
+	s.Empty(untouched.Findings[0].Evidence)
+
+	readOnly := tenant.WithRole(ctx, tenant.RoleReadOnly)
+	s.Error(s.call(readOnly, req, Input{Action: "attach", IDs: []uint{s.findings[1].ID}, Kind: models.EvidenceNote, Content: "x"}, &changed))
+}
+
+func (s *TriageTestSuite) TestValidation() {
+	ctx := tenant.WithTenant(context.Background(), "alpha")
+	var out listing
+
+	s.ErrorContains(s.call(ctx, &mcp.CallToolRequest{}, Input{Action: "close"}, &out), "validation error")
+	s.ErrorContains(s.call(ctx, &mcp.CallToolRequest{}, Input{Action: "status", IDs: []uint{1}, Status: "closed"}, &out), "validation error")
+	s.ErrorContains(s.call(ctx, &mcp.CallToolRequest{}, Input{Action: "status", IDs: []uint{1}}, &out), "status is required")
+	s.ErrorContains(s.call(ctx, &mcp.CallToolRequest{}, Input{Action: "assign", Assignee: "alice"}, &out), "ids are required for assign action")
+	s.ErrorContains(s.call(ctx, &mcp.CallToolRequest{}, Input{Action: "get", IDs: []uint{999}}, &out), "finding 999 not found")
+}
+
+func (s *TriageTestSuite) TestReadOnlyAndTenantScoping() {
+	readOnly := tenant.WithRole(tenant.WithTenant(context.Background(), "alpha"), tenant.RoleReadOnly)
+	var out listing
+
+	err := s.call(readOnly, &mcp.CallToolRequest{}, Input{Action: "assign", Assignee: "alice", IDs: []uint{s.findings[0].ID}}, &out)
+	var wireErr *jsonrpc.Error
+	s.Require().ErrorAs(err, &wireErr)
+	s.EqualValues(tenant.CodeForbidden, wireErr.Code)
+	s.NoError(s.call(readOnly, &mcp.CallToolRequest{}, Input{Action: "list"}, &out))
+
+	beta := tenant.WithTenant(context.Background(), "beta")
+	s.Require().NoError(s.call(beta, &mcp.CallToolRequest{}, Input{Action: "list"}, &out))
+	s.Zero(out.Total)
+	s.ErrorContains(s.call(beta, &mcp.CallToolRequest{}, Input{Action: "assign", Assignee: "mallory", IDs: []uint{s.findings[0].ID}}, &out), "not found")
+}
+
+func TestTriageTestSuite(t *testing.T) {
+	suite.Run(t, new(TriageTestSuite))
+}
@@ -0,0 +1,73 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/tb0hdan/wass-mcp/pkg/bundle"
+	"github.com/tb0hdan/wass-mcp/pkg/types"
+)
+
+// ErrNoVersion is returned by scanners that cannot report the version of their binary.
+var ErrNoVersion = errors.New("scanner does not report its version")
+
+// versionRe matches dotted version numbers such as "2.5.0" or "v3.1.4".
+var versionRe = regexp.MustCompile(`\bv?(\d+(?:\.\d+)+)\b`)
+
+// VersionReporter is implemented by scanners that can report the version of their binary.
+type VersionReporter interface {
+	Version(ctx context.Context) (string, error)
+}
+
+// ParseVersion returns the first dotted version number found in output, or an empty string.
+func ParseVersion(output string) string {
+	if match := versionRe.FindStringSubmatch(output); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
+// Version runs the scanner binary from Path with VersionArgs and returns the version it prints.
+// Some scanners exit with an error after printing their version, so the output is parsed regardless
+// and the error is only returned when no version was found.
+func (b *BaseScanner) Version(ctx context.Context) (string, error) {
+	if len(b.VersionArgs) == 0 {
+		return "", ErrNoVersion
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, types.VersionTimeout)
+	defer cancel()
+
+	output, err := b.Command(ctx, b.VersionArgs...).CombinedOutput()
+	if version := ParseVersion(string(output)); version != "" {
+		return version, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to run %s: %w", b.BinaryName, err)
+	}
+
+	return "", fmt.Errorf("no version reported by %s", b.BinaryName)
+}
+
+// ToolVersions returns the health of the binaries of scanners, see bundle.Check. Scanners that
+// cannot report a version are checked for their binary only.
+func ToolVersions(ctx context.Context, scanners ...Scanner) []bundle.Status {
+	statuses := make([]bundle.Status, 0, len(scanners))
+	for _, scanner := range scanners {
+		var version func() (string, error)
+		if reporter, ok := scanner.(VersionReporter); ok {
+			version = func() (string, error) {
+				found, err := reporter.Version(ctx)
+				if errors.Is(err, ErrNoVersion) {
+					return "", nil
+				}
+				return found, err
+			}
+		}
+		statuses = append(statuses, bundle.Check(scanner.Name(), version))
+	}
+
+	return statuses
+}
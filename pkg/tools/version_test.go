@@ -0,0 +1,115 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/bundle"
+	"github.com/tb0hdan/wass-mcp/pkg/server"
+)
+
+// baseScannerTool adapts a BaseScanner to the Scanner interface.
+type baseScannerTool struct {
+	BaseScanner
+}
+
+func (b *baseScannerTool) Register(_ *server.Server) error { return nil }
+
+func (b *baseScannerTool) Scan(_ context.Context, _ ScanParams) ScanResult { return ScanResult{} }
+
+type VersionTestSuite struct {
+	suite.Suite
+}
+
+// fakeBinary installs an executable shell script named name on a temporary PATH.
+func (s *VersionTestSuite) fakeBinary(name, script string) {
+	dir := s.T().TempDir()
+	path := filepath.Join(dir, name)
+	s.Require().NoError(os.WriteFile(path, []byte("#!/bin/sh\n"+script+"\n"), 0o700)) //nolint:gosec
+	s.T().Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func (s *VersionTestSuite) TestParseVersion() {
+	s.Equal("2.5.0", ParseVersion("Nikto 2.5.0\n"))
+	s.Equal("3.1.4", ParseVersion("[INF] Nuclei Engine Version: v3.1.4"))
+	s.Equal("3.2", ParseVersion("Wapiti 3.2 (wapiti-scanner.github.io)"))
+	s.Empty(ParseVersion("no version here"))
+	s.Empty(ParseVersion(""))
+}
+
+func (s *VersionTestSuite) TestVersion() {
+	s.fakeBinary("fake-scanner", `echo "Fake Scanner v1.4.2"`)
+	scanner := NewBaseScanner("fake-scanner", "test", zerolog.Nop())
+	scanner.VersionArgs = []string{"--version"}
+
+	version, err := scanner.Version(context.Background())
+	s.Require().NoError(err)
+	s.Equal("1.4.2", version)
+}
+
+func (s *VersionTestSuite) TestVersion_FailingExitWithVersion() {
+	s.fakeBinary("fake-scanner", `echo "fake 0.9.1"; exit 1`)
+	scanner := NewBaseScanner("fake-scanner", "test", zerolog.Nop())
+	scanner.VersionArgs = []string{"-V"}
+
+	version, err := scanner.Version(context.Background())
+	s.Require().NoError(err)
+	s.Equal("0.9.1", version)
+}
+
+func (s *VersionTestSuite) TestVersion_NoVersionPrinted() {
+	s.fakeBinary("fake-scanner", `echo "usage: fake-scanner"`)
+	scanner := NewBaseScanner("fake-scanner", "test", zerolog.Nop())
+	scanner.VersionArgs = []string{"--version"}
+
+	_, err := scanner.Version(context.Background())
+	s.ErrorContains(err, "no version reported by fake-scanner")
+}
+
+func (s *VersionTestSuite) TestVersion_NoVersionArgs() {
+	scanner := NewBaseScanner("fake-scanner", "test", zerolog.Nop())
+
+	_, err := scanner.Version(context.Background())
+	s.ErrorIs(err, ErrNoVersion)
+}
+
+func (s *VersionTestSuite) TestVersion_PrefersBundledBinary() {
+	s.fakeBinary("fake-scanner", `echo "fake 1.0.0"`)
+	dir := s.T().TempDir()
+	s.Require().NoError(os.Mkdir(filepath.Join(dir, bundle.BinDir), 0o750))
+	bundled := filepath.Join(dir, bundle.BinDir, "fake-scanner")
+	s.Require().NoError(os.WriteFile(bundled, []byte("#!/bin/sh\necho \"fake 2.0.0\"\n"), 0o700)) //nolint:gosec
+	bundle.Use(&bundle.Bundle{Dir: dir})
+	defer bundle.Use(nil)
+
+	scanner := NewBaseScanner("fake-scanner", "test", zerolog.Nop())
+	scanner.VersionArgs = []string{"--version"}
+	s.Equal(bundled, scanner.Path())
+	s.True(scanner.IsAvailable())
+
+	version, err := scanner.Version(context.Background())
+	s.Require().NoError(err)
+	s.Equal("2.0.0", version)
+}
+
+func (s *VersionTestSuite) TestToolVersions() {
+	s.fakeBinary("fake-scanner", `echo "fake 1.0.0"`)
+	versioned := &baseScannerTool{NewBaseScanner("fake-scanner", "test", zerolog.Nop())}
+	versioned.VersionArgs = []string{"--version"}
+	unversioned := &baseScannerTool{NewBaseScanner("fake-scanner", "test", zerolog.Nop())}
+
+	statuses := ToolVersions(context.Background(), versioned, unversioned)
+	s.Require().Len(statuses, 2)
+	s.Equal("1.0.0", statuses[0].Version)
+	s.True(statuses[0].Healthy)
+	s.Empty(statuses[1].Version)
+	s.Empty(statuses[1].Error, "scanners without a version are checked for their binary only")
+}
+
+func TestVersionTestSuite(t *testing.T) {
+	suite.Run(t, new(VersionTestSuite))
+}
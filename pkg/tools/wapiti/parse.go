@@ -0,0 +1,149 @@
+package wapiti
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/tb0hdan/wass-mcp/pkg/findings"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/types"
+)
+
+// requestRe matches the request line of a wapiti evil request.
+var requestRe = regexp.MustCompile(`^\s*(?:GET|POST|PUT|DELETE|PATCH|HEAD|OPTIONS) (\S+)`)
+
+// highCategories lists wapiti categories treated as high severity.
+var highCategories = []string{
+	"sql injection", "command execution", "xml external entity", "server side request forgery",
+}
+
+// lowCategories lists wapiti categories treated as low severity.
+var lowCategories = []string{
+	"content security policy", "http secure headers", "secure flag", "httponly flag",
+	"clickjacking", "internal server error", "resource consumption", "fingerprint",
+}
+
+// ParseFindings parses the issue records converted from wapiti JSON reports, falling back to the
+// text report format of older stored outputs for other lines. The category of an issue is its
+// template ID, so that its fingerprint does not depend on the wording of its info, and the evil
+// request is kept as evidence.
+func (t *Tool) ParseFindings(output string) ([]models.Finding, error) {
+	var found []models.Finding
+	var rest strings.Builder
+
+	for _, line := range strings.Split(output, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if !strings.HasPrefix(trimmed, "{") {
+			rest.WriteString(line)
+			rest.WriteString("\n")
+			continue
+		}
+
+		var rec record
+		if err := json.Unmarshal([]byte(trimmed), &rec); err != nil || rec.Category == "" {
+			continue
+		}
+
+		finding := models.Finding{
+			Evidence:   findings.AppendEvidence(nil, models.EvidenceRequest, binaryName, rec.Request),
+			Parameter:  rec.Parameter,
+			References: rec.References,
+			Scanner:    binaryName,
+			Severity:   rec.Severity,
+			TemplateID: rec.Category,
+			Title:      rec.Category,
+			URL:        rec.Path,
+		}
+		if finding.Severity == "" {
+			finding.Severity = severity(rec.Category)
+		}
+		if rec.Info != "" {
+			finding.Title = rec.Category + ": " + rec.Info
+		}
+		found = append(found, finding)
+	}
+
+	return append(found, parseText(rest.String())...), nil
+}
+
+// parseText parses wapiti text reports, emitting one finding per evil request.
+// The category is taken from the nearest preceding underlined heading.
+func parseText(output string) []models.Finding {
+	var found []models.Finding
+
+	lines := strings.Split(output, "\n")
+	category := ""
+	description := ""
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if i+1 < len(lines) && trimmed != "" && isUnderline(strings.TrimSpace(lines[i+1])) {
+			category = trimmed
+			description = ""
+			continue
+		}
+		if trimmed == "" || isUnderline(trimmed) {
+			continue
+		}
+		if strings.HasPrefix(trimmed, "Evil request:") && category != "" {
+			finding := models.Finding{
+				Scanner:  binaryName,
+				Severity: severity(category),
+				Title:    category,
+			}
+			if description != "" {
+				finding.Title = category + ": " + description
+			}
+			if i+1 < len(lines) {
+				if match := requestRe.FindStringSubmatch(lines[i+1]); match != nil {
+					finding.URL = match[1]
+				}
+			}
+			finding.Evidence = findings.AppendEvidence(nil, models.EvidenceRequest, binaryName, evilRequest(lines[i+1:]))
+			found = append(found, finding)
+			description = ""
+			continue
+		}
+		if description == "" && !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") &&
+			!strings.HasPrefix(trimmed, "cURL command") {
+			description = trimmed
+		}
+	}
+
+	return found
+}
+
+// evilRequest returns the indented request lines at the start of lines.
+func evilRequest(lines []string) string {
+	var request []string
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" || (!strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t")) {
+			break
+		}
+		request = append(request, strings.TrimSpace(line))
+	}
+
+	return strings.Join(request, "\n")
+}
+
+// isUnderline reports whether a line is a heading underline.
+func isUnderline(line string) bool {
+	return line != "" && strings.Trim(line, "=-") == ""
+}
+
+// severity maps a wapiti vulnerability category to a severity.
+func severity(category string) string {
+	lowered := strings.ToLower(category)
+	for _, fragment := range highCategories {
+		if strings.Contains(lowered, fragment) {
+			return types.SeverityHigh
+		}
+	}
+	for _, fragment := range lowCategories {
+		if strings.Contains(lowered, fragment) {
+			return types.SeverityLow
+		}
+	}
+
+	return types.SeverityMedium
+}
@@ -0,0 +1,67 @@
+package wapiti
+
+import (
+	"os"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/types"
+)
+
+const reportOutput = `Cross Site Scripting
+--------------------
+Reflected Cross Site Scripting vulnerability found via injection in the parameter q
+Evil request:
+    GET /search.php?q=%3Cscript%3E HTTP/1.1
+    host: example.com
+cURL command PoC : "curl http://example.com/search.php?q=%3Cscript%3E"
+
+SQL Injection
+-------------
+SQL Injection via injection in the parameter id
+Evil request:
+    GET /item.php?id=%27 HTTP/1.1
+    host: example.com
+
+Content Security Policy Configuration
+=====================================
+Evil request:
+    GET / HTTP/1.1`
+
+type ParseTestSuite struct {
+	suite.Suite
+	tool *Tool
+}
+
+func (s *ParseTestSuite) SetupTest() {
+	s.tool = New(zerolog.New(os.Stdout)).(*Tool)
+}
+
+func (s *ParseTestSuite) TestParseFindings() {
+	found, err := s.tool.ParseFindings(reportOutput)
+	s.Require().NoError(err)
+	s.Require().Len(found, 3)
+	s.Equal(types.SeverityMedium, found[0].Severity)
+	s.Equal("/search.php?q=%3Cscript%3E", found[0].URL)
+	s.Contains(found[0].Title, "Cross Site Scripting: Reflected")
+	s.Equal(types.SeverityHigh, found[1].Severity)
+	s.Equal("/item.php?id=%27", found[1].URL)
+	s.Equal([]models.Evidence{{Content: "GET /item.php?id=%27 HTTP/1.1\nhost: example.com", Kind: models.EvidenceRequest, Source: "wapiti"}},
+		found[1].Evidence)
+	s.Equal("GET / HTTP/1.1", found[2].Evidence[0].Content)
+	s.Equal(types.SeverityLow, found[2].Severity)
+	s.Equal("Content Security Policy Configuration", found[2].Title)
+	s.Equal("wapiti", found[2].Scanner)
+}
+
+func (s *ParseTestSuite) TestParseFindings_NoRequests() {
+	found, err := s.tool.ParseFindings("Summary\n-------\nNo vulnerabilities found")
+	s.Require().NoError(err)
+	s.Empty(found)
+}
+
+func TestParseTestSuite(t *testing.T) {
+	suite.Run(t, new(ParseTestSuite))
+}
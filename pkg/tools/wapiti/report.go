@@ -0,0 +1,153 @@
+package wapiti
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/tb0hdan/wass-mcp/pkg/findings"
+	"github.com/tb0hdan/wass-mcp/pkg/types"
+)
+
+// legacyVersionPrefixes match wapiti versions that number levels from 1 (high) to 3 (low).
+// Wapiti 3.1 and later use 0 (info) to 4 (critical).
+var legacyVersionPrefixes = []string{"Wapiti 2.", "Wapiti 3.0."}
+
+// report is the subset of a wapiti JSON report used for findings.
+type report struct {
+	Additionals     map[string][]entry        `json:"additionals"`
+	Anomalies       map[string][]entry        `json:"anomalies"`
+	Classifications map[string]classification `json:"classifications"`
+	Infos           struct {
+		Version string `json:"version"`
+	} `json:"infos"`
+	Vulnerabilities map[string][]entry `json:"vulnerabilities"`
+}
+
+// entry is a single issue of a wapiti JSON report.
+type entry struct {
+	HTTPRequest string   `json:"http_request"`
+	Info        string   `json:"info"`
+	Level       int      `json:"level"`
+	Method      string   `json:"method"`
+	Parameter   string   `json:"parameter"`
+	Path        string   `json:"path"`
+	WSTG        []string `json:"wstg"`
+}
+
+// classification describes a wapiti category.
+type classification struct {
+	Ref  map[string]string `json:"ref"`
+	WSTG []string          `json:"wstg"`
+}
+
+// record is a converted wapiti issue, written as one JSON line of the scan output.
+type record struct {
+	Category   string   `json:"category"`
+	Severity   string   `json:"severity"`
+	Method     string   `json:"method,omitempty"`
+	Path       string   `json:"path,omitempty"`
+	Parameter  string   `json:"parameter,omitempty"`
+	Info       string   `json:"info,omitempty"`
+	References []string `json:"references,omitempty"`
+	Request    string   `json:"http_request,omitempty"`
+}
+
+// convertReport converts a wapiti JSON report into one JSON record per issue, vulnerabilities
+// first, then anomalies and additional information, each ordered by category. The records are
+// compact enough to page through and are parsed back by ParseFindings.
+func convertReport(data []byte) (string, error) {
+	var rep report
+	if err := json.Unmarshal(data, &rep); err != nil {
+		return "", fmt.Errorf("failed to parse wapiti report: %w", err)
+	}
+
+	legacy := false
+	for _, prefix := range legacyVersionPrefixes {
+		if strings.HasPrefix(rep.Infos.Version, prefix) {
+			legacy = true
+		}
+	}
+
+	var lines []string
+	for _, section := range []map[string][]entry{rep.Vulnerabilities, rep.Anomalies, rep.Additionals} {
+		categories := make([]string, 0, len(section))
+		for category := range section {
+			categories = append(categories, category)
+		}
+		sort.Strings(categories)
+
+		for _, category := range categories {
+			for _, issue := range section[category] {
+				line, err := json.Marshal(record{
+					Category:   category,
+					Severity:   levelSeverity(issue.Level, legacy),
+					Method:     issue.Method,
+					Path:       issue.Path,
+					Parameter:  issue.Parameter,
+					Info:       issue.Info,
+					References: references(rep.Classifications[category], issue.WSTG),
+					Request:    findings.TruncateEvidence(strings.TrimSpace(issue.HTTPRequest)),
+				})
+				if err != nil {
+					return "", fmt.Errorf("failed to encode wapiti issue: %w", err)
+				}
+				lines = append(lines, string(line))
+			}
+		}
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
+// levelSeverity maps a wapiti issue level to a severity.
+func levelSeverity(level int, legacy bool) string {
+	if legacy {
+		switch level {
+		case 1:
+			return types.SeverityHigh
+		case 2:
+			return types.SeverityMedium
+		default:
+			return types.SeverityLow
+		}
+	}
+
+	switch level {
+	case 4:
+		return types.SeverityCritical
+	case 3:
+		return types.SeverityHigh
+	case 2:
+		return types.SeverityMedium
+	case 1:
+		return types.SeverityLow
+	default:
+		return types.SeverityInfo
+	}
+}
+
+// references returns the WSTG IDs of an issue, falling back to those of its category, followed
+// by the reference URLs of the category ordered by name.
+func references(class classification, wstg []string) []string {
+	if len(wstg) == 0 {
+		wstg = class.WSTG
+	}
+	result := append([]string(nil), wstg...)
+
+	names := make([]string, 0, len(class.Ref))
+	for name := range class.Ref {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		result = append(result, class.Ref[name])
+	}
+
+	if len(result) == 0 {
+		return nil
+	}
+
+	return result
+}
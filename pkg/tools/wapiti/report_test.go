@@ -0,0 +1,120 @@
+package wapiti
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/types"
+)
+
+const jsonReport = `{
+  "vulnerabilities": {
+    "SQL Injection": [
+      {"method": "GET", "path": "/item.php?id=%27", "info": "SQL Injection via injection in the parameter id",
+       "level": 4, "parameter": "id", "http_request": "GET /item.php?id=%27 HTTP/1.1", "wstg": ["WSTG-INPV-05"]}
+    ],
+    "Cross Site Scripting": [
+      {"method": "GET", "path": "/search.php?q=%3Cscript%3E", "info": "Reflected XSS in the parameter q",
+       "level": 2, "parameter": "q"}
+    ],
+    "Backup file": []
+  },
+  "anomalies": {
+    "Internal Server Error": [
+      {"method": "POST", "path": "/upload", "info": "The server responded with a 500 HTTP error code", "level": 1}
+    ]
+  },
+  "additionals": {
+    "Fingerprint web technology": [
+      {"method": "GET", "path": "/", "info": "{\"name\": \"Apache\"}", "level": 0}
+    ]
+  },
+  "classifications": {
+    "Cross Site Scripting": {
+      "ref": {"OWASP": "https://owasp.org/www-community/attacks/xss/", "CWE-79": "https://cwe.mitre.org/data/definitions/79.html"},
+      "wstg": ["WSTG-INPV-01"]
+    }
+  },
+  "infos": {"target": "http://example.com/", "version": "Wapiti 3.2.2"}
+}`
+
+type ReportTestSuite struct {
+	suite.Suite
+	tool *Tool
+}
+
+func (s *ReportTestSuite) SetupTest() {
+	s.tool = New(zerolog.New(os.Stdout)).(*Tool)
+}
+
+func (s *ReportTestSuite) TestConvertReport() {
+	output, err := convertReport([]byte(jsonReport))
+	s.Require().NoError(err)
+
+	lines := strings.Split(output, "\n")
+	s.Require().Len(lines, 4)
+	s.JSONEq(`{"category":"Cross Site Scripting","severity":"medium","method":"GET","path":"/search.php?q=%3Cscript%3E",
+		"parameter":"q","info":"Reflected XSS in the parameter q",
+		"references":["WSTG-INPV-01","https://cwe.mitre.org/data/definitions/79.html","https://owasp.org/www-community/attacks/xss/"]}`, lines[0])
+	s.JSONEq(`{"category":"SQL Injection","severity":"critical","method":"GET","path":"/item.php?id=%27",
+		"parameter":"id","info":"SQL Injection via injection in the parameter id","references":["WSTG-INPV-05"],
+		"http_request":"GET /item.php?id=%27 HTTP/1.1"}`, lines[1])
+	s.Contains(lines[2], `"category":"Internal Server Error","severity":"low"`)
+	s.Contains(lines[3], `"category":"Fingerprint web technology","severity":"info"`)
+}
+
+func (s *ReportTestSuite) TestConvertReport_LegacyLevels() {
+	output, err := convertReport([]byte(`{
+		"vulnerabilities": {"SQL Injection": [{"path": "/a", "level": 1}], "Backup file": [{"path": "/b", "level": 3}]},
+		"infos": {"version": "Wapiti 3.0.4"}
+	}`))
+	s.Require().NoError(err)
+
+	lines := strings.Split(output, "\n")
+	s.Require().Len(lines, 2)
+	s.Contains(lines[0], `"category":"Backup file","severity":"low"`)
+	s.Contains(lines[1], `"category":"SQL Injection","severity":"high"`)
+}
+
+func (s *ReportTestSuite) TestConvertReport_Empty() {
+	output, err := convertReport([]byte(`{"vulnerabilities": {}, "infos": {"version": "Wapiti 3.2.2"}}`))
+	s.Require().NoError(err)
+	s.Empty(output)
+}
+
+func (s *ReportTestSuite) TestConvertReport_Invalid() {
+	_, err := convertReport([]byte("Cross Site Scripting\n----"))
+	s.Error(err)
+}
+
+func (s *ReportTestSuite) TestParseConvertedReport() {
+	output, err := convertReport([]byte(jsonReport))
+	s.Require().NoError(err)
+
+	found, err := s.tool.ParseFindings(output)
+	s.Require().NoError(err)
+	s.Require().Len(found, 4)
+	s.Equal("Cross Site Scripting: Reflected XSS in the parameter q", found[0].Title)
+	s.Equal("/search.php?q=%3Cscript%3E", found[0].URL)
+	s.Equal("Cross Site Scripting", found[0].TemplateID)
+	s.Equal("q", found[0].Parameter)
+	s.Equal(types.SeverityMedium, found[0].Severity)
+	s.Len(found[0].References, 3)
+	s.Empty(found[0].Evidence)
+	s.Equal(types.SeverityCritical, found[1].Severity)
+	s.Equal([]models.Evidence{{Content: "GET /item.php?id=%27 HTTP/1.1", Kind: models.EvidenceRequest, Source: "wapiti"}},
+		found[1].Evidence)
+	s.Equal("/upload", found[2].URL)
+	s.Equal(types.SeverityInfo, found[3].Severity)
+	for _, finding := range found {
+		s.Equal("wapiti", finding.Scanner)
+	}
+}
+
+func TestReportTestSuite(t *testing.T) {
+	suite.Run(t, new(ReportTestSuite))
+}
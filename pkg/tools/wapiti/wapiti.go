@@ -4,20 +4,52 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/rs/zerolog"
 	"github.com/tb0hdan/wass-mcp/pkg/server"
 	"github.com/tb0hdan/wass-mcp/pkg/tools"
+	"github.com/tb0hdan/wass-mcp/pkg/vault"
 )
 
 const (
 	binaryName  = "wapiti"
 	description = "Wapiti is a web application vulnerability scanner."
 	headerVerb  = "report"
+	reportName  = "report.json"
 )
 
+// supportedOptions are the scan options wapiti honours.
+var supportedOptions = []string{
+	tools.OptionCABundle, tools.OptionCapture, tools.OptionConfig, tools.OptionCredential, tools.OptionDepth,
+	tools.OptionInsecureSkipVerify, tools.OptionMaxAttackTime, tools.OptionMaxDepth, tools.OptionMaxLinksPerPage,
+	tools.OptionMaxScanTime, tools.OptionModules, tools.OptionScope, tools.OptionURLs, tools.OptionUserAgent,
+	tools.OptionVhost,
+}
+
+// crawlFlags maps the crawl scope and time budget options to wapiti flags, in argument order.
+var crawlFlags = []struct {
+	option string
+	flag   string
+}{
+	{tools.OptionScope, "--scope"},
+	{tools.OptionDepth, "-d"},
+	{tools.OptionMaxDepth, "--max-depth"},
+	{tools.OptionMaxLinksPerPage, "--max-links-per-page"},
+	{tools.OptionMaxScanTime, "--max-scan-time"},
+	{tools.OptionMaxAttackTime, "--max-attack-time"},
+}
+
+// hintModules maps the technologies detected by fingerprinting scanners to the wapiti modules
+// enumerating them, run along the default modules.
+var hintModules = map[string]string{
+	"drupal":    "drupal_enum",
+	"wordpress": "wp_enum",
+}
+
 // Tool implements the wapiti scanner.
 type Tool struct {
 	tools.BaseScanner
@@ -25,29 +57,32 @@ type Tool struct {
 
 // Scan performs the wapiti scan and returns the output.
 func (t *Tool) Scan(ctx context.Context, params tools.ScanParams) tools.ScanResult {
-	targetURL := tools.BuildTargetURL(params)
-	t.Logger.Info().Msgf("Running wapiti scan on %s", targetURL)
+	targetURL := params.Target().URL()
+	logger := tools.ContextLogger(ctx, t.Logger)
+	logger.Info().Msgf("Running wapiti scan on %s", targetURL)
 
-	// Create temp file for report output.
-	tempFile, err := os.CreateTemp("", "wapiti-report-*.txt")
+	configArgs, err := t.configArgs(params)
 	if err != nil {
 		return tools.ScanResult{
-			Error: fmt.Errorf("failed to create temp file: %w", err),
+			Error: err,
 		}
 	}
-	reportPath := tempFile.Name()
-	_ = tempFile.Close()
-	defer func() {
-		_ = os.Remove(reportPath)
-	}()
 
-	args := []string{"-u", targetURL, "-f", "txt", "-o", reportPath, "--flush-session"}
-	if params.Vhost != "" {
-		args = append(args, "-H", fmt.Sprintf("Host: %s", params.Vhost))
+	// The report is written to the work directory, removed once it has been read.
+	workDir, cleanup, err := t.WorkDir(ctx)
+	if err != nil {
+		return tools.ScanResult{
+			Error: err,
+		}
 	}
+	defer cleanup()
+	reportPath := filepath.Join(workDir, reportName)
 
-	cmd := exec.CommandContext(ctx, binaryName, args...) //nolint:gosec
-	cmdOutput, err := cmd.CombinedOutput()
+	args := slices.Concat(configArgs, buildArgs(targetURL, reportPath, params), moduleArgs(params.Option(tools.OptionModules), params.Hints, configArgs))
+	cmd := t.Command(ctx, args...)
+	cmd.Dir = workDir
+	cmd.Env = tools.ScanEnv(params, workDir)
+	cmdOutput, err := t.CombinedOutput(ctx, cmd)
 
 	if err != nil {
 		return tools.ScanResult{
@@ -59,52 +94,149 @@ func (t *Tool) Scan(ctx context.Context, params tools.ScanParams) tools.ScanResu
 	// Read the generated report file.
 	reportData, err := os.ReadFile(reportPath) //nolint:gosec
 	if err != nil {
-		t.Logger.Warn().Err(err).Msg("Failed to read report file, using command output")
+		logger.Warn().Err(err).Msg("Failed to read report file, using command output")
 		return tools.ScanResult{
 			Output: string(cmdOutput),
 			Error:  nil,
 		}
 	}
 
+	// Convert the JSON report server-side into one record per issue.
+	converted, err := convertReport(reportData)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Failed to convert report file, using raw report")
+		converted = string(reportData)
+	}
+
 	return tools.ScanResult{
-		Output: string(reportData),
+		Output: converted,
 		Error:  nil,
 	}
 }
 
-// Register registers the wapiti tool with the MCP server.
-func (t *Tool) Register(srv *server.Server) error {
-	return t.RegisterTool(srv, t.Handler)
+// configArgs returns the arguments of the config file of params, see readConfigArgs.
+func (t *Tool) configArgs(params tools.ScanParams) ([]string, error) {
+	configFile, err := t.ConfigFile(params)
+	if err != nil || configFile == "" {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(configFile) //nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("failed to read wapiti config: %w", err)
+	}
+
+	return readConfigArgs(string(data)), nil
 }
 
-// Handler handles MCP tool requests.
-func (t *Tool) Handler(ctx context.Context, _ *mcp.CallToolRequest, input tools.ScannerInput) (*mcp.CallToolResult, any, error) {
-	input = t.PrepareInput(input)
+// readConfigArgs parses a wapiti config file. Wapiti has no configuration file of its own, so its
+// config files list command line arguments, whitespace-separated without quoting, skipping blank
+// lines and # comments. They come before the arguments set by the server, which win.
+func readConfigArgs(config string) []string {
+	var args []string
+	for _, line := range strings.Split(config, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		args = append(args, strings.Fields(line)...)
+	}
 
-	if err := t.ValidateInput(input); err != nil {
-		return nil, nil, err
+	return args
+}
+
+// buildArgs builds the wapiti command line arguments.
+func buildArgs(targetURL, reportPath string, params tools.ScanParams) []string {
+	args := []string{"-u", targetURL, "-f", "json", "-o", reportPath, "--flush-session"}
+	// Crawled URLs start the crawl of wapiti along the target.
+	for _, start := range params.URLs {
+		args = append(args, "-s", start)
+	}
+	if header := params.Target().HostHeader(); header != "" {
+		args = append(args, "-H", header)
+	}
+	if userAgent := params.Option(tools.OptionUserAgent); userAgent != "" {
+		args = append(args, "-A", userAgent)
+	}
+	if params.Proxy != "" {
+		args = append(args, "--proxy", params.Proxy)
+	}
+	args = append(args, credentialArgs(params.Credential)...)
+	for _, crawl := range crawlFlags {
+		if value := params.Option(crawl.option); value != "" {
+			args = append(args, crawl.flag, value)
+		}
 	}
 
-	params := t.ResolveInput(input)
+	switch {
+	case params.InsecureSkipVerify:
+		args = append(args, "--verify-ssl", "0")
+	case params.CABundle != "":
+		args = append(args, "--verify-ssl", "1")
+	}
 
-	scanResult := t.Scan(ctx, params)
-	if scanResult.Error != nil {
-		return nil, nil, fmt.Errorf("%w\nOutput: %s", scanResult.Error, scanResult.Output)
+	return args
+}
+
+// moduleArgs returns the arguments choosing the wapiti modules: the modules option as it is, or
+// else the default modules with those of the technologies in hints, see hintModules. Module lists
+// set by the config file are kept unless the modules option is set.
+func moduleArgs(modules string, hints, configArgs []string) []string {
+	if modules != "" {
+		return []string{"-m", modules}
+	}
+	if slices.Contains(configArgs, "-m") || slices.Contains(configArgs, "--module") {
+		return nil
 	}
 
-	targetURL := tools.BuildTargetURL(params)
-	resultText := tools.FormatScannerOutput(binaryName, headerVerb, targetURL, scanResult.Output, input.MaxLines, input.Offset)
+	defaults := []string{"common"}
+	for _, hint := range hints {
+		if module, ok := hintModules[hint]; ok && !slices.Contains(defaults, module) {
+			defaults = append(defaults, module)
+		}
+	}
+	if len(defaults) == 1 {
+		return nil
+	}
 
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{
-			&mcp.TextContent{Text: resultText},
-		},
-	}, nil, nil
+	return []string{"-m", strings.Join(defaults, ",")}
+}
+
+// credentialArgs returns the arguments authenticating the scan with credential: HTTP basic
+// authentication, a login form wapiti logs in with before crawling, or request headers.
+func credentialArgs(credential *vault.Credential) []string {
+	if credential == nil {
+		return nil
+	}
+
+	switch credential.Type {
+	case vault.TypeBasic:
+		return []string{"--auth-user", credential.Username, "--auth-password", credential.Password, "--auth-method", "basic"}
+	case vault.TypeLoginForm:
+		return []string{"--form-url", credential.LoginURL, "--form-user", credential.Username, "--form-password", credential.Password}
+	default:
+		var args []string
+		for _, header := range credential.Headers() {
+			args = append(args, "-H", header)
+		}
+		return args
+	}
+}
+
+// Register registers the wapiti tool with the MCP server.
+func (t *Tool) Register(srv *server.Server) error {
+	return t.RegisterTool(srv, t.Handler)
+}
+
+// Handler handles MCP tool requests.
+func (t *Tool) Handler(ctx context.Context, _ *mcp.CallToolRequest, input tools.ScannerInput) (*mcp.CallToolResult, any, error) {
+	return t.HandleScan(ctx, input, headerVerb, t.Scan)
 }
 
 // New creates a new wapiti scanner tool.
 func New(logger zerolog.Logger) tools.Scanner {
-	return &Tool{
-		BaseScanner: tools.NewBaseScanner(binaryName, description, logger),
-	}
+	base := tools.NewBaseScanner(binaryName, description, logger, supportedOptions...)
+	base.VersionArgs = []string{"--version"}
+
+	return &Tool{BaseScanner: base}
 }
@@ -3,6 +3,7 @@ package wapiti
 import (
 	"context"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -10,7 +11,9 @@ import (
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/server"
 	"github.com/tb0hdan/wass-mcp/pkg/tools"
+	"github.com/tb0hdan/wass-mcp/pkg/vault"
 )
 
 // scanTestTimeout is a short timeout for tests that invoke the actual scanner.
@@ -303,6 +306,124 @@ func (s *WapitiTestSuite) TestScan_WithVhost() {
 	}
 }
 
+func (s *WapitiTestSuite) TestBuildArgs_Default() {
+	args := buildArgs("http://localhost", "/tmp/report.json", tools.ScanParams{Host: "localhost", Port: 80})
+	s.Equal([]string{"-u", "http://localhost", "-f", "json", "-o", "/tmp/report.json", "--flush-session"}, args)
+}
+
+func (s *WapitiTestSuite) TestBuildArgs_InsecureSkipVerify() {
+	args := buildArgs("https://localhost", "/tmp/report.json", tools.ScanParams{InsecureSkipVerify: true})
+	s.Equal([]string{"--verify-ssl", "0"}, args[len(args)-2:])
+}
+
+func (s *WapitiTestSuite) TestBuildArgs_CABundle() {
+	args := buildArgs("https://localhost", "/tmp/report.json", tools.ScanParams{CABundle: "/etc/ssl/ca.pem"})
+	s.Equal([]string{"--verify-ssl", "1"}, args[len(args)-2:])
+}
+
+func (s *WapitiTestSuite) TestBuildArgs_UserAgent() {
+	args := buildArgs("http://localhost", "/tmp/report.json", tools.ScanParams{Options: map[string]string{tools.OptionUserAgent: "wass"}})
+	s.Equal([]string{"-A", "wass"}, args[len(args)-2:])
+}
+
+func (s *WapitiTestSuite) TestBuildArgs_Proxy() {
+	args := buildArgs("http://localhost", "/tmp/report.json", tools.ScanParams{Proxy: "http://127.0.0.1:8080"})
+	s.Equal([]string{"--proxy", "http://127.0.0.1:8080"}, args[len(args)-2:])
+}
+
+func (s *WapitiTestSuite) TestBuildArgs_Credential() {
+	secret := vault.Secret{Username: "admin", Password: "s3cret", LoginURL: "https://localhost/login"}
+	basic := &vault.Credential{Type: vault.TypeBasic, Secret: secret}
+	args := buildArgs("https://localhost", "/tmp/report.json", tools.ScanParams{Credential: basic})
+	s.Equal([]string{"--auth-user", "admin", "--auth-password", "s3cret", "--auth-method", "basic"}, args[len(args)-6:])
+
+	form := &vault.Credential{Type: vault.TypeLoginForm, Secret: secret}
+	args = buildArgs("https://localhost", "/tmp/report.json", tools.ScanParams{Credential: form})
+	s.Equal([]string{"--form-url", "https://localhost/login", "--form-user", "admin", "--form-password", "s3cret"}, args[len(args)-6:])
+
+	bearer := &vault.Credential{Type: vault.TypeBearer, Secret: vault.Secret{Token: "abc"}}
+	args = buildArgs("https://localhost", "/tmp/report.json", tools.ScanParams{Credential: bearer})
+	s.Equal([]string{"-H", "Authorization: Bearer abc"}, args[len(args)-2:])
+}
+
+func (s *WapitiTestSuite) TestReadConfigArgs() {
+	config := `# Tuned for large sites
+--scope folder
+  -m   sql,xss,exec
+
+--max-scan-time 3600
+`
+	s.Equal([]string{"--scope", "folder", "-m", "sql,xss,exec", "--max-scan-time", "3600"}, readConfigArgs(config))
+	s.Empty(readConfigArgs("# nothing\n\n"))
+}
+
+func (s *WapitiTestSuite) TestModuleArgs() {
+	s.Equal([]string{"-m", "common,wp_enum"}, moduleArgs("", []string{"nginx", "wordpress", "php"}, nil))
+	s.Equal([]string{"-m", "common,drupal_enum,wp_enum"}, moduleArgs("", []string{"drupal", "wordpress"}, nil))
+	s.Nil(moduleArgs("", []string{"nginx"}, nil))
+	s.Nil(moduleArgs("", nil, nil))
+	s.Nil(moduleArgs("", []string{"wordpress"}, []string{"-m", "sql"}), "config file module lists are kept")
+	s.Equal([]string{"-m", "sql,xss"}, moduleArgs("sql,xss", []string{"wordpress"}, []string{"-m", "common"}),
+		"the modules option replaces the default, hinted and config file modules")
+}
+
+func (s *WapitiTestSuite) TestScan_IsolatedWorkDir() {
+	// The fake wapiti writes its report and leaves a file in its temp directory.
+	binDir := s.T().TempDir()
+	script := `#!/bin/sh
+while [ $# -gt 0 ]; do
+  if [ "$1" = "-o" ]; then report="$2"; fi
+  shift
+done
+echo '{"vulnerabilities": {"Backup file": [{"path": "/b", "level": 1}]}}' > "$report"
+touch "$TMPDIR/session.db"
+`
+	s.Require().NoError(os.WriteFile(filepath.Join(binDir, binaryName), []byte(script), 0o700)) //nolint:gosec
+	s.T().Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	base := filepath.Join(s.T().TempDir(), "scans")
+	srv := server.NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}, nil)
+	srv.SetWorkDir(base)
+	s.Require().NoError(s.tool.Register(srv))
+
+	ctx := tools.WithCorrelationID(context.Background(), "abc123")
+	result := s.tool.Scan(ctx, tools.ScanParams{Host: "localhost", Port: 80, Scheme: "http"})
+	s.Require().NoError(result.Error)
+	s.Contains(result.Output, `"category":"Backup file"`)
+
+	entries, err := os.ReadDir(base)
+	s.Require().NoError(err)
+	s.Empty(entries)
+}
+
+func (s *WapitiTestSuite) TestBuildArgs_CrawlLimits() {
+	params := tools.ScanParams{Options: map[string]string{
+		tools.OptionMaxAttackTime: "120", tools.OptionMaxDepth: "3", tools.OptionMaxLinksPerPage: "50",
+	}}
+	args := buildArgs("http://localhost", "/tmp/report.json", params)
+	s.Equal([]string{"--max-depth", "3", "--max-links-per-page", "50", "--max-attack-time", "120"}, args[len(args)-6:])
+}
+
+func (s *WapitiTestSuite) TestBuildArgs_ScopeAndBudget() {
+	params := tools.ScanParams{Options: map[string]string{
+		tools.OptionScope: "page", tools.OptionDepth: "5", tools.OptionMaxScanTime: "3600",
+	}}
+	args := buildArgs("http://localhost", "/tmp/report.json", params)
+	s.Equal([]string{"--scope", "page", "-d", "5", "--max-scan-time", "3600"}, args[len(args)-6:])
+	s.Subset(s.tool.SupportedOptions(), []string{tools.OptionDepth, tools.OptionMaxScanTime, tools.OptionModules, tools.OptionScope})
+}
+
+func (s *WapitiTestSuite) TestBuildArgs_URLs() {
+	params := tools.ScanParams{URLs: []string{"http://localhost/", "http://localhost/login"}}
+	args := buildArgs("http://localhost", "/tmp/report.json", params)
+	s.Equal([]string{"-s", "http://localhost/", "-s", "http://localhost/login"}, args[7:11])
+	s.Contains(s.tool.SupportedOptions(), tools.OptionURLs)
+}
+
+func (s *WapitiTestSuite) TestSupportedOptions_CrawlLimits() {
+	s.Subset(s.tool.SupportedOptions(), []string{tools.OptionMaxAttackTime, tools.OptionMaxDepth, tools.OptionMaxLinksPerPage})
+}
+
 func TestWapitiTestSuite(t *testing.T) {
 	suite.Run(t, new(WapitiTestSuite))
 }
@@ -0,0 +1,165 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog"
+	"github.com/tb0hdan/wass-mcp/pkg/bundle"
+	"github.com/tb0hdan/wass-mcp/pkg/types"
+)
+
+// warmUpTailLines bounds the output lines kept in the diagnostics of a failed warm-up.
+const warmUpTailLines = 5
+
+// crashMarkers are printed by interpreters failing to start a scanner, such as a Python
+// environment missing a module or a Ruby gem that cannot be loaded.
+var crashMarkers = []string{
+	"Traceback (most recent call last)",
+	"ModuleNotFoundError",
+	"ImportError",
+	"SyntaxError",
+	"bad interpreter",
+	"cannot load such file",
+	"LoadError",
+}
+
+// WarmUpChecker is implemented by scanners that verify at startup that their binary actually runs,
+// not just that it exists: a broken interpreter environment leaves a binary on PATH that fails on
+// every scan. Scanners failing their warm-up report themselves unavailable.
+type WarmUpChecker interface {
+	// WarmUp runs the binary with benign flags and returns why it failed, nil when it runs.
+	WarmUp(ctx context.Context) error
+	// Diagnostics returns why the last warm-up failed, empty when it passed or did not run.
+	Diagnostics() string
+}
+
+// warmUpState holds the outcome of the last warm-up of a scanner.
+type warmUpState struct {
+	mu          sync.Mutex
+	diagnostics string
+}
+
+func (w *warmUpState) set(err error) {
+	if w == nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.diagnostics = ""
+	if err != nil {
+		w.diagnostics = err.Error()
+	}
+}
+
+func (w *warmUpState) get() string {
+	if w == nil {
+		return ""
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.diagnostics
+}
+
+// WarmUp runs the scanner binary with WarmUpArgs, or VersionArgs when unset, and marks the scanner
+// unavailable when it cannot be started, times out or exits with an error printing an interpreter
+// crash or nothing at all. Scanners without either set are not checked. A missing binary is not a
+// warm-up failure, as IsAvailable already reports it.
+func (b *BaseScanner) WarmUp(ctx context.Context) error {
+	args := b.WarmUpArgs
+	if len(args) == 0 {
+		args = b.VersionArgs
+	}
+	if len(args) == 0 {
+		return nil
+	}
+	if _, _, err := bundle.LookPath(b.BinaryName); err != nil {
+		return nil //nolint:nilerr
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, types.VersionTimeout)
+	defer cancel()
+
+	output, err := b.Command(ctx, args...).CombinedOutput()
+	if ctx.Err() != nil {
+		err = fmt.Errorf("%s %s timed out after %s", b.BinaryName, strings.Join(args, " "), types.VersionTimeout)
+	} else {
+		err = warmUpError(b.BinaryName, args, string(output), err)
+	}
+	b.warmUp.set(err)
+
+	return err
+}
+
+// Diagnostics returns why the last warm-up failed, empty when it passed or did not run.
+func (b *BaseScanner) Diagnostics() string {
+	return b.warmUp.get()
+}
+
+// warmUpError returns the warm-up failure of running name with args, nil when the binary ran.
+// Scanners exiting with an error after printing their usage or version are considered working.
+func warmUpError(name string, args []string, output string, err error) error {
+	if err == nil {
+		return nil
+	}
+	command := strings.TrimSpace(name + " " + strings.Join(args, " "))
+
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return fmt.Errorf("%s failed to start: %w", command, err)
+	}
+
+	output = strings.TrimSpace(output)
+	crashed := output == ""
+	for _, marker := range crashMarkers {
+		if strings.Contains(output, marker) {
+			crashed = true
+			break
+		}
+	}
+	if !crashed {
+		return nil
+	}
+	if output == "" {
+		return fmt.Errorf("%s failed: %w", command, err)
+	}
+
+	return fmt.Errorf("%s failed: %w: %s", command, err, tailLines(output, warmUpTailLines))
+}
+
+// tailLines returns the last n lines of text, joined with " | ".
+func tailLines(text string, n int) string {
+	lines := strings.Split(text, "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	for i := range lines {
+		lines[i] = strings.TrimSpace(lines[i])
+	}
+
+	return strings.Join(lines, " | ")
+}
+
+// WarmUp runs the warm-up of the scanners implementing WarmUpChecker concurrently and logs the
+// scanners that failed it, which report themselves unavailable from then on.
+func WarmUp(ctx context.Context, logger zerolog.Logger, scanners ...Scanner) {
+	var waitGroup sync.WaitGroup
+	for _, scanner := range scanners {
+		checker, ok := scanner.(WarmUpChecker)
+		if !ok {
+			continue
+		}
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			if err := checker.WarmUp(ctx); err != nil {
+				logger.Error().Msgf("Scanner %s failed its warm-up and is unavailable: %v", scanner.Name(), err)
+			}
+		}()
+	}
+	waitGroup.Wait()
+}
@@ -0,0 +1,109 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+)
+
+type WarmUpTestSuite struct {
+	suite.Suite
+}
+
+// fakeBinary installs an executable named name with content on a temporary PATH.
+func (s *WarmUpTestSuite) fakeBinary(name, content string) {
+	dir := s.T().TempDir()
+	path := filepath.Join(dir, name)
+	s.Require().NoError(os.WriteFile(path, []byte(content), 0o700)) //nolint:gosec
+	s.T().Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+// scanner returns a scanner running fake-scanner with --help at warm-up.
+func (s *WarmUpTestSuite) scanner() *baseScannerTool {
+	scanner := &baseScannerTool{BaseScanner: NewBaseScanner("fake-scanner", "test", zerolog.Nop())}
+	scanner.WarmUpArgs = []string{"--help"}
+	return scanner
+}
+
+func (s *WarmUpTestSuite) TestWarmUp_Healthy() {
+	s.fakeBinary("fake-scanner", "#!/bin/sh\necho usage: fake-scanner\n")
+	scanner := s.scanner()
+
+	s.NoError(scanner.WarmUp(context.Background()))
+	s.Empty(scanner.Diagnostics())
+	s.True(scanner.IsAvailable())
+}
+
+func (s *WarmUpTestSuite) TestWarmUp_UsageWithErrorExit() {
+	s.fakeBinary("fake-scanner", "#!/bin/sh\necho usage: fake-scanner >&2\nexit 2\n")
+	scanner := s.scanner()
+
+	s.NoError(scanner.WarmUp(context.Background()), "scanners exiting with an error after their usage work")
+	s.True(scanner.IsAvailable())
+}
+
+func (s *WarmUpTestSuite) TestWarmUp_BrokenInterpreter() {
+	s.fakeBinary("fake-scanner", "#!/bin/sh\n"+
+		"echo 'Traceback (most recent call last):' >&2\n"+
+		"echo '  File \"/usr/bin/wapiti\", line 5, in <module>' >&2\n"+
+		"echo \"ModuleNotFoundError: No module named 'wapitiCore'\" >&2\n"+
+		"exit 1\n")
+	scanner := s.scanner()
+
+	err := scanner.WarmUp(context.Background())
+	s.Require().Error(err)
+	s.Contains(err.Error(), "fake-scanner --help failed")
+	s.Contains(scanner.Diagnostics(), "No module named 'wapitiCore'")
+	s.False(scanner.IsAvailable(), "scanners failing their warm-up are unavailable")
+	s.ErrorContains(scanner.RegisterTool(nil, nil), "fake-scanner is unavailable: ")
+}
+
+func (s *WarmUpTestSuite) TestWarmUp_MissingInterpreter() {
+	s.fakeBinary("fake-scanner", "#!/nonexistent/python3\n")
+	scanner := s.scanner()
+
+	s.ErrorContains(scanner.WarmUp(context.Background()), "failed to start")
+	s.False(scanner.IsAvailable())
+}
+
+func (s *WarmUpTestSuite) TestWarmUp_Recovers() {
+	s.fakeBinary("fake-scanner", "#!/bin/sh\nexit 1\n")
+	scanner := s.scanner()
+	s.Error(scanner.WarmUp(context.Background()), "an error exit without output is a failure")
+
+	s.fakeBinary("fake-scanner", "#!/bin/sh\necho ok\n")
+	s.NoError(scanner.WarmUp(context.Background()))
+	s.True(scanner.IsAvailable())
+}
+
+func (s *WarmUpTestSuite) TestWarmUp_Skipped() {
+	scanner := s.scanner()
+	scanner.WarmUpArgs = nil
+	s.NoError(scanner.WarmUp(context.Background()), "scanners without warm-up or version args are not checked")
+
+	scanner = s.scanner()
+	scanner.BinaryName = "wass-missing-scanner"
+	s.NoError(scanner.WarmUp(context.Background()), "missing binaries are reported by IsAvailable")
+	s.Empty(scanner.Diagnostics())
+}
+
+func (s *WarmUpTestSuite) TestWarmUp_Scanners() {
+	s.fakeBinary("fake-scanner", "#!/bin/sh\nexit 1\n")
+	scanner := s.scanner()
+
+	WarmUp(context.Background(), zerolog.Nop(), scanner)
+	s.NotEmpty(scanner.Diagnostics())
+}
+
+func (s *WarmUpTestSuite) TestTailLines() {
+	s.Equal("c | d", tailLines("a\nb\nc\n d ", 2))
+	s.Equal("a", tailLines("a", 5))
+}
+
+func TestWarmUpTestSuite(t *testing.T) {
+	suite.Run(t, new(WarmUpTestSuite))
+}
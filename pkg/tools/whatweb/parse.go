@@ -0,0 +1,181 @@
+package whatweb
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/types"
+)
+
+// plugin is the result of a whatweb plugin.
+type plugin struct {
+	String  []string `json:"string"`
+	Version []string `json:"version"`
+}
+
+// result is a target result of the whatweb JSON log.
+type result struct {
+	HTTPStatus int               `json:"http_status"`
+	Plugins    map[string]plugin `json:"plugins"`
+	Target     string            `json:"target"`
+}
+
+// serverPlugins are the plugins reporting a "name/version" banner, the web server of HTTPServer
+// and the language or framework of X-Powered-By.
+var serverPlugins = map[string]string{
+	"HTTPServer":   models.TechnologyServer,
+	"X-Powered-By": models.TechnologyLanguage,
+}
+
+// ignoredPlugins are the plugins reporting details of the response rather than a technology.
+var ignoredPlugins = []string{
+	"Allow", "Content-Language", "Cookies", "Country", "Email", "Frame", "HTML5", "HttpOnly", "IP", "Meta-Author",
+	"MetaGenerator", "Object", "PasswordField", "Script", "Strict-Transport-Security", "Title", "UncommonHeaders",
+	"RedirectLocation", "Via-Proxy", "X-Frame-Options", "X-UA-Compatible", "X-XSS-Protection",
+}
+
+// categories are the categories of known technologies by lower-cased name, models.TechnologyOther
+// for the others.
+var categories = map[string]string{
+	"apache": models.TechnologyServer, "caddy": models.TechnologyServer, "envoy": models.TechnologyServer,
+	"gunicorn": models.TechnologyServer, "jetty": models.TechnologyServer, "lighttpd": models.TechnologyServer,
+	"litespeed": models.TechnologyServer, "microsoft-iis": models.TechnologyServer, "nginx": models.TechnologyServer,
+	"openresty": models.TechnologyServer, "tomcat": models.TechnologyServer, "apache-tomcat": models.TechnologyServer,
+
+	"drupal": models.TechnologyCMS, "ghost": models.TechnologyCMS, "joomla": models.TechnologyCMS,
+	"magento": models.TechnologyCMS, "moodle": models.TechnologyCMS, "shopify": models.TechnologyCMS,
+	"silverstripe": models.TechnologyCMS, "typo3": models.TechnologyCMS, "wordpress": models.TechnologyCMS,
+
+	"asp_net": models.TechnologyFramework, "django": models.TechnologyFramework, "express": models.TechnologyFramework,
+	"laravel": models.TechnologyFramework, "ruby-on-rails": models.TechnologyFramework, "spring": models.TechnologyFramework,
+	"bootstrap": models.TechnologyFramework, "graphql": models.TechnologyFramework,
+
+	"angularjs": models.TechnologyJavaScript, "jquery": models.TechnologyJavaScript, "jquery-ui": models.TechnologyJavaScript,
+	"lodash": models.TechnologyJavaScript, "modernizr": models.TechnologyJavaScript, "react": models.TechnologyJavaScript,
+	"vue.js": models.TechnologyJavaScript,
+
+	"java": models.TechnologyLanguage, "perl": models.TechnologyLanguage, "php": models.TechnologyLanguage,
+	"python": models.TechnologyLanguage, "ruby": models.TechnologyLanguage,
+}
+
+// parseReport parses the whatweb JSON log, an array of target results, or results one per line.
+// Output that is not JSON yields no results.
+func parseReport(output string) []result {
+	start := strings.Index(output, "[")
+	end := strings.LastIndex(output, "]")
+	if start >= 0 && end > start {
+		var results []result
+		if err := json.Unmarshal([]byte(output[start:end+1]), &results); err == nil {
+			return results
+		}
+	}
+
+	var results []result
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSuffix(strings.TrimSpace(line), ",")
+		var res result
+		if strings.HasPrefix(line, "{") && json.Unmarshal([]byte(line), &res) == nil {
+			results = append(results, res)
+		}
+	}
+
+	return results
+}
+
+// technologyName returns the lower-cased technology name of a plugin or banner name, e.g.
+// "wordpress" for WordPress, the form technology scanners match.
+func technologyName(name string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), " ", "-")
+}
+
+// technologies returns the technologies detected in results, sorted by name. Technologies found by
+// several plugins or results are listed once, with the first version reported.
+func technologies(results []result) []models.Technology {
+	var detected []models.Technology
+	add := func(technology models.Technology) {
+		if technology.Name == "" {
+			return
+		}
+		index := slices.IndexFunc(detected, func(existing models.Technology) bool { return existing.Name == technology.Name })
+		switch {
+		case index < 0:
+			detected = append(detected, technology)
+		case detected[index].Version == "":
+			detected[index].Version = technology.Version
+		}
+	}
+
+	for _, res := range results {
+		for name, plugin := range res.Plugins {
+			if category, ok := serverPlugins[name]; ok {
+				for _, banner := range plugin.String {
+					add(bannerTechnology(banner, category))
+				}
+				continue
+			}
+			if slices.Contains(ignoredPlugins, name) {
+				continue
+			}
+			technology := models.Technology{Name: technologyName(name), Category: models.TechnologyOther}
+			if category, ok := categories[technology.Name]; ok {
+				technology.Category = category
+			}
+			if len(plugin.Version) > 0 {
+				technology.Version = plugin.Version[0]
+			}
+			add(technology)
+		}
+	}
+	slices.SortFunc(detected, func(a, b models.Technology) int { return strings.Compare(a.Name, b.Name) })
+
+	return detected
+}
+
+// bannerTechnology returns the technology of a "name/version" banner such as "Apache/2.4.41
+// (Ubuntu)" or "PHP/7.4.3", in category unless the name is of a known category.
+func bannerTechnology(banner, category string) models.Technology {
+	product, _, _ := strings.Cut(strings.TrimSpace(banner), " ")
+	name, version, _ := strings.Cut(product, "/")
+	technology := models.Technology{Name: technologyName(name), Category: category, Version: version}
+	if known, ok := categories[technology.Name]; ok {
+		technology.Category = known
+	}
+
+	return technology
+}
+
+// Fingerprint returns the names of the technologies detected in whatweb output, sorted, so that
+// full_scan in auto mode adds the scanners of the detected technologies, see tools.Fingerprinter.
+func (t *Tool) Fingerprint(output string) []string {
+	var names []string
+	for _, technology := range technologies(parseReport(output)) {
+		names = append(names, technology.Name)
+	}
+
+	return names
+}
+
+// ParseFindings parses the whatweb JSON log into one informational finding per detected
+// technology, with its category as template ID and the scanned URL.
+func (t *Tool) ParseFindings(output string) ([]models.Finding, error) {
+	var found []models.Finding
+	for _, res := range parseReport(output) {
+		for _, technology := range technologies([]result{res}) {
+			title := "Technology detected: " + technology.Name
+			if technology.Version != "" {
+				title += " " + technology.Version
+			}
+			found = append(found, models.Finding{
+				Scanner:    binaryName,
+				Severity:   types.SeverityInfo,
+				TemplateID: technology.Category,
+				Title:      title,
+				URL:        res.Target,
+			})
+		}
+	}
+
+	return found, nil
+}
@@ -0,0 +1,81 @@
+package whatweb
+
+import (
+	"os"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/types"
+)
+
+const jsonReport = `[
+{"target":"http://shop.example.com","http_status":200,"request_config":{"headers":{"User-Agent":"WhatWeb/0.5.5"}},"plugins":{"Apache":{"version":["2.4.41"]},"Country":{"string":["RESERVED"],"module":["ZZ"]},"HTTPServer":{"os":["Ubuntu Linux"],"string":["Apache/2.4.41 (Ubuntu)"]},"IP":{"string":["10.0.0.1"]},"JQuery":{"version":["3.6.0"]},"Title":{"string":["Shop"]},"WordPress":{"version":["6.4.2"]},"X-Powered-By":{"string":["PHP/7.4.3"]}}},
+{}
+]`
+
+type ParseTestSuite struct {
+	suite.Suite
+	tool *Tool
+}
+
+func (s *ParseTestSuite) SetupTest() {
+	s.tool = New(zerolog.New(os.Stdout)).(*Tool)
+}
+
+func (s *ParseTestSuite) TestTechnologies() {
+	s.Equal([]models.Technology{
+		{Category: models.TechnologyServer, Name: "apache", Version: "2.4.41"},
+		{Category: models.TechnologyJavaScript, Name: "jquery", Version: "3.6.0"},
+		{Category: models.TechnologyLanguage, Name: "php", Version: "7.4.3"},
+		{Category: models.TechnologyCMS, Name: "wordpress", Version: "6.4.2"},
+	}, technologies(parseReport(jsonReport)), "response details are left out and apache is listed once")
+}
+
+func (s *ParseTestSuite) TestTechnologies_Banners() {
+	results := []result{{Plugins: map[string]plugin{
+		"HTTPServer":   {String: []string{"nginx"}},
+		"X-Powered-By": {String: []string{"Express"}},
+		"Some Plugin":  {String: []string{"detected"}},
+	}}}
+	s.Equal([]models.Technology{
+		{Category: models.TechnologyFramework, Name: "express"},
+		{Category: models.TechnologyServer, Name: "nginx"},
+		{Category: models.TechnologyOther, Name: "some-plugin"},
+	}, technologies(results))
+}
+
+func (s *ParseTestSuite) TestParseReport_Lines() {
+	results := parseReport(`{"target":"http://a.example.com","plugins":{"Drupal":{}}},` + "\n" + `{"target":"http://b.example.com","plugins":{}}`)
+	s.Require().Len(results, 2)
+	s.Equal("http://a.example.com", results[0].Target)
+
+	s.Empty(parseReport("ERROR Opening: http://localhost - Connection refused"))
+}
+
+func (s *ParseTestSuite) TestFingerprint() {
+	s.Equal([]string{"apache", "jquery", "php", "wordpress"}, s.tool.Fingerprint(jsonReport))
+	s.Empty(s.tool.Fingerprint(""))
+}
+
+func (s *ParseTestSuite) TestParseFindings() {
+	found, err := s.tool.ParseFindings(jsonReport)
+	s.Require().NoError(err)
+	s.Require().Len(found, 4)
+
+	s.Equal("Technology detected: apache 2.4.41", found[0].Title)
+	s.Equal(models.TechnologyServer, found[0].TemplateID)
+	s.Equal("http://shop.example.com", found[0].URL)
+	s.Equal(types.SeverityInfo, found[0].Severity)
+	s.Equal("whatweb", found[0].Scanner)
+	s.Equal("Technology detected: wordpress 6.4.2", found[3].Title)
+
+	found, err = s.tool.ParseFindings("not json")
+	s.Require().NoError(err)
+	s.Empty(found)
+}
+
+func TestParseTestSuite(t *testing.T) {
+	suite.Run(t, new(ParseTestSuite))
+}
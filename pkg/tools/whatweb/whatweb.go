@@ -0,0 +1,144 @@
+package whatweb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/server"
+	"github.com/tb0hdan/wass-mcp/pkg/target"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+	"github.com/tb0hdan/wass-mcp/pkg/vault"
+)
+
+const (
+	binaryName  = "whatweb"
+	description = "WhatWeb fingerprints the technologies of the target, such as its web server, CMS, frameworks and JavaScript " +
+		"libraries, with their versions, and stores them as the target profile returned by the target_profile tool."
+	headerVerb = "output"
+	// reportFile is the JSON log, relative to the working directory, whatweb writes its results to.
+	reportFile = "whatweb.json"
+)
+
+// supportedOptions are the scan options whatweb honours.
+var supportedOptions = []string{tools.OptionCapture, tools.OptionCredential, tools.OptionUserAgent, tools.OptionVhost}
+
+// Tool implements the whatweb fingerprinting scanner.
+type Tool struct {
+	tools.BaseScanner
+}
+
+// Scan fingerprints the target and returns the whatweb JSON log. The detected technologies are
+// recorded as the profile of the target, see tools.RecordTargetProfile.
+func (t *Tool) Scan(ctx context.Context, params tools.ScanParams) tools.ScanResult {
+	targetURL := params.Target().URL()
+	logger := tools.ContextLogger(ctx, t.Logger)
+	logger.Info().Msgf("Running whatweb scan on %s", targetURL)
+
+	if err := params.CheckCredential(binaryName, vault.HeaderTypes...); err != nil {
+		return tools.ScanResult{
+			Error: err,
+		}
+	}
+
+	workDir, cleanup, err := t.WorkDir(ctx)
+	if err != nil {
+		return tools.ScanResult{
+			Error: err,
+		}
+	}
+	defer cleanup()
+
+	reportPath := filepath.Join(workDir, reportFile)
+	cmd := t.Command(ctx, buildArgs(targetURL, reportPath, params)...)
+	cmd.Dir = workDir
+	cmd.Env = tools.ScanEnv(params, workDir)
+	output, err := t.CombinedOutput(ctx, cmd)
+	if err != nil {
+		return tools.ScanResult{
+			Output: string(output),
+			Error:  fmt.Errorf("failed to execute whatweb: %w", err),
+		}
+	}
+
+	report, err := os.ReadFile(reportPath) //nolint:gosec
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return tools.ScanResult{
+				Output: string(output),
+				Error:  errors.New("whatweb did not write a report"),
+			}
+		}
+		return tools.ScanResult{
+			Output: string(output),
+			Error:  fmt.Errorf("failed to read whatweb report: %w", err),
+		}
+	}
+
+	port := params.Port
+	if port == 0 {
+		port = target.DefaultPort(params.Scheme)
+	}
+	tools.RecordTargetProfile(ctx, models.TargetProfile{
+		Target:       targetURL,
+		Host:         params.Host,
+		Port:         port,
+		Scanner:      binaryName,
+		Technologies: technologies(parseReport(string(report))),
+	})
+
+	return tools.ScanResult{
+		Output: string(report),
+		Error:  nil,
+	}
+}
+
+// buildArgs builds the whatweb command line arguments. Aggression level 1 sends a single request,
+// following redirects, and identifies the technologies from its response.
+func buildArgs(targetURL, reportPath string, params tools.ScanParams) []string {
+	args := []string{"--color=never", "--quiet", "--aggression", "1", "--log-json=" + reportPath}
+	if header := params.Target().HostHeader(); header != "" {
+		args = append(args, "--header", header)
+	}
+	if userAgent := params.Option(tools.OptionUserAgent); userAgent != "" {
+		args = append(args, "--user-agent", userAgent)
+	}
+	if params.Credential != nil {
+		for _, header := range params.Credential.Headers() {
+			args = append(args, "--header", header)
+		}
+	}
+	if params.Proxy != "" {
+		// whatweb takes the proxy as host:port.
+		proxy := strings.TrimPrefix(strings.TrimPrefix(params.Proxy, "http://"), "https://")
+		args = append(args, "--proxy", strings.TrimSuffix(proxy, "/"))
+	}
+
+	return append(args, targetURL)
+}
+
+// Register registers the whatweb tool with the MCP server.
+func (t *Tool) Register(srv *server.Server) error {
+	return t.RegisterTool(srv, t.Handler)
+}
+
+// Handler handles MCP tool requests.
+func (t *Tool) Handler(ctx context.Context, _ *mcp.CallToolRequest, input tools.ScannerInput) (*mcp.CallToolResult, any, error) {
+	return t.HandleScan(ctx, input, headerVerb, t.Scan)
+}
+
+// New creates a new whatweb scanner tool.
+func New(logger zerolog.Logger) tools.Scanner {
+	base := tools.NewBaseScanner(binaryName, description, logger, supportedOptions...)
+	base.VersionArgs = []string{"--version"}
+	// whatweb identifies the technologies from a single response.
+	base.Passive = true
+
+	return &Tool{BaseScanner: base}
+}
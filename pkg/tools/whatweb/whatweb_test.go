@@ -0,0 +1,104 @@
+package whatweb
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+	"github.com/tb0hdan/wass-mcp/pkg/vault"
+)
+
+type WhatwebTestSuite struct {
+	suite.Suite
+	logger zerolog.Logger
+	tool   *Tool
+}
+
+func (s *WhatwebTestSuite) SetupTest() {
+	s.logger = zerolog.New(os.Stdout).With().Timestamp().Logger()
+	s.tool = New(s.logger).(*Tool)
+}
+
+// fakeBinary installs a fake whatweb running script.
+func (s *WhatwebTestSuite) fakeBinary(script string) {
+	binDir := s.T().TempDir()
+	s.Require().NoError(os.WriteFile(filepath.Join(binDir, binaryName), []byte("#!/bin/sh\n"+script+"\n"), 0o700)) //nolint:gosec
+	s.T().Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func (s *WhatwebTestSuite) TestNew() {
+	s.Equal("whatweb", s.tool.Name())
+	s.True(tools.IsPassive(s.tool), "whatweb sends a single request")
+	s.Empty(tools.Technologies(s.tool), "whatweb runs in full_scan")
+
+	var scanner tools.Scanner = s.tool
+	_, ok := scanner.(tools.Fingerprinter)
+	s.True(ok, "full_scan runs whatweb first in auto mode")
+}
+
+func (s *WhatwebTestSuite) TestBuildArgs_Default() {
+	args := buildArgs("https://example.com", "/tmp/whatweb.json", tools.ScanParams{Host: "example.com", Scheme: "https"})
+	s.Equal([]string{"--color=never", "--quiet", "--aggression", "1", "--log-json=/tmp/whatweb.json", "https://example.com"}, args)
+}
+
+func (s *WhatwebTestSuite) TestBuildArgs_Options() {
+	params := tools.ScanParams{
+		Host:       "10.0.0.1",
+		Port:       80,
+		Vhost:      "shop.example.com",
+		Options:    map[string]string{tools.OptionUserAgent: "wass"},
+		Credential: &vault.Credential{Type: vault.TypeBearer, Secret: vault.Secret{Token: "secret"}},
+		Proxy:      "http://127.0.0.1:8081",
+	}
+	s.Equal([]string{
+		"--color=never", "--quiet", "--aggression", "1", "--log-json=whatweb.json",
+		"--header", "Host: shop.example.com", "--user-agent", "wass", "--header", "Authorization: Bearer secret",
+		"--proxy", "127.0.0.1:8081", "http://10.0.0.1",
+	}, buildArgs("http://10.0.0.1", "whatweb.json", params))
+}
+
+func (s *WhatwebTestSuite) TestScan_Report() {
+	// The fake whatweb writes the report to the --log-json file.
+	s.fakeBinary(`echo 'http://localhost:8080 [200 OK]'; echo '` + jsonReport + `' > "${5#--log-json=}"`)
+
+	result := s.tool.Scan(context.Background(), tools.ScanParams{Host: "localhost", Port: 8080})
+	s.Require().NoError(result.Error)
+	s.Contains(result.Output, `"WordPress"`)
+	s.NotContains(result.Output, "200 OK", "the output is the report")
+	s.Equal([]string{"apache", "jquery", "php", "wordpress"}, s.tool.Fingerprint(result.Output))
+}
+
+func (s *WhatwebTestSuite) TestScan_NoReport() {
+	s.fakeBinary("echo 'ERROR Opening: http://localhost:8080 - Connection refused'")
+
+	result := s.tool.Scan(context.Background(), tools.ScanParams{Host: "localhost", Port: 8080})
+	s.ErrorContains(result.Error, "whatweb did not write a report")
+	s.Contains(result.Output, "Connection refused")
+}
+
+func (s *WhatwebTestSuite) TestScan_Failure() {
+	s.fakeBinary("echo 'invalid option'\nexit 1")
+
+	result := s.tool.Scan(context.Background(), tools.ScanParams{Host: "localhost", Port: 8080})
+	s.ErrorContains(result.Error, "failed to execute whatweb")
+	s.Contains(result.Output, "invalid option")
+}
+
+func (s *WhatwebTestSuite) TestScan_UnsupportedCredential() {
+	params := tools.ScanParams{Host: "localhost", Credential: &vault.Credential{Type: vault.TypeLoginForm}}
+	s.ErrorIs(s.tool.Scan(context.Background(), params).Error, vault.ErrUnsupportedType)
+}
+
+func (s *WhatwebTestSuite) TestHandler_ValidationError() {
+	_, _, err := s.tool.Handler(context.Background(), &mcp.CallToolRequest{}, tools.ScannerInput{Host: "invalid host!!!"})
+	s.ErrorContains(err, "validation error")
+}
+
+func TestWhatwebTestSuite(t *testing.T) {
+	suite.Run(t, new(WhatwebTestSuite))
+}
@@ -0,0 +1,133 @@
+package wordlists
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+	"github.com/tb0hdan/wass-mcp/pkg/server"
+	"github.com/tb0hdan/wass-mcp/pkg/tenant"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+	"github.com/tb0hdan/wass-mcp/pkg/wordlist"
+)
+
+const (
+	toolName = "wordlists"
+	// headEntries is the number of first entries returned by the get action.
+	headEntries = 20
+)
+
+type Input struct {
+	Action string `json:"action" validate:"required,oneof=list get upload delete"`
+	// Content is the wordlist uploaded by the upload action, one entry per line. Larger lists are
+	// uploaded through the admin API.
+	Content string `json:"content,omitempty" validate:"max=1048576"`
+	Name    string `json:"name,omitempty" validate:"omitempty,max=64"`
+}
+
+// modifyingActions are the actions that change stored wordlists, refused to read-only keys.
+var modifyingActions = map[string]struct{}{
+	"delete": {},
+	"upload": {},
+}
+
+type Tool struct {
+	logger    zerolog.Logger
+	registry  *wordlist.Registry
+	validator *validator.Validate
+}
+
+func (t *Tool) Register(srv *server.Server) error {
+	tool := &mcp.Tool{
+		Name: toolName,
+		Description: "Manage the wordlists content discovery scans brute-force paths with, referenced by name in the " +
+			"wordlist input of a scanner or full_scan, e.g. wordlist: \"raft-medium\", instead of a path on the " +
+			"server. Actions: list (your wordlists and the shared ones), get (by name, with the entry count and " +
+			"the first entries), upload (create or replace name from content, one entry per line, up to 1 MiB; " +
+			"larger lists are uploaded through the admin API), delete (by name; shared wordlists are managed " +
+			"through the admin API).",
+	}
+
+	t.registry = srv.Wordlists()
+
+	mcp.AddTool(&srv.Server, tool, t.Handler)
+	t.logger.Debug().Msgf("%s tool registered", toolName)
+
+	return nil
+}
+
+func (t *Tool) Handler(ctx context.Context, _ *mcp.CallToolRequest, input Input) (*mcp.CallToolResult, any, error) {
+	if err := t.validator.Struct(input); err != nil {
+		return nil, nil, fmt.Errorf("validation error: %w", err)
+	}
+	if input.Action != "list" && input.Name == "" {
+		return nil, nil, fmt.Errorf("name is required for %s action", input.Action)
+	}
+	if input.Action == "upload" && input.Content == "" {
+		return nil, nil, fmt.Errorf("content is required for upload action")
+	}
+	if _, modifies := modifyingActions[input.Action]; modifies {
+		if err := tenant.Authorize(ctx, input.Action+" wordlists"); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var result any
+
+	switch input.Action {
+	case "list":
+		wordlists, err := t.registry.List(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		result = map[string]any{
+			"total":     len(wordlists),
+			"wordlists": wordlists,
+		}
+
+	case "get":
+		info, head, err := t.registry.Get(ctx, input.Name, headEntries)
+		if err != nil {
+			return nil, nil, err
+		}
+		result = map[string]any{
+			"head":     head,
+			"wordlist": info,
+		}
+
+	case "upload":
+		info, err := t.registry.Save(ctx, input.Name, strings.NewReader(input.Content))
+		if err != nil {
+			return nil, nil, err
+		}
+		t.logger.Debug().Msgf("Wordlist %s uploaded with %d entries", input.Name, info.Entries)
+		result = info
+
+	case "delete":
+		if err := t.registry.Delete(ctx, input.Name); err != nil {
+			return nil, nil, err
+		}
+		t.logger.Debug().Msgf("Wordlist %s deleted", input.Name)
+		result = map[string]any{"deleted": input.Name}
+	}
+
+	data, _ := json.MarshalIndent(result, "", "  ")
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: string(data)},
+		},
+	}, nil, nil
+}
+
+// New creates a new wordlists tool.
+func New(logger zerolog.Logger) tools.Tool {
+	return &Tool{
+		logger:    logger.With().Str("tool", toolName).Logger(),
+		validator: validator.New(),
+	}
+}
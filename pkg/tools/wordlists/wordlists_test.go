@@ -0,0 +1,107 @@
+package wordlists
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/server"
+	"github.com/tb0hdan/wass-mcp/pkg/storage"
+	"github.com/tb0hdan/wass-mcp/pkg/tenant"
+	"github.com/tb0hdan/wass-mcp/pkg/wordlist"
+)
+
+type WordlistsTestSuite struct {
+	suite.Suite
+	cleanup func()
+	tool    *Tool
+}
+
+func (s *WordlistsTestSuite) SetupTest() {
+	tmpFile, err := os.CreateTemp("", "wordlists-*.db")
+	s.Require().NoError(err)
+	tmpFile.Close()
+
+	store, err := storage.NewSQLiteStorage(storage.Config{DatabasePath: tmpFile.Name()})
+	s.Require().NoError(err)
+	srv := server.NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}, store)
+	srv.SetWordlists(wordlist.New(s.T().TempDir(), 0))
+	s.cleanup = func() {
+		srv.Shutdown(context.Background())
+		os.Remove(tmpFile.Name())
+	}
+
+	s.tool = New(zerolog.Nop()).(*Tool)
+	s.Require().NoError(s.tool.Register(srv))
+}
+
+func (s *WordlistsTestSuite) TearDownTest() {
+	s.cleanup()
+}
+
+// call runs the handler and decodes its JSON response into out.
+func (s *WordlistsTestSuite) call(ctx context.Context, input Input, out any) error {
+	result, _, err := s.tool.Handler(ctx, &mcp.CallToolRequest{}, input)
+	if err != nil {
+		return err
+	}
+	s.Require().NoError(json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), out))
+
+	return nil
+}
+
+func (s *WordlistsTestSuite) TestLifecycle() {
+	ctx := tenant.WithTenant(context.Background(), "alpha")
+
+	var uploaded wordlist.Info
+	s.Require().NoError(s.call(ctx, Input{Action: "upload", Name: "raft-medium", Content: "admin\nbackup\n"}, &uploaded))
+	s.Equal(2, uploaded.Entries)
+	s.False(uploaded.Shared)
+
+	var list map[string]any
+	s.Require().NoError(s.call(ctx, Input{Action: "list"}, &list))
+	s.EqualValues(1, list["total"])
+
+	var got struct {
+		Head     []string      `json:"head"`
+		Wordlist wordlist.Info `json:"wordlist"`
+	}
+	s.Require().NoError(s.call(ctx, Input{Action: "get", Name: "raft-medium"}, &got))
+	s.Equal([]string{"admin", "backup"}, got.Head)
+	s.Equal(2, got.Wordlist.Entries)
+
+	s.ErrorIs(s.call(tenant.WithTenant(context.Background(), "beta"), Input{Action: "get", Name: "raft-medium"}, &got), wordlist.ErrNotFound)
+
+	var deleted map[string]string
+	s.Require().NoError(s.call(ctx, Input{Action: "delete", Name: "raft-medium"}, &deleted))
+	s.Equal("raft-medium", deleted["deleted"])
+	s.ErrorIs(s.call(ctx, Input{Action: "get", Name: "raft-medium"}, &got), wordlist.ErrNotFound)
+}
+
+func (s *WordlistsTestSuite) TestValidation() {
+	ctx := context.Background()
+	var out map[string]any
+	s.ErrorContains(s.call(ctx, Input{Action: "rename"}, &out), "validation error")
+	s.ErrorContains(s.call(ctx, Input{Action: "get"}, &out), "name is required")
+	s.ErrorContains(s.call(ctx, Input{Action: "upload", Name: "common"}, &out), "content is required")
+	s.ErrorIs(s.call(ctx, Input{Action: "upload", Name: "../common", Content: "admin"}, &out), wordlist.ErrInvalidName)
+}
+
+func (s *WordlistsTestSuite) TestReadOnlyCannotModify() {
+	ctx := tenant.WithRole(tenant.WithTenant(context.Background(), "alpha"), tenant.RoleReadOnly)
+	var out map[string]any
+	err := s.call(ctx, Input{Action: "upload", Name: "common", Content: "admin"}, &out)
+	var rpcErr *jsonrpc.Error
+	s.Require().ErrorAs(err, &rpcErr)
+	s.Equal(tenant.CodeForbidden, int(rpcErr.Code))
+	s.NoError(s.call(ctx, Input{Action: "list"}, &out))
+}
+
+func TestWordlistsTestSuite(t *testing.T) {
+	suite.Run(t, new(WordlistsTestSuite))
+}
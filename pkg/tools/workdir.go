@@ -0,0 +1,57 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// urlListName is the file WriteURLList writes the URL list of a scan to.
+const urlListName = "urls.txt"
+
+// ScanWorkDir creates an isolated working directory for one scanner run under base, or under the
+// system temp directory when base is empty. It is named after the tool and the correlation ID of
+// the call. The returned cleanup removes the directory along with everything the scanner left in it.
+func ScanWorkDir(ctx context.Context, base, toolName string) (string, func(), error) {
+	if base != "" {
+		if err := os.MkdirAll(base, 0o750); err != nil {
+			return "", nil, fmt.Errorf("failed to create work directory: %w", err)
+		}
+	}
+
+	pattern := toolName + "-*"
+	if id := CorrelationID(ctx); id != "" {
+		pattern = toolName + "-" + id + "-*"
+	}
+	dir, err := os.MkdirTemp(base, pattern)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create work directory: %w", err)
+	}
+
+	return dir, func() { _ = os.RemoveAll(dir) }, nil
+}
+
+// ScanEnv returns the environment of a scanner run in dir: the server environment with the
+// temporary directory pointed at dir, plus the TLS variables of params.
+func ScanEnv(params ScanParams, dir string) []string {
+	env := append(os.Environ(), "TMPDIR="+dir, "TMP="+dir, "TEMP="+dir)
+
+	return append(env, TLSEnv(params)...)
+}
+
+// WriteURLList writes the crawled URLs of params to a file in dir, one per line, for scanners
+// taking a URL list file, and returns its path. It returns an empty path without URLs.
+func WriteURLList(dir string, params ScanParams) (string, error) {
+	if len(params.URLs) == 0 {
+		return "", nil
+	}
+
+	path := filepath.Join(dir, urlListName)
+	if err := os.WriteFile(path, []byte(strings.Join(params.URLs, "\n")+"\n"), 0o600); err != nil {
+		return "", fmt.Errorf("failed to write URL list: %w", err)
+	}
+
+	return path, nil
+}
@@ -0,0 +1,79 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type WorkDirTestSuite struct {
+	suite.Suite
+}
+
+func (s *WorkDirTestSuite) TestScanWorkDir_UnderBase() {
+	base := filepath.Join(s.T().TempDir(), "scans")
+	ctx := WithCorrelationID(context.Background(), "abc123")
+
+	dir, cleanup, err := ScanWorkDir(ctx, base, "wapiti")
+	s.Require().NoError(err)
+	s.Equal(base, filepath.Dir(dir))
+	s.True(strings.HasPrefix(filepath.Base(dir), "wapiti-abc123-"))
+	s.Require().NoError(os.WriteFile(filepath.Join(dir, "report.json"), []byte("{}"), 0o600))
+
+	other, otherCleanup, err := ScanWorkDir(ctx, base, "wapiti")
+	s.Require().NoError(err)
+	s.NotEqual(dir, other)
+	otherCleanup()
+
+	cleanup()
+	s.NoDirExists(dir)
+	s.DirExists(base)
+}
+
+func (s *WorkDirTestSuite) TestScanWorkDir_SystemTemp() {
+	dir, cleanup, err := ScanWorkDir(context.Background(), "", "nikto")
+	s.Require().NoError(err)
+	defer cleanup()
+
+	s.Equal(filepath.Clean(os.TempDir()), filepath.Dir(dir))
+	s.True(strings.HasPrefix(filepath.Base(dir), "nikto-"))
+}
+
+func (s *WorkDirTestSuite) TestScanWorkDir_InvalidBase() {
+	file := filepath.Join(s.T().TempDir(), "file")
+	s.Require().NoError(os.WriteFile(file, nil, 0o600))
+
+	_, _, err := ScanWorkDir(context.Background(), file, "nikto")
+	s.ErrorContains(err, "failed to create work directory")
+}
+
+func (s *WorkDirTestSuite) TestScanEnv() {
+	env := ScanEnv(ScanParams{}, "/scans/run")
+	s.Equal([]string{"TMPDIR=/scans/run", "TMP=/scans/run", "TEMP=/scans/run"}, env[len(env)-3:])
+
+	env = ScanEnv(ScanParams{CABundle: "/etc/ca.pem"}, "/scans/run")
+	s.Equal([]string{"SSL_CERT_FILE=/etc/ca.pem", "REQUESTS_CA_BUNDLE=/etc/ca.pem"}, env[len(env)-2:])
+	s.Contains(env, "TMPDIR=/scans/run")
+}
+
+func (s *WorkDirTestSuite) TestWriteURLList() {
+	dir := s.T().TempDir()
+
+	path, err := WriteURLList(dir, ScanParams{})
+	s.Require().NoError(err)
+	s.Empty(path, "no list is written without URLs")
+
+	path, err = WriteURLList(dir, ScanParams{URLs: []string{"http://example.com/", "http://example.com/login"}})
+	s.Require().NoError(err)
+	data, err := os.ReadFile(path)
+	s.Require().NoError(err)
+	s.Equal("http://example.com/\nhttp://example.com/login\n", string(data))
+}
+
+func TestWorkDirTestSuite(t *testing.T) {
+	suite.Run(t, new(WorkDirTestSuite))
+}
@@ -0,0 +1,142 @@
+package wpscan
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/tb0hdan/wass-mcp/pkg/findings"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/types"
+)
+
+// vulnerability is a vulnerability of a wpscan JSON report component.
+type vulnerability struct {
+	CVSS *struct {
+		Score string `json:"score"`
+	} `json:"cvss"`
+	FixedIn    string `json:"fixed_in"`
+	References struct {
+		CVE []string `json:"cve"`
+		URL []string `json:"url"`
+	} `json:"references"`
+	Title string `json:"title"`
+}
+
+// component is the WordPress core, a theme or a plugin of a wpscan JSON report.
+type component struct {
+	Vulnerabilities []vulnerability `json:"vulnerabilities"`
+}
+
+// report is the subset of a wpscan JSON report used for findings.
+type report struct {
+	InterestingFindings []struct {
+		ToS string `json:"to_s"`
+		URL string `json:"url"`
+	} `json:"interesting_findings"`
+	MainTheme *component           `json:"main_theme"`
+	Plugins   map[string]component `json:"plugins"`
+	TargetURL string               `json:"target_url"`
+	Themes    map[string]component `json:"themes"`
+	Version   *component           `json:"version"`
+}
+
+// ParseFindings parses a wpscan JSON report: the vulnerabilities of the WordPress core, themes and
+// plugins, rated by their CVSS score when known and high otherwise, and the interesting findings
+// as info. Output that is not JSON falls back to bracketed severity tags.
+func (t *Tool) ParseFindings(output string) ([]models.Finding, error) {
+	start := strings.Index(output, "{")
+	end := strings.LastIndex(output, "}")
+	if start < 0 || end <= start {
+		return findings.ExtractGeneric(binaryName, output), nil
+	}
+
+	var parsed report
+	if err := json.Unmarshal([]byte(output[start:end+1]), &parsed); err != nil {
+		return findings.ExtractGeneric(binaryName, output), nil //nolint:nilerr
+	}
+
+	var found []models.Finding
+	for _, interesting := range parsed.InterestingFindings {
+		found = append(found, models.Finding{
+			Scanner:  binaryName,
+			Severity: types.SeverityInfo,
+			Title:    interesting.ToS,
+			URL:      interesting.URL,
+		})
+	}
+
+	// Components are keyed for a stable order: the core, the main theme, then plugins and themes.
+	components := map[string]*component{"0 core": parsed.Version, "1 main theme": parsed.MainTheme}
+	for name, plugin := range parsed.Plugins {
+		components["2 plugin "+name] = &plugin
+	}
+	for name, theme := range parsed.Themes {
+		components["3 theme "+name] = &theme
+	}
+	names := make([]string, 0, len(components))
+	for name := range components {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if components[name] == nil {
+			continue
+		}
+		for _, vuln := range components[name].Vulnerabilities {
+			title := vuln.Title
+			if vuln.FixedIn != "" {
+				title += " (fixed in " + vuln.FixedIn + ")"
+			}
+			found = append(found, models.Finding{
+				CVEs:       cves(vuln.References.CVE),
+				References: vuln.References.URL,
+				Scanner:    binaryName,
+				Severity:   vuln.severity(),
+				Title:      title,
+				URL:        parsed.TargetURL,
+			})
+		}
+	}
+
+	return found, nil
+}
+
+// severity rates the vulnerability by its CVSS score, high when it has none.
+func (v vulnerability) severity() string {
+	if v.CVSS == nil {
+		return types.SeverityHigh
+	}
+	score, err := strconv.ParseFloat(v.CVSS.Score, 64)
+	if err != nil {
+		return types.SeverityHigh
+	}
+
+	switch {
+	case score >= 9:
+		return types.SeverityCritical
+	case score >= 7:
+		return types.SeverityHigh
+	case score >= 4:
+		return types.SeverityMedium
+	case score > 0:
+		return types.SeverityLow
+	default:
+		return types.SeverityInfo
+	}
+}
+
+// cves returns the CVE IDs of wpscan CVE references, which omit the CVE- prefix.
+func cves(references []string) []string {
+	var ids []string
+	for _, reference := range references {
+		if !strings.HasPrefix(strings.ToUpper(reference), "CVE-") {
+			reference = "CVE-" + reference
+		}
+		ids = append(ids, reference)
+	}
+
+	return ids
+}
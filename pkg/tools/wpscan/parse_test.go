@@ -0,0 +1,64 @@
+package wpscan
+
+import (
+	"os"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/types"
+)
+
+const jsonOutput = `{
+  "target_url": "https://blog.example.com/",
+  "interesting_findings": [
+    {"to_s": "XML-RPC seems to be enabled: https://blog.example.com/xmlrpc.php", "type": "xmlrpc", "url": "https://blog.example.com/xmlrpc.php"}
+  ],
+  "version": {"number": "6.1", "vulnerabilities": [
+    {"title": "WP < 6.1.1 - Unauthenticated Blind SSRF via DNS Rebinding", "fixed_in": "6.1.1", "references": {"cve": ["2022-3590"], "url": ["https://example.com/advisory"]}}
+  ]},
+  "main_theme": null,
+  "plugins": {
+    "contact-form-7": {"vulnerabilities": [
+      {"title": "Contact Form 7 < 5.3.2 - Unrestricted File Upload", "cvss": {"score": "9.8"}, "references": {}}
+    ]}
+  }
+}`
+
+type ParseTestSuite struct {
+	suite.Suite
+	tool *Tool
+}
+
+func (s *ParseTestSuite) SetupTest() {
+	s.tool = New(zerolog.New(os.Stdout)).(*Tool)
+}
+
+func (s *ParseTestSuite) TestParseFindings() {
+	found, err := s.tool.ParseFindings(jsonOutput)
+	s.Require().NoError(err)
+	s.Require().Len(found, 3)
+
+	s.Equal(types.SeverityInfo, found[0].Severity)
+	s.Equal("https://blog.example.com/xmlrpc.php", found[0].URL)
+
+	s.Equal("WP < 6.1.1 - Unauthenticated Blind SSRF via DNS Rebinding (fixed in 6.1.1)", found[1].Title)
+	s.Equal(types.SeverityHigh, found[1].Severity)
+	s.Equal([]string{"CVE-2022-3590"}, []string(found[1].CVEs))
+	s.Equal([]string{"https://example.com/advisory"}, []string(found[1].References))
+	s.Equal("https://blog.example.com/", found[1].URL)
+
+	s.Equal("Contact Form 7 < 5.3.2 - Unrestricted File Upload", found[2].Title)
+	s.Equal(types.SeverityCritical, found[2].Severity)
+	s.Equal("wpscan", found[2].Scanner)
+}
+
+func (s *ParseTestSuite) TestParseFindings_TextFallback() {
+	found, err := s.tool.ParseFindings("Scan Aborted [high] http://blog.example.com")
+	s.Require().NoError(err)
+	s.Len(found, 1)
+}
+
+func TestParseTestSuite(t *testing.T) {
+	suite.Run(t, new(ParseTestSuite))
+}
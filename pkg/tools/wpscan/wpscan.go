@@ -0,0 +1,179 @@
+package wpscan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+	"github.com/tb0hdan/wass-mcp/pkg/server"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+	"github.com/tb0hdan/wass-mcp/pkg/vault"
+)
+
+const (
+	binaryName  = "wpscan"
+	description = "WPScan is a WordPress security scanner enumerating the vulnerable core, plugins and themes of WordPress sites. " +
+		"Pass the site URL as host, options.enumerate to choose what to enumerate (e.g. vp,vt,u) and options.api_token for " +
+		"vulnerability data from the WPScan API."
+	headerVerb = "output"
+)
+
+// exitVulnerable is the exit code of wpscan runs that found vulnerabilities.
+const exitVulnerable = 5
+
+// configFile is the wpscan config file, relative to the working directory, that wpscan loads
+// besides the one in the home directory.
+const configFile = ".wpscan/scan.yml"
+
+// technologies are the technologies wpscan applies to.
+var technologies = []string{"wordpress"}
+
+// supportedOptions are the scan options wpscan honours.
+var supportedOptions = []string{
+	tools.OptionAPIToken, tools.OptionCapture, tools.OptionCredential, tools.OptionEnumerate, tools.OptionInsecureSkipVerify,
+	tools.OptionRateLimit, tools.OptionUserAgent, tools.OptionVhost,
+}
+
+// Tool implements the wpscan scanner.
+type Tool struct {
+	tools.BaseScanner
+	// apiToken is the WPScan API token of scans that pass none, see SetAPIToken.
+	apiToken string
+}
+
+// SetAPIToken sets the WPScan API token used by scans without an api_token option. Without a
+// token, wpscan enumerates WordPress components but reports no vulnerabilities.
+func (t *Tool) SetAPIToken(token string) {
+	t.apiToken = token
+}
+
+// Scan performs the wpscan scan and returns its JSON report.
+func (t *Tool) Scan(ctx context.Context, params tools.ScanParams) tools.ScanResult {
+	targetURL := params.Target().URL()
+	logger := tools.ContextLogger(ctx, t.Logger)
+	logger.Info().Msgf("Running wpscan scan on %s", targetURL)
+
+	if err := params.CheckCredential(binaryName, vault.HeaderTypes...); err != nil {
+		return tools.ScanResult{
+			Error: err,
+		}
+	}
+
+	workDir, cleanup, err := t.WorkDir(ctx)
+	if err != nil {
+		return tools.ScanResult{
+			Error: err,
+		}
+	}
+	defer cleanup()
+
+	apiToken := params.Option(tools.OptionAPIToken)
+	if apiToken == "" {
+		apiToken = t.apiToken
+	}
+	if apiToken != "" {
+		// The token is read from a config file in the working directory to keep it off the
+		// command line.
+		if err := writeConfig(workDir, apiToken); err != nil {
+			return tools.ScanResult{
+				Error: err,
+			}
+		}
+	}
+
+	cmd := t.Command(ctx, buildArgs(targetURL, params)...)
+	cmd.Dir = workDir
+	cmd.Env = tools.ScanEnv(params, workDir)
+	output, err := t.CombinedOutput(ctx, cmd)
+
+	// wpscan reports found vulnerabilities through its exit code.
+	var exitErr *exec.ExitError
+	if err != nil && !(errors.As(err, &exitErr) && exitErr.ExitCode() == exitVulnerable) {
+		return tools.ScanResult{
+			Output: string(output),
+			Error:  fmt.Errorf("failed to execute wpscan: %w", err),
+		}
+	}
+
+	return tools.ScanResult{
+		Output: string(output),
+		Error:  nil,
+	}
+}
+
+// writeConfig writes the wpscan config file passing apiToken in dir.
+func writeConfig(dir, apiToken string) error {
+	path := filepath.Join(dir, configFile)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create wpscan config directory: %w", err)
+	}
+	config := fmt.Sprintf("cli_options:\n  api_token: %q\n", apiToken)
+	if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+		return fmt.Errorf("failed to write wpscan config: %w", err)
+	}
+
+	return nil
+}
+
+// buildArgs builds the wpscan command line arguments. The API token is passed in the config file,
+// see writeConfig.
+func buildArgs(targetURL string, params tools.ScanParams) []string {
+	args := []string{"--url", targetURL, "--format", "json", "--no-banner"}
+	if params.Vhost != "" {
+		args = append(args, "--vhost", params.Vhost)
+	}
+	if params.InsecureSkipVerify {
+		args = append(args, "--disable-tls-checks")
+	}
+	if userAgent := params.Option(tools.OptionUserAgent); userAgent != "" {
+		args = append(args, "--user-agent", userAgent)
+	}
+	if enumerate := params.Option(tools.OptionEnumerate); enumerate != "" {
+		args = append(args, "--enumerate", strings.ReplaceAll(enumerate, " ", ""))
+	}
+	if rate, err := strconv.Atoi(params.Option(tools.OptionRateLimit)); err == nil && rate > 0 {
+		// --throttle waits the given milliseconds between requests, with a single thread.
+		args = append(args, "--throttle", strconv.Itoa(1000/rate))
+	}
+	if params.Proxy != "" {
+		args = append(args, "--proxy", params.Proxy)
+	}
+	if params.Credential != nil {
+		switch params.Credential.Type {
+		case vault.TypeBasic:
+			args = append(args, "--http-auth", params.Credential.Username+":"+params.Credential.Password)
+		case vault.TypeCookie:
+			args = append(args, "--cookie-string", params.Credential.Cookie)
+		default:
+			args = append(args, "--headers", strings.Join(params.Credential.Headers(), "; "))
+		}
+	}
+
+	return args
+}
+
+// Register registers the wpscan tool with the MCP server.
+func (t *Tool) Register(srv *server.Server) error {
+	return t.RegisterTool(srv, t.Handler)
+}
+
+// Handler handles MCP tool requests.
+func (t *Tool) Handler(ctx context.Context, _ *mcp.CallToolRequest, input tools.ScannerInput) (*mcp.CallToolResult, any, error) {
+	return t.HandleScan(ctx, input, headerVerb, t.Scan)
+}
+
+// New creates a new wpscan scanner tool.
+func New(logger zerolog.Logger) tools.Scanner {
+	base := tools.NewBaseScanner(binaryName, description, logger, supportedOptions...)
+	base.ForTechnologies = technologies
+	base.VersionArgs = []string{"--version", "--no-banner"}
+
+	return &Tool{BaseScanner: base}
+}
@@ -0,0 +1,123 @@
+package wpscan
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+	"github.com/tb0hdan/wass-mcp/pkg/vault"
+)
+
+type WpscanTestSuite struct {
+	suite.Suite
+	logger zerolog.Logger
+	tool   *Tool
+}
+
+func (s *WpscanTestSuite) SetupTest() {
+	s.logger = zerolog.New(os.Stdout).With().Timestamp().Logger()
+	s.tool = New(s.logger).(*Tool)
+}
+
+func (s *WpscanTestSuite) TestNew() {
+	s.Equal("wpscan", s.tool.Name())
+	s.Equal([]string{"wordpress"}, tools.Technologies(s.tool))
+	s.False(tools.IsPassive(s.tool))
+}
+
+func (s *WpscanTestSuite) TestBuildArgs_Default() {
+	args := buildArgs("https://blog.example.com", tools.ScanParams{})
+	s.Equal([]string{"--url", "https://blog.example.com", "--format", "json", "--no-banner"}, args)
+}
+
+func (s *WpscanTestSuite) TestBuildArgs_Options() {
+	params := tools.ScanParams{
+		InsecureSkipVerify: true,
+		Options:            map[string]string{tools.OptionRateLimit: "4", tools.OptionUserAgent: "wass"},
+		Proxy:              "http://127.0.0.1:8080",
+		Vhost:              "blog.example.com",
+	}
+	args := buildArgs("https://10.0.0.1", params)
+	s.Equal([]string{
+		"--url", "https://10.0.0.1", "--format", "json", "--no-banner",
+		"--vhost", "blog.example.com", "--disable-tls-checks", "--user-agent", "wass", "--throttle", "250",
+		"--proxy", "http://127.0.0.1:8080",
+	}, args)
+}
+
+func (s *WpscanTestSuite) TestBuildArgs_Credential() {
+	basic := &vault.Credential{Type: vault.TypeBasic, Secret: vault.Secret{Username: "user", Password: "pass"}}
+	args := buildArgs("http://localhost", tools.ScanParams{Credential: basic})
+	s.Equal([]string{"--http-auth", "user:pass"}, args[len(args)-2:])
+
+	cookie := &vault.Credential{Type: vault.TypeCookie, Secret: vault.Secret{Cookie: "session=abc"}}
+	args = buildArgs("http://localhost", tools.ScanParams{Credential: cookie})
+	s.Equal([]string{"--cookie-string", "session=abc"}, args[len(args)-2:])
+
+	bearer := &vault.Credential{Type: vault.TypeBearer, Secret: vault.Secret{Token: "abc"}}
+	args = buildArgs("http://localhost", tools.ScanParams{Credential: bearer})
+	s.Equal([]string{"--headers", "Authorization: Bearer abc"}, args[len(args)-2:])
+}
+
+func (s *WpscanTestSuite) TestScan_VulnerableExitCode() {
+	// The fake wpscan exits with the code of runs that found vulnerabilities.
+	binDir := s.T().TempDir()
+	script := "#!/bin/sh\necho '{\"target_url\": \"http://localhost/\"}'\nexit 5\n"
+	s.Require().NoError(os.WriteFile(filepath.Join(binDir, binaryName), []byte(script), 0o700)) //nolint:gosec
+	s.T().Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	result := s.tool.Scan(context.Background(), tools.ScanParams{Host: "localhost", Port: 80, Scheme: "http"})
+	s.Require().NoError(result.Error)
+	s.Contains(result.Output, "target_url")
+
+	script = "#!/bin/sh\necho 'Scan Aborted: The remote website is up, but does not seem to be running WordPress.'\nexit 4\n"
+	s.Require().NoError(os.WriteFile(filepath.Join(binDir, binaryName), []byte(script), 0o700)) //nolint:gosec
+	result = s.tool.Scan(context.Background(), tools.ScanParams{Host: "localhost", Port: 80, Scheme: "http"})
+	s.ErrorContains(result.Error, "failed to execute wpscan")
+	s.Contains(result.Output, "Scan Aborted")
+}
+
+func (s *WpscanTestSuite) TestBuildArgs_Enumerate() {
+	params := tools.ScanParams{Options: map[string]string{tools.OptionEnumerate: "vp, vt,u1-20", tools.OptionAPIToken: "secret"}}
+	args := buildArgs("https://blog.example.com/wp", params)
+	s.Equal([]string{
+		"--url", "https://blog.example.com/wp", "--format", "json", "--no-banner", "--enumerate", "vp,vt,u1-20",
+	}, args)
+}
+
+func (s *WpscanTestSuite) TestScan_APIToken() {
+	// The fake wpscan prints the config file it finds in its working directory.
+	binDir := s.T().TempDir()
+	script := "#!/bin/sh\ncat .wpscan/scan.yml 2>/dev/null || echo 'no config'\n"
+	s.Require().NoError(os.WriteFile(filepath.Join(binDir, binaryName), []byte(script), 0o700)) //nolint:gosec
+	s.T().Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	params := tools.ScanParams{Host: "localhost", Port: 80, Scheme: "http"}
+
+	result := s.tool.Scan(context.Background(), params)
+	s.Require().NoError(result.Error)
+	s.Equal("no config\n", result.Output)
+
+	s.tool.SetAPIToken("server-token")
+	result = s.tool.Scan(context.Background(), params)
+	s.Require().NoError(result.Error)
+	s.Equal("cli_options:\n  api_token: \"server-token\"\n", result.Output)
+
+	params.Options = map[string]string{tools.OptionAPIToken: "call-token"}
+	result = s.tool.Scan(context.Background(), params)
+	s.Require().NoError(result.Error)
+	s.Contains(result.Output, `api_token: "call-token"`)
+}
+
+func (s *WpscanTestSuite) TestHandler_ValidationError() {
+	_, _, err := s.tool.Handler(context.Background(), &mcp.CallToolRequest{}, tools.ScannerInput{Host: "invalid host!!!"})
+	s.ErrorContains(err, "validation error")
+}
+
+func TestWpscanTestSuite(t *testing.T) {
+	suite.Run(t, new(WpscanTestSuite))
+}
@@ -3,58 +3,624 @@ package tools
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/tb0hdan/wass-mcp/pkg/artifacts"
+	"github.com/tb0hdan/wass-mcp/pkg/findings"
+	"github.com/tb0hdan/wass-mcp/pkg/intel"
+	"github.com/tb0hdan/wass-mcp/pkg/metrics"
 	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/redact"
+	"github.com/tb0hdan/wass-mcp/pkg/running"
+	"github.com/tb0hdan/wass-mcp/pkg/server"
 	"github.com/tb0hdan/wass-mcp/pkg/storage"
+	"github.com/tb0hdan/wass-mcp/pkg/suppress"
+	"github.com/tb0hdan/wass-mcp/pkg/tenant"
+	"github.com/tb0hdan/wass-mcp/pkg/types"
 )
 
+// executionKey is the context key for the in-flight execution.
+type executionKey struct{}
+
+// execution is the in-flight execution record together with findings reported by the handler.
+type execution struct {
+	// captures are the HAR captures of the scanner runs, see CaptureScan. Guarded by mu since
+	// multi-scanner tools record them from concurrent runs.
+	captures []scanCapture
+	findings []models.Finding
+	// input replaces the call input in the stored record when set, see RecordInput.
+	input any
+	// loadSuppressions loads the suppression rules of the tenant, see SuppressFindings.
+	loadSuppressions func() *suppress.Matcher
+	// openStream creates the output stream of the execution, nil when output is not streamed,
+	// see BaseScanner.CombinedOutput.
+	openStream func() *artifacts.Stream
+	parsed     bool
+	// phases are the durations of the execution phases, see RecordPhase. Guarded by mu since
+	// multi-scanner tools record them from concurrent runs.
+	phases map[string]time.Duration
+	// profiles are the technology profiles of the scanned targets, see RecordTargetProfile.
+	// Guarded by mu.
+	profiles []models.TargetProfile
+	// runs are the scanner runs of the execution, see RecordScannerRun. Guarded by mu.
+	runs   []models.ScannerRun
+	record *models.ToolExecution
+	// redactor redacts streamed output.
+	redactor *redact.Redactor
+	// state is the per-scanner state of a paused run, nil unless some runs were held.
+	state []models.ScannerState
+	// stream is the output stream opened on first use, guarded by streamOnce.
+	stream     *artifacts.Stream
+	streamOnce sync.Once
+	// suppressed counts the findings dropped by SuppressFindings. Guarded by mu.
+	suppressed int
+	// suppressions are the suppression rules loaded on first use.
+	suppressions     *suppress.Matcher
+	suppressionsOnce sync.Once
+
+	mu sync.Mutex
+}
+
+// matcher returns the suppression rules of the execution, loaded on first use. A nil matcher
+// suppresses nothing.
+func (e *execution) matcher() *suppress.Matcher {
+	e.suppressionsOnce.Do(func() {
+		if e.loadSuppressions != nil {
+			e.suppressions = e.loadSuppressions()
+		}
+	})
+
+	return e.suppressions
+}
+
+// SuppressFindings drops the findings matched by the configured suppression rules and those of
+// the tenant, counting them with the in-flight execution. Findings stored by the wrapper are
+// always filtered; handlers call it to leave suppressed findings out of their own reports too.
+// It returns found unchanged outside WrapToolHandler.
+func SuppressFindings(ctx context.Context, found []models.Finding) []models.Finding {
+	inFlight, ok := ctx.Value(executionKey{}).(*execution)
+	if !ok {
+		return found
+	}
+	kept, suppressed := inFlight.matcher().Filter(found)
+	inFlight.mu.Lock()
+	inFlight.suppressed += suppressed
+	inFlight.mu.Unlock()
+
+	return kept
+}
+
+// RecordRawOutput attaches the full, unpaginated tool output to the in-flight execution record
+// so that it is persisted alongside the paginated response. It is a no-op outside WrapToolHandler.
+func RecordRawOutput(ctx context.Context, output string) {
+	if inFlight, ok := ctx.Value(executionKey{}).(*execution); ok {
+		inFlight.record.RawOutput = output
+	}
+}
+
+// RawOutputWriter returns a writer whose content is recorded as the raw output of the in-flight
+// execution, like RecordRawOutput, for outputs written piece by piece. Its buffer is grown to size
+// upfront, so that an output of about that size is not copied as it grows. It returns io.Discard
+// outside WrapToolHandler, so that nothing is kept when nothing is stored.
+func RawOutputWriter(ctx context.Context, size int) io.Writer {
+	if inFlight, ok := ctx.Value(executionKey{}).(*execution); ok {
+		writer := &rawOutputWriter{record: inFlight.record}
+		writer.output.Grow(size)
+		return writer
+	}
+
+	return io.Discard
+}
+
+// rawOutputWriter appends the output written to it to the raw output of an execution record.
+type rawOutputWriter struct {
+	output strings.Builder
+	record *models.ToolExecution
+}
+
+// Write appends p to the raw output. Writes never fail.
+func (w *rawOutputWriter) Write(p []byte) (int, error) {
+	w.output.Write(p)
+	// String does not copy the output.
+	w.record.RawOutput = w.output.String()
+
+	return len(p), nil
+}
+
+// WriteString appends s to the raw output without converting it to bytes first.
+func (w *rawOutputWriter) WriteString(s string) (int, error) {
+	w.output.WriteString(s)
+	w.record.RawOutput = w.output.String()
+
+	return len(s), nil
+}
+
+// RecordFindings attaches findings already parsed by the handler to the in-flight execution,
+// so that they are stored instead of being re-extracted from the raw output.
+// It is a no-op outside WrapToolHandler.
+func RecordFindings(ctx context.Context, found []models.Finding) {
+	if inFlight, ok := ctx.Value(executionKey{}).(*execution); ok {
+		inFlight.findings = found
+		inFlight.parsed = true
+	}
+}
+
+// RecordPhase adds d to the duration of phase, one of the models.Phase constants, of the in-flight
+// execution. It is a no-op outside WrapToolHandler.
+func RecordPhase(ctx context.Context, phase string, d time.Duration) {
+	if inFlight, ok := ctx.Value(executionKey{}).(*execution); ok {
+		inFlight.addPhase(phase, d)
+	}
+}
+
+// addPhase adds d to the duration of phase.
+func (e *execution) addPhase(phase string, d time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.phases == nil {
+		e.phases = make(map[string]time.Duration)
+	}
+	e.phases[phase] += d
+}
+
+// durations returns the durations of every phase recorded so far, 0 for phases not recorded.
+func (e *execution) durations() map[string]time.Duration {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	durations := make(map[string]time.Duration, len(models.Phases))
+	for _, phase := range models.Phases {
+		durations[phase] = e.phases[phase]
+	}
+	return durations
+}
+
+// timings returns the phase timings recorded so far.
+func (e *execution) timings() models.PhaseTimings {
+	var timings models.PhaseTimings
+	for phase, d := range e.durations() {
+		timings.Set(phase, d)
+	}
+	return timings
+}
+
+// RecordScanState attaches the per-scanner state of a multi-scanner run to the in-flight
+// execution. When some runs were held the execution is stored as paused, keeping the state for a
+// resume. It is a no-op outside WrapToolHandler.
+func RecordScanState(ctx context.Context, state []models.ScannerState) {
+	inFlight, ok := ctx.Value(executionKey{}).(*execution)
+	if !ok {
+		return
+	}
+	for _, scanner := range state {
+		if scanner.Status == models.ScannerHeld {
+			inFlight.state = state
+			return
+		}
+	}
+}
+
+// RecordScannerRun adds a finished scanner run to the timeline of the in-flight execution, see
+// TimelineScan. It is a no-op outside WrapToolHandler.
+func RecordScannerRun(ctx context.Context, run models.ScannerRun) {
+	if inFlight, ok := ctx.Value(executionKey{}).(*execution); ok {
+		inFlight.mu.Lock()
+		defer inFlight.mu.Unlock()
+		inFlight.runs = append(inFlight.runs, run)
+	}
+}
+
+// timeline returns the scanner runs recorded so far in the order they were queued.
+func (e *execution) timeline() []models.ScannerRun {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	runs := slices.Clone(e.runs)
+	slices.SortStableFunc(runs, func(a, b models.ScannerRun) int { return a.QueuedAt.Compare(b.QueuedAt) })
+	return runs
+}
+
+// RecordTargetProfile attaches the technology profile of a scanned target to the in-flight
+// execution. Profiles are stored with the execution, replacing the stored profile of the same
+// target. It is a no-op outside WrapToolHandler.
+func RecordTargetProfile(ctx context.Context, profile models.TargetProfile) {
+	if inFlight, ok := ctx.Value(executionKey{}).(*execution); ok {
+		inFlight.mu.Lock()
+		defer inFlight.mu.Unlock()
+		inFlight.profiles = append(inFlight.profiles, profile)
+	}
+}
+
+// RecordInput replaces the input of the in-flight execution, for handlers resolving their
+// effective input while running, such as resumed scans. The stored input and target are derived
+// from it. It is a no-op outside WrapToolHandler.
+func RecordInput(ctx context.Context, input any) {
+	if inFlight, ok := ctx.Value(executionKey{}).(*execution); ok {
+		inFlight.input = input
+	}
+}
+
+// ExecutionID returns the ID of the in-flight execution record, 0 outside WrapToolHandler or when
+// the record could not be created up front.
+func ExecutionID(ctx context.Context) uint {
+	if inFlight, ok := ctx.Value(executionKey{}).(*execution); ok {
+		return inFlight.record.ID
+	}
+
+	return 0
+}
+
+// wrapConfig holds the execution logging options.
+type wrapConfig struct {
+	artifacts artifacts.Config
+	intel     *intel.Intel
+	jobs      *running.Registry
+	metrics   *metrics.Metrics
+	redactor  *redact.Redactor
+	server    *server.Server
+	// suppressions are the configured suppression rules, applied with the stored rules of the tenant.
+	suppressions []models.SuppressionRule
+}
+
+// WrapOption configures WrapToolHandler.
+type WrapOption func(*wrapConfig)
+
+// WithRedactor sets the redactor applied to execution records before they are stored.
+// The default redactor is used when not set.
+func WithRedactor(redactor *redact.Redactor) WrapOption {
+	return func(cfg *wrapConfig) {
+		if redactor != nil {
+			cfg.redactor = redactor
+		}
+	}
+}
+
+// WithArtifacts spills stored outputs larger than cfg.MaxOutputBytes to artifact files in cfg.Dir.
+func WithArtifacts(cfg artifacts.Config) WrapOption {
+	return func(wc *wrapConfig) {
+		wc.artifacts = cfg
+	}
+}
+
+// WithIntel enriches stored findings with the EPSS and KEV data of threatIntel, factored into the
+// risk score of the execution.
+func WithIntel(threatIntel *intel.Intel) WrapOption {
+	return func(wc *wrapConfig) {
+		wc.intel = threatIntel
+	}
+}
+
+// WithJobs lists executions in jobs while they run so that they can be cancelled.
+func WithJobs(jobs *running.Registry) WrapOption {
+	return func(wc *wrapConfig) {
+		wc.jobs = jobs
+	}
+}
+
+// WithMetrics records the sizes of tool outputs in scanMetrics.
+func WithMetrics(scanMetrics *metrics.Metrics) WrapOption {
+	return func(wc *wrapConfig) {
+		wc.metrics = scanMetrics
+	}
+}
+
+// WithSuppressionRules drops findings matched by rules, in addition to the stored suppression
+// rules of the tenant, before they are stored and scored.
+func WithSuppressionRules(rules []models.SuppressionRule) WrapOption {
+	return func(wc *wrapConfig) {
+		wc.suppressions = rules
+	}
+}
+
+// WithRerunRegistration registers the wrapped handler with srv so that interrupted executions
+// of the tool can be re-run on startup.
+func WithRerunRegistration(srv *server.Server) WrapOption {
+	return func(wc *wrapConfig) {
+		wc.server = srv
+	}
+}
+
+// ServerWrapOptions returns the execution logging options configured on srv.
+func ServerWrapOptions(srv *server.Server) []WrapOption {
+	return []WrapOption{
+		WithArtifacts(srv.Artifacts()),
+		WithIntel(srv.Intel()),
+		WithJobs(srv.Jobs()),
+		WithMetrics(srv.Metrics()),
+		WithRedactor(srv.Redactor()),
+		WithRerunRegistration(srv),
+		WithSuppressionRules(srv.SuppressionRules()),
+	}
+}
+
 // WrapToolHandler wraps a tool handler to add execution logging.
+// Inputs, outputs and error messages are redacted before they are stored. Outputs are stored
+// normalized, see StoreResult, and spilled to artifact files above the configured size limit.
 func WrapToolHandler[In, Out any](
 	store storage.Storage,
 	toolName string,
 	handler func(context.Context, *mcp.CallToolRequest, In) (*mcp.CallToolResult, Out, error),
+	opts ...WrapOption,
 ) func(context.Context, *mcp.CallToolRequest, In) (*mcp.CallToolResult, Out, error) {
-	return func(ctx context.Context, req *mcp.CallToolRequest, input In) (*mcp.CallToolResult, Out, error) {
+	cfg := wrapConfig{redactor: redact.Default()}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	wrapped := func(ctx context.Context, req *mcp.CallToolRequest, input In) (*mcp.CallToolResult, Out, error) {
 		startTime := time.Now()
 
-		// Get session ID from request
+		// Get session ID and client from request
 		sessionID := ""
 		if req.Session != nil {
 			sessionID = req.Session.ID()
 		}
+		client := server.ClientFromRequest(ctx, req)
 
-		// Marshal input for logging
-		inputJSON, _ := json.Marshal(input)
+		// Trace the call across logs, the execution record, artifacts and errors. Calls made on
+		// behalf of a background scan job keep the correlation ID of the job.
+		correlationID := CorrelationID(ctx)
+		if correlationID == "" {
+			correlationID = NewCorrelationID()
+		}
+		ctx = WithCorrelationID(ctx, correlationID)
+		ctx = WithSessionID(ctx, sessionID)
 
-		// Execute the actual handler
-		result, output, err := handler(ctx, req, input)
+		// Create execution record, exposed to the handler for raw output capture
+		tenantName, _ := tenant.FromContext(ctx)
+		exec := &models.ToolExecution{
+			CorrelationID: correlationID,
+			SessionID:     sessionID,
+			Tenant:        tenantName,
+			ClientName:    client.Name,
+			ClientVersion: client.Version,
+			RemoteAddr:    client.RemoteAddr,
+			ToolName:      toolName,
+			Status:        models.StatusRunning,
+		}
+		setInput(exec, cfg.redactor, input)
+		if retryable, ok := any(input).(RetryableInput); ok {
+			exec.Retryable = retryable.IsRetryable()
+		}
 
-		duration := time.Since(startTime)
+		// Record the running execution so that it can be recovered if the server dies mid-run.
+		// On failure the record is created once the handler completes instead.
+		persistStart := time.Now()
+		_ = store.CreateToolExecution(context.WithoutCancel(ctx), exec)
+		created := time.Since(persistStart)
 
-		// Create execution record
-		exec := &models.ToolExecution{
-			SessionID:  sessionID,
-			ToolName:   toolName,
-			InputJSON:  string(inputJSON),
-			DurationMs: duration.Milliseconds(),
-			Success:    err == nil,
+		// Execute the actual handler, cancellable while it is listed as a running job
+		jobCtx, done := cfg.jobs.Start(ctx, running.Job{
+			CorrelationID: correlationID,
+			ExecutionID:   exec.ID,
+			SessionID:     sessionID,
+			StartedAt:     startTime,
+			Target:        exec.Target,
+			Tenant:        tenantName,
+			ToolName:      toolName,
+		})
+		inFlight := &execution{record: exec, redactor: cfg.redactor}
+		inFlight.addPhase(models.PhasePersist, created)
+		suppressCtx := context.WithoutCancel(ctx)
+		inFlight.loadSuppressions = func() *suppress.Matcher {
+			// Findings are kept when the rules cannot be loaded.
+			matcher, _ := suppress.Load(suppressCtx, store, cfg.suppressions)
+			return matcher
 		}
+		inFlight.openStream = func() *artifacts.Stream {
+			// Output is streamed to the running record only.
+			if cfg.artifacts.Dir == "" || exec.ID == 0 {
+				return nil
+			}
+			stream, err := artifacts.OpenStream(cfg.artifacts.Dir, artifacts.Prefix(exec), types.StreamFlushInterval)
+			if err != nil {
+				return nil
+			}
+			_ = store.UpdateToolExecutionStream(suppressCtx, exec.ID, stream.Path())
+			return stream
+		}
+		result, output, err := handler(context.WithValue(jobCtx, executionKey{}, inFlight), req, input)
+		canceled := running.Canceled(jobCtx)
+		done()
+		// The final record holds the whole output, so the stream is removed once it is stored.
+		streamFile := inFlight.closeStream()
 
-		if err != nil {
-			exec.ErrorMessage = err.Error()
-		} else if result != nil {
-			outputJSON, _ := json.Marshal(result)
-			exec.OutputJSON = string(outputJSON)
+		duration := time.Since(startTime)
+		if inFlight.input != nil {
+			setInput(exec, cfg.redactor, inFlight.input)
+		}
+
+		exec.DurationMs = duration.Milliseconds()
+		exec.Timeline = inFlight.timeline()
+		exec.Success = err == nil && !canceled
+		exec.Status = models.StatusCompleted
+
+		switch {
+		case canceled:
+			if cause := context.Cause(jobCtx); err == nil {
+				err = cause
+			} else {
+				err = fmt.Errorf("%w: %w", cause, err)
+			}
+			err = withCorrelation(err, correlationID)
+			result = nil
+			exec.ErrorMessage = cfg.redactor.Text(err.Error())
+			exec.Status = models.StatusCanceled
+		case err != nil:
+			err = withCorrelation(err, correlationID)
+			exec.ErrorMessage = cfg.redactor.Text(err.Error())
+			exec.Status = models.StatusFailed
+		case result != nil:
+			if result.Meta == nil {
+				result.Meta = mcp.Meta{}
+			}
+			result.Meta[CorrelationField] = correlationID
+			// Return the findings alongside the raw text, see StructuredFindings. They are
+			// extracted from a copy since the stored ones are redacted in place.
+			if result.StructuredContent == nil && (exec.RawOutput != "" || inFlight.parsed) {
+				parseStart := time.Now()
+				found := slices.Clone(inFlight.findings)
+				if !inFlight.parsed {
+					found = findings.ExtractAll(toolName, exec.RawOutput)
+				}
+				found, _ = inFlight.matcher().Filter(found)
+				cfg.intel.Enrich(found)
+				result.StructuredContent = structuredFindings(found)
+				inFlight.addPhase(models.PhaseParse, time.Since(parseStart))
+			}
+			stored, resultBytes := StoreResult(result)
+			outputJSON, _ := json.Marshal(stored)
+			exec.OutputJSON = cfg.redactor.JSON(string(outputJSON))
+			exec.ResultBytes = resultBytes
+			if inFlight.state != nil {
+				exec.Status = models.StatusPaused
+				exec.ScanState = inFlight.state
+				for i := range exec.ScanState {
+					exec.ScanState[i].Output = cfg.redactor.Text(exec.ScanState[i].Output)
+					exec.ScanState[i].Error = cfg.redactor.Text(exec.ScanState[i].Error)
+				}
+			}
 		}
 
 		// Log execution asynchronously to avoid blocking.
 		// Using background context intentionally - logging should complete even if request is cancelled.
 		go func() { //nolint:contextcheck
-			_ = store.CreateToolExecution(context.Background(), exec)
+			found := inFlight.findings
+			for i := range found {
+				found[i].Title = cfg.redactor.Text(found[i].Title)
+				found[i].URL = cfg.redactor.Text(found[i].URL)
+				for j := range found[i].Evidence {
+					found[i].Evidence[j].Content = cfg.redactor.Text(found[i].Evidence[j].Content)
+				}
+			}
+			parseStart := time.Now()
+			if exec.RawOutput != "" {
+				exec.RawOutput = cfg.redactor.Text(exec.RawOutput)
+				if !inFlight.parsed {
+					found = findings.ExtractAll(toolName, exec.RawOutput)
+				}
+			}
+			found, suppressed := inFlight.matcher().Filter(found)
+			inFlight.mu.Lock()
+			exec.Suppressed = inFlight.suppressed + suppressed
+			inFlight.mu.Unlock()
+			cfg.intel.Enrich(found)
+			if exec.RawOutput != "" || inFlight.parsed {
+				exec.RiskScore = findings.ScoreFindings(found)
+			}
+			inFlight.addPhase(models.PhaseParse, time.Since(parseStart))
+			inFlight.mu.Lock()
+			attachCaptures(exec, found, inFlight.captures)
+			inFlight.mu.Unlock()
+
+			// The final record is stored with the persistence time so far, completed once the
+			// findings are stored.
+			persistStart := time.Now()
+			defer func() {
+				cfg.metrics.RecordPhases(toolName, inFlight.durations())
+			}()
+			if err := artifacts.SpillOutput(cfg.artifacts, exec); err != nil {
+				exec.ErrorMessage = err.Error()
+			}
+			cfg.metrics.RecordOutput(toolName, exec.ResultBytes, exec.OutputFile != "")
+			inFlight.addPhase(models.PhasePersist, time.Since(persistStart))
+			exec.Phases = inFlight.timings()
+			persistStart = time.Now()
+			if err := saveExecution(store, exec); err != nil {
+				return
+			}
+			_ = artifacts.Remove(streamFile)
+			findings.SetFingerprints(exec.Target, found)
+			for i := range found {
+				found[i].ExecutionID = exec.ID
+				found[i].Tenant = exec.Tenant
+			}
+			_ = store.CreateFindings(context.Background(), found)
+			inFlight.mu.Lock()
+			profiles := inFlight.profiles
+			inFlight.mu.Unlock()
+			for _, profile := range profiles {
+				profile.Target = cfg.redactor.Text(profile.Target)
+				profile.ExecutionID = exec.ID
+				profile.Tenant = exec.Tenant
+				_ = store.SaveTargetProfile(context.Background(), &profile)
+			}
+			inFlight.addPhase(models.PhasePersist, time.Since(persistStart))
+			exec.Phases = inFlight.timings()
+			_ = store.UpdateToolExecutionPhases(context.Background(), exec.ID, exec.Phases)
 		}()
 
 		return result, output, err
 	}
+
+	if cfg.server != nil {
+		cfg.server.RegisterRerun(toolName, func(ctx context.Context, inputJSON string, schemaVersion int) error {
+			var input In
+			if schemaVersion != 0 {
+				upgraded, err := UpgradeInput(inputJSON, schemaVersion, input)
+				if err != nil {
+					return err
+				}
+				inputJSON = upgraded
+			}
+			if err := json.Unmarshal([]byte(inputJSON), &input); err != nil {
+				return fmt.Errorf("failed to decode stored input: %w", err)
+			}
+			_, _, err := wrapped(ctx, &mcp.CallToolRequest{}, input)
+			return err
+		})
+	}
+
+	return wrapped
+}
+
+// setInput stores the redacted input of exec with its schema version, along with its scan target
+// when input describes one. Group and multi-target scans store the group or the host expressions
+// as their target.
+func setInput(exec *models.ToolExecution, redactor *redact.Redactor, input any) {
+	inputJSON, _ := json.Marshal(input)
+	exec.InputJSON = redactor.JSON(string(inputJSON))
+	exec.SchemaVersion = InputSchemaVersion(input)
+
+	if provider, ok := input.(TargetProvider); ok {
+		params := provider.ScanTarget()
+		exec.Target = params.Target().URL()
+		exec.Host = params.Host
+		exec.Port = params.Port
+		exec.Scheme = params.Scheme
+	}
+	if grouped, ok := input.(GroupProvider); ok && grouped.TargetGroup() != "" {
+		exec.Target = GroupTarget(grouped.TargetGroup())
+		exec.Host = ""
+		exec.Port = 0
+		exec.Scheme = ""
+	}
+	if listed, ok := input.(TargetListProvider); ok && len(listed.TargetList()) > 0 {
+		exec.Target = ListTarget(listed.TargetList())
+		exec.Host = ""
+		exec.Port = 0
+		exec.Scheme = ""
+	}
+}
+
+// withCorrelation appends the correlation ID to a handler error. Structured JSON-RPC errors are
+// returned unchanged, as the SDK only passes them through as they are.
+func withCorrelation(err error, correlationID string) error {
+	if _, ok := err.(*jsonrpc.Error); ok { //nolint:errorlint
+		return err
+	}
+	return fmt.Errorf("%w [%s=%s]", err, CorrelationField, correlationID)
+}
+
+// saveExecution stores a completed execution, updating the running record when one was created.
+func saveExecution(store storage.Storage, exec *models.ToolExecution) error {
+	if exec.ID != 0 {
+		return store.UpdateToolExecution(context.Background(), exec)
+	}
+	return store.CreateToolExecution(context.Background(), exec)
 }
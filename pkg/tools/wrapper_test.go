@@ -1,14 +1,33 @@
 package tools
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+	"github.com/tb0hdan/wass-mcp/pkg/artifacts"
+	"github.com/tb0hdan/wass-mcp/pkg/findings"
+	"github.com/tb0hdan/wass-mcp/pkg/intel"
+	"github.com/tb0hdan/wass-mcp/pkg/limiter"
+	"github.com/tb0hdan/wass-mcp/pkg/metrics"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/redact"
+	"github.com/tb0hdan/wass-mcp/pkg/running"
+	"github.com/tb0hdan/wass-mcp/pkg/server"
 	"github.com/tb0hdan/wass-mcp/pkg/storage"
+	"github.com/tb0hdan/wass-mcp/pkg/tenant"
 )
 
 type testInput struct {
@@ -115,9 +134,10 @@ func TestWrapToolHandler_Error(t *testing.T) {
 	if err == nil {
 		t.Fatal("expected error")
 	}
-	if err.Error() != "test error" {
-		t.Errorf("expected 'test error', got '%s'", err.Error())
+	if !errors.Is(err, expectedErr) {
+		t.Errorf("expected wrapped 'test error', got '%s'", err.Error())
 	}
+	wrappedErr := err.Error()
 
 	// Wait for async logging
 	time.Sleep(100 * time.Millisecond)
@@ -131,8 +151,12 @@ func TestWrapToolHandler_Error(t *testing.T) {
 		if executions[0].Success {
 			t.Error("expected Success to be false for failed execution")
 		}
-		if executions[0].ErrorMessage != "test error" {
-			t.Errorf("expected error message 'test error', got '%s'", executions[0].ErrorMessage)
+		expected := "test error [correlation_id=" + executions[0].CorrelationID + "]"
+		if executions[0].CorrelationID == "" || wrappedErr != expected {
+			t.Errorf("expected error '%s', got '%s'", expected, wrappedErr)
+		}
+		if executions[0].ErrorMessage != expected {
+			t.Errorf("expected error message '%s', got '%s'", expected, executions[0].ErrorMessage)
 		}
 	}
 }
@@ -252,3 +276,1137 @@ func containsString(s, substr string) bool {
 	}
 	return false
 }
+
+func TestWrapToolHandler_RecordsRawOutput(t *testing.T) {
+	store, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	handler := func(ctx context.Context, req *mcp.CallToolRequest, input testInput) (*mcp.CallToolResult, any, error) {
+		RecordRawOutput(ctx, "full unpaginated output")
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: "paginated"},
+			},
+		}, nil, nil
+	}
+
+	wrapped := WrapToolHandler(store, "test-tool", handler)
+
+	ctx := context.Background()
+	if _, _, err := wrapped(ctx, &mcp.CallToolRequest{}, testInput{Host: "localhost", Port: 80}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	// Wait for async logging
+	time.Sleep(100 * time.Millisecond)
+
+	executions, _, err := store.GetToolExecutions(ctx, 10, 0)
+	if err != nil {
+		t.Fatalf("failed to get executions: %v", err)
+	}
+	if len(executions) != 1 {
+		t.Fatalf("expected 1 execution logged, got %d", len(executions))
+	}
+	if executions[0].RawOutput != "full unpaginated output" {
+		t.Errorf("expected raw output to be recorded, got '%s'", executions[0].RawOutput)
+	}
+}
+
+func TestWrapToolHandler_PersistsFindings(t *testing.T) {
+	store, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	handler := func(ctx context.Context, req *mcp.CallToolRequest, input testInput) (*mcp.CallToolResult, any, error) {
+		RecordRawOutput(ctx, "[exposed-git] [http] [high] http://localhost/.git/config")
+		return &mcp.CallToolResult{}, nil, nil
+	}
+
+	wrapped := WrapToolHandler(store, "test-tool", handler)
+
+	ctx := context.Background()
+	if _, _, err := wrapped(ctx, &mcp.CallToolRequest{}, testInput{Host: "localhost", Port: 80}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	// Wait for async logging
+	time.Sleep(100 * time.Millisecond)
+
+	executions, _, err := store.GetToolExecutions(ctx, 10, 0)
+	if err != nil || len(executions) != 1 {
+		t.Fatalf("expected 1 execution logged, got %d (err: %v)", len(executions), err)
+	}
+	if executions[0].RiskScore != 5 {
+		t.Errorf("expected risk score 5, got %v", executions[0].RiskScore)
+	}
+
+	found, err := store.GetFindingsByExecutions(ctx, []uint{executions[0].ID})
+	if err != nil {
+		t.Fatalf("failed to get findings: %v", err)
+	}
+	if len(found) != 1 {
+		t.Fatalf("expected 1 finding persisted, got %d", len(found))
+	}
+	if found[0].Severity != "high" || found[0].URL != "http://localhost/.git/config" {
+		t.Errorf("unexpected finding: %+v", found[0])
+	}
+	if want := findings.Fingerprint(executions[0].Target, found[0]); found[0].Fingerprint != want {
+		t.Errorf("expected fingerprint %s of the execution target, got %q", want, found[0].Fingerprint)
+	}
+}
+
+func TestWrapToolHandler_PersistsTargetProfiles(t *testing.T) {
+	store, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	handler := func(ctx context.Context, req *mcp.CallToolRequest, input testInput) (*mcp.CallToolResult, any, error) {
+		RecordTargetProfile(ctx, models.TargetProfile{
+			Target:       "http://localhost",
+			Host:         "localhost",
+			Port:         80,
+			Scanner:      "whatweb",
+			Technologies: []models.Technology{{Category: models.TechnologyServer, Name: "nginx"}},
+		})
+		return &mcp.CallToolResult{}, nil, nil
+	}
+
+	wrapped := WrapToolHandler(store, "test-tool", handler)
+
+	ctx := tenant.WithTenant(context.Background(), "alpha")
+	if _, _, err := wrapped(ctx, &mcp.CallToolRequest{}, testInput{Host: "localhost", Port: 80}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	// Wait for async logging
+	time.Sleep(100 * time.Millisecond)
+
+	executions, _, err := store.GetToolExecutions(ctx, 10, 0)
+	if err != nil || len(executions) != 1 {
+		t.Fatalf("expected 1 execution logged, got %d (err: %v)", len(executions), err)
+	}
+	profiles, err := store.GetTargetProfiles(ctx, "localhost")
+	if err != nil || len(profiles) != 1 {
+		t.Fatalf("expected 1 target profile stored, got %+v (err: %v)", profiles, err)
+	}
+	if profiles[0].ExecutionID != executions[0].ID || profiles[0].Tenant != "alpha" || profiles[0].Technologies[0].Name != "nginx" {
+		t.Errorf("unexpected target profile: %+v", profiles[0])
+	}
+
+	// Outside the wrapper profiles are not recorded.
+	RecordTargetProfile(context.Background(), models.TargetProfile{Target: "http://localhost"})
+}
+
+func TestWrapToolHandler_PersistsRecordedFindings(t *testing.T) {
+	store, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	handler := func(ctx context.Context, req *mcp.CallToolRequest, input testInput) (*mcp.CallToolResult, any, error) {
+		// The raw output carries a tag that must not be re-extracted once findings are recorded.
+		RecordRawOutput(ctx, "[x] [http] [critical] http://localhost/ignored")
+		RecordFindings(ctx, []models.Finding{
+			{
+				Scanner:  "parser",
+				Severity: "medium",
+				Title:    "Leaked Authorization: Bearer abc",
+				URL:      "http://localhost/",
+				Evidence: []models.Evidence{{Content: "GET / HTTP/1.1\nCookie: session=abc", Kind: models.EvidenceRequest, Source: "parser"}},
+			},
+		})
+		return &mcp.CallToolResult{}, nil, nil
+	}
+
+	wrapped := WrapToolHandler(store, "test-tool", handler)
+
+	ctx := context.Background()
+	if _, _, err := wrapped(ctx, &mcp.CallToolRequest{}, testInput{Host: "localhost", Port: 80}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	// Wait for async logging
+	time.Sleep(100 * time.Millisecond)
+
+	executions, _, err := store.GetToolExecutions(ctx, 10, 0)
+	if err != nil || len(executions) != 1 {
+		t.Fatalf("expected 1 execution logged, got %d (err: %v)", len(executions), err)
+	}
+	if executions[0].RiskScore != 2 {
+		t.Errorf("expected risk score 2, got %v", executions[0].RiskScore)
+	}
+
+	found, err := store.GetFindingsByExecutions(ctx, []uint{executions[0].ID})
+	if err != nil {
+		t.Fatalf("failed to get findings: %v", err)
+	}
+	if len(found) != 1 || found[0].URL != "http://localhost/" {
+		t.Fatalf("expected the recorded finding only, got %+v", found)
+	}
+	if containsString(found[0].Title, "abc") {
+		t.Errorf("expected finding title to be redacted, got %q", found[0].Title)
+	}
+	if len(found[0].Evidence) != 1 || containsString(found[0].Evidence[0].Content, "abc") {
+		t.Errorf("expected finding evidence to be redacted, got %+v", found[0].Evidence)
+	}
+}
+
+func TestWrapToolHandler_ReturnsStructuredFindings(t *testing.T) {
+	store, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	handler := func(ctx context.Context, req *mcp.CallToolRequest, input testInput) (*mcp.CallToolResult, any, error) {
+		RecordRawOutput(ctx, "[xss] [http] [high] http://localhost/?q=1 CWE-79\n[info] [http] [info] http://localhost/")
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "raw"}}}, nil, nil
+	}
+
+	wrapped := WrapToolHandler(store, "test-tool", handler)
+
+	result, _, err := wrapped(context.Background(), &mcp.CallToolRequest{}, testInput{Host: "localhost", Port: 80})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	raw, ok := result.StructuredContent.(json.RawMessage)
+	if !ok {
+		t.Fatalf("expected structured findings, got %T", result.StructuredContent)
+	}
+	var content StructuredFindings
+	if err := json.Unmarshal(raw, &content); err != nil {
+		t.Fatalf("failed to decode structured findings: %v", err)
+	}
+	if content.Total != 2 || len(content.Findings) != 2 || content.Truncated {
+		t.Fatalf("expected 2 findings, got %+v", content)
+	}
+	if first := content.Findings[0]; first.Severity != "high" || len(first.CWEs) != 1 || first.CWEs[0] != "CWE-79" {
+		t.Errorf("expected the high XSS finding with its CWE first, got %+v", first)
+	}
+	if content.Severities["high"] != 1 || content.Severities["info"] != 1 {
+		t.Errorf("unexpected severity counts: %v", content.Severities)
+	}
+	if !strings.Contains(result.Content[0].(*mcp.TextContent).Text, "raw") {
+		t.Error("expected the raw text to be kept alongside the findings")
+	}
+
+	// Wait for async logging
+	time.Sleep(100 * time.Millisecond)
+
+	executions, _, err := store.GetToolExecutions(context.Background(), 10, 0)
+	if err != nil || len(executions) != 1 {
+		t.Fatalf("expected 1 execution logged, got %d (err: %v)", len(executions), err)
+	}
+	if !strings.Contains(executions[0].OutputJSON, `"structuredContent"`) {
+		t.Errorf("expected the structured findings to be stored with the output, got %s", executions[0].OutputJSON)
+	}
+}
+
+func TestWrapToolHandler_StructuredFindingsOmitEvidence(t *testing.T) {
+	store, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	handler := func(ctx context.Context, req *mcp.CallToolRequest, input testInput) (*mcp.CallToolResult, any, error) {
+		RecordFindings(ctx, []models.Finding{
+			{
+				Scanner:  "parser",
+				Severity: "medium",
+				Title:    "Reflected input",
+				Evidence: []models.Evidence{{Content: "GET / HTTP/1.1", Kind: models.EvidenceRequest, Source: "parser"}},
+			},
+		})
+		return &mcp.CallToolResult{}, nil, nil
+	}
+
+	wrapped := WrapToolHandler(store, "test-tool", handler)
+
+	result, _, err := wrapped(context.Background(), &mcp.CallToolRequest{}, testInput{Host: "localhost", Port: 80})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	var content StructuredFindings
+	if err := json.Unmarshal(result.StructuredContent.(json.RawMessage), &content); err != nil {
+		t.Fatalf("failed to decode structured findings: %v", err)
+	}
+	if len(content.Findings) != 1 || content.Findings[0].Evidence != nil {
+		t.Errorf("expected the finding without evidence, got %+v", content.Findings)
+	}
+
+	// Wait for async logging
+	time.Sleep(100 * time.Millisecond)
+
+	executions, _, err := store.GetToolExecutions(context.Background(), 10, 0)
+	if err != nil || len(executions) != 1 {
+		t.Fatalf("expected 1 execution logged, got %d (err: %v)", len(executions), err)
+	}
+	found, err := store.GetFindingsByExecutions(context.Background(), []uint{executions[0].ID})
+	if err != nil || len(found) != 1 || len(found[0].Evidence) != 1 {
+		t.Errorf("expected the stored finding to keep its evidence, got %+v (err: %v)", found, err)
+	}
+}
+
+func TestWrapToolHandler_EnrichesFindings(t *testing.T) {
+	store, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	kevFile := filepath.Join(t.TempDir(), "kev.json")
+	if err := os.WriteFile(kevFile, []byte(`{"vulnerabilities":[{"cveID":"CVE-2021-44228"}]}`), 0o600); err != nil {
+		t.Fatalf("failed to write KEV catalog: %v", err)
+	}
+	threatIntel := intel.New(intel.Config{KEVFile: kevFile}, zerolog.Nop())
+	if err := threatIntel.Load(); err != nil {
+		t.Fatalf("failed to load KEV catalog: %v", err)
+	}
+
+	handler := func(ctx context.Context, req *mcp.CallToolRequest, input testInput) (*mcp.CallToolResult, any, error) {
+		RecordFindings(ctx, []models.Finding{
+			{Scanner: "parser", Severity: "high", Title: "Log4Shell (CVE-2021-44228)", URL: "http://localhost/"},
+			{Scanner: "parser", Severity: "high", Title: "Exposed Git", URL: "http://localhost/.git/config"},
+		})
+		return &mcp.CallToolResult{}, nil, nil
+	}
+
+	wrapped := WrapToolHandler(store, "test-tool", handler, WithIntel(threatIntel))
+
+	ctx := context.Background()
+	if _, _, err := wrapped(ctx, &mcp.CallToolRequest{}, testInput{Host: "localhost", Port: 80}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	// Wait for async logging
+	time.Sleep(100 * time.Millisecond)
+
+	executions, _, err := store.GetToolExecutions(ctx, 10, 0)
+	if err != nil || len(executions) != 1 {
+		t.Fatalf("expected 1 execution logged, got %d (err: %v)", len(executions), err)
+	}
+	// A known exploited high finding (5 * 2) and a high finding (5).
+	if executions[0].RiskScore != 15 {
+		t.Errorf("expected risk score 15, got %v", executions[0].RiskScore)
+	}
+
+	found, err := store.GetFindingsByExecutions(ctx, []uint{executions[0].ID})
+	if err != nil || len(found) != 2 {
+		t.Fatalf("expected 2 findings, got %d (err: %v)", len(found), err)
+	}
+	if !found[0].KEV || len(found[0].CVEs) != 1 || found[0].CVEs[0] != "CVE-2021-44228" {
+		t.Errorf("expected the CVE finding to be enriched, got %+v", found[0])
+	}
+	if found[1].KEV || len(found[1].CVEs) != 0 {
+		t.Errorf("expected the other finding not to be enriched, got %+v", found[1])
+	}
+}
+
+func TestWrapToolHandler_SuppressesFindings(t *testing.T) {
+	store, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	if err := store.SaveSuppressionRule(ctx, &models.SuppressionRule{Name: "tech", TemplateID: "tech-detect"}); err != nil {
+		t.Fatalf("failed to save suppression rule: %v", err)
+	}
+
+	var reported []models.Finding
+	handler := func(ctx context.Context, req *mcp.CallToolRequest, input testInput) (*mcp.CallToolResult, any, error) {
+		reported = SuppressFindings(ctx, []models.Finding{
+			{Scanner: "nuclei", Severity: "info", TemplateID: "tech-detect", Title: "nginx", URL: "http://localhost/"},
+			{Scanner: "nuclei", Severity: "high", TemplateID: "exposed-git", Title: "Exposed Git", URL: "http://localhost/.git/config"},
+		})
+		RecordFindings(ctx, append(reported,
+			models.Finding{Scanner: "shcheck", Severity: "low", Title: "Missing security header: X-Frame-Options", URL: "http://localhost/health"}))
+		return &mcp.CallToolResult{}, nil, nil
+	}
+
+	wrapped := WrapToolHandler(store, "test-tool", handler,
+		WithSuppressionRules([]models.SuppressionRule{{Name: "health", URLPattern: `/health$`}}))
+
+	if _, _, err := wrapped(ctx, &mcp.CallToolRequest{}, testInput{Host: "localhost", Port: 80}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(reported) != 1 || reported[0].TemplateID != "exposed-git" {
+		t.Errorf("expected the handler to keep only the exposed-git finding, got %+v", reported)
+	}
+
+	// Wait for async logging
+	time.Sleep(100 * time.Millisecond)
+
+	executions, _, err := store.GetToolExecutions(ctx, 10, 0)
+	if err != nil || len(executions) != 1 {
+		t.Fatalf("expected 1 execution logged, got %d (err: %v)", len(executions), err)
+	}
+	if executions[0].Suppressed != 2 {
+		t.Errorf("expected 2 suppressed findings, got %d", executions[0].Suppressed)
+	}
+	if executions[0].RiskScore != 5 {
+		t.Errorf("expected risk score 5, got %v", executions[0].RiskScore)
+	}
+
+	found, err := store.GetFindingsByExecutions(ctx, []uint{executions[0].ID})
+	if err != nil || len(found) != 1 {
+		t.Fatalf("expected 1 finding, got %d (err: %v)", len(found), err)
+	}
+	if found[0].Title != "Exposed Git" {
+		t.Errorf("expected the exposed-git finding to be stored, got %+v", found[0])
+	}
+}
+
+func TestWrapToolHandler_StoresTarget(t *testing.T) {
+	store, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	handler := func(ctx context.Context, req *mcp.CallToolRequest, input ScannerInput) (*mcp.CallToolResult, any, error) {
+		return &mcp.CallToolResult{}, nil, nil
+	}
+
+	wrapped := WrapToolHandler(store, "test-tool", handler)
+
+	ctx := context.Background()
+	if _, _, err := wrapped(ctx, &mcp.CallToolRequest{}, ScannerInput{Host: "https://example.com:8443"}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, _, err := WrapToolHandler(store, "plain-tool", func(ctx context.Context, req *mcp.CallToolRequest, input testInput) (*mcp.CallToolResult, any, error) {
+		return &mcp.CallToolResult{}, nil, nil
+	})(ctx, &mcp.CallToolRequest{}, testInput{Host: "localhost"}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	// Wait for async logging
+	time.Sleep(100 * time.Millisecond)
+
+	executions, err := store.GetToolExecutionsByTool(ctx, "test-tool", 0)
+	if err != nil || len(executions) != 1 {
+		t.Fatalf("expected 1 execution logged, got %d (err: %v)", len(executions), err)
+	}
+	exec := executions[0]
+	if exec.Target != "https://example.com:8443" || exec.Host != "example.com" || exec.Port != 8443 || exec.Scheme != "https" {
+		t.Errorf("unexpected target fields: target=%q host=%q port=%d scheme=%q", exec.Target, exec.Host, exec.Port, exec.Scheme)
+	}
+
+	// Inputs without a TargetProvider leave the target fields empty
+	executions, err = store.GetToolExecutionsByTool(ctx, "plain-tool", 0)
+	if err != nil || len(executions) != 1 {
+		t.Fatalf("expected 1 execution logged, got %d (err: %v)", len(executions), err)
+	}
+	if executions[0].Target != "" || executions[0].Host != "" {
+		t.Errorf("expected empty target fields, got target=%q host=%q", executions[0].Target, executions[0].Host)
+	}
+}
+
+// groupInput is a scanner input that may name a target group.
+type groupInput struct {
+	ScannerInput
+	Group string `json:"group,omitempty"`
+}
+
+func (i groupInput) TargetGroup() string {
+	return i.Group
+}
+
+func TestWrapToolHandler_StoresGroupTarget(t *testing.T) {
+	store, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	wrapped := WrapToolHandler(store, "group-tool", func(ctx context.Context, req *mcp.CallToolRequest, input groupInput) (*mcp.CallToolResult, any, error) {
+		return &mcp.CallToolResult{}, nil, nil
+	})
+
+	ctx := context.Background()
+	if _, _, err := wrapped(ctx, &mcp.CallToolRequest{}, groupInput{Group: "staging"}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, _, err := wrapped(ctx, &mcp.CallToolRequest{}, groupInput{ScannerInput: ScannerInput{Host: "example.com"}}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	// Wait for async logging
+	time.Sleep(100 * time.Millisecond)
+
+	executions, err := store.GetToolExecutionsByTool(ctx, "group-tool", 0)
+	if err != nil || len(executions) != 2 {
+		t.Fatalf("expected 2 executions logged, got %d (err: %v)", len(executions), err)
+	}
+	targets := map[string]string{}
+	for _, exec := range executions {
+		targets[exec.Target] = exec.Host
+	}
+	if host, ok := targets["group:staging"]; !ok || host != "" {
+		t.Errorf("expected the group scan stored as group:staging without host, got %v", targets)
+	}
+	if host, ok := targets["http://example.com"]; !ok || host != "example.com" {
+		t.Errorf("expected the host scan stored with its target, got %v", targets)
+	}
+}
+
+func TestWrapToolHandler_RedactsSecrets(t *testing.T) {
+	store, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	type secretInput struct {
+		Host  string `json:"host"`
+		Token string `json:"token"`
+	}
+
+	handler := func(ctx context.Context, req *mcp.CallToolRequest, input secretInput) (*mcp.CallToolResult, any, error) {
+		RecordRawOutput(ctx, "Set-Cookie: session=abc")
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: "sent Authorization: Bearer s3cr3t"},
+			},
+		}, nil, nil
+	}
+
+	redactor, err := redact.New(redact.Config{Patterns: []string{`internal-\d+`}})
+	if err != nil {
+		t.Fatalf("failed to create redactor: %v", err)
+	}
+	wrapped := WrapToolHandler(store, "test-tool", handler, WithRedactor(redactor))
+
+	ctx := context.Background()
+	if _, _, err := wrapped(ctx, &mcp.CallToolRequest{}, secretInput{Host: "internal-42", Token: "tkn"}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	// Wait for async logging
+	time.Sleep(100 * time.Millisecond)
+
+	executions, _, err := store.GetToolExecutions(ctx, 10, 0)
+	if err != nil || len(executions) != 1 {
+		t.Fatalf("expected 1 execution logged, got %d (err: %v)", len(executions), err)
+	}
+	exec := executions[0]
+	for name, stored := range map[string]string{"input": exec.InputJSON, "output": exec.OutputJSON, "raw output": exec.RawOutput} {
+		for _, secret := range []string{"tkn", "s3cr3t", "session=abc", "internal-42"} {
+			if strings.Contains(stored, secret) {
+				t.Errorf("expected %s to be redacted from %s: %s", secret, name, stored)
+			}
+		}
+	}
+	if !strings.Contains(exec.InputJSON, redact.Placeholder) {
+		t.Errorf("expected placeholder in input, got %s", exec.InputJSON)
+	}
+}
+
+func TestWrapToolHandler_SpillsLargeOutput(t *testing.T) {
+	store, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	large := strings.Repeat("x", 10000)
+	handler := func(ctx context.Context, req *mcp.CallToolRequest, input testInput) (*mcp.CallToolResult, any, error) {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: large},
+			},
+		}, nil, nil
+	}
+
+	dir := t.TempDir()
+	wrapped := WrapToolHandler(store, "test-tool", handler, WithArtifacts(artifacts.Config{Dir: dir, MaxOutputBytes: 1000}))
+
+	ctx := context.Background()
+	if _, _, err := wrapped(ctx, &mcp.CallToolRequest{}, testInput{Host: "localhost", Port: 80}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	// Wait for async logging
+	time.Sleep(100 * time.Millisecond)
+
+	executions, _, err := store.GetToolExecutions(ctx, 10, 0)
+	if err != nil || len(executions) != 1 {
+		t.Fatalf("expected 1 execution logged, got %d (err: %v)", len(executions), err)
+	}
+	exec := executions[0]
+	if exec.OutputFile == "" {
+		t.Fatal("expected output to be spilled to an artifact file")
+	}
+	if len(exec.OutputJSON) >= exec.OutputSize {
+		t.Errorf("expected truncated preview, got %d of %d bytes", len(exec.OutputJSON), exec.OutputSize)
+	}
+
+	output, err := artifacts.LoadOutput(&exec)
+	if err != nil {
+		t.Fatalf("failed to load output: %v", err)
+	}
+	if len(output) != exec.OutputSize || !strings.Contains(output, large) {
+		t.Errorf("expected full output in artifact, got %d bytes", len(output))
+	}
+}
+
+func TestWrapToolHandler_StoresNormalizedOutput(t *testing.T) {
+	store, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	blob := bytes.Repeat([]byte("b"), 5000)
+	handler := func(ctx context.Context, req *mcp.CallToolRequest, input testInput) (*mcp.CallToolResult, any, error) {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: "report attached"},
+				&mcp.EmbeddedResource{Resource: &mcp.ResourceContents{Blob: blob, MIMEType: "application/gzip", URI: "wass://reports/test.gz"}},
+			},
+		}, nil, nil
+	}
+
+	scanMetrics := metrics.New()
+	wrapped := WrapToolHandler(store, "test-tool", handler, WithMetrics(scanMetrics))
+
+	ctx := context.Background()
+	if _, _, err := wrapped(ctx, &mcp.CallToolRequest{}, testInput{Host: "localhost", Port: 80}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	// Wait for async logging
+	time.Sleep(100 * time.Millisecond)
+
+	executions, _, err := store.GetToolExecutions(ctx, 10, 0)
+	if err != nil || len(executions) != 1 {
+		t.Fatalf("expected 1 execution logged, got %d (err: %v)", len(executions), err)
+	}
+	exec := executions[0]
+	if !strings.Contains(exec.OutputJSON, "report attached") || !strings.Contains(exec.OutputJSON, "wass://reports/test.gz") {
+		t.Errorf("expected text and resource URI in output, got %s", exec.OutputJSON)
+	}
+	if len(exec.OutputJSON) > 1000 {
+		t.Errorf("expected resource data left out of output, got %d bytes", len(exec.OutputJSON))
+	}
+	if exec.ResultBytes != len("report attached")+len(blob) {
+		t.Errorf("expected result bytes %d, got %d", len("report attached")+len(blob), exec.ResultBytes)
+	}
+
+	var builder strings.Builder
+	if err := scanMetrics.Write(&builder); err != nil {
+		t.Fatalf("failed to write metrics: %v", err)
+	}
+	if !strings.Contains(builder.String(), fmt.Sprintf(`wass_tool_output_bytes_total{tool="test-tool"} %d`, exec.ResultBytes)) {
+		t.Errorf("expected output bytes metric, got %s", builder.String())
+	}
+}
+
+func TestWrapToolHandler_RecordsRunningExecution(t *testing.T) {
+	store, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	var running []models.ToolExecution
+	handler := func(ctx context.Context, req *mcp.CallToolRequest, input ScannerInput) (*mcp.CallToolResult, any, error) {
+		running, _ = store.GetToolExecutionsByTool(ctx, "test-tool", 0)
+		return &mcp.CallToolResult{}, nil, nil
+	}
+
+	wrapped := WrapToolHandler(store, "test-tool", handler)
+	if _, _, err := wrapped(ctx, &mcp.CallToolRequest{}, ScannerInput{Host: "localhost", Retryable: true}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	if len(running) != 1 || running[0].Status != models.StatusRunning || !running[0].Retryable {
+		t.Fatalf("expected a running retryable record during execution, got %+v", running)
+	}
+
+	// Wait for async logging
+	time.Sleep(100 * time.Millisecond)
+
+	executions, err := store.GetToolExecutionsByTool(ctx, "test-tool", 0)
+	if err != nil || len(executions) != 1 {
+		t.Fatalf("expected the running record to be updated, got %d (err: %v)", len(executions), err)
+	}
+	if executions[0].ID != running[0].ID || executions[0].Status != models.StatusCompleted {
+		t.Errorf("expected record %d to be completed, got %+v", running[0].ID, executions[0])
+	}
+}
+
+func TestWrapToolHandler_RegistersRerun(t *testing.T) {
+	store, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	srv := server.NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}, store)
+
+	calls := make(chan ScannerInput, 1)
+	tenants := make(chan string, 1)
+	handler := func(ctx context.Context, req *mcp.CallToolRequest, input ScannerInput) (*mcp.CallToolResult, any, error) {
+		name, _ := tenant.FromContext(ctx)
+		calls <- input
+		tenants <- name
+		return &mcp.CallToolResult{}, nil, nil
+	}
+	WrapToolHandler(store, "test-tool", handler, ServerWrapOptions(srv)...)
+
+	exec := &models.ToolExecution{
+		Tenant:    "alpha",
+		ToolName:  "test-tool",
+		Status:    models.StatusRunning,
+		InputJSON: `{"host":"example.com","port":8080,"retry_on_restart":true}`,
+		Retryable: true,
+	}
+	if err := store.CreateToolExecution(ctx, exec); err != nil {
+		t.Fatalf("failed to create execution: %v", err)
+	}
+
+	done := make(chan error, 1)
+	if _, err := srv.RecoverInterrupted(ctx, true, func(_ models.ToolExecution, err error) { done <- err }); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("expected re-run to succeed, got: %v", err)
+	}
+
+	input := <-calls
+	if input.Host != "example.com" || input.Port != 8080 {
+		t.Errorf("expected stored input to be replayed, got %+v", input)
+	}
+	if name := <-tenants; name != "alpha" {
+		t.Errorf("expected re-run on behalf of tenant alpha, got %q", name)
+	}
+}
+
+func TestWrapToolHandler_RecordsSchemaVersion(t *testing.T) {
+	store, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	handler := func(ctx context.Context, req *mcp.CallToolRequest, input versionedInput) (*mcp.CallToolResult, any, error) {
+		return &mcp.CallToolResult{}, nil, nil
+	}
+	wrapped := WrapToolHandler(store, "test-tool", handler)
+	if _, _, err := wrapped(ctx, &mcp.CallToolRequest{}, versionedInput{Host: "example.com"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	executions, err := store.GetToolExecutionsByTool(ctx, "test-tool", 10)
+	if err != nil {
+		t.Fatalf("failed to list executions: %v", err)
+	}
+	if len(executions) != 1 || executions[0].SchemaVersion != 3 {
+		t.Fatalf("expected an execution with schema version 3, got %+v", executions)
+	}
+}
+
+func TestWrapToolHandler_RerunUpgradesInput(t *testing.T) {
+	store, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	srv := server.NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}, store)
+
+	calls := make(chan versionedInput, 1)
+	handler := func(ctx context.Context, req *mcp.CallToolRequest, input versionedInput) (*mcp.CallToolResult, any, error) {
+		calls <- input
+		return &mcp.CallToolResult{}, nil, nil
+	}
+	WrapToolHandler(store, "test-tool", handler, ServerWrapOptions(srv)...)
+
+	// Executions stored before versioning have schema version 0, the base version.
+	exec := models.ToolExecution{ToolName: "test-tool", InputJSON: `{"hostname":"example.com","threads":4}`}
+	if err := srv.Rerun(ctx, exec); err != nil {
+		t.Fatalf("expected re-run to succeed, got: %v", err)
+	}
+	input := <-calls
+	if input.Host != "example.com" || input.Options["threads"] != "4" {
+		t.Errorf("expected upgraded input to be replayed, got %+v", input)
+	}
+
+	exec = models.ToolExecution{ToolName: "test-tool", InputJSON: `{"host":"example.com"}`, SchemaVersion: 4}
+	if err := srv.Rerun(ctx, exec); !errors.Is(err, ErrInputSchema) {
+		t.Errorf("expected an input schema error for a newer version, got %v", err)
+	}
+}
+
+func TestWrapToolHandler_RecordsTenant(t *testing.T) {
+	store, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	handler := func(ctx context.Context, req *mcp.CallToolRequest, input testInput) (*mcp.CallToolResult, any, error) {
+		RecordRawOutput(ctx, "[exposed-git] [http] [high] http://localhost/.git/config")
+		return &mcp.CallToolResult{}, nil, nil
+	}
+
+	wrapped := WrapToolHandler(store, "test-tool", handler)
+
+	alpha := tenant.WithTenant(context.Background(), "alpha")
+	if _, _, err := wrapped(alpha, &mcp.CallToolRequest{}, testInput{Host: "localhost", Port: 80}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	// Wait for async logging
+	time.Sleep(100 * time.Millisecond)
+
+	executions, _, err := store.GetToolExecutions(alpha, 10, 0)
+	if err != nil || len(executions) != 1 {
+		t.Fatalf("expected 1 alpha execution logged, got %d (err: %v)", len(executions), err)
+	}
+	if executions[0].Tenant != "alpha" {
+		t.Errorf("expected execution of tenant alpha, got %q", executions[0].Tenant)
+	}
+	found, err := store.GetFindingsByExecutions(alpha, []uint{executions[0].ID})
+	if err != nil || len(found) != 1 {
+		t.Fatalf("expected 1 alpha finding, got %d (err: %v)", len(found), err)
+	}
+
+	beta := tenant.WithTenant(context.Background(), "beta")
+	if executions, _, _ := store.GetToolExecutions(beta, 10, 0); len(executions) != 0 {
+		t.Errorf("expected no beta executions, got %d", len(executions))
+	}
+}
+
+func TestWrapToolHandler_CancelJob(t *testing.T) {
+	store, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	jobs := running.New()
+
+	started := make(chan struct{})
+	handler := func(ctx context.Context, req *mcp.CallToolRequest, input ScannerInput) (*mcp.CallToolResult, any, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, nil, ctx.Err()
+	}
+	wrapped := WrapToolHandler(store, "test-tool", handler, WithJobs(jobs))
+
+	errs := make(chan error, 1)
+	go func() {
+		_, _, err := wrapped(ctx, &mcp.CallToolRequest{}, ScannerInput{Host: "example.com"})
+		errs <- err
+	}()
+	<-started
+
+	listed := jobs.List()
+	if len(listed) != 1 || listed[0].ToolName != "test-tool" || listed[0].Target != "http://example.com" || listed[0].ExecutionID == 0 {
+		t.Fatalf("expected the running execution to be listed, got %+v", listed)
+	}
+	if err := jobs.Cancel(listed[0].ID); err != nil {
+		t.Fatalf("failed to cancel job: %v", err)
+	}
+
+	if err := <-errs; !errors.Is(err, running.ErrCanceled) {
+		t.Fatalf("expected cancellation error, got: %v", err)
+	}
+	if len(jobs.List()) != 0 {
+		t.Error("expected the job to be removed once the handler returned")
+	}
+
+	// Wait for async logging
+	time.Sleep(100 * time.Millisecond)
+
+	exec, err := store.GetToolExecution(ctx, listed[0].ExecutionID)
+	if err != nil {
+		t.Fatalf("failed to get execution: %v", err)
+	}
+	if exec.Status != models.StatusCanceled || exec.Success || !strings.Contains(exec.ErrorMessage, "canceled by an administrator") {
+		t.Errorf("expected a canceled execution, got %+v", exec)
+	}
+}
+
+func TestWrapToolHandler_RawOutputWriter(t *testing.T) {
+	store, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	handler := func(ctx context.Context, req *mcp.CallToolRequest, input testInput) (*mcp.CallToolResult, any, error) {
+		writer := RawOutputWriter(ctx, 64)
+		fmt.Fprintf(writer, "section %d\n", 1)
+		fmt.Fprintf(writer, "section %d\n", 2)
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: "paginated"},
+			},
+		}, nil, nil
+	}
+
+	wrapped := WrapToolHandler(store, "test-tool", handler)
+
+	ctx := context.Background()
+	if _, _, err := wrapped(ctx, &mcp.CallToolRequest{}, testInput{Host: "localhost", Port: 80}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	// Wait for async logging
+	time.Sleep(100 * time.Millisecond)
+
+	executions, _, err := store.GetToolExecutions(ctx, 10, 0)
+	if err != nil {
+		t.Fatalf("failed to get executions: %v", err)
+	}
+	if len(executions) != 1 {
+		t.Fatalf("expected 1 execution logged, got %d", len(executions))
+	}
+	if executions[0].RawOutput != "section 1\nsection 2\n" {
+		t.Errorf("expected the written raw output to be recorded, got '%s'", executions[0].RawOutput)
+	}
+	if RawOutputWriter(context.Background(), 64) != io.Discard {
+		t.Errorf("expected output written outside the wrapper to be discarded")
+	}
+}
+
+func TestRecordRawOutput_OutsideWrapper(t *testing.T) {
+	// Must not panic without an in-flight execution.
+	RecordRawOutput(context.Background(), "output")
+	RecordFindings(context.Background(), nil)
+}
+
+func TestWrapToolHandler_RecordsClient(t *testing.T) {
+	store, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	handler := func(ctx context.Context, req *mcp.CallToolRequest, input testInput) (*mcp.CallToolResult, any, error) {
+		return &mcp.CallToolResult{}, nil, nil
+	}
+	wrapped := WrapToolHandler(store, "test-tool", handler)
+
+	// Take the request context the MCP endpoint would see
+	var ctx context.Context
+	httpReq := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+	httpReq.RemoteAddr = "198.51.100.7:40000"
+	server.WithRemoteAddr(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		ctx = r.Context()
+	})).ServeHTTP(httptest.NewRecorder(), httpReq)
+
+	req := &mcp.CallToolRequest{Extra: &mcp.RequestExtra{Header: http.Header{"User-Agent": []string{"scan-agent/1.4"}}}}
+	if _, _, err := wrapped(ctx, req, testInput{Host: "localhost", Port: 80}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	// Wait for async logging
+	time.Sleep(100 * time.Millisecond)
+
+	executions, _, err := store.GetToolExecutions(context.Background(), 10, 0)
+	if err != nil || len(executions) != 1 {
+		t.Fatalf("expected 1 execution logged, got %d (err: %v)", len(executions), err)
+	}
+	exec := executions[0]
+	if exec.ClientName != "scan-agent" || exec.ClientVersion != "1.4" {
+		t.Errorf("expected client scan-agent 1.4, got %q %q", exec.ClientName, exec.ClientVersion)
+	}
+	if exec.RemoteAddr != "198.51.100.7:40000" {
+		t.Errorf("expected remote address to be recorded, got %q", exec.RemoteAddr)
+	}
+}
+
+func TestWrapToolHandler_CorrelationID(t *testing.T) {
+	store, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	var handlerID string
+	handler := func(ctx context.Context, req *mcp.CallToolRequest, input testInput) (*mcp.CallToolResult, any, error) {
+		handlerID = CorrelationID(ctx)
+		return &mcp.CallToolResult{}, nil, nil
+	}
+	wrapped := WrapToolHandler(store, "test-tool", handler)
+
+	result, _, err := wrapped(context.Background(), &mcp.CallToolRequest{}, testInput{Host: "localhost", Port: 80})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(handlerID) != 16 {
+		t.Fatalf("expected a 16 character correlation ID in the handler context, got %q", handlerID)
+	}
+	if result.Meta[CorrelationField] != handlerID {
+		t.Errorf("expected correlation ID in result metadata, got %v", result.Meta)
+	}
+
+	// Wait for async logging
+	time.Sleep(100 * time.Millisecond)
+
+	executions, _, err := store.GetToolExecutions(context.Background(), 10, 0)
+	if err != nil || len(executions) != 1 {
+		t.Fatalf("expected 1 execution logged, got %d (err: %v)", len(executions), err)
+	}
+	if executions[0].CorrelationID != handlerID {
+		t.Errorf("expected correlation ID %q stored, got %q", handlerID, executions[0].CorrelationID)
+	}
+}
+
+func TestWrapToolHandler_KeepsCorrelationID(t *testing.T) {
+	store, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	var handlerID string
+	handler := func(ctx context.Context, req *mcp.CallToolRequest, input testInput) (*mcp.CallToolResult, any, error) {
+		handlerID = CorrelationID(ctx)
+		return &mcp.CallToolResult{}, nil, nil
+	}
+	wrapped := WrapToolHandler(store, "test-tool", handler)
+
+	// Background scan jobs run the tool with the correlation ID of the job.
+	ctx := WithCorrelationID(context.Background(), "00112233aabbccdd")
+	if _, _, err := wrapped(ctx, &mcp.CallToolRequest{}, testInput{Host: "localhost", Port: 80}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if handlerID != "00112233aabbccdd" {
+		t.Errorf("expected the correlation ID of the context kept, got %q", handlerID)
+	}
+}
+
+func TestWrapToolHandler_PausedScanState(t *testing.T) {
+	store, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	var executionID uint
+	handler := func(ctx context.Context, _ *mcp.CallToolRequest, _ ScannerInput) (*mcp.CallToolResult, any, error) {
+		executionID = ExecutionID(ctx)
+		RecordInput(ctx, ScannerInput{Host: "resolved.example", Port: 8080})
+		RecordScanState(ctx, []models.ScannerState{
+			{Port: 8080, Scanner: "nikto", Status: models.ScannerCompleted, Output: "Authorization: Bearer secret123"},
+			{Port: 8080, Scanner: "nuclei", Status: models.ScannerHeld},
+		})
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "paused"}}}, nil, nil
+	}
+
+	wrapped := WrapToolHandler(store, "full_scan", handler)
+	if _, _, err := wrapped(context.Background(), &mcp.CallToolRequest{}, ScannerInput{Host: "localhost"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if executionID == 0 {
+		t.Fatal("expected the execution ID to be exposed to the handler")
+	}
+
+	// Wait for async logging
+	time.Sleep(100 * time.Millisecond)
+
+	exec, err := store.GetToolExecution(context.Background(), executionID)
+	if err != nil {
+		t.Fatalf("failed to get execution: %v", err)
+	}
+	if exec.Status != models.StatusPaused {
+		t.Errorf("expected status paused, got %s", exec.Status)
+	}
+	if len(exec.ScanState) != 2 || exec.ScanState[1].Status != models.ScannerHeld {
+		t.Errorf("expected the scan state to be stored, got %+v", exec.ScanState)
+	}
+	if strings.Contains(exec.ScanState[0].Output, "secret123") {
+		t.Errorf("expected scan state outputs to be redacted, got %q", exec.ScanState[0].Output)
+	}
+	if exec.Target != "http://resolved.example:8080" || !strings.Contains(exec.InputJSON, "resolved.example") {
+		t.Errorf("expected the recorded input to replace the call input, got %s %s", exec.Target, exec.InputJSON)
+	}
+}
+
+func TestRecordScanState_NothingHeld(t *testing.T) {
+	store, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	handler := func(ctx context.Context, _ *mcp.CallToolRequest, _ testInput) (*mcp.CallToolResult, any, error) {
+		RecordScanState(ctx, []models.ScannerState{{Scanner: "nikto", Status: models.ScannerCompleted}})
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "done"}}}, nil, nil
+	}
+
+	wrapped := WrapToolHandler(store, "full_scan", handler)
+	if _, _, err := wrapped(context.Background(), &mcp.CallToolRequest{}, testInput{Host: "localhost"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Wait for async logging
+	time.Sleep(100 * time.Millisecond)
+
+	executions, _, err := store.GetToolExecutions(context.Background(), 10, 0)
+	if err != nil || len(executions) != 1 {
+		t.Fatalf("expected one execution, got %d (%v)", len(executions), err)
+	}
+	if executions[0].Status != models.StatusCompleted || executions[0].ScanState != nil {
+		t.Errorf("expected a completed execution without scan state, got %s %+v", executions[0].Status, executions[0].ScanState)
+	}
+}
+
+func TestWrapToolHandler_RecordsPhases(t *testing.T) {
+	store, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	scanLimiter := limiter.New(1)
+	handler := func(ctx context.Context, _ *mcp.CallToolRequest, _ testInput) (*mcp.CallToolResult, any, error) {
+		scan := LimitScan(scanLimiter, func(_ context.Context, _ ScanParams) ScanResult {
+			time.Sleep(20 * time.Millisecond)
+			return ScanResult{Output: "done"}
+		})
+		RecordPhase(ctx, models.PhaseAvailability, 5*time.Millisecond)
+		RecordRawOutput(ctx, scan(ctx, ScanParams{}).Output)
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "done"}}}, nil, nil
+	}
+
+	scanMetrics := metrics.New()
+	wrapped := WrapToolHandler(store, "test-tool", handler, WithMetrics(scanMetrics))
+	if _, _, err := wrapped(context.Background(), &mcp.CallToolRequest{}, testInput{Host: "localhost"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Wait for async logging
+	time.Sleep(100 * time.Millisecond)
+
+	executions, _, err := store.GetToolExecutions(context.Background(), 10, 0)
+	if err != nil || len(executions) != 1 {
+		t.Fatalf("expected one execution, got %d (%v)", len(executions), err)
+	}
+	phases := executions[0].Phases
+	if phases.ExecMs < 20 {
+		t.Errorf("expected the scan run recorded as exec time, got %+v", phases)
+	}
+	if phases.AvailabilityMs != 5 {
+		t.Errorf("expected the recorded availability check, got %+v", phases)
+	}
+	if phases.ExecMs > executions[0].DurationMs {
+		t.Errorf("expected exec time within the duration %d, got %+v", executions[0].DurationMs, phases)
+	}
+
+	var builder strings.Builder
+	if err := scanMetrics.Write(&builder); err != nil {
+		t.Fatalf("failed to write metrics: %v", err)
+	}
+	for _, phase := range models.Phases {
+		sample := fmt.Sprintf(`wass_tool_phase_seconds_count{tool="test-tool",phase="%s"} 1`, phase)
+		if !strings.Contains(builder.String(), sample) {
+			t.Errorf("expected %s, got %s", sample, builder.String())
+		}
+	}
+}
+
+func TestRecordPhase_OutsideWrapper(t *testing.T) {
+	// Must not panic outside WrapToolHandler.
+	RecordPhase(context.Background(), models.PhaseExec, time.Second)
+}
+
+func TestWrapToolHandler_RecordsTimeline(t *testing.T) {
+	store, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	scanLimiter := limiter.New(1)
+	handler := func(ctx context.Context, _ *mcp.CallToolRequest, _ testInput) (*mcp.CallToolResult, any, error) {
+		scan := func(scanner string, err error) ScanFunc {
+			return TimelineScan(scanner, LimitScan(scanLimiter, func(_ context.Context, _ ScanParams) ScanResult {
+				time.Sleep(20 * time.Millisecond)
+				return ScanResult{Output: "done", Error: err}
+			}))
+		}
+		// The second run waits for the slot of the first.
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			scan("nikto", nil)(ctx, ScanParams{Host: "localhost", Port: 80})
+		}()
+		time.Sleep(5 * time.Millisecond)
+		scan("nuclei", ErrScanTimedOut)(ctx, ScanParams{Host: "localhost", Port: 80, Vhost: "shop.example.com"})
+		<-done
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "done"}}}, nil, nil
+	}
+
+	wrapped := WrapToolHandler(store, "test-tool", handler)
+	if _, _, err := wrapped(context.Background(), &mcp.CallToolRequest{}, testInput{Host: "localhost"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Wait for async logging
+	time.Sleep(100 * time.Millisecond)
+
+	executions, _, err := store.GetToolExecutions(context.Background(), 10, 0)
+	if err != nil || len(executions) != 1 {
+		t.Fatalf("expected one execution, got %d (%v)", len(executions), err)
+	}
+	runs := executions[0].Timeline
+	if len(runs) != 2 {
+		t.Fatalf("expected two scanner runs on the timeline, got %+v", runs)
+	}
+	if runs[0].Scanner != "nikto" || runs[0].Status != models.ScannerCompleted || runs[0].Host != "localhost" {
+		t.Errorf("expected the completed nikto run first, got %+v", runs[0])
+	}
+	if runs[1].Scanner != "nuclei" || runs[1].Status != models.ScannerTimedOut || runs[1].Vhost != "shop.example.com" {
+		t.Errorf("expected the timed out nuclei run second, got %+v", runs[1])
+	}
+	if runs[1].StartedAt.Before(runs[0].FinishedAt) {
+		t.Errorf("expected nuclei to start once nikto released the slot, got %+v", runs)
+	}
+	if wait := runs[1].StartedAt.Sub(runs[1].QueuedAt); wait < 10*time.Millisecond {
+		t.Errorf("expected the queue wait of nuclei on the timeline, got %v", wait)
+	}
+
+	// Outside the wrapper runs are not recorded.
+	RecordScannerRun(context.Background(), models.ScannerRun{Scanner: "nikto"})
+}
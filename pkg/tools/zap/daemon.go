@@ -0,0 +1,243 @@
+package zap
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// apiKeyHeader is the header authenticating ZAP API calls.
+	apiKeyHeader = "X-ZAP-API-Key"
+	// apiTimeout bounds each ZAP API call; scans are polled, not awaited.
+	apiTimeout = 30 * time.Second
+	// maxAPIResponseBytes bounds the ZAP API responses read, alerts included.
+	maxAPIResponseBytes = 64 << 20
+	// alertsPageSize is the number of alerts fetched per call.
+	alertsPageSize = 500
+)
+
+// pollInterval is how often the progress of daemon spider, passive and active scans is polled.
+var pollInterval = 2 * time.Second
+
+// Daemon configures an already-running ZAP daemon that scans run through instead of the
+// zap-baseline.py script, e.g. a ZAP container shared by several servers.
+type Daemon struct {
+	// URL is the ZAP API URL, e.g. "http://127.0.0.1:8080", empty to run zap-baseline.py.
+	URL string
+	// APIKey authenticates with the API, empty when the daemon has the API key disabled.
+	APIKey string
+}
+
+// Validate checks the API URL.
+func (d Daemon) Validate() error {
+	if d.URL == "" {
+		if d.APIKey != "" {
+			return errors.New("zap API key requires a zap API URL")
+		}
+		return nil
+	}
+	parsed, err := url.Parse(d.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" ||
+		parsed.User != nil || parsed.RawQuery != "" {
+		return fmt.Errorf("zap API URL must be an http(s) URL without credentials or query, got %q", d.URL)
+	}
+
+	return nil
+}
+
+// Enabled reports whether scans run through the daemon.
+func (d Daemon) Enabled() bool {
+	return d.URL != ""
+}
+
+// apiClient calls the JSON API of a ZAP daemon.
+type apiClient struct {
+	daemon Daemon
+	client *http.Client
+}
+
+// newAPIClient returns a client of the API of daemon.
+func newAPIClient(daemon Daemon) *apiClient {
+	return &apiClient{daemon: daemon, client: &http.Client{Timeout: apiTimeout}}
+}
+
+// call calls the JSON API component view or action name with params and decodes the response into
+// out, e.g. call(ctx, "spider/action/scan", ...) for /JSON/spider/action/scan/.
+func (c *apiClient) call(ctx context.Context, name string, params url.Values, out any) error {
+	endpoint := strings.TrimSuffix(c.daemon.URL, "/") + "/JSON/" + name + "/"
+	if len(params) > 0 {
+		endpoint += "?" + params.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create zap API request: %w", err)
+	}
+	if c.daemon.APIKey != "" {
+		req.Header.Set(apiKeyHeader, c.daemon.APIKey)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("zap API %s failed: %w", name, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxAPIResponseBytes))
+	if err != nil {
+		return fmt.Errorf("failed to read zap API %s response: %w", name, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("zap API %s returned %s: %s", name, resp.Status, strings.TrimSpace(string(body)))
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to decode zap API %s response: %w", name, err)
+	}
+
+	return nil
+}
+
+// version returns the ZAP version of the daemon.
+func (c *apiClient) version(ctx context.Context) (string, error) {
+	var resp struct {
+		Version string `json:"version"`
+	}
+	if err := c.call(ctx, "core/view/version", nil, &resp); err != nil {
+		return "", err
+	}
+
+	return resp.Version, nil
+}
+
+// startScan starts the spider or active scan (component "spider" or "ascan") of targetURL and
+// returns its ID.
+func (c *apiClient) startScan(ctx context.Context, component, targetURL string) (string, error) {
+	var resp struct {
+		Scan string `json:"scan"`
+	}
+	params := url.Values{"url": {targetURL}, "recurse": {"true"}}
+	if err := c.call(ctx, component+"/action/scan", params, &resp); err != nil {
+		return "", err
+	}
+
+	return resp.Scan, nil
+}
+
+// waitScan polls the spider or active scan scanID until it completes. A scan cancelled through ctx
+// is stopped on the daemon.
+func (c *apiClient) waitScan(ctx context.Context, component, scanID string) error {
+	return c.poll(ctx, func() (bool, error) {
+		var resp struct {
+			Status string `json:"status"`
+		}
+		if err := c.call(ctx, component+"/view/status", url.Values{"scanId": {scanID}}, &resp); err != nil {
+			return false, err
+		}
+		progress, err := strconv.Atoi(resp.Status)
+		if err != nil {
+			return false, fmt.Errorf("unexpected zap %s status %q", component, resp.Status)
+		}
+		return progress >= 100, nil
+	}, func() {
+		// The caller's context is done, so the scan is stopped with a fresh one.
+		stopCtx, cancel := context.WithTimeout(context.Background(), apiTimeout)
+		defer cancel()
+		var resp json.RawMessage
+		_ = c.call(stopCtx, component+"/action/stop", url.Values{"scanId": {scanID}}, &resp)
+	})
+}
+
+// waitPassive polls the passive scanner until it has scanned every recorded request.
+func (c *apiClient) waitPassive(ctx context.Context) error {
+	return c.poll(ctx, func() (bool, error) {
+		var resp struct {
+			RecordsToScan string `json:"recordsToScan"`
+		}
+		if err := c.call(ctx, "pscan/view/recordsToScan", nil, &resp); err != nil {
+			return false, err
+		}
+		return resp.RecordsToScan == "0", nil
+	}, nil)
+}
+
+// poll calls done every pollInterval until it reports completion or fails. When ctx is done first,
+// it calls stop, if set, and returns the cause.
+func (c *apiClient) poll(ctx context.Context, done func() (bool, error), stop func()) error {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		finished, err := done()
+		if ctx.Err() != nil {
+			if stop != nil {
+				stop()
+			}
+			return context.Cause(ctx)
+		}
+		if err != nil || finished {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+		case <-ticker.C:
+		}
+	}
+}
+
+// alerts returns the alerts the daemon raised for the URLs under baseURL.
+func (c *apiClient) alerts(ctx context.Context, baseURL string) ([]apiAlert, error) {
+	var alerts []apiAlert
+	for start := 0; ; start += alertsPageSize {
+		var resp struct {
+			Alerts []apiAlert `json:"alerts"`
+		}
+		params := url.Values{"baseurl": {baseURL}, "start": {strconv.Itoa(start)}, "count": {strconv.Itoa(alertsPageSize)}}
+		if err := c.call(ctx, "core/view/alerts", params, &resp); err != nil {
+			return nil, err
+		}
+		alerts = append(alerts, resp.Alerts...)
+		if len(resp.Alerts) < alertsPageSize {
+			return alerts, nil
+		}
+	}
+}
+
+// scan runs a baseline scan of targetURL on the daemon: the spider, then the passive scan of the
+// requests it recorded, then with active an active scan. It returns the steps run and the
+// alerts raised for targetURL.
+func (c *apiClient) scan(ctx context.Context, targetURL string, active bool) ([]string, []apiAlert, error) {
+	steps := []string{"spider"}
+	spiderID, err := c.startScan(ctx, "spider", targetURL)
+	if err != nil {
+		return steps, nil, err
+	}
+	if err := c.waitScan(ctx, "spider", spiderID); err != nil {
+		return steps, nil, err
+	}
+
+	steps = append(steps, "passive scan")
+	if err := c.waitPassive(ctx); err != nil {
+		return steps, nil, err
+	}
+
+	if active {
+		steps = append(steps, "active scan")
+		scanID, err := c.startScan(ctx, "ascan", targetURL)
+		if err != nil {
+			return steps, nil, err
+		}
+		if err := c.waitScan(ctx, "ascan", scanID); err != nil {
+			return steps, nil, err
+		}
+	}
+
+	alerts, err := c.alerts(ctx, targetURL)
+
+	return steps, alerts, err
+}
@@ -0,0 +1,100 @@
+package zap
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/tb0hdan/wass-mcp/pkg/findings"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/types"
+)
+
+// falsePositive is the confidence code of alerts marked as false positives.
+const falsePositive = "0"
+
+// riskSeverities maps the risk codes of ZAP JSON reports to severities.
+var riskSeverities = map[string]string{
+	"0": types.SeverityInfo,
+	"1": types.SeverityLow,
+	"2": types.SeverityMedium,
+	"3": types.SeverityHigh,
+}
+
+// referenceRe matches the URLs of the HTML reference list of an alert.
+var referenceRe = regexp.MustCompile(`https?://[^\s"'<>]+`)
+
+// ParseFindings parses the ZAP JSON report following the scan log: one finding per occurrence of
+// each alert, rated by its risk code, with the rule ID as template ID, the occurrence URL and
+// parameter, the CWE ID and reference URLs of the rule and the evidence of the occurrence. Alerts
+// marked as false positives are skipped. Output without a report falls back to bracketed
+// severity tags.
+func (t *Tool) ParseFindings(output string) ([]models.Finding, error) {
+	start := strings.Index(output, "\n{")
+	if strings.HasPrefix(output, "{") {
+		start = 0
+	}
+	end := strings.LastIndex(output, "}")
+	if start < 0 || end <= start {
+		return findings.ExtractGeneric(binaryName, output), nil
+	}
+
+	var parsed report
+	if err := json.Unmarshal([]byte(output[start:end+1]), &parsed); err != nil {
+		return findings.ExtractGeneric(binaryName, output), nil //nolint:nilerr
+	}
+
+	var found []models.Finding
+	for _, scanned := range parsed.Sites {
+		for _, raised := range scanned.Alerts {
+			if raised.Confidence == falsePositive {
+				continue
+			}
+			instances := raised.Instances
+			if len(instances) == 0 {
+				instances = []instance{{URI: scanned.Name}}
+			}
+			for _, occurrence := range instances {
+				found = append(found, raised.finding(occurrence))
+			}
+		}
+	}
+
+	return found, nil
+}
+
+// finding returns the finding of an occurrence of the alert.
+func (a alert) finding(occurrence instance) models.Finding {
+	severity, ok := riskSeverities[a.RiskCode]
+	if !ok {
+		severity = types.SeverityInfo
+	}
+	var cwes []string
+	if a.CWEID != "" && a.CWEID != "0" && a.CWEID != "-1" {
+		cwes = []string{"CWE-" + a.CWEID}
+	}
+
+	var evidence []models.Evidence
+	evidence = findings.AppendEvidence(evidence, models.EvidenceExtracted, binaryName, occurrence.Evidence)
+	if occurrence.Attack != "" {
+		evidence = findings.AppendEvidence(evidence, models.EvidenceRequest, binaryName,
+			strings.TrimSpace(occurrence.Method+" "+occurrence.URI)+"\n"+occurrence.Attack)
+	}
+
+	title := a.Name
+	if title == "" {
+		title = a.Alert
+	}
+
+	return models.Finding{
+		CWEs:       cwes,
+		Evidence:   evidence,
+		Parameter:  occurrence.Param,
+		References: referenceRe.FindAllString(a.Reference, -1),
+		Scanner:    binaryName,
+		Severity:   severity,
+		TemplateID: a.PluginID,
+		Title:      title,
+		URL:        occurrence.URI,
+	}
+}
@@ -0,0 +1,108 @@
+package zap
+
+import (
+	"os"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/types"
+)
+
+const baselineOutput = `Total of 12 URLs
+PASS: Vulnerable JS Library [10003]
+WARN-NEW: Content Security Policy (CSP) Header Not Set [10038] x 2
+FAIL-NEW: 0	FAIL-INPROG: 0	WARN-NEW: 2	WARN-INPROG: 0	INFO: 0	IGNORE: 0	PASS: 50
+{
+  "@programName": "ZAP",
+  "@version": "2.14.0",
+  "site": [{
+    "@name": "http://localhost:8080",
+    "alerts": [
+      {
+        "pluginid": "10038", "alert": "Content Security Policy (CSP) Header Not Set",
+        "name": "Content Security Policy (CSP) Header Not Set", "riskcode": "2", "confidence": "3",
+        "instances": [
+          {"uri": "http://localhost:8080/", "method": "GET", "param": "", "attack": "", "evidence": ""},
+          {"uri": "http://localhost:8080/login", "method": "GET", "param": "", "attack": "", "evidence": ""}
+        ],
+        "reference": "<p>https://developer.mozilla.org/en-US/docs/Web/HTTP/CSP</p><p>https://www.w3.org/TR/CSP/</p>",
+        "cweid": "693", "wascid": "15"
+      },
+      {
+        "pluginid": "40012", "alert": "Cross Site Scripting (Reflected)", "riskcode": "3", "confidence": "2",
+        "instances": [
+          {"uri": "http://localhost:8080/search?q=x", "method": "GET", "param": "q",
+           "attack": "<script>alert(1);</script>", "evidence": "<script>alert(1);</script>"}
+        ],
+        "cweid": "79"
+      },
+      {
+        "pluginid": "10096", "name": "Timestamp Disclosure", "riskcode": "0", "confidence": "0",
+        "instances": [{"uri": "http://localhost:8080/app.js"}], "cweid": "-1"
+      }
+    ]
+  }]
+}`
+
+type ParseTestSuite struct {
+	suite.Suite
+	tool *Tool
+}
+
+func (s *ParseTestSuite) SetupTest() {
+	s.tool = New(zerolog.New(os.Stdout)).(*Tool)
+}
+
+func (s *ParseTestSuite) TestParseFindings() {
+	found, err := s.tool.ParseFindings(baselineOutput)
+	s.Require().NoError(err)
+	s.Require().Len(found, 3, "false positives are skipped")
+
+	s.Equal("Content Security Policy (CSP) Header Not Set", found[0].Title)
+	s.Equal(types.SeverityMedium, found[0].Severity)
+	s.Equal("10038", found[0].TemplateID)
+	s.Equal("http://localhost:8080/", found[0].URL)
+	s.Equal([]string{"CWE-693"}, found[0].CWEs)
+	s.Equal([]string{"https://developer.mozilla.org/en-US/docs/Web/HTTP/CSP", "https://www.w3.org/TR/CSP/"}, found[0].References)
+	s.Empty(found[0].Evidence)
+	s.Equal("http://localhost:8080/login", found[1].URL)
+
+	s.Equal("Cross Site Scripting (Reflected)", found[2].Title, "older reports name alerts in alert")
+	s.Equal(types.SeverityHigh, found[2].Severity)
+	s.Equal("q", found[2].Parameter)
+	s.Require().Len(found[2].Evidence, 2)
+	s.Equal(models.EvidenceExtracted, found[2].Evidence[0].Kind)
+	s.Equal(models.EvidenceRequest, found[2].Evidence[1].Kind)
+	s.Equal("GET http://localhost:8080/search?q=x\n<script>alert(1);</script>", found[2].Evidence[1].Content)
+}
+
+func (s *ParseTestSuite) TestParseFindings_DaemonReport() {
+	data, err := buildReport("http://localhost:8080/app", "2.14.0", []apiAlert{
+		{PluginID: "10021", Alert: "X-Content-Type-Options Header Missing", Risk: "Low", Confidence: "Medium", URL: "http://localhost:8080/app", CWEID: "693"},
+		{PluginID: "10021", Alert: "X-Content-Type-Options Header Missing", Risk: "Low", Confidence: "Medium", URL: "http://localhost:8080/app/x.css"},
+		{PluginID: "40018", Alert: "SQL Injection", Risk: "High", Confidence: "Medium", URL: "http://localhost:8080/app?id=1", Param: "id", CWEID: "89"},
+	})
+	s.Require().NoError(err)
+
+	found, err := s.tool.ParseFindings("[ZAP 2.14.0 daemon: spider, passive scan, active scan]\n" + string(data))
+	s.Require().NoError(err)
+	s.Require().Len(found, 3)
+	s.Equal(types.SeverityLow, found[0].Severity)
+	s.Equal("http://localhost:8080/app/x.css", found[1].URL)
+	s.Equal("SQL Injection", found[2].Title)
+	s.Equal(types.SeverityHigh, found[2].Severity)
+	s.Equal("id", found[2].Parameter)
+	s.Equal([]string{"CWE-89"}, found[2].CWEs)
+}
+
+func (s *ParseTestSuite) TestParseFindings_NoReport() {
+	found, err := s.tool.ParseFindings("WARN-NEW: Content Security Policy (CSP) Header Not Set [10038] x 2\n")
+	s.Require().NoError(err)
+	s.Empty(found)
+}
+
+func TestParseTestSuite(t *testing.T) {
+	suite.Run(t, new(ParseTestSuite))
+}
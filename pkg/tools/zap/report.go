@@ -0,0 +1,125 @@
+package zap
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// riskCodes maps the risk names of the ZAP API to the risk codes of ZAP JSON reports.
+var riskCodes = map[string]string{
+	"Informational": "0",
+	"Low":           "1",
+	"Medium":        "2",
+	"High":          "3",
+}
+
+// confidenceCodes maps the confidence names of the ZAP API to the codes of ZAP JSON reports.
+var confidenceCodes = map[string]string{
+	"False Positive": "0",
+	"Low":            "1",
+	"Medium":         "2",
+	"High":           "3",
+	"Confirmed":      "4",
+}
+
+// instance is an occurrence of an alert of a ZAP JSON report.
+type instance struct {
+	URI      string `json:"uri"`
+	Method   string `json:"method"`
+	Param    string `json:"param"`
+	Attack   string `json:"attack"`
+	Evidence string `json:"evidence"`
+}
+
+// alert is an alert of a ZAP JSON report, with the occurrences of one rule on one site.
+type alert struct {
+	PluginID string `json:"pluginid"`
+	// Alert is the name of the alert in reports of older ZAP versions, which lack Name.
+	Alert      string     `json:"alert,omitempty"`
+	Name       string     `json:"name"`
+	RiskCode   string     `json:"riskcode"`
+	Confidence string     `json:"confidence"`
+	RiskDesc   string     `json:"riskdesc"`
+	Desc       string     `json:"desc"`
+	Instances  []instance `json:"instances"`
+	Count      string     `json:"count"`
+	Solution   string     `json:"solution"`
+	Reference  string     `json:"reference"`
+	CWEID      string     `json:"cweid"`
+	WASCID     string     `json:"wascid"`
+}
+
+// site is a site of a ZAP JSON report.
+type site struct {
+	Name   string  `json:"@name"`
+	Alerts []alert `json:"alerts"`
+}
+
+// report is the ZAP JSON report written by zap-baseline.py -J, and rebuilt from daemon alerts.
+type report struct {
+	ProgramName string `json:"@programName,omitempty"`
+	Version     string `json:"@version,omitempty"`
+	Sites       []site `json:"site"`
+}
+
+// apiAlert is an alert of the ZAP API core/view/alerts view, one per occurrence.
+type apiAlert struct {
+	PluginID    string `json:"pluginId"`
+	Alert       string `json:"alert"`
+	Risk        string `json:"risk"`
+	Confidence  string `json:"confidence"`
+	URL         string `json:"url"`
+	Method      string `json:"method"`
+	Param       string `json:"param"`
+	Attack      string `json:"attack"`
+	Evidence    string `json:"evidence"`
+	Description string `json:"description"`
+	Solution    string `json:"solution"`
+	Reference   string `json:"reference"`
+	CWEID       string `json:"cweid"`
+	WASCID      string `json:"wascid"`
+}
+
+// buildReport rebuilds the ZAP JSON report of targetURL from daemon alerts, grouping the
+// occurrences of each rule and confidence in the order the rules were first raised.
+func buildReport(targetURL, version string, alerts []apiAlert) ([]byte, error) {
+	name := targetURL
+	if parsed, err := url.Parse(targetURL); err == nil && parsed.Host != "" {
+		name = parsed.Scheme + "://" + parsed.Host
+	}
+	result := report{ProgramName: "ZAP", Version: version, Sites: []site{{Name: name, Alerts: []alert{}}}}
+
+	index := make(map[string]int)
+	for _, raised := range alerts {
+		key := raised.PluginID + "\x00" + raised.Alert + "\x00" + raised.Confidence
+		i, ok := index[key]
+		if !ok {
+			i = len(result.Sites[0].Alerts)
+			index[key] = i
+			result.Sites[0].Alerts = append(result.Sites[0].Alerts, alert{
+				PluginID:   raised.PluginID,
+				Name:       raised.Alert,
+				RiskCode:   riskCodes[raised.Risk],
+				Confidence: confidenceCodes[raised.Confidence],
+				RiskDesc:   fmt.Sprintf("%s (%s)", raised.Risk, raised.Confidence),
+				Desc:       raised.Description,
+				Solution:   raised.Solution,
+				Reference:  raised.Reference,
+				CWEID:      raised.CWEID,
+				WASCID:     raised.WASCID,
+			})
+		}
+		grouped := &result.Sites[0].Alerts[i]
+		grouped.Instances = append(grouped.Instances, instance{
+			URI:      raised.URL,
+			Method:   raised.Method,
+			Param:    raised.Param,
+			Attack:   raised.Attack,
+			Evidence: raised.Evidence,
+		})
+		grouped.Count = fmt.Sprint(len(grouped.Instances))
+	}
+
+	return json.MarshalIndent(result, "", "  ")
+}
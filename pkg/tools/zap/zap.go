@@ -0,0 +1,175 @@
+package zap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+	"github.com/tb0hdan/wass-mcp/pkg/bundle"
+	"github.com/tb0hdan/wass-mcp/pkg/server"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+)
+
+const (
+	binaryName  = "zap-baseline.py"
+	description = "OWASP ZAP baseline scan: spiders the target and reports the passive scan alerts of the requests, optionally followed by an active scan."
+	headerVerb  = "output"
+	// fullScanScript is the ZAP packaged scan running an active scan after the baseline.
+	fullScanScript = "zap-full-scan.py"
+	// reportFile is the JSON report written by the ZAP packaged scans in their working directory.
+	reportFile = "zap-report.json"
+)
+
+// exitFailures and exitWarnings are the exit codes of ZAP packaged scans that raised failing or
+// warning alerts; other non-zero codes are errors.
+const (
+	exitFailures = 1
+	exitWarnings = 2
+)
+
+// supportedOptions are the scan options zap honours.
+var supportedOptions = []string{tools.OptionActiveScan}
+
+// Tool implements the OWASP ZAP scanner.
+type Tool struct {
+	tools.BaseScanner
+	// daemon is the ZAP daemon scans run through, see SetDaemon.
+	daemon Daemon
+}
+
+// Scan performs the ZAP scan and returns its output: the log of the packaged scan, or the steps
+// run by the daemon, followed by the JSON report.
+func (t *Tool) Scan(ctx context.Context, params tools.ScanParams) tools.ScanResult {
+	targetURL := params.Target().URL()
+	logger := tools.ContextLogger(ctx, t.Logger)
+	active, _ := strconv.ParseBool(params.Option(tools.OptionActiveScan))
+	logger.Info().Msgf("Running zap scan on %s", targetURL)
+
+	if t.daemon.Enabled() {
+		return t.scanDaemon(ctx, targetURL, active)
+	}
+
+	workDir, cleanup, err := t.WorkDir(ctx)
+	if err != nil {
+		return tools.ScanResult{
+			Error: err,
+		}
+	}
+	defer cleanup()
+
+	var cmd *exec.Cmd
+	if active {
+		path, _, err := bundle.LookPath(fullScanScript)
+		if err != nil {
+			return tools.ScanResult{
+				Error: fmt.Errorf("active zap scans need %s or a zap daemon: %w", fullScanScript, err),
+			}
+		}
+		cmd = exec.CommandContext(ctx, path, buildArgs(targetURL)...) //nolint:gosec
+	} else {
+		cmd = t.Command(ctx, buildArgs(targetURL)...)
+	}
+	cmd.Dir = workDir
+	cmd.Env = tools.ScanEnv(params, workDir)
+	output, err := t.CombinedOutput(ctx, cmd)
+
+	// ZAP packaged scans report alerts through their exit code.
+	var exitErr *exec.ExitError
+	if err != nil && !(errors.As(err, &exitErr) && (exitErr.ExitCode() == exitFailures || exitErr.ExitCode() == exitWarnings)) {
+		return tools.ScanResult{
+			Output: string(output),
+			Error:  fmt.Errorf("failed to execute zap: %w", err),
+		}
+	}
+
+	result := string(output)
+	if data, err := os.ReadFile(filepath.Join(workDir, reportFile)); err == nil { //nolint:gosec
+		result = strings.TrimRight(result, "\n") + "\n" + string(data)
+	} else {
+		logger.Warn().Err(err).Msg("ZAP report not found, returning the scan log only")
+	}
+
+	return tools.ScanResult{
+		Output: result,
+		Error:  nil,
+	}
+}
+
+// scanDaemon runs the scan on the configured ZAP daemon and returns the JSON report of its alerts.
+func (t *Tool) scanDaemon(ctx context.Context, targetURL string, active bool) tools.ScanResult {
+	client := newAPIClient(t.daemon)
+	version, err := client.version(ctx)
+	if err != nil {
+		return tools.ScanResult{
+			Error: fmt.Errorf("zap daemon unavailable: %w", err),
+		}
+	}
+
+	steps, alerts, err := client.scan(ctx, targetURL, active)
+	output := fmt.Sprintf("[ZAP %s daemon: %s]\n", version, strings.Join(steps, ", "))
+	if err != nil {
+		return tools.ScanResult{
+			Output: output,
+			Error:  fmt.Errorf("zap daemon scan failed: %w", err),
+		}
+	}
+	data, err := buildReport(targetURL, version, alerts)
+	if err != nil {
+		return tools.ScanResult{
+			Output: output,
+			Error:  fmt.Errorf("failed to build zap report: %w", err),
+		}
+	}
+
+	return tools.ScanResult{
+		Output: output + string(data),
+		Error:  nil,
+	}
+}
+
+// buildArgs builds the arguments of the ZAP packaged scans.
+func buildArgs(targetURL string) []string {
+	return []string{"-t", targetURL, "-J", reportFile}
+}
+
+// SetDaemon makes every scan run through the ZAP daemon configured by cfg instead of the ZAP
+// packaged scans, which then need not be installed.
+func (t *Tool) SetDaemon(cfg Daemon) {
+	t.daemon = cfg
+	t.Remote = cfg.Enabled()
+}
+
+// Version returns the version of the configured ZAP daemon. Without a daemon it returns
+// tools.ErrNoVersion, since the packaged scans cannot report it without starting ZAP.
+func (t *Tool) Version(ctx context.Context) (string, error) {
+	if !t.daemon.Enabled() {
+		return "", tools.ErrNoVersion
+	}
+
+	return newAPIClient(t.daemon).version(ctx)
+}
+
+// Register registers the zap tool with the MCP server.
+func (t *Tool) Register(srv *server.Server) error {
+	return t.RegisterTool(srv, t.Handler)
+}
+
+// Handler handles MCP tool requests.
+func (t *Tool) Handler(ctx context.Context, _ *mcp.CallToolRequest, input tools.ScannerInput) (*mcp.CallToolResult, any, error) {
+	return t.HandleScan(ctx, input, headerVerb, t.Scan)
+}
+
+// New creates a new zap scanner tool.
+func New(logger zerolog.Logger) tools.Scanner {
+	base := tools.NewBaseScanner(binaryName, description, logger, supportedOptions...)
+	base.WarmUpArgs = []string{"-h"}
+
+	return &Tool{BaseScanner: base}
+}
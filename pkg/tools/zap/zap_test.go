@@ -0,0 +1,162 @@
+package zap
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/tools"
+)
+
+type ZapTestSuite struct {
+	suite.Suite
+	tool *Tool
+}
+
+func (s *ZapTestSuite) SetupTest() {
+	s.tool = New(zerolog.New(os.Stdout)).(*Tool)
+	pollInterval = time.Millisecond
+}
+
+// fakeScripts installs fake ZAP packaged scans running script on PATH.
+func (s *ZapTestSuite) fakeScripts(script string) {
+	binDir := s.T().TempDir()
+	for _, name := range []string{binaryName, fullScanScript} {
+		content := "#!/bin/sh\necho " + name + "\n" + script
+		s.Require().NoError(os.WriteFile(filepath.Join(binDir, name), []byte(content), 0o700)) //nolint:gosec
+	}
+	s.T().Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+// fakeDaemon starts a fake ZAP daemon and returns the API paths it was called with.
+func (s *ZapTestSuite) fakeDaemon() (*httptest.Server, *[]string) {
+	var mu sync.Mutex
+	var calls []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls = append(calls, r.URL.Path)
+		mu.Unlock()
+		if r.Header.Get(apiKeyHeader) != "secret" {
+			http.Error(w, `{"code":"bad_api_key"}`, http.StatusForbidden)
+			return
+		}
+		responses := map[string]any{
+			"/JSON/core/view/version/":        map[string]string{"version": "2.14.0"},
+			"/JSON/spider/action/scan/":       map[string]string{"scan": "1"},
+			"/JSON/spider/view/status/":       map[string]string{"status": "100"},
+			"/JSON/pscan/view/recordsToScan/": map[string]string{"recordsToScan": "0"},
+			"/JSON/ascan/action/scan/":        map[string]string{"scan": "2"},
+			"/JSON/ascan/view/status/":        map[string]string{"status": "100"},
+			"/JSON/core/view/alerts/": map[string]any{"alerts": []apiAlert{
+				{PluginID: "10021", Alert: "X-Content-Type-Options Header Missing", Risk: "Low", Confidence: "Medium", URL: r.URL.Query().Get("baseurl")},
+			}},
+		}
+		response, ok := responses[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+	s.T().Cleanup(srv.Close)
+
+	return srv, &calls
+}
+
+func (s *ZapTestSuite) TestNew() {
+	s.Equal("zap-baseline.py", s.tool.Name())
+	s.Empty(tools.Technologies(s.tool))
+	s.False(tools.IsPassive(s.tool))
+	s.Equal([]string{tools.OptionActiveScan}, s.tool.SupportedOptions())
+}
+
+func (s *ZapTestSuite) TestDaemon_Validate() {
+	s.NoError(Daemon{}.Validate())
+	s.NoError(Daemon{URL: "http://127.0.0.1:8080", APIKey: "secret"}.Validate())
+	s.Error(Daemon{APIKey: "secret"}.Validate())
+	for _, url := range []string{"127.0.0.1:8080", "ftp://zap", "http://user:pw@zap:8080", "http://zap:8080/?apikey=x"} {
+		s.Error(Daemon{URL: url}.Validate(), url)
+	}
+}
+
+func (s *ZapTestSuite) TestScan_PackagedScan() {
+	// The fake scan writes its report and exits with the code of scans that raised warnings.
+	s.fakeScripts("echo '{\"site\": []}' > zap-report.json\nexit 2\n")
+
+	result := s.tool.Scan(context.Background(), tools.ScanParams{Host: "localhost", Port: 8080, Scheme: "http"})
+	s.Require().NoError(result.Error)
+	s.Equal("zap-baseline.py\n{\"site\": []}\n", result.Output)
+
+	result = s.tool.Scan(context.Background(), tools.ScanParams{
+		Host: "localhost", Port: 8080, Scheme: "http", Options: map[string]string{tools.OptionActiveScan: "true"},
+	})
+	s.Require().NoError(result.Error)
+	s.Contains(result.Output, "zap-full-scan.py", "active scans run the full scan")
+}
+
+func (s *ZapTestSuite) TestScan_PackagedScanError() {
+	s.fakeScripts("echo 'ZAP failed to start'\nexit 3\n")
+
+	result := s.tool.Scan(context.Background(), tools.ScanParams{Host: "localhost", Port: 8080, Scheme: "http"})
+	s.ErrorContains(result.Error, "failed to execute zap")
+	s.Contains(result.Output, "ZAP failed to start")
+}
+
+func (s *ZapTestSuite) TestScan_Daemon() {
+	srv, calls := s.fakeDaemon()
+	s.tool.SetDaemon(Daemon{URL: srv.URL, APIKey: "secret"})
+	s.True(s.tool.IsAvailable(), "daemon scans need no packaged scan")
+
+	version, err := s.tool.Version(context.Background())
+	s.Require().NoError(err)
+	s.Equal("2.14.0", version)
+
+	result := s.tool.Scan(context.Background(), tools.ScanParams{Host: "localhost", Port: 8080, Scheme: "http"})
+	s.Require().NoError(result.Error)
+	s.Contains(result.Output, "[ZAP 2.14.0 daemon: spider, passive scan]\n")
+	s.NotContains(*calls, "/JSON/ascan/action/scan/")
+
+	found, err := s.tool.ParseFindings(result.Output)
+	s.Require().NoError(err)
+	s.Require().Len(found, 1)
+	s.Equal("http://localhost:8080", found[0].URL)
+
+	result = s.tool.Scan(context.Background(), tools.ScanParams{
+		Host: "localhost", Port: 8080, Scheme: "http", Options: map[string]string{tools.OptionActiveScan: "true"},
+	})
+	s.Require().NoError(result.Error)
+	s.Contains(result.Output, "spider, passive scan, active scan")
+	s.Contains(*calls, "/JSON/ascan/view/status/")
+}
+
+func (s *ZapTestSuite) TestScan_DaemonUnavailable() {
+	srv, _ := s.fakeDaemon()
+	s.tool.SetDaemon(Daemon{URL: srv.URL, APIKey: "wrong"})
+
+	result := s.tool.Scan(context.Background(), tools.ScanParams{Host: "localhost", Port: 8080, Scheme: "http"})
+	s.ErrorContains(result.Error, "zap daemon unavailable")
+	s.ErrorContains(result.Error, "403")
+}
+
+func (s *ZapTestSuite) TestVersion_NoDaemon() {
+	_, err := s.tool.Version(context.Background())
+	s.ErrorIs(err, tools.ErrNoVersion)
+}
+
+func (s *ZapTestSuite) TestHandler_ValidationError() {
+	_, _, err := s.tool.Handler(context.Background(), &mcp.CallToolRequest{}, tools.ScannerInput{Host: "invalid host!!!"})
+	s.ErrorContains(err, "validation error")
+}
+
+func TestZapTestSuite(t *testing.T) {
+	suite.Run(t, new(ZapTestSuite))
+}
@@ -1,5 +1,7 @@
 package types
 
+import "time"
+
 const (
 	// DefaultHost is the default target host for scanner tools.
 	DefaultHost = "localhost"
@@ -17,4 +19,80 @@ const (
 	MaxDefaultLines = 200
 	// MaxAllowedLines is the maximum allowed lines limit for pagination.
 	MaxAllowedLines = 100000
+
+	// SeverityCritical is the critical finding severity.
+	SeverityCritical = "critical"
+	// SeverityHigh is the high finding severity.
+	SeverityHigh = "high"
+	// SeverityMedium is the medium finding severity.
+	SeverityMedium = "medium"
+	// SeverityLow is the low finding severity.
+	SeverityLow = "low"
+	// SeverityInfo is the informational finding severity.
+	SeverityInfo = "info"
+
+	// MaxRedirects is the maximum number of redirects followed during target normalization.
+	MaxRedirects = 10
+	// NormalizeTimeout bounds the redirect-following request made before scanning.
+	NormalizeTimeout = 15 * time.Second
+
+	// DefaultMaxOutputBytes is the default maximum size of output stored in the database.
+	DefaultMaxOutputBytes = 1 << 20
+	// DefaultArtifactSweepInterval is how often artifacts are checked against their retention.
+	DefaultArtifactSweepInterval = time.Hour
+	// OutputPreviewBytes is the size of the preview stored for outputs spilled to artifact files.
+	OutputPreviewBytes = 4096
+	// StreamChunkBytes is the output buffered by a running scanner before it is flushed to its
+	// output stream artifact.
+	StreamChunkBytes = 64 << 10
+	// StreamFlushInterval is how often buffered scanner output is flushed to its output stream
+	// artifact when less than StreamChunkBytes arrived.
+	StreamFlushInterval = 5 * time.Second
+
+	// DefaultMaxResponseBytes is the default byte budget of the output text returned per tool call,
+	// keeping responses within what MCP clients accept in a single tool result.
+	DefaultMaxResponseBytes = 64 << 10
+	// DefaultCompressThreshold is the default page size above which full_scan returns its report
+	// gzip-compressed instead of as text: 0, so that only clients asking for compression get it.
+	DefaultCompressThreshold = 0
+
+	// DefaultMaxConcurrentScans is the default limit of scanner runs in flight across all tools.
+	DefaultMaxConcurrentScans = 8
+
+	// DefaultMaxConcurrentJobs is the default limit of background scan jobs running at once.
+	DefaultMaxConcurrentJobs = 4
+
+	// ProbeTimeout bounds each request made to detect HTTP(S) on a discovered port.
+	ProbeTimeout = 5 * time.Second
+	// ThrottleProbeRequests is the number of requests sent in a burst to detect throttling.
+	ThrottleProbeRequests = 5
+	// ThrottledRateLimit is the requests per second scanners are held to once throttling was
+	// detected.
+	ThrottledRateLimit = 2
+	// NotifyTimeout bounds the webhook request sent when a scan completes.
+	NotifyTimeout = 10 * time.Second
+
+	// MaxEvidenceBytes bounds the content of each evidence snippet kept with a finding.
+	MaxEvidenceBytes = 2048
+	// MaxEvidence bounds the number of evidence entries kept with a finding.
+	MaxEvidence = 20
+	// MaxStructuredFindings bounds the findings listed in the structured content of a tool result.
+	MaxStructuredFindings = 200
+
+	// MaxCaptureBodyBytes bounds each request and response body recorded by a capture proxy.
+	MaxCaptureBodyBytes = 64 << 10
+	// MaxCaptureEntries bounds the transactions recorded by a capture proxy per scanner run.
+	MaxCaptureEntries = 5000
+
+	// DefaultIntelRefresh is how often the EPSS and KEV dataset files are checked for changes.
+	DefaultIntelRefresh = time.Hour
+
+	// MaxVhosts is the maximum number of virtual hosts per scan request, see the vhosts validation tag.
+	MaxVhosts = 32
+	// MaxPorts is the maximum number of ports per full scan request, see the ports validation tag.
+	MaxPorts = 32
+	// MaxOptions is the maximum number of generic scan options per request, see the options validation tag.
+	MaxOptions = 32
+	// VersionTimeout bounds running a scanner binary to report its version.
+	VersionTimeout = 10 * time.Second
 )
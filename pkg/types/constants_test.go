@@ -53,3 +53,9 @@ func TestHTTPSPort(t *testing.T) {
 		t.Errorf("expected HTTPSPort to be 443, got %d", HTTPSPort)
 	}
 }
+
+func TestMaxRedirects_Reasonable(t *testing.T) {
+	if MaxRedirects < 1 || MaxRedirects > 20 {
+		t.Errorf("MaxRedirects should be between 1 and 20, got %d", MaxRedirects)
+	}
+}
@@ -0,0 +1,232 @@
+// Package vault encrypts the secrets of stored credentials and resolves the credentials named by
+// scan inputs, so that secrets are set out of band and never travel through MCP calls.
+package vault
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/tb0hdan/wass-mcp/pkg/crypto"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"gorm.io/gorm"
+)
+
+// Credential types.
+const (
+	// TypeBasic authenticates with HTTP basic authentication.
+	TypeBasic = "basic"
+	// TypeBearer sends a bearer token in the Authorization header.
+	TypeBearer = "bearer"
+	// TypeCookie sends a session cookie.
+	TypeCookie = "cookie"
+	// TypeLoginForm logs in through an HTML login form before scanning.
+	TypeLoginForm = "login_form"
+)
+
+// Types are the credential types.
+var Types = []string{TypeBasic, TypeBearer, TypeCookie, TypeLoginForm}
+
+// HeaderTypes are the credential types sent as request headers, see Credential.Headers.
+var HeaderTypes = []string{TypeBasic, TypeBearer, TypeCookie}
+
+var (
+	// ErrDisabled is returned when using credentials without encryption keys.
+	ErrDisabled = errors.New("credential vault is disabled, start the server with --encryption-key-file or " + crypto.KeyEnv)
+	// ErrInvalidSecret is returned for secrets missing the fields of their credential type.
+	ErrInvalidSecret = errors.New("invalid credential secret")
+	// ErrUnsupportedType is returned for credentials of a type a scanner cannot use.
+	ErrUnsupportedType = errors.New("unsupported credential type")
+)
+
+// Secret is the secret part of a credential. The fields used depend on the credential type.
+type Secret struct {
+	// Cookie is the Cookie header value of cookie credentials, e.g. "session=abc".
+	Cookie string `json:"cookie,omitempty"`
+	// LoginURL is the URL of the login form of login_form credentials.
+	LoginURL string `json:"login_url,omitempty"`
+	// Password is the password of basic and login_form credentials.
+	Password string `json:"password,omitempty"`
+	// Token is the token of bearer credentials.
+	Token string `json:"token,omitempty"`
+	// Username is the user name of basic and login_form credentials.
+	Username string `json:"username,omitempty"`
+}
+
+// String hides the secret from formatted output such as log messages.
+func (Secret) String() string {
+	return "[REDACTED]"
+}
+
+// Validate checks that secret has the fields required by the credential type credentialType.
+func (s Secret) Validate(credentialType string) error {
+	switch credentialType {
+	case TypeBasic:
+		if s.Username == "" {
+			return fmt.Errorf("%w: basic credentials require a username", ErrInvalidSecret)
+		}
+	case TypeBearer:
+		if s.Token == "" {
+			return fmt.Errorf("%w: bearer credentials require a token", ErrInvalidSecret)
+		}
+	case TypeCookie:
+		if s.Cookie == "" {
+			return fmt.Errorf("%w: cookie credentials require a cookie", ErrInvalidSecret)
+		}
+	case TypeLoginForm:
+		if s.Username == "" || s.Password == "" {
+			return fmt.Errorf("%w: login_form credentials require a username and a password", ErrInvalidSecret)
+		}
+		if parsed, err := url.Parse(s.LoginURL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("%w: login_form credentials require an http(s) login_url", ErrInvalidSecret)
+		}
+	default:
+		return fmt.Errorf("%w: %q, expected one of %s", ErrUnsupportedType, credentialType, strings.Join(Types, ", "))
+	}
+
+	return nil
+}
+
+// Credential is a resolved credential a scan authenticates with.
+type Credential struct {
+	Secret
+
+	Name string
+	Type string
+}
+
+// Headers returns the request headers carrying the credential, "Name: value" formatted. It is
+// empty for login_form credentials, which scanners must log in with.
+func (c *Credential) Headers() []string {
+	switch c.Type {
+	case TypeBasic:
+		encoded := base64.StdEncoding.EncodeToString([]byte(c.Username + ":" + c.Password))
+		return []string{"Authorization: Basic " + encoded}
+	case TypeBearer:
+		return []string{"Authorization: Bearer " + c.Token}
+	case TypeCookie:
+		return []string{"Cookie: " + c.Cookie}
+	default:
+		return nil
+	}
+}
+
+// Store loads stored credentials, see storage.Storage.
+type Store interface {
+	GetCredential(ctx context.Context, name string) (*models.Credential, error)
+}
+
+// Rewrapper rewraps the secrets of stored credentials, see storage.Storage.
+type Rewrapper interface {
+	RewrapCredentials(ctx context.Context, rewrap func(secret []byte) ([]byte, bool, error)) (int, error)
+}
+
+// Vault encrypts credential secrets with the envelope encryption of a crypto.Keyring. A nil Vault
+// is disabled and fails every operation with ErrDisabled.
+type Vault struct {
+	keyring *crypto.Keyring
+}
+
+// New creates a vault encrypting with keyring. It returns a disabled vault for a nil keyring.
+func New(keyring *crypto.Keyring) *Vault {
+	if keyring == nil {
+		return nil
+	}
+
+	return &Vault{keyring: keyring}
+}
+
+// Seal encrypts secret.
+func (v *Vault) Seal(secret Secret) ([]byte, error) {
+	if v == nil {
+		return nil, ErrDisabled
+	}
+	plaintext, err := json.Marshal(secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode secret: %w", err)
+	}
+	sealed, err := v.keyring.Seal(plaintext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt secret: %w", err)
+	}
+
+	return sealed, nil
+}
+
+// Open decrypts a secret sealed by Seal.
+func (v *Vault) Open(sealed []byte) (Secret, error) {
+	if v == nil {
+		return Secret{}, ErrDisabled
+	}
+	plaintext, err := v.keyring.Open(sealed)
+	if err != nil {
+		return Secret{}, fmt.Errorf("failed to decrypt secret: %w", err)
+	}
+
+	var secret Secret
+	if err := json.Unmarshal(plaintext, &secret); err != nil {
+		return Secret{}, fmt.Errorf("failed to decode secret: %w", err)
+	}
+
+	return secret, nil
+}
+
+// SealBytes encrypts data other than credential secrets, e.g. the inputs of queued scan jobs.
+func (v *Vault) SealBytes(data []byte) ([]byte, error) {
+	if v == nil {
+		return nil, ErrDisabled
+	}
+	sealed, err := v.keyring.Seal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt data: %w", err)
+	}
+
+	return sealed, nil
+}
+
+// OpenBytes decrypts data sealed by SealBytes.
+func (v *Vault) OpenBytes(sealed []byte) ([]byte, error) {
+	if v == nil {
+		return nil, ErrDisabled
+	}
+	data, err := v.keyring.Open(sealed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt data: %w", err)
+	}
+
+	return data, nil
+}
+
+// Rotate rewraps the secrets of the credentials in store sealed with an older master key with the
+// primary key, see crypto.Keyring.Rewrap, and returns how many changed.
+func (v *Vault) Rotate(ctx context.Context, store Rewrapper) (int, error) {
+	if v == nil {
+		return 0, ErrDisabled
+	}
+
+	return store.RewrapCredentials(ctx, v.keyring.Rewrap)
+}
+
+// Resolve loads the credential named name from store, scoped to the tenant of ctx, and decrypts it.
+func (v *Vault) Resolve(ctx context.Context, store Store, name string) (*Credential, error) {
+	if v == nil {
+		return nil, ErrDisabled
+	}
+	stored, err := store.GetCredential(ctx, name)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("credential %s not found", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load credential %s: %w", name, err)
+	}
+	secret, err := v.Open(stored.Secret)
+	if err != nil {
+		return nil, fmt.Errorf("credential %s: %w", name, err)
+	}
+
+	return &Credential{Name: stored.Name, Secret: secret, Type: stored.Type}, nil
+}
@@ -0,0 +1,188 @@
+package vault
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/crypto"
+	"github.com/tb0hdan/wass-mcp/pkg/models"
+	"github.com/tb0hdan/wass-mcp/pkg/storage"
+	"github.com/tb0hdan/wass-mcp/pkg/tenant"
+)
+
+type VaultTestSuite struct {
+	suite.Suite
+	vault *Vault
+}
+
+func (s *VaultTestSuite) SetupTest() {
+	s.vault = newVault(s.T(), 7)
+}
+
+// newVault returns a vault sealing with a master key of bytes b.
+func newVault(t *testing.T, b byte) *Vault {
+	keyring, err := crypto.NewKeyring(bytes.Repeat([]byte{b}, crypto.KeySize))
+	if err != nil {
+		t.Fatalf("failed to create keyring: %v", err)
+	}
+	return New(keyring)
+}
+
+func (s *VaultTestSuite) TestSealOpen() {
+	secret := Secret{Username: "admin", Password: "hunter2"}
+	sealed, err := s.vault.Seal(secret)
+	s.Require().NoError(err)
+	s.NotContains(string(sealed), "hunter2")
+
+	again, err := s.vault.Seal(secret)
+	s.Require().NoError(err)
+	s.NotEqual(sealed, again, "each seal uses a fresh nonce")
+
+	opened, err := s.vault.Open(sealed)
+	s.Require().NoError(err)
+	s.Equal(secret, opened)
+
+	sealed[len(sealed)-1] ^= 0xff
+	_, err = s.vault.Open(sealed)
+	s.Error(err)
+	_, err = s.vault.Open([]byte{1})
+	s.Error(err)
+
+	_, err = newVault(s.T(), 8).Open(again)
+	s.Error(err, "a different key cannot decrypt")
+}
+
+func (s *VaultTestSuite) TestSealOpenBytes() {
+	sealed, err := s.vault.SealBytes([]byte(`{"password":"hunter2"}`))
+	s.Require().NoError(err)
+	s.NotContains(string(sealed), "hunter2")
+
+	opened, err := s.vault.OpenBytes(sealed)
+	s.Require().NoError(err)
+	s.Equal(`{"password":"hunter2"}`, string(opened))
+
+	_, err = newVault(s.T(), 8).OpenBytes(sealed)
+	s.Error(err, "a different key cannot decrypt")
+}
+
+func (s *VaultTestSuite) TestDisabled() {
+	disabled := New(nil)
+	s.Nil(disabled)
+	_, err := disabled.Seal(Secret{Token: "t"})
+	s.ErrorIs(err, ErrDisabled)
+	_, err = disabled.Open([]byte("sealed"))
+	s.ErrorIs(err, ErrDisabled)
+	_, err = disabled.SealBytes([]byte("data"))
+	s.ErrorIs(err, ErrDisabled)
+	_, err = disabled.OpenBytes([]byte("sealed"))
+	s.ErrorIs(err, ErrDisabled)
+	_, err = disabled.Resolve(context.Background(), nil, "api")
+	s.ErrorIs(err, ErrDisabled)
+	_, err = disabled.Rotate(context.Background(), nil)
+	s.ErrorIs(err, ErrDisabled)
+}
+
+func (s *VaultTestSuite) TestValidate() {
+	s.NoError(Secret{Username: "admin"}.Validate(TypeBasic))
+	s.NoError(Secret{Token: "t"}.Validate(TypeBearer))
+	s.NoError(Secret{Cookie: "session=abc"}.Validate(TypeCookie))
+	s.NoError(Secret{Username: "admin", Password: "p", LoginURL: "https://example.com/login"}.Validate(TypeLoginForm))
+
+	s.ErrorIs(Secret{}.Validate(TypeBasic), ErrInvalidSecret)
+	s.ErrorIs(Secret{Username: "admin"}.Validate(TypeBearer), ErrInvalidSecret)
+	s.ErrorIs(Secret{Token: "t"}.Validate(TypeCookie), ErrInvalidSecret)
+	s.ErrorIs(Secret{Username: "admin", Password: "p", LoginURL: "/login"}.Validate(TypeLoginForm), ErrInvalidSecret)
+	s.ErrorIs(Secret{Username: "admin"}.Validate("ntlm"), ErrUnsupportedType)
+}
+
+func (s *VaultTestSuite) TestHeaders() {
+	basic := &Credential{Type: TypeBasic, Secret: Secret{Username: "user", Password: "pass"}}
+	s.Equal([]string{"Authorization: Basic dXNlcjpwYXNz"}, basic.Headers())
+	bearer := &Credential{Type: TypeBearer, Secret: Secret{Token: "abc"}}
+	s.Equal([]string{"Authorization: Bearer abc"}, bearer.Headers())
+	cookie := &Credential{Type: TypeCookie, Secret: Secret{Cookie: "session=abc"}}
+	s.Equal([]string{"Cookie: session=abc"}, cookie.Headers())
+	form := &Credential{Type: TypeLoginForm, Secret: Secret{Username: "user", Password: "pass"}}
+	s.Empty(form.Headers())
+}
+
+func (s *VaultTestSuite) TestFormattingHidesSecret() {
+	credential := &Credential{Name: "api", Type: TypeBearer, Secret: Secret{Token: "top-secret"}}
+	s.NotContains(fmt.Sprintf("%v %+v %s", credential, *credential, credential.Secret), "top-secret")
+}
+
+// newStorage returns an empty storage removed after the test.
+func (s *VaultTestSuite) newStorage() storage.Storage {
+	tmpFile, err := os.CreateTemp("", "vault-test-*.db")
+	s.Require().NoError(err)
+	s.Require().NoError(tmpFile.Close())
+	s.T().Cleanup(func() { _ = os.Remove(tmpFile.Name()) })
+	store, err := storage.NewSQLiteStorage(storage.Config{DatabasePath: tmpFile.Name()})
+	s.Require().NoError(err)
+	s.T().Cleanup(func() { _ = store.Close() })
+
+	return store
+}
+
+func (s *VaultTestSuite) TestResolve() {
+	store := s.newStorage()
+
+	alpha := tenant.WithTenant(context.Background(), "alpha")
+	beta := tenant.WithTenant(context.Background(), "beta")
+	sealed, err := s.vault.Seal(Secret{Token: "abc"})
+	s.Require().NoError(err)
+	s.Require().NoError(store.SaveCredential(alpha, &models.Credential{Name: "api", Type: TypeBearer, Secret: sealed}))
+
+	credential, err := s.vault.Resolve(alpha, store, "api")
+	s.Require().NoError(err)
+	s.Equal("api", credential.Name)
+	s.Equal(TypeBearer, credential.Type)
+	s.Equal("abc", credential.Token)
+
+	_, err = s.vault.Resolve(beta, store, "api")
+	s.ErrorContains(err, "credential api not found")
+}
+
+func (s *VaultTestSuite) TestRotate() {
+	store := s.newStorage()
+	alpha := tenant.WithTenant(context.Background(), "alpha")
+	beta := tenant.WithTenant(context.Background(), "beta")
+	for _, ctx := range []context.Context{alpha, beta} {
+		sealed, err := s.vault.Seal(Secret{Token: "abc"})
+		s.Require().NoError(err)
+		s.Require().NoError(store.SaveCredential(ctx, &models.Credential{Name: "api", Type: TypeBearer, Secret: sealed}))
+	}
+
+	oldKey := bytes.Repeat([]byte{7}, crypto.KeySize)
+	keyring, err := crypto.NewKeyring(bytes.Repeat([]byte{9}, crypto.KeySize), oldKey)
+	s.Require().NoError(err)
+	rotated := New(keyring)
+
+	changed, err := rotated.Rotate(context.Background(), store)
+	s.Require().NoError(err)
+	s.Equal(2, changed)
+	changed, err = rotated.Rotate(context.Background(), store)
+	s.Require().NoError(err)
+	s.Zero(changed, "secrets already sealed with the primary key are kept")
+
+	stored, err := store.GetCredential(beta, "api")
+	s.Require().NoError(err)
+	keyID, err := crypto.SealedKeyID(stored.Secret)
+	s.Require().NoError(err)
+	s.Equal(keyring.Primary(), keyID)
+
+	_, err = s.vault.Resolve(beta, store, "api")
+	s.ErrorIs(err, crypto.ErrUnknownKey, "the old key alone no longer opens rotated secrets")
+	newOnly := newVault(s.T(), 9)
+	credential, err := newOnly.Resolve(beta, store, "api")
+	s.Require().NoError(err)
+	s.Equal("abc", credential.Token)
+}
+
+func TestVaultTestSuite(t *testing.T) {
+	suite.Run(t, new(VaultTestSuite))
+}
@@ -0,0 +1,366 @@
+// Package wordlist manages the wordlists content discovery scanners brute-force paths with.
+// Wordlists are uploaded once, stored under a data directory and referenced by name in scan
+// inputs (e.g. wordlist: "raft-medium"), so that agents never pass host paths. Lists uploaded
+// without a tenant are shared by every tenant; a tenant's own lists take precedence over shared
+// lists of the same name.
+package wordlist
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tb0hdan/wass-mcp/pkg/tenant"
+)
+
+const (
+	// DefaultMaxSize is the default size limit of an uploaded wordlist in bytes.
+	DefaultMaxSize = 64 << 20
+	// extension is the file extension of stored wordlists.
+	extension = ".txt"
+	// tenantsDir is the subdirectory of the tenant-scoped wordlists, one directory per tenant.
+	tenantsDir = "tenants"
+	dirPerms   = 0o750
+	filePerms  = 0o640
+)
+
+var (
+	// ErrDisabled is returned when using wordlists on a server without a wordlist directory.
+	ErrDisabled = errors.New("wordlists are disabled, start the server with --wordlist-dir")
+	// ErrInvalidName is returned for wordlist names that are not plain, short identifiers.
+	ErrInvalidName = errors.New("wordlist name must be 1-64 letters, digits, dots, dashes or underscores")
+	// ErrNotFound is returned for wordlists that do not exist.
+	ErrNotFound = errors.New("wordlist not found")
+	// ErrTooLarge is returned for uploads exceeding the size limit.
+	ErrTooLarge = errors.New("wordlist exceeds the size limit")
+	// ErrInvalidTenant is returned for tenant names that cannot name a wordlist directory.
+	ErrInvalidTenant = errors.New("invalid tenant name")
+	// ErrInvalidContent is returned for uploads that are empty or not text.
+	ErrInvalidContent = errors.New("wordlist must be non-empty text with one entry per line")
+	// nameRe matches valid wordlist names.
+	nameRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]{0,63}$`)
+)
+
+// Info describes a stored wordlist.
+type Info struct {
+	// Entries is the number of non-empty lines, counted when a single wordlist is loaded.
+	Entries    int       `json:"entries,omitempty"`
+	ModifiedAt time.Time `json:"modified_at"`
+	Name       string    `json:"name"`
+	// Shared reports whether the wordlist is available to every tenant.
+	Shared bool  `json:"shared"`
+	Size   int64 `json:"size"`
+}
+
+// Registry stores wordlists under a directory. A nil Registry is disabled and fails every
+// operation with ErrDisabled.
+type Registry struct {
+	dir     string
+	maxSize int64
+}
+
+// New creates a registry storing wordlists under dir, accepting uploads of at most maxSize bytes,
+// DefaultMaxSize when not positive. It returns a disabled registry for an empty dir.
+func New(dir string, maxSize int64) *Registry {
+	if dir == "" {
+		return nil
+	}
+	if maxSize <= 0 {
+		maxSize = DefaultMaxSize
+	}
+
+	return &Registry{dir: dir, maxSize: maxSize}
+}
+
+// ValidName reports whether name can name a wordlist.
+func ValidName(name string) bool {
+	return nameRe.MatchString(name)
+}
+
+// MaxSize returns the size limit of uploads in bytes.
+func (r *Registry) MaxSize() int64 {
+	if r == nil {
+		return 0
+	}
+	return r.maxSize
+}
+
+// Save stores the wordlist read from content as name in the scope of the tenant of ctx, replacing
+// any wordlist of the same name. The file is written atomically, so scans running with the
+// previous version are not affected.
+func (r *Registry) Save(ctx context.Context, name string, content io.Reader) (*Info, error) {
+	if r == nil {
+		return nil, ErrDisabled
+	}
+	if !ValidName(name) {
+		return nil, fmt.Errorf("%w, got %q", ErrInvalidName, name)
+	}
+	dir, err := r.scopeDir(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, dirPerms); err != nil {
+		return nil, fmt.Errorf("failed to create wordlist directory: %w", err)
+	}
+
+	file, err := os.CreateTemp(dir, "."+name+"-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create wordlist: %w", err)
+	}
+	defer func() { _ = os.Remove(file.Name()) }()
+
+	entries, size, err := copyEntries(file, io.LimitReader(content, r.maxSize+1))
+	if err == nil && size > r.maxSize {
+		err = fmt.Errorf("%w of %d bytes", ErrTooLarge, r.maxSize)
+	}
+	if err == nil && entries == 0 {
+		err = ErrInvalidContent
+	}
+	if err == nil {
+		err = file.Chmod(filePerms)
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	path := filepath.Join(dir, name+extension)
+	if err := os.Rename(file.Name(), path); err != nil {
+		return nil, fmt.Errorf("failed to store wordlist: %w", err)
+	}
+	wordlist, err := r.describe(path, name, dir == r.dir)
+	if err != nil {
+		return nil, err
+	}
+	wordlist.Entries = entries
+
+	return wordlist, nil
+}
+
+// List returns the wordlists available to the tenant of ctx, sorted by name: its own wordlists
+// and the shared ones it does not override.
+func (r *Registry) List(ctx context.Context) ([]Info, error) {
+	if r == nil {
+		return nil, ErrDisabled
+	}
+	dirs, err := r.searchDirs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]Info)
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list wordlists: %w", err)
+		}
+
+		for _, entry := range entries {
+			name, ok := strings.CutSuffix(entry.Name(), extension)
+			if !ok || !entry.Type().IsRegular() || !ValidName(name) {
+				continue
+			}
+			if _, overridden := byName[name]; overridden {
+				continue
+			}
+			wordlist, err := r.describe(filepath.Join(dir, entry.Name()), name, dir == r.dir)
+			if err != nil {
+				return nil, err
+			}
+			byName[name] = *wordlist
+		}
+	}
+
+	wordlists := make([]Info, 0, len(byName))
+	for _, wordlist := range byName {
+		wordlists = append(wordlists, wordlist)
+	}
+	sort.Slice(wordlists, func(i, j int) bool { return wordlists[i].Name < wordlists[j].Name })
+
+	return wordlists, nil
+}
+
+// Get returns the wordlist name available to the tenant of ctx with its entry count, and up to
+// head of its first entries.
+func (r *Registry) Get(ctx context.Context, name string, head int) (*Info, []string, error) {
+	path, shared, err := r.find(ctx, name)
+	if err != nil {
+		return nil, nil, err
+	}
+	wordlist, err := r.describe(path, name, shared)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	file, err := os.Open(path) //nolint:gosec
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read wordlist %s: %w", name, err)
+	}
+	defer func() { _ = file.Close() }()
+
+	var first []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		wordlist.Entries++
+		if len(first) < head {
+			first = append(first, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to read wordlist %s: %w", name, err)
+	}
+
+	return wordlist, first, nil
+}
+
+// Delete removes the wordlist name from the scope of the tenant of ctx. Tenants cannot delete
+// shared wordlists.
+func (r *Registry) Delete(ctx context.Context, name string) error {
+	if r == nil {
+		return ErrDisabled
+	}
+	if !ValidName(name) {
+		return fmt.Errorf("%w, got %q", ErrInvalidName, name)
+	}
+
+	dir, err := r.scopeDir(ctx)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(filepath.Join(dir, name+extension))
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete wordlist %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// Resolve returns the path of the wordlist name available to the tenant of ctx, which scanners
+// read the entries from.
+func (r *Registry) Resolve(ctx context.Context, name string) (string, error) {
+	path, _, err := r.find(ctx, name)
+	return path, err
+}
+
+// find returns the path of the wordlist name available to the tenant of ctx and whether it is
+// shared.
+func (r *Registry) find(ctx context.Context, name string) (string, bool, error) {
+	if r == nil {
+		return "", false, ErrDisabled
+	}
+	if !ValidName(name) {
+		return "", false, fmt.Errorf("%w, got %q", ErrInvalidName, name)
+	}
+
+	dirs, err := r.searchDirs(ctx)
+	if err != nil {
+		return "", false, err
+	}
+	for _, dir := range dirs {
+		path := filepath.Join(dir, name+extension)
+		if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() {
+			return path, dir == r.dir, nil
+		}
+	}
+
+	return "", false, fmt.Errorf("%w: %s", ErrNotFound, name)
+}
+
+// describe returns the metadata of the wordlist stored at path.
+func (r *Registry) describe(path, name string, shared bool) (*Info, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read wordlist %s: %w", name, err)
+	}
+
+	return &Info{ModifiedAt: info.ModTime().UTC(), Name: name, Shared: shared, Size: info.Size()}, nil
+}
+
+// scopeDir returns the directory of the wordlists of the tenant of ctx, the shared directory
+// without a tenant. Tenant names are path elements, so names that are not plain identifiers, or
+// that would resolve outside the tenants directory, fail with ErrInvalidTenant.
+func (r *Registry) scopeDir(ctx context.Context) (string, error) {
+	name, ok := tenant.FromContext(ctx)
+	if !ok || name == "" {
+		return r.dir, nil
+	}
+	if !tenant.ValidName(name) {
+		return "", fmt.Errorf("%w %q", ErrInvalidTenant, name)
+	}
+	tenants := filepath.Join(r.dir, tenantsDir)
+	dir := filepath.Join(tenants, name)
+	if rel, err := filepath.Rel(tenants, dir); err != nil || rel != name {
+		return "", fmt.Errorf("%w %q", ErrInvalidTenant, name)
+	}
+
+	return dir, nil
+}
+
+// searchDirs returns the directories wordlists are looked up in for the tenant of ctx, its own
+// directory first.
+func (r *Registry) searchDirs(ctx context.Context) ([]string, error) {
+	dir, err := r.scopeDir(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if dir != r.dir {
+		return []string{dir, r.dir}, nil
+	}
+	return []string{r.dir}, nil
+}
+
+// copyEntries copies the lines of src to dst with line endings normalized to "\n", returning the
+// number of non-empty lines and the bytes read. Content holding NUL bytes is rejected as binary.
+func copyEntries(dst io.Writer, src io.Reader) (int, int64, error) {
+	reader := bufio.NewReader(src)
+	writer := bufio.NewWriter(dst)
+
+	var (
+		entries int
+		size    int64
+	)
+	for {
+		line, err := reader.ReadBytes('\n')
+		size += int64(len(line))
+		if bytes.IndexByte(line, 0) >= 0 {
+			return 0, size, ErrInvalidContent
+		}
+		if trimmed := bytes.TrimRight(line, "\r\n"); len(bytes.TrimSpace(trimmed)) > 0 {
+			entries++
+			if _, writeErr := writer.Write(append(trimmed, '\n')); writeErr != nil {
+				return 0, size, fmt.Errorf("failed to write wordlist: %w", writeErr)
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return 0, size, fmt.Errorf("failed to read wordlist: %w", err)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		return 0, size, fmt.Errorf("failed to write wordlist: %w", err)
+	}
+
+	return entries, size, nil
+}
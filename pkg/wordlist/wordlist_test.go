@@ -0,0 +1,147 @@
+package wordlist
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/tb0hdan/wass-mcp/pkg/tenant"
+)
+
+type WordlistTestSuite struct {
+	suite.Suite
+	dir      string
+	registry *Registry
+}
+
+func (s *WordlistTestSuite) SetupTest() {
+	s.dir = s.T().TempDir()
+	s.registry = New(s.dir, 0)
+}
+
+func (s *WordlistTestSuite) TestValidName() {
+	s.True(ValidName("raft-medium"))
+	s.True(ValidName("common_2.0"))
+	s.False(ValidName(""))
+	s.False(ValidName(".hidden"))
+	s.False(ValidName("../etc/passwd"))
+	s.False(ValidName("a/b"))
+	s.False(ValidName(strings.Repeat("a", 65)))
+}
+
+func (s *WordlistTestSuite) TestDisabled() {
+	disabled := New("", 0)
+	s.Nil(disabled)
+	ctx := context.Background()
+	_, err := disabled.Save(ctx, "common", strings.NewReader("admin"))
+	s.ErrorIs(err, ErrDisabled)
+	_, err = disabled.List(ctx)
+	s.ErrorIs(err, ErrDisabled)
+	_, _, err = disabled.Get(ctx, "common", 0)
+	s.ErrorIs(err, ErrDisabled)
+	_, err = disabled.Resolve(ctx, "common")
+	s.ErrorIs(err, ErrDisabled)
+	s.ErrorIs(disabled.Delete(ctx, "common"), ErrDisabled)
+}
+
+func (s *WordlistTestSuite) TestSaveGetResolve() {
+	ctx := context.Background()
+	saved, err := s.registry.Save(ctx, "raft-medium", strings.NewReader("admin\r\n\nbackup\n  \n.git"))
+	s.Require().NoError(err)
+	s.Equal("raft-medium", saved.Name)
+	s.Equal(3, saved.Entries)
+	s.True(saved.Shared)
+
+	path, err := s.registry.Resolve(ctx, "raft-medium")
+	s.Require().NoError(err)
+	s.Equal(filepath.Join(s.dir, "raft-medium.txt"), path)
+	data, err := os.ReadFile(path)
+	s.Require().NoError(err)
+	s.Equal("admin\nbackup\n.git\n", string(data), "line endings are normalized and blank lines dropped")
+
+	wordlist, head, err := s.registry.Get(ctx, "raft-medium", 2)
+	s.Require().NoError(err)
+	s.Equal(3, wordlist.Entries)
+	s.Equal(int64(len(data)), wordlist.Size)
+	s.Equal([]string{"admin", "backup"}, head)
+
+	_, err = s.registry.Resolve(ctx, "missing")
+	s.ErrorIs(err, ErrNotFound)
+	_, err = s.registry.Resolve(ctx, "../raft-medium")
+	s.ErrorIs(err, ErrInvalidName)
+}
+
+func (s *WordlistTestSuite) TestSave_Invalid() {
+	ctx := context.Background()
+	_, err := s.registry.Save(ctx, "empty", strings.NewReader("\n\n"))
+	s.ErrorIs(err, ErrInvalidContent)
+	_, err = s.registry.Save(ctx, "binary", strings.NewReader("admin\n\x00\x01"))
+	s.ErrorIs(err, ErrInvalidContent)
+	_, err = s.registry.Save(ctx, "a/b", strings.NewReader("admin"))
+	s.ErrorIs(err, ErrInvalidName)
+
+	small := New(s.dir, 8)
+	_, err = small.Save(ctx, "large", strings.NewReader("admin\nbackup\n"))
+	s.ErrorIs(err, ErrTooLarge)
+
+	wordlists, err := s.registry.List(ctx)
+	s.Require().NoError(err)
+	s.Empty(wordlists, "rejected uploads leave no files behind")
+}
+
+func (s *WordlistTestSuite) TestTenantScopes() {
+	shared := context.Background()
+	alpha := tenant.WithTenant(shared, "alpha")
+	beta := tenant.WithTenant(shared, "beta")
+
+	_, err := s.registry.Save(shared, "common", strings.NewReader("admin\n"))
+	s.Require().NoError(err)
+	_, err = s.registry.Save(shared, "raft-medium", strings.NewReader("admin\n"))
+	s.Require().NoError(err)
+	own, err := s.registry.Save(alpha, "common", strings.NewReader("admin\nlogin\n"))
+	s.Require().NoError(err)
+	s.False(own.Shared)
+
+	wordlists, err := s.registry.List(alpha)
+	s.Require().NoError(err)
+	s.Require().Len(wordlists, 2)
+	s.Equal("common", wordlists[0].Name)
+	s.False(wordlists[0].Shared, "the tenant's own list overrides the shared one")
+	s.True(wordlists[1].Shared)
+
+	wordlist, _, err := s.registry.Get(alpha, "common", 0)
+	s.Require().NoError(err)
+	s.Equal(2, wordlist.Entries)
+	wordlist, _, err = s.registry.Get(beta, "common", 0)
+	s.Require().NoError(err)
+	s.Equal(1, wordlist.Entries, "other tenants see the shared list")
+
+	s.ErrorIs(s.registry.Delete(beta, "common"), ErrNotFound, "tenants cannot delete shared lists")
+	s.Require().NoError(s.registry.Delete(alpha, "common"))
+	wordlist, _, err = s.registry.Get(alpha, "common", 0)
+	s.Require().NoError(err)
+	s.True(wordlist.Shared)
+}
+
+func (s *WordlistTestSuite) TestTenantScopes_InvalidTenant() {
+	outside := filepath.Join(filepath.Dir(s.dir), "outside")
+	for _, name := range []string{"..", "../outside", "../../tmp", "alpha/../..", ".hidden"} {
+		ctx := tenant.WithTenant(context.Background(), name)
+		_, err := s.registry.Save(ctx, "common", strings.NewReader("admin\n"))
+		s.ErrorIs(err, ErrInvalidTenant, name)
+		s.ErrorIs(s.registry.Delete(ctx, "common"), ErrInvalidTenant, name)
+		_, err = s.registry.List(ctx)
+		s.ErrorIs(err, ErrInvalidTenant, name)
+		_, err = s.registry.Resolve(ctx, "common")
+		s.ErrorIs(err, ErrInvalidTenant, name)
+	}
+	s.NoFileExists(filepath.Join(outside, "common.txt"))
+	s.NoFileExists(filepath.Join(s.dir, "common.txt"), "nothing is written to the shared directory either")
+}
+
+func TestWordlistsTestSuite(t *testing.T) {
+	suite.Run(t, new(WordlistTestSuite))
+}